@@ -0,0 +1,68 @@
+package benchmarks
+
+import (
+	"fmt"
+	"runtime"
+	"testing"
+
+	"argus/internal/database"
+)
+
+// BenchmarkFileLocksDistinctPaths locks a new path on every iteration, as the task
+// repository does for each execution record, and reports how many entries remain.
+// The lock map must stay empty no matter how many paths have been used.
+func BenchmarkFileLocksDistinctPaths(b *testing.B) {
+	locks := database.NewLockMap()
+
+	var before runtime.MemStats
+	runtime.GC()
+	runtime.ReadMemStats(&before)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		unlock := locks.Lock(fmt.Sprintf("executions/exec-%d.json", i))
+		unlock()
+	}
+	b.StopTimer()
+
+	var after runtime.MemStats
+	runtime.GC()
+	runtime.ReadMemStats(&after)
+
+	if n := locks.Len(); n != 0 {
+		b.Fatalf("lock map retained %d entries after %d distinct paths", n, b.N)
+	}
+	b.ReportMetric(float64(locks.Len()), "entries")
+	b.ReportMetric(float64(int64(after.HeapAlloc)-int64(before.HeapAlloc)), "heap-delta-B")
+}
+
+// BenchmarkFileLocksContended has many goroutines competing for a small set of paths
+func BenchmarkFileLocksContended(b *testing.B) {
+	locks := database.NewLockMap()
+	paths := make([]string, 8)
+	for i := range paths {
+		paths[i] = fmt.Sprintf("tasks/task-%d.json", i)
+	}
+
+	// Each counter is only touched while its path's lock is held
+	var counters [8]int
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		i := 0
+		for pb.Next() {
+			idx := i % len(paths)
+			unlock := locks.Lock(paths[idx])
+			counters[idx]++
+			unlock()
+			i++
+		}
+	})
+	b.StopTimer()
+
+	if n := locks.Len(); n != 0 {
+		b.Fatalf("lock map retained %d entries", n)
+	}
+}
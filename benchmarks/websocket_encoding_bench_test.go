@@ -0,0 +1,106 @@
+package benchmarks
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"argus/internal/metrics"
+	"argus/internal/msgpack"
+)
+
+// processFrame builds a per-second process update like the ones streamed to
+// dashboards, with n processes
+func processFrame(n int) map[string]interface{} {
+	processes := make([]metrics.ProcessInfo, n)
+	for i := range processes {
+		processes[i] = metrics.ProcessInfo{
+			PID:        int32(1000 + i),
+			Name:       fmt.Sprintf("worker-%d", i%50),
+			CPUPercent: float64(i%17) * 0.37,
+			MemPercent: float32(i%23) * 0.11,
+		}
+	}
+	return map[string]interface{}{
+		"type": "processes",
+		"data": metrics.ProcessMetrics{Processes: processes, UpdatedAt: time.Now()},
+	}
+}
+
+// BenchmarkWebSocketEncoding compares encode time and frame size of JSON and
+// MessagePack frames; the B/frame metric is the bandwidth per client per update
+func BenchmarkWebSocketEncoding(b *testing.B) {
+	for _, n := range []int{100, 1000, 5000} {
+		frame := processFrame(n)
+		jsonFrame, err := json.Marshal(frame)
+		if err != nil {
+			b.Fatal(err)
+		}
+
+		b.Run(fmt.Sprintf("JSON/%d", n), func(b *testing.B) {
+			b.ReportAllocs()
+			var size int
+			for i := 0; i < b.N; i++ {
+				data, err := json.Marshal(frame)
+				if err != nil {
+					b.Fatal(err)
+				}
+				size = len(data)
+			}
+			b.ReportMetric(float64(size), "B/frame")
+		})
+
+		b.Run(fmt.Sprintf("Msgpack/%d", n), func(b *testing.B) {
+			b.ReportAllocs()
+			var size int
+			for i := 0; i < b.N; i++ {
+				data, err := msgpack.Marshal(frame)
+				if err != nil {
+					b.Fatal(err)
+				}
+				size = len(data)
+			}
+			b.ReportMetric(float64(size), "B/frame")
+		})
+
+		// Hub.Broadcast callers hand over JSON, which is transcoded once per broadcast
+		b.Run(fmt.Sprintf("MsgpackFromJSON/%d", n), func(b *testing.B) {
+			b.ReportAllocs()
+			var size int
+			for i := 0; i < b.N; i++ {
+				data, err := msgpack.FromJSON(jsonFrame)
+				if err != nil {
+					b.Fatal(err)
+				}
+				size = len(data)
+			}
+			b.ReportMetric(float64(size), "B/frame")
+		})
+	}
+}
+
+// BenchmarkProcessDelta measures diffing a process list in which 1% of the
+// processes changed, and the size of the delta frame against the full list
+func BenchmarkProcessDelta(b *testing.B) {
+	for _, n := range []int{1000, 5000} {
+		prev := processFrame(n)["data"].(metrics.ProcessMetrics).Processes
+		next := append([]metrics.ProcessInfo{}, prev...)
+		for i := 0; i < n; i += 100 {
+			next[i].CPUPercent += 1
+		}
+
+		b.Run(fmt.Sprintf("%d", n), func(b *testing.B) {
+			b.ReportAllocs()
+			var delta metrics.ProcessDelta
+			for i := 0; i < b.N; i++ {
+				delta = metrics.DiffProcesses(prev, next)
+			}
+			b.StopTimer()
+			full, _ := json.Marshal(next)
+			diff, _ := json.Marshal(delta)
+			b.ReportMetric(float64(len(full)), "B/full")
+			b.ReportMetric(float64(len(diff)), "B/delta")
+		})
+	}
+}
@@ -0,0 +1,158 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/shirou/gopsutil/v3/disk"
+	"github.com/shirou/gopsutil/v3/mem"
+
+	"argus/internal/agent"
+	"argus/internal/config"
+	"argus/internal/models"
+	"argus/internal/services"
+	"argus/internal/tlsclient"
+)
+
+// Usage percentages of the root filesystem or memory at which the agent
+// reports its host degraded or unhealthy
+const (
+	agentDegradedPercent  = 90
+	agentUnhealthyPercent = 95
+)
+
+// runAgent implements `argus agent`: it enrolls with a central server and reports this host to it.
+// Returns the process exit code: 0 on shutdown, 1 on failures, 2 on usage errors.
+func runAgent(args []string) int {
+	fs := flag.NewFlagSet("agent", flag.ContinueOnError)
+	server := fs.String("server", os.Getenv("ARGUS_AGENT_SERVER"), "base URL of the central server, e.g. https://argus.example.com:8080")
+	token := fs.String("token", os.Getenv("ARGUS_AGENT_TOKEN"), "shared enrollment token (default $ARGUS_AGENT_TOKEN)")
+	statePath := fs.String("state", agent.DefaultStatePath, "file keeping the agent credential and server pin")
+	pin := fs.String("pin", os.Getenv("ARGUS_AGENT_PIN"), "hex SHA-256 of the server certificate's public key (default: pin on first enrollment)")
+	id := fs.String("id", "", "host ID (default: the hostname)")
+	labels := fs.String("labels", "", "host labels as name=value pairs separated by commas")
+	interval := fs.Duration("interval", agent.DefaultInterval, "time between heartbeats")
+	caFile := fs.String("ca-file", os.Getenv("ARGUS_AGENT_CA_FILE"), "PEM bundle of CAs trusted for the server in addition to the system pool, before a pin is known")
+	certFile := fs.String("cert-file", os.Getenv("ARGUS_AGENT_CERT_FILE"), "PEM client certificate presented to the server or a proxy in front of it (mTLS)")
+	keyFile := fs.String("key-file", os.Getenv("ARGUS_AGENT_KEY_FILE"), "PEM private key of -cert-file")
+	runTasks := fs.Bool("tasks", true, "run the tasks the server assigns to this host")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if *server == "" {
+		fmt.Fprintln(os.Stderr, "argus agent: -server is required")
+		return 2
+	}
+	hostLabels, err := parseAgentLabels(*labels)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "argus agent: %v\n", err)
+		return 2
+	}
+
+	clientTLS := tlsclient.Config{CAFile: *caFile, CertFile: *certFile, KeyFile: *keyFile}
+	rootCAs, err := clientTLS.RootCAs()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "argus agent: %v\n", err)
+		return 2
+	}
+
+	setupLogger()
+	var runner agent.RunFunc
+	if *runTasks {
+		runner = runLocalTask
+	}
+	a, err := agent.New(agent.Config{
+		ServerURL:       *server,
+		EnrollmentToken: *token,
+		StatePath:       *statePath,
+		Pin:             *pin,
+		RootCAs:         rootCAs,
+		ClientCert:      clientTLS,
+		ID:              *id,
+		Labels:          hostLabels,
+		AgentVersion:    buildVersion(),
+		Interval:        *interval,
+		Health:          hostHealth,
+		Runner:          runner,
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "argus agent: %v\n", err)
+		return 1
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+	if clientTLS.CertFile != "" {
+		go watchClientCertificates(ctx, func() []tlsclient.CertStatus {
+			return []tlsclient.CertStatus{clientTLS.Check("agent", time.Now(), config.DefaultCertExpiryWarning)}
+		})
+	}
+	if err := a.Run(ctx); err != nil {
+		fmt.Fprintf(os.Stderr, "argus agent: %v\n", err)
+		if errors.Is(err, agent.ErrPinMismatch) {
+			fmt.Fprintf(os.Stderr, "argus agent: if the server's key was rotated on purpose, pass its new pin with -pin or delete %s\n", *statePath)
+		}
+		return 1
+	}
+	return 0
+}
+
+// parseAgentLabels parses labels such as "env=prod,role=web"
+func parseAgentLabels(s string) (map[string]string, error) {
+	labels := make(map[string]string)
+	for _, pair := range strings.Split(s, ",") {
+		if pair = strings.TrimSpace(pair); pair == "" {
+			continue
+		}
+		name, value, ok := strings.Cut(pair, "=")
+		if !ok || strings.TrimSpace(name) == "" {
+			return nil, fmt.Errorf("invalid label %q: expected name=value", pair)
+		}
+		labels[strings.TrimSpace(name)] = strings.TrimSpace(value)
+	}
+	return labels, nil
+}
+
+// runLocalTask runs a task the server assigned to this host with the local
+// runner of its type
+func runLocalTask(ctx context.Context, task *models.TaskConfig) (*models.TaskExecution, error) {
+	runner, err := services.NewTaskRunner(task.Type)
+	if err != nil {
+		return nil, err
+	}
+	return runner.Run(ctx, task)
+}
+
+// hostHealth grades the host by root filesystem and memory usage
+func hostHealth(ctx context.Context) (models.HostHealth, string) {
+	var worst float64
+	var problems []string
+	if usage, err := disk.UsageWithContext(ctx, "/"); err == nil {
+		worst = max(worst, usage.UsedPercent)
+		if usage.UsedPercent >= agentDegradedPercent {
+			problems = append(problems, fmt.Sprintf("root filesystem %.0f%% full", usage.UsedPercent))
+		}
+	}
+	if vm, err := mem.VirtualMemoryWithContext(ctx); err == nil {
+		worst = max(worst, vm.UsedPercent)
+		if vm.UsedPercent >= agentDegradedPercent {
+			problems = append(problems, fmt.Sprintf("memory %.0f%% used", vm.UsedPercent))
+		}
+	}
+	message := strings.Join(problems, ", ")
+	switch {
+	case worst >= agentUnhealthyPercent:
+		return models.HostUnhealthy, message
+	case worst >= agentDegradedPercent:
+		return models.HostDegraded, message
+	default:
+		return models.HostHealthy, message
+	}
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"runtime/debug"
+
+	"argus/internal/config"
+	"argus/internal/features"
+	"argus/internal/handlers"
+)
+
+// Build details of the binary, set with e.g.
+// -ldflags "-X main.version=v1.2.3 -X main.commit=$(git rev-parse HEAD) -X main.buildDate=2026-10-14T08:00:00Z"
+var (
+	version   string
+	commit    string
+	buildDate string
+)
+
+// buildVersion returns the version the binary was built as, falling back to
+// the module version
+func buildVersion() string {
+	if version != "" {
+		return version
+	}
+	if info, ok := debug.ReadBuildInfo(); ok && info.Main.Version != "" {
+		return info.Main.Version
+	}
+	return "unknown"
+}
+
+// buildInfo returns the build details of the binary. The commit and date not
+// set at link time fall back to the VCS stamp of `go build` in a checkout.
+func buildInfo() handlers.BuildInfo {
+	info := handlers.BuildInfo{Version: buildVersion(), Commit: commit, BuildDate: buildDate}
+	if vcs, ok := debug.ReadBuildInfo(); ok {
+		modified := false
+		for _, setting := range vcs.Settings {
+			switch setting.Key {
+			case "vcs.revision":
+				if info.Commit == "" {
+					info.Commit = setting.Value
+				}
+			case "vcs.time":
+				if info.BuildDate == "" {
+					info.BuildDate = setting.Value
+				}
+			case "vcs.modified":
+				modified = setting.Value == "true"
+			}
+		}
+		if modified && commit == "" && info.Commit != "" {
+			info.Commit += "-dirty"
+		}
+	}
+	return info
+}
+
+// enabledSubsystems reports which optional subsystems the configuration and
+// feature flags turn on, for support requests and UI compatibility checks
+func enabledSubsystems(cfg *config.Config, flags *features.Set) map[string]bool {
+	return map[string]bool{
+		"alerts":                  cfg.Alerts.Enabled,
+		"tasks":                   cfg.Tasks.Enabled,
+		"websocket":               cfg.WebSocket.Enabled,
+		"prometheus_remote_write": cfg.Ingest.RemoteWrite.Enabled,
+		"grafana":                 cfg.Grafana.Enabled,
+		"metrics_exporters":       len(cfg.Metrics.Exporters) > 0,
+		"agents":                  cfg.Hosts.Enabled && flags.Enabled(features.AgentMode),
+		"log_watch":               cfg.LogWatch.Enabled,
+		"syslog":                  cfg.Syslog.Enabled,
+		"status_page":             cfg.StatusPage.Enabled,
+		"api_metrics":             cfg.APIMetrics.Enabled,
+		"response_cache":          cfg.ResponseCache.Enabled,
+		"retention":               cfg.Retention.Enabled,
+		"process_details":         cfg.Monitoring.ProcessDetails,
+		"update_check":            cfg.Update.Enabled,
+		"oidc":                    cfg.Auth.OIDC.Enabled,
+		"ldap":                    cfg.Auth.LDAP.Enabled,
+	}
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"argus/internal/config"
+	"argus/internal/diagnostics"
+)
+
+// runDoctor implements `argus doctor`: it runs the environment self-test and prints a report.
+// Returns the process exit code: 0 when no check failed, 1 on failures, 2 on usage errors.
+func runDoctor(args []string) int {
+	fs := flag.NewFlagSet("doctor", flag.ContinueOnError)
+	cfgPath := fs.String("config", resolveConfigPath(), "path to the configuration file")
+	asJSON := fs.Bool("json", false, "print the report as JSON")
+	timeout := fs.Duration("timeout", diagnostics.DefaultCheckTimeout, "timeout for each network or system probe")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	cfg, err := config.LoadConfig(*cfgPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "argus doctor: failed to load configuration %s: %v\n", *cfgPath, err)
+		return 1
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+
+	report := diagnostics.Run(ctx, diagnostics.Options{
+		Config:     cfg,
+		ConfigPath: *cfgPath,
+		SMTPHost:   os.Getenv("SMTP_HOST"),
+		SMTPPort:   getEnvAsInt("SMTP_PORT", 587),
+		Sendmail:   os.Getenv("SENDMAIL_PATH"),
+		Timeout:    *timeout,
+	})
+
+	if *asJSON {
+		err = report.WriteJSON(os.Stdout)
+	} else {
+		err = report.WriteText(os.Stdout)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "argus doctor: failed to write report: %v\n", err)
+		return 1
+	}
+
+	if report.HasFailures() {
+		return 1
+	}
+	return 0
+}
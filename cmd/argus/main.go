@@ -1,26 +1,48 @@
 package main
 
 import (
+	"bytes"
 	"context"
+	"crypto/tls"
+	"encoding/json"
 	"fmt"
 	"log/slog"
 	"net/http"
 	"os"
 	"os/signal"
+	"slices"
+	"sort"
 	"strconv"
+	"strings"
 	"syscall"
 	"time"
 
 	"github.com/gin-gonic/gin"
 
+	"argus/internal/auth"
+	"argus/internal/compress"
 	"argus/internal/config"
 	"argus/internal/database"
+	"argus/internal/export"
+	"argus/internal/faults"
+	"argus/internal/features"
 	"argus/internal/handlers"
+	"argus/internal/i18n"
+	"argus/internal/ingest"
+	"argus/internal/logwatch"
 	"argus/internal/metrics"
+	"argus/internal/migrate"
 	"argus/internal/models"
+	"argus/internal/query"
+	"argus/internal/retention"
 	"argus/internal/server"
 	"argus/internal/services"
+	"argus/internal/statuspage"
+	"argus/internal/tlsclient"
+	"argus/internal/update"
+	"argus/internal/usage"
 	"argus/internal/utils"
+	"argus/internal/widgets"
 )
 
 // setupLogger configures structured logging
@@ -81,20 +103,247 @@ func getEnvAsInt(key string, defaultVal int) int {
 	return defaultVal
 }
 
-func main() {
-	// Setup structured logging
-	setupLogger()
+// overflowConfig converts a configured queue overflow policy, falling back to def
+func overflowConfig(q config.QueueConfig, def services.OverflowPolicy) services.OverflowConfig {
+	policy, err := services.ParseOverflowPolicy(q.Overflow, def)
+	if err != nil {
+		policy = def
+	}
+	timeout, _ := time.ParseDuration(q.BlockTimeout)
+	return services.OverflowConfig{Policy: policy, BlockTimeout: timeout}
+}
 
-	// Load configuration (with minimal logging)
+// outboundTransport builds the HTTP transport of an outbound integration, one
+// of config.OutboundChannels, with its proxy and TLS settings
+func outboundTransport(cfg *config.Config, channel string) http.RoundTripper {
+	transport, err := cfg.OutboundTransport(channel)
+	if err != nil { // Checked by config validation
+		slog.Error("Invalid outbound transport settings", "channel", channel, "error", err)
+		os.Exit(1)
+	}
+	if proxy := cfg.ProxyFor(channel); proxy.URL != "" {
+		slog.Info("Outbound proxy configured", "channel", channel, "proxy", proxy.String(), "no_proxy", proxy.NoProxy)
+	}
+	if t := cfg.TLSFor(channel); t.CAFile != "" || t.CertFile != "" {
+		slog.Info("Outbound TLS configured", "channel", channel, "ca_file", t.CAFile, "cert_file", t.CertFile)
+	}
+	return transport
+}
+
+// provisionAlerts stores the provisioned alerts missing from the store and
+// resets those changed since, e.g. through the API, to their configuration,
+// logging a warning for each reset alert so the lost edits can be found
+func provisionAlerts(store database.AlertStore, alerts []*models.AlertConfig) (created, reset int, err error) {
+	var changed []*models.AlertConfig
+	var resetIDs []string
+	for _, alert := range alerts {
+		stored, err := store.GetAlert(alert.ID)
+		if err == database.ErrAlertNotFound {
+			created++
+			changed = append(changed, alert)
+			continue
+		}
+		if err != nil {
+			return 0, 0, err
+		}
+		updatedAt := alert.UpdatedAt
+		alert.CreatedAt, alert.UpdatedAt = stored.CreatedAt, stored.UpdatedAt
+		want, _ := json.Marshal(alert)
+		if have, _ := json.Marshal(stored); bytes.Equal(want, have) {
+			continue
+		}
+		alert.UpdatedAt = updatedAt
+		resetIDs = append(resetIDs, alert.ID)
+		changed = append(changed, alert)
+	}
+	if len(changed) == 0 {
+		return 0, 0, nil
+	}
+	if err := store.SaveAlerts(changed); err != nil {
+		return 0, 0, err
+	}
+	for _, id := range resetIDs {
+		slog.Warn("Provisioned alert reset to its configuration, discarding changes made since", "id", id)
+	}
+	return created, len(resetIDs), nil
+}
+
+// newAuthenticator creates the login providers the configuration enables
+func newAuthenticator(cfg *config.Config) *auth.Authenticator {
+	roles, _ := cfg.RoleMapping() // Checked by config validation
+	sessions, _ := cfg.SessionConfig()
+	authenticator := &auth.Authenticator{Roles: roles, Sessions: auth.NewSessions(sessions)}
+	if o := cfg.Auth.OIDC; o.Enabled {
+		authenticator.OIDC = auth.NewOIDC(auth.OIDCConfig{
+			Issuer:       o.Issuer,
+			ClientID:     o.ClientID,
+			ClientSecret: o.ClientSecret,
+			RedirectURL:  o.RedirectURL,
+			Scopes:       o.Scopes,
+			Audience:     o.Audience,
+			UserClaim:    o.UserClaim,
+			GroupsClaim:  o.GroupsClaim,
+			Client:       &http.Client{Timeout: 10 * time.Second, Transport: outboundTransport(cfg, "oidc")},
+		})
+	}
+	if l := cfg.Auth.LDAP; l.Enabled {
+		timeout, _ := time.ParseDuration(l.Timeout)
+		roots, _ := (tlsclient.Config{CAFile: l.CAFile}).RootCAs()
+		ldap, err := auth.NewLDAP(auth.LDAPConfig{
+			URL:            l.URL,
+			BindDN:         l.BindDN,
+			BindPassword:   l.BindPassword,
+			BaseDN:         l.BaseDN,
+			UserAttribute:  l.UserAttribute,
+			GroupAttribute: l.GroupAttribute,
+			Timeout:        timeout,
+			TLS:            &tls.Config{RootCAs: roots, MinVersion: tls.VersionTLS12},
+		})
+		if err != nil { // Checked by config validation
+			slog.Error("Invalid LDAP settings", "error", err)
+			os.Exit(1)
+		}
+		authenticator.LDAP = ldap
+	}
+	if keys, _ := cfg.APIKeys(); len(keys) > 0 {
+		authenticator.APIKeys = auth.NewAPIKeys(cfg.Auth.APIKeyHeader, keys)
+	}
+	return authenticator
+}
+
+// clientCertificateStatus reports the expiry of the configured outbound
+// client certificates, sorted by name
+func clientCertificateStatus(cfg *config.Config) []tlsclient.CertStatus {
+	certs := cfg.ClientCertificates()
+	names := make([]string, 0, len(certs))
+	for name := range certs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	now := time.Now()
+	statuses := make([]tlsclient.CertStatus, 0, len(names))
+	for _, name := range names {
+		statuses = append(statuses, certs[name].Check(name, now, cfg.CertExpiryWarning()))
+	}
+	return statuses
+}
+
+// watchClientCertificates logs a warning for every outbound client
+// certificate reported expiring, expired or unreadable, at startup and then
+// daily until ctx is done
+func watchClientCertificates(ctx context.Context, status func() []tlsclient.CertStatus) {
+	ticker := time.NewTicker(24 * time.Hour)
+	defer ticker.Stop()
+	for {
+		for _, cert := range status() {
+			switch cert.Status {
+			case tlsclient.StatusExpiring:
+				slog.Warn("Outbound client certificate expires soon", "name", cert.Name, "cert_file", cert.File, "subject", cert.Subject, "not_after", cert.NotAfter, "days_left", cert.DaysLeft)
+			case tlsclient.StatusExpired:
+				slog.Error("Outbound client certificate has expired", "name", cert.Name, "cert_file", cert.File, "subject", cert.Subject, "not_after", cert.NotAfter)
+			case tlsclient.StatusError:
+				slog.Error("Outbound client certificate is unreadable", "name", cert.Name, "cert_file", cert.File, "error", cert.Error)
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// channelTemplateStates maps a channel template state to the event states it
+// renders: active covers pending and active alerts, resolved both ways out
+var channelTemplateStates = map[string][]models.AlertState{
+	"active":   {models.StatePending, models.StateActive},
+	"resolved": {models.StateResolved, models.StateInactive},
+}
+
+// channelTemplates expands the configured channel templates into the set of
+// every severity and state they match; later entries win
+func channelTemplates(configured map[string][]config.ChannelTemplate) map[models.NotificationType]map[models.AlertSeverity]map[models.AlertState]services.NotificationTemplate {
+	result := make(map[models.NotificationType]map[models.AlertSeverity]map[models.AlertState]services.NotificationTemplate, len(configured))
+	for channel, templates := range configured {
+		set := make(map[models.AlertSeverity]map[models.AlertState]services.NotificationTemplate)
+		for _, tmpl := range templates {
+			severities := models.Severities
+			if tmpl.Severity != "" {
+				severities = []models.AlertSeverity{models.AlertSeverity(tmpl.Severity)}
+			}
+			states := slices.Concat(channelTemplateStates["active"], channelTemplateStates["resolved"])
+			if tmpl.State != "" {
+				states = channelTemplateStates[tmpl.State]
+			}
+			for _, severity := range severities {
+				if set[severity] == nil {
+					set[severity] = make(map[models.AlertState]services.NotificationTemplate)
+				}
+				for _, state := range states {
+					set[severity][state] = services.NotificationTemplate{Subject: tmpl.Subject, Body: tmpl.Body}
+				}
+			}
+		}
+		result[models.NotificationType(channel)] = set
+	}
+	return result
+}
+
+// resolveConfigPath returns config.yaml if present, otherwise the bundled example config
+func resolveConfigPath() string {
 	cfgPath := "config.yaml"
 	if _, err := os.Stat(cfgPath); os.IsNotExist(err) {
 		cfgPath = "config.example.yaml"
 	}
+	return cfgPath
+}
+
+func main() {
+	// Subcommands run instead of the server
+	if len(os.Args) > 1 && os.Args[1] == "doctor" {
+		os.Exit(runDoctor(os.Args[2:]))
+	}
+	if len(os.Args) > 1 && os.Args[1] == "agent" {
+		os.Exit(runAgent(os.Args[2:]))
+	}
+	if len(os.Args) > 1 && os.Args[1] == "update" {
+		os.Exit(runUpdate(os.Args[2:]))
+	}
+
+	// Setup structured logging
+	setupLogger()
+
+	// Load configuration (with minimal logging)
+	cfgPath := resolveConfigPath()
 	cfg, err := config.LoadConfig(cfgPath)
 	if err != nil {
 		slog.Error("Failed to load configuration", "error", err)
 		os.Exit(1)
 	}
+	featureFlags, err := features.New(cfg.Features) // Validated with the configuration
+	if err != nil {
+		slog.Error("Invalid feature flags", "error", err)
+		os.Exit(1)
+	}
+
+	// Bring the on-disk data to the layout this binary expects before any store opens it
+	migrator, err := migrate.New(migrate.Config{
+		BaseDir: cfg.Storage.BasePath,
+		Dirs:    []string{cfg.Storage.BasePath, cfg.Alerts.StoragePath, cfg.Tasks.StoragePath},
+		Backup:  cfg.Storage.BackupEnabled,
+	}, database.Migrations(cfg.Alerts.StoragePath, cfg.Tasks.StoragePath))
+	if err != nil {
+		slog.Error("Invalid data migrations", "error", err)
+		os.Exit(1)
+	}
+	migration, err := migrator.Run()
+	if err != nil {
+		slog.Error("Failed to migrate data", "from", migration.From, "to", migration.To, "error", err)
+		os.Exit(1)
+	}
+	if len(migration.Applied) > 0 {
+		slog.Info("Data migrated", "from", migration.From, "to", migration.To, "backup", migration.BackupDir)
+	}
 
 	// Initialize metrics collector
 	metricsConfig := metrics.DefaultConfig()
@@ -104,9 +353,30 @@ func main() {
 			metricsConfig.UpdateInterval = interval
 		}
 	}
+	if ttl, err := time.ParseDuration(cfg.Monitoring.CacheTTL); err == nil {
+		metricsConfig.CacheTTL = ttl
+	}
+	metricsConfig.Disabled = cfg.Monitoring.DisabledCollectors
 	if cfg.Monitoring.ProcessLimit > 0 {
 		metricsConfig.ProcessLimit = cfg.Monitoring.ProcessLimit
 	}
+	if cfg.Monitoring.ProcessLimitMin > 0 {
+		metricsConfig.MinProcessLimit = cfg.Monitoring.ProcessLimitMin
+	}
+	if budget, err := time.ParseDuration(cfg.Monitoring.ProcessBudget); err == nil {
+		metricsConfig.ProcessBudget = budget
+	}
+	if timeout, err := time.ParseDuration(cfg.Monitoring.CallTimeout); err == nil {
+		metricsConfig.CallTimeout = timeout
+	}
+	// Both the collector and the evaluator throttle errors that repeat every round
+	logRepeatInterval, _ := time.ParseDuration(cfg.Logging.RepeatInterval)
+	metricsConfig.ProcessDetails = cfg.Monitoring.ProcessDetails
+	metricsConfig.LogRepeatInterval = logRepeatInterval
+	metricsConfig.UPSServers = cfg.Monitoring.UPSServers
+	if len(cfg.Monitoring.Services) > 0 {
+		metricsConfig.Services = cfg.Monitoring.Services
+	}
 
 	metricsCollector := metrics.NewCollector(metricsConfig)
 
@@ -114,6 +384,41 @@ func main() {
 	metricsCtx, metricsCancel := context.WithCancel(context.Background())
 	defer metricsCancel()
 
+	// History of the collected metrics, read by history queries, rollups,
+	// dashboard widgets, alert impact previews, the samples of alert events and
+	// the Grafana datasource
+	var metricsHistory *metrics.SeriesStore
+	var metricsRollups *metrics.Rollups
+	if cfg.Monitoring.HistoryEnabled || cfg.Grafana.Enabled {
+		historyConfig := metrics.DefaultSeriesStoreConfig()
+		historyConfig.Retention = 24 * time.Hour
+		if maxAge, err := time.ParseDuration(cfg.Monitoring.MetricsRetention); err == nil && maxAge > 0 {
+			historyConfig.Retention = maxAge
+		}
+		historyConfig.MaxSeries = 0 // One series per metric and mounted partition
+		if metricsConfig.UpdateInterval > 0 {
+			historyConfig.MaxSamplesPerSeries = int(historyConfig.Retention/metricsConfig.UpdateInterval) + 1
+		}
+		metricsHistory = metrics.NewSeriesStore(historyConfig)
+		metricsHistory.StartPruner(metricsCtx, time.Minute)
+		metricsCollector.SetHistory(metricsHistory)
+
+		// Downsampled copies kept longer than the raw samples for long-range queries
+		resolutions, _ := cfg.RollupResolutions() // Checked by config validation
+		if len(resolutions) > 0 {
+			rollupConfig := metrics.DefaultRollupConfig()
+			rollupConfig.Resolutions = nil
+			for _, r := range resolutions {
+				rollupConfig.Resolutions = append(rollupConfig.Resolutions, metrics.Resolution{Step: r.Step, Retention: r.Retention})
+			}
+			if metricsRollups, err = metrics.NewRollups(metricsHistory, rollupConfig); err != nil {
+				slog.Error("Failed to initialize metric rollups", "error", err)
+				os.Exit(1)
+			}
+			metricsRollups.Start(metricsCtx)
+		}
+	}
+
 	// Start the metrics collector
 	if err := metricsCollector.Start(metricsCtx); err != nil {
 		slog.Error("Failed to start metrics collector", "error", err)
@@ -121,17 +426,148 @@ func main() {
 	}
 	slog.Info("Metrics collector started successfully")
 
-	// Initialize alert storage
-	alertStore, err := database.NewAlertStore(cfg.Alerts.StoragePath)
+	// Pushes of the collected metrics to remote TSDBs
+	hostname, _ := os.Hostname()
+	var metricsExporters []*export.Exporter
+	for _, e := range cfg.Metrics.Exporters {
+		selector, _ := ingest.NewSelector(e.Metrics) // Checked by config validation
+		exporterConfig := export.Config{
+			Name:      e.Name,
+			Format:    export.Format(e.Format),
+			URL:       e.URL,
+			Headers:   e.Headers,
+			Labels:    map[string]string{"host": hostname},
+			Selector:  selector,
+			Transport: outboundTransport(cfg, "exporters"),
+		}
+		for k, v := range e.Labels {
+			exporterConfig.Labels[k] = v
+		}
+		exporterConfig.Interval, _ = time.ParseDuration(e.Interval)
+		exporterConfig.Timeout, _ = time.ParseDuration(e.Timeout)
+		exporter, err := export.New(metricsCollector, exporterConfig)
+		if err != nil {
+			slog.Error("Failed to initialize metrics exporter", "exporter", e.Name, "error", err)
+			os.Exit(1)
+		}
+		exporter.Start(metricsCtx)
+		metricsExporters = append(metricsExporters, exporter)
+		slog.Info("Metrics exporter started", "exporter", exporter.Stats().Name, "format", e.Format, "endpoint", exporter.Stats().Endpoint)
+	}
+
+	// Open the alert and task stores of storage.driver; a new SQLite database
+	// imports the file stores
+	storageDriver := cfg.StorageDriver()
+	if storageDriver == database.DriverSQLite && !featureFlags.Enabled(features.SQLiteStorage) {
+		slog.Warn("SQLite storage disabled by feature flag, using the JSON file stores", "feature", features.SQLiteStorage)
+		storageDriver = database.DriverFile
+	}
+	stores, err := database.OpenDriver(storageDriver, database.DriverOptions{
+		AlertsPath: cfg.Alerts.StoragePath,
+		TasksPath:  cfg.Tasks.StoragePath,
+		Options:    cfg.StorageDriverOptions(),
+	})
 	if err != nil {
-		slog.Error("Failed to initialize alert storage", "error", err)
+		slog.Error("Failed to open storage", "driver", storageDriver, "error", err)
 		os.Exit(1)
 	}
+	defer stores.Close()
+	slog.Info("Storage opened", "driver", storageDriver)
+
+	// Initialize alert storage
+	alertStore := stores.Alerts
+	if alg, err := compress.Parse(cfg.Alerts.HistoryCompression); err == nil {
+		alertStore.SetHistoryCompression(alg)
+	}
+	flushInterval, err := time.ParseDuration(cfg.Alerts.FlushInterval)
+	if err != nil {
+		flushInterval = database.DefaultFlushInterval
+	}
+	storeCtx, storeCancel := context.WithCancel(context.Background())
+	defer storeCancel()
+	alertStore.StartFlusher(storeCtx, flushInterval)
+
+	// Create the alerts of the provisioned_alerts section, or reset them to it
+	if provisioned, err := cfg.ProvisionedAlertConfigs(time.Now()); err == nil && len(provisioned) > 0 { // Checked by config validation
+		if created, reset, err := provisionAlerts(alertStore, provisioned); err != nil {
+			slog.Error("Failed to provision alerts", "error", err)
+		} else {
+			slog.Info("Alerts provisioned", "alerts", len(provisioned), "created", created, "reset", reset)
+		}
+	}
 
 	// Initialize alert evaluator
 	evalConfig := services.DefaultEvaluatorConfig()
+	if cfg.Alerts.Queues.Events.Size > 0 {
+		evalConfig.EventChannelSize = cfg.Alerts.Queues.Events.Size
+	}
+	evalConfig.EventOverflow = overflowConfig(cfg.Alerts.Queues.Events, services.OverflowDropNewest)
+	evalConfig.LogRepeatInterval = logRepeatInterval
 	alertEvaluator := services.NewEvaluator(alertStore, evalConfig)
 	alertEvaluator.SetMetricsCollector(metricsCollector)
+	endpointResults := services.NewEndpointResults() // Latest health_check probes, evaluated by http alerts
+	alertEvaluator.SetEndpointResults(endpointResults)
+	if metricsHistory != nil {
+		alertEvaluator.SetHistory(metricsHistory) // Replayed by alert impact previews
+	}
+
+	// Store for series pushed by Prometheus remote-write, evaluated by series alerts
+	var seriesStore *metrics.SeriesStore
+	if cfg.Ingest.RemoteWrite.Enabled {
+		seriesConfig := metrics.DefaultSeriesStoreConfig()
+		if retention, err := time.ParseDuration(cfg.Ingest.RemoteWrite.Retention); err == nil {
+			seriesConfig.Retention = retention
+		}
+		seriesConfig.MaxSeries = cfg.Ingest.RemoteWrite.MaxSeries
+		seriesStore = metrics.NewSeriesStore(seriesConfig)
+		seriesStore.StartPruner(storeCtx, time.Minute)
+		alertEvaluator.SetSeriesStore(seriesStore)
+	}
+
+	// API usage per namespace and token, also evaluated by api alerts
+	var apiUsage *usage.Tracker
+	if cfg.APIMetrics.Enabled {
+		usageConfig := usage.DefaultConfig()
+		if window, err := time.ParseDuration(cfg.APIMetrics.Window); err == nil {
+			usageConfig.Window = window
+		}
+		usageConfig.MaxKeys = cfg.APIMetrics.MaxKeys
+		apiUsage = usage.NewTracker(usageConfig)
+		alertEvaluator.SetAPIUsage(apiUsage)
+	}
+
+	// Syslog listener for network devices and legacy applications
+	var syslogListener *logwatch.Syslog
+	if cfg.Syslog.Enabled {
+		syslogConfig := logwatch.DefaultSyslogConfig()
+		syslogConfig.UDPAddress = cfg.Syslog.UDPAddress
+		syslogConfig.TCPAddress = cfg.Syslog.TCPAddress
+		syslogConfig.BufferSize = cfg.Syslog.BufferSize
+		if cfg.Syslog.MaxMessageSize > 0 {
+			syslogConfig.MaxMessageSize = cfg.Syslog.MaxMessageSize
+		}
+		syslogListener = logwatch.NewSyslog(syslogConfig)
+		if err := syslogListener.Start(storeCtx); err != nil {
+			slog.Error("Failed to start syslog listener", "error", err)
+			os.Exit(1)
+		}
+		stats := syslogListener.Stats()
+		slog.Info("Syslog listener started", "udp", stats.UDPAddress, "tcp", stats.TCPAddress)
+	}
+
+	// Log watches whose pattern matches are counted by log alerts
+	var logWatcher *logwatch.Watcher
+	if cfg.LogWatch.Enabled {
+		watchConfig := logwatch.DefaultConfig()
+		watchConfig.RecentMatches = cfg.LogWatch.RecentMatches
+		watchConfig.Syslog = syslogListener
+		if logWatcher, err = logwatch.NewWatcher(cfg.LogWatch.Watches, watchConfig); err != nil {
+			slog.Error("Failed to initialize log watches", "error", err)
+			os.Exit(1)
+		}
+		logWatcher.Start(storeCtx)
+		alertEvaluator.SetLogWatcher(logWatcher)
+	}
 
 	// Create a context for the evaluator
 	evalCtx, evalCancel := context.WithCancel(context.Background())
@@ -145,48 +581,270 @@ func main() {
 
 	// Initialize notification system
 	hub := server.NewHub()
+	hub.SetAllowedOrigins(cfg.WebSocket.AllowedOrigins)
 	go hub.Run()
 	notifierConfig := services.DefaultConfig()
+	if loc, ok := i18n.Parse(cfg.Alerts.Locale); ok {
+		notifierConfig.Locale = loc
+	}
+	redactor, err := cfg.Redactor()
+	if err != nil {
+		slog.Error("Failed to build redaction rules", "error", err)
+		os.Exit(1)
+	}
+	notifierConfig.Redactor = redactor
+	notifierConfig.CircuitFailureThreshold = cfg.Alerts.CircuitFailureThreshold
+	notifierConfig.DeadLetterSize = cfg.Alerts.DeadLetterSize
+	if d, err := time.ParseDuration(cfg.Alerts.CircuitOpenTimeout); err == nil {
+		notifierConfig.CircuitOpenTimeout = d
+	}
+	if cfg.Alerts.Queues.Email.Size > 0 {
+		notifierConfig.EmailQueueSize = cfg.Alerts.Queues.Email.Size
+	}
+	notifierConfig.EmailQueueOverflow = overflowConfig(cfg.Alerts.Queues.Email, services.OverflowDropNewest)
+	notifierConfig.DispatchWorkers = cfg.Alerts.DispatchWorkers
+	if cfg.Alerts.Queues.Dispatch.Size > 0 {
+		notifierConfig.DispatchQueueSize = cfg.Alerts.Queues.Dispatch.Size
+	}
+	notifierConfig.DispatchQueueOverflow = overflowConfig(cfg.Alerts.Queues.Dispatch, services.OverflowDropNewest)
+	notifierConfig.Location = config.LoadLocationOrLocal(cfg.Alerts.Timezone)
+	notifierConfig.ChannelLocations = make(map[models.NotificationType]*time.Location, len(cfg.Alerts.ChannelTimezones))
+	for channel, tz := range cfg.Alerts.ChannelTimezones {
+		notifierConfig.ChannelLocations[models.NotificationType(channel)] = config.LoadLocation(tz)
+	}
+	notifierConfig.ChannelTemplates = channelTemplates(cfg.Alerts.ChannelTemplates)
+	notifierConfig.TemplateLimits = cfg.TemplateLimits()
 	alertNotifier := services.NewNotifier(notifierConfig)
 
+	// Silences mute notifications for alerts whose labels match
+	silenceStore, err := database.NewSilenceStore(cfg.Alerts.StoragePath)
+	if err != nil {
+		slog.Error("Failed to initialize silence storage", "error", err)
+		os.Exit(1)
+	}
+	alertNotifier.SetSilencer(silenceStore)
+
+	// Groups (folders) organize alerts; alerts reference them by ID
+	groupStore, err := database.NewGroupStore(cfg.Alerts.StoragePath)
+	if err != nil {
+		slog.Error("Failed to initialize alert group storage", "error", err)
+		os.Exit(1)
+	}
+
+	// Dashboards lay out widgets whose payloads the server computes
+	dashboardStore, err := database.NewDashboardStore(cfg.Alerts.StoragePath)
+	if err != nil {
+		slog.Error("Failed to initialize dashboard storage", "error", err)
+		os.Exit(1)
+	}
+
 	// Register notification channels
-	inAppChannel := services.NewInAppChannel(100, hub) // Store up to 100 notifications
+	inAppSize := cfg.Alerts.Queues.InApp.Size
+	if inAppSize <= 0 {
+		inAppSize = 100 // Store up to 100 notifications
+	}
+	inAppChannel := services.NewInAppChannel(inAppSize, hub)
+	inAppChannel.SetOverflow(overflowConfig(cfg.Alerts.Queues.InApp, services.OverflowDropOldest))
 	alertNotifier.RegisterChannel(inAppChannel)
 
-	// Register email notification if configured
-	if os.Getenv("SMTP_HOST") != "" {
+	// Register email notification if configured (SMTP, or a sendmail-compatible relay command)
+	if os.Getenv("SMTP_HOST") != "" || os.Getenv("SENDMAIL_PATH") != "" {
 		emailConfig := &services.EmailConfig{
-			Host:     os.Getenv("SMTP_HOST"),
-			Port:     getEnvAsInt("SMTP_PORT", 587), // Convert string to int with default value
-			Username: os.Getenv("SMTP_USERNAME"),
-			Password: os.Getenv("SMTP_PASSWORD"),
-			From:     os.Getenv("SMTP_FROM"),
+			Host:         os.Getenv("SMTP_HOST"),
+			Port:         getEnvAsInt("SMTP_PORT", 587), // Convert string to int with default value
+			Username:     os.Getenv("SMTP_USERNAME"),
+			Password:     os.Getenv("SMTP_PASSWORD"),
+			From:         os.Getenv("SMTP_FROM"),
+			SendmailPath: os.Getenv("SENDMAIL_PATH"),
+			SendmailArgs: strings.Fields(os.Getenv("SENDMAIL_ARGS")),
 		}
 		emailChannel := services.NewEmailChannel(emailConfig, notifierConfig)
 		alertNotifier.RegisterChannel(emailChannel)
 		slog.Info("Email notification channel registered successfully")
 	}
 
-	// Connect evaluator events to notifier
+	// Webhooks POST to the URL set on each alert; deliveries queue on disk while the target is down
+	webhookConfig := services.WebhookConfig{
+		QueueDir:  cfg.WebhookQueuePath(),
+		QueueSize: cfg.Alerts.Webhook.QueueSize,
+		Transport: outboundTransport(cfg, "webhook"),
+		Limits:    cfg.TemplateLimits(),
+	}
+	// Checked by config validation; unset values use the channel defaults
+	webhookConfig.Retention, _ = time.ParseDuration(cfg.Alerts.Webhook.Retention)
+	webhookConfig.RetryInterval, _ = time.ParseDuration(cfg.Alerts.Webhook.RetryInterval)
+	webhookConfig.Timeout, _ = time.ParseDuration(cfg.Alerts.Webhook.Timeout)
+	webhookChannel, err := services.NewWebhookChannel(webhookConfig)
+	if err != nil {
+		slog.Error("Failed to initialize webhook notification channel", "error", err)
+		os.Exit(1)
+	}
+	alertNotifier.RegisterChannel(webhookChannel)
+
+	// Jira issues are opened for critical alerts with a jira notification once a URL is configured
+	if jira := cfg.Alerts.Jira; jira.URL != "" {
+		token, _ := jira.Token() // Checked by config validation
+		jiraConfig := services.JiraConfig{
+			URL:               jira.URL,
+			User:              jira.User,
+			APIToken:          token,
+			Project:           jira.Project,
+			IssueType:         jira.IssueType,
+			ResolveTransition: jira.ResolveTransition,
+			StatePath:         cfg.JiraIssuesPath(),
+			Transport:         outboundTransport(cfg, "jira"),
+			Limits:            cfg.TemplateLimits(),
+		}
+		jiraConfig.Timeout, _ = time.ParseDuration(jira.Timeout)
+		jiraChannel, err := services.NewJiraChannel(jiraConfig)
+		if err != nil {
+			slog.Error("Failed to initialize jira notification channel", "error", err)
+			os.Exit(1)
+		}
+		alertNotifier.RegisterChannel(jiraChannel)
+		slog.Info("Jira notification channel registered successfully", "url", jira.URL)
+	}
+
+	// Warn while outbound client certificates near their expiry
+	if len(cfg.ClientCertificates()) > 0 {
+		go watchClientCertificates(evalCtx, func() []tlsclient.CertStatus { return clientCertificateStatus(cfg) })
+	}
+
+	// Alerts with a remediation run its task once they keep firing past its delay
+	remediator, err := services.NewRemediator(services.RemediatorConfig{
+		Enabled:   cfg.Alerts.Remediation.Enabled,
+		AuditPath: cfg.RemediationAuditPath(),
+	})
+	if err != nil {
+		slog.Error("Failed to initialize alert remediation", "error", err)
+		os.Exit(1)
+	}
+	remediator.SetSilencer(silenceStore)
+
+	// Alert state changes for WebSocket clients subscribed to them
+	var alertStream *server.AlertStream
+	if cfg.WebSocket.AlertStream.Enabled {
+		alertStream = server.NewAlertStream(hub, alertEvaluator.GetAllAlertStatus)
+		alertStream.Start()
+	}
+
+	// Connect evaluator events to notifier, remediator and the alert stream
 	go func() {
 		for event := range alertEvaluator.Events() {
 			alertNotifier.ProcessEvent(event)
+			remediator.ProcessEvent(event)
+			if alertStream != nil {
+				alertStream.Publish(event)
+			}
 		}
 	}()
 	slog.Info("Alert notification system initialized successfully")
 
+	// Alerts pushed through the Alertmanager API resolve when their clients stop re-sending them
+	externalAlerts := services.NewExternalAlerts(alertNotifier, nil)
+	externalAlerts.Start(storeCtx, time.Minute)
+
+	// Inventory of agents reporting to this server
+	var hostRegistry *services.HostRegistry
+	var agentEnroller *services.AgentEnroller
+	if cfg.Hosts.Enabled && !featureFlags.Enabled(features.AgentMode) {
+		slog.Warn("Host inventory disabled by feature flag", "feature", features.AgentMode)
+	}
+	if cfg.Hosts.Enabled && featureFlags.Enabled(features.AgentMode) {
+		hostConfig := services.DefaultHostRegistryConfig()
+		if staleAfter, err := time.ParseDuration(cfg.Hosts.StaleAfter); err == nil {
+			hostConfig.StaleAfter = staleAfter
+		}
+		hostRegistry = services.NewHostRegistry(hostConfig, externalAlerts)
+		// Sweeps often enough to notice a stale host soon and keep its alert firing
+		sweepInterval := hostConfig.StaleAfter / 4
+		if sweepInterval > 30*time.Second {
+			sweepInterval = 30 * time.Second
+		}
+		hostRegistry.Start(storeCtx, sweepInterval)
+
+		if cfg.Hosts.EnrollmentToken != "" {
+			agentStore, err := database.NewAgentStore(cfg.Alerts.StoragePath)
+			if err != nil {
+				slog.Error("Failed to initialize agent storage", "error", err)
+				os.Exit(1)
+			}
+			agentEnroller = services.NewAgentEnroller(agentStore, cfg.Hosts.EnrollmentToken)
+		} else {
+			slog.Warn("Host inventory accepts heartbeats without agent credentials; set hosts.enrollment_token to require enrollment")
+		}
+	}
+
 	// Create API handlers
 	alertsHandler := handlers.NewAlertsHandler(alertStore, alertEvaluator, alertNotifier)
+	alertsHandler.SetGroupStore(groupStore)
+	alertsHandler.SetExternalAlerts(externalAlerts)
+	alertsHandler.SetRemediator(remediator)
 	metricsHandler := handlers.NewMetricsHandler(metricsCollector)
+	metricsHandler.SetAlertStatusProvider(alertEvaluator)
+	metricsHandler.SetAPIUsage(apiUsage)
+	metricsHandler.RegisterSelfMetrics("alert_store", func() interface{} {
+		return alertStore.CacheStats()
+	})
+	metricsHandler.RegisterSelfMetrics("queues", func() interface{} {
+		return append([]services.QueueStats{alertEvaluator.EventQueueStats()}, alertNotifier.QueueStats()...)
+	})
+	metricsHandler.RegisterSelfMetrics("process_collection", func() interface{} {
+		return metricsCollector.ProcessCollectionStats()
+	})
+	metricsHandler.RegisterSelfMetrics("watchdog", func() interface{} {
+		return metricsCollector.WatchdogStats()
+	})
+	metricsHandler.RegisterSelfMetrics("websocket", func() interface{} {
+		return hub.Stats()
+	})
+	metricsHandler.RegisterSelfMetrics("client_certificates", func() interface{} {
+		return clientCertificateStatus(cfg)
+	})
+	if len(metricsExporters) > 0 {
+		metricsHandler.RegisterSelfMetrics("exporters", func() interface{} {
+			stats := make([]export.Stats, 0, len(metricsExporters))
+			for _, exporter := range metricsExporters {
+				stats = append(stats, exporter.Stats())
+			}
+			return stats
+		})
+	}
+	if seriesStore != nil {
+		metricsHandler.RegisterSelfMetrics("ingest", func() interface{} {
+			return seriesStore.Stats()
+		})
+	}
 
 	// Initialize task repository and scheduler
-	taskRepo, err := database.NewFileTaskRepository(cfg.Tasks.StoragePath)
-	if err != nil {
-		slog.Error("Failed to initialize task repository", "error", err)
-		os.Exit(1)
+	taskRepo := stores.Tasks
+	if alg, err := compress.Parse(cfg.Tasks.Compression); err == nil {
+		taskRepo.SetCompression(alg)
 	}
 	slog.Info("Task repository initialized successfully")
-	taskScheduler := services.NewTaskScheduler(taskRepo, nil)
+	taskLocation := config.LoadLocationOrLocal(cfg.Tasks.Timezone)
+	schedulerConfig := services.DefaultTaskSchedulerConfig()
+	schedulerConfig.Location = taskLocation
+	schedulerConfig.Redactor = redactor
+	schedulerConfig.Throttle = services.ThrottleConfig{
+		CPUPercent:    cfg.Tasks.Throttle.CPUPercent,
+		IOWaitPercent: cfg.Tasks.Throttle.IOWaitPercent,
+		MaxDefer:      services.DefaultThrottleMaxDefer,
+	}
+	if maxDefer, err := time.ParseDuration(cfg.Tasks.Throttle.MaxDefer); err == nil {
+		schedulerConfig.Throttle.MaxDefer = maxDefer
+	}
+	schedulerConfig.DryRun = cfg.Tasks.DryRun
+	if cfg.Tasks.DryRun {
+		slog.Warn("Tasks run as dry runs: runners report the files they would delete or modify without touching them")
+	}
+	taskScheduler := services.NewTaskScheduler(taskRepo, schedulerConfig)
+	taskScheduler.SetMetricsCollector(metricsCollector)
+	if hostRegistry != nil {
+		// Tasks targeting agents are queued for them to claim
+		taskScheduler.SetHostRegistry(hostRegistry)
+	}
+	remediator.SetRunner(taskScheduler)
 
 	// Register all task runners
 	runners := []services.TaskRunner{}
@@ -202,6 +860,10 @@ func main() {
 			slog.Error("Failed to create task runner", "type", t, "error", err)
 			continue
 		}
+		if healthCheck, ok := runner.(*services.HealthCheckRunner); ok {
+			healthCheck.SetTransport(outboundTransport(cfg, "health_check"))
+			healthCheck.SetResults(endpointResults)
+		}
 		taskScheduler.RegisterRunner(runner)
 		runners = append(runners, runner)
 	}
@@ -214,18 +876,306 @@ func main() {
 
 	// Create tasks API handler
 	tasksHandler := handlers.NewTasksHandler(taskRepo, taskScheduler)
+	tasksHandler.SetLocation(taskLocation)
+
+	// Retention policies purging old history, executions and notifications
+	retentionConfig := retention.DefaultConfig()
+	if interval, err := time.ParseDuration(cfg.Retention.Interval); err == nil {
+		retentionConfig.Interval = interval
+	}
+	retentionEngine := retention.NewEngine(retentionConfig)
+	if metricsHistory != nil {
+		// Without a policy the history keeps monitoring.metrics_retention on its own
+		retentionEngine.Register(retention.KindMetricsHistory, retention.Target{
+			Usage: func() (retention.Usage, error) {
+				usage := metricsHistory.Usage()
+				if metricsRollups != nil {
+					rolled := metricsRollups.Usage()
+					usage.Items += rolled.Items
+					if rolled.Oldest != nil && (usage.Oldest == nil || rolled.Oldest.Before(*usage.Oldest)) {
+						usage.Oldest = rolled.Oldest
+					}
+				}
+				return usage, nil
+			},
+			Purge: func(before time.Time) (int, error) {
+				purged := metricsHistory.PurgeBefore(before)
+				if metricsRollups != nil {
+					purged += metricsRollups.PurgeBefore(before)
+				}
+				return purged, nil
+			},
+		})
+	}
+	retentionEngine.Register(retention.KindAlertEvents, retention.Target{
+		Usage: alertStore.HistoryUsage,
+		Purge: alertStore.PurgeHistory,
+	})
+	retentionEngine.Register(retention.KindExecutions, retention.Target{
+		Usage: func() (retention.Usage, error) { return taskRepo.ExecutionUsage(context.Background()) },
+		Purge: func(before time.Time) (int, error) { return taskRepo.PurgeExecutions(context.Background(), before) },
+	})
+	retentionEngine.Register(retention.KindNotifications, retention.Target{
+		Usage: func() (retention.Usage, error) { return alertNotifier.NotificationUsage(), nil },
+		Purge: func(before time.Time) (int, error) { return alertNotifier.PurgeNotifications(before), nil },
+	})
+	retentionEngine.Register(retention.KindAuditLogs, retention.Target{
+		Usage: remediator.AuditUsage,
+		Purge: remediator.PurgeAudit,
+	})
+	for name, value := range cfg.Retention.Policies {
+		kind, _ := retention.ParseKind(name)   // Checked by config validation
+		maxAge, _ := time.ParseDuration(value) // Checked by config validation
+		retentionEngine.SetPolicy(kind, maxAge)
+	}
+	if cfg.Retention.Enabled {
+		retentionEngine.Start(storeCtx)
+		slog.Info("Retention policies enabled", "interval", retentionConfig.Interval, "policies", cfg.Retention.Policies)
+	}
+
+	// Storage budget keeping Argus from filling the disk it monitors
+	var storageBudget *retention.Budget
+	if cfg.Storage.BudgetBytes > 0 {
+		budgetConfig := retention.DefaultBudgetConfig()
+		budgetConfig.MaxBytes = cfg.Storage.BudgetBytes
+		budgetConfig.WarnPercent = cfg.Storage.BudgetWarnPercent
+		if interval, err := time.ParseDuration(cfg.Storage.BudgetCheckInterval); err == nil {
+			budgetConfig.Interval = interval
+		}
+		budgetConfig.Dirs = []string{cfg.Storage.BasePath, cfg.Alerts.StoragePath, cfg.Tasks.StoragePath}
+		storageBudget = retention.NewBudget(budgetConfig, retentionEngine)
+		storageBudget.SetWarnFunc(func(status retention.BudgetStatus) {
+			alert := services.ExternalAlert{
+				Labels: map[string]string{
+					models.LabelAlertName: "ArgusStorageBudget",
+					models.LabelSeverity:  string(models.SeverityWarning),
+				},
+				Annotations: map[string]string{
+					"summary": fmt.Sprintf("Argus storage uses %.0f%% of its %d byte budget", status.Percent, status.MaxBytes),
+				},
+				Source: models.SourceLocal,
+			}
+			if !status.Warning {
+				alert.EndsAt = time.Now() // Resolved
+			}
+			externalAlerts.Receive([]services.ExternalAlert{alert})
+		})
+		storageBudget.Start(storeCtx)
+		metricsHandler.RegisterSelfMetrics("storage_budget", func() interface{} {
+			return storageBudget.Status()
+		})
+		slog.Info("Storage budget enabled", "max_bytes", budgetConfig.MaxBytes, "warn_percent", budgetConfig.WarnPercent)
+	}
 
 	// --- Use the new server package for all server setup ---
-	router := server.NewServer(cfg, alertsHandler, tasksHandler, metricsHandler)
-	// Add WebSocket route
+	var middleware []gin.HandlerFunc
+	if apiUsage != nil {
+		middleware = append(middleware, apiUsage.Middleware())
+	}
+	// Login providers; requests are authenticated before any is served
+	authenticator := newAuthenticator(cfg)
+	if authenticator.Enabled() {
+		middleware = append(middleware, server.AuthMiddleware(authenticator, agentEnroller != nil), server.AccessControlMiddleware())
+		slog.Info("Authentication enabled", "oidc", authenticator.OIDC != nil, "ldap", authenticator.LDAP != nil,
+			"api_keys", len(cfg.Auth.APIKeys), "default_role", cfg.Auth.Roles.Default)
+	}
+	// Read-only mode rejects mutating requests; evaluation and scheduling go on
+	readOnly := server.NewReadOnlyMode(cfg.Server.ReadOnly)
+	middleware = append(middleware, readOnly.Middleware())
+	if cfg.Server.ReadOnly {
+		slog.Warn("Read-only mode enabled", "toggle", server.ReadOnlyPath)
+	}
+	if cfg.ResponseCache.Enabled {
+		cacheConfig := server.DefaultResponseCacheConfig()
+		if ttl, err := time.ParseDuration(cfg.ResponseCache.TTL); err == nil {
+			cacheConfig.TTL = ttl
+		}
+		cacheConfig.Paths = cfg.ResponseCache.Paths
+		cacheConfig.MaxEntries = cfg.ResponseCache.MaxEntries
+		responseCache := server.NewResponseCache(cacheConfig)
+		middleware = append(middleware, responseCache.Middleware())
+		metricsHandler.RegisterSelfMetrics("response_cache", func() interface{} {
+			return responseCache.Stats()
+		})
+	}
+	router := server.NewServer(cfg, alertsHandler, tasksHandler, metricsHandler, middleware...)
+	// Add WebSocket route; commands sent over it are served by the router
+	hub.SetCommandHandler(router)
 	router.GET("/ws", func(c *gin.Context) {
 		server.ServeWs(hub, c.Writer, c.Request)
 	})
+	if cfg.WebSocket.ProcessStream.Enabled {
+		streamConfig := server.DefaultProcessStreamConfig()
+		if interval, err := time.ParseDuration(cfg.WebSocket.ProcessStream.Interval); err == nil {
+			streamConfig.Interval = interval
+		}
+		streamConfig.KeyframeEvery = cfg.WebSocket.ProcessStream.KeyframeEvery
+		server.NewProcessStream(hub, metricsCollector.GetProcessMetrics, streamConfig).Start(metricsCtx)
+	}
+
+	// Calendar feed of scheduled task runs and maintenance windows
+	calendarHandler := handlers.NewCalendarHandler(taskRepo, silenceStore)
+	calendarHandler.SetLocation(taskLocation)
+	calendarHandler.RegisterRoutes(router.Group("/api"))
+
+	// Alertmanager-compatible API for amtool, Grafana and Prometheus
+	handlers.NewAlertmanagerHandler(alertStore, alertEvaluator, externalAlerts, silenceStore).RegisterRoutes(router.Group("/api"))
+	handlers.NewGroupsHandler(groupStore, alertStore, silenceStore).RegisterRoutes(router.Group("/api"))
+	handlers.NewReadOnlyHandler(readOnly).RegisterRoutes(router.Group("/api"))
+	handlers.NewAuthHandler(authenticator).RegisterRoutes(router.Group("/api"))
+	// Collector settings tuned at runtime are saved to the loaded config file
+	collectorHandler := handlers.NewCollectorHandler(metricsCollector)
+	collectorHandler.SetPersist(func(t metrics.Tuning) error {
+		return config.UpdateFile(cfgPath, map[string]any{
+			"monitoring.update_interval":     t.UpdateInterval.String(),
+			"monitoring.cache_ttl":           t.CacheTTL.String(),
+			"monitoring.process_limit":       t.ProcessLimit,
+			"monitoring.disabled_collectors": t.Disabled,
+		})
+	})
+	collectorHandler.RegisterRoutes(router.Group("/api"))
+	// Feature flags switched at runtime are saved to the loaded config file
+	featuresHandler := handlers.NewFeaturesHandler(featureFlags)
+	featuresHandler.SetPersist(func(name string, enabled bool) error {
+		return config.UpdateFile(cfgPath, map[string]any{"features." + name: enabled})
+	})
+	featuresHandler.RegisterRoutes(router.Group("/api"))
+
+	// Incidents correlated from alert, acknowledgment, silence and task activity
+	incidentWindow, _ := time.ParseDuration(cfg.Incidents.Window) // Checked by config validation
+	incidentLookback, _ := time.ParseDuration(cfg.Incidents.Lookback)
+	incidentsHandler := handlers.NewIncidentsHandler(alertStore, alertNotifier, silenceStore, taskRepo, incidentWindow, incidentLookback)
+	incidentsHandler.SetMetricHistory(alertEvaluator)
+	incidentsHandler.RegisterRoutes(router.Group("/api"))
+
+	// System services panel of the critical daemons and their alerts
+	handlers.NewServicesHandler(metricsCollector, alertStore, alertEvaluator).RegisterRoutes(router.Group("/api"))
+
+	// JSON Schemas of alert and task definitions for external validation
+	schemasHandler, err := handlers.NewSchemasHandler()
+	if err != nil {
+		slog.Error("Failed to generate JSON schemas", "error", err)
+		os.Exit(1)
+	}
+	schemasHandler.RegisterRoutes(router.Group("/api"))
+	retentionHandler := handlers.NewRetentionHandler(retentionEngine)
+	if storageBudget != nil {
+		retentionHandler.SetBudget(storageBudget)
+	}
+	retentionHandler.RegisterRoutes(router.Group("/api"))
+
+	// Running build and subsystems, and the latest release when the update
+	// check is enabled
+	versionHandler := handlers.NewVersionHandler(buildInfo())
+	versionHandler.SetSubsystems(enabledSubsystems(cfg, featureFlags))
+	versionHandler.SetFeatures(featureFlags.Active())
+	if cfg.Update.Enabled {
+		interval, _ := time.ParseDuration(cfg.Update.Interval)
+		updateChecker := update.NewChecker(update.Config{
+			URL:       cfg.Update.URL,
+			Current:   buildVersion(),
+			Interval:  interval,
+			Transport: outboundTransport(cfg, "update"),
+		})
+		if cfg.Update.Notify {
+			updateChecker.OnUpdate(func(status update.Status) {
+				inAppChannel.SendSystem(models.SeverityInfo,
+					fmt.Sprintf("Argus %s is available", status.Latest),
+					fmt.Sprintf("This server runs %s. Release notes: %s. Single-binary deployments can install it with `argus update`.", status.Current, status.ReleaseURL))
+			})
+		}
+		versionHandler.SetChecker(updateChecker)
+		go updateChecker.Run(evalCtx)
+		slog.Info("Update check enabled", "url", cfg.Update.URL, "interval", interval)
+	}
+	versionHandler.RegisterRoutes(router.Group("/api"))
+
+	// Host inventory of agents reporting to this server
+	if hostRegistry != nil {
+		hostsHandler := handlers.NewHostsHandler(hostRegistry)
+		if agentEnroller != nil {
+			hostsHandler.SetEnroller(agentEnroller)
+		}
+		hostsHandler.SetScheduler(taskScheduler)
+		hostsHandler.RegisterRoutes(router.Group("/api"))
+		slog.Info("Host inventory enabled", "endpoint", "/api/hosts", "stale_after", hostRegistry.StaleAfter(), "enrollment", agentEnroller != nil)
+	}
+
+	// Log watches
+	if logWatcher != nil {
+		handlers.NewLogWatchHandler(logWatcher).RegisterRoutes(router.Group("/api"))
+		slog.Info("Log watches enabled", "endpoint", "/api/logwatch", "watches", len(cfg.LogWatch.Watches))
+	}
+
+	if syslogListener != nil {
+		handlers.NewSyslogHandler(syslogListener).RegisterRoutes(router.Group("/api"))
+	}
+
+	// Public status page of the published alert groups
+	if cfg.StatusPage.Enabled {
+		statusPage := statuspage.Options{Title: cfg.StatusPage.Title, Components: cfg.StatusPage.Components, HistoryDays: cfg.StatusPage.HistoryDays}
+		handlers.NewStatusPageHandler(statusPage, groupStore, alertStore, alertEvaluator).RegisterRoutes(router.Group(""))
+		slog.Info("Status page enabled", "endpoint", "/status", "components", len(cfg.StatusPage.Components))
+	}
+
+	// Prometheus remote-write receiver
+	if seriesStore != nil {
+		selector, _ := ingest.NewSelector(cfg.Ingest.RemoteWrite.Metrics) // Patterns are checked by config validation
+		handlers.NewIngestHandler(seriesStore, selector).RegisterRoutes(router.Group("/api"))
+		slog.Info("Remote-write ingestion enabled", "endpoint", "/api/ingest/remote-write", "metrics", cfg.Ingest.RemoteWrite.Metrics)
+	}
+
+	// Grafana JSON datasource
+	if cfg.Grafana.Enabled {
+		grafanaHandler := handlers.NewGrafanaHandler(metricsHistory, alertStore)
+		if seriesStore != nil {
+			grafanaHandler.SetSeriesStore(seriesStore)
+		}
+		if metricsRollups != nil {
+			grafanaHandler.SetRollups(metricsRollups)
+			metricsHandler.RegisterSelfMetrics("metrics_rollups", func() interface{} {
+				return metricsRollups.Stats()
+			})
+		}
+		grafanaHandler.RegisterRoutes(router.Group("/api"))
+		slog.Info("Grafana datasource enabled", "endpoint", "/api/grafana", "retention", cfg.Monitoring.MetricsRetention)
+	}
+
+	// Aggregating queries over the metric history and the ingested series
+	var querySources []query.Source
+	switch {
+	case metricsRollups != nil:
+		querySources = append(querySources, query.FromRollups(metricsRollups))
+	case metricsHistory != nil:
+		querySources = append(querySources, query.FromStore(metricsHistory))
+	}
+	if seriesStore != nil {
+		querySources = append(querySources, query.FromStore(seriesStore))
+	}
+	if len(querySources) > 0 {
+		handlers.NewQueryHandler(querySources...).RegisterRoutes(router.Group("/api"))
+		slog.Info("Metric history queries enabled", "endpoint", "/api/metrics/query")
+	}
+
+	// Dashboards; query widgets need the metric history, the others do not
+	dashboardRenderer := &widgets.Renderer{
+		Sources:   querySources,
+		Processes: metricsCollector.GetProcessMetrics,
+		Alerts:    alertsHandler.WidgetAlerts,
+	}
+	handlers.NewDashboardsHandler(dashboardStore, dashboardRenderer).RegisterRoutes(router.Group("/api"))
+
+	// Fault injection admin API for chaos testing (never enable in production)
+	if faults.BuildEnabled || cfg.Debug.FaultInjection {
+		faults.Default.Enable()
+		handlers.NewFaultsHandler(faults.Default).RegisterRoutes(router.Group("/api"))
+		slog.Warn("Fault injection enabled", "endpoint", "/api/admin/faults")
+	}
 
 	slog.Info("API routes and static file serving configured via server package")
 
-	// Create optimized HTTP server with production settings
-	srv := server.CreateOptimizedHTTPServer(router, fmt.Sprintf(":%d", cfg.Server.Port))
+	// Create HTTP server with the configured timeouts and limits
+	srv := server.CreateOptimizedHTTPServer(router, cfg)
 
 	// Start server in a goroutine
 	go func() {
@@ -243,9 +1193,25 @@ func main() {
 
 	slog.Info("Shutting down server...")
 
+	// Tell streaming clients we are going away and let them disconnect before
+	// the HTTP server stops; hijacked WebSocket connections are not tracked by Shutdown.
+	drainTimeout, err := time.ParseDuration(cfg.WebSocket.DrainTimeout)
+	if err != nil || drainTimeout <= 0 {
+		drainTimeout = 10 * time.Second
+	}
+	drainCtx, drainCancel := context.WithTimeout(context.Background(), drainTimeout)
+	if err := hub.Shutdown(drainCtx); err != nil {
+		slog.Warn("WebSocket clients did not disconnect before drain timeout", "timeout", drainTimeout, "error", err)
+	}
+	drainCancel()
+
 	// Cancel the evaluator context to stop it
 	evalCancel()
 
+	// Deliver the notifications waiting for dispatch, then stop the channels;
+	// queued webhook deliveries resume on the next start
+	alertNotifier.Stop()
+
 	// Cancel the metrics collector context to stop it
 	metricsCancel()
 	metricsCollector.Stop()
@@ -259,8 +1225,16 @@ func main() {
 		os.Exit(1)
 	}
 
-	// On shutdown, stop the scheduler
+	// Abandon the remediations waiting for their delay and let running ones
+	// finish, then stop the scheduler
+	remediator.Stop()
 	taskScheduler.Stop()
 
+	// Persist any bulk alert writes still pending
+	storeCancel()
+	if err := alertStore.Flush(); err != nil {
+		slog.Error("Failed to flush alert store", "error", err)
+	}
+
 	slog.Info("Server shutdown completed successfully")
 }
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"time"
+
+	"argus/internal/config"
+	"argus/internal/update"
+)
+
+// runUpdate implements `argus update`: it installs the latest release over a
+// single-binary deployment, leaving the restart to the service manager.
+// Returns the process exit code: 0 when up to date or updated, 1 on failures,
+// 2 on usage errors.
+func runUpdate(args []string) int {
+	fs := flag.NewFlagSet("update", flag.ContinueOnError)
+	cfgPath := fs.String("config", resolveConfigPath(), "path to the configuration file")
+	checkOnly := fs.Bool("check", false, "only report whether a newer release is available")
+	timeout := fs.Duration("timeout", 10*time.Minute, "timeout for the check and the download")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	cfg, err := config.LoadConfig(*cfgPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "argus update: failed to load configuration %s: %v\n", *cfgPath, err)
+		return 1
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+
+	checker := update.NewChecker(update.Config{
+		URL:       cfg.Update.URL,
+		Current:   buildVersion(),
+		Transport: outboundTransport(cfg, "update"),
+	})
+	release, err := checker.Latest(ctx)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "argus update: failed to check %s: %v\n", cfg.Update.URL, err)
+		return 1
+	}
+	if !update.Newer(release.Version, buildVersion()) {
+		fmt.Printf("Running %s; the latest release is %s, nothing to update\n", buildVersion(), release.Version)
+		return 0
+	}
+	fmt.Printf("Running %s; %s is available: %s\n", buildVersion(), release.Version, release.URL)
+	if *checkOnly {
+		return 0
+	}
+
+	exe, err := os.Executable()
+	if err == nil {
+		exe, err = filepath.EvalSymlinks(exe)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "argus update: failed to locate the running binary: %v\n", err)
+		return 1
+	}
+	backup, err := checker.Apply(ctx, release, update.AssetName(runtime.GOOS, runtime.GOARCH), exe)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "argus update: %v\n", err)
+		return 1
+	}
+	fmt.Printf("Installed %s at %s; the previous binary is kept at %s\n", release.Version, exe, backup)
+	fmt.Println("Restart the Argus service (e.g. systemctl restart argus) to run it")
+	return 0
+}
@@ -0,0 +1,493 @@
+// File: internal/agent/agent.go
+// Brief: Agent reporting a host to a central Argus server
+// Detailed: Enrolls with the server using the shared enrollment token, keeps the per-agent credential it receives in a private state file, sends periodic heartbeats carrying it and pins the server's certificate, either to a configured public key hash or to the one seen on first enrollment. A client certificate can be presented for mTLS. With a runner configured, claims the task runs the server queued for the host after each heartbeat, runs them locally and reports the executions back.
+// Author: drama.lin@aver.com
+// Date: 2026-10-14
+
+package agent
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"argus/internal/clock"
+	"argus/internal/models"
+	"argus/internal/tlsclient"
+)
+
+// Defaults for the agent configuration
+const (
+	DefaultInterval  = 30 * time.Second
+	DefaultTimeout   = 10 * time.Second
+	DefaultStatePath = ".argus/agent.json"
+)
+
+// Agent errors
+var (
+	ErrRevoked           = errors.New("agent has been revoked by the server")
+	ErrInvalidToken      = errors.New("server rejected the enrollment token")
+	ErrPinMismatch       = errors.New("server certificate does not match the pinned public key")
+	ErrEnrollUnsupported = errors.New("server does not accept agent enrollment")
+)
+
+// RunFunc runs a task on the local host
+type RunFunc func(ctx context.Context, task *models.TaskConfig) (*models.TaskExecution, error)
+
+// HealthFunc reports the health of the local host and an optional message
+type HealthFunc func(ctx context.Context) (models.HostHealth, string)
+
+// Config holds configuration for the agent
+type Config struct {
+	ServerURL       string            // Base URL of the central server, e.g. https://argus.example.com:8080
+	EnrollmentToken string            // Shared token for the first enrollment (empty sends heartbeats without a credential)
+	StatePath       string            // File keeping the credential and server pin
+	Pin             string            // Hex SHA-256 of the server certificate's public key (empty trusts on first use)
+	RootCAs         *x509.CertPool    // CAs for verifying the server before a pin is known (nil uses the system pool)
+	ClientCert      tlsclient.Config  // Client certificate presented when the server, or a proxy in front of it, asks for one (mTLS)
+	ID              string            // Host ID (defaults to the hostname)
+	Hostname        string            // Reported hostname (defaults to os.Hostname)
+	Labels          map[string]string // Host labels
+	AgentVersion    string            // Reported agent version
+	Interval        time.Duration     // Time between heartbeats
+	Timeout         time.Duration     // Timeout of each request
+	Health          HealthFunc        // Host health (nil always reports healthy)
+	Runner          RunFunc           // Runs the tasks the server queues for the host (nil takes none)
+	Clock           clock.Clock       // Time source (nil uses the real clock)
+}
+
+// State is what the agent keeps between runs
+type State struct {
+	ID         string `json:"id"`
+	Credential string `json:"credential,omitempty"`
+	ServerPin  string `json:"server_pin,omitempty"`
+}
+
+// Agent reports its host to a central server
+type Agent struct {
+	config Config
+	clock  clock.Clock
+	client *http.Client
+
+	mu       sync.Mutex
+	state    State
+	observed string // Pin of the certificate seen on the last TLS connection
+
+	jobs sync.WaitGroup // Task runs in progress
+}
+
+// New creates an agent, loading the state kept by a previous run
+func New(config Config) (*Agent, error) {
+	serverURL := strings.TrimRight(config.ServerURL, "/")
+	if !strings.HasPrefix(serverURL, "https://") && !strings.HasPrefix(serverURL, "http://") {
+		return nil, fmt.Errorf("invalid server URL %q: must start with https:// or http://", config.ServerURL)
+	}
+	config.ServerURL = serverURL
+	if config.Hostname == "" {
+		hostname, err := os.Hostname()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get hostname: %w", err)
+		}
+		config.Hostname = hostname
+	}
+	if config.ID == "" {
+		config.ID = config.Hostname
+	}
+	if config.StatePath == "" {
+		config.StatePath = DefaultStatePath
+	}
+	if config.Interval <= 0 {
+		config.Interval = DefaultInterval
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = DefaultTimeout
+	}
+	pin, err := normalizePin(config.Pin)
+	if err != nil {
+		return nil, err
+	}
+	config.Pin = pin
+
+	a := &Agent{config: config, clock: clock.OrReal(config.Clock)}
+	if err := a.loadState(); err != nil {
+		return nil, err
+	}
+	// A configured pin replaces the one recorded, e.g. after a key rotation
+	if config.Pin != "" {
+		a.state.ServerPin = config.Pin
+	}
+	if strings.HasPrefix(serverURL, "http://") {
+		slog.Warn("Agent talks to the server over plain HTTP; the credential and reports are not protected", "server", serverURL)
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		// Verification is done in verifyConnection, against the pin once known
+		InsecureSkipVerify: true,
+		VerifyConnection:   a.verifyConnection,
+	}
+	// Only the client certificate applies: the server is checked against RootCAs in verifyConnection
+	if err := config.ClientCert.Apply(transport.TLSClientConfig); err != nil {
+		return nil, err
+	}
+	a.client = &http.Client{Timeout: config.Timeout, Transport: transport}
+	return a, nil
+}
+
+// PublicKeyPin returns the pin of cert: the hex SHA-256 of its public key
+func PublicKeyPin(cert *x509.Certificate) string {
+	sum := sha256.Sum256(cert.RawSubjectPublicKeyInfo)
+	return hex.EncodeToString(sum[:])
+}
+
+// normalizePin accepts pins in hex, optionally prefixed with "sha256:" or
+// separated by colons
+func normalizePin(pin string) (string, error) {
+	pin = strings.ToLower(strings.TrimPrefix(strings.TrimSpace(pin), "sha256:"))
+	pin = strings.ReplaceAll(pin, ":", "")
+	if pin == "" {
+		return "", nil
+	}
+	if b, err := hex.DecodeString(pin); err != nil || len(b) != sha256.Size {
+		return "", fmt.Errorf("invalid server pin: expected the hex SHA-256 of the certificate's public key")
+	}
+	return pin, nil
+}
+
+// verifyConnection checks the server certificate against the pin, or verifies
+// its chain with the CAs while no pin is known yet
+func (a *Agent) verifyConnection(cs tls.ConnectionState) error {
+	if len(cs.PeerCertificates) == 0 {
+		return errors.New("server presented no certificate")
+	}
+	leaf := cs.PeerCertificates[0]
+	observed := PublicKeyPin(leaf)
+
+	a.mu.Lock()
+	pin := a.state.ServerPin
+	a.observed = observed
+	a.mu.Unlock()
+
+	if pin != "" {
+		if observed != pin {
+			return fmt.Errorf("%w: got %s", ErrPinMismatch, observed)
+		}
+		return nil
+	}
+	intermediates := x509.NewCertPool()
+	for _, cert := range cs.PeerCertificates[1:] {
+		intermediates.AddCert(cert)
+	}
+	_, err := leaf.Verify(x509.VerifyOptions{
+		DNSName:       cs.ServerName,
+		Roots:         a.config.RootCAs,
+		Intermediates: intermediates,
+	})
+	return err
+}
+
+// State returns a copy of the agent's current state
+func (a *Agent) State() State {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.state
+}
+
+// loadState reads the state file, if any
+func (a *Agent) loadState() error {
+	a.state = State{ID: a.config.ID}
+	data, err := os.ReadFile(a.config.StatePath)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read agent state: %w", err)
+	}
+	var state State
+	if err := json.Unmarshal(data, &state); err != nil {
+		return fmt.Errorf("failed to parse agent state %s: %w", a.config.StatePath, err)
+	}
+	// A credential is only valid for the ID it was issued to
+	if state.ID != a.config.ID {
+		state.Credential = ""
+		state.ID = a.config.ID
+	}
+	a.state = state
+	return nil
+}
+
+// saveState writes the state file, readable by its owner only
+func (a *Agent) saveState() error {
+	a.mu.Lock()
+	data, err := json.MarshalIndent(a.state, "", "  ")
+	a.mu.Unlock()
+	if err != nil {
+		return fmt.Errorf("failed to marshal agent state: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(a.config.StatePath), 0700); err != nil {
+		return fmt.Errorf("failed to create agent state directory: %w", err)
+	}
+	tmp := a.config.StatePath + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return fmt.Errorf("failed to write agent state: %w", err)
+	}
+	if err := os.Rename(tmp, a.config.StatePath); err != nil {
+		return fmt.Errorf("failed to write agent state: %w", err)
+	}
+	return nil
+}
+
+// post sends body as JSON to path on the server
+func (a *Agent) post(ctx context.Context, path, credential string, body any) (*http.Response, error) {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, a.config.ServerURL+path, bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if credential != "" {
+		req.Header.Set("Authorization", "Bearer "+credential)
+	}
+	resp, err := a.client.Do(req)
+	if err != nil && errors.Is(err, ErrPinMismatch) {
+		return nil, ErrPinMismatch
+	}
+	return resp, err
+}
+
+// responseError describes an unexpected response
+func responseError(resp *http.Response) error {
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+	var payload struct {
+		Error string `json:"error"`
+	}
+	if json.Unmarshal(body, &payload) == nil && payload.Error != "" {
+		return fmt.Errorf("server returned %s: %s", resp.Status, payload.Error)
+	}
+	return fmt.Errorf("server returned %s", resp.Status)
+}
+
+// Enroll exchanges the enrollment token for a credential of the agent's own
+// and, on first use, pins the server's certificate
+func (a *Agent) Enroll(ctx context.Context) error {
+	resp, err := a.post(ctx, "/api/hosts/enroll", "", models.AgentEnrollRequest{
+		Token:    a.config.EnrollmentToken,
+		ID:       a.config.ID,
+		Hostname: a.config.Hostname,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to enroll: %w", err)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusCreated, http.StatusOK:
+	case http.StatusUnauthorized:
+		return ErrInvalidToken
+	case http.StatusForbidden:
+		return ErrRevoked
+	case http.StatusNotFound:
+		return ErrEnrollUnsupported
+	default:
+		return fmt.Errorf("failed to enroll: %w", responseError(resp))
+	}
+	var enrolled models.AgentEnrollResponse
+	if err := json.NewDecoder(resp.Body).Decode(&enrolled); err != nil || enrolled.Credential == "" {
+		return fmt.Errorf("failed to enroll: invalid response from server")
+	}
+
+	a.mu.Lock()
+	a.state.Credential = enrolled.Credential
+	if a.state.ServerPin == "" && resp.TLS != nil {
+		a.state.ServerPin = a.observed
+		slog.Info("Pinned server certificate", "server", a.config.ServerURL, "pin", a.observed)
+	}
+	a.mu.Unlock()
+	slog.Info("Agent enrolled", "server", a.config.ServerURL, "id", a.config.ID)
+	return a.saveState()
+}
+
+// Heartbeat sends one report. A rejected credential is dropped so the next
+// report enrolls again.
+func (a *Agent) Heartbeat(ctx context.Context) error {
+	hb := models.HostHeartbeat{
+		ID:           a.config.ID,
+		Hostname:     a.config.Hostname,
+		Labels:       a.config.Labels,
+		AgentVersion: a.config.AgentVersion,
+		Health:       models.HostHealthy,
+		Tasks:        a.config.Runner != nil,
+	}
+	if a.config.Health != nil {
+		hb.Health, hb.HealthMessage = a.config.Health(ctx)
+	}
+
+	resp, err := a.post(ctx, "/api/hosts/heartbeat", a.State().Credential, hb)
+	if err != nil {
+		return fmt.Errorf("failed to send heartbeat: %w", err)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return nil
+	case http.StatusForbidden:
+		return ErrRevoked
+	case http.StatusUnauthorized:
+		a.mu.Lock()
+		a.state.Credential = ""
+		a.mu.Unlock()
+		if err := a.saveState(); err != nil {
+			return err
+		}
+		return fmt.Errorf("failed to send heartbeat: %w", responseError(resp))
+	default:
+		return fmt.Errorf("failed to send heartbeat: %w", responseError(resp))
+	}
+}
+
+// jobsPath returns the path of the host's task runs on the server
+func (a *Agent) jobsPath() string {
+	return "/api/hosts/" + url.PathEscape(a.config.ID) + "/jobs"
+}
+
+// ClaimJobs fetches the task runs the server queued for the host. A server
+// not running tasks on agents returns none.
+func (a *Agent) ClaimJobs(ctx context.Context) ([]models.AgentJob, error) {
+	resp, err := a.post(ctx, a.jobsPath()+"/claim", a.State().Credential, struct{}{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim jobs: %w", err)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		return nil, nil
+	default:
+		return nil, fmt.Errorf("failed to claim jobs: %w", responseError(resp))
+	}
+	var claimed models.AgentJobsResponse
+	if err := json.NewDecoder(resp.Body).Decode(&claimed); err != nil {
+		return nil, fmt.Errorf("failed to claim jobs: invalid response from server")
+	}
+	return claimed.Jobs, nil
+}
+
+// runJob runs a claimed task and reports its execution, or its failure, back
+// to the server
+func (a *Agent) runJob(ctx context.Context, job models.AgentJob) {
+	slog.Info("Running task for the server", "task_id", job.Task.ID, "task_name", job.Task.Name, "job", job.ID)
+	runCtx := ctx
+	if timeout, err := time.ParseDuration(job.Timeout); err == nil && timeout > 0 {
+		var cancel context.CancelFunc
+		runCtx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	start := a.clock.Now().UTC()
+	execution, err := a.config.Runner(runCtx, &job.Task)
+	if err != nil || execution == nil {
+		if err == nil {
+			err = errors.New("runner returned no execution")
+		}
+		execution = &models.TaskExecution{
+			TaskID:    job.Task.ID,
+			TaskName:  job.Task.Name,
+			TaskType:  job.Task.Type,
+			StartTime: start,
+			EndTime:   a.clock.Now().UTC(),
+			Status:    models.StatusFailed,
+			Error:     err.Error(),
+		}
+	}
+
+	resp, err := a.post(ctx, a.jobsPath()+"/"+url.PathEscape(job.ID)+"/result", a.State().Credential, execution)
+	if err != nil {
+		slog.Warn("Failed to report task execution", "job", job.ID, "error", err)
+		return
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		slog.Warn("Server did not accept task execution", "job", job.ID, "error", responseError(resp))
+	}
+}
+
+// Report enrolls if the agent has no credential yet, sends a heartbeat and
+// starts the task runs queued for the host
+func (a *Agent) Report(ctx context.Context) error {
+	if a.config.EnrollmentToken != "" && a.State().Credential == "" {
+		if err := a.Enroll(ctx); err != nil {
+			return err
+		}
+	}
+	if err := a.Heartbeat(ctx); err != nil {
+		return err
+	}
+	if a.config.Runner == nil {
+		return nil
+	}
+	jobs, err := a.ClaimJobs(ctx)
+	if err != nil {
+		return err
+	}
+	for _, job := range jobs {
+		a.jobs.Add(1)
+		go func(job models.AgentJob) {
+			defer a.jobs.Done()
+			a.runJob(ctx, job)
+		}(job)
+	}
+	return nil
+}
+
+// fatal reports whether err needs an operator to act before retrying helps
+func fatal(err error) bool {
+	return errors.Is(err, ErrRevoked) || errors.Is(err, ErrInvalidToken) ||
+		errors.Is(err, ErrPinMismatch) || errors.Is(err, ErrEnrollUnsupported)
+}
+
+// Run reports every interval until ctx is cancelled or the server revokes the
+// agent, rejects its token or presents a certificate not matching the pin.
+// Other failures are logged and retried with the next report. Task runs in
+// progress are waited for before returning.
+func (a *Agent) Run(ctx context.Context) error {
+	ticker := a.clock.NewTicker(a.config.Interval)
+	defer ticker.Stop()
+	defer a.jobs.Wait()
+	for {
+		if err := a.Report(ctx); err != nil {
+			if fatal(err) {
+				return err
+			}
+			if ctx.Err() != nil {
+				return nil
+			}
+			slog.Warn("Agent report failed", "server", a.config.ServerURL, "error", err)
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C():
+		}
+	}
+}
@@ -0,0 +1,385 @@
+package agent
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"io"
+	"log"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"argus/internal/models"
+	"argus/internal/tlsclient"
+)
+
+const testToken = "enroll-token-0123456789"
+
+// fakeServer mimics the enrollment and heartbeat endpoints of a central server
+type fakeServer struct {
+	mu         sync.Mutex
+	issued     int
+	credential string
+	revoked    bool
+	heartbeats []models.HostHeartbeat
+	jobs       []models.AgentJob // Handed out with the next claim
+	claims     int
+	results    map[string]models.TaskExecution // By job ID
+}
+
+func (f *fakeServer) handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/hosts/enroll", func(w http.ResponseWriter, r *http.Request) {
+		var req models.AgentEnrollRequest
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		if req.Token != testToken {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if f.revoked {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		f.issued++
+		f.credential = "agt_" + strings.Repeat("x", f.issued)
+		w.WriteHeader(http.StatusCreated)
+		_ = json.NewEncoder(w).Encode(models.AgentEnrollResponse{ID: req.ID, Credential: f.credential})
+	})
+	mux.HandleFunc("/api/hosts/heartbeat", func(w http.ResponseWriter, r *http.Request) {
+		var hb models.HostHeartbeat
+		_ = json.NewDecoder(r.Body).Decode(&hb)
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		switch {
+		case r.Header.Get("Authorization") != "Bearer "+f.credential:
+			w.WriteHeader(http.StatusUnauthorized)
+		case f.revoked:
+			w.WriteHeader(http.StatusForbidden)
+		default:
+			f.heartbeats = append(f.heartbeats, hb)
+			w.WriteHeader(http.StatusOK)
+		}
+	})
+	mux.HandleFunc("/api/hosts/web-1/jobs/claim", func(w http.ResponseWriter, r *http.Request) {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		if r.Header.Get("Authorization") != "Bearer "+f.credential {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		f.claims++
+		_ = json.NewEncoder(w).Encode(models.AgentJobsResponse{Jobs: f.jobs})
+		f.jobs = nil
+	})
+	mux.HandleFunc("/api/hosts/web-1/jobs/{job}/result", func(w http.ResponseWriter, r *http.Request) {
+		var execution models.TaskExecution
+		_ = json.NewDecoder(r.Body).Decode(&execution)
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		if f.results == nil {
+			f.results = make(map[string]models.TaskExecution)
+		}
+		f.results[r.PathValue("job")] = execution
+		w.WriteHeader(http.StatusOK)
+	})
+	return mux
+}
+
+func newTestAgent(t *testing.T, server *httptest.Server, statePath, pin string) *Agent {
+	t.Helper()
+	config := Config{
+		ServerURL:       server.URL,
+		EnrollmentToken: testToken,
+		StatePath:       statePath,
+		Pin:             pin,
+		Hostname:        "web-1",
+		Labels:          map[string]string{"env": "prod"},
+		AgentVersion:    "1.2.3",
+		Health: func(context.Context) (models.HostHealth, string) {
+			return models.HostDegraded, "disk 91% full"
+		},
+	}
+	if server.TLS != nil {
+		config.RootCAs = server.Client().Transport.(*http.Transport).TLSClientConfig.RootCAs
+	}
+	a, err := New(config)
+	require.NoError(t, err)
+	return a
+}
+
+// selfSigned returns a certificate with a fresh key for 127.0.0.1
+func selfSigned(t *testing.T) tls.Certificate {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "impostor"},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}
+}
+
+func TestAgent_EnrollsPinsAndReports(t *testing.T) {
+	fake := &fakeServer{}
+	server := httptest.NewTLSServer(fake.handler())
+	defer server.Close()
+	statePath := filepath.Join(t.TempDir(), "agent.json")
+
+	a := newTestAgent(t, server, statePath, "")
+	require.NoError(t, a.Report(context.Background()))
+
+	state := a.State()
+	assert.Equal(t, "web-1", state.ID)
+	assert.Equal(t, fake.credential, state.Credential)
+	assert.Equal(t, PublicKeyPin(server.Certificate()), state.ServerPin)
+
+	require.Len(t, fake.heartbeats, 1)
+	hb := fake.heartbeats[0]
+	assert.Equal(t, "web-1", hb.ID)
+	assert.Equal(t, "prod", hb.Labels["env"])
+	assert.Equal(t, "1.2.3", hb.AgentVersion)
+	assert.Equal(t, models.HostDegraded, hb.Health)
+	assert.Equal(t, "disk 91% full", hb.HealthMessage)
+
+	info, err := os.Stat(statePath)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	// A restarted agent reuses its credential instead of enrolling again
+	restarted := newTestAgent(t, server, statePath, "")
+	require.NoError(t, restarted.Report(context.Background()))
+	assert.Equal(t, 1, fake.issued)
+	assert.Len(t, fake.heartbeats, 2)
+}
+
+func TestAgent_RejectsCertificateNotMatchingPin(t *testing.T) {
+	fake := &fakeServer{}
+	pinned := httptest.NewTLSServer(fake.handler())
+	defer pinned.Close()
+	statePath := filepath.Join(t.TempDir(), "agent.json")
+
+	a := newTestAgent(t, pinned, statePath, "")
+	require.NoError(t, a.Report(context.Background()))
+
+	// Another server at the agent's address presents a different key
+	impostor := httptest.NewUnstartedServer(fake.handler())
+	impostor.TLS = &tls.Config{Certificates: []tls.Certificate{selfSigned(t)}}
+	impostor.StartTLS()
+	defer impostor.Close()
+	moved, err := New(Config{
+		ServerURL:       impostor.URL,
+		EnrollmentToken: testToken,
+		StatePath:       statePath,
+		Hostname:        "web-1",
+	})
+	require.NoError(t, err)
+	err = moved.Report(context.Background())
+	assert.ErrorIs(t, err, ErrPinMismatch)
+	assert.True(t, fatal(err))
+}
+
+func TestAgent_ConfiguredPinAcceptsSelfSignedServer(t *testing.T) {
+	fake := &fakeServer{}
+	server := httptest.NewTLSServer(fake.handler())
+	defer server.Close()
+
+	pin := PublicKeyPin(server.Certificate())
+	a, err := New(Config{
+		ServerURL:       server.URL,
+		EnrollmentToken: testToken,
+		StatePath:       filepath.Join(t.TempDir(), "agent.json"),
+		Pin:             "sha256:" + strings.ToUpper(pin),
+		Hostname:        "web-1",
+	})
+	require.NoError(t, err)
+	require.NoError(t, a.Report(context.Background()))
+	assert.Equal(t, pin, a.State().ServerPin)
+
+	// Without a pin the test server's certificate is not trusted
+	untrusted, err := New(Config{
+		ServerURL:       server.URL,
+		EnrollmentToken: testToken,
+		StatePath:       filepath.Join(t.TempDir(), "agent.json"),
+		Hostname:        "web-1",
+	})
+	require.NoError(t, err)
+	assert.Error(t, untrusted.Report(context.Background()))
+}
+
+func TestAgent_PresentsClientCertificate(t *testing.T) {
+	clientCert := selfSigned(t)
+	leaf, err := x509.ParseCertificate(clientCert.Certificate[0])
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(clientCert.PrivateKey.(*ecdsa.PrivateKey))
+	require.NoError(t, err)
+	dir := t.TempDir()
+	certFile, keyFile := filepath.Join(dir, "agent.crt"), filepath.Join(dir, "agent.key")
+	require.NoError(t, os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: leaf.Raw}), 0644))
+	require.NoError(t, os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0600))
+
+	fake := &fakeServer{}
+	server := httptest.NewUnstartedServer(fake.handler())
+	clients := x509.NewCertPool()
+	clients.AddCert(leaf)
+	server.TLS = &tls.Config{ClientAuth: tls.RequireAndVerifyClientCert, ClientCAs: clients}
+	server.Config.ErrorLog = log.New(io.Discard, "", 0)
+	server.StartTLS()
+	defer server.Close()
+
+	anonymous := newTestAgent(t, server, filepath.Join(dir, "anonymous.json"), "")
+	assert.Error(t, anonymous.Report(context.Background()), "the server requires a client certificate")
+
+	a, err := New(Config{
+		ServerURL:       server.URL,
+		EnrollmentToken: testToken,
+		StatePath:       filepath.Join(dir, "agent.json"),
+		RootCAs:         server.Client().Transport.(*http.Transport).TLSClientConfig.RootCAs,
+		ClientCert:      tlsclient.Config{CertFile: certFile, KeyFile: keyFile},
+		Hostname:        "web-1",
+	})
+	require.NoError(t, err)
+	require.NoError(t, a.Report(context.Background()))
+	assert.Len(t, fake.heartbeats, 1)
+
+	_, err = New(Config{ServerURL: server.URL, Hostname: "web-1", ClientCert: tlsclient.Config{CertFile: certFile}})
+	assert.Error(t, err, "a certificate without its key")
+}
+
+func TestAgent_ReenrollsAfterRejectedCredentialAndStopsWhenRevoked(t *testing.T) {
+	fake := &fakeServer{}
+	server := httptest.NewTLSServer(fake.handler())
+	defer server.Close()
+
+	a := newTestAgent(t, server, filepath.Join(t.TempDir(), "agent.json"), "")
+	require.NoError(t, a.Report(context.Background()))
+
+	// The server forgot the agent: the next report fails and drops the credential
+	fake.mu.Lock()
+	fake.credential = "agt_forgotten"
+	fake.mu.Unlock()
+	assert.Error(t, a.Report(context.Background()))
+	assert.Empty(t, a.State().Credential)
+	require.NoError(t, a.Report(context.Background()))
+	assert.Equal(t, 2, fake.issued)
+
+	fake.mu.Lock()
+	fake.revoked = true
+	fake.mu.Unlock()
+	assert.ErrorIs(t, a.Run(context.Background()), ErrRevoked)
+}
+
+func TestAgent_InvalidTokenAndConfig(t *testing.T) {
+	fake := &fakeServer{}
+	server := httptest.NewServer(fake.handler())
+	defer server.Close()
+
+	a, err := New(Config{
+		ServerURL:       server.URL,
+		EnrollmentToken: "wrong-token-0123456789",
+		StatePath:       filepath.Join(t.TempDir(), "agent.json"),
+		Hostname:        "web-1",
+	})
+	require.NoError(t, err)
+	assert.ErrorIs(t, a.Report(context.Background()), ErrInvalidToken)
+
+	_, err = New(Config{ServerURL: "argus.example.com", Hostname: "web-1"})
+	assert.Error(t, err)
+	_, err = New(Config{ServerURL: server.URL, Hostname: "web-1", Pin: "abc"})
+	assert.Error(t, err)
+}
+
+func TestLoadState_DropsCredentialOfAnotherID(t *testing.T) {
+	statePath := filepath.Join(t.TempDir(), "agent.json")
+	data, _ := json.Marshal(State{ID: "old-name", Credential: "agt_old", ServerPin: strings.Repeat("ab", 32)})
+	require.NoError(t, os.WriteFile(statePath, data, 0600))
+
+	a, err := New(Config{ServerURL: "https://argus.example.com", StatePath: statePath, Hostname: "web-1"})
+	require.NoError(t, err)
+	state := a.State()
+	assert.Equal(t, "web-1", state.ID)
+	assert.Empty(t, state.Credential)
+	assert.Equal(t, strings.Repeat("ab", 32), state.ServerPin)
+}
+
+func TestAgent_RunsClaimedJobsAndReportsResults(t *testing.T) {
+	fake := &fakeServer{jobs: []models.AgentJob{
+		{ID: "job-1", Host: "web-1", Task: models.TaskConfig{ID: "t1", Name: "probe", Type: models.TaskHealthCheck}, Timeout: "1m0s"},
+		{ID: "job-2", Host: "web-1", Task: models.TaskConfig{ID: "t2", Name: "broken", Type: models.TaskSystemCleanup}, Timeout: "1m0s"},
+	}}
+	server := httptest.NewTLSServer(fake.handler())
+	defer server.Close()
+
+	a := newTestAgent(t, server, filepath.Join(t.TempDir(), "agent.json"), "")
+	a.config.Runner = func(ctx context.Context, task *models.TaskConfig) (*models.TaskExecution, error) {
+		if _, ok := ctx.Deadline(); !ok {
+			return nil, errors.New("no timeout")
+		}
+		if task.Type != models.TaskHealthCheck {
+			return nil, errors.New("not implemented")
+		}
+		return &models.TaskExecution{TaskID: task.ID, Status: models.StatusCompleted, Output: "all endpoints up"}, nil
+	}
+	require.NoError(t, a.Report(context.Background()))
+	a.jobs.Wait()
+
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+	assert.True(t, fake.heartbeats[0].Tasks, "the heartbeat announces the agent runs tasks")
+	assert.Equal(t, 1, fake.claims)
+	require.Len(t, fake.results, 2)
+	assert.Equal(t, models.StatusCompleted, fake.results["job-1"].Status)
+	assert.Equal(t, "all endpoints up", fake.results["job-1"].Output)
+	assert.Equal(t, models.StatusFailed, fake.results["job-2"].Status)
+	assert.Equal(t, "not implemented", fake.results["job-2"].Error)
+	assert.Equal(t, "t2", fake.results["job-2"].TaskID)
+}
+
+func TestAgent_WithoutRunnerClaimsNoJobs(t *testing.T) {
+	fake := &fakeServer{}
+	server := httptest.NewTLSServer(fake.handler())
+	defer server.Close()
+
+	a := newTestAgent(t, server, filepath.Join(t.TempDir(), "agent.json"), "")
+	require.NoError(t, a.Report(context.Background()))
+	assert.False(t, fake.heartbeats[0].Tasks)
+	assert.Zero(t, fake.claims)
+
+	// A server not running tasks on agents has no job endpoints
+	other := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/hosts/heartbeat" {
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer other.Close()
+	b, err := New(Config{ServerURL: other.URL, StatePath: filepath.Join(t.TempDir(), "agent.json"), Hostname: "web-1",
+		Runner: func(context.Context, *models.TaskConfig) (*models.TaskExecution, error) { return nil, nil }})
+	require.NoError(t, err)
+	assert.NoError(t, b.Report(context.Background()))
+}
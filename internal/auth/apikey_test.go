@@ -0,0 +1,46 @@
+package auth
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAPIKeys(t *testing.T) {
+	hash, err := ParseAPIKeyHash("9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08") // sha256 of "test"
+	require.NoError(t, err)
+	assert.Equal(t, HashAPIKey("test"), hash)
+	_, err = ParseAPIKeyHash("9f86d081")
+	assert.Error(t, err)
+
+	keys := NewAPIKeys("", []APIKey{
+		{Name: "backup-script", Hash: HashAPIKey("b4ckup-key-0123456789"), Role: RoleOperator},
+		{Name: "grafana", Hash: hash, Role: RoleViewer},
+	})
+	assert.Equal(t, DefaultAPIKeyHeader, keys.Header())
+	identity, err := keys.Authenticate("b4ckup-key-0123456789")
+	require.NoError(t, err)
+	assert.Equal(t, &Identity{User: "backup-script", Role: RoleOperator, Provider: ProviderAPIKey}, identity)
+	identity, err = keys.Authenticate("test")
+	require.NoError(t, err)
+	assert.Equal(t, "grafana", identity.User)
+	_, err = keys.Authenticate("guess")
+	assert.ErrorIs(t, err, ErrUnauthenticated)
+	_, err = keys.Authenticate("")
+	assert.ErrorIs(t, err, ErrUnauthenticated)
+
+	a := &Authenticator{APIKeys: NewAPIKeys("X-Argus-Key", keys.keys), Roles: RoleMapping{Default: RoleNone}}
+	require.True(t, a.Enabled())
+	r, err := http.NewRequest(http.MethodGet, "/api/tasks", nil)
+	require.NoError(t, err)
+	r.Header.Set("X-Argus-Key", "test")
+	identity, err = a.Authenticate(context.Background(), r)
+	require.NoError(t, err)
+	assert.Equal(t, RoleViewer, identity.Role, "the key's role, not the default")
+	r.Header.Set("X-Argus-Key", "wrong")
+	_, err = a.Authenticate(context.Background(), r)
+	assert.ErrorIs(t, err, ErrUnauthenticated)
+}
@@ -0,0 +1,199 @@
+// File: internal/auth/auth.go
+// Brief: Authentication providers of the API and the dashboard
+// Detailed: Authenticates requests against the configured providers: API keys sent in a header, OIDC access tokens sent as bearer tokens, LDAP user names and passwords sent with basic authentication, and the session cookie of a dashboard login. The groups an identity provider reports are mapped to the role the access control of the API enforces.
+// Author: drama.lin@aver.com
+// Date: 2026-10-14
+
+// Package auth authenticates the users of Argus against OIDC, LDAP and API
+// keys.
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Provider names
+const (
+	ProviderOIDC = "oidc"
+	ProviderLDAP = "ldap"
+)
+
+// ErrUnauthenticated is returned when a request carries no credentials a
+// provider accepts
+var ErrUnauthenticated = errors.New("unauthenticated")
+
+// Role is the access level of a user, from viewer to admin
+type Role string
+
+// Roles
+const (
+	RoleViewer   Role = "viewer"   // Reads metrics, alerts and tasks
+	RoleOperator Role = "operator" // Also acknowledges, silences and runs
+	RoleAdmin    Role = "admin"    // Also configures Argus
+	RoleNone     Role = "none"     // Denied, for users in no mapped group
+)
+
+var roleRanks = map[Role]int{RoleNone: 0, RoleViewer: 1, RoleOperator: 2, RoleAdmin: 3}
+
+// ParseRole returns the role named name
+func ParseRole(name string) (Role, error) {
+	role := Role(strings.ToLower(strings.TrimSpace(name)))
+	if _, ok := roleRanks[role]; !ok {
+		return "", fmt.Errorf("unknown role %q: expected viewer, operator, admin or none", name)
+	}
+	return role, nil
+}
+
+// Allows reports whether the role grants at least the access of required
+func (r Role) Allows(required Role) bool {
+	return r != RoleNone && roleRanks[r] >= roleRanks[required]
+}
+
+// Identity is an authenticated user
+type Identity struct {
+	User     string    `json:"user"`
+	Groups   []string  `json:"groups,omitempty"`
+	Role     Role      `json:"role"`
+	Provider string    `json:"provider"`
+	Expires  time.Time `json:"expires,omitempty"` // Expiry of the token or session; zero for passwords
+	// Session the request was authenticated by; empty for credentials sent
+	// with the request
+	SessionID string `json:"session_id,omitempty"`
+}
+
+// RoleMapping maps the groups of users to their role
+type RoleMapping struct {
+	Default Role            // Role of users in no mapped group; empty means viewer
+	Groups  map[string]Role // Role by group name or DN
+}
+
+// Role returns the highest role the groups map to, or the default. Groups
+// match case-insensitively by name or DN, and a DN also matches by the
+// value of its first component, e.g. cn=ops,ou=groups,dc=example,dc=com as
+// ops.
+func (m RoleMapping) Role(groups []string) Role {
+	role := m.Default
+	if role == "" {
+		role = RoleViewer
+	}
+	mapped := false
+	for _, group := range groups {
+		for name, r := range m.Groups {
+			if !groupMatches(group, name) {
+				continue
+			}
+			if !mapped || roleRanks[r] > roleRanks[role] {
+				role = r
+			}
+			mapped = true
+		}
+	}
+	return role
+}
+
+func groupMatches(group, name string) bool {
+	if strings.EqualFold(group, name) {
+		return true
+	}
+	first, _, isDN := strings.Cut(group, ",")
+	if !isDN {
+		return false
+	}
+	_, value, ok := strings.Cut(first, "=")
+	return ok && strings.EqualFold(strings.TrimSpace(value), name)
+}
+
+// Authenticator authenticates requests against the enabled providers
+type Authenticator struct {
+	OIDC     *OIDC     // nil when OIDC is disabled
+	LDAP     *LDAP     // nil when LDAP is disabled
+	APIKeys  *APIKeys  // nil without API keys
+	Sessions *Sessions // Dashboard logins; nil issues none
+	Roles    RoleMapping
+}
+
+// Enabled reports whether any provider is enabled
+func (a *Authenticator) Enabled() bool {
+	return a != nil && (a.OIDC != nil || a.LDAP != nil || a.APIKeys != nil)
+}
+
+// Authenticate returns the identity of the user making the request: an API
+// key, a bearer token verified by OIDC, basic credentials bound against LDAP,
+// or the session cookie of a dashboard login. It returns ErrUnauthenticated without
+// credentials, and when the user's groups map to no role.
+func (a *Authenticator) Authenticate(ctx context.Context, r *http.Request) (*Identity, error) {
+	var (
+		identity *Identity
+		err      error
+	)
+	if a.APIKeys != nil {
+		if key := r.Header.Get(a.APIKeys.Header()); key != "" {
+			// The role is the key's own, whatever groups map to
+			return a.APIKeys.Authenticate(key)
+		}
+	}
+	header := r.Header.Get("Authorization")
+	scheme, credentials, _ := strings.Cut(header, " ")
+	switch {
+	case strings.EqualFold(scheme, "Bearer") && a.OIDC != nil:
+		identity, err = a.OIDC.VerifyAccessToken(ctx, strings.TrimSpace(credentials))
+	case strings.EqualFold(scheme, "Basic") && a.LDAP != nil:
+		user, password, ok := r.BasicAuth()
+		if !ok {
+			return nil, ErrUnauthenticated
+		}
+		identity, err = a.LDAP.Authenticate(ctx, user, password)
+	case header == "" && a.Sessions != nil:
+		cookie, cookieErr := r.Cookie(a.Sessions.CookieName())
+		if cookieErr != nil {
+			return nil, ErrUnauthenticated
+		}
+		session, ok := a.Sessions.Lookup(cookie.Value)
+		if !ok {
+			return nil, fmt.Errorf("%w: session expired or revoked", ErrUnauthenticated)
+		}
+		// The role was mapped when the session was issued
+		return &Identity{User: session.User, Groups: session.Groups, Role: session.Role, Provider: session.Provider,
+			Expires: session.ExpiresAt, SessionID: session.ID}, nil
+	default:
+		return nil, ErrUnauthenticated
+	}
+	if err != nil {
+		return nil, err
+	}
+	return a.authorize(identity)
+}
+
+// CheckCSRF checks that a mutating request authenticated by a session cookie
+// carries the session's CSRF token. Credentials sent with the request are not
+// sent by browsers on their own, so they need none.
+func (a *Authenticator) CheckCSRF(r *http.Request, identity *Identity) error {
+	if identity.SessionID == "" {
+		return nil
+	}
+	switch r.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return nil
+	}
+	return a.Sessions.CheckCSRF(identity.SessionID, r.Header.Get(CSRFHeader))
+}
+
+// authorize sets the role of an identity from its groups
+func (a *Authenticator) authorize(identity *Identity) (*Identity, error) {
+	identity.Role = a.Roles.Role(identity.Groups)
+	if identity.Role == RoleNone {
+		return nil, fmt.Errorf("%w: user %q is in no group with access", ErrUnauthenticated, identity.User)
+	}
+	return identity, nil
+}
+
+// Login authorizes an identity returned by an OIDC code exchange or an LDAP
+// password check
+func (a *Authenticator) Login(identity *Identity) (*Identity, error) {
+	return a.authorize(identity)
+}
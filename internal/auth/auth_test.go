@@ -0,0 +1,94 @@
+package auth
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRoleMapping(t *testing.T) {
+	mapping := RoleMapping{Groups: map[string]Role{
+		"ops":                                   RoleOperator,
+		"cn=admins,ou=groups,dc=example,dc=com": RoleAdmin,
+	}}
+	assert.Equal(t, RoleViewer, mapping.Role(nil), "viewer by default")
+	assert.Equal(t, RoleOperator, mapping.Role([]string{"OPS"}))
+	assert.Equal(t, RoleOperator, mapping.Role([]string{"cn=ops,ou=groups,dc=example,dc=com"}), "DN by its first component")
+	assert.Equal(t, RoleAdmin, mapping.Role([]string{"ops", "CN=Admins,OU=Groups,DC=example,DC=com"}), "highest role")
+
+	mapping.Default = RoleNone
+	assert.Equal(t, RoleNone, mapping.Role([]string{"staff"}))
+	mapping.Groups["readers"] = RoleViewer
+	assert.Equal(t, RoleViewer, mapping.Role([]string{"readers"}))
+}
+
+func TestRole(t *testing.T) {
+	assert.True(t, RoleAdmin.Allows(RoleOperator))
+	assert.True(t, RoleOperator.Allows(RoleOperator))
+	assert.False(t, RoleViewer.Allows(RoleOperator))
+	assert.False(t, RoleNone.Allows(RoleNone))
+
+	role, err := ParseRole(" Admin ")
+	require.NoError(t, err)
+	assert.Equal(t, RoleAdmin, role)
+	_, err = ParseRole("root")
+	assert.Error(t, err)
+}
+
+func TestAuthenticator_Authenticate(t *testing.T) {
+	issuer := newTestIssuer(t)
+	directory := newTestDirectory(t)
+	ldap, err := NewLDAP(LDAPConfig{URL: "ldap://" + directory.addr, BaseDN: "ou=people,dc=example,dc=com"})
+	require.NoError(t, err)
+	a := &Authenticator{
+		OIDC:     NewOIDC(OIDCConfig{Issuer: issuer.server.URL, ClientID: "argus"}),
+		LDAP:     ldap,
+		Sessions: NewSessions(SessionConfig{}),
+		Roles:    RoleMapping{Default: RoleNone, Groups: map[string]Role{"ops": RoleOperator, "admins": RoleAdmin}},
+	}
+	require.True(t, a.Enabled())
+	assert.False(t, (&Authenticator{}).Enabled())
+	ctx := context.Background()
+
+	request := func(set func(r *http.Request)) (*Identity, error) {
+		r, err := http.NewRequest(http.MethodGet, "/api/alerts", nil)
+		require.NoError(t, err)
+		set(r)
+		return a.Authenticate(ctx, r)
+	}
+
+	identity, err := request(func(r *http.Request) {
+		r.Header.Set("Authorization", "Bearer "+issuer.sign(t, map[string]any{"aud": "argus", "preferred_username": "carol", "groups": []string{"admins"}}))
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "carol", identity.User)
+	assert.Equal(t, RoleAdmin, identity.Role)
+
+	identity, err = request(func(r *http.Request) { r.SetBasicAuth("alice", "wonderland") })
+	require.NoError(t, err)
+	assert.Equal(t, RoleOperator, identity.Role)
+
+	assert.Empty(t, identity.SessionID)
+	token, session, err := a.Sessions.Create(identity, "", "")
+	require.NoError(t, err)
+	identity, err = request(func(r *http.Request) { r.AddCookie(&http.Cookie{Name: DefaultSessionCookie, Value: token}) })
+	require.NoError(t, err)
+	assert.Equal(t, "alice", identity.User)
+	assert.Equal(t, RoleOperator, identity.Role)
+	assert.Equal(t, session.ID, identity.SessionID)
+	a.Sessions.Revoke(session.ID)
+	_, err = request(func(r *http.Request) { r.AddCookie(&http.Cookie{Name: DefaultSessionCookie, Value: token}) })
+	assert.ErrorIs(t, err, ErrUnauthenticated, "revoked session")
+
+	_, err = request(func(r *http.Request) {})
+	assert.ErrorIs(t, err, ErrUnauthenticated)
+	_, err = request(func(r *http.Request) {
+		r.Header.Set("Authorization", "Bearer "+issuer.sign(t, map[string]any{"aud": "argus", "groups": []string{"staff"}}))
+	})
+	assert.ErrorIs(t, err, ErrUnauthenticated, "in no group with access")
+	_, err = request(func(r *http.Request) { r.Header.Set("Authorization", "Token abc") })
+	assert.ErrorIs(t, err, ErrUnauthenticated)
+}
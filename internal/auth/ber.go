@@ -0,0 +1,156 @@
+// File: internal/auth/ber.go
+// Brief: BER encoding of the LDAP messages
+// Detailed: Encodes and decodes the subset of ASN.1 Basic Encoding Rules LDAPv3 messages use: definite-length elements with single-byte tags, integers, octet strings and constructed sequences.
+// Author: drama.lin@aver.com
+// Date: 2026-10-14
+
+package auth
+
+import (
+	"bufio"
+	"errors"
+	"io"
+)
+
+// BER tags of the universal types used
+const (
+	berBoolean     = 0x01
+	berInteger     = 0x02
+	berOctetString = 0x04
+	berEnumerated  = 0x0a
+	berSequence    = 0x30
+	berSet         = 0x31
+)
+
+// maxMessageBytes caps the size of the LDAP messages read
+const maxMessageBytes = 1 << 20
+
+// berElement is a decoded element
+type berElement struct {
+	Tag     byte
+	Content []byte
+}
+
+// berEncode returns an element with the content of the given parts
+func berEncode(tag byte, parts ...[]byte) []byte {
+	size := 0
+	for _, p := range parts {
+		size += len(p)
+	}
+	out := append([]byte{tag}, berLength(size)...)
+	for _, p := range parts {
+		out = append(out, p...)
+	}
+	return out
+}
+
+func berLength(n int) []byte {
+	if n < 0x80 {
+		return []byte{byte(n)}
+	}
+	var digits []byte
+	for ; n > 0; n >>= 8 {
+		digits = append([]byte{byte(n)}, digits...)
+	}
+	return append([]byte{0x80 | byte(len(digits))}, digits...)
+}
+
+// berInt encodes a non-negative integer with tag
+func berInt(tag byte, n int) []byte {
+	var digits []byte
+	for {
+		digits = append([]byte{byte(n)}, digits...)
+		n >>= 8
+		if n == 0 {
+			break
+		}
+	}
+	if digits[0]&0x80 != 0 {
+		digits = append([]byte{0}, digits...)
+	}
+	return berEncode(tag, digits)
+}
+
+// berString encodes a string with tag
+func berString(tag byte, s string) []byte {
+	return berEncode(tag, []byte(s))
+}
+
+// berDecode splits the first element off data
+func berDecode(data []byte) (berElement, []byte, error) {
+	if len(data) < 2 {
+		return berElement{}, nil, errors.New("truncated BER element")
+	}
+	tag, length := data[0], int(data[1])
+	data = data[2:]
+	if length&0x80 != 0 {
+		digits := length & 0x7f
+		if digits == 0 || digits > 4 || len(data) < digits {
+			return berElement{}, nil, errors.New("unsupported BER length")
+		}
+		length = 0
+		for _, d := range data[:digits] {
+			length = length<<8 | int(d)
+		}
+		data = data[digits:]
+	}
+	if length > len(data) {
+		return berElement{}, nil, errors.New("truncated BER element")
+	}
+	return berElement{Tag: tag, Content: data[:length]}, data[length:], nil
+}
+
+// berChildren decodes the elements a constructed element holds
+func berChildren(content []byte) ([]berElement, error) {
+	var children []berElement
+	for len(content) > 0 {
+		child, rest, err := berDecode(content)
+		if err != nil {
+			return nil, err
+		}
+		children = append(children, child)
+		content = rest
+	}
+	return children, nil
+}
+
+// intValue returns the value of an integer or enumerated element
+func (e berElement) intValue() int {
+	n := 0
+	for _, d := range e.Content {
+		n = n<<8 | int(d)
+	}
+	return n
+}
+
+// berRead reads one element from r
+func berRead(r *bufio.Reader) (berElement, error) {
+	header := make([]byte, 2, 6)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return berElement{}, err
+	}
+	if digits := int(header[1] & 0x7f); header[1]&0x80 != 0 {
+		if digits == 0 || digits > 4 {
+			return berElement{}, errors.New("unsupported BER length")
+		}
+		header = header[:2+digits]
+		if _, err := io.ReadFull(r, header[2:]); err != nil {
+			return berElement{}, err
+		}
+	}
+	length := int(header[1])
+	if length&0x80 != 0 {
+		length = 0
+		for _, d := range header[2:] {
+			length = length<<8 | int(d)
+		}
+	}
+	if length > maxMessageBytes {
+		return berElement{}, errors.New("LDAP message too large")
+	}
+	content := make([]byte, length)
+	if _, err := io.ReadFull(r, content); err != nil {
+		return berElement{}, err
+	}
+	return berElement{Tag: header[0], Content: content}, nil
+}
@@ -0,0 +1,375 @@
+// File: internal/auth/ldap.go
+// Brief: LDAP bind authentication of user names and passwords
+// Detailed: Checks the password of a user by binding as the user's entry on an LDAP or Active Directory server, over ldap:// or ldaps://. With a service account, the entry is searched for by user name first; without one, its DN is built from the user attribute and the base DN. The group attribute of the entry, such as memberOf, lists the groups mapped to the user's role. Successful logins are cached briefly, so API clients sending basic credentials do not bind on every request.
+// Author: drama.lin@aver.com
+// Date: 2026-10-14
+
+package auth
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// LDAP protocol operations and result codes used
+const (
+	ldapBindRequest    = 0x60
+	ldapBindResponse   = 0x61
+	ldapUnbindRequest  = 0x42
+	ldapSearchRequest  = 0x63
+	ldapSearchEntry    = 0x64
+	ldapSearchDone     = 0x65
+	ldapSearchRef      = 0x73
+	ldapSimpleAuth     = 0x80
+	ldapEqualityFilter = 0xa3
+
+	ldapSuccess            = 0
+	ldapInvalidCredentials = 49
+
+	ldapScopeSubtree = 2
+)
+
+const (
+	// ldapCacheTTL is how long a successful login is reused
+	ldapCacheTTL = time.Minute
+	// ldapCacheSize caps the logins cached
+	ldapCacheSize = 1024
+)
+
+// ErrInvalidCredentials is returned when the user name or password is wrong
+var ErrInvalidCredentials = errors.New("invalid user name or password")
+
+// LDAPConfig configures LDAP bind authentication
+type LDAPConfig struct {
+	URL            string // ldap://host:389 or ldaps://host:636
+	BindDN         string // Service account searching for users; empty binds as uid=<user>,<base DN>
+	BindPassword   string
+	BaseDN         string // Base of the user search
+	UserAttribute  string // Attribute holding the user name; empty means uid
+	GroupAttribute string // Attribute listing the user's groups; empty means memberOf
+	Timeout        time.Duration
+	TLS            *tls.Config // TLS of ldaps:// connections; nil uses the system roots
+}
+
+// LDAP authenticates users by binding against an LDAP server
+type LDAP struct {
+	config LDAPConfig
+	addr   string
+	tls    bool
+
+	mu    sync.Mutex
+	cache map[[sha256.Size]byte]ldapLogin
+	now   func() time.Time
+}
+
+type ldapLogin struct {
+	identity Identity
+	expires  time.Time
+}
+
+// NewLDAP creates an LDAP authenticator
+func NewLDAP(config LDAPConfig) (*LDAP, error) {
+	u, err := url.Parse(config.URL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid LDAP URL: %w", err)
+	}
+	l := &LDAP{config: config, cache: make(map[[sha256.Size]byte]ldapLogin), now: time.Now}
+	switch u.Scheme {
+	case "ldap":
+		l.addr = hostPort(u, "389")
+	case "ldaps":
+		l.addr = hostPort(u, "636")
+		l.tls = true
+	default:
+		return nil, fmt.Errorf("invalid LDAP URL %q: scheme must be ldap or ldaps", config.URL)
+	}
+	if u.Hostname() == "" {
+		return nil, fmt.Errorf("invalid LDAP URL %q: missing host", config.URL)
+	}
+	if l.config.UserAttribute == "" {
+		l.config.UserAttribute = "uid"
+	}
+	if l.config.GroupAttribute == "" {
+		l.config.GroupAttribute = "memberOf"
+	}
+	if l.config.Timeout <= 0 {
+		l.config.Timeout = 10 * time.Second
+	}
+	return l, nil
+}
+
+func hostPort(u *url.URL, port string) string {
+	if u.Port() != "" {
+		port = u.Port()
+	}
+	return net.JoinHostPort(u.Hostname(), port)
+}
+
+// Authenticate checks the password of user and returns the user's identity
+func (l *LDAP) Authenticate(ctx context.Context, user, password string) (*Identity, error) {
+	// An empty password would be an unauthenticated bind, which servers accept
+	if user == "" || password == "" {
+		return nil, ErrInvalidCredentials
+	}
+	key := sha256.Sum256([]byte(user + "\x00" + password))
+	if identity, ok := l.cached(key); ok {
+		return identity, nil
+	}
+
+	conn, err := l.dial(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.close()
+
+	dn := l.config.UserAttribute + "=" + escapeDN(user) + "," + l.config.BaseDN
+	var groups []string
+	if l.config.BindDN != "" {
+		if err := conn.bind(l.config.BindDN, l.config.BindPassword); err != nil {
+			return nil, fmt.Errorf("LDAP service bind failed: %w", err)
+		}
+		if dn, groups, err = conn.findUser(l.config.BaseDN, l.config.UserAttribute, user, l.config.GroupAttribute); err != nil {
+			return nil, err
+		}
+	}
+	if err := conn.bind(dn, password); err != nil {
+		return nil, err
+	}
+	if l.config.BindDN == "" {
+		// Without a service account, read the groups as the user
+		if _, userGroups, err := conn.findUser(l.config.BaseDN, l.config.UserAttribute, user, l.config.GroupAttribute); err == nil {
+			groups = userGroups
+		}
+	}
+
+	identity := Identity{User: user, Groups: groups, Provider: ProviderLDAP}
+	l.store(key, identity)
+	return &identity, nil
+}
+
+func (l *LDAP) cached(key [sha256.Size]byte) (*Identity, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	login, ok := l.cache[key]
+	if !ok || !l.now().Before(login.expires) {
+		return nil, false
+	}
+	identity := login.identity
+	return &identity, true
+}
+
+func (l *LDAP) store(key [sha256.Size]byte, identity Identity) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := l.now()
+	if len(l.cache) >= ldapCacheSize {
+		for k, login := range l.cache {
+			if !now.Before(login.expires) {
+				delete(l.cache, k)
+			}
+		}
+		if len(l.cache) >= ldapCacheSize {
+			clear(l.cache)
+		}
+	}
+	l.cache[key] = ldapLogin{identity: identity, expires: now.Add(ldapCacheTTL)}
+}
+
+// ldapConn is a connection to an LDAP server
+type ldapConn struct {
+	conn     net.Conn
+	reader   *bufio.Reader
+	deadline time.Time
+	nextID   int
+}
+
+func (l *LDAP) dial(ctx context.Context) (*ldapConn, error) {
+	dialer := &net.Dialer{Timeout: l.config.Timeout}
+	var (
+		conn net.Conn
+		err  error
+	)
+	if l.tls {
+		config := l.config.TLS
+		if config == nil {
+			config = &tls.Config{MinVersion: tls.VersionTLS12}
+		}
+		conn, err = (&tls.Dialer{NetDialer: dialer, Config: config}).DialContext(ctx, "tcp", l.addr)
+	} else {
+		conn, err = dialer.DialContext(ctx, "tcp", l.addr)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to LDAP server: %w", err)
+	}
+	deadline := time.Now().Add(l.config.Timeout)
+	if d, ok := ctx.Deadline(); ok && d.Before(deadline) {
+		deadline = d
+	}
+	conn.SetDeadline(deadline)
+	return &ldapConn{conn: conn, reader: bufio.NewReader(conn), deadline: deadline}, nil
+}
+
+func (c *ldapConn) close() {
+	c.send(berEncode(ldapUnbindRequest))
+	c.conn.Close()
+}
+
+// send writes a message with the protocol operation op and returns its ID
+func (c *ldapConn) send(op []byte) (int, error) {
+	c.nextID++
+	_, err := c.conn.Write(berEncode(berSequence, berInt(berInteger, c.nextID), op))
+	return c.nextID, err
+}
+
+// receive reads the protocol operation of the next message with ID id
+func (c *ldapConn) receive(id int) (berElement, error) {
+	for {
+		message, err := berRead(c.reader)
+		if err != nil {
+			return berElement{}, fmt.Errorf("failed to read LDAP response: %w", err)
+		}
+		parts, err := berChildren(message.Content)
+		if err != nil || len(parts) < 2 || parts[0].Tag != berInteger {
+			return berElement{}, errors.New("malformed LDAP response")
+		}
+		if parts[0].intValue() == id {
+			return parts[1], nil
+		}
+	}
+}
+
+// bind authenticates the connection as dn with a simple bind
+func (c *ldapConn) bind(dn, password string) error {
+	id, err := c.send(berEncode(ldapBindRequest,
+		berInt(berInteger, 3),
+		berString(berOctetString, dn),
+		berString(ldapSimpleAuth, password)))
+	if err != nil {
+		return fmt.Errorf("failed to send LDAP bind: %w", err)
+	}
+	op, err := c.receive(id)
+	if err != nil {
+		return err
+	}
+	if op.Tag != ldapBindResponse {
+		return errors.New("unexpected LDAP bind response")
+	}
+	return ldapResult(op)
+}
+
+// findUser searches base for the single entry with attribute equal to user
+// and returns its DN and groupAttribute values
+func (c *ldapConn) findUser(base, attribute, user, groupAttribute string) (string, []string, error) {
+	id, err := c.send(berEncode(ldapSearchRequest,
+		berString(berOctetString, base),
+		berInt(berEnumerated, ldapScopeSubtree),
+		berInt(berEnumerated, 0), // Never dereference aliases
+		berInt(berInteger, 2),    // Size limit: detect ambiguous user names
+		berInt(berInteger, max(int(time.Until(c.deadline).Seconds()), 1)),
+		berEncode(berBoolean, []byte{0}),
+		berEncode(ldapEqualityFilter, berString(berOctetString, attribute), berString(berOctetString, user)),
+		berEncode(berSequence, berString(berOctetString, groupAttribute))))
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to send LDAP search: %w", err)
+	}
+	var (
+		dn     string
+		groups []string
+		found  int
+	)
+	for {
+		op, err := c.receive(id)
+		if err != nil {
+			return "", nil, err
+		}
+		switch op.Tag {
+		case ldapSearchEntry:
+			found++
+			if dn, groups, err = parseEntry(op, groupAttribute); err != nil {
+				return "", nil, err
+			}
+		case ldapSearchRef:
+			// Referrals to other servers are not followed
+		case ldapSearchDone:
+			if err := ldapResult(op); err != nil && found < 2 {
+				return "", nil, fmt.Errorf("LDAP user search failed: %w", err)
+			}
+			switch found {
+			case 0:
+				return "", nil, ErrInvalidCredentials
+			case 1:
+				return dn, groups, nil
+			}
+			return "", nil, fmt.Errorf("LDAP user search for %q matched several entries", user)
+		default:
+			return "", nil, errors.New("unexpected LDAP search response")
+		}
+	}
+}
+
+// parseEntry returns the DN of a search result entry and the values of attribute
+func parseEntry(op berElement, attribute string) (string, []string, error) {
+	parts, err := berChildren(op.Content)
+	if err != nil || len(parts) < 2 {
+		return "", nil, errors.New("malformed LDAP search entry")
+	}
+	attributes, err := berChildren(parts[1].Content)
+	if err != nil {
+		return "", nil, errors.New("malformed LDAP search entry")
+	}
+	var values []string
+	for _, a := range attributes {
+		fields, err := berChildren(a.Content)
+		if err != nil || len(fields) < 2 || !strings.EqualFold(string(fields[0].Content), attribute) {
+			continue
+		}
+		items, err := berChildren(fields[1].Content)
+		if err != nil {
+			continue
+		}
+		for _, item := range items {
+			values = append(values, string(item.Content))
+		}
+	}
+	return string(parts[0].Content), values, nil
+}
+
+// ldapResult returns the error an LDAPResult reports
+func ldapResult(op berElement) error {
+	parts, err := berChildren(op.Content)
+	if err != nil || len(parts) < 3 {
+		return errors.New("malformed LDAP result")
+	}
+	switch code := parts[0].intValue(); code {
+	case ldapSuccess:
+		return nil
+	case ldapInvalidCredentials:
+		return ErrInvalidCredentials
+	default:
+		return fmt.Errorf("LDAP result %d: %s", code, parts[2].Content)
+	}
+}
+
+// escapeDN escapes the special characters of a DN attribute value
+func escapeDN(value string) string {
+	var b strings.Builder
+	for i, r := range value {
+		switch {
+		case strings.ContainsRune(`,+"\<>;=`, r),
+			i == 0 && (r == ' ' || r == '#'),
+			i == len(value)-1 && r == ' ':
+			b.WriteByte('\\')
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
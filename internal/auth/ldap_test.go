@@ -0,0 +1,154 @@
+package auth
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testDirectory is an LDAP server with a few users
+type testDirectory struct {
+	addr     string
+	binds    atomic.Int32
+	accounts map[string]string   // Password by DN
+	groups   map[string][]string // memberOf by DN
+}
+
+func newTestDirectory(t *testing.T) *testDirectory {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { listener.Close() })
+	d := &testDirectory{
+		addr: listener.Addr().String(),
+		accounts: map[string]string{
+			"cn=argus,dc=example,dc=com":            "service",
+			"uid=alice,ou=people,dc=example,dc=com": "wonderland",
+		},
+		groups: map[string][]string{
+			"uid=alice,ou=people,dc=example,dc=com": {"cn=ops,ou=groups,dc=example,dc=com", "cn=staff,ou=groups,dc=example,dc=com"},
+		},
+	}
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go d.serve(conn)
+		}
+	}()
+	return d
+}
+
+func (d *testDirectory) serve(conn net.Conn) {
+	defer conn.Close()
+	reader := bufio.NewReader(conn)
+	bound := ""
+	for {
+		message, err := berRead(reader)
+		if err != nil {
+			return
+		}
+		parts, _ := berChildren(message.Content)
+		id := parts[0].intValue()
+		reply := func(op []byte) {
+			conn.Write(berEncode(berSequence, berInt(berInteger, id), op))
+		}
+		result := func(tag byte, code int) {
+			reply(berEncode(tag, berInt(berEnumerated, code), berString(berOctetString, ""), berString(berOctetString, "")))
+		}
+		switch op := parts[1]; op.Tag {
+		case ldapBindRequest:
+			d.binds.Add(1)
+			fields, _ := berChildren(op.Content)
+			dn, password := string(fields[1].Content), string(fields[2].Content)
+			if stored, ok := d.accounts[dn]; !ok || stored != password {
+				result(ldapBindResponse, ldapInvalidCredentials)
+				continue
+			}
+			bound = dn
+			result(ldapBindResponse, ldapSuccess)
+		case ldapSearchRequest:
+			fields, _ := berChildren(op.Content)
+			filter, _ := berChildren(fields[6].Content)
+			if bound != "" && string(filter[0].Content) == "uid" {
+				dn := "uid=" + string(filter[1].Content) + ",ou=people,dc=example,dc=com"
+				if _, ok := d.accounts[dn]; ok {
+					var values [][]byte
+					for _, g := range d.groups[dn] {
+						values = append(values, berString(berOctetString, g))
+					}
+					reply(berEncode(ldapSearchEntry,
+						berString(berOctetString, dn),
+						berEncode(berSequence, berEncode(berSequence,
+							berString(berOctetString, "memberOf"),
+							berEncode(berSet, values...)))))
+				}
+			}
+			result(ldapSearchDone, ldapSuccess)
+		case ldapUnbindRequest:
+			return
+		}
+	}
+}
+
+func TestLDAP_Authenticate(t *testing.T) {
+	directory := newTestDirectory(t)
+	ldap, err := NewLDAP(LDAPConfig{
+		URL: "ldap://" + directory.addr, BindDN: "cn=argus,dc=example,dc=com", BindPassword: "service",
+		BaseDN: "dc=example,dc=com",
+	})
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	identity, err := ldap.Authenticate(ctx, "alice", "wonderland")
+	require.NoError(t, err)
+	assert.Equal(t, "alice", identity.User)
+	assert.Equal(t, ProviderLDAP, identity.Provider)
+	assert.Equal(t, []string{"cn=ops,ou=groups,dc=example,dc=com", "cn=staff,ou=groups,dc=example,dc=com"}, identity.Groups)
+	assert.EqualValues(t, 2, directory.binds.Load(), "service bind, then user bind")
+
+	_, err = ldap.Authenticate(ctx, "alice", "wonderland")
+	require.NoError(t, err)
+	assert.EqualValues(t, 2, directory.binds.Load(), "cached login")
+
+	_, err = ldap.Authenticate(ctx, "alice", "guess")
+	assert.ErrorIs(t, err, ErrInvalidCredentials)
+	_, err = ldap.Authenticate(ctx, "bob", "guess")
+	assert.ErrorIs(t, err, ErrInvalidCredentials, "unknown user")
+	_, err = ldap.Authenticate(ctx, "alice", "")
+	assert.ErrorIs(t, err, ErrInvalidCredentials, "an unauthenticated bind is no login")
+}
+
+func TestLDAP_DirectBind(t *testing.T) {
+	directory := newTestDirectory(t)
+	ldap, err := NewLDAP(LDAPConfig{URL: "ldap://" + directory.addr, BaseDN: "ou=people,dc=example,dc=com"})
+	require.NoError(t, err)
+
+	identity, err := ldap.Authenticate(context.Background(), "alice", "wonderland")
+	require.NoError(t, err)
+	assert.Len(t, identity.Groups, 2, "groups read as the user")
+	assert.EqualValues(t, 1, directory.binds.Load())
+}
+
+func TestNewLDAP(t *testing.T) {
+	_, err := NewLDAP(LDAPConfig{URL: "http://ldap.example.com"})
+	assert.ErrorContains(t, err, "scheme")
+	l, err := NewLDAP(LDAPConfig{URL: "ldaps://ldap.example.com"})
+	require.NoError(t, err)
+	assert.Equal(t, "ldap.example.com:636", l.addr)
+	assert.Equal(t, "uid", l.config.UserAttribute)
+	assert.Equal(t, "memberOf", l.config.GroupAttribute)
+}
+
+func TestEscapeDN(t *testing.T) {
+	assert.Equal(t, "alice", escapeDN("alice"))
+	assert.Equal(t, `smith\, john`, escapeDN("smith, john"))
+	assert.Equal(t, `\#admin\=x`, escapeDN("#admin=x"))
+}
@@ -0,0 +1,288 @@
+// File: internal/auth/oidc.go
+// Brief: OpenID Connect provider of the dashboard login and API tokens
+// Detailed: Discovers the endpoints and signing keys of an OpenID Connect issuer, runs the authorization code flow of the dashboard login and verifies the ID tokens it returns, and validates the JWT access tokens API clients send as bearer tokens. Discovery runs on first use, so Argus starts while the issuer is unreachable, and the signing keys are fetched again when a token names a key rotated in since.
+// Author: drama.lin@aver.com
+// Date: 2026-10-14
+
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// keyRefreshInterval limits how often unknown key IDs refetch the key set
+	keyRefreshInterval = time.Minute
+	// maxDocumentBytes caps the discovery, key set and token responses read
+	maxDocumentBytes = 1 << 20
+)
+
+// OIDCConfig configures an OpenID Connect provider
+type OIDCConfig struct {
+	Issuer       string   // Issuer URL, e.g. https://idp.example.com/realms/ops
+	ClientID     string   // Client of the dashboard login
+	ClientSecret string   // Secret of ClientID; empty for public clients
+	RedirectURL  string   // Callback URL registered for ClientID
+	Scopes       []string // Requested scopes; openid is always added
+	Audience     string   // Audience of API access tokens; empty means ClientID
+	UserClaim    string   // Claim naming the user; empty means preferred_username, then email, then sub
+	GroupsClaim  string   // Claim listing the groups; empty means groups
+	Client       *http.Client
+}
+
+// discovery is the part of the issuer's provider metadata used
+type discovery struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+// OIDC is an OpenID Connect provider
+type OIDC struct {
+	config OIDCConfig
+	client *http.Client
+
+	mu        sync.Mutex
+	metadata  *discovery
+	keys      keySet
+	keysFetch time.Time
+	now       func() time.Time
+}
+
+// NewOIDC creates a provider for the issuer of config
+func NewOIDC(config OIDCConfig) *OIDC {
+	client := config.Client
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+	config.Issuer = strings.TrimSuffix(config.Issuer, "/")
+	if config.Audience == "" {
+		config.Audience = config.ClientID
+	}
+	if config.GroupsClaim == "" {
+		config.GroupsClaim = "groups"
+	}
+	return &OIDC{config: config, client: client, now: time.Now}
+}
+
+// RedirectURL returns the callback URL of the login
+func (o *OIDC) RedirectURL() string {
+	return o.config.RedirectURL
+}
+
+// discover returns the provider metadata, fetching it on first use
+func (o *OIDC) discover(ctx context.Context) (*discovery, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if o.metadata != nil {
+		return o.metadata, nil
+	}
+	var metadata discovery
+	if err := o.getJSON(ctx, o.config.Issuer+"/.well-known/openid-configuration", &metadata); err != nil {
+		return nil, fmt.Errorf("OIDC discovery failed: %w", err)
+	}
+	if strings.TrimSuffix(metadata.Issuer, "/") != o.config.Issuer {
+		return nil, fmt.Errorf("OIDC discovery returned issuer %q, expected %q", metadata.Issuer, o.config.Issuer)
+	}
+	if metadata.AuthorizationEndpoint == "" || metadata.TokenEndpoint == "" || metadata.JWKSURI == "" {
+		return nil, errors.New("OIDC discovery document lacks an endpoint")
+	}
+	metadata.Issuer = o.config.Issuer
+	o.metadata = &metadata
+	return o.metadata, nil
+}
+
+// verificationKeys returns the signing keys, fetching them again when kid is
+// not among them and they were not fetched within keyRefreshInterval
+func (o *OIDC) verificationKeys(ctx context.Context, kid string, metadata *discovery) (keySet, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if o.keys != nil {
+		if _, ok := o.keys[kid]; ok || kid == "" || o.now().Sub(o.keysFetch) < keyRefreshInterval {
+			return o.keys, nil
+		}
+	}
+	var raw json.RawMessage
+	if err := o.getJSON(ctx, metadata.JWKSURI, &raw); err != nil {
+		if o.keys != nil {
+			return o.keys, nil // Keep verifying with the keys known
+		}
+		return nil, fmt.Errorf("failed to fetch OIDC signing keys: %w", err)
+	}
+	keys, err := parseKeySet(raw)
+	if err != nil {
+		return nil, err
+	}
+	o.keys = keys
+	o.keysFetch = o.now()
+	return keys, nil
+}
+
+func (o *OIDC) getJSON(ctx context.Context, target string, v any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	resp, err := o.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s returned %s", target, resp.Status)
+	}
+	return json.NewDecoder(io.LimitReader(resp.Body, maxDocumentBytes)).Decode(v)
+}
+
+// AuthCodeURL returns the URL of the issuer's login page, which redirects
+// back to the callback with a code and state
+func (o *OIDC) AuthCodeURL(ctx context.Context, state, nonce string) (string, error) {
+	metadata, err := o.discover(ctx)
+	if err != nil {
+		return "", err
+	}
+	scopes := []string{"openid"}
+	for _, scope := range o.config.Scopes {
+		if scope != "openid" {
+			scopes = append(scopes, scope)
+		}
+	}
+	query := url.Values{
+		"response_type": {"code"},
+		"client_id":     {o.config.ClientID},
+		"redirect_uri":  {o.config.RedirectURL},
+		"scope":         {strings.Join(scopes, " ")},
+		"state":         {state},
+		"nonce":         {nonce},
+	}
+	separator := "?"
+	if strings.Contains(metadata.AuthorizationEndpoint, "?") {
+		separator = "&"
+	}
+	return metadata.AuthorizationEndpoint + separator + query.Encode(), nil
+}
+
+// Exchange redeems the code of a login callback for the user's ID token,
+// verified against nonce, and returns it with the user's identity
+func (o *OIDC) Exchange(ctx context.Context, code, nonce string) (string, *Identity, error) {
+	metadata, err := o.discover(ctx)
+	if err != nil {
+		return "", nil, err
+	}
+	form := url.Values{
+		"grant_type":   {"authorization_code"},
+		"code":         {code},
+		"redirect_uri": {o.config.RedirectURL},
+	}
+	if o.config.ClientSecret == "" {
+		form.Set("client_id", o.config.ClientID)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, metadata.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	if o.config.ClientSecret != "" {
+		req.SetBasicAuth(url.QueryEscape(o.config.ClientID), url.QueryEscape(o.config.ClientSecret))
+	}
+	resp, err := o.client.Do(req)
+	if err != nil {
+		return "", nil, fmt.Errorf("OIDC token request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	var token struct {
+		IDToken          string `json:"id_token"`
+		Error            string `json:"error"`
+		ErrorDescription string `json:"error_description"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxDocumentBytes)).Decode(&token); err != nil {
+		return "", nil, fmt.Errorf("invalid OIDC token response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK || token.Error != "" {
+		return "", nil, fmt.Errorf("OIDC token request failed: %s %s %s", resp.Status, token.Error, token.ErrorDescription)
+	}
+	if token.IDToken == "" {
+		return "", nil, errors.New("OIDC token response has no ID token")
+	}
+	identity, err := o.VerifyIDToken(ctx, token.IDToken, nonce)
+	if err != nil {
+		return "", nil, err
+	}
+	return token.IDToken, identity, nil
+}
+
+// VerifyIDToken verifies an ID token issued to the dashboard client, and its
+// nonce unless nonce is empty
+func (o *OIDC) VerifyIDToken(ctx context.Context, token, nonce string) (*Identity, error) {
+	c, err := o.verify(ctx, token, o.config.ClientID)
+	if err != nil {
+		return nil, err
+	}
+	if nonce != "" && c.string("nonce") != nonce {
+		return nil, errors.New("ID token nonce does not match the login")
+	}
+	return o.identity(c), nil
+}
+
+// VerifyAccessToken verifies a JWT access token issued for the API audience
+func (o *OIDC) VerifyAccessToken(ctx context.Context, token string) (*Identity, error) {
+	c, err := o.verify(ctx, token, o.config.Audience)
+	if err != nil {
+		return nil, err
+	}
+	return o.identity(c), nil
+}
+
+func (o *OIDC) verify(ctx context.Context, token, audience string) (claims, error) {
+	metadata, err := o.discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var header struct {
+		Kid string `json:"kid"`
+	}
+	if head, _, ok := strings.Cut(token, "."); !ok || decodeSegment(head, &header) != nil {
+		return nil, errors.New("malformed token")
+	}
+	keys, err := o.verificationKeys(ctx, header.Kid, metadata)
+	if err != nil {
+		return nil, err
+	}
+	c, err := verifyJWT(token, keys)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.validate(metadata.Issuer, audience, o.now()); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// identity returns the user and groups the claims name
+func (o *OIDC) identity(c claims) *Identity {
+	user := c.string(o.config.UserClaim)
+	if o.config.UserClaim == "" {
+		for _, name := range []string{"preferred_username", "email", "sub"} {
+			if user = c.string(name); user != "" {
+				break
+			}
+		}
+	}
+	if user == "" {
+		user = c.string("sub")
+	}
+	expires, _ := c.time("exp")
+	return &Identity{User: user, Groups: c.strings(o.config.GroupsClaim), Provider: ProviderOIDC, Expires: expires}
+}
@@ -0,0 +1,214 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testIssuer is an OpenID Connect issuer signing tokens with an RSA key
+type testIssuer struct {
+	server   *httptest.Server
+	key      *rsa.PrivateKey
+	kid      string
+	jwksHits atomic.Int32
+	nonce    string // Nonce of the ID token the token endpoint returns
+}
+
+func newTestIssuer(t *testing.T) *testIssuer {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	issuer := &testIssuer{key: key, kid: "k1"}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]string{
+			"issuer":                 issuer.server.URL,
+			"authorization_endpoint": issuer.server.URL + "/authorize",
+			"token_endpoint":         issuer.server.URL + "/token",
+			"jwks_uri":               issuer.server.URL + "/jwks",
+		})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		issuer.jwksHits.Add(1)
+		json.NewEncoder(w).Encode(map[string]any{"keys": []map[string]string{{
+			"kty": "RSA", "kid": issuer.kid, "use": "sig",
+			"n": base64.RawURLEncoding.EncodeToString(issuer.key.N.Bytes()),
+			"e": base64.RawURLEncoding.EncodeToString(big.NewInt(int64(issuer.key.E)).Bytes()),
+		}}})
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		user, secret, _ := r.BasicAuth()
+		if user != "argus" || secret != "s3cret" || r.FormValue("code") != "good-code" {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]string{"error": "invalid_grant"})
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]string{"id_token": issuer.sign(t, map[string]any{
+			"aud": "argus", "preferred_username": "alice", "groups": []string{"ops"}, "nonce": issuer.nonce,
+		})})
+	})
+	issuer.server = httptest.NewServer(mux)
+	t.Cleanup(issuer.server.Close)
+	return issuer
+}
+
+// sign returns an RS256 token of the claims, issued by the issuer and valid
+// for an hour unless the claims say otherwise
+func (i *testIssuer) sign(t *testing.T, c map[string]any) string {
+	t.Helper()
+	full := map[string]any{"iss": i.server.URL, "sub": "u-1", "exp": time.Now().Add(time.Hour).Unix()}
+	for k, v := range c {
+		full[k] = v
+	}
+	return signJWT(t, map[string]string{"alg": "RS256", "kid": i.kid}, full, func(digest []byte) []byte {
+		signature, err := rsa.SignPKCS1v15(rand.Reader, i.key, crypto.SHA256, digest)
+		require.NoError(t, err)
+		return signature
+	})
+}
+
+func signJWT(t *testing.T, header map[string]string, c map[string]any, sign func(digest []byte) []byte) string {
+	t.Helper()
+	h, err := json.Marshal(header)
+	require.NoError(t, err)
+	p, err := json.Marshal(c)
+	require.NoError(t, err)
+	signed := base64.RawURLEncoding.EncodeToString(h) + "." + base64.RawURLEncoding.EncodeToString(p)
+	digest := sha256.Sum256([]byte(signed))
+	return signed + "." + base64.RawURLEncoding.EncodeToString(sign(digest[:]))
+}
+
+func TestVerifyJWT_EC(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	keys := keySet{"ec": &key.PublicKey}
+	token := signJWT(t, map[string]string{"alg": "ES256", "kid": "ec"}, map[string]any{"sub": "bob"}, func(digest []byte) []byte {
+		r, s, err := ecdsa.Sign(rand.Reader, key, digest)
+		require.NoError(t, err)
+		signature := make([]byte, 64)
+		r.FillBytes(signature[:32])
+		s.FillBytes(signature[32:])
+		return signature
+	})
+	c, err := verifyJWT(token, keys)
+	require.NoError(t, err)
+	assert.Equal(t, "bob", c.string("sub"))
+
+	parts := strings.Split(token, ".")
+	_, err = verifyJWT(parts[0]+"."+base64.RawURLEncoding.EncodeToString([]byte(`{"sub":"mallory"}`))+"."+parts[2], keys)
+	assert.Error(t, err, "tampered claims")
+
+	none := base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"none","kid":"ec"}`)) + "." + parts[1] + "."
+	_, err = verifyJWT(none, keys)
+	assert.ErrorContains(t, err, "unsupported token algorithm")
+
+	_, err = verifyJWT(signJWT(t, map[string]string{"alg": "ES256", "kid": "other"}, map[string]any{}, func([]byte) []byte { return nil }), keys)
+	assert.ErrorIs(t, err, errUnknownKey)
+}
+
+func TestClaimsValidate(t *testing.T) {
+	now := time.Unix(1_800_000_000, 0)
+	c := claims{"iss": "https://idp", "aud": []any{"argus", "other"}, "exp": float64(now.Unix() + 60)}
+	assert.NoError(t, c.validate("https://idp", "argus", now))
+	assert.ErrorContains(t, c.validate("https://evil", "argus", now), "issued by")
+	assert.ErrorContains(t, c.validate("https://idp", "grafana", now), "audience")
+	assert.NoError(t, c.validate("https://idp", "argus", now.Add(90*time.Second)), "within the clock skew")
+	assert.ErrorContains(t, c.validate("https://idp", "argus", now.Add(3*time.Minute)), "expired")
+	c["nbf"] = float64(now.Unix() + 300)
+	assert.ErrorContains(t, c.validate("https://idp", "argus", now), "not valid yet")
+	delete(c, "exp")
+	assert.ErrorContains(t, c.validate("https://idp", "argus", now), "no expiry")
+}
+
+func TestOIDC_VerifyAccessToken(t *testing.T) {
+	issuer := newTestIssuer(t)
+	provider := NewOIDC(OIDCConfig{Issuer: issuer.server.URL + "/", ClientID: "argus", Audience: "argus-api"})
+	ctx := context.Background()
+
+	identity, err := provider.VerifyAccessToken(ctx, issuer.sign(t, map[string]any{
+		"aud": "argus-api", "email": "alice@example.com", "groups": "ops",
+	}))
+	require.NoError(t, err)
+	assert.Equal(t, "alice@example.com", identity.User, "email without preferred_username")
+	assert.Equal(t, []string{"ops"}, identity.Groups)
+	assert.Equal(t, ProviderOIDC, identity.Provider)
+	assert.False(t, identity.Expires.IsZero())
+
+	_, err = provider.VerifyAccessToken(ctx, issuer.sign(t, map[string]any{"aud": "argus"}))
+	assert.ErrorContains(t, err, "audience", "ID tokens of the dashboard are not API tokens")
+	_, err = provider.VerifyAccessToken(ctx, issuer.sign(t, map[string]any{"aud": "argus-api", "exp": time.Now().Add(-time.Hour).Unix()}))
+	assert.ErrorContains(t, err, "expired")
+
+	// A key rotated in is fetched once the refresh interval passed, then
+	// unknown key IDs are not refetched until it passed again
+	issuer.kid = "k2"
+	_, err = provider.VerifyAccessToken(ctx, issuer.sign(t, map[string]any{"aud": "argus-api"}))
+	assert.ErrorIs(t, err, errUnknownKey)
+	provider.now = func() time.Time { return time.Now().Add(keyRefreshInterval) }
+	_, err = provider.VerifyAccessToken(ctx, issuer.sign(t, map[string]any{"aud": "argus-api"}))
+	require.NoError(t, err)
+	assert.EqualValues(t, 2, issuer.jwksHits.Load())
+	issuer.kid = "k3"
+	_, err = provider.VerifyAccessToken(ctx, issuer.sign(t, map[string]any{"aud": "argus-api"}))
+	assert.ErrorIs(t, err, errUnknownKey)
+	assert.EqualValues(t, 2, issuer.jwksHits.Load())
+}
+
+func TestOIDC_AuthorizationCodeFlow(t *testing.T) {
+	issuer := newTestIssuer(t)
+	provider := NewOIDC(OIDCConfig{
+		Issuer: issuer.server.URL, ClientID: "argus", ClientSecret: "s3cret",
+		RedirectURL: "https://argus.example.com/api/auth/oidc/callback", Scopes: []string{"openid", "profile", "groups"},
+	})
+	ctx := context.Background()
+
+	login, err := provider.AuthCodeURL(ctx, "state-1", "nonce-1")
+	require.NoError(t, err)
+	u, err := url.Parse(login)
+	require.NoError(t, err)
+	assert.Equal(t, issuer.server.URL+"/authorize", u.Scheme+"://"+u.Host+u.Path)
+	assert.Equal(t, "code", u.Query().Get("response_type"))
+	assert.Equal(t, "openid profile groups", u.Query().Get("scope"))
+	assert.Equal(t, "state-1", u.Query().Get("state"))
+	assert.Equal(t, "nonce-1", u.Query().Get("nonce"))
+
+	issuer.nonce = "nonce-1"
+	token, identity, err := provider.Exchange(ctx, "good-code", "nonce-1")
+	require.NoError(t, err)
+	assert.NotEmpty(t, token)
+	assert.Equal(t, "alice", identity.User)
+
+	cookieIdentity, err := provider.VerifyIDToken(ctx, token, "")
+	require.NoError(t, err)
+	assert.Equal(t, "alice", cookieIdentity.User)
+
+	_, _, err = provider.Exchange(ctx, "good-code", "nonce-2")
+	assert.ErrorContains(t, err, "nonce")
+	_, _, err = provider.Exchange(ctx, "bad-code", "nonce-1")
+	assert.ErrorContains(t, err, "invalid_grant")
+}
+
+func TestOIDC_DiscoveryIssuerMismatch(t *testing.T) {
+	issuer := newTestIssuer(t)
+	provider := NewOIDC(OIDCConfig{Issuer: issuer.server.URL + "/realms/other", ClientID: "argus"})
+	_, err := provider.AuthCodeURL(context.Background(), "s", "n")
+	assert.Error(t, err)
+}
@@ -0,0 +1,299 @@
+// File: internal/auth/session.go
+// Brief: Login sessions of the dashboard, with their cookies and CSRF tokens
+// Detailed: Issues a session when a user signs in to the dashboard and keeps it in memory until it expires, sits idle too long or is revoked. The browser holds a session token in an HttpOnly cookie, whose path, domain, Secure and SameSite attributes fit deployments behind a reverse proxy: the session's random ID signed with HMAC-SHA256 by a key generated at startup, so tokens cannot be forged from a listed ID and tokens of an earlier run are rejected without a lookup. Each session has a CSRF token that mutating requests authenticated by the cookie must echo in a header, which a cross-site page cannot read. Sessions are lost on a restart.
+// Author: drama.lin@aver.com
+// Date: 2026-10-14
+
+package auth
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// CSRFHeader is the header mutating requests authenticated by a session
+// cookie carry the session's CSRF token in
+const CSRFHeader = "X-CSRF-Token"
+
+// Defaults of SessionConfig
+const (
+	DefaultSessionCookie      = "argus_session"
+	DefaultSessionTTL         = 12 * time.Hour
+	DefaultSessionIdleTimeout = time.Hour
+)
+
+// Secure cookie modes
+const (
+	SecureAuto   = "auto"   // Secure when the request came over HTTPS, directly or through a proxy
+	SecureAlways = "always" // E.g. behind a proxy terminating TLS that sets no X-Forwarded-Proto
+	SecureNever  = "never"  // Plain HTTP test setups only
+)
+
+// ErrInvalidCSRFToken is returned when a mutating request authenticated by a
+// session cookie lacks the session's CSRF token
+var ErrInvalidCSRFToken = errors.New("missing or invalid CSRF token")
+
+// SessionConfig configures the sessions and their cookie
+type SessionConfig struct {
+	TTL          time.Duration // Lifetime from sign-in; zero means DefaultSessionTTL
+	IdleTimeout  time.Duration // Ends sessions unused as long; zero means DefaultSessionIdleTimeout, negative never
+	CookieName   string        // Zero means DefaultSessionCookie
+	CookiePath   string        // Path Argus is served under by the proxy; zero means /
+	CookieDomain string        // Empty scopes the cookie to the host
+	Secure       string        // SecureAuto, SecureAlways or SecureNever; zero means SecureAuto
+	SameSite     http.SameSite // Zero means http.SameSiteLaxMode, which lets the login redirect back in
+}
+
+// Session is a dashboard login
+type Session struct {
+	ID        string    `json:"id"` // Public ID to list and revoke the session by, not the cookie token
+	User      string    `json:"user"`
+	Groups    []string  `json:"groups,omitempty"`
+	Role      Role      `json:"role"`
+	Provider  string    `json:"provider"`
+	CreatedAt time.Time `json:"created_at"`
+	LastSeen  time.Time `json:"last_seen"`
+	ExpiresAt time.Time `json:"expires_at"`
+	ClientIP  string    `json:"client_ip,omitempty"`
+	UserAgent string    `json:"user_agent,omitempty"`
+
+	CSRFToken string `json:"-"` // Echoed in CSRFHeader by mutating requests
+}
+
+// Sessions holds the active sessions
+type Sessions struct {
+	config SessionConfig
+	key    []byte // Signs the session tokens
+
+	mu       sync.Mutex
+	sessions map[string]*Session // By ID
+	now      func() time.Time
+}
+
+// NewSessions creates an empty session store with a new signing key
+func NewSessions(config SessionConfig) *Sessions {
+	if config.TTL <= 0 {
+		config.TTL = DefaultSessionTTL
+	}
+	if config.IdleTimeout == 0 {
+		config.IdleTimeout = DefaultSessionIdleTimeout
+	}
+	if config.CookieName == "" {
+		config.CookieName = DefaultSessionCookie
+	}
+	if config.CookiePath == "" {
+		config.CookiePath = "/"
+	}
+	if config.Secure == "" {
+		config.Secure = SecureAuto
+	}
+	if config.SameSite == 0 {
+		config.SameSite = http.SameSiteLaxMode
+	}
+	key := make([]byte, sha256.Size)
+	if _, err := rand.Read(key); err != nil {
+		panic("auth: no randomness for the session key: " + err.Error())
+	}
+	return &Sessions{config: config, key: key, sessions: make(map[string]*Session), now: time.Now}
+}
+
+// CookieName returns the name of the session cookie
+func (s *Sessions) CookieName() string {
+	return s.config.CookieName
+}
+
+// Create starts a session for identity and returns the signed token of its
+// cookie with the session
+func (s *Sessions) Create(identity *Identity, clientIP, userAgent string) (string, Session, error) {
+	id, err := randomString(16)
+	if err != nil {
+		return "", Session{}, err
+	}
+	csrf, err := randomString(32)
+	if err != nil {
+		return "", Session{}, err
+	}
+	now := s.now()
+	session := &Session{
+		ID:        id,
+		User:      identity.User,
+		Groups:    identity.Groups,
+		Role:      identity.Role,
+		Provider:  identity.Provider,
+		CreatedAt: now,
+		LastSeen:  now,
+		ExpiresAt: now.Add(s.config.TTL),
+		ClientIP:  clientIP,
+		UserAgent: userAgent,
+		CSRFToken: csrf,
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.purge(now)
+	s.sessions[session.ID] = session
+	return session.ID + "." + s.sign(session.ID), *session, nil
+}
+
+// Lookup returns the active session of a cookie token and marks it used
+func (s *Sessions) Lookup(token string) (Session, bool) {
+	id, signature, ok := strings.Cut(token, ".")
+	if !ok || !hmac.Equal([]byte(signature), []byte(s.sign(id))) {
+		return Session{}, false
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	session, ok := s.sessions[id]
+	if !ok {
+		return Session{}, false
+	}
+	now := s.now()
+	if s.expired(session, now) {
+		s.remove(session)
+		return Session{}, false
+	}
+	session.LastSeen = now
+	return *session, true
+}
+
+// CheckCSRF checks the CSRF token sent with a request of session id
+func (s *Sessions) CheckCSRF(id, token string) error {
+	session, ok := s.Get(id)
+	if !ok || token == "" || subtle.ConstantTimeCompare([]byte(token), []byte(session.CSRFToken)) != 1 {
+		return ErrInvalidCSRFToken
+	}
+	return nil
+}
+
+// List returns the active sessions of user, or of every user for "", oldest
+// first
+func (s *Sessions) List(user string) []Session {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.purge(s.now())
+	sessions := make([]Session, 0, len(s.sessions))
+	for _, session := range s.sessions {
+		if user == "" || session.User == user {
+			sessions = append(sessions, *session)
+		}
+	}
+	sort.Slice(sessions, func(i, j int) bool { return sessions[i].CreatedAt.Before(sessions[j].CreatedAt) })
+	return sessions
+}
+
+// Get returns the active session id
+func (s *Sessions) Get(id string) (Session, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	session, ok := s.sessions[id]
+	if !ok || s.expired(session, s.now()) {
+		return Session{}, false
+	}
+	return *session, true
+}
+
+// Revoke ends session id, reporting whether it was active
+func (s *Sessions) Revoke(id string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	session, ok := s.sessions[id]
+	if ok {
+		s.remove(session)
+	}
+	return ok
+}
+
+// RevokeUser ends every session of user and returns how many were active
+func (s *Sessions) RevokeUser(user string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	revoked := 0
+	for _, session := range s.sessions {
+		if session.User == user {
+			s.remove(session)
+			revoked++
+		}
+	}
+	return revoked
+}
+
+// purge drops the expired sessions; s.mu must be held
+func (s *Sessions) purge(now time.Time) {
+	for _, session := range s.sessions {
+		if s.expired(session, now) {
+			s.remove(session)
+		}
+	}
+}
+
+// remove drops a session; s.mu must be held
+func (s *Sessions) remove(session *Session) {
+	delete(s.sessions, session.ID)
+}
+
+func (s *Sessions) expired(session *Session, now time.Time) bool {
+	if !now.Before(session.ExpiresAt) {
+		return true
+	}
+	return s.config.IdleTimeout > 0 && now.Sub(session.LastSeen) >= s.config.IdleTimeout
+}
+
+// sign returns the signature of session id in its token
+func (s *Sessions) sign(id string) string {
+	mac := hmac.New(sha256.New, s.key)
+	mac.Write([]byte(id))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// Cookie returns a cookie with the attributes of the session cookie, named
+// name under path (relative to the cookie path) and deleted for a negative
+// maxAge
+func (s *Sessions) Cookie(r *http.Request, name, path, value string, maxAge int) *http.Cookie {
+	return &http.Cookie{
+		Name:     name,
+		Value:    value,
+		Path:     strings.TrimSuffix(s.config.CookiePath, "/") + path,
+		Domain:   s.config.CookieDomain,
+		MaxAge:   maxAge,
+		Secure:   s.secure(r),
+		HttpOnly: true,
+		SameSite: s.config.SameSite,
+	}
+}
+
+// SessionCookie returns the session cookie of token, or the cookie deleting
+// it for an empty token
+func (s *Sessions) SessionCookie(r *http.Request, token string) *http.Cookie {
+	if token == "" {
+		return s.Cookie(r, s.config.CookieName, "/", "", -1)
+	}
+	return s.Cookie(r, s.config.CookieName, "/", token, int(s.config.TTL.Seconds()))
+}
+
+// secure reports whether cookies set for the request are marked Secure
+func (s *Sessions) secure(r *http.Request) bool {
+	switch s.config.Secure {
+	case SecureAlways:
+		return true
+	case SecureNever:
+		return false
+	}
+	return r.TLS != nil || strings.EqualFold(r.Header.Get("X-Forwarded-Proto"), "https")
+}
+
+// randomString returns n random bytes, URL-safe base64 encoded
+func randomString(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
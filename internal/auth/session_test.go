@@ -0,0 +1,124 @@
+package auth
+
+import (
+	"crypto/tls"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSessions(t *testing.T) {
+	now := time.Unix(1_800_000_000, 0)
+	sessions := NewSessions(SessionConfig{TTL: 8 * time.Hour, IdleTimeout: 30 * time.Minute})
+	sessions.now = func() time.Time { return now }
+
+	token, session, err := sessions.Create(&Identity{User: "alice", Role: RoleOperator, Provider: ProviderLDAP}, "10.0.0.1", "curl")
+	require.NoError(t, err)
+	assert.NotEqual(t, token, session.ID, "the public ID is not the cookie token")
+	assert.NotEmpty(t, session.CSRFToken)
+	assert.Equal(t, now.Add(8*time.Hour), session.ExpiresAt)
+
+	now = now.Add(20 * time.Minute)
+	found, ok := sessions.Lookup(token)
+	require.True(t, ok)
+	assert.Equal(t, "alice", found.User)
+	assert.Equal(t, now, found.LastSeen)
+	_, ok = sessions.Lookup(session.ID)
+	assert.False(t, ok, "the public ID does not authenticate")
+	_, ok = sessions.Lookup(session.ID + ".forged")
+	assert.False(t, ok, "the signature is checked")
+	_, ok = NewSessions(SessionConfig{}).Lookup(token)
+	assert.False(t, ok, "signed by another key, e.g. before a restart")
+
+	now = now.Add(29 * time.Minute)
+	_, ok = sessions.Lookup(token)
+	assert.True(t, ok, "used within the idle timeout")
+	now = now.Add(30 * time.Minute)
+	_, ok = sessions.Lookup(token)
+	assert.False(t, ok, "idle too long")
+	assert.Empty(t, sessions.List(""))
+
+	token, _, err = sessions.Create(&Identity{User: "alice"}, "", "")
+	require.NoError(t, err)
+	_, bob, err := sessions.Create(&Identity{User: "bob"}, "", "")
+	require.NoError(t, err)
+	for i := 0; i < 17; i++ {
+		now = now.Add(29 * time.Minute)
+		sessions.Lookup(token)
+	}
+	_, ok = sessions.Lookup(token)
+	assert.False(t, ok, "expired after its TTL however active")
+	assert.Empty(t, sessions.List("alice"))
+	assert.Empty(t, sessions.List(""), "bob's idle session is gone too")
+	assert.False(t, sessions.Revoke(bob.ID))
+
+	_, first, err := sessions.Create(&Identity{User: "carol"}, "", "")
+	require.NoError(t, err)
+	now = now.Add(time.Second)
+	_, second, err := sessions.Create(&Identity{User: "carol"}, "", "")
+	require.NoError(t, err)
+	listed := sessions.List("carol")
+	require.Len(t, listed, 2)
+	assert.Equal(t, []string{first.ID, second.ID}, []string{listed[0].ID, listed[1].ID}, "oldest first")
+	assert.True(t, sessions.Revoke(first.ID))
+	assert.Len(t, sessions.List("carol"), 1)
+	assert.Equal(t, 1, sessions.RevokeUser("carol"))
+}
+
+func TestSessions_CheckCSRF(t *testing.T) {
+	sessions := NewSessions(SessionConfig{})
+	a := &Authenticator{Sessions: sessions}
+	_, session, err := sessions.Create(&Identity{User: "alice"}, "", "")
+	require.NoError(t, err)
+	identity := &Identity{User: "alice", SessionID: session.ID}
+
+	request := func(method, csrf string) *http.Request {
+		r, err := http.NewRequest(method, "/api/tasks/backup/run", nil)
+		require.NoError(t, err)
+		if csrf != "" {
+			r.Header.Set(CSRFHeader, csrf)
+		}
+		return r
+	}
+	assert.NoError(t, a.CheckCSRF(request(http.MethodGet, ""), identity))
+	assert.NoError(t, a.CheckCSRF(request(http.MethodPost, session.CSRFToken), identity))
+	assert.ErrorIs(t, a.CheckCSRF(request(http.MethodPost, ""), identity), ErrInvalidCSRFToken)
+	assert.ErrorIs(t, a.CheckCSRF(request(http.MethodDelete, "guess"), identity), ErrInvalidCSRFToken)
+	assert.NoError(t, a.CheckCSRF(request(http.MethodPost, ""), &Identity{User: "alice"}), "credentials sent with the request")
+
+	sessions.Revoke(session.ID)
+	assert.ErrorIs(t, a.CheckCSRF(request(http.MethodPost, session.CSRFToken), identity), ErrInvalidCSRFToken)
+}
+
+func TestSessions_Cookie(t *testing.T) {
+	r, err := http.NewRequest(http.MethodGet, "/api/auth/session", nil)
+	require.NoError(t, err)
+
+	cookie := NewSessions(SessionConfig{}).SessionCookie(r, "token")
+	assert.Equal(t, DefaultSessionCookie, cookie.Name)
+	assert.Equal(t, "/", cookie.Path)
+	assert.True(t, cookie.HttpOnly)
+	assert.False(t, cookie.Secure, "plain HTTP")
+	assert.Equal(t, http.SameSiteLaxMode, cookie.SameSite)
+	assert.Equal(t, int(DefaultSessionTTL.Seconds()), cookie.MaxAge)
+
+	r.Header.Set("X-Forwarded-Proto", "https")
+	assert.True(t, NewSessions(SessionConfig{}).SessionCookie(r, "token").Secure, "HTTPS at the proxy")
+	r.Header.Del("X-Forwarded-Proto")
+	r.TLS = &tls.ConnectionState{}
+	assert.True(t, NewSessions(SessionConfig{}).SessionCookie(r, "token").Secure)
+	assert.False(t, NewSessions(SessionConfig{Secure: SecureNever}).SessionCookie(r, "token").Secure)
+
+	proxied := NewSessions(SessionConfig{CookieName: "argus", CookiePath: "/argus/", CookieDomain: "example.com", SameSite: http.SameSiteStrictMode})
+	cookie = proxied.Cookie(r, "state", "/api/auth/oidc", "s", 600)
+	assert.Equal(t, "/argus/api/auth/oidc", cookie.Path)
+	assert.Equal(t, "example.com", cookie.Domain)
+	assert.Equal(t, http.SameSiteStrictMode, cookie.SameSite)
+	deleted := proxied.SessionCookie(r, "")
+	assert.Equal(t, "argus", deleted.Name)
+	assert.Equal(t, "/argus/", deleted.Path)
+	assert.Negative(t, deleted.MaxAge)
+}
@@ -0,0 +1,38 @@
+package badge
+
+import (
+	"encoding/xml"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBadge_SVG(t *testing.T) {
+	svg := string(Badge{Label: "alerts", Message: "2 critical", Color: ColorRed}.SVG())
+
+	assert.True(t, strings.HasPrefix(svg, `<svg xmlns="http://www.w3.org/2000/svg"`))
+	assert.Contains(t, svg, `aria-label="alerts: 2 critical"`)
+	assert.Contains(t, svg, `fill="#e05d44"`)
+	assert.Contains(t, svg, ">2 critical</text>")
+
+	// The output is well-formed XML
+	require.NoError(t, xml.Unmarshal([]byte(svg), new(struct{})))
+}
+
+func TestBadge_EscapesAndBoundsText(t *testing.T) {
+	svg := string(Badge{Label: `<script>alert("x")</script>`, Message: strings.Repeat("w", 500), Color: `"><script>`}.SVG())
+
+	assert.NotContains(t, svg, "<script>")
+	require.NoError(t, xml.Unmarshal([]byte(svg), new(struct{})))
+	assert.Less(t, len(svg), 3000)
+
+	// A missing color falls back to grey
+	assert.Contains(t, string(Badge{Label: "alerts", Message: "unknown"}.SVG()), ColorGrey)
+}
+
+func TestTextWidth(t *testing.T) {
+	assert.Less(t, textWidth("ill"), textWidth("www"))
+	assert.Zero(t, textWidth(""))
+}
@@ -0,0 +1,202 @@
+// File: internal/clock/clock.go
+// Brief: Time source abstraction for schedulers, evaluators and rate limiters
+// Detailed: Provides the Clock interface with a real implementation backed by package time and a Fake that tests advance by hand, so timing logic can be exercised without sleeping.
+// Author: drama.lin@aver.com
+// Date: 2026-10-14
+
+// Package clock abstracts the current time and timers so they can be faked in tests.
+package clock
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// Clock is a source of the current time, tickers and one-shot timers.
+type Clock interface {
+	Now() time.Time
+	NewTicker(d time.Duration) Ticker
+	After(d time.Duration) <-chan time.Time
+}
+
+// Ticker delivers ticks on C until it is stopped.
+type Ticker interface {
+	C() <-chan time.Time
+	Stop()
+}
+
+// Real is the Clock backed by package time.
+var Real Clock = realClock{}
+
+// OrReal returns c, or Real when c is nil, so components can take an optional Clock.
+func OrReal(c Clock) Clock {
+	if c == nil {
+		return Real
+	}
+	return c
+}
+
+type realClock struct{}
+
+func (realClock) Now() time.Time                         { return time.Now() }
+func (realClock) After(d time.Duration) <-chan time.Time { return time.After(d) }
+func (realClock) NewTicker(d time.Duration) Ticker       { return realTicker{time.NewTicker(d)} }
+
+type realTicker struct{ t *time.Ticker }
+
+func (t realTicker) C() <-chan time.Time { return t.t.C }
+func (t realTicker) Stop()               { t.t.Stop() }
+
+// Fake is a Clock whose time only moves when Advance or Set is called. Tickers
+// and timers fire synchronously during Advance, in deadline order. Like
+// time.Ticker, a fake ticker drops ticks its reader has not kept up with.
+type Fake struct {
+	mu      sync.Mutex
+	now     time.Time
+	waiters []*fakeWaiter
+	added   chan struct{} // Signalled whenever a ticker or timer is created
+}
+
+type fakeWaiter struct {
+	deadline time.Time
+	period   time.Duration // Zero for one-shot timers
+	ch       chan time.Time
+	stopped  bool
+}
+
+// NewFake returns a Fake clock set to start.
+func NewFake(start time.Time) *Fake {
+	return &Fake{now: start, added: make(chan struct{}, 1)}
+}
+
+// Now returns the fake current time.
+func (f *Fake) Now() time.Time {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.now
+}
+
+// After returns a channel that receives the fake time once d has been advanced.
+func (f *Fake) After(d time.Duration) <-chan time.Time {
+	return f.add(d, 0).ch
+}
+
+// NewTicker returns a ticker firing every d of fake time. d must be positive.
+func (f *Fake) NewTicker(d time.Duration) Ticker {
+	if d <= 0 {
+		panic("clock: non-positive interval for NewTicker")
+	}
+	return &fakeTicker{clock: f, w: f.add(d, d)}
+}
+
+func (f *Fake) add(d, period time.Duration) *fakeWaiter {
+	f.mu.Lock()
+	w := &fakeWaiter{deadline: f.now.Add(d), period: period, ch: make(chan time.Time, 1)}
+	if d <= 0 {
+		w.ch <- f.now
+		f.mu.Unlock()
+		return w
+	}
+	f.waiters = append(f.waiters, w)
+	f.mu.Unlock()
+
+	select {
+	case f.added <- struct{}{}:
+	default:
+	}
+	return w
+}
+
+// Advance moves the clock forward by d, firing every ticker and timer whose
+// deadline is reached along the way.
+func (f *Fake) Advance(d time.Duration) {
+	f.Set(f.Now().Add(d))
+}
+
+// Set moves the clock to t (never backwards), firing due tickers and timers.
+func (f *Fake) Set(t time.Time) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if t.Before(f.now) {
+		return
+	}
+	for {
+		next := f.nextDue(t)
+		if next == nil {
+			break
+		}
+		f.now = next.deadline
+		select {
+		case next.ch <- f.now:
+		default: // Reader is behind; drop the tick
+		}
+		if next.period > 0 {
+			next.deadline = next.deadline.Add(next.period)
+		} else {
+			next.stopped = true
+		}
+	}
+	f.now = t
+	f.prune()
+}
+
+// nextDue returns the waiter with the earliest deadline not after t
+func (f *Fake) nextDue(t time.Time) *fakeWaiter {
+	var due []*fakeWaiter
+	for _, w := range f.waiters {
+		if !w.stopped && !w.deadline.After(t) {
+			due = append(due, w)
+		}
+	}
+	if len(due) == 0 {
+		return nil
+	}
+	sort.SliceStable(due, func(i, j int) bool { return due[i].deadline.Before(due[j].deadline) })
+	return due[0]
+}
+
+func (f *Fake) prune() {
+	live := f.waiters[:0]
+	for _, w := range f.waiters {
+		if !w.stopped {
+			live = append(live, w)
+		}
+	}
+	f.waiters = live
+}
+
+// Waiters returns the number of active tickers and pending timers.
+func (f *Fake) Waiters() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	n := 0
+	for _, w := range f.waiters {
+		if !w.stopped {
+			n++
+		}
+	}
+	return n
+}
+
+// BlockUntil waits until at least n tickers or timers are active, so a test
+// can be sure a goroutine has armed its timer before advancing the clock.
+func (f *Fake) BlockUntil(n int) {
+	for f.Waiters() < n {
+		<-f.added
+	}
+}
+
+type fakeTicker struct {
+	clock *Fake
+	w     *fakeWaiter
+}
+
+func (t *fakeTicker) C() <-chan time.Time { return t.w.ch }
+
+func (t *fakeTicker) Stop() {
+	t.clock.mu.Lock()
+	defer t.clock.mu.Unlock()
+	t.w.stopped = true
+	t.clock.prune()
+}
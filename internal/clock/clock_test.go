@@ -0,0 +1,76 @@
+package clock
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var epoch = time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+func received(ch <-chan time.Time) (time.Time, bool) {
+	select {
+	case t := <-ch:
+		return t, true
+	default:
+		return time.Time{}, false
+	}
+}
+
+func TestFakeAfter(t *testing.T) {
+	f := NewFake(epoch)
+	ch := f.After(10 * time.Second)
+
+	f.Advance(9 * time.Second)
+	_, ok := received(ch)
+	assert.False(t, ok)
+
+	f.Advance(time.Second)
+	got, ok := received(ch)
+	assert.True(t, ok)
+	assert.Equal(t, epoch.Add(10*time.Second), got)
+	assert.Equal(t, 0, f.Waiters(), "fired timers are removed")
+}
+
+func TestFakeTicker(t *testing.T) {
+	f := NewFake(epoch)
+	ticker := f.NewTicker(time.Minute)
+
+	f.Advance(time.Minute)
+	got, ok := received(ticker.C())
+	assert.True(t, ok)
+	assert.Equal(t, epoch.Add(time.Minute), got)
+
+	// Ticks the reader missed are dropped, like time.Ticker
+	f.Advance(3 * time.Minute)
+	got, ok = received(ticker.C())
+	assert.True(t, ok)
+	assert.Equal(t, epoch.Add(2*time.Minute), got)
+	_, ok = received(ticker.C())
+	assert.False(t, ok)
+	assert.Equal(t, epoch.Add(4*time.Minute), f.Now())
+
+	ticker.Stop()
+	f.Advance(time.Hour)
+	_, ok = received(ticker.C())
+	assert.False(t, ok)
+}
+
+func TestFakeBlockUntil(t *testing.T) {
+	f := NewFake(epoch)
+	done := make(chan time.Time)
+	go func() {
+		done <- <-f.After(time.Second)
+	}()
+
+	f.BlockUntil(1)
+	f.Advance(time.Second)
+	assert.Equal(t, epoch.Add(time.Second), <-done)
+}
+
+func TestOrReal(t *testing.T) {
+	assert.Equal(t, Real, OrReal(nil))
+	f := NewFake(epoch)
+	assert.Equal(t, Clock(f), OrReal(f))
+}
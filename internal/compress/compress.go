@@ -0,0 +1,133 @@
+// File: internal/compress/compress.go
+// Brief: Transparent compression for stored JSON blobs
+// Detailed: Encodes stored records with a configurable algorithm and decodes any record by its magic bytes, so files written before compression was enabled stay readable.
+// Author: drama.lin@aver.com
+// Date: 2026-10-14
+
+// Package compress compresses stored records and detects the format of existing ones.
+package compress
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// Algorithm names a compression format used for stored records.
+type Algorithm string
+
+const (
+	None Algorithm = "none"
+	Gzip Algorithm = "gzip"
+	Zstd Algorithm = "zstd" // Recognized by its magic bytes; encoding/decoding is not built in
+)
+
+var (
+	gzipMagic = []byte{0x1f, 0x8b}
+	zstdMagic = []byte{0x28, 0xb5, 0x2f, 0xfd}
+)
+
+// ErrUnsupported is returned for algorithms this build cannot encode or decode.
+var ErrUnsupported = errors.New("unsupported compression algorithm")
+
+// Parse validates a configured algorithm name. An empty name means None.
+func Parse(name string) (Algorithm, error) {
+	switch Algorithm(name) {
+	case "", None:
+		return None, nil
+	case Gzip:
+		return Gzip, nil
+	case Zstd:
+		return "", fmt.Errorf("%w: zstd is not available in this build, use gzip", ErrUnsupported)
+	}
+	return "", fmt.Errorf("%w: %q (supported: none, gzip)", ErrUnsupported, name)
+}
+
+// Detect reports the format of a stored record from its leading bytes.
+func Detect(data []byte) Algorithm {
+	switch {
+	case bytes.HasPrefix(data, gzipMagic):
+		return Gzip
+	case bytes.HasPrefix(data, zstdMagic):
+		return Zstd
+	}
+	return None
+}
+
+// Encode compresses data with alg. None returns data unchanged.
+func Encode(alg Algorithm, data []byte) ([]byte, error) {
+	switch alg {
+	case "", None:
+		return data, nil
+	case Gzip:
+		var buf bytes.Buffer
+		zw := gzip.NewWriter(&buf)
+		if _, err := zw.Write(data); err != nil {
+			return nil, fmt.Errorf("gzip encode: %w", err)
+		}
+		if err := zw.Close(); err != nil {
+			return nil, fmt.Errorf("gzip encode: %w", err)
+		}
+		return buf.Bytes(), nil
+	}
+	return nil, fmt.Errorf("%w: %s", ErrUnsupported, alg)
+}
+
+// Decode returns the uncompressed content of a stored record, whatever
+// format it was written in. Uncompressed records are returned unchanged.
+func Decode(data []byte) ([]byte, error) {
+	switch Detect(data) {
+	case Gzip:
+		zr, err := gzip.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, fmt.Errorf("gzip decode: %w", err)
+		}
+		defer zr.Close()
+		out, err := io.ReadAll(zr)
+		if err != nil {
+			return nil, fmt.Errorf("gzip decode: %w", err)
+		}
+		return out, nil
+	case Zstd:
+		return nil, fmt.Errorf("%w: zstd", ErrUnsupported)
+	}
+	return data, nil
+}
+
+// DecodeStream decodes an append-only file made of plain lines and
+// individually compressed members in any order, as produced by appending
+// records before and after compression was enabled. Decoding stops at a
+// truncated trailing member, returning everything read before it.
+func DecodeStream(data []byte) ([]byte, error) {
+	var out bytes.Buffer
+	for len(data) > 0 {
+		switch Detect(data) {
+		case Gzip:
+			r := bytes.NewReader(data)
+			zr, err := gzip.NewReader(r)
+			if err != nil {
+				return out.Bytes(), nil
+			}
+			// bytes.Reader is an io.ByteReader, so the decoder stops exactly at the member end
+			zr.Multistream(false)
+			member, err := io.ReadAll(zr)
+			if err != nil {
+				return out.Bytes(), nil
+			}
+			out.Write(member)
+			data = data[len(data)-r.Len():]
+		case Zstd:
+			return nil, fmt.Errorf("%w: zstd", ErrUnsupported)
+		default:
+			line := data
+			if i := bytes.IndexByte(data, '\n'); i >= 0 {
+				line = data[:i+1]
+			}
+			out.Write(line)
+			data = data[len(line):]
+		}
+	}
+	return out.Bytes(), nil
+}
@@ -0,0 +1,66 @@
+package compress
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	alg, err := Parse("")
+	require.NoError(t, err)
+	assert.Equal(t, None, alg)
+
+	alg, err = Parse("gzip")
+	require.NoError(t, err)
+	assert.Equal(t, Gzip, alg)
+
+	_, err = Parse("zstd")
+	assert.ErrorIs(t, err, ErrUnsupported)
+	_, err = Parse("lz4")
+	assert.ErrorIs(t, err, ErrUnsupported)
+}
+
+func TestEncodeDecodeRoundTrip(t *testing.T) {
+	data := bytes.Repeat([]byte(`{"output":"rotated /var/log/app.log"}`), 100)
+
+	encoded, err := Encode(Gzip, data)
+	require.NoError(t, err)
+	assert.Equal(t, Gzip, Detect(encoded))
+	assert.Less(t, len(encoded), len(data))
+
+	decoded, err := Decode(encoded)
+	require.NoError(t, err)
+	assert.Equal(t, data, decoded)
+
+	// Records written before compression was enabled are returned as-is
+	plain, err := Decode(data)
+	require.NoError(t, err)
+	assert.Equal(t, data, plain)
+
+	_, err = Decode([]byte{0x28, 0xb5, 0x2f, 0xfd, 0x00})
+	assert.ErrorIs(t, err, ErrUnsupported)
+}
+
+func TestDecodeStreamMixed(t *testing.T) {
+	var file []byte
+	file = append(file, "{\"n\":1}\n"...)
+	member, err := Encode(Gzip, []byte("{\"n\":2}\n"))
+	require.NoError(t, err)
+	file = append(file, member...)
+	file = append(file, "{\"n\":3}\n"...)
+	member, err = Encode(Gzip, []byte("{\"n\":4}\n"))
+	require.NoError(t, err)
+	file = append(file, member...)
+
+	out, err := DecodeStream(file)
+	require.NoError(t, err)
+	assert.Equal(t, "{\"n\":1}\n{\"n\":2}\n{\"n\":3}\n{\"n\":4}\n", string(out))
+
+	// A truncated trailing member is dropped rather than failing the read
+	out, err = DecodeStream(file[:len(file)-3])
+	require.NoError(t, err)
+	assert.Equal(t, "{\"n\":1}\n{\"n\":2}\n{\"n\":3}\n", string(out))
+}
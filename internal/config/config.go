@@ -1,21 +1,220 @@
 package config
 
 import (
+	"crypto/tls"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"net/http"
+	"net/url"
 	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
 	"time"
 
 	"gopkg.in/yaml.v3"
+
+	"argus/internal/auth"
+	"argus/internal/compress"
+	"argus/internal/features"
+	"argus/internal/i18n"
+	"argus/internal/logwatch"
+	"argus/internal/metrics"
+	"argus/internal/models"
+	"argus/internal/netproxy"
+	"argus/internal/redact"
+	"argus/internal/retention"
+	"argus/internal/statuspage"
+	"argus/internal/tlsclient"
+	"argus/internal/tmplfunc"
 )
 
+// QueueConfig sizes a bounded queue and sets what happens when it is full
+type QueueConfig struct {
+	Size         int    `yaml:"size"`
+	Overflow     string `yaml:"overflow"`      // drop-oldest, drop-newest or block
+	BlockTimeout string `yaml:"block_timeout"` // How long "block" waits for room before dropping
+}
+
+// WebhookConfig configures the on-disk queue behind the webhook notification channel
+type WebhookConfig struct {
+	QueuePath     string `yaml:"queue_path"`     // Queue directory (empty = webhook-queue under alerts storage_path)
+	QueueSize     int    `yaml:"queue_size"`     // Pending deliveries kept; the oldest are dropped beyond it
+	Retention     string `yaml:"retention"`      // Pending deliveries older than this are dropped
+	RetryInterval string `yaml:"retry_interval"` // Wait before retrying a failing URL
+	Timeout       string `yaml:"timeout"`        // Per delivery request
+}
+
+// JiraConfig connects the jira notification channel, which opens an issue
+// for critical alerts and comments on it when they resolve
+type JiraConfig struct {
+	URL               string `yaml:"url"`                // Base URL, e.g. https://example.atlassian.net (empty disables the channel)
+	User              string `yaml:"user"`               // Account email of the API token (empty sends it as a bearer token)
+	APIToken          string `yaml:"api_token"`          // Prefer api_token_file or ARGUS_ALERTS_JIRA_API_TOKEN
+	APITokenFile      string `yaml:"api_token_file"`     // File holding the API token, e.g. a mounted secret
+	Project           string `yaml:"project"`            // Project key of alerts not setting one
+	IssueType         string `yaml:"issue_type"`         // Issue type of alerts not setting one (empty = Task)
+	ResolveTransition string `yaml:"resolve_transition"` // Transition applied on resolution, e.g. Done (empty only comments)
+	Timeout           string `yaml:"timeout"`            // Per request
+}
+
+// RemediationConfig switches the remediation tasks alerts run when they activate
+type RemediationConfig struct {
+	Enabled bool `yaml:"enabled"` // false audits every remediation as skipped
+}
+
+// TaskThrottleConfig defers non-critical scheduled tasks while the host is busy
+type TaskThrottleConfig struct {
+	CPUPercent    float64 `yaml:"cpu_percent"`    // Defer while CPU usage is above (0 disables)
+	IOWaitPercent float64 `yaml:"iowait_percent"` // Defer while CPU time waiting on IO is above (0 disables)
+	MaxDefer      string  `yaml:"max_defer"`      // Run anyway after being deferred this long (empty = 1h, 0 = until the load drops)
+}
+
+// TemplateSandboxConfig limits the execution of user-provided notification
+// templates
+type TemplateSandboxConfig struct {
+	Timeout        string `yaml:"timeout"`          // Per rendered subject or body (empty = 1s)
+	MaxOutputBytes int    `yaml:"max_output_bytes"` // Per rendered subject or body (0 = 64 KiB)
+}
+
+// ProxyConfig routes outbound HTTP requests through a proxy
+type ProxyConfig struct {
+	URL     string   `yaml:"url"`      // http, https, socks5 or socks5h URL; "none" connects directly; empty uses HTTP_PROXY, HTTPS_PROXY and NO_PROXY
+	NoProxy []string `yaml:"no_proxy"` // Hosts (with subdomains), .domains, IPs and CIDR ranges reached directly
+}
+
+// TLSConfig holds the CA bundle and client certificate of outbound HTTPS requests
+type TLSConfig struct {
+	CAFile   string `yaml:"ca_file"`   // PEM CAs trusted in addition to the system pool
+	CertFile string `yaml:"cert_file"` // PEM client certificate presented to servers requesting one (mTLS)
+	KeyFile  string `yaml:"key_file"`  // PEM private key of cert_file
+}
+
+// OIDCConfig configures the login with an OpenID Connect identity provider
+type OIDCConfig struct {
+	Enabled      bool     `yaml:"enabled"`
+	Issuer       string   `yaml:"issuer"`        // Issuer URL, e.g. https://idp.example.com/realms/ops
+	ClientID     string   `yaml:"client_id"`     // Client of the dashboard login
+	ClientSecret string   `yaml:"client_secret"` // Prefer ARGUS_AUTH_OIDC_CLIENT_SECRET; empty for public clients
+	RedirectURL  string   `yaml:"redirect_url"`  // https://<argus>/api/auth/oidc/callback, registered with the provider
+	Scopes       []string `yaml:"scopes"`        // Requested besides openid, e.g. profile, email, groups
+	Audience     string   `yaml:"audience"`      // Audience of API access tokens (empty = client_id)
+	UserClaim    string   `yaml:"user_claim"`    // Claim naming the user (empty = preferred_username, email, then sub)
+	GroupsClaim  string   `yaml:"groups_claim"`  // Claim listing the groups (empty = groups)
+}
+
+// LDAPConfig configures the login with an LDAP or Active Directory password
+type LDAPConfig struct {
+	Enabled        bool   `yaml:"enabled"`
+	URL            string `yaml:"url"`             // ldap://host:389 or ldaps://host:636
+	BindDN         string `yaml:"bind_dn"`         // Service account searching for users (empty binds as user_attribute=<user>,base_dn)
+	BindPassword   string `yaml:"bind_password"`   // Prefer ARGUS_AUTH_LDAP_BIND_PASSWORD
+	BaseDN         string `yaml:"base_dn"`         // Base of the user search
+	UserAttribute  string `yaml:"user_attribute"`  // Attribute holding the user name (empty = uid; sAMAccountName on Active Directory)
+	GroupAttribute string `yaml:"group_attribute"` // Attribute listing the user's groups (empty = memberOf)
+	Timeout        string `yaml:"timeout"`         // Per login (empty = 10s)
+	CAFile         string `yaml:"ca_file"`         // PEM CAs trusted for ldaps:// in addition to the system pool
+}
+
+// APIKeyConfig is a key programs authenticate to the API with, given as the
+// key, a file holding it or its SHA-256 hash
+type APIKeyConfig struct {
+	Name    string `yaml:"name"`     // Reported as the user, e.g. backup-script
+	Key     string `yaml:"key"`      // Prefer key_file or sha256, which keep the key out of the config
+	KeyFile string `yaml:"key_file"` // File holding the key, e.g. a mounted secret
+	SHA256  string `yaml:"sha256"`   // Hex SHA-256 of the key, e.g. from sha256sum
+	Role    string `yaml:"role"`     // viewer (default), operator or admin
+}
+
+// SessionConfig configures the sessions of dashboard logins and their cookie
+type SessionConfig struct {
+	TTL          string `yaml:"ttl"`           // Lifetime from sign-in (empty = 12h)
+	IdleTimeout  string `yaml:"idle_timeout"`  // Ends sessions unused as long (empty = 1h, 0s = never)
+	CookieName   string `yaml:"cookie_name"`   // Empty = argus_session
+	CookiePath   string `yaml:"cookie_path"`   // Path a reverse proxy serves Argus under, e.g. /argus/ (empty = /)
+	CookieDomain string `yaml:"cookie_domain"` // Empty scopes the cookie to the host
+	Secure       string `yaml:"secure"`        // auto (default: when served over HTTPS or X-Forwarded-Proto is https), always or never
+	SameSite     string `yaml:"same_site"`     // lax (default) or strict
+}
+
+// SecurityHeadersConfig configures the security headers sent with every
+// response
+type SecurityHeadersConfig struct {
+	Enabled               bool   `yaml:"enabled"`
+	HSTSMaxAge            string `yaml:"hsts_max_age"`            // Sent over HTTPS only (empty = 8760h, 0s = no HSTS)
+	HSTSIncludeSubdomains bool   `yaml:"hsts_include_subdomains"` // Also pin the subdomains of the host to HTTPS
+	FrameOptions          string `yaml:"frame_options"`           // deny (default) or sameorigin, to embed the dashboard in pages of its own origin
+	// Content-Security-Policy of the dashboard (empty = the built-in policy,
+	// off = none); frame-ancestors follows frame_options unless given
+	ContentSecurityPolicy string `yaml:"content_security_policy"`
+	CSPReportOnly         bool   `yaml:"csp_report_only"` // Report violations of the policy without blocking, to try one out
+}
+
+// OutboundChannels lists the outbound integrations whose proxy and TLS
+// settings can be overridden
+var OutboundChannels = []string{"webhook", "jira", "health_check", "update", "oidc", "exporters"}
+
+// DefaultUpdateURL is the latest release endpoint of the update check
+const DefaultUpdateURL = "https://api.github.com/repos/dramalin/argus/releases/latest"
+
+// DefaultCertExpiryWarning is how long before their end client certificates
+// are reported as expiring
+const DefaultCertExpiryWarning = 30 * 24 * time.Hour
+
+// ExporterConfig configures the shipping of the collected host metrics to
+// one remote time series database
+type ExporterConfig struct {
+	Name     string            `yaml:"name"`     // Identifies the exporter in logs and self metrics (default: its format)
+	Format   string            `yaml:"format"`   // influx (line protocol) or remote_write (Prometheus)
+	URL      string            `yaml:"url"`      // e.g. http://influxdb:8086/api/v2/write?org=ops&bucket=argus
+	Interval string            `yaml:"interval"` // How often samples are pushed (default 30s)
+	Timeout  string            `yaml:"timeout"`  // Limit on each push (default 10s)
+	Headers  map[string]string `yaml:"headers"`  // Sent with every push, e.g. Authorization
+	Labels   map[string]string `yaml:"labels"`   // Added to every series; host defaults to the hostname
+	Metrics  []string          `yaml:"metrics"`  // Glob patterns of series names to ship (empty ships all)
+}
+
+// ExporterFormats are the wire formats of the metrics exporters
+var ExporterFormats = []string{"influx", "remote_write"}
+
+// ProvisionedAlertConfig is an alert kept as configured: one of the default
+// alerts named by template, or an alert given in full, with the fields of the
+// alerts API
+type ProvisionedAlertConfig struct {
+	Template string                 `yaml:"template"` // Default alert the fields override, e.g. cpu (empty requires an id)
+	Fields   map[string]interface{} `yaml:",inline"`  // e.g. severity: critical and threshold: {value: 95}
+}
+
+// ChannelTemplate overrides the notification template of one channel for a
+// severity and state; empty ones match all
+type ChannelTemplate struct {
+	Severity string `yaml:"severity"` // info, warning or critical
+	State    string `yaml:"state"`    // active (firing) or resolved
+	Subject  string `yaml:"subject"`
+	Body     string `yaml:"body"`
+}
+
+// ChannelTemplateStates are the states a channel template can be selected for
+var ChannelTemplateStates = []string{"active", "resolved"}
+
 // Config holds all application configuration loaded from YAML and environment variables.
 type Config struct {
 	Server struct {
-		Port         int    `yaml:"port"`
-		Host         string `yaml:"host"`
-		ReadTimeout  string `yaml:"read_timeout"`
-		WriteTimeout string `yaml:"write_timeout"`
+		Port                 int              `yaml:"port"`
+		Host                 string           `yaml:"host"`
+		ReadTimeout          string           `yaml:"read_timeout"`
+		WriteTimeout         string           `yaml:"write_timeout"`
+		IdleTimeout          string           `yaml:"idle_timeout"`
+		ReadHeaderTimeout    string           `yaml:"read_header_timeout"`
+		MaxHeaderBytes       int              `yaml:"max_header_bytes"`
+		MaxBodyBytes         int64            `yaml:"max_body_bytes"`
+		BodyLimits           map[string]int64 `yaml:"body_limits"` // Per path-prefix overrides of max_body_bytes
+		SlowRequestThreshold string           `yaml:"slow_request_threshold"`
+		ReadOnly             bool             `yaml:"read_only"`   // Reject mutating API requests; toggled at /api/admin/read-only
+		UserHeader           string           `yaml:"user_header"` // Header naming the user authenticated by a trusted reverse proxy
 	} `yaml:"server"`
 
 	Debug struct {
@@ -23,36 +222,102 @@ type Config struct {
 		PprofEnabled     bool   `yaml:"pprof_enabled"`
 		PprofPath        string `yaml:"pprof_path"`
 		BenchmarkEnabled bool   `yaml:"benchmark_enabled"`
+		FaultInjection   bool   `yaml:"fault_injection"` // Expose /api/admin/faults for chaos testing
 	} `yaml:"debug"`
 
 	Monitoring struct {
 		UpdateInterval   string `yaml:"update_interval"`
+		CacheTTL         string `yaml:"cache_ttl"` // How long collected metrics are served before they count as stale
 		MetricsRetention string `yaml:"metrics_retention"`
+		HistoryEnabled   bool   `yaml:"history_enabled"` // Keep metrics_retention of collected metrics for queries, rollups, previews and event samples
 		ProcessLimit     int    `yaml:"process_limit"`
+		ProcessLimitMin  int    `yaml:"process_limit_min"` // Lowest limit the adaptive process limit shrinks to
+		ProcessBudget    string `yaml:"process_budget"`    // Process collection time above which the limit shrinks ("0s" disables)
+		CallTimeout      string `yaml:"call_timeout"`      // Bound of each system metrics call, so a hung /proc read cannot stall collection
+		// Read the open file descriptors, threads, IO counters and user of
+		// each kept process, at the cost of more /proc reads per round
+		ProcessDetails bool `yaml:"process_details"`
+		// Min/max/avg rollups of the metric history served to long-range queries:
+		// retention per rollup step ("0s" keeps forever, "off" drops the step)
+		RollupsEnabled bool              `yaml:"rollups_enabled"`
+		Rollups        map[string]string `yaml:"rollups"`
+		UPSServers     []string          `yaml:"ups_servers"` // NUT upsd servers (host or host:port) queried for UPS status
+		// Critical daemons of the system services panel, each with the systemd
+		// units that may provide it (empty watches ntp, ssh, cron and docker)
+		Services []metrics.ServiceCheck `yaml:"services"`
+		// Metric kinds not collected, e.g. raid or power; adjustable at /api/admin/collector
+		DisabledCollectors []string `yaml:"disabled_collectors"`
 	} `yaml:"monitoring"`
 
 	Alerts struct {
-		Enabled              bool   `yaml:"enabled"`
-		StoragePath          string `yaml:"storage_path"`
-		NotificationInterval string `yaml:"notification_interval"`
+		Enabled              bool              `yaml:"enabled"`
+		StoragePath          string            `yaml:"storage_path"`
+		NotificationInterval string            `yaml:"notification_interval"`
+		Locale               string            `yaml:"locale"`            // Default notification language (en, zh-TW)
+		Timezone             string            `yaml:"timezone"`          // IANA timezone for notification times (empty = server local)
+		ChannelTimezones     map[string]string `yaml:"channel_timezones"` // Per notification channel type overrides
+		// Per notification channel type template overrides
+		ChannelTemplates map[string][]ChannelTemplate `yaml:"channel_templates"`
+		// Execution limits of the channel templates
+		TemplateSandbox TemplateSandboxConfig `yaml:"template_sandbox"`
+		// Circuit breaker per notification channel and the dead-letter queue behind it
+		CircuitFailureThreshold int    `yaml:"circuit_failure_threshold"`
+		CircuitOpenTimeout      string `yaml:"circuit_open_timeout"`
+		DeadLetterSize          int    `yaml:"dead_letter_size"`
+		FlushInterval           string `yaml:"flush_interval"`      // How often bulk alert writes are persisted
+		HistoryCompression      string `yaml:"history_compression"` // none or gzip for alert history files
+		// Bounded queues between the evaluator, the notifier and its channels
+		Queues struct {
+			Events   QueueConfig `yaml:"events"`   // Evaluator to notifier
+			Dispatch QueueConfig `yaml:"dispatch"` // Notifier to each channel's dispatch workers
+			Email    QueueConfig `yaml:"email"`    // Pending outgoing emails
+			InApp    QueueConfig `yaml:"in_app"`   // Stored in-app notifications
+		} `yaml:"queues"`
+		// Workers rendering and sending the notifications of each channel, so a
+		// slow channel does not delay the others
+		DispatchWorkers int `yaml:"dispatch_workers"`
+		// Webhook deliveries wait on disk until their URL accepts them
+		Webhook WebhookConfig `yaml:"webhook"`
+		// Jira issues opened for critical alerts
+		Jira JiraConfig `yaml:"jira"`
+		// Tasks run by the remediation of activated alerts
+		Remediation RemediationConfig `yaml:"remediation"`
 	} `yaml:"alerts"`
 
+	// Alerts created, or reset to their configuration, at every start, e.g.
+	// the same standard alerts on every host of a fleet
+	ProvisionedAlerts []ProvisionedAlertConfig `yaml:"provisioned_alerts"`
+
 	Tasks struct {
 		Enabled       bool   `yaml:"enabled"`
 		StoragePath   string `yaml:"storage_path"`
 		MaxConcurrent int    `yaml:"max_concurrent"`
+		Timezone      string `yaml:"timezone"`    // Default IANA timezone for task schedules (empty = server local)
+		Compression   string `yaml:"compression"` // none or gzip for stored execution records
+		DryRun        bool   `yaml:"dry_run"`     // Runs every task as a dry run, reporting the file changes it would make
+		// Defers non-critical tasks while the host CPU or IO is busy
+		Throttle TaskThrottleConfig `yaml:"throttle"`
 	} `yaml:"tasks"`
 
 	Storage struct {
-		BasePath        string `yaml:"base_path"`
-		FilePermissions int    `yaml:"file_permissions"`
-		BackupEnabled   bool   `yaml:"backup_enabled"`
+		BasePath            string            `yaml:"base_path"`
+		FilePermissions     int               `yaml:"file_permissions"`
+		BackupEnabled       bool              `yaml:"backup_enabled"`
+		Driver              string            `yaml:"driver"`                // Store of alerts and tasks: file (JSON files), sqlite or a registered driver
+		SQLitePath          string            `yaml:"sqlite_path"`           // SQLite database (empty = argus.db under base_path)
+		Options             map[string]string `yaml:"options"`               // Settings of the driver, e.g. a DSN
+		BudgetBytes         int64             `yaml:"budget_bytes"`          // Size limit of Argus's storage directories (0 disables)
+		BudgetWarnPercent   float64           `yaml:"budget_warn_percent"`   // Usage percentage raising the storage budget alert
+		BudgetCheckInterval string            `yaml:"budget_check_interval"` // How often storage usage is measured
 	} `yaml:"storage"`
 
 	Logging struct {
 		Level  string `yaml:"level"`
 		Format string `yaml:"format"`
 		File   string `yaml:"file"`
+		// How often an error repeating every collection or evaluation round
+		// is logged again; occurrences in between are counted
+		RepeatInterval string `yaml:"repeat_interval"`
 	} `yaml:"logging"`
 
 	WebSocket struct {
@@ -60,6 +325,23 @@ type Config struct {
 		Path            string `yaml:"path"`
 		ReadBufferSize  int    `yaml:"read_buffer_size"`
 		WriteBufferSize int    `yaml:"write_buffer_size"`
+		DrainTimeout    string `yaml:"drain_timeout"` // How long to wait for clients to disconnect on shutdown
+
+		// Origins besides the server's own whose pages may connect, e.g.
+		// https://dashboard.example.com; handshakes of other pages get 403
+		AllowedOrigins []string `yaml:"allowed_origins"`
+
+		// Process list streamed to clients connecting with ?subscribe=processes
+		ProcessStream struct {
+			Enabled       bool   `yaml:"enabled"`
+			Interval      string `yaml:"interval"`       // How often new process data is checked for
+			KeyframeEvery int    `yaml:"keyframe_every"` // Frames between full snapshots; deltas in between
+		} `yaml:"process_stream"`
+
+		// Alert state changes streamed to clients connecting with ?subscribe=alerts
+		AlertStream struct {
+			Enabled bool `yaml:"enabled"`
+		} `yaml:"alert_stream"`
 	} `yaml:"websocket"`
 
 	CORS struct {
@@ -68,6 +350,134 @@ type Config struct {
 		AllowedMethods []string `yaml:"allowed_methods"`
 		AllowedHeaders []string `yaml:"allowed_headers"`
 	} `yaml:"cors"`
+
+	SecurityHeaders SecurityHeadersConfig `yaml:"security_headers"`
+
+	Redaction struct {
+		Enabled      bool          `yaml:"enabled"`
+		DefaultRules bool          `yaml:"default_rules"` // Include the built-in secret patterns
+		Rules        []redact.Rule `yaml:"rules"`         // Additional regex rules, applied after the defaults
+	} `yaml:"redaction"`
+
+	Ingest struct {
+		// Prometheus remote-write receiver at /api/ingest/remote-write
+		RemoteWrite struct {
+			Enabled   bool     `yaml:"enabled"`
+			Metrics   []string `yaml:"metrics"`    // Glob patterns of metric names to store (empty stores all)
+			Retention string   `yaml:"retention"`  // How long ingested samples are kept
+			MaxSeries int      `yaml:"max_series"` // Limit on distinct series
+		} `yaml:"remote_write"`
+	} `yaml:"ingest"`
+
+	Metrics struct {
+		// Periodic pushes of the collected host metrics to remote TSDBs
+		Exporters []ExporterConfig `yaml:"exporters"`
+	} `yaml:"metrics"`
+
+	// Grafana JSON datasource at /api/grafana, serving the history of the
+	// collected metrics, which it records even without monitoring.history_enabled
+	Grafana struct {
+		Enabled bool `yaml:"enabled"`
+	} `yaml:"grafana"`
+
+	// API request counts and latencies per namespace and token at /api/metrics/self/api
+	APIMetrics struct {
+		Enabled bool   `yaml:"enabled"`
+		Window  string `yaml:"window"`   // Rolling window for error rates and api alerts
+		MaxKeys int    `yaml:"max_keys"` // Namespace/token pairs tracked before folding into "other"
+	} `yaml:"api_metrics"`
+
+	// Short-lived cache of GET responses for list endpoints polled by dashboards
+	ResponseCache struct {
+		Enabled    bool     `yaml:"enabled"`
+		TTL        string   `yaml:"ttl"`         // How long a response is served from cache
+		Paths      []string `yaml:"paths"`       // Path prefixes whose GET responses are cached
+		MaxEntries int      `yaml:"max_entries"` // Responses kept at most (0 means unlimited)
+	} `yaml:"response_cache"`
+
+	// Scheduled purge of stored data older than a per-type maximum age, reported at /api/retention
+	Retention struct {
+		Enabled  bool              `yaml:"enabled"`
+		Interval string            `yaml:"interval"` // How often the purge job runs
+		Policies map[string]string `yaml:"policies"` // Maximum age per data type ("0s" keeps forever)
+	} `yaml:"retention"`
+
+	// Inventory of agents reporting to this server in agent mode, at /api/hosts
+	Hosts struct {
+		Enabled         bool   `yaml:"enabled"`
+		StaleAfter      string `yaml:"stale_after"`      // Time without a heartbeat before ArgusAgentDown fires
+		EnrollmentToken string `yaml:"enrollment_token"` // Shared token agents enroll with; empty accepts unauthenticated heartbeats
+	} `yaml:"hosts"`
+
+	// Log watches counting pattern matches for log alerts, at /api/logwatch
+	LogWatch struct {
+		Enabled       bool             `yaml:"enabled"`
+		RecentMatches int              `yaml:"recent_matches"` // Matches kept per watch for the API
+		Watches       []logwatch.Watch `yaml:"watches"`
+	} `yaml:"log_watch"`
+
+	// Syslog (RFC 3164/5424) listener feeding syslog log watches, recent messages at /api/syslog/messages
+	Syslog struct {
+		Enabled        bool   `yaml:"enabled"`
+		UDPAddress     string `yaml:"udp_address"`      // Empty disables UDP
+		TCPAddress     string `yaml:"tcp_address"`      // Empty disables TCP
+		BufferSize     int    `yaml:"buffer_size"`      // Recent messages kept for the API
+		MaxMessageSize int    `yaml:"max_message_size"` // Longer messages are truncated
+	} `yaml:"syslog"`
+
+	// Unauthenticated read-only status page of alert groups at /status and /status.json
+	StatusPage struct {
+		Enabled     bool                   `yaml:"enabled"`
+		Title       string                 `yaml:"title"`
+		HistoryDays int                    `yaml:"history_days"` // Days of uptime history shown
+		Components  []statuspage.Component `yaml:"components"`   // Published alert groups, in page order
+	} `yaml:"status_page"`
+
+	// Incidents correlated from alert, acknowledgment, silence and task activity at /api/incidents
+	Incidents struct {
+		Window   string `yaml:"window"`   // How close firing alerts must be to join an incident
+		Lookback string `yaml:"lookback"` // How far back incidents are looked for
+	} `yaml:"incidents"`
+
+	// Periodic check for a newer release, reported at /api/version
+	Update struct {
+		Enabled  bool   `yaml:"enabled"`
+		URL      string `yaml:"url"`      // Latest release endpoint in the format of the GitHub releases API
+		Interval string `yaml:"interval"` // Between checks
+		Notify   bool   `yaml:"notify"`   // Raise an in-app notification for each newer release
+	} `yaml:"update"`
+
+	// Flags of experimental subsystems by name, e.g. agent_mode: false;
+	// undeclared names are rejected and unset flags take their default
+	Features map[string]bool `yaml:"features"`
+
+	// Proxy of outbound HTTP integrations; channels overrides it for one of OutboundChannels
+	Proxy struct {
+		ProxyConfig `yaml:",inline"`
+		Channels    map[string]ProxyConfig `yaml:"channels"`
+	} `yaml:"proxy"`
+
+	// TLS of outbound HTTPS integrations; channels overrides it for one of OutboundChannels
+	OutboundTLS struct {
+		TLSConfig     `yaml:",inline"`
+		ExpiryWarning string               `yaml:"expiry_warning"` // Client certificates ending within this are reported as expiring (default 720h)
+		Channels      map[string]TLSConfig `yaml:"channels"`
+	} `yaml:"outbound_tls"`
+
+	// Login providers; while one is enabled, the API and the WebSocket
+	// require an authenticated user
+	Auth struct {
+		OIDC     OIDCConfig    `yaml:"oidc"`
+		LDAP     LDAPConfig    `yaml:"ldap"`
+		Sessions SessionConfig `yaml:"sessions"`
+		// Keys of scripts and integrations, sent in api_key_header
+		APIKeyHeader string         `yaml:"api_key_header"` // Empty = X-API-Key
+		APIKeys      []APIKeyConfig `yaml:"api_keys"`
+		Roles        struct {
+			Default string            `yaml:"default"` // Role of users in no mapped group: viewer (default), operator, admin or none to deny
+			Groups  map[string]string `yaml:"groups"`  // Role by group name or DN
+		} `yaml:"roles"`
+	} `yaml:"auth"`
 }
 
 // LoadConfig loads configuration from a YAML file and applies environment variable overrides.
@@ -95,21 +505,35 @@ func LoadConfig(path string) (*Config, error) {
 func defaultConfig() *Config {
 	return &Config{
 		Server: struct {
-			Port         int    `yaml:"port"`
-			Host         string `yaml:"host"`
-			ReadTimeout  string `yaml:"read_timeout"`
-			WriteTimeout string `yaml:"write_timeout"`
+			Port                 int              `yaml:"port"`
+			Host                 string           `yaml:"host"`
+			ReadTimeout          string           `yaml:"read_timeout"`
+			WriteTimeout         string           `yaml:"write_timeout"`
+			IdleTimeout          string           `yaml:"idle_timeout"`
+			ReadHeaderTimeout    string           `yaml:"read_header_timeout"`
+			MaxHeaderBytes       int              `yaml:"max_header_bytes"`
+			MaxBodyBytes         int64            `yaml:"max_body_bytes"`
+			BodyLimits           map[string]int64 `yaml:"body_limits"`
+			SlowRequestThreshold string           `yaml:"slow_request_threshold"`
+			ReadOnly             bool             `yaml:"read_only"`
+			UserHeader           string           `yaml:"user_header"`
 		}{
-			Port:         8080,
-			Host:         "localhost",
-			ReadTimeout:  "30s",
-			WriteTimeout: "30s",
+			Port:                 8080,
+			Host:                 "localhost",
+			ReadTimeout:          "30s",
+			WriteTimeout:         "30s",
+			IdleTimeout:          "60s",
+			ReadHeaderTimeout:    "5s",
+			MaxHeaderBytes:       1 << 20, // 1 MB
+			MaxBodyBytes:         4 << 20, // 4 MB
+			SlowRequestThreshold: "1s",
 		},
 		Debug: struct {
 			Enabled          bool   `yaml:"enabled"`
 			PprofEnabled     bool   `yaml:"pprof_enabled"`
 			PprofPath        string `yaml:"pprof_path"`
 			BenchmarkEnabled bool   `yaml:"benchmark_enabled"`
+			FaultInjection   bool   `yaml:"fault_injection"` // Expose /api/admin/faults for chaos testing
 		}{
 			Enabled:          true,
 			PprofEnabled:     true,
@@ -117,60 +541,177 @@ func defaultConfig() *Config {
 			BenchmarkEnabled: true,
 		},
 		Monitoring: struct {
-			UpdateInterval   string `yaml:"update_interval"`
-			MetricsRetention string `yaml:"metrics_retention"`
-			ProcessLimit     int    `yaml:"process_limit"`
+			UpdateInterval   string                 `yaml:"update_interval"`
+			CacheTTL         string                 `yaml:"cache_ttl"`
+			MetricsRetention string                 `yaml:"metrics_retention"`
+			HistoryEnabled   bool                   `yaml:"history_enabled"`
+			ProcessLimit     int                    `yaml:"process_limit"`
+			ProcessLimitMin  int                    `yaml:"process_limit_min"`
+			ProcessBudget    string                 `yaml:"process_budget"`
+			CallTimeout      string                 `yaml:"call_timeout"`
+			ProcessDetails   bool                   `yaml:"process_details"`
+			RollupsEnabled   bool                   `yaml:"rollups_enabled"`
+			Rollups          map[string]string      `yaml:"rollups"`
+			UPSServers       []string               `yaml:"ups_servers"`
+			Services         []metrics.ServiceCheck `yaml:"services"`
+
+			DisabledCollectors []string `yaml:"disabled_collectors"`
 		}{
 			UpdateInterval:   "5s",
+			CacheTTL:         "10s",
 			MetricsRetention: "24h",
+			HistoryEnabled:   true,
 			ProcessLimit:     100,
+			ProcessLimitMin:  20,
+			ProcessBudget:    "1s",
+			CallTimeout:      "5s",
+			RollupsEnabled:   true,
+			Rollups: map[string]string{
+				"1m": "168h",
+				"5m": "720h",
+				"1h": "8760h",
+			},
 		},
 		Alerts: struct {
-			Enabled              bool   `yaml:"enabled"`
-			StoragePath          string `yaml:"storage_path"`
-			NotificationInterval string `yaml:"notification_interval"`
+			Enabled              bool              `yaml:"enabled"`
+			StoragePath          string            `yaml:"storage_path"`
+			NotificationInterval string            `yaml:"notification_interval"`
+			Locale               string            `yaml:"locale"`
+			Timezone             string            `yaml:"timezone"`
+			ChannelTimezones     map[string]string `yaml:"channel_timezones"`
+
+			ChannelTemplates map[string][]ChannelTemplate `yaml:"channel_templates"`
+			TemplateSandbox  TemplateSandboxConfig        `yaml:"template_sandbox"`
+
+			CircuitFailureThreshold int    `yaml:"circuit_failure_threshold"`
+			CircuitOpenTimeout      string `yaml:"circuit_open_timeout"`
+			DeadLetterSize          int    `yaml:"dead_letter_size"`
+			FlushInterval           string `yaml:"flush_interval"`
+			HistoryCompression      string `yaml:"history_compression"`
+			Queues                  struct {
+				Events   QueueConfig `yaml:"events"`
+				Dispatch QueueConfig `yaml:"dispatch"`
+				Email    QueueConfig `yaml:"email"`
+				InApp    QueueConfig `yaml:"in_app"`
+			} `yaml:"queues"`
+			DispatchWorkers int `yaml:"dispatch_workers"`
+
+			Webhook WebhookConfig `yaml:"webhook"`
+			Jira    JiraConfig    `yaml:"jira"`
+
+			Remediation RemediationConfig `yaml:"remediation"`
 		}{
 			Enabled:              true,
 			StoragePath:          "./.argus/alerts",
 			NotificationInterval: "1m",
+			Locale:               "en",
+			TemplateSandbox:      TemplateSandboxConfig{Timeout: "1s", MaxOutputBytes: 64 << 10},
+
+			CircuitFailureThreshold: 5,
+			CircuitOpenTimeout:      "1m",
+			DeadLetterSize:          500,
+			FlushInterval:           "5s",
+			HistoryCompression:      "none",
+			DispatchWorkers:         2,
+			Queues: struct {
+				Events   QueueConfig `yaml:"events"`
+				Dispatch QueueConfig `yaml:"dispatch"`
+				Email    QueueConfig `yaml:"email"`
+				InApp    QueueConfig `yaml:"in_app"`
+			}{
+				Events:   QueueConfig{Size: 1000, Overflow: "drop-newest", BlockTimeout: "1s"},
+				Dispatch: QueueConfig{Size: 100, Overflow: "drop-newest", BlockTimeout: "1s"},
+				Email:    QueueConfig{Size: 100, Overflow: "drop-newest", BlockTimeout: "1s"},
+				InApp:    QueueConfig{Size: 100, Overflow: "drop-oldest", BlockTimeout: "1s"},
+			},
+			Webhook: WebhookConfig{QueueSize: 1000, Retention: "24h", RetryInterval: "30s", Timeout: "10s"},
+			Jira:    JiraConfig{IssueType: "Task", Timeout: "10s"},
+
+			Remediation: RemediationConfig{Enabled: true},
 		},
 		Tasks: struct {
 			Enabled       bool   `yaml:"enabled"`
 			StoragePath   string `yaml:"storage_path"`
 			MaxConcurrent int    `yaml:"max_concurrent"`
+			Timezone      string `yaml:"timezone"`
+			Compression   string `yaml:"compression"`
+			DryRun        bool   `yaml:"dry_run"`
+			// Defers non-critical tasks while the host CPU or IO is busy
+			Throttle TaskThrottleConfig `yaml:"throttle"`
 		}{
 			Enabled:       true,
 			StoragePath:   "./.argus/tasks",
 			MaxConcurrent: 5,
+			Compression:   "none",
+			Throttle:      TaskThrottleConfig{MaxDefer: "1h"},
 		},
 		Storage: struct {
-			BasePath        string `yaml:"base_path"`
-			FilePermissions int    `yaml:"file_permissions"`
-			BackupEnabled   bool   `yaml:"backup_enabled"`
+			BasePath            string            `yaml:"base_path"`
+			FilePermissions     int               `yaml:"file_permissions"`
+			BackupEnabled       bool              `yaml:"backup_enabled"`
+			Driver              string            `yaml:"driver"`
+			SQLitePath          string            `yaml:"sqlite_path"`
+			Options             map[string]string `yaml:"options"`
+			BudgetBytes         int64             `yaml:"budget_bytes"`
+			BudgetWarnPercent   float64           `yaml:"budget_warn_percent"`
+			BudgetCheckInterval string            `yaml:"budget_check_interval"`
 		}{
-			BasePath:        "./.argus",
-			FilePermissions: 0644,
-			BackupEnabled:   true,
+			BasePath:            "./.argus",
+			FilePermissions:     0644,
+			BackupEnabled:       true,
+			Driver:              "file",
+			BudgetBytes:         0,
+			BudgetWarnPercent:   80,
+			BudgetCheckInterval: "1m",
 		},
 		Logging: struct {
 			Level  string `yaml:"level"`
 			Format string `yaml:"format"`
 			File   string `yaml:"file"`
+			// How often an error repeating every collection or evaluation round
+			// is logged again; occurrences in between are counted
+			RepeatInterval string `yaml:"repeat_interval"`
 		}{
-			Level:  "info",
-			Format: "json",
-			File:   "",
+			Level:          "info",
+			Format:         "json",
+			File:           "",
+			RepeatInterval: "5m",
 		},
 		WebSocket: struct {
-			Enabled         bool   `yaml:"enabled"`
-			Path            string `yaml:"path"`
-			ReadBufferSize  int    `yaml:"read_buffer_size"`
-			WriteBufferSize int    `yaml:"write_buffer_size"`
+			Enabled         bool     `yaml:"enabled"`
+			Path            string   `yaml:"path"`
+			ReadBufferSize  int      `yaml:"read_buffer_size"`
+			WriteBufferSize int      `yaml:"write_buffer_size"`
+			DrainTimeout    string   `yaml:"drain_timeout"`
+			AllowedOrigins  []string `yaml:"allowed_origins"`
+			ProcessStream   struct {
+				Enabled       bool   `yaml:"enabled"`
+				Interval      string `yaml:"interval"`
+				KeyframeEvery int    `yaml:"keyframe_every"`
+			} `yaml:"process_stream"`
+			AlertStream struct {
+				Enabled bool `yaml:"enabled"`
+			} `yaml:"alert_stream"`
 		}{
 			Enabled:         true,
 			Path:            "/ws",
 			ReadBufferSize:  1024,
 			WriteBufferSize: 1024,
+			DrainTimeout:    "10s",
+			ProcessStream: struct {
+				Enabled       bool   `yaml:"enabled"`
+				Interval      string `yaml:"interval"`
+				KeyframeEvery int    `yaml:"keyframe_every"`
+			}{
+				Enabled:       true,
+				Interval:      "1s",
+				KeyframeEvery: 30,
+			},
+			AlertStream: struct {
+				Enabled bool `yaml:"enabled"`
+			}{
+				Enabled: true,
+			},
 		},
 		CORS: struct {
 			Enabled        bool     `yaml:"enabled"`
@@ -183,6 +724,131 @@ func defaultConfig() *Config {
 			AllowedMethods: []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
 			AllowedHeaders: []string{"Content-Type", "Authorization"},
 		},
+		SecurityHeaders: SecurityHeadersConfig{Enabled: true},
+		Redaction: struct {
+			Enabled      bool          `yaml:"enabled"`
+			DefaultRules bool          `yaml:"default_rules"`
+			Rules        []redact.Rule `yaml:"rules"`
+		}{
+			Enabled:      true,
+			DefaultRules: true,
+		},
+		Ingest: struct {
+			RemoteWrite struct {
+				Enabled   bool     `yaml:"enabled"`
+				Metrics   []string `yaml:"metrics"`
+				Retention string   `yaml:"retention"`
+				MaxSeries int      `yaml:"max_series"`
+			} `yaml:"remote_write"`
+		}{
+			RemoteWrite: struct {
+				Enabled   bool     `yaml:"enabled"`
+				Metrics   []string `yaml:"metrics"`
+				Retention string   `yaml:"retention"`
+				MaxSeries int      `yaml:"max_series"`
+			}{
+				Enabled:   false,
+				Retention: "1h",
+				MaxSeries: 10000,
+			},
+		},
+		Metrics: struct {
+			Exporters []ExporterConfig `yaml:"exporters"`
+		}{},
+		Grafana: struct {
+			Enabled bool `yaml:"enabled"`
+		}{
+			Enabled: false,
+		},
+		APIMetrics: struct {
+			Enabled bool   `yaml:"enabled"`
+			Window  string `yaml:"window"`
+			MaxKeys int    `yaml:"max_keys"`
+		}{
+			Enabled: true,
+			Window:  "5m",
+			MaxKeys: 1000,
+		},
+		ResponseCache: struct {
+			Enabled    bool     `yaml:"enabled"`
+			TTL        string   `yaml:"ttl"`
+			Paths      []string `yaml:"paths"`
+			MaxEntries int      `yaml:"max_entries"`
+		}{
+			Enabled:    false,
+			TTL:        "2s",
+			Paths:      []string{"/api/alerts", "/api/tasks", "/api/process", "/api/metrics/process"},
+			MaxEntries: 1000,
+		},
+		Retention: struct {
+			Enabled  bool              `yaml:"enabled"`
+			Interval string            `yaml:"interval"`
+			Policies map[string]string `yaml:"policies"`
+		}{
+			Enabled:  false,
+			Interval: "1h",
+			Policies: map[string]string{
+				"alert_events":  "720h",
+				"executions":    "720h",
+				"notifications": "168h",
+				"audit_logs":    "2160h",
+			},
+		},
+		Hosts: struct {
+			Enabled         bool   `yaml:"enabled"`
+			StaleAfter      string `yaml:"stale_after"`
+			EnrollmentToken string `yaml:"enrollment_token"`
+		}{
+			Enabled:    false,
+			StaleAfter: "2m",
+		},
+		LogWatch: struct {
+			Enabled       bool             `yaml:"enabled"`
+			RecentMatches int              `yaml:"recent_matches"`
+			Watches       []logwatch.Watch `yaml:"watches"`
+		}{
+			Enabled:       false,
+			RecentMatches: logwatch.DefaultRecentMatches,
+		},
+		Syslog: struct {
+			Enabled        bool   `yaml:"enabled"`
+			UDPAddress     string `yaml:"udp_address"`
+			TCPAddress     string `yaml:"tcp_address"`
+			BufferSize     int    `yaml:"buffer_size"`
+			MaxMessageSize int    `yaml:"max_message_size"`
+		}{
+			Enabled:        false,
+			UDPAddress:     ":5514",
+			BufferSize:     1000,
+			MaxMessageSize: 8192,
+		},
+		StatusPage: struct {
+			Enabled     bool                   `yaml:"enabled"`
+			Title       string                 `yaml:"title"`
+			HistoryDays int                    `yaml:"history_days"`
+			Components  []statuspage.Component `yaml:"components"`
+		}{
+			Enabled:     false,
+			Title:       statuspage.DefaultTitle,
+			HistoryDays: statuspage.DefaultHistoryDays,
+		},
+		Incidents: struct {
+			Window   string `yaml:"window"`
+			Lookback string `yaml:"lookback"`
+		}{
+			Window:   "5m",
+			Lookback: "168h",
+		},
+		Update: struct {
+			Enabled  bool   `yaml:"enabled"`
+			URL      string `yaml:"url"`
+			Interval string `yaml:"interval"`
+			Notify   bool   `yaml:"notify"`
+		}{
+			URL:      DefaultUpdateURL,
+			Interval: "24h",
+			Notify:   true,
+		},
 	}
 }
 
@@ -194,6 +860,12 @@ func applyEnvOverrides(cfg *Config) {
 	if v := os.Getenv("ARGUS_SERVER_HOST"); v != "" {
 		cfg.Server.Host = v
 	}
+	if v := os.Getenv("ARGUS_SERVER_READ_ONLY"); v != "" {
+		cfg.Server.ReadOnly = v == "true"
+	}
+	if v := os.Getenv("ARGUS_SERVER_USER_HEADER"); v != "" {
+		cfg.Server.UserHeader = v
+	}
 	if v := os.Getenv("ARGUS_DEBUG_ENABLED"); v != "" {
 		cfg.Debug.Enabled = v == "true"
 	}
@@ -206,6 +878,35 @@ func applyEnvOverrides(cfg *Config) {
 	if v := os.Getenv("ARGUS_DEBUG_BENCHMARK_ENABLED"); v != "" {
 		cfg.Debug.BenchmarkEnabled = v == "true"
 	}
+	if v := os.Getenv("ARGUS_DEBUG_FAULT_INJECTION"); v != "" {
+		cfg.Debug.FaultInjection = v == "true"
+	}
+	if v := os.Getenv("ARGUS_HOSTS_ENROLLMENT_TOKEN"); v != "" {
+		cfg.Hosts.EnrollmentToken = v
+	}
+	if v := os.Getenv("ARGUS_ALERTS_JIRA_API_TOKEN"); v != "" {
+		cfg.Alerts.Jira.APIToken = v
+	}
+	if v := os.Getenv("ARGUS_AUTH_OIDC_CLIENT_SECRET"); v != "" {
+		cfg.Auth.OIDC.ClientSecret = v
+	}
+	if v := os.Getenv("ARGUS_AUTH_LDAP_BIND_PASSWORD"); v != "" {
+		cfg.Auth.LDAP.BindPassword = v
+	}
+	if v := os.Getenv("ARGUS_PROXY_URL"); v != "" {
+		cfg.Proxy.URL = v
+	}
+	if v := os.Getenv("ARGUS_PROXY_NO_PROXY"); v != "" {
+		cfg.Proxy.NoProxy = strings.Split(v, ",")
+	}
+	for _, flag := range features.Flags {
+		if v := os.Getenv("ARGUS_FEATURE_" + strings.ToUpper(flag.Name)); v != "" {
+			if cfg.Features == nil {
+				cfg.Features = make(map[string]bool)
+			}
+			cfg.Features[flag.Name] = v == "true"
+		}
+	}
 	// Add more environment variable overrides as needed for other fields
 }
 
@@ -220,5 +921,923 @@ func validateConfig(cfg *Config) error {
 	if _, err := time.ParseDuration(cfg.Server.WriteTimeout); err != nil {
 		return fmt.Errorf("invalid server write_timeout: %w", err)
 	}
+	optionalDurations := map[string]string{
+		"idle_timeout":           cfg.Server.IdleTimeout,
+		"read_header_timeout":    cfg.Server.ReadHeaderTimeout,
+		"slow_request_threshold": cfg.Server.SlowRequestThreshold,
+	}
+	for name, value := range optionalDurations {
+		if value == "" {
+			continue
+		}
+		if _, err := time.ParseDuration(value); err != nil {
+			return fmt.Errorf("invalid server %s: %w", name, err)
+		}
+	}
+	if cfg.Server.MaxHeaderBytes < 0 {
+		return errors.New("invalid server max_header_bytes: must not be negative")
+	}
+	if cfg.Server.MaxBodyBytes < 0 {
+		return errors.New("invalid server max_body_bytes: must not be negative")
+	}
+	if strings.ContainsAny(cfg.Server.UserHeader, " \t\r\n:") {
+		return fmt.Errorf("invalid server user_header %q: must be a header name", cfg.Server.UserHeader)
+	}
+	if cfg.WebSocket.DrainTimeout != "" {
+		if _, err := time.ParseDuration(cfg.WebSocket.DrainTimeout); err != nil {
+			return fmt.Errorf("invalid websocket drain_timeout: %w", err)
+		}
+	}
+	for _, origin := range cfg.WebSocket.AllowedOrigins {
+		if u, err := url.Parse(origin); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" || strings.TrimRight(u.Path, "/") != "" {
+			return fmt.Errorf("invalid websocket allowed_origins entry %q: must be a scheme and host, e.g. https://dashboard.example.com", origin)
+		}
+	}
+	if cfg.Monitoring.ProcessBudget != "" {
+		if d, err := time.ParseDuration(cfg.Monitoring.ProcessBudget); err != nil || d < 0 {
+			return fmt.Errorf("invalid monitoring process_budget %q: must be a non-negative duration", cfg.Monitoring.ProcessBudget)
+		}
+	}
+	if cfg.Monitoring.CacheTTL != "" {
+		if d, err := time.ParseDuration(cfg.Monitoring.CacheTTL); err != nil || d <= 0 {
+			return fmt.Errorf("invalid monitoring cache_ttl %q: must be a positive duration", cfg.Monitoring.CacheTTL)
+		}
+	}
+	for _, kind := range cfg.Monitoring.DisabledCollectors {
+		if !slices.Contains(metrics.Kinds, kind) {
+			return fmt.Errorf("invalid monitoring disabled_collectors entry %q: must be one of %s", kind, strings.Join(metrics.Kinds, ", "))
+		}
+	}
+	if cfg.Monitoring.CallTimeout != "" {
+		if d, err := time.ParseDuration(cfg.Monitoring.CallTimeout); err != nil || d <= 0 {
+			return fmt.Errorf("invalid monitoring call_timeout %q: must be a positive duration", cfg.Monitoring.CallTimeout)
+		}
+	}
+	if cfg.Monitoring.ProcessLimitMin < 0 {
+		return errors.New("invalid monitoring process_limit_min: must not be negative")
+	}
+	serviceNames := make(map[string]bool, len(cfg.Monitoring.Services))
+	for _, service := range cfg.Monitoring.Services {
+		if service.Name == "" || len(service.Units) == 0 || slices.Contains(service.Units, "") {
+			return fmt.Errorf("invalid monitoring services entry %q: requires a name and units", service.Name)
+		}
+		if serviceNames[service.Name] {
+			return fmt.Errorf("invalid monitoring services: duplicate service %q", service.Name)
+		}
+		serviceNames[service.Name] = true
+	}
+	for _, server := range cfg.Monitoring.UPSServers {
+		if server == "" || strings.ContainsAny(server, " \t/") {
+			return fmt.Errorf("invalid monitoring ups_servers entry %q: must be a host or host:port", server)
+		}
+	}
+	if _, err := cfg.RollupResolutions(); err != nil {
+		return err
+	}
+	if cfg.WebSocket.ProcessStream.Interval != "" {
+		if d, err := time.ParseDuration(cfg.WebSocket.ProcessStream.Interval); err != nil || d <= 0 {
+			return fmt.Errorf("invalid websocket process_stream interval %q: must be a positive duration", cfg.WebSocket.ProcessStream.Interval)
+		}
+	}
+	if cfg.WebSocket.ProcessStream.KeyframeEvery < 0 {
+		return errors.New("invalid websocket process_stream keyframe_every: must not be negative")
+	}
+	if cfg.Alerts.CircuitOpenTimeout != "" {
+		if _, err := time.ParseDuration(cfg.Alerts.CircuitOpenTimeout); err != nil {
+			return fmt.Errorf("invalid alerts circuit_open_timeout: %w", err)
+		}
+	}
+	if cfg.Alerts.FlushInterval != "" {
+		if _, err := time.ParseDuration(cfg.Alerts.FlushInterval); err != nil {
+			return fmt.Errorf("invalid alerts flush_interval: %w", err)
+		}
+	}
+	if cfg.Alerts.CircuitFailureThreshold < 0 || cfg.Alerts.DeadLetterSize < 0 {
+		return errors.New("invalid alerts circuit_failure_threshold/dead_letter_size: must not be negative")
+	}
+	if cfg.Alerts.Locale != "" {
+		if _, ok := i18n.Parse(cfg.Alerts.Locale); !ok {
+			return fmt.Errorf("invalid alerts locale %q: supported locales are %v", cfg.Alerts.Locale, i18n.Supported)
+		}
+	}
+	if timeout := cfg.Alerts.TemplateSandbox.Timeout; timeout != "" {
+		if d, err := time.ParseDuration(timeout); err != nil || d <= 0 {
+			return fmt.Errorf("invalid alerts template_sandbox timeout %q: must be a positive duration", timeout)
+		}
+	}
+	if cfg.Alerts.TemplateSandbox.MaxOutputBytes < 0 {
+		return errors.New("invalid alerts template_sandbox max_output_bytes: must not be negative")
+	}
+	limits := cfg.TemplateLimits()
+	for channel, templates := range cfg.Alerts.ChannelTemplates {
+		for i, tmpl := range templates {
+			name := fmt.Sprintf("alerts channel_templates entry %s[%d]", channel, i)
+			if tmpl.Severity != "" && !slices.Contains(models.Severities, models.AlertSeverity(tmpl.Severity)) {
+				return fmt.Errorf("invalid %s severity %q: expected info, warning or critical", name, tmpl.Severity)
+			}
+			if tmpl.State != "" && !slices.Contains(ChannelTemplateStates, tmpl.State) {
+				return fmt.Errorf("invalid %s state %q: expected active or resolved", name, tmpl.State)
+			}
+			if tmpl.Subject == "" || tmpl.Body == "" {
+				return fmt.Errorf("invalid %s: subject and body are required", name)
+			}
+			if err := checkChannelTemplate(tmpl, limits); err != nil {
+				return fmt.Errorf("invalid %s %w", name, err)
+			}
+		}
+	}
+	queues := map[string]QueueConfig{
+		"alerts queues.events":   cfg.Alerts.Queues.Events,
+		"alerts queues.dispatch": cfg.Alerts.Queues.Dispatch,
+		"alerts queues.email":    cfg.Alerts.Queues.Email,
+		"alerts queues.in_app":   cfg.Alerts.Queues.InApp,
+	}
+	if cfg.Alerts.DispatchWorkers < 0 {
+		return errors.New("invalid alerts dispatch_workers: must not be negative")
+	}
+	for name, q := range queues {
+		if q.Size < 0 {
+			return fmt.Errorf("invalid %s size: must not be negative", name)
+		}
+		switch q.Overflow {
+		case "", "drop-oldest", "drop-newest", "block":
+		default:
+			return fmt.Errorf("invalid %s overflow %q: must be drop-oldest, drop-newest or block", name, q.Overflow)
+		}
+		if q.BlockTimeout != "" {
+			if _, err := time.ParseDuration(q.BlockTimeout); err != nil {
+				return fmt.Errorf("invalid %s block_timeout: %w", name, err)
+			}
+		}
+	}
+	if cfg.Alerts.Webhook.QueueSize < 0 {
+		return errors.New("invalid alerts webhook queue_size: must not be negative")
+	}
+	webhookDurations := map[string]string{
+		"retention":      cfg.Alerts.Webhook.Retention,
+		"retry_interval": cfg.Alerts.Webhook.RetryInterval,
+		"timeout":        cfg.Alerts.Webhook.Timeout,
+	}
+	for name, value := range webhookDurations {
+		if value == "" {
+			continue
+		}
+		if d, err := time.ParseDuration(value); err != nil || d <= 0 {
+			return fmt.Errorf("invalid alerts webhook %s %q: must be a positive duration", name, value)
+		}
+	}
+	if err := validateJira(cfg.Alerts.Jira); err != nil {
+		return err
+	}
+	if _, err := cfg.ProvisionedAlertConfigs(time.Now()); err != nil {
+		return err
+	}
+	compressions := map[string]string{
+		"alerts history_compression": cfg.Alerts.HistoryCompression,
+		"tasks compression":          cfg.Tasks.Compression,
+	}
+	for name, alg := range compressions {
+		if _, err := compress.Parse(alg); err != nil {
+			return fmt.Errorf("invalid %s: %w", name, err)
+		}
+	}
+	throttle := cfg.Tasks.Throttle
+	for name, p := range map[string]float64{"cpu_percent": throttle.CPUPercent, "iowait_percent": throttle.IOWaitPercent} {
+		if p < 0 || p > 100 {
+			return fmt.Errorf("invalid tasks throttle %s %v: must be between 0 and 100", name, p)
+		}
+	}
+	if throttle.MaxDefer != "" {
+		if d, err := time.ParseDuration(throttle.MaxDefer); err != nil || d < 0 {
+			return fmt.Errorf("invalid tasks throttle max_defer %q: must be a non-negative duration", throttle.MaxDefer)
+		}
+	}
+	timezones := map[string]string{
+		"alerts timezone": cfg.Alerts.Timezone,
+		"tasks timezone":  cfg.Tasks.Timezone,
+	}
+	for channel, tz := range cfg.Alerts.ChannelTimezones {
+		timezones[fmt.Sprintf("alerts channel_timezones entry %q", channel)] = tz
+	}
+	for name, tz := range timezones {
+		if tz == "" {
+			continue
+		}
+		if _, err := time.LoadLocation(tz); err != nil {
+			return fmt.Errorf("invalid %s: %w", name, err)
+		}
+	}
+	for prefix, limit := range cfg.Server.BodyLimits {
+		if limit < 0 {
+			return fmt.Errorf("invalid server body_limits entry %q: must not be negative", prefix)
+		}
+	}
+	if rw := cfg.Ingest.RemoteWrite; rw.Retention != "" {
+		if _, err := time.ParseDuration(rw.Retention); err != nil {
+			return fmt.Errorf("invalid ingest remote_write retention: %w", err)
+		}
+	}
+	if cfg.Ingest.RemoteWrite.MaxSeries < 0 {
+		return errors.New("invalid ingest remote_write max_series: must not be negative")
+	}
+	for _, pattern := range cfg.Ingest.RemoteWrite.Metrics {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid ingest remote_write metrics pattern %q: %w", pattern, err)
+		}
+	}
+	if err := validateExporters(cfg.Metrics.Exporters); err != nil {
+		return err
+	}
+	if cfg.APIMetrics.Window != "" {
+		if d, err := time.ParseDuration(cfg.APIMetrics.Window); err != nil || d < time.Minute {
+			return fmt.Errorf("invalid api_metrics window %q: must be a duration of at least 1m", cfg.APIMetrics.Window)
+		}
+	}
+	if cfg.APIMetrics.MaxKeys < 0 {
+		return errors.New("invalid api_metrics max_keys: must not be negative")
+	}
+	if cfg.ResponseCache.TTL != "" {
+		if d, err := time.ParseDuration(cfg.ResponseCache.TTL); err != nil || d <= 0 {
+			return fmt.Errorf("invalid response_cache ttl %q: must be a positive duration", cfg.ResponseCache.TTL)
+		}
+	}
+	if cfg.ResponseCache.MaxEntries < 0 {
+		return errors.New("invalid response_cache max_entries: must not be negative")
+	}
+	for _, prefix := range cfg.ResponseCache.Paths {
+		if !strings.HasPrefix(prefix, "/") {
+			return fmt.Errorf("invalid response_cache path %q: must start with /", prefix)
+		}
+	}
+	// Drivers register at startup, so only the form of the name is checked here
+	if strings.ContainsFunc(cfg.Storage.Driver, func(r rune) bool {
+		return (r < 'a' || r > 'z') && (r < '0' || r > '9') && r != '_' && r != '-'
+	}) {
+		return fmt.Errorf("invalid storage driver %q: expected a driver name such as file or sqlite", cfg.Storage.Driver)
+	}
+	if cfg.Storage.BudgetBytes < 0 {
+		return errors.New("invalid storage budget_bytes: must not be negative")
+	}
+	if p := cfg.Storage.BudgetWarnPercent; p < 0 || p > 100 {
+		return fmt.Errorf("invalid storage budget_warn_percent %v: must be between 0 and 100", p)
+	}
+	if cfg.Storage.BudgetCheckInterval != "" {
+		if d, err := time.ParseDuration(cfg.Storage.BudgetCheckInterval); err != nil || d <= 0 {
+			return fmt.Errorf("invalid storage budget_check_interval %q: must be a positive duration", cfg.Storage.BudgetCheckInterval)
+		}
+	}
+	if cfg.Retention.Interval != "" {
+		if d, err := time.ParseDuration(cfg.Retention.Interval); err != nil || d <= 0 {
+			return fmt.Errorf("invalid retention interval %q: must be a positive duration", cfg.Retention.Interval)
+		}
+	}
+	for kind, maxAge := range cfg.Retention.Policies {
+		if _, err := retention.ParseKind(kind); err != nil {
+			return fmt.Errorf("invalid retention policies entry: %w", err)
+		}
+		if d, err := time.ParseDuration(maxAge); err != nil || d < 0 {
+			return fmt.Errorf("invalid retention policies entry %q: %q must be a non-negative duration", kind, maxAge)
+		}
+	}
+	if cfg.Hosts.StaleAfter != "" {
+		if d, err := time.ParseDuration(cfg.Hosts.StaleAfter); err != nil || d < time.Second {
+			return fmt.Errorf("invalid hosts stale_after %q: must be a duration of at least 1s", cfg.Hosts.StaleAfter)
+		}
+	}
+	if t := cfg.Hosts.EnrollmentToken; t != "" && len(t) < 16 {
+		return errors.New("invalid hosts enrollment_token: must be at least 16 characters")
+	}
+	if cfg.LogWatch.RecentMatches < 0 {
+		return errors.New("invalid log_watch recent_matches: must not be negative")
+	}
+	watchNames := make(map[string]bool, len(cfg.LogWatch.Watches))
+	for _, watch := range cfg.LogWatch.Watches {
+		if err := watch.Validate(); err != nil {
+			return fmt.Errorf("invalid log_watch watches entry %q: %w", watch.Name, err)
+		}
+		if watchNames[watch.Name] {
+			return fmt.Errorf("invalid log_watch watches entry %q: duplicate name", watch.Name)
+		}
+		if watch.Source == logwatch.SourceSyslog && !cfg.Syslog.Enabled {
+			return fmt.Errorf("invalid log_watch watches entry %q: the syslog source requires syslog.enabled", watch.Name)
+		}
+		watchNames[watch.Name] = true
+	}
+	if cfg.Syslog.Enabled && cfg.Syslog.UDPAddress == "" && cfg.Syslog.TCPAddress == "" {
+		return errors.New("invalid syslog: udp_address or tcp_address is required")
+	}
+	if cfg.Syslog.BufferSize < 0 {
+		return errors.New("invalid syslog buffer_size: must not be negative")
+	}
+	if n := cfg.Syslog.MaxMessageSize; n != 0 && (n < logwatch.MinSyslogMessageSize || n > 1<<20) {
+		return fmt.Errorf("invalid syslog max_message_size %d: must be between %d and 1048576", n, logwatch.MinSyslogMessageSize)
+	}
+	if n := cfg.StatusPage.HistoryDays; n < 0 || n > 366 {
+		return fmt.Errorf("invalid status_page history_days %d: must be between 0 and 366", n)
+	}
+	componentGroups := make(map[string]bool, len(cfg.StatusPage.Components))
+	for _, component := range cfg.StatusPage.Components {
+		if component.Group == "" {
+			return fmt.Errorf("invalid status_page components entry %q: group is required", component.Name)
+		}
+		if componentGroups[component.Group] {
+			return fmt.Errorf("invalid status_page components entry %q: duplicate group", component.Group)
+		}
+		componentGroups[component.Group] = true
+	}
+	if cfg.StatusPage.Enabled && len(cfg.StatusPage.Components) == 0 {
+		return errors.New("invalid status_page: at least one component is required")
+	}
+	if cfg.Logging.RepeatInterval != "" {
+		if d, err := time.ParseDuration(cfg.Logging.RepeatInterval); err != nil || d <= 0 {
+			return fmt.Errorf("invalid logging repeat_interval %q: must be a positive duration", cfg.Logging.RepeatInterval)
+		}
+	}
+	if cfg.Incidents.Window != "" {
+		if d, err := time.ParseDuration(cfg.Incidents.Window); err != nil || d <= 0 {
+			return fmt.Errorf("invalid incidents window %q: must be a positive duration", cfg.Incidents.Window)
+		}
+	}
+	if err := cfg.ProxyFor("").Validate(); err != nil {
+		return fmt.Errorf("invalid proxy: %w", err)
+	}
+	for channel := range cfg.Proxy.Channels {
+		if !slices.Contains(OutboundChannels, channel) {
+			return fmt.Errorf("invalid proxy channels entry %q: must be one of %s", channel, strings.Join(OutboundChannels, ", "))
+		}
+		if err := cfg.ProxyFor(channel).Validate(); err != nil {
+			return fmt.Errorf("invalid proxy channels entry %q: %w", channel, err)
+		}
+	}
+	if err := cfg.TLSFor("").Validate(); err != nil {
+		return fmt.Errorf("invalid outbound_tls: %w", err)
+	}
+	for channel := range cfg.OutboundTLS.Channels {
+		if !slices.Contains(OutboundChannels, channel) {
+			return fmt.Errorf("invalid outbound_tls channels entry %q: must be one of %s", channel, strings.Join(OutboundChannels, ", "))
+		}
+		if err := cfg.TLSFor(channel).Validate(); err != nil {
+			return fmt.Errorf("invalid outbound_tls channels entry %q: %w", channel, err)
+		}
+	}
+	if cfg.OutboundTLS.ExpiryWarning != "" {
+		if d, err := time.ParseDuration(cfg.OutboundTLS.ExpiryWarning); err != nil || d <= 0 {
+			return fmt.Errorf("invalid outbound_tls expiry_warning %q: must be a positive duration", cfg.OutboundTLS.ExpiryWarning)
+		}
+	}
+	if cfg.Incidents.Lookback != "" {
+		if d, err := time.ParseDuration(cfg.Incidents.Lookback); err != nil || d <= 0 {
+			return fmt.Errorf("invalid incidents lookback %q: must be a positive duration", cfg.Incidents.Lookback)
+		}
+	}
+	if err := validateUpdate(cfg); err != nil {
+		return err
+	}
+	if err := validateAuth(cfg); err != nil {
+		return err
+	}
+	if err := validateSecurityHeaders(cfg.SecurityHeaders); err != nil {
+		return err
+	}
+	if err := features.Validate(cfg.Features); err != nil {
+		return fmt.Errorf("invalid features: %w", err)
+	}
+	if _, err := cfg.Redactor(); err != nil {
+		return err
+	}
+	return nil
+}
+
+// Redactor builds the redactor described by the redaction section.
+// It returns nil (no redaction) when redaction is disabled.
+func (cfg *Config) Redactor() (*redact.Redactor, error) {
+	if !cfg.Redaction.Enabled {
+		return nil, nil
+	}
+	var rules []redact.Rule
+	if cfg.Redaction.DefaultRules {
+		rules = append(rules, redact.DefaultRules...)
+	}
+	rules = append(rules, cfg.Redaction.Rules...)
+	return redact.New(rules)
+}
+
+// ProvisionedAlertConfigs returns the alerts of the provisioned_alerts
+// section, created or updated at now. Unless configured otherwise, they are
+// enabled and notify in-app.
+func (cfg *Config) ProvisionedAlertConfigs(now time.Time) ([]*models.AlertConfig, error) {
+	alerts := make([]*models.AlertConfig, 0, len(cfg.ProvisionedAlerts))
+	ids := make(map[string]bool, len(cfg.ProvisionedAlerts))
+	for i, p := range cfg.ProvisionedAlerts {
+		alert := &models.AlertConfig{
+			Enabled:       true,
+			Notifications: []models.NotificationConfig{{Type: models.NotificationInApp, Enabled: true}},
+		}
+		if p.Template != "" {
+			var err error
+			if alert, err = models.CreateDefaultAlertConfig(p.Template, now); err != nil {
+				return nil, fmt.Errorf("invalid provisioned_alerts[%d] template: %w", i, err)
+			}
+		}
+		if len(p.Fields) > 0 {
+			// Nested YAML mappings decode as map[string]interface{}, which
+			// encode as the JSON objects of the alerts API
+			fields, err := json.Marshal(p.Fields)
+			if err == nil {
+				err = alert.Overlay(fields)
+			}
+			if err != nil {
+				return nil, fmt.Errorf("invalid provisioned_alerts[%d]: %w", i, err)
+			}
+		}
+		if alert.ID == "" {
+			return nil, fmt.Errorf("invalid provisioned_alerts[%d]: an id is required without a template", i)
+		}
+		if ids[alert.ID] {
+			return nil, fmt.Errorf("invalid provisioned_alerts %q: duplicate id", alert.ID)
+		}
+		ids[alert.ID] = true
+		alert.CreatedAt, alert.UpdatedAt = now, now
+		if err := alert.Validate(); err != nil {
+			return nil, fmt.Errorf("invalid provisioned_alerts %q: %w", alert.ID, err)
+		}
+		for j := range alert.Notifications {
+			if err := alert.Notifications[j].Validate(); err != nil {
+				return nil, fmt.Errorf("invalid provisioned_alerts %q notification: %w", alert.ID, err)
+			}
+		}
+		alerts = append(alerts, alert)
+	}
+	return alerts, nil
+}
+
+// WebhookQueuePath returns the directory of the webhook delivery queue,
+// webhook-queue under the alerts storage path unless configured
+func (cfg *Config) WebhookQueuePath() string {
+	if cfg.Alerts.Webhook.QueuePath != "" {
+		return cfg.Alerts.Webhook.QueuePath
+	}
+	return filepath.Join(cfg.Alerts.StoragePath, "webhook-queue")
+}
+
+// RemediationAuditPath returns the audit log of alert remediations,
+// remediation-audit.jsonl under the alerts storage path
+func (cfg *Config) RemediationAuditPath() string {
+	return filepath.Join(cfg.Alerts.StoragePath, "remediation-audit.jsonl")
+}
+
+// JiraIssuesPath returns the file recording the jira issues opened for
+// alerts, jira-issues.json under the alerts storage path
+func (cfg *Config) JiraIssuesPath() string {
+	return filepath.Join(cfg.Alerts.StoragePath, "jira-issues.json")
+}
+
+// SQLiteDatabasePath returns the SQLite database of storage.driver sqlite,
+// argus.db under the storage base path unless configured
+func (cfg *Config) SQLiteDatabasePath() string {
+	if cfg.Storage.SQLitePath != "" {
+		return cfg.Storage.SQLitePath
+	}
+	return filepath.Join(cfg.Storage.BasePath, "argus.db")
+}
+
+// StorageDriver returns the storage driver of alerts and tasks, file unless
+// configured
+func (cfg *Config) StorageDriver() string {
+	if cfg.Storage.Driver == "" {
+		return "file"
+	}
+	return cfg.Storage.Driver
+}
+
+// StorageDriverOptions returns the options the storage driver is opened with:
+// storage.options, with the "path" of the sqlite driver defaulting to
+// SQLiteDatabasePath
+func (cfg *Config) StorageDriverOptions() map[string]string {
+	options := make(map[string]string, len(cfg.Storage.Options)+1)
+	for k, v := range cfg.Storage.Options {
+		options[k] = v
+	}
+	if cfg.StorageDriver() == "sqlite" && options["path"] == "" {
+		options["path"] = cfg.SQLiteDatabasePath()
+	}
+	return options
+}
+
+// ProxyFor returns the proxy of an outbound integration, one of
+// OutboundChannels: its own entry under proxy.channels, or the global proxy
+func (cfg *Config) ProxyFor(channel string) netproxy.Config {
+	p, ok := cfg.Proxy.Channels[channel]
+	if !ok {
+		p = cfg.Proxy.ProxyConfig
+	}
+	return netproxy.Config{URL: p.URL, NoProxy: p.NoProxy}
+}
+
+// TLSFor returns the TLS settings of an outbound integration, one of
+// OutboundChannels: its own entry under outbound_tls.channels, or the
+// global settings
+func (cfg *Config) TLSFor(channel string) tlsclient.Config {
+	t, ok := cfg.OutboundTLS.Channels[channel]
+	if !ok {
+		t = cfg.OutboundTLS.TLSConfig
+	}
+	return tlsclient.Config{CAFile: t.CAFile, CertFile: t.CertFile, KeyFile: t.KeyFile}
+}
+
+// OutboundTransport returns the HTTP transport of an outbound integration,
+// one of OutboundChannels, with its proxy and TLS settings
+func (cfg *Config) OutboundTransport(channel string) (*http.Transport, error) {
+	transport, err := cfg.ProxyFor(channel).Transport()
+	if err != nil {
+		return nil, err
+	}
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+	if err := cfg.TLSFor(channel).Apply(tlsConfig); err != nil {
+		return nil, err
+	}
+	transport.TLSClientConfig = tlsConfig
+	return transport, nil
+}
+
+// ClientCertificates returns the configured outbound client certificates by
+// the name they are reported under: "default" for the global one, or the
+// channel overriding it
+func (cfg *Config) ClientCertificates() map[string]tlsclient.Config {
+	certs := make(map[string]tlsclient.Config)
+	if cfg.OutboundTLS.CertFile != "" {
+		certs["default"] = cfg.TLSFor("")
+	}
+	for channel, t := range cfg.OutboundTLS.Channels {
+		if t.CertFile != "" {
+			certs[channel] = cfg.TLSFor(channel)
+		}
+	}
+	return certs
+}
+
+// TemplateLimits returns the execution limits of user-provided templates;
+// unset ones are left to the tmplfunc defaults
+func (cfg *Config) TemplateLimits() tmplfunc.Limits {
+	limits := tmplfunc.Limits{MaxOutput: cfg.Alerts.TemplateSandbox.MaxOutputBytes}
+	if d, err := time.ParseDuration(cfg.Alerts.TemplateSandbox.Timeout); err == nil {
+		limits.Timeout = d
+	}
+	return limits
+}
+
+// checkChannelTemplate parses a channel template in the sandbox and renders it
+// for a sample alert in each state it applies to, so templates that fail or
+// exceed the limits are reported when they are saved rather than when an
+// alert fires
+func checkChannelTemplate(tmpl ChannelTemplate, limits tmplfunc.Limits) error {
+	severity := models.SeverityCritical
+	if tmpl.Severity != "" {
+		severity = models.AlertSeverity(tmpl.Severity)
+	}
+	states := ChannelTemplateStates
+	if tmpl.State != "" {
+		states = []string{tmpl.State}
+	}
+	for _, part := range []struct{ name, text string }{{"subject", tmpl.Subject}, {"body", tmpl.Body}} {
+		t, err := tmplfunc.ParseSandboxed(part.name, part.text, limits)
+		if err != nil {
+			return fmt.Errorf("%s: %w", part.name, err)
+		}
+		for _, state := range states {
+			event := models.SampleAlertEvent(severity, models.AlertState(state), time.Now())
+			if _, err := tmplfunc.Execute(t, event, limits); err != nil {
+				return fmt.Errorf("%s: rendering a sample %s alert: %w", part.name, state, err)
+			}
+		}
+	}
+	return nil
+}
+
+// validateJira checks the jira channel settings once a URL enables it
+func validateJira(j JiraConfig) error {
+	if j.URL == "" {
+		return nil
+	}
+	if u, err := url.Parse(j.URL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("invalid alerts jira url %q: must be an http or https URL", j.URL)
+	}
+	token, err := j.Token()
+	if err != nil {
+		return fmt.Errorf("invalid alerts jira api_token_file: %w", err)
+	}
+	if token == "" {
+		return errors.New("invalid alerts jira: an api_token, api_token_file or ARGUS_ALERTS_JIRA_API_TOKEN is required")
+	}
+	if j.Timeout != "" {
+		if d, err := time.ParseDuration(j.Timeout); err != nil || d <= 0 {
+			return fmt.Errorf("invalid alerts jira timeout %q: must be a positive duration", j.Timeout)
+		}
+	}
+	return nil
+}
+
+// validateUpdate checks the endpoint and interval of an enabled update check
+func validateUpdate(cfg *Config) error {
+	if !cfg.Update.Enabled {
+		return nil
+	}
+	if u, err := url.Parse(cfg.Update.URL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("invalid update url %q: must be an http or https URL", cfg.Update.URL)
+	}
+	if d, err := time.ParseDuration(cfg.Update.Interval); err != nil || d < time.Minute {
+		return fmt.Errorf("invalid update interval %q: must be a duration of at least 1m", cfg.Update.Interval)
+	}
+	return nil
+}
+
+// validateExporters checks the metrics exporters, whose names must be unique
+func validateExporters(exporters []ExporterConfig) error {
+	names := make(map[string]bool, len(exporters))
+	for i, e := range exporters {
+		name := e.Name
+		if name == "" {
+			name = e.Format
+		}
+		if !slices.Contains(ExporterFormats, e.Format) {
+			return fmt.Errorf("invalid metrics exporter %d format %q: must be one of %s", i, e.Format, strings.Join(ExporterFormats, ", "))
+		}
+		if names[name] {
+			return fmt.Errorf("invalid metrics exporter %q: the name is used twice; give each exporter a name", name)
+		}
+		names[name] = true
+		if u, err := url.Parse(e.URL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("invalid metrics exporter %q url %q: must be an http or https URL", name, e.URL)
+		}
+		for _, d := range []struct{ field, value string }{{"interval", e.Interval}, {"timeout", e.Timeout}} {
+			if d.value == "" {
+				continue
+			}
+			if v, err := time.ParseDuration(d.value); err != nil || v <= 0 {
+				return fmt.Errorf("invalid metrics exporter %q %s %q: must be a positive duration", name, d.field, d.value)
+			}
+		}
+		for _, pattern := range e.Metrics {
+			if _, err := path.Match(pattern, ""); err != nil {
+				return fmt.Errorf("invalid metrics exporter %q metrics pattern %q: %w", name, pattern, err)
+			}
+		}
+	}
+	return nil
+}
+
+// validateAuth checks the enabled login providers and the role mapping
+func validateAuth(cfg *Config) error {
+	if o := cfg.Auth.OIDC; o.Enabled {
+		if u, err := url.Parse(o.Issuer); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("invalid auth oidc issuer %q: must be an http or https URL", o.Issuer)
+		}
+		if o.ClientID == "" {
+			return errors.New("invalid auth oidc: a client_id is required")
+		}
+		if u, err := url.Parse(o.RedirectURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("invalid auth oidc redirect_url %q: must be an http or https URL", o.RedirectURL)
+		}
+	}
+	if l := cfg.Auth.LDAP; l.Enabled {
+		if u, err := url.Parse(l.URL); err != nil || (u.Scheme != "ldap" && u.Scheme != "ldaps") || u.Host == "" {
+			return fmt.Errorf("invalid auth ldap url %q: must be an ldap or ldaps URL", l.URL)
+		}
+		if l.BaseDN == "" {
+			return errors.New("invalid auth ldap: a base_dn is required")
+		}
+		if l.Timeout != "" {
+			if d, err := time.ParseDuration(l.Timeout); err != nil || d <= 0 {
+				return fmt.Errorf("invalid auth ldap timeout %q: must be a positive duration", l.Timeout)
+			}
+		}
+		if _, err := (tlsclient.Config{CAFile: l.CAFile}).RootCAs(); err != nil {
+			return fmt.Errorf("invalid auth ldap ca_file: %w", err)
+		}
+	}
+	if _, err := cfg.RoleMapping(); err != nil {
+		return err
+	}
+	if _, err := cfg.SessionConfig(); err != nil {
+		return err
+	}
+	if cfg.Auth.APIKeyHeader != "" && !validToken(cfg.Auth.APIKeyHeader) {
+		return fmt.Errorf("invalid auth api_key_header %q", cfg.Auth.APIKeyHeader)
+	}
+	if _, err := cfg.APIKeys(); err != nil {
+		return err
+	}
 	return nil
 }
+
+// minAPIKeyLength is the length below which keys are too easily guessed
+const minAPIKeyLength = 16
+
+// APIKeys returns the configured API keys, reading key files
+func (cfg *Config) APIKeys() ([]auth.APIKey, error) {
+	keys := make([]auth.APIKey, 0, len(cfg.Auth.APIKeys))
+	names := make(map[string]bool, len(cfg.Auth.APIKeys))
+	for i, k := range cfg.Auth.APIKeys {
+		if k.Name == "" {
+			return nil, fmt.Errorf("invalid auth api_keys[%d]: a name is required", i)
+		}
+		if names[k.Name] {
+			return nil, fmt.Errorf("invalid auth api_keys %q: duplicate name", k.Name)
+		}
+		names[k.Name] = true
+		key := auth.APIKey{Name: k.Name, Role: auth.RoleViewer}
+		if k.Role != "" {
+			role, err := auth.ParseRole(k.Role)
+			if err != nil || role == auth.RoleNone {
+				return nil, fmt.Errorf("invalid auth api_keys %q role %q: expected viewer, operator or admin", k.Name, k.Role)
+			}
+			key.Role = role
+		}
+		set := 0
+		for _, v := range []string{k.Key, k.KeyFile, k.SHA256} {
+			if v != "" {
+				set++
+			}
+		}
+		if set != 1 {
+			return nil, fmt.Errorf("invalid auth api_keys %q: set one of key, key_file or sha256", k.Name)
+		}
+		switch {
+		case k.SHA256 != "":
+			hash, err := auth.ParseAPIKeyHash(strings.TrimSpace(k.SHA256))
+			if err != nil {
+				return nil, fmt.Errorf("invalid auth api_keys %q sha256: %w", k.Name, err)
+			}
+			key.Hash = hash
+		default:
+			secret := k.Key
+			if k.KeyFile != "" {
+				data, err := os.ReadFile(k.KeyFile)
+				if err != nil {
+					return nil, fmt.Errorf("invalid auth api_keys %q key_file: %w", k.Name, err)
+				}
+				secret = strings.TrimSpace(string(data))
+			}
+			if len(secret) < minAPIKeyLength {
+				return nil, fmt.Errorf("invalid auth api_keys %q: the key must have at least %d characters", k.Name, minAPIKeyLength)
+			}
+			key.Hash = auth.HashAPIKey(secret)
+		}
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+// SessionConfig returns the settings of dashboard sessions
+func (cfg *Config) SessionConfig() (auth.SessionConfig, error) {
+	s := cfg.Auth.Sessions
+	config := auth.SessionConfig{CookieName: s.CookieName, CookiePath: s.CookiePath, CookieDomain: s.CookieDomain}
+	if s.TTL != "" {
+		d, err := time.ParseDuration(s.TTL)
+		if err != nil || d < time.Minute {
+			return auth.SessionConfig{}, fmt.Errorf("invalid auth sessions ttl %q: must be a duration of at least 1m", s.TTL)
+		}
+		config.TTL = d
+	}
+	if s.IdleTimeout != "" {
+		d, err := time.ParseDuration(s.IdleTimeout)
+		if err != nil || d < 0 {
+			return auth.SessionConfig{}, fmt.Errorf("invalid auth sessions idle_timeout %q: must be a duration, 0s for none", s.IdleTimeout)
+		}
+		config.IdleTimeout = d
+		if d == 0 {
+			config.IdleTimeout = -1
+		}
+	}
+	if s.CookieName != "" && !validToken(s.CookieName) {
+		return auth.SessionConfig{}, fmt.Errorf("invalid auth sessions cookie_name %q", s.CookieName)
+	}
+	if s.CookiePath != "" && !strings.HasPrefix(s.CookiePath, "/") {
+		return auth.SessionConfig{}, fmt.Errorf("invalid auth sessions cookie_path %q: must start with /", s.CookiePath)
+	}
+	switch strings.ToLower(s.Secure) {
+	case "", auth.SecureAuto, auth.SecureAlways, auth.SecureNever:
+		config.Secure = strings.ToLower(s.Secure)
+	default:
+		return auth.SessionConfig{}, fmt.Errorf("invalid auth sessions secure %q: expected auto, always or never", s.Secure)
+	}
+	switch strings.ToLower(s.SameSite) {
+	case "", "lax":
+		config.SameSite = http.SameSiteLaxMode
+	case "strict":
+		config.SameSite = http.SameSiteStrictMode
+	default:
+		return auth.SessionConfig{}, fmt.Errorf("invalid auth sessions same_site %q: expected lax or strict", s.SameSite)
+	}
+	return config, nil
+}
+
+// validateSecurityHeaders checks the HSTS max age, frame options and policy
+func validateSecurityHeaders(h SecurityHeadersConfig) error {
+	if h.HSTSMaxAge != "" {
+		if d, err := time.ParseDuration(h.HSTSMaxAge); err != nil || d < 0 {
+			return fmt.Errorf("invalid security_headers hsts_max_age %q: must be a duration, 0s for none", h.HSTSMaxAge)
+		}
+	}
+	switch strings.ToLower(h.FrameOptions) {
+	case "", "deny", "sameorigin":
+	default:
+		return fmt.Errorf("invalid security_headers frame_options %q: expected deny or sameorigin", h.FrameOptions)
+	}
+	for _, r := range h.ContentSecurityPolicy {
+		if r < ' ' || r >= 0x7f {
+			return fmt.Errorf("invalid security_headers content_security_policy: must be printable ASCII on one line")
+		}
+	}
+	return nil
+}
+
+// validToken reports whether name is a token, as cookie and header names must
+// be
+func validToken(name string) bool {
+	for _, r := range name {
+		if r <= ' ' || r >= 0x7f || strings.ContainsRune(`()<>@,;:\"/[]?={}`, r) {
+			return false
+		}
+	}
+	return true
+}
+
+// RoleMapping returns the roles of users by the groups providers report
+func (cfg *Config) RoleMapping() (auth.RoleMapping, error) {
+	mapping := auth.RoleMapping{Groups: make(map[string]auth.Role, len(cfg.Auth.Roles.Groups))}
+	if cfg.Auth.Roles.Default != "" {
+		role, err := auth.ParseRole(cfg.Auth.Roles.Default)
+		if err != nil {
+			return auth.RoleMapping{}, fmt.Errorf("invalid auth roles default: %w", err)
+		}
+		mapping.Default = role
+	}
+	for group, name := range cfg.Auth.Roles.Groups {
+		role, err := auth.ParseRole(name)
+		if err != nil {
+			return auth.RoleMapping{}, fmt.Errorf("invalid auth roles group %q: %w", group, err)
+		}
+		mapping.Groups[group] = role
+	}
+	return mapping, nil
+}
+
+// Token returns the API token of the jira channel: api_token (or its
+// environment override), else the content of api_token_file
+func (j JiraConfig) Token() (string, error) {
+	if j.APIToken != "" || j.APITokenFile == "" {
+		return j.APIToken, nil
+	}
+	data, err := os.ReadFile(j.APITokenFile)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(data)), nil
+}
+
+// CertExpiryWarning returns how long before their end client certificates
+// are reported as expiring
+func (cfg *Config) CertExpiryWarning() time.Duration {
+	if d, err := time.ParseDuration(cfg.OutboundTLS.ExpiryWarning); err == nil && d > 0 {
+		return d
+	}
+	return DefaultCertExpiryWarning
+}
+
+// RollupResolution is a metric history rollup step and its retention (0 keeps
+// forever)
+type RollupResolution struct {
+	Step      time.Duration
+	Retention time.Duration
+}
+
+// RollupResolutions parses monitoring.rollups into resolutions sorted by step,
+// each a multiple of the previous one. It returns none when rollups are disabled.
+func (cfg *Config) RollupResolutions() ([]RollupResolution, error) {
+	if !cfg.Monitoring.RollupsEnabled {
+		return nil, nil
+	}
+	var resolutions []RollupResolution
+	for step, keep := range cfg.Monitoring.Rollups {
+		d, err := time.ParseDuration(step)
+		if err != nil || d < time.Second {
+			return nil, fmt.Errorf("invalid monitoring rollups step %q: must be a duration of at least 1s", step)
+		}
+		if keep == "off" {
+			continue
+		}
+		retention, err := time.ParseDuration(keep)
+		if err != nil || retention < 0 {
+			return nil, fmt.Errorf("invalid monitoring rollups entry %q: %q must be a non-negative duration or off", step, keep)
+		}
+		resolutions = append(resolutions, RollupResolution{Step: d, Retention: retention})
+	}
+	sort.Slice(resolutions, func(i, j int) bool { return resolutions[i].Step < resolutions[j].Step })
+	for i := 1; i < len(resolutions); i++ {
+		if prev, cur := resolutions[i-1].Step, resolutions[i].Step; cur == prev || cur%prev != 0 {
+			return nil, fmt.Errorf("invalid monitoring rollups step %s: must be a multiple of %s", cur, prev)
+		}
+	}
+	return resolutions, nil
+}
@@ -1,12 +1,28 @@
 package config
 
 import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net/http"
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+
+	"argus/internal/auth"
+	"argus/internal/features"
+	"argus/internal/models"
+	"argus/internal/netproxy"
+	"argus/internal/tlsclient"
+	"argus/internal/tmplfunc"
 )
 
 func TestLoadConfig_FromFile(t *testing.T) {
@@ -103,10 +119,18 @@ func TestValidateConfig(t *testing.T) {
 			name: "Valid config",
 			config: &Config{
 				Server: struct {
-					Port         int    `yaml:"port"`
-					Host         string `yaml:"host"`
-					ReadTimeout  string `yaml:"read_timeout"`
-					WriteTimeout string `yaml:"write_timeout"`
+					Port                 int              `yaml:"port"`
+					Host                 string           `yaml:"host"`
+					ReadTimeout          string           `yaml:"read_timeout"`
+					WriteTimeout         string           `yaml:"write_timeout"`
+					IdleTimeout          string           `yaml:"idle_timeout"`
+					ReadHeaderTimeout    string           `yaml:"read_header_timeout"`
+					MaxHeaderBytes       int              `yaml:"max_header_bytes"`
+					MaxBodyBytes         int64            `yaml:"max_body_bytes"`
+					BodyLimits           map[string]int64 `yaml:"body_limits"`
+					SlowRequestThreshold string           `yaml:"slow_request_threshold"`
+					ReadOnly             bool             `yaml:"read_only"`
+					UserHeader           string           `yaml:"user_header"`
 				}{
 					Host:         "localhost",
 					Port:         8080,
@@ -120,10 +144,18 @@ func TestValidateConfig(t *testing.T) {
 			name: "Invalid server config - invalid port",
 			config: &Config{
 				Server: struct {
-					Port         int    `yaml:"port"`
-					Host         string `yaml:"host"`
-					ReadTimeout  string `yaml:"read_timeout"`
-					WriteTimeout string `yaml:"write_timeout"`
+					Port                 int              `yaml:"port"`
+					Host                 string           `yaml:"host"`
+					ReadTimeout          string           `yaml:"read_timeout"`
+					WriteTimeout         string           `yaml:"write_timeout"`
+					IdleTimeout          string           `yaml:"idle_timeout"`
+					ReadHeaderTimeout    string           `yaml:"read_header_timeout"`
+					MaxHeaderBytes       int              `yaml:"max_header_bytes"`
+					MaxBodyBytes         int64            `yaml:"max_body_bytes"`
+					BodyLimits           map[string]int64 `yaml:"body_limits"`
+					SlowRequestThreshold string           `yaml:"slow_request_threshold"`
+					ReadOnly             bool             `yaml:"read_only"`
+					UserHeader           string           `yaml:"user_header"`
 				}{
 					Host:         "localhost",
 					Port:         -1,
@@ -137,10 +169,18 @@ func TestValidateConfig(t *testing.T) {
 			name: "Invalid server config - invalid read timeout",
 			config: &Config{
 				Server: struct {
-					Port         int    `yaml:"port"`
-					Host         string `yaml:"host"`
-					ReadTimeout  string `yaml:"read_timeout"`
-					WriteTimeout string `yaml:"write_timeout"`
+					Port                 int              `yaml:"port"`
+					Host                 string           `yaml:"host"`
+					ReadTimeout          string           `yaml:"read_timeout"`
+					WriteTimeout         string           `yaml:"write_timeout"`
+					IdleTimeout          string           `yaml:"idle_timeout"`
+					ReadHeaderTimeout    string           `yaml:"read_header_timeout"`
+					MaxHeaderBytes       int              `yaml:"max_header_bytes"`
+					MaxBodyBytes         int64            `yaml:"max_body_bytes"`
+					BodyLimits           map[string]int64 `yaml:"body_limits"`
+					SlowRequestThreshold string           `yaml:"slow_request_threshold"`
+					ReadOnly             bool             `yaml:"read_only"`
+					UserHeader           string           `yaml:"user_header"`
 				}{
 					Host:         "localhost",
 					Port:         8080,
@@ -150,6 +190,32 @@ func TestValidateConfig(t *testing.T) {
 			},
 			expectError: true,
 		},
+		{
+			name: "Invalid server config - invalid slow request threshold",
+			config: &Config{
+				Server: struct {
+					Port                 int              `yaml:"port"`
+					Host                 string           `yaml:"host"`
+					ReadTimeout          string           `yaml:"read_timeout"`
+					WriteTimeout         string           `yaml:"write_timeout"`
+					IdleTimeout          string           `yaml:"idle_timeout"`
+					ReadHeaderTimeout    string           `yaml:"read_header_timeout"`
+					MaxHeaderBytes       int              `yaml:"max_header_bytes"`
+					MaxBodyBytes         int64            `yaml:"max_body_bytes"`
+					BodyLimits           map[string]int64 `yaml:"body_limits"`
+					SlowRequestThreshold string           `yaml:"slow_request_threshold"`
+					ReadOnly             bool             `yaml:"read_only"`
+					UserHeader           string           `yaml:"user_header"`
+				}{
+					Host:                 "localhost",
+					Port:                 8080,
+					ReadTimeout:          "30s",
+					WriteTimeout:         "30s",
+					SlowRequestThreshold: "soon",
+				},
+			},
+			expectError: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -164,6 +230,47 @@ func TestValidateConfig(t *testing.T) {
 	}
 }
 
+func TestLoadConfig_ServerLimits(t *testing.T) {
+	tmpDir := t.TempDir()
+	configPath := filepath.Join(tmpDir, "limits-config.yaml")
+
+	configContent := `
+server:
+  port: 8080
+  read_timeout: "10s"
+  write_timeout: "20s"
+  idle_timeout: "90s"
+  max_body_bytes: 1024
+  body_limits:
+    "/api/tasks": 2048
+  slow_request_threshold: "250ms"
+`
+	err := os.WriteFile(configPath, []byte(configContent), 0644)
+	require.NoError(t, err, "Failed to create test config file")
+
+	cfg, err := LoadConfig(configPath)
+	require.NoError(t, err, "Failed to load config")
+
+	assert.Equal(t, "90s", cfg.Server.IdleTimeout)
+	assert.Equal(t, "5s", cfg.Server.ReadHeaderTimeout, "unset values keep their defaults")
+	assert.Equal(t, 1<<20, cfg.Server.MaxHeaderBytes)
+	assert.Equal(t, int64(1024), cfg.Server.MaxBodyBytes)
+	assert.Equal(t, int64(2048), cfg.Server.BodyLimits["/api/tasks"])
+	assert.Equal(t, "250ms", cfg.Server.SlowRequestThreshold)
+}
+
+func TestLoadConfig_UserHeader(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(configPath, []byte("server:\n  user_header: X-Forwarded-User\n"), 0644))
+	cfg, err := LoadConfig(configPath)
+	require.NoError(t, err)
+	assert.Equal(t, "X-Forwarded-User", cfg.Server.UserHeader)
+
+	require.NoError(t, os.WriteFile(configPath, []byte("server:\n  user_header: \"X-User: admin\"\n"), 0644))
+	_, err = LoadConfig(configPath)
+	assert.ErrorContains(t, err, "invalid server user_header")
+}
+
 func TestLoadLocation(t *testing.T) {
 	// Test with valid timezone
 	tz := LoadLocation("America/New_York")
@@ -180,3 +287,1099 @@ func TestLoadLocation(t *testing.T) {
 	assert.NotNil(t, tz)
 	assert.Equal(t, "UTC", tz.String())
 }
+
+func TestLoadConfig_AlertLocale(t *testing.T) {
+	tmpDir := t.TempDir()
+	configPath := filepath.Join(tmpDir, "locale-config.yaml")
+
+	require.NoError(t, os.WriteFile(configPath, []byte("alerts:\n  locale: \"zh-TW\"\n"), 0644))
+	cfg, err := LoadConfig(configPath)
+	require.NoError(t, err)
+	assert.Equal(t, "zh-TW", cfg.Alerts.Locale)
+
+	require.NoError(t, os.WriteFile(configPath, []byte("alerts:\n  locale: \"klingon\"\n"), 0644))
+	_, err = LoadConfig(configPath)
+	assert.Error(t, err, "unsupported locales are rejected")
+}
+
+func TestLoadConfig_Timezones(t *testing.T) {
+	tmpDir := t.TempDir()
+	configPath := filepath.Join(tmpDir, "tz-config.yaml")
+
+	content := "alerts:\n  timezone: \"Asia/Taipei\"\n  channel_timezones:\n    email: \"Europe/Berlin\"\ntasks:\n  timezone: \"UTC\"\n"
+	require.NoError(t, os.WriteFile(configPath, []byte(content), 0644))
+	cfg, err := LoadConfig(configPath)
+	require.NoError(t, err)
+	assert.Equal(t, "Asia/Taipei", cfg.Alerts.Timezone)
+	assert.Equal(t, "Europe/Berlin", cfg.Alerts.ChannelTimezones["email"])
+	assert.Equal(t, "UTC", cfg.Tasks.Timezone)
+
+	require.NoError(t, os.WriteFile(configPath, []byte("tasks:\n  timezone: \"Nowhere/City\"\n"), 0644))
+	_, err = LoadConfig(configPath)
+	assert.Error(t, err)
+
+	assert.Equal(t, time.Local, LoadLocationOrLocal(""))
+}
+
+func TestLoadConfig_ChannelTemplates(t *testing.T) {
+	tmpDir := t.TempDir()
+	configPath := filepath.Join(tmpDir, "templates-config.yaml")
+
+	content := "alerts:\n  channel_templates:\n    email:\n      - subject: \"{{ .Alert.Name }}\"\n        body: \"{{ markdown .Alert.Description }} {{ humanizeDuration 90 }}\"\n      - severity: critical\n        state: resolved\n        subject: \"OK\"\n        body: \"ok\"\n"
+	require.NoError(t, os.WriteFile(configPath, []byte(content), 0644))
+	cfg, err := LoadConfig(configPath)
+	require.NoError(t, err)
+	require.Len(t, cfg.Alerts.ChannelTemplates["email"], 2)
+	assert.Equal(t, ChannelTemplate{Severity: "critical", State: "resolved", Subject: "OK", Body: "ok"}, cfg.Alerts.ChannelTemplates["email"][1])
+
+	for _, entry := range []string{
+		"- state: pending\n        subject: a\n        body: b",
+		"- severity: fatal\n        subject: a\n        body: b",
+		"- subject: a",
+		"- subject: \"{{ .Alert.Name\"\n        body: b",
+	} {
+		require.NoError(t, os.WriteFile(configPath, []byte("alerts:\n  channel_templates:\n    email:\n      "+entry+"\n"), 0644))
+		_, err = LoadConfig(configPath)
+		assert.Error(t, err, entry)
+	}
+}
+
+func TestLoadConfig_TemplateSandbox(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "sandbox-config.yaml")
+
+	cfg, err := LoadConfig("")
+	require.NoError(t, err)
+	assert.Equal(t, tmplfunc.Limits{Timeout: time.Second, MaxOutput: 65536}, cfg.TemplateLimits())
+
+	write := func(sandbox, body string) error {
+		content := "alerts:\n  template_sandbox:\n" + sandbox + "  channel_templates:\n    webhook:\n      - subject: s\n        body: '" + body + "'\n"
+		require.NoError(t, os.WriteFile(configPath, []byte(content), 0644))
+		_, err := LoadConfig(configPath)
+		return err
+	}
+	assert.NoError(t, write("", "{{ .Alert.Name }} on {{ index .Alert.Labels \"host\" }}{{ with .Status.ResolvedAt }} until {{ .Format \"15:04\" }}{{ end }}"))
+	assert.ErrorContains(t, write("", "{{ call .Alert.Name }}"), "invalid alerts channel_templates entry webhook[0] body: body:1:3: the call function is not allowed")
+	assert.ErrorContains(t, write("", "{{ .Alert.Nmae }}"), "rendering a sample active alert")
+	assert.ErrorContains(t, write("", "{{ .Status.ResolvedAt.Format \"15:04\" }}"), "rendering a sample active alert")
+	assert.ErrorContains(t, write("    max_output_bytes: 100\n", "{{ range .Context.TopProcesses }}{{ printf \"%200s\" .Name }}{{ end }}"), "exceeds the 100 byte output limit")
+	assert.ErrorContains(t, write("    max_output_bytes: 100\n", "{{ range 200 }}xx{{ end }}"), "template output too large")
+	assert.ErrorContains(t, write("    timeout: \"0s\"\n", "ok"), "invalid alerts template_sandbox timeout")
+	assert.ErrorContains(t, write("    max_output_bytes: -1\n", "ok"), "invalid alerts template_sandbox max_output_bytes")
+}
+
+func TestLoadConfig_Redaction(t *testing.T) {
+	tmpDir := t.TempDir()
+	configPath := filepath.Join(tmpDir, "redaction-config.yaml")
+
+	content := "redaction:\n  enabled: true\n  default_rules: false\n  rules:\n    - name: ticket\n      pattern: 'INC-\\d+'\n"
+	require.NoError(t, os.WriteFile(configPath, []byte(content), 0644))
+	cfg, err := LoadConfig(configPath)
+	require.NoError(t, err)
+	r, err := cfg.Redactor()
+	require.NoError(t, err)
+	assert.Equal(t, 1, r.Len())
+	assert.Equal(t, "see [REDACTED]", r.Redact("see INC-42"))
+
+	require.NoError(t, os.WriteFile(configPath, []byte("redaction:\n  rules:\n    - pattern: '('\n"), 0644))
+	_, err = LoadConfig(configPath)
+	assert.Error(t, err, "invalid patterns are rejected at load time")
+
+	cfg = defaultConfig()
+	cfg.Redaction.Enabled = false
+	r, err = cfg.Redactor()
+	require.NoError(t, err)
+	assert.Nil(t, r)
+}
+
+func TestLoadConfig_Compression(t *testing.T) {
+	tmpDir := t.TempDir()
+	configPath := filepath.Join(tmpDir, "compression-config.yaml")
+
+	require.NoError(t, os.WriteFile(configPath, []byte("alerts:\n  history_compression: gzip\ntasks:\n  compression: gzip\n"), 0644))
+	cfg, err := LoadConfig(configPath)
+	require.NoError(t, err)
+	assert.Equal(t, "gzip", cfg.Alerts.HistoryCompression)
+	assert.Equal(t, "gzip", cfg.Tasks.Compression)
+
+	require.NoError(t, os.WriteFile(configPath, []byte("tasks:\n  compression: zstd\n"), 0644))
+	_, err = LoadConfig(configPath)
+	assert.Error(t, err, "zstd is not built in")
+}
+
+func TestLoadConfig_RemoteWrite(t *testing.T) {
+	tmpDir := t.TempDir()
+	configPath := filepath.Join(tmpDir, "ingest-config.yaml")
+
+	require.NoError(t, os.WriteFile(configPath, []byte("ingest:\n  remote_write:\n    enabled: true\n    metrics: [\"node_*\", \"up\"]\n"), 0644))
+	cfg, err := LoadConfig(configPath)
+	require.NoError(t, err)
+	assert.True(t, cfg.Ingest.RemoteWrite.Enabled)
+	assert.Equal(t, []string{"node_*", "up"}, cfg.Ingest.RemoteWrite.Metrics)
+	assert.Equal(t, "1h", cfg.Ingest.RemoteWrite.Retention)
+	assert.Equal(t, 10000, cfg.Ingest.RemoteWrite.MaxSeries)
+
+	require.NoError(t, os.WriteFile(configPath, []byte("ingest:\n  remote_write:\n    metrics: [\"node_[\"]\n"), 0644))
+	_, err = LoadConfig(configPath)
+	assert.Error(t, err)
+}
+
+func TestLoadConfig_APIMetrics(t *testing.T) {
+	tmpDir := t.TempDir()
+	configPath := filepath.Join(tmpDir, "api-metrics-config.yaml")
+
+	require.NoError(t, os.WriteFile(configPath, []byte("server:\n  port: 8080\n"), 0644))
+	cfg, err := LoadConfig(configPath)
+	require.NoError(t, err)
+	assert.True(t, cfg.APIMetrics.Enabled)
+	assert.Equal(t, "5m", cfg.APIMetrics.Window)
+	assert.Equal(t, 1000, cfg.APIMetrics.MaxKeys)
+
+	require.NoError(t, os.WriteFile(configPath, []byte("api_metrics:\n  window: \"30s\"\n"), 0644))
+	_, err = LoadConfig(configPath)
+	assert.Error(t, err)
+}
+
+func TestLoadConfig_ResponseCache(t *testing.T) {
+	tmpDir := t.TempDir()
+	configPath := filepath.Join(tmpDir, "response-cache-config.yaml")
+
+	require.NoError(t, os.WriteFile(configPath, []byte("server:\n  port: 8080\n"), 0644))
+	cfg, err := LoadConfig(configPath)
+	require.NoError(t, err)
+	assert.False(t, cfg.ResponseCache.Enabled)
+	assert.Equal(t, "2s", cfg.ResponseCache.TTL)
+	assert.Contains(t, cfg.ResponseCache.Paths, "/api/alerts")
+
+	require.NoError(t, os.WriteFile(configPath, []byte("response_cache:\n  ttl: \"soon\"\n"), 0644))
+	_, err = LoadConfig(configPath)
+	assert.Error(t, err)
+
+	require.NoError(t, os.WriteFile(configPath, []byte("response_cache:\n  paths: [\"api/tasks\"]\n"), 0644))
+	_, err = LoadConfig(configPath)
+	assert.Error(t, err)
+}
+
+func TestLoadConfig_ProcessStream(t *testing.T) {
+	tmpDir := t.TempDir()
+	configPath := filepath.Join(tmpDir, "process-stream-config.yaml")
+
+	require.NoError(t, os.WriteFile(configPath, []byte("websocket:\n  process_stream:\n    keyframe_every: 10\n"), 0644))
+	cfg, err := LoadConfig(configPath)
+	require.NoError(t, err)
+	assert.True(t, cfg.WebSocket.ProcessStream.Enabled)
+	assert.Equal(t, "1s", cfg.WebSocket.ProcessStream.Interval)
+	assert.Equal(t, 10, cfg.WebSocket.ProcessStream.KeyframeEvery)
+	assert.True(t, cfg.WebSocket.AlertStream.Enabled)
+
+	require.NoError(t, os.WriteFile(configPath, []byte("websocket:\n  process_stream:\n    interval: \"0s\"\n"), 0644))
+	_, err = LoadConfig(configPath)
+	assert.Error(t, err)
+}
+
+func TestLoadConfig_WebSocketOrigins(t *testing.T) {
+	tmpDir := t.TempDir()
+	configPath := filepath.Join(tmpDir, "websocket-config.yaml")
+
+	require.NoError(t, os.WriteFile(configPath, []byte("websocket:\n  enabled: true\n"), 0644))
+	cfg, err := LoadConfig(configPath)
+	require.NoError(t, err)
+	assert.Empty(t, cfg.WebSocket.AllowedOrigins, "only the server's own origin by default")
+
+	require.NoError(t, os.WriteFile(configPath, []byte("websocket:\n  allowed_origins: [\"https://dashboard.example.com\"]\n"), 0644))
+	cfg, err = LoadConfig(configPath)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"https://dashboard.example.com"}, cfg.WebSocket.AllowedOrigins)
+
+	for _, origin := range []string{"*", "dashboard.example.com", "https://dashboard.example.com/app"} {
+		require.NoError(t, os.WriteFile(configPath, []byte("websocket:\n  allowed_origins: [\""+origin+"\"]\n"), 0644))
+		_, err = LoadConfig(configPath)
+		assert.Error(t, err, origin)
+	}
+}
+
+func TestLoadConfig_ProcessBudget(t *testing.T) {
+	tmpDir := t.TempDir()
+	configPath := filepath.Join(tmpDir, "process-budget-config.yaml")
+
+	require.NoError(t, os.WriteFile(configPath, []byte("server:\n  port: 8080\n"), 0644))
+	cfg, err := LoadConfig(configPath)
+	require.NoError(t, err)
+	assert.Equal(t, 20, cfg.Monitoring.ProcessLimitMin)
+	assert.Equal(t, "1s", cfg.Monitoring.ProcessBudget)
+	assert.Equal(t, "5s", cfg.Monitoring.CallTimeout)
+	assert.False(t, cfg.Monitoring.ProcessDetails)
+
+	require.NoError(t, os.WriteFile(configPath, []byte("monitoring:\n  process_details: true\n"), 0644))
+	cfg, err = LoadConfig(configPath)
+	require.NoError(t, err)
+	assert.True(t, cfg.Monitoring.ProcessDetails)
+
+	require.NoError(t, os.WriteFile(configPath, []byte("monitoring:\n  process_budget: \"fast\"\n"), 0644))
+	_, err = LoadConfig(configPath)
+	assert.Error(t, err)
+
+	require.NoError(t, os.WriteFile(configPath, []byte("monitoring:\n  call_timeout: \"0s\"\n"), 0644))
+	_, err = LoadConfig(configPath)
+	assert.ErrorContains(t, err, "invalid monitoring call_timeout")
+}
+
+func TestLoadConfig_MetricsHistory(t *testing.T) {
+	tmpDir := t.TempDir()
+	configPath := filepath.Join(tmpDir, "history-config.yaml")
+
+	// Recorded without the Grafana datasource, which only serves it
+	require.NoError(t, os.WriteFile(configPath, []byte("server:\n  port: 8080\n"), 0644))
+	cfg, err := LoadConfig(configPath)
+	require.NoError(t, err)
+	assert.True(t, cfg.Monitoring.HistoryEnabled)
+	assert.False(t, cfg.Grafana.Enabled)
+
+	require.NoError(t, os.WriteFile(configPath, []byte("monitoring:\n  history_enabled: false\n"), 0644))
+	cfg, err = LoadConfig(configPath)
+	require.NoError(t, err)
+	assert.False(t, cfg.Monitoring.HistoryEnabled)
+}
+
+func TestLoadConfig_Retention(t *testing.T) {
+	tmpDir := t.TempDir()
+	configPath := filepath.Join(tmpDir, "retention-config.yaml")
+
+	require.NoError(t, os.WriteFile(configPath, []byte("retention:\n  policies:\n    executions: \"48h\"\n"), 0644))
+	cfg, err := LoadConfig(configPath)
+	require.NoError(t, err)
+	assert.False(t, cfg.Retention.Enabled)
+	assert.Equal(t, "1h", cfg.Retention.Interval)
+	assert.Equal(t, "48h", cfg.Retention.Policies["executions"])
+	assert.Equal(t, "720h", cfg.Retention.Policies["alert_events"])
+
+	require.NoError(t, os.WriteFile(configPath, []byte("retention:\n  policies:\n    logs: \"48h\"\n"), 0644))
+	_, err = LoadConfig(configPath)
+	assert.Error(t, err)
+
+	require.NoError(t, os.WriteFile(configPath, []byte("retention:\n  interval: \"0s\"\n"), 0644))
+	_, err = LoadConfig(configPath)
+	assert.Error(t, err)
+}
+
+func TestLoadConfig_StorageBudget(t *testing.T) {
+	tmpDir := t.TempDir()
+	configPath := filepath.Join(tmpDir, "storage-budget-config.yaml")
+
+	require.NoError(t, os.WriteFile(configPath, []byte("storage:\n  budget_bytes: 1048576\n"), 0644))
+	cfg, err := LoadConfig(configPath)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1048576), cfg.Storage.BudgetBytes)
+	assert.Equal(t, 80.0, cfg.Storage.BudgetWarnPercent)
+	assert.Equal(t, "1m", cfg.Storage.BudgetCheckInterval)
+
+	require.NoError(t, os.WriteFile(configPath, []byte("storage:\n  budget_warn_percent: 120\n"), 0644))
+	_, err = LoadConfig(configPath)
+	assert.Error(t, err)
+
+	require.NoError(t, os.WriteFile(configPath, []byte("storage:\n  budget_bytes: -1\n"), 0644))
+	_, err = LoadConfig(configPath)
+	assert.Error(t, err)
+}
+
+func TestLoadConfig_Hosts(t *testing.T) {
+	tmpDir := t.TempDir()
+	configPath := filepath.Join(tmpDir, "hosts-config.yaml")
+
+	require.NoError(t, os.WriteFile(configPath, []byte("hosts:\n  enabled: true\n"), 0644))
+	cfg, err := LoadConfig(configPath)
+	require.NoError(t, err)
+	assert.True(t, cfg.Hosts.Enabled)
+	assert.Equal(t, "2m", cfg.Hosts.StaleAfter)
+
+	require.NoError(t, os.WriteFile(configPath, []byte("hosts:\n  stale_after: \"10ms\"\n"), 0644))
+	_, err = LoadConfig(configPath)
+	assert.Error(t, err)
+
+	require.NoError(t, os.WriteFile(configPath, []byte("hosts:\n  enrollment_token: \"short\"\n"), 0644))
+	_, err = LoadConfig(configPath)
+	assert.Error(t, err)
+
+	require.NoError(t, os.WriteFile(configPath, []byte("hosts:\n  enrollment_token: \"0123456789abcdef\"\n"), 0644))
+	cfg, err = LoadConfig(configPath)
+	require.NoError(t, err)
+	assert.Equal(t, "0123456789abcdef", cfg.Hosts.EnrollmentToken)
+}
+
+func TestLoadConfig_LogWatch(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "logwatch-config.yaml")
+
+	require.NoError(t, os.WriteFile(configPath, []byte(`log_watch:
+  enabled: true
+  watches:
+    - name: oom
+      source: journald
+      units: [app.service]
+      priority: err
+      pattern: "Out of memory"
+    - name: nginx-errors
+      source: file
+      path: /var/log/nginx/error.log
+      pattern: "\\[crit\\]"
+      window: 10m
+`), 0644))
+	cfg, err := LoadConfig(configPath)
+	require.NoError(t, err)
+	assert.True(t, cfg.LogWatch.Enabled)
+	assert.Equal(t, 20, cfg.LogWatch.RecentMatches)
+	require.Len(t, cfg.LogWatch.Watches, 2)
+	assert.Equal(t, []string{"app.service"}, cfg.LogWatch.Watches[0].Units)
+	assert.Equal(t, `\[crit\]`, cfg.LogWatch.Watches[1].Pattern)
+
+	require.NoError(t, os.WriteFile(configPath, []byte("log_watch:\n  watches:\n    - {name: a, source: journald, pattern: \"(\"}\n"), 0644))
+	_, err = LoadConfig(configPath)
+	assert.ErrorContains(t, err, "invalid log_watch watches entry \"a\"")
+
+	require.NoError(t, os.WriteFile(configPath, []byte("log_watch:\n  watches:\n    - {name: a, source: journald, pattern: x}\n    - {name: a, source: journald, pattern: y}\n"), 0644))
+	_, err = LoadConfig(configPath)
+	assert.ErrorContains(t, err, "duplicate name")
+}
+
+func TestLoadConfig_Syslog(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "syslog-config.yaml")
+
+	require.NoError(t, os.WriteFile(configPath, []byte("syslog:\n  enabled: true\n  tcp_address: \":6514\"\nlog_watch:\n  watches:\n    - {name: links, source: syslog, priority: warning, pattern: \"link down\"}\n"), 0644))
+	cfg, err := LoadConfig(configPath)
+	require.NoError(t, err)
+	assert.True(t, cfg.Syslog.Enabled)
+	assert.Equal(t, ":5514", cfg.Syslog.UDPAddress)
+	assert.Equal(t, ":6514", cfg.Syslog.TCPAddress)
+	assert.Equal(t, 1000, cfg.Syslog.BufferSize)
+	assert.Equal(t, 8192, cfg.Syslog.MaxMessageSize)
+
+	require.NoError(t, os.WriteFile(configPath, []byte("log_watch:\n  watches:\n    - {name: links, source: syslog, pattern: x}\n"), 0644))
+	_, err = LoadConfig(configPath)
+	assert.ErrorContains(t, err, "requires syslog.enabled")
+
+	require.NoError(t, os.WriteFile(configPath, []byte("syslog:\n  enabled: true\n  udp_address: \"\"\n"), 0644))
+	_, err = LoadConfig(configPath)
+	assert.ErrorContains(t, err, "invalid syslog")
+
+	require.NoError(t, os.WriteFile(configPath, []byte("syslog:\n  max_message_size: 100\n"), 0644))
+	_, err = LoadConfig(configPath)
+	assert.ErrorContains(t, err, "invalid syslog max_message_size")
+}
+
+func TestLoadConfig_Services(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "services-config.yaml")
+
+	require.NoError(t, os.WriteFile(configPath, []byte("monitoring:\n  services:\n    - {name: web, units: [nginx.service, apache2.service]}\n"), 0644))
+	cfg, err := LoadConfig(configPath)
+	require.NoError(t, err)
+	require.Len(t, cfg.Monitoring.Services, 1)
+	assert.Equal(t, []string{"nginx.service", "apache2.service"}, cfg.Monitoring.Services[0].Units)
+
+	require.NoError(t, os.WriteFile(configPath, []byte("monitoring:\n  services:\n    - {name: web}\n"), 0644))
+	_, err = LoadConfig(configPath)
+	assert.ErrorContains(t, err, "invalid monitoring services")
+
+	require.NoError(t, os.WriteFile(configPath, []byte("monitoring:\n  services:\n    - {name: web, units: [a.service]}\n    - {name: web, units: [b.service]}\n"), 0644))
+	_, err = LoadConfig(configPath)
+	assert.ErrorContains(t, err, "duplicate service")
+}
+
+func TestLoadConfig_StatusPage(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "status-config.yaml")
+
+	require.NoError(t, os.WriteFile(configPath, []byte("status_page:\n  enabled: true\n  components:\n    - {group: web, name: Website}\n    - {group: db}\n"), 0644))
+	cfg, err := LoadConfig(configPath)
+	require.NoError(t, err)
+	assert.Equal(t, "System Status", cfg.StatusPage.Title)
+	assert.Equal(t, 90, cfg.StatusPage.HistoryDays)
+	require.Len(t, cfg.StatusPage.Components, 2)
+	assert.Equal(t, "Website", cfg.StatusPage.Components[0].Name)
+
+	require.NoError(t, os.WriteFile(configPath, []byte("status_page:\n  enabled: true\n"), 0644))
+	_, err = LoadConfig(configPath)
+	assert.ErrorContains(t, err, "at least one component")
+
+	require.NoError(t, os.WriteFile(configPath, []byte("status_page:\n  components:\n    - {group: web}\n    - {group: web}\n"), 0644))
+	_, err = LoadConfig(configPath)
+	assert.ErrorContains(t, err, "duplicate group")
+
+	require.NoError(t, os.WriteFile(configPath, []byte("status_page:\n  history_days: 400\n"), 0644))
+	_, err = LoadConfig(configPath)
+	assert.ErrorContains(t, err, "invalid status_page history_days")
+}
+
+func TestLoadConfig_Incidents(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "incidents-config.yaml")
+
+	cfg, err := LoadConfig("")
+	require.NoError(t, err)
+	assert.Equal(t, "5m", cfg.Incidents.Window)
+	assert.Equal(t, "168h", cfg.Incidents.Lookback)
+
+	require.NoError(t, os.WriteFile(configPath, []byte("incidents:\n  window: \"-1m\"\n"), 0644))
+	_, err = LoadConfig(configPath)
+	assert.ErrorContains(t, err, "invalid incidents window")
+
+	require.NoError(t, os.WriteFile(configPath, []byte("incidents:\n  lookback: week\n"), 0644))
+	_, err = LoadConfig(configPath)
+	assert.ErrorContains(t, err, "invalid incidents lookback")
+}
+
+func TestLoadConfig_LogRepeatInterval(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "logging-config.yaml")
+
+	cfg, err := LoadConfig("")
+	require.NoError(t, err)
+	assert.Equal(t, "5m", cfg.Logging.RepeatInterval)
+
+	require.NoError(t, os.WriteFile(configPath, []byte("logging:\n  repeat_interval: \"1h\"\n"), 0644))
+	cfg, err = LoadConfig(configPath)
+	require.NoError(t, err)
+	assert.Equal(t, "1h", cfg.Logging.RepeatInterval)
+
+	require.NoError(t, os.WriteFile(configPath, []byte("logging:\n  repeat_interval: \"0s\"\n"), 0644))
+	_, err = LoadConfig(configPath)
+	assert.ErrorContains(t, err, "invalid logging repeat_interval")
+}
+
+func TestLoadConfig_Webhook(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "webhook-config.yaml")
+
+	cfg, err := LoadConfig("")
+	require.NoError(t, err)
+	assert.Equal(t, WebhookConfig{QueueSize: 1000, Retention: "24h", RetryInterval: "30s", Timeout: "10s"}, cfg.Alerts.Webhook)
+	assert.Equal(t, filepath.Join(cfg.Alerts.StoragePath, "webhook-queue"), cfg.WebhookQueuePath())
+
+	require.NoError(t, os.WriteFile(configPath, []byte("alerts:\n  webhook:\n    queue_path: /var/spool/argus\n    retention: \"2h\"\n"), 0644))
+	cfg, err = LoadConfig(configPath)
+	require.NoError(t, err)
+	assert.Equal(t, "/var/spool/argus", cfg.WebhookQueuePath())
+	assert.Equal(t, "2h", cfg.Alerts.Webhook.Retention)
+	assert.Equal(t, "30s", cfg.Alerts.Webhook.RetryInterval, "unset fields keep their defaults")
+
+	for _, bad := range []string{"queue_size: -1", "retention: \"0s\"", "retry_interval: soon", "timeout: \"-1s\""} {
+		require.NoError(t, os.WriteFile(configPath, []byte("alerts:\n  webhook:\n    "+bad+"\n"), 0644))
+		_, err = LoadConfig(configPath)
+		assert.ErrorContains(t, err, "invalid alerts webhook", bad)
+	}
+}
+
+func TestLoadConfig_Jira(t *testing.T) {
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "jira-config.yaml")
+	tokenPath := filepath.Join(dir, "jira-token")
+	require.NoError(t, os.WriteFile(tokenPath, []byte("file-token\n"), 0600))
+
+	cfg, err := LoadConfig("")
+	require.NoError(t, err)
+	assert.Equal(t, JiraConfig{IssueType: "Task", Timeout: "10s"}, cfg.Alerts.Jira, "disabled without a url")
+
+	write := func(jira string) (*Config, error) {
+		require.NoError(t, os.WriteFile(configPath, []byte("alerts:\n  jira:\n    url: https://example.atlassian.net\n"+jira), 0644))
+		return LoadConfig(configPath)
+	}
+	cfg, err = write("    api_token_file: " + tokenPath + "\n    project: OPS\n")
+	require.NoError(t, err)
+	token, err := cfg.Alerts.Jira.Token()
+	require.NoError(t, err)
+	assert.Equal(t, "file-token", token, "read from the file without the trailing newline")
+	assert.Equal(t, "OPS", cfg.Alerts.Jira.Project)
+	assert.Equal(t, "Task", cfg.Alerts.Jira.IssueType, "unset fields keep their defaults")
+
+	t.Setenv("ARGUS_ALERTS_JIRA_API_TOKEN", "env-token")
+	cfg, err = write("    api_token_file: " + tokenPath + "\n")
+	require.NoError(t, err)
+	token, err = cfg.Alerts.Jira.Token()
+	require.NoError(t, err)
+	assert.Equal(t, "env-token", token, "the environment overrides the file")
+	t.Setenv("ARGUS_ALERTS_JIRA_API_TOKEN", "")
+
+	for bad, want := range map[string]string{
+		"":                                   "an api_token, api_token_file or ARGUS_ALERTS_JIRA_API_TOKEN is required",
+		"    api_token_file: /nonexistent\n": "invalid alerts jira api_token_file",
+		"    api_token: t\n    timeout: \"0s\"\n": "invalid alerts jira timeout",
+	} {
+		_, err = write(bad)
+		assert.ErrorContains(t, err, want, bad)
+	}
+	require.NoError(t, os.WriteFile(configPath, []byte("alerts:\n  jira:\n    url: example.atlassian.net\n    api_token: t\n"), 0644))
+	_, err = LoadConfig(configPath)
+	assert.ErrorContains(t, err, "invalid alerts jira url")
+}
+
+func TestLoadConfig_Auth(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "auth-config.yaml")
+	write := func(yaml string) (*Config, error) {
+		require.NoError(t, os.WriteFile(configPath, []byte("auth:\n"+yaml), 0644))
+		return LoadConfig(configPath)
+	}
+
+	cfg, err := LoadConfig("")
+	require.NoError(t, err)
+	assert.False(t, cfg.Auth.OIDC.Enabled)
+	assert.False(t, cfg.Auth.LDAP.Enabled)
+
+	t.Setenv("ARGUS_AUTH_OIDC_CLIENT_SECRET", "env-secret")
+	t.Setenv("ARGUS_AUTH_LDAP_BIND_PASSWORD", "env-password")
+	cfg, err = write(`  oidc:
+    enabled: true
+    issuer: https://idp.example.com/realms/ops
+    client_id: argus
+    redirect_url: https://argus.example.com/api/auth/oidc/callback
+  ldap:
+    enabled: true
+    url: ldaps://ldap.example.com
+    base_dn: dc=example,dc=com
+  roles:
+    default: none
+    groups:
+      ops: operator
+      cn=admins,ou=groups,dc=example,dc=com: Admin
+`)
+	require.NoError(t, err)
+	assert.Equal(t, "env-secret", cfg.Auth.OIDC.ClientSecret)
+	assert.Equal(t, "env-password", cfg.Auth.LDAP.BindPassword)
+	mapping, err := cfg.RoleMapping()
+	require.NoError(t, err)
+	assert.Equal(t, auth.RoleNone, mapping.Default)
+	assert.Equal(t, map[string]auth.Role{"ops": auth.RoleOperator, "cn=admins,ou=groups,dc=example,dc=com": auth.RoleAdmin}, mapping.Groups)
+
+	for bad, want := range map[string]string{
+		"  oidc:\n    enabled: true\n    issuer: idp.example.com\n":                                              "invalid auth oidc issuer",
+		"  oidc:\n    enabled: true\n    issuer: https://idp\n    redirect_url: https://argus/cb\n":              "a client_id is required",
+		"  oidc:\n    enabled: true\n    issuer: https://idp\n    client_id: argus\n":                            "invalid auth oidc redirect_url",
+		"  ldap:\n    enabled: true\n    url: https://ldap\n":                                                    "invalid auth ldap url",
+		"  ldap:\n    enabled: true\n    url: ldap://ldap\n":                                                     "a base_dn is required",
+		"  ldap:\n    enabled: true\n    url: ldap://ldap\n    base_dn: dc=example\n    timeout: \"0s\"\n":       "invalid auth ldap timeout",
+		"  ldap:\n    enabled: true\n    url: ldap://ldap\n    base_dn: dc=example\n    ca_file: /nonexistent\n": "invalid auth ldap ca_file",
+		"  roles:\n    default: root\n":                                                                          "invalid auth roles default",
+		"  roles:\n    groups:\n      ops: superuser\n":                                                          "invalid auth roles group",
+		"  sessions:\n    ttl: 30s\n":                                                                            "invalid auth sessions ttl",
+		"  sessions:\n    idle_timeout: -1h\n":                                                                   "invalid auth sessions idle_timeout",
+		"  sessions:\n    cookie_name: \"argus session\"\n":                                                      "invalid auth sessions cookie_name",
+		"  sessions:\n    cookie_path: argus\n":                                                                  "invalid auth sessions cookie_path",
+		"  sessions:\n    secure: sometimes\n":                                                                   "invalid auth sessions secure",
+		"  sessions:\n    same_site: none\n":                                                                     "invalid auth sessions same_site",
+		"  api_key_header: \"X API Key\"\n":                                                                      "invalid auth api_key_header",
+		"  api_keys:\n    - key: 0123456789abcdef\n":                                                             "a name is required",
+		"  api_keys:\n    - name: ci\n":                                                                          "set one of key, key_file or sha256",
+		"  api_keys:\n    - name: ci\n      key: short\n":                                                        "at least 16 characters",
+		"  api_keys:\n    - name: ci\n      sha256: abc\n":                                                       "invalid auth api_keys \"ci\" sha256",
+		"  api_keys:\n    - name: ci\n      key_file: /nonexistent\n":                                            "invalid auth api_keys \"ci\" key_file",
+		"  api_keys:\n    - name: ci\n      key: 0123456789abcdef\n      role: none\n":                           "invalid auth api_keys \"ci\" role",
+	} {
+		_, err = write(bad)
+		assert.ErrorContains(t, err, want, bad)
+	}
+}
+
+func TestLoadConfig_AuthAPIKeys(t *testing.T) {
+	dir := t.TempDir()
+	keyFile := filepath.Join(dir, "ci.key")
+	require.NoError(t, os.WriteFile(keyFile, []byte("ci-key-0123456789\n"), 0600))
+	configPath := filepath.Join(dir, "api-keys-config.yaml")
+	require.NoError(t, os.WriteFile(configPath, []byte(`auth:
+  api_key_header: X-Argus-Key
+  api_keys:
+    - name: backup-script
+      key: backup-key-0123456789
+      role: operator
+    - name: ci
+      key_file: `+keyFile+`
+      role: Admin
+    - name: grafana
+      sha256: 9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08
+`), 0644))
+	cfg, err := LoadConfig(configPath)
+	require.NoError(t, err)
+	keys, err := cfg.APIKeys()
+	require.NoError(t, err)
+	assert.Equal(t, []auth.APIKey{
+		{Name: "backup-script", Hash: auth.HashAPIKey("backup-key-0123456789"), Role: auth.RoleOperator},
+		{Name: "ci", Hash: auth.HashAPIKey("ci-key-0123456789"), Role: auth.RoleAdmin},
+		{Name: "grafana", Hash: auth.HashAPIKey("test"), Role: auth.RoleViewer},
+	}, keys)
+	assert.Equal(t, "X-Argus-Key", cfg.Auth.APIKeyHeader)
+}
+
+func TestLoadConfig_AuthSessions(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "sessions-config.yaml")
+
+	cfg, err := LoadConfig("")
+	require.NoError(t, err)
+	sessions, err := cfg.SessionConfig()
+	require.NoError(t, err)
+	assert.Equal(t, auth.SessionConfig{SameSite: http.SameSiteLaxMode}, sessions, "defaults of the auth package")
+
+	require.NoError(t, os.WriteFile(configPath, []byte(`auth:
+  sessions:
+    ttl: 8h
+    idle_timeout: 0s
+    cookie_name: argus_sid
+    cookie_path: /argus/
+    cookie_domain: example.com
+    secure: Always
+    same_site: strict
+`), 0644))
+	cfg, err = LoadConfig(configPath)
+	require.NoError(t, err)
+	sessions, err = cfg.SessionConfig()
+	require.NoError(t, err)
+	assert.Equal(t, auth.SessionConfig{
+		TTL:          8 * time.Hour,
+		IdleTimeout:  -1,
+		CookieName:   "argus_sid",
+		CookiePath:   "/argus/",
+		CookieDomain: "example.com",
+		Secure:       auth.SecureAlways,
+		SameSite:     http.SameSiteStrictMode,
+	}, sessions)
+}
+
+func TestLoadConfig_Update(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "update-config.yaml")
+
+	cfg, err := LoadConfig("")
+	require.NoError(t, err)
+	assert.False(t, cfg.Update.Enabled)
+	assert.Equal(t, DefaultUpdateURL, cfg.Update.URL)
+	assert.Equal(t, "24h", cfg.Update.Interval)
+	assert.True(t, cfg.Update.Notify)
+
+	require.NoError(t, os.WriteFile(configPath, []byte("update:\n  enabled: true\n  url: https://releases.example.com/latest\n  interval: 6h\n"), 0644))
+	cfg, err = LoadConfig(configPath)
+	require.NoError(t, err)
+	assert.Equal(t, "https://releases.example.com/latest", cfg.Update.URL)
+	assert.Equal(t, "6h", cfg.Update.Interval)
+
+	for bad, want := range map[string]string{
+		"  url: releases.example.com\n": "invalid update url",
+		"  interval: 30s\n":             "invalid update interval",
+	} {
+		require.NoError(t, os.WriteFile(configPath, []byte("update:\n  enabled: true\n"+bad), 0644))
+		_, err = LoadConfig(configPath)
+		assert.ErrorContains(t, err, want, bad)
+	}
+	require.NoError(t, os.WriteFile(configPath, []byte("update:\n  url: releases.example.com\n"), 0644))
+	_, err = LoadConfig(configPath)
+	assert.NoError(t, err, "settings of a disabled check are not validated")
+}
+
+func TestLoadConfig_SecurityHeaders(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "security-headers-config.yaml")
+
+	cfg, err := LoadConfig("")
+	require.NoError(t, err)
+	assert.Equal(t, SecurityHeadersConfig{Enabled: true}, cfg.SecurityHeaders)
+
+	require.NoError(t, os.WriteFile(configPath, []byte("security_headers:\n  hsts_max_age: 0s\n  frame_options: SameOrigin\n  content_security_policy: \"default-src 'self'\"\n  csp_report_only: true\n"), 0644))
+	cfg, err = LoadConfig(configPath)
+	require.NoError(t, err)
+	assert.Equal(t, SecurityHeadersConfig{Enabled: true, HSTSMaxAge: "0s", FrameOptions: "SameOrigin",
+		ContentSecurityPolicy: "default-src 'self'", CSPReportOnly: true}, cfg.SecurityHeaders)
+
+	for bad, want := range map[string]string{
+		"  hsts_max_age: a year\n":                                          "invalid security_headers hsts_max_age",
+		"  frame_options: allow-from https://example.com\n":                 "invalid security_headers frame_options",
+		"  content_security_policy: \"default-src *\\r\\nX-Injected: 1\"\n": "invalid security_headers content_security_policy",
+	} {
+		require.NoError(t, os.WriteFile(configPath, []byte("security_headers:\n"+bad), 0644))
+		_, err = LoadConfig(configPath)
+		assert.ErrorContains(t, err, want, bad)
+	}
+}
+
+func TestLoadConfig_StorageDriver(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "storage-driver-config.yaml")
+
+	cfg, err := LoadConfig("")
+	require.NoError(t, err)
+	assert.Equal(t, "file", cfg.Storage.Driver)
+	assert.Equal(t, filepath.Join(cfg.Storage.BasePath, "argus.db"), cfg.SQLiteDatabasePath())
+
+	require.NoError(t, os.WriteFile(configPath, []byte("storage:\n  driver: sqlite\n  sqlite_path: /var/lib/argus/argus.db\n"), 0644))
+	cfg, err = LoadConfig(configPath)
+	require.NoError(t, err)
+	assert.Equal(t, "sqlite", cfg.Storage.Driver)
+	assert.Equal(t, "/var/lib/argus/argus.db", cfg.SQLiteDatabasePath())
+
+	assert.Equal(t, map[string]string{"path": "/var/lib/argus/argus.db"}, cfg.StorageDriverOptions())
+
+	// Drivers registered by other packages are checked when opened
+	require.NoError(t, os.WriteFile(configPath, []byte("storage:\n  driver: postgres\n  options:\n    dsn: postgres://argus@db/argus\n"), 0644))
+	cfg, err = LoadConfig(configPath)
+	require.NoError(t, err)
+	assert.Equal(t, "postgres", cfg.StorageDriver())
+	assert.Equal(t, map[string]string{"dsn": "postgres://argus@db/argus"}, cfg.StorageDriverOptions())
+
+	require.NoError(t, os.WriteFile(configPath, []byte("storage:\n  driver: Postgres SQL\n"), 0644))
+	_, err = LoadConfig(configPath)
+	assert.ErrorContains(t, err, `invalid storage driver "Postgres SQL"`)
+}
+
+func TestLoadConfig_MetricsExporters(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "exporters-config.yaml")
+
+	cfg, err := LoadConfig("")
+	require.NoError(t, err)
+	assert.Empty(t, cfg.Metrics.Exporters)
+
+	exporters := "metrics:\n  exporters:\n" +
+		"    - format: influx\n      url: http://influxdb:8086/api/v2/write?org=ops&bucket=argus\n      headers:\n        Authorization: Token abc\n      metrics: [\"cpu_*\"]\n" +
+		"    - format: remote_write\n      url: https://vm.example.com/api/v1/write\n      interval: 1m\n      labels:\n        env: prod\n"
+	require.NoError(t, os.WriteFile(configPath, []byte(exporters), 0644))
+	cfg, err = LoadConfig(configPath)
+	require.NoError(t, err)
+	require.Len(t, cfg.Metrics.Exporters, 2)
+	assert.Equal(t, map[string]string{"Authorization": "Token abc"}, cfg.Metrics.Exporters[0].Headers)
+	assert.Equal(t, []string{"cpu_*"}, cfg.Metrics.Exporters[0].Metrics)
+	assert.Equal(t, "1m", cfg.Metrics.Exporters[1].Interval)
+
+	for bad, want := range map[string]string{
+		"    - format: graphite\n      url: http://graphite:2003\n":                                        `invalid metrics exporter 0 format "graphite"`,
+		"    - format: influx\n      url: influxdb:8086\n":                                                 `invalid metrics exporter "influx" url`,
+		"    - format: influx\n      url: http://influxdb:8086\n      timeout: 0s\n":                       `invalid metrics exporter "influx" timeout "0s"`,
+		"    - format: influx\n      url: http://influxdb:8086\n      metrics: [\"[\"]\n":                  `invalid metrics exporter "influx" metrics pattern`,
+		"    - format: influx\n      url: http://a:8086\n    - format: influx\n      url: http://b:8086\n": `invalid metrics exporter "influx": the name is used twice`,
+	} {
+		require.NoError(t, os.WriteFile(configPath, []byte("metrics:\n  exporters:\n"+bad), 0644))
+		_, err = LoadConfig(configPath)
+		assert.ErrorContains(t, err, want, bad)
+	}
+}
+
+func TestLoadConfig_ProvisionedAlerts(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "provisioned-config.yaml")
+
+	provisioned := "provisioned_alerts:\n" +
+		"  - template: cpu\n" +
+		"  - template: disk\n    severity: critical\n    threshold:\n      value: 95\n      labels:\n        mountpoint: /data\n" +
+		"  - id: nginx-down\n    name: nginx is down\n    severity: critical\n    threshold: {metric_type: service, metric_name: down, operator: \">\", value: 0, target: nginx}\n" +
+		"    notifications:\n      - type: webhook\n        enabled: true\n        settings: {url: \"https://hooks.example.com/argus\"}\n"
+	require.NoError(t, os.WriteFile(configPath, []byte(provisioned), 0644))
+	cfg, err := LoadConfig(configPath)
+	require.NoError(t, err)
+	now := time.Date(2026, 10, 14, 0, 0, 0, 0, time.UTC)
+	alerts, err := cfg.ProvisionedAlertConfigs(now)
+	require.NoError(t, err)
+	require.Len(t, alerts, 3)
+
+	assert.Equal(t, "default-cpu", alerts[0].ID)
+	assert.Equal(t, 90.0, alerts[0].Threshold.Value)
+	assert.Equal(t, now, alerts[0].CreatedAt)
+
+	disk := alerts[1]
+	assert.Equal(t, "default-disk", disk.ID)
+	assert.Equal(t, "Low disk space", disk.Name, "fields not given keep the template's")
+	assert.Equal(t, models.SeverityCritical, disk.Severity)
+	assert.Equal(t, "usage_percent", disk.Threshold.MetricName)
+	assert.Equal(t, 95.0, disk.Threshold.Value)
+	assert.Equal(t, map[string]string{"mountpoint": "/data"}, disk.Threshold.Labels)
+
+	custom := alerts[2]
+	assert.True(t, custom.Enabled, "provisioned alerts are enabled by default")
+	assert.Equal(t, "nginx", *custom.Threshold.Target)
+	require.Len(t, custom.Notifications, 1, "the notifications replace the default in-app one")
+	assert.Equal(t, models.NotificationWebhook, custom.Notifications[0].Type)
+
+	for bad, want := range map[string]string{
+		"  - template: swap\n":                                                      `invalid provisioned_alerts[0] template: unknown default alert "swap"`,
+		"  - name: No ID\n    severity: info\n":                                     `invalid provisioned_alerts[0]: an id is required without a template`,
+		"  - template: cpu\n  - template: cpu\n":                                    `invalid provisioned_alerts "default-cpu": duplicate id`,
+		"  - template: cpu\n    severity: urgent\n":                                 `invalid provisioned_alerts "default-cpu": invalid severity: urgent`,
+		"  - template: cpu\n    threshold: {value: x}\n":                            `invalid provisioned_alerts[0]`,
+		"  - template: memory\n    notifications: [{type: email, enabled: true}]\n": `invalid provisioned_alerts "default-memory" notification: email notification requires settings`,
+	} {
+		require.NoError(t, os.WriteFile(configPath, []byte("provisioned_alerts:\n"+bad), 0644))
+		_, err = LoadConfig(configPath)
+		assert.ErrorContains(t, err, want, bad)
+	}
+}
+
+func TestLoadConfig_Features(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "features-config.yaml")
+
+	require.NoError(t, os.WriteFile(configPath, []byte("features:\n  agent_mode: false\n"), 0644))
+	cfg, err := LoadConfig(configPath)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]bool{features.AgentMode: false}, cfg.Features)
+
+	t.Setenv("ARGUS_FEATURE_AGENT_MODE", "true")
+	cfg, err = LoadConfig(configPath)
+	require.NoError(t, err)
+	assert.True(t, cfg.Features[features.AgentMode], "the environment overrides the file")
+
+	require.NoError(t, os.WriteFile(configPath, []byte("features:\n  time_travel: true\n"), 0644))
+	_, err = LoadConfig(configPath)
+	assert.ErrorContains(t, err, `unknown feature flag "time_travel"`)
+}
+
+func TestLoadConfig_Remediation(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "remediation-config.yaml")
+
+	cfg, err := LoadConfig("")
+	require.NoError(t, err)
+	assert.True(t, cfg.Alerts.Remediation.Enabled)
+	assert.Equal(t, filepath.Join(cfg.Alerts.StoragePath, "remediation-audit.jsonl"), cfg.RemediationAuditPath())
+
+	require.NoError(t, os.WriteFile(configPath, []byte("alerts:\n  remediation:\n    enabled: false\n"), 0644))
+	cfg, err = LoadConfig(configPath)
+	require.NoError(t, err)
+	assert.False(t, cfg.Alerts.Remediation.Enabled)
+}
+
+func TestLoadConfig_Dispatch(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "dispatch-config.yaml")
+
+	cfg, err := LoadConfig("")
+	require.NoError(t, err)
+	assert.Equal(t, 2, cfg.Alerts.DispatchWorkers)
+	assert.Equal(t, QueueConfig{Size: 100, Overflow: "drop-newest", BlockTimeout: "1s"}, cfg.Alerts.Queues.Dispatch)
+
+	require.NoError(t, os.WriteFile(configPath, []byte("alerts:\n  dispatch_workers: 0\n  queues:\n    dispatch:\n      size: 10\n      overflow: block\n"), 0644))
+	cfg, err = LoadConfig(configPath)
+	require.NoError(t, err)
+	assert.Equal(t, 0, cfg.Alerts.DispatchWorkers, "zero dispatches synchronously")
+	assert.Equal(t, 10, cfg.Alerts.Queues.Dispatch.Size)
+	assert.Equal(t, "block", cfg.Alerts.Queues.Dispatch.Overflow)
+
+	require.NoError(t, os.WriteFile(configPath, []byte("alerts:\n  dispatch_workers: -1\n"), 0644))
+	_, err = LoadConfig(configPath)
+	assert.ErrorContains(t, err, "invalid alerts dispatch_workers")
+
+	require.NoError(t, os.WriteFile(configPath, []byte("alerts:\n  queues:\n    dispatch:\n      overflow: spill\n"), 0644))
+	_, err = LoadConfig(configPath)
+	assert.ErrorContains(t, err, "alerts queues.dispatch")
+}
+
+func TestLoadConfig_TaskThrottle(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "throttle-config.yaml")
+
+	cfg, err := LoadConfig("")
+	require.NoError(t, err)
+	assert.Equal(t, TaskThrottleConfig{MaxDefer: "1h"}, cfg.Tasks.Throttle, "disabled by default")
+
+	require.NoError(t, os.WriteFile(configPath, []byte("tasks:\n  throttle:\n    cpu_percent: 85\n    iowait_percent: 30\n    max_defer: 0s\n"), 0644))
+	cfg, err = LoadConfig(configPath)
+	require.NoError(t, err)
+	assert.Equal(t, TaskThrottleConfig{CPUPercent: 85, IOWaitPercent: 30, MaxDefer: "0s"}, cfg.Tasks.Throttle)
+
+	for body, want := range map[string]string{
+		"tasks:\n  throttle:\n    cpu_percent: 120\n":   "invalid tasks throttle cpu_percent",
+		"tasks:\n  throttle:\n    iowait_percent: -1\n": "invalid tasks throttle iowait_percent",
+		"tasks:\n  throttle:\n    max_defer: soon\n":    "invalid tasks throttle max_defer",
+	} {
+		require.NoError(t, os.WriteFile(configPath, []byte(body), 0644))
+		_, err = LoadConfig(configPath)
+		assert.ErrorContains(t, err, want, body)
+	}
+}
+
+func TestLoadConfig_Proxy(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "proxy-config.yaml")
+
+	cfg, err := LoadConfig("")
+	require.NoError(t, err)
+	assert.Equal(t, netproxy.Config{}, cfg.ProxyFor("webhook"), "the environment applies by default")
+
+	require.NoError(t, os.WriteFile(configPath, []byte(`proxy:
+  url: "http://proxy.corp:3128"
+  no_proxy: ["internal.corp", "10.0.0.0/8"]
+  channels:
+    health_check:
+      url: "none"
+`), 0644))
+	cfg, err = LoadConfig(configPath)
+	require.NoError(t, err)
+	assert.Equal(t, netproxy.Config{URL: "http://proxy.corp:3128", NoProxy: []string{"internal.corp", "10.0.0.0/8"}}, cfg.ProxyFor("webhook"))
+	assert.Equal(t, netproxy.Config{URL: netproxy.None}, cfg.ProxyFor("health_check"), "a channel entry replaces the global proxy")
+
+	t.Setenv("ARGUS_PROXY_URL", "socks5://proxy.corp:1080")
+	cfg, err = LoadConfig(configPath)
+	require.NoError(t, err)
+	assert.Equal(t, "socks5://proxy.corp:1080", cfg.ProxyFor("webhook").URL)
+
+	t.Setenv("ARGUS_PROXY_URL", "")
+	for bad, message := range map[string]string{
+		"proxy:\n  url: \"ftp://proxy.corp\"\n":                        "invalid proxy",
+		"proxy:\n  channels:\n    slack:\n      url: \"none\"\n":       "invalid proxy channels entry \"slack\"",
+		"proxy:\n  channels:\n    webhook:\n      url: \"proxy:80\"\n": "invalid proxy channels entry \"webhook\"",
+	} {
+		require.NoError(t, os.WriteFile(configPath, []byte(bad), 0644))
+		_, err = LoadConfig(configPath)
+		assert.ErrorContains(t, err, message)
+	}
+}
+
+// writeKeyPair writes a self-signed certificate and its key to dir
+func writeKeyPair(t *testing.T, dir, name string) (certFile, keyFile string) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: name},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(24 * time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+	certFile = filepath.Join(dir, name+".crt")
+	keyFile = filepath.Join(dir, name+".key")
+	require.NoError(t, os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0644))
+	require.NoError(t, os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0600))
+	return certFile, keyFile
+}
+
+func TestLoadConfig_OutboundTLS(t *testing.T) {
+	tmpDir := t.TempDir()
+	configPath := filepath.Join(tmpDir, "tls-config.yaml")
+	caFile, _ := writeKeyPair(t, tmpDir, "ca")
+	certFile, keyFile := writeKeyPair(t, tmpDir, "argus")
+	hookCert, hookKey := writeKeyPair(t, tmpDir, "argus-webhook")
+
+	cfg, err := LoadConfig("")
+	require.NoError(t, err)
+	assert.Equal(t, tlsclient.Config{}, cfg.TLSFor("webhook"))
+	assert.Empty(t, cfg.ClientCertificates())
+	assert.Equal(t, DefaultCertExpiryWarning, cfg.CertExpiryWarning())
+
+	require.NoError(t, os.WriteFile(configPath, []byte(`outbound_tls:
+  ca_file: "`+caFile+`"
+  cert_file: "`+certFile+`"
+  key_file: "`+keyFile+`"
+  expiry_warning: "168h"
+  channels:
+    webhook:
+      ca_file: "`+caFile+`"
+      cert_file: "`+hookCert+`"
+      key_file: "`+hookKey+`"
+`), 0644))
+	cfg, err = LoadConfig(configPath)
+	require.NoError(t, err)
+	assert.Equal(t, tlsclient.Config{CAFile: caFile, CertFile: certFile, KeyFile: keyFile}, cfg.TLSFor("health_check"))
+	assert.Equal(t, tlsclient.Config{CAFile: caFile, CertFile: hookCert, KeyFile: hookKey}, cfg.TLSFor("webhook"), "a channel entry replaces the global settings")
+	assert.Equal(t, map[string]tlsclient.Config{"default": cfg.TLSFor(""), "webhook": cfg.TLSFor("webhook")}, cfg.ClientCertificates())
+	assert.Equal(t, 168*time.Hour, cfg.CertExpiryWarning())
+	transport, err := cfg.OutboundTransport("webhook")
+	require.NoError(t, err)
+	assert.NotNil(t, transport.TLSClientConfig.RootCAs)
+	assert.NotNil(t, transport.TLSClientConfig.GetClientCertificate)
+
+	for bad, message := range map[string]string{
+		"outbound_tls:\n  cert_file: \"" + certFile + "\"\n":                              "invalid outbound_tls: cert_file and key_file must be set together",
+		"outbound_tls:\n  ca_file: \"" + filepath.Join(tmpDir, "missing.pem") + "\"\n":    "invalid outbound_tls: failed to read CA bundle",
+		"outbound_tls:\n  channels:\n    slack:\n      ca_file: \"" + caFile + "\"\n":     "invalid outbound_tls channels entry \"slack\"",
+		"outbound_tls:\n  channels:\n    webhook:\n      key_file: \"" + keyFile + "\"\n": "invalid outbound_tls channels entry \"webhook\"",
+		"outbound_tls:\n  expiry_warning: \"soon\"\n":                                     "invalid outbound_tls expiry_warning",
+	} {
+		require.NoError(t, os.WriteFile(configPath, []byte(bad), 0644))
+		_, err = LoadConfig(configPath)
+		assert.ErrorContains(t, err, message)
+	}
+}
+
+func TestLoadConfig_Rollups(t *testing.T) {
+	tmpDir := t.TempDir()
+	configPath := filepath.Join(tmpDir, "rollups-config.yaml")
+
+	require.NoError(t, os.WriteFile(configPath, []byte("monitoring:\n  rollups:\n    \"1h\": \"0s\"\n    \"5m\": \"off\"\n"), 0644))
+	cfg, err := LoadConfig(configPath)
+	require.NoError(t, err)
+	resolutions, err := cfg.RollupResolutions()
+	require.NoError(t, err)
+	assert.Equal(t, []RollupResolution{
+		{Step: time.Minute, Retention: 168 * time.Hour},
+		{Step: time.Hour, Retention: 0},
+	}, resolutions)
+
+	require.NoError(t, os.WriteFile(configPath, []byte("monitoring:\n  rollups_enabled: false\n"), 0644))
+	cfg, err = LoadConfig(configPath)
+	require.NoError(t, err)
+	resolutions, err = cfg.RollupResolutions()
+	require.NoError(t, err)
+	assert.Empty(t, resolutions)
+
+	require.NoError(t, os.WriteFile(configPath, []byte("monitoring:\n  rollups:\n    \"90s\": \"24h\"\n"), 0644))
+	_, err = LoadConfig(configPath)
+	assert.Error(t, err)
+
+	require.NoError(t, os.WriteFile(configPath, []byte("monitoring:\n  rollups:\n    \"15m\": \"forever\"\n"), 0644))
+	_, err = LoadConfig(configPath)
+	assert.Error(t, err)
+}
+
+func TestUpdateFile(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.yaml")
+	original := `# Argus configuration
+server:
+        port: 8080 # API port
+
+monitoring:
+        update_interval: "5s" # How often metrics are collected
+        process_limit: 500
+        disabled_collectors: []
+`
+	require.NoError(t, os.WriteFile(configPath, []byte(original), 0600))
+
+	require.NoError(t, UpdateFile(configPath, map[string]any{
+		"monitoring.update_interval":     "30s",
+		"monitoring.cache_ttl":           "1m0s",
+		"monitoring.process_limit":       50,
+		"monitoring.disabled_collectors": []string{"raid", "power"},
+	}))
+	data, err := os.ReadFile(configPath)
+	require.NoError(t, err)
+	assert.Equal(t, `# Argus configuration
+server:
+        port: 8080 # API port
+
+monitoring:
+        update_interval: "30s" # How often metrics are collected
+        process_limit: 50
+        disabled_collectors: [raid, power]
+        cache_ttl: 1m0s
+`, string(data), "only the changed lines are rewritten")
+
+	cfg, err := LoadConfig(configPath)
+	require.NoError(t, err)
+	assert.Equal(t, "30s", cfg.Monitoring.UpdateInterval)
+	assert.Equal(t, "1m0s", cfg.Monitoring.CacheTTL)
+	assert.Equal(t, 50, cfg.Monitoring.ProcessLimit)
+	assert.Equal(t, []string{"raid", "power"}, cfg.Monitoring.DisabledCollectors)
+	info, err := os.Stat(configPath)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	require.NoError(t, UpdateFile(configPath, map[string]any{"incidents.window": "10m"}), "missing sections are created")
+	cfg, err = LoadConfig(configPath)
+	require.NoError(t, err)
+	assert.Equal(t, "10m", cfg.Incidents.Window)
+
+	require.NoError(t, os.WriteFile(configPath, []byte("monitoring:\n    disabled_collectors: # Skipped kinds\n        - raid\n    cache_ttl: 1m0s\n"), 0600))
+	require.NoError(t, UpdateFile(configPath, map[string]any{"monitoring.disabled_collectors": []string{}}))
+	data, err = os.ReadFile(configPath)
+	require.NoError(t, err)
+	assert.Equal(t, "monitoring:\n    disabled_collectors: [] # Skipped kinds\n    cache_ttl: 1m0s\n", string(data), "block lists are replaced")
+
+	err = UpdateFile(configPath, map[string]any{"monitoring.cache_ttl": "soon"})
+	assert.ErrorContains(t, err, "invalid monitoring cache_ttl")
+	cfg, err = LoadConfig(configPath)
+	require.NoError(t, err)
+	assert.Equal(t, "1m0s", cfg.Monitoring.CacheTTL, "an invalid update leaves the file as it was")
+
+	assert.Error(t, UpdateFile(configPath, map[string]any{"server.port.number": 1}), "a setting is not a section")
+}
+
+func TestLoadConfig_DisabledCollectors(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "collectors-config.yaml")
+
+	cfg, err := LoadConfig("")
+	require.NoError(t, err)
+	assert.Equal(t, "10s", cfg.Monitoring.CacheTTL)
+	assert.Empty(t, cfg.Monitoring.DisabledCollectors)
+
+	require.NoError(t, os.WriteFile(configPath, []byte("monitoring:\n  disabled_collectors: [\"gpu\"]\n"), 0644))
+	_, err = LoadConfig(configPath)
+	assert.ErrorContains(t, err, "invalid monitoring disabled_collectors entry")
+}
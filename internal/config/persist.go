@@ -0,0 +1,286 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// UpdateFile sets settings, keyed by dotted path such as
+// "monitoring.update_interval", in the YAML config file at path. Only the
+// lines of the changed values are rewritten, so comments, blank lines and
+// formatting are kept; missing keys are added to the end of their section.
+// The result must still be a valid configuration; the file is replaced
+// atomically.
+func UpdateFile(path string, settings map[string]any) error {
+	data, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	keys := make([]string, 0, len(settings))
+	for key := range settings {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		if data, err = setValue(data, strings.Split(key, "."), settings[key]); err != nil {
+			return fmt.Errorf("failed to set %s: %w", key, err)
+		}
+	}
+
+	cfg := defaultConfig()
+	if err := yaml.Unmarshal(data, cfg); err != nil {
+		return fmt.Errorf("failed to decode updated config: %w", err)
+	}
+	if err := validateConfig(cfg); err != nil {
+		return err
+	}
+
+	mode := os.FileMode(0644)
+	if info, err := os.Stat(path); err == nil {
+		mode = info.Mode().Perm()
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*")
+	if err != nil {
+		return fmt.Errorf("failed to create config file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write config file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write config file: %w", err)
+	}
+	if err := os.Chmod(tmp.Name(), mode); err != nil {
+		return fmt.Errorf("failed to write config file: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to replace config file: %w", err)
+	}
+	return nil
+}
+
+// setValue returns data with the value at keys set to value
+func setValue(data []byte, keys []string, value any) ([]byte, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("failed to parse config yaml: %w", err)
+	}
+	lines := strings.Split(string(data), "\n")
+	indent := indentOf(lines)
+
+	var mapping, parentKey *yaml.Node
+	if len(doc.Content) > 0 {
+		mapping = doc.Content[0]
+	}
+	for i, key := range keys {
+		var keyNode, valueNode *yaml.Node
+		if mapping != nil {
+			if mapping.Kind != yaml.MappingNode || mapping.Style == yaml.FlowStyle {
+				return nil, fmt.Errorf("%s is not a section", strings.Join(keys[:i], "."))
+			}
+			for j := 0; j+1 < len(mapping.Content); j += 2 {
+				if mapping.Content[j].Value == key {
+					keyNode, valueNode = mapping.Content[j], mapping.Content[j+1]
+					break
+				}
+			}
+		}
+		if keyNode == nil {
+			return insertKeys(lines, mapping, parentKey, keys[i:], value, indent)
+		}
+		if i < len(keys)-1 {
+			if valueNode.Kind == yaml.ScalarNode && valueNode.Tag == "!!null" {
+				// An empty section
+				return insertKeys(lines, nil, keyNode, keys[i+1:], value, indent)
+			}
+			mapping, parentKey = valueNode, keyNode
+			continue
+		}
+		return replaceValue(lines, keyNode, valueNode, value)
+	}
+	return nil, errors.New("no key given")
+}
+
+// insertKeys adds keys, nested in one another, with value after the last
+// line of mapping, the section of parentKey (nil for the top level)
+func insertKeys(lines []string, mapping, parentKey *yaml.Node, keys []string, value any, indent int) ([]byte, error) {
+	rendered, err := renderValue(value, nil)
+	if err != nil {
+		return nil, err
+	}
+	column := 0
+	after := len(lines)
+	switch {
+	case mapping != nil && len(mapping.Content) > 0 && parentKey != nil:
+		column = mapping.Content[0].Column - 1
+		after = lastLine(mapping)
+	case parentKey != nil:
+		column = parentKey.Column - 1 + indent
+		after = lastLine(parentKey)
+	default:
+		// A new top-level section goes to the end, after a blank line
+		for after > 0 && strings.TrimSpace(lines[after-1]) == "" {
+			after--
+		}
+		lines = lines[:after]
+		if after > 0 {
+			lines = append(lines, "")
+		}
+		lines = append(lines, "")
+		after = len(lines) - 1
+	}
+
+	added := make([]string, len(keys))
+	for i, key := range keys {
+		added[i] = strings.Repeat(" ", column+i*indent) + key + ":"
+	}
+	added[len(added)-1] += " " + rendered
+	result := append(append(append([]string{}, lines[:after]...), added...), lines[after:]...)
+	return []byte(strings.Join(result, "\n")), nil
+}
+
+// replaceValue rewrites the value of keyNode in place
+func replaceValue(lines []string, keyNode, valueNode *yaml.Node, value any) ([]byte, error) {
+	inline := valueNode.Line == keyNode.Line && !(valueNode.Tag == "!!null" && valueNode.Value == "")
+	if inline && valueNode.Style&(yaml.LiteralStyle|yaml.FoldedStyle) == 0 &&
+		(valueNode.Kind == yaml.ScalarNode || valueNode.Style == yaml.FlowStyle) {
+		line := lines[valueNode.Line-1]
+		start := valueNode.Column - 1
+		end, err := tokenEnd(line, start, valueNode)
+		if err != nil {
+			return nil, err
+		}
+		rendered, err := renderValue(value, valueNode)
+		if err != nil {
+			return nil, err
+		}
+		lines[valueNode.Line-1] = line[:start] + rendered + line[end:]
+		return []byte(strings.Join(lines, "\n")), nil
+	}
+
+	// A block value or none: put the new value on the key's line, keeping
+	// its comment, and drop the old value's lines
+	rendered, err := renderValue(value, nil)
+	if err != nil {
+		return nil, err
+	}
+	line := lines[keyNode.Line-1]
+	keyEnd := strings.Index(line[keyNode.Column-1:], ":")
+	if keyEnd < 0 {
+		return nil, fmt.Errorf("unsupported key %s", keyNode.Value)
+	}
+	keyEnd += keyNode.Column
+	newLine := line[:keyEnd] + " " + rendered
+	if rest := strings.TrimSpace(line[keyEnd:]); strings.HasPrefix(rest, "#") {
+		newLine += " " + rest
+	}
+	last := keyNode.Line
+	if valueNode.Line > keyNode.Line {
+		last = lastLine(valueNode)
+	}
+	result := append(append(append([]string{}, lines[:keyNode.Line-1]...), newLine), lines[last:]...)
+	return []byte(strings.Join(result, "\n")), nil
+}
+
+// renderValue formats value as a single line of YAML, quoted like the value
+// it replaces when there is one of the same type
+func renderValue(value any, previous *yaml.Node) (string, error) {
+	var node yaml.Node
+	if err := node.Encode(value); err != nil {
+		return "", err
+	}
+	if node.Kind == yaml.SequenceNode || node.Kind == yaml.MappingNode {
+		node.Style = yaml.FlowStyle
+	} else if previous != nil && previous.Kind == yaml.ScalarNode && previous.Tag == node.Tag {
+		node.Style = previous.Style
+	}
+	out, err := yaml.Marshal(&node)
+	if err != nil {
+		return "", err
+	}
+	rendered := strings.TrimSuffix(string(out), "\n")
+	if strings.Contains(rendered, "\n") {
+		return "", fmt.Errorf("value %v does not fit on one line", value)
+	}
+	return rendered, nil
+}
+
+// tokenEnd returns the offset in line just past the value starting at start
+func tokenEnd(line string, start int, node *yaml.Node) (int, error) {
+	switch {
+	case node.Style&yaml.DoubleQuotedStyle != 0:
+		for i := start + 1; i < len(line); i++ {
+			switch line[i] {
+			case '\\':
+				i++
+			case '"':
+				return i + 1, nil
+			}
+		}
+	case node.Style&yaml.SingleQuotedStyle != 0:
+		for i := start + 1; i < len(line); i++ {
+			if line[i] == '\'' {
+				if i+1 < len(line) && line[i+1] == '\'' {
+					i++
+					continue
+				}
+				return i + 1, nil
+			}
+		}
+	case node.Style&yaml.FlowStyle != 0:
+		depth := 0
+		var quote byte
+		for i := start; i < len(line); i++ {
+			c := line[i]
+			switch {
+			case quote != 0:
+				if c == quote {
+					quote = 0
+				}
+			case c == '"' || c == '\'':
+				quote = c
+			case c == '[' || c == '{':
+				depth++
+			case c == ']' || c == '}':
+				if depth--; depth == 0 {
+					return i + 1, nil
+				}
+			}
+		}
+	default:
+		end := len(line)
+		if i := strings.Index(line[start:], " #"); i >= 0 {
+			end = start + i
+		}
+		return len(strings.TrimRight(line[:end], " \t")), nil
+	}
+	return 0, fmt.Errorf("unsupported multi-line value at line %d", node.Line)
+}
+
+// lastLine returns the last line of node and everything nested in it
+func lastLine(node *yaml.Node) int {
+	last := node.Line
+	for _, child := range node.Content {
+		last = max(last, lastLine(child))
+	}
+	return last
+}
+
+// indentOf returns the indentation the YAML lines use, 4 when they have none
+func indentOf(lines []string) int {
+	for _, line := range lines {
+		trimmed := strings.TrimLeft(line, " ")
+		if n := len(line) - len(trimmed); n > 0 && trimmed != "" && !strings.HasPrefix(trimmed, "#") {
+			return n
+		}
+	}
+	return 4
+}
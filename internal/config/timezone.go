@@ -15,3 +15,12 @@ func LoadLocation(tz string) *time.Location {
 	}
 	return loc
 }
+
+// LoadLocationOrLocal is like LoadLocation but treats an empty timezone as server local time,
+// which is the behaviour for timezone settings that were not configured.
+func LoadLocationOrLocal(tz string) *time.Location {
+	if tz == "" {
+		return time.Local
+	}
+	return LoadLocation(tz)
+}
@@ -0,0 +1,157 @@
+// File: internal/database/agent_store.go
+// Brief: Persistent store for enrolled agents
+// Detailed: Keeps the agents admitted with the enrollment token, with the hash of their credential and whether they were revoked, in memory for authenticating heartbeats and as one JSON file per agent next to the alert configurations.
+// Author: drama.lin@aver.com
+// Date: 2026-10-14
+
+package database
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+
+	"argus/internal/faults"
+	"argus/internal/models"
+)
+
+// AgentsDir is the subdirectory for storing agent enrollments
+const AgentsDir = "agents"
+
+// ErrAgentNotFound is returned when an agent is not enrolled
+var ErrAgentNotFound = errors.New("agent not enrolled")
+
+// AgentStore manages the storage of agent enrollments
+type AgentStore struct {
+	dir       string
+	mu        sync.RWMutex
+	agents    map[string]*models.AgentEnrollment
+	fileLocks *LockMap
+}
+
+// NewAgentStore creates an agent store under configDir and loads the
+// enrollments already stored there
+func NewAgentStore(configDir string) (*AgentStore, error) {
+	if configDir == "" {
+		configDir = DefaultConfigDir
+	}
+	dir := filepath.Join(configDir, AgentsDir)
+	if err := os.MkdirAll(dir, DefaultDirMode); err != nil {
+		return nil, fmt.Errorf("%w: %s: %v", ErrDirectoryCreation, dir, err)
+	}
+
+	s := &AgentStore{
+		dir:       dir,
+		agents:    make(map[string]*models.AgentEnrollment),
+		fileLocks: NewLockMap(),
+	}
+	if err := s.load(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *AgentStore) agentFilePath(id string) string {
+	return filepath.Join(s.dir, id+".json")
+}
+
+// load reads every stored enrollment, skipping files that cannot be parsed
+func (s *AgentStore) load() error {
+	files, err := os.ReadDir(s.dir)
+	if err != nil {
+		return fmt.Errorf("failed to read agents directory: %w", err)
+	}
+	for _, file := range files {
+		if file.IsDir() || filepath.Ext(file.Name()) != ".json" {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(s.dir, file.Name()))
+		if err != nil {
+			return fmt.Errorf("failed to read agent %s: %w", file.Name(), err)
+		}
+		agent := &models.AgentEnrollment{}
+		if err := json.Unmarshal(data, agent); err != nil || agent.ID == "" {
+			slog.Warn("Skipping unreadable agent enrollment", "file", file.Name(), "error", err)
+			continue
+		}
+		s.agents[agent.ID] = agent
+	}
+	return nil
+}
+
+// Save creates or replaces the enrollment of agent.ID
+func (s *AgentStore) Save(agent *models.AgentEnrollment) error {
+	if !validDirName(agent.ID) {
+		return fmt.Errorf("invalid agent ID %q", agent.ID)
+	}
+	data, err := json.MarshalIndent(agent, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal agent: %w", err)
+	}
+	filePath := s.agentFilePath(agent.ID)
+	unlock := s.fileLocks.Lock(filePath)
+	defer unlock()
+	if err := faults.Inject(faults.StoreWrite); err != nil {
+		return fmt.Errorf("failed to write agent: %w", err)
+	}
+	// Only the credential hash is stored, but keep the file private anyway
+	if err := os.WriteFile(filePath, data, 0600); err != nil {
+		return fmt.Errorf("failed to write agent: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	stored := *agent
+	s.agents[agent.ID] = &stored
+	return nil
+}
+
+// Get returns a copy of the enrollment of the agent with the given ID
+func (s *AgentStore) Get(id string) (*models.AgentEnrollment, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	agent, ok := s.agents[id]
+	if !ok {
+		return nil, ErrAgentNotFound
+	}
+	copied := *agent
+	return &copied, nil
+}
+
+// List returns copies of every enrollment ordered by ID
+func (s *AgentStore) List() []*models.AgentEnrollment {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	result := make([]*models.AgentEnrollment, 0, len(s.agents))
+	for _, agent := range s.agents {
+		copied := *agent
+		result = append(result, &copied)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].ID < result[j].ID })
+	return result
+}
+
+// Delete removes the enrollment of the agent with the given ID
+func (s *AgentStore) Delete(id string) error {
+	s.mu.Lock()
+	_, ok := s.agents[id]
+	delete(s.agents, id)
+	s.mu.Unlock()
+	if !ok {
+		return ErrAgentNotFound
+	}
+
+	filePath := s.agentFilePath(id)
+	unlock := s.fileLocks.Lock(filePath)
+	defer unlock()
+	if err := os.Remove(filePath); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("failed to delete agent: %w", err)
+	}
+	return nil
+}
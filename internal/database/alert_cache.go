@@ -0,0 +1,363 @@
+// File: internal/database/alert_cache.go
+// Brief: In-memory cache and write batching for the alert store
+// Detailed: Keeps the encoded alert configurations in memory so evaluator ticks and API reads avoid the disk, tracks hit/miss counts, and persists bulk writes from a background flusher.
+// Author: drama.lin@aver.com
+// Date: 2026-10-14
+
+package database
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/uuid"
+
+	"argus/internal/faults"
+	"argus/internal/models"
+)
+
+// DefaultFlushInterval is how often pending bulk writes are persisted
+const DefaultFlushInterval = 5 * time.Second
+
+// AlertCacheStats reports alert store cache usage for self-metrics
+type AlertCacheStats struct {
+	Hits         uint64     `json:"hits"`
+	Misses       uint64     `json:"misses"`
+	HitRatio     float64    `json:"hit_ratio"`
+	Entries      int        `json:"entries"`
+	PendingWrite int        `json:"pending_writes"`
+	Flushes      uint64     `json:"flushes"`
+	FlushErrors  uint64     `json:"flush_errors"`
+	LastFlush    *time.Time `json:"last_flush,omitempty"`
+}
+
+// alertCache holds the JSON encoding of each alert so every read decodes a
+// private copy and callers can never mutate the cached value. complete is set
+// once the whole alerts directory has been loaded, after which a missing ID
+// is known not to exist.
+type alertCache struct {
+	mu       sync.RWMutex
+	entries  map[string][]byte
+	dirty    map[string]struct{}
+	complete bool
+	version  uint64 // Bumped on every write so stale disk loads are not cached
+
+	hits        atomic.Uint64
+	misses      atomic.Uint64
+	flushes     atomic.Uint64
+	flushErrors atomic.Uint64
+	lastFlush   atomic.Pointer[time.Time]
+}
+
+func newAlertCache() *alertCache {
+	return &alertCache{
+		entries: make(map[string][]byte),
+		dirty:   make(map[string]struct{}),
+	}
+}
+
+// get returns a decoded copy of the cached alert
+func (c *alertCache) get(id string) (*models.AlertConfig, bool, error) {
+	c.mu.RLock()
+	data, ok := c.entries[id]
+	complete := c.complete
+	c.mu.RUnlock()
+
+	if !ok {
+		if complete {
+			// The directory has been loaded, so the alert does not exist
+			c.hits.Add(1)
+			return nil, true, ErrAlertNotFound
+		}
+		c.misses.Add(1)
+		return nil, false, nil
+	}
+
+	c.hits.Add(1)
+	alert := &models.AlertConfig{}
+	if err := json.Unmarshal(data, alert); err != nil {
+		return nil, true, fmt.Errorf("failed to unmarshal cached alert configuration: %w", err)
+	}
+	return alert, true, nil
+}
+
+// list returns decoded copies of every cached alert, ordered by ID like the
+// directory listing, or false if the directory has not been loaded yet
+func (c *alertCache) list() ([]*models.AlertConfig, bool, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if !c.complete {
+		c.misses.Add(1)
+		return nil, false, nil
+	}
+	c.hits.Add(1)
+
+	ids := make([]string, 0, len(c.entries))
+	for id := range c.entries {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	alerts := make([]*models.AlertConfig, 0, len(ids))
+	for _, id := range ids {
+		alert := &models.AlertConfig{}
+		if err := json.Unmarshal(c.entries[id], alert); err != nil {
+			return nil, true, fmt.Errorf("failed to unmarshal cached alert configuration %s: %w", id, err)
+		}
+		alerts = append(alerts, alert)
+	}
+	return alerts, true, nil
+}
+
+// snapshot returns the current version, to be passed to load or fill
+func (c *alertCache) snapshot() uint64 {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.version
+}
+
+// load caches an alert read from disk, unless the store was written since
+// version was taken
+func (c *alertCache) load(id string, data []byte, version uint64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.version != version {
+		return
+	}
+	if _, ok := c.entries[id]; !ok {
+		c.entries[id] = data
+	}
+}
+
+// put stores an alert's encoding; dirty marks it as not yet on disk
+func (c *alertCache) put(id string, data []byte, dirty bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.version++
+	c.entries[id] = data
+	if dirty {
+		c.dirty[id] = struct{}{}
+	} else {
+		delete(c.dirty, id)
+	}
+}
+
+// remove drops an alert from the cache and any pending write for it
+func (c *alertCache) remove(id string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.version++
+	delete(c.entries, id)
+	delete(c.dirty, id)
+}
+
+// fill replaces the cache with a full directory load taken at version.
+// Pending writes are kept because they are newer than what is on disk.
+func (c *alertCache) fill(entries map[string][]byte, version uint64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.version != version {
+		return
+	}
+	for id := range c.dirty {
+		if data, ok := c.entries[id]; ok {
+			entries[id] = data
+		}
+	}
+	c.entries = entries
+	c.complete = true
+}
+
+// pending returns the encoding of id if it has not been written to disk yet
+func (c *alertCache) pending(id string) ([]byte, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, ok := c.dirty[id]; !ok {
+		return nil, false
+	}
+	delete(c.dirty, id)
+	return c.entries[id], true
+}
+
+// takeDirty returns the pending writes and clears the dirty set
+func (c *alertCache) takeDirty() map[string][]byte {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	pending := make(map[string][]byte, len(c.dirty))
+	for id := range c.dirty {
+		pending[id] = c.entries[id]
+	}
+	c.dirty = make(map[string]struct{})
+	return pending
+}
+
+// current reports whether data is still the cached encoding of id
+func (c *alertCache) current(id string, data []byte) bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	cached, ok := c.entries[id]
+	return ok && bytes.Equal(cached, data)
+}
+
+// markDirty re-queues an entry whose flush failed, unless it was deleted meanwhile
+func (c *alertCache) markDirty(id string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, ok := c.entries[id]; ok {
+		c.dirty[id] = struct{}{}
+	}
+}
+
+func (c *alertCache) stats() AlertCacheStats {
+	c.mu.RLock()
+	entries, pending := len(c.entries), len(c.dirty)
+	c.mu.RUnlock()
+
+	st := AlertCacheStats{
+		Hits:         c.hits.Load(),
+		Misses:       c.misses.Load(),
+		Entries:      entries,
+		PendingWrite: pending,
+		Flushes:      c.flushes.Load(),
+		FlushErrors:  c.flushErrors.Load(),
+		LastFlush:    c.lastFlush.Load(),
+	}
+	if total := st.Hits + st.Misses; total > 0 {
+		st.HitRatio = float64(st.Hits) / float64(total)
+	}
+	return st
+}
+
+// CacheStats returns the alert cache hit/miss and flush counters
+func (s *cachedAlertStore) CacheStats() AlertCacheStats {
+	return s.cache.stats()
+}
+
+// SaveAlerts creates or replaces several alert configurations at once. The
+// cache is updated immediately, so reads see the new values, while they are
+// stored by the background flusher (or an explicit Flush). All alerts are
+// validated before any of them is stored.
+func (s *cachedAlertStore) SaveAlerts(alerts []*models.AlertConfig) error {
+	now := time.Now()
+	encoded := make(map[string][]byte, len(alerts))
+	for i, alert := range alerts {
+		if alert == nil {
+			return fmt.Errorf("alert %d: %w", i, ErrInvalidAlertID)
+		}
+		if alert.ID == "" {
+			alert.ID = uuid.New().String()
+		}
+		if alert.CreatedAt.IsZero() {
+			alert.CreatedAt = now
+		}
+		alert.UpdatedAt = now
+
+		if err := alert.Validate(); err != nil {
+			return fmt.Errorf("invalid alert configuration %s: %w", alert.ID, err)
+		}
+		data, err := json.MarshalIndent(alert, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal alert configuration %s: %w", alert.ID, err)
+		}
+		encoded[alert.ID] = data
+	}
+
+	for id, data := range encoded {
+		s.cache.put(id, data, true)
+	}
+	return nil
+}
+
+// Flush stores all pending bulk writes. Entries that fail are kept
+// pending and retried on the next flush.
+func (s *cachedAlertStore) Flush() error {
+	pending := s.cache.takeDirty()
+	if len(pending) == 0 {
+		return nil
+	}
+
+	var firstErr error
+	for id, data := range pending {
+		if err := s.writePendingAlert(id, data); err != nil {
+			s.cache.markDirty(id)
+			if firstErr == nil {
+				firstErr = err
+			}
+		}
+	}
+
+	s.cache.flushes.Add(1)
+	now := time.Now().UTC()
+	s.cache.lastFlush.Store(&now)
+	if firstErr != nil {
+		s.cache.flushErrors.Add(1)
+		return firstErr
+	}
+	return nil
+}
+
+// StartFlusher persists pending bulk writes every interval until ctx is
+// cancelled, flushing once more before it returns
+func (s *cachedAlertStore) StartFlusher(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		interval = DefaultFlushInterval
+	}
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				if err := s.Flush(); err != nil {
+					slog.Error("Failed to flush alert store on shutdown", "error", err)
+				}
+				return
+			case <-ticker.C:
+				if err := s.Flush(); err != nil {
+					slog.Error("Failed to flush alert store", "error", err)
+				}
+			}
+		}
+	}()
+}
+
+// flushAlert writes a pending bulk write for id so single-alert operations see it on disk
+func (s *cachedAlertStore) flushAlert(id string) error {
+	data, ok := s.cache.pending(id)
+	if !ok {
+		return nil
+	}
+	if err := s.writePendingAlert(id, data); err != nil {
+		s.cache.markDirty(id)
+		return err
+	}
+	return nil
+}
+
+// writePendingAlert stores an encoded alert under its lock. It is skipped
+// when a single-alert write or delete replaced the entry after it was queued,
+// as that operation has already stored it.
+func (s *cachedAlertStore) writePendingAlert(id string, data []byte) error {
+	unlock := s.locks.Lock(id)
+	defer unlock()
+
+	if !s.cache.current(id, data) {
+		return nil
+	}
+
+	if err := faults.Inject(faults.StoreWrite); err != nil {
+		return fmt.Errorf("failed to write alert configuration %s: %w", id, err)
+	}
+	if err := s.backend.Write(id, data, false); err != nil {
+		return fmt.Errorf("alert %s: %w", id, err)
+	}
+	return nil
+}
@@ -0,0 +1,435 @@
+// File: internal/database/alert_files.go
+// Brief: JSON file backend of the alert store
+// Detailed: Stores each alert configuration as a JSON file under the alerts directory, the copy replaced or deleted by every update and delete in the backups directory, and the state change history of each alert as a JSON Lines file whose entries may each be compressed. This is the alert storage of storage.driver file.
+// Author: drama.lin@aver.com
+// Date: 2026-10-14
+
+package database
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"argus/internal/compress"
+	"argus/internal/faults"
+	"argus/internal/models"
+	"argus/internal/retention"
+)
+
+// fileAlerts stores alerts in the JSON files of a configuration directory
+type fileAlerts struct {
+	alertsDir  string
+	backupDir  string
+	historyDir string
+	mu         sync.RWMutex
+	fileLocks  *LockMap
+
+	historyCompression compress.Algorithm
+}
+
+func newFileAlerts(configDir string) (*fileAlerts, error) {
+	alertsDir := filepath.Join(configDir, AlertsDir)
+	backupDir := filepath.Join(configDir, BackupDir)
+
+	// Create directories if they don't exist
+	if err := os.MkdirAll(alertsDir, DefaultDirMode); err != nil {
+		return nil, fmt.Errorf("%w: %s: %v", ErrDirectoryCreation, alertsDir, err)
+	}
+
+	if err := os.MkdirAll(backupDir, DefaultDirMode); err != nil {
+		return nil, fmt.Errorf("%w: %s: %v", ErrDirectoryCreation, backupDir, err)
+	}
+
+	return &fileAlerts{
+		alertsDir:  alertsDir,
+		backupDir:  backupDir,
+		historyDir: filepath.Join(configDir, HistoryDir),
+		fileLocks:  NewLockMap(),
+	}, nil
+}
+
+// alertFilePath returns the file path for the given alert ID
+func (f *fileAlerts) alertFilePath(id string) string {
+	return filepath.Join(f.alertsDir, fmt.Sprintf("%s.json", id))
+}
+
+// backupFilePath returns the backup file path for the given alert ID
+func (f *fileAlerts) backupFilePath(id string) string {
+	timestamp := time.Now().Format(backupTimestampFormat)
+	return filepath.Join(f.backupDir, fmt.Sprintf("%s-%s.json", id, timestamp))
+}
+
+func (f *fileAlerts) Exists(id string) (bool, error) {
+	if _, err := os.Stat(f.alertFilePath(id)); err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return false, nil
+		}
+		return false, fmt.Errorf("error checking file: %w", err)
+	}
+	return true, nil
+}
+
+func (f *fileAlerts) Read(id string) ([]byte, error) {
+	filePath := f.alertFilePath(id)
+
+	// Check if file exists
+	if _, err := os.Stat(filePath); err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, ErrAlertNotFound
+		}
+		return nil, fmt.Errorf("error checking file: %w", err)
+	}
+
+	// Get file lock for reading
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read alert configuration: %w", err)
+	}
+	return data, nil
+}
+
+func (f *fileAlerts) ReadAll() (map[string][]byte, error) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	// Read all JSON files in the alerts directory
+	files, err := os.ReadDir(f.alertsDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read alerts directory: %w", err)
+	}
+
+	loaded := make(map[string][]byte)
+	for _, file := range files {
+		if file.IsDir() || filepath.Ext(file.Name()) != ".json" {
+			continue
+		}
+
+		data, err := os.ReadFile(filepath.Join(f.alertsDir, file.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read alert configuration %s: %w", file.Name(), err)
+		}
+
+		var alert struct {
+			ID string `json:"id"`
+		}
+		if err := json.Unmarshal(data, &alert); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal alert configuration %s: %w", file.Name(), err)
+		}
+		loaded[alert.ID] = data
+	}
+	return loaded, nil
+}
+
+func (f *fileAlerts) Write(id string, data []byte, backup bool) error {
+	filePath := f.alertFilePath(id)
+	unlock := f.fileLocks.Lock(filePath)
+	defer unlock()
+
+	// Create a backup of the existing file
+	if backup {
+		if err := f.backupAlert(id); err != nil {
+			return fmt.Errorf("failed to create backup: %w", err)
+		}
+	}
+	if err := os.WriteFile(filePath, data, DefaultFileMode); err != nil {
+		return fmt.Errorf("failed to write alert configuration: %w", err)
+	}
+	return nil
+}
+
+func (f *fileAlerts) Remove(id string) error {
+	filePath := f.alertFilePath(id)
+	unlock := f.fileLocks.Lock(filePath)
+	defer unlock()
+
+	// Check if file exists
+	if _, err := os.Stat(filePath); err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return ErrAlertNotFound
+		}
+		return fmt.Errorf("error checking file: %w", err)
+	}
+
+	// Create a backup of the file before deletion
+	if err := f.backupAlert(id); err != nil {
+		return fmt.Errorf("failed to create backup: %w", err)
+	}
+
+	if err := os.Remove(filePath); err != nil {
+		return fmt.Errorf("failed to delete alert configuration: %w", err)
+	}
+	return nil
+}
+
+// backupAlert creates a backup of an alert configuration. The caller holds
+// the alert file's lock.
+func (f *fileAlerts) backupAlert(id string) error {
+	// Read the source file
+	data, err := os.ReadFile(f.alertFilePath(id))
+	if err != nil {
+		return fmt.Errorf("failed to read alert configuration for backup: %w", err)
+	}
+
+	// Write the backup file
+	if err := os.WriteFile(f.backupFilePath(id), data, DefaultFileMode); err != nil {
+		return fmt.Errorf("failed to write backup file: %w", err)
+	}
+
+	return nil
+}
+
+func (f *fileAlerts) Backup(id, timestamp string) ([]byte, error) {
+	backupPath := filepath.Join(f.backupDir, fmt.Sprintf("%s-%s.json", id, timestamp))
+
+	// Check if backup file exists
+	if _, err := os.Stat(backupPath); err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, fmt.Errorf("backup file not found: %s", backupPath)
+		}
+		return nil, fmt.Errorf("error checking backup file: %w", err)
+	}
+
+	data, err := os.ReadFile(backupPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read backup file: %w", err)
+	}
+	return data, nil
+}
+
+func (f *fileAlerts) Backups(id string) ([]string, error) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	var backups []string
+	prefix := fmt.Sprintf("%s-", id)
+
+	// Read all files in the backup directory
+	files, err := os.ReadDir(f.backupDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read backups directory: %w", err)
+	}
+
+	for _, file := range files {
+		if file.IsDir() || filepath.Ext(file.Name()) != ".json" {
+			continue
+		}
+
+		if len(file.Name()) > len(prefix) && file.Name()[:len(prefix)] == prefix {
+			// Extract timestamp from filename
+			timestamp := file.Name()[len(prefix) : len(file.Name())-5] // Remove prefix and .json extension
+			backups = append(backups, timestamp)
+		}
+	}
+
+	return backups, nil
+}
+
+func (f *fileAlerts) SetHistoryCompression(alg compress.Algorithm) {
+	f.historyCompression = alg
+}
+
+// historyFilePath returns the history file path for the given alert ID
+func (f *fileAlerts) historyFilePath(id string) string {
+	return filepath.Join(f.historyDir, fmt.Sprintf("%s.jsonl", id))
+}
+
+func (f *fileAlerts) AppendHistory(entry models.AlertHistoryEntry) error {
+	if err := os.MkdirAll(f.historyDir, DefaultDirMode); err != nil {
+		return fmt.Errorf("%w: %s: %v", ErrDirectoryCreation, f.historyDir, err)
+	}
+
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed to marshal alert history entry: %w", err)
+	}
+	data, err = compress.Encode(f.historyCompression, append(data, '\n'))
+	if err != nil {
+		return fmt.Errorf("failed to compress alert history entry: %w", err)
+	}
+
+	filePath := f.historyFilePath(entry.AlertID)
+	unlock := f.fileLocks.Lock(filePath)
+	defer unlock()
+
+	if err := faults.Inject(faults.StoreWrite); err != nil {
+		return fmt.Errorf("failed to write alert history: %w", err)
+	}
+	file, err := os.OpenFile(filePath, os.O_WRONLY|os.O_CREATE|os.O_APPEND, DefaultFileMode)
+	if err != nil {
+		return fmt.Errorf("failed to open alert history: %w", err)
+	}
+	defer file.Close()
+
+	if _, err := file.Write(data); err != nil {
+		return fmt.Errorf("failed to write alert history: %w", err)
+	}
+	return nil
+}
+
+func (f *fileAlerts) History(id string, limit int) ([]models.AlertHistoryEntry, error) {
+	filePath := f.historyFilePath(id)
+	unlock := f.fileLocks.Lock(filePath)
+	entries, _, err := readHistoryFile(filePath)
+	unlock()
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	// Newest first
+	for i, j := 0, len(entries)-1; i < j; i, j = i+1, j-1 {
+		entries[i], entries[j] = entries[j], entries[i]
+	}
+	if limit > 0 && len(entries) > limit {
+		entries = entries[:limit]
+	}
+	return entries, nil
+}
+
+// readHistoryFile returns the entries of a history file, oldest first, and
+// the file's size. The caller holds the file's lock.
+func readHistoryFile(filePath string) ([]models.AlertHistoryEntry, int64, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, 0, err
+		}
+		return nil, 0, fmt.Errorf("failed to read alert history: %w", err)
+	}
+	size := int64(len(data))
+	if data, err = compress.DecodeStream(data); err != nil {
+		return nil, 0, fmt.Errorf("failed to decompress alert history: %w", err)
+	}
+
+	var entries []models.AlertHistoryEntry
+	for _, line := range bytes.Split(data, []byte("\n")) {
+		if len(bytes.TrimSpace(line)) == 0 {
+			continue
+		}
+		var entry models.AlertHistoryEntry
+		if err := json.Unmarshal(line, &entry); err != nil {
+			// Skip a partially written trailing line rather than failing the whole read
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	return entries, size, nil
+}
+
+// historyFiles returns the paths of every alert's history file
+func (f *fileAlerts) historyFiles() ([]string, error) {
+	files, err := os.ReadDir(f.historyDir)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read alert history directory: %w", err)
+	}
+	var paths []string
+	for _, file := range files {
+		if !file.IsDir() && filepath.Ext(file.Name()) == ".jsonl" {
+			paths = append(paths, filepath.Join(f.historyDir, file.Name()))
+		}
+	}
+	return paths, nil
+}
+
+func (f *fileAlerts) HistoryUsage() (retention.Usage, error) {
+	var usage retention.Usage
+	paths, err := f.historyFiles()
+	if err != nil {
+		return usage, err
+	}
+	for _, filePath := range paths {
+		unlock := f.fileLocks.Lock(filePath)
+		entries, size, err := readHistoryFile(filePath)
+		unlock()
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return usage, err
+		}
+		usage.Bytes += size
+		for _, entry := range entries {
+			usage.Observe(entry.Timestamp)
+		}
+	}
+	return usage, nil
+}
+
+func (f *fileAlerts) PurgeHistory(before time.Time) (int, error) {
+	paths, err := f.historyFiles()
+	if err != nil {
+		return 0, err
+	}
+	purged := 0
+	for _, filePath := range paths {
+		n, err := f.purgeHistoryFile(filePath, before)
+		purged += n
+		if err != nil {
+			return purged, err
+		}
+	}
+	return purged, nil
+}
+
+func (f *fileAlerts) purgeHistoryFile(filePath string, before time.Time) (int, error) {
+	unlock := f.fileLocks.Lock(filePath)
+	defer unlock()
+
+	entries, _, err := readHistoryFile(filePath)
+	if errors.Is(err, fs.ErrNotExist) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	var kept []byte
+	keptCount := 0
+	for _, entry := range entries {
+		if entry.Timestamp.Before(before) {
+			continue
+		}
+		line, err := json.Marshal(entry)
+		if err != nil {
+			return 0, fmt.Errorf("failed to marshal alert history entry: %w", err)
+		}
+		kept = append(append(kept, line...), '\n')
+		keptCount++
+	}
+	purged := len(entries) - keptCount
+	if purged == 0 {
+		return 0, nil
+	}
+
+	if err := faults.Inject(faults.StoreWrite); err != nil {
+		return 0, fmt.Errorf("failed to write alert history: %w", err)
+	}
+	if keptCount == 0 {
+		if err := os.Remove(filePath); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return 0, fmt.Errorf("failed to delete alert history: %w", err)
+		}
+		return purged, nil
+	}
+	data, err := compress.Encode(f.historyCompression, kept)
+	if err != nil {
+		return 0, fmt.Errorf("failed to compress alert history: %w", err)
+	}
+	if err := os.WriteFile(filePath, data, DefaultFileMode); err != nil {
+		return 0, fmt.Errorf("failed to write alert history: %w", err)
+	}
+	return purged, nil
+}
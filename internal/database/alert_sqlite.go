@@ -0,0 +1,249 @@
+// File: internal/database/alert_sqlite.go
+// Brief: SQLite backend of the alert store
+// Detailed: Stores the alert configurations, their backups and their state change history in the SQLite database of storage.driver sqlite. A write and the backup of the alert it replaces happen in one transaction. History entries are compressed one by one with alerts.history_compression like the lines of the history files, and are purged with a single indexed delete instead of rewriting a file per alert.
+// Author: drama.lin@aver.com
+// Date: 2026-10-14
+
+package database
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"argus/internal/compress"
+	"argus/internal/faults"
+	"argus/internal/models"
+	"argus/internal/retention"
+)
+
+// sqliteAlerts stores alerts in the tables of an SQLite database
+type sqliteAlerts struct {
+	db *sql.DB
+
+	historyCompression compress.Algorithm
+}
+
+func (s *sqliteAlerts) Exists(id string) (bool, error) {
+	var one int
+	err := s.db.QueryRow(`SELECT 1 FROM alerts WHERE id = ?`, id).Scan(&one)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to check alert configuration: %w", err)
+	}
+	return true, nil
+}
+
+func (s *sqliteAlerts) Read(id string) ([]byte, error) {
+	var data []byte
+	err := s.db.QueryRow(`SELECT config FROM alerts WHERE id = ?`, id).Scan(&data)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrAlertNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read alert configuration: %w", err)
+	}
+	return data, nil
+}
+
+func (s *sqliteAlerts) ReadAll() (map[string][]byte, error) {
+	rows, err := s.db.Query(`SELECT id, config FROM alerts`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read alert configurations: %w", err)
+	}
+	defer rows.Close()
+
+	loaded := make(map[string][]byte)
+	for rows.Next() {
+		var (
+			id   string
+			data []byte
+		)
+		if err := rows.Scan(&id, &data); err != nil {
+			return nil, fmt.Errorf("failed to read alert configurations: %w", err)
+		}
+		loaded[id] = data
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read alert configurations: %w", err)
+	}
+	return loaded, nil
+}
+
+func (s *sqliteAlerts) Write(id string, data []byte, backup bool) error {
+	err := withTx(s.db, func(tx *sql.Tx) error {
+		if backup {
+			if err := backupSQLiteAlert(tx, id); err != nil {
+				return fmt.Errorf("failed to create backup: %w", err)
+			}
+		}
+		_, err := tx.Exec(`INSERT INTO alerts (id, config) VALUES (?, ?)
+			ON CONFLICT (id) DO UPDATE SET config = excluded.config`, id, data)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("failed to write alert configuration: %w", err)
+	}
+	return nil
+}
+
+func (s *sqliteAlerts) Remove(id string) error {
+	err := withTx(s.db, func(tx *sql.Tx) error {
+		if err := backupSQLiteAlert(tx, id); err != nil {
+			return fmt.Errorf("failed to create backup: %w", err)
+		}
+		result, err := tx.Exec(`DELETE FROM alerts WHERE id = ?`, id)
+		if err != nil {
+			return err
+		}
+		if n, err := result.RowsAffected(); err != nil {
+			return err
+		} else if n == 0 {
+			return ErrAlertNotFound
+		}
+		return nil
+	})
+	if errors.Is(err, ErrAlertNotFound) {
+		return err
+	}
+	if err != nil {
+		return fmt.Errorf("failed to delete alert configuration: %w", err)
+	}
+	return nil
+}
+
+// backupSQLiteAlert copies the stored configuration of an alert, if any, to
+// its backups
+func backupSQLiteAlert(tx *sql.Tx, id string) error {
+	_, err := tx.Exec(`INSERT OR REPLACE INTO alert_backups (alert_id, taken_at, config)
+		SELECT id, ?, config FROM alerts WHERE id = ?`, time.Now().Format(backupTimestampFormat), id)
+	return err
+}
+
+func (s *sqliteAlerts) Backup(id, timestamp string) ([]byte, error) {
+	var data []byte
+	err := s.db.QueryRow(`SELECT config FROM alert_backups WHERE alert_id = ? AND taken_at = ?`, id, timestamp).Scan(&data)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("backup not found: %s-%s", id, timestamp)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read backup: %w", err)
+	}
+	return data, nil
+}
+
+func (s *sqliteAlerts) Backups(id string) ([]string, error) {
+	rows, err := s.db.Query(`SELECT taken_at FROM alert_backups WHERE alert_id = ? ORDER BY taken_at`, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read backups: %w", err)
+	}
+	defer rows.Close()
+
+	var backups []string
+	for rows.Next() {
+		var timestamp string
+		if err := rows.Scan(&timestamp); err != nil {
+			return nil, fmt.Errorf("failed to read backups: %w", err)
+		}
+		backups = append(backups, timestamp)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read backups: %w", err)
+	}
+	return backups, nil
+}
+
+func (s *sqliteAlerts) SetHistoryCompression(alg compress.Algorithm) {
+	s.historyCompression = alg
+}
+
+func (s *sqliteAlerts) AppendHistory(entry models.AlertHistoryEntry) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed to marshal alert history entry: %w", err)
+	}
+	data, err = compress.Encode(s.historyCompression, data)
+	if err != nil {
+		return fmt.Errorf("failed to compress alert history entry: %w", err)
+	}
+
+	if err := faults.Inject(faults.StoreWrite); err != nil {
+		return fmt.Errorf("failed to write alert history: %w", err)
+	}
+	if _, err := s.db.Exec(`INSERT INTO alert_history (alert_id, timestamp, entry) VALUES (?, ?, ?)`,
+		entry.AlertID, entry.Timestamp.UnixMicro(), data); err != nil {
+		return fmt.Errorf("failed to write alert history: %w", err)
+	}
+	return nil
+}
+
+func (s *sqliteAlerts) History(id string, limit int) ([]models.AlertHistoryEntry, error) {
+	if limit <= 0 {
+		limit = -1 // No limit
+	}
+	rows, err := s.db.Query(`SELECT entry FROM alert_history WHERE alert_id = ? ORDER BY seq DESC LIMIT ?`, id, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read alert history: %w", err)
+	}
+	defer rows.Close()
+
+	var entries []models.AlertHistoryEntry
+	for rows.Next() {
+		var data []byte
+		if err := rows.Scan(&data); err != nil {
+			return nil, fmt.Errorf("failed to read alert history: %w", err)
+		}
+		if data, err = compress.Decode(data); err != nil {
+			return nil, fmt.Errorf("failed to decompress alert history: %w", err)
+		}
+		var entry models.AlertHistoryEntry
+		if err := json.Unmarshal(data, &entry); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal alert history entry: %w", err)
+		}
+		entries = append(entries, entry)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read alert history: %w", err)
+	}
+	return entries, nil
+}
+
+func (s *sqliteAlerts) HistoryUsage() (retention.Usage, error) {
+	var (
+		usage  retention.Usage
+		oldest sql.NullInt64
+	)
+	err := s.db.QueryRow(`SELECT COUNT(*), COALESCE(SUM(LENGTH(entry)), 0), MIN(timestamp) FROM alert_history`).
+		Scan(&usage.Items, &usage.Bytes, &oldest)
+	if err != nil {
+		return usage, fmt.Errorf("failed to read alert history: %w", err)
+	}
+	if oldest.Valid {
+		t := time.UnixMicro(oldest.Int64).UTC()
+		usage.Oldest = &t
+	}
+	return usage, nil
+}
+
+func (s *sqliteAlerts) PurgeHistory(before time.Time) (int, error) {
+	if err := faults.Inject(faults.StoreWrite); err != nil {
+		return 0, fmt.Errorf("failed to write alert history: %w", err)
+	}
+	result, err := s.db.Exec(`DELETE FROM alert_history WHERE timestamp < ?`, before.UnixMicro())
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge alert history: %w", err)
+	}
+	purged, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge alert history: %w", err)
+	}
+	if err := reclaimSQLite(context.Background(), s.db); err != nil {
+		return int(purged), fmt.Errorf("failed to shrink database after purging alert history: %w", err)
+	}
+	return int(purged), nil
+}
@@ -2,18 +2,20 @@
 package database
 
 import (
+	"context"
+	"database/sql"
 	"encoding/json"
 	"errors"
 	"fmt"
-	"io/fs"
-	"os"
-	"path/filepath"
-	"sync"
+	"sort"
 	"time"
 
 	"github.com/google/uuid"
 
+	"argus/internal/compress"
+	"argus/internal/faults"
 	"argus/internal/models"
+	"argus/internal/retention"
 )
 
 const (
@@ -26,11 +28,17 @@ const (
 	// BackupDir is the subdirectory for storing configuration backups
 	BackupDir = "backups"
 
+	// HistoryDir is the subdirectory for alert state change history (one JSON Lines file per alert)
+	HistoryDir = "history"
+
 	// DefaultFileMode is the default file permission mode for configuration files
 	DefaultFileMode = 0644
 
 	// DefaultDirMode is the default directory permission mode
 	DefaultDirMode = 0755
+
+	// backupTimestampFormat names the backups of an alert by when they were taken
+	backupTimestampFormat = "20060102-150405"
 )
 
 var (
@@ -47,81 +55,121 @@ var (
 	ErrFileLocked = errors.New("file is locked for writing")
 )
 
-// AlertStore manages the storage of alert configurations
-type AlertStore struct {
-	configDir string
-	alertsDir string
-	backupDir string
-	mu        sync.RWMutex
-	fileLocks map[string]*sync.Mutex
-	lockMu    sync.Mutex
+// AlertStore manages the storage of alert configurations with their backups
+// and state change history. The built-in drivers wrap an AlertBackend in an
+// in-memory cache with NewCachedAlertStore; drivers may do the same or
+// implement AlertStore directly.
+type AlertStore interface {
+	CreateAlert(alert *models.AlertConfig) error
+	GetAlert(id string) (*models.AlertConfig, error)
+	UpdateAlert(alert *models.AlertConfig) error
+	DeleteAlert(id string) error
+	// ListAlerts returns every alert configuration, ordered by ID
+	ListAlerts() ([]*models.AlertConfig, error)
+	RestoreAlert(id string, timestamp string) error
+	ListBackups(id string) ([]string, error)
+
+	// SaveAlerts creates or replaces several alerts, validating all of them
+	// before storing any; they may be stored asynchronously, until Flush
+	SaveAlerts(alerts []*models.AlertConfig) error
+	Flush() error
+	// StartFlusher stores asynchronous writes every interval until ctx is done
+	StartFlusher(ctx context.Context, interval time.Duration)
+	CacheStats() AlertCacheStats
+
+	SetHistoryCompression(alg compress.Algorithm)
+	AppendHistory(entry models.AlertHistoryEntry) error
+	GetHistory(id string, limit int) ([]models.AlertHistoryEntry, error)
+	HistoryUsage() (retention.Usage, error)
+	PurgeHistory(before time.Time) (int, error)
 }
 
-// NewAlertStore creates a new AlertStore with the given configuration directory
-func NewAlertStore(configDir string) (*AlertStore, error) {
-	if configDir == "" {
-		configDir = DefaultConfigDir
-	}
-
-	alertsDir := filepath.Join(configDir, AlertsDir)
-	backupDir := filepath.Join(configDir, BackupDir)
-
-	// Create directories if they don't exist
-	if err := os.MkdirAll(alertsDir, DefaultDirMode); err != nil {
-		return nil, fmt.Errorf("%w: %s: %v", ErrDirectoryCreation, alertsDir, err)
-	}
-
-	if err := os.MkdirAll(backupDir, DefaultDirMode); err != nil {
-		return nil, fmt.Errorf("%w: %s: %v", ErrDirectoryCreation, backupDir, err)
-	}
+// cachedAlertStore is the AlertStore of an AlertBackend, behind an in-memory
+// cache
+type cachedAlertStore struct {
+	backend AlertBackend
+	locks   *LockMap // Per alert ID; orders each write with its cache update
+	cache   *alertCache
+}
 
-	return &AlertStore{
-		configDir: configDir,
-		alertsDir: alertsDir,
-		backupDir: backupDir,
-		fileLocks: make(map[string]*sync.Mutex),
-	}, nil
+// AlertBackend holds the encoded alert configurations of a cached AlertStore
+// with their backups and state change history. Errors describe what failed.
+// The store serializes the writes of each alert, but not of different alerts.
+type AlertBackend interface {
+	// Exists reports whether an alert is stored
+	Exists(id string) (bool, error)
+	// Read returns a stored alert, or ErrAlertNotFound
+	Read(id string) ([]byte, error)
+	// ReadAll returns every stored alert by ID
+	ReadAll() (map[string][]byte, error)
+	// Write stores an alert, first backing up the one it replaces if backup is set
+	Write(id string, data []byte, backup bool) error
+	// Remove backs up and deletes a stored alert, or returns ErrAlertNotFound
+	Remove(id string) error
+	// Backup returns the backup of an alert taken at timestamp
+	Backup(id, timestamp string) ([]byte, error)
+	// Backups returns the timestamps of an alert's backups, oldest first
+	Backups(id string) ([]string, error)
+
+	SetHistoryCompression(alg compress.Algorithm)
+	AppendHistory(entry models.AlertHistoryEntry) error
+	// History returns up to limit entries of an alert, newest first, or every
+	// entry for a limit of zero or less
+	History(id string, limit int) ([]models.AlertHistoryEntry, error)
+	HistoryUsage() (retention.Usage, error)
+	PurgeHistory(before time.Time) (int, error)
 }
 
-// getFileLock returns a mutex for the given file path, creating one if it doesn't exist
-func (s *AlertStore) getFileLock(path string) *sync.Mutex {
-	s.lockMu.Lock()
-	defer s.lockMu.Unlock()
+var (
+	_ AlertBackend = (*fileAlerts)(nil)
+	_ AlertBackend = (*sqliteAlerts)(nil)
+)
 
-	if lock, exists := s.fileLocks[path]; exists {
-		return lock
+// NewAlertStore creates an AlertStore keeping the alerts in JSON files under
+// the given configuration directory
+func NewAlertStore(configDir string) (AlertStore, error) {
+	if configDir == "" {
+		configDir = DefaultConfigDir
 	}
-
-	lock := &sync.Mutex{}
-	s.fileLocks[path] = lock
-	return lock
+	files, err := newFileAlerts(configDir)
+	if err != nil {
+		return nil, err
+	}
+	return NewCachedAlertStore(files), nil
 }
 
-// alertFilePath returns the file path for the given alert ID
-func (s *AlertStore) alertFilePath(id string) string {
-	return filepath.Join(s.alertsDir, fmt.Sprintf("%s.json", id))
+// NewSQLiteAlertStore creates an AlertStore keeping the alerts in the SQLite
+// database opened by OpenSQLite
+func NewSQLiteAlertStore(db *sql.DB) AlertStore {
+	return NewCachedAlertStore(&sqliteAlerts{db: db})
 }
 
-// backupFilePath returns the backup file path for the given alert ID
-func (s *AlertStore) backupFilePath(id string) string {
-	timestamp := time.Now().Format("20060102-150405")
-	return filepath.Join(s.backupDir, fmt.Sprintf("%s-%s.json", id, timestamp))
+// NewCachedAlertStore creates an AlertStore keeping the alerts in backend,
+// behind an in-memory cache
+func NewCachedAlertStore(backend AlertBackend) AlertStore {
+	return &cachedAlertStore{
+		backend: backend,
+		locks:   NewLockMap(),
+		cache:   newAlertCache(),
+	}
 }
 
 // CreateAlert stores a new alert configuration
-func (s *AlertStore) CreateAlert(alert *models.AlertConfig) error {
+func (s *cachedAlertStore) CreateAlert(alert *models.AlertConfig) error {
 	// Check if alert ID is valid
 	if alert.ID == "" {
 		// Generate a new UUID if ID is empty
 		alert.ID = uuid.New().String()
 	}
+	if err := s.flushAlert(alert.ID); err != nil {
+		return err
+	}
 
-	// Check if file already exists
-	filePath := s.alertFilePath(alert.ID)
-	if _, err := os.Stat(filePath); err == nil {
+	// Check if the alert already exists
+	if exists, err := s.backend.Exists(alert.ID); err != nil {
+		return err
+	} else if exists {
 		return fmt.Errorf("alert with ID %s already exists", alert.ID)
-	} else if !errors.Is(err, fs.ErrNotExist) {
-		return fmt.Errorf("error checking file: %w", err)
 	}
 
 	// Update timestamps
@@ -134,49 +182,43 @@ func (s *AlertStore) CreateAlert(alert *models.AlertConfig) error {
 		return fmt.Errorf("invalid alert configuration: %w", err)
 	}
 
-	// Get file lock
-	lock := s.getFileLock(filePath)
-	lock.Lock()
-	defer lock.Unlock()
-
 	// Marshal the alert configuration to JSON
 	data, err := json.MarshalIndent(alert, "", "  ")
 	if err != nil {
 		return fmt.Errorf("failed to marshal alert configuration: %w", err)
 	}
 
-	// Write the file
-	if err := os.WriteFile(filePath, data, DefaultFileMode); err != nil {
+	unlock := s.locks.Lock(alert.ID)
+	defer unlock()
+
+	if err := faults.Inject(faults.StoreWrite); err != nil {
 		return fmt.Errorf("failed to write alert configuration: %w", err)
 	}
+	if err := s.backend.Write(alert.ID, data, false); err != nil {
+		return err
+	}
+	s.cache.put(alert.ID, data, false)
 
 	return nil
 }
 
 // GetAlert retrieves an alert configuration by ID
-func (s *AlertStore) GetAlert(id string) (*models.AlertConfig, error) {
+func (s *cachedAlertStore) GetAlert(id string) (*models.AlertConfig, error) {
 	if id == "" {
 		return nil, ErrInvalidAlertID
 	}
 
-	filePath := s.alertFilePath(id)
-
-	// Check if file exists
-	if _, err := os.Stat(filePath); err != nil {
-		if errors.Is(err, fs.ErrNotExist) {
-			return nil, ErrAlertNotFound
-		}
-		return nil, fmt.Errorf("error checking file: %w", err)
+	if err := faults.Inject(faults.StoreRead); err != nil {
+		return nil, fmt.Errorf("failed to read alert configuration: %w", err)
 	}
+	if alert, ok, err := s.cache.get(id); ok {
+		return alert, err
+	}
+	version := s.cache.snapshot()
 
-	// Get file lock for reading
-	s.mu.RLock()
-	defer s.mu.RUnlock()
-
-	// Read the file
-	data, err := os.ReadFile(filePath)
+	data, err := s.backend.Read(id)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read alert configuration: %w", err)
+		return nil, err
 	}
 
 	// Unmarshal the JSON data
@@ -184,29 +226,26 @@ func (s *AlertStore) GetAlert(id string) (*models.AlertConfig, error) {
 	if err := json.Unmarshal(data, alert); err != nil {
 		return nil, fmt.Errorf("failed to unmarshal alert configuration: %w", err)
 	}
+	s.cache.load(id, data, version)
 
 	return alert, nil
 }
 
-// UpdateAlert updates an existing alert configuration
-func (s *AlertStore) UpdateAlert(alert *models.AlertConfig) error {
+// UpdateAlert updates an existing alert configuration, backing up the one
+// it replaces
+func (s *cachedAlertStore) UpdateAlert(alert *models.AlertConfig) error {
 	if alert.ID == "" {
 		return ErrInvalidAlertID
 	}
-
-	filePath := s.alertFilePath(alert.ID)
-
-	// Check if file exists
-	if _, err := os.Stat(filePath); err != nil {
-		if errors.Is(err, fs.ErrNotExist) {
-			return ErrAlertNotFound
-		}
-		return fmt.Errorf("error checking file: %w", err)
+	if err := s.flushAlert(alert.ID); err != nil {
+		return err
 	}
 
-	// Create a backup of the existing file
-	if err := s.backupAlert(alert.ID); err != nil {
-		return fmt.Errorf("failed to create backup: %w", err)
+	// Check if the alert exists
+	if exists, err := s.backend.Exists(alert.ID); err != nil {
+		return err
+	} else if !exists {
+		return ErrAlertNotFound
 	}
 
 	// Update timestamp
@@ -217,139 +256,99 @@ func (s *AlertStore) UpdateAlert(alert *models.AlertConfig) error {
 		return fmt.Errorf("invalid alert configuration: %w", err)
 	}
 
-	// Get file lock
-	lock := s.getFileLock(filePath)
-	lock.Lock()
-	defer lock.Unlock()
-
 	// Marshal the alert configuration to JSON
 	data, err := json.MarshalIndent(alert, "", "  ")
 	if err != nil {
 		return fmt.Errorf("failed to marshal alert configuration: %w", err)
 	}
 
-	// Write the file
-	if err := os.WriteFile(filePath, data, DefaultFileMode); err != nil {
+	unlock := s.locks.Lock(alert.ID)
+	defer unlock()
+
+	if err := faults.Inject(faults.StoreWrite); err != nil {
 		return fmt.Errorf("failed to write alert configuration: %w", err)
 	}
+	if err := s.backend.Write(alert.ID, data, true); err != nil {
+		return err
+	}
+	s.cache.put(alert.ID, data, false)
 
 	return nil
 }
 
-// DeleteAlert removes an alert configuration
-func (s *AlertStore) DeleteAlert(id string) error {
+// DeleteAlert removes an alert configuration, keeping a backup of it
+func (s *cachedAlertStore) DeleteAlert(id string) error {
 	if id == "" {
 		return ErrInvalidAlertID
 	}
-
-	filePath := s.alertFilePath(id)
-
-	// Check if file exists
-	if _, err := os.Stat(filePath); err != nil {
-		if errors.Is(err, fs.ErrNotExist) {
-			return ErrAlertNotFound
-		}
-		return fmt.Errorf("error checking file: %w", err)
-	}
-
-	// Create a backup of the file before deletion
-	if err := s.backupAlert(id); err != nil {
-		return fmt.Errorf("failed to create backup: %w", err)
+	if err := s.flushAlert(id); err != nil {
+		return err
 	}
 
-	// Get file lock
-	lock := s.getFileLock(filePath)
-	lock.Lock()
-	defer lock.Unlock()
+	unlock := s.locks.Lock(id)
+	defer unlock()
 
-	// Delete the file
-	if err := os.Remove(filePath); err != nil {
+	if err := faults.Inject(faults.StoreWrite); err != nil {
 		return fmt.Errorf("failed to delete alert configuration: %w", err)
 	}
+	if err := s.backend.Remove(id); err != nil {
+		return err
+	}
+	s.cache.remove(id)
 
 	return nil
 }
 
-// ListAlerts returns a list of all alert configurations
-func (s *AlertStore) ListAlerts() ([]*models.AlertConfig, error) {
-	s.mu.RLock()
-	defer s.mu.RUnlock()
-
-	var alertConfigs []*models.AlertConfig
-
-	// Read all JSON files in the alerts directory
-	files, err := os.ReadDir(s.alertsDir)
-	if err != nil {
+// ListAlerts returns a list of all alert configurations, ordered by ID
+func (s *cachedAlertStore) ListAlerts() ([]*models.AlertConfig, error) {
+	if err := faults.Inject(faults.StoreRead); err != nil {
 		return nil, fmt.Errorf("failed to read alerts directory: %w", err)
 	}
+	if alerts, ok, err := s.cache.list(); ok {
+		return alerts, err
+	}
+	// Pending bulk writes must be stored for the listing to include them
+	if err := s.Flush(); err != nil {
+		return nil, err
+	}
+	version := s.cache.snapshot()
 
-	for _, file := range files {
-		if file.IsDir() || filepath.Ext(file.Name()) != ".json" {
-			continue
-		}
-
-		filePath := filepath.Join(s.alertsDir, file.Name())
-		data, err := os.ReadFile(filePath)
-		if err != nil {
-			return nil, fmt.Errorf("failed to read alert configuration %s: %w", file.Name(), err)
-		}
+	loaded, err := s.backend.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]string, 0, len(loaded))
+	for id := range loaded {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
 
+	alertConfigs := make([]*models.AlertConfig, 0, len(ids))
+	for _, id := range ids {
 		alert := &models.AlertConfig{}
-		if err := json.Unmarshal(data, alert); err != nil {
-			return nil, fmt.Errorf("failed to unmarshal alert configuration %s: %w", file.Name(), err)
+		if err := json.Unmarshal(loaded[id], alert); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal alert configuration %s: %w", id, err)
 		}
-
 		alertConfigs = append(alertConfigs, alert)
 	}
+	s.cache.fill(loaded, version)
 
 	return alertConfigs, nil
 }
 
-// backupAlert creates a backup of an alert configuration
-func (s *AlertStore) backupAlert(id string) error {
-	srcPath := s.alertFilePath(id)
-	dstPath := s.backupFilePath(id)
-
-	// Read the source file
-	data, err := os.ReadFile(srcPath)
-	if err != nil {
-		return fmt.Errorf("failed to read alert configuration for backup: %w", err)
-	}
-
-	// Write the backup file
-	if err := os.WriteFile(dstPath, data, DefaultFileMode); err != nil {
-		return fmt.Errorf("failed to write backup file: %w", err)
-	}
-
-	return nil
-}
-
 // RestoreAlert restores an alert configuration from a backup
-func (s *AlertStore) RestoreAlert(id string, timestamp string) error {
+func (s *cachedAlertStore) RestoreAlert(id string, timestamp string) error {
 	if id == "" {
 		return ErrInvalidAlertID
 	}
 
-	backupPath := filepath.Join(s.backupDir, fmt.Sprintf("%s-%s.json", id, timestamp))
-	destPath := s.alertFilePath(id)
-
-	// Check if backup file exists
-	if _, err := os.Stat(backupPath); err != nil {
-		if errors.Is(err, fs.ErrNotExist) {
-			return fmt.Errorf("backup file not found: %s", backupPath)
-		}
-		return fmt.Errorf("error checking backup file: %w", err)
+	if err := s.flushAlert(id); err != nil {
+		return err
 	}
 
-	// Get file lock
-	lock := s.getFileLock(destPath)
-	lock.Lock()
-	defer lock.Unlock()
-
-	// Read the backup file
-	data, err := os.ReadFile(backupPath)
+	data, err := s.backend.Backup(id, timestamp)
 	if err != nil {
-		return fmt.Errorf("failed to read backup file: %w", err)
+		return err
 	}
 
 	// Validate the backup data
@@ -358,43 +357,66 @@ func (s *AlertStore) RestoreAlert(id string, timestamp string) error {
 		return fmt.Errorf("invalid backup file: %w", err)
 	}
 
-	// Write the file
-	if err := os.WriteFile(destPath, data, DefaultFileMode); err != nil {
+	unlock := s.locks.Lock(id)
+	defer unlock()
+
+	if err := s.backend.Write(id, data, false); err != nil {
 		return fmt.Errorf("failed to restore alert configuration: %w", err)
 	}
+	s.cache.put(id, data, false)
 
 	return nil
 }
 
 // ListBackups returns a list of available backups for an alert
-func (s *AlertStore) ListBackups(id string) ([]string, error) {
+func (s *cachedAlertStore) ListBackups(id string) ([]string, error) {
 	if id == "" {
 		return nil, ErrInvalidAlertID
 	}
+	return s.backend.Backups(id)
+}
 
-	s.mu.RLock()
-	defer s.mu.RUnlock()
-
-	var backups []string
-	prefix := fmt.Sprintf("%s-", id)
+// SetHistoryCompression sets the algorithm used for newly appended history
+// entries. Each entry is compressed on its own, so the history may mix plain
+// and compressed entries and stays readable after the setting changes.
+func (s *cachedAlertStore) SetHistoryCompression(alg compress.Algorithm) {
+	s.backend.SetHistoryCompression(alg)
+}
 
-	// Read all files in the backup directory
-	files, err := os.ReadDir(s.backupDir)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read backups directory: %w", err)
+// AppendHistory appends a state change record to the alert's history
+func (s *cachedAlertStore) AppendHistory(entry models.AlertHistoryEntry) error {
+	if entry.AlertID == "" {
+		return ErrInvalidAlertID
 	}
+	return s.backend.AppendHistory(entry)
+}
 
-	for _, file := range files {
-		if file.IsDir() || filepath.Ext(file.Name()) != ".json" {
-			continue
-		}
+// GetHistory returns up to limit history entries for an alert, newest first.
+// A limit of zero or less returns every entry.
+func (s *cachedAlertStore) GetHistory(id string, limit int) ([]models.AlertHistoryEntry, error) {
+	if id == "" {
+		return nil, ErrInvalidAlertID
+	}
 
-		if len(file.Name()) > len(prefix) && file.Name()[:len(prefix)] == prefix {
-			// Extract timestamp from filename
-			timestamp := file.Name()[len(prefix) : len(file.Name())-5] // Remove prefix and .json extension
-			backups = append(backups, timestamp)
-		}
+	entries, err := s.backend.History(id, limit)
+	if err != nil {
+		return nil, err
+	}
+	if entries == nil {
+		entries = []models.AlertHistoryEntry{}
 	}
+	return entries, nil
+}
+
+// HistoryUsage reports the number of history entries of all alerts, their
+// stored size and the oldest entry's timestamp
+func (s *cachedAlertStore) HistoryUsage() (retention.Usage, error) {
+	return s.backend.HistoryUsage()
+}
 
-	return backups, nil
+// PurgeHistory removes history entries older than before from every alert's
+// history and returns the number of entries removed. History files left
+// empty are deleted, and rewritten ones use the current history compression.
+func (s *cachedAlertStore) PurgeHistory(before time.Time) (int, error) {
+	return s.backend.PurgeHistory(before)
 }
@@ -0,0 +1,192 @@
+// File: internal/database/dashboard_store.go
+// Brief: Persistent store for dashboards
+// Detailed: Keeps dashboard definitions in memory and persists each one as a JSON file next to the alert configurations. Widget payloads are computed on request and never stored.
+// Author: drama.lin@aver.com
+// Date: 2026-10-14
+
+package database
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"argus/internal/faults"
+	"argus/internal/models"
+)
+
+// DashboardsDir is the subdirectory for storing dashboards
+const DashboardsDir = "dashboards"
+
+// ErrDashboardNotFound is returned when a dashboard is not found
+var ErrDashboardNotFound = errors.New("dashboard not found")
+
+// DashboardStore manages the storage of dashboards
+type DashboardStore struct {
+	dir        string
+	mu         sync.RWMutex
+	dashboards map[string]*models.Dashboard
+	fileLocks  *LockMap
+	now        func() time.Time
+}
+
+// NewDashboardStore creates a dashboard store under configDir and loads the
+// dashboards already stored there
+func NewDashboardStore(configDir string) (*DashboardStore, error) {
+	if configDir == "" {
+		configDir = DefaultConfigDir
+	}
+	dir := filepath.Join(configDir, DashboardsDir)
+	if err := os.MkdirAll(dir, DefaultDirMode); err != nil {
+		return nil, fmt.Errorf("%w: %s: %v", ErrDirectoryCreation, dir, err)
+	}
+
+	s := &DashboardStore{
+		dir:        dir,
+		dashboards: make(map[string]*models.Dashboard),
+		fileLocks:  NewLockMap(),
+		now:        time.Now,
+	}
+	if err := s.load(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *DashboardStore) dashboardFilePath(id string) string {
+	return filepath.Join(s.dir, id+".json")
+}
+
+// load reads every stored dashboard, skipping files that cannot be parsed
+func (s *DashboardStore) load() error {
+	files, err := os.ReadDir(s.dir)
+	if err != nil {
+		return fmt.Errorf("failed to read dashboards directory: %w", err)
+	}
+	for _, file := range files {
+		if file.IsDir() || filepath.Ext(file.Name()) != ".json" {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(s.dir, file.Name()))
+		if err != nil {
+			return fmt.Errorf("failed to read dashboard %s: %w", file.Name(), err)
+		}
+		dashboard := &models.Dashboard{}
+		if err := json.Unmarshal(data, dashboard); err != nil {
+			slog.Warn("Skipping unreadable dashboard", "file", file.Name(), "error", err)
+			continue
+		}
+		if err := dashboard.Validate(); err != nil {
+			slog.Warn("Skipping invalid dashboard", "file", file.Name(), "error", err)
+			continue
+		}
+		s.dashboards[dashboard.ID] = dashboard
+	}
+	return nil
+}
+
+// Save creates a dashboard, or replaces it when its ID already exists. A new
+// ID is generated when empty, as are missing widget IDs; the creation time of
+// an existing dashboard is kept.
+func (s *DashboardStore) Save(dashboard *models.Dashboard) error {
+	if dashboard.ID == "" {
+		dashboard.ID = uuid.New().String()
+	}
+	if dashboard.Widgets == nil {
+		dashboard.Widgets = []models.Widget{}
+	}
+	dashboard.AssignWidgetIDs()
+	now := s.now().UTC()
+	dashboard.UpdatedAt = now
+	if existing, err := s.Get(dashboard.ID); err == nil {
+		dashboard.CreatedAt = existing.CreatedAt
+	} else {
+		dashboard.CreatedAt = now
+	}
+	if err := dashboard.Validate(); err != nil {
+		return fmt.Errorf("invalid dashboard: %w", err)
+	}
+
+	data, err := json.MarshalIndent(dashboard, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal dashboard: %w", err)
+	}
+	filePath := s.dashboardFilePath(dashboard.ID)
+	unlock := s.fileLocks.Lock(filePath)
+	defer unlock()
+	if err := faults.Inject(faults.StoreWrite); err != nil {
+		return fmt.Errorf("failed to write dashboard: %w", err)
+	}
+	if err := os.WriteFile(filePath, data, DefaultFileMode); err != nil {
+		return fmt.Errorf("failed to write dashboard: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.dashboards[dashboard.ID] = cloneDashboard(dashboard)
+	return nil
+}
+
+// Get returns a copy of the dashboard with the given ID
+func (s *DashboardStore) Get(id string) (*models.Dashboard, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	dashboard, ok := s.dashboards[id]
+	if !ok {
+		return nil, ErrDashboardNotFound
+	}
+	return cloneDashboard(dashboard), nil
+}
+
+// List returns copies of every dashboard, sorted by name
+func (s *DashboardStore) List() []*models.Dashboard {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	result := make([]*models.Dashboard, 0, len(s.dashboards))
+	for _, dashboard := range s.dashboards {
+		result = append(result, cloneDashboard(dashboard))
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Name != result[j].Name {
+			return result[i].Name < result[j].Name
+		}
+		return result[i].ID < result[j].ID
+	})
+	return result
+}
+
+// Delete removes a dashboard
+func (s *DashboardStore) Delete(id string) error {
+	s.mu.Lock()
+	if _, ok := s.dashboards[id]; !ok {
+		s.mu.Unlock()
+		return ErrDashboardNotFound
+	}
+	delete(s.dashboards, id)
+	s.mu.Unlock()
+
+	filePath := s.dashboardFilePath(id)
+	unlock := s.fileLocks.Lock(filePath)
+	defer unlock()
+	if err := os.Remove(filePath); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("failed to delete dashboard: %w", err)
+	}
+	return nil
+}
+
+// cloneDashboard copies a dashboard together with its widget list
+func cloneDashboard(dashboard *models.Dashboard) *models.Dashboard {
+	copied := *dashboard
+	copied.Widgets = slices.Clone(dashboard.Widgets)
+	return &copied
+}
@@ -0,0 +1,140 @@
+// File: internal/database/driver.go
+// Brief: Registry of the storage drivers of alerts and tasks
+// Detailed: A storage driver opens the AlertStore and TaskRepository selected by storage.driver. The file and sqlite drivers are built in; other backends, such as Postgres or Redis, are Go packages that call RegisterDriver from an init function and are linked into the binary with a blank import, so no core code changes to add one. Drivers read their settings, e.g. a connection string, from storage.options. A driver may keep its data itself behind the shared alert cache by implementing AlertBackend and wrapping it with NewCachedAlertStore.
+// Author: drama.lin@aver.com
+// Date: 2026-10-14
+
+package database
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Built-in storage drivers
+const (
+	DriverFile   = "file"
+	DriverSQLite = "sqlite"
+)
+
+// DriverOptions are the settings a storage driver is opened with
+type DriverOptions struct {
+	AlertsPath string            // alerts.storage_path: alert files of the file driver, imported by the sqlite driver
+	TasksPath  string            // tasks.storage_path: task files of the file driver, imported by the sqlite driver
+	Options    map[string]string // storage.options, e.g. the "path" of the sqlite driver or a DSN
+}
+
+// Stores are the stores opened by a storage driver
+type Stores struct {
+	Alerts AlertStore
+	Tasks  TaskRepository
+	Closer io.Closer // Released on shutdown, e.g. a connection pool; may be nil
+}
+
+// Close releases what the driver holds open
+func (s *Stores) Close() error {
+	if s.Closer == nil {
+		return nil
+	}
+	return s.Closer.Close()
+}
+
+// Driver opens the stores of a storage backend
+type Driver interface {
+	Open(opts DriverOptions) (*Stores, error)
+}
+
+// DriverFunc adapts a function to a Driver
+type DriverFunc func(opts DriverOptions) (*Stores, error)
+
+// Open calls f(opts)
+func (f DriverFunc) Open(opts DriverOptions) (*Stores, error) {
+	return f(opts)
+}
+
+var (
+	driversMu sync.RWMutex
+	drivers   = map[string]Driver{
+		DriverFile:   DriverFunc(openFileDriver),
+		DriverSQLite: DriverFunc(openSQLiteDriver),
+	}
+)
+
+// RegisterDriver makes a storage driver available under name. Like
+// database/sql drivers it is meant to be called from an init function, and it
+// panics if the driver is nil or the name is already registered.
+func RegisterDriver(name string, driver Driver) {
+	driversMu.Lock()
+	defer driversMu.Unlock()
+	if driver == nil {
+		panic("database: RegisterDriver driver is nil")
+	}
+	if _, dup := drivers[name]; dup {
+		panic("database: RegisterDriver called twice for driver " + name)
+	}
+	drivers[name] = driver
+}
+
+// Drivers returns the names of the registered storage drivers, sorted
+func Drivers() []string {
+	driversMu.RLock()
+	defer driversMu.RUnlock()
+	names := make([]string, 0, len(drivers))
+	for name := range drivers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// OpenDriver opens the stores of the storage driver registered under name
+func OpenDriver(name string, opts DriverOptions) (*Stores, error) {
+	driversMu.RLock()
+	driver, ok := drivers[name]
+	driversMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unknown storage driver %q: registered drivers are %s", name, strings.Join(Drivers(), ", "))
+	}
+	stores, err := driver.Open(opts)
+	if err != nil {
+		return nil, fmt.Errorf("storage driver %s: %w", name, err)
+	}
+	if stores == nil || stores.Alerts == nil || stores.Tasks == nil {
+		if stores != nil {
+			stores.Close()
+		}
+		return nil, fmt.Errorf("storage driver %s returned no alert store or task repository", name)
+	}
+	return stores, nil
+}
+
+// openFileDriver opens the JSON file stores
+func openFileDriver(opts DriverOptions) (*Stores, error) {
+	alerts, err := NewAlertStore(opts.AlertsPath)
+	if err != nil {
+		return nil, err
+	}
+	tasks, err := NewFileTaskRepository(opts.TasksPath)
+	if err != nil {
+		return nil, err
+	}
+	return &Stores{Alerts: alerts, Tasks: tasks}, nil
+}
+
+// openSQLiteDriver opens the SQLite database at the "path" option, importing
+// the JSON file stores into a new one
+func openSQLiteDriver(opts DriverOptions) (*Stores, error) {
+	path := opts.Options["path"]
+	if path == "" {
+		return nil, errors.New(`the "path" option is required`)
+	}
+	db, err := OpenSQLite(path, opts.AlertsPath, opts.TasksPath)
+	if err != nil {
+		return nil, err
+	}
+	return &Stores{Alerts: NewSQLiteAlertStore(db), Tasks: NewSQLiteTaskRepository(db), Closer: db}, nil
+}
@@ -0,0 +1,56 @@
+package database
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOpenDriver_BuiltIn(t *testing.T) {
+	dir := t.TempDir()
+	opts := DriverOptions{AlertsPath: filepath.Join(dir, "alerts"), TasksPath: filepath.Join(dir, "tasks")}
+
+	stores, err := OpenDriver(DriverFile, opts)
+	require.NoError(t, err)
+	require.NoError(t, stores.Alerts.CreateAlert(testAlert("a1")))
+	require.NoError(t, stores.Close(), "the file driver holds nothing open")
+
+	_, err = OpenDriver(DriverSQLite, opts)
+	assert.ErrorContains(t, err, `"path" option is required`)
+	opts.Options = map[string]string{"path": filepath.Join(dir, "argus.db")}
+	stores, err = OpenDriver(DriverSQLite, opts)
+	require.NoError(t, err)
+	_, err = stores.Alerts.GetAlert("a1")
+	assert.NoError(t, err, "a new database imports the file stores")
+	require.NoError(t, stores.Close())
+
+	_, err = OpenDriver("postgres", opts)
+	assert.ErrorContains(t, err, `unknown storage driver "postgres": registered drivers are file, sqlite`)
+}
+
+func TestRegisterDriver(t *testing.T) {
+	var opened DriverOptions
+	RegisterDriver("test", DriverFunc(func(opts DriverOptions) (*Stores, error) {
+		opened = opts
+		alerts, err := NewAlertStore(opts.AlertsPath)
+		if err != nil {
+			return nil, err
+		}
+		return &Stores{Alerts: alerts}, nil
+	}))
+	t.Cleanup(func() {
+		driversMu.Lock()
+		delete(drivers, "test")
+		driversMu.Unlock()
+	})
+	assert.Contains(t, Drivers(), "test")
+	assert.Panics(t, func() { RegisterDriver("test", DriverFunc(openFileDriver)) })
+	assert.Panics(t, func() { RegisterDriver("other", nil) })
+
+	opts := DriverOptions{AlertsPath: t.TempDir(), Options: map[string]string{"dsn": "test://"}}
+	_, err := OpenDriver("test", opts)
+	assert.ErrorContains(t, err, "returned no alert store or task repository")
+	assert.Equal(t, "test://", opened.Options["dsn"])
+}
@@ -0,0 +1,59 @@
+// File: internal/database/file_locks.go
+// Brief: Reference-counted per-path file locks
+// Detailed: Serializes writers of the same file while keeping the lock map bounded by the number of paths currently in use; entries are evicted as soon as their last holder releases them.
+// Author: drama.lin@aver.com
+// Date: 2026-10-14
+
+package database
+
+import "sync"
+
+// lockEntry is a path's mutex and the number of goroutines holding or waiting for it
+type lockEntry struct {
+	mu   sync.Mutex
+	refs int
+}
+
+// LockMap hands out one mutex per file path. Unlike a plain map of mutexes it
+// removes an entry once nobody holds or waits for it, so storing many
+// distinct files (e.g. one per task execution) does not grow memory forever.
+type LockMap struct {
+	mu    sync.Mutex
+	locks map[string]*lockEntry
+}
+
+// NewLockMap creates an empty lock map
+func NewLockMap() *LockMap {
+	return &LockMap{locks: make(map[string]*lockEntry)}
+}
+
+// Lock blocks until the lock for path is held and returns the function that
+// releases it. The caller must call unlock exactly once.
+func (m *LockMap) Lock(path string) (unlock func()) {
+	m.mu.Lock()
+	entry, ok := m.locks[path]
+	if !ok {
+		entry = &lockEntry{}
+		m.locks[path] = entry
+	}
+	entry.refs++
+	m.mu.Unlock()
+
+	entry.mu.Lock()
+	return func() {
+		entry.mu.Unlock()
+		m.mu.Lock()
+		entry.refs--
+		if entry.refs == 0 {
+			delete(m.locks, path)
+		}
+		m.mu.Unlock()
+	}
+}
+
+// Len returns the number of paths currently locked or waited on
+func (m *LockMap) Len() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return len(m.locks)
+}
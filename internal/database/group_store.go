@@ -0,0 +1,182 @@
+// File: internal/database/group_store.go
+// Brief: Persistent store for alert groups
+// Detailed: Keeps alert groups (folders) in memory and persists each one as a JSON file next to the alert configurations. Alerts reference their group by ID; the store does not track membership.
+// Author: drama.lin@aver.com
+// Date: 2026-10-14
+
+package database
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"argus/internal/faults"
+	"argus/internal/models"
+)
+
+// GroupsDir is the subdirectory for storing alert groups
+const GroupsDir = "groups"
+
+// ErrGroupNotFound is returned when an alert group is not found
+var ErrGroupNotFound = errors.New("alert group not found")
+
+// GroupStore manages the storage of alert groups
+type GroupStore struct {
+	dir       string
+	mu        sync.RWMutex
+	groups    map[string]*models.AlertGroup
+	fileLocks *LockMap
+	now       func() time.Time
+}
+
+// NewGroupStore creates a group store under configDir and loads the groups
+// already stored there
+func NewGroupStore(configDir string) (*GroupStore, error) {
+	if configDir == "" {
+		configDir = DefaultConfigDir
+	}
+	dir := filepath.Join(configDir, GroupsDir)
+	if err := os.MkdirAll(dir, DefaultDirMode); err != nil {
+		return nil, fmt.Errorf("%w: %s: %v", ErrDirectoryCreation, dir, err)
+	}
+
+	s := &GroupStore{
+		dir:       dir,
+		groups:    make(map[string]*models.AlertGroup),
+		fileLocks: NewLockMap(),
+		now:       time.Now,
+	}
+	if err := s.load(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *GroupStore) groupFilePath(id string) string {
+	return filepath.Join(s.dir, id+".json")
+}
+
+// load reads every stored group, skipping files that cannot be parsed
+func (s *GroupStore) load() error {
+	files, err := os.ReadDir(s.dir)
+	if err != nil {
+		return fmt.Errorf("failed to read groups directory: %w", err)
+	}
+	for _, file := range files {
+		if file.IsDir() || filepath.Ext(file.Name()) != ".json" {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(s.dir, file.Name()))
+		if err != nil {
+			return fmt.Errorf("failed to read group %s: %w", file.Name(), err)
+		}
+		group := &models.AlertGroup{}
+		if err := json.Unmarshal(data, group); err != nil {
+			slog.Warn("Skipping unreadable alert group", "file", file.Name(), "error", err)
+			continue
+		}
+		if err := group.Validate(); err != nil {
+			slog.Warn("Skipping invalid alert group", "file", file.Name(), "error", err)
+			continue
+		}
+		s.groups[group.ID] = group
+	}
+	return nil
+}
+
+// Save creates a group, or replaces it when its ID already exists. A new ID
+// is generated when empty; the creation time of an existing group is kept.
+func (s *GroupStore) Save(group *models.AlertGroup) error {
+	if group.ID == "" {
+		group.ID = uuid.New().String()
+	}
+	now := s.now().UTC()
+	group.UpdatedAt = now
+	if existing, err := s.Get(group.ID); err == nil {
+		group.CreatedAt = existing.CreatedAt
+	} else {
+		group.CreatedAt = now
+	}
+	if err := group.Validate(); err != nil {
+		return fmt.Errorf("invalid alert group: %w", err)
+	}
+
+	data, err := json.MarshalIndent(group, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal alert group: %w", err)
+	}
+	filePath := s.groupFilePath(group.ID)
+	unlock := s.fileLocks.Lock(filePath)
+	defer unlock()
+	if err := faults.Inject(faults.StoreWrite); err != nil {
+		return fmt.Errorf("failed to write alert group: %w", err)
+	}
+	if err := os.WriteFile(filePath, data, DefaultFileMode); err != nil {
+		return fmt.Errorf("failed to write alert group: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	stored := *group
+	s.groups[group.ID] = &stored
+	return nil
+}
+
+// Get returns a copy of the group with the given ID
+func (s *GroupStore) Get(id string) (*models.AlertGroup, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	group, ok := s.groups[id]
+	if !ok {
+		return nil, ErrGroupNotFound
+	}
+	copied := *group
+	return &copied, nil
+}
+
+// List returns copies of every group, sorted by name
+func (s *GroupStore) List() []*models.AlertGroup {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	result := make([]*models.AlertGroup, 0, len(s.groups))
+	for _, group := range s.groups {
+		copied := *group
+		result = append(result, &copied)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Name != result[j].Name {
+			return result[i].Name < result[j].Name
+		}
+		return result[i].ID < result[j].ID
+	})
+	return result
+}
+
+// Delete removes a group. Alerts still referencing it are left to the caller.
+func (s *GroupStore) Delete(id string) error {
+	s.mu.Lock()
+	if _, ok := s.groups[id]; !ok {
+		s.mu.Unlock()
+		return ErrGroupNotFound
+	}
+	delete(s.groups, id)
+	s.mu.Unlock()
+
+	filePath := s.groupFilePath(id)
+	unlock := s.fileLocks.Lock(filePath)
+	defer unlock()
+	if err := os.Remove(filePath); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("failed to delete alert group: %w", err)
+	}
+	return nil
+}
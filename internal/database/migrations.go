@@ -0,0 +1,94 @@
+// File: internal/database/migrations.go
+// Brief: Migrations of the alert and task store layouts
+// Detailed: Lists the versioned changes to the on-disk layout of the file stores, applied in order on startup by the migrate package. Append new migrations at the end; never renumber or change released ones.
+// Author: drama.lin@aver.com
+// Date: 2026-10-14
+
+package database
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+
+	"argus/internal/compress"
+	"argus/internal/migrate"
+)
+
+// Migrations returns the layout migrations of the alert store at alertsPath
+// and the task repository at tasksPath, ordered by version
+func Migrations(alertsPath, tasksPath string) []migrate.Migration {
+	if alertsPath == "" {
+		alertsPath = DefaultConfigDir
+	}
+	if tasksPath == "" {
+		tasksPath = DefaultConfigDir
+	}
+	return []migrate.Migration{
+		{
+			Version:     1,
+			Description: "baseline layout",
+			Apply:       func() error { return nil },
+		},
+		{
+			Version:     2,
+			Description: "move flat execution records into per-task directories",
+			Apply:       func() error { return migrateFlatExecutions(filepath.Join(tasksPath, ExecutionsDir)) },
+		},
+	}
+}
+
+// migrateFlatExecutions moves execution records stored directly in
+// executionsDir, as early versions did, into the directory of their task.
+// Records without a task ID are left in place.
+func migrateFlatExecutions(executionsDir string) error {
+	entries, err := os.ReadDir(executionsDir)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read executions directory: %w", err)
+	}
+	moved := 0
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".json" {
+			continue
+		}
+		src := filepath.Join(executionsDir, entry.Name())
+		data, err := os.ReadFile(src)
+		if err != nil {
+			return fmt.Errorf("failed to read execution %s: %w", src, err)
+		}
+		if data, err = compress.Decode(data); err != nil {
+			return fmt.Errorf("failed to decompress execution %s: %w", src, err)
+		}
+		var exec struct {
+			TaskID string
+		}
+		if err := json.Unmarshal(data, &exec); err != nil || !validDirName(exec.TaskID) {
+			slog.Warn("Leaving execution record without a valid task ID in place", "file", src)
+			continue
+		}
+		taskDir := filepath.Join(executionsDir, exec.TaskID)
+		if err := os.MkdirAll(taskDir, DefaultDirMode); err != nil {
+			return fmt.Errorf("%w: %s: %v", ErrDirectoryCreation, taskDir, err)
+		}
+		if err := os.Rename(src, filepath.Join(taskDir, entry.Name())); err != nil {
+			return fmt.Errorf("failed to move execution %s: %w", src, err)
+		}
+		moved++
+	}
+	if moved > 0 {
+		slog.Info("Moved execution records into task directories", "count", moved)
+	}
+	return nil
+}
+
+// validDirName reports whether name can be used as a single directory name
+func validDirName(name string) bool {
+	return name != "" && name != "." && name != ".." && filepath.Base(name) == name
+}
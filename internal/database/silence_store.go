@@ -0,0 +1,230 @@
+// File: internal/database/silence_store.go
+// Brief: Persistent store for notification silences
+// Detailed: Keeps silences in memory for fast matching at notification time and persists each one as a JSON file next to the alert configurations; silences expired longer than the retention period are removed.
+// Author: drama.lin@aver.com
+// Date: 2026-10-14
+
+package database
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"argus/internal/faults"
+	"argus/internal/models"
+)
+
+// SilencesDir is the subdirectory for storing silences
+const SilencesDir = "silences"
+
+// SilenceRetention is how long expired silences are kept for reference
+const SilenceRetention = 5 * 24 * time.Hour
+
+// ErrSilenceNotFound is returned when a silence is not found
+var ErrSilenceNotFound = errors.New("silence not found")
+
+// SilenceStore manages the storage of silences
+type SilenceStore struct {
+	dir       string
+	mu        sync.RWMutex
+	silences  map[string]*models.Silence
+	fileLocks *LockMap
+	now       func() time.Time
+}
+
+// NewSilenceStore creates a silence store under configDir and loads the
+// silences already stored there
+func NewSilenceStore(configDir string) (*SilenceStore, error) {
+	if configDir == "" {
+		configDir = DefaultConfigDir
+	}
+	dir := filepath.Join(configDir, SilencesDir)
+	if err := os.MkdirAll(dir, DefaultDirMode); err != nil {
+		return nil, fmt.Errorf("%w: %s: %v", ErrDirectoryCreation, dir, err)
+	}
+
+	s := &SilenceStore{
+		dir:       dir,
+		silences:  make(map[string]*models.Silence),
+		fileLocks: NewLockMap(),
+		now:       time.Now,
+	}
+	if err := s.load(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *SilenceStore) silenceFilePath(id string) string {
+	return filepath.Join(s.dir, id+".json")
+}
+
+// load reads every stored silence, skipping files that cannot be parsed
+func (s *SilenceStore) load() error {
+	files, err := os.ReadDir(s.dir)
+	if err != nil {
+		return fmt.Errorf("failed to read silences directory: %w", err)
+	}
+	for _, file := range files {
+		if file.IsDir() || filepath.Ext(file.Name()) != ".json" {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(s.dir, file.Name()))
+		if err != nil {
+			return fmt.Errorf("failed to read silence %s: %w", file.Name(), err)
+		}
+		silence := &models.Silence{}
+		if err := json.Unmarshal(data, silence); err != nil {
+			slog.Warn("Skipping unreadable silence", "file", file.Name(), "error", err)
+			continue
+		}
+		if err := silence.Validate(); err != nil {
+			slog.Warn("Skipping invalid silence", "file", file.Name(), "error", err)
+			continue
+		}
+		s.silences[silence.ID] = silence
+	}
+	return nil
+}
+
+// Save creates a silence, or replaces it when its ID already exists. A new
+// ID is generated when empty.
+func (s *SilenceStore) Save(silence *models.Silence) error {
+	if silence.ID == "" {
+		silence.ID = uuid.New().String()
+	}
+	silence.UpdatedAt = s.now().UTC()
+	if err := silence.Validate(); err != nil {
+		return fmt.Errorf("invalid silence: %w", err)
+	}
+	if err := s.write(silence); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	stored := *silence
+	s.silences[silence.ID] = &stored
+	return nil
+}
+
+func (s *SilenceStore) write(silence *models.Silence) error {
+	data, err := json.MarshalIndent(silence, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal silence: %w", err)
+	}
+	filePath := s.silenceFilePath(silence.ID)
+	unlock := s.fileLocks.Lock(filePath)
+	defer unlock()
+	if err := faults.Inject(faults.StoreWrite); err != nil {
+		return fmt.Errorf("failed to write silence: %w", err)
+	}
+	if err := os.WriteFile(filePath, data, DefaultFileMode); err != nil {
+		return fmt.Errorf("failed to write silence: %w", err)
+	}
+	return nil
+}
+
+// Get returns a copy of the silence with the given ID
+func (s *SilenceStore) Get(id string) (*models.Silence, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	silence, ok := s.silences[id]
+	if !ok {
+		return nil, ErrSilenceNotFound
+	}
+	copied := *silence
+	return &copied, nil
+}
+
+// List returns copies of every silence, newest first. Silences expired for
+// longer than SilenceRetention are removed.
+func (s *SilenceStore) List() []*models.Silence {
+	s.prune()
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	result := make([]*models.Silence, 0, len(s.silences))
+	for _, silence := range s.silences {
+		copied := *silence
+		result = append(result, &copied)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if !result[i].StartsAt.Equal(result[j].StartsAt) {
+			return result[i].StartsAt.After(result[j].StartsAt)
+		}
+		return result[i].ID < result[j].ID
+	})
+	return result
+}
+
+// Expire ends a silence now. Expiring an already expired silence is a no-op.
+func (s *SilenceStore) Expire(id string) error {
+	silence, err := s.Get(id)
+	if err != nil {
+		return err
+	}
+	now := s.now().UTC()
+	switch silence.State(now) {
+	case models.SilenceExpired:
+		return nil
+	case models.SilencePending:
+		// A silence that never started ends at its start
+		silence.StartsAt = now
+	}
+	silence.EndsAt = now
+	silence.UpdatedAt = now
+	if err := s.write(silence); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.silences[id] = silence
+	return nil
+}
+
+// Silenced returns the IDs of the active silences matching labels at the given time
+func (s *SilenceStore) Silenced(labels map[string]string, at time.Time) []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var ids []string
+	for id, silence := range s.silences {
+		if silence.Mutes(labels, at) {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// prune deletes silences that expired more than SilenceRetention ago
+func (s *SilenceStore) prune() {
+	cutoff := s.now().Add(-SilenceRetention)
+
+	s.mu.Lock()
+	var stale []string
+	for id, silence := range s.silences {
+		if silence.EndsAt.Before(cutoff) {
+			stale = append(stale, id)
+			delete(s.silences, id)
+		}
+	}
+	s.mu.Unlock()
+
+	for _, id := range stale {
+		if err := os.Remove(s.silenceFilePath(id)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			slog.Warn("Failed to remove expired silence", "id", id, "error", err)
+		}
+	}
+}
@@ -0,0 +1,367 @@
+// File: internal/database/sqlite.go
+// Brief: SQLite database of storage.driver sqlite
+// Detailed: Opens the SQLite database holding the alerts and tasks when storage.driver is sqlite, in WAL mode so readers never wait for the single writer and with incremental auto-vacuum so purges shrink the file, and brings its schema to the version this binary expects. The version is kept in the database's user_version; migrations are applied in order on startup, each in a transaction. Creating the tables is followed by importing the JSON file stores, so a deployment switching drivers keeps its alerts, backups, history, tasks and executions. Append new migrations at the end; never renumber or change released ones.
+// Author: drama.lin@aver.com
+// Date: 2026-10-14
+
+package database
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	_ "modernc.org/sqlite" // Registers the "sqlite" driver
+)
+
+// sqliteBusyTimeout is how long a write waits for another to finish
+const sqliteBusyTimeout = 5 * time.Second
+
+// sqliteMigration is a versioned change to the schema of the SQLite database
+type sqliteMigration struct {
+	Version     int
+	Description string
+	Apply       func(tx *sql.Tx) error
+}
+
+// sqliteMigrations returns the schema migrations of the SQLite database,
+// ordered by version. The JSON file stores imported are the alert store at
+// alertsPath and the task repository at tasksPath.
+func sqliteMigrations(alertsPath, tasksPath string) []sqliteMigration {
+	return []sqliteMigration{
+		{
+			Version:     1,
+			Description: "create the alert and task tables",
+			Apply: execStatements(
+				`CREATE TABLE alerts (
+					id     TEXT PRIMARY KEY,
+					config BLOB NOT NULL
+				)`,
+				`CREATE TABLE alert_backups (
+					alert_id TEXT NOT NULL,
+					taken_at TEXT NOT NULL,
+					config   BLOB NOT NULL,
+					PRIMARY KEY (alert_id, taken_at)
+				)`,
+				// Timestamps are Unix microseconds; entries may be compressed
+				`CREATE TABLE alert_history (
+					seq       INTEGER PRIMARY KEY AUTOINCREMENT,
+					alert_id  TEXT NOT NULL,
+					timestamp INTEGER NOT NULL,
+					entry     BLOB NOT NULL
+				)`,
+				`CREATE INDEX alert_history_alert ON alert_history (alert_id, seq)`,
+				`CREATE INDEX alert_history_timestamp ON alert_history (timestamp)`,
+				`CREATE TABLE tasks (
+					id         TEXT PRIMARY KEY,
+					type       TEXT NOT NULL,
+					created_at INTEGER NOT NULL,
+					config     BLOB NOT NULL
+				)`,
+				`CREATE INDEX tasks_type ON tasks (type, created_at)`,
+				`CREATE TABLE task_executions (
+					id         TEXT PRIMARY KEY,
+					task_id    TEXT NOT NULL,
+					start_time INTEGER NOT NULL,
+					record     BLOB NOT NULL
+				)`,
+				`CREATE INDEX task_executions_task ON task_executions (task_id, start_time)`,
+				`CREATE INDEX task_executions_start_time ON task_executions (start_time)`,
+			),
+		},
+		{
+			Version:     2,
+			Description: "import the JSON file stores",
+			Apply: func(tx *sql.Tx) error {
+				return importFileStores(tx, alertsPath, tasksPath)
+			},
+		},
+	}
+}
+
+// OpenSQLite opens the SQLite database at path, creating it if needed, and
+// applies the schema migrations it lacks. A new database imports the JSON
+// file stores of the alert store at alertsPath and the task repository at
+// tasksPath.
+func OpenSQLite(path, alertsPath, tasksPath string) (*sql.DB, error) {
+	if alertsPath == "" {
+		alertsPath = DefaultConfigDir
+	}
+	if tasksPath == "" {
+		tasksPath = DefaultConfigDir
+	}
+	if dir := filepath.Dir(path); dir != "" {
+		if err := os.MkdirAll(dir, DefaultDirMode); err != nil {
+			return nil, fmt.Errorf("%w: %s: %v", ErrDirectoryCreation, dir, err)
+		}
+	}
+
+	// Transactions take the write lock when they begin, so a transaction that
+	// reads before writing waits for other writers instead of failing.
+	// Auto-vacuum applies to a new database right away, to an existing one
+	// once rebuilt.
+	dsn := fmt.Sprintf("file:%s?_pragma=auto_vacuum(INCREMENTAL)&_pragma=busy_timeout(%d)&_pragma=journal_mode(WAL)&_pragma=synchronous(NORMAL)&_txlock=immediate",
+		path, sqliteBusyTimeout.Milliseconds())
+	db, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open SQLite database %s: %w", path, err)
+	}
+	if err := enableIncrementalVacuum(db); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to enable auto-vacuum of SQLite database %s: %w", path, err)
+	}
+	if err := migrateSQLite(db, sqliteMigrations(alertsPath, tasksPath)); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to migrate SQLite database %s: %w", path, err)
+	}
+	return db, nil
+}
+
+// sqliteAutoVacuumIncremental is the PRAGMA auto_vacuum value of incremental mode
+const sqliteAutoVacuumIncremental = 2
+
+// enableIncrementalVacuum switches a database created without auto-vacuum to
+// incremental auto-vacuum, which takes rebuilding it once
+func enableIncrementalVacuum(db *sql.DB) error {
+	var mode int
+	if err := db.QueryRow("PRAGMA auto_vacuum").Scan(&mode); err != nil {
+		return fmt.Errorf("failed to read auto-vacuum mode: %w", err)
+	}
+	if mode == sqliteAutoVacuumIncremental {
+		return nil
+	}
+	// Every connection sets the mode on opening, so VACUUM applies it
+	if _, err := db.Exec("VACUUM"); err != nil {
+		return fmt.Errorf("failed to vacuum: %w", err)
+	}
+	slog.Info("SQLite auto-vacuum enabled", "previous_mode", mode)
+	return nil
+}
+
+// reclaimSQLite returns the pages freed by deletes to the file system, so purges
+// shrink the database file the storage budget measures
+func reclaimSQLite(ctx context.Context, db *sql.DB) error {
+	// Each step of the statement frees one page
+	rows, err := db.QueryContext(ctx, "PRAGMA incremental_vacuum")
+	if err != nil {
+		return fmt.Errorf("failed to vacuum: %w", err)
+	}
+	for rows.Next() {
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to vacuum: %w", err)
+	}
+	// The database file is truncated when the WAL is checkpointed into it
+	if _, err := db.ExecContext(ctx, "PRAGMA wal_checkpoint(TRUNCATE)"); err != nil {
+		return fmt.Errorf("failed to checkpoint: %w", err)
+	}
+	return nil
+}
+
+// migrateSQLite applies the migrations newer than the database's version
+func migrateSQLite(db *sql.DB, migrations []sqliteMigration) error {
+	var version int
+	if err := db.QueryRow("PRAGMA user_version").Scan(&version); err != nil {
+		return fmt.Errorf("failed to read schema version: %w", err)
+	}
+	if latest := migrations[len(migrations)-1].Version; version > latest {
+		return fmt.Errorf("schema version %d is newer than this binary's %d", version, latest)
+	}
+	for _, m := range migrations {
+		if m.Version <= version {
+			continue
+		}
+		err := withTx(db, func(tx *sql.Tx) error {
+			if err := m.Apply(tx); err != nil {
+				return err
+			}
+			// PRAGMA takes no parameters; the version is an int
+			_, err := tx.Exec(fmt.Sprintf("PRAGMA user_version = %d", m.Version))
+			return err
+		})
+		if err != nil {
+			return fmt.Errorf("migration %d (%s): %w", m.Version, m.Description, err)
+		}
+		slog.Info("SQLite schema migrated", "version", m.Version, "description", m.Description)
+	}
+	return nil
+}
+
+// execStatements returns a migration step running statements in order
+func execStatements(statements ...string) func(tx *sql.Tx) error {
+	return func(tx *sql.Tx) error {
+		for _, statement := range statements {
+			if _, err := tx.Exec(statement); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+// withTx runs fn in a transaction, committed when fn succeeds
+func withTx(db *sql.DB, fn func(tx *sql.Tx) error) error {
+	return withTxContext(context.Background(), db, fn)
+}
+
+func withTxContext(ctx context.Context, db *sql.DB, fn func(tx *sql.Tx) error) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	if err := fn(tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+// importFileStores copies what the JSON file stores hold into a new
+// database. Stores that were never created are skipped.
+func importFileStores(tx *sql.Tx, alertsPath, tasksPath string) error {
+	var alerts, tasks, executions int
+	alertsDir := filepath.Join(alertsPath, AlertsDir)
+	if _, err := os.Stat(alertsDir); err == nil {
+		files := &fileAlerts{
+			alertsDir:  alertsDir,
+			backupDir:  filepath.Join(alertsPath, BackupDir),
+			historyDir: filepath.Join(alertsPath, HistoryDir),
+			fileLocks:  NewLockMap(),
+		}
+		var err error
+		if alerts, err = importFileAlerts(tx, files); err != nil {
+			return err
+		}
+	}
+	tasksDir := filepath.Join(tasksPath, TasksDir)
+	if _, err := os.Stat(tasksDir); err == nil {
+		repo := &FileTaskRepository{
+			baseDir:       tasksPath,
+			tasksDir:      tasksDir,
+			executionsDir: filepath.Join(tasksPath, ExecutionsDir),
+			fileLocks:     NewLockMap(),
+		}
+		var err error
+		if tasks, executions, err = importFileTasks(tx, repo); err != nil {
+			return err
+		}
+	}
+	if alerts+tasks > 0 {
+		slog.Info("Imported the JSON file stores into SQLite", "alerts", alerts, "tasks", tasks, "executions", executions)
+	}
+	return nil
+}
+
+// importFileAlerts copies the alerts, their backups and their history, and
+// returns the number of alerts
+func importFileAlerts(tx *sql.Tx, files *fileAlerts) (int, error) {
+	stored, err := files.ReadAll()
+	if err != nil {
+		return 0, err
+	}
+	for id, data := range stored {
+		if _, err := tx.Exec(`INSERT INTO alerts (id, config) VALUES (?, ?)`, id, data); err != nil {
+			return 0, fmt.Errorf("failed to import alert %s: %w", id, err)
+		}
+	}
+
+	backups, err := os.ReadDir(files.backupDir)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return 0, fmt.Errorf("failed to read backups directory: %w", err)
+	}
+	for _, file := range backups {
+		// <alert ID>-<timestamp>.json
+		name := strings.TrimSuffix(file.Name(), ".json")
+		cut := len(name) - len(backupTimestampFormat)
+		if file.IsDir() || name == file.Name() || cut < 2 || name[cut-1] != '-' {
+			continue
+		}
+		id, takenAt := name[:cut-1], name[cut:]
+		if _, err := time.Parse(backupTimestampFormat, takenAt); err != nil {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(files.backupDir, file.Name()))
+		if err != nil {
+			return 0, fmt.Errorf("failed to read backup file: %w", err)
+		}
+		if _, err := tx.Exec(`INSERT OR REPLACE INTO alert_backups (alert_id, taken_at, config) VALUES (?, ?, ?)`,
+			id, takenAt, data); err != nil {
+			return 0, fmt.Errorf("failed to import backup %s: %w", name, err)
+		}
+	}
+
+	paths, err := files.historyFiles()
+	if err != nil {
+		return 0, err
+	}
+	for _, filePath := range paths {
+		entries, _, err := readHistoryFile(filePath)
+		if err != nil {
+			return 0, err
+		}
+		for _, entry := range entries {
+			data, err := json.Marshal(entry)
+			if err != nil {
+				return 0, fmt.Errorf("failed to marshal alert history entry: %w", err)
+			}
+			if _, err := tx.Exec(`INSERT INTO alert_history (alert_id, timestamp, entry) VALUES (?, ?, ?)`,
+				entry.AlertID, entry.Timestamp.UnixMicro(), data); err != nil {
+				return 0, fmt.Errorf("failed to import alert history: %w", err)
+			}
+		}
+	}
+	return len(stored), nil
+}
+
+// importFileTasks copies the tasks and their executions, and returns their
+// numbers. Unreadable files are skipped as the file repository skips them.
+func importFileTasks(tx *sql.Tx, repo *FileTaskRepository) (int, int, error) {
+	tasks, err := repo.ListTasks(context.Background())
+	if err != nil {
+		return 0, 0, err
+	}
+	for _, task := range tasks {
+		data, err := json.Marshal(task)
+		if err != nil {
+			return 0, 0, fmt.Errorf("failed to encode task: %w", err)
+		}
+		if _, err := tx.Exec(`INSERT INTO tasks (id, type, created_at, config) VALUES (?, ?, ?, ?)`,
+			task.ID, string(task.Type), task.CreatedAt.UnixMicro(), data); err != nil {
+			return 0, 0, fmt.Errorf("failed to import task %s: %w", task.ID, err)
+		}
+	}
+
+	paths, err := repo.executionFiles()
+	if err != nil {
+		return 0, 0, err
+	}
+	executions := 0
+	for _, filePath := range paths {
+		exec, err := repo.readExecutionFromFile(filePath)
+		if err != nil {
+			continue
+		}
+		if exec.ExecutionID == "" {
+			exec.ExecutionID = strings.TrimSuffix(filepath.Base(filePath), ".json")
+		}
+		data, err := json.Marshal(exec)
+		if err != nil {
+			return 0, 0, fmt.Errorf("failed to encode execution: %w", err)
+		}
+		if _, err := tx.Exec(`INSERT OR REPLACE INTO task_executions (id, task_id, start_time, record) VALUES (?, ?, ?, ?)`,
+			exec.ExecutionID, exec.TaskID, exec.StartTime.UnixMicro(), data); err != nil {
+			return 0, 0, fmt.Errorf("failed to import execution %s: %w", exec.ExecutionID, err)
+		}
+		executions++
+	}
+	return len(tasks), executions, nil
+}
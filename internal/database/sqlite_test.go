@@ -0,0 +1,267 @@
+package database
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"argus/internal/clock"
+	"argus/internal/compress"
+	"argus/internal/models"
+	"argus/internal/retention"
+)
+
+func testAlert(id string) *models.AlertConfig {
+	return &models.AlertConfig{
+		ID:       id,
+		Name:     "High CPU " + id,
+		Severity: models.SeverityCritical,
+		Enabled:  true,
+		Threshold: models.ThresholdConfig{
+			MetricType: models.MetricCPU,
+			MetricName: "usage_percent",
+			Operator:   models.OperatorGreaterThan,
+			Value:      90,
+		},
+	}
+}
+
+func TestSQLiteAlertStore(t *testing.T) {
+	dir := t.TempDir()
+	db, err := OpenSQLite(filepath.Join(dir, "argus.db"), filepath.Join(dir, "alerts"), filepath.Join(dir, "tasks"))
+	require.NoError(t, err)
+	defer db.Close()
+	store := NewSQLiteAlertStore(db)
+
+	require.NoError(t, store.CreateAlert(testAlert("a1")))
+	assert.ErrorContains(t, store.CreateAlert(testAlert("a1")), "already exists")
+
+	alert, err := store.GetAlert("a1")
+	require.NoError(t, err)
+	alert.Threshold.Value = 95
+	require.NoError(t, store.UpdateAlert(alert))
+	assert.ErrorIs(t, store.UpdateAlert(testAlert("missing")), ErrAlertNotFound)
+
+	// A fresh store reads what the first wrote, bypassing its cache
+	alert, err = NewSQLiteAlertStore(db).GetAlert("a1")
+	require.NoError(t, err)
+	assert.Equal(t, 95.0, alert.Threshold.Value)
+
+	backups, err := store.ListBackups("a1")
+	require.NoError(t, err)
+	require.Len(t, backups, 1)
+	require.NoError(t, store.RestoreAlert("a1", backups[0]))
+	alert, err = NewSQLiteAlertStore(db).GetAlert("a1")
+	require.NoError(t, err)
+	assert.Equal(t, 90.0, alert.Threshold.Value, "restored from the backup taken by the update")
+
+	require.NoError(t, store.SaveAlerts([]*models.AlertConfig{testAlert("a2")}))
+	alerts, err := NewSQLiteAlertStore(db).ListAlerts()
+	require.NoError(t, err)
+	assert.Len(t, alerts, 1, "bulk writes are stored by the flusher")
+	require.NoError(t, store.Flush())
+	alerts, err = NewSQLiteAlertStore(db).ListAlerts()
+	require.NoError(t, err)
+	require.Len(t, alerts, 2)
+	assert.Equal(t, "a1", alerts[0].ID)
+
+	require.NoError(t, store.DeleteAlert("a2"))
+	assert.ErrorIs(t, store.DeleteAlert("a2"), ErrAlertNotFound)
+	_, err = NewSQLiteAlertStore(db).GetAlert("a2")
+	assert.ErrorIs(t, err, ErrAlertNotFound)
+}
+
+func TestSQLiteAlertStore_History(t *testing.T) {
+	dir := t.TempDir()
+	db, err := OpenSQLite(filepath.Join(dir, "argus.db"), dir, dir)
+	require.NoError(t, err)
+	defer db.Close()
+	store := NewSQLiteAlertStore(db)
+
+	start := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	for i, state := range []models.AlertState{models.StateActive, models.StateResolved, models.StateActive} {
+		if i == 2 {
+			store.SetHistoryCompression(compress.Gzip)
+		}
+		require.NoError(t, store.AppendHistory(models.AlertHistoryEntry{
+			AlertID: "a1", NewState: state, Timestamp: start.Add(time.Duration(i) * time.Hour),
+		}))
+	}
+
+	entries, err := store.GetHistory("a1", 2)
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	assert.Equal(t, start.Add(2*time.Hour), entries[0].Timestamp, "newest first, compressed or not")
+	assert.Equal(t, models.StateResolved, entries[1].NewState)
+
+	usage, err := store.HistoryUsage()
+	require.NoError(t, err)
+	assert.Equal(t, 3, usage.Items)
+	require.NotNil(t, usage.Oldest)
+	assert.Equal(t, start, *usage.Oldest)
+
+	purged, err := store.PurgeHistory(start.Add(90 * time.Minute))
+	require.NoError(t, err)
+	assert.Equal(t, 2, purged)
+	entries, err = store.GetHistory("a1", 0)
+	require.NoError(t, err)
+	assert.Len(t, entries, 1)
+
+	entries, err = store.GetHistory("missing", 0)
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+}
+
+func TestSQLiteTaskRepository(t *testing.T) {
+	dir := t.TempDir()
+	db, err := OpenSQLite(filepath.Join(dir, "argus.db"), dir, dir)
+	require.NoError(t, err)
+	defer db.Close()
+	repo := NewSQLiteTaskRepository(db)
+	ctx := context.Background()
+
+	older := createTestTask("t1", models.TaskLogRotation)
+	older.CreatedAt = time.Now().Add(-time.Hour)
+	require.NoError(t, repo.CreateTask(ctx, older))
+	require.NoError(t, repo.CreateTask(ctx, createTestTask("t2", models.TaskHealthCheck)))
+	assert.ErrorContains(t, repo.CreateTask(ctx, createTestTask("t2", models.TaskHealthCheck)), "already exists")
+
+	tasks, err := repo.ListTasks(ctx)
+	require.NoError(t, err)
+	require.Len(t, tasks, 2)
+	assert.Equal(t, "t2", tasks[0].ID, "newest first")
+	tasks, err = repo.GetTasksByType(ctx, models.TaskLogRotation)
+	require.NoError(t, err)
+	require.Len(t, tasks, 1)
+	assert.Equal(t, time.UTC, tasks[0].CreatedAt.Location())
+
+	tasks[0].Name = "Renamed"
+	require.NoError(t, repo.UpdateTask(ctx, tasks[0]))
+	task, err := repo.GetTask(ctx, "t1")
+	require.NoError(t, err)
+	assert.Equal(t, "Renamed", task.Name)
+	assert.ErrorIs(t, repo.UpdateTask(ctx, createTestTask("missing", models.TaskHealthCheck)), ErrTaskNotFound)
+
+	repo.SetCompression(compress.Gzip)
+	start := time.Now().Add(-time.Hour)
+	for i := 0; i < 3; i++ {
+		require.NoError(t, repo.RecordExecution(ctx, &models.TaskExecution{
+			ExecutionID: models.GenerateID(), TaskID: "t1", Status: models.StatusCompleted,
+			StartTime: start.Add(time.Duration(i) * time.Minute),
+		}))
+	}
+	assert.Error(t, repo.RecordExecution(ctx, &models.TaskExecution{ExecutionID: "e", TaskID: "missing"}))
+
+	executions, err := repo.GetTaskExecutions(ctx, "t1", 2)
+	require.NoError(t, err)
+	require.Len(t, executions, 2)
+	assert.True(t, executions[0].StartTime.After(executions[1].StartTime), "newest first")
+	execution, err := repo.GetExecution(ctx, executions[1].ExecutionID)
+	require.NoError(t, err)
+	assert.Equal(t, "t1", execution.TaskID)
+	_, err = repo.GetExecution(ctx, "missing")
+	assert.ErrorIs(t, err, ErrExecutionNotFound)
+
+	usage, err := repo.ExecutionUsage(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 3, usage.Items)
+	purged, err := repo.PurgeExecutions(ctx, start.Add(90*time.Second))
+	require.NoError(t, err)
+	assert.Equal(t, 2, purged)
+
+	require.NoError(t, repo.DeleteTask(ctx, "t1"))
+	assert.ErrorIs(t, repo.DeleteTask(ctx, "t1"), ErrTaskNotFound)
+}
+
+func TestSQLiteStorageBudget(t *testing.T) {
+	dir := t.TempDir()
+	db, err := OpenSQLite(filepath.Join(dir, "argus.db"), dir, dir)
+	require.NoError(t, err)
+	defer db.Close()
+	repo := NewSQLiteTaskRepository(db)
+	ctx := context.Background()
+	require.NoError(t, repo.CreateTask(ctx, createTestTask("t1", models.TaskHealthCheck)))
+
+	// One 4 KiB execution per minute for the last 400 minutes
+	now := time.Now().UTC()
+	for i := 0; i < 400; i++ {
+		require.NoError(t, repo.RecordExecution(ctx, &models.TaskExecution{
+			ExecutionID: models.GenerateID(), TaskID: "t1", Status: models.StatusCompleted,
+			StartTime: now.Add(-time.Duration(400-i) * time.Minute),
+			Output:    strings.Repeat("x", 4096),
+		}))
+	}
+	// Start from the WAL folded into the database file, as every purge leaves it
+	_, err = db.Exec("PRAGMA wal_checkpoint(TRUNCATE)")
+	require.NoError(t, err)
+
+	clk := clock.NewFake(now)
+	engine := retention.NewEngine(retention.Config{Clock: clk})
+	engine.Register(retention.KindExecutions, retention.Target{
+		Usage: func() (retention.Usage, error) { return repo.ExecutionUsage(ctx) },
+		Purge: func(before time.Time) (int, error) { return repo.PurgeExecutions(ctx, before) },
+	})
+
+	// Purging the oldest executions shrinks the file below the budget without
+	// emptying the table
+	status := retention.NewBudget(retention.BudgetConfig{MaxBytes: 1 << 20, Dirs: []string{dir}, Clock: clk}, engine).Check()
+	assert.Empty(t, status.Error)
+	assert.Greater(t, status.Pruned[retention.KindExecutions], 0)
+	assert.LessOrEqual(t, status.UsedBytes, int64(1<<20))
+	usage, err := repo.ExecutionUsage(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 400-status.Pruned[retention.KindExecutions], usage.Items)
+	assert.Greater(t, usage.Items, 100)
+	executions, err := repo.GetTaskExecutions(ctx, "t1", 1)
+	require.NoError(t, err)
+	require.Len(t, executions, 1)
+	assert.Equal(t, now.Add(-time.Minute).UnixMicro(), executions[0].StartTime.UnixMicro(), "the newest executions are kept")
+}
+
+func TestOpenSQLite_ImportsFileStores(t *testing.T) {
+	dir := t.TempDir()
+	alertsPath, tasksPath := filepath.Join(dir, "alerts"), filepath.Join(dir, "tasks")
+	ctx := context.Background()
+
+	files, err := NewAlertStore(alertsPath)
+	require.NoError(t, err)
+	require.NoError(t, files.CreateAlert(testAlert("a1")))
+	require.NoError(t, files.UpdateAlert(testAlert("a1")))
+	require.NoError(t, files.AppendHistory(models.AlertHistoryEntry{AlertID: "a1", NewState: models.StateActive, Timestamp: time.Now()}))
+	taskFiles, err := NewFileTaskRepository(tasksPath)
+	require.NoError(t, err)
+	require.NoError(t, taskFiles.CreateTask(ctx, createTestTask("t1", models.TaskHealthCheck)))
+	require.NoError(t, taskFiles.RecordExecution(ctx, &models.TaskExecution{ExecutionID: "e1", TaskID: "t1", StartTime: time.Now()}))
+
+	path := filepath.Join(dir, "argus.db")
+	db, err := OpenSQLite(path, alertsPath, tasksPath)
+	require.NoError(t, err)
+	store := NewSQLiteAlertStore(db)
+	_, err = store.GetAlert("a1")
+	require.NoError(t, err)
+	backups, err := store.ListBackups("a1")
+	require.NoError(t, err)
+	assert.Len(t, backups, 1)
+	history, err := store.GetHistory("a1", 0)
+	require.NoError(t, err)
+	assert.Len(t, history, 1)
+	repo := NewSQLiteTaskRepository(db)
+	_, err = repo.GetTask(ctx, "t1")
+	require.NoError(t, err)
+	_, err = repo.GetExecution(ctx, "e1")
+	require.NoError(t, err)
+
+	// The import runs once: alerts deleted later are not brought back
+	require.NoError(t, store.DeleteAlert("a1"))
+	require.NoError(t, db.Close())
+	db, err = OpenSQLite(path, alertsPath, tasksPath)
+	require.NoError(t, err)
+	defer db.Close()
+	_, err = NewSQLiteAlertStore(db).GetAlert("a1")
+	assert.ErrorIs(t, err, ErrAlertNotFound)
+}
@@ -12,7 +12,10 @@ import (
 	"sync"
 	"time"
 
+	"argus/internal/compress"
+	"argus/internal/faults"
 	"argus/internal/models"
+	"argus/internal/retention"
 )
 
 // FileTaskRepository implements models.TaskRepository using the filesystem
@@ -31,13 +34,28 @@ var (
 	ErrInvalidTaskID     = errors.New("invalid task ID")
 )
 
+// TaskRepository is the task repository of a storage driver, such as
+// FileTaskRepository or SQLiteTaskRepository, whose execution records the
+// retention policy and storage budget can measure and purge
+type TaskRepository interface {
+	models.TaskRepository
+	SetCompression(alg compress.Algorithm)
+	ExecutionUsage(ctx context.Context) (retention.Usage, error)
+	PurgeExecutions(ctx context.Context, before time.Time) (int, error)
+}
+
+var (
+	_ TaskRepository = (*FileTaskRepository)(nil)
+	_ TaskRepository = (*SQLiteTaskRepository)(nil)
+)
+
 type FileTaskRepository struct {
 	baseDir       string
 	tasksDir      string
 	executionsDir string
 	mutex         sync.RWMutex
-	fileLocks     map[string]*sync.Mutex
-	lockMu        sync.Mutex
+	fileLocks     *LockMap
+	compression   compress.Algorithm
 }
 
 func NewFileTaskRepository(baseDir string) (*FileTaskRepository, error) {
@@ -56,19 +74,14 @@ func NewFileTaskRepository(baseDir string) (*FileTaskRepository, error) {
 		baseDir:       baseDir,
 		tasksDir:      tasksDir,
 		executionsDir: executionsDir,
-		fileLocks:     make(map[string]*sync.Mutex),
+		fileLocks:     NewLockMap(),
 	}, nil
 }
 
-func (r *FileTaskRepository) getFileLock(path string) *sync.Mutex {
-	r.lockMu.Lock()
-	defer r.lockMu.Unlock()
-	if lock, exists := r.fileLocks[path]; exists {
-		return lock
-	}
-	lock := &sync.Mutex{}
-	r.fileLocks[path] = lock
-	return lock
+// SetCompression sets the algorithm used for newly written execution records.
+// Existing records are read in whatever format they were stored.
+func (r *FileTaskRepository) SetCompression(alg compress.Algorithm) {
+	r.compression = alg
 }
 
 func (r *FileTaskRepository) taskFilePath(id string) string {
@@ -97,9 +110,9 @@ func (r *FileTaskRepository) CreateTask(ctx context.Context, task *models.TaskCo
 		task.ID = models.GenerateID()
 	}
 	if task.CreatedAt.IsZero() {
-		task.CreatedAt = time.Now()
+		task.CreatedAt = time.Now().UTC()
 	}
-	task.UpdatedAt = time.Now()
+	task.UpdatedAt = time.Now().UTC()
 	r.mutex.Lock()
 	defer r.mutex.Unlock()
 	filePath := r.taskFilePath(task.ID)
@@ -147,7 +160,7 @@ func (r *FileTaskRepository) UpdateTask(ctx context.Context, task *models.TaskCo
 		}
 		return fmt.Errorf("failed to check if task exists: %w", err)
 	}
-	task.UpdatedAt = time.Now()
+	task.UpdatedAt = time.Now().UTC()
 	return r.writeTaskToFile(task, filePath)
 }
 
@@ -164,9 +177,8 @@ func (r *FileTaskRepository) DeleteTask(ctx context.Context, id string) error {
 		}
 		return fmt.Errorf("failed to check if task exists: %w", err)
 	}
-	lock := r.getFileLock(filePath)
-	lock.Lock()
-	defer lock.Unlock()
+	unlock := r.fileLocks.Lock(filePath)
+	defer unlock()
 	if err := os.Remove(filePath); err != nil {
 		return fmt.Errorf("failed to delete task: %w", err)
 	}
@@ -233,6 +245,8 @@ func (r *FileTaskRepository) RecordExecution(ctx context.Context, execution *mod
 		return fmt.Errorf("%w: %s: %v", ErrDirectoryCreation, taskExecDir, err)
 	}
 	filePath := filepath.Join(taskExecDir, fmt.Sprintf("%s.json", execution.ExecutionID))
+	execution.StartTime = execution.StartTime.UTC()
+	execution.EndTime = execution.EndTime.UTC()
 	return r.writeExecutionToFile(execution, filePath)
 }
 
@@ -309,15 +323,106 @@ func (r *FileTaskRepository) GetExecutions(ctx context.Context, taskID string) (
 	return r.GetTaskExecutions(ctx, taskID, 0)
 }
 
+// executionFiles returns the paths of every stored execution record
+func (r *FileTaskRepository) executionFiles() ([]string, error) {
+	taskDirs, err := os.ReadDir(r.executionsDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read executions directory: %w", err)
+	}
+	var paths []string
+	for _, taskDir := range taskDirs {
+		if !taskDir.IsDir() {
+			continue
+		}
+		files, err := os.ReadDir(filepath.Join(r.executionsDir, taskDir.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("failed to list execution files: %w", err)
+		}
+		for _, file := range files {
+			if !file.IsDir() && filepath.Ext(file.Name()) == ".json" {
+				paths = append(paths, filepath.Join(r.executionsDir, taskDir.Name(), file.Name()))
+			}
+		}
+	}
+	return paths, nil
+}
+
+// ExecutionUsage reports the number of stored execution records, their size
+// on disk and the oldest one's start time. Unreadable records are counted
+// without a time.
+func (r *FileTaskRepository) ExecutionUsage(ctx context.Context) (retention.Usage, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	var usage retention.Usage
+	paths, err := r.executionFiles()
+	if err != nil {
+		return usage, err
+	}
+	for _, filePath := range paths {
+		info, err := os.Stat(filePath)
+		if err != nil {
+			continue
+		}
+		usage.Bytes += info.Size()
+		exec, err := r.readExecutionFromFile(filePath)
+		if err != nil {
+			usage.Items++
+			continue
+		}
+		usage.Observe(exec.StartTime)
+	}
+	return usage, nil
+}
+
+// PurgeExecutions deletes execution records that started before before and
+// returns the number deleted. Unreadable records are left in place.
+func (r *FileTaskRepository) PurgeExecutions(ctx context.Context, before time.Time) (int, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	paths, err := r.executionFiles()
+	if err != nil {
+		return 0, err
+	}
+	purged := 0
+	for _, filePath := range paths {
+		if err := ctx.Err(); err != nil {
+			return purged, err
+		}
+		exec, err := r.readExecutionFromFile(filePath)
+		if err != nil || !exec.StartTime.Before(before) {
+			continue
+		}
+		unlock := r.fileLocks.Lock(filePath)
+		err = os.Remove(filePath)
+		unlock()
+		if err != nil && !os.IsNotExist(err) {
+			return purged, fmt.Errorf("failed to delete execution: %w", err)
+		}
+		purged++
+	}
+	return purged, nil
+}
+
 func (r *FileTaskRepository) writeTaskToFile(task *models.TaskConfig, filePath string) error {
-	lock := r.getFileLock(filePath)
-	lock.Lock()
-	defer lock.Unlock()
+	if err := faults.Inject(faults.StoreWrite); err != nil {
+		return fmt.Errorf("failed to write task: %w", err)
+	}
+	unlock := r.fileLocks.Lock(filePath)
+	defer unlock()
 	f, err := os.OpenFile(filePath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, DefaultFileMode)
 	if err != nil {
 		return fmt.Errorf("failed to open file for writing: %w", err)
 	}
 	defer f.Close()
+	// Timestamps are always persisted in UTC; handlers render them per task timezone
+	task.CreatedAt = task.CreatedAt.UTC()
+	task.UpdatedAt = task.UpdatedAt.UTC()
+	task.Schedule.NextRunTime = task.Schedule.NextRunTime.UTC()
 	enc := json.NewEncoder(f)
 	enc.SetIndent("", "  ")
 	if err := enc.Encode(task); err != nil {
@@ -332,6 +437,9 @@ func (r *FileTaskRepository) writeTaskToFile(task *models.TaskConfig, filePath s
 }
 
 func (r *FileTaskRepository) readTaskFromFile(filePath string) (*models.TaskConfig, error) {
+	if err := faults.Inject(faults.StoreRead); err != nil {
+		return nil, fmt.Errorf("failed to read task: %w", err)
+	}
 	f, err := os.Open(filePath)
 	if err != nil {
 		return nil, err
@@ -346,31 +454,40 @@ func (r *FileTaskRepository) readTaskFromFile(filePath string) (*models.TaskConf
 }
 
 func (r *FileTaskRepository) writeExecutionToFile(execution *models.TaskExecution, filePath string) error {
-	lock := r.getFileLock(filePath)
-	lock.Lock()
-	defer lock.Unlock()
-	f, err := os.OpenFile(filePath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, DefaultFileMode)
+	data, err := json.MarshalIndent(execution, "", "  ")
 	if err != nil {
-		return fmt.Errorf("failed to open file for writing: %w", err)
-	}
-	defer f.Close()
-	enc := json.NewEncoder(f)
-	enc.SetIndent("", "  ")
-	if err := enc.Encode(execution); err != nil {
 		return fmt.Errorf("failed to encode execution: %w", err)
 	}
+	data, err = compress.Encode(r.compression, append(data, '\n'))
+	if err != nil {
+		return fmt.Errorf("failed to compress execution: %w", err)
+	}
+	if err := faults.Inject(faults.StoreWrite); err != nil {
+		return fmt.Errorf("failed to write execution: %w", err)
+	}
+	unlock := r.fileLocks.Lock(filePath)
+	defer unlock()
+	if err := os.WriteFile(filePath, data, DefaultFileMode); err != nil {
+		return fmt.Errorf("failed to write execution: %w", err)
+	}
 	return nil
 }
 
 func (r *FileTaskRepository) readExecutionFromFile(filePath string) (*models.TaskExecution, error) {
-	f, err := os.Open(filePath)
+	if err := faults.Inject(faults.StoreRead); err != nil {
+		return nil, fmt.Errorf("failed to read execution: %w", err)
+	}
+	data, err := os.ReadFile(filePath)
 	if err != nil {
 		return nil, err
 	}
-	defer f.Close()
+	// Records may be plain JSON or compressed, depending on when they were written
+	data, err = compress.Decode(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decompress execution: %w", err)
+	}
 	var exec models.TaskExecution
-	dec := json.NewDecoder(f)
-	if err := dec.Decode(&exec); err != nil {
+	if err := json.Unmarshal(data, &exec); err != nil {
 		return nil, fmt.Errorf("failed to decode execution: %w", err)
 	}
 	return &exec, nil
@@ -50,12 +50,12 @@ func createTestTask(id string, taskType models.TaskType) *models.TaskConfig {
 // createTestExecution creates a test task execution record
 func createTestExecution(taskID string, status models.TaskStatus) *models.TaskExecution {
 	return &models.TaskExecution{
-		ID:        models.GenerateID(),
-		TaskID:    taskID,
-		Status:    status,
-		StartTime: time.Now().Add(-1 * time.Minute),
-		EndTime:   time.Now(),
-		Output:    "Test execution output",
+		ExecutionID: models.GenerateID(),
+		TaskID:      taskID,
+		Status:      status,
+		StartTime:   time.Now().Add(-1 * time.Minute),
+		EndTime:     time.Now(),
+		Output:      "Test execution output",
 	}
 }
 
@@ -80,8 +80,10 @@ func TestNewFileTaskRepository(t *testing.T) {
 	_, err = os.Stat(executionsDir)
 	assert.NoError(t, err)
 
-	// Test with invalid path
-	_, err = NewFileTaskRepository("/nonexistent/path")
+	// Test with invalid path: below a regular file, so it fails even as root
+	blocker := filepath.Join(tempDir, "not-a-dir")
+	require.NoError(t, os.WriteFile(blocker, nil, 0644))
+	_, err = NewFileTaskRepository(filepath.Join(blocker, "path"))
 	assert.Error(t, err)
 }
 
@@ -274,15 +276,15 @@ func TestFileTaskRepository_RecordExecution(t *testing.T) {
 	require.NoError(t, err)
 
 	// Get the recorded execution
-	retrieved, err := repo.GetExecution(context.Background(), execution.ID)
+	retrieved, err := repo.GetExecution(context.Background(), execution.ExecutionID)
 	require.NoError(t, err)
-	assert.Equal(t, execution.ID, retrieved.ID)
+	assert.Equal(t, execution.ExecutionID, retrieved.ExecutionID)
 	assert.Equal(t, task.ID, retrieved.TaskID)
 	assert.Equal(t, models.StatusCompleted, retrieved.Status)
 
 	// Try to record with empty execution ID
 	invalidExec := createTestExecution(task.ID, models.StatusCompleted)
-	invalidExec.ID = ""
+	invalidExec.ExecutionID = ""
 	err = repo.RecordExecution(context.Background(), invalidExec)
 	assert.Error(t, err)
 }
@@ -299,13 +301,13 @@ func TestFileTaskRepository_GetTaskExecutions(t *testing.T) {
 
 	// Create test executions
 	exec1 := createTestExecution(task.ID, models.StatusCompleted)
-	exec1.ID = "exec-1"
+	exec1.ExecutionID = "exec-1"
 	exec1.StartTime = time.Now().Add(-2 * time.Minute)
 	err = repo.RecordExecution(context.Background(), exec1)
 	require.NoError(t, err)
 
 	exec2 := createTestExecution(task.ID, models.StatusCompleted)
-	exec2.ID = "exec-2"
+	exec2.ExecutionID = "exec-2"
 	exec2.StartTime = time.Now().Add(-1 * time.Minute)
 	err = repo.RecordExecution(context.Background(), exec2)
 	require.NoError(t, err)
@@ -316,14 +318,14 @@ func TestFileTaskRepository_GetTaskExecutions(t *testing.T) {
 	assert.Len(t, executions, 2)
 
 	// Verify the executions are sorted by start time (newest first)
-	assert.Equal(t, exec2.ID, executions[0].ID)
-	assert.Equal(t, exec1.ID, executions[1].ID)
+	assert.Equal(t, exec2.ExecutionID, executions[0].ExecutionID)
+	assert.Equal(t, exec1.ExecutionID, executions[1].ExecutionID)
 
 	// Test limit
 	limitedExecs, err := repo.GetTaskExecutions(context.Background(), task.ID, 1)
 	assert.NoError(t, err)
 	assert.Len(t, limitedExecs, 1)
-	assert.Equal(t, exec2.ID, limitedExecs[0].ID) // Should get the newest one
+	assert.Equal(t, exec2.ExecutionID, limitedExecs[0].ExecutionID) // Should get the newest one
 
 	// Test getting executions for non-existent task
 	emptyExecs, err := repo.GetTaskExecutions(context.Background(), "non-existent", 10)
@@ -347,9 +349,9 @@ func TestFileTaskRepository_GetExecution(t *testing.T) {
 	require.NoError(t, err)
 
 	// Get the execution
-	retrieved, err := repo.GetExecution(context.Background(), execution.ID)
+	retrieved, err := repo.GetExecution(context.Background(), execution.ExecutionID)
 	require.NoError(t, err)
-	assert.Equal(t, execution.ID, retrieved.ID)
+	assert.Equal(t, execution.ExecutionID, retrieved.ExecutionID)
 	assert.Equal(t, task.ID, retrieved.TaskID)
 	assert.Equal(t, models.StatusCompleted, retrieved.Status)
 
@@ -0,0 +1,347 @@
+// File: internal/database/task_sqlite.go
+// Brief: SQLite task repository of storage.driver sqlite
+// Detailed: Implements models.TaskRepository on the SQLite database opened by OpenSQLite, with the behaviour of FileTaskRepository: tasks are validated and their timestamps stored in UTC, executions require their task to exist and replace any record with their execution ID, and execution records are compressed with tasks.compression. Listing a task's executions and purging old ones are indexed queries rather than directory scans.
+// Author: drama.lin@aver.com
+// Date: 2026-10-14
+
+package database
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"argus/internal/compress"
+	"argus/internal/faults"
+	"argus/internal/models"
+	"argus/internal/retention"
+)
+
+// SQLiteTaskRepository implements models.TaskRepository on an SQLite database
+type SQLiteTaskRepository struct {
+	db          *sql.DB
+	compression compress.Algorithm
+}
+
+// NewSQLiteTaskRepository creates a task repository on the SQLite database
+// opened by OpenSQLite
+func NewSQLiteTaskRepository(db *sql.DB) *SQLiteTaskRepository {
+	return &SQLiteTaskRepository{db: db}
+}
+
+// SetCompression sets the algorithm used for newly written execution records.
+// Existing records are read in whatever format they were stored.
+func (r *SQLiteTaskRepository) SetCompression(alg compress.Algorithm) {
+	r.compression = alg
+}
+
+func (r *SQLiteTaskRepository) CreateTask(ctx context.Context, task *models.TaskConfig) error {
+	if task == nil {
+		return errors.New("task cannot be nil")
+	}
+
+	// Validate task before generating ID
+	if err := task.Validate(); err != nil {
+		return err
+	}
+
+	if task.ID == "" {
+		task.ID = models.GenerateID()
+	}
+	if task.CreatedAt.IsZero() {
+		task.CreatedAt = time.Now().UTC()
+	}
+	task.UpdatedAt = time.Now().UTC()
+	data, err := encodeTask(task)
+	if err != nil {
+		return err
+	}
+
+	if err := faults.Inject(faults.StoreWrite); err != nil {
+		return fmt.Errorf("failed to write task: %w", err)
+	}
+	result, err := r.db.ExecContext(ctx, `INSERT INTO tasks (id, type, created_at, config) VALUES (?, ?, ?, ?)
+		ON CONFLICT (id) DO NOTHING`, task.ID, string(task.Type), task.CreatedAt.UnixMicro(), data)
+	if err != nil {
+		return fmt.Errorf("failed to write task: %w", err)
+	}
+	if n, err := result.RowsAffected(); err != nil {
+		return fmt.Errorf("failed to write task: %w", err)
+	} else if n == 0 {
+		return fmt.Errorf("task with ID %s already exists", task.ID)
+	}
+	return nil
+}
+
+func (r *SQLiteTaskRepository) GetTask(ctx context.Context, id string) (*models.TaskConfig, error) {
+	if id == "" {
+		return nil, ErrInvalidTaskID
+	}
+	if err := faults.Inject(faults.StoreRead); err != nil {
+		return nil, fmt.Errorf("failed to read task: %w", err)
+	}
+	var data []byte
+	err := r.db.QueryRowContext(ctx, `SELECT config FROM tasks WHERE id = ?`, id).Scan(&data)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrTaskNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read task: %w", err)
+	}
+	var task models.TaskConfig
+	if err := json.Unmarshal(data, &task); err != nil {
+		return nil, fmt.Errorf("failed to read task: failed to decode task: %w", err)
+	}
+	return &task, nil
+}
+
+func (r *SQLiteTaskRepository) UpdateTask(ctx context.Context, task *models.TaskConfig) error {
+	if task == nil {
+		return errors.New("task cannot be nil")
+	}
+	if task.ID == "" {
+		return ErrInvalidTaskID
+	}
+	if err := task.Validate(); err != nil {
+		return err
+	}
+	task.UpdatedAt = time.Now().UTC()
+	data, err := encodeTask(task)
+	if err != nil {
+		return err
+	}
+
+	if err := faults.Inject(faults.StoreWrite); err != nil {
+		return fmt.Errorf("failed to write task: %w", err)
+	}
+	result, err := r.db.ExecContext(ctx, `UPDATE tasks SET type = ?, created_at = ?, config = ? WHERE id = ?`,
+		string(task.Type), task.CreatedAt.UnixMicro(), data, task.ID)
+	if err != nil {
+		return fmt.Errorf("failed to write task: %w", err)
+	}
+	if n, err := result.RowsAffected(); err != nil {
+		return fmt.Errorf("failed to write task: %w", err)
+	} else if n == 0 {
+		return ErrTaskNotFound
+	}
+	return nil
+}
+
+func (r *SQLiteTaskRepository) DeleteTask(ctx context.Context, id string) error {
+	if id == "" {
+		return ErrInvalidTaskID
+	}
+	result, err := r.db.ExecContext(ctx, `DELETE FROM tasks WHERE id = ?`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete task: %w", err)
+	}
+	if n, err := result.RowsAffected(); err != nil {
+		return fmt.Errorf("failed to delete task: %w", err)
+	} else if n == 0 {
+		return ErrTaskNotFound
+	}
+	return nil
+}
+
+func (r *SQLiteTaskRepository) ListTasks(ctx context.Context) ([]*models.TaskConfig, error) {
+	return r.queryTasks(ctx, `SELECT config FROM tasks ORDER BY created_at DESC`)
+}
+
+func (r *SQLiteTaskRepository) GetTasksByType(ctx context.Context, taskType models.TaskType) ([]*models.TaskConfig, error) {
+	return r.queryTasks(ctx, `SELECT config FROM tasks WHERE type = ? ORDER BY created_at DESC`, string(taskType))
+}
+
+// queryTasks returns the tasks a query selects the config of, skipping
+// undecodable ones like the file repository skips unreadable files
+func (r *SQLiteTaskRepository) queryTasks(ctx context.Context, query string, args ...any) ([]*models.TaskConfig, error) {
+	if err := faults.Inject(faults.StoreRead); err != nil {
+		return nil, fmt.Errorf("failed to list tasks: %w", err)
+	}
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tasks: %w", err)
+	}
+	defer rows.Close()
+
+	var tasksList []*models.TaskConfig
+	for rows.Next() {
+		var data []byte
+		if err := rows.Scan(&data); err != nil {
+			return nil, fmt.Errorf("failed to list tasks: %w", err)
+		}
+		task := &models.TaskConfig{}
+		if err := json.Unmarshal(data, task); err != nil {
+			continue
+		}
+		tasksList = append(tasksList, task)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list tasks: %w", err)
+	}
+	return tasksList, nil
+}
+
+func (r *SQLiteTaskRepository) RecordExecution(ctx context.Context, execution *models.TaskExecution) error {
+	if execution == nil {
+		return errors.New("execution cannot be nil")
+	}
+	if execution.TaskID == "" {
+		return errors.New("task ID is required for execution record")
+	}
+
+	// Verify task exists before creating execution
+	_, err := r.GetTask(ctx, execution.TaskID)
+	if err != nil {
+		return fmt.Errorf("cannot create execution for task: %w", err)
+	}
+
+	// ExecutionID is required and must be provided
+	if execution.ExecutionID == "" {
+		return errors.New("execution ExecutionID is required")
+	}
+	execution.StartTime = execution.StartTime.UTC()
+	execution.EndTime = execution.EndTime.UTC()
+
+	data, err := json.Marshal(execution)
+	if err != nil {
+		return fmt.Errorf("failed to encode execution: %w", err)
+	}
+	data, err = compress.Encode(r.compression, data)
+	if err != nil {
+		return fmt.Errorf("failed to compress execution: %w", err)
+	}
+	if err := faults.Inject(faults.StoreWrite); err != nil {
+		return fmt.Errorf("failed to write execution: %w", err)
+	}
+	if _, err := r.db.ExecContext(ctx, `INSERT OR REPLACE INTO task_executions (id, task_id, start_time, record) VALUES (?, ?, ?, ?)`,
+		execution.ExecutionID, execution.TaskID, execution.StartTime.UnixMicro(), data); err != nil {
+		return fmt.Errorf("failed to write execution: %w", err)
+	}
+	return nil
+}
+
+func (r *SQLiteTaskRepository) GetTaskExecutions(ctx context.Context, taskID string, limit int) ([]*models.TaskExecution, error) {
+	if taskID == "" {
+		return nil, ErrInvalidTaskID
+	}
+	if limit <= 0 {
+		limit = -1 // No limit
+	}
+	if err := faults.Inject(faults.StoreRead); err != nil {
+		return nil, fmt.Errorf("failed to read execution: %w", err)
+	}
+	rows, err := r.db.QueryContext(ctx, `SELECT record FROM task_executions WHERE task_id = ?
+		ORDER BY start_time DESC LIMIT ?`, taskID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list executions: %w", err)
+	}
+	defer rows.Close()
+
+	executions := []*models.TaskExecution{}
+	for rows.Next() {
+		var data []byte
+		if err := rows.Scan(&data); err != nil {
+			return nil, fmt.Errorf("failed to list executions: %w", err)
+		}
+		exec, err := decodeExecution(data)
+		if err != nil {
+			continue
+		}
+		executions = append(executions, exec)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list executions: %w", err)
+	}
+	return executions, nil
+}
+
+func (r *SQLiteTaskRepository) GetExecution(ctx context.Context, id string) (*models.TaskExecution, error) {
+	if id == "" {
+		return nil, ErrInvalidTaskID
+	}
+	if err := faults.Inject(faults.StoreRead); err != nil {
+		return nil, fmt.Errorf("failed to read execution: %w", err)
+	}
+	var data []byte
+	err := r.db.QueryRowContext(ctx, `SELECT record FROM task_executions WHERE id = ?`, id).Scan(&data)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrExecutionNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read execution: %w", err)
+	}
+	exec, err := decodeExecution(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read execution: %w", err)
+	}
+	return exec, nil
+}
+
+func (r *SQLiteTaskRepository) GetExecutions(ctx context.Context, taskID string) ([]*models.TaskExecution, error) {
+	return r.GetTaskExecutions(ctx, taskID, 0)
+}
+
+// ExecutionUsage reports the number of stored execution records, their
+// stored size and the oldest one's start time
+func (r *SQLiteTaskRepository) ExecutionUsage(ctx context.Context) (retention.Usage, error) {
+	var (
+		usage  retention.Usage
+		oldest sql.NullInt64
+	)
+	err := r.db.QueryRowContext(ctx, `SELECT COUNT(*), COALESCE(SUM(LENGTH(record)), 0), MIN(start_time) FROM task_executions`).
+		Scan(&usage.Items, &usage.Bytes, &oldest)
+	if err != nil {
+		return usage, fmt.Errorf("failed to read executions: %w", err)
+	}
+	if oldest.Valid {
+		t := time.UnixMicro(oldest.Int64).UTC()
+		usage.Oldest = &t
+	}
+	return usage, nil
+}
+
+// PurgeExecutions deletes execution records that started before before and
+// returns the number deleted
+func (r *SQLiteTaskRepository) PurgeExecutions(ctx context.Context, before time.Time) (int, error) {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM task_executions WHERE start_time < ?`, before.UnixMicro())
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete executions: %w", err)
+	}
+	purged, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete executions: %w", err)
+	}
+	if err := reclaimSQLite(ctx, r.db); err != nil {
+		return int(purged), fmt.Errorf("failed to shrink database after deleting executions: %w", err)
+	}
+	return int(purged), nil
+}
+
+// encodeTask encodes a task with its timestamps in UTC, as the file
+// repository persists them
+func encodeTask(task *models.TaskConfig) ([]byte, error) {
+	task.CreatedAt = task.CreatedAt.UTC()
+	task.UpdatedAt = task.UpdatedAt.UTC()
+	task.Schedule.NextRunTime = task.Schedule.NextRunTime.UTC()
+	data, err := json.Marshal(task)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode task: %w", err)
+	}
+	return data, nil
+}
+
+func decodeExecution(data []byte) (*models.TaskExecution, error) {
+	// Records may be plain JSON or compressed, depending on when they were written
+	data, err := compress.Decode(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decompress execution: %w", err)
+	}
+	var exec models.TaskExecution
+	if err := json.Unmarshal(data, &exec); err != nil {
+		return nil, fmt.Errorf("failed to decode execution: %w", err)
+	}
+	return &exec, nil
+}
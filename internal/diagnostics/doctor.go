@@ -0,0 +1,544 @@
+// File: internal/diagnostics/doctor.go
+// Brief: Startup self-test and environment diagnostics for Argus
+// Detailed: Implements the checks behind `argus doctor`: storage permissions, SMTP connectivity, webhook reachability, outbound client certificate expiry, stored task cron validity, clock sanity, and gopsutil capability, producing a report suitable for attaching to bug reports.
+// Author: drama.lin@aver.com
+// Date: 2026-10-14
+
+package diagnostics
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/shirou/gopsutil/v3/cpu"
+	"github.com/shirou/gopsutil/v3/disk"
+	"github.com/shirou/gopsutil/v3/host"
+	"github.com/shirou/gopsutil/v3/load"
+	"github.com/shirou/gopsutil/v3/mem"
+	gnet "github.com/shirou/gopsutil/v3/net"
+	"github.com/shirou/gopsutil/v3/process"
+
+	"argus/internal/config"
+	"argus/internal/database"
+	"argus/internal/features"
+	"argus/internal/services"
+	"argus/internal/tlsclient"
+)
+
+// DefaultCheckTimeout bounds each network or system call made by a check
+const DefaultCheckTimeout = 5 * time.Second
+
+// CheckStatus is the outcome of a single diagnostic check
+type CheckStatus string
+
+// Available check outcomes
+const (
+	StatusOK   CheckStatus = "ok"   // Check passed
+	StatusWarn CheckStatus = "warn" // Check passed with a potential problem
+	StatusFail CheckStatus = "fail" // Check failed
+	StatusSkip CheckStatus = "skip" // Check not applicable to this deployment
+)
+
+// CheckResult holds the outcome of a single diagnostic check
+type CheckResult struct {
+	Name     string        `json:"name"`
+	Status   CheckStatus   `json:"status"`
+	Message  string        `json:"message"`
+	Duration time.Duration `json:"duration_ns"`
+}
+
+// PlatformInfo describes the runtime environment the report was generated on
+type PlatformInfo struct {
+	OS              string `json:"os"`
+	Arch            string `json:"arch"`
+	GoVersion       string `json:"go_version"`
+	NumCPU          int    `json:"num_cpu"`
+	Platform        string `json:"platform,omitempty"`
+	PlatformVersion string `json:"platform_version,omitempty"`
+	KernelVersion   string `json:"kernel_version,omitempty"`
+	Virtualization  string `json:"virtualization,omitempty"`
+	Timezone        string `json:"timezone"`
+}
+
+// Report is the full diagnostics report
+type Report struct {
+	GeneratedAt time.Time     `json:"generated_at"`
+	ConfigPath  string        `json:"config_path"`
+	Platform    PlatformInfo  `json:"platform"`
+	Results     []CheckResult `json:"results"`
+}
+
+// HasFailures reports whether any check failed
+func (r *Report) HasFailures() bool {
+	for _, result := range r.Results {
+		if result.Status == StatusFail {
+			return true
+		}
+	}
+	return false
+}
+
+// WriteJSON writes the report as indented JSON
+func (r *Report) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(r)
+}
+
+// WriteText writes a human-readable version of the report
+func (r *Report) WriteText(w io.Writer) error {
+	p := r.Platform
+	lines := []string{
+		"Argus diagnostics report",
+		fmt.Sprintf("Generated: %s", r.GeneratedAt.Format(time.RFC3339)),
+		fmt.Sprintf("Config:    %s", r.ConfigPath),
+		fmt.Sprintf("Platform:  %s/%s, %s, %d CPUs, timezone %s", p.OS, p.Arch, p.GoVersion, p.NumCPU, p.Timezone),
+	}
+	if p.Platform != "" {
+		lines = append(lines, fmt.Sprintf("Host:      %s %s, kernel %s", p.Platform, p.PlatformVersion, p.KernelVersion))
+	}
+	if p.Virtualization != "" {
+		lines = append(lines, fmt.Sprintf("Virt:      %s", p.Virtualization))
+	}
+	lines = append(lines, "")
+
+	for _, result := range r.Results {
+		lines = append(lines, fmt.Sprintf("[%-4s] %-16s %s", strings.ToUpper(string(result.Status)), result.Name, result.Message))
+	}
+
+	_, err := io.WriteString(w, strings.Join(lines, "\n")+"\n")
+	return err
+}
+
+// Options controls which external resources the checks probe
+type Options struct {
+	Config     *config.Config
+	ConfigPath string
+	SMTPHost   string        // SMTP relay host; SMTP check is skipped when empty
+	SMTPPort   int           // SMTP relay port
+	Sendmail   string        // Sendmail-compatible relay command; replaces the SMTP probe when set
+	Timeout    time.Duration // Per-check timeout for network and system calls
+}
+
+// Run executes all diagnostic checks and returns the report
+func Run(ctx context.Context, opts Options) *Report {
+	if opts.Timeout <= 0 {
+		opts.Timeout = DefaultCheckTimeout
+	}
+
+	report := &Report{
+		GeneratedAt: time.Now(),
+		ConfigPath:  opts.ConfigPath,
+		Platform:    collectPlatformInfo(ctx),
+	}
+
+	checks := []struct {
+		name string
+		fn   func(context.Context, Options) (CheckStatus, string)
+	}{
+		{"storage", checkStoragePaths},
+		{"smtp", checkSMTP},
+		{"webhooks", checkWebhooks},
+		{"client-certificates", checkClientCertificates},
+		{"task-schedules", checkTaskSchedules},
+		{"clock", checkClock},
+		{"system-metrics", checkSystemMetrics},
+	}
+
+	for _, check := range checks {
+		start := time.Now()
+		status, message := check.fn(ctx, opts)
+		report.Results = append(report.Results, CheckResult{
+			Name:     check.name,
+			Status:   status,
+			Message:  message,
+			Duration: time.Since(start),
+		})
+	}
+
+	return report
+}
+
+// collectPlatformInfo gathers runtime and host details; host lookups are best effort
+func collectPlatformInfo(ctx context.Context) PlatformInfo {
+	info := PlatformInfo{
+		OS:        runtime.GOOS,
+		Arch:      runtime.GOARCH,
+		GoVersion: runtime.Version(),
+		NumCPU:    runtime.NumCPU(),
+		Timezone:  time.Local.String(),
+	}
+	if hostInfo, err := host.InfoWithContext(ctx); err == nil {
+		info.Platform = hostInfo.Platform
+		info.PlatformVersion = hostInfo.PlatformVersion
+		info.KernelVersion = hostInfo.KernelVersion
+		if hostInfo.VirtualizationSystem != "" {
+			info.Virtualization = hostInfo.VirtualizationSystem + " (" + hostInfo.VirtualizationRole + ")"
+		}
+	}
+	return info
+}
+
+// checkStoragePaths verifies the configured storage directories are writable
+func checkStoragePaths(ctx context.Context, opts Options) (CheckStatus, string) {
+	paths := []struct{ key, path string }{
+		{"storage.base_path", opts.Config.Storage.BasePath},
+		{"alerts.storage_path", opts.Config.Alerts.StoragePath},
+		{"tasks.storage_path", opts.Config.Tasks.StoragePath},
+	}
+
+	status := StatusOK
+	var messages []string
+	for _, p := range paths {
+		if p.path == "" {
+			continue
+		}
+		pathStatus, msg := checkWritableDir(p.path)
+		messages = append(messages, fmt.Sprintf("%s=%s: %s", p.key, p.path, msg))
+		status = worst(status, pathStatus)
+	}
+
+	if len(messages) == 0 {
+		return StatusSkip, "no storage paths configured"
+	}
+	return status, strings.Join(messages, "; ")
+}
+
+// checkWritableDir checks that path is a writable directory, or that it can be created
+func checkWritableDir(path string) (CheckStatus, string) {
+	info, err := os.Stat(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		// Walk up to the nearest existing parent; it must be writable for Argus to create the directory
+		parent := filepath.Dir(path)
+		for {
+			if _, err := os.Stat(parent); err == nil {
+				break
+			}
+			next := filepath.Dir(parent)
+			if next == parent {
+				break
+			}
+			parent = next
+		}
+		if err := probeWrite(parent); err != nil {
+			return StatusFail, fmt.Sprintf("missing and parent %s is not writable: %v", parent, err)
+		}
+		return StatusWarn, "missing, will be created on startup"
+	}
+	if err != nil {
+		return StatusFail, err.Error()
+	}
+	if !info.IsDir() {
+		return StatusFail, "not a directory"
+	}
+	if err := probeWrite(path); err != nil {
+		return StatusFail, fmt.Sprintf("not writable: %v", err)
+	}
+	return StatusOK, "writable"
+}
+
+// probeWrite creates and removes a temporary file in dir
+func probeWrite(dir string) error {
+	f, err := os.CreateTemp(dir, ".argus-doctor-*")
+	if err != nil {
+		return err
+	}
+	name := f.Name()
+	f.Close()
+	return os.Remove(name)
+}
+
+// checkSMTP verifies the SMTP relay accepts TCP connections and greets like an SMTP server
+func checkSMTP(ctx context.Context, opts Options) (CheckStatus, string) {
+	if opts.Sendmail != "" {
+		return checkSendmail(opts.Sendmail)
+	}
+	if opts.SMTPHost == "" {
+		return StatusSkip, "SMTP_HOST not set, email notifications disabled"
+	}
+
+	addr := net.JoinHostPort(opts.SMTPHost, fmt.Sprintf("%d", opts.SMTPPort))
+	dialer := net.Dialer{Timeout: opts.Timeout}
+	conn, err := dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return StatusFail, fmt.Sprintf("cannot connect to %s: %v", addr, err)
+	}
+	defer conn.Close()
+
+	conn.SetReadDeadline(time.Now().Add(opts.Timeout))
+	greeting := make([]byte, 512)
+	n, err := conn.Read(greeting)
+	if err != nil {
+		return StatusWarn, fmt.Sprintf("connected to %s but no greeting received: %v", addr, err)
+	}
+	line := strings.TrimSpace(strings.SplitN(string(greeting[:n]), "\n", 2)[0])
+	if !strings.HasPrefix(line, "220") {
+		return StatusWarn, fmt.Sprintf("unexpected greeting from %s: %q", addr, line)
+	}
+	return StatusOK, fmt.Sprintf("%s answered: %s", addr, line)
+}
+
+// checkSendmail verifies the relay command exists and is executable
+func checkSendmail(command string) (CheckStatus, string) {
+	path, err := exec.LookPath(command)
+	if err != nil {
+		return StatusFail, fmt.Sprintf("relay command %s not usable: %v", command, err)
+	}
+	return StatusOK, fmt.Sprintf("sendmail mode, relay command %s", path)
+}
+
+// checkWebhooks probes every webhook URL referenced by stored alert notification settings
+func checkWebhooks(ctx context.Context, opts Options) (CheckStatus, string) {
+	var store database.AlertStore
+	if storageDriver(opts.Config) != database.DriverFile {
+		stores, status, msg := openStorage(opts.Config)
+		if stores == nil {
+			return status, msg
+		}
+		defer stores.Close()
+		store = stores.Alerts
+	} else {
+		alertsDir := filepath.Join(opts.Config.Alerts.StoragePath, database.AlertsDir)
+		if _, err := os.Stat(alertsDir); err != nil {
+			return StatusSkip, "no stored alerts"
+		}
+		var err error
+		if store, err = database.NewAlertStore(opts.Config.Alerts.StoragePath); err != nil {
+			return StatusFail, fmt.Sprintf("cannot open alert store: %v", err)
+		}
+	}
+	alerts, err := store.ListAlerts()
+	if err != nil {
+		return StatusFail, fmt.Sprintf("cannot list alerts: %v", err)
+	}
+
+	seen := make(map[string]bool)
+	var urls []string
+	for _, alert := range alerts {
+		for _, notif := range alert.Notifications {
+			if u, ok := notif.Settings["url"].(string); ok && u != "" && !seen[u] {
+				seen[u] = true
+				urls = append(urls, u)
+			}
+		}
+	}
+	sort.Strings(urls)
+	if len(urls) == 0 {
+		return StatusSkip, "no webhook destinations configured"
+	}
+
+	// Probe through the proxy and with the TLS settings the webhook channel uses
+	transport, err := opts.Config.OutboundTransport("webhook")
+	if err != nil {
+		return StatusFail, err.Error()
+	}
+	client := &http.Client{Timeout: opts.Timeout, Transport: transport}
+	status := StatusOK
+	var messages []string
+	for _, u := range urls {
+		req, err := http.NewRequestWithContext(ctx, http.MethodHead, u, nil)
+		if err != nil {
+			status = worst(status, StatusFail)
+			messages = append(messages, fmt.Sprintf("%s: invalid URL", u))
+			continue
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			status = worst(status, StatusFail)
+			messages = append(messages, fmt.Sprintf("%s: unreachable (%v)", u, err))
+			continue
+		}
+		resp.Body.Close()
+		// Any HTTP answer proves reachability; receivers often reject HEAD with 4xx
+		if resp.StatusCode >= 500 {
+			status = worst(status, StatusWarn)
+		}
+		messages = append(messages, fmt.Sprintf("%s: HTTP %d", u, resp.StatusCode))
+	}
+	return status, strings.Join(messages, "; ")
+}
+
+// checkClientCertificates reports outbound client certificates that are
+// expiring, expired or unreadable
+func checkClientCertificates(ctx context.Context, opts Options) (CheckStatus, string) {
+	certs := opts.Config.ClientCertificates()
+	if len(certs) == 0 {
+		return StatusSkip, "no client certificates configured"
+	}
+	names := make([]string, 0, len(certs))
+	for name := range certs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	status := StatusOK
+	var messages []string
+	for _, name := range names {
+		cert := certs[name].Check(name, time.Now(), opts.Config.CertExpiryWarning())
+		switch cert.Status {
+		case tlsclient.StatusError:
+			status = worst(status, StatusFail)
+			messages = append(messages, fmt.Sprintf("%s: %s", name, cert.Error))
+		case tlsclient.StatusExpired:
+			status = worst(status, StatusFail)
+			messages = append(messages, fmt.Sprintf("%s: %s expired on %s", name, cert.Subject, cert.NotAfter.Format(time.DateOnly)))
+		case tlsclient.StatusExpiring:
+			status = worst(status, StatusWarn)
+			messages = append(messages, fmt.Sprintf("%s: %s expires in %d days", name, cert.Subject, cert.DaysLeft))
+		default:
+			messages = append(messages, fmt.Sprintf("%s: %s valid until %s", name, cert.Subject, cert.NotAfter.Format(time.DateOnly)))
+		}
+	}
+	return status, strings.Join(messages, "; ")
+}
+
+// checkTaskSchedules validates the cron expression of every stored recurring task
+func checkTaskSchedules(ctx context.Context, opts Options) (CheckStatus, string) {
+	var repo database.TaskRepository
+	if storageDriver(opts.Config) != database.DriverFile {
+		stores, status, msg := openStorage(opts.Config)
+		if stores == nil {
+			return status, msg
+		}
+		defer stores.Close()
+		repo = stores.Tasks
+	} else {
+		tasksDir := filepath.Join(opts.Config.Tasks.StoragePath, database.TasksDir)
+		if _, err := os.Stat(tasksDir); err != nil {
+			return StatusSkip, "no stored tasks"
+		}
+		var err error
+		if repo, err = database.NewFileTaskRepository(opts.Config.Tasks.StoragePath); err != nil {
+			return StatusFail, fmt.Sprintf("cannot open task repository: %v", err)
+		}
+	}
+	tasks, err := repo.ListTasks(ctx)
+	if err != nil {
+		return StatusFail, fmt.Sprintf("cannot list tasks: %v", err)
+	}
+
+	var invalid []string
+	checked := 0
+	for _, task := range tasks {
+		if task.Schedule.CronExpression == "" {
+			continue
+		}
+		checked++
+		if err := services.ValidateCronExpression(task.Schedule.CronExpression); err != nil {
+			invalid = append(invalid, fmt.Sprintf("%s (%s): %v", task.Name, task.ID, err))
+		}
+	}
+
+	if len(invalid) > 0 {
+		return StatusFail, strings.Join(invalid, "; ")
+	}
+	return StatusOK, fmt.Sprintf("%d of %d tasks have valid cron expressions", checked, len(tasks))
+}
+
+// storageDriver returns the storage driver the server keeps alerts and tasks
+// in: storage.driver, unless it is sqlite and its feature flag is off
+func storageDriver(cfg *config.Config) string {
+	driver := cfg.StorageDriver()
+	if driver != database.DriverSQLite {
+		return driver
+	}
+	if flags, err := features.New(cfg.Features); err != nil || !flags.Enabled(features.SQLiteStorage) {
+		return database.DriverFile
+	}
+	return driver
+}
+
+// openStorage opens the stores of a storage driver other than file, returning
+// the check's result instead when its SQLite database has not been created
+// yet or the stores fail to open
+func openStorage(cfg *config.Config) (*database.Stores, CheckStatus, string) {
+	driver := storageDriver(cfg)
+	options := cfg.StorageDriverOptions()
+	if driver == database.DriverSQLite {
+		if _, err := os.Stat(options["path"]); err != nil {
+			return nil, StatusSkip, "no SQLite database at " + options["path"]
+		}
+	}
+	stores, err := database.OpenDriver(driver, database.DriverOptions{
+		AlertsPath: cfg.Alerts.StoragePath,
+		TasksPath:  cfg.Tasks.StoragePath,
+		Options:    options,
+	})
+	if err != nil {
+		return nil, StatusFail, fmt.Sprintf("cannot open storage: %v", err)
+	}
+	return stores, StatusOK, ""
+}
+
+// checkClock looks for obviously wrong wall-clock time, which breaks schedules and alert timestamps
+func checkClock(ctx context.Context, opts Options) (CheckStatus, string) {
+	now := time.Now()
+	if now.Year() < 2020 {
+		return StatusFail, fmt.Sprintf("system time %s is implausibly old", now.Format(time.RFC3339))
+	}
+
+	bootTime, err := host.BootTimeWithContext(ctx)
+	if err != nil {
+		return StatusWarn, fmt.Sprintf("system time %s, boot time unavailable: %v", now.Format(time.RFC3339), err)
+	}
+	booted := time.Unix(int64(bootTime), 0)
+	if booted.After(now) {
+		return StatusFail, fmt.Sprintf("boot time %s is in the future", booted.Format(time.RFC3339))
+	}
+	return StatusOK, fmt.Sprintf("system time %s, up since %s", now.Format(time.RFC3339), booted.Format(time.RFC3339))
+}
+
+// checkSystemMetrics calls every gopsutil API the collector depends on
+func checkSystemMetrics(ctx context.Context, opts Options) (CheckStatus, string) {
+	probes := []struct {
+		name string
+		fn   func(context.Context) error
+	}{
+		{"cpu", func(ctx context.Context) error { _, err := cpu.PercentWithContext(ctx, 0, false); return err }},
+		{"load", func(ctx context.Context) error { _, err := load.AvgWithContext(ctx); return err }},
+		{"memory", func(ctx context.Context) error { _, err := mem.VirtualMemoryWithContext(ctx); return err }},
+		{"network", func(ctx context.Context) error { _, err := gnet.IOCountersWithContext(ctx, false); return err }},
+		{"disk", func(ctx context.Context) error { _, err := disk.PartitionsWithContext(ctx, false); return err }},
+		{"process", func(ctx context.Context) error { _, err := process.ProcessesWithContext(ctx); return err }},
+	}
+
+	status := StatusOK
+	var failed, passed []string
+	for _, probe := range probes {
+		probeCtx, cancel := context.WithTimeout(ctx, opts.Timeout)
+		err := probe.fn(probeCtx)
+		cancel()
+		if err != nil {
+			status = StatusFail
+			failed = append(failed, fmt.Sprintf("%s: %v", probe.name, err))
+			continue
+		}
+		passed = append(passed, probe.name)
+	}
+
+	if len(failed) > 0 {
+		return status, "unsupported: " + strings.Join(failed, "; ")
+	}
+	return status, "supported: " + strings.Join(passed, ", ")
+}
+
+// worst returns the more severe of two statuses
+func worst(a, b CheckStatus) CheckStatus {
+	rank := map[CheckStatus]int{StatusSkip: 0, StatusOK: 1, StatusWarn: 2, StatusFail: 3}
+	if rank[b] > rank[a] {
+		return b
+	}
+	return a
+}
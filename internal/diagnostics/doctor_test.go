@@ -0,0 +1,113 @@
+package diagnostics
+
+import (
+	"bytes"
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"argus/internal/config"
+	"argus/internal/database"
+	"argus/internal/models"
+)
+
+func TestCheckWritableDir(t *testing.T) {
+	dir := t.TempDir()
+
+	status, _ := checkWritableDir(dir)
+	assert.Equal(t, StatusOK, status)
+
+	status, msg := checkWritableDir(filepath.Join(dir, "missing", "nested"))
+	assert.Equal(t, StatusWarn, status)
+	assert.Contains(t, msg, "will be created")
+}
+
+func TestCheckTaskSchedules(t *testing.T) {
+	dir := t.TempDir()
+	repo, err := database.NewFileTaskRepository(dir)
+	require.NoError(t, err)
+
+	for _, expr := range []string{"*/5 * * * *", "not a cron"} {
+		task := &models.TaskConfig{
+			ID:       models.GenerateID(),
+			Name:     "task " + expr,
+			Type:     models.TaskSystemCleanup,
+			Schedule: models.Schedule{CronExpression: expr},
+		}
+		require.NoError(t, repo.CreateTask(context.Background(), task))
+	}
+
+	cfg := &config.Config{}
+	cfg.Tasks.StoragePath = dir
+
+	status, msg := checkTaskSchedules(context.Background(), Options{Config: cfg})
+	assert.Equal(t, StatusFail, status)
+	assert.Contains(t, msg, "not a cron")
+}
+
+func TestCheckTaskSchedules_SQLite(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Storage.Driver = "sqlite"
+	cfg.Storage.SQLitePath = filepath.Join(t.TempDir(), "argus.db")
+	status, _ := checkTaskSchedules(context.Background(), Options{Config: cfg})
+	assert.Equal(t, StatusSkip, status, "no database yet")
+
+	db, err := database.OpenSQLite(cfg.Storage.SQLitePath, "", "")
+	require.NoError(t, err)
+	task := &models.TaskConfig{
+		ID:       models.GenerateID(),
+		Name:     "broken",
+		Type:     models.TaskSystemCleanup,
+		Schedule: models.Schedule{CronExpression: "not a cron"},
+	}
+	require.NoError(t, database.NewSQLiteTaskRepository(db).CreateTask(context.Background(), task))
+	require.NoError(t, db.Close())
+
+	status, msg := checkTaskSchedules(context.Background(), Options{Config: cfg})
+	assert.Equal(t, StatusFail, status)
+	assert.Contains(t, msg, "not a cron")
+}
+
+func TestCheckClientCertificates(t *testing.T) {
+	cfg := &config.Config{}
+	status, _ := checkClientCertificates(context.Background(), Options{Config: cfg})
+	assert.Equal(t, StatusSkip, status)
+
+	missing := filepath.Join(t.TempDir(), "argus.crt")
+	cfg.OutboundTLS.Channels = map[string]config.TLSConfig{"webhook": {CertFile: missing, KeyFile: missing}}
+	status, msg := checkClientCertificates(context.Background(), Options{Config: cfg})
+	assert.Equal(t, StatusFail, status)
+	assert.Contains(t, msg, "webhook: invalid client certificate")
+}
+
+func TestReport(t *testing.T) {
+	report := &Report{
+		GeneratedAt: time.Now(),
+		Results: []CheckResult{
+			{Name: "storage", Status: StatusOK, Message: "writable"},
+			{Name: "smtp", Status: StatusSkip, Message: "not configured"},
+		},
+	}
+	assert.False(t, report.HasFailures())
+
+	report.Results = append(report.Results, CheckResult{Name: "clock", Status: StatusFail, Message: "bad"})
+	assert.True(t, report.HasFailures())
+
+	var buf bytes.Buffer
+	require.NoError(t, report.WriteText(&buf))
+	assert.Contains(t, buf.String(), "[FAIL] clock")
+	assert.Equal(t, StatusFail, worst(StatusWarn, StatusFail))
+	assert.Equal(t, StatusWarn, worst(StatusWarn, StatusOK))
+}
+
+func TestCheckSendmail(t *testing.T) {
+	status, _ := checkSMTP(context.Background(), Options{Sendmail: "sh"})
+	assert.Equal(t, StatusOK, status)
+
+	status, msg := checkSMTP(context.Background(), Options{Sendmail: filepath.Join(t.TempDir(), "missing-sendmail")})
+	assert.Equal(t, StatusFail, status, msg)
+}
@@ -0,0 +1,262 @@
+// File: internal/diskqueue/diskqueue.go
+// Brief: Bounded on-disk FIFO queue
+// Detailed: Stores each queued item as a JSON file named by its sequence number, so pending work survives restarts. The queue keeps at most a configured number of items, dropping the oldest beyond it, and expires items older than its retention.
+// Author: drama.lin@aver.com
+// Date: 2026-10-14
+
+package diskqueue
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"argus/internal/clock"
+)
+
+// DefaultMaxItems is the number of items kept when Config.MaxItems is unset
+const DefaultMaxItems = 1000
+
+// ErrNotFound is returned for items no longer in the queue
+var ErrNotFound = errors.New("queue item not found")
+
+// Config configures a queue
+type Config struct {
+	Dir       string        // Directory holding one file per item
+	MaxItems  int           // Items kept; the oldest are dropped beyond it (0 uses DefaultMaxItems)
+	Retention time.Duration // Items older than this expire (0 keeps them until removed)
+	Clock     clock.Clock   // nil uses the real clock
+}
+
+// Item is a queued payload with its delivery bookkeeping
+type Item struct {
+	ID        string          `json:"id"`
+	Enqueued  time.Time       `json:"enqueued"`
+	Attempts  int             `json:"attempts"`
+	LastError string          `json:"last_error,omitempty"`
+	Payload   json.RawMessage `json:"payload"`
+}
+
+// Decode unmarshals the item's payload into v
+func (i Item) Decode(v any) error {
+	return json.Unmarshal(i.Payload, v)
+}
+
+// Stats reports the fill level of a queue and the items it lost
+type Stats struct {
+	Length   int        `json:"length"`
+	Capacity int        `json:"capacity"`
+	Dropped  uint64     `json:"dropped"` // Oldest items dropped because the queue was full
+	Expired  uint64     `json:"expired"` // Items dropped after the retention
+	Oldest   *time.Time `json:"oldest,omitempty"`
+}
+
+// Queue is a FIFO of items persisted under a directory. It is safe for
+// concurrent use.
+type Queue struct {
+	config  Config
+	clock   clock.Clock
+	ready   chan struct{}
+	mu      sync.Mutex
+	items   []Item // Oldest first
+	seq     uint64
+	dropped uint64
+	expired uint64
+}
+
+// Open loads the items queued under config.Dir, creating it if needed.
+// Unreadable item files are logged and removed.
+func Open(config Config) (*Queue, error) {
+	if config.MaxItems <= 0 {
+		config.MaxItems = DefaultMaxItems
+	}
+	if err := os.MkdirAll(config.Dir, 0700); err != nil {
+		return nil, fmt.Errorf("failed to create queue directory: %w", err)
+	}
+	entries, err := os.ReadDir(config.Dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read queue directory: %w", err)
+	}
+
+	q := &Queue{config: config, clock: clock.OrReal(config.Clock), ready: make(chan struct{}, 1)}
+	for _, entry := range entries {
+		name := entry.Name()
+		path := filepath.Join(config.Dir, name)
+		if strings.HasSuffix(name, ".tmp") {
+			// Left over from a write interrupted by a crash
+			os.Remove(path)
+			continue
+		}
+		seq, err := strconv.ParseUint(strings.TrimSuffix(name, ".json"), 10, 64)
+		if entry.IsDir() || !strings.HasSuffix(name, ".json") || err != nil {
+			continue
+		}
+		data, err := os.ReadFile(path)
+		var item Item
+		if err == nil {
+			err = json.Unmarshal(data, &item)
+		}
+		if err != nil {
+			slog.Warn("Removing unreadable queue item", "path", path, "error", err)
+			os.Remove(path)
+			continue
+		}
+		item.ID = strings.TrimSuffix(name, ".json")
+		q.items = append(q.items, item)
+		q.seq = max(q.seq, seq)
+	}
+	sort.Slice(q.items, func(i, j int) bool { return q.items[i].ID < q.items[j].ID })
+	if len(q.items) > 0 {
+		q.signal()
+	}
+	return q, nil
+}
+
+// Push appends payload to the queue and returns the new item along with the
+// oldest items dropped to make room for it
+func (q *Queue) Push(payload any) (Item, []Item, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return Item{}, nil, fmt.Errorf("failed to encode queue item: %w", err)
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.seq++
+	item := Item{ID: fmt.Sprintf("%016d", q.seq), Enqueued: q.clock.Now().UTC(), Payload: data}
+	if err := q.write(item); err != nil {
+		return Item{}, nil, err
+	}
+	q.items = append(q.items, item)
+
+	var dropped []Item
+	for len(q.items) > q.config.MaxItems {
+		dropped = append(dropped, q.items[0])
+		q.remove(0)
+		q.dropped++
+	}
+	q.signal()
+	return item, dropped, nil
+}
+
+// Items returns the queued items, oldest first
+func (q *Queue) Items() []Item {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return append([]Item(nil), q.items...)
+}
+
+// Update saves the attempt count and last error of a queued item
+func (q *Queue) Update(item Item) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	i := q.index(item.ID)
+	if i < 0 {
+		return ErrNotFound
+	}
+	q.items[i].Attempts, q.items[i].LastError = item.Attempts, item.LastError
+	return q.write(q.items[i])
+}
+
+// Remove deletes a queued item, e.g. once it has been delivered
+func (q *Queue) Remove(id string) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	i := q.index(id)
+	if i < 0 {
+		return ErrNotFound
+	}
+	q.remove(i)
+	return nil
+}
+
+// Expire removes and returns the items queued longer than the retention
+func (q *Queue) Expire() []Item {
+	if q.config.Retention <= 0 {
+		return nil
+	}
+	cutoff := q.clock.Now().Add(-q.config.Retention)
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	var expired []Item
+	for i := 0; i < len(q.items); {
+		if q.items[i].Enqueued.Before(cutoff) {
+			expired = append(expired, q.items[i])
+			q.remove(i)
+			q.expired++
+			continue
+		}
+		i++
+	}
+	return expired
+}
+
+// Ready is signalled when items are pushed, so a consumer can wait for work
+func (q *Queue) Ready() <-chan struct{} {
+	return q.ready
+}
+
+// Stats reports the fill level and losses of the queue
+func (q *Queue) Stats() Stats {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	stats := Stats{Length: len(q.items), Capacity: q.config.MaxItems, Dropped: q.dropped, Expired: q.expired}
+	if len(q.items) > 0 {
+		oldest := q.items[0].Enqueued
+		stats.Oldest = &oldest
+	}
+	return stats
+}
+
+func (q *Queue) signal() {
+	select {
+	case q.ready <- struct{}{}:
+	default:
+	}
+}
+
+func (q *Queue) index(id string) int {
+	for i, item := range q.items {
+		if item.ID == id {
+			return i
+		}
+	}
+	return -1
+}
+
+// remove deletes the item at index i and its file; q.mu must be held
+func (q *Queue) remove(i int) {
+	if err := os.Remove(q.path(q.items[i].ID)); err != nil && !os.IsNotExist(err) {
+		slog.Warn("Failed to remove queue item", "path", q.path(q.items[i].ID), "error", err)
+	}
+	q.items = append(q.items[:i], q.items[i+1:]...)
+}
+
+// write saves item atomically; q.mu must be held
+func (q *Queue) write(item Item) error {
+	data, err := json.Marshal(item)
+	if err != nil {
+		return fmt.Errorf("failed to encode queue item: %w", err)
+	}
+	path := q.path(item.ID)
+	if err := os.WriteFile(path+".tmp", data, 0600); err != nil {
+		return fmt.Errorf("failed to write queue item: %w", err)
+	}
+	if err := os.Rename(path+".tmp", path); err != nil {
+		return fmt.Errorf("failed to write queue item: %w", err)
+	}
+	return nil
+}
+
+func (q *Queue) path(id string) string {
+	return filepath.Join(q.config.Dir, id+".json")
+}
@@ -0,0 +1,117 @@
+package diskqueue
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"argus/internal/clock"
+)
+
+var epoch = time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+type payload struct {
+	N int `json:"n"`
+}
+
+func decode(t *testing.T, items []Item) []int {
+	t.Helper()
+	ns := make([]int, len(items))
+	for i, item := range items {
+		var p payload
+		require.NoError(t, item.Decode(&p))
+		ns[i] = p.N
+	}
+	return ns
+}
+
+func TestQueue_FIFOAndBound(t *testing.T) {
+	q, err := Open(Config{Dir: t.TempDir(), MaxItems: 3})
+	require.NoError(t, err)
+
+	for n := 1; n <= 3; n++ {
+		_, dropped, err := q.Push(payload{n})
+		require.NoError(t, err)
+		assert.Empty(t, dropped)
+	}
+	select {
+	case <-q.Ready():
+	default:
+		t.Fatal("a push signals consumers")
+	}
+
+	_, dropped, err := q.Push(payload{4})
+	require.NoError(t, err)
+	assert.Equal(t, []int{1}, decode(t, dropped), "the oldest item makes room")
+	assert.Equal(t, []int{2, 3, 4}, decode(t, q.Items()))
+
+	items := q.Items()
+	require.NoError(t, q.Remove(items[1].ID))
+	assert.Equal(t, []int{2, 4}, decode(t, q.Items()))
+	assert.ErrorIs(t, q.Remove(items[1].ID), ErrNotFound)
+
+	stats := q.Stats()
+	assert.Equal(t, 2, stats.Length)
+	assert.Equal(t, 3, stats.Capacity)
+	assert.Equal(t, uint64(1), stats.Dropped)
+}
+
+func TestQueue_SurvivesReopen(t *testing.T) {
+	dir := t.TempDir()
+	q, err := Open(Config{Dir: dir})
+	require.NoError(t, err)
+	for n := 1; n <= 3; n++ {
+		_, _, err := q.Push(payload{n})
+		require.NoError(t, err)
+	}
+	first := q.Items()[0]
+	first.Attempts, first.LastError = 2, "connection refused"
+	require.NoError(t, q.Update(first))
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "0000000000000009.json"), []byte("{"), 0600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "0000000000000010.json.tmp"), []byte("{}"), 0600))
+
+	q, err = Open(Config{Dir: dir})
+	require.NoError(t, err)
+	items := q.Items()
+	assert.Equal(t, []int{1, 2, 3}, decode(t, items), "unreadable and partial files are discarded")
+	assert.Equal(t, 2, items[0].Attempts)
+	assert.Equal(t, "connection refused", items[0].LastError)
+	assert.NoFileExists(t, filepath.Join(dir, "0000000000000009.json"))
+	assert.NoFileExists(t, filepath.Join(dir, "0000000000000010.json.tmp"))
+	select {
+	case <-q.Ready():
+	default:
+		t.Fatal("a reopened queue with items signals consumers")
+	}
+
+	item, _, err := q.Push(payload{4})
+	require.NoError(t, err)
+	assert.Greater(t, item.ID, items[2].ID, "sequence numbers continue after a restart")
+}
+
+func TestQueue_Expire(t *testing.T) {
+	clk := clock.NewFake(epoch)
+	q, err := Open(Config{Dir: t.TempDir(), Retention: time.Hour, Clock: clk})
+	require.NoError(t, err)
+
+	_, _, err = q.Push(payload{1})
+	require.NoError(t, err)
+	clk.Advance(40 * time.Minute)
+	_, _, err = q.Push(payload{2})
+	require.NoError(t, err)
+
+	assert.Empty(t, q.Expire())
+	clk.Advance(30 * time.Minute)
+	assert.Equal(t, []int{1}, decode(t, q.Expire()))
+	assert.Equal(t, []int{2}, decode(t, q.Items()))
+
+	stats := q.Stats()
+	assert.Equal(t, uint64(1), stats.Expired)
+	require.NotNil(t, stats.Oldest)
+	assert.Equal(t, epoch.Add(40*time.Minute), *stats.Oldest)
+}
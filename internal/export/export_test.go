@@ -0,0 +1,160 @@
+package export
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"argus/internal/clock"
+	"argus/internal/ingest"
+	"argus/internal/metrics"
+)
+
+type staticSource struct {
+	samples []metrics.HostSample
+}
+
+func (s *staticSource) HostSamples() []metrics.HostSample { return s.samples }
+
+func hostSample(name string, labels map[string]string, at time.Time, value float64) metrics.HostSample {
+	return metrics.HostSample{Name: name, Labels: labels, Sample: metrics.Sample{Timestamp: at, Value: value}}
+}
+
+func TestInfluxLines(t *testing.T) {
+	at := time.Unix(1700000000, 5)
+	lines := InfluxLines([]metrics.HostSample{
+		hostSample("cpu_usage_percent", map[string]string{"host": "web 1"}, at, 42.5),
+		hostSample("cpu_load1", map[string]string{"host": "web 1"}, at, 1),
+		hostSample("disk_used_percent", map[string]string{"mountpoint": "/data,x", "host": "web 1"}, at, 70),
+		hostSample("uptime", nil, at, 3),
+		hostSample("cpu_load5", nil, at, 0),
+	})
+	assert.Equal(t, "cpu,host=web\\ 1 usage_percent=42.5,load1=1 1700000000000000005\n"+
+		"disk,host=web\\ 1,mountpoint=/data\\,x used_percent=70 1700000000000000005\n"+
+		"uptime value=3 1700000000000000005\n"+
+		"cpu load5=0 1700000000000000005\n", string(lines))
+}
+
+func TestWriteRequest_RoundTrip(t *testing.T) {
+	at := time.UnixMilli(1700000000123).UTC()
+	series, err := ingest.ParseWriteRequest(WriteRequest([]metrics.HostSample{
+		hostSample("disk_used_percent", map[string]string{"mountpoint": "/", "host": "web1"}, at, 70.5),
+		hostSample("cpu_load1", nil, at, 1),
+	}))
+	require.NoError(t, err)
+	require.Len(t, series, 2)
+	assert.Equal(t, "disk_used_percent", series[0].Name)
+	assert.Equal(t, map[string]string{"mountpoint": "/", "host": "web1"}, series[0].Labels)
+	assert.Equal(t, []metrics.Sample{{Timestamp: at, Value: 70.5}}, series[0].Samples)
+	assert.Equal(t, "cpu_load1", series[1].Name)
+}
+
+func TestExporter_Push(t *testing.T) {
+	var (
+		bodies  []string
+		headers http.Header
+		status  = http.StatusNoContent
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		headers = r.Header
+		bodies = append(bodies, string(body))
+		w.WriteHeader(status)
+		w.Write([]byte("bad timestamp\n"))
+	}))
+	defer server.Close()
+
+	selector, err := ingest.NewSelector([]string{"cpu_*"})
+	require.NoError(t, err)
+	at := time.Unix(1700000000, 0)
+	source := &staticSource{samples: []metrics.HostSample{
+		hostSample("cpu_usage_percent", nil, at, 10),
+		hostSample("memory_used_percent", nil, at, 50),
+	}}
+	exporter, err := New(source, Config{
+		Format:   FormatInflux,
+		URL:      "http://user:secret@" + strings.TrimPrefix(server.URL, "http://") + "/api/v2/write?bucket=argus",
+		Headers:  map[string]string{"Authorization": "Token abc"},
+		Labels:   map[string]string{"host": "web1", "env": "prod"},
+		Selector: selector,
+	})
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	require.NoError(t, exporter.Push(ctx))
+	require.Len(t, bodies, 1)
+	assert.Equal(t, "cpu,env=prod,host=web1 usage_percent=10 1700000000000000000\n", bodies[0])
+	assert.Equal(t, "Token abc", headers.Get("Authorization"))
+	assert.Equal(t, "Argus", headers.Get("User-Agent"))
+
+	require.NoError(t, exporter.Push(ctx))
+	assert.Len(t, bodies, 1, "samples already delivered are not sent again")
+
+	source.samples[0] = hostSample("cpu_usage_percent", map[string]string{"host": "db1"}, at.Add(time.Minute), 20)
+	status = http.StatusBadRequest
+	err = exporter.Push(ctx)
+	assert.ErrorContains(t, err, "push rejected with status 400: bad timestamp")
+	assert.Contains(t, bodies[1], "cpu,env=prod,host=db1 ", "a sample's labels win over the configured ones")
+
+	stats := exporter.Stats()
+	assert.Equal(t, "influx", stats.Name)
+	assert.Equal(t, server.URL+"/api/v2/write", stats.Endpoint)
+	assert.Equal(t, int64(1), stats.Pushes)
+	assert.Equal(t, int64(1), stats.Failures)
+	assert.Equal(t, int64(1), stats.Samples)
+	assert.Contains(t, stats.LastError, "status 400")
+
+	status = http.StatusNoContent
+	require.NoError(t, exporter.Push(ctx))
+	assert.Len(t, bodies, 3, "a failed push is retried with the current samples")
+	assert.Equal(t, int64(2), exporter.Stats().Pushes)
+}
+
+func TestExporter_RemoteWrite(t *testing.T) {
+	received := make(chan []ingest.TimeSeries, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "snappy", r.Header.Get("Content-Encoding"))
+		assert.Equal(t, "application/x-protobuf", r.Header.Get("Content-Type"))
+		assert.Equal(t, "0.1.0", r.Header.Get("X-Prometheus-Remote-Write-Version"))
+		body, _ := io.ReadAll(r.Body)
+		data, err := ingest.DecodeSnappy(body, 1<<20)
+		require.NoError(t, err)
+		series, err := ingest.ParseWriteRequest(data)
+		require.NoError(t, err)
+		received <- series
+	}))
+	defer server.Close()
+
+	fake := clock.NewFake(time.Unix(1700000000, 0))
+	source := &staticSource{samples: []metrics.HostSample{hostSample("cpu_load1", nil, fake.Now(), 1.5)}}
+	exporter, err := New(source, Config{Format: FormatRemoteWrite, URL: server.URL + "/api/v1/write", Interval: time.Minute, Clock: fake})
+	require.NoError(t, err)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	exporter.Start(ctx)
+
+	fake.BlockUntil(1)
+	fake.Advance(time.Minute)
+	select {
+	case series := <-received:
+		require.Len(t, series, 1)
+		assert.Equal(t, "cpu_load1", series[0].Name)
+		assert.Equal(t, 1.5, series[0].Samples[0].Value)
+	case <-time.After(5 * time.Second):
+		t.Fatal("no push after the interval")
+	}
+}
+
+func TestNew_Invalid(t *testing.T) {
+	_, err := New(&staticSource{}, Config{Format: FormatInflux, URL: "ftp://example.com"})
+	assert.ErrorContains(t, err, "must be an http or https URL")
+	_, err = New(&staticSource{}, Config{Format: "graphite", URL: "http://example.com"})
+	assert.ErrorContains(t, err, `invalid exporter format "graphite"`)
+}
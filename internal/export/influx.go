@@ -0,0 +1,95 @@
+// File: internal/export/influx.go
+// Brief: InfluxDB line protocol encoding of host samples
+// Detailed: Encodes samples as InfluxDB line protocol. A series name is split at its first underscore into the measurement and the field, so cpu_usage_percent and cpu_load1 become the usage_percent and load1 fields of one cpu line; samples sharing a measurement, tags and timestamp are written on the same line. Timestamps are in nanoseconds, the protocol's default precision. Values that line protocol cannot carry, NaN and infinities, are skipped.
+// Author: drama.lin@aver.com
+// Date: 2026-10-14
+
+package export
+
+import (
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"argus/internal/metrics"
+)
+
+var (
+	measurementEscaper = strings.NewReplacer(`,`, `\,`, ` `, `\ `)
+	tagEscaper         = strings.NewReplacer(`,`, `\,`, ` `, `\ `, `=`, `\=`)
+)
+
+// influxLine is the fields of one measurement, tag set and timestamp
+type influxLine struct {
+	head      string // Measurement and tags
+	timestamp int64
+	fields    []string
+}
+
+// InfluxLines encodes samples as InfluxDB line protocol
+func InfluxLines(samples []metrics.HostSample) []byte {
+	var lines []*influxLine
+	byKey := make(map[string]*influxLine)
+	for _, s := range samples {
+		if math.IsNaN(s.Value) || math.IsInf(s.Value, 0) {
+			continue
+		}
+		measurement, field := splitSeriesName(s.Name)
+		head := influxHead(measurement, s.Labels)
+		timestamp := s.Timestamp.UnixNano()
+		key := head + " " + strconv.FormatInt(timestamp, 10)
+		line, ok := byKey[key]
+		if !ok {
+			line = &influxLine{head: head, timestamp: timestamp}
+			byKey[key] = line
+			lines = append(lines, line)
+		}
+		line.fields = append(line.fields, tagEscaper.Replace(field)+"="+strconv.FormatFloat(s.Value, 'g', -1, 64))
+	}
+
+	var b strings.Builder
+	for _, line := range lines {
+		b.WriteString(line.head)
+		b.WriteByte(' ')
+		b.WriteString(strings.Join(line.fields, ","))
+		b.WriteByte(' ')
+		b.WriteString(strconv.FormatInt(line.timestamp, 10))
+		b.WriteByte('\n')
+	}
+	return []byte(b.String())
+}
+
+// splitSeriesName splits a series name into a measurement and a field, e.g.
+// memory_used_percent into memory and used_percent. A name without an
+// underscore is a measurement with a single "value" field.
+func splitSeriesName(name string) (string, string) {
+	measurement, field, ok := strings.Cut(name, "_")
+	if !ok || measurement == "" || field == "" {
+		return name, "value"
+	}
+	return measurement, field
+}
+
+// influxHead returns the measurement with its tags in key order, as InfluxDB
+// recommends
+func influxHead(measurement string, labels map[string]string) string {
+	keys := make([]string, 0, len(labels))
+	for k, v := range labels {
+		// Tags cannot be empty
+		if k != "" && v != "" {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+
+	var b strings.Builder
+	b.WriteString(measurementEscaper.Replace(measurement))
+	for _, k := range keys {
+		b.WriteByte(',')
+		b.WriteString(tagEscaper.Replace(k))
+		b.WriteByte('=')
+		b.WriteString(tagEscaper.Replace(labels[k]))
+	}
+	return b.String()
+}
@@ -0,0 +1,63 @@
+// File: internal/export/remotewrite.go
+// Brief: Prometheus remote-write encoding of host samples
+// Detailed: Encodes samples as a prometheus.WriteRequest protobuf, the payload Prometheus, VictoriaMetrics, Mimir and Thanos receive on their remote-write endpoints, with one time series per sample carrying the series name as its __name__ label. The exporter sends the request snappy-compressed.
+// Author: drama.lin@aver.com
+// Date: 2026-10-14
+
+package export
+
+import (
+	"math"
+	"sort"
+
+	"google.golang.org/protobuf/encoding/protowire"
+
+	"argus/internal/ingest"
+	"argus/internal/metrics"
+)
+
+// WriteRequest encodes samples as an uncompressed prometheus.WriteRequest
+func WriteRequest(samples []metrics.HostSample) []byte {
+	var request []byte
+	for _, s := range samples {
+		request = protowire.AppendTag(request, 1, protowire.BytesType)
+		request = protowire.AppendBytes(request, timeSeries(s))
+	}
+	return request
+}
+
+// timeSeries encodes a prometheus.TimeSeries holding one sample. Labels are
+// sorted by name, as remote-write receivers require.
+func timeSeries(s metrics.HostSample) []byte {
+	names := make([]string, 0, len(s.Labels)+1)
+	names = append(names, ingest.MetricNameLabel)
+	for name, value := range s.Labels {
+		if name != ingest.MetricNameLabel && value != "" {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	var series []byte
+	for _, name := range names {
+		value := s.Labels[name]
+		if name == ingest.MetricNameLabel {
+			value = s.Name
+		}
+		var label []byte
+		label = protowire.AppendTag(label, 1, protowire.BytesType)
+		label = protowire.AppendString(label, name)
+		label = protowire.AppendTag(label, 2, protowire.BytesType)
+		label = protowire.AppendString(label, value)
+		series = protowire.AppendTag(series, 1, protowire.BytesType)
+		series = protowire.AppendBytes(series, label)
+	}
+
+	var sample []byte
+	sample = protowire.AppendTag(sample, 1, protowire.Fixed64Type)
+	sample = protowire.AppendFixed64(sample, math.Float64bits(s.Value))
+	sample = protowire.AppendTag(sample, 2, protowire.VarintType)
+	sample = protowire.AppendVarint(sample, uint64(s.Timestamp.UnixMilli()))
+	series = protowire.AppendTag(series, 2, protowire.BytesType)
+	return protowire.AppendBytes(series, sample)
+}
@@ -0,0 +1,6 @@
+//go:build faults
+
+package faults
+
+// BuildEnabled is true in binaries built with -tags faults; injection starts enabled
+const BuildEnabled = true
@@ -0,0 +1,193 @@
+// File: internal/faults/faults.go
+// Brief: Fault injection hooks for chaos and resilience testing
+// Detailed: Lets tests and operators make metric collection slow, stores fail, email delivery fail and queues appear full at named points, so circuit breakers, retries and drop counters can be exercised without breaking real dependencies.
+// Author: drama.lin@aver.com
+// Date: 2026-10-14
+
+// Package faults injects delays and errors at named points in Argus. Injection
+// is off unless the binary is built with the "faults" tag or it is enabled at
+// runtime (debug.fault_injection), and costs a single atomic load when off.
+package faults
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Point names a place in the code where faults can be injected
+type Point string
+
+const (
+	CollectMetrics Point = "metrics.collect" // Metric collection cycle (delay simulates slow gopsutil calls)
+	StoreRead      Point = "store.read"      // Alert and task storage reads
+	StoreWrite     Point = "store.write"     // Alert and task storage writes
+	EmailSend      Point = "email.send"      // SMTP or relay delivery of one email
+	WebhookSend    Point = "webhook.send"    // HTTP delivery of one webhook notification
+	QueueFull      Point = "queue.full"      // Bounded event and notification queues reject new entries
+)
+
+// Points lists every injection point
+var Points = []Point{CollectMetrics, StoreRead, StoreWrite, EmailSend, WebhookSend, QueueFull}
+
+// ErrInjected wraps every injected error so callers and tests can recognize it
+var ErrInjected = errors.New("injected fault")
+
+// Fault describes what happens when execution reaches Point
+type Fault struct {
+	Point       Point         `json:"point"`
+	Delay       time.Duration `json:"delay"`               // Sleep before continuing
+	Error       string        `json:"error,omitempty"`     // Fail with this message (empty only delays)
+	Probability float64       `json:"probability"`         // Chance per hit in (0,1]; zero means always
+	Remaining   int           `json:"remaining,omitempty"` // Hits left before the fault clears itself; zero means unlimited
+	Hits        uint64        `json:"hits"`                // Times the fault has triggered
+}
+
+// Injector holds the active faults
+type Injector struct {
+	enabled atomic.Bool
+	mu      sync.Mutex
+	faults  map[Point]*Fault
+	rand    func() float64
+	sleep   func(time.Duration)
+}
+
+// New returns a disabled injector with no faults
+func New() *Injector {
+	return &Injector{
+		faults: make(map[Point]*Fault),
+		rand:   rand.Float64,
+		sleep:  time.Sleep,
+	}
+}
+
+// Default is the process-wide injector used by the hooks in Argus
+var Default = New()
+
+func init() {
+	if BuildEnabled {
+		Default.Enable()
+	}
+}
+
+// Enable turns injection on
+func (in *Injector) Enable() { in.enabled.Store(true) }
+
+// Disable turns injection off; configured faults are kept
+func (in *Injector) Disable() { in.enabled.Store(false) }
+
+// Enabled reports whether injection is on
+func (in *Injector) Enabled() bool { return in.enabled.Load() }
+
+// Set installs or replaces the fault for f.Point
+func (in *Injector) Set(f Fault) error {
+	if !validPoint(f.Point) {
+		return fmt.Errorf("unknown fault point %q", f.Point)
+	}
+	if f.Delay < 0 {
+		return errors.New("fault delay must not be negative")
+	}
+	if f.Probability < 0 || f.Probability > 1 {
+		return errors.New("fault probability must be between 0 and 1")
+	}
+	if f.Remaining < 0 {
+		return errors.New("fault remaining must not be negative")
+	}
+	f.Hits = 0
+	in.mu.Lock()
+	defer in.mu.Unlock()
+	in.faults[f.Point] = &f
+	return nil
+}
+
+// Clear removes the fault at p
+func (in *Injector) Clear(p Point) {
+	in.mu.Lock()
+	defer in.mu.Unlock()
+	delete(in.faults, p)
+}
+
+// Reset removes every fault
+func (in *Injector) Reset() {
+	in.mu.Lock()
+	defer in.mu.Unlock()
+	in.faults = make(map[Point]*Fault)
+}
+
+// List returns a copy of the active faults ordered by point
+func (in *Injector) List() []Fault {
+	in.mu.Lock()
+	defer in.mu.Unlock()
+	result := make([]Fault, 0, len(in.faults))
+	for _, f := range in.faults {
+		result = append(result, *f)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Point < result[j].Point })
+	return result
+}
+
+// trigger reports whether the fault at p fires on this hit and returns a copy of it
+func (in *Injector) trigger(p Point) (Fault, bool) {
+	if !in.enabled.Load() {
+		return Fault{}, false
+	}
+	in.mu.Lock()
+	defer in.mu.Unlock()
+	f, ok := in.faults[p]
+	if !ok || (f.Probability > 0 && in.rand() >= f.Probability) {
+		return Fault{}, false
+	}
+	f.Hits++
+	if f.Remaining > 0 {
+		f.Remaining--
+		if f.Remaining == 0 {
+			delete(in.faults, p)
+		}
+	}
+	return *f, true
+}
+
+// Inject applies the fault at p, if any: it sleeps for the delay and returns
+// the configured error wrapped in ErrInjected
+func (in *Injector) Inject(p Point) error {
+	f, ok := in.trigger(p)
+	if !ok {
+		return nil
+	}
+	if f.Delay > 0 {
+		in.sleep(f.Delay)
+	}
+	if f.Error != "" {
+		return fmt.Errorf("%w at %s: %s", ErrInjected, p, f.Error)
+	}
+	return nil
+}
+
+// Active reports whether the fault at p fires on this hit, for points that
+// change behaviour rather than fail (e.g. QueueFull). Delays are applied too.
+func (in *Injector) Active(p Point) bool {
+	f, ok := in.trigger(p)
+	if ok && f.Delay > 0 {
+		in.sleep(f.Delay)
+	}
+	return ok
+}
+
+// Inject applies the fault at p on the default injector
+func Inject(p Point) error { return Default.Inject(p) }
+
+// Active reports whether the fault at p fires on the default injector
+func Active(p Point) bool { return Default.Active(p) }
+
+func validPoint(p Point) bool {
+	for _, known := range Points {
+		if p == known {
+			return true
+		}
+	}
+	return false
+}
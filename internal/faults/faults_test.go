@@ -0,0 +1,90 @@
+package faults
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInject_DisabledIsNoop(t *testing.T) {
+	in := New()
+	require.NoError(t, in.Set(Fault{Point: StoreWrite, Error: "disk full"}))
+
+	assert.NoError(t, in.Inject(StoreWrite))
+	assert.False(t, in.Active(StoreWrite))
+}
+
+func TestInject_ReturnsWrappedError(t *testing.T) {
+	in := New()
+	in.Enable()
+	require.NoError(t, in.Set(Fault{Point: StoreWrite, Error: "disk full"}))
+
+	err := in.Inject(StoreWrite)
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, ErrInjected))
+	assert.Contains(t, err.Error(), "disk full")
+	assert.NoError(t, in.Inject(StoreRead))
+}
+
+func TestInject_Delay(t *testing.T) {
+	in := New()
+	in.Enable()
+	var slept time.Duration
+	in.sleep = func(d time.Duration) { slept += d }
+	require.NoError(t, in.Set(Fault{Point: CollectMetrics, Delay: 3 * time.Second}))
+
+	assert.NoError(t, in.Inject(CollectMetrics))
+	assert.Equal(t, 3*time.Second, slept)
+}
+
+func TestInject_RemainingClearsFault(t *testing.T) {
+	in := New()
+	in.Enable()
+	require.NoError(t, in.Set(Fault{Point: EmailSend, Error: "smtp down", Remaining: 2}))
+
+	assert.Error(t, in.Inject(EmailSend))
+	assert.Error(t, in.Inject(EmailSend))
+	assert.NoError(t, in.Inject(EmailSend))
+	assert.Empty(t, in.List())
+}
+
+func TestInject_Probability(t *testing.T) {
+	in := New()
+	in.Enable()
+	rolls := []float64{0.1, 0.9}
+	in.rand = func() float64 {
+		r := rolls[0]
+		rolls = rolls[1:]
+		return r
+	}
+	require.NoError(t, in.Set(Fault{Point: QueueFull, Probability: 0.5}))
+
+	assert.True(t, in.Active(QueueFull))
+	assert.False(t, in.Active(QueueFull))
+	assert.Equal(t, uint64(1), in.List()[0].Hits)
+}
+
+func TestSet_Validation(t *testing.T) {
+	in := New()
+	assert.Error(t, in.Set(Fault{Point: "nope"}))
+	assert.Error(t, in.Set(Fault{Point: StoreRead, Delay: -time.Second}))
+	assert.Error(t, in.Set(Fault{Point: StoreRead, Probability: 1.5}))
+	assert.Error(t, in.Set(Fault{Point: StoreRead, Remaining: -1}))
+}
+
+func TestClearAndReset(t *testing.T) {
+	in := New()
+	require.NoError(t, in.Set(Fault{Point: StoreRead, Error: "x"}))
+	require.NoError(t, in.Set(Fault{Point: StoreWrite, Error: "y"}))
+
+	in.Clear(StoreRead)
+	faults := in.List()
+	require.Len(t, faults, 1)
+	assert.Equal(t, StoreWrite, faults[0].Point)
+
+	in.Reset()
+	assert.Empty(t, in.List())
+}
@@ -0,0 +1,122 @@
+// File: internal/features/features.go
+// Brief: Feature flags gating experimental subsystems
+// Detailed: Declares the flags of the subsystems that can ship dark and be enabled per deployment in the features section of the config file or through the admin API, without a separate build. Subsystems read their flag when they start, so a change made while Argus runs is saved and applies from the next restart.
+// Author: drama.lin@aver.com
+// Date: 2026-10-14
+
+// Package features holds the feature flags of Argus.
+package features
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"sort"
+	"sync"
+)
+
+// Flag names
+const (
+	AgentMode     = "agent_mode"     // Host inventory and task dispatch for agents (hosts.enabled)
+	SQLiteStorage = "sqlite_storage" // SQLite store of alerts and tasks (storage.driver sqlite)
+)
+
+// Flag declares a feature flag
+type Flag struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Default     bool   `json:"default"`
+}
+
+// Flags lists every declared flag
+var Flags = []Flag{
+	{Name: AgentMode, Description: "Inventory of the agents reporting to this server and dispatch of tasks to them, when hosts.enabled is set", Default: true},
+	{Name: SQLiteStorage, Description: "Storage of alerts and tasks in an SQLite database instead of JSON files, when storage.driver is sqlite", Default: true},
+}
+
+// Lookup returns the declared flag named name
+func Lookup(name string) (Flag, bool) {
+	i := slices.IndexFunc(Flags, func(f Flag) bool { return f.Name == name })
+	if i < 0 {
+		return Flag{}, false
+	}
+	return Flags[i], true
+}
+
+// Validate checks that every configured flag is declared
+func Validate(configured map[string]bool) error {
+	for name := range configured {
+		if _, ok := Lookup(name); !ok {
+			return fmt.Errorf("unknown feature flag %q", name)
+		}
+	}
+	return nil
+}
+
+// State is a flag with its value
+type State struct {
+	Flag
+	Enabled bool `json:"enabled"` // Configured value, applied from the next restart
+	Active  bool `json:"active"`  // Value the running subsystems started with
+}
+
+// Set holds the flag values of a deployment
+type Set struct {
+	mu         sync.RWMutex
+	active     map[string]bool
+	configured map[string]bool
+}
+
+// New creates the flag values of a deployment from the configured ones;
+// flags not configured take their default
+func New(configured map[string]bool) (*Set, error) {
+	if err := Validate(configured); err != nil {
+		return nil, err
+	}
+	values := make(map[string]bool, len(Flags))
+	for _, f := range Flags {
+		values[f.Name] = f.Default
+		if enabled, ok := configured[f.Name]; ok {
+			values[f.Name] = enabled
+		}
+	}
+	return &Set{active: values, configured: maps.Clone(values)}, nil
+}
+
+// Enabled reports whether the flag was on when Argus started. Undeclared
+// flags are off.
+func (s *Set) Enabled(name string) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.active[name]
+}
+
+// Configure changes the value of a flag from the next restart
+func (s *Set) Configure(name string, enabled bool) error {
+	if _, ok := Lookup(name); !ok {
+		return fmt.Errorf("unknown feature flag %q", name)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.configured[name] = enabled
+	return nil
+}
+
+// States returns every flag with its values, by name
+func (s *Set) States() []State {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	states := make([]State, 0, len(Flags))
+	for _, f := range Flags {
+		states = append(states, State{Flag: f, Enabled: s.configured[f.Name], Active: s.active[f.Name]})
+	}
+	sort.Slice(states, func(i, j int) bool { return states[i].Name < states[j].Name })
+	return states
+}
+
+// Active returns the values the running subsystems started with
+func (s *Set) Active() map[string]bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return maps.Clone(s.active)
+}
@@ -0,0 +1,30 @@
+package features
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSet(t *testing.T) {
+	set, err := New(nil)
+	require.NoError(t, err)
+	assert.True(t, set.Enabled(AgentMode), "flags not configured take their default")
+	assert.False(t, set.Enabled("time_travel"), "undeclared flags are off")
+
+	set, err = New(map[string]bool{AgentMode: false})
+	require.NoError(t, err)
+	assert.False(t, set.Enabled(AgentMode))
+
+	require.NoError(t, set.Configure(AgentMode, true))
+	assert.False(t, set.Enabled(AgentMode), "a change applies from the next restart")
+	states := set.States()
+	require.Len(t, states, len(Flags))
+	assert.Equal(t, State{Flag: Flags[0], Enabled: true, Active: false}, states[0])
+	assert.Equal(t, map[string]bool{AgentMode: false, SQLiteStorage: true}, set.Active())
+
+	assert.Error(t, set.Configure("time_travel", true))
+	_, err = New(map[string]bool{"time_travel": true})
+	assert.ErrorContains(t, err, `unknown feature flag "time_travel"`)
+}
@@ -0,0 +1,61 @@
+// File: internal/handlers/access.go
+// Brief: Roles the API routes require
+// Detailed: Holds the role each route requires for the access control middleware. By default reading requires the viewer role, and changing anything, as well as every admin and debug endpoint, the admin role. Handlers annotate the routes that differ where they register them: the operator actions, acknowledging, silencing and running, and the POSTs that only read or change the caller's own state, which viewers may send.
+// Author: drama.lin@aver.com
+// Date: 2026-10-14
+
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+
+	"argus/internal/auth"
+)
+
+var (
+	routeRolesMu sync.RWMutex
+	routeRoles   = map[string]auth.Role{} // By "METHOD /full/path"
+)
+
+// Annotate declares the role routes of group require instead of the default,
+// each given as method and path relative to the group like they are
+// registered, e.g. Annotate(alerts, auth.RoleOperator, "POST /:id/ack")
+func Annotate(group *gin.RouterGroup, role auth.Role, routes ...string) {
+	routeRolesMu.Lock()
+	defer routeRolesMu.Unlock()
+	for _, route := range routes {
+		method, path, ok := strings.Cut(route, " ")
+		if !ok {
+			panic(fmt.Sprintf("handlers: route %q is not METHOD /path", route))
+		}
+		full := group.BasePath()
+		if path != "" {
+			full = strings.TrimSuffix(full, "/") + path
+		}
+		routeRoles[method+" "+full] = role
+	}
+}
+
+// RouteRole returns the role requests to a route require: its annotation,
+// else admin under /api/admin/ and /debug/, viewer to read and admin to change
+func RouteRole(method, fullPath string) auth.Role {
+	routeRolesMu.RLock()
+	role, ok := routeRoles[method+" "+fullPath]
+	routeRolesMu.RUnlock()
+	if ok {
+		return role
+	}
+	if strings.HasPrefix(fullPath, "/api/admin/") || strings.HasPrefix(fullPath, "/debug/") {
+		return auth.RoleAdmin
+	}
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return auth.RoleViewer
+	}
+	return auth.RoleAdmin
+}
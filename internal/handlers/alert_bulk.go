@@ -0,0 +1,261 @@
+// File: internal/handlers/alert_bulk.go
+// Brief: Alert clone and bulk edit endpoints
+// Detailed: Serves POST /api/alerts/:id/clone, which copies an alert under a new ID with optional overrides, and PATCH /api/alerts/bulk, which applies one partial edit, such as raising every warning CPU threshold by 5%, to the alerts selected by ID and/or a label selector. Overrides and edits are JSON objects laid over the stored alert, so threshold, labels and remediation are merged field by field. Every edited alert is validated before any of them is stored.
+// Author: drama.lin@aver.com
+// Date: 2026-10-14
+
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"argus/internal/database"
+	"argus/internal/i18n"
+	"argus/internal/models"
+)
+
+// cloneNameSuffix is appended to the name of a clone given no name
+const cloneNameSuffix = " (copy)"
+
+// alertBulkRequest is the body of PATCH /alerts/bulk. The edited alerts are
+// those listed in IDs and matching Match; at least one of them is required.
+type alertBulkRequest struct {
+	IDs   []string        `json:"ids"`
+	Match string          `json:"match"` // Selector over the alert labels, e.g. {severity="warning",metric_type="cpu"}
+	Set   json.RawMessage `json:"set"`   // Fields to change, e.g. {"enabled": false} or {"threshold": {"duration": 300000000000}}
+	// ThresholdPercent raises numeric threshold values by this percentage, or
+	// lowers them when negative
+	ThresholdPercent float64 `json:"threshold_percent"`
+}
+
+// checkAlert validates an alert about to be stored, returning the message of
+// the response with the error
+func (h *AlertsHandler) checkAlert(alert *models.AlertConfig) (i18n.MessageKey, error) {
+	if err := alert.Validate(); err != nil {
+		return i18n.MsgAlertInvalidConfig, err
+	}
+	if err := h.validateGroup(alert); err != nil {
+		return i18n.MsgAlertInvalidConfig, err
+	}
+	for i := range alert.Notifications {
+		if err := alert.Notifications[i].Validate(); err != nil {
+			return i18n.MsgNotificationInvalid, err
+		}
+	}
+	return "", nil
+}
+
+// CloneAlert copies an alert under a new ID. The optional body overrides
+// fields of the copy, e.g. {"name": "High CPU (db)", "threshold": {"value": 85}};
+// without a name the copy is named after the source with cloneNameSuffix.
+// Like a create, ?preview=true validates the copy and previews its impact
+// without saving it.
+func (h *AlertsHandler) CloneAlert(c *gin.Context) {
+	id := c.Param("id")
+	slog.Debug("Cloning alert configuration", "id", id)
+
+	source, err := h.alertStore.GetAlert(id)
+	if err != nil {
+		if err == database.ErrAlertNotFound {
+			slog.Debug("Alert not found for cloning", "id", id)
+			c.JSON(http.StatusNotFound, models.APIResponse{Success: false, Error: i18n.T(locale(c), i18n.MsgAlertNotFound)})
+			return
+		}
+		slog.Error("Failed to get alert for cloning", "id", id, "error", err)
+		c.JSON(http.StatusInternalServerError, models.APIResponse{Success: false, Error: i18n.T(locale(c), i18n.MsgAlertGetFailed, err)})
+		return
+	}
+
+	alert := *source
+	body, err := c.GetRawData()
+	if err == nil && len(bytes.TrimSpace(body)) > 0 {
+		err = alert.Overlay(body)
+	}
+	if err != nil {
+		slog.Debug("Invalid alert clone data", "error", err)
+		c.JSON(http.StatusBadRequest, models.APIResponse{Success: false, Error: i18n.T(locale(c), i18n.MsgAlertInvalidConfig, err)})
+		return
+	}
+
+	if alert.ID == "" || alert.ID == source.ID {
+		alert.ID = uuid.New().String()
+	}
+	if alert.Name == source.Name {
+		alert.Name = source.Name + cloneNameSuffix
+	}
+	now := time.Now()
+	alert.CreatedAt = now
+	alert.UpdatedAt = now
+
+	if msg, err := h.checkAlert(&alert); err != nil {
+		slog.Debug("Invalid alert clone", "error", err)
+		c.JSON(http.StatusBadRequest, models.APIResponse{Success: false, Error: i18n.T(locale(c), msg, err)})
+		return
+	}
+
+	if previewRequested(c) {
+		h.previewImpact(c, &alert)
+		return
+	}
+
+	if err := h.alertStore.CreateAlert(&alert); err != nil {
+		slog.Error("Failed to create alert clone", "source", id, "error", err)
+		c.JSON(http.StatusInternalServerError, models.APIResponse{Success: false, Error: i18n.T(locale(c), i18n.MsgAlertCreateFailed, err)})
+		return
+	}
+
+	slog.Info("Alert cloned successfully", "source", id, "id", alert.ID, "name", alert.Name)
+	c.JSON(http.StatusCreated, models.APIResponse{Success: true, Data: alert})
+}
+
+// BulkUpdateAlerts applies one partial edit to several alerts, e.g.
+// {"match": "{severity=\"warning\",metric_type=\"cpu\"}", "threshold_percent": 5}.
+// Nothing is stored unless every edited alert is valid. With ?dry_run=true
+// the edited alerts are returned without being stored.
+func (h *AlertsHandler) BulkUpdateAlerts(c *gin.Context) {
+	var req alertBulkRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		slog.Debug("Invalid bulk alert edit", "error", err)
+		c.JSON(http.StatusBadRequest, models.APIResponse{Success: false, Error: i18n.T(locale(c), i18n.MsgAlertBulkInvalid, err)})
+		return
+	}
+	dryRun, _ := strconv.ParseBool(c.Query("dry_run"))
+
+	matchers, err := req.validate()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{Success: false, Error: i18n.T(locale(c), i18n.MsgAlertBulkInvalid, err)})
+		return
+	}
+
+	alerts, err := h.alertStore.ListAlerts()
+	if err != nil {
+		slog.Error("Failed to list alerts", "error", err)
+		c.JSON(http.StatusInternalServerError, models.APIResponse{Success: false, Error: i18n.T(locale(c), i18n.MsgAlertListFailed, err)})
+		return
+	}
+	selected, err := req.selectAlerts(alerts, matchers)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{Success: false, Error: i18n.T(locale(c), i18n.MsgAlertBulkInvalid, err)})
+		return
+	}
+
+	now := time.Now()
+	changed := []*models.AlertConfig{}
+	for _, alert := range selected {
+		before, _ := json.Marshal(alert)
+		if err := req.apply(alert); err != nil {
+			c.JSON(http.StatusBadRequest, models.APIResponse{Success: false, Error: i18n.T(locale(c), i18n.MsgAlertBulkInvalid, fmt.Errorf("alert %s: %w", alert.ID, err))})
+			return
+		}
+		if after, _ := json.Marshal(alert); bytes.Equal(before, after) {
+			continue
+		}
+		alert.UpdatedAt = now
+		if msg, err := h.checkAlert(alert); err != nil {
+			slog.Debug("Invalid bulk alert edit", "id", alert.ID, "error", err)
+			c.JSON(http.StatusBadRequest, models.APIResponse{Success: false, Error: i18n.T(locale(c), msg, fmt.Errorf("alert %s: %w", alert.ID, err))})
+			return
+		}
+		changed = append(changed, alert)
+	}
+
+	if !dryRun {
+		for i, alert := range changed {
+			if err := h.alertStore.UpdateAlert(alert); err != nil {
+				slog.Error("Failed to update alert", "id", alert.ID, "updated", i, "error", err)
+				c.JSON(http.StatusInternalServerError, models.APIResponse{Success: false, Error: i18n.T(locale(c), i18n.MsgAlertUpdateFailed, err)})
+				return
+			}
+		}
+		slog.Info("Alerts updated in bulk", "matched", len(selected), "updated", len(changed))
+	}
+	c.JSON(http.StatusOK, models.APIResponse{Success: true, Data: gin.H{
+		"matched": len(selected),
+		"updated": len(changed),
+		"dry_run": dryRun,
+		"alerts":  changed,
+	}})
+}
+
+// validate checks the request and parses its selector
+func (r *alertBulkRequest) validate() ([]models.Matcher, error) {
+	if len(r.IDs) == 0 && r.Match == "" {
+		return nil, errors.New("ids or match is required")
+	}
+	if len(r.Set) == 0 && r.ThresholdPercent == 0 {
+		return nil, errors.New("set or threshold_percent is required")
+	}
+	if r.ThresholdPercent <= -100 {
+		return nil, fmt.Errorf("threshold_percent must be greater than -100, got %g", r.ThresholdPercent)
+	}
+	if r.Match == "" {
+		return nil, nil
+	}
+	matchers, err := models.ParseMatchers(r.Match)
+	if err != nil {
+		return nil, fmt.Errorf("invalid match: %w", err)
+	}
+	return matchers, nil
+}
+
+// selectAlerts returns the alerts the request edits. Listed IDs must exist.
+func (r *alertBulkRequest) selectAlerts(alerts []*models.AlertConfig, matchers []models.Matcher) ([]*models.AlertConfig, error) {
+	byID := make(map[string]*models.AlertConfig, len(alerts))
+	for _, alert := range alerts {
+		byID[alert.ID] = alert
+	}
+	if len(r.IDs) > 0 {
+		alerts = make([]*models.AlertConfig, 0, len(r.IDs))
+		seen := make(map[string]bool, len(r.IDs))
+		for _, id := range r.IDs {
+			alert, ok := byID[id]
+			if !ok {
+				return nil, fmt.Errorf("alert not found: %s", id)
+			}
+			if !seen[id] {
+				seen[id] = true
+				alerts = append(alerts, alert)
+			}
+		}
+	}
+
+	var selected []*models.AlertConfig
+	for _, alert := range alerts {
+		if models.MatchAll(matchers, alert.LabelSet()) {
+			selected = append(selected, alert)
+		}
+	}
+	return selected, nil
+}
+
+// apply edits an alert; its ID and creation time are kept
+func (r *alertBulkRequest) apply(alert *models.AlertConfig) error {
+	id, createdAt := alert.ID, alert.CreatedAt
+	if len(r.Set) > 0 {
+		if err := alert.Overlay(r.Set); err != nil {
+			return fmt.Errorf("invalid set: %w", err)
+		}
+	}
+	alert.ID, alert.CreatedAt = id, createdAt
+
+	if r.ThresholdPercent != 0 {
+		if alert.Threshold.StringMetric() {
+			return fmt.Errorf("threshold_percent cannot change the string threshold of %s", alert.Threshold.MetricName)
+		}
+		// Rounded to hide floating point noise such as 84.00000000000001
+		value := alert.Threshold.Value * (1 + r.ThresholdPercent/100)
+		alert.Threshold.Value = math.Round(value*1e6) / 1e6
+	}
+	return nil
+}
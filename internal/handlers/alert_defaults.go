@@ -0,0 +1,107 @@
+// File: internal/handlers/alert_defaults.go
+// Brief: Default alert endpoints
+// Detailed: Serves GET /api/alerts/defaults, which lists the standard CPU, memory and disk alerts by template name, and POST /api/alerts/defaults, which creates them. Alerts already stored under a default's ID are left as they are, so the defaults can be requested again, e.g. by every host of a fleet, without duplicating or resetting them.
+// Author: drama.lin@aver.com
+// Date: 2026-10-14
+
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"argus/internal/database"
+	"argus/internal/i18n"
+	"argus/internal/models"
+)
+
+// alertDefaultsRequest is the optional body of POST /alerts/defaults
+type alertDefaultsRequest struct {
+	Templates []string `json:"templates"` // Names of the defaults to create (empty = all)
+}
+
+// defaultAlertItem is a default alert with its template name
+type defaultAlertItem struct {
+	Template string `json:"template"`
+	*models.AlertConfig
+}
+
+// ListDefaultAlerts returns the default alerts as they would be created
+func (h *AlertsHandler) ListDefaultAlerts(c *gin.Context) {
+	now := time.Now()
+	items := make([]defaultAlertItem, 0, len(models.DefaultAlertNames))
+	for _, name := range models.DefaultAlertNames {
+		alert, _ := models.CreateDefaultAlertConfig(name, now)
+		items = append(items, defaultAlertItem{Template: name, AlertConfig: alert})
+	}
+	c.JSON(http.StatusOK, models.APIResponse{Success: true, Data: items})
+}
+
+// CreateDefaultAlerts creates the default alerts, or those listed in
+// {"templates": ["cpu", "disk"]}, that are not stored yet. It answers 201
+// when it created any, listing the created alerts and the IDs of the
+// defaults that already existed.
+func (h *AlertsHandler) CreateDefaultAlerts(c *gin.Context) {
+	req := alertDefaultsRequest{}
+	body, err := c.GetRawData()
+	if err == nil && len(bytes.TrimSpace(body)) > 0 {
+		err = json.Unmarshal(body, &req)
+	}
+	if err != nil {
+		slog.Debug("Invalid default alerts request", "error", err)
+		c.JSON(http.StatusBadRequest, models.APIResponse{Success: false, Error: i18n.T(locale(c), i18n.MsgAlertDefaultsInvalid, err)})
+		return
+	}
+	if len(req.Templates) == 0 {
+		req.Templates = models.DefaultAlertNames
+	}
+
+	now := time.Now()
+	created, existing := []*models.AlertConfig{}, []string{}
+	var missing []*models.AlertConfig
+	seen := make(map[string]bool, len(req.Templates))
+	for _, name := range req.Templates {
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+		alert, err := models.CreateDefaultAlertConfig(name, now)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, models.APIResponse{Success: false, Error: i18n.T(locale(c), i18n.MsgAlertDefaultsInvalid, err)})
+			return
+		}
+		if _, err := h.alertStore.GetAlert(alert.ID); err == nil {
+			existing = append(existing, alert.ID)
+			continue
+		} else if err != database.ErrAlertNotFound {
+			slog.Error("Failed to get alert", "id", alert.ID, "error", err)
+			c.JSON(http.StatusInternalServerError, models.APIResponse{Success: false, Error: i18n.T(locale(c), i18n.MsgAlertGetFailed, err)})
+			return
+		}
+		missing = append(missing, alert)
+	}
+
+	for _, alert := range missing {
+		if err := h.alertStore.CreateAlert(alert); err != nil {
+			slog.Error("Failed to create default alert", "id", alert.ID, "error", err)
+			c.JSON(http.StatusInternalServerError, models.APIResponse{Success: false, Error: i18n.T(locale(c), i18n.MsgAlertCreateFailed, err)})
+			return
+		}
+		created = append(created, alert)
+		slog.Info("Default alert created", "id", alert.ID, "name", alert.Name)
+	}
+
+	status := http.StatusOK
+	if len(created) > 0 {
+		status = http.StatusCreated
+	}
+	c.JSON(status, models.APIResponse{Success: true, Data: gin.H{
+		"created":  created,
+		"existing": existing,
+	}})
+}
@@ -0,0 +1,416 @@
+// File: internal/handlers/alertmanager.go
+// Brief: Alertmanager-compatible subset of the v2 API
+// Detailed: Serves /api/v2/alerts, /api/v2/silences and /api/v2/status in the Alertmanager wire format so amtool, Grafana's Alertmanager datasource and Prometheus can list Argus alerts, push their own alerts and manage silences.
+// Author: drama.lin@aver.com
+// Date: 2026-10-14
+
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"argus/internal/auth"
+	"argus/internal/database"
+	"argus/internal/models"
+	"argus/internal/services"
+)
+
+// alertmanagerReceiver is the single receiver name reported for every alert
+const alertmanagerReceiver = "argus"
+
+// AlertmanagerHandler manages the Alertmanager-compatible endpoints
+type AlertmanagerHandler struct {
+	alertStore database.AlertStore
+	evaluator  *services.Evaluator
+	external   *services.ExternalAlerts
+	silences   *database.SilenceStore
+	startedAt  time.Time
+}
+
+// NewAlertmanagerHandler creates a handler listing the evaluator's alerts and
+// the external alerts, and managing silences in the silence store
+func NewAlertmanagerHandler(alertStore database.AlertStore, evaluator *services.Evaluator, external *services.ExternalAlerts, silences *database.SilenceStore) *AlertmanagerHandler {
+	return &AlertmanagerHandler{
+		alertStore: alertStore,
+		evaluator:  evaluator,
+		external:   external,
+		silences:   silences,
+		startedAt:  time.Now().UTC(),
+	}
+}
+
+// RegisterRoutes registers the Alertmanager routes to the given router group
+func (h *AlertmanagerHandler) RegisterRoutes(router *gin.RouterGroup) {
+	group := router.Group("/v2")
+	{
+		group.GET("/status", h.GetStatus)
+		group.GET("/receivers", h.ListReceivers)
+		group.GET("/alerts", h.ListAlerts)
+		group.POST("/alerts", h.PostAlerts)
+		group.GET("/silences", h.ListSilences)
+		group.POST("/silences", h.PostSilence)
+		group.GET("/silence/:id", h.GetSilence)
+		group.DELETE("/silence/:id", h.DeleteSilence)
+	}
+	// Prometheus pushes its alerts with an operator's key
+	Annotate(group, auth.RoleOperator, "POST /alerts", "POST /silences", "DELETE /silence/:id")
+}
+
+// amError writes an error in the Alertmanager format, which is a bare JSON string
+func amError(c *gin.Context, status int, message string) {
+	c.JSON(status, message)
+}
+
+type amReceiver struct {
+	Name string `json:"name"`
+}
+
+type amAlertStatus struct {
+	State       string   `json:"state"`
+	SilencedBy  []string `json:"silencedBy"`
+	InhibitedBy []string `json:"inhibitedBy"`
+}
+
+type amAlert struct {
+	Labels       map[string]string `json:"labels"`
+	Annotations  map[string]string `json:"annotations"`
+	StartsAt     time.Time         `json:"startsAt"`
+	EndsAt       time.Time         `json:"endsAt"`
+	UpdatedAt    time.Time         `json:"updatedAt"`
+	Fingerprint  string            `json:"fingerprint"`
+	GeneratorURL string            `json:"generatorURL"`
+	Receivers    []amReceiver      `json:"receivers"`
+	Status       amAlertStatus     `json:"status"`
+	Source       string            `json:"source,omitempty"` // Argus extension: where the alert originated
+}
+
+type amPostableAlert struct {
+	Labels       map[string]string `json:"labels" binding:"required"`
+	Annotations  map[string]string `json:"annotations"`
+	StartsAt     time.Time         `json:"startsAt"`
+	EndsAt       time.Time         `json:"endsAt"`
+	GeneratorURL string            `json:"generatorURL"`
+}
+
+type amSilenceStatus struct {
+	State models.SilenceState `json:"state"`
+}
+
+type amSilence struct {
+	*models.Silence
+	Status amSilenceStatus `json:"status"`
+}
+
+// GetStatus returns a minimal Alertmanager status, enough for client health checks
+func (h *AlertmanagerHandler) GetStatus(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"cluster":     gin.H{"status": "disabled", "peers": []string{}},
+		"config":      gin.H{"original": ""},
+		"uptime":      h.startedAt,
+		"versionInfo": gin.H{"version": "argus", "branch": "", "revision": "", "buildUser": "", "buildDate": "", "goVersion": ""},
+	})
+}
+
+// ListReceivers returns the single Argus receiver
+func (h *AlertmanagerHandler) ListReceivers(c *gin.Context) {
+	c.JSON(http.StatusOK, []amReceiver{{Name: alertmanagerReceiver}})
+}
+
+// queryBool parses an optional boolean query parameter
+func queryBool(c *gin.Context, name string, def bool) (bool, error) {
+	value, ok := c.GetQuery(name)
+	if !ok || value == "" {
+		return def, nil
+	}
+	return strconv.ParseBool(value)
+}
+
+// ListAlerts returns firing Argus alerts and pushed alerts. Supports the
+// Alertmanager filter (repeatable matcher), active and silenced parameters.
+func (h *AlertmanagerHandler) ListAlerts(c *gin.Context) {
+	var matchers []models.Matcher
+	for _, filter := range c.QueryArray("filter") {
+		parsed, err := models.ParseMatchers(filter)
+		if err != nil {
+			amError(c, http.StatusBadRequest, err.Error())
+			return
+		}
+		matchers = append(matchers, parsed...)
+	}
+	showActive, err := queryBool(c, "active", true)
+	if err != nil {
+		amError(c, http.StatusBadRequest, "invalid active parameter: "+err.Error())
+		return
+	}
+	showSilenced, err := queryBool(c, "silenced", true)
+	if err != nil {
+		amError(c, http.StatusBadRequest, "invalid silenced parameter: "+err.Error())
+		return
+	}
+	h.listAlerts(c, matchers, showActive, showSilenced)
+}
+
+func (h *AlertmanagerHandler) listAlerts(c *gin.Context, matchers []models.Matcher, showActive, showSilenced bool) {
+	alerts, err := h.firingAlerts(matchers, time.Now().UTC())
+	if err != nil {
+		amError(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+	result := []amAlert{}
+	for _, alert := range alerts {
+		if (alert.Status.State == "active" && showActive) || (alert.Status.State == "suppressed" && showSilenced) {
+			result = append(result, alert)
+		}
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+// firingAlerts returns the firing evaluator and external alerts whose labels
+// satisfy matchers, with the silences muting them at now
+func (h *AlertmanagerHandler) firingAlerts(matchers []models.Matcher, now time.Time) ([]amAlert, error) {
+	result := []amAlert{}
+	add := func(alert amAlert) {
+		if !models.MatchAll(matchers, alert.Labels) {
+			return
+		}
+		alert.Receivers = []amReceiver{{Name: alertmanagerReceiver}}
+		alert.Status = amAlertStatus{State: "active", SilencedBy: []string{}, InhibitedBy: []string{}}
+		if ids := h.silences.Silenced(alert.Labels, now); len(ids) > 0 {
+			alert.Status.State = "suppressed"
+			alert.Status.SilencedBy = ids
+		}
+		result = append(result, alert)
+	}
+
+	configs, err := h.alertStore.ListAlerts()
+	if err != nil {
+		return nil, err
+	}
+	for _, config := range configs {
+		status, ok := h.evaluator.GetAlertStatus(config.ID)
+		if !ok || !status.State.Firing() {
+			continue
+		}
+		labels := config.LabelSet()
+		alert := amAlert{
+			Labels:      labels,
+			Annotations: map[string]string{},
+			UpdatedAt:   now,
+			Fingerprint: models.Fingerprint(labels),
+			Source:      models.SourceLocal,
+		}
+		if config.Description != "" {
+			alert.Annotations["description"] = config.Description
+		}
+		if status.Message != "" {
+			alert.Annotations["summary"] = status.Message
+		}
+		for name, value := range map[string]string{"owner": config.Owner, "team": config.Team, "contact": config.Contact} {
+			if value != "" {
+				alert.Annotations[name] = value
+			}
+		}
+		if status.TriggeredAt != nil {
+			alert.StartsAt = *status.TriggeredAt
+		}
+		add(alert)
+	}
+
+	for _, external := range h.external.List() {
+		if !external.Firing(now) {
+			continue
+		}
+		annotations := external.Annotations
+		if annotations == nil {
+			annotations = map[string]string{}
+		}
+		add(amAlert{
+			Labels:       external.Labels,
+			Annotations:  annotations,
+			StartsAt:     external.StartsAt,
+			EndsAt:       external.EndsAt,
+			UpdatedAt:    external.UpdatedAt,
+			Fingerprint:  external.Fingerprint,
+			GeneratorURL: external.GeneratorURL,
+			Source:       external.Source,
+		})
+	}
+	return result, nil
+}
+
+// PostAlerts accepts alerts pushed by Prometheus or other Alertmanager clients.
+// Their source names the client from its User-Agent, e.g. "external:prometheus".
+func (h *AlertmanagerHandler) PostAlerts(c *gin.Context) {
+	var posted []amPostableAlert
+	if err := c.ShouldBindJSON(&posted); err != nil {
+		amError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	source := models.ExternalSource(pushingSystem(c.Request.UserAgent()))
+	alerts := make([]services.ExternalAlert, 0, len(posted))
+	for i, p := range posted {
+		if p.Labels[models.LabelAlertName] == "" {
+			amError(c, http.StatusBadRequest, "alert "+strconv.Itoa(i)+": missing alertname label")
+			return
+		}
+		if !p.StartsAt.IsZero() && !p.EndsAt.IsZero() && p.EndsAt.Before(p.StartsAt) {
+			amError(c, http.StatusBadRequest, "alert "+strconv.Itoa(i)+": endsAt must not be before startsAt")
+			return
+		}
+		alerts = append(alerts, services.ExternalAlert{
+			Labels:       p.Labels,
+			Annotations:  p.Annotations,
+			StartsAt:     p.StartsAt,
+			EndsAt:       p.EndsAt,
+			GeneratorURL: p.GeneratorURL,
+			Source:       source,
+		})
+	}
+	h.external.Receive(alerts)
+	c.Status(http.StatusOK)
+}
+
+// pushingSystem names the client pushing alerts from the product of its
+// User-Agent, e.g. "prometheus" for "Prometheus/2.48.0", or
+// services.ExternalAlertSystem for generic HTTP libraries
+func pushingSystem(userAgent string) string {
+	product, _, _ := strings.Cut(strings.TrimSpace(userAgent), "/")
+	product = strings.ToLower(strings.TrimSpace(product))
+	if product == "" || strings.ContainsAny(product, " ()") || product == "go-http-client" {
+		return services.ExternalAlertSystem
+	}
+	return product
+}
+
+func silenceView(silence *models.Silence, now time.Time) amSilence {
+	return amSilence{Silence: silence, Status: amSilenceStatus{State: silence.State(now)}}
+}
+
+// ListSilences returns every silence, newest first. A silence matches the
+// filter when it has a matcher equal to each filter matcher.
+func (h *AlertmanagerHandler) ListSilences(c *gin.Context) {
+	var filters []models.Matcher
+	for _, filter := range c.QueryArray("filter") {
+		parsed, err := models.ParseMatchers(filter)
+		if err != nil {
+			amError(c, http.StatusBadRequest, err.Error())
+			return
+		}
+		filters = append(filters, parsed...)
+	}
+
+	now := time.Now().UTC()
+	result := []amSilence{}
+	for _, silence := range h.silences.List() {
+		if hasMatchers(silence.Matchers, filters) {
+			result = append(result, silenceView(silence, now))
+		}
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+// hasMatchers reports whether every filter appears among matchers
+func hasMatchers(matchers, filters []models.Matcher) bool {
+	for _, f := range filters {
+		found := false
+		for _, m := range matchers {
+			if m.String() == f.String() {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// PostSilence creates a silence, or updates it when the body carries an ID.
+// With ?preview=true it saves nothing and returns the firing alerts the
+// matchers select instead.
+func (h *AlertmanagerHandler) PostSilence(c *gin.Context) {
+	var silence models.Silence
+	if err := c.ShouldBindJSON(&silence); err != nil {
+		amError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	if preview, _ := strconv.ParseBool(c.Query("preview")); preview {
+		h.previewSilence(c, silence)
+		return
+	}
+	if silence.ID != "" {
+		if _, err := h.silences.Get(silence.ID); err != nil {
+			amError(c, http.StatusNotFound, err.Error())
+			return
+		}
+	}
+	if silence.StartsAt.IsZero() {
+		silence.StartsAt = time.Now().UTC()
+	}
+	if err := h.silences.Save(&silence); err != nil {
+		amError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"silenceID": silence.ID})
+}
+
+// silencePreview lists the firing alerts a silence would match. Muted tells
+// whether the silence's time window covers now, so the alerts are muted as
+// soon as it is saved.
+type silencePreview struct {
+	Muted  bool      `json:"muted"`
+	Alerts []amAlert `json:"alerts"`
+}
+
+// previewSilence validates the matchers of a silence and returns the firing
+// alerts they select, whether or not other silences already mute them
+func (h *AlertmanagerHandler) previewSilence(c *gin.Context, silence models.Silence) {
+	if len(silence.Matchers) == 0 {
+		amError(c, http.StatusBadRequest, "silence requires at least one matcher")
+		return
+	}
+	for i := range silence.Matchers {
+		if err := silence.Matchers[i].Validate(); err != nil {
+			amError(c, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+	now := time.Now().UTC()
+	alerts, err := h.firingAlerts(silence.Matchers, now)
+	if err != nil {
+		amError(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+	muted := !silence.StartsAt.After(now) && (silence.EndsAt.IsZero() || silence.EndsAt.After(now))
+	c.JSON(http.StatusOK, silencePreview{Muted: muted, Alerts: alerts})
+}
+
+// GetSilence returns one silence
+func (h *AlertmanagerHandler) GetSilence(c *gin.Context) {
+	silence, err := h.silences.Get(c.Param("id"))
+	if err != nil {
+		amError(c, http.StatusNotFound, err.Error())
+		return
+	}
+	c.JSON(http.StatusOK, silenceView(silence, time.Now().UTC()))
+}
+
+// DeleteSilence expires a silence; it stays listed until the retention period ends
+func (h *AlertmanagerHandler) DeleteSilence(c *gin.Context) {
+	if err := h.silences.Expire(c.Param("id")); err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, database.ErrSilenceNotFound) {
+			status = http.StatusNotFound
+		}
+		amError(c, status, err.Error())
+		return
+	}
+	c.Status(http.StatusOK)
+}
@@ -0,0 +1,100 @@
+package handlers_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"argus/internal/clock"
+	"argus/internal/database"
+	"argus/internal/handlers"
+	"argus/internal/metrics"
+	"argus/internal/models"
+	"argus/internal/services"
+)
+
+// startFiringEvaluator starts an evaluator over alertStore and runs it until
+// the alerts with the given IDs fire. They must be series alerts on
+// "test_metric" breached by a value of 95.
+func startFiringEvaluator(t *testing.T, alertStore database.AlertStore, ids ...string) *services.Evaluator {
+	clk := clock.NewFake(time.Now())
+	series := metrics.NewSeriesStore(metrics.SeriesStoreConfig{Retention: time.Hour, Clock: clk})
+	config := services.DefaultEvaluatorConfig()
+	config.Clock = clk
+	evaluator := services.NewEvaluator(alertStore, config)
+	evaluator.SetSeriesStore(series)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	require.NoError(t, evaluator.Start(ctx))
+	t.Cleanup(func() {
+		cancel()
+		evaluator.Stop()
+	})
+	clk.BlockUntil(1)
+
+	require.Eventually(t, func() bool {
+		clk.Advance(config.EvaluationInterval)
+		series.Append("test_metric", nil, []metrics.Sample{{Timestamp: clk.Now(), Value: 95}})
+		for _, id := range ids {
+			if status, ok := evaluator.GetAlertStatus(id); !ok || !status.State.Firing() {
+				return false
+			}
+		}
+		return true
+	}, 5*time.Second, time.Millisecond)
+	return evaluator
+}
+
+// seriesAlert returns an enabled alert firing while test_metric exceeds 90
+func seriesAlert(id, name string, severity models.AlertSeverity) *models.AlertConfig {
+	return &models.AlertConfig{
+		ID:       id,
+		Name:     name,
+		Enabled:  true,
+		Severity: severity,
+		Threshold: models.ThresholdConfig{
+			MetricType: models.MetricSeries,
+			MetricName: "test_metric",
+			Operator:   models.OperatorGreaterThan,
+			Value:      90,
+		},
+	}
+}
+
+func TestAlertmanagerAPI_ListsPendingAlerts(t *testing.T) {
+	alertStore, err := database.NewAlertStore(t.TempDir())
+	require.NoError(t, err)
+	require.NoError(t, alertStore.CreateAlert(seriesAlert("high-metric", "High Metric", models.SeverityCritical)))
+
+	evaluator := startFiringEvaluator(t, alertStore, "high-metric")
+	status, _ := evaluator.GetAlertStatus("high-metric")
+	require.Equal(t, models.StatePending, status.State, "the evaluator marks breaching alerts pending")
+
+	silences, err := database.NewSilenceStore(t.TempDir())
+	require.NoError(t, err)
+	notifier := services.NewNotifier(services.DefaultConfig())
+	router := setupRouter()
+	handlers.NewAlertmanagerHandler(alertStore, evaluator, services.NewExternalAlerts(notifier, nil), silences).RegisterRoutes(router.Group("/api"))
+
+	req, _ := http.NewRequest(http.MethodGet, "/api/v2/alerts", nil)
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+	require.Equal(t, http.StatusOK, rr.Code)
+
+	var alerts []struct {
+		Labels map[string]string `json:"labels"`
+		Status struct {
+			State string `json:"state"`
+		} `json:"status"`
+	}
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &alerts))
+	require.Len(t, alerts, 1)
+	assert.Equal(t, "High Metric", alerts[0].Labels[models.LabelAlertName])
+	assert.Equal(t, "active", alerts[0].Status.State)
+}
@@ -2,27 +2,45 @@
 package handlers
 
 import (
+	"errors"
+	"fmt"
 	"log/slog"
 	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 
+	"argus/internal/auth"
 	"argus/internal/database"
+	"argus/internal/i18n"
 	"argus/internal/models"
 	"argus/internal/services"
+	"argus/internal/tmplfunc"
+)
+
+// DefaultAlertHistoryLimit is the number of history entries returned when no limit is given
+const DefaultAlertHistoryLimit = 50
+
+// Window of metrics history replayed by ?preview=true, in hours
+const (
+	DefaultPreviewHours = 24
+	MaxPreviewHours     = 7 * 24
 )
 
 // AlertsHandler manages alert-related API endpoints
 type AlertsHandler struct {
-	alertStore *database.AlertStore
+	alertStore database.AlertStore
 	evaluator  *services.Evaluator
 	notifier   *services.Notifier
+	groups     *database.GroupStore
+	external   *services.ExternalAlerts
+	remediator *services.Remediator
 }
 
 // NewAlertsHandler creates a new alerts API handler
-func NewAlertsHandler(alertStore *database.AlertStore, evaluator *services.Evaluator, notifier *services.Notifier) *AlertsHandler {
+func NewAlertsHandler(alertStore database.AlertStore, evaluator *services.Evaluator, notifier *services.Notifier) *AlertsHandler {
 	return &AlertsHandler{
 		alertStore: alertStore,
 		evaluator:  evaluator,
@@ -30,6 +48,12 @@ func NewAlertsHandler(alertStore *database.AlertStore, evaluator *services.Evalu
 	}
 }
 
+// SetGroupStore enables alert groups: alert lists include each alert's group
+// and can be filtered by it, and alerts may only reference existing groups
+func (h *AlertsHandler) SetGroupStore(groups *database.GroupStore) {
+	h.groups = groups
+}
+
 // RegisterRoutes registers all alert-related routes to the given router group
 func (h *AlertsHandler) RegisterRoutes(router *gin.RouterGroup) {
 	alerts := router.Group("/alerts")
@@ -40,10 +64,16 @@ func (h *AlertsHandler) RegisterRoutes(router *gin.RouterGroup) {
 		alerts.POST("", h.CreateAlert)
 		alerts.PUT("/:id", h.UpdateAlert)
 		alerts.DELETE("/:id", h.DeleteAlert)
+		alerts.GET("/:id/history", h.GetAlertHistory)
+		alerts.POST("/:id/clone", h.CloneAlert)
+		alerts.PATCH("/bulk", h.BulkUpdateAlerts)
+		alerts.GET("/defaults", h.ListDefaultAlerts)
+		alerts.POST("/defaults", h.CreateDefaultAlerts)
 
 		// Alert status endpoints
 		alerts.GET("/status", h.GetAllAlertStatus)
 		alerts.GET("/status/:id", h.GetAlertStatus)
+		alerts.GET("/summary", h.GetAlertSummary)
 
 		// Notification endpoints
 		alerts.GET("/notifications", h.GetNotifications)
@@ -51,24 +81,98 @@ func (h *AlertsHandler) RegisterRoutes(router *gin.RouterGroup) {
 		alerts.POST("/notifications/read-all", h.MarkAllNotificationsRead)
 		alerts.DELETE("/notifications", h.ClearNotifications)
 
+		// Delivery health endpoints
+		alerts.GET("/channels", h.GetChannelStatus)
+		alerts.GET("/dead-letters", h.ListDeadLetters)
+		alerts.POST("/dead-letters/:id/retry", h.RetryDeadLetter)
+		alerts.DELETE("/dead-letters", h.ClearDeadLetters)
+
+		// Audit log of the remediation tasks run for activated alerts
+		alerts.GET("/remediations", h.ListRemediations)
+
 		// Test endpoint
 		alerts.POST("/test/:id", h.TestAlert)
+		alerts.POST("/:id/simulate", h.SimulateAlert)
+		alerts.POST("/:id/ack", h.AcknowledgeAlert)
 	}
+	Annotate(alerts, auth.RoleViewer, "POST /notifications/:id/read", "POST /notifications/read-all", "POST /:id/simulate")
+	Annotate(alerts, auth.RoleOperator, "POST /:id/ack", "POST /test/:id", "DELETE /notifications", "POST /dead-letters/:id/retry")
+
+	// Status badge for embedding in wikis and READMEs
+	router.GET("/badge.svg", h.GetBadge)
+
+	router.GET("/notifications/template-helpers", h.GetTemplateHelpers)
+	router.POST("/notifications/preview", h.PreviewNotification)
+	Annotate(router, auth.RoleViewer, "POST /notifications/preview")
+}
+
+// locale returns the negotiated locale for API messages in this request
+func locale(c *gin.Context) i18n.Locale {
+	return i18n.FromRequest(c.Request)
 }
 
-// ListAlerts returns all alert configurations
+// alertListItem is an alert with the metadata of its group
+type alertListItem struct {
+	*models.AlertConfig
+	Group *models.AlertGroup `json:"group,omitempty"`
+}
+
+// ListAlerts returns all alert configurations, optionally only those of an
+// ?owner= and/or ?team=. With groups enabled each alert carries its group, and
+// ?group= keeps only the alerts of one group ("none" for ungrouped alerts).
 func (h *AlertsHandler) ListAlerts(c *gin.Context) {
 	slog.Debug("Fetching all alert configurations")
 
 	alerts, err := h.alertStore.ListAlerts()
 	if err != nil {
 		slog.Error("Failed to list alerts", "error", err)
-		c.JSON(http.StatusInternalServerError, models.APIResponse{Success: false, Error: "Failed to list alerts: " + err.Error()})
+		c.JSON(http.StatusInternalServerError, models.APIResponse{Success: false, Error: i18n.T(locale(c), i18n.MsgAlertListFailed, err)})
 		return
 	}
 
+	if owner, team := c.Query("owner"), c.Query("team"); owner != "" || team != "" {
+		owned := alerts[:0]
+		for _, alert := range alerts {
+			if alert.OwnedBy(owner, team) {
+				owned = append(owned, alert)
+			}
+		}
+		alerts = owned
+	}
+
 	slog.Debug("Alert configurations retrieved successfully", "count", len(alerts))
-	c.JSON(http.StatusOK, models.APIResponse{Success: true, Data: alerts})
+	if h.groups == nil {
+		c.JSON(http.StatusOK, models.APIResponse{Success: true, Data: alerts})
+		return
+	}
+
+	filter, filtered := c.GetQuery("group")
+	if filter == "none" {
+		filter = ""
+	}
+	groups := make(map[string]*models.AlertGroup)
+	for _, group := range h.groups.List() {
+		groups[group.ID] = group
+	}
+	items := []alertListItem{}
+	for _, alert := range alerts {
+		if filtered && alert.GroupID != filter {
+			continue
+		}
+		items = append(items, alertListItem{AlertConfig: alert, Group: groups[alert.GroupID]})
+	}
+	c.JSON(http.StatusOK, models.APIResponse{Success: true, Data: items})
+}
+
+// validateGroup checks that the alert's group exists when groups are enabled
+func (h *AlertsHandler) validateGroup(alert *models.AlertConfig) error {
+	if h.groups == nil || alert.GroupID == "" {
+		return nil
+	}
+	if _, err := h.groups.Get(alert.GroupID); err != nil {
+		return fmt.Errorf("unknown group: %s", alert.GroupID)
+	}
+	return nil
 }
 
 // GetAlert returns a specific alert configuration by ID
@@ -80,11 +184,11 @@ func (h *AlertsHandler) GetAlert(c *gin.Context) {
 	if err != nil {
 		if err == database.ErrAlertNotFound {
 			slog.Debug("Alert not found", "id", id)
-			c.JSON(http.StatusNotFound, models.APIResponse{Success: false, Error: "Alert not found"})
+			c.JSON(http.StatusNotFound, models.APIResponse{Success: false, Error: i18n.T(locale(c), i18n.MsgAlertNotFound)})
 			return
 		}
 		slog.Error("Failed to get alert", "id", id, "error", err)
-		c.JSON(http.StatusInternalServerError, models.APIResponse{Success: false, Error: "Failed to get alert: " + err.Error()})
+		c.JSON(http.StatusInternalServerError, models.APIResponse{Success: false, Error: i18n.T(locale(c), i18n.MsgAlertGetFailed, err)})
 		return
 	}
 
@@ -97,7 +201,7 @@ func (h *AlertsHandler) CreateAlert(c *gin.Context) {
 	var alert models.AlertConfig
 	if err := c.ShouldBindJSON(&alert); err != nil {
 		slog.Debug("Invalid alert configuration data", "error", err)
-		c.JSON(http.StatusBadRequest, models.APIResponse{Success: false, Error: "Invalid alert configuration: " + err.Error()})
+		c.JSON(http.StatusBadRequest, models.APIResponse{Success: false, Error: i18n.T(locale(c), i18n.MsgAlertInvalidConfig, err)})
 		return
 	}
 
@@ -112,24 +216,21 @@ func (h *AlertsHandler) CreateAlert(c *gin.Context) {
 	alert.UpdatedAt = now
 
 	// Validate the alert configuration
-	if err := alert.Validate(); err != nil {
+	if msg, err := h.checkAlert(&alert); err != nil {
 		slog.Debug("Invalid alert configuration", "error", err)
-		c.JSON(http.StatusBadRequest, models.APIResponse{Success: false, Error: "Invalid alert configuration: " + err.Error()})
+		c.JSON(http.StatusBadRequest, models.APIResponse{Success: false, Error: i18n.T(locale(c), msg, err)})
 		return
 	}
 
-	for i := range alert.Notifications {
-		if err := alert.Notifications[i].Validate(); err != nil {
-			slog.Debug("Invalid notification configuration", "error", err)
-			c.JSON(http.StatusBadRequest, models.APIResponse{Success: false, Error: "Invalid notification configuration: " + err.Error()})
-			return
-		}
+	if previewRequested(c) {
+		h.previewImpact(c, &alert)
+		return
 	}
 
 	// Store the alert
 	if err := h.alertStore.CreateAlert(&alert); err != nil {
 		slog.Error("Failed to create alert", "error", err)
-		c.JSON(http.StatusInternalServerError, models.APIResponse{Success: false, Error: "Failed to create alert: " + err.Error()})
+		c.JSON(http.StatusInternalServerError, models.APIResponse{Success: false, Error: i18n.T(locale(c), i18n.MsgAlertCreateFailed, err)})
 		return
 	}
 
@@ -147,11 +248,11 @@ func (h *AlertsHandler) UpdateAlert(c *gin.Context) {
 	if err != nil {
 		if err == database.ErrAlertNotFound {
 			slog.Debug("Alert not found for update", "id", id)
-			c.JSON(http.StatusNotFound, models.APIResponse{Success: false, Error: "Alert not found"})
+			c.JSON(http.StatusNotFound, models.APIResponse{Success: false, Error: i18n.T(locale(c), i18n.MsgAlertNotFound)})
 			return
 		}
 		slog.Error("Failed to get alert for update", "id", id, "error", err)
-		c.JSON(http.StatusInternalServerError, models.APIResponse{Success: false, Error: "Failed to get alert: " + err.Error()})
+		c.JSON(http.StatusInternalServerError, models.APIResponse{Success: false, Error: i18n.T(locale(c), i18n.MsgAlertGetFailed, err)})
 		return
 	}
 
@@ -159,7 +260,7 @@ func (h *AlertsHandler) UpdateAlert(c *gin.Context) {
 	var alert models.AlertConfig
 	if err := c.ShouldBindJSON(&alert); err != nil {
 		slog.Debug("Invalid alert update data", "error", err)
-		c.JSON(http.StatusBadRequest, models.APIResponse{Success: false, Error: "Invalid alert configuration: " + err.Error()})
+		c.JSON(http.StatusBadRequest, models.APIResponse{Success: false, Error: i18n.T(locale(c), i18n.MsgAlertInvalidConfig, err)})
 		return
 	}
 
@@ -169,24 +270,21 @@ func (h *AlertsHandler) UpdateAlert(c *gin.Context) {
 	alert.UpdatedAt = time.Now()
 
 	// Validate the alert configuration
-	if err := alert.Validate(); err != nil {
+	if msg, err := h.checkAlert(&alert); err != nil {
 		slog.Debug("Invalid alert update", "error", err)
-		c.JSON(http.StatusBadRequest, models.APIResponse{Success: false, Error: "Invalid alert configuration: " + err.Error()})
+		c.JSON(http.StatusBadRequest, models.APIResponse{Success: false, Error: i18n.T(locale(c), msg, err)})
 		return
 	}
 
-	for i := range alert.Notifications {
-		if err := alert.Notifications[i].Validate(); err != nil {
-			slog.Debug("Invalid notification configuration", "error", err)
-			c.JSON(http.StatusBadRequest, models.APIResponse{Success: false, Error: "Invalid notification configuration: " + err.Error()})
-			return
-		}
+	if previewRequested(c) {
+		h.previewImpact(c, &alert)
+		return
 	}
 
 	// Update the alert
 	if err := h.alertStore.UpdateAlert(&alert); err != nil {
 		slog.Error("Failed to update alert", "id", id, "error", err)
-		c.JSON(http.StatusInternalServerError, models.APIResponse{Success: false, Error: "Failed to update alert: " + err.Error()})
+		c.JSON(http.StatusInternalServerError, models.APIResponse{Success: false, Error: i18n.T(locale(c), i18n.MsgAlertUpdateFailed, err)})
 		return
 	}
 
@@ -194,6 +292,36 @@ func (h *AlertsHandler) UpdateAlert(c *gin.Context) {
 	c.JSON(http.StatusOK, models.APIResponse{Success: true, Data: alert})
 }
 
+// previewRequested reports whether a create or update asks for an impact
+// preview (?preview=true) instead of saving
+func previewRequested(c *gin.Context) bool {
+	preview, _ := strconv.ParseBool(c.Query("preview"))
+	return preview
+}
+
+// previewImpact responds with how often the validated but unsaved alert would
+// have fired over the last ?hours= of metrics history
+func (h *AlertsHandler) previewImpact(c *gin.Context, alert *models.AlertConfig) {
+	hours := DefaultPreviewHours
+	if hoursStr := c.Query("hours"); hoursStr != "" {
+		parsed, err := strconv.Atoi(hoursStr)
+		if err != nil || parsed <= 0 || parsed > MaxPreviewHours {
+			err = fmt.Errorf("hours must be between 1 and %d", MaxPreviewHours)
+			c.JSON(http.StatusBadRequest, models.APIResponse{Success: false, Error: i18n.T(locale(c), i18n.MsgAlertPreviewFailed, err)})
+			return
+		}
+		hours = parsed
+	}
+
+	impact, err := h.evaluator.PreviewImpact(alert, time.Duration(hours)*time.Hour)
+	if err != nil {
+		slog.Debug("Alert impact preview unavailable", "id", alert.ID, "error", err)
+		c.JSON(http.StatusBadRequest, models.APIResponse{Success: false, Error: i18n.T(locale(c), i18n.MsgAlertPreviewFailed, err)})
+		return
+	}
+	c.JSON(http.StatusOK, models.APIResponse{Success: true, Data: gin.H{"alert": alert, "impact": impact}})
+}
+
 // DeleteAlert deletes an alert configuration
 func (h *AlertsHandler) DeleteAlert(c *gin.Context) {
 	id := c.Param("id")
@@ -204,23 +332,56 @@ func (h *AlertsHandler) DeleteAlert(c *gin.Context) {
 	if err != nil {
 		if err == database.ErrAlertNotFound {
 			slog.Debug("Alert not found for deletion", "id", id)
-			c.JSON(http.StatusNotFound, models.APIResponse{Success: false, Error: "Alert not found"})
+			c.JSON(http.StatusNotFound, models.APIResponse{Success: false, Error: i18n.T(locale(c), i18n.MsgAlertNotFound)})
 			return
 		}
 		slog.Error("Failed to get alert for deletion", "id", id, "error", err)
-		c.JSON(http.StatusInternalServerError, models.APIResponse{Success: false, Error: "Failed to get alert: " + err.Error()})
+		c.JSON(http.StatusInternalServerError, models.APIResponse{Success: false, Error: i18n.T(locale(c), i18n.MsgAlertGetFailed, err)})
 		return
 	}
 
 	// Delete the alert
 	if err := h.alertStore.DeleteAlert(id); err != nil {
 		slog.Error("Failed to delete alert", "id", id, "error", err)
-		c.JSON(http.StatusInternalServerError, models.APIResponse{Success: false, Error: "Failed to delete alert: " + err.Error()})
+		c.JSON(http.StatusInternalServerError, models.APIResponse{Success: false, Error: i18n.T(locale(c), i18n.MsgAlertDeleteFailed, err)})
 		return
 	}
 
 	slog.Info("Alert deleted successfully", "id", id)
-	c.JSON(http.StatusOK, models.APIResponse{Success: true, Data: gin.H{"message": "Alert deleted successfully"}})
+	c.JSON(http.StatusOK, models.APIResponse{Success: true, Data: gin.H{"message": i18n.T(locale(c), i18n.MsgAlertDeleted)}})
+}
+
+// GetAlertHistory returns the state change history of an alert, newest first,
+// including the resources captured when it fired
+func (h *AlertsHandler) GetAlertHistory(c *gin.Context) {
+	id := c.Param("id")
+	slog.Debug("Fetching alert history", "id", id)
+
+	if _, err := h.alertStore.GetAlert(id); err != nil {
+		if err == database.ErrAlertNotFound {
+			c.JSON(http.StatusNotFound, models.APIResponse{Success: false, Error: i18n.T(locale(c), i18n.MsgAlertNotFound)})
+			return
+		}
+		slog.Error("Failed to get alert", "id", id, "error", err)
+		c.JSON(http.StatusInternalServerError, models.APIResponse{Success: false, Error: i18n.T(locale(c), i18n.MsgAlertGetFailed, err)})
+		return
+	}
+
+	limit := DefaultAlertHistoryLimit
+	if limitStr := c.Query("limit"); limitStr != "" {
+		if parsed, err := strconv.Atoi(limitStr); err == nil && parsed > 0 {
+			limit = parsed
+		}
+	}
+
+	history, err := h.alertStore.GetHistory(id, limit)
+	if err != nil {
+		slog.Error("Failed to get alert history", "id", id, "error", err)
+		c.JSON(http.StatusInternalServerError, models.APIResponse{Success: false, Error: i18n.T(locale(c), i18n.MsgAlertHistoryFailed, err)})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{Success: true, Data: history})
 }
 
 // GetAllAlertStatus returns the current status of all alerts
@@ -239,41 +400,65 @@ func (h *AlertsHandler) GetAlertStatus(c *gin.Context) {
 	status, found := h.evaluator.GetAlertStatus(id)
 	if !found {
 		slog.Debug("Alert status not found", "id", id)
-		c.JSON(http.StatusNotFound, models.APIResponse{Success: false, Error: "Alert status not found"})
+		c.JSON(http.StatusNotFound, models.APIResponse{Success: false, Error: i18n.T(locale(c), i18n.MsgAlertStatusNotFound)})
 		return
 	}
 
 	c.JSON(http.StatusOK, models.APIResponse{Success: true, Data: status})
 }
 
-// GetNotifications returns all in-app notifications
+// GetNotifications returns all in-app notifications, rendered in the locale
+// negotiated from the ?lang= parameter or Accept-Language header. When users
+// are identified, Read is the requesting user's read state.
 func (h *AlertsHandler) GetNotifications(c *gin.Context) {
-	slog.Debug("Fetching in-app notifications")
+	loc := locale(c)
+	slog.Debug("Fetching in-app notifications", "locale", loc)
 
-	notifications := h.notifier.GetNotifications()
+	notifications := h.notifier.GetNotificationsForLocale(loc, RequestUser(c))
+	c.Header("Content-Language", string(loc))
 	c.JSON(http.StatusOK, models.APIResponse{Success: true, Data: notifications})
 }
 
-// MarkNotificationRead marks a notification as read
+// MarkNotificationRead marks a notification as read, only for the requesting
+// user when users are identified
 func (h *AlertsHandler) MarkNotificationRead(c *gin.Context) {
-	id := c.Param("id")
-	slog.Debug("Marking notification as read", "id", id)
+	id, user := c.Param("id"), RequestUser(c)
+	slog.Debug("Marking notification as read", "id", id, "user", user)
 
-	if !h.notifier.MarkNotificationRead(id) {
+	if !h.notifier.MarkNotificationRead(id, user) {
 		slog.Debug("Notification not found", "id", id)
-		c.JSON(http.StatusNotFound, models.APIResponse{Success: false, Error: "Notification not found"})
+		c.JSON(http.StatusNotFound, models.APIResponse{Success: false, Error: i18n.T(locale(c), i18n.MsgNotificationNotFound)})
 		return
 	}
 
-	c.JSON(http.StatusOK, models.APIResponse{Success: true, Data: gin.H{"message": "Notification marked as read"}})
+	c.JSON(http.StatusOK, models.APIResponse{Success: true, Data: gin.H{"message": i18n.T(locale(c), i18n.MsgNotificationMarkedRead)}})
+}
+
+// AcknowledgeAlert marks every notification of an alert as read, only for the
+// requesting user when users are identified. External alerts are acknowledged
+// by their "external-<fingerprint>" ID.
+func (h *AlertsHandler) AcknowledgeAlert(c *gin.Context) {
+	id, user := c.Param("id"), RequestUser(c)
+	slog.Debug("Acknowledging alert", "id", id, "user", user)
+
+	marked := h.notifier.MarkAlertNotificationsRead(id, user)
+	if marked == 0 {
+		if _, err := h.alertStore.GetAlert(id); err != nil {
+			c.JSON(http.StatusNotFound, models.APIResponse{Success: false, Error: i18n.T(locale(c), i18n.MsgAlertNotFound)})
+			return
+		}
+	}
+	c.JSON(http.StatusOK, models.APIResponse{Success: true, Data: gin.H{"message": i18n.T(locale(c), i18n.MsgAlertAcknowledged, marked), "acknowledged": marked}})
 }
 
-// MarkAllNotificationsRead marks all notifications as read
+// MarkAllNotificationsRead marks all notifications as read, only for the
+// requesting user when users are identified
 func (h *AlertsHandler) MarkAllNotificationsRead(c *gin.Context) {
-	slog.Debug("Marking all notifications as read")
+	user := RequestUser(c)
+	slog.Debug("Marking all notifications as read", "user", user)
 
-	h.notifier.MarkAllNotificationsRead()
-	c.JSON(http.StatusOK, models.APIResponse{Success: true, Data: gin.H{"message": "All notifications marked as read"}})
+	h.notifier.MarkAllNotificationsRead(user)
+	c.JSON(http.StatusOK, models.APIResponse{Success: true, Data: gin.H{"message": i18n.T(locale(c), i18n.MsgNotificationsMarkedRead)}})
 }
 
 // ClearNotifications removes all notifications
@@ -281,7 +466,61 @@ func (h *AlertsHandler) ClearNotifications(c *gin.Context) {
 	slog.Debug("Clearing all notifications")
 
 	h.notifier.ClearNotifications()
-	c.JSON(http.StatusOK, models.APIResponse{Success: true, Data: gin.H{"message": "All notifications cleared"}})
+	c.JSON(http.StatusOK, models.APIResponse{Success: true, Data: gin.H{"message": i18n.T(locale(c), i18n.MsgNotificationsCleared)}})
+}
+
+// GetTemplateHelpers documents the helper functions notification templates can call
+func (h *AlertsHandler) GetTemplateHelpers(c *gin.Context) {
+	c.JSON(http.StatusOK, models.APIResponse{Success: true, Data: tmplfunc.Helpers})
+}
+
+// GetChannelStatus returns the circuit breaker state of each notification channel
+func (h *AlertsHandler) GetChannelStatus(c *gin.Context) {
+	c.JSON(http.StatusOK, models.APIResponse{Success: true, Data: h.notifier.GetChannelStatus()})
+}
+
+// ListDeadLetters returns notifications that could not be delivered
+func (h *AlertsHandler) ListDeadLetters(c *gin.Context) {
+	c.JSON(http.StatusOK, models.APIResponse{Success: true, Data: h.notifier.DeadLetters().List()})
+}
+
+// SetRemediator enables the remediation audit log endpoint
+func (h *AlertsHandler) SetRemediator(remediator *services.Remediator) {
+	h.remediator = remediator
+}
+
+// ListRemediations returns the latest remediation audit records, newest
+// first, optionally only those of ?alert_id=
+func (h *AlertsHandler) ListRemediations(c *gin.Context) {
+	records := []models.RemediationRecord{}
+	if h.remediator != nil {
+		records = h.remediator.Records(c.Query("alert_id"))
+	}
+	c.JSON(http.StatusOK, models.APIResponse{Success: true, Data: records})
+}
+
+// RetryDeadLetter re-sends a dead-lettered notification
+func (h *AlertsHandler) RetryDeadLetter(c *gin.Context) {
+	id := c.Param("id")
+	slog.Debug("Retrying dead letter", "id", id)
+
+	if err := h.notifier.RetryDeadLetter(id); err != nil {
+		if errors.Is(err, services.ErrDeadLetterNotFound) {
+			c.JSON(http.StatusNotFound, models.APIResponse{Success: false, Error: i18n.T(locale(c), i18n.MsgDeadLetterNotFound)})
+			return
+		}
+		slog.Warn("Dead letter retry failed", "id", id, "error", err)
+		c.JSON(http.StatusBadGateway, models.APIResponse{Success: false, Error: i18n.T(locale(c), i18n.MsgDeadLetterRetryFailed, err)})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{Success: true, Data: gin.H{"message": i18n.T(locale(c), i18n.MsgDeadLetterRetried)}})
+}
+
+// ClearDeadLetters discards all dead-lettered notifications
+func (h *AlertsHandler) ClearDeadLetters(c *gin.Context) {
+	h.notifier.DeadLetters().Clear()
+	c.JSON(http.StatusOK, models.APIResponse{Success: true, Data: gin.H{"message": i18n.T(locale(c), i18n.MsgDeadLettersCleared)}})
 }
 
 // TestAlert tests an alert by simulating an alert event
@@ -294,11 +533,11 @@ func (h *AlertsHandler) TestAlert(c *gin.Context) {
 	if err != nil {
 		if err == database.ErrAlertNotFound {
 			slog.Debug("Alert not found for testing", "id", id)
-			c.JSON(http.StatusNotFound, models.APIResponse{Success: false, Error: "Alert not found"})
+			c.JSON(http.StatusNotFound, models.APIResponse{Success: false, Error: i18n.T(locale(c), i18n.MsgAlertNotFound)})
 			return
 		}
 		slog.Error("Failed to get alert for testing", "id", id, "error", err)
-		c.JSON(http.StatusInternalServerError, models.APIResponse{Success: false, Error: "Failed to get alert: " + err.Error()})
+		c.JSON(http.StatusInternalServerError, models.APIResponse{Success: false, Error: i18n.T(locale(c), i18n.MsgAlertGetFailed, err)})
 		return
 	}
 
@@ -329,7 +568,189 @@ func (h *AlertsHandler) TestAlert(c *gin.Context) {
 
 	slog.Info("Test alert sent successfully", "id", id, "name", alertConfig.Name)
 	c.JSON(http.StatusOK, models.APIResponse{Success: true, Data: gin.H{
-		"message": "Test alert sent successfully",
+		"message": i18n.T(locale(c), i18n.MsgAlertTestSent),
 		"event":   testEvent,
 	}})
 }
+
+// simulateRequest is the body of POST /alerts/:id/simulate
+type simulateRequest struct {
+	Values        []float64  `json:"values"`
+	Interval      string     `json:"interval"`       // Spacing between values, e.g. "30s"
+	Start         *time.Time `json:"start"`          // Timestamp of the first value
+	DebounceCount int        `json:"debounce_count"` // Overrides the evaluator setting
+	ResolveCount  int        `json:"resolve_count"`  // Overrides the evaluator setting
+}
+
+// SimulateAlert runs a synthetic series of metric values through an alert and
+// returns the state transitions and notifications it would produce, without
+// changing any live state or sending anything
+func (h *AlertsHandler) SimulateAlert(c *gin.Context) {
+	id := c.Param("id")
+	slog.Debug("Simulating alert", "id", id)
+
+	alertConfig, err := h.alertStore.GetAlert(id)
+	if err != nil {
+		if err == database.ErrAlertNotFound {
+			c.JSON(http.StatusNotFound, models.APIResponse{Success: false, Error: i18n.T(locale(c), i18n.MsgAlertNotFound)})
+			return
+		}
+		slog.Error("Failed to get alert for simulation", "id", id, "error", err)
+		c.JSON(http.StatusInternalServerError, models.APIResponse{Success: false, Error: i18n.T(locale(c), i18n.MsgAlertGetFailed, err)})
+		return
+	}
+
+	if alertConfig.Threshold.StringMetric() {
+		c.JSON(http.StatusBadRequest, models.APIResponse{Success: false, Error: i18n.T(locale(c), i18n.MsgAlertSimulateInvalid, errors.New("string metrics cannot be simulated with numeric values"))})
+		return
+	}
+
+	var req simulateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{Success: false, Error: i18n.T(locale(c), i18n.MsgAlertSimulateInvalid, err)})
+		return
+	}
+	opts, err := req.options()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{Success: false, Error: i18n.T(locale(c), i18n.MsgAlertSimulateInvalid, err)})
+		return
+	}
+
+	result := h.evaluator.Simulate(alertConfig, req.Values, opts)
+	if h.notifier != nil {
+		result.Notifications = h.notifier.PreviewNotifications(alertConfig, result.Transitions)
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{Success: true, Data: result})
+}
+
+// notificationPreviewRequest is the body of POST /notifications/preview: a
+// saved alert by ID or an inline, e.g. unsaved, alert configuration
+type notificationPreviewRequest struct {
+	AlertID  string               `json:"alert_id"`
+	Alert    *models.AlertConfig  `json:"alert"`
+	Severity models.AlertSeverity `json:"severity"` // Overrides the alert's
+	State    models.AlertState    `json:"state"`    // active (default) or resolved
+	Value    *float64             `json:"value"`    // Default: past the threshold, as for test notifications
+}
+
+// PreviewNotification renders the notification of an alert for a severity and
+// state with every registered channel's templates and returns the subjects
+// and bodies without sending anything
+func (h *AlertsHandler) PreviewNotification(c *gin.Context) {
+	var req notificationPreviewRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{Success: false, Error: i18n.T(locale(c), i18n.MsgPreviewInvalid, err)})
+		return
+	}
+
+	var alert models.AlertConfig
+	switch {
+	case (req.AlertID == "") == (req.Alert == nil):
+		c.JSON(http.StatusBadRequest, models.APIResponse{Success: false, Error: i18n.T(locale(c), i18n.MsgPreviewInvalid, errors.New("either alert_id or alert is required"))})
+		return
+	case req.Alert != nil:
+		alert = *req.Alert
+		if alert.ID == "" {
+			alert.ID = "preview"
+		}
+	default:
+		saved, err := h.alertStore.GetAlert(req.AlertID)
+		if err != nil {
+			if err == database.ErrAlertNotFound {
+				c.JSON(http.StatusNotFound, models.APIResponse{Success: false, Error: i18n.T(locale(c), i18n.MsgAlertNotFound)})
+				return
+			}
+			slog.Error("Failed to get alert for notification preview", "id", req.AlertID, "error", err)
+			c.JSON(http.StatusInternalServerError, models.APIResponse{Success: false, Error: i18n.T(locale(c), i18n.MsgAlertGetFailed, err)})
+			return
+		}
+		alert = *saved
+	}
+	if req.Severity != "" {
+		alert.Severity = req.Severity
+	}
+	if err := alert.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{Success: false, Error: i18n.T(locale(c), i18n.MsgAlertInvalidConfig, err)})
+		return
+	}
+	for i := range alert.Notifications {
+		if err := alert.Notifications[i].Validate(); err != nil {
+			c.JSON(http.StatusBadRequest, models.APIResponse{Success: false, Error: i18n.T(locale(c), i18n.MsgNotificationInvalid, err)})
+			return
+		}
+	}
+
+	oldState := models.StateInactive
+	switch req.State {
+	case "", models.StateActive:
+		req.State = models.StateActive
+	case models.StateResolved:
+		oldState = models.StateActive
+	default:
+		c.JSON(http.StatusBadRequest, models.APIResponse{Success: false, Error: i18n.T(locale(c), i18n.MsgPreviewInvalid, fmt.Errorf("invalid state %q: expected active or resolved", req.State))})
+		return
+	}
+	value := alert.Threshold.Value + 1
+	if req.Value != nil {
+		value = *req.Value
+	}
+
+	now := time.Now()
+	event := models.AlertEvent{
+		AlertID:      alert.ID,
+		OldState:     oldState,
+		NewState:     req.State,
+		CurrentValue: value,
+		Threshold:    alert.Threshold.Value,
+		Timestamp:    now,
+		Message:      "This is a preview of the alert notification",
+		Alert:        &alert,
+		Status: &models.AlertStatus{
+			AlertID:      alert.ID,
+			State:        req.State,
+			CurrentValue: value,
+			Source:       models.SourceLocal,
+		},
+		Source: models.SourceLocal,
+	}
+	if req.State == models.StateActive {
+		event.Status.TriggeredAt = &now
+	} else {
+		event.Status.ResolvedAt = &now
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{Success: true, Data: gin.H{
+		"alert_id":      alert.ID,
+		"severity":      alert.Severity,
+		"state":         req.State,
+		"notifications": h.notifier.PreviewEvent(event),
+	}})
+}
+
+// options validates the request and converts it to simulation options
+func (r simulateRequest) options() (services.SimulationOptions, error) {
+	var opts services.SimulationOptions
+	if len(r.Values) == 0 {
+		return opts, errors.New("values must not be empty")
+	}
+	if len(r.Values) > services.MaxSimulationValues {
+		return opts, fmt.Errorf("at most %d values are allowed", services.MaxSimulationValues)
+	}
+	if r.Interval != "" {
+		interval, err := time.ParseDuration(r.Interval)
+		if err != nil || interval <= 0 {
+			return opts, fmt.Errorf("invalid interval %q", r.Interval)
+		}
+		opts.Interval = interval
+	}
+	if r.DebounceCount < 0 || r.ResolveCount < 0 {
+		return opts, errors.New("debounce_count and resolve_count must not be negative")
+	}
+	if r.Start != nil {
+		opts.Start = r.Start.UTC()
+	}
+	opts.DebounceCount = r.DebounceCount
+	opts.ResolveCount = r.ResolveCount
+	return opts, nil
+}
@@ -6,7 +6,6 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
-	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
@@ -15,7 +14,6 @@ import (
 	"argus/internal/database"
 	"argus/internal/handlers"
 	"argus/internal/models"
-	"argus/internal/server"
 	"argus/internal/services"
 )
 
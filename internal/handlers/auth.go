@@ -0,0 +1,306 @@
+// File: internal/handlers/auth.go
+// Brief: API of the dashboard login and its sessions
+// Detailed: Reports the enabled login providers and the authenticated user, and runs the OpenID Connect authorization code flow of the dashboard: the login endpoint redirects to the identity provider with a state and nonce kept in a short-lived cookie, and the callback exchanges the returned code for an ID token and signs the user in. A sign-in issues a session held in an HttpOnly cookie; the session endpoints also issue one for LDAP credentials, return the CSRF token the dashboard sends with mutating requests, and list and revoke sessions, the user's own or, for admins, anyone's.
+// Author: drama.lin@aver.com
+// Date: 2026-10-14
+
+package handlers
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"argus/internal/auth"
+)
+
+const (
+	// oidcStateCookie keeps the state, nonce and return path of a login
+	oidcStateCookie = "argus_oidc_state"
+	// oidcLoginTimeout is how long a login may stay on the provider's page
+	oidcLoginTimeout = 10 * time.Minute
+)
+
+// AuthHandler manages the login endpoints
+type AuthHandler struct {
+	authenticator *auth.Authenticator
+	sessions      *auth.Sessions
+}
+
+// sessionRequest signs in with LDAP credentials
+type sessionRequest struct {
+	Username string `json:"username" binding:"required"`
+	Password string `json:"password" binding:"required"`
+}
+
+// sessionResponse is a session with the CSRF token of its mutating requests
+type sessionResponse struct {
+	auth.Session
+	CSRFToken string `json:"csrf_token"`
+}
+
+// NewAuthHandler creates a handler for the providers and sessions of
+// authenticator
+func NewAuthHandler(authenticator *auth.Authenticator) *AuthHandler {
+	sessions := authenticator.Sessions
+	if sessions == nil {
+		sessions = auth.NewSessions(auth.SessionConfig{})
+	}
+	return &AuthHandler{authenticator: authenticator, sessions: sessions}
+}
+
+// RegisterRoutes registers the login routes to the given router group
+func (h *AuthHandler) RegisterRoutes(router *gin.RouterGroup) {
+	group := router.Group("/auth")
+	{
+		group.GET("/config", h.GetConfig)
+		group.GET("/me", h.GetMe)
+		group.POST("/logout", h.Logout)
+		if h.authenticator.Enabled() {
+			group.POST("/sessions", h.CreateSession)
+			group.GET("/sessions", h.ListSessions)
+			group.DELETE("/sessions/:id", h.RevokeSession)
+			group.GET("/session", h.GetSession)
+		}
+		if h.authenticator.OIDC != nil {
+			group.GET("/oidc/login", h.OIDCLogin)
+			group.GET("/oidc/callback", h.OIDCCallback)
+		}
+	}
+	// Everyone manages their own sessions
+	Annotate(group, auth.RoleViewer, "POST /logout", "POST /sessions", "DELETE /sessions/:id")
+}
+
+// GetConfig reports which login providers are enabled, so the dashboard knows
+// where to send unauthenticated users
+func (h *AuthHandler) GetConfig(c *gin.Context) {
+	response := gin.H{
+		"enabled": h.authenticator.Enabled(),
+		"oidc":    h.authenticator.OIDC != nil,
+		"ldap":    h.authenticator.LDAP != nil,
+		"api_key": h.authenticator.APIKeys != nil,
+	}
+	if h.authenticator.OIDC != nil {
+		response["login_url"] = "/api/auth/oidc/login"
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// GetMe returns the identity of the requesting user
+func (h *AuthHandler) GetMe(c *gin.Context) {
+	if identity := RequestIdentity(c); identity != nil {
+		c.JSON(http.StatusOK, identity)
+		return
+	}
+	if h.authenticator.Enabled() {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Not authenticated"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"user": RequestUser(c)})
+}
+
+// Logout ends the session of the dashboard and removes its cookie
+func (h *AuthHandler) Logout(c *gin.Context) {
+	if identity := RequestIdentity(c); identity != nil && identity.SessionID != "" {
+		h.sessions.Revoke(identity.SessionID)
+		slog.Info("User logged out", "user", identity.User, "session", identity.SessionID)
+	}
+	http.SetCookie(c.Writer, h.sessions.SessionCookie(c.Request, ""))
+	c.JSON(http.StatusOK, gin.H{"message": "Logged out"})
+}
+
+// CreateSession signs in with the LDAP username and password in the body, or
+// with the credentials of the Authorization header, and sets the session
+// cookie
+func (h *AuthHandler) CreateSession(c *gin.Context) {
+	identity := RequestIdentity(c)
+	if identity == nil || identity.SessionID != "" {
+		if h.authenticator.LDAP == nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Sign in with the identity provider or send a bearer token"})
+			return
+		}
+		var req sessionRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
+			return
+		}
+		var err error
+		if identity, err = h.authenticator.LDAP.Authenticate(c.Request.Context(), req.Username, req.Password); err == nil {
+			identity, err = h.authenticator.Login(identity)
+		}
+		if err != nil {
+			slog.Warn("LDAP login failed", "user", req.Username, "error", err, "client_ip", c.ClientIP())
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid username or password"})
+			return
+		}
+	}
+	session, ok := h.startSession(c, identity)
+	if !ok {
+		return
+	}
+	c.JSON(http.StatusCreated, sessionResponse{Session: session, CSRFToken: session.CSRFToken})
+}
+
+// GetSession returns the session of the request with its CSRF token
+func (h *AuthHandler) GetSession(c *gin.Context) {
+	session, ok := h.currentSession(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Not signed in"})
+		return
+	}
+	c.JSON(http.StatusOK, sessionResponse{Session: session, CSRFToken: session.CSRFToken})
+}
+
+// ListSessions returns the active sessions of the user, or of every user with
+// all=true for admins
+func (h *AuthHandler) ListSessions(c *gin.Context) {
+	identity := RequestIdentity(c)
+	if identity == nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Not authenticated"})
+		return
+	}
+	user := identity.User
+	if c.Query("all") == "true" {
+		if !identity.Role.Allows(auth.RoleAdmin) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Listing the sessions of all users requires the admin role"})
+			return
+		}
+		user = ""
+	}
+	sessions := h.sessions.List(user)
+	c.JSON(http.StatusOK, gin.H{"sessions": sessions, "current": identity.SessionID, "count": len(sessions)})
+}
+
+// RevokeSession ends a session of the user, or of any user for admins
+func (h *AuthHandler) RevokeSession(c *gin.Context) {
+	identity := RequestIdentity(c)
+	if identity == nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Not authenticated"})
+		return
+	}
+	id := c.Param("id")
+	session, ok := h.sessions.Get(id)
+	// Others' sessions are not found, so their IDs cannot be probed
+	if !ok || (session.User != identity.User && !identity.Role.Allows(auth.RoleAdmin)) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Session not found"})
+		return
+	}
+	h.sessions.Revoke(id)
+	if id == identity.SessionID {
+		http.SetCookie(c.Writer, h.sessions.SessionCookie(c.Request, ""))
+	}
+	slog.Info("Session revoked", "user", session.User, "session", id, "by", identity.User)
+	c.JSON(http.StatusOK, gin.H{"message": "Session revoked", "id": id})
+}
+
+// startSession issues a session for identity and sets its cookie, responding
+// with the error itself when it fails
+func (h *AuthHandler) startSession(c *gin.Context, identity *auth.Identity) (auth.Session, bool) {
+	token, session, err := h.sessions.Create(identity, c.ClientIP(), c.Request.UserAgent())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create session: " + err.Error()})
+		return auth.Session{}, false
+	}
+	http.SetCookie(c.Writer, h.sessions.SessionCookie(c.Request, token))
+	slog.Info("User logged in", "user", identity.User, "provider", identity.Provider, "role", identity.Role, "session", session.ID)
+	return session, true
+}
+
+// currentSession returns the session the request was authenticated by
+func (h *AuthHandler) currentSession(c *gin.Context) (auth.Session, bool) {
+	identity := RequestIdentity(c)
+	if identity == nil || identity.SessionID == "" {
+		return auth.Session{}, false
+	}
+	return h.sessions.Get(identity.SessionID)
+}
+
+// OIDCLogin redirects to the identity provider's login page, returning to the
+// dashboard path in redirect afterwards
+func (h *AuthHandler) OIDCLogin(c *gin.Context) {
+	redirect := c.DefaultQuery("redirect", "/")
+	// Only paths of this server, so the login is no open redirect
+	if !strings.HasPrefix(redirect, "/") || strings.HasPrefix(redirect, "//") || strings.HasPrefix(redirect, "/\\") {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid redirect: must be a path on this server"})
+		return
+	}
+	state, err := randomToken()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start login: " + err.Error()})
+		return
+	}
+	nonce, err := randomToken()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start login: " + err.Error()})
+		return
+	}
+	target, err := h.authenticator.OIDC.AuthCodeURL(c.Request.Context(), state, nonce)
+	if err != nil {
+		slog.Error("Failed to start OIDC login", "error", err)
+		c.JSON(http.StatusBadGateway, gin.H{"error": "Identity provider unavailable: " + err.Error()})
+		return
+	}
+	value := state + "." + nonce + "." + base64.RawURLEncoding.EncodeToString([]byte(redirect))
+	cookie := h.sessions.Cookie(c.Request, oidcStateCookie, "/api/auth/oidc", value, int(oidcLoginTimeout.Seconds()))
+	// The provider redirects back cross-site, which strict cookies miss
+	cookie.SameSite = http.SameSiteLaxMode
+	http.SetCookie(c.Writer, cookie)
+	c.Redirect(http.StatusFound, target)
+}
+
+// OIDCCallback completes a login: it checks the state, exchanges the code for
+// an ID token and starts a session
+func (h *AuthHandler) OIDCCallback(c *gin.Context) {
+	if reason := c.Query("error"); reason != "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Login failed: " + reason + " " + c.Query("error_description")})
+		return
+	}
+	cookie, err := c.Cookie(oidcStateCookie)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Login expired, please sign in again"})
+		return
+	}
+	http.SetCookie(c.Writer, h.sessions.Cookie(c.Request, oidcStateCookie, "/api/auth/oidc", "", -1))
+	parts := strings.SplitN(cookie, ".", 3)
+	if len(parts) != 3 || subtle.ConstantTimeCompare([]byte(parts[0]), []byte(c.Query("state"))) != 1 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid login state"})
+		return
+	}
+	redirect, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid login state"})
+		return
+	}
+
+	_, identity, err := h.authenticator.OIDC.Exchange(c.Request.Context(), c.Query("code"), parts[1])
+	if err != nil {
+		slog.Warn("OIDC login failed", "error", err, "client_ip", c.ClientIP())
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Login failed: " + err.Error()})
+		return
+	}
+	if identity, err = h.authenticator.Login(identity); err != nil {
+		slog.Warn("OIDC login denied", "error", err, "client_ip", c.ClientIP())
+		c.JSON(http.StatusForbidden, gin.H{"error": "Login denied: " + err.Error()})
+		return
+	}
+	if _, ok := h.startSession(c, identity); !ok {
+		return
+	}
+	c.Redirect(http.StatusFound, string(redirect))
+}
+
+// randomToken returns 128 random bits, hex encoded
+func randomToken() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,162 @@
+// File: internal/handlers/calendar.go
+// Brief: Calendar feed of scheduled task runs and maintenance windows
+// Detailed: Lists the upcoming runs of enabled tasks and the pending or active silences as calendar events, as JSON and as an iCalendar feed that ops calendars can subscribe to.
+// Author: drama.lin@aver.com
+// Date: 2026-10-14
+
+package handlers
+
+import (
+	"bytes"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"argus/internal/database"
+	"argus/internal/ical"
+	"argus/internal/models"
+	"argus/internal/services"
+)
+
+// Calendar feed limits
+const (
+	DefaultCalendarDays = 7   // Days ahead covered when ?days= is not given
+	MaxCalendarDays     = 90  // Largest accepted ?days=
+	MaxRunsPerTask      = 500 // Runs listed per task, so minutely tasks do not flood the feed
+)
+
+// CalendarHandler manages the schedule feed endpoints
+type CalendarHandler struct {
+	repo     models.TaskRepository
+	silences *database.SilenceStore
+	location *time.Location // Timezone for tasks without their own (nil means server local time)
+}
+
+// NewCalendarHandler creates a handler listing the tasks in repo and, when
+// silences is not nil, the maintenance windows
+func NewCalendarHandler(repo models.TaskRepository, silences *database.SilenceStore) *CalendarHandler {
+	return &CalendarHandler{repo: repo, silences: silences}
+}
+
+// SetLocation sets the timezone cron schedules without their own are evaluated in
+func (h *CalendarHandler) SetLocation(loc *time.Location) {
+	h.location = loc
+}
+
+// RegisterRoutes registers the calendar routes to the given router group
+func (h *CalendarHandler) RegisterRoutes(router *gin.RouterGroup) {
+	router.GET("/calendar", h.GetEvents)
+	router.GET("/calendar.ics", h.GetFeed)
+}
+
+// events collects the task runs and maintenance windows between from and until
+func (h *CalendarHandler) events(c *gin.Context, from, until time.Time) ([]ical.Event, error) {
+	tasks, err := h.repo.ListTasks(c.Request.Context())
+	if err != nil {
+		return nil, err
+	}
+	fallback := h.location
+	if fallback == nil {
+		fallback = time.Local
+	}
+
+	events := []ical.Event{}
+	for _, task := range tasks {
+		for _, run := range services.UpcomingRuns(task, fallback, from, until, MaxRunsPerTask) {
+			description := task.Description
+			if task.Schedule.CronExpression != "" {
+				description = strings.TrimSpace(fmt.Sprintf("%s\nSchedule: %s (%s)", description, task.Schedule.CronExpression, task.Schedule.Location(fallback)))
+			}
+			events = append(events, ical.Event{
+				UID:         fmt.Sprintf("task-%s-%d@argus", task.ID, run.Unix()),
+				Summary:     task.Name,
+				Description: description,
+				Start:       run,
+				Categories:  []string{"task", string(task.Type)},
+			})
+		}
+	}
+
+	if h.silences != nil {
+		for _, silence := range h.silences.List() {
+			if silence.State(from) == models.SilenceExpired || silence.StartsAt.After(until) {
+				continue
+			}
+			matchers := make([]string, len(silence.Matchers))
+			for i, m := range silence.Matchers {
+				matchers[i] = m.String()
+			}
+			end := silence.EndsAt
+			events = append(events, ical.Event{
+				UID:         "silence-" + silence.ID + "@argus",
+				Summary:     "Maintenance: " + strings.Join(matchers, ", "),
+				Description: strings.TrimSpace(fmt.Sprintf("%s\nCreated by: %s", silence.Comment, silence.CreatedBy)),
+				Start:       silence.StartsAt,
+				End:         &end,
+				Categories:  []string{"silence"},
+			})
+		}
+	}
+
+	sort.SliceStable(events, func(i, j int) bool { return events[i].Start.Before(events[j].Start) })
+	return events, nil
+}
+
+// window parses ?days= into the covered time range starting now
+func window(c *gin.Context) (time.Time, time.Time, error) {
+	days := DefaultCalendarDays
+	if v := c.Query("days"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > MaxCalendarDays {
+			return time.Time{}, time.Time{}, fmt.Errorf("days must be between 1 and %d", MaxCalendarDays)
+		}
+		days = n
+	}
+	now := time.Now().UTC()
+	return now, now.AddDate(0, 0, days), nil
+}
+
+// GetEvents returns the upcoming task runs and maintenance windows as JSON
+func (h *CalendarHandler) GetEvents(c *gin.Context) {
+	from, until, err := window(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	events, err := h.events(c, from, until)
+	if err != nil {
+		slog.Error("Failed to build calendar", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list tasks: " + err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"from": from, "until": until, "events": events})
+}
+
+// GetFeed returns the upcoming task runs and maintenance windows as iCalendar
+func (h *CalendarHandler) GetFeed(c *gin.Context) {
+	from, until, err := window(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	events, err := h.events(c, from, until)
+	if err != nil {
+		slog.Error("Failed to build calendar", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list tasks: " + err.Error()})
+		return
+	}
+
+	var buf bytes.Buffer
+	if err := ical.Encode(&buf, "Argus schedule", events, from); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to encode calendar: " + err.Error()})
+		return
+	}
+	c.Header("Content-Disposition", `inline; filename="argus.ics"`)
+	c.Data(http.StatusOK, "text/calendar; charset=utf-8", buf.Bytes())
+}
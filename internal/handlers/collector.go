@@ -0,0 +1,149 @@
+// File: internal/handlers/collector.go
+// Brief: Admin API for runtime tuning of the metrics collector
+// Detailed: Reports and adjusts the collection interval, cache TTL, process limit and the metric kinds that are collected while Argus runs. Changes are persisted to the config file so they survive restarts; a change that cannot be persisted is rolled back.
+// Author: drama.lin@aver.com
+// Date: 2026-10-14
+
+package handlers
+
+import (
+	"log/slog"
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"argus/internal/metrics"
+)
+
+// CollectorTuner is the collector whose runtime settings CollectorHandler manages
+type CollectorTuner interface {
+	Tuning() metrics.Tuning
+	Tune(metrics.Tuning) error
+}
+
+// CollectorHandler manages the collector admin endpoints
+type CollectorHandler struct {
+	collector CollectorTuner
+	persist   func(metrics.Tuning) error // nil keeps changes in memory only
+	mu        sync.Mutex                 // Serializes changes, so none is lost or persisted out of order
+}
+
+// NewCollectorHandler creates a handler for the given collector
+func NewCollectorHandler(collector CollectorTuner) *CollectorHandler {
+	return &CollectorHandler{collector: collector}
+}
+
+// SetPersist sets the function saving tuned settings, e.g. to the config file
+func (h *CollectorHandler) SetPersist(persist func(metrics.Tuning) error) {
+	h.persist = persist
+}
+
+// collectorTuningRequest is the body of PATCH /admin/collector; omitted
+// fields keep their current value
+type collectorTuningRequest struct {
+	UpdateInterval *string         `json:"update_interval"` // Go duration, e.g. "10s"
+	CacheTTL       *string         `json:"cache_ttl"`
+	ProcessLimit   *int            `json:"process_limit"`
+	Collectors     map[string]bool `json:"collectors"` // Metric kind to enabled
+}
+
+// collectorTuningView renders the runtime settings with readable durations
+type collectorTuningView struct {
+	UpdateInterval string          `json:"update_interval"`
+	CacheTTL       string          `json:"cache_ttl"`
+	ProcessLimit   int             `json:"process_limit"`
+	Collectors     map[string]bool `json:"collectors"`
+	Persisted      bool            `json:"persisted"` // Whether changes are saved to the config file
+}
+
+func (h *CollectorHandler) view(t metrics.Tuning) collectorTuningView {
+	collectors := make(map[string]bool, len(metrics.Kinds))
+	for _, kind := range metrics.Kinds {
+		collectors[kind] = t.Enabled(kind)
+	}
+	return collectorTuningView{
+		UpdateInterval: t.UpdateInterval.String(),
+		CacheTTL:       t.CacheTTL.String(),
+		ProcessLimit:   t.ProcessLimit,
+		Collectors:     collectors,
+		Persisted:      h.persist != nil,
+	}
+}
+
+// RegisterRoutes registers the collector admin routes to the given router group
+func (h *CollectorHandler) RegisterRoutes(router *gin.RouterGroup) {
+	admin := router.Group("/admin/collector")
+	{
+		admin.GET("", h.GetCollector)
+		admin.PATCH("", h.TuneCollector)
+	}
+}
+
+// GetCollector returns the collector's runtime settings
+func (h *CollectorHandler) GetCollector(c *gin.Context) {
+	c.JSON(http.StatusOK, h.view(h.collector.Tuning()))
+}
+
+// TuneCollector adjusts the collector's runtime settings, e.g.
+// {"update_interval": "15s", "collectors": {"raid": false}}
+func (h *CollectorHandler) TuneCollector(c *gin.Context) {
+	var req collectorTuningRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid collector settings: " + err.Error()})
+		return
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	previous := h.collector.Tuning()
+	tuning := h.collector.Tuning()
+	parseDuration := func(field string, value *string, target *time.Duration) bool {
+		if value == nil {
+			return true
+		}
+		d, err := time.ParseDuration(*value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid collector " + field + ": " + err.Error()})
+			return false
+		}
+		*target = d
+		return true
+	}
+	if !parseDuration("update_interval", req.UpdateInterval, &tuning.UpdateInterval) ||
+		!parseDuration("cache_ttl", req.CacheTTL, &tuning.CacheTTL) {
+		return
+	}
+	if req.ProcessLimit != nil {
+		tuning.ProcessLimit = *req.ProcessLimit
+	}
+	for kind, enabled := range req.Collectors {
+		if !slices.Contains(metrics.Kinds, kind) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid collector settings: unknown metric kind " + kind})
+			return
+		}
+		tuning.Disabled = slices.DeleteFunc(tuning.Disabled, func(k string) bool { return k == kind })
+		if !enabled {
+			tuning.Disabled = append(tuning.Disabled, kind)
+		}
+	}
+
+	if err := h.collector.Tune(tuning); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid collector settings: " + err.Error()})
+		return
+	}
+	tuning = h.collector.Tuning()
+	if h.persist != nil {
+		if err := h.persist(tuning); err != nil {
+			slog.Error("Failed to persist collector settings", "error", err)
+			if rollbackErr := h.collector.Tune(previous); rollbackErr != nil {
+				slog.Error("Failed to restore collector settings", "error", rollbackErr)
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to persist collector settings: " + err.Error()})
+			return
+		}
+	}
+	c.JSON(http.StatusOK, h.view(tuning))
+}
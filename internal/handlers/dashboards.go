@@ -0,0 +1,158 @@
+// File: internal/handlers/dashboards.go
+// Brief: Dashboard API handlers
+// Detailed: Serves /api/dashboards to manage stored dashboard definitions and computes their widget payloads on request, so thin clients such as wall-mounted displays fetch one document per refresh and only draw it.
+// Author: drama.lin@aver.com
+// Date: 2026-10-14
+
+package handlers
+
+import (
+	"log/slog"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"argus/internal/auth"
+	"argus/internal/database"
+	"argus/internal/models"
+	"argus/internal/widgets"
+)
+
+// DashboardsHandler manages dashboards and renders their widgets
+type DashboardsHandler struct {
+	dashboards *database.DashboardStore
+	renderer   *widgets.Renderer
+}
+
+// NewDashboardsHandler creates a dashboards handler rendering widgets with renderer
+func NewDashboardsHandler(dashboards *database.DashboardStore, renderer *widgets.Renderer) *DashboardsHandler {
+	return &DashboardsHandler{dashboards: dashboards, renderer: renderer}
+}
+
+// RegisterRoutes registers the dashboard routes to the given router group
+func (h *DashboardsHandler) RegisterRoutes(router *gin.RouterGroup) {
+	dashboards := router.Group("/dashboards")
+	{
+		dashboards.GET("", h.ListDashboards)
+		dashboards.GET("/:id", h.GetDashboard)
+		dashboards.POST("", h.CreateDashboard)
+		dashboards.PUT("/:id", h.UpdateDashboard)
+		dashboards.DELETE("/:id", h.DeleteDashboard)
+
+		// Computed widget payloads
+		dashboards.GET("/:id/data", h.GetDashboardData)
+		dashboards.GET("/:id/widgets/:widget", h.GetWidgetData)
+	}
+	Annotate(dashboards, auth.RoleOperator, "POST ", "PUT /:id", "DELETE /:id")
+}
+
+// dashboard loads the dashboard named by the :id parameter, writing the
+// error response and returning nil when it cannot
+func (h *DashboardsHandler) dashboard(c *gin.Context) *models.Dashboard {
+	dashboard, err := h.dashboards.Get(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, models.APIResponse{Success: false, Error: "Dashboard not found"})
+		return nil
+	}
+	return dashboard
+}
+
+// ListDashboards returns every dashboard definition
+func (h *DashboardsHandler) ListDashboards(c *gin.Context) {
+	c.JSON(http.StatusOK, models.APIResponse{Success: true, Data: h.dashboards.List()})
+}
+
+// GetDashboard returns a dashboard definition
+func (h *DashboardsHandler) GetDashboard(c *gin.Context) {
+	if dashboard := h.dashboard(c); dashboard != nil {
+		c.JSON(http.StatusOK, models.APIResponse{Success: true, Data: dashboard})
+	}
+}
+
+// CreateDashboard creates a dashboard. IDs are generated for the dashboard
+// and its widgets when none are given.
+func (h *DashboardsHandler) CreateDashboard(c *gin.Context) {
+	var dashboard models.Dashboard
+	if err := c.ShouldBindJSON(&dashboard); err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{Success: false, Error: "Invalid dashboard: " + err.Error()})
+		return
+	}
+	if dashboard.ID != "" {
+		if _, err := h.dashboards.Get(dashboard.ID); err == nil {
+			c.JSON(http.StatusConflict, models.APIResponse{Success: false, Error: "Dashboard already exists: " + dashboard.ID})
+			return
+		}
+	}
+	h.save(c, &dashboard, http.StatusCreated)
+}
+
+// UpdateDashboard replaces a dashboard definition
+func (h *DashboardsHandler) UpdateDashboard(c *gin.Context) {
+	existing := h.dashboard(c)
+	if existing == nil {
+		return
+	}
+	var dashboard models.Dashboard
+	if err := c.ShouldBindJSON(&dashboard); err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{Success: false, Error: "Invalid dashboard: " + err.Error()})
+		return
+	}
+	dashboard.ID = existing.ID
+	h.save(c, &dashboard, http.StatusOK)
+}
+
+func (h *DashboardsHandler) save(c *gin.Context, dashboard *models.Dashboard, status int) {
+	dashboard.AssignWidgetIDs()
+	check := *dashboard
+	if check.ID == "" {
+		check.ID = "new" // The store assigns the IDs of new dashboards
+	}
+	if err := widgets.Validate(&check); err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{Success: false, Error: "Invalid dashboard: " + err.Error()})
+		return
+	}
+	if err := h.dashboards.Save(dashboard); err != nil {
+		slog.Error("Failed to save dashboard", "id", dashboard.ID, "error", err)
+		c.JSON(http.StatusInternalServerError, models.APIResponse{Success: false, Error: err.Error()})
+		return
+	}
+	slog.Info("Dashboard saved", "id", dashboard.ID, "name", dashboard.Name, "widgets", len(dashboard.Widgets))
+	c.JSON(status, models.APIResponse{Success: true, Data: dashboard})
+}
+
+// DeleteDashboard deletes a dashboard
+func (h *DashboardsHandler) DeleteDashboard(c *gin.Context) {
+	dashboard := h.dashboard(c)
+	if dashboard == nil {
+		return
+	}
+	if err := h.dashboards.Delete(dashboard.ID); err != nil {
+		slog.Error("Failed to delete dashboard", "id", dashboard.ID, "error", err)
+		c.JSON(http.StatusInternalServerError, models.APIResponse{Success: false, Error: err.Error()})
+		return
+	}
+	slog.Info("Dashboard deleted", "id", dashboard.ID)
+	c.JSON(http.StatusOK, models.APIResponse{Success: true, Data: gin.H{"message": "Dashboard deleted"}})
+}
+
+// GetDashboardData returns the computed payload of every widget. A widget
+// that cannot be rendered carries an error instead of failing the request.
+func (h *DashboardsHandler) GetDashboardData(c *gin.Context) {
+	if dashboard := h.dashboard(c); dashboard != nil {
+		c.JSON(http.StatusOK, models.APIResponse{Success: true, Data: h.renderer.RenderDashboard(dashboard)})
+	}
+}
+
+// GetWidgetData returns the computed payload of one widget
+func (h *DashboardsHandler) GetWidgetData(c *gin.Context) {
+	dashboard := h.dashboard(c)
+	if dashboard == nil {
+		return
+	}
+	widget, ok := dashboard.Widget(c.Param("widget"))
+	if !ok {
+		c.JSON(http.StatusNotFound, models.APIResponse{Success: false, Error: "Widget not found"})
+		return
+	}
+	c.JSON(http.StatusOK, models.APIResponse{Success: true, Data: h.renderer.Render(widget)})
+}
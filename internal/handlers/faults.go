@@ -0,0 +1,130 @@
+// File: internal/handlers/faults.go
+// Brief: Admin API for runtime fault injection
+// Detailed: Lists, installs and clears injected faults (slow collection, failing stores, SMTP outages, full queues) so resilience behaviour can be exercised against a running server. Only registered when fault injection is enabled.
+// Author: drama.lin@aver.com
+// Date: 2026-10-14
+
+package handlers
+
+import (
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"argus/internal/faults"
+)
+
+// FaultsHandler manages the fault injection admin endpoints
+type FaultsHandler struct {
+	injector *faults.Injector
+}
+
+// NewFaultsHandler creates a handler for the given injector
+func NewFaultsHandler(injector *faults.Injector) *FaultsHandler {
+	return &FaultsHandler{injector: injector}
+}
+
+// faultRequest is the body of PUT /admin/faults/:point
+type faultRequest struct {
+	Delay       string  `json:"delay"`       // Go duration, e.g. "2s"
+	Error       string  `json:"error"`       // Error message; empty only delays
+	Probability float64 `json:"probability"` // Chance per hit; zero means always
+	Remaining   int     `json:"remaining"`   // Hits before the fault clears itself; zero means unlimited
+}
+
+// faultView renders a fault with a readable delay
+type faultView struct {
+	Point       faults.Point `json:"point"`
+	Delay       string       `json:"delay,omitempty"`
+	Error       string       `json:"error,omitempty"`
+	Probability float64      `json:"probability,omitempty"`
+	Remaining   int          `json:"remaining,omitempty"`
+	Hits        uint64       `json:"hits"`
+}
+
+func newFaultView(f faults.Fault) faultView {
+	v := faultView{
+		Point:       f.Point,
+		Error:       f.Error,
+		Probability: f.Probability,
+		Remaining:   f.Remaining,
+		Hits:        f.Hits,
+	}
+	if f.Delay > 0 {
+		v.Delay = f.Delay.String()
+	}
+	return v
+}
+
+// RegisterRoutes registers the fault injection routes to the given router group
+func (h *FaultsHandler) RegisterRoutes(router *gin.RouterGroup) {
+	admin := router.Group("/admin/faults")
+	{
+		admin.GET("", h.ListFaults)
+		admin.PUT("/:point", h.SetFault)
+		admin.DELETE("/:point", h.ClearFault)
+		admin.DELETE("", h.ResetFaults)
+	}
+}
+
+// ListFaults returns the active faults and the available injection points
+func (h *FaultsHandler) ListFaults(c *gin.Context) {
+	active := h.injector.List()
+	views := make([]faultView, 0, len(active))
+	for _, f := range active {
+		views = append(views, newFaultView(f))
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"enabled": h.injector.Enabled(),
+		"points":  faults.Points,
+		"faults":  views,
+	})
+}
+
+// SetFault installs or replaces the fault at a point
+func (h *FaultsHandler) SetFault(c *gin.Context) {
+	var req faultRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid fault: " + err.Error()})
+		return
+	}
+
+	fault := faults.Fault{
+		Point:       faults.Point(c.Param("point")),
+		Error:       req.Error,
+		Probability: req.Probability,
+		Remaining:   req.Remaining,
+	}
+	if req.Delay != "" {
+		delay, err := time.ParseDuration(req.Delay)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid fault delay: " + err.Error()})
+			return
+		}
+		fault.Delay = delay
+	}
+	if err := h.injector.Set(fault); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid fault: " + err.Error()})
+		return
+	}
+
+	slog.Warn("Fault injected", "point", fault.Point, "delay", fault.Delay, "error", fault.Error, "probability", fault.Probability, "remaining", fault.Remaining)
+	c.JSON(http.StatusOK, newFaultView(fault))
+}
+
+// ClearFault removes the fault at a point
+func (h *FaultsHandler) ClearFault(c *gin.Context) {
+	point := faults.Point(c.Param("point"))
+	h.injector.Clear(point)
+	slog.Info("Fault cleared", "point", point)
+	c.Status(http.StatusNoContent)
+}
+
+// ResetFaults removes every fault
+func (h *FaultsHandler) ResetFaults(c *gin.Context) {
+	h.injector.Reset()
+	slog.Info("All faults cleared")
+	c.Status(http.StatusNoContent)
+}
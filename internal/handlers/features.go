@@ -0,0 +1,98 @@
+// File: internal/handlers/features.go
+// Brief: Admin API for the feature flags of experimental subsystems
+// Detailed: Lists the declared feature flags with their configured and active values, and switches one on or off. Changes are persisted to the config file and apply from the next restart; a change that cannot be persisted is rolled back.
+// Author: drama.lin@aver.com
+// Date: 2026-10-14
+
+package handlers
+
+import (
+	"log/slog"
+	"net/http"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+
+	"argus/internal/features"
+)
+
+// FeaturesHandler manages the feature flag admin endpoints
+type FeaturesHandler struct {
+	flags   *features.Set
+	persist func(name string, enabled bool) error // nil keeps changes in memory only
+	mu      sync.Mutex                            // Serializes changes, so none is persisted out of order
+}
+
+// NewFeaturesHandler creates a handler for the given flags
+func NewFeaturesHandler(flags *features.Set) *FeaturesHandler {
+	return &FeaturesHandler{flags: flags}
+}
+
+// SetPersist sets the function saving a changed flag, e.g. to the config file
+func (h *FeaturesHandler) SetPersist(persist func(name string, enabled bool) error) {
+	h.persist = persist
+}
+
+// featureRequest is the body of PUT /admin/features/:name
+type featureRequest struct {
+	Enabled *bool `json:"enabled" binding:"required"`
+}
+
+// RegisterRoutes registers the feature flag routes to the given router group
+func (h *FeaturesHandler) RegisterRoutes(router *gin.RouterGroup) {
+	admin := router.Group("/admin/features")
+	{
+		admin.GET("", h.ListFeatures)
+		admin.PUT("/:name", h.SetFeature)
+	}
+}
+
+// ListFeatures returns every declared flag with its values
+func (h *FeaturesHandler) ListFeatures(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"features": h.flags.States(), "persisted": h.persist != nil})
+}
+
+// SetFeature switches a flag from the next restart, e.g. {"enabled": true}
+func (h *FeaturesHandler) SetFeature(c *gin.Context) {
+	var req featureRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid feature request: " + err.Error()})
+		return
+	}
+	name := c.Param("name")
+	if _, ok := features.Lookup(name); !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Unknown feature flag: " + name})
+		return
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	previous := h.state(name).Enabled
+	if err := h.flags.Configure(name, *req.Enabled); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid feature request: " + err.Error()})
+		return
+	}
+	if h.persist != nil {
+		if err := h.persist(name, *req.Enabled); err != nil {
+			slog.Error("Failed to persist feature flag", "feature", name, "error", err)
+			if rollbackErr := h.flags.Configure(name, previous); rollbackErr != nil {
+				slog.Error("Failed to restore feature flag", "feature", name, "error", rollbackErr)
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to persist feature flag: " + err.Error()})
+			return
+		}
+	}
+
+	state := h.state(name)
+	slog.Info("Feature flag changed", "feature", name, "enabled", state.Enabled, "active", state.Active)
+	c.JSON(http.StatusOK, gin.H{"feature": state, "restart_required": state.Enabled != state.Active})
+}
+
+func (h *FeaturesHandler) state(name string) features.State {
+	for _, state := range h.flags.States() {
+		if state.Name == name {
+			return state
+		}
+	}
+	return features.State{}
+}
@@ -0,0 +1,385 @@
+// File: internal/handlers/grafana.go
+// Brief: Grafana JSON datasource endpoints
+// Detailed: Implements the SimpleJSON datasource contract (/search, /query, /annotations) used by the Grafana JSON and Infinity plugins, serving the recorded host metric history, ingested series and alert state changes so dashboards can be built directly on Argus.
+// Author: drama.lin@aver.com
+// Date: 2026-10-14
+
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"argus/internal/auth"
+	"argus/internal/database"
+	"argus/internal/metrics"
+	"argus/internal/models"
+)
+
+// GrafanaHandler manages the Grafana JSON datasource endpoints
+type GrafanaHandler struct {
+	history    *metrics.SeriesStore
+	rollups    *metrics.Rollups
+	ingested   *metrics.SeriesStore
+	alertStore database.AlertStore
+}
+
+// NewGrafanaHandler creates a handler serving the host metric history and the
+// state changes of the alerts in alertStore
+func NewGrafanaHandler(history *metrics.SeriesStore, alertStore database.AlertStore) *GrafanaHandler {
+	return &GrafanaHandler{history: history, alertStore: alertStore}
+}
+
+// SetSeriesStore adds the series ingested through remote-write to the datasource
+func (h *GrafanaHandler) SetSeriesStore(store *metrics.SeriesStore) {
+	h.ingested = store
+}
+
+// SetRollups serves long-range history queries from the rollups of the history
+func (h *GrafanaHandler) SetRollups(rollups *metrics.Rollups) {
+	h.rollups = rollups
+}
+
+// RegisterRoutes registers the datasource routes to the given router group
+func (h *GrafanaHandler) RegisterRoutes(router *gin.RouterGroup) {
+	group := router.Group("/grafana")
+	{
+		group.GET("", h.TestConnection)
+		group.GET("/", h.TestConnection)
+		group.POST("/search", h.Search)
+		group.POST("/query", h.Query)
+		group.POST("/annotations", h.Annotations)
+	}
+	// Grafana queries are POSTs
+	Annotate(group, auth.RoleViewer, "POST /search", "POST /query", "POST /annotations")
+}
+
+func (h *GrafanaHandler) stores() []*metrics.SeriesStore {
+	stores := []*metrics.SeriesStore{h.history}
+	if h.ingested != nil {
+		stores = append(stores, h.ingested)
+	}
+	return stores
+}
+
+// grafanaRange is the dashboard time range sent with queries and annotations
+type grafanaRange struct {
+	From time.Time `json:"from"`
+	To   time.Time `json:"to"`
+}
+
+type grafanaSearchRequest struct {
+	Target string `json:"target"`
+}
+
+type grafanaTarget struct {
+	Target string `json:"target"`
+	RefID  string `json:"refId"`
+	Type   string `json:"type"` // timeserie (default) or table
+}
+
+type grafanaQueryRequest struct {
+	Range         grafanaRange    `json:"range"`
+	IntervalMs    int64           `json:"intervalMs"`
+	MaxDataPoints int             `json:"maxDataPoints"`
+	Targets       []grafanaTarget `json:"targets"`
+}
+
+// step returns the time between points the dashboard asks for
+func (r grafanaQueryRequest) step() time.Duration {
+	if r.IntervalMs > 0 {
+		return time.Duration(r.IntervalMs) * time.Millisecond
+	}
+	if r.MaxDataPoints > 0 && !r.Range.From.IsZero() && r.Range.To.After(r.Range.From) {
+		return r.Range.To.Sub(r.Range.From) / time.Duration(r.MaxDataPoints)
+	}
+	return 0
+}
+
+type grafanaTimeSeries struct {
+	Target     string       `json:"target"`
+	RefID      string       `json:"refId,omitempty"`
+	Datapoints [][2]float64 `json:"datapoints"` // [value, unix milliseconds]
+}
+
+type grafanaColumn struct {
+	Text string `json:"text"`
+	Type string `json:"type"`
+}
+
+type grafanaTable struct {
+	Type    string          `json:"type"`
+	RefID   string          `json:"refId,omitempty"`
+	Columns []grafanaColumn `json:"columns"`
+	Rows    [][]interface{} `json:"rows"`
+}
+
+type grafanaAnnotationQuery struct {
+	Name  string `json:"name"`
+	Query string `json:"query"`
+}
+
+type grafanaAnnotationRequest struct {
+	Range      grafanaRange           `json:"range"`
+	Annotation grafanaAnnotationQuery `json:"annotation"`
+}
+
+type grafanaAnnotation struct {
+	Annotation grafanaAnnotationQuery `json:"annotation"`
+	Time       int64                  `json:"time"`
+	TimeEnd    int64                  `json:"timeEnd,omitempty"`
+	IsRegion   bool                   `json:"isRegion,omitempty"`
+	Title      string                 `json:"title"`
+	Text       string                 `json:"text"`
+	Tags       []string               `json:"tags"`
+}
+
+// TestConnection answers the datasource "Save & test" check
+func (h *GrafanaHandler) TestConnection(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"status": "ok"})
+}
+
+// Search returns the metric names whose name contains the request target
+func (h *GrafanaHandler) Search(c *gin.Context) {
+	var req grafanaSearchRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body: " + err.Error()})
+		return
+	}
+
+	seen := make(map[string]struct{})
+	names := []string{}
+	for _, store := range h.stores() {
+		for _, name := range store.Names() {
+			if _, ok := seen[name]; ok || !strings.Contains(name, req.Target) {
+				continue
+			}
+			seen[name] = struct{}{}
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	c.JSON(http.StatusOK, names)
+}
+
+// parseTarget splits a target such as disk_used_percent{mountpoint="/"} into
+// the metric name and label matchers
+func parseTarget(target string) (string, []models.Matcher, error) {
+	target = strings.TrimSpace(target)
+	idx := strings.IndexByte(target, '{')
+	if idx < 0 {
+		return target, nil, nil
+	}
+	if !strings.HasSuffix(target, "}") {
+		return "", nil, fmt.Errorf("invalid target %q: unterminated label selector", target)
+	}
+	matchers, err := models.ParseMatchers(target[idx:])
+	if err != nil {
+		return "", nil, err
+	}
+	return strings.TrimSpace(target[:idx]), matchers, nil
+}
+
+// seriesName renders a series as name{label="value",...}
+func seriesName(s metrics.Series) string {
+	if len(s.Labels) == 0 {
+		return s.Name
+	}
+	keys := make([]string, 0, len(s.Labels))
+	for k := range s.Labels {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	pairs := make([]string, len(keys))
+	for i, k := range keys {
+		pairs[i] = fmt.Sprintf("%s=%q", k, s.Labels[k])
+	}
+	return s.Name + "{" + strings.Join(pairs, ",") + "}"
+}
+
+// downsample averages consecutive samples so at most max points remain
+func downsample(samples []metrics.Sample, max int) [][2]float64 {
+	step := 1
+	if max > 0 && len(samples) > max {
+		step = (len(samples) + max - 1) / max
+	}
+	points := make([][2]float64, 0, (len(samples)+step-1)/step)
+	for i := 0; i < len(samples); i += step {
+		end := i + step
+		if end > len(samples) {
+			end = len(samples)
+		}
+		sum := 0.0
+		for _, s := range samples[i:end] {
+			sum += s.Value
+		}
+		points = append(points, [2]float64{sum / float64(end-i), float64(samples[end-1].Timestamp.UnixMilli())})
+	}
+	return points
+}
+
+// Query returns the samples of every target within the range, as time series
+// or, for table targets, as the latest value of each matching series
+func (h *GrafanaHandler) Query(c *gin.Context) {
+	var req grafanaQueryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body: " + err.Error()})
+		return
+	}
+
+	result := []interface{}{}
+	for _, target := range req.Targets {
+		if strings.TrimSpace(target.Target) == "" {
+			continue
+		}
+		name, matchers, err := parseTarget(target.Target)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		var series []metrics.Series
+		for _, store := range h.stores() {
+			if store == h.history && h.rollups != nil && target.Type != "table" {
+				series = append(series, h.rollupRange(name, matchers, req)...)
+				continue
+			}
+			for _, s := range store.Range(name, nil, req.Range.From, req.Range.To) {
+				if models.MatchAll(matchers, s.Labels) {
+					series = append(series, s)
+				}
+			}
+		}
+
+		if target.Type == "table" {
+			table := grafanaTable{
+				Type:    "table",
+				RefID:   target.RefID,
+				Columns: []grafanaColumn{{Text: "Time", Type: "time"}, {Text: "Series", Type: "string"}, {Text: "Value", Type: "number"}},
+				Rows:    [][]interface{}{},
+			}
+			for _, s := range series {
+				if n := len(s.Samples); n > 0 {
+					latest := s.Samples[n-1]
+					table.Rows = append(table.Rows, []interface{}{latest.Timestamp.UnixMilli(), seriesName(s), latest.Value})
+				}
+			}
+			result = append(result, table)
+			continue
+		}
+		for _, s := range series {
+			result = append(result, grafanaTimeSeries{
+				Target:     seriesName(s),
+				RefID:      target.RefID,
+				Datapoints: downsample(s.Samples, req.MaxDataPoints),
+			})
+		}
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+// rollupRange returns the history series matching the target at the
+// resolution suited to the request's range and step, with bucket averages as
+// samples
+func (h *GrafanaHandler) rollupRange(name string, matchers []models.Matcher, req grafanaQueryRequest) []metrics.Series {
+	aggregated, _ := h.rollups.Query(name, nil, req.Range.From, req.Range.To, req.step())
+	var series []metrics.Series
+	for _, a := range aggregated {
+		if !models.MatchAll(matchers, a.Labels) {
+			continue
+		}
+		s := metrics.Series{Name: a.Name, Labels: a.Labels, Samples: make([]metrics.Sample, len(a.Points))}
+		for i, p := range a.Points {
+			s.Samples[i] = metrics.Sample{Timestamp: p.Timestamp, Value: p.Avg}
+		}
+		series = append(series, s)
+	}
+	return series
+}
+
+// Annotations returns alert firing periods within the range as regions. The
+// annotation query is an optional label selector on the alerts, e.g.
+// {severity="critical"}.
+func (h *GrafanaHandler) Annotations(c *gin.Context) {
+	var req grafanaAnnotationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body: " + err.Error()})
+		return
+	}
+	matchers, err := models.ParseMatchers(req.Annotation.Query)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	alerts, err := h.alertStore.ListAlerts()
+	if err != nil {
+		slog.Error("Failed to list alerts for annotations", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list alerts: " + err.Error()})
+		return
+	}
+
+	annotations := []grafanaAnnotation{}
+	for _, alert := range alerts {
+		if !models.MatchAll(matchers, alert.LabelSet()) {
+			continue
+		}
+		history, err := h.alertStore.GetHistory(alert.ID, 0)
+		if err != nil {
+			slog.Warn("Failed to read alert history for annotations", "id", alert.ID, "error", err)
+			continue
+		}
+		annotations = append(annotations, firingRegions(alert, history, req)...)
+	}
+	sort.Slice(annotations, func(i, j int) bool { return annotations[i].Time < annotations[j].Time })
+	c.JSON(http.StatusOK, annotations)
+}
+
+// firingRegions pairs each firing entry of a newest-first history with the
+// entry that ended it and keeps the regions overlapping the request range
+func firingRegions(alert *models.AlertConfig, history []models.AlertHistoryEntry, req grafanaAnnotationRequest) []grafanaAnnotation {
+	var regions []grafanaAnnotation
+	var open *grafanaAnnotation
+	for i := len(history) - 1; i >= 0; i-- {
+		entry := history[i]
+		switch {
+		case entry.NewState.Firing() && open == nil:
+			open = &grafanaAnnotation{
+				Annotation: req.Annotation,
+				Time:       entry.Timestamp.UnixMilli(),
+				Title:      alert.Name,
+				Text:       entry.Message,
+				Tags:       []string{string(alert.Severity), string(alert.Threshold.MetricType)},
+			}
+		case !entry.NewState.Firing() && open != nil:
+			open.TimeEnd = entry.Timestamp.UnixMilli()
+			open.IsRegion = true
+			regions = append(regions, *open)
+			open = nil
+		}
+	}
+	if open != nil {
+		regions = append(regions, *open) // Still firing
+	}
+
+	from, to := req.Range.From.UnixMilli(), req.Range.To.UnixMilli()
+	kept := regions[:0]
+	for _, r := range regions {
+		if !req.Range.To.IsZero() && r.Time > to {
+			continue
+		}
+		if !req.Range.From.IsZero() && r.IsRegion && r.TimeEnd < from {
+			continue
+		}
+		kept = append(kept, r)
+	}
+	return kept
+}
@@ -0,0 +1,336 @@
+// File: internal/handlers/groups.go
+// Brief: Alert group API handlers
+// Detailed: Serves CRUD endpoints for alert groups (folders) plus group-level operations: enabling or disabling every alert of a group and silencing a group through a "group" label matcher.
+// Author: drama.lin@aver.com
+// Date: 2026-10-14
+
+package handlers
+
+import (
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"argus/internal/auth"
+	"argus/internal/database"
+	"argus/internal/models"
+)
+
+// GroupsHandler manages the alert group endpoints
+type GroupsHandler struct {
+	groups     *database.GroupStore
+	alertStore database.AlertStore
+	silences   *database.SilenceStore
+}
+
+// NewGroupsHandler creates a handler for the groups in groups and the alerts
+// filed under them. silences may be nil, which disables group silencing.
+func NewGroupsHandler(groups *database.GroupStore, alertStore database.AlertStore, silences *database.SilenceStore) *GroupsHandler {
+	return &GroupsHandler{groups: groups, alertStore: alertStore, silences: silences}
+}
+
+// RegisterRoutes registers the alert group routes to the given router group
+func (h *GroupsHandler) RegisterRoutes(router *gin.RouterGroup) {
+	groups := router.Group("/alert-groups")
+	{
+		groups.GET("", h.ListGroups)
+		groups.GET("/:id", h.GetGroup)
+		groups.POST("", h.CreateGroup)
+		groups.PUT("/:id", h.UpdateGroup)
+		groups.DELETE("/:id", h.DeleteGroup)
+
+		// Group-level operations on the member alerts
+		groups.POST("/:id/enable", h.EnableGroup)
+		groups.POST("/:id/disable", h.DisableGroup)
+		groups.POST("/:id/silence", h.SilenceGroup)
+		groups.DELETE("/:id/silence", h.UnsilenceGroup)
+	}
+	Annotate(groups, auth.RoleOperator, "POST /:id/silence", "DELETE /:id/silence")
+}
+
+// groupView is a group with a summary of its member alerts
+type groupView struct {
+	*models.AlertGroup
+	AlertCount   int      `json:"alert_count"`
+	EnabledCount int      `json:"enabled_count"`
+	SilencedBy   []string `json:"silenced_by,omitempty"` // Active silences muting the whole group
+}
+
+// groupSilenceRequest is the body of POST /alert-groups/:id/silence
+type groupSilenceRequest struct {
+	Duration  string `json:"duration" binding:"required"` // Go duration, e.g. "2h"
+	Comment   string `json:"comment"`
+	CreatedBy string `json:"created_by"`
+}
+
+// groupLabels is the label set matched by silences of the whole group
+func groupLabels(id string) map[string]string {
+	return map[string]string{models.LabelGroup: id}
+}
+
+// members returns the alerts filed under the group
+func (h *GroupsHandler) members(id string) ([]*models.AlertConfig, error) {
+	alerts, err := h.alertStore.ListAlerts()
+	if err != nil {
+		return nil, err
+	}
+	var result []*models.AlertConfig
+	for _, alert := range alerts {
+		if alert.GroupID == id {
+			result = append(result, alert)
+		}
+	}
+	return result, nil
+}
+
+func (h *GroupsHandler) view(group *models.AlertGroup, alerts []*models.AlertConfig) groupView {
+	v := groupView{AlertGroup: group}
+	for _, alert := range alerts {
+		if alert.GroupID != group.ID {
+			continue
+		}
+		v.AlertCount++
+		if alert.Enabled {
+			v.EnabledCount++
+		}
+	}
+	if h.silences != nil {
+		v.SilencedBy = h.silences.Silenced(groupLabels(group.ID), time.Now())
+	}
+	return v
+}
+
+// group loads the group named by the :id parameter, writing the error
+// response and returning nil when it cannot
+func (h *GroupsHandler) group(c *gin.Context) *models.AlertGroup {
+	group, err := h.groups.Get(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, models.APIResponse{Success: false, Error: "Alert group not found"})
+		return nil
+	}
+	return group
+}
+
+// ListGroups returns every group with its alert counts
+func (h *GroupsHandler) ListGroups(c *gin.Context) {
+	alerts, err := h.alertStore.ListAlerts()
+	if err != nil {
+		slog.Error("Failed to list alerts", "error", err)
+		c.JSON(http.StatusInternalServerError, models.APIResponse{Success: false, Error: "Failed to list alerts: " + err.Error()})
+		return
+	}
+	result := []groupView{}
+	for _, group := range h.groups.List() {
+		result = append(result, h.view(group, alerts))
+	}
+	c.JSON(http.StatusOK, models.APIResponse{Success: true, Data: result})
+}
+
+// GetGroup returns a group with its member alerts
+func (h *GroupsHandler) GetGroup(c *gin.Context) {
+	group := h.group(c)
+	if group == nil {
+		return
+	}
+	alerts, err := h.members(group.ID)
+	if err != nil {
+		slog.Error("Failed to list alerts", "error", err)
+		c.JSON(http.StatusInternalServerError, models.APIResponse{Success: false, Error: "Failed to list alerts: " + err.Error()})
+		return
+	}
+	if alerts == nil {
+		alerts = []*models.AlertConfig{}
+	}
+	c.JSON(http.StatusOK, models.APIResponse{Success: true, Data: gin.H{
+		"group":  h.view(group, alerts),
+		"alerts": alerts,
+	}})
+}
+
+// CreateGroup creates a group. An ID is generated when none is given.
+func (h *GroupsHandler) CreateGroup(c *gin.Context) {
+	var group models.AlertGroup
+	if err := c.ShouldBindJSON(&group); err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{Success: false, Error: "Invalid alert group: " + err.Error()})
+		return
+	}
+	if group.ID != "" {
+		if _, err := h.groups.Get(group.ID); err == nil {
+			c.JSON(http.StatusConflict, models.APIResponse{Success: false, Error: "Alert group already exists: " + group.ID})
+			return
+		}
+	}
+	h.save(c, &group, http.StatusCreated)
+}
+
+// UpdateGroup replaces a group's name, description and labels
+func (h *GroupsHandler) UpdateGroup(c *gin.Context) {
+	existing := h.group(c)
+	if existing == nil {
+		return
+	}
+	var group models.AlertGroup
+	if err := c.ShouldBindJSON(&group); err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{Success: false, Error: "Invalid alert group: " + err.Error()})
+		return
+	}
+	group.ID = existing.ID
+	h.save(c, &group, http.StatusOK)
+}
+
+func (h *GroupsHandler) save(c *gin.Context, group *models.AlertGroup, status int) {
+	if group.Name == "" {
+		c.JSON(http.StatusBadRequest, models.APIResponse{Success: false, Error: "Invalid alert group: group name is required"})
+		return
+	}
+	if err := h.groups.Save(group); err != nil {
+		slog.Error("Failed to save alert group", "id", group.ID, "error", err)
+		c.JSON(http.StatusInternalServerError, models.APIResponse{Success: false, Error: err.Error()})
+		return
+	}
+	slog.Info("Alert group saved", "id", group.ID, "name", group.Name)
+	alerts, _ := h.members(group.ID)
+	c.JSON(status, models.APIResponse{Success: true, Data: h.view(group, alerts)})
+}
+
+// DeleteGroup deletes a group. Its alerts are kept and become ungrouped.
+func (h *GroupsHandler) DeleteGroup(c *gin.Context) {
+	group := h.group(c)
+	if group == nil {
+		return
+	}
+	ungrouped, err := h.updateMembers(group.ID, func(alert *models.AlertConfig) bool {
+		alert.GroupID = ""
+		return true
+	})
+	if err != nil {
+		slog.Error("Failed to ungroup alerts", "group_id", group.ID, "error", err)
+		c.JSON(http.StatusInternalServerError, models.APIResponse{Success: false, Error: "Failed to ungroup alerts: " + err.Error()})
+		return
+	}
+	if err := h.groups.Delete(group.ID); err != nil {
+		slog.Error("Failed to delete alert group", "id", group.ID, "error", err)
+		c.JSON(http.StatusInternalServerError, models.APIResponse{Success: false, Error: err.Error()})
+		return
+	}
+	slog.Info("Alert group deleted", "id", group.ID, "ungrouped", ungrouped)
+	c.JSON(http.StatusOK, models.APIResponse{Success: true, Data: gin.H{"ungrouped": ungrouped}})
+}
+
+// EnableGroup enables every alert of the group
+func (h *GroupsHandler) EnableGroup(c *gin.Context) {
+	h.setEnabled(c, true)
+}
+
+// DisableGroup disables every alert of the group
+func (h *GroupsHandler) DisableGroup(c *gin.Context) {
+	h.setEnabled(c, false)
+}
+
+func (h *GroupsHandler) setEnabled(c *gin.Context, enabled bool) {
+	group := h.group(c)
+	if group == nil {
+		return
+	}
+	updated, err := h.updateMembers(group.ID, func(alert *models.AlertConfig) bool {
+		if alert.Enabled == enabled {
+			return false
+		}
+		alert.Enabled = enabled
+		return true
+	})
+	if err != nil {
+		slog.Error("Failed to update group alerts", "group_id", group.ID, "error", err)
+		c.JSON(http.StatusInternalServerError, models.APIResponse{Success: false, Error: "Failed to update alerts: " + err.Error()})
+		return
+	}
+	slog.Info("Alert group updated", "id", group.ID, "enabled", enabled, "updated", updated)
+	c.JSON(http.StatusOK, models.APIResponse{Success: true, Data: gin.H{"enabled": enabled, "updated": updated}})
+}
+
+// updateMembers applies change to each alert of the group and stores the
+// alerts it reports as changed, returning how many were stored
+func (h *GroupsHandler) updateMembers(id string, change func(alert *models.AlertConfig) bool) (int, error) {
+	alerts, err := h.members(id)
+	if err != nil {
+		return 0, err
+	}
+	updated := 0
+	for _, alert := range alerts {
+		if !change(alert) {
+			continue
+		}
+		alert.UpdatedAt = time.Now()
+		if err := h.alertStore.UpdateAlert(alert); err != nil {
+			return updated, err
+		}
+		updated++
+	}
+	return updated, nil
+}
+
+// SilenceGroup mutes the notifications of every alert of the group, present
+// or added later, for the requested duration
+func (h *GroupsHandler) SilenceGroup(c *gin.Context) {
+	if h.silences == nil {
+		c.JSON(http.StatusNotImplemented, models.APIResponse{Success: false, Error: "Silences are not enabled"})
+		return
+	}
+	group := h.group(c)
+	if group == nil {
+		return
+	}
+	var req groupSilenceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.APIResponse{Success: false, Error: "Invalid silence request: " + err.Error()})
+		return
+	}
+	duration, err := time.ParseDuration(req.Duration)
+	if err != nil || duration <= 0 {
+		c.JSON(http.StatusBadRequest, models.APIResponse{Success: false, Error: "Invalid silence duration: " + req.Duration})
+		return
+	}
+	if req.Comment == "" {
+		req.Comment = "Silenced alert group " + group.Name
+	}
+
+	now := time.Now().UTC()
+	silence := &models.Silence{
+		Matchers:  []models.Matcher{{Name: models.LabelGroup, Value: group.ID, IsEqual: true}},
+		StartsAt:  now,
+		EndsAt:    now.Add(duration),
+		CreatedBy: req.CreatedBy,
+		Comment:   req.Comment,
+	}
+	if err := h.silences.Save(silence); err != nil {
+		slog.Error("Failed to silence alert group", "id", group.ID, "error", err)
+		c.JSON(http.StatusInternalServerError, models.APIResponse{Success: false, Error: err.Error()})
+		return
+	}
+	slog.Info("Alert group silenced", "id", group.ID, "silence_id", silence.ID, "until", silence.EndsAt)
+	c.JSON(http.StatusCreated, models.APIResponse{Success: true, Data: silence})
+}
+
+// UnsilenceGroup expires the active silences muting the whole group
+func (h *GroupsHandler) UnsilenceGroup(c *gin.Context) {
+	if h.silences == nil {
+		c.JSON(http.StatusNotImplemented, models.APIResponse{Success: false, Error: "Silences are not enabled"})
+		return
+	}
+	group := h.group(c)
+	if group == nil {
+		return
+	}
+	ids := h.silences.Silenced(groupLabels(group.ID), time.Now())
+	for _, id := range ids {
+		if err := h.silences.Expire(id); err != nil {
+			slog.Error("Failed to expire silence", "id", id, "error", err)
+			c.JSON(http.StatusInternalServerError, models.APIResponse{Success: false, Error: err.Error()})
+			return
+		}
+	}
+	slog.Info("Alert group unsilenced", "id", group.ID, "expired", len(ids))
+	c.JSON(http.StatusOK, models.APIResponse{Success: true, Data: gin.H{"expired": ids}})
+}
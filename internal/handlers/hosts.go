@@ -0,0 +1,291 @@
+// File: internal/handlers/hosts.go
+// Brief: Host inventory API handlers
+// Detailed: Receives agent heartbeats and serves the inventory of hosts reporting to a central server, with their labels, agent version, health and last-seen time, filterable by status and labels. With an enrollment token configured, agents enroll for a credential of their own that every heartbeat must carry, and can be revoked. Agents running tasks claim the runs queued for their host and report the executions back.
+// Author: drama.lin@aver.com
+// Date: 2026-10-14
+
+package handlers
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"argus/internal/database"
+	"argus/internal/models"
+	"argus/internal/services"
+)
+
+// HostsHandler manages the host inventory endpoints
+type HostsHandler struct {
+	registry  *services.HostRegistry
+	enroller  *services.AgentEnroller
+	scheduler agentJobScheduler
+}
+
+// agentJobScheduler is implemented by schedulers running tasks on agents
+type agentJobScheduler interface {
+	ClaimAgentJobs(hostID string) []models.AgentJob
+	CompleteAgentJob(hostID, jobID string, execution *models.TaskExecution) error
+	AgentJobs() []models.AgentJob
+}
+
+// NewHostsHandler creates a handler for the hosts in registry
+func NewHostsHandler(registry *services.HostRegistry) *HostsHandler {
+	return &HostsHandler{registry: registry}
+}
+
+// SetEnroller requires heartbeats to carry a credential issued by enroller and
+// enables the enrollment endpoints
+func (h *HostsHandler) SetEnroller(enroller *services.AgentEnroller) {
+	h.enroller = enroller
+}
+
+// SetScheduler enables the endpoints agents claim the tasks targeting them
+// from and report their executions to
+func (h *HostsHandler) SetScheduler(scheduler agentJobScheduler) {
+	h.scheduler = scheduler
+}
+
+// RegisterRoutes registers the host routes to the given router group
+func (h *HostsHandler) RegisterRoutes(router *gin.RouterGroup) {
+	hosts := router.Group("/hosts")
+	{
+		hosts.GET("", h.ListHosts)
+		hosts.POST("/heartbeat", h.Heartbeat)
+		hosts.GET("/:id", h.GetHost)
+		hosts.DELETE("/:id", h.DeleteHost)
+
+		if h.enroller != nil {
+			hosts.POST("/enroll", h.Enroll)
+			hosts.GET("/enrollments", h.ListEnrollments)
+			hosts.POST("/:id/revoke", h.RevokeHost)
+		}
+		if h.scheduler != nil {
+			hosts.GET("/jobs", h.ListJobs)
+			hosts.POST("/:id/jobs/claim", h.ClaimJobs)
+			hosts.POST("/:id/jobs/:job/result", h.ReportJob)
+		}
+	}
+}
+
+// Heartbeat registers or refreshes the reporting host
+func (h *HostsHandler) Heartbeat(c *gin.Context) {
+	var hb models.HostHeartbeat
+	if err := c.ShouldBindJSON(&hb); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid heartbeat: " + err.Error()})
+		return
+	}
+	if err := hb.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid heartbeat: " + err.Error()})
+		return
+	}
+	if !h.authenticate(c, hb.ID) {
+		return
+	}
+	c.JSON(http.StatusOK, h.registry.Heartbeat(hb, c.ClientIP()))
+}
+
+// authenticate checks the credential of the agent with the given ID when
+// enrollment is enabled, responding with an error when it is rejected.
+// Without enrollment, agents are authenticated like any API client by the
+// login providers, if enabled.
+func (h *HostsHandler) authenticate(c *gin.Context, id string) bool {
+	if h.enroller == nil {
+		return true
+	}
+	credential, _ := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer ")
+	switch err := h.enroller.Authenticate(id, credential); {
+	case errors.Is(err, services.ErrAgentRevoked):
+		c.JSON(http.StatusForbidden, gin.H{"error": "Agent has been revoked"})
+		return false
+	case err != nil:
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or missing agent credential"})
+		return false
+	}
+	return true
+}
+
+// ClaimJobs hands the agent the task runs queued for its host
+func (h *HostsHandler) ClaimJobs(c *gin.Context) {
+	id := c.Param("id")
+	if !h.authenticate(c, id) {
+		return
+	}
+	c.JSON(http.StatusOK, models.AgentJobsResponse{Jobs: h.scheduler.ClaimAgentJobs(id)})
+}
+
+// ReportJob records the execution an agent reports for one of its jobs
+func (h *HostsHandler) ReportJob(c *gin.Context) {
+	id, jobID := c.Param("id"), c.Param("job")
+	if !h.authenticate(c, id) {
+		return
+	}
+	var execution models.TaskExecution
+	if err := c.ShouldBindJSON(&execution); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid execution: " + err.Error()})
+		return
+	}
+	switch err := h.scheduler.CompleteAgentJob(id, jobID, &execution); {
+	case errors.Is(err, services.ErrAgentJobNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Job not found; it may have expired"})
+	case err != nil:
+		slog.Error("Failed to record agent execution", "agent", id, "job", jobID, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to record execution"})
+	default:
+		c.JSON(http.StatusOK, gin.H{"execution_id": execution.ExecutionID})
+	}
+}
+
+// ListJobs returns the task runs waiting for agents to claim them or report
+// their results
+func (h *HostsHandler) ListJobs(c *gin.Context) {
+	jobs := h.scheduler.AgentJobs()
+	c.JSON(http.StatusOK, gin.H{"jobs": jobs, "total": len(jobs)})
+}
+
+// Enroll admits an agent presenting the enrollment token and returns its credential
+func (h *HostsHandler) Enroll(c *gin.Context) {
+	var req models.AgentEnrollRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid enrollment request: " + err.Error()})
+		return
+	}
+	// The enrolled ID must also be valid in heartbeats
+	hb := models.HostHeartbeat{ID: req.ID, Hostname: req.Hostname}
+	if err := hb.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid enrollment request: " + err.Error()})
+		return
+	}
+	resp, err := h.enroller.Enroll(req.Token, hb.ID, hb.Hostname)
+	switch {
+	case errors.Is(err, services.ErrInvalidEnrollmentToken):
+		slog.Warn("Agent enrollment rejected", "agent", hb.ID, "address", c.ClientIP())
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid enrollment token"})
+	case errors.Is(err, services.ErrAgentRevoked):
+		c.JSON(http.StatusForbidden, gin.H{"error": "Agent has been revoked"})
+	case err != nil:
+		slog.Error("Failed to enroll agent", "agent", hb.ID, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to enroll agent"})
+	default:
+		c.JSON(http.StatusCreated, resp)
+	}
+}
+
+// ListEnrollments returns the enrolled agents, including revoked ones
+func (h *HostsHandler) ListEnrollments(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"enrollments": h.enroller.List()})
+}
+
+// RevokeHost revokes an agent's credential, blocks it from enrolling again and
+// drops its host from the inventory
+func (h *HostsHandler) RevokeHost(c *gin.Context) {
+	id := c.Param("id")
+	if err := h.enroller.Revoke(id); err != nil {
+		if errors.Is(err, database.ErrAgentNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Agent not enrolled"})
+			return
+		}
+		slog.Error("Failed to revoke agent", "agent", id, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke agent"})
+		return
+	}
+	h.registry.Remove(id)
+	c.JSON(http.StatusOK, gin.H{"id": id, "revoked": true})
+}
+
+// parseLabelSelector parses selectors such as "env=prod,role=web"
+func parseLabelSelector(s string) (map[string]string, bool) {
+	selector := make(map[string]string)
+	for _, pair := range strings.Split(s, ",") {
+		if pair = strings.TrimSpace(pair); pair == "" {
+			continue
+		}
+		name, value, ok := strings.Cut(pair, "=")
+		if !ok || name == "" {
+			return nil, false
+		}
+		selector[strings.TrimSpace(name)] = strings.TrimSpace(value)
+	}
+	return selector, true
+}
+
+// ListHosts returns the registered hosts, optionally filtered with
+// ?status=up|stale, ?health=healthy|degraded|unhealthy and ?labels=k=v,...
+func (h *HostsHandler) ListHosts(c *gin.Context) {
+	status := models.HostStatus(c.Query("status"))
+	health := models.HostHealth(c.Query("health"))
+	selector, ok := parseLabelSelector(c.Query("labels"))
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid labels selector: expected name=value pairs separated by commas"})
+		return
+	}
+
+	hosts := []models.Host{}
+	counts := map[models.HostStatus]int{models.HostUp: 0, models.HostStale: 0}
+	for _, host := range h.registry.List() {
+		if status != "" && host.Status != status {
+			continue
+		}
+		if health != "" && host.Health != health {
+			continue
+		}
+		if !matchesLabels(host.Labels, selector) {
+			continue
+		}
+		hosts = append(hosts, host)
+		counts[host.Status]++
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"hosts":       hosts,
+		"total":       len(hosts),
+		"up":          counts[models.HostUp],
+		"stale":       counts[models.HostStale],
+		"stale_after": h.registry.StaleAfter().String(),
+	})
+}
+
+// matchesLabels reports whether labels contain every pair in selector
+func matchesLabels(labels, selector map[string]string) bool {
+	for name, value := range selector {
+		if labels[name] != value {
+			return false
+		}
+	}
+	return true
+}
+
+// GetHost returns one registered host
+func (h *HostsHandler) GetHost(c *gin.Context) {
+	host, ok := h.registry.Get(c.Param("id"))
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Host not found"})
+		return
+	}
+	c.JSON(http.StatusOK, host)
+}
+
+// DeleteHost forgets a host, e.g. a decommissioned one, and its enrollment,
+// lifting a revocation; it registers again with its next heartbeat or enrollment
+func (h *HostsHandler) DeleteHost(c *gin.Context) {
+	id := c.Param("id")
+	found := h.registry.Remove(id)
+	if h.enroller != nil {
+		switch err := h.enroller.Forget(id); {
+		case err == nil:
+			found = true
+		case !errors.Is(err, database.ErrAgentNotFound):
+			slog.Error("Failed to delete agent enrollment", "agent", id, "error", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete agent enrollment"})
+			return
+		}
+	}
+	if !found {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Host not found"})
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,281 @@
+// File: internal/handlers/incidents.go
+// Brief: Incident timeline API handlers
+// Detailed: Serves /api/incidents, grouping the alert state changes, acknowledgments, silences and task runs of the lookback period into incidents with a timeline, /api/incidents/:id for a single incident and /api/incidents/:id/export for a zip bundle with everything needed for its postmortem. Incidents are built from the stored history on each request.
+// Author: drama.lin@aver.com
+// Date: 2026-10-14
+
+package handlers
+
+import (
+	"bytes"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"argus/internal/auth"
+	"argus/internal/database"
+	"argus/internal/incidents"
+	"argus/internal/metrics"
+	"argus/internal/models"
+	"argus/internal/services"
+)
+
+// DefaultIncidentLookback is how far back incidents are looked for
+const DefaultIncidentLookback = 7 * 24 * time.Hour
+
+// DefaultIncidentLimit is the number of incidents listed without ?limit=
+const DefaultIncidentLimit = 50
+
+// MetricHistorySource provides the recorded values of an alert's metric
+type MetricHistorySource interface {
+	MetricHistory(config *models.AlertConfig, from, to time.Time) ([]metrics.Series, error)
+}
+
+// IncidentsHandler serves the incident endpoints
+type IncidentsHandler struct {
+	alertStore database.AlertStore
+	notifier   *services.Notifier
+	silences   *database.SilenceStore
+	tasks      models.TaskRepository
+	history    MetricHistorySource
+	window     time.Duration
+	lookback   time.Duration
+}
+
+// incidentMetrics is the metric history slice of an alert in an export
+type incidentMetrics struct {
+	AlertID string           `json:"alert_id"`
+	From    time.Time        `json:"from"`
+	To      time.Time        `json:"to"`
+	Series  []metrics.Series `json:"series"`
+	Error   string           `json:"error,omitempty"` // Why the metric has no history
+}
+
+// incidentNotifications are the notifications sent for the alerts of an export
+type incidentNotifications struct {
+	Deliveries []services.Delivery        `json:"deliveries"`
+	InApp      []models.InAppNotification `json:"in_app"`
+	DeadLetter []services.DeadLetter      `json:"dead_letters"`
+}
+
+// NewIncidentsHandler creates an incidents handler correlating activity
+// within window over the last lookback. silences and tasks may be nil,
+// which leaves their events out of the timelines.
+func NewIncidentsHandler(alertStore database.AlertStore, notifier *services.Notifier, silences *database.SilenceStore, tasks models.TaskRepository, window, lookback time.Duration) *IncidentsHandler {
+	if lookback <= 0 {
+		lookback = DefaultIncidentLookback
+	}
+	return &IncidentsHandler{alertStore: alertStore, notifier: notifier, silences: silences, tasks: tasks, window: window, lookback: lookback}
+}
+
+// SetMetricHistory sets the source of the metric history slices included in
+// exports; without one they are left out
+func (h *IncidentsHandler) SetMetricHistory(history MetricHistorySource) {
+	h.history = history
+}
+
+// RegisterRoutes registers the incident routes to the given router group
+func (h *IncidentsHandler) RegisterRoutes(router *gin.RouterGroup) {
+	router.GET("/incidents", h.ListIncidents)
+	router.GET("/incidents/:id", h.GetIncident)
+	router.POST("/incidents/:id/export", h.ExportIncident)
+	Annotate(router, auth.RoleViewer, "POST /incidents/:id/export")
+}
+
+// build returns the incidents since the given time, newest first
+func (h *IncidentsHandler) build(c *gin.Context, since time.Time) ([]*incidents.Incident, error) {
+	alerts, err := h.alertStore.ListAlerts()
+	if err != nil {
+		return nil, err
+	}
+	src := incidents.Source{
+		Alerts: alerts,
+		History: func(alertID string) ([]models.AlertHistoryEntry, error) {
+			return h.alertStore.GetHistory(alertID, 0)
+		},
+	}
+	if h.notifier != nil {
+		src.Notifications = h.notifier.GetNotifications()
+	}
+	if h.silences != nil {
+		src.Silences = h.silences.List()
+	}
+	if h.tasks != nil {
+		tasks, err := h.tasks.ListTasks(c.Request.Context())
+		if err != nil {
+			return nil, err
+		}
+		for _, task := range tasks {
+			runs, err := h.tasks.GetTaskExecutions(c.Request.Context(), task.ID, 0)
+			if err != nil {
+				slog.Warn("Failed to read task executions for incidents", "task_id", task.ID, "error", err)
+				continue
+			}
+			for _, run := range runs {
+				if !run.StartTime.Before(since.Add(-h.window)) {
+					src.Executions = append(src.Executions, run)
+				}
+			}
+		}
+	}
+	return incidents.Build(src, incidents.Options{Window: h.window, Since: since}, time.Now().UTC()), nil
+}
+
+// ListIncidents returns the incidents of the lookback period, newest first.
+// ?since= (RFC 3339 or Unix seconds) changes the start, ?status= keeps
+// active or resolved incidents and ?limit= bounds the list.
+func (h *IncidentsHandler) ListIncidents(c *gin.Context) {
+	since := time.Now().UTC().Add(-h.lookback)
+	if s := c.Query("since"); s != "" {
+		t, err := parseQueryTime(s)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, models.APIResponse{Success: false, Error: err.Error()})
+			return
+		}
+		since = t
+	}
+	status := c.Query("status")
+	if status != "" && status != incidents.StatusActive && status != incidents.StatusResolved {
+		c.JSON(http.StatusBadRequest, models.APIResponse{Success: false, Error: "Invalid status: " + status})
+		return
+	}
+	limit := DefaultIncidentLimit
+	if s := c.Query("limit"); s != "" {
+		n, err := strconv.Atoi(s)
+		if err != nil || n <= 0 {
+			c.JSON(http.StatusBadRequest, models.APIResponse{Success: false, Error: "Invalid limit: " + s})
+			return
+		}
+		limit = n
+	}
+
+	all, err := h.build(c, since)
+	if err != nil {
+		slog.Error("Failed to build incidents", "error", err)
+		c.JSON(http.StatusInternalServerError, models.APIResponse{Success: false, Error: "Failed to build incidents: " + err.Error()})
+		return
+	}
+	result := make([]*incidents.Incident, 0, min(len(all), limit))
+	for _, incident := range all {
+		if len(result) == limit {
+			break
+		}
+		if status == "" || incident.Status == status {
+			result = append(result, incident)
+		}
+	}
+	c.JSON(http.StatusOK, models.APIResponse{Success: true, Data: result})
+}
+
+// GetIncident returns one incident of the lookback period
+func (h *IncidentsHandler) GetIncident(c *gin.Context) {
+	incident, err := h.incident(c)
+	if err != nil {
+		slog.Error("Failed to build incidents", "error", err)
+		c.JSON(http.StatusInternalServerError, models.APIResponse{Success: false, Error: "Failed to build incidents: " + err.Error()})
+		return
+	}
+	if incident == nil {
+		c.JSON(http.StatusNotFound, models.APIResponse{Success: false, Error: "Incident not found"})
+		return
+	}
+	c.JSON(http.StatusOK, models.APIResponse{Success: true, Data: incident})
+}
+
+// incident returns the incident named by the :id parameter, nil when there
+// is none in the lookback period
+func (h *IncidentsHandler) incident(c *gin.Context) (*incidents.Incident, error) {
+	all, err := h.build(c, time.Now().UTC().Add(-h.lookback))
+	if err != nil {
+		return nil, err
+	}
+	for _, incident := range all {
+		if incident.ID == c.Param("id") {
+			return incident, nil
+		}
+	}
+	return nil, nil
+}
+
+// ExportIncident returns a zip bundle for the postmortem of an incident: the
+// incident and its timeline, the configs of its alerts, their metric history
+// from one window before the start to one window after the resolution, and
+// the notifications sent for them in that span.
+func (h *IncidentsHandler) ExportIncident(c *gin.Context) {
+	incident, err := h.incident(c)
+	if err != nil {
+		slog.Error("Failed to build incidents", "error", err)
+		c.JSON(http.StatusInternalServerError, models.APIResponse{Success: false, Error: "Failed to build incidents: " + err.Error()})
+		return
+	}
+	if incident == nil {
+		c.JSON(http.StatusNotFound, models.APIResponse{Success: false, Error: "Incident not found"})
+		return
+	}
+
+	window := h.window
+	if window <= 0 {
+		window = incidents.DefaultWindow
+	}
+	from, to := incident.StartedAt.Add(-window), time.Now().UTC()
+	if incident.ResolvedAt != nil && incident.ResolvedAt.Add(window).Before(to) {
+		to = incident.ResolvedAt.Add(window)
+	}
+	involved := make(map[string]bool, len(incident.Alerts))
+	for _, ref := range incident.Alerts {
+		involved[ref.ID] = true
+	}
+
+	alerts := make([]*models.AlertConfig, 0, len(incident.Alerts))
+	var files []incidents.File
+	for _, ref := range incident.Alerts {
+		config, err := h.alertStore.GetAlert(ref.ID)
+		if err != nil {
+			// Deleted since it fired; the incident still names it
+			continue
+		}
+		alerts = append(alerts, config)
+		if h.history == nil {
+			continue
+		}
+		slice := incidentMetrics{AlertID: ref.ID, From: from, To: to, Series: []metrics.Series{}}
+		if series, err := h.history.MetricHistory(config, from, to); err != nil {
+			slice.Error = err.Error()
+		} else if series != nil {
+			slice.Series = series
+		}
+		files = append(files, incidents.File{Name: fmt.Sprintf("metrics/%s.json", ref.ID), Data: slice})
+	}
+	files = append([]incidents.File{{Name: "alerts.json", Data: alerts}}, files...)
+
+	notifications := incidentNotifications{Deliveries: []services.Delivery{}, InApp: []models.InAppNotification{}, DeadLetter: []services.DeadLetter{}}
+	if h.notifier != nil {
+		for _, ref := range incident.Alerts {
+			notifications.Deliveries = append(notifications.Deliveries, h.notifier.Deliveries().ForAlert(ref.ID, from, to)...)
+		}
+		for _, n := range h.notifier.GetNotifications() {
+			if involved[n.AlertID] && !n.Timestamp.Before(from) && !n.Timestamp.After(to) {
+				notifications.InApp = append(notifications.InApp, n)
+			}
+		}
+		for _, letter := range h.notifier.DeadLetters().List() {
+			if involved[letter.AlertID] && !letter.Timestamp.Before(from) && !letter.Timestamp.After(to) {
+				notifications.DeadLetter = append(notifications.DeadLetter, letter)
+			}
+		}
+	}
+	files = append(files, incidents.File{Name: "notifications.json", Data: notifications})
+
+	var buf bytes.Buffer
+	if err := incidents.WriteBundle(&buf, incident, files); err != nil {
+		slog.Error("Failed to write incident export", "incident_id", incident.ID, "error", err)
+		c.JSON(http.StatusInternalServerError, models.APIResponse{Success: false, Error: "Failed to write export: " + err.Error()})
+		return
+	}
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="incident-%s.zip"`, incident.ID))
+	c.Data(http.StatusOK, "application/zip", buf.Bytes())
+}
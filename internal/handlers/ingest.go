@@ -0,0 +1,85 @@
+// File: internal/handlers/ingest.go
+// Brief: HTTP endpoints for externally pushed metrics
+// Detailed: Receives Prometheus remote-write requests, stores the selected series in the series store that series alerts evaluate, and lists what has been ingested.
+// Author: drama.lin@aver.com
+// Date: 2026-10-14
+
+package handlers
+
+import (
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"argus/internal/auth"
+	"argus/internal/ingest"
+	"argus/internal/metrics"
+)
+
+// MaxRemoteWriteDecodedBytes bounds the decompressed size of a remote-write request
+const MaxRemoteWriteDecodedBytes = 32 << 20
+
+// IngestHandler manages the metric ingestion endpoints
+type IngestHandler struct {
+	store    *metrics.SeriesStore
+	selector ingest.Selector
+}
+
+// NewIngestHandler creates a handler storing the series selected by selector in store
+func NewIngestHandler(store *metrics.SeriesStore, selector ingest.Selector) *IngestHandler {
+	return &IngestHandler{store: store, selector: selector}
+}
+
+// RegisterRoutes registers the ingestion routes to the given router group
+func (h *IngestHandler) RegisterRoutes(router *gin.RouterGroup) {
+	group := router.Group("/ingest")
+	{
+		group.POST("/remote-write", h.RemoteWrite)
+		group.GET("/series", h.ListSeries)
+	}
+	Annotate(group, auth.RoleOperator, "POST /remote-write")
+}
+
+// RemoteWrite accepts a snappy-compressed Prometheus WriteRequest. Malformed
+// requests get 400 so Prometheus drops them instead of retrying.
+func (h *IngestHandler) RemoteWrite(c *gin.Context) {
+	if enc := c.GetHeader("Content-Encoding"); enc != "" && !strings.EqualFold(enc, "snappy") {
+		c.JSON(http.StatusUnsupportedMediaType, gin.H{"error": "Unsupported content encoding: " + enc})
+		return
+	}
+
+	body, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read request body: " + err.Error()})
+		return
+	}
+	data, err := ingest.DecodeSnappy(body, MaxRemoteWriteDecodedBytes)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to decompress request: " + err.Error()})
+		return
+	}
+	series, err := ingest.ParseWriteRequest(data)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to decode request: " + err.Error()})
+		return
+	}
+
+	result := ingest.Store(h.store, series, h.selector)
+	if result.Rejected > 0 {
+		slog.Warn("Remote-write series rejected", "rejected", result.Rejected, "error", metrics.ErrTooManySeries)
+	}
+	slog.Debug("Remote-write request stored", "series", result.Series, "samples", result.Samples, "skipped", result.Skipped)
+	c.Status(http.StatusNoContent)
+}
+
+// ListSeries returns a summary of every ingested series (?name= filters by metric name)
+func (h *IngestHandler) ListSeries(c *gin.Context) {
+	if name := c.Query("name"); name != "" {
+		c.JSON(http.StatusOK, h.store.Latest(name, nil))
+		return
+	}
+	c.JSON(http.StatusOK, h.store.List())
+}
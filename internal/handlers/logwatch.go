@@ -0,0 +1,46 @@
+// File: internal/handlers/logwatch.go
+// Brief: API for the log watches
+// Detailed: Reports every log watch with its source, match count over the window, total matches, source errors and most recent matching entries.
+// Author: drama.lin@aver.com
+// Date: 2026-10-14
+
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"argus/internal/logwatch"
+)
+
+// LogWatchHandler manages the log watch endpoints
+type LogWatchHandler struct {
+	watcher *logwatch.Watcher
+}
+
+// NewLogWatchHandler creates a handler for the given watcher
+func NewLogWatchHandler(watcher *logwatch.Watcher) *LogWatchHandler {
+	return &LogWatchHandler{watcher: watcher}
+}
+
+// RegisterRoutes registers the log watch routes to the given router group
+func (h *LogWatchHandler) RegisterRoutes(router *gin.RouterGroup) {
+	router.GET("/logwatch", h.ListWatches)
+	router.GET("/logwatch/:name", h.GetWatch)
+}
+
+// ListWatches reports the state of every watch
+func (h *LogWatchHandler) ListWatches(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"watches": h.watcher.Statuses()})
+}
+
+// GetWatch reports the state of one watch
+func (h *LogWatchHandler) GetWatch(c *gin.Context) {
+	status, ok := h.watcher.Status(c.Param("name"))
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Log watch not found"})
+		return
+	}
+	c.JSON(http.StatusOK, status)
+}
@@ -1,6 +1,6 @@
 // File: internal/handlers/metrics.go
 // Brief: HTTP handlers for metrics endpoints using centralized collector
-// Detailed: Implements Gin HTTP handlers for CPU, memory, network, and process metrics that use the centralized metrics collector for improved performance.
+// Detailed: Implements Gin HTTP handlers for CPU, memory, disk, network, and process metrics that use the centralized metrics collector for improved performance.
 // Author: drama.lin@aver.com
 // Date: 2024-07-04
 
@@ -9,24 +9,68 @@ package handlers
 import (
 	"log/slog"
 	"net/http"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
 
 	"argus/internal/metrics"
+	"argus/internal/models"
+	"argus/internal/usage"
 
 	"github.com/gin-gonic/gin"
 )
 
+// DefaultBatchTopProcesses is the number of top processes included in the batch snapshot
+const DefaultBatchTopProcesses = 10
+
+// AlertStatusProvider exposes the current alert statuses to the metrics handlers
+type AlertStatusProvider interface {
+	GetAllAlertStatus() map[string]*models.AlertStatus
+}
+
+// SelfMetricsFunc returns one section of Argus's own operational metrics
+type SelfMetricsFunc func() interface{}
+
 // MetricsHandler provides HTTP handlers for metrics endpoints
 type MetricsHandler struct {
-	collector *metrics.Collector
+	collector   *metrics.Collector
+	alertStatus AlertStatusProvider
+	apiUsage    *usage.Tracker
+	startedAt   time.Time
+
+	selfMu      sync.RWMutex
+	selfMetrics map[string]SelfMetricsFunc
 }
 
 // NewMetricsHandler creates a new metrics handler instance
 func NewMetricsHandler(collector *metrics.Collector) *MetricsHandler {
 	return &MetricsHandler{
-		collector: collector,
+		collector:   collector,
+		startedAt:   time.Now(),
+		selfMetrics: make(map[string]SelfMetricsFunc),
 	}
 }
 
+// RegisterSelfMetrics adds a named section to the self-metrics response,
+// replacing any section previously registered under that name
+func (h *MetricsHandler) RegisterSelfMetrics(name string, fn SelfMetricsFunc) {
+	h.selfMu.Lock()
+	defer h.selfMu.Unlock()
+	h.selfMetrics[name] = fn
+}
+
+// SetAlertStatusProvider sets the source of alert statuses used in the batch snapshot
+func (h *MetricsHandler) SetAlertStatusProvider(provider AlertStatusProvider) {
+	h.alertStatus = provider
+}
+
+// SetAPIUsage sets the tracker served by the API usage endpoint
+func (h *MetricsHandler) SetAPIUsage(tracker *usage.Tracker) {
+	h.apiUsage = tracker
+}
+
 // GetCPU handles CPU metrics requests
 func (h *MetricsHandler) GetCPU(c *gin.Context) {
 	slog.Debug("Fetching cached CPU metrics")
@@ -108,6 +152,7 @@ func (h *MetricsHandler) GetNetwork(c *gin.Context) {
 		"bytes_recv":   networkMetrics.BytesRecv,
 		"packets_sent": networkMetrics.PacketsSent,
 		"packets_recv": networkMetrics.PacketsRecv,
+		"interfaces":   networkMetrics.Interfaces,
 	})
 }
 
@@ -215,6 +260,12 @@ func (h *MetricsHandler) GetProcess(c *gin.Context) {
 			"cpu_percent": p.CPUPercent,
 			"mem_percent": p.MemPercent,
 		}
+		if p.Limits != nil {
+			processes[i]["limits"] = p.Limits
+		}
+		if p.Details != nil {
+			processes[i]["details"] = p.Details
+		}
 	}
 
 	// Calculate pagination metadata
@@ -243,7 +294,8 @@ func (h *MetricsHandler) GetProcess(c *gin.Context) {
 			"name_contains": params.NameContains,
 			"top_n":         params.TopN,
 		},
-		"updated_at": processMetrics.UpdatedAt,
+		"updated_at":   processMetrics.UpdatedAt,
+		"initializing": processMetrics.Initializing,
 	}
 
 	slog.Debug("Process metrics retrieved with optimization",
@@ -261,13 +313,194 @@ func (h *MetricsHandler) GetProcess(c *gin.Context) {
 func (h *MetricsHandler) GetMetricsHealth(c *gin.Context) {
 	healthy := h.collector.IsHealthy()
 
-	status := "healthy"
-	if !healthy {
+	// initializing while warming up, degraded when only some metric kinds
+	// are collected, unhealthy when none is
+	status := h.collector.Status()
+	if status == metrics.StatusStale {
 		status = "unhealthy"
 	}
 
 	c.JSON(http.StatusOK, gin.H{
-		"status":  status,
-		"healthy": healthy,
+		"status":       status,
+		"healthy":      healthy,
+		"initializing": status == metrics.StatusInitializing,
+		"metrics":      h.collector.Health(),
+	})
+}
+
+// GetSelfMetrics returns Argus's own runtime statistics plus every registered
+// component section (e.g. alert store cache hits/misses)
+func (h *MetricsHandler) GetSelfMetrics(c *gin.Context) {
+	var mem runtime.MemStats
+	runtime.ReadMemStats(&mem)
+
+	response := gin.H{
+		"runtime": gin.H{
+			"goroutines":     runtime.NumGoroutine(),
+			"heap_alloc":     mem.HeapAlloc,
+			"heap_objects":   mem.HeapObjects,
+			"num_gc":         mem.NumGC,
+			"uptime_seconds": int64(time.Since(h.startedAt).Seconds()),
+		},
+		"timestamp": time.Now(),
+	}
+
+	h.selfMu.RLock()
+	for name, fn := range h.selfMetrics {
+		response[name] = fn()
+	}
+	h.selfMu.RUnlock()
+
+	c.JSON(http.StatusOK, response)
+}
+
+// GetAPIUsage returns request counts, status classes and latencies per
+// namespace and token, as JSON or, with ?format=prometheus or a text/plain
+// Accept header, as Prometheus counters and histograms
+func (h *MetricsHandler) GetAPIUsage(c *gin.Context) {
+	if h.apiUsage == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "API usage metrics are disabled"})
+		return
+	}
+
+	accept := c.GetHeader("Accept")
+	if c.Query("format") == "prometheus" || strings.Contains(accept, "text/plain") || strings.Contains(accept, "application/openmetrics-text") {
+		c.Header("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		c.Status(http.StatusOK)
+		if err := h.apiUsage.WritePrometheus(c.Writer); err != nil {
+			slog.Error("Failed to write API usage metrics", "error", err)
+		}
+		return
+	}
+	c.JSON(http.StatusOK, h.apiUsage.Snapshot())
+}
+
+// GetDisk handles disk metrics requests
+func (h *MetricsHandler) GetDisk(c *gin.Context) {
+	slog.Debug("Fetching cached disk metrics")
+
+	diskMetrics := h.collector.GetDiskMetrics()
+	if diskMetrics == nil {
+		slog.Error("Disk metrics not available")
+		c.JSON(http.StatusServiceUnavailable, gin.H{
+			"error": "Disk metrics not available",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"total":        diskMetrics.Total,
+		"used":         diskMetrics.Used,
+		"free":         diskMetrics.Free,
+		"used_percent": diskMetrics.UsedPercent,
+		"partitions":   diskMetrics.Partitions,
 	})
 }
+
+// GetRAID handles RAID array and ZFS pool health requests
+func (h *MetricsHandler) GetRAID(c *gin.Context) {
+	slog.Debug("Fetching cached RAID metrics")
+
+	raidMetrics := h.collector.GetRAIDMetrics()
+	if raidMetrics == nil {
+		slog.Error("RAID metrics not available")
+		c.JSON(http.StatusServiceUnavailable, gin.H{
+			"error": "RAID metrics not available",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"arrays":   raidMetrics.Arrays,
+		"pools":    raidMetrics.Pools,
+		"degraded": raidMetrics.Degraded(),
+	})
+}
+
+// GetPower handles battery and UPS requests
+func (h *MetricsHandler) GetPower(c *gin.Context) {
+	slog.Debug("Fetching cached power metrics")
+
+	powerMetrics := h.collector.GetPowerMetrics()
+	if powerMetrics == nil {
+		slog.Error("Power metrics not available")
+		c.JSON(http.StatusServiceUnavailable, gin.H{
+			"error": "Power metrics not available",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, powerMetrics)
+}
+
+// GetAllMetrics returns a combined snapshot of all cached metrics in a single response.
+// Sections whose cache is empty or expired are returned as null rather than failing the request.
+func (h *MetricsHandler) GetAllMetrics(c *gin.Context) {
+	slog.Debug("Fetching combined metrics snapshot")
+
+	topN := DefaultBatchTopProcesses
+	if topStr := c.Query("top_n"); topStr != "" {
+		parsed, err := strconv.Atoi(topStr)
+		if err != nil || parsed <= 0 || parsed > 500 {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Invalid top_n. Must be an integer between 1 and 500",
+			})
+			return
+		}
+		topN = parsed
+	}
+
+	response := gin.H{
+		"cpu":       h.collector.GetCPUMetrics(),
+		"memory":    h.collector.GetMemoryMetrics(),
+		"disk":      h.collector.GetDiskMetrics(),
+		"raid":      h.collector.GetRAIDMetrics(),
+		"power":     h.collector.GetPowerMetrics(),
+		"network":   h.collector.GetNetworkMetrics(),
+		"processes": nil,
+		"alerts":    h.buildAlertSummary(),
+		"healthy":   h.collector.IsHealthy(),
+		"status":    h.collector.Status(),
+		"timestamp": time.Now(),
+	}
+
+	processes, totalCount, err := h.collector.GetOptimizedProcessMetrics(metrics.ProcessFilter{
+		Limit:     topN,
+		SortBy:    "cpu",
+		SortOrder: "desc",
+		TopN:      topN,
+	})
+	if err != nil {
+		slog.Debug("Process metrics not available for snapshot", "error", err)
+	} else {
+		response["processes"] = gin.H{
+			"top":         processes,
+			"total_count": totalCount,
+		}
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// buildAlertSummary counts alerts by state for the batch snapshot
+func (h *MetricsHandler) buildAlertSummary() gin.H {
+	if h.alertStatus == nil {
+		return nil
+	}
+
+	statuses := h.alertStatus.GetAllAlertStatus()
+	byState := map[models.AlertState]int{
+		models.StateActive:   0,
+		models.StatePending:  0,
+		models.StateResolved: 0,
+		models.StateInactive: 0,
+	}
+	for _, status := range statuses {
+		byState[status.State]++
+	}
+
+	return gin.H{
+		"total":    len(statuses),
+		"by_state": byState,
+	}
+}
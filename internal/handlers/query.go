@@ -0,0 +1,108 @@
+// File: internal/handlers/query.go
+// Brief: Metric history query endpoint
+// Detailed: Serves /api/metrics/query, which evaluates a query such as avg by (host) (cpu_usage_percent) over a time range at a given step against the recorded metric history and the ingested series, so clients get aggregated series instead of fetching raw samples.
+// Author: drama.lin@aver.com
+// Date: 2026-10-14
+
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"argus/internal/query"
+)
+
+// Defaults for history queries
+const (
+	DefaultQueryRange  = time.Hour
+	DefaultQueryPoints = 240 // Points per series when no step is given
+)
+
+// QueryHandler serves queries over the metric history
+type QueryHandler struct {
+	sources []query.Source
+}
+
+// NewQueryHandler creates a handler querying the given sources
+func NewQueryHandler(sources ...query.Source) *QueryHandler {
+	return &QueryHandler{sources: sources}
+}
+
+// RegisterRoutes registers the query route to the given router group
+func (h *QueryHandler) RegisterRoutes(router *gin.RouterGroup) {
+	router.GET("/metrics/query", h.Query)
+}
+
+// parseQueryTime accepts RFC 3339 times and Unix seconds
+func parseQueryTime(s string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	if secs, err := strconv.ParseFloat(s, 64); err == nil {
+		return time.Unix(0, int64(secs*float64(time.Second))).UTC(), nil
+	}
+	return time.Time{}, fmt.Errorf("invalid time %q: expected RFC 3339 or Unix seconds", s)
+}
+
+// queryRange reads ?start, ?end, ?range and ?step. Without start the range
+// (default 1h) ends at end (default now); without step the range is split
+// into DefaultQueryPoints steps.
+func queryRange(c *gin.Context) (query.Range, error) {
+	var r query.Range
+	r.End = time.Now().UTC()
+	if s := c.Query("end"); s != "" {
+		end, err := parseQueryTime(s)
+		if err != nil {
+			return r, err
+		}
+		r.End = end
+	}
+	if s := c.Query("start"); s != "" {
+		start, err := parseQueryTime(s)
+		if err != nil {
+			return r, err
+		}
+		r.Start = start
+	} else {
+		span := DefaultQueryRange
+		if s := c.Query("range"); s != "" {
+			d, err := time.ParseDuration(s)
+			if err != nil || d <= 0 {
+				return r, fmt.Errorf("invalid range %q: must be a positive duration", s)
+			}
+			span = d
+		}
+		r.Start = r.End.Add(-span)
+	}
+	if s := c.Query("step"); s != "" {
+		d, err := time.ParseDuration(s)
+		if err != nil || d <= 0 {
+			return r, fmt.Errorf("invalid step %q: must be a positive duration", s)
+		}
+		r.Step = d
+	} else {
+		r.Step = max(r.End.Sub(r.Start)/DefaultQueryPoints, time.Second).Round(time.Second)
+	}
+	return r, r.Validate()
+}
+
+// Query evaluates ?query over the requested range, e.g.
+// ?query=avg by (host) (node_load1)&range=6h&step=5m
+func (h *QueryHandler) Query(c *gin.Context) {
+	q, err := query.Parse(c.Query("query"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid query: " + err.Error()})
+		return
+	}
+	r, err := queryRange(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid range: " + err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, query.Evaluate(q, h.sources, r))
+}
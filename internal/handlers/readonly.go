@@ -0,0 +1,59 @@
+// File: internal/handlers/readonly.go
+// Brief: Admin API for the global read-only mode
+// Detailed: Reports and switches read-only mode, in which the API rejects mutating requests while evaluation and scheduling continue.
+// Author: drama.lin@aver.com
+// Date: 2026-10-14
+
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ReadOnlySwitch is the read-only state managed by ReadOnlyHandler
+type ReadOnlySwitch interface {
+	ReadOnly() bool
+	SetReadOnly(enabled bool)
+}
+
+// ReadOnlyHandler manages the read-only admin endpoints
+type ReadOnlyHandler struct {
+	mode ReadOnlySwitch
+}
+
+// NewReadOnlyHandler creates a handler for the given read-only state
+func NewReadOnlyHandler(mode ReadOnlySwitch) *ReadOnlyHandler {
+	return &ReadOnlyHandler{mode: mode}
+}
+
+// readOnlyRequest is the body of PUT /admin/read-only
+type readOnlyRequest struct {
+	ReadOnly *bool `json:"read_only" binding:"required"`
+}
+
+// RegisterRoutes registers the read-only routes to the given router group
+func (h *ReadOnlyHandler) RegisterRoutes(router *gin.RouterGroup) {
+	admin := router.Group("/admin/read-only")
+	{
+		admin.GET("", h.GetReadOnly)
+		admin.PUT("", h.SetReadOnly)
+	}
+}
+
+// GetReadOnly reports whether read-only mode is on
+func (h *ReadOnlyHandler) GetReadOnly(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"read_only": h.mode.ReadOnly()})
+}
+
+// SetReadOnly switches read-only mode, e.g. {"read_only": true}
+func (h *ReadOnlyHandler) SetReadOnly(c *gin.Context) {
+	var req readOnlyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid read-only request: " + err.Error()})
+		return
+	}
+	h.mode.SetReadOnly(*req.ReadOnly)
+	c.JSON(http.StatusOK, gin.H{"read_only": h.mode.ReadOnly()})
+}
@@ -0,0 +1,49 @@
+// File: internal/handlers/retention.go
+// Brief: API for the data retention policies
+// Detailed: Reports the maximum age, current usage, last purge and next purge of every stored data type, and the storage budget when one is configured.
+// Author: drama.lin@aver.com
+// Date: 2026-10-14
+
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"argus/internal/retention"
+)
+
+// RetentionHandler manages the retention endpoint
+type RetentionHandler struct {
+	engine *retention.Engine
+	budget *retention.Budget
+}
+
+// NewRetentionHandler creates a handler for the given retention engine
+func NewRetentionHandler(engine *retention.Engine) *RetentionHandler {
+	return &RetentionHandler{engine: engine}
+}
+
+// SetBudget adds the storage budget to the retention report
+func (h *RetentionHandler) SetBudget(budget *retention.Budget) {
+	h.budget = budget
+}
+
+// RegisterRoutes registers the retention routes to the given router group
+func (h *RetentionHandler) RegisterRoutes(router *gin.RouterGroup) {
+	router.GET("/retention", h.GetRetention)
+}
+
+// GetRetention reports the policy and usage of every data type
+func (h *RetentionHandler) GetRetention(c *gin.Context) {
+	response := gin.H{
+		"enabled":  h.engine.Running(),
+		"interval": h.engine.Interval().String(),
+		"policies": h.engine.Status(),
+	}
+	if h.budget != nil {
+		response["budget"] = h.budget.Status()
+	}
+	c.JSON(http.StatusOK, response)
+}
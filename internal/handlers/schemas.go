@@ -0,0 +1,72 @@
+// File: internal/handlers/schemas.go
+// Brief: JSON Schema API handlers
+// Detailed: Serves the JSON Schemas of alert and task definitions so external UIs and CI pipelines can validate configurations with the same rules as the API. The schemas are generated from the models once, at startup.
+// Author: drama.lin@aver.com
+// Date: 2026-10-14
+
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+
+	"github.com/gin-gonic/gin"
+
+	"argus/internal/schema"
+)
+
+// SchemaContentType is the media type of JSON Schema documents
+const SchemaContentType = "application/schema+json"
+
+// SchemasHandler serves the JSON Schema endpoints
+type SchemasHandler struct {
+	names   []string
+	schemas map[string][]byte
+}
+
+// NewSchemasHandler creates a handler serving every schema of package schema
+func NewSchemasHandler() (*SchemasHandler, error) {
+	h := &SchemasHandler{schemas: make(map[string][]byte)}
+	for name, s := range schema.All() {
+		data, err := json.MarshalIndent(s, "", "  ")
+		if err != nil {
+			return nil, err
+		}
+		h.names = append(h.names, name)
+		h.schemas[name] = data
+	}
+	sort.Strings(h.names)
+	return h, nil
+}
+
+// RegisterRoutes registers the schema routes to the given router group
+func (h *SchemasHandler) RegisterRoutes(router *gin.RouterGroup) {
+	router.GET("/schemas", h.ListSchemas)
+	router.GET("/schemas/:name", h.GetSchema)
+}
+
+// schemaLink names a schema and where to fetch it
+type schemaLink struct {
+	Name string `json:"name"`
+	URL  string `json:"url"`
+}
+
+// ListSchemas lists the available schemas
+func (h *SchemasHandler) ListSchemas(c *gin.Context) {
+	result := make([]schemaLink, 0, len(h.names))
+	for _, name := range h.names {
+		result = append(result, schemaLink{Name: name, URL: schema.IDBase + name})
+	}
+	c.JSON(http.StatusOK, gin.H{"schemas": result})
+}
+
+// GetSchema returns one schema, e.g. /api/schemas/alert
+func (h *SchemasHandler) GetSchema(c *gin.Context) {
+	data, ok := h.schemas[c.Param("name")]
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Schema not found"})
+		return
+	}
+	c.Data(http.StatusOK, SchemaContentType, data)
+}
@@ -0,0 +1,170 @@
+// File: internal/handlers/services.go
+// Brief: System services health panel
+// Detailed: Composes the states of the critical daemons gathered by the service collector (time synchronization, SSH, cron, Docker by default) and the clock synchronization into one panel payload with an overall status, listing the service alerts wired to each service.
+// Author: drama.lin@aver.com
+// Date: 2026-10-14
+
+package handlers
+
+import (
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"argus/internal/database"
+	"argus/internal/metrics"
+	"argus/internal/models"
+	"argus/internal/widgets"
+)
+
+// States of a service in the panel
+const (
+	ServiceOK           = "ok"
+	ServiceStarting     = "starting" // activating or deactivating
+	ServiceDown         = "down"
+	ServiceNotInstalled = "not_installed"
+	ServiceUnknown      = "unknown" // systemd is not available
+)
+
+// ServicePanelEntry is one service of the panel
+type ServicePanelEntry struct {
+	metrics.ServiceState
+	Status string          `json:"status"`
+	Alerts []widgets.Alert `json:"alerts"` // Service alerts on this service
+}
+
+// ServicePanel is the system services panel payload
+type ServicePanel struct {
+	Status    string              `json:"status"` // healthy, degraded or unknown
+	Available bool                `json:"available"`
+	Services  []ServicePanelEntry `json:"services"`
+	TimeSync  *metrics.TimeSync   `json:"time_sync,omitempty"`
+	Alerts    []widgets.Alert     `json:"alerts"` // Service alerts covering every service
+	UpdatedAt time.Time           `json:"updated_at"`
+}
+
+// ServicesHandler serves the system services panel
+type ServicesHandler struct {
+	collector  *metrics.Collector
+	alertStore database.AlertStore
+	statuses   AlertStatusProvider
+}
+
+// NewServicesHandler creates a services panel handler. alertStore and
+// statuses may be nil, which leaves the panel without alerts.
+func NewServicesHandler(collector *metrics.Collector, alertStore database.AlertStore, statuses AlertStatusProvider) *ServicesHandler {
+	return &ServicesHandler{collector: collector, alertStore: alertStore, statuses: statuses}
+}
+
+// RegisterRoutes registers the services panel route to the given router group
+func (h *ServicesHandler) RegisterRoutes(router *gin.RouterGroup) {
+	router.GET("/services", h.GetPanel)
+}
+
+// GetPanel returns the system services panel
+func (h *ServicesHandler) GetPanel(c *gin.Context) {
+	serviceMetrics := h.collector.GetServiceMetrics()
+	if serviceMetrics == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Service metrics not available"})
+		return
+	}
+	var statuses map[string]*models.AlertStatus
+	if h.statuses != nil {
+		statuses = h.statuses.GetAllAlertStatus()
+	}
+	c.JSON(http.StatusOK, BuildServicePanel(serviceMetrics, h.serviceAlerts(), statuses))
+}
+
+// serviceAlerts returns the enabled service alerts
+func (h *ServicesHandler) serviceAlerts() []*models.AlertConfig {
+	if h.alertStore == nil {
+		return nil
+	}
+	alerts, err := h.alertStore.ListAlerts()
+	if err != nil {
+		slog.Error("Failed to list alerts for the services panel", "error", err)
+		return nil
+	}
+	result := make([]*models.AlertConfig, 0)
+	for _, alert := range alerts {
+		if alert.Enabled && alert.Threshold.MetricType == models.MetricService {
+			result = append(result, alert)
+		}
+	}
+	return result
+}
+
+// BuildServicePanel composes the panel from the collected service states
+// and the service alerts with their evaluated statuses. Clock
+// synchronization alerts belong to the NTP service; alerts without a target
+// cover every service.
+func BuildServicePanel(serviceMetrics *metrics.ServiceMetrics, alerts []*models.AlertConfig, statuses map[string]*models.AlertStatus) ServicePanel {
+	panel := ServicePanel{
+		Status:    "healthy",
+		Available: serviceMetrics.Available,
+		Services:  make([]ServicePanelEntry, 0, len(serviceMetrics.Services)),
+		TimeSync:  serviceMetrics.TimeSync,
+		Alerts:    []widgets.Alert{},
+		UpdatedAt: serviceMetrics.UpdatedAt,
+	}
+	byName := make(map[string]int, len(serviceMetrics.Services))
+	for i, service := range serviceMetrics.Services {
+		entry := ServicePanelEntry{ServiceState: service, Status: serviceStatus(serviceMetrics.Available, service), Alerts: []widgets.Alert{}}
+		if entry.Status != ServiceOK && entry.Status != ServiceNotInstalled {
+			panel.Status = "degraded"
+		}
+		panel.Services = append(panel.Services, entry)
+		byName[service.Name] = i
+		if service.Unit != "" {
+			byName[service.Unit] = i
+		}
+	}
+	if panel.TimeSync != nil && !panel.TimeSync.Synchronized {
+		panel.Status = "degraded"
+	}
+
+	for _, alert := range alerts {
+		item := widgets.Alert{ID: alert.ID, Name: alert.Name, Severity: alert.Severity, State: models.StateInactive,
+			Owner: alert.Owner, Team: alert.Team, GroupID: alert.GroupID}
+		if status, ok := statuses[alert.ID]; ok {
+			item.State, item.Value, item.Since = status.State, status.CurrentValue, status.TriggeredAt
+		}
+		if item.State.Firing() {
+			panel.Status = "degraded"
+		}
+
+		target := ""
+		if alert.Threshold.Target != nil {
+			target = *alert.Threshold.Target
+		}
+		if name := alert.Threshold.MetricName; name == "ntp_synchronized" || name == "time_offset_ms" {
+			target = metrics.ServiceNTP
+		}
+		if i, ok := byName[target]; ok {
+			panel.Services[i].Alerts = append(panel.Services[i].Alerts, item)
+		} else {
+			panel.Alerts = append(panel.Alerts, item)
+		}
+	}
+	if !panel.Available {
+		panel.Status = ServiceUnknown
+	}
+	return panel
+}
+
+// serviceStatus returns the panel state of a service
+func serviceStatus(available bool, service metrics.ServiceState) string {
+	switch {
+	case !available:
+		return ServiceUnknown
+	case !service.Installed:
+		return ServiceNotInstalled
+	case service.Active():
+		return ServiceOK
+	case service.ActiveState == "activating" || service.ActiveState == "deactivating":
+		return ServiceStarting
+	}
+	return ServiceDown
+}
@@ -0,0 +1,111 @@
+// File: internal/handlers/statuspage.go
+// Brief: Public status page endpoints
+// Detailed: Serves the status page of the alert groups published under status_page as /status.json and a minimal HTML page at /status. Both are read-only and meant to be reachable without credentials, so they only expose component names, states and uptime. The page is rebuilt from the alert history at most every statusPageTTL.
+// Author: drama.lin@aver.com
+// Date: 2026-10-14
+
+package handlers
+
+import (
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"argus/internal/database"
+	"argus/internal/models"
+	"argus/internal/statuspage"
+)
+
+// statusPageTTL is how long a built status page is served, as building it
+// reads the history of every published alert
+const statusPageTTL = 30 * time.Second
+
+// StatusPageHandler serves the public status page
+type StatusPageHandler struct {
+	opts       statuspage.Options
+	groups     *database.GroupStore
+	alertStore database.AlertStore
+	statuses   AlertStatusProvider
+
+	mu    sync.Mutex
+	page  *statuspage.Page
+	built time.Time
+}
+
+// NewStatusPageHandler creates a status page handler for the components in
+// opts, backed by the groups and alerts of the stores
+func NewStatusPageHandler(opts statuspage.Options, groups *database.GroupStore, alertStore database.AlertStore, statuses AlertStatusProvider) *StatusPageHandler {
+	return &StatusPageHandler{opts: opts, groups: groups, alertStore: alertStore, statuses: statuses}
+}
+
+// RegisterRoutes registers the status page routes to the given router group,
+// normally the root of the server
+func (h *StatusPageHandler) RegisterRoutes(router *gin.RouterGroup) {
+	router.GET("/status.json", h.GetStatusJSON)
+	router.GET("/status", h.GetStatusHTML)
+}
+
+// current returns the status page, rebuilding it when older than statusPageTTL
+func (h *StatusPageHandler) current() (*statuspage.Page, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	now := time.Now()
+	if h.page != nil && now.Sub(h.built) < statusPageTTL {
+		return h.page, nil
+	}
+
+	alerts, err := h.alertStore.ListAlerts()
+	if err != nil {
+		return nil, err
+	}
+	src := statuspage.Source{
+		Groups:   make(map[string]*models.AlertGroup),
+		Alerts:   alerts,
+		Statuses: h.statuses.GetAllAlertStatus(),
+		History: func(alertID string) ([]models.AlertHistoryEntry, error) {
+			return h.alertStore.GetHistory(alertID, 0)
+		},
+	}
+	if h.groups != nil {
+		for _, group := range h.groups.List() {
+			src.Groups[group.ID] = group
+		}
+	}
+	h.page, h.built = statuspage.Build(h.opts, src, now), now
+	return h.page, nil
+}
+
+// GetStatusJSON returns the status page as JSON. Any origin may read it, so
+// other sites can embed the status.
+func (h *StatusPageHandler) GetStatusJSON(c *gin.Context) {
+	page, err := h.current()
+	if err != nil {
+		slog.Error("Failed to build status page", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Status not available"})
+		return
+	}
+	c.Header("Access-Control-Allow-Origin", "*")
+	c.Header("Cache-Control", "public, max-age=30")
+	c.JSON(http.StatusOK, page)
+}
+
+// GetStatusHTML renders the status page as HTML
+func (h *StatusPageHandler) GetStatusHTML(c *gin.Context) {
+	page, err := h.current()
+	if err != nil {
+		slog.Error("Failed to build status page", "error", err)
+		c.String(http.StatusInternalServerError, "Status not available")
+		return
+	}
+	html, err := page.HTML()
+	if err != nil {
+		slog.Error("Failed to render status page", "error", err)
+		c.String(http.StatusInternalServerError, "Status not available")
+		return
+	}
+	c.Header("Cache-Control", "public, max-age=30")
+	c.Data(http.StatusOK, "text/html; charset=utf-8", html)
+}
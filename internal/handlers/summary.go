@@ -0,0 +1,164 @@
+// File: internal/handlers/summary.go
+// Brief: Alert summary and status badge endpoints
+// Detailed: Serves /api/alerts/summary with alert counts by state and severity, the highest active severity and the unacknowledged count, and /api/badge.svg rendering the same status as an SVG badge for embedding in wikis and READMEs. Also lists the current alert states for dashboard alert list widgets.
+// Author: drama.lin@aver.com
+// Date: 2026-10-14
+
+package handlers
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"argus/internal/badge"
+	"argus/internal/i18n"
+	"argus/internal/models"
+	"argus/internal/services"
+	"argus/internal/widgets"
+)
+
+// DefaultBadgeLabel is the left-hand text of the status badge
+const DefaultBadgeLabel = "alerts"
+
+// badgeColors maps the highest active severity to the badge color
+var badgeColors = map[models.AlertSeverity]string{
+	models.SeverityInfo:     badge.ColorBlue,
+	models.SeverityWarning:  badge.ColorOrange,
+	models.SeverityCritical: badge.ColorRed,
+}
+
+// SetExternalAlerts includes alerts pushed by Alertmanager clients in the
+// alert summary and status badge
+func (h *AlertsHandler) SetExternalAlerts(external *services.ExternalAlerts) {
+	h.external = external
+}
+
+// summarize counts the enabled alerts matching ?owner=, ?team= and ?group=
+// ("none" for ungrouped alerts). External alerts have no owner or group and
+// are only counted when no filter is given. Unread counts are those of the
+// requesting user when users are identified.
+func (h *AlertsHandler) summarize(c *gin.Context) (*models.AlertSummary, error) {
+	alerts, err := h.alertStore.ListAlerts()
+	if err != nil {
+		return nil, err
+	}
+	owner, team := c.Query("owner"), c.Query("team")
+	group, grouped := c.GetQuery("group")
+	if group == "none" {
+		group = ""
+	}
+
+	user := RequestUser(c)
+	unread := make(map[string]bool)
+	unreadCount := 0
+	for _, notification := range h.notifier.GetNotifications() {
+		if !notification.ReadFor(user) {
+			unread[notification.AlertID] = true
+			unreadCount++
+		}
+	}
+
+	summary := models.NewAlertSummary()
+	summary.User = user
+	summary.UnreadNotifications = unreadCount
+	statuses := h.evaluator.GetAllAlertStatus()
+	for _, alert := range alerts {
+		if !alert.Enabled || !alert.OwnedBy(owner, team) || (grouped && alert.GroupID != group) {
+			continue
+		}
+		state := models.StateInactive
+		if status, ok := statuses[alert.ID]; ok {
+			state = status.State
+		}
+		summary.Add(alert.Severity, state, unread[alert.ID])
+	}
+
+	if h.external != nil && owner == "" && team == "" && !grouped {
+		now := time.Now()
+		for _, alert := range h.external.List() {
+			if !alert.Firing(now) {
+				continue
+			}
+			config := alert.Config()
+			summary.Add(config.Severity, models.StateActive, unread[config.ID])
+		}
+	}
+	return summary, nil
+}
+
+// GetAlertSummary returns alert counts by state and severity
+func (h *AlertsHandler) GetAlertSummary(c *gin.Context) {
+	summary, err := h.summarize(c)
+	if err != nil {
+		slog.Error("Failed to summarize alerts", "error", err)
+		c.JSON(http.StatusInternalServerError, models.APIResponse{Success: false, Error: i18n.T(locale(c), i18n.MsgAlertListFailed, err)})
+		return
+	}
+	c.JSON(http.StatusOK, models.APIResponse{Success: true, Data: summary})
+}
+
+// GetBadge renders the alert status as an SVG badge, e.g. "alerts | 2 critical"
+// or "alerts | ok". It takes the summary filters and ?label= to change the
+// left-hand text.
+func (h *AlertsHandler) GetBadge(c *gin.Context) {
+	label := c.DefaultQuery("label", DefaultBadgeLabel)
+	b := badge.Badge{Label: label, Message: "unknown", Color: badge.ColorGrey}
+	if summary, err := h.summarize(c); err != nil {
+		slog.Error("Failed to summarize alerts for badge", "error", err)
+	} else if summary.HighestSeverity == "" {
+		b.Message, b.Color = "ok", badge.ColorGreen
+	} else {
+		b.Message = fmt.Sprintf("%d %s", summary.BySeverity[summary.HighestSeverity], summary.HighestSeverity)
+		b.Color = badgeColors[summary.HighestSeverity]
+	}
+	c.Header("Cache-Control", "no-cache, max-age=0")
+	c.Data(http.StatusOK, "image/svg+xml", b.SVG())
+}
+
+// WidgetAlerts returns the state of every enabled alert and firing external
+// alert for dashboard alert lists. Unacknowledged reflects the global read
+// state, as dashboards are shared displays.
+func (h *AlertsHandler) WidgetAlerts() []widgets.Alert {
+	alerts, err := h.alertStore.ListAlerts()
+	if err != nil {
+		slog.Error("Failed to list alerts for widgets", "error", err)
+		return nil
+	}
+	unread := make(map[string]bool)
+	for _, notification := range h.notifier.GetNotifications() {
+		if !notification.Read {
+			unread[notification.AlertID] = true
+		}
+	}
+
+	statuses := h.evaluator.GetAllAlertStatus()
+	result := make([]widgets.Alert, 0, len(alerts))
+	for _, alert := range alerts {
+		if !alert.Enabled {
+			continue
+		}
+		item := widgets.Alert{ID: alert.ID, Name: alert.Name, Severity: alert.Severity, State: models.StateInactive,
+			Owner: alert.Owner, Team: alert.Team, GroupID: alert.GroupID, Source: models.SourceLocal, Unacknowledged: unread[alert.ID]}
+		if status, ok := statuses[alert.ID]; ok {
+			item.State, item.Value, item.Since = status.State, status.CurrentValue, status.TriggeredAt
+		}
+		result = append(result, item)
+	}
+	if h.external != nil {
+		now := time.Now()
+		for _, alert := range h.external.List() {
+			if !alert.Firing(now) {
+				continue
+			}
+			config := alert.Config()
+			since := alert.StartsAt
+			result = append(result, widgets.Alert{ID: config.ID, Name: config.Name, Severity: config.Severity, State: models.StateActive,
+				Since: &since, Source: alert.Source, Unacknowledged: unread[config.ID]})
+		}
+	}
+	return result
+}
@@ -0,0 +1,37 @@
+package handlers_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"argus/internal/database"
+	"argus/internal/handlers"
+	"argus/internal/models"
+	"argus/internal/services"
+)
+
+func TestGetBadge_PendingCritical(t *testing.T) {
+	alertStore, err := database.NewAlertStore(t.TempDir())
+	require.NoError(t, err)
+	require.NoError(t, alertStore.CreateAlert(seriesAlert("high-metric", "High Metric", models.SeverityCritical)))
+
+	evaluator := startFiringEvaluator(t, alertStore, "high-metric")
+	status, _ := evaluator.GetAlertStatus("high-metric")
+	require.Equal(t, models.StatePending, status.State)
+
+	notifier := services.NewNotifier(services.DefaultConfig())
+	router := setupRouter()
+	handlers.NewAlertsHandler(alertStore, evaluator, notifier).RegisterRoutes(router.Group("/api"))
+
+	req, _ := http.NewRequest(http.MethodGet, "/api/badge.svg", nil)
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+	require.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "image/svg+xml", rr.Header().Get("Content-Type"))
+	assert.Contains(t, rr.Body.String(), "1 critical")
+	assert.NotContains(t, rr.Body.String(), ">ok<")
+}
@@ -0,0 +1,64 @@
+// File: internal/handlers/syslog.go
+// Brief: API for the syslog listener
+// Detailed: Serves the recent messages received by the syslog listener, filtered by host, app name, priority and message text, with the listener's addresses and counters.
+// Author: drama.lin@aver.com
+// Date: 2026-10-14
+
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"argus/internal/logwatch"
+)
+
+// DefaultSyslogMessagesLimit is the number of messages returned when no limit is given
+const DefaultSyslogMessagesLimit = 100
+
+// SyslogHandler manages the syslog endpoints
+type SyslogHandler struct {
+	listener *logwatch.Syslog
+}
+
+// NewSyslogHandler creates a handler for the given listener
+func NewSyslogHandler(listener *logwatch.Syslog) *SyslogHandler {
+	return &SyslogHandler{listener: listener}
+}
+
+// RegisterRoutes registers the syslog routes to the given router group
+func (h *SyslogHandler) RegisterRoutes(router *gin.RouterGroup) {
+	router.GET("/syslog/messages", h.GetMessages)
+}
+
+// GetMessages returns recent messages, newest first, filtered by ?host=,
+// ?app=, ?priority= (least severe, e.g. "err"), ?q= (message text) and ?limit=
+func (h *SyslogHandler) GetMessages(c *gin.Context) {
+	filter := logwatch.MessageFilter{
+		Host:     c.Query("host"),
+		Unit:     c.Query("app"),
+		Priority: logwatch.NoPriority,
+		Contains: c.Query("q"),
+		Limit:    DefaultSyslogMessagesLimit,
+	}
+	if priority := c.Query("priority"); priority != "" {
+		p, err := logwatch.ParsePriority(priority)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		filter.Priority = p
+	}
+	if limitStr := c.Query("limit"); limitStr != "" {
+		if parsed, err := strconv.Atoi(limitStr); err == nil && parsed > 0 {
+			filter.Limit = parsed
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"messages": h.listener.Messages(filter),
+		"stats":    h.listener.Stats(),
+	})
+}
@@ -6,11 +6,13 @@ import (
 	"log/slog"
 	"net/http"
 	"strconv"
+	"sync"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 
+	"argus/internal/auth"
 	"argus/internal/models"
 	"argus/internal/services"
 )
@@ -19,6 +21,7 @@ import (
 type TasksHandler struct {
 	repo      models.TaskRepository
 	scheduler services.TaskSchedulerInterface
+	location  *time.Location // Timezone for tasks without their own (nil means server local time)
 }
 
 // NewTasksHandler creates a new tasks API handler
@@ -29,6 +32,44 @@ func NewTasksHandler(repo models.TaskRepository, scheduler services.TaskSchedule
 	}
 }
 
+// SetLocation sets the timezone used to render tasks whose schedule has no timezone
+func (h *TasksHandler) SetLocation(loc *time.Location) {
+	h.location = loc
+}
+
+// taskLocation returns the timezone a task's times are rendered in
+func (h *TasksHandler) taskLocation(task *models.TaskConfig) *time.Location {
+	fallback := h.location
+	if fallback == nil {
+		fallback = time.Local
+	}
+	return task.Schedule.Location(fallback)
+}
+
+// schedule checks the cron expression of a task and, when the task has no
+// next run time or its schedule differs from previous (nil for a new task)
+// while the next run time does not, computes it from the expression
+func (h *TasksHandler) schedule(task, previous *models.TaskConfig) error {
+	if task.Schedule.CronExpression == "" {
+		return nil
+	}
+	if err := services.ValidateCronExpression(task.Schedule.CronExpression); err != nil {
+		return err
+	}
+	rescheduled := previous != nil &&
+		(task.Schedule.CronExpression != previous.Schedule.CronExpression || task.Schedule.Timezone != previous.Schedule.Timezone) &&
+		task.Schedule.NextRunTime.Equal(previous.Schedule.NextRunTime)
+	if !task.Schedule.NextRunTime.IsZero() && !rescheduled {
+		return nil
+	}
+	return services.ScheduleNextRun(task, h.taskLocation(task), time.Now())
+}
+
+// renderTask converts a stored (UTC) task to its configured timezone for responses
+func (h *TasksHandler) renderTask(task *models.TaskConfig) models.TaskConfig {
+	return task.In(h.taskLocation(task))
+}
+
 // RegisterRoutes registers all task-related routes to the given router group
 func (h *TasksHandler) RegisterRoutes(router *gin.RouterGroup) {
 	tasks := router.Group("/tasks")
@@ -39,11 +80,43 @@ func (h *TasksHandler) RegisterRoutes(router *gin.RouterGroup) {
 		tasks.PUT("/:id", h.UpdateTask)
 		tasks.DELETE("/:id", h.DeleteTask)
 		tasks.GET("/:id/executions", h.GetTaskExecutions)
+		tasks.GET("/:id/cleanup-trend", h.GetCleanupTrend)
 		tasks.POST("/:id/run", h.RunTaskNow)
+		tasks.POST("/bulk", h.BulkTasks)
+		tasks.GET("/throttle", h.GetThrottle)
 	}
+	// Running tasks and pausing them in bulk during an incident
+	Annotate(tasks, auth.RoleOperator, "POST /:id/run", "POST /bulk")
 }
 
-// ListTasks returns all task configurations
+// throttleReporter is implemented by schedulers that defer tasks while the
+// host is busy
+type throttleReporter interface {
+	ThrottleStatus() services.ThrottleStatus
+}
+
+// GetThrottle returns the throttle thresholds, the current host load and the
+// scheduled runs held back by it
+func (h *TasksHandler) GetThrottle(c *gin.Context) {
+	reporter, ok := h.scheduler.(throttleReporter)
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Task throttling is not available"})
+		return
+	}
+	status := reporter.ThrottleStatus()
+	loc := h.location
+	if loc == nil {
+		loc = time.Local
+	}
+	for i := range status.Deferred {
+		status.Deferred[i].DueAt = status.Deferred[i].DueAt.In(loc)
+		status.Deferred[i].Since = status.Deferred[i].Since.In(loc)
+	}
+	c.JSON(http.StatusOK, status)
+}
+
+// ListTasks returns all task configurations, optionally only those matching
+// repeatable ?tag= and ?type= parameters and an ?owner= and/or ?team=
 func (h *TasksHandler) ListTasks(c *gin.Context) {
 	slog.Debug("Fetching all task configurations")
 
@@ -54,8 +127,20 @@ func (h *TasksHandler) ListTasks(c *gin.Context) {
 		return
 	}
 
+	selector := models.TaskSelector{Tags: c.QueryArray("tag")}
+	for _, taskType := range c.QueryArray("type") {
+		selector.Types = append(selector.Types, models.TaskType(taskType))
+	}
+	owner, team := c.Query("owner"), c.Query("team")
+	rendered := make([]models.TaskConfig, 0, len(tasksList))
+	for _, task := range tasksList {
+		if selector.Matches(task) && task.OwnedBy(owner, team) {
+			rendered = append(rendered, h.renderTask(task))
+		}
+	}
+
 	slog.Debug("Task configurations retrieved successfully", "count", len(tasksList))
-	c.JSON(http.StatusOK, tasksList)
+	c.JSON(http.StatusOK, rendered)
 }
 
 // GetTask returns a specific task configuration by ID
@@ -71,7 +156,7 @@ func (h *TasksHandler) GetTask(c *gin.Context) {
 	}
 
 	slog.Debug("Task configuration retrieved successfully", "id", id)
-	c.JSON(http.StatusOK, task)
+	c.JSON(http.StatusOK, h.renderTask(task))
 }
 
 // CreateTask creates a new task configuration
@@ -89,7 +174,7 @@ func (h *TasksHandler) CreateTask(c *gin.Context) {
 	}
 
 	// Set timestamps
-	now := time.Now()
+	now := time.Now().UTC()
 	task.CreatedAt = now
 	task.UpdatedAt = now
 
@@ -99,6 +184,11 @@ func (h *TasksHandler) CreateTask(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid task configuration: " + err.Error()})
 		return
 	}
+	if err := h.schedule(&task, nil); err != nil {
+		slog.Debug("Invalid task schedule", "error", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid task configuration: " + err.Error()})
+		return
+	}
 
 	// Store the task
 	if err := h.repo.CreateTask(c.Request.Context(), &task); err != nil {
@@ -108,7 +198,7 @@ func (h *TasksHandler) CreateTask(c *gin.Context) {
 	}
 
 	slog.Info("Task created successfully", "id", task.ID, "name", task.Name, "type", task.Type)
-	c.JSON(http.StatusCreated, task)
+	c.JSON(http.StatusCreated, h.renderTask(&task))
 }
 
 // UpdateTask updates an existing task configuration
@@ -135,7 +225,7 @@ func (h *TasksHandler) UpdateTask(c *gin.Context) {
 	// Ensure ID matches and preserve creation timestamp
 	task.ID = id
 	task.CreatedAt = existing.CreatedAt
-	task.UpdatedAt = time.Now()
+	task.UpdatedAt = time.Now().UTC()
 
 	// Validate the task configuration
 	if err := task.Validate(); err != nil {
@@ -143,6 +233,11 @@ func (h *TasksHandler) UpdateTask(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid task configuration: " + err.Error()})
 		return
 	}
+	if err := h.schedule(&task, existing); err != nil {
+		slog.Debug("Invalid task schedule", "id", id, "error", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid task configuration: " + err.Error()})
+		return
+	}
 
 	// Update the task
 	if err := h.repo.UpdateTask(c.Request.Context(), &task); err != nil {
@@ -152,7 +247,7 @@ func (h *TasksHandler) UpdateTask(c *gin.Context) {
 	}
 
 	slog.Info("Task updated successfully", "id", id, "name", task.Name)
-	c.JSON(http.StatusOK, task)
+	c.JSON(http.StatusOK, h.renderTask(&task))
 }
 
 // DeleteTask deletes a task configuration
@@ -177,7 +272,7 @@ func (h *TasksHandler) GetTaskExecutions(c *gin.Context) {
 	slog.Debug("Fetching task executions", "id", id)
 
 	// Check if task exists
-	_, err := h.repo.GetTask(c.Request.Context(), id)
+	task, err := h.repo.GetTask(c.Request.Context(), id)
 	if err != nil {
 		slog.Debug("Task not found for execution history", "id", id, "error", err)
 		c.JSON(http.StatusNotFound, gin.H{"error": "Task not found"})
@@ -199,24 +294,79 @@ func (h *TasksHandler) GetTaskExecutions(c *gin.Context) {
 		return
 	}
 
+	loc := h.taskLocation(task)
+	rendered := make([]models.TaskExecution, 0, len(executions))
+	for _, execution := range executions {
+		rendered = append(rendered, execution.In(loc))
+	}
+
 	slog.Debug("Task executions retrieved successfully", "id", id, "count", len(executions))
-	c.JSON(http.StatusOK, executions)
+	c.JSON(http.StatusOK, rendered)
+}
+
+// GetCleanupTrend returns the space reclaimed by the latest runs of a cleanup
+// or log rotation task (?limit= executions, default 100), oldest first, with
+// each run compared to the previous one on its host
+func (h *TasksHandler) GetCleanupTrend(c *gin.Context) {
+	id := c.Param("id")
+	task, err := h.repo.GetTask(c.Request.Context(), id)
+	if err != nil {
+		slog.Debug("Task not found for cleanup trend", "id", id, "error", err)
+		c.JSON(http.StatusNotFound, gin.H{"error": "Task not found"})
+		return
+	}
+
+	limit := 100
+	if limitStr := c.Query("limit"); limitStr != "" {
+		if parsedLimit, err := strconv.Atoi(limitStr); err == nil && parsedLimit > 0 {
+			limit = parsedLimit
+		}
+	}
+
+	executions, err := h.repo.GetTaskExecutions(c.Request.Context(), id, limit)
+	if err != nil {
+		slog.Error("Failed to get task executions", "id", id, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Failed to get task executions: %v", err)})
+		return
+	}
+
+	loc := h.taskLocation(task)
+	points := services.CleanupTrend(executions)
+	for i := range points {
+		points[i].StartTime = points[i].StartTime.In(loc)
+	}
+	c.JSON(http.StatusOK, gin.H{"task_id": id, "points": points})
 }
 
-// RunTaskNow executes a task immediately
+// RunTaskNow executes a task immediately; ?dry_run=true only reports the
+// actions of runners that delete or modify files
 func (h *TasksHandler) RunTaskNow(c *gin.Context) {
 	id := c.Param("id")
 	slog.Debug("Running task immediately", "id", id)
 
+	dryRun := false
+	if s := c.Query("dry_run"); s != "" {
+		var err error
+		if dryRun, err = strconv.ParseBool(s); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid dry_run parameter: " + s})
+			return
+		}
+	}
+
 	// Check if task exists
-	_, err := h.repo.GetTask(c.Request.Context(), id)
+	task, err := h.repo.GetTask(c.Request.Context(), id)
 	if err != nil {
 		slog.Debug("Task not found for execution", "id", id, "error", err)
 		c.JSON(http.StatusNotFound, gin.H{"error": "Task not found"})
 		return
 	}
 
-	execution, err := h.scheduler.RunTaskNow(id)
+	var execution *models.TaskExecution
+	if dryRun {
+		execution, err = h.scheduler.RunTaskWithMetadata(id, map[string]string{services.DryRunMetadata: "true"})
+	} else {
+		execution, err = h.scheduler.RunTaskNow(id)
+	}
 	if err != nil {
 		slog.Error("Failed to run task", "id", id, "error", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to run task: " + err.Error()})
@@ -224,5 +374,111 @@ func (h *TasksHandler) RunTaskNow(c *gin.Context) {
 	}
 
 	slog.Info("Task executed successfully", "id", id, "execution_id", execution.ExecutionID, "status", execution.Status)
-	c.JSON(http.StatusOK, execution)
+	c.JSON(http.StatusOK, execution.In(h.taskLocation(task)))
+}
+
+// Bulk task actions
+const (
+	bulkEnable  = "enable"
+	bulkDisable = "disable"
+	bulkTrigger = "trigger"
+)
+
+// bulkTaskRequest is the body of POST /tasks/bulk
+type bulkTaskRequest struct {
+	Action string `json:"action" binding:"required"` // enable, disable or trigger
+	models.TaskSelector
+}
+
+// bulkTaskResult reports the outcome of a bulk action on one task
+type bulkTaskResult struct {
+	TaskID      string            `json:"task_id"`
+	Name        string            `json:"name"`
+	Changed     bool              `json:"changed,omitempty"`      // Enable/disable: the task was not already in the requested state
+	ExecutionID string            `json:"execution_id,omitempty"` // Trigger: the execution that ran
+	Status      models.TaskStatus `json:"status,omitempty"`       // Trigger: how the execution ended
+	Error       string            `json:"error,omitempty"`
+}
+
+// BulkTasks enables, disables or triggers every task matching a tag and/or
+// type selector, e.g. {"action": "disable", "tags": ["cleanup"]} to pause all
+// cleanup tasks during an incident. Triggered tasks run concurrently.
+func (h *TasksHandler) BulkTasks(c *gin.Context) {
+	var req bulkTaskRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid bulk request: " + err.Error()})
+		return
+	}
+	switch req.Action {
+	case bulkEnable, bulkDisable, bulkTrigger:
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid bulk action: " + req.Action})
+		return
+	}
+	if req.Empty() {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Bulk request requires tags or types"})
+		return
+	}
+
+	ctx := c.Request.Context()
+	tasksList, err := h.repo.ListTasks(ctx)
+	if err != nil {
+		slog.Error("Failed to list tasks", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list tasks: " + err.Error()})
+		return
+	}
+	var matched []*models.TaskConfig
+	for _, task := range tasksList {
+		if req.Matches(task) {
+			matched = append(matched, task)
+		}
+	}
+
+	results := make([]bulkTaskResult, len(matched))
+	if req.Action == bulkTrigger {
+		var wg sync.WaitGroup
+		for i, task := range matched {
+			results[i] = bulkTaskResult{TaskID: task.ID, Name: task.Name}
+			wg.Add(1)
+			go func(result *bulkTaskResult) {
+				defer wg.Done()
+				execution, err := h.scheduler.RunTaskNow(result.TaskID)
+				if err != nil {
+					result.Error = err.Error()
+					return
+				}
+				result.ExecutionID, result.Status = execution.ExecutionID, execution.Status
+			}(&results[i])
+		}
+		wg.Wait()
+	} else {
+		enabled := req.Action == bulkEnable
+		for i, task := range matched {
+			results[i] = bulkTaskResult{TaskID: task.ID, Name: task.Name}
+			if task.Enabled == enabled {
+				continue
+			}
+			task.Enabled = enabled
+			task.UpdatedAt = time.Now().UTC()
+			if err := h.repo.UpdateTask(ctx, task); err != nil {
+				results[i].Error = err.Error()
+				continue
+			}
+			results[i].Changed = true
+		}
+	}
+
+	failed := 0
+	for _, result := range results {
+		if result.Error != "" {
+			failed++
+		}
+	}
+	slog.Info("Bulk task action applied", "action", req.Action, "tags", req.Tags, "types", req.Types, "matched", len(matched), "failed", failed)
+	c.JSON(http.StatusOK, gin.H{
+		"action":  req.Action,
+		"matched": len(matched),
+		"failed":  failed,
+		"results": results,
+	})
 }
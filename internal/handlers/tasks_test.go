@@ -121,6 +121,14 @@ func (m *MockTaskScheduler) RunTaskNow(taskID string) (*models.TaskExecution, er
 	return args.Get(0).(*models.TaskExecution), args.Error(1)
 }
 
+func (m *MockTaskScheduler) RunTaskWithMetadata(taskID string, metadata map[string]string) (*models.TaskExecution, error) {
+	args := m.Called(taskID, metadata)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.TaskExecution), args.Error(1)
+}
+
 func (m *MockTaskScheduler) Start() error {
 	m.Called()
 	return nil
@@ -0,0 +1,34 @@
+// File: internal/handlers/user.go
+// Brief: Requesting user of an API request
+// Detailed: Defines the gin context keys under which middleware stores the authenticated user name and, when a login provider authenticated it, the user's identity with groups and role, and the accessors handlers use to scope per-user state such as notification read receipts.
+// Author: drama.lin@aver.com
+// Date: 2026-10-14
+
+package handlers
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"argus/internal/auth"
+)
+
+// UserContextKey is the gin context key holding the authenticated user name
+const UserContextKey = "argus.user"
+
+// IdentityContextKey is the gin context key holding the *auth.Identity of a
+// user authenticated by a login provider
+const IdentityContextKey = "argus.identity"
+
+// RequestUser returns the user making the request, or "" when users are not
+// identified
+func RequestUser(c *gin.Context) string {
+	return c.GetString(UserContextKey)
+}
+
+// RequestIdentity returns the identity of the user making the request, or nil
+// when no login provider authenticated it
+func RequestIdentity(c *gin.Context) *auth.Identity {
+	identity, _ := c.Get(IdentityContextKey)
+	i, _ := identity.(*auth.Identity)
+	return i
+}
@@ -0,0 +1,75 @@
+// File: internal/handlers/version.go
+// Brief: API for the running build and the update check
+// Detailed: Reports the version, commit and build date of the running binary, its Go runtime and platform, the optional subsystems its configuration enables and its feature flags, so support requests and UI compatibility checks have reliable data, and, when the update check is enabled, the outcome of its last check against the latest release.
+// Author: drama.lin@aver.com
+// Date: 2026-10-14
+
+package handlers
+
+import (
+	"net/http"
+	"runtime"
+
+	"github.com/gin-gonic/gin"
+
+	"argus/internal/update"
+)
+
+// BuildInfo describes how the running binary was built
+type BuildInfo struct {
+	Version   string
+	Commit    string // Empty when unknown
+	BuildDate string // RFC 3339; empty when unknown
+}
+
+// VersionHandler manages the version endpoint
+type VersionHandler struct {
+	build      BuildInfo
+	subsystems map[string]bool
+	features   map[string]bool
+	checker    *update.Checker
+}
+
+// NewVersionHandler creates a handler reporting build as the running build
+func NewVersionHandler(build BuildInfo) *VersionHandler {
+	return &VersionHandler{build: build, subsystems: map[string]bool{}, features: map[string]bool{}}
+}
+
+// SetSubsystems sets whether each optional subsystem is enabled
+func (h *VersionHandler) SetSubsystems(subsystems map[string]bool) {
+	h.subsystems = subsystems
+}
+
+// SetFeatures sets the feature flag values the running subsystems use
+func (h *VersionHandler) SetFeatures(features map[string]bool) {
+	h.features = features
+}
+
+// SetChecker adds the update check to the version report
+func (h *VersionHandler) SetChecker(checker *update.Checker) {
+	h.checker = checker
+}
+
+// RegisterRoutes registers the version routes to the given router group
+func (h *VersionHandler) RegisterRoutes(router *gin.RouterGroup) {
+	router.GET("/version", h.GetVersion)
+}
+
+// GetVersion reports the running build, its subsystems and the latest
+// release known
+func (h *VersionHandler) GetVersion(c *gin.Context) {
+	response := gin.H{
+		"version":      h.build.Version,
+		"commit":       h.build.Commit,
+		"build_date":   h.build.BuildDate,
+		"subsystems":   h.subsystems,
+		"features":     h.features,
+		"go_version":   runtime.Version(),
+		"platform":     runtime.GOOS + "/" + runtime.GOARCH,
+		"update_check": h.checker != nil,
+	}
+	if h.checker != nil {
+		response["update"] = h.checker.Status()
+	}
+	c.JSON(http.StatusOK, response)
+}
@@ -0,0 +1,126 @@
+// File: internal/i18n/i18n.go
+// Brief: Locale negotiation and message catalogs for Argus
+// Detailed: Defines the supported locales, Accept-Language negotiation and the translated API message strings used by the alert handlers.
+// Author: drama.lin@aver.com
+// Date: 2026-10-14
+
+// Package i18n provides locale negotiation and translated message strings.
+package i18n
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Locale is a BCP 47 language tag supported by Argus.
+type Locale string
+
+const (
+	// English is the default locale.
+	English Locale = "en"
+	// TraditionalChinese is Traditional Chinese as used in Taiwan.
+	TraditionalChinese Locale = "zh-TW"
+)
+
+// DefaultLocale is used when negotiation finds no supported locale.
+const DefaultLocale = English
+
+// LangQueryParam lets clients override Accept-Language, e.g. ?lang=zh-TW.
+const LangQueryParam = "lang"
+
+// Supported lists every locale with a message catalog, default first.
+var Supported = []Locale{English, TraditionalChinese}
+
+// Parse returns the supported locale matching tag, ignoring case and
+// accepting "_" as a separator. Bare "zh" and the Hant script tags map to
+// TraditionalChinese; other regional variants fall back to their base language.
+func Parse(tag string) (Locale, bool) {
+	tag = strings.ToLower(strings.ReplaceAll(strings.TrimSpace(tag), "_", "-"))
+	if tag == "" {
+		return "", false
+	}
+	for _, loc := range Supported {
+		if tag == strings.ToLower(string(loc)) {
+			return loc, true
+		}
+	}
+	switch {
+	case tag == "zh", tag == "zh-hant", strings.HasPrefix(tag, "zh-hant-"):
+		return TraditionalChinese, true
+	case strings.HasPrefix(tag, "en-"):
+		return English, true
+	}
+	return "", false
+}
+
+// Negotiate picks the best supported locale for an Accept-Language header
+// value, honouring q-values. It returns DefaultLocale when nothing matches.
+func Negotiate(acceptLanguage string) Locale {
+	type candidate struct {
+		tag string
+		q   float64
+	}
+	var candidates []candidate
+	for _, part := range strings.Split(acceptLanguage, ",") {
+		fields := strings.Split(part, ";")
+		tag := strings.TrimSpace(fields[0])
+		if tag == "" || tag == "*" {
+			continue
+		}
+		q := 1.0
+		for _, param := range fields[1:] {
+			param = strings.TrimSpace(param)
+			if v, ok := strings.CutPrefix(param, "q="); ok {
+				if parsed, err := strconv.ParseFloat(v, 64); err == nil {
+					q = parsed
+				}
+			}
+		}
+		if q <= 0 {
+			continue
+		}
+		candidates = append(candidates, candidate{tag: tag, q: q})
+	}
+	// Stable sort keeps header order for equal q-values
+	sort.SliceStable(candidates, func(i, j int) bool { return candidates[i].q > candidates[j].q })
+
+	for _, c := range candidates {
+		if loc, ok := Parse(c.tag); ok {
+			return loc
+		}
+	}
+	return DefaultLocale
+}
+
+// FromRequest resolves the locale for an HTTP request. An explicit ?lang=
+// query parameter wins over the Accept-Language header.
+func FromRequest(r *http.Request) Locale {
+	if r == nil {
+		return DefaultLocale
+	}
+	if lang := r.URL.Query().Get(LangQueryParam); lang != "" {
+		if loc, ok := Parse(lang); ok {
+			return loc
+		}
+	}
+	return Negotiate(r.Header.Get("Accept-Language"))
+}
+
+// T returns the message for key in the given locale, formatted with args.
+// Missing translations fall back to English, and unknown keys to the key itself.
+func T(loc Locale, key MessageKey, args ...interface{}) string {
+	msg, ok := catalogs[loc][key]
+	if !ok {
+		msg, ok = catalogs[English][key]
+	}
+	if !ok {
+		msg = string(key)
+	}
+	if len(args) == 0 {
+		return msg
+	}
+	return fmt.Sprintf(msg, args...)
+}
@@ -0,0 +1,56 @@
+package i18n
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNegotiate(t *testing.T) {
+	tests := []struct {
+		header string
+		want   Locale
+	}{
+		{"", English},
+		{"zh-TW", TraditionalChinese},
+		{"zh-tw,en;q=0.5", TraditionalChinese},
+		{"en;q=0.4, zh-TW;q=0.9", TraditionalChinese},
+		{"zh-Hant-HK", TraditionalChinese},
+		{"fr-FR, en-GB;q=0.8", English},
+		{"fr, de", English},
+		{"zh-TW;q=0, en", English},
+	}
+	for _, tt := range tests {
+		t.Run(tt.header, func(t *testing.T) {
+			assert.Equal(t, tt.want, Negotiate(tt.header))
+		})
+	}
+}
+
+func TestFromRequest(t *testing.T) {
+	req := httptest.NewRequest("GET", "/api/alerts/notifications?lang=zh_TW", nil)
+	req.Header.Set("Accept-Language", "en")
+	assert.Equal(t, TraditionalChinese, FromRequest(req), "query parameter overrides the header")
+
+	req = httptest.NewRequest("GET", "/api/alerts/notifications?lang=xx", nil)
+	req.Header.Set("Accept-Language", "zh-TW")
+	assert.Equal(t, TraditionalChinese, FromRequest(req), "unsupported query value falls back to the header")
+}
+
+func TestT(t *testing.T) {
+	assert.Equal(t, "Alert not found", T(English, MsgAlertNotFound))
+	assert.Equal(t, "找不到告警", T(TraditionalChinese, MsgAlertNotFound))
+	assert.Equal(t, "Failed to get alert: boom", T(English, MsgAlertGetFailed, "boom"))
+	assert.Equal(t, "Alert not found", T(Locale("fr"), MsgAlertNotFound), "unknown locales fall back to English")
+	assert.Equal(t, "missing.key", T(English, MessageKey("missing.key")))
+}
+
+func TestCatalogsComplete(t *testing.T) {
+	for loc, catalog := range catalogs {
+		for key := range catalogs[English] {
+			_, ok := catalog[key]
+			assert.True(t, ok, "locale %s is missing %s", loc, key)
+		}
+	}
+}
@@ -0,0 +1,100 @@
+// File: internal/i18n/messages.go
+// Brief: Translated API message strings
+// Detailed: Message keys and per-locale catalogs for alert and notification API responses.
+// Author: drama.lin@aver.com
+// Date: 2026-10-14
+
+package i18n
+
+// MessageKey identifies a translatable message.
+type MessageKey string
+
+// Alert and notification API messages
+const (
+	MsgAlertNotFound           MessageKey = "alert.not_found"
+	MsgAlertStatusNotFound     MessageKey = "alert.status_not_found"
+	MsgAlertInvalidConfig      MessageKey = "alert.invalid_config"
+	MsgAlertListFailed         MessageKey = "alert.list_failed"
+	MsgAlertGetFailed          MessageKey = "alert.get_failed"
+	MsgAlertCreateFailed       MessageKey = "alert.create_failed"
+	MsgAlertUpdateFailed       MessageKey = "alert.update_failed"
+	MsgAlertDeleteFailed       MessageKey = "alert.delete_failed"
+	MsgAlertHistoryFailed      MessageKey = "alert.history_failed"
+	MsgAlertDeleted            MessageKey = "alert.deleted"
+	MsgAlertTestSent           MessageKey = "alert.test_sent"
+	MsgAlertSimulateInvalid    MessageKey = "alert.simulate_invalid"
+	MsgAlertPreviewFailed      MessageKey = "alert.preview_failed"
+	MsgAlertAcknowledged       MessageKey = "alert.acknowledged"
+	MsgAlertBulkInvalid        MessageKey = "alert.bulk_invalid"
+	MsgAlertDefaultsInvalid    MessageKey = "alert.defaults_invalid"
+	MsgNotificationInvalid     MessageKey = "notification.invalid_config"
+	MsgNotificationNotFound    MessageKey = "notification.not_found"
+	MsgPreviewInvalid          MessageKey = "notification.preview_invalid"
+	MsgNotificationMarkedRead  MessageKey = "notification.marked_read"
+	MsgNotificationsMarkedRead MessageKey = "notification.all_marked_read"
+	MsgNotificationsCleared    MessageKey = "notification.cleared"
+	MsgDeadLetterNotFound      MessageKey = "dead_letter.not_found"
+	MsgDeadLetterRetryFailed   MessageKey = "dead_letter.retry_failed"
+	MsgDeadLetterRetried       MessageKey = "dead_letter.retried"
+	MsgDeadLettersCleared      MessageKey = "dead_letter.cleared"
+)
+
+// catalogs holds the message strings per locale. English must contain every key.
+var catalogs = map[Locale]map[MessageKey]string{
+	English: {
+		MsgAlertNotFound:           "Alert not found",
+		MsgAlertStatusNotFound:     "Alert status not found",
+		MsgAlertInvalidConfig:      "Invalid alert configuration: %v",
+		MsgAlertListFailed:         "Failed to list alerts: %v",
+		MsgAlertGetFailed:          "Failed to get alert: %v",
+		MsgAlertCreateFailed:       "Failed to create alert: %v",
+		MsgAlertUpdateFailed:       "Failed to update alert: %v",
+		MsgAlertDeleteFailed:       "Failed to delete alert: %v",
+		MsgAlertHistoryFailed:      "Failed to get alert history: %v",
+		MsgAlertDeleted:            "Alert deleted successfully",
+		MsgAlertTestSent:           "Test alert sent successfully",
+		MsgAlertSimulateInvalid:    "Invalid simulation request: %v",
+		MsgAlertPreviewFailed:      "Cannot preview alert impact: %v",
+		MsgAlertAcknowledged:       "Alert acknowledged, %d notifications marked as read",
+		MsgAlertBulkInvalid:        "Invalid bulk alert edit: %v",
+		MsgAlertDefaultsInvalid:    "Invalid default alerts request: %v",
+		MsgNotificationInvalid:     "Invalid notification configuration: %v",
+		MsgNotificationNotFound:    "Notification not found",
+		MsgPreviewInvalid:          "Invalid notification preview request: %v",
+		MsgNotificationMarkedRead:  "Notification marked as read",
+		MsgNotificationsMarkedRead: "All notifications marked as read",
+		MsgNotificationsCleared:    "All notifications cleared",
+		MsgDeadLetterNotFound:      "Dead letter not found",
+		MsgDeadLetterRetryFailed:   "Retry failed: %v",
+		MsgDeadLetterRetried:       "Notification re-sent",
+		MsgDeadLettersCleared:      "Dead-letter queue cleared",
+	},
+	TraditionalChinese: {
+		MsgAlertNotFound:           "找不到告警",
+		MsgAlertStatusNotFound:     "找不到告警狀態",
+		MsgAlertInvalidConfig:      "告警設定無效：%v",
+		MsgAlertListFailed:         "無法列出告警：%v",
+		MsgAlertGetFailed:          "無法取得告警：%v",
+		MsgAlertCreateFailed:       "無法建立告警：%v",
+		MsgAlertUpdateFailed:       "無法更新告警：%v",
+		MsgAlertDeleteFailed:       "無法刪除告警：%v",
+		MsgAlertHistoryFailed:      "無法取得告警歷史紀錄：%v",
+		MsgAlertDeleted:            "告警已刪除",
+		MsgAlertTestSent:           "測試告警已送出",
+		MsgAlertSimulateInvalid:    "模擬請求無效：%v",
+		MsgAlertPreviewFailed:      "無法預覽告警影響：%v",
+		MsgAlertAcknowledged:       "告警已確認，%d 則通知已標示為已讀",
+		MsgAlertBulkInvalid:        "批次編輯告警無效：%v",
+		MsgAlertDefaultsInvalid:    "預設告警請求無效：%v",
+		MsgNotificationInvalid:     "通知設定無效：%v",
+		MsgNotificationNotFound:    "找不到通知",
+		MsgPreviewInvalid:          "通知預覽請求無效：%v",
+		MsgNotificationMarkedRead:  "通知已標示為已讀",
+		MsgNotificationsMarkedRead: "所有通知已標示為已讀",
+		MsgNotificationsCleared:    "所有通知已清除",
+		MsgDeadLetterNotFound:      "找不到無法投遞的通知",
+		MsgDeadLetterRetryFailed:   "重試失敗：%v",
+		MsgDeadLetterRetried:       "通知已重新送出",
+		MsgDeadLettersCleared:      "無法投遞佇列已清除",
+	},
+}
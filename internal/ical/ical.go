@@ -0,0 +1,109 @@
+// File: internal/ical/ical.go
+// Brief: Minimal iCalendar (RFC 5545) feed encoder
+// Detailed: Writes a VCALENDAR of VEVENT entries with the escaping, UTC timestamps and 75-octet line folding calendar clients expect, for subscribing to Argus schedules.
+// Author: drama.lin@aver.com
+// Date: 2026-10-14
+
+// Package ical encodes events as an iCalendar feed.
+package ical
+
+import (
+	"bufio"
+	"io"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// ProdID identifies Argus as the feed producer
+const ProdID = "-//Argus//Schedule Feed//EN"
+
+// maxLineOctets is the longest content line before folding
+const maxLineOctets = 75
+
+// Event is one calendar entry. A nil End makes a point-in-time event.
+type Event struct {
+	UID         string     `json:"uid"`
+	Summary     string     `json:"summary"`
+	Description string     `json:"description,omitempty"`
+	Start       time.Time  `json:"start"`
+	End         *time.Time `json:"end,omitempty"`
+	Categories  []string   `json:"categories,omitempty"`
+}
+
+// Encode writes a calendar named name holding events. stamp is the DTSTAMP of
+// every event, normally the time the feed is generated.
+func Encode(w io.Writer, name string, events []Event, stamp time.Time) error {
+	bw := bufio.NewWriter(w)
+	line := func(s string) {
+		writeFolded(bw, s)
+	}
+
+	line("BEGIN:VCALENDAR")
+	line("VERSION:2.0")
+	line("PRODID:" + ProdID)
+	line("CALSCALE:GREGORIAN")
+	line("METHOD:PUBLISH")
+	if name != "" {
+		line("X-WR-CALNAME:" + escape(name))
+	}
+	for _, e := range events {
+		line("BEGIN:VEVENT")
+		line("UID:" + escape(e.UID))
+		line("DTSTAMP:" + formatTime(stamp))
+		line("DTSTART:" + formatTime(e.Start))
+		if e.End != nil {
+			line("DTEND:" + formatTime(*e.End))
+		}
+		line("SUMMARY:" + escape(e.Summary))
+		if e.Description != "" {
+			line("DESCRIPTION:" + escape(e.Description))
+		}
+		if len(e.Categories) > 0 {
+			escaped := make([]string, len(e.Categories))
+			for i, c := range e.Categories {
+				escaped[i] = escape(c)
+			}
+			line("CATEGORIES:" + strings.Join(escaped, ","))
+		}
+		line("END:VEVENT")
+	}
+	line("END:VCALENDAR")
+	return bw.Flush()
+}
+
+// formatTime renders t as a UTC date-time, e.g. 20261014T090000Z
+func formatTime(t time.Time) string {
+	return t.UTC().Format("20060102T150405Z")
+}
+
+// escape escapes a TEXT value
+func escape(s string) string {
+	return strings.NewReplacer(
+		`\`, `\\`,
+		";", `\;`,
+		",", `\,`,
+		"\r\n", `\n`,
+		"\n", `\n`,
+		"\r", `\n`,
+	).Replace(s)
+}
+
+// writeFolded writes a content line ending in CRLF, continuing lines longer
+// than 75 octets on lines starting with a space without splitting UTF-8
+// sequences
+func writeFolded(w *bufio.Writer, s string) {
+	limit := maxLineOctets
+	for len(s) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(s[cut]) {
+			cut--
+		}
+		w.WriteString(s[:cut])
+		w.WriteString("\r\n ")
+		s = s[cut:]
+		limit = maxLineOctets - 1 // The leading space counts toward the limit
+	}
+	w.WriteString(s)
+	w.WriteString("\r\n")
+}
@@ -0,0 +1,63 @@
+package ical
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEncode(t *testing.T) {
+	stamp := time.Date(2026, 10, 14, 8, 0, 0, 0, time.UTC)
+	taipei := time.FixedZone("CST", 8*3600)
+	end := stamp.Add(time.Hour)
+	events := []Event{
+		{
+			UID:         "task-1",
+			Summary:     "Log rotation; nightly",
+			Description: "Rotates logs,\nkeeps 7",
+			Start:       time.Date(2026, 10, 15, 2, 0, 0, 0, taipei),
+			Categories:  []string{"task", "log_rotation"},
+		},
+		{
+			UID:     "silence-1",
+			Summary: "Maintenance",
+			Start:   stamp,
+			End:     &end,
+		},
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, Encode(&buf, "Argus", events, stamp))
+	out := buf.String()
+
+	assert.True(t, strings.HasPrefix(out, "BEGIN:VCALENDAR\r\nVERSION:2.0\r\n"))
+	assert.True(t, strings.HasSuffix(out, "END:VCALENDAR\r\n"))
+	assert.Equal(t, 2, strings.Count(out, "BEGIN:VEVENT\r\n"))
+	assert.Contains(t, out, "DTSTART:20261014T180000Z\r\n", "times are converted to UTC")
+	assert.Contains(t, out, "SUMMARY:Log rotation\\; nightly\r\n")
+	assert.Contains(t, out, "DESCRIPTION:Rotates logs\\,\\nkeeps 7\r\n")
+	assert.Contains(t, out, "CATEGORIES:task,log_rotation\r\n")
+	assert.Contains(t, out, "DTEND:20261014T090000Z\r\n")
+	assert.Equal(t, 1, strings.Count(out, "DTEND:"), "events without an end have no DTEND")
+}
+
+func TestEncode_FoldsLongLines(t *testing.T) {
+	var buf bytes.Buffer
+	summary := strings.Repeat("警告", 40) // 3-octet runes
+	require.NoError(t, Encode(&buf, "", []Event{{UID: "u", Summary: summary, Start: time.Unix(0, 0)}}, time.Unix(0, 0)))
+
+	var unfolded strings.Builder
+	for i, line := range strings.Split(strings.TrimSuffix(buf.String(), "\r\n"), "\r\n") {
+		assert.LessOrEqual(t, len(line), 75, "line %d", i)
+		if strings.HasPrefix(line, " ") {
+			unfolded.WriteString(line[1:])
+		} else {
+			unfolded.WriteString("\n" + line)
+		}
+	}
+	assert.Contains(t, unfolded.String(), "\nSUMMARY:"+summary+"\n")
+}
@@ -0,0 +1,85 @@
+// File: internal/incidents/bundle.go
+// Brief: Post-incident export bundles
+// Detailed: Writes an incident and the material gathered for its postmortem as one zip archive: the incident with its timeline as JSON and as plain text, plus any further JSON documents such as the involved alert configurations, metric history slices and notification deliveries.
+// Author: drama.lin@aver.com
+// Date: 2026-10-14
+
+package incidents
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// File is a JSON document of an export bundle
+type File struct {
+	Name string // Path within the archive, e.g. metrics/db.json
+	Data any
+}
+
+// WriteBundle writes the export bundle of an incident as a zip archive to w:
+// incident.json, timeline.txt and the given files, in that order
+func WriteBundle(w io.Writer, incident *Incident, files []File) error {
+	archive := zip.NewWriter(w)
+	modified := time.Now().UTC()
+	if incident.ResolvedAt != nil {
+		modified = *incident.ResolvedAt
+	}
+	create := func(name string) (io.Writer, error) {
+		return archive.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Deflate, Modified: modified})
+	}
+	writeJSON := func(name string, data any) error {
+		f, err := create(name)
+		if err != nil {
+			return fmt.Errorf("failed to add %s: %w", name, err)
+		}
+		enc := json.NewEncoder(f)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(data); err != nil {
+			return fmt.Errorf("failed to write %s: %w", name, err)
+		}
+		return nil
+	}
+
+	if err := writeJSON("incident.json", incident); err != nil {
+		return err
+	}
+	f, err := create("timeline.txt")
+	if err != nil {
+		return fmt.Errorf("failed to add timeline.txt: %w", err)
+	}
+	if _, err := io.WriteString(f, incident.Text()); err != nil {
+		return fmt.Errorf("failed to write timeline.txt: %w", err)
+	}
+	for _, file := range files {
+		if err := writeJSON(file.Name, file.Data); err != nil {
+			return err
+		}
+	}
+	return archive.Close()
+}
+
+// Text describes the incident and lists its timeline, one entry per line
+func (i *Incident) Text() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Incident %s: %s\n", i.ID, i.Title)
+	fmt.Fprintf(&b, "Status: %s, severity: %s\n", i.Status, i.Severity)
+	fmt.Fprintf(&b, "Started: %s\n", i.StartedAt.Format(time.RFC3339))
+	if i.ResolvedAt != nil {
+		fmt.Fprintf(&b, "Resolved: %s\n", i.ResolvedAt.Format(time.RFC3339))
+	}
+	fmt.Fprintf(&b, "Duration: %s\n", time.Duration(i.Duration*float64(time.Second)).Round(time.Second))
+	names := make([]string, len(i.Alerts))
+	for n, alert := range i.Alerts {
+		names[n] = alert.Name
+	}
+	fmt.Fprintf(&b, "Alerts: %s\n\nTimeline:\n", strings.Join(names, ", "))
+	for _, entry := range i.Timeline {
+		fmt.Fprintf(&b, "%s  %-14s  %s\n", entry.Time.Format(time.RFC3339), entry.Kind, entry.Summary)
+	}
+	return b.String()
+}
@@ -0,0 +1,311 @@
+// File: internal/incidents/incidents.go
+// Brief: Incident construction from correlated alert activity
+// Detailed: Groups the periods alerts were firing into incidents when they overlap or start within a correlation window of each other, and builds each incident's timeline from the alert state changes, acknowledgments, silences and task runs that happened during it, widened by the window. Incidents are derived from stored history on demand; their IDs are derived from the first firing, so they stay stable while that history is kept.
+// Author: drama.lin@aver.com
+// Date: 2026-10-14
+
+// Package incidents correlates alert activity into incidents.
+package incidents
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"argus/internal/models"
+)
+
+// DefaultWindow is how close firing periods must be to join an incident,
+// and how far around an incident related activity is collected
+const DefaultWindow = 5 * time.Minute
+
+// Incident states
+const (
+	StatusActive   = "active" // An alert of the incident is still firing
+	StatusResolved = "resolved"
+)
+
+// Timeline entry kinds
+const (
+	KindAlert          = "alert"          // An alert state change
+	KindAcknowledgment = "acknowledgment" // An alert's notifications were read
+	KindSilence        = "silence"        // A silence started or ended
+	KindTask           = "task"           // A task run started
+)
+
+// severityRank orders severities to find the highest
+var severityRank = map[models.AlertSeverity]int{models.SeverityInfo: 1, models.SeverityWarning: 2, models.SeverityCritical: 3}
+
+// Entry is one event of an incident timeline
+type Entry struct {
+	Time      time.Time            `json:"time"`
+	Kind      string               `json:"kind"`
+	Summary   string               `json:"summary"`
+	AlertID   string               `json:"alert_id,omitempty"`
+	AlertName string               `json:"alert_name,omitempty"`
+	Severity  models.AlertSeverity `json:"severity,omitempty"`
+	OldState  models.AlertState    `json:"old_state,omitempty"`
+	NewState  models.AlertState    `json:"new_state,omitempty"`
+	Value     *float64             `json:"value,omitempty"`
+	User      string               `json:"user,omitempty"` // Who acknowledged or created the silence
+	SilenceID string               `json:"silence_id,omitempty"`
+	TaskID    string               `json:"task_id,omitempty"`
+	TaskName  string               `json:"task_name,omitempty"`
+	RunID     string               `json:"execution_id,omitempty"`
+	RunStatus models.TaskStatus    `json:"execution_status,omitempty"`
+}
+
+// AlertRef is an alert that fired during an incident
+type AlertRef struct {
+	ID       string               `json:"id"`
+	Name     string               `json:"name"`
+	Severity models.AlertSeverity `json:"severity"`
+}
+
+// Incident is a span of correlated alert activity
+type Incident struct {
+	ID         string               `json:"id"`
+	Title      string               `json:"title"`
+	Status     string               `json:"status"`
+	Severity   models.AlertSeverity `json:"severity"` // Highest severity of its alerts
+	StartedAt  time.Time            `json:"started_at"`
+	ResolvedAt *time.Time           `json:"resolved_at,omitempty"`
+	Duration   float64              `json:"duration_seconds"` // Until now while active
+	Alerts     []AlertRef           `json:"alerts"`
+	Timeline   []Entry              `json:"timeline"` // Oldest first
+}
+
+// Source provides the activity incidents are built from
+type Source struct {
+	Alerts []*models.AlertConfig
+	// History returns an alert's state changes, in any order
+	History       func(alertID string) ([]models.AlertHistoryEntry, error)
+	Notifications []models.InAppNotification
+	Silences      []*models.Silence
+	Executions    []*models.TaskExecution
+}
+
+// Options configure incident construction
+type Options struct {
+	Window time.Duration // DefaultWindow when zero
+	Since  time.Time     // Incidents that ended before are left out
+}
+
+// firing is one period an alert was pending or active; end is zero while it
+// still is
+type firing struct {
+	alert *models.AlertConfig
+	start time.Time
+	end   time.Time
+}
+
+// Build returns the incidents since opts.Since at now, newest first. An
+// alert whose history cannot be read is left out.
+func Build(src Source, opts Options, now time.Time) []*Incident {
+	window := opts.Window
+	if window <= 0 {
+		window = DefaultWindow
+	}
+
+	histories := make(map[string][]models.AlertHistoryEntry, len(src.Alerts))
+	var periods []firing
+	for _, alert := range src.Alerts {
+		if src.History == nil {
+			break
+		}
+		history, err := src.History(alert.ID)
+		if err != nil {
+			continue
+		}
+		history = slices.Clone(history)
+		slices.SortStableFunc(history, func(a, b models.AlertHistoryEntry) int { return a.Timestamp.Compare(b.Timestamp) })
+		histories[alert.ID] = history
+		for _, p := range firingPeriods(alert, history) {
+			if p.end.IsZero() || !p.end.Before(opts.Since) {
+				periods = append(periods, p)
+			}
+		}
+	}
+	slices.SortStableFunc(periods, func(a, b firing) int { return a.start.Compare(b.start) })
+
+	var incidents []*Incident
+	for i := 0; i < len(periods); {
+		// Join the following periods starting within the window of the
+		// incident's end; an open period extends it to now
+		cluster := []firing{periods[i]}
+		end := periods[i].end
+		for i++; i < len(periods); i++ {
+			if !end.IsZero() && periods[i].start.After(end.Add(window)) {
+				break
+			}
+			cluster = append(cluster, periods[i])
+			if periods[i].end.IsZero() || end.IsZero() {
+				end = time.Time{}
+			} else if periods[i].end.After(end) {
+				end = periods[i].end
+			}
+		}
+		incidents = append(incidents, build(cluster, end, window, src, histories, now))
+	}
+	slices.Reverse(incidents)
+	return incidents
+}
+
+// firingPeriods returns the periods an alert was firing from its history,
+// oldest first. A period whose start was purged from the history is left out.
+func firingPeriods(alert *models.AlertConfig, history []models.AlertHistoryEntry) []firing {
+	var periods []firing
+	var current *firing
+	for _, entry := range history {
+		switch {
+		case entry.NewState.Firing() && current == nil:
+			current = &firing{alert: alert, start: entry.Timestamp}
+		case !entry.NewState.Firing() && current != nil:
+			current.end = entry.Timestamp
+			periods = append(periods, *current)
+			current = nil
+		}
+	}
+	if current != nil {
+		periods = append(periods, *current)
+	}
+	return periods
+}
+
+// ID returns the incident ID of a first firing
+func ID(alertID string, start time.Time) string {
+	sum := sha256.Sum256([]byte(alertID + "@" + strconv.FormatInt(start.UnixNano(), 10)))
+	return hex.EncodeToString(sum[:8])
+}
+
+// build assembles the incident of a cluster of firing periods ending at end
+// (zero while active)
+func build(cluster []firing, end time.Time, window time.Duration, src Source, histories map[string][]models.AlertHistoryEntry, now time.Time) *Incident {
+	first := cluster[0]
+	incident := &Incident{
+		ID:        ID(first.alert.ID, first.start),
+		Status:    StatusActive,
+		StartedAt: first.start,
+		Alerts:    []AlertRef{},
+		Timeline:  []Entry{},
+	}
+	involved := make(map[string]*models.AlertConfig)
+	for _, p := range cluster {
+		if _, ok := involved[p.alert.ID]; ok {
+			continue
+		}
+		involved[p.alert.ID] = p.alert
+		incident.Alerts = append(incident.Alerts, AlertRef{ID: p.alert.ID, Name: p.alert.Name, Severity: p.alert.Severity})
+		if severityRank[p.alert.Severity] > severityRank[incident.Severity] {
+			incident.Severity = p.alert.Severity
+		}
+	}
+	incident.Title = first.alert.Name
+	if n := len(incident.Alerts); n > 1 {
+		incident.Title = fmt.Sprintf("%s and %d more", first.alert.Name, n-1)
+	}
+
+	until := now
+	if !end.IsZero() {
+		incident.Status = StatusResolved
+		resolved := end
+		incident.ResolvedAt = &resolved
+		until = end.Add(window)
+	}
+	stop := now
+	if !end.IsZero() && end.Before(now) {
+		stop = end
+	}
+	incident.Duration = stop.Sub(incident.StartedAt).Seconds()
+	from := incident.StartedAt.Add(-window)
+	in := func(t time.Time) bool { return !t.Before(from) && !t.After(until) }
+
+	for _, alert := range src.Alerts {
+		for _, entry := range histories[alert.ID] {
+			if !in(entry.Timestamp) {
+				continue
+			}
+			value := entry.Value
+			incident.Timeline = append(incident.Timeline, Entry{
+				Time: entry.Timestamp, Kind: KindAlert, AlertID: alert.ID, AlertName: alert.Name, Severity: alert.Severity,
+				OldState: entry.OldState, NewState: entry.NewState, Value: &value,
+				Summary: fmt.Sprintf("%s: %s → %s", alert.Name, entry.OldState, entry.NewState),
+			})
+		}
+	}
+
+	acked := make(map[string]bool)
+	ack := func(alertID, user string, at time.Time) {
+		key := alertID + "\x00" + user
+		if acked[key] || !in(at) {
+			return
+		}
+		acked[key] = true
+		alert := involved[alertID]
+		summary := "Acknowledged " + alert.Name
+		if user != "" {
+			summary += " by " + user
+		}
+		incident.Timeline = append(incident.Timeline, Entry{Time: at, Kind: KindAcknowledgment, AlertID: alertID, AlertName: alert.Name, User: user, Summary: summary})
+	}
+	notifications := slices.Clone(src.Notifications)
+	slices.SortStableFunc(notifications, func(a, b models.InAppNotification) int { return a.Timestamp.Compare(b.Timestamp) })
+	for _, n := range notifications {
+		if involved[n.AlertID] == nil {
+			continue
+		}
+		if n.ReadAt != nil {
+			ack(n.AlertID, "", *n.ReadAt)
+		}
+		users := make([]string, 0, len(n.ReadBy))
+		for user := range n.ReadBy {
+			users = append(users, user)
+		}
+		slices.SortFunc(users, func(a, b string) int { return n.ReadBy[a].Compare(n.ReadBy[b]) })
+		for _, user := range users {
+			ack(n.AlertID, user, n.ReadBy[user])
+		}
+	}
+
+	for _, silence := range src.Silences {
+		if in(silence.StartsAt) {
+			incident.Timeline = append(incident.Timeline, Entry{Time: silence.StartsAt, Kind: KindSilence, SilenceID: silence.ID, User: silence.CreatedBy,
+				Summary: "Silence started: " + silenceSummary(silence)})
+		}
+		if in(silence.EndsAt) && !silence.EndsAt.After(now) {
+			incident.Timeline = append(incident.Timeline, Entry{Time: silence.EndsAt, Kind: KindSilence, SilenceID: silence.ID, User: silence.CreatedBy,
+				Summary: "Silence ended: " + silenceSummary(silence)})
+		}
+	}
+
+	for _, run := range src.Executions {
+		if !in(run.StartTime) {
+			continue
+		}
+		name := run.TaskName
+		if name == "" {
+			name = run.TaskID
+		}
+		incident.Timeline = append(incident.Timeline, Entry{Time: run.StartTime, Kind: KindTask, TaskID: run.TaskID, TaskName: run.TaskName,
+			RunID: run.ExecutionID, RunStatus: run.Status, Summary: fmt.Sprintf("Task %s ran: %s", name, run.Status)})
+	}
+
+	slices.SortStableFunc(incident.Timeline, func(a, b Entry) int { return a.Time.Compare(b.Time) })
+	return incident
+}
+
+// silenceSummary describes a silence by its comment, or its matchers
+func silenceSummary(s *models.Silence) string {
+	if s.Comment != "" {
+		return s.Comment
+	}
+	matchers := make([]string, len(s.Matchers))
+	for i, m := range s.Matchers {
+		matchers[i] = m.String()
+	}
+	return strings.Join(matchers, ", ")
+}
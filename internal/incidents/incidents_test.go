@@ -0,0 +1,168 @@
+package incidents
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"argus/internal/models"
+)
+
+var t0 = time.Date(2026, 10, 14, 8, 0, 0, 0, time.UTC)
+
+func at(minutes int) time.Time {
+	return t0.Add(time.Duration(minutes) * time.Minute)
+}
+
+func change(old, new models.AlertState, minutes int) models.AlertHistoryEntry {
+	return models.AlertHistoryEntry{OldState: old, NewState: new, Timestamp: at(minutes), Value: float64(minutes)}
+}
+
+func fired(start, end int) []models.AlertHistoryEntry {
+	entries := []models.AlertHistoryEntry{change(models.StateInactive, models.StatePending, start)}
+	if end >= 0 {
+		entries = append(entries, change(models.StatePending, models.StateResolved, end))
+	}
+	return entries
+}
+
+func TestBuild_Correlation(t *testing.T) {
+	db := &models.AlertConfig{ID: "db", Name: "DB down", Severity: models.SeverityCritical}
+	api := &models.AlertConfig{ID: "api", Name: "API errors", Severity: models.SeverityWarning}
+	disk := &models.AlertConfig{ID: "disk", Name: "Disk full", Severity: models.SeverityWarning}
+	broken := &models.AlertConfig{ID: "broken", Name: "Broken"}
+	histories := map[string][]models.AlertHistoryEntry{
+		"db": append(fired(10, 30), fired(120, -1)...), // Fires again later and is still active
+		"api": { // Within the window of db's end, active counts as firing too
+			change(models.StateInactive, models.StateActive, 34),
+			change(models.StateActive, models.StateResolved, 40),
+		},
+		"disk": fired(60, 70), // Too late to join
+	}
+	src := Source{
+		Alerts: []*models.AlertConfig{db, api, disk, broken},
+		History: func(id string) ([]models.AlertHistoryEntry, error) {
+			if id == "broken" {
+				return nil, errors.New("unreadable")
+			}
+			return histories[id], nil
+		},
+	}
+
+	incidents := Build(src, Options{Window: 5 * time.Minute}, at(130))
+	require.Len(t, incidents, 3)
+
+	latest := incidents[0]
+	assert.Equal(t, StatusActive, latest.Status)
+	assert.Nil(t, latest.ResolvedAt)
+	assert.Equal(t, 600.0, latest.Duration, "active incidents last until now")
+
+	outage := incidents[2]
+	assert.Equal(t, ID("db", at(10)), outage.ID)
+	assert.Equal(t, "DB down and 1 more", outage.Title)
+	assert.Equal(t, StatusResolved, outage.Status)
+	assert.Equal(t, models.SeverityCritical, outage.Severity)
+	assert.Equal(t, at(10), outage.StartedAt)
+	assert.Equal(t, at(40), *outage.ResolvedAt)
+	assert.Equal(t, 1800.0, outage.Duration)
+	assert.Equal(t, []AlertRef{{ID: "db", Name: "DB down", Severity: models.SeverityCritical}, {ID: "api", Name: "API errors", Severity: models.SeverityWarning}}, outage.Alerts)
+
+	var states []models.AlertState
+	for _, entry := range outage.Timeline {
+		assert.Equal(t, KindAlert, entry.Kind)
+		states = append(states, entry.NewState)
+	}
+	assert.Equal(t, []models.AlertState{models.StatePending, models.StateResolved, models.StateActive, models.StateResolved}, states,
+		"the state changes from the window before the start to the window after the end")
+	assert.Equal(t, "DB down: inactive → pending", outage.Timeline[0].Summary)
+
+	assert.Equal(t, "Disk full", incidents[1].Title)
+
+	since := Build(src, Options{Since: at(50)}, at(130))
+	assert.Len(t, since, 2, "incidents that ended before Since are left out")
+}
+
+func TestBuild_Timeline(t *testing.T) {
+	alert := &models.AlertConfig{ID: "db", Name: "DB down", Severity: models.SeverityCritical}
+	other := &models.AlertConfig{ID: "other", Name: "Other"}
+	readAt := at(12)
+	src := Source{
+		Alerts: []*models.AlertConfig{alert, other},
+		History: func(id string) ([]models.AlertHistoryEntry, error) {
+			return map[string][]models.AlertHistoryEntry{"db": fired(10, 30)}[id], nil
+		},
+		Notifications: []models.InAppNotification{
+			{AlertID: "db", Timestamp: at(10), ReadAt: &readAt, ReadBy: map[string]time.Time{"bob": at(15), "alice": at(11)}},
+			{AlertID: "db", Timestamp: at(20), ReadBy: map[string]time.Time{"alice": at(21)}}, // alice already acknowledged
+			{AlertID: "other", Timestamp: at(10), ReadAt: &readAt},                            // Not part of the incident
+		},
+		Silences: []*models.Silence{
+			{ID: "s1", StartsAt: at(13), EndsAt: at(25), CreatedBy: "alice", Comment: "failover"},
+			{ID: "s2", StartsAt: at(20), EndsAt: at(500), Matchers: []models.Matcher{{Name: "alertname", Value: "DB down", IsEqual: true}}},
+			{ID: "old", StartsAt: at(-60), EndsAt: at(-30)},
+		},
+		Executions: []*models.TaskExecution{
+			{ExecutionID: "e1", TaskID: "t1", TaskName: "restart-db", StartTime: at(16), Status: models.StatusCompleted},
+			{ExecutionID: "e0", TaskID: "t1", StartTime: at(-20)},
+		},
+	}
+
+	incidents := Build(src, Options{}, at(60))
+	require.Len(t, incidents, 1)
+	var summaries []string
+	for _, entry := range incidents[0].Timeline {
+		summaries = append(summaries, entry.Summary)
+	}
+	assert.Equal(t, []string{
+		"DB down: inactive → pending",
+		"Acknowledged DB down by alice",
+		"Acknowledged DB down",
+		"Silence started: failover",
+		"Acknowledged DB down by bob",
+		"Task restart-db ran: completed",
+		"Silence started: alertname=\"DB down\"",
+		"Silence ended: failover",
+		"DB down: pending → resolved",
+	}, summaries, "a silence ending in the future is not in the timeline yet")
+	assert.Equal(t, "e1", incidents[0].Timeline[5].RunID)
+}
+
+func TestWriteBundle(t *testing.T) {
+	resolved := at(30)
+	incident := &Incident{
+		ID: "abc", Title: "DB down", Status: StatusResolved, Severity: models.SeverityCritical,
+		StartedAt: at(10), ResolvedAt: &resolved, Duration: 1200,
+		Alerts:   []AlertRef{{ID: "db", Name: "DB down"}},
+		Timeline: []Entry{{Time: at(10), Kind: KindAlert, Summary: "DB down: inactive → pending"}},
+	}
+	var buf bytes.Buffer
+	require.NoError(t, WriteBundle(&buf, incident, []File{{Name: "alerts.json", Data: []string{"db"}}}))
+
+	archive, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	require.NoError(t, err)
+	contents := make(map[string]string)
+	var names []string
+	for _, f := range archive.File {
+		names = append(names, f.Name)
+		r, err := f.Open()
+		require.NoError(t, err)
+		data, err := io.ReadAll(r)
+		require.NoError(t, err)
+		contents[f.Name] = string(data)
+	}
+	assert.Equal(t, []string{"incident.json", "timeline.txt", "alerts.json"}, names)
+
+	var decoded Incident
+	require.NoError(t, json.Unmarshal([]byte(contents["incident.json"]), &decoded))
+	assert.Equal(t, "abc", decoded.ID)
+	assert.Contains(t, contents["timeline.txt"], "Duration: 20m0s\n")
+	assert.Contains(t, contents["timeline.txt"], "2026-10-14T08:10:00Z  alert           DB down: inactive → pending\n")
+	assert.JSONEq(t, `["db"]`, contents["alerts.json"])
+}
@@ -0,0 +1,159 @@
+package ingest
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/encoding/protowire"
+
+	"argus/internal/metrics"
+)
+
+// snappyLiteral encodes data as a single literal element
+func snappyLiteral(data []byte) []byte {
+	out := binary.AppendUvarint(nil, uint64(len(data)))
+	n := len(data) - 1
+	if n < 60 {
+		out = append(out, byte(n<<2))
+	} else {
+		out = append(out, 61<<2, byte(n), byte(n>>8))
+	}
+	return append(out, data...)
+}
+
+func TestDecodeSnappy_Literal(t *testing.T) {
+	for _, size := range []int{1, 59, 60, 300} {
+		data := make([]byte, size)
+		for i := range data {
+			data[i] = byte(i)
+		}
+		got, err := DecodeSnappy(snappyLiteral(data), 0)
+		require.NoError(t, err)
+		assert.Equal(t, data, got)
+	}
+}
+
+func TestDecodeSnappy_Copies(t *testing.T) {
+	// "abcd" literal, then copy-1 of 4 bytes at offset 4, then an
+	// overlapping copy-2 of 6 bytes at offset 2
+	src := []byte{14, 3 << 2, 'a', 'b', 'c', 'd', 0x01, 4, (6-1)<<2 | 0x02, 2, 0}
+	got, err := DecodeSnappy(src, 0)
+	require.NoError(t, err)
+	assert.Equal(t, "abcdabcdcdcdcd", string(got))
+}
+
+func TestEncodeSnappy_RoundTrip(t *testing.T) {
+	repetitive := bytes.Repeat([]byte(`cpu_usage_percent{host="web-1"} `), 500)
+	random := make([]byte, 70000)
+	for i := range random {
+		random[i] = byte(i * 7919 >> 3)
+	}
+	for name, data := range map[string][]byte{
+		"empty":      {},
+		"short":      []byte("abc"),
+		"repetitive": repetitive,
+		"long runs":  bytes.Repeat([]byte{'x'}, 1000),
+		"random":     random,
+	} {
+		encoded := EncodeSnappy(data)
+		got, err := DecodeSnappy(encoded, 0)
+		require.NoError(t, err, name)
+		assert.Equal(t, len(data), len(got), name)
+		assert.True(t, bytes.Equal(data, got), name)
+	}
+	assert.Less(t, len(EncodeSnappy(repetitive)), len(repetitive)/10, "repeated labels compress")
+}
+
+func TestDecodeSnappy_Corrupt(t *testing.T) {
+	cases := map[string][]byte{
+		"empty":          {},
+		"short literal":  {5, 4 << 2, 'a'},
+		"bad offset":     {8, 0x01, 9},
+		"length too big": {9, 0, 'a'},
+	}
+	for name, src := range cases {
+		_, err := DecodeSnappy(src, 0)
+		assert.Error(t, err, name)
+	}
+
+	_, err := DecodeSnappy(snappyLiteral(make([]byte, 100)), 10)
+	assert.Error(t, err, "decoded size above the limit")
+}
+
+func appendLabel(b []byte, name, value string) []byte {
+	var label []byte
+	label = protowire.AppendTag(label, 1, protowire.BytesType)
+	label = protowire.AppendString(label, name)
+	label = protowire.AppendTag(label, 2, protowire.BytesType)
+	label = protowire.AppendString(label, value)
+	b = protowire.AppendTag(b, 1, protowire.BytesType)
+	return protowire.AppendBytes(b, label)
+}
+
+func appendSample(b []byte, value float64, ts int64) []byte {
+	var sample []byte
+	sample = protowire.AppendTag(sample, 1, protowire.Fixed64Type)
+	sample = protowire.AppendFixed64(sample, math.Float64bits(value))
+	sample = protowire.AppendTag(sample, 2, protowire.VarintType)
+	sample = protowire.AppendVarint(sample, uint64(ts))
+	b = protowire.AppendTag(b, 2, protowire.BytesType)
+	return protowire.AppendBytes(b, sample)
+}
+
+func writeRequest(series ...[]byte) []byte {
+	var req []byte
+	for _, ts := range series {
+		req = protowire.AppendTag(req, 1, protowire.BytesType)
+		req = protowire.AppendBytes(req, ts)
+	}
+	// Metadata (field 3) must be skipped
+	req = protowire.AppendTag(req, 3, protowire.BytesType)
+	return protowire.AppendBytes(req, []byte{0x08, 0x01})
+}
+
+func TestParseWriteRequest(t *testing.T) {
+	now := time.Now().UnixMilli()
+	var ts []byte
+	ts = appendLabel(ts, MetricNameLabel, "node_load1")
+	ts = appendLabel(ts, "instance", "web-1")
+	ts = appendSample(ts, 1.5, now-1000)
+	ts = appendSample(ts, 2.5, now)
+
+	series, err := ParseWriteRequest(writeRequest(ts))
+	require.NoError(t, err)
+	require.Len(t, series, 1)
+	assert.Equal(t, "node_load1", series[0].Name)
+	assert.Equal(t, map[string]string{"instance": "web-1"}, series[0].Labels)
+	require.Len(t, series[0].Samples, 2)
+	assert.Equal(t, 2.5, series[0].Samples[1].Value)
+	assert.Equal(t, now, series[0].Samples[1].Timestamp.UnixMilli())
+
+	_, err = ParseWriteRequest(writeRequest(appendSample(nil, 1, now)))
+	assert.Error(t, err, "series without a name")
+
+	_, err = ParseWriteRequest([]byte{0x0a, 0x10, 0x01})
+	assert.Error(t, err, "truncated message")
+}
+
+func TestStore_Selector(t *testing.T) {
+	store := metrics.NewSeriesStore(metrics.DefaultSeriesStoreConfig())
+	sel, err := NewSelector([]string{"node_*"})
+	require.NoError(t, err)
+
+	now := time.Now()
+	res := Store(store, []TimeSeries{
+		{Name: "node_load1", Samples: []metrics.Sample{{Timestamp: now, Value: 1}, {Timestamp: now.Add(time.Second), Value: math.NaN()}}},
+		{Name: "go_goroutines", Samples: []metrics.Sample{{Timestamp: now, Value: 10}}},
+	}, sel)
+
+	assert.Equal(t, Result{Series: 1, Samples: 1, Skipped: 1}, res)
+	assert.Equal(t, []string{"node_load1"}, store.Names())
+
+	_, err = NewSelector([]string{"node_["})
+	assert.Error(t, err)
+}
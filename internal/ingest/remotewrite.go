@@ -0,0 +1,228 @@
+// File: internal/ingest/remotewrite.go
+// Brief: Prometheus remote-write request decoding and storage
+// Detailed: Parses remote-write WriteRequest protobufs (after snappy decompression) into labelled series, keeps only the metric names selected in configuration, and appends their samples to the series store used by alert thresholds.
+// Author: drama.lin@aver.com
+// Date: 2026-10-14
+
+// Package ingest accepts metrics pushed to Argus by external systems.
+package ingest
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"path"
+	"time"
+
+	"google.golang.org/protobuf/encoding/protowire"
+
+	"argus/internal/metrics"
+)
+
+// MetricNameLabel is the label holding a Prometheus series' metric name
+const MetricNameLabel = "__name__"
+
+// TimeSeries is one series of a remote-write request
+type TimeSeries struct {
+	Name    string
+	Labels  map[string]string // Excluding MetricNameLabel
+	Samples []metrics.Sample
+}
+
+// ParseWriteRequest decodes a (decompressed) prometheus.WriteRequest. Only
+// float samples are read; exemplars, histograms and metadata are skipped.
+func ParseWriteRequest(data []byte) ([]TimeSeries, error) {
+	var result []TimeSeries
+	err := eachField(data, func(num protowire.Number, typ protowire.Type, value []byte) error {
+		if num != 1 || typ != protowire.BytesType {
+			return nil
+		}
+		ts, err := parseTimeSeries(value)
+		if err != nil {
+			return err
+		}
+		result = append(result, ts)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("invalid write request: %w", err)
+	}
+	return result, nil
+}
+
+func parseTimeSeries(data []byte) (TimeSeries, error) {
+	ts := TimeSeries{Labels: make(map[string]string)}
+	err := eachField(data, func(num protowire.Number, typ protowire.Type, value []byte) error {
+		if typ != protowire.BytesType {
+			return nil
+		}
+		switch num {
+		case 1:
+			name, val, err := parseLabel(value)
+			if err != nil {
+				return err
+			}
+			if name == MetricNameLabel {
+				ts.Name = val
+			} else {
+				ts.Labels[name] = val
+			}
+		case 2:
+			sample, err := parseSample(value)
+			if err != nil {
+				return err
+			}
+			ts.Samples = append(ts.Samples, sample)
+		}
+		return nil
+	})
+	if err != nil {
+		return TimeSeries{}, err
+	}
+	if ts.Name == "" {
+		return TimeSeries{}, errors.New("time series without " + MetricNameLabel + " label")
+	}
+	return ts, nil
+}
+
+func parseLabel(data []byte) (string, string, error) {
+	var name, value string
+	err := eachField(data, func(num protowire.Number, typ protowire.Type, v []byte) error {
+		if typ != protowire.BytesType {
+			return nil
+		}
+		switch num {
+		case 1:
+			name = string(v)
+		case 2:
+			value = string(v)
+		}
+		return nil
+	})
+	return name, value, err
+}
+
+func parseSample(data []byte) (metrics.Sample, error) {
+	var value float64
+	var timestampMs int64
+	for len(data) > 0 {
+		num, typ, n := protowire.ConsumeTag(data)
+		if n < 0 {
+			return metrics.Sample{}, protowire.ParseError(n)
+		}
+		data = data[n:]
+		switch {
+		case num == 1 && typ == protowire.Fixed64Type:
+			v, n := protowire.ConsumeFixed64(data)
+			if n < 0 {
+				return metrics.Sample{}, protowire.ParseError(n)
+			}
+			value = math.Float64frombits(v)
+			data = data[n:]
+		case num == 2 && typ == protowire.VarintType:
+			v, n := protowire.ConsumeVarint(data)
+			if n < 0 {
+				return metrics.Sample{}, protowire.ParseError(n)
+			}
+			timestampMs = int64(v)
+			data = data[n:]
+		default:
+			n := protowire.ConsumeFieldValue(num, typ, data)
+			if n < 0 {
+				return metrics.Sample{}, protowire.ParseError(n)
+			}
+			data = data[n:]
+		}
+	}
+	return metrics.Sample{Timestamp: time.UnixMilli(timestampMs).UTC(), Value: value}, nil
+}
+
+// eachField calls fn for every field of a message; value is the payload of
+// length-delimited fields and nil otherwise
+func eachField(data []byte, fn func(num protowire.Number, typ protowire.Type, value []byte) error) error {
+	for len(data) > 0 {
+		num, typ, n := protowire.ConsumeTag(data)
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		data = data[n:]
+
+		var value []byte
+		if typ == protowire.BytesType {
+			value, n = protowire.ConsumeBytes(data)
+		} else {
+			n = protowire.ConsumeFieldValue(num, typ, data)
+		}
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		data = data[n:]
+
+		if err := fn(num, typ, value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Selector decides which metric names are stored. An empty selector keeps
+// every metric.
+type Selector struct {
+	patterns []string
+}
+
+// NewSelector validates glob patterns (path.Match syntax, e.g. "node_*")
+func NewSelector(patterns []string) (Selector, error) {
+	for _, p := range patterns {
+		if _, err := path.Match(p, ""); err != nil {
+			return Selector{}, fmt.Errorf("invalid metric pattern %q: %w", p, err)
+		}
+	}
+	return Selector{patterns: patterns}, nil
+}
+
+// Match reports whether name is selected
+func (s Selector) Match(name string) bool {
+	if len(s.patterns) == 0 {
+		return true
+	}
+	for _, p := range s.patterns {
+		if ok, _ := path.Match(p, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// Result counts what a write request stored
+type Result struct {
+	Series   int `json:"series"`
+	Samples  int `json:"samples"`
+	Skipped  int `json:"skipped"`  // Series not selected
+	Rejected int `json:"rejected"` // Series refused by the store (e.g. series limit)
+}
+
+// Store appends the selected series to store
+func Store(store *metrics.SeriesStore, series []TimeSeries, sel Selector) Result {
+	var res Result
+	for _, ts := range series {
+		if !sel.Match(ts.Name) {
+			res.Skipped++
+			continue
+		}
+		samples := ts.Samples[:0]
+		for _, s := range ts.Samples {
+			// Prometheus marks stale series with a special NaN; drop all NaNs
+			if !math.IsNaN(s.Value) {
+				samples = append(samples, s)
+			}
+		}
+		if err := store.Append(ts.Name, ts.Labels, samples); err != nil {
+			res.Rejected++
+			continue
+		}
+		res.Series++
+		res.Samples += len(samples)
+	}
+	return res
+}
@@ -0,0 +1,186 @@
+// File: internal/ingest/snappy.go
+// Brief: Snappy block format decoder and encoder
+// Detailed: Decodes the snappy block (not framed) format used by Prometheus remote-write request bodies, with bounds checks so malformed or hostile input fails cleanly instead of allocating unbounded memory. Also encodes it for the remote-write requests of the metrics exporters, with a simple greedy matcher: label names and values repeat in every series, so it compresses well without the tuning of a full implementation.
+// Author: drama.lin@aver.com
+// Date: 2026-10-14
+
+package ingest
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+// ErrCorruptSnappy is returned for malformed snappy input
+var ErrCorruptSnappy = errors.New("snappy: corrupt input")
+
+// Snappy element tags (low two bits of the tag byte)
+const (
+	tagLiteral = 0x00
+	tagCopy1   = 0x01
+	tagCopy2   = 0x02
+	tagCopy4   = 0x03
+)
+
+// DecodeSnappy decompresses a snappy block. maxLen bounds the decoded size
+// announced in the header (0 means unlimited).
+func DecodeSnappy(src []byte, maxLen int) ([]byte, error) {
+	length, n := binary.Uvarint(src)
+	if n <= 0 || length > uint64(^uint32(0)) {
+		return nil, ErrCorruptSnappy
+	}
+	if maxLen > 0 && length > uint64(maxLen) {
+		return nil, fmt.Errorf("snappy: decoded size %d exceeds limit %d", length, maxLen)
+	}
+	src = src[n:]
+	dst := make([]byte, 0, length)
+
+	for len(src) > 0 {
+		tag := src[0]
+		switch tag & 0x03 {
+		case tagLiteral:
+			litLen := int(tag >> 2)
+			src = src[1:]
+			if litLen >= 60 {
+				// Lengths 60..63 mean the length-1 follows in 1..4 bytes
+				extra := litLen - 59
+				if len(src) < extra {
+					return nil, ErrCorruptSnappy
+				}
+				litLen = 0
+				for i := extra - 1; i >= 0; i-- {
+					litLen = litLen<<8 | int(src[i])
+				}
+				src = src[extra:]
+			}
+			litLen++
+			if litLen <= 0 || litLen > len(src) || uint64(len(dst)+litLen) > length {
+				return nil, ErrCorruptSnappy
+			}
+			dst = append(dst, src[:litLen]...)
+			src = src[litLen:]
+			continue
+
+		case tagCopy1:
+			if len(src) < 2 {
+				return nil, ErrCorruptSnappy
+			}
+			copyLen := 4 + int(tag>>2)&0x07
+			offset := int(tag&0xe0)<<3 | int(src[1])
+			src = src[2:]
+			if err := appendCopy(&dst, offset, copyLen, length); err != nil {
+				return nil, err
+			}
+
+		case tagCopy2:
+			if len(src) < 3 {
+				return nil, ErrCorruptSnappy
+			}
+			copyLen := 1 + int(tag>>2)
+			offset := int(binary.LittleEndian.Uint16(src[1:3]))
+			src = src[3:]
+			if err := appendCopy(&dst, offset, copyLen, length); err != nil {
+				return nil, err
+			}
+
+		case tagCopy4:
+			if len(src) < 5 {
+				return nil, ErrCorruptSnappy
+			}
+			copyLen := 1 + int(tag>>2)
+			offset := int(binary.LittleEndian.Uint32(src[1:5]))
+			src = src[5:]
+			if err := appendCopy(&dst, offset, copyLen, length); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if uint64(len(dst)) != length {
+		return nil, ErrCorruptSnappy
+	}
+	return dst, nil
+}
+
+// appendCopy appends copyLen bytes starting offset bytes back. Copies may
+// overlap their own output (offset < copyLen), so bytes are copied one by one.
+func appendCopy(dst *[]byte, offset, copyLen int, length uint64) error {
+	d := *dst
+	if offset <= 0 || offset > len(d) || uint64(len(d)+copyLen) > length {
+		return ErrCorruptSnappy
+	}
+	start := len(d) - offset
+	for i := 0; i < copyLen; i++ {
+		d = append(d, d[start+i])
+	}
+	*dst = d
+	return nil
+}
+
+// Encoder settings: matches are found through a table of the last position of
+// each hashed 4-byte sequence, and copies use 2-byte offsets
+const (
+	snappyMinMatch  = 4
+	snappyMaxOffset = 1<<16 - 1
+	snappyMaxCopy   = 64
+	snappyTableBits = 14
+)
+
+// EncodeSnappy compresses src as a snappy block
+func EncodeSnappy(src []byte) []byte {
+	dst := binary.AppendUvarint(make([]byte, 0, len(src)/2+16), uint64(len(src)))
+	var table [1 << snappyTableBits]int32 // Position+1 of the sequences, 0 when empty
+	literal := 0                          // Start of the bytes not yet emitted
+	for i := 0; i+snappyMinMatch <= len(src); {
+		v := binary.LittleEndian.Uint32(src[i:])
+		h := (v * 0x1e35a7bd) >> (32 - snappyTableBits)
+		candidate := int(table[h]) - 1
+		table[h] = int32(i + 1)
+		if candidate < 0 || i-candidate > snappyMaxOffset || binary.LittleEndian.Uint32(src[candidate:]) != v {
+			i++
+			continue
+		}
+		length := snappyMinMatch
+		for i+length < len(src) && src[candidate+length] == src[i+length] {
+			length++
+		}
+		dst = appendSnappyLiteral(dst, src[literal:i])
+		dst = appendSnappyCopy(dst, i-candidate, length)
+		i += length
+		literal = i
+	}
+	return appendSnappyLiteral(dst, src[literal:])
+}
+
+// appendSnappyLiteral appends a literal element holding lit
+func appendSnappyLiteral(dst, lit []byte) []byte {
+	if len(lit) == 0 {
+		return dst
+	}
+	n := len(lit) - 1
+	switch {
+	case n < 60:
+		dst = append(dst, byte(n)<<2|tagLiteral)
+	case n < 1<<8:
+		dst = append(dst, 60<<2|tagLiteral, byte(n))
+	case n < 1<<16:
+		dst = append(dst, 61<<2|tagLiteral, byte(n), byte(n>>8))
+	case n < 1<<24:
+		dst = append(dst, 62<<2|tagLiteral, byte(n), byte(n>>8), byte(n>>16))
+	default:
+		dst = append(dst, 63<<2|tagLiteral, byte(n), byte(n>>8), byte(n>>16), byte(n>>24))
+	}
+	return append(dst, lit...)
+}
+
+// appendSnappyCopy appends copy elements repeating length bytes from offset
+// bytes back, at most snappyMaxCopy bytes each
+func appendSnappyCopy(dst []byte, offset, length int) []byte {
+	for length > 0 {
+		n := min(length, snappyMaxCopy)
+		dst = append(dst, byte(n-1)<<2|tagCopy2, byte(offset), byte(offset>>8))
+		length -= n
+	}
+	return dst
+}
@@ -0,0 +1,152 @@
+// File: internal/logwatch/file.go
+// Brief: Tailed log file source
+// Detailed: Follows a log file from its current end by polling, reopening it when it is rotated (replaced by a new file) and rereading it from the start when it is truncated. The file may not exist yet.
+// Author: drama.lin@aver.com
+// Date: 2026-10-14
+
+package logwatch
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"strings"
+	"time"
+)
+
+// DefaultPollInterval is how often a tailed file is checked for new lines
+const DefaultPollInterval = time.Second
+
+// maxLineLength is the longest partial line buffered while waiting for its
+// end; longer lines are emitted in pieces
+const maxLineLength = 64 << 10
+
+// File tails a log file
+type File struct {
+	Path string
+	Poll time.Duration // Check interval (0 uses DefaultPollInterval)
+}
+
+// tail is the open state of a tailed file
+type tail struct {
+	file    *os.File
+	info    os.FileInfo
+	reader  *bufio.Reader
+	offset  int64
+	partial string
+}
+
+// Run implements Source
+func (f *File) Run(ctx context.Context, emit func(Entry)) error {
+	poll := f.Poll
+	if poll <= 0 {
+		poll = DefaultPollInterval
+	}
+	ticker := time.NewTicker(poll)
+	defer ticker.Stop()
+
+	var t *tail
+	defer func() {
+		if t != nil {
+			t.file.Close()
+		}
+	}()
+	first := true
+	for {
+		var err error
+		if t, err = f.check(t, first, emit); err != nil {
+			return err
+		}
+		first = false
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// check reads the lines appended since the last check, following rotation
+// and truncation. The file is read from its end when it is first opened at
+// startup, and from its start when it appears or is replaced later.
+func (f *File) check(t *tail, atEnd bool, emit func(Entry)) (*tail, error) {
+	info, err := os.Stat(f.Path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return t, nil // Rotated away or not created yet
+		}
+		return t, err
+	}
+
+	if t != nil && !os.SameFile(info, t.info) {
+		// Rotated: finish the old file before switching to the new one
+		if err := f.read(t, emit); err != nil {
+			return t, err
+		}
+		f.flush(t, emit)
+		t.file.Close()
+		t = nil
+	}
+	if t == nil {
+		file, err := os.Open(f.Path)
+		if err != nil {
+			return nil, err
+		}
+		t = &tail{file: file, info: info, reader: bufio.NewReader(file)}
+		if atEnd {
+			if t.offset, err = file.Seek(0, io.SeekEnd); err != nil {
+				file.Close()
+				return nil, err
+			}
+		}
+	}
+	if info.Size() < t.offset {
+		// Truncated in place
+		if _, err := t.file.Seek(0, io.SeekStart); err != nil {
+			return t, err
+		}
+		t.reader.Reset(t.file)
+		t.offset, t.partial = 0, ""
+	}
+	t.info = info
+	return t, f.read(t, emit)
+}
+
+// read emits the complete lines available
+func (f *File) read(t *tail, emit func(Entry)) error {
+	for {
+		chunk, err := t.reader.ReadString('\n')
+		t.offset += int64(len(chunk))
+		if err == nil {
+			f.emit(t.partial+chunk, emit)
+			t.partial = ""
+			continue
+		}
+		t.partial += chunk
+		if len(t.partial) >= maxLineLength {
+			f.flush(t, emit)
+		}
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		return err
+	}
+}
+
+// flush emits a buffered partial line
+func (f *File) flush(t *tail, emit func(Entry)) {
+	if t.partial != "" {
+		f.emit(t.partial, emit)
+		t.partial = ""
+	}
+}
+
+func (f *File) emit(line string, emit func(Entry)) {
+	line = strings.TrimRight(line, "\r\n")
+	if line == "" {
+		return
+	}
+	emit(Entry{Time: time.Now().UTC(), Source: SourceFile, Unit: f.Path, Priority: NoPriority, Message: line})
+}
@@ -0,0 +1,343 @@
+// File: internal/logwatch/logwatch.go
+// Brief: Log watches for pattern alerts
+// Detailed: Reads log entries from sources such as tailed files, systemd-journald and the syslog listener, counts the entries matching each watch's pattern over a rolling window for log alerts, and keeps the most recent matches for the API.
+// Author: drama.lin@aver.com
+// Date: 2026-10-14
+
+// Package logwatch matches log entries against patterns for log alerts.
+package logwatch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"argus/internal/clock"
+)
+
+// Source kinds a watch can read from
+const (
+	SourceFile     = "file"
+	SourceJournald = "journald"
+	SourceSyslog   = "syslog"
+)
+
+// SourceKinds lists every source kind
+var SourceKinds = []string{SourceFile, SourceJournald, SourceSyslog}
+
+// Defaults of watch settings
+const (
+	DefaultWindow        = 5 * time.Minute
+	DefaultRecentMatches = 20
+)
+
+// retryDelay is how long a failed source waits before it is restarted
+const retryDelay = 5 * time.Second
+
+// Priorities are the syslog severities journald and syslog entries carry,
+// most severe first; an entry's priority is its index
+var Priorities = []string{"emerg", "alert", "crit", "err", "warning", "notice", "info", "debug"}
+
+// NoPriority marks entries that carry no priority, such as file lines
+const NoPriority = -1
+
+// ParsePriority parses a priority name ("err", also "error" and "warn") or
+// number (0-7)
+func ParsePriority(s string) (int, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	switch s {
+	case "error":
+		s = "err"
+	case "warn":
+		s = "warning"
+	}
+	if i := slices.Index(Priorities, s); i >= 0 {
+		return i, nil
+	}
+	if n, err := strconv.Atoi(s); err == nil && n >= 0 && n < len(Priorities) {
+		return n, nil
+	}
+	return 0, fmt.Errorf("invalid priority %q: must be one of %s or 0-7", s, strings.Join(Priorities, ", "))
+}
+
+// PriorityName returns the name of a priority, or "" for NoPriority
+func PriorityName(priority int) string {
+	if priority < 0 || priority >= len(Priorities) {
+		return ""
+	}
+	return Priorities[priority]
+}
+
+// Entry is one log entry read from a source
+type Entry struct {
+	Time     time.Time `json:"time"`
+	Source   string    `json:"source"`         // Source kind, e.g. "journald"
+	Unit     string    `json:"unit,omitempty"` // Systemd unit, syslog app name, or the file path of file entries
+	Host     string    `json:"host,omitempty"`
+	Facility string    `json:"facility,omitempty"` // Syslog facility, e.g. "daemon"
+	Priority int       `json:"priority"`           // Index into Priorities, or NoPriority
+	Message  string    `json:"message"`
+}
+
+// Source produces log entries until its context is cancelled
+type Source interface {
+	Run(ctx context.Context, emit func(Entry)) error
+}
+
+// Watch counts the log entries of one source matching a pattern. The match
+// count over the window is the value of log alerts naming the watch.
+type Watch struct {
+	Name     string   `yaml:"name" json:"name"`
+	Source   string   `yaml:"source" json:"source"`               // file, journald or syslog
+	Path     string   `yaml:"path" json:"path,omitempty"`         // File to tail
+	Units    []string `yaml:"units" json:"units,omitempty"`       // Journald units or syslog app names to read (empty reads all)
+	Priority string   `yaml:"priority" json:"priority,omitempty"` // Least severe journald or syslog priority read, e.g. "err"
+	Pattern  string   `yaml:"pattern" json:"pattern"`             // Regular expression matched against messages
+	Window   string   `yaml:"window" json:"window,omitempty"`     // Period matches are counted over (default 5m)
+}
+
+// Validate checks the watch's settings
+func (w *Watch) Validate() error {
+	if w.Name == "" {
+		return errors.New("name is required")
+	}
+	if !slices.Contains(SourceKinds, w.Source) {
+		return fmt.Errorf("invalid source %q: must be one of %s", w.Source, strings.Join(SourceKinds, ", "))
+	}
+	if w.Source == SourceFile && w.Path == "" {
+		return errors.New("file source requires a path")
+	}
+	if w.Source == SourceFile && (len(w.Units) > 0 || w.Priority != "") {
+		return errors.New("units and priority only apply to the journald and syslog sources")
+	}
+	if w.Priority != "" {
+		if _, err := ParsePriority(w.Priority); err != nil {
+			return err
+		}
+	}
+	if w.Pattern == "" {
+		return errors.New("pattern is required")
+	}
+	if _, err := regexp.Compile(w.Pattern); err != nil {
+		return fmt.Errorf("invalid pattern: %w", err)
+	}
+	if w.Window != "" {
+		if d, err := time.ParseDuration(w.Window); err != nil || d < time.Second {
+			return fmt.Errorf("invalid window %q: must be a duration of at least 1s", w.Window)
+		}
+	}
+	return nil
+}
+
+// window returns the watch's counting period
+func (w *Watch) window() time.Duration {
+	if d, err := time.ParseDuration(w.Window); err == nil && d > 0 {
+		return d
+	}
+	return DefaultWindow
+}
+
+// source builds the source the watch reads from; syslog watches subscribe
+// to the listener
+func (w *Watch) source(listener *Syslog) (Source, error) {
+	switch w.Source {
+	case SourceJournald:
+		return &Journald{Units: w.Units, Priority: w.Priority}, nil
+	case SourceSyslog:
+		if listener == nil {
+			return nil, errors.New("syslog source requires the syslog listener")
+		}
+		return listener.Subscribe(w.Units, w.Priority)
+	default:
+		return &File{Path: w.Path}, nil
+	}
+}
+
+// Status is the state of a watch reported by the API
+type Status struct {
+	Watch
+	Matches   int        `json:"matches"` // Matches within the window
+	Total     uint64     `json:"total"`   // Matches since startup
+	LastMatch *time.Time `json:"last_match,omitempty"`
+	Recent    []Entry    `json:"recent"` // Most recent matches, newest first
+	Error     string     `json:"error,omitempty"`
+}
+
+// Config holds configuration for the watcher
+type Config struct {
+	RecentMatches int         // Matches kept per watch for the API
+	Syslog        *Syslog     // Listener syslog watches read from
+	Clock         clock.Clock // Time source (nil uses the real clock)
+}
+
+// DefaultConfig returns default configuration for the watcher
+func DefaultConfig() Config {
+	return Config{RecentMatches: DefaultRecentMatches}
+}
+
+// watchState tracks the matches of one watch
+type watchState struct {
+	watch   Watch
+	pattern *regexp.Regexp
+	source  Source
+	window  time.Duration
+
+	mu      sync.Mutex
+	times   []time.Time // Match times within the window, oldest first
+	recent  []Entry     // Newest last
+	total   uint64
+	lastErr string
+}
+
+// Watcher runs the sources of every watch and counts their matches
+type Watcher struct {
+	config  Config
+	clock   clock.Clock
+	watches []*watchState
+	byName  map[string]*watchState
+}
+
+// NewWatcher creates a watcher for the given watches
+func NewWatcher(watches []Watch, config Config) (*Watcher, error) {
+	w := &Watcher{config: config, clock: clock.OrReal(config.Clock), byName: make(map[string]*watchState, len(watches))}
+	for _, watch := range watches {
+		if err := watch.Validate(); err != nil {
+			return nil, fmt.Errorf("log watch %q: %w", watch.Name, err)
+		}
+		if _, ok := w.byName[watch.Name]; ok {
+			return nil, fmt.Errorf("duplicate log watch: %s", watch.Name)
+		}
+		source, err := watch.source(config.Syslog)
+		if err != nil {
+			return nil, fmt.Errorf("log watch %q: %w", watch.Name, err)
+		}
+		state := &watchState{watch: watch, pattern: regexp.MustCompile(watch.Pattern), source: source, window: watch.window()}
+		w.watches = append(w.watches, state)
+		w.byName[watch.Name] = state
+	}
+	return w, nil
+}
+
+// Start runs every watch's source in the background until ctx is cancelled.
+// A source that fails is restarted after a delay.
+func (w *Watcher) Start(ctx context.Context) {
+	for _, state := range w.watches {
+		go w.run(ctx, state)
+	}
+}
+
+func (w *Watcher) run(ctx context.Context, state *watchState) {
+	failed := false
+	emit := func(entry Entry) {
+		if failed {
+			// The source recovered
+			state.mu.Lock()
+			state.lastErr = ""
+			state.mu.Unlock()
+			failed = false
+		}
+		w.observe(state, entry)
+	}
+	for {
+		err := state.source.Run(ctx, emit)
+		if ctx.Err() != nil {
+			return
+		}
+		if err == nil {
+			err = errors.New("source stopped")
+		}
+		slog.Warn("Log watch source failed", "watch", state.watch.Name, "source", state.watch.Source, "error", err, "retry_in", retryDelay)
+		state.mu.Lock()
+		state.lastErr = err.Error()
+		state.mu.Unlock()
+		failed = true
+		select {
+		case <-ctx.Done():
+			return
+		case <-w.clock.After(retryDelay):
+		}
+	}
+}
+
+// observe records entry if it matches the watch's pattern
+func (w *Watcher) observe(state *watchState, entry Entry) {
+	if !state.pattern.MatchString(entry.Message) {
+		return
+	}
+	now := w.clock.Now()
+	if entry.Time.IsZero() {
+		entry.Time = now
+	}
+
+	state.mu.Lock()
+	defer state.mu.Unlock()
+	state.total++
+	state.times = append(prune(state.times, now.Add(-state.window)), now)
+	state.recent = append(state.recent, entry)
+	if limit := w.config.RecentMatches; limit > 0 && len(state.recent) > limit {
+		state.recent = slices.Delete(state.recent, 0, len(state.recent)-limit)
+	}
+}
+
+// prune drops the times before cutoff
+func prune(times []time.Time, cutoff time.Time) []time.Time {
+	i := 0
+	for i < len(times) && times[i].Before(cutoff) {
+		i++
+	}
+	return times[i:]
+}
+
+// Count returns the matches of the named watch within its window
+func (w *Watcher) Count(name string) (int, error) {
+	state, ok := w.byName[name]
+	if !ok {
+		return 0, fmt.Errorf("unknown log watch: %s", name)
+	}
+	state.mu.Lock()
+	defer state.mu.Unlock()
+	state.times = prune(state.times, w.clock.Now().Add(-state.window))
+	return len(state.times), nil
+}
+
+// Status returns the state of the named watch
+func (w *Watcher) Status(name string) (Status, bool) {
+	state, ok := w.byName[name]
+	if !ok {
+		return Status{}, false
+	}
+	return w.status(state), true
+}
+
+// Statuses returns the state of every watch in configuration order
+func (w *Watcher) Statuses() []Status {
+	result := make([]Status, 0, len(w.watches))
+	for _, state := range w.watches {
+		result = append(result, w.status(state))
+	}
+	return result
+}
+
+func (w *Watcher) status(state *watchState) Status {
+	state.mu.Lock()
+	defer state.mu.Unlock()
+	state.times = prune(state.times, w.clock.Now().Add(-state.window))
+
+	status := Status{Watch: state.watch, Matches: len(state.times), Total: state.total, Error: state.lastErr}
+	status.Recent = make([]Entry, 0, len(state.recent))
+	for i := len(state.recent) - 1; i >= 0; i-- {
+		status.Recent = append(status.Recent, state.recent[i])
+	}
+	if len(state.recent) > 0 {
+		last := state.recent[len(state.recent)-1].Time
+		status.LastMatch = &last
+	}
+	return status
+}
@@ -0,0 +1,260 @@
+package logwatch
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"argus/internal/clock"
+)
+
+var epoch = time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+func TestParsePriority(t *testing.T) {
+	for input, want := range map[string]int{"emerg": 0, "err": 3, "error": 3, "WARN": 4, "debug": 7, "5": 5} {
+		got, err := ParsePriority(input)
+		require.NoError(t, err, input)
+		assert.Equal(t, want, got, input)
+	}
+	for _, input := range []string{"", "fatal", "8", "-1"} {
+		_, err := ParsePriority(input)
+		assert.Error(t, err, input)
+	}
+	assert.Equal(t, "crit", PriorityName(2))
+	assert.Equal(t, "", PriorityName(NoPriority))
+}
+
+func TestWatch_Validate(t *testing.T) {
+	valid := Watch{Name: "oom", Source: SourceJournald, Units: []string{"app.service"}, Priority: "err", Pattern: "out of memory", Window: "10m"}
+	assert.NoError(t, valid.Validate())
+
+	for name, mutate := range map[string]func(*Watch){
+		"no name":           func(w *Watch) { w.Name = "" },
+		"unknown source":    func(w *Watch) { w.Source = "eventlog" },
+		"file without path": func(w *Watch) { w.Source, w.Units, w.Priority = SourceFile, nil, "" },
+		"file with units":   func(w *Watch) { w.Source, w.Path = SourceFile, "/var/log/app.log" },
+		"bad priority":      func(w *Watch) { w.Priority = "loud" },
+		"no pattern":        func(w *Watch) { w.Pattern = "" },
+		"bad pattern":       func(w *Watch) { w.Pattern = "(" },
+		"short window":      func(w *Watch) { w.Window = "10ms" },
+	} {
+		w := valid
+		mutate(&w)
+		assert.Error(t, w.Validate(), name)
+	}
+}
+
+// staticSource emits its entries and waits for cancellation
+type staticSource struct {
+	entries []Entry
+	done    sync.WaitGroup
+}
+
+func (s *staticSource) Run(ctx context.Context, emit func(Entry)) error {
+	for _, entry := range s.entries {
+		emit(entry)
+	}
+	s.done.Done()
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+func TestWatcher_CountsMatchesInWindow(t *testing.T) {
+	clk := clock.NewFake(epoch)
+	config := DefaultConfig()
+	config.Clock = clk
+	config.RecentMatches = 2
+	w, err := NewWatcher([]Watch{{Name: "errors", Source: SourceFile, Path: "/var/log/app.log", Pattern: `(?i)error`, Window: "1m"}}, config)
+	require.NoError(t, err)
+
+	state := w.byName["errors"]
+	for _, message := range []string{"ERROR one", "all good", "error two"} {
+		w.observe(state, Entry{Source: SourceFile, Message: message, Priority: NoPriority})
+	}
+	clk.Advance(45 * time.Second)
+	w.observe(state, Entry{Source: SourceFile, Message: "error three", Priority: NoPriority})
+
+	count, err := w.Count("errors")
+	require.NoError(t, err)
+	assert.Equal(t, 3, count)
+
+	clk.Advance(30 * time.Second)
+	count, _ = w.Count("errors")
+	assert.Equal(t, 1, count, "the first two matches left the window")
+
+	status, ok := w.Status("errors")
+	require.True(t, ok)
+	assert.Equal(t, uint64(3), status.Total)
+	require.Len(t, status.Recent, 2, "recent matches are bounded")
+	assert.Equal(t, "error three", status.Recent[0].Message, "newest first")
+	assert.Equal(t, "error two", status.Recent[1].Message)
+	assert.Equal(t, epoch.Add(45*time.Second), *status.LastMatch, "entries without a time are stamped on arrival")
+
+	_, err = w.Count("missing")
+	assert.Error(t, err)
+}
+
+func TestWatcher_StartRunsSources(t *testing.T) {
+	w, err := NewWatcher([]Watch{{Name: "panics", Source: SourceJournald, Pattern: "panic"}}, DefaultConfig())
+	require.NoError(t, err)
+	source := &staticSource{entries: []Entry{{Message: "panic: nil map"}, {Message: "started"}}}
+	source.done.Add(1)
+	w.byName["panics"].source = source
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	w.Start(ctx)
+	source.done.Wait()
+
+	statuses := w.Statuses()
+	require.Len(t, statuses, 1)
+	assert.Equal(t, "panics", statuses[0].Name)
+	assert.Equal(t, 1, statuses[0].Matches)
+}
+
+func TestNewWatcher_Rejects(t *testing.T) {
+	_, err := NewWatcher([]Watch{{Name: "a", Source: SourceJournald, Pattern: "x"}, {Name: "a", Source: SourceJournald, Pattern: "y"}}, DefaultConfig())
+	assert.ErrorContains(t, err, "duplicate")
+	_, err = NewWatcher([]Watch{{Name: "a", Source: SourceJournald}}, DefaultConfig())
+	assert.ErrorContains(t, err, "pattern is required")
+}
+
+func TestParseJournalEntry(t *testing.T) {
+	entry, err := parseJournalEntry([]byte(`{"MESSAGE":"disk full","PRIORITY":"3","__REALTIME_TIMESTAMP":"1767225600000000","_SYSTEMD_UNIT":"app.service","_HOSTNAME":"db1"}`))
+	require.NoError(t, err)
+	assert.Equal(t, Entry{Time: epoch, Source: SourceJournald, Unit: "app.service", Host: "db1", Priority: 3, Message: "disk full"}, entry)
+
+	entry, err = parseJournalEntry([]byte(`{"MESSAGE":[104,105],"SYSLOG_IDENTIFIER":"kernel"}`))
+	require.NoError(t, err)
+	assert.Equal(t, "hi", entry.Message, "binary messages are byte arrays")
+	assert.Equal(t, "kernel", entry.Unit, "falls back to the syslog identifier")
+	assert.Equal(t, NoPriority, entry.Priority)
+
+	_, err = parseJournalEntry([]byte(`{"PRIORITY":"3"}`))
+	assert.Error(t, err)
+	_, err = parseJournalEntry([]byte(`not json`))
+	assert.Error(t, err)
+}
+
+func TestJournald_Args(t *testing.T) {
+	args, err := (&Journald{Units: []string{"a.service", "b.service"}, Priority: "warning"}).args()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"--follow", "--lines=0", "--output=json", "--no-pager", "--unit=a.service", "--unit=b.service", "--priority=4"}, args)
+}
+
+func TestJournald_Run(t *testing.T) {
+	script := filepath.Join(t.TempDir(), "journalctl")
+	require.NoError(t, os.WriteFile(script, []byte("#!/bin/sh\n"+
+		"echo '{\"MESSAGE\":\"first\",\"PRIORITY\":\"6\"}'\n"+
+		"echo 'garbage'\n"+
+		"echo '{\"MESSAGE\":\"second\",\"PRIORITY\":\"2\"}'\n"), 0o755))
+
+	var messages []string
+	err := (&Journald{Command: script}).Run(context.Background(), func(e Entry) { messages = append(messages, e.Message) })
+	assert.ErrorContains(t, err, "exited", "journalctl exiting is an error so the watcher restarts it")
+	assert.Equal(t, []string{"first", "second"}, messages)
+
+	err = (&Journald{Command: filepath.Join(t.TempDir(), "missing")}).Run(context.Background(), func(Entry) {})
+	assert.ErrorContains(t, err, "failed to start")
+}
+
+// collect gathers the messages of file entries
+type collect struct{ messages []string }
+
+func (c *collect) emit(e Entry) { c.messages = append(c.messages, e.Message) }
+
+func appendFile(t *testing.T, path, data string) {
+	t.Helper()
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	require.NoError(t, err)
+	_, err = f.WriteString(data)
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+}
+
+func TestFile_Tail(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	appendFile(t, path, "old line\n")
+	f := &File{Path: path}
+	var c collect
+
+	tail, err := f.check(nil, true, c.emit)
+	require.NoError(t, err)
+	assert.Empty(t, c.messages, "existing lines are skipped at startup")
+
+	appendFile(t, path, "one\ntw")
+	tail, err = f.check(tail, false, c.emit)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"one"}, c.messages, "partial lines wait for their end")
+
+	appendFile(t, path, "o\r\n")
+	tail, err = f.check(tail, false, c.emit)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"one", "two"}, c.messages)
+
+	// Truncated in place
+	require.NoError(t, os.WriteFile(path, []byte("x\n"), 0o644))
+	tail, err = f.check(tail, false, c.emit)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"one", "two", "x"}, c.messages)
+
+	// Rotated: the rest of the old file is read, then the new one from its start
+	appendFile(t, path, "last old\n")
+	require.NoError(t, os.Rename(path, path+".1"))
+	tail, err = f.check(tail, false, c.emit)
+	require.NoError(t, err, "a missing file is waited for")
+	appendFile(t, path, "new\n")
+	tail, err = f.check(tail, false, c.emit)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"one", "two", "x", "last old", "new"}, c.messages)
+	tail.file.Close()
+}
+
+func TestFile_CreatedLater(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "later.log")
+	f := &File{Path: path}
+	var c collect
+
+	tail, err := f.check(nil, true, c.emit)
+	require.NoError(t, err)
+	assert.Nil(t, tail, "a missing file is waited for")
+
+	appendFile(t, path, "first\n")
+	tail, err = f.check(tail, false, c.emit)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"first"}, c.messages, "a file created after startup is read from its start")
+	tail.file.Close()
+}
+
+func TestFile_RunUntilCancelled(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	appendFile(t, path, "old\n")
+	ctx, cancel := context.WithCancel(context.Background())
+	lines := make(chan string, 100)
+	done := make(chan error, 1)
+	go func() {
+		done <- (&File{Path: path, Poll: 10 * time.Millisecond}).Run(ctx, func(e Entry) { lines <- e.Message })
+	}()
+
+	// Appended until the tail, which starts at the end, picks a line up
+	deadline := time.After(5 * time.Second)
+	var line string
+	for line == "" {
+		appendFile(t, path, "new\n")
+		select {
+		case line = <-lines:
+		case <-time.After(20 * time.Millisecond):
+		case <-deadline:
+			t.Fatal("no line read")
+		}
+	}
+	assert.Equal(t, "new", line)
+	cancel()
+	assert.ErrorIs(t, <-done, context.Canceled)
+}
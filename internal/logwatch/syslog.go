@@ -0,0 +1,511 @@
+// File: internal/logwatch/syslog.go
+// Brief: Syslog ingestion listener
+// Detailed: Receives RFC 3164 and RFC 5424 syslog messages over UDP and TCP (octet-counted or newline-framed) from network devices and legacy applications, keeps a bounded buffer of recent messages for the API and feeds them to the syslog log watches.
+// Author: drama.lin@aver.com
+// Date: 2026-10-14
+
+package logwatch
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"argus/internal/clock"
+)
+
+// Facilities are the syslog facilities; a message's facility is its index
+var Facilities = []string{"kern", "user", "mail", "daemon", "auth", "syslog", "lpr", "news", "uucp", "cron", "authpriv", "ftp",
+	"ntp", "security", "console", "solaris-cron", "local0", "local1", "local2", "local3", "local4", "local5", "local6", "local7"}
+
+// MinSyslogMessageSize is the message size every receiver must accept (RFC 5424)
+const MinSyslogMessageSize = 480
+
+// SyslogConfig holds configuration for the syslog listener
+type SyslogConfig struct {
+	UDPAddress     string      // Empty disables UDP
+	TCPAddress     string      // Empty disables TCP
+	BufferSize     int         // Recent messages kept for the API
+	MaxMessageSize int         // Longer messages are truncated
+	Clock          clock.Clock // Time source (nil uses the real clock)
+}
+
+// DefaultSyslogConfig returns default configuration for the syslog listener
+func DefaultSyslogConfig() SyslogConfig {
+	return SyslogConfig{
+		UDPAddress:     ":5514",
+		BufferSize:     1000,
+		MaxMessageSize: 8192,
+	}
+}
+
+// SyslogStats reports the listener's addresses and counters
+type SyslogStats struct {
+	UDPAddress string `json:"udp_address,omitempty"`
+	TCPAddress string `json:"tcp_address,omitempty"`
+	Received   uint64 `json:"received"`
+	Invalid    uint64 `json:"invalid"` // Messages that could not be parsed
+	Buffered   int    `json:"buffered"`
+	BufferSize int    `json:"buffer_size"`
+}
+
+// MessageFilter selects buffered messages. Empty fields match everything.
+type MessageFilter struct {
+	Host     string
+	Unit     string // App name
+	Priority int    // Least severe priority, or NoPriority
+	Contains string // Case-insensitive substring of the message
+	Limit    int    // Messages returned at most (0 returns all)
+}
+
+// Syslog is the syslog listener
+type Syslog struct {
+	config SyslogConfig
+	clock  clock.Clock
+
+	mu      sync.RWMutex
+	buffer  []Entry // Ring of recent messages
+	next    int     // Index the next message is stored at
+	wrapped bool
+
+	subMu       sync.RWMutex
+	subscribers map[*subscription]struct{}
+
+	received atomic.Uint64
+	invalid  atomic.Uint64
+
+	udp net.PacketConn
+	tcp net.Listener
+}
+
+// NewSyslog creates a syslog listener; Start binds its addresses
+func NewSyslog(config SyslogConfig) *Syslog {
+	if config.MaxMessageSize < MinSyslogMessageSize {
+		config.MaxMessageSize = MinSyslogMessageSize
+	}
+	return &Syslog{
+		config:      config,
+		clock:       clock.OrReal(config.Clock),
+		buffer:      make([]Entry, max(config.BufferSize, 0)),
+		subscribers: make(map[*subscription]struct{}),
+	}
+}
+
+// Start binds the configured addresses and receives messages in the
+// background until ctx is cancelled
+func (s *Syslog) Start(ctx context.Context) error {
+	if s.config.UDPAddress != "" {
+		conn, err := net.ListenPacket("udp", s.config.UDPAddress)
+		if err != nil {
+			return fmt.Errorf("failed to listen for syslog on udp %s: %w", s.config.UDPAddress, err)
+		}
+		s.udp = conn
+		go s.serveUDP()
+	}
+	if s.config.TCPAddress != "" {
+		listener, err := net.Listen("tcp", s.config.TCPAddress)
+		if err != nil {
+			if s.udp != nil {
+				s.udp.Close()
+			}
+			return fmt.Errorf("failed to listen for syslog on tcp %s: %w", s.config.TCPAddress, err)
+		}
+		s.tcp = listener
+		go s.serveTCP(ctx)
+	}
+	go func() {
+		<-ctx.Done()
+		if s.udp != nil {
+			s.udp.Close()
+		}
+		if s.tcp != nil {
+			s.tcp.Close()
+		}
+	}()
+	return nil
+}
+
+func (s *Syslog) serveUDP() {
+	buf := make([]byte, s.config.MaxMessageSize)
+	for {
+		n, addr, err := s.udp.ReadFrom(buf)
+		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				return
+			}
+			slog.Debug("Syslog UDP read failed", "error", err)
+			continue
+		}
+		s.Ingest(buf[:n], remoteHost(addr))
+	}
+}
+
+func (s *Syslog) serveTCP(ctx context.Context) {
+	for {
+		conn, err := s.tcp.Accept()
+		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				return
+			}
+			slog.Debug("Syslog TCP accept failed", "error", err)
+			continue
+		}
+		go s.serveConn(ctx, conn)
+	}
+}
+
+// serveConn reads the messages of one TCP connection, framed as
+// "<length> <message>" (RFC 6587 octet counting) or one per line
+func (s *Syslog) serveConn(ctx context.Context, conn net.Conn) {
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+		case <-done:
+		}
+		conn.Close()
+	}()
+
+	host := remoteHost(conn.RemoteAddr())
+	reader := bufio.NewReaderSize(conn, s.config.MaxMessageSize)
+	for {
+		message, err := s.readFrame(reader)
+		if len(message) > 0 {
+			s.Ingest(message, host)
+		}
+		if err != nil {
+			if !errors.Is(err, io.EOF) && !errors.Is(err, net.ErrClosed) {
+				slog.Debug("Syslog TCP connection closed", "remote", host, "error", err)
+			}
+			return
+		}
+	}
+}
+
+// readFrame reads one message from a TCP stream
+func (s *Syslog) readFrame(reader *bufio.Reader) ([]byte, error) {
+	first, err := reader.Peek(1)
+	if err != nil {
+		return nil, err
+	}
+	if first[0] >= '1' && first[0] <= '9' {
+		prefix, err := reader.ReadString(' ')
+		if err != nil {
+			return nil, err
+		}
+		length, err := strconv.Atoi(strings.TrimSuffix(prefix, " "))
+		if err != nil || length <= 0 {
+			return nil, fmt.Errorf("invalid octet count %q", prefix)
+		}
+		message := make([]byte, min(length, s.config.MaxMessageSize))
+		if _, err := io.ReadFull(reader, message); err != nil {
+			return nil, err
+		}
+		if length > len(message) {
+			// Truncated: skip the rest of the frame
+			if _, err := reader.Discard(length - len(message)); err != nil {
+				return message, err
+			}
+		}
+		return message, nil
+	}
+
+	var line []byte
+	for {
+		chunk, isPrefix, err := reader.ReadLine()
+		if len(line) < s.config.MaxMessageSize {
+			line = append(line, chunk...)
+		}
+		if err != nil || !isPrefix {
+			return truncate(line, s.config.MaxMessageSize), err
+		}
+	}
+}
+
+func truncate(data []byte, n int) []byte {
+	if len(data) > n {
+		return data[:n]
+	}
+	return data
+}
+
+// remoteHost returns the IP of a remote address
+func remoteHost(addr net.Addr) string {
+	if addr == nil {
+		return ""
+	}
+	host, _, err := net.SplitHostPort(addr.String())
+	if err != nil {
+		return addr.String()
+	}
+	return host
+}
+
+// Ingest parses one message received from remote, buffers it and passes it
+// to the subscribed watches
+func (s *Syslog) Ingest(data []byte, remote string) {
+	entry, err := parseSyslog(data, s.clock.Now())
+	if err != nil {
+		s.invalid.Add(1)
+		slog.Debug("Invalid syslog message", "remote", remote, "error", err)
+		return
+	}
+	s.received.Add(1)
+	if entry.Host == "" {
+		entry.Host = remote
+	}
+
+	if len(s.buffer) > 0 {
+		s.mu.Lock()
+		s.buffer[s.next] = entry
+		s.next = (s.next + 1) % len(s.buffer)
+		if s.next == 0 {
+			s.wrapped = true
+		}
+		s.mu.Unlock()
+	}
+
+	s.subMu.RLock()
+	defer s.subMu.RUnlock()
+	for sub := range s.subscribers {
+		sub.deliver(entry)
+	}
+}
+
+// Messages returns the buffered messages matching filter, newest first
+func (s *Syslog) Messages(filter MessageFilter) []Entry {
+	contains := strings.ToLower(filter.Contains)
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	count := s.next
+	if s.wrapped {
+		count = len(s.buffer)
+	}
+	result := make([]Entry, 0, min(count, max(filter.Limit, 0)))
+	for i := 0; i < count; i++ {
+		entry := s.buffer[(s.next-1-i+len(s.buffer))%len(s.buffer)]
+		switch {
+		case filter.Host != "" && entry.Host != filter.Host,
+			filter.Unit != "" && entry.Unit != filter.Unit,
+			filter.Priority != NoPriority && (entry.Priority == NoPriority || entry.Priority > filter.Priority),
+			contains != "" && !strings.Contains(strings.ToLower(entry.Message), contains):
+			continue
+		}
+		result = append(result, entry)
+		if filter.Limit > 0 && len(result) == filter.Limit {
+			break
+		}
+	}
+	return result
+}
+
+// Stats returns the listener's addresses and counters
+func (s *Syslog) Stats() SyslogStats {
+	stats := SyslogStats{Received: s.received.Load(), Invalid: s.invalid.Load(), BufferSize: len(s.buffer)}
+	if s.udp != nil {
+		stats.UDPAddress = s.udp.LocalAddr().String()
+	}
+	if s.tcp != nil {
+		stats.TCPAddress = s.tcp.Addr().String()
+	}
+	s.mu.RLock()
+	stats.Buffered = s.next
+	if s.wrapped {
+		stats.Buffered = len(s.buffer)
+	}
+	s.mu.RUnlock()
+	return stats
+}
+
+// Subscribe returns a source of the received messages from the given app
+// names (empty for all) at least as severe as priority (empty for all)
+func (s *Syslog) Subscribe(units []string, priority string) (Source, error) {
+	sub := &subscription{listener: s, units: units, priority: NoPriority}
+	if priority != "" {
+		p, err := ParsePriority(priority)
+		if err != nil {
+			return nil, err
+		}
+		sub.priority = p
+	}
+	return sub, nil
+}
+
+// subscription is a Source fed by the listener
+type subscription struct {
+	listener *Syslog
+	units    []string
+	priority int
+
+	mu   sync.Mutex // Serializes emit across connections
+	emit func(Entry)
+}
+
+// Run implements Source
+func (sub *subscription) Run(ctx context.Context, emit func(Entry)) error {
+	sub.mu.Lock()
+	sub.emit = emit
+	sub.mu.Unlock()
+
+	sub.listener.subMu.Lock()
+	sub.listener.subscribers[sub] = struct{}{}
+	sub.listener.subMu.Unlock()
+
+	<-ctx.Done()
+
+	sub.listener.subMu.Lock()
+	delete(sub.listener.subscribers, sub)
+	sub.listener.subMu.Unlock()
+	return ctx.Err()
+}
+
+func (sub *subscription) deliver(entry Entry) {
+	if len(sub.units) > 0 && !slices.Contains(sub.units, entry.Unit) {
+		return
+	}
+	if sub.priority != NoPriority && entry.Priority > sub.priority {
+		return
+	}
+	sub.mu.Lock()
+	defer sub.mu.Unlock()
+	if sub.emit != nil {
+		sub.emit(entry)
+	}
+}
+
+// parseSyslog parses an RFC 5424 or RFC 3164 message. Fields the sender
+// left out are empty; a missing timestamp is now.
+func parseSyslog(data []byte, now time.Time) (Entry, error) {
+	data = bytes.TrimRight(data, "\r\n\x00")
+	if len(data) < 3 || data[0] != '<' {
+		return Entry{}, errors.New("missing priority")
+	}
+	end := bytes.IndexByte(data[:min(len(data), 5)], '>')
+	if end < 2 {
+		return Entry{}, errors.New("missing priority")
+	}
+	pri, err := strconv.Atoi(string(data[1:end]))
+	if err != nil || pri < 0 || pri >= len(Facilities)*len(Priorities) {
+		return Entry{}, fmt.Errorf("invalid priority %q", data[1:end])
+	}
+	entry := Entry{Source: SourceSyslog, Facility: Facilities[pri/8], Priority: pri % 8}
+
+	rest := string(data[end+1:])
+	if strings.HasPrefix(rest, "1 ") {
+		err = parseRFC5424(&entry, rest[2:], now)
+	} else {
+		parseRFC3164(&entry, rest, now)
+	}
+	if entry.Time.IsZero() {
+		entry.Time = now
+	}
+	return entry, err
+}
+
+// field splits the next space-separated field off s; "-" reads as empty
+func field(s string) (string, string) {
+	value, rest, _ := strings.Cut(s, " ")
+	if value == "-" {
+		value = ""
+	}
+	return value, rest
+}
+
+// parseRFC5424 parses "TIMESTAMP HOSTNAME APP-NAME PROCID MSGID SD [MSG]"
+func parseRFC5424(entry *Entry, s string, now time.Time) error {
+	timestamp, s := field(s)
+	if timestamp != "" {
+		t, err := time.Parse(time.RFC3339Nano, timestamp)
+		if err != nil {
+			return fmt.Errorf("invalid timestamp %q", timestamp)
+		}
+		entry.Time = t
+	}
+	entry.Host, s = field(s)
+	entry.Unit, s = field(s)
+	_, s = field(s) // PROCID
+	_, s = field(s) // MSGID
+
+	// Structured data: "-" or one or more [id param="value" ...] elements,
+	// where values may contain escaped quotes and brackets
+	if strings.HasPrefix(s, "-") {
+		s = s[1:]
+	} else {
+		i := 0
+		for i < len(s) && s[i] == '[' {
+			quoted := false
+			for i++; i < len(s); i++ {
+				if s[i] == '\\' {
+					i++
+				} else if s[i] == '"' {
+					quoted = !quoted
+				} else if s[i] == ']' && !quoted {
+					i++
+					break
+				}
+			}
+		}
+		s = s[min(i, len(s)):]
+	}
+	s = strings.TrimPrefix(s, " ")
+	entry.Message = strings.TrimPrefix(s, "\xef\xbb\xbf") // UTF-8 BOM
+	return nil
+}
+
+// parseRFC3164 parses "Mmm dd hh:mm:ss HOSTNAME TAG: MSG", where any part
+// but the message may be missing. The year and zone the timestamp lacks are
+// those of now.
+func parseRFC3164(entry *Entry, s string, now time.Time) {
+	const stamp = "Jan _2 15:04:05"
+	if len(s) >= len(stamp) {
+		if t, err := time.ParseInLocation(stamp, s[:len(stamp)], now.Location()); err == nil {
+			t = t.AddDate(now.Year(), 0, 0)
+			if t.After(now.Add(24 * time.Hour)) {
+				t = t.AddDate(-1, 0, 0) // Sent in December, received in January
+			}
+			entry.Time = t
+			s = strings.TrimPrefix(s[len(stamp):], " ")
+
+			// The hostname is the first word unless that is already the tag
+			if word, rest, ok := strings.Cut(s, " "); ok && !isTag(word) {
+				entry.Host, s = word, rest
+			}
+		}
+	}
+	if word, rest, ok := strings.Cut(s, " "); ok && isTag(word) {
+		tag := strings.TrimSuffix(word, ":")
+		if i := strings.IndexByte(tag, '['); i > 0 {
+			tag = tag[:i]
+		}
+		entry.Unit, s = tag, rest
+	}
+	entry.Message = s
+}
+
+// isTag reports whether word is a "program:" or "program[pid]:" tag
+func isTag(word string) bool {
+	if len(word) < 2 || len(word) > 48 || !strings.HasSuffix(word, ":") {
+		return false
+	}
+	name := strings.TrimSuffix(word, ":")
+	if i := strings.IndexByte(name, '['); i >= 0 {
+		if i == 0 || !strings.HasSuffix(name, "]") {
+			return false
+		}
+		name = name[:i]
+	}
+	return !strings.ContainsAny(name, "[]:")
+}
@@ -0,0 +1,176 @@
+package logwatch
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"argus/internal/clock"
+)
+
+func TestParseSyslog_RFC5424(t *testing.T) {
+	entry, err := parseSyslog([]byte(`<165>1 2026-01-01T00:00:00.5Z router1 bgpd 42 ID47 [exampleSDID@32473 iut="3" eventSource="App\"lication]"][meta x="1"] BOM-less peer down`+"\n"), epoch)
+	require.NoError(t, err)
+	assert.Equal(t, Entry{
+		Time:     epoch.Add(500 * time.Millisecond),
+		Source:   SourceSyslog,
+		Unit:     "bgpd",
+		Host:     "router1",
+		Facility: "local4",
+		Priority: 5,
+		Message:  "BOM-less peer down",
+	}, entry)
+
+	entry, err = parseSyslog([]byte("<11>1 - - - - - - \xef\xbb\xbfdisk failure"), epoch)
+	require.NoError(t, err)
+	assert.Equal(t, "disk failure", entry.Message, "the BOM is stripped")
+	assert.Equal(t, epoch, entry.Time, "a missing timestamp is the receive time")
+	assert.Equal(t, "user", entry.Facility)
+	assert.Equal(t, 3, entry.Priority)
+	assert.Empty(t, entry.Host)
+
+	_, err = parseSyslog([]byte("<11>1 yesterday host app - - - msg"), epoch)
+	assert.Error(t, err)
+}
+
+func TestParseSyslog_RFC3164(t *testing.T) {
+	now := time.Date(2026, 10, 14, 12, 0, 0, 0, time.UTC)
+	entry, err := parseSyslog([]byte("<34>Oct  1 22:14:15 mymachine su[230]: 'su root' failed for lonvick on /dev/pts/8"), now)
+	require.NoError(t, err)
+	assert.Equal(t, Entry{
+		Time:     time.Date(2026, 10, 1, 22, 14, 15, 0, time.UTC),
+		Source:   SourceSyslog,
+		Unit:     "su",
+		Host:     "mymachine",
+		Facility: "auth",
+		Priority: 2,
+		Message:  "'su root' failed for lonvick on /dev/pts/8",
+	}, entry)
+
+	entry, err = parseSyslog([]byte("<13>Oct 14 11:00:00 cron: job done"), now)
+	require.NoError(t, err)
+	assert.Empty(t, entry.Host, "the first word is the tag")
+	assert.Equal(t, "cron", entry.Unit)
+	assert.Equal(t, "job done", entry.Message)
+
+	entry, err = parseSyslog([]byte("<13>Dec 31 23:59:59 host app: late"), time.Date(2027, 1, 1, 0, 0, 5, 0, time.UTC))
+	require.NoError(t, err)
+	assert.Equal(t, 2026, entry.Time.Year(), "December messages received in January are from last year")
+
+	entry, err = parseSyslog([]byte("<190>link up on port 3"), now)
+	require.NoError(t, err)
+	assert.Equal(t, "link up on port 3", entry.Message, "devices that send only a message")
+	assert.Equal(t, now, entry.Time)
+	assert.Equal(t, "local7", entry.Facility)
+
+	for _, invalid := range []string{"", "no priority", "<>x", "<192>x", "<abc>x"} {
+		_, err := parseSyslog([]byte(invalid), now)
+		assert.Error(t, err, invalid)
+	}
+}
+
+func TestSyslog_BufferAndFilter(t *testing.T) {
+	config := DefaultSyslogConfig()
+	config.BufferSize = 3
+	config.Clock = clock.NewFake(epoch)
+	s := NewSyslog(config)
+
+	s.Ingest([]byte("<11>1 - web1 nginx - - - upstream timed out"), "10.0.0.1")
+	s.Ingest([]byte("<14>1 - web2 nginx - - - request served"), "10.0.0.2")
+	s.Ingest([]byte("<10>link flapping"), "10.0.0.3")
+	s.Ingest([]byte("garbage"), "10.0.0.3")
+	s.Ingest([]byte("<9>1 - db1 postgres - - - FATAL too many connections"), "10.0.0.4")
+
+	all := s.Messages(MessageFilter{Priority: NoPriority})
+	require.Len(t, all, 3, "the oldest message is dropped")
+	assert.Equal(t, "FATAL too many connections", all[0].Message, "newest first")
+	assert.Equal(t, "10.0.0.3", all[1].Host, "messages without a hostname carry the sender's address")
+
+	assert.Len(t, s.Messages(MessageFilter{Priority: 2}), 2, "crit and more severe")
+	assert.Len(t, s.Messages(MessageFilter{Unit: "nginx", Priority: NoPriority}), 1)
+	assert.Len(t, s.Messages(MessageFilter{Host: "db1", Priority: NoPriority}), 1)
+	assert.Len(t, s.Messages(MessageFilter{Contains: "fatal", Priority: NoPriority}), 1)
+	assert.Len(t, s.Messages(MessageFilter{Priority: NoPriority, Limit: 1}), 1)
+
+	stats := s.Stats()
+	assert.Equal(t, uint64(4), stats.Received)
+	assert.Equal(t, uint64(1), stats.Invalid)
+	assert.Equal(t, 3, stats.Buffered)
+}
+
+func TestSyslog_FeedsWatches(t *testing.T) {
+	listener := NewSyslog(DefaultSyslogConfig())
+	config := DefaultConfig()
+	config.Syslog = listener
+	w, err := NewWatcher([]Watch{{Name: "nginx-errors", Source: SourceSyslog, Units: []string{"nginx"}, Priority: "err", Pattern: "timed out"}}, config)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	w.Start(ctx)
+	require.Eventually(t, func() bool {
+		listener.subMu.RLock()
+		defer listener.subMu.RUnlock()
+		return len(listener.subscribers) == 1
+	}, 5*time.Second, time.Millisecond)
+
+	listener.Ingest([]byte("<11>1 - web1 nginx - - - upstream timed out"), "")
+	listener.Ingest([]byte("<14>1 - web1 nginx - - - upstream timed out (info)"), "")
+	listener.Ingest([]byte("<11>1 - web1 haproxy - - - backend timed out"), "")
+	count, err := w.Count("nginx-errors")
+	require.NoError(t, err)
+	assert.Equal(t, 1, count, "other apps and less severe messages are filtered out")
+
+	_, err = NewWatcher([]Watch{{Name: "x", Source: SourceSyslog, Pattern: "x"}}, DefaultConfig())
+	assert.ErrorContains(t, err, "requires the syslog listener")
+}
+
+func TestSyslog_Listeners(t *testing.T) {
+	config := DefaultSyslogConfig()
+	config.UDPAddress = "127.0.0.1:0"
+	config.TCPAddress = "127.0.0.1:0"
+	s := NewSyslog(config)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	require.NoError(t, s.Start(ctx))
+	stats := s.Stats()
+
+	udp, err := net.Dial("udp", stats.UDPAddress)
+	require.NoError(t, err)
+	_, err = udp.Write([]byte("<11>1 - host1 app - - - over udp"))
+	require.NoError(t, err)
+	udp.Close()
+
+	tcp, err := net.Dial("tcp", stats.TCPAddress)
+	require.NoError(t, err)
+	framed := "<11>1 - host1 app - - - octet\ncounted"
+	long := "<11>1 - host1 app - - - " + strings.Repeat("x", 9000)
+	_, err = fmt.Fprintf(tcp, "%d %s%d %s<11>line framed\n", len(framed), framed, len(long), long)
+	require.NoError(t, err)
+	tcp.Close()
+
+	require.Eventually(t, func() bool { return s.Stats().Received == 4 }, 5*time.Second, 10*time.Millisecond)
+	messages := s.Messages(MessageFilter{Priority: NoPriority})
+	var texts []string
+	for _, m := range messages {
+		texts = append(texts, m.Message)
+	}
+	assert.Contains(t, texts, "over udp")
+	assert.Contains(t, texts, "octet\ncounted", "octet counting keeps newlines")
+	assert.Contains(t, texts, "line framed")
+	for _, m := range messages {
+		assert.LessOrEqual(t, len(m.Message), config.MaxMessageSize, "long messages are truncated")
+	}
+
+	cancel()
+	require.Eventually(t, func() bool {
+		_, err := net.Dial("tcp", stats.TCPAddress)
+		return err != nil
+	}, 5*time.Second, 10*time.Millisecond, "listeners close with the context")
+}
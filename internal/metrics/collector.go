@@ -1,6 +1,6 @@
 // File: internal/metrics/collector.go
 // Brief: Centralized metrics collection system with caching for Argus
-// Detailed: Implements a background metrics collector that caches CPU, memory, network, and process metrics to reduce HTTP response latency and system load.
+// Detailed: Implements a background metrics collector that caches CPU, memory, network, disk, process, RAID, power, and system service metrics to reduce HTTP response latency and system load.
 // Author: drama.lin@aver.com
 // Date: 2024-07-04
 
@@ -10,31 +10,56 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"slices"
 	"sort"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/shirou/gopsutil/v3/cpu"
+	"github.com/shirou/gopsutil/v3/disk"
 	"github.com/shirou/gopsutil/v3/load"
 	"github.com/shirou/gopsutil/v3/mem"
 	"github.com/shirou/gopsutil/v3/net"
 	"github.com/shirou/gopsutil/v3/process"
+
+	"argus/internal/clock"
+	"argus/internal/faults"
+	"argus/internal/utils"
 )
 
 // CollectorConfig holds configuration for the metrics collector
 type CollectorConfig struct {
 	UpdateInterval time.Duration // How often to update metrics
 	CacheTTL       time.Duration // How long cached metrics are valid
-	ProcessLimit   int           // Maximum number of processes to collect
+	ProcessLimit   int           // Processes kept by CPU and by memory (0 keeps all)
+	// The limit adapts to collection latency: it is halved, down to
+	// MinProcessLimit, when a collection exceeds ProcessBudget
+	MinProcessLimit int
+	ProcessBudget   time.Duration  // 0 disables adaptation
+	ProcessDetails  bool           // Read the file descriptors, threads, IO and user of kept processes
+	WarmupRounds    int            // Collection rounds reported as initializing (process CPU needs two)
+	UPSServers      []string       // NUT upsd servers (host or host:port) queried for UPS status
+	Services        []ServiceCheck // Critical services watched (nil watches DefaultServiceChecks)
+	Disabled        []string       // Metric kinds not collected
+	Clock           clock.Clock    // Time source (nil uses the real clock)
+	// How often a repeating collection error is logged again (0 uses
+	// utils.DefaultLogRepeatInterval)
+	LogRepeatInterval time.Duration
+	CallTimeout       time.Duration // Bound of each gopsutil call (0 uses DefaultCallTimeout)
 }
 
 // DefaultConfig returns default configuration for the metrics collector
 func DefaultConfig() CollectorConfig {
 	return CollectorConfig{
-		UpdateInterval: 5 * time.Second,
-		CacheTTL:       10 * time.Second,
-		ProcessLimit:   100,
+		UpdateInterval:  5 * time.Second,
+		CacheTTL:        10 * time.Second,
+		ProcessLimit:    100,
+		MinProcessLimit: 20,
+		ProcessBudget:   time.Second,
+		WarmupRounds:    2,
+		CallTimeout:     DefaultCallTimeout,
 	}
 }
 
@@ -45,6 +70,10 @@ type CPUMetrics struct {
 	Load15       float64   `json:"load15"`
 	UsagePercent float64   `json:"usage_percent"`
 	UpdatedAt    time.Time `json:"updated_at"`
+
+	// Share of CPU time spent waiting on IO since the previous round; 0 on
+	// platforms that do not report it
+	IOWaitPercent float64 `json:"iowait_percent"`
 }
 
 // MemoryMetrics holds memory-related metrics
@@ -56,13 +85,50 @@ type MemoryMetrics struct {
 	UpdatedAt   time.Time `json:"updated_at"`
 }
 
-// NetworkMetrics holds network-related metrics
+// InterfaceIO holds the counters of a single network interface
+type InterfaceIO struct {
+	Name        string `json:"name"`
+	BytesSent   uint64 `json:"bytes_sent"`
+	BytesRecv   uint64 `json:"bytes_recv"`
+	PacketsSent uint64 `json:"packets_sent"`
+	PacketsRecv uint64 `json:"packets_recv"`
+}
+
+// NetworkMetrics holds network-related metrics: the counters summed over
+// every interface, and per interface
 type NetworkMetrics struct {
-	BytesSent   uint64    `json:"bytes_sent"`
-	BytesRecv   uint64    `json:"bytes_recv"`
-	PacketsSent uint64    `json:"packets_sent"`
-	PacketsRecv uint64    `json:"packets_recv"`
-	UpdatedAt   time.Time `json:"updated_at"`
+	BytesSent   uint64        `json:"bytes_sent"`
+	BytesRecv   uint64        `json:"bytes_recv"`
+	PacketsSent uint64        `json:"packets_sent"`
+	PacketsRecv uint64        `json:"packets_recv"`
+	Interfaces  []InterfaceIO `json:"interfaces"`
+	UpdatedAt   time.Time     `json:"updated_at"`
+}
+
+// DiskUsage holds usage information for a single mounted partition
+type DiskUsage struct {
+	Device      string  `json:"device"`
+	Mountpoint  string  `json:"mountpoint"`
+	Fstype      string  `json:"fstype"`
+	Total       uint64  `json:"total"`
+	Used        uint64  `json:"used"`
+	Free        uint64  `json:"free"`
+	UsedPercent float64 `json:"used_percent"`
+
+	// Inode usage; InodesTotal is 0 on filesystems without a fixed inode table
+	InodesTotal       uint64  `json:"inodes_total"`
+	InodesFree        uint64  `json:"inodes_free"`
+	InodesUsedPercent float64 `json:"inodes_used_percent"`
+}
+
+// DiskMetrics holds disk-related metrics
+type DiskMetrics struct {
+	Total       uint64      `json:"total"`
+	Used        uint64      `json:"used"`
+	Free        uint64      `json:"free"`
+	UsedPercent float64     `json:"used_percent"`
+	Partitions  []DiskUsage `json:"partitions"`
+	UpdatedAt   time.Time   `json:"updated_at"`
 }
 
 // ProcessInfo holds information about a single process
@@ -71,12 +137,16 @@ type ProcessInfo struct {
 	Name       string  `json:"name"`
 	CPUPercent float64 `json:"cpu_percent"`
 	MemPercent float32 `json:"mem_percent"`
+
+	Limits  *ProcessLimits  `json:"limits,omitempty"`  // Resource limits and their usage
+	Details *ProcessDetails `json:"details,omitempty"` // Set with CollectorConfig.ProcessDetails
 }
 
 // ProcessMetrics holds process-related metrics
 type ProcessMetrics struct {
-	Processes []ProcessInfo `json:"processes"`
-	UpdatedAt time.Time     `json:"updated_at"`
+	Processes    []ProcessInfo `json:"processes"`
+	UpdatedAt    time.Time     `json:"updated_at"`
+	Initializing bool          `json:"initializing,omitempty"` // CPU percentages are lifetime averages, not yet rates
 }
 
 // ProcessFilter defines filtering and pagination options for process metrics
@@ -94,10 +164,12 @@ type ProcessFilter struct {
 // Collector manages centralized metrics collection with caching
 type Collector struct {
 	config CollectorConfig
+	clock  clock.Clock
 
 	// Cached metrics with RWMutex for concurrent access
 	cpuMutex   sync.RWMutex
 	cpuMetrics *CPUMetrics
+	cpuTimes   *cpu.TimesStat // Totals of the previous round, for the IO wait share
 
 	memoryMutex   sync.RWMutex
 	memoryMetrics *MemoryMetrics
@@ -105,9 +177,57 @@ type Collector struct {
 	networkMutex   sync.RWMutex
 	networkMetrics *NetworkMetrics
 
+	diskMutex   sync.RWMutex
+	diskMetrics *DiskMetrics
+
 	processMutex   sync.RWMutex
 	processMetrics *ProcessMetrics
 
+	// Names of every running process, for process counts
+	names processNames
+
+	raidMutex   sync.RWMutex
+	raidMetrics *RAIDMetrics
+
+	powerMutex   sync.RWMutex
+	powerMetrics *PowerMetrics
+
+	// When each battery and UPS switched to battery power, for how long
+	// they have been on it
+	onBatterySince map[string]time.Time
+
+	serviceMutex   sync.RWMutex
+	serviceMetrics *ServiceMetrics
+
+	// Optional store receiving every collection round
+	history *SeriesStore
+
+	// Collection results per metric kind
+	healthMutex sync.Mutex
+	health      map[string]*MetricHealth
+
+	// Throttles errors that repeat every round while a source stays broken
+	errorLog *utils.LogSampler
+
+	// System calls that timed out and are still blocked
+	watchdog watchdog
+
+	// Settings adjustable at runtime, and a signal to the collection loop
+	// that the update interval changed
+	tuned           atomic.Pointer[Tuning]
+	intervalChanged chan struct{}
+
+	// Adaptive process limit and the cost of the last process collection
+	processLimit      atomic.Int64
+	processCandidates atomic.Int64
+	processDuration   atomic.Int64
+
+	// Completed collection rounds, for warm-up
+	rounds atomic.Int64
+
+	// CPU time of every process at the previous round, for CPU rates
+	cpuSamples map[int32]cpuSample
+
 	// Object pools for reducing allocations
 	processInfoPool sync.Pool
 	stringSlicePool sync.Pool
@@ -119,10 +239,15 @@ type Collector struct {
 
 // NewCollector creates a new metrics collector instance
 func NewCollector(config CollectorConfig) *Collector {
-	return &Collector{
-		config:   config,
-		stopChan: make(chan struct{}),
-		doneChan: make(chan struct{}),
+	c := &Collector{
+		config:          config,
+		clock:           clock.OrReal(config.Clock),
+		health:          make(map[string]*MetricHealth, len(Kinds)),
+		errorLog:        utils.NewLogSampler(config.LogRepeatInterval, config.Clock),
+		watchdog:        watchdog{hung: make(map[string]*HungCall)},
+		intervalChanged: make(chan struct{}, 1),
+		stopChan:        make(chan struct{}),
+		doneChan:        make(chan struct{}),
 		processInfoPool: sync.Pool{
 			New: func() interface{} {
 				return make([]ProcessInfo, 0, config.ProcessLimit)
@@ -134,11 +259,19 @@ func NewCollector(config CollectorConfig) *Collector {
 			},
 		},
 	}
+	c.processLimit.Store(int64(config.ProcessLimit))
+	c.tuned.Store(&Tuning{
+		UpdateInterval: config.UpdateInterval,
+		CacheTTL:       config.CacheTTL,
+		ProcessLimit:   config.ProcessLimit,
+		Disabled:       slices.Clone(config.Disabled),
+	})
+	return c
 }
 
 // Start begins the background metrics collection
 func (c *Collector) Start(ctx context.Context) error {
-	slog.Info("Starting metrics collector", "update_interval", c.config.UpdateInterval)
+	slog.Info("Starting metrics collector", "update_interval", c.tuning().UpdateInterval, "disabled", c.tuning().Disabled)
 
 	// Collect initial metrics
 	c.collectAllMetrics(ctx)
@@ -160,8 +293,8 @@ func (c *Collector) Stop() {
 func (c *Collector) collectLoop(ctx context.Context) {
 	defer close(c.doneChan)
 
-	ticker := time.NewTicker(c.config.UpdateInterval)
-	defer ticker.Stop()
+	ticker := c.clock.NewTicker(c.tuning().UpdateInterval)
+	defer func() { ticker.Stop() }()
 
 	for {
 		select {
@@ -171,53 +304,67 @@ func (c *Collector) collectLoop(ctx context.Context) {
 		case <-c.stopChan:
 			slog.Info("Metrics collector stopped")
 			return
-		case <-ticker.C:
+		case <-ticker.C():
 			c.collectAllMetrics(ctx)
+		case <-c.intervalChanged:
+			ticker.Stop()
+			ticker = c.clock.NewTicker(c.tuning().UpdateInterval)
 		}
 	}
 }
 
 // collectAllMetrics collects all types of metrics
 func (c *Collector) collectAllMetrics(ctx context.Context) {
-	// Use separate goroutines for parallel collection
-	var wg sync.WaitGroup
-
-	wg.Add(4)
-	go func() {
-		defer wg.Done()
-		c.collectCPUMetrics(ctx)
-	}()
-
-	go func() {
-		defer wg.Done()
-		c.collectMemoryMetrics(ctx)
-	}()
-
-	go func() {
-		defer wg.Done()
-		c.collectNetworkMetrics(ctx)
-	}()
+	tuning := c.tuning()
+	if err := faults.Inject(faults.CollectMetrics); err != nil {
+		for _, kind := range Kinds {
+			if tuning.Enabled(kind) {
+				c.recordResult(kind, err)
+			}
+		}
+		return
+	}
 
-	go func() {
-		defer wg.Done()
-		c.collectProcessMetrics(ctx)
-	}()
+	collectors := map[string]func(context.Context) error{
+		KindCPU:      c.collectCPUMetrics,
+		KindMemory:   c.collectMemoryMetrics,
+		KindNetwork:  c.collectNetworkMetrics,
+		KindDisk:     c.collectDiskMetrics,
+		KindProcess:  c.collectProcessMetrics,
+		KindRAID:     c.collectRAIDMetrics,
+		KindPower:    c.collectPowerMetrics,
+		KindServices: c.collectServiceMetrics,
+	}
 
+	// Use separate goroutines for parallel collection
+	var wg sync.WaitGroup
+	for _, kind := range Kinds {
+		if !tuning.Enabled(kind) {
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			c.recordResult(kind, collectors[kind](ctx))
+		}()
+	}
 	wg.Wait()
+	c.recordHistory()
+	c.rounds.Add(1)
 }
 
 // collectCPUMetrics collects CPU metrics
-func (c *Collector) collectCPUMetrics(ctx context.Context) {
-	loadAvg, err := load.AvgWithContext(ctx)
+func (c *Collector) collectCPUMetrics(ctx context.Context) error {
+	loadAvg, err := guard(c, ctx, "load.Avg", load.AvgWithContext)
 	if err != nil {
-		slog.Error("Failed to get load average", "error", err)
-		return
+		return fmt.Errorf("failed to get load average: %w", err)
 	}
 
-	cpuPercent, err := cpu.PercentWithContext(ctx, time.Second, false)
+	cpuPercent, err := guard(c, ctx, "cpu.Percent", func(ctx context.Context) ([]float64, error) {
+		return cpu.PercentWithContext(ctx, time.Second, false)
+	})
 	if err != nil {
-		slog.Error("Failed to get CPU percent", "error", err)
-		return
+		return fmt.Errorf("failed to get CPU percent: %w", err)
 	}
 
 	var usage float64
@@ -230,22 +377,49 @@ func (c *Collector) collectCPUMetrics(ctx context.Context) {
 		Load5:        loadAvg.Load5,
 		Load15:       loadAvg.Load15,
 		UsagePercent: usage,
-		UpdatedAt:    time.Now(),
+		UpdatedAt:    c.clock.Now(),
+	}
+
+	times, err := guard(c, ctx, "cpu.Times", func(ctx context.Context) ([]cpu.TimesStat, error) {
+		return cpu.TimesWithContext(ctx, false)
+	})
+	if err != nil || len(times) == 0 {
+		slog.Debug("Failed to get CPU times", "error", err)
+		times = nil
 	}
 
 	c.cpuMutex.Lock()
+	if len(times) > 0 {
+		if c.cpuTimes != nil {
+			metrics.IOWaitPercent = ioWaitPercent(*c.cpuTimes, times[0])
+		}
+		c.cpuTimes = &times[0]
+	}
 	c.cpuMetrics = metrics
 	c.cpuMutex.Unlock()
 
-	slog.Debug("CPU metrics updated", "usage_percent", usage, "load1", loadAvg.Load1)
+	slog.Debug("CPU metrics updated", "usage_percent", usage, "iowait_percent", metrics.IOWaitPercent, "load1", loadAvg.Load1)
+	return nil
+}
+
+// ioWaitPercent returns the share of CPU time spent waiting on IO between two
+// readings of the CPU totals
+func ioWaitPercent(prev, cur cpu.TimesStat) float64 {
+	total := func(t cpu.TimesStat) float64 {
+		return t.User + t.System + t.Idle + t.Nice + t.Iowait + t.Irq + t.Softirq + t.Steal
+	}
+	elapsed := total(cur) - total(prev)
+	if elapsed <= 0 || cur.Iowait < prev.Iowait {
+		return 0
+	}
+	return (cur.Iowait - prev.Iowait) / elapsed * 100
 }
 
 // collectMemoryMetrics collects memory metrics
-func (c *Collector) collectMemoryMetrics(ctx context.Context) {
-	vm, err := mem.VirtualMemoryWithContext(ctx)
+func (c *Collector) collectMemoryMetrics(ctx context.Context) error {
+	vm, err := guard(c, ctx, "mem.VirtualMemory", mem.VirtualMemoryWithContext)
 	if err != nil {
-		slog.Error("Failed to get memory info", "error", err)
-		return
+		return fmt.Errorf("failed to get memory info: %w", err)
 	}
 
 	metrics := &MemoryMetrics{
@@ -253,7 +427,7 @@ func (c *Collector) collectMemoryMetrics(ctx context.Context) {
 		Used:        vm.Used,
 		Free:        vm.Free,
 		UsedPercent: vm.UsedPercent,
-		UpdatedAt:   time.Now(),
+		UpdatedAt:   c.clock.Now(),
 	}
 
 	c.memoryMutex.Lock()
@@ -261,144 +435,323 @@ func (c *Collector) collectMemoryMetrics(ctx context.Context) {
 	c.memoryMutex.Unlock()
 
 	slog.Debug("Memory metrics updated", "used_percent", vm.UsedPercent, "total", vm.Total)
+	return nil
 }
 
 // collectNetworkMetrics collects network metrics
-func (c *Collector) collectNetworkMetrics(ctx context.Context) {
-	ioCounters, err := net.IOCountersWithContext(ctx, false)
+func (c *Collector) collectNetworkMetrics(ctx context.Context) error {
+	// Per interface counters; the totals are their sums
+	ioCounters, err := guard(c, ctx, "net.IOCounters", func(ctx context.Context) ([]net.IOCountersStat, error) {
+		return net.IOCountersWithContext(ctx, true)
+	})
 	if err != nil {
-		slog.Error("Failed to get network stats", "error", err)
-		return
+		return fmt.Errorf("failed to get network stats: %w", err)
 	}
 
 	if len(ioCounters) == 0 {
-		slog.Warn("No network interfaces found")
-		return
+		return fmt.Errorf("no network interfaces found")
 	}
 
-	io := ioCounters[0]
 	metrics := &NetworkMetrics{
-		BytesSent:   io.BytesSent,
-		BytesRecv:   io.BytesRecv,
-		PacketsSent: io.PacketsSent,
-		PacketsRecv: io.PacketsRecv,
-		UpdatedAt:   time.Now(),
+		Interfaces: make([]InterfaceIO, 0, len(ioCounters)),
+		UpdatedAt:  c.clock.Now(),
+	}
+	for _, io := range ioCounters {
+		metrics.Interfaces = append(metrics.Interfaces, InterfaceIO{
+			Name:        io.Name,
+			BytesSent:   io.BytesSent,
+			BytesRecv:   io.BytesRecv,
+			PacketsSent: io.PacketsSent,
+			PacketsRecv: io.PacketsRecv,
+		})
+		metrics.BytesSent += io.BytesSent
+		metrics.BytesRecv += io.BytesRecv
+		metrics.PacketsSent += io.PacketsSent
+		metrics.PacketsRecv += io.PacketsRecv
 	}
 
 	c.networkMutex.Lock()
 	c.networkMetrics = metrics
 	c.networkMutex.Unlock()
 
-	slog.Debug("Network metrics updated", "bytes_sent", io.BytesSent, "bytes_recv", io.BytesRecv)
+	slog.Debug("Network metrics updated", "interfaces", len(metrics.Interfaces), "bytes_sent", metrics.BytesSent, "bytes_recv", metrics.BytesRecv)
+	return nil
+}
+
+// collectDiskMetrics collects usage metrics for all physical partitions
+func (c *Collector) collectDiskMetrics(ctx context.Context) error {
+	partitions, err := guard(c, ctx, "disk.Partitions", func(ctx context.Context) ([]disk.PartitionStat, error) {
+		return disk.PartitionsWithContext(ctx, false)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to get disk partitions: %w", err)
+	}
+
+	metrics := &DiskMetrics{
+		Partitions: make([]DiskUsage, 0, len(partitions)),
+	}
+
+	// Skip duplicate mountpoints (bind mounts report the same filesystem twice)
+	seen := make(map[string]bool, len(partitions))
+	for _, part := range partitions {
+		if seen[part.Mountpoint] {
+			continue
+		}
+		seen[part.Mountpoint] = true
+
+		// Usage stats the mountpoint, which blocks on an unresponsive network filesystem
+		usage, err := guard(c, ctx, "disk.Usage "+part.Mountpoint, func(ctx context.Context) (*disk.UsageStat, error) {
+			return disk.UsageWithContext(ctx, part.Mountpoint)
+		})
+		if err != nil {
+			slog.Debug("Failed to get disk usage", "mountpoint", part.Mountpoint, "error", err)
+			continue
+		}
+
+		metrics.Partitions = append(metrics.Partitions, DiskUsage{
+			Device:      part.Device,
+			Mountpoint:  part.Mountpoint,
+			Fstype:      part.Fstype,
+			Total:       usage.Total,
+			Used:        usage.Used,
+			Free:        usage.Free,
+			UsedPercent: usage.UsedPercent,
+
+			InodesTotal:       usage.InodesTotal,
+			InodesFree:        usage.InodesFree,
+			InodesUsedPercent: usage.InodesUsedPercent,
+		})
+		metrics.Total += usage.Total
+		metrics.Used += usage.Used
+		metrics.Free += usage.Free
+	}
+
+	if metrics.Total > 0 {
+		metrics.UsedPercent = float64(metrics.Used) / float64(metrics.Total) * 100
+	}
+	metrics.UpdatedAt = c.clock.Now()
+
+	c.diskMutex.Lock()
+	c.diskMetrics = metrics
+	c.diskMutex.Unlock()
+
+	slog.Debug("Disk metrics updated", "partitions", len(metrics.Partitions), "used_percent", metrics.UsedPercent)
+	return nil
+}
+
+// processStats is the usage of one process read in a collection round
+type processStats struct {
+	info   ProcessInfo
+	sample *cpuSample // Nil when its CPU times could not be read
 }
 
 // collectProcessMetrics collects process metrics
-func (c *Collector) collectProcessMetrics(ctx context.Context) {
+func (c *Collector) collectProcessMetrics(ctx context.Context) error {
+	started := c.clock.Now()
+
 	// Add timeout to prevent hanging
 	processCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
 	defer cancel()
 
-	procs, err := process.ProcessesWithContext(processCtx)
+	procs, err := guard(c, processCtx, "process.Processes", process.ProcessesWithContext)
 	if err != nil {
-		slog.Error("Failed to get process list", "error", err)
-		return
+		return fmt.Errorf("failed to get process list: %w", err)
+	}
+
+	// Memory percentages are computed from RSS against one reading of total
+	// memory instead of re-reading it for every process
+	var totalMemory uint64
+	if vm, err := guard(c, processCtx, "mem.VirtualMemory", mem.VirtualMemoryWithContext); err == nil {
+		totalMemory = vm.Total
 	}
 
 	// Get process info slice from pool
-	processes := c.processInfoPool.Get().([]ProcessInfo)
-	processes = processes[:0] // Reset slice but keep capacity
+	candidates := c.processInfoPool.Get().([]ProcessInfo)
+	candidates = candidates[:0] // Reset slice but keep capacity
+	handles := make(map[int32]*process.Process, len(procs))
+	samples := make(map[int32]cpuSample, len(procs))
+	initializing := c.cpuSamples == nil
 
-	processedCount := 0
 	errorCount := 0
 
-	// Collect process information with error handling
+	// Gather lightweight stats for every process, so the top consumers are
+	// found regardless of enumeration order
 	for _, p := range procs {
-		// Check context cancellation
-		select {
-		case <-processCtx.Done():
-			slog.Warn("Process metrics collection cancelled due to timeout")
+		if processCtx.Err() != nil {
+			c.errorLog.Log(slog.LevelWarn, "process-timeout", "Process metrics collection cancelled due to timeout")
 			break
-		default:
 		}
 
 		if p == nil || p.Pid <= 0 {
 			continue
 		}
 
-		// Limit number of processes to prevent excessive memory usage
-		if len(processes) >= c.config.ProcessLimit {
-			break
-		}
-
-		// Process individual process with error recovery
-		func() {
-			defer func() {
-				if r := recover(); r != nil {
-					slog.Debug("Panic occurred processing process", "pid", p.Pid, "panic", r)
-					errorCount++
+		// Stats of one process, with panics and hung /proc reads recovered
+		prev, hasPrev := c.cpuSamples[p.Pid]
+		stats, err := guard(c, processCtx, fmt.Sprintf("process.Stats %d", p.Pid), func(ctx context.Context) (processStats, error) {
+			stats := processStats{info: ProcessInfo{PID: p.Pid}}
+
+			// CPU percentage over the time since the previous round; new
+			// processes fall back to their lifetime average
+			if times, err := p.TimesWithContext(ctx); err == nil {
+				stats.sample = &cpuSample{seconds: times.User + times.System, at: c.clock.Now()}
+				if rate, ok := cpuRate(prev, hasPrev, *stats.sample); ok {
+					stats.info.CPUPercent = rate
+				} else if cpu, err := p.CPUPercentWithContext(ctx); err == nil {
+					stats.info.CPUPercent = cpu
 				}
-			}()
-
-			processedCount++
-
-			name, err := p.NameWithContext(processCtx)
-			if err != nil {
-				slog.Debug("Failed to get process name", "pid", p.Pid, "error", err)
-				return
 			}
 
-			// Skip kernel threads and system processes
-			if name == "" || (len(name) > 0 && name[0] == '[') {
-				return
+			// Get memory percentage with error handling
+			if totalMemory > 0 {
+				if mi, err := p.MemoryInfoWithContext(ctx); err == nil {
+					stats.info.MemPercent = float32(float64(mi.RSS) / float64(totalMemory) * 100)
+				}
 			}
+			return stats, nil
+		})
+		if err != nil {
+			slog.Debug("Failed to get process stats", "pid", p.Pid, "error", err)
+			errorCount++
+			continue
+		}
+		if stats.sample != nil {
+			samples[p.Pid] = *stats.sample
+		}
+		candidates = append(candidates, stats.info)
+		handles[p.Pid] = p
+	}
 
-			var cpuP float64 = 0.0
-			var memP float32 = 0.0
+	limit := int(c.processLimit.Load())
+	selected := c.selectTopConsumers(candidates, limit)
 
-			// Get CPU percentage with error handling
-			if cpu, err := p.CPUPercentWithContext(processCtx); err == nil {
-				cpuP = cpu
-			}
+	// Names are only looked up for the processes that are kept
+	processSlice := make([]ProcessInfo, 0, len(selected))
+	for _, info := range selected {
+		p := handles[info.PID]
+		name, err := guard(c, processCtx, fmt.Sprintf("process.Name %d", info.PID), p.NameWithContext)
+		if err != nil {
+			slog.Debug("Failed to get process name", "pid", info.PID, "error", err)
+			continue
+		}
 
-			// Get memory percentage with error handling
-			if mem, err := p.MemoryPercentWithContext(processCtx); err == nil {
-				memP = mem
-			}
+		// Skip kernel threads and system processes
+		if name == "" || name[0] == '[' {
+			continue
+		}
 
-			processes = append(processes, ProcessInfo{
-				PID:        p.Pid,
-				Name:       name,
-				CPUPercent: cpuP,
-				MemPercent: memP,
+		info.Name = name
+		info.Limits, _ = guard(c, processCtx, fmt.Sprintf("process.Limits %d", info.PID), func(ctx context.Context) (*ProcessLimits, error) {
+			return processLimits(ctx, p), nil
+		})
+		if c.config.ProcessDetails {
+			info.Details, _ = guard(c, processCtx, fmt.Sprintf("process.Details %d", info.PID), func(ctx context.Context) (*ProcessDetails, error) {
+				return processDetails(ctx, p, info.Limits), nil
 			})
-		}()
+		}
+		processSlice = append(processSlice, info)
 	}
 
 	// Sort by CPU percentage in descending order
-	sort.Slice(processes, func(i, j int) bool {
-		return processes[i].CPUPercent > processes[j].CPUPercent
+	sort.Slice(processSlice, func(i, j int) bool {
+		return processSlice[i].CPUPercent > processSlice[j].CPUPercent
 	})
 
-	// Create metrics with copied slice to avoid pool interference
-	processSlice := make([]ProcessInfo, len(processes))
-	copy(processSlice, processes)
-
 	metrics := &ProcessMetrics{
-		Processes: processSlice,
-		UpdatedAt: time.Now(),
+		Processes:    processSlice,
+		UpdatedAt:    c.clock.Now(),
+		Initializing: initializing,
 	}
 
 	c.processMutex.Lock()
 	c.processMetrics = metrics
 	c.processMutex.Unlock()
+	c.cpuSamples = samples
 
 	// Return slice to pool
-	c.processInfoPool.Put(processes)
+	c.processInfoPool.Put(candidates)
+
+	elapsed := c.clock.Now().Sub(started)
+	c.adaptProcessLimit(elapsed, len(candidates))
 
 	slog.Debug("Process metrics updated",
-		"total_processed", processedCount,
+		"total_processed", len(candidates),
 		"successful", len(processSlice),
-		"errors", errorCount)
+		"errors", errorCount,
+		"limit", limit,
+		"duration", elapsed)
+	return nil
+}
+
+// selectTopConsumers keeps the top limit processes by CPU and the top limit
+// by memory, so a memory hog at 0% CPU is not dropped. limit <= 0 keeps all.
+func (c *Collector) selectTopConsumers(processes []ProcessInfo, limit int) []ProcessInfo {
+	if limit <= 0 || len(processes) <= limit {
+		result := make([]ProcessInfo, len(processes))
+		copy(result, processes)
+		return result
+	}
+
+	byCPU := c.selectTopNProcesses(append([]ProcessInfo(nil), processes...), limit, "cpu", "desc")
+	byMemory := c.selectTopNProcesses(append([]ProcessInfo(nil), processes...), limit, "memory", "desc")
+
+	seen := make(map[int32]bool, len(byCPU))
+	result := make([]ProcessInfo, 0, len(byCPU)+len(byMemory))
+	for _, p := range append(byCPU, byMemory...) {
+		if !seen[p.PID] {
+			seen[p.PID] = true
+			result = append(result, p)
+		}
+	}
+	return result
+}
+
+// adaptProcessLimit halves the process limit when a collection took longer
+// than the budget and grows it back by a quarter, up to ProcessLimit, once
+// collections finish within half of it
+func (c *Collector) adaptProcessLimit(elapsed time.Duration, candidates int) {
+	c.processCandidates.Store(int64(candidates))
+	c.processDuration.Store(int64(elapsed))
+
+	budget := c.config.ProcessBudget
+	maxLimit := c.tuning().ProcessLimit
+	if budget <= 0 || maxLimit <= 0 {
+		return
+	}
+
+	current := c.processLimit.Load()
+	next := current
+	switch {
+	case elapsed > budget:
+		next = max(current/2, int64(c.config.MinProcessLimit), 1)
+	case elapsed < budget/2:
+		next = min(current+max(current/4, 1), int64(maxLimit))
+	}
+	if next != current {
+		c.processLimit.Store(next)
+		slog.Info("Adjusted process collection limit", "from", current, "to", next, "duration", elapsed, "budget", budget)
+	}
+}
+
+// ProcessCollectionStats reports the adaptive process limit for self-metrics
+type ProcessCollectionStats struct {
+	Limit      int     `json:"limit"`       // Processes currently kept by CPU and by memory
+	MaxLimit   int     `json:"max_limit"`   // Configured ProcessLimit
+	Candidates int     `json:"candidates"`  // Processes enumerated in the last collection
+	DurationMs float64 `json:"duration_ms"` // Duration of the last collection
+	BudgetMs   float64 `json:"budget_ms"`
+}
+
+// ProcessCollectionStats returns the current process limit and the cost of
+// the last process collection
+func (c *Collector) ProcessCollectionStats() ProcessCollectionStats {
+	return ProcessCollectionStats{
+		Limit:      int(c.processLimit.Load()),
+		MaxLimit:   c.tuning().ProcessLimit,
+		Candidates: int(c.processCandidates.Load()),
+		DurationMs: float64(c.processDuration.Load()) / float64(time.Millisecond),
+		BudgetMs:   float64(c.config.ProcessBudget) / float64(time.Millisecond),
+	}
 }
 
 // GetCPUMetrics returns cached CPU metrics
@@ -411,7 +764,7 @@ func (c *Collector) GetCPUMetrics() *CPUMetrics {
 	}
 
 	// Check if cache is still valid
-	if time.Since(c.cpuMetrics.UpdatedAt) > c.config.CacheTTL {
+	if c.clock.Now().Sub(c.cpuMetrics.UpdatedAt) > c.tuning().CacheTTL {
 		slog.Debug("CPU metrics cache expired")
 		return nil
 	}
@@ -430,7 +783,7 @@ func (c *Collector) GetMemoryMetrics() *MemoryMetrics {
 		return nil
 	}
 
-	if time.Since(c.memoryMetrics.UpdatedAt) > c.config.CacheTTL {
+	if c.clock.Now().Sub(c.memoryMetrics.UpdatedAt) > c.tuning().CacheTTL {
 		slog.Debug("Memory metrics cache expired")
 		return nil
 	}
@@ -448,7 +801,7 @@ func (c *Collector) GetNetworkMetrics() *NetworkMetrics {
 		return nil
 	}
 
-	if time.Since(c.networkMetrics.UpdatedAt) > c.config.CacheTTL {
+	if c.clock.Now().Sub(c.networkMetrics.UpdatedAt) > c.tuning().CacheTTL {
 		slog.Debug("Network metrics cache expired")
 		return nil
 	}
@@ -457,6 +810,27 @@ func (c *Collector) GetNetworkMetrics() *NetworkMetrics {
 	return &metrics
 }
 
+// GetDiskMetrics returns cached disk metrics
+func (c *Collector) GetDiskMetrics() *DiskMetrics {
+	c.diskMutex.RLock()
+	defer c.diskMutex.RUnlock()
+
+	if c.diskMetrics == nil {
+		return nil
+	}
+
+	if c.clock.Now().Sub(c.diskMetrics.UpdatedAt) > c.tuning().CacheTTL {
+		slog.Debug("Disk metrics cache expired")
+		return nil
+	}
+
+	// Return a copy with copied slice to prevent race conditions
+	metrics := *c.diskMetrics
+	metrics.Partitions = make([]DiskUsage, len(c.diskMetrics.Partitions))
+	copy(metrics.Partitions, c.diskMetrics.Partitions)
+	return &metrics
+}
+
 // GetProcessMetrics returns cached process metrics
 func (c *Collector) GetProcessMetrics() *ProcessMetrics {
 	c.processMutex.RLock()
@@ -466,7 +840,7 @@ func (c *Collector) GetProcessMetrics() *ProcessMetrics {
 		return nil
 	}
 
-	if time.Since(c.processMetrics.UpdatedAt) > c.config.CacheTTL {
+	if c.clock.Now().Sub(c.processMetrics.UpdatedAt) > c.tuning().CacheTTL {
 		slog.Debug("Process metrics cache expired")
 		return nil
 	}
@@ -476,8 +850,9 @@ func (c *Collector) GetProcessMetrics() *ProcessMetrics {
 	copy(processes, c.processMetrics.Processes)
 
 	metrics := &ProcessMetrics{
-		Processes: processes,
-		UpdatedAt: c.processMetrics.UpdatedAt,
+		Processes:    processes,
+		UpdatedAt:    c.processMetrics.UpdatedAt,
+		Initializing: c.processMetrics.Initializing,
 	}
 
 	return metrics
@@ -492,7 +867,7 @@ func (c *Collector) GetOptimizedProcessMetrics(filter ProcessFilter) ([]ProcessI
 		return nil, 0, fmt.Errorf("process metrics not available")
 	}
 
-	if time.Since(c.processMetrics.UpdatedAt) > c.config.CacheTTL {
+	if c.clock.Now().Sub(c.processMetrics.UpdatedAt) > c.tuning().CacheTTL {
 		return nil, 0, fmt.Errorf("process metrics cache expired")
 	}
 
@@ -653,12 +1028,12 @@ func (c *Collector) heapifyDownProcesses(heap []ProcessInfo, i int, sortBy strin
 		right := 2*i + 2
 
 		// Compare with left child
-		if left < n && c.compareProcesses(heap[left], heap[largest], sortBy, isMinHeap) {
+		if left < n && c.compareProcesses(heap[left], heap[largest], sortBy, !isMinHeap) {
 			largest = left
 		}
 
 		// Compare with right child
-		if right < n && c.compareProcesses(heap[right], heap[largest], sortBy, isMinHeap) {
+		if right < n && c.compareProcesses(heap[right], heap[largest], sortBy, !isMinHeap) {
 			largest = right
 		}
 
@@ -674,7 +1049,7 @@ func (c *Collector) heapifyDownProcesses(heap []ProcessInfo, i int, sortBy strin
 
 // shouldReplaceHeapRoot checks if new process should replace heap root
 func (c *Collector) shouldReplaceHeapRoot(root, candidate ProcessInfo, sortBy string, isMinHeap bool) bool {
-	return c.compareProcesses(candidate, root, sortBy, !isMinHeap)
+	return c.compareProcesses(candidate, root, sortBy, isMinHeap)
 }
 
 // compareProcesses compares two processes based on the specified field
@@ -701,25 +1076,36 @@ func (c *Collector) compareProcesses(a, b ProcessInfo, sortBy string, aIsGreater
 	return result
 }
 
-// IsHealthy returns true if all metrics are being collected successfully
+// IsHealthy returns true if all metrics are being collected successfully.
+// A collector still warming up is healthy: it is not failing, its values are
+// just not final yet (see Status).
 func (c *Collector) IsHealthy() bool {
-	now := time.Now()
+	return c.Initializing() || c.fresh()
+}
+
+// fresh reports whether every metric was refreshed within twice the cache TTL
+func (c *Collector) fresh() bool {
+	now := c.clock.Now()
 
 	c.cpuMutex.RLock()
-	cpuHealthy := c.cpuMetrics != nil && now.Sub(c.cpuMetrics.UpdatedAt) < c.config.CacheTTL*2
+	cpuHealthy := c.cpuMetrics != nil && now.Sub(c.cpuMetrics.UpdatedAt) < c.tuning().CacheTTL*2
 	c.cpuMutex.RUnlock()
 
 	c.memoryMutex.RLock()
-	memoryHealthy := c.memoryMetrics != nil && now.Sub(c.memoryMetrics.UpdatedAt) < c.config.CacheTTL*2
+	memoryHealthy := c.memoryMetrics != nil && now.Sub(c.memoryMetrics.UpdatedAt) < c.tuning().CacheTTL*2
 	c.memoryMutex.RUnlock()
 
 	c.networkMutex.RLock()
-	networkHealthy := c.networkMetrics != nil && now.Sub(c.networkMetrics.UpdatedAt) < c.config.CacheTTL*2
+	networkHealthy := c.networkMetrics != nil && now.Sub(c.networkMetrics.UpdatedAt) < c.tuning().CacheTTL*2
 	c.networkMutex.RUnlock()
 
+	c.diskMutex.RLock()
+	diskHealthy := c.diskMetrics != nil && now.Sub(c.diskMetrics.UpdatedAt) < c.tuning().CacheTTL*2
+	c.diskMutex.RUnlock()
+
 	c.processMutex.RLock()
-	processHealthy := c.processMetrics != nil && now.Sub(c.processMetrics.UpdatedAt) < c.config.CacheTTL*2
+	processHealthy := c.processMetrics != nil && now.Sub(c.processMetrics.UpdatedAt) < c.tuning().CacheTTL*2
 	c.processMutex.RUnlock()
 
-	return cpuHealthy && memoryHealthy && networkHealthy && processHealthy
+	return cpuHealthy && memoryHealthy && networkHealthy && diskHealthy && processHealthy
 }
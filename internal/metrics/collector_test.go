@@ -0,0 +1,79 @@
+package metrics
+
+import (
+	"testing"
+	"time"
+
+	"github.com/shirou/gopsutil/v3/cpu"
+	"github.com/stretchr/testify/assert"
+)
+
+func testProcesses() []ProcessInfo {
+	processes := make([]ProcessInfo, 10)
+	for i := range processes {
+		processes[i] = ProcessInfo{PID: int32(i + 1), CPUPercent: float64((i * 7) % 10), MemPercent: float32(i)}
+	}
+	return processes
+}
+
+func pids(processes []ProcessInfo) []int32 {
+	result := make([]int32, len(processes))
+	for i, p := range processes {
+		result[i] = p.PID
+	}
+	return result
+}
+
+func TestSelectTopNProcesses(t *testing.T) {
+	c := NewCollector(DefaultConfig())
+
+	top := c.selectTopNProcesses(testProcesses(), 3, "cpu", "desc")
+	assert.Equal(t, []float64{9, 8, 7}, []float64{top[0].CPUPercent, top[1].CPUPercent, top[2].CPUPercent})
+
+	bottom := c.selectTopNProcesses(testProcesses(), 3, "cpu", "asc")
+	assert.Equal(t, []float64{0, 1, 2}, []float64{bottom[0].CPUPercent, bottom[1].CPUPercent, bottom[2].CPUPercent})
+}
+
+func TestSelectTopConsumers(t *testing.T) {
+	c := NewCollector(DefaultConfig())
+
+	// Top 2 by CPU are PIDs 8 (9%) and 5 (8%); top 2 by memory are PIDs 10 and 9
+	kept := c.selectTopConsumers(testProcesses(), 2)
+	assert.ElementsMatch(t, []int32{8, 5, 10, 9}, pids(kept))
+
+	assert.Len(t, c.selectTopConsumers(testProcesses(), 0), 10)
+}
+
+func TestAdaptProcessLimit(t *testing.T) {
+	config := DefaultConfig()
+	config.ProcessLimit = 100
+	config.MinProcessLimit = 30
+	config.ProcessBudget = time.Second
+	c := NewCollector(config)
+
+	c.adaptProcessLimit(2*time.Second, 5000)
+	assert.Equal(t, 50, c.ProcessCollectionStats().Limit)
+	c.adaptProcessLimit(2*time.Second, 5000)
+	assert.Equal(t, 30, c.ProcessCollectionStats().Limit, "never below the minimum")
+
+	// Within budget but not by a wide margin: unchanged
+	c.adaptProcessLimit(800*time.Millisecond, 5000)
+	assert.Equal(t, 30, c.ProcessCollectionStats().Limit)
+
+	for i := 0; i < 10; i++ {
+		c.adaptProcessLimit(100*time.Millisecond, 5000)
+	}
+	stats := c.ProcessCollectionStats()
+	assert.Equal(t, 100, stats.Limit, "never above ProcessLimit")
+	assert.Equal(t, 5000, stats.Candidates)
+	assert.Equal(t, 100.0, stats.DurationMs)
+}
+
+func TestIOWaitPercent(t *testing.T) {
+	prev := cpu.TimesStat{User: 100, System: 50, Idle: 800, Iowait: 50}
+	cur := cpu.TimesStat{User: 110, System: 55, Idle: 820, Iowait: 65}
+	assert.InDelta(t, 30, ioWaitPercent(prev, cur), 0.001, "15 of 50 elapsed seconds")
+
+	assert.Zero(t, ioWaitPercent(cur, cur), "no time elapsed")
+	assert.Zero(t, ioWaitPercent(cur, prev), "counters reset")
+}
@@ -0,0 +1,63 @@
+package metrics
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiffProcesses(t *testing.T) {
+	prev := []ProcessInfo{
+		{PID: 1, Name: "init"},
+		{PID: 20, Name: "nginx", CPUPercent: 1.5},
+		{PID: 30, Name: "cron"},
+	}
+	next := []ProcessInfo{
+		{PID: 20, Name: "nginx", CPUPercent: 3},
+		{PID: 1, Name: "init"},
+		{PID: 40, Name: "sshd"},
+	}
+
+	delta := DiffProcesses(prev, next)
+	assert.Equal(t, []ProcessInfo{{PID: 40, Name: "sshd"}}, delta.Added)
+	assert.Equal(t, []ProcessInfo{{PID: 20, Name: "nginx", CPUPercent: 3}}, delta.Changed)
+	assert.Equal(t, []int32{30}, delta.Removed)
+	assert.False(t, delta.Empty())
+
+	got := delta.Apply(prev)
+	assert.Equal(t, []ProcessInfo{next[1], next[0], next[2]}, got)
+	assert.Equal(t, "cron", prev[2].Name, "Apply must not modify its input")
+
+	assert.True(t, DiffProcesses(next, next).Empty())
+}
+
+func TestDiffProcesses_Limits(t *testing.T) {
+	prev := []ProcessInfo{
+		{PID: 1, Name: "init", Limits: &ProcessLimits{OpenFiles: 10, MaxOpenFiles: 1024}},
+		{PID: 2, Name: "nginx", Limits: &ProcessLimits{OpenFiles: 10, MaxOpenFiles: 1024}},
+	}
+	next := []ProcessInfo{
+		{PID: 1, Name: "init", Limits: &ProcessLimits{OpenFiles: 10, MaxOpenFiles: 1024}},
+		{PID: 2, Name: "nginx", Limits: &ProcessLimits{OpenFiles: 900, MaxOpenFiles: 1024}},
+	}
+
+	delta := DiffProcesses(prev, next)
+	assert.Empty(t, delta.Added)
+	assert.Equal(t, []ProcessInfo{next[1]}, delta.Changed, "equal limits held in new values are unchanged")
+}
+
+func TestDiffProcesses_Details(t *testing.T) {
+	prev := []ProcessInfo{
+		{PID: 1, Name: "init", Details: &ProcessDetails{Threads: 1, Username: "root"}},
+		{PID: 2, Name: "nginx", Details: &ProcessDetails{Threads: 4, ReadBytes: 100}},
+		{PID: 3, Name: "sshd"},
+	}
+	next := []ProcessInfo{
+		{PID: 1, Name: "init", Details: &ProcessDetails{Threads: 1, Username: "root"}},
+		{PID: 2, Name: "nginx", Details: &ProcessDetails{Threads: 4, ReadBytes: 4096}},
+		{PID: 3, Name: "sshd", Details: &ProcessDetails{Threads: 1}},
+	}
+
+	delta := DiffProcesses(prev, next)
+	assert.Equal(t, []ProcessInfo{next[1], next[2]}, delta.Changed)
+}
@@ -0,0 +1,45 @@
+// File: internal/metrics/details.go
+// Brief: Optional per-process details
+// Detailed: Reads the open file descriptors, thread count, storage IO counters and owning user of tracked processes. Each costs further /proc reads (and a user lookup) per process and round, so the collector only reads them when CollectorConfig.ProcessDetails is set.
+// Author: drama.lin@aver.com
+// Date: 2026-10-14
+
+package metrics
+
+import (
+	"context"
+
+	"github.com/shirou/gopsutil/v3/process"
+)
+
+// ProcessDetails holds the optional details of a process. Values the
+// platform does not expose, or the collector may not read (e.g. the IO
+// counters of another user's process), are left zero.
+type ProcessDetails struct {
+	OpenFDs    int32  `json:"open_fds"`
+	Threads    int32  `json:"threads"`
+	ReadBytes  uint64 `json:"read_bytes"`  // Read from storage since the process started
+	WriteBytes uint64 `json:"write_bytes"` // Written to storage since the process started
+	Username   string `json:"username,omitempty"`
+}
+
+// processDetails collects the details of p. The open file count of limits
+// is reused when it was read.
+func processDetails(ctx context.Context, p *process.Process, limits *ProcessLimits) *ProcessDetails {
+	details := &ProcessDetails{}
+	if limits != nil && limits.OpenFiles > 0 {
+		details.OpenFDs = int32(limits.OpenFiles)
+	} else if fds, err := p.NumFDsWithContext(ctx); err == nil {
+		details.OpenFDs = fds
+	}
+	if threads, err := p.NumThreadsWithContext(ctx); err == nil {
+		details.Threads = threads
+	}
+	if io, err := p.IOCountersWithContext(ctx); err == nil {
+		details.ReadBytes, details.WriteBytes = io.ReadBytes, io.WriteBytes
+	}
+	if user, err := p.UsernameWithContext(ctx); err == nil {
+		details.Username = user
+	}
+	return details
+}
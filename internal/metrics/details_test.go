@@ -0,0 +1,28 @@
+package metrics
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/shirou/gopsutil/v3/process"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProcessDetails_Self(t *testing.T) {
+	if _, err := os.Stat("/proc/self/io"); err != nil {
+		t.Skip("process details are read from /proc")
+	}
+	p, err := process.NewProcess(int32(os.Getpid()))
+	require.NoError(t, err)
+
+	details := processDetails(context.Background(), p, nil)
+	require.NotNil(t, details)
+	assert.NotZero(t, details.OpenFDs, "the test binary has open files")
+	assert.NotZero(t, details.Threads)
+	assert.NotEmpty(t, details.Username)
+
+	reused := processDetails(context.Background(), p, &ProcessLimits{OpenFiles: 7})
+	assert.Equal(t, int32(7), reused.OpenFDs, "the count read with the limits is reused")
+}
@@ -0,0 +1,106 @@
+// File: internal/metrics/health.go
+// Brief: Per-metric collection health
+// Detailed: Records the last success, last error and consecutive failures of every metric kind the collector gathers, so health endpoints can report partial failures and the evaluator can tell why an alert has no data.
+// Author: drama.lin@aver.com
+// Date: 2026-10-14
+
+package metrics
+
+import (
+	"log/slog"
+	"time"
+)
+
+// Metric kinds gathered by the collector
+const (
+	KindCPU      = "cpu"
+	KindMemory   = "memory"
+	KindNetwork  = "network"
+	KindDisk     = "disk"
+	KindProcess  = "process"
+	KindRAID     = "raid"
+	KindPower    = "power"
+	KindServices = "services"
+)
+
+// Kinds lists every metric kind in collection order
+var Kinds = []string{KindCPU, KindMemory, KindNetwork, KindDisk, KindProcess, KindRAID, KindPower, KindServices}
+
+// Further health states of a single metric kind
+const (
+	StatusFailing  = "failing"  // The last collection attempt failed
+	StatusDegraded = "degraded" // Some metric kinds are failing or stale
+)
+
+// MetricHealth reports the collection results of one metric kind
+type MetricHealth struct {
+	Status              string     `json:"status"` // initializing, healthy, failing or stale
+	LastSuccess         *time.Time `json:"last_success,omitempty"`
+	LastError           string     `json:"last_error,omitempty"`
+	LastErrorAt         *time.Time `json:"last_error_at,omitempty"`
+	ConsecutiveFailures int        `json:"consecutive_failures"`
+}
+
+// recordResult stores the outcome of collecting one metric kind
+func (c *Collector) recordResult(kind string, err error) {
+	now := c.clock.Now()
+
+	c.healthMutex.Lock()
+	h := c.health[kind]
+	if h == nil {
+		h = &MetricHealth{}
+		c.health[kind] = h
+	}
+	if err == nil {
+		h.LastSuccess = &now
+		h.ConsecutiveFailures = 0
+	} else {
+		h.LastError = err.Error()
+		h.LastErrorAt = &now
+		h.ConsecutiveFailures++
+	}
+	failures := h.ConsecutiveFailures
+	c.healthMutex.Unlock()
+
+	// Log the first failure and then periodically while the source stays broken
+	if err != nil {
+		c.errorLog.Log(slog.LevelError, kind, "Failed to collect metrics", "kind", kind, "error", err, "consecutive_failures", failures)
+	} else {
+		c.errorLog.Reset(kind)
+	}
+}
+
+// MetricHealth returns the collection health of one metric kind
+func (c *Collector) MetricHealth(kind string) MetricHealth {
+	now := c.clock.Now()
+
+	c.healthMutex.Lock()
+	defer c.healthMutex.Unlock()
+
+	var h MetricHealth
+	if recorded := c.health[kind]; recorded != nil {
+		h = *recorded
+	}
+	switch {
+	case !c.tuning().Enabled(kind):
+		h.Status = StatusDisabled
+	case h.ConsecutiveFailures > 0:
+		h.Status = StatusFailing
+	case h.LastSuccess == nil:
+		h.Status = StatusInitializing
+	case now.Sub(*h.LastSuccess) >= c.tuning().CacheTTL*2:
+		h.Status = StatusStale
+	default:
+		h.Status = StatusHealthy
+	}
+	return h
+}
+
+// Health returns the collection health of every metric kind
+func (c *Collector) Health() map[string]MetricHealth {
+	result := make(map[string]MetricHealth, len(Kinds))
+	for _, kind := range Kinds {
+		result[kind] = c.MetricHealth(kind)
+	}
+	return result
+}
@@ -0,0 +1,129 @@
+// File: internal/metrics/history.go
+// Brief: Recording of collected host metrics as time series
+// Detailed: After each collection round the collector can append its CPU, memory, network, per-partition disk, RAID and power values to a series store, giving dashboards such as the Grafana datasource a history of the host metrics. The same samples are what the metrics exporters ship to remote time series databases.
+// Author: drama.lin@aver.com
+// Date: 2026-10-14
+
+package metrics
+
+import "time"
+
+// Names of the host metric series recorded by the collector. Disk series
+// carry a mountpoint label, RAID series an array label, ZFS series a pool
+// label and power series a device label (a battery name or UPS ID).
+const (
+	HistoryCPUUsagePercent    = "cpu_usage_percent"
+	HistoryCPULoad1           = "cpu_load1"
+	HistoryCPULoad5           = "cpu_load5"
+	HistoryCPULoad15          = "cpu_load15"
+	HistoryMemoryUsedPercent  = "memory_used_percent"
+	HistoryMemoryUsed         = "memory_used"
+	HistoryNetworkBytesSent   = "network_bytes_sent"
+	HistoryNetworkBytesRecv   = "network_bytes_recv"
+	HistoryNetworkPacketsSent = "network_packets_sent"
+	HistoryNetworkPacketsRecv = "network_packets_recv"
+	HistoryDiskUsedPercent    = "disk_used_percent"
+	HistoryRAIDDegraded       = "raid_degraded"
+	HistoryRAIDSyncPercent    = "raid_sync_percent"
+	HistoryZFSDegraded        = "zfs_pool_degraded"
+	HistoryZFSCapacityPercent = "zfs_pool_capacity_percent"
+	HistoryPowerChargePercent = "power_charge_percent"
+	HistoryPowerRuntime       = "power_runtime_seconds"
+	HistoryPowerOnBattery     = "power_on_battery"
+)
+
+// SetHistory makes the collector append every collection round to store.
+// It must be called before Start.
+func (c *Collector) SetHistory(store *SeriesStore) {
+	c.history = store
+}
+
+// HostSample is one value of the collected host metrics, named like its
+// history series
+type HostSample struct {
+	Name   string
+	Labels map[string]string
+	Sample
+}
+
+// recordHistory appends the cached metrics to the history store. Samples are
+// stamped with the metrics' update time, so unchanged caches add nothing.
+func (c *Collector) recordHistory() {
+	if c.history == nil {
+		return
+	}
+	for _, s := range c.HostSamples() {
+		// Rejected samples are counted in the store's drop counter
+		_ = c.history.Append(s.Name, s.Labels, []Sample{s.Sample})
+	}
+}
+
+// HostSamples returns the cached host metrics as samples named like the
+// history series, stamped with the metrics' update time
+func (c *Collector) HostSamples() []HostSample {
+	var samples []HostSample
+	add := func(name string, labels map[string]string, at time.Time, value float64) {
+		samples = append(samples, HostSample{Name: name, Labels: labels, Sample: Sample{Timestamp: at, Value: value}})
+	}
+
+	if m := c.GetCPUMetrics(); m != nil {
+		add(HistoryCPUUsagePercent, nil, m.UpdatedAt, m.UsagePercent)
+		add(HistoryCPULoad1, nil, m.UpdatedAt, m.Load1)
+		add(HistoryCPULoad5, nil, m.UpdatedAt, m.Load5)
+		add(HistoryCPULoad15, nil, m.UpdatedAt, m.Load15)
+	}
+	if m := c.GetMemoryMetrics(); m != nil {
+		add(HistoryMemoryUsedPercent, nil, m.UpdatedAt, m.UsedPercent)
+		add(HistoryMemoryUsed, nil, m.UpdatedAt, float64(m.Used))
+	}
+	if m := c.GetNetworkMetrics(); m != nil {
+		add(HistoryNetworkBytesSent, nil, m.UpdatedAt, float64(m.BytesSent))
+		add(HistoryNetworkBytesRecv, nil, m.UpdatedAt, float64(m.BytesRecv))
+		add(HistoryNetworkPacketsSent, nil, m.UpdatedAt, float64(m.PacketsSent))
+		add(HistoryNetworkPacketsRecv, nil, m.UpdatedAt, float64(m.PacketsRecv))
+	}
+	if m := c.GetDiskMetrics(); m != nil {
+		for _, p := range m.Partitions {
+			add(HistoryDiskUsedPercent, map[string]string{"mountpoint": p.Mountpoint}, m.UpdatedAt, p.UsedPercent)
+		}
+	}
+	if m := c.GetRAIDMetrics(); m != nil {
+		for _, a := range m.Arrays {
+			labels := map[string]string{"array": a.Name}
+			add(HistoryRAIDDegraded, labels, m.UpdatedAt, boolValue(a.Degraded))
+			if a.SyncAction != "" {
+				add(HistoryRAIDSyncPercent, labels, m.UpdatedAt, a.SyncPercent)
+			}
+		}
+		for _, p := range m.Pools {
+			labels := map[string]string{"pool": p.Name}
+			add(HistoryZFSDegraded, labels, m.UpdatedAt, boolValue(p.Degraded))
+			add(HistoryZFSCapacityPercent, labels, m.UpdatedAt, p.CapacityPercent)
+		}
+	}
+	if m := c.GetPowerMetrics(); m != nil {
+		for _, b := range m.Batteries {
+			labels := map[string]string{"device": b.Name}
+			add(HistoryPowerChargePercent, labels, m.UpdatedAt, b.ChargePercent)
+			add(HistoryPowerOnBattery, labels, m.UpdatedAt, boolValue(b.OnBattery))
+			if b.RuntimeSeconds > 0 {
+				add(HistoryPowerRuntime, labels, m.UpdatedAt, b.RuntimeSeconds)
+			}
+		}
+		for _, u := range m.UPSes {
+			labels := map[string]string{"device": u.ID()}
+			add(HistoryPowerChargePercent, labels, m.UpdatedAt, u.ChargePercent)
+			add(HistoryPowerOnBattery, labels, m.UpdatedAt, boolValue(u.OnBattery))
+			add(HistoryPowerRuntime, labels, m.UpdatedAt, u.RuntimeSeconds)
+		}
+	}
+	return samples
+}
+
+// boolValue records a state as 1 when set and 0 otherwise
+func boolValue(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,42 @@
+package metrics
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"argus/internal/clock"
+)
+
+func TestCollector_RecordHistory(t *testing.T) {
+	clk := clock.NewFake(epoch)
+	config := DefaultConfig()
+	config.Clock = clk
+	c := NewCollector(config)
+	store := NewSeriesStore(SeriesStoreConfig{Retention: time.Hour, Clock: clk})
+	c.SetHistory(store)
+
+	c.cpuMetrics = &CPUMetrics{UsagePercent: 42, Load1: 1.5, UpdatedAt: epoch}
+	c.diskMetrics = &DiskMetrics{
+		Partitions: []DiskUsage{{Mountpoint: "/", UsedPercent: 70}, {Mountpoint: "/boot", UsedPercent: 20}},
+		UpdatedAt:  epoch,
+	}
+	c.recordHistory()
+	// An unchanged cache adds no samples
+	c.recordHistory()
+
+	cpu := store.Latest(HistoryCPUUsagePercent, nil)
+	require.Len(t, cpu, 1)
+	assert.Equal(t, 42.0, cpu[0].Latest.Value)
+	assert.Equal(t, 1, cpu[0].Samples)
+
+	root := store.Latest(HistoryDiskUsedPercent, map[string]string{"mountpoint": "/"})
+	require.Len(t, root, 1)
+	assert.Equal(t, 70.0, root[0].Latest.Value)
+
+	// Memory and network were never collected
+	assert.Empty(t, store.Latest(HistoryMemoryUsedPercent, nil))
+	assert.Empty(t, store.Latest(HistoryNetworkBytesSent, nil))
+}
@@ -0,0 +1,160 @@
+// File: internal/metrics/limits.go
+// Brief: Process resource limit collection
+// Detailed: Reads the open file (RLIMIT_NOFILE) and address space (RLIMIT_AS) limits of tracked processes and the memory limit of the cgroup they run in, with current usage as a percentage of each, so alerts can fire before a process runs out of file descriptors or is OOM-killed.
+// Author: drama.lin@aver.com
+// Date: 2026-10-14
+
+package metrics
+
+import (
+	"bufio"
+	"context"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/shirou/gopsutil/v3/process"
+)
+
+// Locations of the proc and cgroup filesystems
+var (
+	procRoot   = "/proc"
+	cgroupRoot = "/sys/fs/cgroup"
+)
+
+// cgroupUnlimited is the smallest cgroup v1 memory limit read as no limit;
+// v1 reports an unset limit as the largest page-aligned int64
+const cgroupUnlimited = 1 << 62
+
+// ProcessLimits holds a process's resource limits and its usage of them.
+// A zero limit means the process has none; its percentage is then 0.
+type ProcessLimits struct {
+	OpenFiles        uint64  `json:"open_files"`
+	MaxOpenFiles     uint64  `json:"max_open_files"` // Soft RLIMIT_NOFILE
+	OpenFilesPercent float64 `json:"open_files_percent"`
+
+	AddressSpace        uint64  `json:"address_space"`     // Virtual memory size in bytes
+	MaxAddressSpace     uint64  `json:"max_address_space"` // Soft RLIMIT_AS
+	AddressSpacePercent float64 `json:"address_space_percent"`
+
+	// Memory charged to the nearest cgroup enforcing a memory limit
+	CgroupMemory        uint64  `json:"cgroup_memory,omitempty"`
+	CgroupMemoryLimit   uint64  `json:"cgroup_memory_limit,omitempty"`
+	CgroupMemoryPercent float64 `json:"cgroup_memory_percent,omitempty"`
+}
+
+// limitPercent returns used as a percentage of limit, 0 without a limit
+func limitPercent(used, limit uint64) float64 {
+	if limit == 0 {
+		return 0
+	}
+	return float64(used) / float64(limit) * 100
+}
+
+// rlimit converts a soft limit to 0 when it is unlimited
+func rlimit(soft uint64) uint64 {
+	if soft == math.MaxUint64 {
+		return 0
+	}
+	return soft
+}
+
+// processLimits collects the limits of p. It returns nil where the platform
+// does not expose them.
+func processLimits(ctx context.Context, p *process.Process) *ProcessLimits {
+	rlimits, err := p.RlimitUsageWithContext(ctx, false)
+	if err != nil {
+		return nil
+	}
+
+	limits := &ProcessLimits{}
+	for _, r := range rlimits {
+		switch r.Resource {
+		case process.RLIMIT_NOFILE:
+			limits.MaxOpenFiles = rlimit(r.Soft)
+		case process.RLIMIT_AS:
+			limits.MaxAddressSpace = rlimit(r.Soft)
+		}
+	}
+	if fds, err := p.NumFDsWithContext(ctx); err == nil && fds > 0 {
+		limits.OpenFiles = uint64(fds)
+	}
+	if mi, err := p.MemoryInfoWithContext(ctx); err == nil {
+		limits.AddressSpace = mi.VMS
+	}
+	limits.OpenFilesPercent = limitPercent(limits.OpenFiles, limits.MaxOpenFiles)
+	limits.AddressSpacePercent = limitPercent(limits.AddressSpace, limits.MaxAddressSpace)
+
+	if usage, limit, ok := cgroupMemory(procRoot, cgroupRoot, p.Pid); ok {
+		limits.CgroupMemory = usage
+		limits.CgroupMemoryLimit = limit
+		limits.CgroupMemoryPercent = limitPercent(usage, limit)
+	}
+	return limits
+}
+
+// cgroupMemory returns the memory usage and limit of the nearest cgroup of
+// pid, or one of its ancestors, that enforces a memory limit. Both cgroup v2
+// and the v1 memory controller are supported. Cgroups not visible under
+// cgroupFS, e.g. outside the container's cgroup namespace, are skipped.
+func cgroupMemory(procFS, cgroupFS string, pid int32) (usage, limit uint64, ok bool) {
+	file, err := os.Open(filepath.Join(procFS, strconv.Itoa(int(pid)), "cgroup"))
+	if err != nil {
+		return 0, 0, false
+	}
+	defer file.Close()
+
+	// Lines are hierarchy-ID:controllers:path; v2 has empty controllers
+	var v1, v2 string
+	hasV1 := false
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		fields := strings.SplitN(scanner.Text(), ":", 3)
+		if len(fields) != 3 {
+			continue
+		}
+		switch {
+		case fields[0] == "0" && fields[1] == "":
+			v2 = fields[2]
+		case strings.Contains(","+fields[1]+",", ",memory,"):
+			v1, hasV1 = fields[2], true
+		}
+	}
+
+	if hasV1 {
+		return nearestLimit(filepath.Join(cgroupFS, "memory"), v1, "memory.usage_in_bytes", "memory.limit_in_bytes")
+	}
+	if v2 != "" {
+		return nearestLimit(cgroupFS, v2, "memory.current", "memory.max")
+	}
+	return 0, 0, false
+}
+
+// nearestLimit walks from the cgroup at path up to the root of the
+// hierarchy mounted at mount, returning the first limit found
+func nearestLimit(mount, path, usageFile, limitFile string) (usage, limit uint64, ok bool) {
+	for dir := filepath.Clean("/" + path); ; dir = filepath.Dir(dir) {
+		cgroup := filepath.Join(mount, dir)
+		if limit, ok := readCgroupValue(filepath.Join(cgroup, limitFile)); ok && limit > 0 && limit < cgroupUnlimited {
+			if usage, ok := readCgroupValue(filepath.Join(cgroup, usageFile)); ok {
+				return usage, limit, true
+			}
+		}
+		if dir == "/" {
+			return 0, 0, false
+		}
+	}
+}
+
+// readCgroupValue reads a cgroup file holding one number; v2's "max" reads
+// as not set
+func readCgroupValue(path string) (uint64, bool) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0, false
+	}
+	value, err := strconv.ParseUint(strings.TrimSpace(string(data)), 10, 64)
+	return value, err == nil
+}
@@ -0,0 +1,84 @@
+package metrics
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/shirou/gopsutil/v3/process"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+}
+
+func TestCgroupMemory_V2(t *testing.T) {
+	proc, cgroups := t.TempDir(), t.TempDir()
+	writeFile(t, filepath.Join(proc, "42", "cgroup"), "0::/system.slice/app.service\n")
+	writeFile(t, filepath.Join(cgroups, "system.slice", "app.service", "memory.max"), "max\n")
+	writeFile(t, filepath.Join(cgroups, "system.slice", "app.service", "memory.current"), "100\n")
+	writeFile(t, filepath.Join(cgroups, "system.slice", "memory.max"), "1000\n")
+	writeFile(t, filepath.Join(cgroups, "system.slice", "memory.current"), "250\n")
+
+	usage, limit, ok := cgroupMemory(proc, cgroups, 42)
+	require.True(t, ok)
+	assert.Equal(t, uint64(250), usage, "the unlimited leaf is skipped for the parent's limit")
+	assert.Equal(t, uint64(1000), limit)
+
+	writeFile(t, filepath.Join(cgroups, "system.slice", "app.service", "memory.max"), "500\n")
+	usage, limit, ok = cgroupMemory(proc, cgroups, 42)
+	require.True(t, ok)
+	assert.Equal(t, uint64(100), usage)
+	assert.Equal(t, uint64(500), limit)
+}
+
+func TestCgroupMemory_V1(t *testing.T) {
+	proc, cgroups := t.TempDir(), t.TempDir()
+	writeFile(t, filepath.Join(proc, "7", "cgroup"), "5:cpu,cpuacct:/\n4:memory:/docker/abc\n0::/\n")
+	writeFile(t, filepath.Join(cgroups, "memory", "docker", "abc", "memory.limit_in_bytes"), "2048\n")
+	writeFile(t, filepath.Join(cgroups, "memory", "docker", "abc", "memory.usage_in_bytes"), "512\n")
+	writeFile(t, filepath.Join(cgroups, "memory", "memory.limit_in_bytes"), "9223372036854771712\n")
+
+	usage, limit, ok := cgroupMemory(proc, cgroups, 7)
+	require.True(t, ok)
+	assert.Equal(t, uint64(512), usage)
+	assert.Equal(t, uint64(2048), limit)
+}
+
+func TestCgroupMemory_NoLimit(t *testing.T) {
+	proc, cgroups := t.TempDir(), t.TempDir()
+	writeFile(t, filepath.Join(proc, "7", "cgroup"), "4:memory:/outside/namespace\n")
+	writeFile(t, filepath.Join(cgroups, "memory", "memory.limit_in_bytes"), "9223372036854771712\n")
+	writeFile(t, filepath.Join(cgroups, "memory", "memory.usage_in_bytes"), "512\n")
+
+	_, _, ok := cgroupMemory(proc, cgroups, 7)
+	assert.False(t, ok, "the root's unset limit is no limit")
+	_, _, ok = cgroupMemory(proc, cgroups, 8)
+	assert.False(t, ok, "unknown process")
+}
+
+func TestLimitPercent(t *testing.T) {
+	assert.InDelta(t, 25.0, limitPercent(256, 1024), 0.001)
+	assert.Zero(t, limitPercent(256, 0), "no limit")
+}
+
+func TestProcessLimits_Self(t *testing.T) {
+	if _, err := os.Stat("/proc/self/limits"); err != nil {
+		t.Skip("process limits are read from /proc")
+	}
+	p, err := process.NewProcess(int32(os.Getpid()))
+	require.NoError(t, err)
+
+	limits := processLimits(context.Background(), p)
+	require.NotNil(t, limits)
+	assert.NotZero(t, limits.OpenFiles, "the test binary has open files")
+	assert.NotZero(t, limits.AddressSpace)
+	if limits.MaxOpenFiles > 0 {
+		assert.InDelta(t, limitPercent(limits.OpenFiles, limits.MaxOpenFiles), limits.OpenFilesPercent, 0.001)
+	}
+}
@@ -0,0 +1,88 @@
+// File: internal/metrics/names.go
+// Brief: Process counts by name across every running process
+// Detailed: The process metrics only keep the top consumers, so a daemon using no resources is missing from them whether it runs or not. CountProcesses lists the names of all running processes instead, for alerts such as "fewer than 1 process named sshd", reading them at most once per update interval however many alerts count.
+// Author: drama.lin@aver.com
+// Date: 2026-10-14
+
+package metrics
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/shirou/gopsutil/v3/process"
+)
+
+// processName identifies a running process
+type processName struct {
+	pid  int32
+	name string
+}
+
+// processNames caches the names of all running processes
+type processNames struct {
+	mu    sync.Mutex
+	names []processName
+	at    time.Time
+}
+
+// CountProcesses returns how many running processes match is true for.
+// Kernel threads are not counted.
+func (c *Collector) CountProcesses(ctx context.Context, match func(name string, pid int32) bool) (int, error) {
+	names, err := c.processNames(ctx)
+	if err != nil {
+		return 0, err
+	}
+	count := 0
+	for _, p := range names {
+		if match(p.name, p.pid) {
+			count++
+		}
+	}
+	return count, nil
+}
+
+// processNames returns the names of all running processes, listed again once
+// the previous list is an update interval old. The result must not be
+// modified.
+func (c *Collector) processNames(ctx context.Context) ([]processName, error) {
+	tuning := c.tuning()
+	if !tuning.Enabled(KindProcess) {
+		return nil, errors.New("process metrics are not collected")
+	}
+
+	c.names.mu.Lock()
+	defer c.names.mu.Unlock()
+	if c.names.names != nil && c.clock.Now().Sub(c.names.at) < tuning.UpdateInterval {
+		return c.names.names, nil
+	}
+
+	listCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	procs, err := guard(c, listCtx, "process.Processes", process.ProcessesWithContext)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get process list: %w", err)
+	}
+
+	names := make([]processName, 0, len(procs))
+	for _, p := range procs {
+		// A partial list would undercount and fire count alerts
+		if err := listCtx.Err(); err != nil {
+			return nil, fmt.Errorf("listing process names cancelled: %w", err)
+		}
+		if p == nil || p.Pid <= 0 {
+			continue
+		}
+		// Processes that exited since the listing are skipped
+		name, err := guard(c, listCtx, fmt.Sprintf("process.Name %d", p.Pid), p.NameWithContext)
+		if err != nil || name == "" || name[0] == '[' {
+			continue
+		}
+		names = append(names, processName{pid: p.Pid, name: name})
+	}
+	c.names.names, c.names.at = names, c.clock.Now()
+	return names, nil
+}
@@ -0,0 +1,57 @@
+package metrics
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/shirou/gopsutil/v3/process"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"argus/internal/clock"
+)
+
+func TestCollector_CountProcesses(t *testing.T) {
+	self, err := process.NewProcess(int32(os.Getpid()))
+	require.NoError(t, err)
+	name, err := self.Name()
+	if err != nil {
+		t.Skip("process names are not readable:", err)
+	}
+
+	clk := clock.NewFake(epoch)
+	config := DefaultConfig()
+	config.Clock = clk
+	c := NewCollector(config)
+	ctx := context.Background()
+
+	count, err := c.CountProcesses(ctx, func(n string, _ int32) bool { return n == name })
+	require.NoError(t, err)
+	assert.GreaterOrEqual(t, count, 1, "the test binary is running")
+
+	count, err = c.CountProcesses(ctx, func(_ string, pid int32) bool { return pid == self.Pid })
+	require.NoError(t, err)
+	assert.Equal(t, 1, count)
+
+	count, err = c.CountProcesses(ctx, func(string, int32) bool { return false })
+	require.NoError(t, err)
+	assert.Zero(t, count)
+
+	listed := c.names.at
+	clk.Advance(time.Second)
+	_, err = c.CountProcesses(ctx, func(string, int32) bool { return true })
+	require.NoError(t, err)
+	assert.Equal(t, listed, c.names.at, "the list is reused within the update interval")
+	clk.Advance(config.UpdateInterval)
+	_, err = c.CountProcesses(ctx, func(string, int32) bool { return true })
+	require.NoError(t, err)
+	assert.Equal(t, clk.Now(), c.names.at, "and listed again once it is older")
+
+	tuning := c.Tuning()
+	tuning.Disabled = []string{KindProcess}
+	require.NoError(t, c.Tune(tuning))
+	_, err = c.CountProcesses(ctx, func(string, int32) bool { return true })
+	assert.Error(t, err, "processes are not listed while their collection is disabled")
+}
@@ -0,0 +1,351 @@
+// File: internal/metrics/power.go
+// Brief: Battery and UPS power collection
+// Detailed: Reads laptop and edge device batteries from the Linux power supply class and queries NUT (Network UPS Tools) upsd servers for their UPSes, reporting charge, estimated runtime, UPS load and whether each device runs on battery and for how long, so alerts can fire on a power outage that lasts or a runtime running out.
+// Author: drama.lin@aver.com
+// Date: 2026-10-14
+
+package metrics
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// powerSupplyRoot is the sysfs power supply class, a variable so tests can
+// point it at fixtures
+var powerSupplyRoot = "/sys/class/power_supply"
+
+// DefaultNUTPort is the port of upsd servers given without one
+const DefaultNUTPort = "3493"
+
+// nutTimeout bounds the whole exchange with one upsd server
+const nutTimeout = 3 * time.Second
+
+// Battery is the state of one system battery
+type Battery struct {
+	Name             string  `json:"name"`   // e.g. "BAT0"
+	Status           string  `json:"status"` // Charging, Discharging, Full, Not charging or Unknown
+	ChargePercent    float64 `json:"charge_percent"`
+	RuntimeSeconds   float64 `json:"runtime_seconds,omitempty"` // Estimated from the discharge rate while discharging
+	OnBattery        bool    `json:"on_battery"`
+	OnBatterySeconds float64 `json:"on_battery_seconds,omitempty"` // Time since the battery started discharging
+}
+
+// UPS is the state of one UPS reported by a NUT server
+type UPS struct {
+	Name             string  `json:"name"`
+	Server           string  `json:"server"` // upsd address the UPS was read from
+	Description      string  `json:"description,omitempty"`
+	Status           string  `json:"status"` // ups.status flags, e.g. "OL CHRG" or "OB LB"
+	ChargePercent    float64 `json:"charge_percent"`
+	RuntimeSeconds   float64 `json:"runtime_seconds,omitempty"` // battery.runtime
+	LoadPercent      float64 `json:"load_percent"`
+	OnBattery        bool    `json:"on_battery"`  // OB flag
+	LowBattery       bool    `json:"low_battery"` // LB flag
+	OnBatterySeconds float64 `json:"on_battery_seconds,omitempty"`
+}
+
+// ID returns the NUT identifier of the UPS, e.g. "myups@localhost:3493"
+func (u UPS) ID() string {
+	return u.Name + "@" + u.Server
+}
+
+// PowerMetrics holds the state of every battery and UPS
+type PowerMetrics struct {
+	Batteries    []Battery         `json:"batteries"`
+	UPSes        []UPS             `json:"ups"`
+	ServerErrors map[string]string `json:"server_errors,omitempty"` // NUT servers that could not be queried
+	UpdatedAt    time.Time         `json:"updated_at"`
+}
+
+// collectPowerMetrics collects batteries and the UPSes of the configured NUT
+// servers. An unreachable server is reported in ServerErrors rather than
+// failing the collection, so batteries and other servers still update.
+func (c *Collector) collectPowerMetrics(ctx context.Context) error {
+	batteries, err := readBatteries(powerSupplyRoot)
+	if err != nil {
+		return fmt.Errorf("failed to read batteries: %w", err)
+	}
+
+	metrics := &PowerMetrics{Batteries: batteries, UPSes: []UPS{}}
+	for _, server := range c.config.UPSServers {
+		upses, err := queryNUT(ctx, server)
+		if err != nil {
+			c.errorLog.Log(slog.LevelWarn, "nut:"+server, "Failed to query NUT server", "server", server, "error", err)
+			if metrics.ServerErrors == nil {
+				metrics.ServerErrors = make(map[string]string)
+			}
+			metrics.ServerErrors[server] = err.Error()
+			continue
+		}
+		c.errorLog.Reset("nut:" + server)
+		metrics.UPSes = append(metrics.UPSes, upses...)
+	}
+	metrics.UpdatedAt = c.clock.Now()
+	c.trackOnBattery(metrics)
+
+	c.powerMutex.Lock()
+	c.powerMetrics = metrics
+	c.powerMutex.Unlock()
+
+	slog.Debug("Power metrics updated", "batteries", len(metrics.Batteries), "ups", len(metrics.UPSes))
+	return nil
+}
+
+// trackOnBattery sets how long each device has been running on battery,
+// remembering when it switched across collections. Devices of a server that
+// could not be queried keep their start time.
+func (c *Collector) trackOnBattery(m *PowerMetrics) {
+	since := make(map[string]time.Time, len(c.onBatterySince))
+	for key, start := range c.onBatterySince {
+		for server := range m.ServerErrors {
+			if strings.HasSuffix(key, "@"+server) {
+				since[key] = start
+			}
+		}
+	}
+	track := func(key string, onBattery bool) float64 {
+		if !onBattery {
+			return 0
+		}
+		start, ok := c.onBatterySince[key]
+		if !ok {
+			start = m.UpdatedAt
+		}
+		since[key] = start
+		return m.UpdatedAt.Sub(start).Seconds()
+	}
+	for i := range m.Batteries {
+		b := &m.Batteries[i]
+		b.OnBatterySeconds = track("battery:"+b.Name, b.OnBattery)
+	}
+	for i := range m.UPSes {
+		u := &m.UPSes[i]
+		u.OnBatterySeconds = track("ups:"+u.ID(), u.OnBattery)
+	}
+	c.onBatterySince = since
+}
+
+// GetPowerMetrics returns cached battery and UPS metrics
+func (c *Collector) GetPowerMetrics() *PowerMetrics {
+	c.powerMutex.RLock()
+	defer c.powerMutex.RUnlock()
+
+	if c.powerMetrics == nil {
+		return nil
+	}
+
+	if c.clock.Now().Sub(c.powerMetrics.UpdatedAt) > c.tuning().CacheTTL {
+		slog.Debug("Power metrics cache expired")
+		return nil
+	}
+
+	metrics := *c.powerMetrics
+	metrics.Batteries = slices.Clone(c.powerMetrics.Batteries)
+	metrics.UPSes = slices.Clone(c.powerMetrics.UPSes)
+	return &metrics
+}
+
+// readBatteries reads the system batteries of the power supply class.
+// Peripheral batteries (mice, keyboards) are skipped, and a missing class
+// means no batteries.
+func readBatteries(root string) ([]Battery, error) {
+	entries, err := os.ReadDir(root)
+	if errors.Is(err, os.ErrNotExist) {
+		return []Battery{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	batteries := []Battery{}
+	for _, entry := range entries {
+		dir := filepath.Join(root, entry.Name())
+		attr := func(name string) string {
+			data, _ := os.ReadFile(filepath.Join(dir, name))
+			return strings.TrimSpace(string(data))
+		}
+		number := func(name string) float64 {
+			v, _ := strconv.ParseFloat(attr(name), 64)
+			return v
+		}
+		if attr("type") != "Battery" || attr("scope") == "Device" {
+			continue
+		}
+
+		b := Battery{Name: entry.Name(), Status: attr("status"), ChargePercent: number("capacity")}
+		if b.ChargePercent == 0 {
+			if full := number("energy_full"); full > 0 {
+				b.ChargePercent = number("energy_now") / full * 100
+			} else if full := number("charge_full"); full > 0 {
+				b.ChargePercent = number("charge_now") / full * 100
+			}
+		}
+		b.OnBattery = b.Status == "Discharging"
+		if b.OnBattery {
+			// µWh over µW, or µAh over µA, is hours
+			if power := number("power_now"); power > 0 {
+				b.RuntimeSeconds = number("energy_now") / power * 3600
+			} else if current := number("current_now"); current > 0 {
+				b.RuntimeSeconds = number("charge_now") / current * 3600
+			}
+		}
+		batteries = append(batteries, b)
+	}
+	return batteries, nil
+}
+
+// NUTAddress returns the address of a upsd server given as host or host:port
+func NUTAddress(server string) string {
+	if _, _, err := net.SplitHostPort(server); err == nil {
+		return server
+	}
+	return net.JoinHostPort(strings.Trim(server, "[]"), DefaultNUTPort)
+}
+
+// queryNUT lists the UPSes of a upsd server and reads their variables
+func queryNUT(ctx context.Context, server string) ([]UPS, error) {
+	ctx, cancel := context.WithTimeout(ctx, nutTimeout)
+	defer cancel()
+
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", NUTAddress(server))
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+	client := &nutClient{conn: conn, reader: bufio.NewReader(conn)}
+
+	names, err := client.list("UPS")
+	if err != nil {
+		return nil, err
+	}
+	upses := make([]UPS, 0, len(names))
+	for _, fields := range names {
+		if len(fields) == 0 {
+			continue
+		}
+		ups := UPS{Name: fields[0], Server: server}
+		if len(fields) > 1 {
+			ups.Description = fields[1]
+		}
+		vars, err := client.list("VAR " + ups.Name)
+		if err != nil {
+			return nil, fmt.Errorf("ups %s: %w", ups.Name, err)
+		}
+		applyNUTVars(&ups, vars)
+		upses = append(upses, ups)
+	}
+	fmt.Fprint(conn, "LOGOUT\n")
+	return upses, nil
+}
+
+// applyNUTVars sets the UPS's state from its "name value" variables
+func applyNUTVars(ups *UPS, vars [][]string) {
+	for _, fields := range vars {
+		if len(fields) != 2 {
+			continue
+		}
+		value, _ := strconv.ParseFloat(fields[1], 64)
+		switch fields[0] {
+		case "battery.charge":
+			ups.ChargePercent = value
+		case "battery.runtime":
+			ups.RuntimeSeconds = value
+		case "ups.load":
+			ups.LoadPercent = value
+		case "ups.status":
+			ups.Status = fields[1]
+			flags := strings.Fields(fields[1])
+			ups.OnBattery = slices.Contains(flags, "OB")
+			ups.LowBattery = slices.Contains(flags, "LB")
+		}
+	}
+}
+
+// nutClient speaks the upsd network protocol
+type nutClient struct {
+	conn   net.Conn
+	reader *bufio.Reader
+}
+
+// list sends "LIST <query>" and returns the fields of each item after the
+// query's prefix, e.g. ["battery.charge", "100"] for "LIST VAR myups"
+func (n *nutClient) list(query string) ([][]string, error) {
+	if _, err := fmt.Fprintf(n.conn, "LIST %s\n", query); err != nil {
+		return nil, err
+	}
+	prefix := strings.Fields(query)
+	var items [][]string
+	begun := false
+	for {
+		line, err := n.reader.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+		fields, err := nutFields(strings.TrimRight(line, "\r\n"))
+		if err != nil {
+			return nil, err
+		}
+		switch {
+		case len(fields) > 0 && fields[0] == "ERR":
+			return nil, fmt.Errorf("upsd error: %s", strings.Join(fields[1:], " "))
+		case !begun:
+			if !slices.Equal(fields, append([]string{"BEGIN", "LIST"}, prefix...)) {
+				return nil, fmt.Errorf("unexpected upsd response: %q", line)
+			}
+			begun = true
+		case slices.Equal(fields, append([]string{"END", "LIST"}, prefix...)):
+			return items, nil
+		case len(fields) > len(prefix) && slices.Equal(fields[:len(prefix)], prefix):
+			items = append(items, fields[len(prefix):])
+		}
+	}
+}
+
+// nutFields splits a upsd response line into words, unquoting the quoted
+// ones ("a \"b\"" is one word)
+func nutFields(line string) ([]string, error) {
+	var fields []string
+	for i := 0; i < len(line); {
+		switch {
+		case line[i] == ' ':
+			i++
+		case line[i] == '"':
+			var word strings.Builder
+			i++
+			for ; i < len(line) && line[i] != '"'; i++ {
+				if line[i] == '\\' && i+1 < len(line) {
+					i++
+				}
+				word.WriteByte(line[i])
+			}
+			if i >= len(line) {
+				return nil, fmt.Errorf("unterminated quote in upsd response: %q", line)
+			}
+			fields = append(fields, word.String())
+			i++
+		default:
+			end := strings.IndexByte(line[i:], ' ')
+			if end < 0 {
+				end = len(line) - i
+			}
+			fields = append(fields, line[i:i+end])
+			i += end
+		}
+	}
+	return fields, nil
+}
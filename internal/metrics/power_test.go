@@ -0,0 +1,184 @@
+package metrics
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"argus/internal/clock"
+)
+
+func TestReadBatteries(t *testing.T) {
+	root := t.TempDir()
+	writeFile(t, filepath.Join(root, "AC", "type"), "Mains\n")
+	writeFile(t, filepath.Join(root, "BAT0", "type"), "Battery\n")
+	writeFile(t, filepath.Join(root, "BAT0", "status"), "Discharging\n")
+	writeFile(t, filepath.Join(root, "BAT0", "capacity"), "80\n")
+	writeFile(t, filepath.Join(root, "BAT0", "energy_now"), "40000000\n")
+	writeFile(t, filepath.Join(root, "BAT0", "power_now"), "20000000\n")
+	writeFile(t, filepath.Join(root, "BAT1", "type"), "Battery\n")
+	writeFile(t, filepath.Join(root, "BAT1", "status"), "Charging\n")
+	writeFile(t, filepath.Join(root, "BAT1", "charge_now"), "1500000\n")
+	writeFile(t, filepath.Join(root, "BAT1", "charge_full"), "3000000\n")
+	writeFile(t, filepath.Join(root, "hidpp_battery_0", "type"), "Battery\n")
+	writeFile(t, filepath.Join(root, "hidpp_battery_0", "scope"), "Device\n")
+
+	batteries, err := readBatteries(root)
+	require.NoError(t, err)
+	require.Len(t, batteries, 2, "mains and peripheral batteries are skipped")
+	assert.Equal(t, Battery{Name: "BAT0", Status: "Discharging", ChargePercent: 80, RuntimeSeconds: 7200, OnBattery: true}, batteries[0])
+	assert.Equal(t, Battery{Name: "BAT1", Status: "Charging", ChargePercent: 50}, batteries[1], "charge from charge_now/charge_full, no runtime while charging")
+
+	batteries, err = readBatteries(filepath.Join(root, "missing"))
+	require.NoError(t, err)
+	assert.Empty(t, batteries)
+}
+
+func TestNUTFields(t *testing.T) {
+	fields, err := nutFields(`VAR myups device.mfr "APC \"Back\" UPS"`)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"VAR", "myups", "device.mfr", `APC "Back" UPS`}, fields)
+	fields, err = nutFields(`VAR myups ups.status ""`)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"VAR", "myups", "ups.status", ""}, fields)
+	_, err = nutFields(`UPS myups "unterminated`)
+	assert.Error(t, err)
+}
+
+func TestNUTAddress(t *testing.T) {
+	assert.Equal(t, "localhost:3493", NUTAddress("localhost"))
+	assert.Equal(t, "10.0.0.5:4000", NUTAddress("10.0.0.5:4000"))
+	assert.Equal(t, "[::1]:3493", NUTAddress("::1"))
+}
+
+// fakeUPSD serves the NUT protocol for the given UPS variables and returns
+// its address
+func fakeUPSD(t *testing.T, upses map[string]map[string]string) string {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { listener.Close() })
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				scanner := bufio.NewScanner(conn)
+				for scanner.Scan() {
+					line := scanner.Text()
+					switch {
+					case line == "LIST UPS":
+						fmt.Fprint(conn, "BEGIN LIST UPS\n")
+						for name := range upses {
+							fmt.Fprintf(conn, "UPS %s \"Rack %s\"\n", name, name)
+						}
+						fmt.Fprint(conn, "END LIST UPS\n")
+					case strings.HasPrefix(line, "LIST VAR "):
+						name := strings.TrimPrefix(line, "LIST VAR ")
+						vars, ok := upses[name]
+						if !ok {
+							fmt.Fprint(conn, "ERR UNKNOWN-UPS\n")
+							continue
+						}
+						fmt.Fprintf(conn, "BEGIN LIST VAR %s\n", name)
+						for k, v := range vars {
+							fmt.Fprintf(conn, "VAR %s %s \"%s\"\n", name, k, v)
+						}
+						fmt.Fprintf(conn, "END LIST VAR %s\n", name)
+					case line == "LOGOUT":
+						fmt.Fprint(conn, "OK Goodbye\n")
+						return
+					}
+				}
+			}()
+		}
+	}()
+	return listener.Addr().String()
+}
+
+func TestQueryNUT(t *testing.T) {
+	server := fakeUPSD(t, map[string]map[string]string{
+		"rack1": {"battery.charge": "64", "battery.runtime": "420", "ups.load": "35", "ups.status": "OB DISCHRG LB"},
+	})
+	upses, err := queryNUT(context.Background(), server)
+	require.NoError(t, err)
+	require.Len(t, upses, 1)
+	assert.Equal(t, UPS{
+		Name: "rack1", Server: server, Description: "Rack rack1", Status: "OB DISCHRG LB",
+		ChargePercent: 64, RuntimeSeconds: 420, LoadPercent: 35, OnBattery: true, LowBattery: true,
+	}, upses[0])
+	assert.Equal(t, "rack1@"+server, upses[0].ID())
+}
+
+func TestQueryNUT_Unreachable(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	address := listener.Addr().String()
+	listener.Close()
+
+	_, err = queryNUT(context.Background(), address)
+	assert.Error(t, err)
+}
+
+func TestCollector_PowerMetrics(t *testing.T) {
+	oldRoot := powerSupplyRoot
+	powerSupplyRoot = filepath.Join(t.TempDir(), "none")
+	t.Cleanup(func() { powerSupplyRoot = oldRoot })
+
+	vars := map[string]string{"battery.charge": "100", "battery.runtime": "1800", "ups.status": "OL"}
+	server := fakeUPSD(t, map[string]map[string]string{"rack1": vars})
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	down := listener.Addr().String()
+	listener.Close()
+
+	clk := clock.NewFake(epoch)
+	config := DefaultConfig()
+	config.Clock = clk
+	config.UPSServers = []string{server, down}
+	c := NewCollector(config)
+
+	require.NoError(t, c.collectPowerMetrics(context.Background()), "an unreachable server does not fail the collection")
+	m := c.GetPowerMetrics()
+	require.NotNil(t, m)
+	require.Len(t, m.UPSes, 1)
+	assert.False(t, m.UPSes[0].OnBattery)
+	assert.Contains(t, m.ServerErrors, down)
+
+	// The outage starts, and is timed across collections
+	vars["ups.status"] = "OB DISCHRG"
+	clk.Advance(time.Second)
+	require.NoError(t, c.collectPowerMetrics(context.Background()))
+	clk.Advance(6 * time.Minute)
+	require.NoError(t, c.collectPowerMetrics(context.Background()))
+	assert.Equal(t, 360.0, c.GetPowerMetrics().UPSes[0].OnBatterySeconds)
+
+	vars["ups.status"] = "OL CHRG"
+	clk.Advance(time.Second)
+	require.NoError(t, c.collectPowerMetrics(context.Background()))
+	assert.Zero(t, c.GetPowerMetrics().UPSes[0].OnBatterySeconds)
+	assert.Empty(t, c.onBatterySince)
+}
+
+func TestCollector_TrackOnBatteryKeepsFailedServers(t *testing.T) {
+	c := NewCollector(DefaultConfig())
+	m := &PowerMetrics{UPSes: []UPS{{Name: "a", Server: "s1", OnBattery: true}}, UpdatedAt: epoch}
+	c.trackOnBattery(m)
+
+	m = &PowerMetrics{ServerErrors: map[string]string{"s1": "timeout"}, UpdatedAt: epoch.Add(time.Minute)}
+	c.trackOnBattery(m)
+	m = &PowerMetrics{UPSes: []UPS{{Name: "a", Server: "s1", OnBattery: true}}, UpdatedAt: epoch.Add(2 * time.Minute)}
+	c.trackOnBattery(m)
+	assert.Equal(t, 120.0, m.UPSes[0].OnBatterySeconds, "a missed query does not restart the outage")
+}
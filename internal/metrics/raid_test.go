@@ -0,0 +1,186 @@
+package metrics
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"argus/internal/clock"
+)
+
+const mdstatFixture = `Personalities : [raid1] [raid6] [raid5] [raid4] [raid0]
+md0 : active raid1 sdb1[1] sda1[0]
+      1047552 blocks super 1.2 [2/2] [UU]
+      bitmap: 0/1 pages [0KB], 65536KB chunk
+
+md1 : active raid5 sde1[4](S) sdd1[3](F) sdc1[1] sdb2[0]
+      2095104 blocks super 1.2 level 5, 512k chunk, algorithm 2 [3/2] [UU_]
+      [=>...................]  recovery =  8.5% (89600/1047552) finish=1.2min speed=12800K/sec
+
+md2 : active (auto-read-only) raid0 sdf1[1] sdg1[0]
+      2095104 blocks super 1.2 512k chunks
+
+md3 : inactive sdh1[0](S)
+      1048576 blocks super 1.2
+
+md4 : active raid1 sdi1[1] sdj1[0]
+      1047552 blocks super 1.2 [2/2] [UU]
+      [===>.................]  check = 17.3% (181248/1047552) finish=0.5min speed=30208K/sec
+
+unused devices: <none>
+`
+
+func TestParseMDStat(t *testing.T) {
+	arrays, err := parseMDStat(strings.NewReader(mdstatFixture))
+	require.NoError(t, err)
+	require.Len(t, arrays, 5)
+
+	assert.Equal(t, RAIDArray{
+		Name: "md0", Level: "raid1", State: "active",
+		Devices: []string{"sdb1", "sda1"}, FailedDevices: []string{}, SpareDevices: []string{},
+		TotalDevices: 2, ActiveDevices: 2,
+	}, arrays[0])
+
+	degraded := arrays[1]
+	assert.True(t, degraded.Degraded)
+	assert.Equal(t, []string{"sdd1"}, degraded.FailedDevices)
+	assert.Equal(t, []string{"sde1"}, degraded.SpareDevices)
+	assert.Equal(t, 3, degraded.TotalDevices)
+	assert.Equal(t, 2, degraded.ActiveDevices)
+	assert.Equal(t, "recovery", degraded.SyncAction)
+	assert.InDelta(t, 8.5, degraded.SyncPercent, 0.001)
+	assert.True(t, degraded.Rebuilding())
+
+	assert.Equal(t, "active (auto-read-only)", arrays[2].State)
+	assert.Equal(t, "raid0", arrays[2].Level)
+	assert.False(t, arrays[2].Degraded, "striped arrays have no count line")
+	assert.Equal(t, 2, arrays[2].ActiveDevices)
+
+	assert.True(t, arrays[3].Degraded, "inactive arrays are degraded")
+	assert.Empty(t, arrays[3].Level)
+
+	assert.False(t, arrays[4].Degraded)
+	assert.Equal(t, "check", arrays[4].SyncAction)
+	assert.False(t, arrays[4].Rebuilding(), "a check is not a rebuild")
+}
+
+func TestReadMDStat_Missing(t *testing.T) {
+	arrays, err := readMDStat(filepath.Join(t.TempDir(), "mdstat"))
+	require.NoError(t, err, "no md driver means no arrays")
+	assert.Empty(t, arrays)
+}
+
+const zpoolStatusFixture = `  pool: backup
+ state: ONLINE
+  scan: scrub repaired 0B in 00:10:02 with 0 errors on Sun Oct 11 00:34:03 2026
+config:
+
+	NAME        STATE     READ WRITE CKSUM
+	backup      ONLINE       0     0     0
+	  sdc       ONLINE       0     0     0
+
+errors: No known data errors
+
+  pool: tank
+ state: DEGRADED
+status: One or more devices is currently being resilvered.
+  scan: resilver in progress since Wed Oct 14 09:12:40 2026
+	1.23T scanned at 1.02G/s, 650G issued at 540M/s, 2.40T total
+	108G resilvered, 26.45% done, 00:56:40 to go
+config:
+
+	NAME          STATE     READ WRITE CKSUM
+	tank          DEGRADED     0     0     0
+	  raidz1-0    DEGRADED     0     0     0
+	    sda       ONLINE       0     0     0
+	    sdb       FAULTED      3   210     0  too many errors
+	    sdd       ONLINE       0     0     0  (resilvering)
+
+errors: No known data errors
+`
+
+func TestParseZpool(t *testing.T) {
+	pools := parseZpoolList([]byte("backup\t1000\t250\t750\t25\tONLINE\ntank\t4000\t3600\t400\t90\tDEGRADED\n"))
+	require.Len(t, pools, 2)
+	applyZpoolStatus(pools, []byte(zpoolStatusFixture))
+
+	assert.Equal(t, ZFSPool{
+		Name: "backup", Health: "ONLINE", Size: 1000, Allocated: 250, Free: 750, CapacityPercent: 25,
+		FailedDevices: []string{},
+	}, pools[0], "a finished scrub is no scan in progress")
+
+	tank := pools[1]
+	assert.True(t, tank.Degraded)
+	assert.Equal(t, 90.0, tank.CapacityPercent)
+	assert.Equal(t, []string{"sdb"}, tank.FailedDevices)
+	assert.Equal(t, "resilver", tank.ScanAction)
+	assert.InDelta(t, 26.45, tank.ScanPercent, 0.001)
+	assert.True(t, tank.Rebuilding())
+}
+
+// fakeZFS points the ZFS sources at a fake zpool script
+func fakeZFS(t *testing.T, list, status string) {
+	t.Helper()
+	dir := t.TempDir()
+	script := filepath.Join(dir, "zpool")
+	writeFile(t, filepath.Join(dir, "list.out"), list)
+	writeFile(t, filepath.Join(dir, "status.out"), status)
+	require.NoError(t, os.WriteFile(script, []byte("#!/bin/sh\ncat \""+dir+"/$1.out\"\n"), 0o755))
+	writeFile(t, filepath.Join(dir, "zfs"), "")
+
+	oldCommand, oldDevice := zpoolCommand, zfsDevice
+	zpoolCommand, zfsDevice = script, filepath.Join(dir, "zfs")
+	t.Cleanup(func() { zpoolCommand, zfsDevice = oldCommand, oldDevice })
+}
+
+func TestZFSPools(t *testing.T) {
+	fakeZFS(t, "tank\t4000\t3600\t400\t90\tDEGRADED\n", zpoolStatusFixture)
+	pools, err := zfsPools(context.Background())
+	require.NoError(t, err)
+	require.Len(t, pools, 1)
+	assert.Equal(t, []string{"sdb"}, pools[0].FailedDevices)
+
+	zfsDevice = filepath.Join(t.TempDir(), "zfs")
+	pools, err = zfsPools(context.Background())
+	require.NoError(t, err, "hosts without the ZFS module have no pools")
+	assert.Empty(t, pools)
+}
+
+func TestCollector_RAIDMetrics(t *testing.T) {
+	mdstat := filepath.Join(t.TempDir(), "mdstat")
+	writeFile(t, mdstat, mdstatFixture)
+	oldPath := mdstatPath
+	mdstatPath = mdstat
+	t.Cleanup(func() { mdstatPath = oldPath })
+	fakeZFS(t, "tank\t4000\t3600\t400\t90\tDEGRADED\n", zpoolStatusFixture)
+
+	clk := clock.NewFake(epoch)
+	config := DefaultConfig()
+	config.Clock = clk
+	c := NewCollector(config)
+	store := NewSeriesStore(SeriesStoreConfig{Retention: time.Hour, Clock: clk})
+	c.SetHistory(store)
+
+	require.NoError(t, c.collectRAIDMetrics(context.Background()))
+	m := c.GetRAIDMetrics()
+	require.NotNil(t, m)
+	assert.Len(t, m.Arrays, 5)
+	assert.Equal(t, 3, m.Degraded(), "md1, md3 and tank")
+
+	c.recordHistory()
+	degraded := store.Latest(HistoryRAIDDegraded, map[string]string{"array": "md1"})
+	require.Len(t, degraded, 1)
+	assert.Equal(t, 1.0, degraded[0].Latest.Value)
+	capacity := store.Latest(HistoryZFSCapacityPercent, map[string]string{"pool": "tank"})
+	require.Len(t, capacity, 1)
+	assert.Equal(t, 90.0, capacity[0].Latest.Value)
+
+	clk.Advance(2 * config.CacheTTL)
+	assert.Nil(t, c.GetRAIDMetrics(), "expired")
+}
@@ -0,0 +1,499 @@
+// File: internal/metrics/rollup.go
+// Brief: Downsampled rollups of the metric history
+// Detailed: Aggregates the raw samples of a series store into min/max/avg buckets at coarser resolutions (1m, 5m and 1h by default) in the background, each resolution built from the one below it and kept for its own retention, and answers range queries at the resolution suited to the requested range and step.
+// Author: drama.lin@aver.com
+// Date: 2026-10-14
+
+package metrics
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"argus/internal/clock"
+	"argus/internal/retention"
+)
+
+// RawResolution is the resolution reported for queries answered from raw samples
+const RawResolution time.Duration = 0
+
+// Aggregate summarizes the samples of a series within one bucket
+type Aggregate struct {
+	Timestamp time.Time `json:"timestamp"` // Start of the bucket
+	Min       float64   `json:"min"`
+	Max       float64   `json:"max"`
+	Avg       float64   `json:"avg"`
+	Count     int       `json:"count"` // Raw samples in the bucket
+}
+
+// Merge folds other into a, keeping a's timestamp
+func (a *Aggregate) Merge(other Aggregate) {
+	if a.Count == 0 {
+		*a = Aggregate{Timestamp: a.Timestamp, Min: other.Min, Max: other.Max, Avg: other.Avg, Count: other.Count}
+		return
+	}
+	a.Min = min(a.Min, other.Min)
+	a.Max = max(a.Max, other.Max)
+	total := a.Count + other.Count
+	a.Avg = (a.Avg*float64(a.Count) + other.Avg*float64(other.Count)) / float64(total)
+	a.Count = total
+}
+
+// AggregateSeries is a series summarized at one resolution, ordered by time
+type AggregateSeries struct {
+	Name       string            `json:"name"`
+	Labels     map[string]string `json:"labels,omitempty"`
+	Resolution time.Duration     `json:"resolution"`
+	Points     []Aggregate       `json:"points"`
+}
+
+// Resolution is a rollup step and how long its buckets are kept
+type Resolution struct {
+	Step      time.Duration
+	Retention time.Duration
+}
+
+// RollupConfig holds configuration for the rollups
+type RollupConfig struct {
+	Resolutions []Resolution  // Each step must be a multiple of the previous one
+	Interval    time.Duration // How often completed buckets are rolled up
+	Delay       time.Duration // Grace for late samples before a bucket is closed
+	Clock       clock.Clock   // Time source (nil uses the real clock)
+}
+
+// DefaultRollupConfig returns default configuration for the rollups
+func DefaultRollupConfig() RollupConfig {
+	return RollupConfig{
+		Resolutions: []Resolution{
+			{Step: time.Minute, Retention: 7 * 24 * time.Hour},
+			{Step: 5 * time.Minute, Retention: 30 * 24 * time.Hour},
+			{Step: time.Hour, Retention: 365 * 24 * time.Hour},
+		},
+		Interval: 30 * time.Second,
+		Delay:    30 * time.Second,
+	}
+}
+
+// ResolutionStats reports the stored buckets of one resolution
+type ResolutionStats struct {
+	Step      string     `json:"step"`
+	Retention string     `json:"retention"`
+	Series    int        `json:"series"`
+	Points    int        `json:"points"`
+	Oldest    *time.Time `json:"oldest,omitempty"`
+}
+
+// RollupStats reports rollup usage for self-metrics
+type RollupStats struct {
+	Resolutions []ResolutionStats `json:"resolutions"`
+	LastRun     *time.Time        `json:"last_run,omitempty"`
+}
+
+// rollupLevel is one series at one resolution
+type rollupLevel struct {
+	points []Aggregate
+	rolled time.Time // End of the last closed bucket
+}
+
+// rollupSeries is one source series at every resolution
+type rollupSeries struct {
+	name   string
+	labels map[string]string
+	levels []rollupLevel
+}
+
+// Rollups keeps downsampled copies of the series in a store
+type Rollups struct {
+	config RollupConfig
+	clock  clock.Clock
+	source *SeriesStore
+
+	mu      sync.RWMutex
+	series  map[string]*rollupSeries
+	lastRun time.Time
+}
+
+// ValidateResolutions checks that steps are positive and ascending, each a
+// multiple of the previous one
+func ValidateResolutions(resolutions []Resolution) error {
+	for i, r := range resolutions {
+		if r.Step <= 0 {
+			return fmt.Errorf("rollup step %s must be positive", r.Step)
+		}
+		if i > 0 && (r.Step <= resolutions[i-1].Step || r.Step%resolutions[i-1].Step != 0) {
+			return fmt.Errorf("rollup step %s must be a multiple of %s", r.Step, resolutions[i-1].Step)
+		}
+	}
+	return nil
+}
+
+// NewRollups creates rollups of the series in source. Resolutions are sorted
+// by step.
+func NewRollups(source *SeriesStore, config RollupConfig) (*Rollups, error) {
+	defaults := DefaultRollupConfig()
+	if config.Interval <= 0 {
+		config.Interval = defaults.Interval
+	}
+	if config.Delay < 0 {
+		config.Delay = 0
+	}
+	resolutions := append([]Resolution(nil), config.Resolutions...)
+	sort.Slice(resolutions, func(i, j int) bool { return resolutions[i].Step < resolutions[j].Step })
+	if err := ValidateResolutions(resolutions); err != nil {
+		return nil, err
+	}
+	config.Resolutions = resolutions
+	return &Rollups{
+		config: config,
+		clock:  clock.OrReal(config.Clock),
+		source: source,
+		series: make(map[string]*rollupSeries),
+	}, nil
+}
+
+// Resolutions returns the configured resolutions, finest first
+func (r *Rollups) Resolutions() []Resolution {
+	return append([]Resolution(nil), r.config.Resolutions...)
+}
+
+// bucketize aggregates samples into buckets of step, in time order
+func bucketize(samples []Sample, step time.Duration) []Aggregate {
+	var points []Aggregate
+	for _, s := range samples {
+		start := s.Timestamp.Truncate(step)
+		if n := len(points); n == 0 || !points[n-1].Timestamp.Equal(start) {
+			points = append(points, Aggregate{Timestamp: start})
+		}
+		points[len(points)-1].Merge(Aggregate{Min: s.Value, Max: s.Value, Avg: s.Value, Count: 1})
+	}
+	return points
+}
+
+// regroup merges finer aggregates into buckets of step, in time order
+func regroup(finer []Aggregate, step time.Duration) []Aggregate {
+	var points []Aggregate
+	for _, a := range finer {
+		start := a.Timestamp.Truncate(step)
+		if n := len(points); n == 0 || !points[n-1].Timestamp.Equal(start) {
+			points = append(points, Aggregate{Timestamp: start})
+		}
+		points[len(points)-1].Merge(a)
+	}
+	return points
+}
+
+// samplesBetween returns the samples in [from, to)
+func samplesBetween(samples []Sample, from, to time.Time) []Sample {
+	start := sort.Search(len(samples), func(i int) bool { return !samples[i].Timestamp.Before(from) })
+	end := sort.Search(len(samples), func(i int) bool { return !samples[i].Timestamp.Before(to) })
+	return samples[start:end]
+}
+
+// aggregatesBetween returns the aggregates starting in [from, to)
+func aggregatesBetween(points []Aggregate, from, to time.Time) []Aggregate {
+	start := sort.Search(len(points), func(i int) bool { return !points[i].Timestamp.Before(from) })
+	end := sort.Search(len(points), func(i int) bool { return !points[i].Timestamp.Before(to) })
+	return points[start:end]
+}
+
+// Compute rolls up every bucket closed since the last run and drops buckets
+// past their resolution's retention. It returns the number of buckets added.
+func (r *Rollups) Compute() int {
+	now := r.clock.Now()
+	closeBefore := now.Add(-r.config.Delay)
+
+	r.source.mu.RLock()
+	defer r.source.mu.RUnlock()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for key, source := range r.source.series {
+		if _, ok := r.series[key]; !ok && len(source.Samples) > 0 {
+			r.series[key] = &rollupSeries{name: source.Name, labels: source.Labels, levels: make([]rollupLevel, len(r.config.Resolutions))}
+		}
+	}
+
+	added := 0
+	for key, rs := range r.series {
+		// Series pruned from the source still close their coarser buckets
+		var raw []Sample
+		if source, ok := r.source.series[key]; ok {
+			raw = source.Samples
+		}
+		for i, res := range r.config.Resolutions {
+			level := &rs.levels[i]
+			closed := closeBefore.Truncate(res.Step)
+			if i > 0 {
+				// Only buckets the finer level has closed are complete
+				closed = minTime(closed, rs.levels[i-1].rolled.Truncate(res.Step))
+			}
+			if !closed.After(level.rolled) {
+				continue
+			}
+			var points []Aggregate
+			if i == 0 {
+				points = bucketize(samplesBetween(raw, level.rolled, closed), res.Step)
+			} else {
+				points = regroup(aggregatesBetween(rs.levels[i-1].points, level.rolled, closed), res.Step)
+			}
+			level.points = append(level.points, points...)
+			level.rolled = closed
+			added += len(points)
+		}
+	}
+
+	r.trim(now)
+	r.lastRun = now
+	return added
+}
+
+// trim drops buckets past their retention and series left empty. The caller
+// must hold r.mu.
+func (r *Rollups) trim(now time.Time) {
+	for key, rs := range r.series {
+		empty := true
+		for i, res := range r.config.Resolutions {
+			level := &rs.levels[i]
+			if res.Retention > 0 {
+				level.points = trimAggregates(level.points, now.Add(-res.Retention))
+			}
+			if len(level.points) > 0 {
+				empty = false
+			}
+		}
+		if _, ok := r.source.series[key]; !ok && empty {
+			delete(r.series, key)
+		}
+	}
+}
+
+// trimAggregates drops the buckets starting before cutoff
+func trimAggregates(points []Aggregate, cutoff time.Time) []Aggregate {
+	start := sort.Search(len(points), func(i int) bool { return !points[i].Timestamp.Before(cutoff) })
+	if start == 0 {
+		return points
+	}
+	return append(points[:0:0], points[start:]...)
+}
+
+func minTime(a, b time.Time) time.Time {
+	if b.Before(a) {
+		return b
+	}
+	return a
+}
+
+// Start computes the rollups every interval until ctx is cancelled
+func (r *Rollups) Start(ctx context.Context) {
+	go func() {
+		ticker := r.clock.NewTicker(r.config.Interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C():
+				r.Compute()
+			}
+		}
+	}()
+}
+
+// ChooseResolution picks the resolution for a query over [from, now] at the
+// given step: the coarsest one not coarser than step whose data reaches back
+// to from. When none qualifies, data availability wins over step: the finest
+// resolution reaching back to from, or else the longest kept one.
+func (r *Rollups) ChooseResolution(from time.Time, step time.Duration) time.Duration {
+	now := r.clock.Now()
+	// Without a start only data kept forever covers the range
+	covers := func(keep time.Duration) bool {
+		return keep <= 0 || (!from.IsZero() && !from.Before(now.Add(-keep)))
+	}
+
+	best, found := RawResolution, false
+	if covers(r.source.config.Retention) && step < r.firstStep() {
+		best, found = RawResolution, true
+	}
+	for _, res := range r.config.Resolutions {
+		if res.Step <= step && covers(res.Retention) {
+			best, found = res.Step, true
+		}
+	}
+	if found {
+		return best
+	}
+	if covers(r.source.config.Retention) {
+		return RawResolution
+	}
+	for _, res := range r.config.Resolutions {
+		if covers(res.Retention) {
+			return res.Step
+		}
+	}
+	if n := len(r.config.Resolutions); n > 0 {
+		return r.config.Resolutions[n-1].Step
+	}
+	return RawResolution
+}
+
+// firstStep returns the finest rollup step, or an unbounded one without rollups
+func (r *Rollups) firstStep() time.Duration {
+	if len(r.config.Resolutions) == 0 {
+		return time.Duration(1<<63 - 1)
+	}
+	return r.config.Resolutions[0].Step
+}
+
+// Query returns the series called name whose labels match selector within
+// [from, to] at the resolution chosen for step, and that resolution. Buckets
+// not rolled up yet are aggregated from the raw samples. A zero from or to is
+// unbounded.
+func (r *Rollups) Query(name string, selector map[string]string, from, to time.Time, step time.Duration) ([]AggregateSeries, time.Duration) {
+	resolution := r.ChooseResolution(from, step)
+	level := -1
+	for i, res := range r.config.Resolutions {
+		if res.Step == resolution {
+			level = i
+		}
+	}
+
+	// Buckets are kept when they overlap the range
+	start := from
+	if resolution > 0 {
+		start = from.Truncate(resolution)
+	}
+	inRange := func(t time.Time) bool {
+		return (from.IsZero() || !t.Before(start)) && (to.IsZero() || !t.After(to))
+	}
+
+	r.source.mu.RLock()
+	defer r.source.mu.RUnlock()
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	type entry struct {
+		name        string
+		labels      map[string]string
+		raw         []Sample
+		rolled      []Aggregate
+		rolledUntil time.Time
+	}
+	entries := make(map[string]*entry)
+	for key, series := range r.source.series {
+		if series.Name == name && matches(series.Labels, selector) {
+			entries[key] = &entry{name: series.Name, labels: series.Labels, raw: series.Samples}
+		}
+	}
+	if level >= 0 {
+		for key, rs := range r.series {
+			if rs.name != name || !matches(rs.labels, selector) {
+				continue
+			}
+			e, ok := entries[key]
+			if !ok {
+				e = &entry{name: rs.name, labels: rs.labels}
+				entries[key] = e
+			}
+			e.rolled = rs.levels[level].points
+			e.rolledUntil = rs.levels[level].rolled
+		}
+	}
+
+	result := make([]AggregateSeries, 0, len(entries))
+	for _, e := range entries {
+		series := AggregateSeries{Name: e.name, Labels: e.labels, Resolution: resolution, Points: []Aggregate{}}
+		var points []Aggregate
+		if level < 0 {
+			points = make([]Aggregate, len(e.raw))
+			for i, sample := range e.raw {
+				points[i] = Aggregate{Timestamp: sample.Timestamp, Min: sample.Value, Max: sample.Value, Avg: sample.Value, Count: 1}
+			}
+		} else {
+			// The buckets not closed yet come from the raw samples
+			start := sort.Search(len(e.raw), func(i int) bool { return !e.raw[i].Timestamp.Before(e.rolledUntil) })
+			points = append(append(points, e.rolled...), bucketize(e.raw[start:], resolution)...)
+		}
+		for _, p := range points {
+			if inRange(p.Timestamp) {
+				series.Points = append(series.Points, p)
+			}
+		}
+		result = append(result, series)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return seriesKey(result[i].Name, result[i].Labels) < seriesKey(result[j].Name, result[j].Labels)
+	})
+	return result, resolution
+}
+
+// PurgeBefore drops buckets starting before before at every resolution,
+// regardless of their retention. It returns the number of buckets dropped.
+func (r *Rollups) PurgeBefore(before time.Time) int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	purged := 0
+	for key, rs := range r.series {
+		empty := true
+		for i := range rs.levels {
+			kept := trimAggregates(rs.levels[i].points, before)
+			purged += len(rs.levels[i].points) - len(kept)
+			rs.levels[i].points = kept
+			if len(kept) > 0 {
+				empty = false
+			}
+		}
+		if empty {
+			delete(r.series, key)
+		}
+	}
+	return purged
+}
+
+// Usage reports the number of stored buckets and the oldest one's start
+func (r *Rollups) Usage() retention.Usage {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	var usage retention.Usage
+	for _, rs := range r.series {
+		for _, level := range rs.levels {
+			if len(level.points) == 0 {
+				continue
+			}
+			usage.Items += len(level.points)
+			if first := level.points[0].Timestamp; usage.Oldest == nil || first.Before(*usage.Oldest) {
+				usage.Oldest = &first
+			}
+		}
+	}
+	return usage
+}
+
+// Stats returns the stored buckets per resolution
+func (r *Rollups) Stats() RollupStats {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	st := RollupStats{Resolutions: make([]ResolutionStats, len(r.config.Resolutions))}
+	for i, res := range r.config.Resolutions {
+		rs := ResolutionStats{Step: res.Step.String(), Retention: res.Retention.String()}
+		for _, series := range r.series {
+			level := series.levels[i]
+			if len(level.points) == 0 {
+				continue
+			}
+			rs.Series++
+			rs.Points += len(level.points)
+			if first := level.points[0].Timestamp; rs.Oldest == nil || first.Before(*rs.Oldest) {
+				rs.Oldest = &first
+			}
+		}
+		st.Resolutions[i] = rs
+	}
+	if !r.lastRun.IsZero() {
+		lastRun := r.lastRun
+		st.LastRun = &lastRun
+	}
+	return st
+}
@@ -0,0 +1,138 @@
+package metrics
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"argus/internal/clock"
+)
+
+// appendEvery adds one sample every interval in [from, to) with values 0, 1, 2, ...
+func appendEvery(t *testing.T, store *SeriesStore, name string, labels map[string]string, from, to time.Time, interval time.Duration) {
+	t.Helper()
+	var samples []Sample
+	for ts, v := from, 0.0; ts.Before(to); ts, v = ts.Add(interval), v+1 {
+		samples = append(samples, Sample{Timestamp: ts, Value: v})
+	}
+	require.NoError(t, store.Append(name, labels, samples))
+}
+
+func newTestRollups(t *testing.T, clk *clock.Fake, rawRetention time.Duration) (*SeriesStore, *Rollups) {
+	t.Helper()
+	store := NewSeriesStore(SeriesStoreConfig{Retention: rawRetention, Clock: clk})
+	config := DefaultRollupConfig()
+	config.Delay = 0
+	config.Clock = clk
+	rollups, err := NewRollups(store, config)
+	require.NoError(t, err)
+	return store, rollups
+}
+
+func TestRollups_ComputeAggregatesClosedBuckets(t *testing.T) {
+	clk := clock.NewFake(epoch.Add(2*time.Hour + 30*time.Second))
+	store, rollups := newTestRollups(t, clk, 24*time.Hour)
+	appendEvery(t, store, "cpu", nil, epoch, clk.Now(), 5*time.Second)
+
+	assert.Positive(t, rollups.Compute())
+
+	series, resolution := rollups.Query("cpu", nil, epoch, epoch.Add(time.Hour), time.Minute)
+	assert.Equal(t, time.Minute, resolution)
+	require.Len(t, series, 1)
+	first := series[0].Points[0]
+	assert.Equal(t, epoch, first.Timestamp)
+	assert.Equal(t, 12, first.Count)
+	assert.Equal(t, 0.0, first.Min)
+	assert.Equal(t, 11.0, first.Max)
+	assert.InDelta(t, 5.5, first.Avg, 1e-9)
+
+	// The 5m buckets are built from the 1m ones and match the raw samples
+	series, resolution = rollups.Query("cpu", nil, epoch, epoch.Add(time.Hour), 5*time.Minute)
+	assert.Equal(t, 5*time.Minute, resolution)
+	require.Len(t, series, 1)
+	assert.Len(t, series[0].Points, 13) // Both ends included
+	assert.Equal(t, 60, series[0].Points[0].Count)
+	assert.InDelta(t, 29.5, series[0].Points[0].Avg, 1e-9)
+	assert.Equal(t, 59.0, series[0].Points[0].Max)
+
+	hourly, resolution := rollups.Query("cpu", nil, epoch, clk.Now(), time.Hour)
+	assert.Equal(t, time.Hour, resolution)
+	require.Len(t, hourly, 1)
+	points := hourly[0].Points
+	require.Len(t, points, 3)
+	assert.Equal(t, 720, points[0].Count)
+	assert.Equal(t, 720, points[1].Count)
+	// The open hour comes from the raw samples
+	assert.Equal(t, 6, points[2].Count)
+	assert.Equal(t, epoch.Add(2*time.Hour), points[2].Timestamp)
+
+	// A second run adds nothing until another bucket closes
+	assert.Zero(t, rollups.Compute())
+	clk.Advance(time.Minute)
+	appendEvery(t, store, "cpu", nil, epoch.Add(2*time.Hour+30*time.Second), clk.Now(), 5*time.Second)
+	assert.Equal(t, 1, rollups.Compute())
+}
+
+func TestRollups_ChooseResolution(t *testing.T) {
+	clk := clock.NewFake(epoch.Add(400 * 24 * time.Hour))
+	_, rollups := newTestRollups(t, clk, 24*time.Hour)
+	now := clk.Now()
+
+	// Short range and fine step: raw samples
+	assert.Equal(t, RawResolution, rollups.ChooseResolution(now.Add(-time.Hour), 15*time.Second))
+	// Coarsest resolution not coarser than the step
+	assert.Equal(t, time.Minute, rollups.ChooseResolution(now.Add(-6*time.Hour), 2*time.Minute))
+	assert.Equal(t, 5*time.Minute, rollups.ChooseResolution(now.Add(-6*time.Hour), 30*time.Minute))
+	assert.Equal(t, time.Hour, rollups.ChooseResolution(now.Add(-6*time.Hour), 6*time.Hour))
+	// Past the raw retention a fine step still needs a rollup
+	assert.Equal(t, time.Minute, rollups.ChooseResolution(now.Add(-48*time.Hour), 10*time.Second))
+	// Past the 1m retention only coarser data exists
+	assert.Equal(t, 5*time.Minute, rollups.ChooseResolution(now.Add(-10*24*time.Hour), time.Minute))
+	// Past every retention the longest kept resolution is served
+	assert.Equal(t, time.Hour, rollups.ChooseResolution(now.Add(-390*24*time.Hour), time.Minute))
+}
+
+func TestRollups_OutliveRawSamples(t *testing.T) {
+	clk := clock.NewFake(epoch.Add(time.Hour))
+	store, rollups := newTestRollups(t, clk, time.Hour)
+	labels := map[string]string{"mountpoint": "/"}
+	appendEvery(t, store, "disk", labels, epoch, clk.Now(), 5*time.Second)
+	rollups.Compute()
+
+	// The raw samples expire, the rollups remain
+	clk.Advance(3 * time.Hour)
+	store.Prune()
+	assert.Empty(t, store.Range("disk", nil, time.Time{}, time.Time{}))
+	rollups.Compute()
+
+	series, resolution := rollups.Query("disk", labels, epoch, epoch.Add(time.Hour), time.Minute)
+	assert.Equal(t, time.Minute, resolution)
+	require.Len(t, series, 1)
+	assert.Len(t, series[0].Points, 60)
+	assert.Equal(t, labels, series[0].Labels)
+
+	hourly, _ := rollups.Query("disk", nil, epoch, epoch.Add(time.Hour), time.Hour)
+	require.Len(t, hourly, 1)
+	require.NotEmpty(t, hourly[0].Points)
+	assert.Equal(t, 720, hourly[0].Points[0].Count)
+
+	usage := rollups.Usage()
+	assert.Equal(t, 60+12+1, usage.Items)
+	assert.Equal(t, 73, rollups.PurgeBefore(clk.Now()))
+	assert.Zero(t, rollups.Usage().Items)
+}
+
+func TestNewRollups_RejectsIncompatibleSteps(t *testing.T) {
+	store := NewSeriesStore(DefaultSeriesStoreConfig())
+	_, err := NewRollups(store, RollupConfig{Resolutions: []Resolution{{Step: time.Minute}, {Step: 90 * time.Second}}})
+	assert.Error(t, err)
+	_, err = NewRollups(store, RollupConfig{Resolutions: []Resolution{{Step: 0}}})
+	assert.Error(t, err)
+
+	// Resolutions are sorted before checking
+	rollups, err := NewRollups(store, RollupConfig{Resolutions: []Resolution{{Step: time.Hour}, {Step: time.Minute}}})
+	require.NoError(t, err)
+	assert.Equal(t, time.Minute, rollups.Resolutions()[0].Step)
+}
@@ -0,0 +1,377 @@
+// File: internal/metrics/series.go
+// Brief: In-memory history of externally ingested metric series
+// Detailed: Stores labelled time series pushed to Argus (e.g. by Prometheus remote-write) with a retention window and a cap on the number of series, and answers latest-value and range queries for alert thresholds and dashboards.
+// Author: drama.lin@aver.com
+// Date: 2026-10-14
+
+package metrics
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"argus/internal/clock"
+	"argus/internal/retention"
+)
+
+// ErrTooManySeries is returned when appending would exceed MaxSeries
+var ErrTooManySeries = errors.New("series limit reached")
+
+// Sample is one value of a series
+type Sample struct {
+	Timestamp time.Time `json:"timestamp"`
+	Value     float64   `json:"value"`
+}
+
+// Series is a metric name, its labels and samples ordered by time
+type Series struct {
+	Name    string            `json:"name"`
+	Labels  map[string]string `json:"labels,omitempty"`
+	Samples []Sample          `json:"samples"`
+}
+
+// SeriesInfo summarizes a stored series
+type SeriesInfo struct {
+	Name    string            `json:"name"`
+	Labels  map[string]string `json:"labels,omitempty"`
+	Samples int               `json:"samples"`
+	Latest  Sample            `json:"latest"`
+}
+
+// SeriesStoreStats reports series store usage for self-metrics
+type SeriesStoreStats struct {
+	Series   int    `json:"series"`
+	Samples  int    `json:"samples"`
+	Accepted uint64 `json:"accepted"`
+	Dropped  uint64 `json:"dropped"` // Samples rejected by the series limit or outside retention
+}
+
+// SeriesStoreConfig holds configuration for the series store
+type SeriesStoreConfig struct {
+	Retention           time.Duration // How long samples are kept
+	MaxSeries           int           // Maximum number of distinct series (0 means unlimited)
+	MaxSamplesPerSeries int           // Oldest samples are dropped beyond this (0 means unlimited)
+	Clock               clock.Clock   // Time source (nil uses the real clock)
+}
+
+// DefaultSeriesStoreConfig returns default configuration for the series store
+func DefaultSeriesStoreConfig() SeriesStoreConfig {
+	return SeriesStoreConfig{
+		Retention:           time.Hour,
+		MaxSeries:           10000,
+		MaxSamplesPerSeries: 1000,
+	}
+}
+
+// SeriesStore keeps ingested series in memory
+type SeriesStore struct {
+	config SeriesStoreConfig
+	clock  clock.Clock
+
+	mu     sync.RWMutex
+	series map[string]*Series
+
+	accepted atomic.Uint64
+	dropped  atomic.Uint64
+}
+
+// NewSeriesStore creates an empty series store
+func NewSeriesStore(config SeriesStoreConfig) *SeriesStore {
+	if config.Retention <= 0 {
+		config.Retention = DefaultSeriesStoreConfig().Retention
+	}
+	return &SeriesStore{
+		config: config,
+		clock:  clock.OrReal(config.Clock),
+		series: make(map[string]*Series),
+	}
+}
+
+// seriesKey identifies a series by its name and sorted labels
+func seriesKey(name string, labels map[string]string) string {
+	keys := make([]string, 0, len(labels))
+	for k := range labels {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var b strings.Builder
+	b.WriteString(name)
+	for _, k := range keys {
+		b.WriteByte(0)
+		b.WriteString(k)
+		b.WriteByte('=')
+		b.WriteString(labels[k])
+	}
+	return b.String()
+}
+
+// matches reports whether labels contain every pair in selector
+func matches(labels, selector map[string]string) bool {
+	for k, v := range selector {
+		if labels[k] != v {
+			return false
+		}
+	}
+	return true
+}
+
+// Append adds samples to the series identified by name and labels, creating
+// it if needed. Samples older than the retention window or not newer than the
+// last stored sample are dropped.
+func (s *SeriesStore) Append(name string, labels map[string]string, samples []Sample) error {
+	if len(samples) == 0 {
+		return nil
+	}
+	cutoff := s.clock.Now().Add(-s.config.Retention)
+	key := seriesKey(name, labels)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	series, ok := s.series[key]
+	if !ok {
+		if s.config.MaxSeries > 0 && len(s.series) >= s.config.MaxSeries {
+			s.dropped.Add(uint64(len(samples)))
+			return ErrTooManySeries
+		}
+		copied := make(map[string]string, len(labels))
+		for k, v := range labels {
+			copied[k] = v
+		}
+		series = &Series{Name: name, Labels: copied}
+		s.series[key] = series
+	}
+
+	for _, sample := range samples {
+		last := len(series.Samples) - 1
+		if sample.Timestamp.Before(cutoff) || (last >= 0 && !sample.Timestamp.After(series.Samples[last].Timestamp)) {
+			s.dropped.Add(1)
+			continue
+		}
+		series.Samples = append(series.Samples, sample)
+		s.accepted.Add(1)
+	}
+	series.Samples = trimSamples(series.Samples, cutoff, s.config.MaxSamplesPerSeries)
+	return nil
+}
+
+// trimSamples drops samples before cutoff and beyond the per-series limit
+func trimSamples(samples []Sample, cutoff time.Time, limit int) []Sample {
+	start := sort.Search(len(samples), func(i int) bool { return !samples[i].Timestamp.Before(cutoff) })
+	if limit > 0 && len(samples)-start > limit {
+		start = len(samples) - limit
+	}
+	if start == 0 {
+		return samples
+	}
+	return append(samples[:0:0], samples[start:]...)
+}
+
+// Latest returns the most recent sample of every series called name whose
+// labels match selector
+func (s *SeriesStore) Latest(name string, selector map[string]string) []SeriesInfo {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	result := []SeriesInfo{}
+	for _, series := range s.series {
+		if series.Name != name || len(series.Samples) == 0 || !matches(series.Labels, selector) {
+			continue
+		}
+		result = append(result, info(series))
+	}
+	sortInfos(result)
+	return result
+}
+
+// Range returns copies of the series called name whose labels match selector,
+// limited to samples in [from, to]. A zero from or to is unbounded.
+func (s *SeriesStore) Range(name string, selector map[string]string, from, to time.Time) []Series {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var result []Series
+	for _, series := range s.series {
+		if series.Name != name || !matches(series.Labels, selector) {
+			continue
+		}
+		copied := Series{Name: series.Name, Labels: series.Labels, Samples: []Sample{}}
+		for _, sample := range series.Samples {
+			if (from.IsZero() || !sample.Timestamp.Before(from)) && (to.IsZero() || !sample.Timestamp.After(to)) {
+				copied.Samples = append(copied.Samples, sample)
+			}
+		}
+		result = append(result, copied)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return seriesKey(result[i].Name, result[i].Labels) < seriesKey(result[j].Name, result[j].Labels)
+	})
+	return result
+}
+
+// Tail returns copies of the series called name whose labels match selector,
+// limited to their last n samples
+func (s *SeriesStore) Tail(name string, selector map[string]string, n int) []Series {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var result []Series
+	for _, series := range s.series {
+		if series.Name != name || len(series.Samples) == 0 || !matches(series.Labels, selector) {
+			continue
+		}
+		samples := series.Samples
+		if n > 0 && len(samples) > n {
+			samples = samples[len(samples)-n:]
+		}
+		result = append(result, Series{Name: series.Name, Labels: series.Labels, Samples: append([]Sample(nil), samples...)})
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return seriesKey(result[i].Name, result[i].Labels) < seriesKey(result[j].Name, result[j].Labels)
+	})
+	return result
+}
+
+// AggregateRange returns the series called name whose labels match selector
+// within [from, to], their samples aggregated into buckets of step. A zero from
+// or to is unbounded.
+func (s *SeriesStore) AggregateRange(name string, selector map[string]string, from, to time.Time, step time.Duration) []AggregateSeries {
+	series := s.Range(name, selector, from, to)
+	result := make([]AggregateSeries, 0, len(series))
+	for _, ser := range series {
+		points := bucketize(ser.Samples, max(step, time.Nanosecond))
+		if points == nil {
+			points = []Aggregate{}
+		}
+		result = append(result, AggregateSeries{Name: ser.Name, Labels: ser.Labels, Resolution: step, Points: points})
+	}
+	return result
+}
+
+// List summarizes every stored series, ordered by name and labels
+func (s *SeriesStore) List() []SeriesInfo {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	result := make([]SeriesInfo, 0, len(s.series))
+	for _, series := range s.series {
+		result = append(result, info(series))
+	}
+	sortInfos(result)
+	return result
+}
+
+// Names returns the distinct metric names in the store, sorted
+func (s *SeriesStore) Names() []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	seen := make(map[string]struct{})
+	for _, series := range s.series {
+		seen[series.Name] = struct{}{}
+	}
+	names := make([]string, 0, len(seen))
+	for name := range seen {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func info(series *Series) SeriesInfo {
+	si := SeriesInfo{Name: series.Name, Labels: series.Labels, Samples: len(series.Samples)}
+	if n := len(series.Samples); n > 0 {
+		si.Latest = series.Samples[n-1]
+	}
+	return si
+}
+
+func sortInfos(infos []SeriesInfo) {
+	sort.Slice(infos, func(i, j int) bool {
+		return seriesKey(infos[i].Name, infos[i].Labels) < seriesKey(infos[j].Name, infos[j].Labels)
+	})
+}
+
+// Prune drops samples outside the retention window and series left empty
+func (s *SeriesStore) Prune() {
+	cutoff := s.clock.Now().Add(-s.config.Retention)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for key, series := range s.series {
+		series.Samples = trimSamples(series.Samples, cutoff, s.config.MaxSamplesPerSeries)
+		if len(series.Samples) == 0 {
+			delete(s.series, key)
+		}
+	}
+}
+
+// PurgeBefore drops samples older than before, regardless of the store's own
+// retention, and series left empty. It returns the number of samples dropped.
+func (s *SeriesStore) PurgeBefore(before time.Time) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	purged := 0
+	for key, series := range s.series {
+		kept := trimSamples(series.Samples, before, 0)
+		purged += len(series.Samples) - len(kept)
+		series.Samples = kept
+		if len(series.Samples) == 0 {
+			delete(s.series, key)
+		}
+	}
+	return purged
+}
+
+// Usage reports the number of stored samples and the oldest one's timestamp
+func (s *SeriesStore) Usage() retention.Usage {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var usage retention.Usage
+	for _, series := range s.series {
+		if len(series.Samples) == 0 {
+			continue
+		}
+		usage.Items += len(series.Samples)
+		if first := series.Samples[0].Timestamp; usage.Oldest == nil || first.Before(*usage.Oldest) {
+			usage.Oldest = &first
+		}
+	}
+	return usage
+}
+
+// StartPruner prunes the store every interval until ctx is cancelled
+func (s *SeriesStore) StartPruner(ctx context.Context, interval time.Duration) {
+	go func() {
+		ticker := s.clock.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C():
+				s.Prune()
+			}
+		}
+	}()
+}
+
+// Stats returns the series and sample counts
+func (s *SeriesStore) Stats() SeriesStoreStats {
+	s.mu.RLock()
+	st := SeriesStoreStats{Series: len(s.series)}
+	for _, series := range s.series {
+		st.Samples += len(series.Samples)
+	}
+	s.mu.RUnlock()
+
+	st.Accepted = s.accepted.Load()
+	st.Dropped = s.dropped.Load()
+	return st
+}
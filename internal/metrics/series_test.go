@@ -0,0 +1,105 @@
+package metrics
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"argus/internal/clock"
+)
+
+var epoch = time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+func TestSeriesStore_AppendAndLatest(t *testing.T) {
+	clk := clock.NewFake(epoch)
+	store := NewSeriesStore(SeriesStoreConfig{Retention: time.Hour, Clock: clk})
+
+	web1 := map[string]string{"instance": "web-1"}
+	web2 := map[string]string{"instance": "web-2"}
+	require.NoError(t, store.Append("up", web1, []Sample{{epoch.Add(-time.Minute), 1}, {epoch, 0}}))
+	require.NoError(t, store.Append("up", web2, []Sample{{epoch, 1}}))
+
+	all := store.Latest("up", nil)
+	require.Len(t, all, 2)
+	assert.Equal(t, 0.0, all[0].Latest.Value)
+	assert.Equal(t, 2, all[0].Samples)
+
+	one := store.Latest("up", web2)
+	require.Len(t, one, 1)
+	assert.Equal(t, 1.0, one[0].Latest.Value)
+
+	assert.Empty(t, store.Latest("down", nil))
+}
+
+func TestSeriesStore_Tail(t *testing.T) {
+	clk := clock.NewFake(epoch)
+	store := NewSeriesStore(SeriesStoreConfig{Retention: time.Hour, Clock: clk})
+
+	web1 := map[string]string{"instance": "web-1"}
+	require.NoError(t, store.Append("up", web1, []Sample{{epoch.Add(-2 * time.Minute), 1}, {epoch.Add(-time.Minute), 2}, {epoch, 3}}))
+	require.NoError(t, store.Append("up", map[string]string{"instance": "web-2"}, []Sample{{epoch, 4}}))
+
+	tail := store.Tail("up", web1, 2)
+	require.Len(t, tail, 1)
+	assert.Equal(t, []Sample{{epoch.Add(-time.Minute), 2}, {epoch, 3}}, tail[0].Samples)
+
+	all := store.Tail("up", nil, 5)
+	require.Len(t, all, 2)
+	assert.Len(t, all[0].Samples, 3)
+	assert.Len(t, all[1].Samples, 1)
+
+	assert.Empty(t, store.Tail("down", nil, 5))
+}
+
+func TestSeriesStore_DropsOldAndOutOfOrder(t *testing.T) {
+	clk := clock.NewFake(epoch)
+	store := NewSeriesStore(SeriesStoreConfig{Retention: time.Hour, MaxSamplesPerSeries: 2, Clock: clk})
+
+	require.NoError(t, store.Append("m", nil, []Sample{
+		{epoch.Add(-2 * time.Hour), 1}, // Outside retention
+		{epoch.Add(-3 * time.Second), 2},
+		{epoch.Add(-4 * time.Second), 3}, // Out of order
+		{epoch.Add(-2 * time.Second), 4},
+		{epoch.Add(-1 * time.Second), 5}, // Pushes out the oldest
+	}))
+
+	series := store.Range("m", nil, time.Time{}, time.Time{})
+	require.Len(t, series, 1)
+	assert.Equal(t, []Sample{{epoch.Add(-2 * time.Second), 4}, {epoch.Add(-1 * time.Second), 5}}, series[0].Samples)
+
+	st := store.Stats()
+	assert.Equal(t, uint64(3), st.Accepted)
+	assert.Equal(t, uint64(2), st.Dropped)
+}
+
+func TestSeriesStore_MaxSeriesAndPrune(t *testing.T) {
+	clk := clock.NewFake(epoch)
+	store := NewSeriesStore(SeriesStoreConfig{Retention: time.Minute, MaxSeries: 1, Clock: clk})
+
+	require.NoError(t, store.Append("a", nil, []Sample{{epoch, 1}}))
+	assert.ErrorIs(t, store.Append("b", nil, []Sample{{epoch, 1}}), ErrTooManySeries)
+
+	clk.Advance(2 * time.Minute)
+	store.Prune()
+	assert.Empty(t, store.List())
+	require.NoError(t, store.Append("b", nil, []Sample{{clk.Now(), 1}}))
+}
+
+func TestSeriesStore_PurgeBeforeAndUsage(t *testing.T) {
+	clk := clock.NewFake(epoch)
+	store := NewSeriesStore(SeriesStoreConfig{Retention: time.Hour, Clock: clk})
+
+	require.NoError(t, store.Append("a", nil, []Sample{{epoch.Add(-30 * time.Minute), 1}, {epoch, 2}}))
+	require.NoError(t, store.Append("b", nil, []Sample{{epoch.Add(-20 * time.Minute), 1}}))
+
+	usage := store.Usage()
+	assert.Equal(t, 3, usage.Items)
+	require.NotNil(t, usage.Oldest)
+	assert.Equal(t, epoch.Add(-30*time.Minute), *usage.Oldest)
+
+	assert.Equal(t, 2, store.PurgeBefore(epoch.Add(-10*time.Minute)))
+	assert.Equal(t, []string{"a"}, store.Names())
+	assert.Equal(t, 1, store.Usage().Items)
+}
@@ -0,0 +1,298 @@
+// File: internal/metrics/services.go
+// Brief: Critical system service and time synchronization collection
+// Detailed: Reads the systemd state of the daemons a host depends on (time synchronization, SSH, cron, Docker by default), picking the first installed unit of each service's alternatives, and the clock synchronization state from timedatectl and chronyc, for the system services panel and service alerts.
+// Author: drama.lin@aver.com
+// Date: 2026-10-14
+
+package metrics
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"os"
+	"os/exec"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Commands the service collector runs, variables so tests can replace them
+var (
+	systemctlCommand   = "systemctl"
+	timedatectlCommand = "timedatectl"
+	chronycCommand     = "chronyc"
+)
+
+// commandTimeout bounds each command collectors run, e.g. zpool, which can
+// hang on a suspended pool
+const commandTimeout = 5 * time.Second
+
+// ServiceCheck is one critical daemon and the systemd units that may provide
+// it, in order of preference
+type ServiceCheck struct {
+	Name  string   `yaml:"name" json:"name"`
+	Units []string `yaml:"units" json:"units"`
+}
+
+// ServiceNTP is the name of the time synchronization service, whose state
+// includes the clock synchronization
+const ServiceNTP = "ntp"
+
+// DefaultServiceChecks are the services watched unless configured otherwise
+var DefaultServiceChecks = []ServiceCheck{
+	{Name: ServiceNTP, Units: []string{"chronyd.service", "chrony.service", "ntpd.service", "ntp.service", "ntpsec.service", "systemd-timesyncd.service"}},
+	{Name: "ssh", Units: []string{"sshd.service", "ssh.service"}},
+	{Name: "cron", Units: []string{"cron.service", "crond.service", "cronie.service"}},
+	{Name: "docker", Units: []string{"docker.service"}},
+}
+
+// ServiceState is the systemd state of one watched service
+type ServiceState struct {
+	Name        string     `json:"name"`
+	Unit        string     `json:"unit,omitempty"` // The installed unit providing the service
+	Description string     `json:"description,omitempty"`
+	Installed   bool       `json:"installed"`
+	ActiveState string     `json:"active_state,omitempty"` // active, inactive, failed, activating, deactivating or reloading
+	SubState    string     `json:"sub_state,omitempty"`    // e.g. running, dead, exited
+	Since       *time.Time `json:"since,omitempty"`        // When the unit last entered its active state
+	Restarts    int        `json:"restarts"`               // Automatic restarts by systemd
+}
+
+// Active reports whether the service is up
+func (s ServiceState) Active() bool {
+	return s.ActiveState == "active" || s.ActiveState == "reloading"
+}
+
+// TimeSync is the clock synchronization state
+type TimeSync struct {
+	Synchronized  bool    `json:"synchronized"`
+	Reference     string  `json:"reference,omitempty"` // Server the clock follows (chrony only)
+	Stratum       int     `json:"stratum,omitempty"`
+	OffsetSeconds float64 `json:"offset_seconds"` // Difference between the system clock and NTP time (chrony only)
+	HasOffset     bool    `json:"has_offset"`
+}
+
+// ServiceMetrics holds the state of every watched service
+type ServiceMetrics struct {
+	Available bool           `json:"available"` // systemd could be queried
+	Services  []ServiceState `json:"services"`
+	TimeSync  *TimeSync      `json:"time_sync,omitempty"`
+	UpdatedAt time.Time      `json:"updated_at"`
+}
+
+// Service returns the state of the service with the given name or unit
+func (m *ServiceMetrics) Service(name string) (ServiceState, bool) {
+	for _, s := range m.Services {
+		if s.Name == name || (s.Unit != "" && s.Unit == name) {
+			return s, true
+		}
+	}
+	return ServiceState{}, false
+}
+
+// collectServiceMetrics collects the state of the watched services. Hosts
+// without systemd report them as not available rather than failing.
+func (c *Collector) collectServiceMetrics(ctx context.Context) error {
+	checks := c.config.Services
+	if checks == nil {
+		checks = DefaultServiceChecks
+	}
+	metrics := &ServiceMetrics{Services: make([]ServiceState, 0, len(checks))}
+
+	var units []string
+	for _, check := range checks {
+		units = append(units, check.Units...)
+	}
+	out, err := runCommand(ctx, systemctlCommand, append([]string{"show", "--property=Id,LoadState,ActiveState,SubState,Description,ActiveEnterTimestamp,NRestarts", "--"}, units...)...)
+	switch {
+	case commandMissing(err) || (err != nil && strings.Contains(err.Error(), "not been booted with systemd")):
+		for _, check := range checks {
+			metrics.Services = append(metrics.Services, ServiceState{Name: check.Name})
+		}
+	case err != nil:
+		return fmt.Errorf("failed to get service states: %w", err)
+	default:
+		metrics.Available = true
+		metrics.Services = resolveServices(checks, parseSystemctlShow(out))
+	}
+
+	if ntp, ok := metrics.Service(ServiceNTP); ok && ntp.Active() {
+		metrics.TimeSync = timeSync(ctx, ntp.Unit)
+	}
+	metrics.UpdatedAt = c.clock.Now()
+
+	c.serviceMutex.Lock()
+	c.serviceMetrics = metrics
+	c.serviceMutex.Unlock()
+
+	slog.Debug("Service metrics updated", "available", metrics.Available, "services", len(metrics.Services))
+	return nil
+}
+
+// GetServiceMetrics returns cached service states
+func (c *Collector) GetServiceMetrics() *ServiceMetrics {
+	c.serviceMutex.RLock()
+	defer c.serviceMutex.RUnlock()
+
+	if c.serviceMetrics == nil {
+		return nil
+	}
+
+	if c.clock.Now().Sub(c.serviceMetrics.UpdatedAt) > c.tuning().CacheTTL {
+		slog.Debug("Service metrics cache expired")
+		return nil
+	}
+
+	metrics := *c.serviceMetrics
+	metrics.Services = slices.Clone(c.serviceMetrics.Services)
+	if c.serviceMetrics.TimeSync != nil {
+		timeSync := *c.serviceMetrics.TimeSync
+		metrics.TimeSync = &timeSync
+	}
+	return &metrics
+}
+
+// runCommand runs a command and returns its output; failures carry its
+// standard error
+func runCommand(ctx context.Context, name string, args ...string) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(ctx, commandTimeout)
+	defer cancel()
+	var stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("%s: %w: %s", name, err, msg)
+		}
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	return out, nil
+}
+
+// commandMissing reports whether err is from running a command that is not
+// installed
+func commandMissing(err error) bool {
+	return errors.Is(err, exec.ErrNotFound) || errors.Is(err, os.ErrNotExist)
+}
+
+// parseSystemctlShow parses "systemctl show" output: blocks of
+// Property=value lines, one per unit, separated by blank lines
+func parseSystemctlShow(out []byte) map[string]map[string]string {
+	units := make(map[string]map[string]string)
+	properties := make(map[string]string)
+	flush := func() {
+		if id := properties["Id"]; id != "" {
+			units[id] = properties
+		}
+		properties = make(map[string]string)
+	}
+	scanner := bufio.NewScanner(bytes.NewReader(out))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" {
+			flush()
+			continue
+		}
+		if key, value, ok := strings.Cut(line, "="); ok {
+			properties[key] = value
+		}
+	}
+	flush()
+	return units
+}
+
+// resolveServices picks the first installed unit of every check
+func resolveServices(checks []ServiceCheck, units map[string]map[string]string) []ServiceState {
+	states := make([]ServiceState, 0, len(checks))
+	for _, check := range checks {
+		state := ServiceState{Name: check.Name}
+		for _, unit := range check.Units {
+			properties, ok := units[unit]
+			if !ok || properties["LoadState"] == "not-found" {
+				continue
+			}
+			state.Unit = unit
+			state.Installed = true
+			state.Description = properties["Description"]
+			state.ActiveState = properties["ActiveState"]
+			state.SubState = properties["SubState"]
+			state.Restarts, _ = strconv.Atoi(properties["NRestarts"])
+			if since, ok := parseSystemdTimestamp(properties["ActiveEnterTimestamp"]); ok {
+				state.Since = &since
+			}
+			break
+		}
+		states = append(states, state)
+	}
+	return states
+}
+
+// parseSystemdTimestamp parses timestamps such as
+// "Wed 2026-10-14 09:12:40 UTC"; units never active have an empty one
+func parseSystemdTimestamp(s string) (time.Time, bool) {
+	if s == "" || s == "n/a" {
+		return time.Time{}, false
+	}
+	t, err := time.Parse("Mon 2006-01-02 15:04:05 MST", s)
+	return t, err == nil
+}
+
+// timeSync reads the clock synchronization state, with the offset and
+// reference from chronyc when chrony is the NTP daemon. Commands that fail
+// leave their part unknown.
+func timeSync(ctx context.Context, unit string) *TimeSync {
+	state := &TimeSync{}
+	out, err := runCommand(ctx, timedatectlCommand, "show", "--property=NTPSynchronized", "--value")
+	timedated := err == nil
+	if timedated {
+		state.Synchronized = strings.TrimSpace(string(out)) == "yes"
+	} else {
+		slog.Debug("Failed to get NTP synchronization", "error", err)
+	}
+	if !strings.HasPrefix(unit, "chrony") {
+		return state
+	}
+	out, err = runCommand(ctx, chronycCommand, "-c", "tracking")
+	if err != nil {
+		slog.Debug("Failed to get chrony tracking", "error", err)
+		return state
+	}
+	tracking, ok := parseChronyTracking(out)
+	if !ok {
+		return state
+	}
+	if timedated {
+		// timedated's view wins, as it is what other tools report
+		tracking.Synchronized = state.Synchronized
+	}
+	return tracking
+}
+
+// parseChronyTracking parses the CSV output of "chronyc -c tracking":
+// reference ID, reference name, stratum, reference time, system time
+// offset, ..., leap status
+func parseChronyTracking(out []byte) (*TimeSync, bool) {
+	fields := strings.Split(strings.TrimSpace(string(out)), ",")
+	if len(fields) < 14 {
+		return nil, false
+	}
+	offset, err := strconv.ParseFloat(fields[4], 64)
+	if err != nil {
+		return nil, false
+	}
+	stratum, _ := strconv.Atoi(fields[2])
+	return &TimeSync{
+		Synchronized:  fields[13] != "Not synchronised",
+		Reference:     fields[1],
+		Stratum:       stratum,
+		OffsetSeconds: math.Abs(offset),
+		HasOffset:     true,
+	}, true
+}
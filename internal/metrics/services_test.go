@@ -0,0 +1,136 @@
+package metrics
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"argus/internal/clock"
+)
+
+const systemctlFixture = `Id=chronyd.service
+LoadState=not-found
+ActiveState=inactive
+SubState=dead
+Description=chronyd.service
+ActiveEnterTimestamp=
+NRestarts=0
+
+Id=chrony.service
+LoadState=loaded
+ActiveState=active
+SubState=running
+Description=chrony, an NTP client/server
+ActiveEnterTimestamp=Wed 2026-10-14 09:12:40 UTC
+NRestarts=0
+
+Id=ssh.service
+LoadState=loaded
+ActiveState=failed
+SubState=failed
+Description=OpenBSD Secure Shell server
+ActiveEnterTimestamp=Tue 2026-10-13 08:00:00 UTC
+NRestarts=3
+
+Id=docker.service
+LoadState=not-found
+ActiveState=inactive
+SubState=dead
+Description=docker.service
+ActiveEnterTimestamp=
+NRestarts=0
+`
+
+func TestResolveServices(t *testing.T) {
+	services := resolveServices(DefaultServiceChecks, parseSystemctlShow([]byte(systemctlFixture)))
+	require.Len(t, services, 4)
+
+	ntp := services[0]
+	assert.Equal(t, "chrony.service", ntp.Unit, "the first installed alternative")
+	assert.True(t, ntp.Installed)
+	assert.True(t, ntp.Active())
+	require.NotNil(t, ntp.Since)
+	assert.Equal(t, time.Date(2026, 10, 14, 9, 12, 40, 0, time.UTC), ntp.Since.UTC())
+
+	ssh := services[1]
+	assert.Equal(t, "ssh.service", ssh.Unit, "aliases report the unit they resolve to")
+	assert.False(t, ssh.Active())
+	assert.Equal(t, 3, ssh.Restarts)
+
+	assert.Equal(t, ServiceState{Name: "cron"}, services[2], "no output for the unit")
+	assert.False(t, services[3].Installed, "not-found units are not installed")
+}
+
+func TestParseChronyTracking(t *testing.T) {
+	sync, ok := parseChronyTracking([]byte("A9FEA97B,169.254.169.123,4,1760433160.123,-0.000012345,0.000002,0.000034,-12.5,0.001,0.02,0.000412,0.000123,64.2,Normal\n"))
+	require.True(t, ok)
+	assert.True(t, sync.Synchronized)
+	assert.Equal(t, "169.254.169.123", sync.Reference)
+	assert.Equal(t, 4, sync.Stratum)
+	assert.InDelta(t, 0.000012345, sync.OffsetSeconds, 1e-12, "the offset's magnitude")
+
+	sync, ok = parseChronyTracking([]byte("00000000,,0,0.0,0.0,0.0,0.0,0.0,0.0,0.0,1.0,1.0,0.0,Not synchronised\n"))
+	require.True(t, ok)
+	assert.False(t, sync.Synchronized)
+
+	_, ok = parseChronyTracking([]byte("506 Cannot talk to daemon"))
+	assert.False(t, ok)
+}
+
+// fakeCommand writes a script printing output and points command at it
+func fakeCommand(t *testing.T, command *string, output string) {
+	t.Helper()
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "output"), output)
+	script := filepath.Join(dir, "command")
+	require.NoError(t, os.WriteFile(script, []byte("#!/bin/sh\ncat \""+dir+"/output\"\n"), 0o755))
+	old := *command
+	*command = script
+	t.Cleanup(func() { *command = old })
+}
+
+func TestCollector_ServiceMetrics(t *testing.T) {
+	fakeCommand(t, &systemctlCommand, systemctlFixture)
+	fakeCommand(t, &timedatectlCommand, "yes\n")
+	fakeCommand(t, &chronycCommand, "A9FEA97B,ntp1,2,1760433160.1,0.0025,0.0,0.0,0.0,0.0,0.0,0.0,0.0,64.0,Normal\n")
+
+	clk := clock.NewFake(epoch)
+	config := DefaultConfig()
+	config.Clock = clk
+	c := NewCollector(config)
+	require.NoError(t, c.collectServiceMetrics(context.Background()))
+
+	m := c.GetServiceMetrics()
+	require.NotNil(t, m)
+	assert.True(t, m.Available)
+	require.NotNil(t, m.TimeSync)
+	assert.True(t, m.TimeSync.Synchronized)
+	assert.Equal(t, "ntp1", m.TimeSync.Reference)
+	assert.InDelta(t, 0.0025, m.TimeSync.OffsetSeconds, 1e-9)
+
+	ssh, ok := m.Service("ssh.service")
+	require.True(t, ok, "services are found by unit too")
+	assert.Equal(t, "ssh", ssh.Name)
+}
+
+func TestCollector_ServiceMetricsWithoutSystemd(t *testing.T) {
+	old := systemctlCommand
+	systemctlCommand = filepath.Join(t.TempDir(), "systemctl")
+	t.Cleanup(func() { systemctlCommand = old })
+
+	config := DefaultConfig()
+	config.Services = []ServiceCheck{{Name: "web", Units: []string{"nginx.service"}}}
+	c := NewCollector(config)
+	require.NoError(t, c.collectServiceMetrics(context.Background()), "hosts without systemd are not a collection failure")
+
+	m := c.GetServiceMetrics()
+	require.NotNil(t, m)
+	assert.False(t, m.Available)
+	assert.Equal(t, []ServiceState{{Name: "web"}}, m.Services)
+	assert.Nil(t, m.TimeSync)
+}
@@ -0,0 +1,76 @@
+package metrics
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"argus/internal/clock"
+)
+
+func TestCollector_Tune(t *testing.T) {
+	config := DefaultConfig()
+	config.Disabled = []string{KindPower}
+	c := NewCollector(config)
+
+	tuning := c.Tuning()
+	assert.Equal(t, Tuning{UpdateInterval: 5 * time.Second, CacheTTL: 10 * time.Second, ProcessLimit: 100, Disabled: []string{KindPower}}, tuning)
+
+	tuning.Disabled[0] = KindCPU
+	assert.Equal(t, []string{KindPower}, c.Tuning().Disabled, "Tuning returns a copy")
+
+	c.adaptProcessLimit(2*time.Second, 5000)
+	require.Equal(t, 50, c.ProcessCollectionStats().Limit)
+
+	require.NoError(t, c.Tune(Tuning{
+		UpdateInterval: 30 * time.Second,
+		CacheTTL:       time.Minute,
+		ProcessLimit:   40,
+		Disabled:       []string{KindServices, KindRAID},
+	}))
+	tuning = c.Tuning()
+	assert.Equal(t, 30*time.Second, tuning.UpdateInterval)
+	assert.Equal(t, []string{KindRAID, KindServices}, tuning.Disabled, "kept in collection order")
+	stats := c.ProcessCollectionStats()
+	assert.Equal(t, 40, stats.Limit, "the adaptive limit restarts from the new limit")
+	assert.Equal(t, 40, stats.MaxLimit)
+	assert.Len(t, c.intervalChanged, 1, "the collection loop is told to reset its ticker")
+
+	for _, invalid := range []Tuning{
+		{UpdateInterval: 0, CacheTTL: time.Second},
+		{UpdateInterval: time.Second, CacheTTL: 0},
+		{UpdateInterval: time.Second, CacheTTL: time.Second, ProcessLimit: -1},
+		{UpdateInterval: time.Second, CacheTTL: time.Second, ProcessLimit: 10}, // Below MinProcessLimit
+		{UpdateInterval: time.Second, CacheTTL: time.Second, Disabled: []string{"gpu"}},
+	} {
+		assert.Error(t, c.Tune(invalid), invalid)
+	}
+	assert.Equal(t, tuning, c.Tuning(), "invalid settings are not applied")
+}
+
+func TestCollector_DisabledKinds(t *testing.T) {
+	clk := clock.NewFake(epoch)
+	config := DefaultConfig()
+	config.Clock = clk
+	c := NewCollector(config)
+	c.rounds.Store(int64(config.WarmupRounds))
+
+	tuning := c.Tuning()
+	tuning.Disabled = []string{KindRAID}
+	require.NoError(t, c.Tune(tuning))
+	for _, kind := range Kinds {
+		if kind != KindRAID {
+			c.recordResult(kind, nil)
+		}
+	}
+
+	assert.Equal(t, StatusDisabled, c.MetricHealth(KindRAID).Status)
+	assert.Equal(t, StatusHealthy, c.Status(), "disabled kinds do not degrade the collector")
+
+	tuning.CacheTTL = time.Hour
+	require.NoError(t, c.Tune(tuning))
+	clk.Advance(time.Minute)
+	assert.Equal(t, StatusHealthy, c.Status(), "staleness follows the tuned cache TTL")
+}
@@ -0,0 +1,70 @@
+// File: internal/metrics/warmup.go
+// Brief: Collector warm-up and per-process CPU rates
+// Detailed: Tracks collection rounds so the collector reports "initializing" until rate-based metrics have the prior state they need, and computes process CPU usage from the CPU time used between two rounds instead of the lifetime average.
+// Author: drama.lin@aver.com
+// Date: 2026-10-14
+
+package metrics
+
+import (
+	"errors"
+	"time"
+)
+
+// ErrInitializing is returned for metrics that are not meaningful yet
+// because the collector is still warming up
+var ErrInitializing = errors.New("metrics collector is initializing")
+
+// Collector health states
+const (
+	StatusInitializing = "initializing" // Warming up; rate-based values are not reliable yet
+	StatusHealthy      = "healthy"
+	StatusStale        = "stale" // Some metrics have not been refreshed in time
+)
+
+// cpuSample is the CPU time a process had used at a collection round
+type cpuSample struct {
+	seconds float64 // User plus system CPU time
+	at      time.Time
+}
+
+// cpuRate returns the CPU percentage (of one core) used since prev, or false
+// when there is no usable prior sample, e.g. for a new or reused PID
+func cpuRate(prev cpuSample, ok bool, now cpuSample) (float64, bool) {
+	elapsed := now.at.Sub(prev.at).Seconds()
+	if !ok || elapsed <= 0 || now.seconds < prev.seconds {
+		return 0, false
+	}
+	return (now.seconds - prev.seconds) / elapsed * 100, true
+}
+
+// Initializing reports whether the collector has not yet completed the
+// rounds rate-based metrics need
+func (c *Collector) Initializing() bool {
+	return c.rounds.Load() < int64(c.config.WarmupRounds)
+}
+
+// Status returns StatusInitializing while warming up, StatusHealthy when
+// every enabled metric kind is collected, StatusStale when none is, and
+// StatusDegraded when only some are
+func (c *Collector) Status() string {
+	if c.Initializing() {
+		return StatusInitializing
+	}
+	healthy, enabled := 0, 0
+	for _, h := range c.Health() {
+		if h.Status != StatusDisabled {
+			enabled++
+		}
+		if h.Status == StatusHealthy {
+			healthy++
+		}
+	}
+	switch healthy {
+	case enabled:
+		return StatusHealthy
+	case 0:
+		return StatusStale
+	}
+	return StatusDegraded
+}
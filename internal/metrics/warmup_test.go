@@ -0,0 +1,84 @@
+package metrics
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"argus/internal/clock"
+)
+
+func TestCPURate(t *testing.T) {
+	prev := cpuSample{seconds: 10, at: epoch}
+	rate, ok := cpuRate(prev, true, cpuSample{seconds: 11.5, at: epoch.Add(5 * time.Second)})
+	assert.True(t, ok)
+	assert.InDelta(t, 30.0, rate, 0.001)
+
+	_, ok = cpuRate(cpuSample{}, false, cpuSample{seconds: 1, at: epoch})
+	assert.False(t, ok, "no prior sample")
+	_, ok = cpuRate(prev, true, cpuSample{seconds: 2, at: epoch.Add(time.Second)})
+	assert.False(t, ok, "CPU time went backwards: the PID was reused")
+	_, ok = cpuRate(prev, true, cpuSample{seconds: 12, at: epoch})
+	assert.False(t, ok, "no time elapsed")
+}
+
+func TestCollector_WarmupStatus(t *testing.T) {
+	clk := clock.NewFake(epoch)
+	config := DefaultConfig()
+	config.Clock = clk
+	c := NewCollector(config)
+
+	// Nothing collected yet: initializing, which is not a failure
+	assert.Equal(t, StatusInitializing, c.Status())
+	assert.True(t, c.IsHealthy())
+
+	c.cpuMetrics = &CPUMetrics{UpdatedAt: epoch}
+	c.memoryMetrics = &MemoryMetrics{UpdatedAt: epoch}
+	c.networkMetrics = &NetworkMetrics{UpdatedAt: epoch}
+	c.diskMetrics = &DiskMetrics{UpdatedAt: epoch}
+	c.processMetrics = &ProcessMetrics{UpdatedAt: epoch}
+	for _, kind := range Kinds {
+		c.recordResult(kind, nil)
+	}
+	c.rounds.Store(int64(config.WarmupRounds))
+	assert.Equal(t, StatusHealthy, c.Status())
+	assert.True(t, c.IsHealthy())
+
+	clk.Advance(3 * config.CacheTTL)
+	assert.Equal(t, StatusStale, c.Status())
+	assert.False(t, c.IsHealthy())
+}
+
+func TestCollector_MetricHealth(t *testing.T) {
+	clk := clock.NewFake(epoch)
+	config := DefaultConfig()
+	config.Clock = clk
+	c := NewCollector(config)
+	c.rounds.Store(int64(config.WarmupRounds))
+
+	for _, kind := range Kinds {
+		c.recordResult(kind, nil)
+	}
+	clk.Advance(time.Second)
+	c.recordResult(KindMemory, errors.New("meminfo unreadable"))
+	c.recordResult(KindMemory, errors.New("meminfo unreadable"))
+
+	memory := c.MetricHealth(KindMemory)
+	assert.Equal(t, StatusFailing, memory.Status)
+	assert.Equal(t, 2, memory.ConsecutiveFailures)
+	assert.Equal(t, "meminfo unreadable", memory.LastError)
+	assert.Equal(t, epoch, *memory.LastSuccess)
+	assert.Equal(t, epoch.Add(time.Second), *memory.LastErrorAt)
+	assert.Equal(t, StatusHealthy, c.MetricHealth(KindCPU).Status)
+	assert.Equal(t, StatusDegraded, c.Status())
+
+	// Recovery clears the failure count but keeps the last error for reference
+	c.recordResult(KindMemory, nil)
+	memory = c.MetricHealth(KindMemory)
+	assert.Equal(t, StatusHealthy, memory.Status)
+	assert.Equal(t, 0, memory.ConsecutiveFailures)
+	assert.Equal(t, "meminfo unreadable", memory.LastError)
+	assert.Equal(t, StatusHealthy, c.Status())
+}
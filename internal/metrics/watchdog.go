@@ -0,0 +1,138 @@
+// File: internal/metrics/watchdog.go
+// Brief: Timeouts and hang tracking for system metrics calls
+// Detailed: Runs each gopsutil call of the collector with its own timeout. gopsutil reads /proc and sysfs without honoring its context, so a read stuck in the kernel on a sick host would otherwise stall the whole collection round. A call that times out is left to finish in the background and recorded as hung; until it returns, further calls of the same name fail fast instead of piling up blocked goroutines.
+// Author: drama.lin@aver.com
+// Date: 2026-10-14
+
+package metrics
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// DefaultCallTimeout bounds each system metrics call
+const DefaultCallTimeout = 5 * time.Second
+
+var (
+	// ErrCallTimeout is returned when a system metrics call exceeds its timeout
+	ErrCallTimeout = errors.New("call timed out")
+	// ErrCallHung is returned while an earlier call of the same name is still blocked
+	ErrCallHung = errors.New("earlier call still hung")
+)
+
+// HungCall is a system metrics call that timed out and has not returned yet
+type HungCall struct {
+	Name    string    `json:"name"` // e.g. "disk.Usage /mnt/nfs"
+	Since   time.Time `json:"since"`
+	Skipped int       `json:"skipped"` // Calls failed fast while waiting for it
+}
+
+// WatchdogStats reports timed out and hung system metrics calls for self-metrics
+type WatchdogStats struct {
+	TimeoutMs float64    `json:"timeout_ms"`
+	Timeouts  uint64     `json:"timeouts"` // Calls that timed out since startup
+	Hung      []HungCall `json:"hung"`     // By name
+}
+
+// watchdog tracks the calls that timed out and are still running
+type watchdog struct {
+	mu       sync.Mutex
+	hung     map[string]*HungCall
+	timeouts atomic.Uint64
+}
+
+// callTimeout returns the configured timeout of one system metrics call
+func (c *Collector) callTimeout() time.Duration {
+	if c.config.CallTimeout > 0 {
+		return c.config.CallTimeout
+	}
+	return DefaultCallTimeout
+}
+
+// guard runs fn in its own goroutine and waits at most the call timeout for
+// it. A timed out call keeps running and is reported as hung until it
+// returns. Panics in fn are returned as errors.
+func guard[T any](c *Collector, ctx context.Context, name string, fn func(context.Context) (T, error)) (T, error) {
+	var zero T
+	w := &c.watchdog
+	w.mu.Lock()
+	if h := w.hung[name]; h != nil {
+		h.Skipped++
+		since := h.Since
+		w.mu.Unlock()
+		return zero, fmt.Errorf("%s: %w since %s", name, ErrCallHung, since.Format(time.RFC3339))
+	}
+	w.mu.Unlock()
+
+	type result struct {
+		value T
+		err   error
+	}
+	timeout := c.callTimeout()
+	started := c.clock.Now()
+	callCtx, cancel := context.WithTimeout(ctx, timeout)
+	done := make(chan result, 1)
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				done <- result{err: fmt.Errorf("%s: panic: %v", name, r)}
+			}
+		}()
+		value, err := fn(callCtx)
+		done <- result{value: value, err: err}
+	}()
+
+	select {
+	case r := <-done:
+		cancel()
+		return r.value, r.err
+	case <-callCtx.Done():
+	}
+	if ctx.Err() != nil {
+		// Cancelled by the caller, not hung
+		go func() { <-done; cancel() }()
+		return zero, fmt.Errorf("%s: %w", name, ctx.Err())
+	}
+
+	w.mu.Lock()
+	w.hung[name] = &HungCall{Name: name, Since: started}
+	w.mu.Unlock()
+	w.timeouts.Add(1)
+	c.errorLog.Log(slog.LevelWarn, "hung:"+name, "System metrics call timed out", "call", name, "timeout", timeout)
+
+	go func() {
+		<-done
+		cancel()
+		w.mu.Lock()
+		delete(w.hung, name)
+		w.mu.Unlock()
+		slog.Info("Hung system metrics call returned", "call", name, "duration", c.clock.Now().Sub(started))
+	}()
+	return zero, fmt.Errorf("%s: %w after %s", name, ErrCallTimeout, timeout)
+}
+
+// WatchdogStats returns the call timeout, the number of calls that timed out
+// and the calls that are still hung
+func (c *Collector) WatchdogStats() WatchdogStats {
+	w := &c.watchdog
+	w.mu.Lock()
+	hung := make([]HungCall, 0, len(w.hung))
+	for _, h := range w.hung {
+		hung = append(hung, *h)
+	}
+	w.mu.Unlock()
+	sort.Slice(hung, func(i, j int) bool { return hung[i].Name < hung[j].Name })
+
+	return WatchdogStats{
+		TimeoutMs: float64(c.callTimeout()) / float64(time.Millisecond),
+		Timeouts:  w.timeouts.Load(),
+		Hung:      hung,
+	}
+}
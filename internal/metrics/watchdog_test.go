@@ -0,0 +1,76 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGuard(t *testing.T) {
+	config := DefaultConfig()
+	config.CallTimeout = 20 * time.Millisecond
+	c := NewCollector(config)
+	ctx := context.Background()
+
+	value, err := guard(c, ctx, "mem.VirtualMemory", func(context.Context) (int, error) { return 42, nil })
+	require.NoError(t, err)
+	assert.Equal(t, 42, value)
+
+	_, err = guard(c, ctx, "cpu.Percent", func(context.Context) (int, error) { return 0, errors.New("no /proc") })
+	assert.EqualError(t, err, "no /proc")
+
+	_, err = guard(c, ctx, "load.Avg", func(context.Context) (int, error) { panic("boom") })
+	assert.ErrorContains(t, err, "load.Avg: panic: boom")
+
+	// A call blocked in the kernel ignores its context
+	release := make(chan struct{})
+	started := time.Now()
+	_, err = guard(c, ctx, "disk.Usage /mnt/nfs", func(context.Context) (int, error) {
+		<-release
+		return 0, nil
+	})
+	assert.ErrorIs(t, err, ErrCallTimeout)
+	assert.Less(t, time.Since(started), time.Second)
+
+	calls := 0
+	_, err = guard(c, ctx, "disk.Usage /mnt/nfs", func(context.Context) (int, error) {
+		calls++
+		return 0, nil
+	})
+	assert.ErrorIs(t, err, ErrCallHung)
+	assert.Zero(t, calls, "not called again while the earlier call is hung")
+
+	_, err = guard(c, ctx, "disk.Usage /", func(context.Context) (int, error) { return 0, nil })
+	assert.NoError(t, err, "other calls are unaffected")
+
+	stats := c.WatchdogStats()
+	assert.Equal(t, 20.0, stats.TimeoutMs)
+	assert.Equal(t, uint64(1), stats.Timeouts)
+	require.Len(t, stats.Hung, 1)
+	assert.Equal(t, "disk.Usage /mnt/nfs", stats.Hung[0].Name)
+	assert.Equal(t, 1, stats.Hung[0].Skipped)
+
+	close(release)
+	require.Eventually(t, func() bool { return len(c.WatchdogStats().Hung) == 0 }, time.Second, time.Millisecond)
+	_, err = guard(c, ctx, "disk.Usage /mnt/nfs", func(context.Context) (int, error) { return 0, nil })
+	assert.NoError(t, err, "called again once the hung call returned")
+}
+
+func TestGuard_CallerCancelled(t *testing.T) {
+	config := DefaultConfig()
+	config.CallTimeout = time.Minute
+	c := NewCollector(config)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := guard(c, ctx, "process.Processes", func(ctx context.Context) (int, error) {
+		<-time.After(50 * time.Millisecond)
+		return 0, nil
+	})
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Empty(t, c.WatchdogStats().Hung, "a cancelled call is not hung")
+}
@@ -0,0 +1,291 @@
+// File: internal/migrate/migrate.go
+// Brief: Versioned migrations of the on-disk data layout
+// Detailed: Records the data version in the storage base path and, on startup, backs up the storage directories and applies the pending migrations in order. Refuses to start on data written by a newer version than the binary supports, so an accidental downgrade cannot corrupt it.
+// Author: drama.lin@aver.com
+// Date: 2026-10-14
+
+package migrate
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"time"
+
+	"argus/internal/clock"
+	"argus/internal/utils"
+)
+
+const (
+	// VersionFile records the data version in the base directory
+	VersionFile = "data_version.json"
+
+	// BackupDir is the subdirectory of the base directory holding the layout
+	// backed up before each migration run
+	BackupDir = "migration-backups"
+)
+
+// ErrNewerVersion is returned when the data was written by a newer version
+var ErrNewerVersion = errors.New("data version is newer than this binary supports")
+
+// Migration upgrades the data layout from Version-1 to Version
+type Migration struct {
+	Version     int
+	Description string
+	Apply       func() error
+}
+
+// Config holds configuration for the migrator
+type Config struct {
+	BaseDir string      // Directory holding the version file and backups
+	Dirs    []string    // Storage directories the migrations change, backed up first
+	Backup  bool        // Back up Dirs before migrating
+	Clock   clock.Clock // Time source (nil uses the real clock)
+}
+
+// Applied records one migration applied to the data
+type Applied struct {
+	Version     int       `json:"version"`
+	Description string    `json:"description"`
+	AppliedAt   time.Time `json:"applied_at"`
+}
+
+// State is the content of the version file
+type State struct {
+	Version   int       `json:"version"`
+	UpdatedAt time.Time `json:"updated_at"`
+	Applied   []Applied `json:"applied,omitempty"`
+}
+
+// Result reports a migration run
+type Result struct {
+	From      int
+	To        int
+	Applied   []int
+	BackupDir string // Empty when nothing was backed up
+}
+
+// Migrator applies migrations to the data layout
+type Migrator struct {
+	config     Config
+	clock      clock.Clock
+	migrations []Migration
+}
+
+// New creates a migrator for migrations, which must be numbered 1, 2, 3...
+func New(config Config, migrations []Migration) (*Migrator, error) {
+	for i, m := range migrations {
+		if m.Version != i+1 {
+			return nil, fmt.Errorf("migration %d (%s) is out of order: expected version %d", m.Version, m.Description, i+1)
+		}
+		if m.Apply == nil {
+			return nil, fmt.Errorf("migration %d (%s) has no apply function", m.Version, m.Description)
+		}
+	}
+	config.Dirs = utils.OutermostDirs(config.Dirs)
+	return &Migrator{config: config, clock: clock.OrReal(config.Clock), migrations: migrations}, nil
+}
+
+// Latest returns the data version this binary writes
+func (m *Migrator) Latest() int {
+	return len(m.migrations)
+}
+
+func (m *Migrator) versionPath() string {
+	return filepath.Join(m.config.BaseDir, VersionFile)
+}
+
+// State reads the version file. ok is false when there is none yet.
+func (m *Migrator) State() (state State, ok bool, err error) {
+	data, err := os.ReadFile(m.versionPath())
+	if errors.Is(err, fs.ErrNotExist) {
+		return State{}, false, nil
+	}
+	if err != nil {
+		return State{}, false, fmt.Errorf("failed to read data version: %w", err)
+	}
+	if err := json.Unmarshal(data, &state); err != nil {
+		return State{}, false, fmt.Errorf("failed to decode data version %s: %w", m.versionPath(), err)
+	}
+	return state, true, nil
+}
+
+func (m *Migrator) writeState(state State) error {
+	if err := os.MkdirAll(m.config.BaseDir, 0755); err != nil {
+		return fmt.Errorf("failed to create data directory: %w", err)
+	}
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode data version: %w", err)
+	}
+	// Written to a temporary file first so a crash never leaves a truncated version
+	tmp := m.versionPath() + ".tmp"
+	if err := os.WriteFile(tmp, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write data version: %w", err)
+	}
+	if err := os.Rename(tmp, m.versionPath()); err != nil {
+		return fmt.Errorf("failed to write data version: %w", err)
+	}
+	return nil
+}
+
+// hasData reports whether any storage directory contains a file, other than
+// the migrator's own
+func (m *Migrator) hasData() (bool, error) {
+	backups := filepath.Join(m.config.BaseDir, BackupDir)
+	found := errors.New("found")
+	for _, dir := range m.config.Dirs {
+		err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				if errors.Is(err, fs.ErrNotExist) {
+					return nil
+				}
+				return err
+			}
+			if d.IsDir() {
+				if sameDir(path, backups) {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if d.Name() == VersionFile {
+				return nil
+			}
+			return found
+		})
+		if errors.Is(err, found) {
+			return true, nil
+		}
+		if err != nil {
+			return false, fmt.Errorf("failed to scan %s: %w", dir, err)
+		}
+	}
+	return false, nil
+}
+
+// Run brings the data to the latest version. A new installation is stamped
+// with the latest version; existing data without a version file is treated as
+// version 0 and migrated from the start.
+func (m *Migrator) Run() (Result, error) {
+	state, ok, err := m.State()
+	if err != nil {
+		return Result{}, err
+	}
+	latest := m.Latest()
+	result := Result{From: state.Version, To: latest}
+	if state.Version > latest {
+		return result, fmt.Errorf("%w: data is at version %d, this binary supports up to %d", ErrNewerVersion, state.Version, latest)
+	}
+	now := m.clock.Now().UTC()
+
+	if !ok {
+		existing, err := m.hasData()
+		if err != nil {
+			return result, err
+		}
+		if !existing {
+			slog.Info("Initializing data version", "version", latest)
+			return result, m.writeState(State{Version: latest, UpdatedAt: now})
+		}
+	}
+	if state.Version == latest {
+		return result, nil
+	}
+
+	if m.config.Backup {
+		dir := filepath.Join(m.config.BaseDir, BackupDir, fmt.Sprintf("v%d-%s", state.Version, now.Format("20060102T150405Z")))
+		if err := m.backup(dir); err != nil {
+			return result, err
+		}
+		result.BackupDir = dir
+		slog.Info("Backed up data before migration", "from", state.Version, "to", latest, "backup", dir)
+	}
+
+	for _, migration := range m.migrations[state.Version:] {
+		slog.Info("Applying data migration", "version", migration.Version, "description", migration.Description)
+		if err := migration.Apply(); err != nil {
+			return result, fmt.Errorf("migration %d (%s) failed: %w", migration.Version, migration.Description, err)
+		}
+		// Recorded after every step so a failed run resumes where it stopped
+		state.Version = migration.Version
+		state.UpdatedAt = m.clock.Now().UTC()
+		state.Applied = append(state.Applied, Applied{Version: migration.Version, Description: migration.Description, AppliedAt: state.UpdatedAt})
+		if err := m.writeState(state); err != nil {
+			return result, err
+		}
+		result.Applied = append(result.Applied, migration.Version)
+	}
+	return result, nil
+}
+
+// backup copies every storage directory under dir, skipping earlier backups
+func (m *Migrator) backup(dir string) error {
+	backups := filepath.Join(m.config.BaseDir, BackupDir)
+	for i, src := range m.config.Dirs {
+		dst := filepath.Join(dir, fmt.Sprintf("%d-%s", i, filepath.Base(src)))
+		err := filepath.WalkDir(src, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				if errors.Is(err, fs.ErrNotExist) {
+					return nil
+				}
+				return err
+			}
+			if d.IsDir() && sameDir(path, backups) {
+				return filepath.SkipDir
+			}
+			rel, err := filepath.Rel(src, path)
+			if err != nil {
+				return err
+			}
+			target := filepath.Join(dst, rel)
+			switch {
+			case d.IsDir():
+				return os.MkdirAll(target, 0755)
+			case d.Type().IsRegular():
+				return copyFile(path, target)
+			default:
+				return nil // Sockets, devices and symlinks are not data
+			}
+		})
+		if err != nil {
+			return fmt.Errorf("failed to back up %s: %w", src, err)
+		}
+	}
+	return nil
+}
+
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	info, err := in.Stat()
+	if err != nil {
+		return err
+	}
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, info.Mode().Perm())
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+// sameDir reports whether a and b name the same directory
+func sameDir(a, b string) bool {
+	absA, errA := filepath.Abs(a)
+	absB, errB := filepath.Abs(b)
+	if errA != nil || errB != nil {
+		return filepath.Clean(a) == filepath.Clean(b)
+	}
+	return absA == absB
+}
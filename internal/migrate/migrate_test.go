@@ -0,0 +1,126 @@
+package migrate
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"argus/internal/clock"
+)
+
+var epoch = time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+func newMigrator(t *testing.T, base string, backup bool, applied *[]int, migrations ...Migration) *Migrator {
+	t.Helper()
+	if migrations == nil {
+		for _, v := range []int{1, 2} {
+			v := v
+			migrations = append(migrations, Migration{Version: v, Description: "step", Apply: func() error {
+				*applied = append(*applied, v)
+				return nil
+			}})
+		}
+	}
+	m, err := New(Config{BaseDir: base, Dirs: []string{base, filepath.Join(base, "alerts")}, Backup: backup, Clock: clock.NewFake(epoch)}, migrations)
+	require.NoError(t, err)
+	return m
+}
+
+func TestNew_RejectsUnorderedMigrations(t *testing.T) {
+	noop := func() error { return nil }
+	_, err := New(Config{}, []Migration{{Version: 2, Apply: noop}})
+	assert.Error(t, err)
+	_, err = New(Config{}, []Migration{{Version: 1}})
+	assert.Error(t, err)
+}
+
+func TestRun_StampsNewInstallation(t *testing.T) {
+	base := t.TempDir()
+	var applied []int
+	m := newMigrator(t, base, true, &applied)
+
+	result, err := m.Run()
+	require.NoError(t, err)
+	assert.Empty(t, applied)
+	assert.Empty(t, result.BackupDir)
+
+	state, ok, err := m.State()
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, 2, state.Version)
+}
+
+func TestRun_MigratesExistingDataWithBackup(t *testing.T) {
+	base := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(base, "alerts"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(base, "alerts", "a1.json"), []byte(`{"id":"a1"}`), 0644))
+
+	var applied []int
+	m := newMigrator(t, base, true, &applied)
+	result, err := m.Run()
+	require.NoError(t, err)
+	assert.Equal(t, []int{1, 2}, applied)
+	assert.Equal(t, []int{1, 2}, result.Applied)
+	assert.Equal(t, 0, result.From)
+
+	backedUp, err := os.ReadFile(filepath.Join(result.BackupDir, "0-"+filepath.Base(base), "alerts", "a1.json"))
+	require.NoError(t, err)
+	assert.Equal(t, `{"id":"a1"}`, string(backedUp))
+
+	state, _, err := m.State()
+	require.NoError(t, err)
+	assert.Equal(t, 2, state.Version)
+	assert.Len(t, state.Applied, 2)
+
+	// Nothing is pending on the next start
+	applied = nil
+	result, err = m.Run()
+	require.NoError(t, err)
+	assert.Empty(t, applied)
+	assert.Empty(t, result.BackupDir)
+}
+
+func TestRun_ResumesAfterFailure(t *testing.T) {
+	base := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(base, "tasks.json"), []byte("{}"), 0644))
+
+	fail := errors.New("disk full")
+	var applied []int
+	step := func(v int) Migration {
+		return Migration{Version: v, Description: "step", Apply: func() error {
+			if v == 2 && fail != nil {
+				return fail
+			}
+			applied = append(applied, v)
+			return nil
+		}}
+	}
+	m := newMigrator(t, base, false, &applied, step(1), step(2))
+	_, err := m.Run()
+	assert.ErrorIs(t, err, fail)
+	state, _, err := m.State()
+	require.NoError(t, err)
+	assert.Equal(t, 1, state.Version)
+
+	fail = nil
+	_, err = m.Run()
+	require.NoError(t, err)
+	assert.Equal(t, []int{1, 2}, applied)
+	_, err = os.Stat(filepath.Join(base, BackupDir))
+	assert.True(t, os.IsNotExist(err), "no backup when disabled")
+}
+
+func TestRun_RefusesNewerVersion(t *testing.T) {
+	base := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(base, VersionFile), []byte(`{"version": 3}`), 0644))
+
+	var applied []int
+	_, err := newMigrator(t, base, true, &applied).Run()
+	assert.ErrorIs(t, err, ErrNewerVersion)
+	assert.Empty(t, applied)
+}
@@ -0,0 +1,82 @@
+// File: internal/models/agentjob.go
+// Brief: Models for tasks executed on remote agents
+// Detailed: Contains the AgentTarget a task sets to run on one agent or on every agent matching a label selector instead of on the server, and the AgentJob the server queues for each targeted agent to claim, run with its local runners and report back.
+// Author: drama.lin@aver.com
+// Date: 2026-10-14
+
+package models
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// AgentTarget selects the agents a task runs on instead of the server: one
+// host by ID, or every reporting host carrying all the labels
+type AgentTarget struct {
+	Host   string            `json:"host,omitempty"`   // ID of the host
+	Labels map[string]string `json:"labels,omitempty"` // Label selector, e.g. {"role": "web"}
+}
+
+// Validate checks that the target sets either a host or a label selector
+func (t *AgentTarget) Validate() error {
+	switch {
+	case t.Host == "" && len(t.Labels) == 0:
+		return errors.New("a host or labels are required")
+	case t.Host != "" && len(t.Labels) > 0:
+		return errors.New("host and labels are mutually exclusive")
+	case strings.ContainsAny(t.Host, "/?#"):
+		return fmt.Errorf("invalid host ID %q: must not contain '/', '?' or '#'", t.Host)
+	}
+	for name := range t.Labels {
+		if name == "" {
+			return errors.New("label names must not be empty")
+		}
+	}
+	return nil
+}
+
+// Matches reports whether the target selects host
+func (t *AgentTarget) Matches(host Host) bool {
+	if t.Host != "" {
+		return host.ID == t.Host
+	}
+	for name, value := range t.Labels {
+		if host.Labels[name] != value {
+			return false
+		}
+	}
+	return true
+}
+
+// String describes the target, e.g. "host web-1" or "labels role=web"
+func (t *AgentTarget) String() string {
+	if t.Host != "" {
+		return "host " + t.Host
+	}
+	pairs := make([]string, 0, len(t.Labels))
+	for name, value := range t.Labels {
+		pairs = append(pairs, name+"="+value)
+	}
+	sort.Strings(pairs)
+	return "labels " + strings.Join(pairs, ",")
+}
+
+// AgentJob is one run of a task queued for an agent
+type AgentJob struct {
+	ID        string            `json:"id"`
+	Host      string            `json:"host"` // ID of the host whose agent runs it
+	Task      TaskConfig        `json:"task"`
+	Timeout   string            `json:"timeout"` // How long the agent may run the task, e.g. "30m0s"
+	CreatedAt time.Time         `json:"created_at"`
+	ClaimedAt *time.Time        `json:"claimed_at,omitempty"` // When the agent picked it up
+	Metadata  map[string]string `json:"-"`                    // Recorded with the execution the agent reports
+}
+
+// AgentJobsResponse carries the jobs an agent claimed
+type AgentJobsResponse struct {
+	Jobs []AgentJob `json:"jobs"`
+}
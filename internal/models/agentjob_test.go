@@ -0,0 +1,37 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAgentTargetValidate(t *testing.T) {
+	assert.NoError(t, (&AgentTarget{Host: "web-1"}).Validate())
+	assert.NoError(t, (&AgentTarget{Labels: map[string]string{"role": "web"}}).Validate())
+	assert.ErrorContains(t, (&AgentTarget{}).Validate(), "required")
+	assert.ErrorContains(t, (&AgentTarget{Host: "web-1", Labels: map[string]string{"role": "web"}}).Validate(), "mutually exclusive")
+	assert.ErrorContains(t, (&AgentTarget{Host: "web/1"}).Validate(), "invalid host ID")
+	assert.ErrorContains(t, (&AgentTarget{Labels: map[string]string{"": "web"}}).Validate(), "label names")
+
+	task := TaskConfig{ID: "t1", Name: "cleanup", Type: TaskSystemCleanup, Schedule: Schedule{CronExpression: "0 * * * *"},
+		Agents: &AgentTarget{}}
+	assert.ErrorContains(t, task.Validate(), "invalid agents")
+}
+
+func TestAgentTargetMatches(t *testing.T) {
+	web := Host{ID: "web-1", Labels: map[string]string{"role": "web", "env": "prod"}}
+	db := Host{ID: "db-1", Labels: map[string]string{"role": "db", "env": "prod"}}
+
+	byHost := &AgentTarget{Host: "web-1"}
+	assert.True(t, byHost.Matches(web))
+	assert.False(t, byHost.Matches(db))
+
+	byLabels := &AgentTarget{Labels: map[string]string{"env": "prod", "role": "web"}}
+	assert.True(t, byLabels.Matches(web))
+	assert.False(t, byLabels.Matches(db))
+	assert.True(t, (&AgentTarget{Labels: map[string]string{"env": "prod"}}).Matches(db))
+
+	assert.Equal(t, "host web-1", byHost.String())
+	assert.Equal(t, "labels env=prod,role=web", byLabels.String())
+}
@@ -1,15 +1,23 @@
 // File: internal/models/alert.go
 // Brief: Alert-related data models for Argus
-// Detailed: Contains type definitions for MetricType, ComparisonOperator, AlertSeverity, NotificationType, ThresholdConfig, NotificationConfig, AlertConfig, AlertState, AlertStatus, and related constants/methods.
+// Detailed: Contains type definitions for MetricType, its string (state) metrics, ComparisonOperator, AlertSeverity, NotificationType, ThresholdConfig, NotificationConfig, AlertConfig, AlertState, AlertStatus, and related constants/methods.
 // Author: drama.lin@aver.com
 // Date: 2024-07-03
 
 package models
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
+	"net/url"
+	"path"
+	"regexp"
+	"slices"
+	"strings"
 	"time"
+
+	"argus/internal/tmplfunc"
 )
 
 // MetricType represents the type of system metric to monitor
@@ -21,10 +29,68 @@ const (
 	MetricMemory  MetricType = "memory"  // Memory usage percentage
 	MetricLoad    MetricType = "load"    // System load average
 	MetricNetwork MetricType = "network" // Network traffic
-	MetricDisk    MetricType = "disk"    // Disk usage/IO (for future implementation)
-	MetricProcess MetricType = "process" // Process specific metrics (for future implementation)
+	MetricDisk    MetricType = "disk"    // Partition space and inode usage
+	MetricProcess MetricType = "process" // Usage of the processes named by the target, and their count
+	MetricSeries  MetricType = "series"  // Ingested external series (e.g. Prometheus remote-write)
+	MetricAPI     MetricType = "api"     // Argus's own API usage over the rolling window
+	MetricLog     MetricType = "log"     // Matches of a log watch's pattern over its window
+	MetricRAID    MetricType = "raid"    // Software RAID array and ZFS pool health
+	MetricPower   MetricType = "power"   // Battery and UPS charge, runtime and on-battery state
+	MetricService MetricType = "service" // Critical system services and clock synchronization
+	MetricHTTP    MetricType = "http"    // Latest probe of a health_check task endpoint
 )
 
+// MetricTypes lists every metric type alerts can watch
+var MetricTypes = []MetricType{MetricCPU, MetricMemory, MetricLoad, MetricNetwork, MetricDisk, MetricProcess, MetricSeries, MetricAPI, MetricLog, MetricRAID, MetricPower, MetricService, MetricHTTP}
+
+// MetricNames lists the metric names built-in metric types support. Types
+// without an entry accept any name, e.g. the name of an ingested series.
+var MetricNames = map[MetricType][]string{
+	MetricCPU:     {"usage_percent", "load1", "load5", "load15"},
+	MetricMemory:  {"used_percent", "used", "free"},
+	MetricNetwork: {"bytes_sent", "bytes_recv", "packets_sent", "packets_recv"},
+	MetricDisk:    {"usage_percent", "free", "inode_percent"},
+	MetricProcess: {"cpu_percent", "memory_percent", "open_files", "open_files_percent", "address_space_percent", "cgroup_memory_percent", "count"},
+	MetricAPI:     {"error_rate_percent", "client_error_rate_percent", "requests_per_minute", "avg_latency_ms"},
+	MetricRAID:    {"degraded", "failed_devices", "rebuild_percent", "capacity_percent", "state"},
+	MetricPower:   {"charge_percent", "runtime_ms", "on_battery", "on_battery_ms", "load_percent"},
+	MetricService: {"down", "restarts", "ntp_synchronized", "time_offset_ms", "active_state", "sub_state"},
+	MetricHTTP:    {"healthy", "status_code", "duration_ms", "status"},
+}
+
+// StringMetricNames lists the metric names whose values are states or text
+// rather than numbers, e.g. a service's active_state "failed". They compare
+// StringValue with ==, != or the regular expression operators =~ and !~,
+// and watch the single array, service or endpoint named by the target.
+var StringMetricNames = map[MetricType][]string{
+	MetricRAID:    {"state"},
+	MetricService: {"active_state", "sub_state"},
+	MetricHTTP:    {"status"},
+}
+
+// SelectorLabels lists the labels built-in metric types select their
+// per-entity metrics by, e.g. interface="eth0" for network alerts or
+// mountpoint="/data" for disk alerts. Series alerts accept any label.
+var SelectorLabels = map[MetricType][]string{
+	MetricNetwork: {"interface"},
+	MetricDisk:    {"mountpoint", "device", "fstype"},
+	MetricAPI:     {"namespace", "token"},
+}
+
+// metricTypeLabels names metric types in validation errors
+var metricTypeLabels = map[MetricType]string{
+	MetricCPU:     "CPU",
+	MetricMemory:  "memory",
+	MetricNetwork: "network",
+	MetricDisk:    "disk",
+	MetricProcess: "process",
+	MetricAPI:     "API",
+	MetricRAID:    "RAID",
+	MetricPower:   "power",
+	MetricService: "service",
+	MetricHTTP:    "HTTP",
+}
+
 // ComparisonOperator defines how a threshold is compared to the actual value
 type ComparisonOperator string
 
@@ -36,8 +102,20 @@ const (
 	OperatorLessThanOrEqual    ComparisonOperator = "<=" // Less than or equal to
 	OperatorEqual              ComparisonOperator = "==" // Equal to
 	OperatorNotEqual           ComparisonOperator = "!=" // Not equal to
+	OperatorMatches            ComparisonOperator = "=~" // Matches a regular expression (string metrics)
+	OperatorNotMatches         ComparisonOperator = "!~" // Does not match a regular expression (string metrics)
 )
 
+// Operators lists every comparison operator
+var Operators = []ComparisonOperator{
+	OperatorGreaterThan, OperatorGreaterThanOrEqual, OperatorLessThan,
+	OperatorLessThanOrEqual, OperatorEqual, OperatorNotEqual,
+	OperatorMatches, OperatorNotMatches,
+}
+
+// StringOperators lists the operators of string metrics
+var StringOperators = []ComparisonOperator{OperatorEqual, OperatorNotEqual, OperatorMatches, OperatorNotMatches}
+
 // AlertSeverity represents the importance/urgency of an alert
 type AlertSeverity string
 
@@ -48,24 +126,36 @@ const (
 	SeverityCritical AlertSeverity = "critical" // Critical, highest severity
 )
 
+// Severities lists every alert severity, lowest first
+var Severities = []AlertSeverity{SeverityInfo, SeverityWarning, SeverityCritical}
+
 // NotificationType represents the channel through which an alert is delivered
 type NotificationType string
 
 // Available notification channels
 const (
-	NotificationInApp NotificationType = "in-app" // In-application notification
-	NotificationEmail NotificationType = "email"  // Email notification
+	NotificationInApp   NotificationType = "in-app"  // In-application notification
+	NotificationEmail   NotificationType = "email"   // Email notification
+	NotificationWebhook NotificationType = "webhook" // JSON POST to a URL
+	NotificationJira    NotificationType = "jira"    // Jira issue for critical activations
 )
 
+// NotificationTypes lists every notification channel alerts can configure
+var NotificationTypes = []NotificationType{NotificationInApp, NotificationEmail, NotificationWebhook, NotificationJira}
+
 // ThresholdConfig defines a threshold condition that triggers an alert
 type ThresholdConfig struct {
 	MetricType   MetricType         `json:"metric_type"`
 	MetricName   string             `json:"metric_name"`
 	Operator     ComparisonOperator `json:"operator"`
-	Value        float64            `json:"value"`
+	Value        float64            `json:"value"`          // In the base unit of the metric (e.g. bytes)
+	Unit         Unit               `json:"unit,omitempty"` // Unit the value is displayed in, e.g. "MB"
 	Duration     time.Duration      `json:"duration,omitempty"`
 	SustainedFor int                `json:"sustained_for,omitempty"`
-	Target       *string            `json:"target,omitempty"` // For process-specific alerts
+	Target       *string            `json:"target,omitempty"` // Process, RAID array, ZFS pool, battery, UPS, service or endpoint name
+	Labels       map[string]string  `json:"labels,omitempty"` // Label selector of series, API, network and disk alerts
+	// StringValue is compared with string metrics; a regular expression for =~ and !~
+	StringValue string `json:"string_value,omitempty"`
 }
 
 // Validate checks if the threshold configuration is valid
@@ -73,54 +163,136 @@ func (t *ThresholdConfig) Validate() error {
 	if t.MetricType == "" {
 		return errors.New("metric type is required")
 	}
-	validMetricTypes := map[MetricType]bool{
-		MetricCPU:     true,
-		MetricMemory:  true,
-		MetricLoad:    true,
-		MetricNetwork: true,
-		MetricDisk:    true,
-		MetricProcess: true,
-	}
-	if !validMetricTypes[t.MetricType] {
+	if !slices.Contains(MetricTypes, t.MetricType) {
 		return fmt.Errorf("invalid metric type: %s", t.MetricType)
 	}
-	validOperators := map[ComparisonOperator]bool{
-		OperatorGreaterThan:        true,
-		OperatorGreaterThanOrEqual: true,
-		OperatorLessThan:           true,
-		OperatorLessThanOrEqual:    true,
-		OperatorEqual:              true,
-		OperatorNotEqual:           true,
-	}
-	if !validOperators[t.Operator] {
+	if !slices.Contains(Operators, t.Operator) {
 		return fmt.Errorf("invalid operator: %s", t.Operator)
 	}
-	// Validate metric name based on metric type (partial, see original for full logic)
-	switch t.MetricType {
-	case MetricCPU:
-		if t.MetricName != "usage_percent" && t.MetricName != "load1" &&
-			t.MetricName != "load5" && t.MetricName != "load15" {
-			return fmt.Errorf("invalid CPU metric name: %s", t.MetricName)
+	// Validate metric name based on metric type
+	if names, ok := MetricNames[t.MetricType]; ok && !slices.Contains(names, t.MetricName) {
+		return fmt.Errorf("invalid %s metric name: %s", metricTypeLabels[t.MetricType], t.MetricName)
+	}
+	if names, ok := SelectorLabels[t.MetricType]; ok {
+		for name := range t.Labels {
+			if !slices.Contains(names, name) {
+				return fmt.Errorf("invalid %s label %q: expected %s", metricTypeLabels[t.MetricType], name, strings.Join(names, ", "))
+			}
+		}
+	}
+	if t.MetricType == MetricSeries && t.MetricName == "" {
+		return errors.New("series alert requires a metric name")
+	}
+	if t.MetricType == MetricLog && t.MetricName == "" {
+		return errors.New("log alert requires the name of a log watch")
+	}
+	if t.MetricType == MetricHTTP && (t.Target == nil || *t.Target == "") {
+		return errors.New("http alert requires the name or URL of a health check endpoint as target")
+	}
+	if t.MetricType == MetricProcess {
+		if err := t.validateProcessTarget(); err != nil {
+			return err
 		}
-	case MetricMemory:
-		if t.MetricName != "used_percent" && t.MetricName != "used" &&
-			t.MetricName != "free" {
-			return fmt.Errorf("invalid memory metric name: %s", t.MetricName)
+	}
+	if t.StringMetric() {
+		return t.validateString()
+	}
+	if t.Operator == OperatorMatches || t.Operator == OperatorNotMatches {
+		return fmt.Errorf("operator %s only applies to string metrics", t.Operator)
+	}
+	return t.validateUnit()
+}
+
+// StringMetric reports whether the threshold watches a string metric
+func (t *ThresholdConfig) StringMetric() bool {
+	return slices.Contains(StringMetricNames[t.MetricType], t.MetricName)
+}
+
+// validateString checks the operator, target and pattern of a string metric
+func (t *ThresholdConfig) validateString() error {
+	if !slices.Contains(StringOperators, t.Operator) {
+		return fmt.Errorf("invalid operator for %s metric %s: %s (expected ==, !=, =~ or !~)", metricTypeLabels[t.MetricType], t.MetricName, t.Operator)
+	}
+	if t.Target == nil || *t.Target == "" {
+		return fmt.Errorf("%s metric %s requires a target", metricTypeLabels[t.MetricType], t.MetricName)
+	}
+	if t.Unit != "" {
+		return fmt.Errorf("metric %s/%s takes no unit", t.MetricType, t.MetricName)
+	}
+	if t.Operator == OperatorMatches || t.Operator == OperatorNotMatches {
+		if _, err := t.Pattern(); err != nil {
+			return fmt.Errorf("invalid string_value pattern %q: %w", t.StringValue, err)
 		}
-	case MetricNetwork:
-		if t.MetricName != "bytes_sent" && t.MetricName != "bytes_recv" &&
-			t.MetricName != "packets_sent" && t.MetricName != "packets_recv" {
-			return fmt.Errorf("invalid network metric name: %s", t.MetricName)
+	}
+	return nil
+}
+
+// SelectsLabels reports whether the threshold's labels select an entity with
+// the given labels: every threshold label must have the entity's value
+func (t *ThresholdConfig) SelectsLabels(labels map[string]string) bool {
+	for name, value := range t.Labels {
+		if labels[name] != value {
+			return false
 		}
 	}
+	return true
+}
+
+// validateProcessTarget checks that a process alert names its processes
+func (t *ThresholdConfig) validateProcessTarget() error {
+	if t.Target == nil || *t.Target == "" {
+		return errors.New("process alert requires a process name, name pattern or PID as target")
+	}
+	if _, err := path.Match(*t.Target, ""); err != nil {
+		return fmt.Errorf("invalid process name pattern %q: %w", *t.Target, err)
+	}
 	return nil
 }
 
+// MatchProcess reports whether a process alert targets a process: by PID, or
+// by a name matching the target as a glob pattern, e.g. "php-fpm*"
+func (t *ThresholdConfig) MatchProcess(name string, pid int32) bool {
+	if t.Target == nil {
+		return false
+	}
+	if matched, _ := path.Match(*t.Target, name); matched {
+		return true
+	}
+	return fmt.Sprint(pid) == *t.Target
+}
+
+// Pattern compiles the StringValue of =~ and !~ thresholds. It matches the
+// whole value, as in Prometheus: "fail.*" matches "failed" but not "unfailing".
+func (t *ThresholdConfig) Pattern() (*regexp.Regexp, error) {
+	return regexp.Compile("^(?:" + t.StringValue + ")$")
+}
+
+// MatchString reports whether a string metric value meets the threshold
+func (t *ThresholdConfig) MatchString(value string) (bool, error) {
+	switch t.Operator {
+	case OperatorEqual:
+		return value == t.StringValue, nil
+	case OperatorNotEqual:
+		return value != t.StringValue, nil
+	case OperatorMatches, OperatorNotMatches:
+		pattern, err := t.Pattern()
+		if err != nil {
+			return false, err
+		}
+		return pattern.MatchString(value) == (t.Operator == OperatorMatches), nil
+	}
+	return false, fmt.Errorf("invalid operator for string metric: %s", t.Operator)
+}
+
+// WebhookPayloadSetting is the webhook notification setting mapping the
+// default payload to the JSON body a receiver expects
+const WebhookPayloadSetting = "payload"
+
 // NotificationConfig defines how an alert is delivered
 type NotificationConfig struct {
 	Type     NotificationType       `json:"type"`
 	Enabled  bool                   `json:"enabled"`
-	Settings map[string]interface{} `json:"settings,omitempty"` // e.g., {"recipient": "user@example.com"}
+	Settings map[string]interface{} `json:"settings,omitempty"` // e.g., {"recipient": "user@example.com", "timezone": "Asia/Taipei"}
 }
 
 // Validate checks if the notification configuration is valid
@@ -128,11 +300,7 @@ func (n *NotificationConfig) Validate() error {
 	if n.Type == "" {
 		return errors.New("notification type is required")
 	}
-	validTypes := map[NotificationType]bool{
-		NotificationInApp: true,
-		NotificationEmail: true,
-	}
-	if !validTypes[n.Type] {
+	if !slices.Contains(NotificationTypes, n.Type) {
 		return fmt.Errorf("invalid notification type: %s", n.Type)
 	}
 	switch n.Type {
@@ -147,21 +315,81 @@ func (n *NotificationConfig) Validate() error {
 		if _, ok := recipient.(string); !ok || recipient == "" {
 			return errors.New("email recipient must be a non-empty string")
 		}
+	case NotificationWebhook:
+		target, _ := n.Settings["url"].(string)
+		if target == "" {
+			return errors.New("webhook notification requires a url")
+		}
+		if u, err := url.Parse(target); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("invalid webhook url %q: must be an http or https URL", target)
+		}
+		// Optional payload mapping replacing the default JSON body
+		if payload, ok := n.Settings[WebhookPayloadSetting]; ok {
+			switch payload.(type) {
+			case map[string]interface{}, []interface{}:
+			default:
+				return errors.New("webhook payload must be a JSON object or array")
+			}
+			if _, err := tmplfunc.ParseJSON(WebhookPayloadSetting, payload, tmplfunc.Limits{}); err != nil {
+				return fmt.Errorf("invalid webhook payload: %w", err)
+			}
+		}
+	case NotificationJira:
+		// The project and issue type default to the server's jira settings
+		for _, key := range []string{"project", "issue_type"} {
+			if v, ok := n.Settings[key]; ok {
+				if s, isString := v.(string); !isString || s == "" {
+					return fmt.Errorf("jira %s must be a non-empty string", key)
+				}
+			}
+		}
+		if labels, ok := n.Settings["labels"]; ok {
+			list, isList := labels.([]interface{})
+			if !isList {
+				return errors.New("jira labels must be a list of strings")
+			}
+			for i, label := range list {
+				text, isString := label.(string)
+				if !isString {
+					return errors.New("jira labels must be a list of strings")
+				}
+				if _, err := tmplfunc.ParseSandboxedText(fmt.Sprintf("labels[%d]", i), text, tmplfunc.Limits{}); err != nil {
+					return fmt.Errorf("invalid jira label: %w", err)
+				}
+			}
+		}
+	}
+	// Optional timezone used to render event times for this channel
+	if tz, ok := n.Settings["timezone"]; ok {
+		name, isString := tz.(string)
+		if !isString {
+			return errors.New("notification timezone must be a string")
+		}
+		if _, err := time.LoadLocation(name); err != nil {
+			return fmt.Errorf("invalid notification timezone %q: %w", name, err)
+		}
 	}
 	return nil
 }
 
 // AlertConfig defines a complete alert configuration
 type AlertConfig struct {
-	ID            string               `json:"id"`
-	Name          string               `json:"name"`
-	Description   string               `json:"description,omitempty"`
-	Enabled       bool                 `json:"enabled"`
-	Severity      AlertSeverity        `json:"severity"`
-	Threshold     ThresholdConfig      `json:"threshold"`
-	Notifications []NotificationConfig `json:"notifications"`
-	CreatedAt     time.Time            `json:"created_at"`
-	UpdatedAt     time.Time            `json:"updated_at"`
+	ID               string               `json:"id"`
+	Name             string               `json:"name"`
+	Description      string               `json:"description,omitempty"`
+	Enabled          bool                 `json:"enabled"`
+	Severity         AlertSeverity        `json:"severity"`
+	Threshold        ThresholdConfig      `json:"threshold"`
+	Notifications    []NotificationConfig `json:"notifications"`
+	Labels           map[string]string    `json:"labels,omitempty"`             // Custom labels for silences and the Alertmanager API
+	GroupID          string               `json:"group_id,omitempty"`           // ID of the AlertGroup the alert is filed under
+	Owner            string               `json:"owner,omitempty"`              // Person responsible, e.g. "Alice Chen"
+	Team             string               `json:"team,omitempty"`               // Owning team, e.g. "storage"
+	Contact          string               `json:"contact,omitempty"`            // Email, URL or chat handle to reach the owner
+	AutoResolveAfter string               `json:"auto_resolve_after,omitempty"` // Resolve a firing alert after this long without data, e.g. "30m"
+	Remediation      *RemediationConfig   `json:"remediation,omitempty"`        // Task run when the alert activates
+	CreatedAt        time.Time            `json:"created_at"`
+	UpdatedAt        time.Time            `json:"updated_at"`
 }
 
 // Validate checks if the alert configuration is valid
@@ -172,20 +400,54 @@ func (a *AlertConfig) Validate() error {
 	if a.Name == "" {
 		return errors.New("alert name is required")
 	}
-	validSeverities := map[AlertSeverity]bool{
-		SeverityInfo:     true,
-		SeverityWarning:  true,
-		SeverityCritical: true,
-	}
-	if !validSeverities[a.Severity] {
+	if !slices.Contains(Severities, a.Severity) {
 		return fmt.Errorf("invalid severity: %s", a.Severity)
 	}
 	if err := a.Threshold.Validate(); err != nil {
 		return fmt.Errorf("invalid threshold: %w", err)
 	}
+	if err := validateOwnership(a.Owner, a.Team, a.Contact); err != nil {
+		return fmt.Errorf("invalid ownership: %w", err)
+	}
+	if a.AutoResolveAfter != "" {
+		if d, err := time.ParseDuration(a.AutoResolveAfter); err != nil || d <= 0 {
+			return fmt.Errorf("invalid auto_resolve_after %q: must be a positive duration", a.AutoResolveAfter)
+		}
+	}
+	if a.Remediation != nil {
+		if err := a.Remediation.Validate(); err != nil {
+			return fmt.Errorf("invalid remediation: %w", err)
+		}
+	}
 	return nil
 }
 
+// Overlay lays the fields of a JSON object over the alert. Fields given
+// replace the alert's, except objects and maps, which are merged: a threshold
+// of {"value": 85} keeps the metric and operator. Notifications are replaced
+// as a whole.
+func (a *AlertConfig) Overlay(fields []byte) error {
+	var keys map[string]json.RawMessage
+	if err := json.Unmarshal(fields, &keys); err != nil {
+		return err
+	}
+	if _, ok := keys["notifications"]; ok {
+		// Decoding into the existing slice would merge into its elements
+		a.Notifications = nil
+	}
+	return json.Unmarshal(fields, a)
+}
+
+// AutoResolveTimeout returns how long a firing alert may have no data before
+// it is resolved, zero when it never is
+func (a *AlertConfig) AutoResolveTimeout() time.Duration {
+	d, err := time.ParseDuration(a.AutoResolveAfter)
+	if err != nil || d <= 0 {
+		return 0
+	}
+	return d
+}
+
 // AlertState represents the state of an alert
 type AlertState string
 
@@ -197,6 +459,13 @@ const (
 	StateResolved AlertState = "resolved"
 )
 
+// Firing reports whether the state is one an alert notifies in. The
+// evaluator moves a breaching alert to pending, so pending counts as firing
+// alongside active.
+func (s AlertState) Firing() bool {
+	return s == StatePending || s == StateActive
+}
+
 // AlertStatus represents the current status of an alert
 type AlertStatus struct {
 	AlertID      string     `json:"alert_id"`
@@ -205,4 +474,9 @@ type AlertStatus struct {
 	TriggeredAt  *time.Time `json:"triggered_at,omitempty"`
 	ResolvedAt   *time.Time `json:"resolved_at,omitempty"`
 	Message      string     `json:"message,omitempty"`
+	NoData       bool       `json:"no_data,omitempty"`        // The last evaluation had no value to compare
+	NoDataSince  *time.Time `json:"no_data_since,omitempty"`  // Start of the current no-data period
+	NoDataReason string     `json:"no_data_reason,omitempty"` // Why the metric could not be evaluated
+	Source       string     `json:"source,omitempty"`         // Where the alert originated, see AlertEvent.Source
+	StringValue  string     `json:"string_value,omitempty"`   // Current value of string metrics, e.g. "failed"
 }
@@ -0,0 +1,69 @@
+// File: internal/models/alert_defaults.go
+// Brief: Standard alerts every host can start with
+// Detailed: Defines the default CPU, memory and disk alerts by template name. They are created through POST /api/alerts/defaults or the provisioned_alerts section of config.yaml, which can override their fields. Each default has a fixed ID, so creating the defaults again finds the alerts already stored rather than duplicating them.
+// Author: drama.lin@aver.com
+// Date: 2026-10-14
+
+package models
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// DefaultAlertIDPrefix prefixes the IDs of the default alerts, e.g. default-cpu
+const DefaultAlertIDPrefix = "default-"
+
+// DefaultAlertNames lists the template names of the default alerts
+var DefaultAlertNames = []string{"cpu", "memory", "disk", "disk-critical", "inodes"}
+
+// defaultAlerts holds the default alerts by template name
+var defaultAlerts = map[string]AlertConfig{
+	"cpu": {
+		Name:        "High CPU usage",
+		Description: "CPU usage is above 90%",
+		Severity:    SeverityWarning,
+		Threshold:   ThresholdConfig{MetricType: MetricCPU, MetricName: "usage_percent", Operator: OperatorGreaterThan, Value: 90},
+	},
+	"memory": {
+		Name:        "High memory usage",
+		Description: "Memory usage is above 90%",
+		Severity:    SeverityWarning,
+		Threshold:   ThresholdConfig{MetricType: MetricMemory, MetricName: "used_percent", Operator: OperatorGreaterThan, Value: 90},
+	},
+	"disk": {
+		Name:        "Low disk space",
+		Description: "The fullest partition is more than 85% used",
+		Severity:    SeverityWarning,
+		Threshold:   ThresholdConfig{MetricType: MetricDisk, MetricName: "usage_percent", Operator: OperatorGreaterThan, Value: 85},
+	},
+	"disk-critical": {
+		Name:        "Disk almost full",
+		Description: "The fullest partition is more than 95% used",
+		Severity:    SeverityCritical,
+		Threshold:   ThresholdConfig{MetricType: MetricDisk, MetricName: "usage_percent", Operator: OperatorGreaterThan, Value: 95},
+	},
+	"inodes": {
+		Name:        "Inodes running out",
+		Description: "A partition has used more than 90% of its inodes",
+		Severity:    SeverityWarning,
+		Threshold:   ThresholdConfig{MetricType: MetricDisk, MetricName: "inode_percent", Operator: OperatorGreaterThan, Value: 90},
+	},
+}
+
+// CreateDefaultAlertConfig returns a new, enabled copy of the default alert
+// with the given template name, notifying in-app
+func CreateDefaultAlertConfig(name string, now time.Time) (*AlertConfig, error) {
+	template, ok := defaultAlerts[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown default alert %q: expected %s", name, strings.Join(DefaultAlertNames, ", "))
+	}
+	alert := template
+	alert.ID = DefaultAlertIDPrefix + name
+	alert.Enabled = true
+	alert.Notifications = []NotificationConfig{{Type: NotificationInApp, Enabled: true}}
+	alert.CreatedAt = now
+	alert.UpdatedAt = now
+	return &alert, nil
+}
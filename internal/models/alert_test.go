@@ -45,6 +45,106 @@ func TestThresholdConfigValidate(t *testing.T) {
 			},
 			expectError: false,
 		},
+		{
+			name: "Valid disk threshold",
+			threshold: ThresholdConfig{
+				MetricType: MetricDisk,
+				MetricName: "usage_percent",
+				Operator:   OperatorGreaterThan,
+				Value:      90,
+			},
+			expectError: false,
+		},
+		{
+			name: "Invalid disk metric name",
+			threshold: ThresholdConfig{
+				MetricType: MetricDisk,
+				MetricName: "read_bytes",
+				Operator:   OperatorGreaterThan,
+				Value:      1000,
+			},
+			expectError: true,
+		},
+		{
+			name: "Valid API error rate threshold",
+			threshold: ThresholdConfig{
+				MetricType: MetricAPI,
+				MetricName: "error_rate_percent",
+				Operator:   OperatorGreaterThan,
+				Value:      5,
+				Labels:     map[string]string{"namespace": "alerts"},
+			},
+			expectError: false,
+		},
+		{
+			name: "Invalid API metric name",
+			threshold: ThresholdConfig{
+				MetricType: MetricAPI,
+				MetricName: "p99",
+				Operator:   OperatorGreaterThan,
+				Value:      5,
+			},
+			expectError: true,
+		},
+		{
+			name: "Valid log threshold",
+			threshold: ThresholdConfig{
+				MetricType: MetricLog,
+				MetricName: "oom-kills",
+				Operator:   OperatorGreaterThan,
+				Value:      0,
+			},
+			expectError: false,
+		},
+		{
+			name: "Log threshold without a watch",
+			threshold: ThresholdConfig{
+				MetricType: MetricLog,
+				Operator:   OperatorGreaterThan,
+				Value:      0,
+			},
+			expectError: true,
+		},
+		{
+			name: "Valid RAID degraded threshold",
+			threshold: ThresholdConfig{
+				MetricType: MetricRAID,
+				MetricName: "degraded",
+				Operator:   OperatorGreaterThan,
+				Value:      0,
+			},
+			expectError: false,
+		},
+		{
+			name: "Invalid RAID metric name",
+			threshold: ThresholdConfig{
+				MetricType: MetricRAID,
+				MetricName: "temperature",
+				Operator:   OperatorGreaterThan,
+				Value:      0,
+			},
+			expectError: true,
+		},
+		{
+			name: "Valid power runtime threshold",
+			threshold: ThresholdConfig{
+				MetricType: MetricPower,
+				MetricName: "runtime_ms",
+				Operator:   OperatorLessThan,
+				Value:      600000,
+			},
+			expectError: false,
+		},
+		{
+			name: "Valid service down threshold",
+			threshold: ThresholdConfig{
+				MetricType: MetricService,
+				MetricName: "down",
+				Operator:   OperatorGreaterThan,
+				Value:      0,
+			},
+			expectError: false,
+		},
 		{
 			name: "Missing metric type",
 			threshold: ThresholdConfig{
@@ -106,6 +206,120 @@ func TestThresholdConfigValidate(t *testing.T) {
 	}
 }
 
+func TestThresholdConfigValidate_StringMetrics(t *testing.T) {
+	target := func(name string) *string { return &name }
+	valid := []ThresholdConfig{
+		{MetricType: MetricService, MetricName: "active_state", Operator: OperatorEqual, StringValue: "failed", Target: target("nginx")},
+		{MetricType: MetricRAID, MetricName: "state", Operator: OperatorMatches, StringValue: "degraded|faulted", Target: target("md0")},
+		{MetricType: MetricHTTP, MetricName: "status", Operator: OperatorNotEqual, StringValue: "200", Target: target("api")},
+		{MetricType: MetricHTTP, MetricName: "duration_ms", Operator: OperatorGreaterThan, Value: 500, Unit: UnitMilliseconds, Target: target("api")},
+	}
+	for _, threshold := range valid {
+		assert.NoError(t, threshold.Validate(), "%s/%s", threshold.MetricType, threshold.MetricName)
+	}
+
+	for threshold, want := range map[*ThresholdConfig]string{
+		{MetricType: MetricService, MetricName: "active_state", Operator: OperatorGreaterThan, StringValue: "failed", Target: target("nginx")}: "expected ==, !=, =~ or !~",
+		{MetricType: MetricService, MetricName: "sub_state", Operator: OperatorEqual, StringValue: "dead"}:                                     "requires a target",
+		{MetricType: MetricRAID, MetricName: "state", Operator: OperatorMatches, StringValue: "(degraded", Target: target("md0")}:              "invalid string_value pattern",
+		{MetricType: MetricRAID, MetricName: "state", Operator: OperatorEqual, Unit: UnitPercent, Target: target("md0")}:                       "takes no unit",
+		{MetricType: MetricCPU, MetricName: "usage_percent", Operator: OperatorMatches, StringValue: "9.*"}:                                    "only applies to string metrics",
+		{MetricType: MetricHTTP, MetricName: "healthy", Operator: OperatorEqual, Value: 0}:                                                     "requires the name or URL of a health check endpoint",
+	} {
+		assert.ErrorContains(t, threshold.Validate(), want, "%s/%s", threshold.MetricType, threshold.MetricName)
+	}
+}
+
+func TestThresholdConfigMatchString(t *testing.T) {
+	for _, tt := range []struct {
+		operator ComparisonOperator
+		pattern  string
+		value    string
+		want     bool
+	}{
+		{OperatorEqual, "failed", "failed", true},
+		{OperatorEqual, "failed", "active", false},
+		{OperatorNotEqual, "200", "503", true},
+		{OperatorNotEqual, "200", "200", false},
+		{OperatorMatches, "fail.*", "failed", true},
+		{OperatorMatches, "fail.*", "unfailing", false},
+		{OperatorMatches, "5..", "503", true},
+		{OperatorNotMatches, "2..", "200", false},
+		{OperatorNotMatches, "2..", "", true},
+	} {
+		threshold := ThresholdConfig{Operator: tt.operator, StringValue: tt.pattern}
+		got, err := threshold.MatchString(tt.value)
+		require.NoError(t, err)
+		assert.Equal(t, tt.want, got, "%q %s %q", tt.value, tt.operator, tt.pattern)
+	}
+
+	_, err := (&ThresholdConfig{Operator: OperatorGreaterThan}).MatchString("x")
+	assert.Error(t, err)
+}
+
+func TestThresholdConfigValidate_Process(t *testing.T) {
+	target := func(name string) *string { return &name }
+	valid := []ThresholdConfig{
+		{MetricType: MetricProcess, MetricName: "cpu_percent", Operator: OperatorGreaterThan, Value: 80, Target: target("nginx")},
+		{MetricType: MetricProcess, MetricName: "count", Operator: OperatorLessThan, Value: 1, Target: target("php-fpm*")},
+		{MetricType: MetricProcess, MetricName: "count", Operator: OperatorEqual, Value: 0, Target: target("1234")},
+	}
+	for _, threshold := range valid {
+		assert.NoError(t, threshold.Validate(), *threshold.Target)
+	}
+
+	missing := ThresholdConfig{MetricType: MetricProcess, MetricName: "count", Operator: OperatorLessThan, Value: 1}
+	assert.ErrorContains(t, missing.Validate(), "requires a process name")
+	bad := ThresholdConfig{MetricType: MetricProcess, MetricName: "count", Operator: OperatorLessThan, Value: 1, Target: target("sshd[")}
+	assert.ErrorContains(t, bad.Validate(), "invalid process name pattern")
+}
+
+func TestThresholdConfigMatchProcess(t *testing.T) {
+	for _, tt := range []struct {
+		target string
+		name   string
+		pid    int32
+		want   bool
+	}{
+		{"sshd", "sshd", 10, true},
+		{"sshd", "sshd-session", 10, false},
+		{"php-fpm*", "php-fpm8.2", 10, true},
+		{"php-fpm*", "php", 10, false},
+		{"worker-[0-9]", "worker-3", 10, true},
+		{"42", "postgres", 42, true},
+		{"42", "postgres", 43, false},
+	} {
+		threshold := ThresholdConfig{MetricType: MetricProcess, Target: &tt.target}
+		assert.Equal(t, tt.want, threshold.MatchProcess(tt.name, tt.pid), "%s against %s (%d)", tt.target, tt.name, tt.pid)
+	}
+	assert.False(t, (&ThresholdConfig{MetricType: MetricProcess}).MatchProcess("sshd", 1), "no target matches nothing")
+}
+
+func TestThresholdConfigValidate_Labels(t *testing.T) {
+	valid := []ThresholdConfig{
+		{MetricType: MetricNetwork, MetricName: "bytes_recv", Operator: OperatorGreaterThan, Value: 1e9, Labels: map[string]string{"interface": "eth0"}},
+		{MetricType: MetricDisk, MetricName: "usage_percent", Operator: OperatorGreaterThan, Value: 90, Labels: map[string]string{"mountpoint": "/data", "fstype": "ext4"}},
+		{MetricType: MetricAPI, MetricName: "error_rate_percent", Operator: OperatorGreaterThan, Value: 5, Labels: map[string]string{"namespace": "alerts"}},
+		{MetricType: MetricSeries, MetricName: "queue_depth", Operator: OperatorGreaterThan, Value: 100, Labels: map[string]string{"queue": "mail"}},
+	}
+	for _, threshold := range valid {
+		assert.NoError(t, threshold.Validate(), "%s %v", threshold.MetricType, threshold.Labels)
+	}
+
+	unknown := ThresholdConfig{MetricType: MetricNetwork, MetricName: "bytes_recv", Operator: OperatorGreaterThan, Value: 1e9, Labels: map[string]string{"mountpoint": "/data"}}
+	assert.ErrorContains(t, unknown.Validate(), `invalid network label "mountpoint": expected interface`)
+	unknown = ThresholdConfig{MetricType: MetricDisk, MetricName: "free", Operator: OperatorLessThan, Value: 1e9, Labels: map[string]string{"interface": "eth0"}}
+	assert.ErrorContains(t, unknown.Validate(), "expected mountpoint, device, fstype")
+}
+
+func TestThresholdConfigSelectsLabels(t *testing.T) {
+	threshold := ThresholdConfig{MetricType: MetricDisk, Labels: map[string]string{"mountpoint": "/data", "fstype": "xfs"}}
+	assert.True(t, threshold.SelectsLabels(map[string]string{"mountpoint": "/data", "device": "/dev/sdb1", "fstype": "xfs"}))
+	assert.False(t, threshold.SelectsLabels(map[string]string{"mountpoint": "/data", "device": "/dev/sdb1", "fstype": "ext4"}))
+	assert.False(t, threshold.SelectsLabels(map[string]string{"mountpoint": "/"}), "every label must match")
+	assert.True(t, (&ThresholdConfig{MetricType: MetricDisk}).SelectsLabels(map[string]string{"mountpoint": "/"}), "no labels select everything")
+}
+
 func TestAlertConfigValidate(t *testing.T) {
 	tests := []struct {
 		name        string
@@ -218,6 +432,22 @@ func TestAlertConfigValidate(t *testing.T) {
 			},
 			expectError: true,
 		},
+		{
+			name: "Invalid auto-resolve timeout",
+			config: AlertConfig{
+				ID:       "test-alert-3",
+				Name:     "Endpoint down",
+				Severity: SeverityWarning,
+				Threshold: ThresholdConfig{
+					MetricType: MetricCPU,
+					MetricName: "usage_percent",
+					Operator:   OperatorGreaterThan,
+					Value:      90.0,
+				},
+				AutoResolveAfter: "soon",
+			},
+			expectError: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -266,6 +496,90 @@ func TestNotificationConfigValidate(t *testing.T) {
 			},
 			expectError: true,
 		},
+		{
+			name: "Valid webhook notification",
+			config: NotificationConfig{
+				Type:     NotificationWebhook,
+				Enabled:  true,
+				Settings: map[string]interface{}{"url": "https://hooks.example.com/argus"},
+			},
+			expectError: false,
+		},
+		{
+			name: "Webhook notification with invalid url",
+			config: NotificationConfig{
+				Type:     NotificationWebhook,
+				Enabled:  true,
+				Settings: map[string]interface{}{"url": "hooks.example.com"},
+			},
+			expectError: true,
+		},
+		{
+			name: "Webhook notification with payload mapping",
+			config: NotificationConfig{
+				Type:    NotificationWebhook,
+				Enabled: true,
+				Settings: map[string]interface{}{"url": "https://hooks.example.com/argus", "payload": map[string]interface{}{
+					"summary": "{{ .AlertName }}", "labels": []interface{}{"argus"},
+				}},
+			},
+			expectError: false,
+		},
+		{
+			name: "Webhook notification with invalid payload template",
+			config: NotificationConfig{
+				Type:    NotificationWebhook,
+				Enabled: true,
+				Settings: map[string]interface{}{"url": "https://hooks.example.com/argus", "payload": map[string]interface{}{
+					"summary": "{{ .AlertName",
+				}},
+			},
+			expectError: true,
+		},
+		{
+			name: "Webhook notification with scalar payload",
+			config: NotificationConfig{
+				Type:     NotificationWebhook,
+				Enabled:  true,
+				Settings: map[string]interface{}{"url": "https://hooks.example.com/argus", "payload": "{{ .AlertName }}"},
+			},
+			expectError: true,
+		},
+		{
+			name: "Jira notification with server defaults",
+			config: NotificationConfig{
+				Type:    NotificationJira,
+				Enabled: true,
+			},
+			expectError: false,
+		},
+		{
+			name: "Jira notification with project and label templates",
+			config: NotificationConfig{
+				Type:     NotificationJira,
+				Enabled:  true,
+				Settings: map[string]interface{}{"project": "OPS", "issue_type": "Incident", "labels": []interface{}{"argus", "team-{{ .Labels.team }}"}},
+			},
+			expectError: false,
+		},
+		{
+			name: "Jira notification with invalid label template",
+			config: NotificationConfig{
+				Type:     NotificationJira,
+				Enabled:  true,
+				Settings: map[string]interface{}{"labels": []interface{}{"{{ .Labels.team "}},
+			},
+			expectError: true,
+		},
+		{
+			name: "Jira notification with empty project",
+			config: NotificationConfig{
+				Type:     NotificationJira,
+				Enabled:  true,
+				Settings: map[string]interface{}{"project": ""},
+			},
+			expectError: true,
+		},
 		{
 			name: "Invalid notification type",
 			config: NotificationConfig{
@@ -456,3 +770,51 @@ func TestAlertConfigSerialization(t *testing.T) {
 	assert.WithinDuration(t, config.CreatedAt, decoded.CreatedAt, time.Second)
 	assert.WithinDuration(t, config.UpdatedAt, decoded.UpdatedAt, time.Second)
 }
+
+func TestAlertConfigAutoResolveTimeout(t *testing.T) {
+	config := AlertConfig{ID: "a", Name: "a", Severity: SeverityInfo,
+		Threshold: ThresholdConfig{MetricType: MetricCPU, MetricName: "usage_percent", Operator: OperatorGreaterThan}}
+	assert.Zero(t, config.AutoResolveTimeout(), "no auto-resolution by default")
+
+	config.AutoResolveAfter = "30m"
+	assert.NoError(t, config.Validate())
+	assert.Equal(t, 30*time.Minute, config.AutoResolveTimeout())
+
+	config.AutoResolveAfter = "-5m"
+	assert.Error(t, config.Validate())
+	assert.Zero(t, config.AutoResolveTimeout())
+}
+
+func TestCreateDefaultAlertConfig(t *testing.T) {
+	now := time.Date(2026, 10, 14, 0, 0, 0, 0, time.UTC)
+	for _, name := range DefaultAlertNames {
+		alert, err := CreateDefaultAlertConfig(name, now)
+		require.NoError(t, err, name)
+		assert.NoError(t, alert.Validate(), name)
+		assert.Equal(t, DefaultAlertIDPrefix+name, alert.ID)
+		assert.True(t, alert.Enabled)
+		assert.Equal(t, []NotificationConfig{{Type: NotificationInApp, Enabled: true}}, alert.Notifications)
+		assert.Equal(t, now, alert.CreatedAt)
+	}
+
+	first, _ := CreateDefaultAlertConfig("cpu", now)
+	first.Threshold.Value = 50
+	second, _ := CreateDefaultAlertConfig("cpu", now)
+	assert.Equal(t, 90.0, second.Threshold.Value, "each call returns a new copy")
+
+	_, err := CreateDefaultAlertConfig("swap", now)
+	assert.EqualError(t, err, `unknown default alert "swap": expected cpu, memory, disk, disk-critical, inodes`)
+}
+
+func TestAlertConfigOverlay(t *testing.T) {
+	alert, err := CreateDefaultAlertConfig("disk", time.Now())
+	require.NoError(t, err)
+
+	require.NoError(t, alert.Overlay([]byte(`{"severity": "critical", "threshold": {"value": 95}, "notifications": [{"type": "webhook", "enabled": true}]}`)))
+	assert.Equal(t, SeverityCritical, alert.Severity)
+	assert.Equal(t, MetricDisk, alert.Threshold.MetricType, "objects are merged")
+	assert.Equal(t, 95.0, alert.Threshold.Value)
+	assert.Equal(t, []NotificationConfig{{Type: NotificationWebhook, Enabled: true}}, alert.Notifications, "notifications are replaced")
+
+	assert.Error(t, alert.Overlay([]byte(`["severity"]`)))
+}
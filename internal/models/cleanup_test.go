@@ -0,0 +1,54 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseCleanupStats(t *testing.T) {
+	stats, ok := ParseCleanupStats(map[string]string{ItemsRemovedMetadata: "12", BytesReclaimedMetadata: "4096"})
+	require.True(t, ok)
+	assert.Equal(t, CleanupStats{ItemsRemoved: 12, BytesReclaimed: 4096}, stats)
+
+	_, ok = ParseCleanupStats(map[string]string{"endpoints": "3"})
+	assert.False(t, ok, "runs not reporting reclaimed space have no stats")
+	_, ok = ParseCleanupStats(nil)
+	assert.False(t, ok)
+}
+
+func TestCompareCleanup(t *testing.T) {
+	start := time.Date(2026, 10, 14, 3, 0, 0, 0, time.UTC)
+	previous := &TaskExecution{ExecutionID: "e1", StartTime: start, Metadata: map[string]string{ItemsRemovedMetadata: "100", BytesReclaimedMetadata: "1000000"}}
+	current := &TaskExecution{ExecutionID: "e2", StartTime: start.Add(4 * time.Hour), Metadata: map[string]string{ItemsRemovedMetadata: "150", BytesReclaimedMetadata: "3000000"}}
+
+	comparison, ok := CompareCleanup(current, previous)
+	require.True(t, ok)
+	assert.Equal(t, "e1", comparison.PreviousExecution)
+	assert.Equal(t, int64(50), comparison.ItemsRemovedDelta)
+	assert.Equal(t, int64(2000000), comparison.BytesReclaimedDelta)
+	require.NotNil(t, comparison.GrowthPercent)
+	assert.InDelta(t, 200, *comparison.GrowthPercent, 0.001)
+	require.NotNil(t, comparison.BytesPerHour)
+	assert.InDelta(t, 750000, *comparison.BytesPerHour, 0.001)
+	assert.Equal(t, map[string]string{
+		PreviousExecutionMetadata:     "e1",
+		ItemsRemovedDeltaMetadata:     "50",
+		BytesReclaimedDeltaMetadata:   "2000000",
+		BytesReclaimedGrowthMetadata:  "200.0",
+		BytesReclaimedPerHourMetadata: "750000",
+	}, comparison.Metadata())
+
+	previous.Metadata[BytesReclaimedMetadata] = "0"
+	previous.StartTime = current.StartTime
+	comparison, ok = CompareCleanup(current, previous)
+	require.True(t, ok)
+	assert.Nil(t, comparison.GrowthPercent, "no growth from nothing")
+	assert.Nil(t, comparison.BytesPerHour, "no rate without elapsed time")
+	assert.NotContains(t, comparison.Metadata(), BytesReclaimedGrowthMetadata)
+
+	_, ok = CompareCleanup(current, &TaskExecution{ExecutionID: "e0", StartTime: start})
+	assert.False(t, ok, "a previous run without stats is not compared")
+}
@@ -0,0 +1,192 @@
+// File: internal/models/dashboard.go
+// Brief: Dashboard model for Argus
+// Detailed: Contains the Dashboard type, a named list of widgets (gauge, top list, sparkline, alert list) whose payloads the server computes so thin clients such as wall-mounted displays only have to draw them.
+// Author: drama.lin@aver.com
+// Date: 2026-10-14
+
+package models
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+)
+
+// WidgetType is the kind of a dashboard widget
+type WidgetType string
+
+// Supported widget types
+const (
+	WidgetGauge     WidgetType = "gauge"      // Latest value of a query against its bounds and thresholds
+	WidgetTopList   WidgetType = "top_list"   // Series of a query, or processes, ranked by latest value
+	WidgetSparkline WidgetType = "sparkline"  // Recent history of a query
+	WidgetAlertList WidgetType = "alert_list" // Firing alerts, most severe first
+)
+
+// WidgetTypes lists every widget type
+var WidgetTypes = []WidgetType{WidgetGauge, WidgetTopList, WidgetSparkline, WidgetAlertList}
+
+// Processes a top list can rank by
+const (
+	ProcessesByCPU    = "cpu"
+	ProcessesByMemory = "memory"
+)
+
+// Limits and defaults of widget settings
+const (
+	MaxWidgetLimit  = 100   // Rows of a top or alert list
+	MaxWidgetPoints = 1000  // Points of a sparkline
+	DefaultGaugeMax = 100.0 // Gauge max when none is set
+)
+
+// Widget is one cell of a dashboard. Which fields apply depends on Type.
+type Widget struct {
+	ID    string     `json:"id"`
+	Title string     `json:"title,omitempty"`
+	Type  WidgetType `json:"type"`
+
+	// Gauge, sparkline and top list: a metric history query such as
+	// avg(cpu_usage_percent) or max by (host) (node_load1)
+	Query string `json:"query,omitempty"`
+	Unit  Unit   `json:"unit,omitempty"` // Unit values are displayed in
+
+	// Gauge bounds and thresholds; Critical below Warning means lower values
+	// are worse
+	Min      float64  `json:"min,omitempty"`
+	Max      float64  `json:"max,omitempty"` // Defaults to DefaultGaugeMax
+	Warning  *float64 `json:"warning,omitempty"`
+	Critical *float64 `json:"critical,omitempty"`
+
+	// Sparkline window and number of points
+	Range  string `json:"range,omitempty"`  // Defaults to 1h
+	Points int    `json:"points,omitempty"` // Defaults to 60
+
+	// Top list of processes instead of series: "cpu" or "memory"
+	Processes string `json:"processes,omitempty"`
+
+	// Rows of a top or alert list (default 10)
+	Limit int `json:"limit,omitempty"`
+
+	// Alert list filters
+	Severity AlertSeverity `json:"severity,omitempty"` // Lowest severity listed
+	Owner    string        `json:"owner,omitempty"`
+	Team     string        `json:"team,omitempty"`
+	GroupID  string        `json:"group_id,omitempty"`
+}
+
+// Validate checks the widget's type and the settings it uses. Queries are
+// checked by the widget renderer.
+func (w *Widget) Validate() error {
+	if w.ID == "" {
+		return errors.New("widget ID is required")
+	}
+	if !slices.Contains(WidgetTypes, w.Type) {
+		return fmt.Errorf("invalid widget type: %q", w.Type)
+	}
+	if !w.Unit.Valid() {
+		return fmt.Errorf("invalid unit: %q", w.Unit)
+	}
+	if w.Limit < 0 || w.Limit > MaxWidgetLimit {
+		return fmt.Errorf("limit must be between 0 and %d", MaxWidgetLimit)
+	}
+	switch w.Type {
+	case WidgetGauge:
+		if w.Query == "" {
+			return errors.New("gauge requires a query")
+		}
+		if w.GaugeMax() <= w.Min {
+			return errors.New("gauge max must be greater than min")
+		}
+	case WidgetSparkline:
+		if w.Query == "" {
+			return errors.New("sparkline requires a query")
+		}
+		if w.Range != "" {
+			if d, err := time.ParseDuration(w.Range); err != nil || d <= 0 {
+				return fmt.Errorf("invalid sparkline range: %q", w.Range)
+			}
+		}
+		if w.Points < 0 || w.Points > MaxWidgetPoints {
+			return fmt.Errorf("sparkline points must be between 0 and %d", MaxWidgetPoints)
+		}
+	case WidgetTopList:
+		if (w.Query == "") == (w.Processes == "") {
+			return errors.New("top list requires either a query or processes")
+		}
+		if w.Processes != "" && w.Processes != ProcessesByCPU && w.Processes != ProcessesByMemory {
+			return fmt.Errorf("invalid processes: %q, must be cpu or memory", w.Processes)
+		}
+	case WidgetAlertList:
+		if w.Severity != "" && !slices.Contains(Severities, w.Severity) {
+			return fmt.Errorf("invalid severity: %q", w.Severity)
+		}
+	}
+	return nil
+}
+
+// GaugeMax returns the upper bound of a gauge
+func (w *Widget) GaugeMax() float64 {
+	if w.Max == 0 {
+		return DefaultGaugeMax
+	}
+	return w.Max
+}
+
+// Dashboard is a stored layout of widgets
+type Dashboard struct {
+	ID          string    `json:"id"`
+	Name        string    `json:"name"`
+	Description string    `json:"description,omitempty"`
+	Refresh     string    `json:"refresh,omitempty"` // How often clients should reload the data, e.g. "30s"
+	Widgets     []Widget  `json:"widgets"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// Widget returns the widget with the given ID
+func (d *Dashboard) Widget(id string) (*Widget, bool) {
+	for i := range d.Widgets {
+		if d.Widgets[i].ID == id {
+			return &d.Widgets[i], true
+		}
+	}
+	return nil, false
+}
+
+// AssignWidgetIDs names the widgets without an ID "widget-<n>" after their
+// position
+func (d *Dashboard) AssignWidgetIDs() {
+	for i := range d.Widgets {
+		if d.Widgets[i].ID == "" {
+			d.Widgets[i].ID = fmt.Sprintf("widget-%d", i+1)
+		}
+	}
+}
+
+// Validate checks the dashboard and its widgets
+func (d *Dashboard) Validate() error {
+	if d.ID == "" {
+		return errors.New("dashboard ID is required")
+	}
+	if d.Name == "" {
+		return errors.New("dashboard name is required")
+	}
+	if d.Refresh != "" {
+		if r, err := time.ParseDuration(d.Refresh); err != nil || r <= 0 {
+			return fmt.Errorf("invalid refresh: %q", d.Refresh)
+		}
+	}
+	seen := make(map[string]bool, len(d.Widgets))
+	for i := range d.Widgets {
+		w := &d.Widgets[i]
+		if err := w.Validate(); err != nil {
+			return fmt.Errorf("widget %d: %w", i+1, err)
+		}
+		if seen[w.ID] {
+			return fmt.Errorf("duplicate widget ID: %s", w.ID)
+		}
+		seen[w.ID] = true
+	}
+	return nil
+}
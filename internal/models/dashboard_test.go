@@ -0,0 +1,52 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDashboard_Validate(t *testing.T) {
+	valid := func() Dashboard {
+		return Dashboard{ID: "d1", Name: "Ops", Refresh: "30s", Widgets: []Widget{
+			{Type: WidgetGauge, Query: "avg(cpu_usage_percent)"},
+			{Type: WidgetTopList, Processes: ProcessesByMemory, Limit: 5},
+			{Type: WidgetSparkline, Query: "node_load1", Range: "6h", Points: 120},
+			{Type: WidgetAlertList, Severity: SeverityWarning},
+		}}
+	}
+	d := valid()
+	d.AssignWidgetIDs()
+	assert.NoError(t, d.Validate())
+	assert.Equal(t, "widget-3", d.Widgets[2].ID)
+	w, ok := d.Widget("widget-2")
+	assert.True(t, ok)
+	assert.Equal(t, WidgetTopList, w.Type)
+
+	tests := []struct {
+		name   string
+		modify func(*Dashboard)
+		want   string
+	}{
+		{"missing name", func(d *Dashboard) { d.Name = "" }, "name is required"},
+		{"bad refresh", func(d *Dashboard) { d.Refresh = "soon" }, "invalid refresh"},
+		{"unknown type", func(d *Dashboard) { d.Widgets[0].Type = "pie" }, "invalid widget type"},
+		{"gauge without query", func(d *Dashboard) { d.Widgets[0].Query = "" }, "gauge requires a query"},
+		{"gauge bounds", func(d *Dashboard) { d.Widgets[0].Min = 150 }, "max must be greater than min"},
+		{"top list with both", func(d *Dashboard) { d.Widgets[1].Query = "node_load1" }, "either a query or processes"},
+		{"top list by disk", func(d *Dashboard) { d.Widgets[1].Processes = "disk" }, "must be cpu or memory"},
+		{"limit too large", func(d *Dashboard) { d.Widgets[1].Limit = MaxWidgetLimit + 1 }, "limit must be between"},
+		{"bad range", func(d *Dashboard) { d.Widgets[2].Range = "-1h" }, "invalid sparkline range"},
+		{"too many points", func(d *Dashboard) { d.Widgets[2].Points = MaxWidgetPoints + 1 }, "points must be between"},
+		{"bad severity", func(d *Dashboard) { d.Widgets[3].Severity = "high" }, "invalid severity"},
+		{"duplicate IDs", func(d *Dashboard) { d.Widgets[1].ID = d.Widgets[0].ID }, "duplicate widget ID"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := valid()
+			d.AssignWidgetIDs()
+			tt.modify(&d)
+			assert.ErrorContains(t, d.Validate(), tt.want)
+		})
+	}
+}
@@ -1,24 +1,241 @@
 // File: internal/models/event.go
 // Brief: Event-related data models for Argus
-// Detailed: Contains type definition for AlertEvent.
+// Detailed: Contains type definitions for AlertEvent, its source and trigger-time EventContext, including the recent samples of the alert's metric rendered as a sparkline, a sample event for checking templates, and AlertHistoryEntry.
 // Author: drama.lin@aver.com
 // Date: 2024-07-03
 
 package models
 
 import (
+	"fmt"
 	"time"
 )
 
+// Alert sources: where an alert originated
+const (
+	SourceLocal          = "local-evaluator" // Alert rules evaluated by this server
+	SourceAgentPrefix    = "agent:"          // Followed by the host ID of a reporting agent
+	SourceExternalPrefix = "external:"       // Followed by the system that pushed the alert
+)
+
+// AgentSource returns the source of alerts about the agent of host
+func AgentSource(host string) string {
+	return SourceAgentPrefix + host
+}
+
+// ExternalSource returns the source of alerts pushed by system, e.g. "prometheus"
+func ExternalSource(system string) string {
+	return SourceExternalPrefix + system
+}
+
 // AlertEvent represents an alert state change event
 type AlertEvent struct {
-	AlertID      string       // ID of the alert that changed state
-	OldState     AlertState   // Previous state
-	NewState     AlertState   // New state
-	CurrentValue float64      // Current metric value
-	Threshold    float64      // Alert threshold value
-	Timestamp    time.Time    // When the state change occurred
-	Message      string       // Human-readable message
-	Alert        *AlertConfig // The full alert configuration
-	Status       *AlertStatus // The current alert status
+	AlertID      string        // ID of the alert that changed state
+	OldState     AlertState    // Previous state
+	NewState     AlertState    // New state
+	CurrentValue float64       // Current metric value
+	Threshold    float64       // Alert threshold value
+	Timestamp    time.Time     // When the state change occurred
+	Message      string        // Human-readable message
+	Alert        *AlertConfig  // The full alert configuration
+	Status       *AlertStatus  // The current alert status
+	Context      *EventContext // Affected resources captured when the alert fired (may be nil)
+	Source       string        // Where the alert originated: SourceLocal, AgentSource or ExternalSource
+	StringValue  string        // Current value of string metrics, e.g. "failed"
+}
+
+// DisplayValue renders the current value for notifications: the state of
+// string metrics ("(none)" when empty), otherwise the number in the
+// threshold's unit
+func (e AlertEvent) DisplayValue() string {
+	if e.Alert == nil {
+		return fmt.Sprintf("%.2f", e.CurrentValue)
+	}
+	if e.Alert.Threshold.StringMetric() {
+		if e.StringValue == "" {
+			return "(none)"
+		}
+		return e.StringValue
+	}
+	return e.Alert.Threshold.FormatValue(e.CurrentValue)
+}
+
+// EventContext captures the resources behind an alert at trigger time, e.g. the
+// processes consuming the most CPU or the partitions that are filling up
+type EventContext struct {
+	TopProcesses []ProcessSnapshot   `json:"top_processes,omitempty"`
+	Partitions   []PartitionSnapshot `json:"partitions,omitempty"`
+	Samples      []MetricSample      `json:"samples,omitempty"` // Recent values of the alert's metric, oldest first
+	CapturedAt   time.Time           `json:"captured_at"`
+}
+
+// MetricSample is a recorded value of an alert's metric
+type MetricSample struct {
+	Timestamp time.Time `json:"timestamp"`
+	Value     float64   `json:"value"`
+}
+
+// sparkBars are the levels of a sparkline, lowest first
+var sparkBars = []rune("▁▂▃▄▅▆▇█")
+
+// Sparkline renders the samples as a row of bars scaled between their lowest
+// and highest value, e.g. "▁▂▂▅█", for the mini-trend of text notifications
+func (c EventContext) Sparkline() string {
+	if len(c.Samples) == 0 {
+		return ""
+	}
+	low, high := c.Samples[0].Value, c.Samples[0].Value
+	for _, s := range c.Samples {
+		low, high = min(low, s.Value), max(high, s.Value)
+	}
+	bars := make([]rune, len(c.Samples))
+	for i, s := range c.Samples {
+		level := 0
+		if high > low {
+			level = int((s.Value - low) / (high - low) * float64(len(sparkBars)-1))
+		}
+		bars[i] = sparkBars[level]
+	}
+	return string(bars)
+}
+
+// ProcessSnapshot is a process as seen by the collector when an alert fired
+type ProcessSnapshot struct {
+	PID        int32   `json:"pid"`
+	Name       string  `json:"name"`
+	CPUPercent float64 `json:"cpu_percent"`
+	MemPercent float64 `json:"mem_percent"`
+}
+
+// PartitionSnapshot is a mounted filesystem's usage when an alert fired
+type PartitionSnapshot struct {
+	Device      string  `json:"device"`
+	Mountpoint  string  `json:"mountpoint"`
+	Fstype      string  `json:"fstype"`
+	Total       uint64  `json:"total"`
+	Free        uint64  `json:"free"`
+	UsedPercent float64 `json:"used_percent"`
+
+	InodesUsedPercent float64 `json:"inodes_used_percent,omitempty"`
+}
+
+// FreeHuman returns the free space in binary units (e.g. "1.5 GiB") for templates
+func (p PartitionSnapshot) FreeHuman() string {
+	const unit = 1024
+	if p.Free < unit {
+		return fmt.Sprintf("%d B", p.Free)
+	}
+	div, exp := uint64(unit), 0
+	for n := p.Free / unit; n >= unit && exp < 4; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(p.Free)/float64(div), "KMGTP"[exp])
+}
+
+// SampleAlertEvent returns a representative event with every field set, from
+// OldState to state, for trying out notification templates
+func SampleAlertEvent(severity AlertSeverity, state AlertState, now time.Time) AlertEvent {
+	oldState := StateInactive
+	if state != StateActive {
+		oldState = StateActive
+	}
+	triggered := now.Add(-5 * time.Minute)
+	alert := &AlertConfig{
+		ID:          "sample-alert",
+		Name:        "High CPU usage",
+		Description: "CPU usage is above **90%** on `web-1`",
+		Enabled:     true,
+		Severity:    severity,
+		Threshold: ThresholdConfig{
+			MetricType:   MetricCPU,
+			MetricName:   "usage_percent",
+			Operator:     OperatorGreaterThan,
+			Value:        90,
+			Duration:     time.Minute,
+			SustainedFor: 3,
+		},
+		Labels:    map[string]string{"env": "production", "host": "web-1"},
+		Owner:     "On-call",
+		Team:      "platform",
+		Contact:   "oncall@example.com",
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	status := &AlertStatus{
+		AlertID:      alert.ID,
+		State:        state,
+		CurrentValue: 97.5,
+		TriggeredAt:  &triggered,
+		Message:      "CPU usage 97.50% > 90.00%",
+		Source:       SourceLocal,
+	}
+	if state == StateResolved {
+		status.ResolvedAt = &now
+	}
+	return AlertEvent{
+		AlertID:      alert.ID,
+		OldState:     oldState,
+		NewState:     state,
+		CurrentValue: 97.5,
+		Threshold:    alert.Threshold.Value,
+		Timestamp:    now,
+		Message:      "CPU usage 97.50% > 90.00%",
+		Alert:        alert,
+		Status:       status,
+		Context: &EventContext{
+			TopProcesses: []ProcessSnapshot{{PID: 4242, Name: "java", CPUPercent: 85.2, MemPercent: 12.4}},
+			Partitions:   []PartitionSnapshot{{Device: "/dev/sda1", Mountpoint: "/", Fstype: "ext4", Total: 100 << 30, Free: 20 << 30, UsedPercent: 80}},
+			Samples:      sampleTrend(now, 30*time.Second, 62, 64, 71, 78, 85, 93, 97.5),
+			CapturedAt:   now,
+		},
+		Source: SourceLocal,
+	}
+}
+
+// sampleTrend returns values sampled every step up to now
+func sampleTrend(now time.Time, step time.Duration, values ...float64) []MetricSample {
+	samples := make([]MetricSample, len(values))
+	for i, v := range values {
+		samples[i] = MetricSample{Timestamp: now.Add(-time.Duration(len(values)-1-i) * step), Value: v}
+	}
+	return samples
+}
+
+// AlertHistoryEntry is the persisted record of one alert state change
+type AlertHistoryEntry struct {
+	AlertID   string        `json:"alert_id"`
+	AlertName string        `json:"alert_name"`
+	Severity  AlertSeverity `json:"severity"`
+	OldState  AlertState    `json:"old_state"`
+	NewState  AlertState    `json:"new_state"`
+	Value     float64       `json:"value"`
+	Threshold float64       `json:"threshold"`
+	Message   string        `json:"message,omitempty"`
+	Timestamp time.Time     `json:"timestamp"`
+	Context   *EventContext `json:"context,omitempty"`
+	Source    string        `json:"source,omitempty"` // Where the alert originated (empty in records predating sources)
+	// StringValue is the value of string metrics, whose Value is 0
+	StringValue string `json:"string_value,omitempty"`
+}
+
+// NewAlertHistoryEntry builds the history record for an event
+func NewAlertHistoryEntry(event AlertEvent) AlertHistoryEntry {
+	entry := AlertHistoryEntry{
+		AlertID:     event.AlertID,
+		OldState:    event.OldState,
+		NewState:    event.NewState,
+		Value:       event.CurrentValue,
+		Threshold:   event.Threshold,
+		Message:     event.Message,
+		Timestamp:   event.Timestamp.UTC(),
+		Context:     event.Context,
+		Source:      event.Source,
+		StringValue: event.StringValue,
+	}
+	if event.Alert != nil {
+		entry.AlertName = event.Alert.Name
+		entry.Severity = event.Alert.Severity
+	}
+	return entry
 }
@@ -77,3 +77,76 @@ func TestAlertEvent(t *testing.T) {
 		})
 	}
 }
+
+func TestPartitionSnapshotFreeHuman(t *testing.T) {
+	tests := []struct {
+		free uint64
+		want string
+	}{
+		{512, "512 B"},
+		{2048, "2.0 KiB"},
+		{3 << 30, "3.0 GiB"},
+		{5 << 40, "5.0 TiB"},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, PartitionSnapshot{Free: tt.free}.FreeHuman())
+	}
+}
+
+func TestNewAlertHistoryEntry(t *testing.T) {
+	ctx := &EventContext{TopProcesses: []ProcessSnapshot{{PID: 42, Name: "stress"}}}
+	event := AlertEvent{
+		AlertID:      "alert-1",
+		OldState:     StateInactive,
+		NewState:     StatePending,
+		CurrentValue: 97,
+		Threshold:    90,
+		Timestamp:    time.Now(),
+		Alert:        &AlertConfig{Name: "High CPU", Severity: SeverityCritical},
+		Context:      ctx,
+		Source:       SourceLocal,
+	}
+
+	entry := NewAlertHistoryEntry(event)
+	assert.Equal(t, "High CPU", entry.AlertName)
+	assert.Equal(t, SeverityCritical, entry.Severity)
+	assert.Equal(t, StatePending, entry.NewState)
+	assert.Equal(t, time.UTC, entry.Timestamp.Location())
+	assert.Same(t, ctx, entry.Context)
+	assert.Equal(t, "local-evaluator", entry.Source)
+}
+
+func TestAlertSources(t *testing.T) {
+	assert.Equal(t, "agent:web-1", AgentSource("web-1"))
+	assert.Equal(t, "external:prometheus", ExternalSource("prometheus"))
+}
+
+func TestSampleAlertEvent(t *testing.T) {
+	now := time.Now()
+	event := SampleAlertEvent(SeverityWarning, StateResolved, now)
+	assert.Equal(t, StateActive, event.OldState)
+	assert.Equal(t, StateResolved, event.NewState)
+	assert.Equal(t, SeverityWarning, event.Alert.Severity)
+	assert.Equal(t, &now, event.Status.ResolvedAt)
+	assert.NotEmpty(t, event.Context.TopProcesses)
+	if assert.Len(t, event.Context.Samples, 7) {
+		assert.Equal(t, now, event.Context.Samples[6].Timestamp)
+	}
+
+	assert.Equal(t, StateInactive, SampleAlertEvent(SeverityCritical, StateActive, now).OldState)
+	assert.Nil(t, SampleAlertEvent(SeverityCritical, StateActive, now).Status.ResolvedAt)
+}
+
+func TestEventContext_Sparkline(t *testing.T) {
+	samples := func(values ...float64) EventContext {
+		ctx := EventContext{}
+		for _, v := range values {
+			ctx.Samples = append(ctx.Samples, MetricSample{Value: v})
+		}
+		return ctx
+	}
+	assert.Equal(t, "", EventContext{}.Sparkline())
+	assert.Equal(t, "▁▄█", samples(0, 50, 100).Sparkline())
+	assert.Equal(t, "█▁", samples(10, -10).Sparkline())
+	assert.Equal(t, "▁▁▁", samples(5, 5, 5).Sparkline())
+}
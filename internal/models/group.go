@@ -0,0 +1,33 @@
+// File: internal/models/group.go
+// Brief: Alert group model for Argus
+// Detailed: Contains the AlertGroup type, a named folder alerts can be filed under so they can be listed, enabled, disabled and silenced together.
+// Author: drama.lin@aver.com
+// Date: 2026-10-14
+
+package models
+
+import (
+	"errors"
+	"time"
+)
+
+// AlertGroup is a folder of alerts
+type AlertGroup struct {
+	ID          string            `json:"id"`
+	Name        string            `json:"name"`
+	Description string            `json:"description,omitempty"`
+	Labels      map[string]string `json:"labels,omitempty"` // Free-form metadata, e.g. {"team": "storage"}
+	CreatedAt   time.Time         `json:"created_at"`
+	UpdatedAt   time.Time         `json:"updated_at"`
+}
+
+// Validate checks if the group is valid
+func (g *AlertGroup) Validate() error {
+	if g.ID == "" {
+		return errors.New("group ID is required")
+	}
+	if g.Name == "" {
+		return errors.New("group name is required")
+	}
+	return nil
+}
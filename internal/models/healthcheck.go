@@ -0,0 +1,140 @@
+// File: internal/models/healthcheck.go
+// Brief: Parameters and results of health check tasks
+// Detailed: Parses the endpoints a health_check task probes from its parameters, either as a JSON array of endpoint specs or as a comma-separated list of URLs, applying the task-wide timeout, expected status and concurrency defaults, and defines the structured result of each probe.
+// Author: drama.lin@aver.com
+// Date: 2026-10-14
+
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Health check task parameter defaults
+const (
+	DefaultHealthCheckTimeout     = 5 * time.Second
+	DefaultHealthCheckConcurrency = 8
+)
+
+// HealthEndpoint is one endpoint probed by a health_check task
+type HealthEndpoint struct {
+	Name           string `json:"name,omitempty"`            // Defaults to the URL
+	URL            string `json:"url"`                       // http or https
+	Method         string `json:"method,omitempty"`          // Defaults to GET
+	Timeout        string `json:"timeout,omitempty"`         // Defaults to the timeout parameter
+	ExpectedStatus int    `json:"expected_status,omitempty"` // Defaults to expected_status; 0 accepts any 2xx
+}
+
+// HealthCheckParams are the parsed parameters of a health_check task
+type HealthCheckParams struct {
+	Endpoints   []HealthEndpoint // With their defaults applied
+	Concurrency int              // Endpoints probed at once
+}
+
+// HealthProbeResult is the outcome of probing one endpoint
+type HealthProbeResult struct {
+	Name       string  `json:"name"`
+	URL        string  `json:"url"`
+	Healthy    bool    `json:"healthy"`
+	Status     int     `json:"status,omitempty"` // HTTP status, when a response arrived
+	Expected   int     `json:"expected_status,omitempty"`
+	DurationMs float64 `json:"duration_ms"`
+	Error      string  `json:"error,omitempty"`
+}
+
+// ParseHealthCheckParams reads the endpoints parameter, a JSON array of
+// HealthEndpoint or comma-separated URLs, and the task-wide timeout (default
+// 5s), expected_status (default any 2xx) and concurrency (default 8)
+func ParseHealthCheckParams(params map[string]string) (*HealthCheckParams, error) {
+	timeout := DefaultHealthCheckTimeout
+	if s := params["timeout"]; s != "" {
+		d, err := time.ParseDuration(s)
+		if err != nil || d <= 0 {
+			return nil, fmt.Errorf("invalid timeout %q: must be a positive duration", s)
+		}
+		timeout = d
+	}
+	expected := 0
+	if s := params["expected_status"]; s != "" {
+		n, err := strconv.Atoi(s)
+		if err != nil || !validStatus(n) {
+			return nil, fmt.Errorf("invalid expected_status %q: must be an HTTP status code", s)
+		}
+		expected = n
+	}
+	concurrency := DefaultHealthCheckConcurrency
+	if s := params["concurrency"]; s != "" {
+		n, err := strconv.Atoi(s)
+		if err != nil || n <= 0 {
+			return nil, fmt.Errorf("invalid concurrency %q: must be a positive number", s)
+		}
+		concurrency = n
+	}
+
+	var endpoints []HealthEndpoint
+	raw := strings.TrimSpace(params["endpoints"])
+	if strings.HasPrefix(raw, "[") {
+		if err := json.Unmarshal([]byte(raw), &endpoints); err != nil {
+			return nil, fmt.Errorf("invalid endpoints: %w", err)
+		}
+	} else if raw != "" {
+		for _, u := range strings.Split(raw, ",") {
+			if u = strings.TrimSpace(u); u != "" {
+				endpoints = append(endpoints, HealthEndpoint{URL: u})
+			}
+		}
+	}
+
+	result := &HealthCheckParams{Concurrency: concurrency}
+	for i, e := range endpoints {
+		if u, err := url.Parse(e.URL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return nil, fmt.Errorf("invalid endpoint %d url %q: must be an http or https URL", i+1, e.URL)
+		}
+		if e.Name == "" {
+			e.Name = e.URL
+		}
+		if e.Method == "" {
+			e.Method = http.MethodGet
+		}
+		e.Method = strings.ToUpper(e.Method)
+		if e.Timeout == "" {
+			e.Timeout = timeout.String()
+		} else if d, err := time.ParseDuration(e.Timeout); err != nil || d <= 0 {
+			return nil, fmt.Errorf("invalid endpoint %q timeout %q: must be a positive duration", e.Name, e.Timeout)
+		}
+		if e.ExpectedStatus == 0 {
+			e.ExpectedStatus = expected
+		} else if !validStatus(e.ExpectedStatus) {
+			return nil, fmt.Errorf("invalid endpoint %q expected_status %d: must be an HTTP status code", e.Name, e.ExpectedStatus)
+		}
+		result.Endpoints = append(result.Endpoints, e)
+	}
+	return result, nil
+}
+
+// validStatus reports whether n is an HTTP status code
+func validStatus(n int) bool {
+	return n >= 100 && n <= 599
+}
+
+// TimeoutDuration returns the probe timeout, DefaultHealthCheckTimeout when unset
+func (e HealthEndpoint) TimeoutDuration() time.Duration {
+	if d, err := time.ParseDuration(e.Timeout); err == nil && d > 0 {
+		return d
+	}
+	return DefaultHealthCheckTimeout
+}
+
+// Healthy reports whether status satisfies the expected status (any 2xx when 0)
+func (e HealthEndpoint) Healthy(status int) bool {
+	if e.ExpectedStatus == 0 {
+		return status >= 200 && status < 300
+	}
+	return status == e.ExpectedStatus
+}
@@ -0,0 +1,73 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseHealthCheckParams(t *testing.T) {
+	params, err := ParseHealthCheckParams(map[string]string{"endpoints": "http://a.local/health, https://b.local"})
+	require.NoError(t, err)
+	assert.Equal(t, DefaultHealthCheckConcurrency, params.Concurrency)
+	require.Len(t, params.Endpoints, 2)
+	assert.Equal(t, HealthEndpoint{Name: "http://a.local/health", URL: "http://a.local/health", Method: "GET", Timeout: "5s"}, params.Endpoints[0])
+	assert.Equal(t, DefaultHealthCheckTimeout, params.Endpoints[1].TimeoutDuration())
+
+	params, err = ParseHealthCheckParams(map[string]string{
+		"endpoints":       `[{"name":"api","url":"http://api.local/ready","method":"head","timeout":"500ms"},{"url":"http://db.local","expected_status":204}]`,
+		"timeout":         "2s",
+		"expected_status": "200",
+		"concurrency":     "3",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 3, params.Concurrency)
+	assert.Equal(t, HealthEndpoint{Name: "api", URL: "http://api.local/ready", Method: "HEAD", Timeout: "500ms", ExpectedStatus: 200}, params.Endpoints[0])
+	assert.Equal(t, 500*time.Millisecond, params.Endpoints[0].TimeoutDuration())
+	assert.Equal(t, 2*time.Second, params.Endpoints[1].TimeoutDuration(), "the task-wide timeout applies")
+	assert.Equal(t, 204, params.Endpoints[1].ExpectedStatus, "endpoints override the task-wide status")
+
+	params, err = ParseHealthCheckParams(nil)
+	require.NoError(t, err)
+	assert.Empty(t, params.Endpoints)
+
+	for _, bad := range []map[string]string{
+		{"endpoints": "localhost:8080"},
+		{"endpoints": "ftp://files.local"},
+		{"endpoints": `[{"url":"http://a.local","timeout":"soon"}]`},
+		{"endpoints": `[{"url":"http://a.local","expected_status":42}]`},
+		{"endpoints": `[{"url":}]`},
+		{"timeout": "-1s"},
+		{"expected_status": "ok"},
+		{"concurrency": "0"},
+	} {
+		_, err := ParseHealthCheckParams(bad)
+		assert.Error(t, err, bad)
+	}
+}
+
+func TestHealthEndpoint_Healthy(t *testing.T) {
+	any2xx := HealthEndpoint{}
+	assert.True(t, any2xx.Healthy(200))
+	assert.True(t, any2xx.Healthy(204))
+	assert.False(t, any2xx.Healthy(301))
+	assert.False(t, any2xx.Healthy(503))
+
+	exact := HealthEndpoint{ExpectedStatus: 401}
+	assert.True(t, exact.Healthy(401))
+	assert.False(t, exact.Healthy(200))
+}
+
+func TestTaskConfigValidate_HealthCheckParameters(t *testing.T) {
+	task := TaskConfig{
+		ID: "hc", Name: "Health", Type: TaskHealthCheck,
+		Schedule:   Schedule{CronExpression: "*/5 * * * *"},
+		Parameters: map[string]string{"endpoints": "http://api.local/health"},
+	}
+	assert.NoError(t, task.Validate())
+
+	task.Parameters["timeout"] = "never"
+	assert.ErrorContains(t, task.Validate(), "invalid health check parameters")
+}
@@ -0,0 +1,117 @@
+// File: internal/models/host.go
+// Brief: Host inventory models for agent mode
+// Detailed: Contains the Host type describing an agent reporting to a central Argus server, the heartbeat an agent sends, and the health and reporting states of a host.
+// Author: drama.lin@aver.com
+// Date: 2026-10-14
+
+package models
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+// HostHealth is the health an agent reports for its host
+type HostHealth string
+
+// Reported host health
+const (
+	HostHealthy   HostHealth = "healthy"
+	HostDegraded  HostHealth = "degraded"
+	HostUnhealthy HostHealth = "unhealthy"
+)
+
+// HostStatus is whether an agent is still reporting
+type HostStatus string
+
+// Host reporting states
+const (
+	HostUp    HostStatus = "up"    // Reported within the stale timeout
+	HostStale HostStatus = "stale" // Stopped reporting
+)
+
+// Well-known host alert labels
+const (
+	LabelHost = "host" // ID of the host whose agent stopped reporting
+)
+
+// HostHealths lists the valid reported host healths
+var HostHealths = []HostHealth{HostHealthy, HostDegraded, HostUnhealthy}
+
+// Host is an agent registered with the central server
+type Host struct {
+	ID            string            `json:"id"`
+	Hostname      string            `json:"hostname"`
+	Labels        map[string]string `json:"labels,omitempty"`
+	AgentVersion  string            `json:"agent_version,omitempty"`
+	Address       string            `json:"address,omitempty"` // Address the last heartbeat came from
+	Health        HostHealth        `json:"health"`
+	HealthMessage string            `json:"health_message,omitempty"`
+	Tasks         bool              `json:"tasks,omitempty"` // Whether the agent runs the tasks targeting it
+	Status        HostStatus        `json:"status"`
+	RegisteredAt  time.Time         `json:"registered_at"`
+	LastSeen      time.Time         `json:"last_seen"`
+}
+
+// HostHeartbeat is the report an agent sends periodically. The first one
+// registers the host.
+type HostHeartbeat struct {
+	ID            string            `json:"id"` // Defaults to the hostname
+	Hostname      string            `json:"hostname"`
+	Labels        map[string]string `json:"labels,omitempty"`
+	AgentVersion  string            `json:"agent_version,omitempty"`
+	Health        HostHealth        `json:"health"` // Defaults to healthy
+	HealthMessage string            `json:"health_message,omitempty"`
+	Tasks         bool              `json:"tasks,omitempty"` // Whether the agent runs the tasks targeting it
+}
+
+// Validate checks the heartbeat and fills in its defaults
+func (h *HostHeartbeat) Validate() error {
+	if h.Hostname == "" {
+		return errors.New("hostname is required")
+	}
+	if h.ID == "" {
+		h.ID = h.Hostname
+	}
+	if h.Health == "" {
+		h.Health = HostHealthy
+	}
+	if strings.ContainsAny(h.ID, "/?#") {
+		return fmt.Errorf("invalid host ID %q: must not contain '/', '?' or '#'", h.ID)
+	}
+	if !slices.Contains(HostHealths, h.Health) {
+		return fmt.Errorf("invalid health %q: must be one of %v", h.Health, HostHealths)
+	}
+	for name := range h.Labels {
+		if name == "" {
+			return errors.New("label names must not be empty")
+		}
+	}
+	return nil
+}
+
+// AgentEnrollment is an agent admitted with the shared enrollment token
+type AgentEnrollment struct {
+	ID             string     `json:"id"`
+	Hostname       string     `json:"hostname"`
+	CredentialHash string     `json:"credential_hash,omitempty"` // SHA-256 of the agent's credential; never served by the API
+	EnrolledAt     time.Time  `json:"enrolled_at"`
+	Revoked        bool       `json:"revoked"`
+	RevokedAt      *time.Time `json:"revoked_at,omitempty"`
+}
+
+// AgentEnrollRequest is the body an agent sends to enroll
+type AgentEnrollRequest struct {
+	Token    string `json:"token" binding:"required"` // Shared enrollment token
+	ID       string `json:"id"`                       // Defaults to the hostname
+	Hostname string `json:"hostname" binding:"required"`
+}
+
+// AgentEnrollResponse carries the credential issued to an enrolled agent
+type AgentEnrollResponse struct {
+	ID         string `json:"id"`
+	Credential string `json:"credential"` // Sent as a bearer token with every heartbeat
+}
@@ -0,0 +1,20 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHostHeartbeat_Validate(t *testing.T) {
+	hb := HostHeartbeat{Hostname: "web-1"}
+	require.NoError(t, hb.Validate())
+	assert.Equal(t, "web-1", hb.ID)
+	assert.Equal(t, HostHealthy, hb.Health)
+
+	assert.Error(t, (&HostHeartbeat{}).Validate())
+	assert.Error(t, (&HostHeartbeat{Hostname: "web-1", Health: "sick"}).Validate())
+	assert.Error(t, (&HostHeartbeat{Hostname: "web-1", ID: "a/b"}).Validate())
+	assert.Error(t, (&HostHeartbeat{Hostname: "web-1", Labels: map[string]string{"": "x"}}).Validate())
+}
@@ -1,6 +1,6 @@
 // File: internal/models/notification.go
 // Brief: Notification-related data models for Argus
-// Detailed: Contains type definition for InAppNotification.
+// Detailed: Contains type definitions for InAppNotification, its per-user read receipts and its localized content.
 // Author: drama.lin@aver.com
 // Date: 2024-07-03
 
@@ -20,5 +20,50 @@ type InAppNotification struct {
 	Message   string        // Notification message
 	Subject   string        // Notification subject
 	Timestamp time.Time     // When the notification was created
-	Read      bool          // Whether the notification has been read
+	Source    string        // Where the alert originated, see AlertEvent.Source
+	Read      bool          // Whether the notification has been read (by the requesting user when users are known)
+
+	// ReadBy holds the read receipts of identified users: when each of them
+	// read the notification
+	ReadBy map[string]time.Time `json:"ReadBy,omitempty"`
+	// ReadAt is when the notification was first marked read for everyone
+	ReadAt *time.Time `json:"ReadAt,omitempty"`
+
+	// Localized holds the rendered content per locale tag so the API can
+	// serve the language negotiated with each client
+	Localized map[string]LocalizedContent `json:"-"`
+}
+
+// LocalizedContent holds a notification subject and message rendered for one locale
+type LocalizedContent struct {
+	Subject string `json:"subject"`
+	Message string `json:"message"`
+}
+
+// ReadFor reports whether user has read the notification. Without a user the
+// global read flag applies.
+func (n *InAppNotification) ReadFor(user string) bool {
+	if user == "" {
+		return n.Read
+	}
+	_, ok := n.ReadBy[user]
+	return ok
+}
+
+// MarkReadBy records that user read the notification at the given time; only
+// the first read is kept. Without a user the global read flag is set.
+func (n *InAppNotification) MarkReadBy(user string, at time.Time) {
+	if user == "" {
+		if !n.Read {
+			n.Read, n.ReadAt = true, &at
+		}
+		return
+	}
+	if _, ok := n.ReadBy[user]; ok {
+		return
+	}
+	if n.ReadBy == nil {
+		n.ReadBy = make(map[string]time.Time)
+	}
+	n.ReadBy[user] = at
 }
@@ -57,3 +57,21 @@ func TestInAppNotification(t *testing.T) {
 		})
 	}
 }
+
+func TestInAppNotification_ReadBy(t *testing.T) {
+	first := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	n := InAppNotification{ID: "n1"}
+
+	n.MarkReadBy("alice", first)
+	n.MarkReadBy("alice", first.Add(time.Hour)) // The first read is kept
+	assert.True(t, n.ReadFor("alice"))
+	assert.False(t, n.ReadFor("bob"))
+	assert.False(t, n.ReadFor(""), "a user's read does not mark it read globally")
+	assert.Equal(t, map[string]time.Time{"alice": first}, n.ReadBy)
+
+	n.MarkReadBy("", first)
+	n.MarkReadBy("", first.Add(time.Hour))
+	assert.True(t, n.Read)
+	assert.Equal(t, &first, n.ReadAt, "the first global read is kept")
+	assert.False(t, n.ReadFor("bob"))
+}
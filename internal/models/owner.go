@@ -0,0 +1,73 @@
+// File: internal/models/owner.go
+// Brief: Ownership metadata for alerts and tasks
+// Detailed: Validates the owner, team and contact fields of AlertConfig and TaskConfig, which name who is responsible so notifications and lists show who to call, and matches them for list filters.
+// Author: drama.lin@aver.com
+// Date: 2026-10-14
+
+package models
+
+import (
+	"fmt"
+	"net/mail"
+	"net/url"
+	"strings"
+)
+
+// MaxOwnershipFieldLength bounds the owner, team and contact fields
+const MaxOwnershipFieldLength = 200
+
+// validateOwnership checks the ownership fields. They are rendered into
+// notification headers and bodies, so line breaks are rejected.
+func validateOwnership(owner, team, contact string) error {
+	for _, field := range []struct{ name, value string }{{"owner", owner}, {"team", team}, {"contact", contact}} {
+		if len(field.value) > MaxOwnershipFieldLength {
+			return fmt.Errorf("%s must be at most %d characters", field.name, MaxOwnershipFieldLength)
+		}
+		if strings.ContainsAny(field.value, "\r\n") {
+			return fmt.Errorf("%s must be a single line", field.name)
+		}
+	}
+	if contact != "" && !validContact(contact) {
+		return fmt.Errorf("invalid contact %q: expected an email address, a URL or a #channel/@handle", contact)
+	}
+	return nil
+}
+
+// validContact accepts email addresses, absolute URLs (http, https, mailto,
+// tel) and chat handles starting with # or @
+func validContact(contact string) bool {
+	if !strings.ContainsAny(contact, " \t") {
+		if (contact[0] == '#' || contact[0] == '@') && len(contact) > 1 {
+			return true
+		}
+		if u, err := url.Parse(contact); err == nil {
+			switch strings.ToLower(u.Scheme) {
+			case "http", "https":
+				return u.Host != ""
+			case "mailto", "tel":
+				return u.Opaque != ""
+			}
+		}
+	}
+	// Also covers the "Name <address>" form
+	_, err := mail.ParseAddress(contact)
+	return err == nil
+}
+
+// ownedBy reports whether the fields match the wanted owner and team, ignoring
+// case; an empty wanted value matches anything
+func ownedBy(owner, team, wantOwner, wantTeam string) bool {
+	return (wantOwner == "" || strings.EqualFold(owner, wantOwner)) && (wantTeam == "" || strings.EqualFold(team, wantTeam))
+}
+
+// OwnedBy reports whether the alert belongs to owner and team; an empty
+// argument matches anything
+func (a *AlertConfig) OwnedBy(owner, team string) bool {
+	return ownedBy(a.Owner, a.Team, owner, team)
+}
+
+// OwnedBy reports whether the task belongs to owner and team; an empty
+// argument matches anything
+func (t *TaskConfig) OwnedBy(owner, team string) bool {
+	return ownedBy(t.Owner, t.Team, owner, team)
+}
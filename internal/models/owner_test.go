@@ -0,0 +1,55 @@
+package models
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateOwnership(t *testing.T) {
+	for _, contact := range []string{
+		"",
+		"oncall@example.com",
+		"Storage On-call <storage@example.com>",
+		"https://pager.example.com/services/storage",
+		"mailto:storage@example.com",
+		"tel:+886-2-1234-5678",
+		"#storage-oncall",
+		"@alice",
+	} {
+		assert.NoError(t, validateOwnership("Alice Chen", "storage", contact), contact)
+	}
+
+	for _, contact := range []string{"alice", "ftp://example.com", "https://", "#", "call alice"} {
+		assert.Error(t, validateOwnership("", "", contact), contact)
+	}
+
+	assert.Error(t, validateOwnership("Alice\nBcc: evil@example.com", "", ""), "line breaks")
+	assert.Error(t, validateOwnership("", strings.Repeat("x", MaxOwnershipFieldLength+1), ""))
+}
+
+func TestOwnedBy(t *testing.T) {
+	alert := &AlertConfig{Owner: "Alice", Team: "Storage"}
+	assert.True(t, alert.OwnedBy("", ""))
+	assert.True(t, alert.OwnedBy("alice", ""))
+	assert.True(t, alert.OwnedBy("", "storage"))
+	assert.False(t, alert.OwnedBy("bob", "storage"))
+
+	task := &TaskConfig{Team: "web"}
+	assert.True(t, task.OwnedBy("", "web"))
+	assert.False(t, task.OwnedBy("alice", ""))
+}
+
+func TestAlertConfigValidate_Ownership(t *testing.T) {
+	alert := AlertConfig{
+		ID:        "a1",
+		Name:      "CPU",
+		Severity:  SeverityWarning,
+		Threshold: ThresholdConfig{MetricType: MetricCPU, MetricName: "usage_percent", Operator: OperatorGreaterThan, Value: 90},
+		Contact:   "not a contact",
+	}
+	assert.Error(t, alert.Validate())
+	alert.Contact = "#oncall"
+	assert.NoError(t, alert.Validate())
+}
@@ -0,0 +1,105 @@
+// File: internal/models/remediation.go
+// Brief: Models for remediation actions bound to alerts
+// Detailed: Contains the RemediationConfig an alert sets to run a task when it activates, with its confirmation delay and guard rails (minimum severity and maximum runs per hour), and the RemediationRecord audit entries describing each remediation scheduled, cancelled, skipped, executed or failed.
+// Author: drama.lin@aver.com
+// Date: 2026-10-14
+
+package models
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Remediation defaults
+const (
+	DefaultRemediationDelay       = time.Minute
+	DefaultRemediationRunsPerHour = 1
+)
+
+// RemediationConfig runs a task when an alert activates and is still firing
+// after the confirmation delay
+type RemediationConfig struct {
+	TaskID         string        `json:"task_id"`                     // Task to run, e.g. a service restart
+	Delay          string        `json:"delay,omitempty"`             // Confirmation delay, e.g. "2m" (empty = 1m, "0s" runs at once)
+	MaxRunsPerHour int           `json:"max_runs_per_hour,omitempty"` // Runs of the alert's remediation per rolling hour (0 = 1)
+	MinSeverity    AlertSeverity `json:"min_severity,omitempty"`      // Lowest alert severity remediated (empty = critical)
+}
+
+// Validate checks the task reference, the delay and the guard rails
+func (r *RemediationConfig) Validate() error {
+	if r.TaskID == "" {
+		return errors.New("a task_id is required")
+	}
+	if strings.ContainsAny(r.TaskID, "/?#") {
+		return fmt.Errorf("invalid task_id %q: must not contain '/', '?' or '#'", r.TaskID)
+	}
+	if r.Delay != "" {
+		if d, err := time.ParseDuration(r.Delay); err != nil || d < 0 {
+			return fmt.Errorf("invalid delay %q: must be a duration of zero or more", r.Delay)
+		}
+	}
+	if r.MaxRunsPerHour < 0 {
+		return errors.New("max_runs_per_hour must not be negative")
+	}
+	if r.MinSeverity != "" && !slices.Contains(Severities, r.MinSeverity) {
+		return fmt.Errorf("invalid min_severity %q: expected info, warning or critical", r.MinSeverity)
+	}
+	return nil
+}
+
+// ConfirmationDelay returns how long the alert must keep firing before the task runs
+func (r *RemediationConfig) ConfirmationDelay() time.Duration {
+	d, err := time.ParseDuration(r.Delay)
+	if err != nil || d < 0 {
+		return DefaultRemediationDelay
+	}
+	return d
+}
+
+// RunsPerHour returns the maximum runs of the remediation per rolling hour
+func (r *RemediationConfig) RunsPerHour() int {
+	if r.MaxRunsPerHour <= 0 {
+		return DefaultRemediationRunsPerHour
+	}
+	return r.MaxRunsPerHour
+}
+
+// Remediates reports whether an alert of the given severity is remediated
+func (r *RemediationConfig) Remediates(severity AlertSeverity) bool {
+	lowest := r.MinSeverity
+	if lowest == "" {
+		lowest = SeverityCritical
+	}
+	return slices.Index(Severities, severity) >= slices.Index(Severities, lowest)
+}
+
+// RemediationAction is what happened to a remediation
+type RemediationAction string
+
+// Remediation actions recorded in the audit log
+const (
+	RemediationScheduled RemediationAction = "scheduled" // Waiting for the confirmation delay
+	RemediationCancelled RemediationAction = "cancelled" // The alert stopped firing during the delay
+	RemediationSkipped   RemediationAction = "skipped"   // Held back by a guard rail
+	RemediationExecuted  RemediationAction = "executed"  // The task ran (or was queued for agents)
+	RemediationFailed    RemediationAction = "failed"    // The task could not be run
+)
+
+// RemediationRecord is one entry of the remediation audit log
+type RemediationRecord struct {
+	ID          string            `json:"id"`
+	Timestamp   time.Time         `json:"timestamp"`
+	AlertID     string            `json:"alert_id"`
+	AlertName   string            `json:"alert_name,omitempty"`
+	Severity    AlertSeverity     `json:"severity,omitempty"`
+	Value       float64           `json:"value"` // Alert value at activation
+	TaskID      string            `json:"task_id"`
+	Action      RemediationAction `json:"action"`
+	Reason      string            `json:"reason,omitempty"`
+	ExecutionID string            `json:"execution_id,omitempty"`
+	Status      TaskStatus        `json:"status,omitempty"` // Of the execution
+}
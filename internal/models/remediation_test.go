@@ -0,0 +1,39 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRemediationConfigValidate(t *testing.T) {
+	assert.NoError(t, (&RemediationConfig{TaskID: "restart-api"}).Validate())
+	assert.NoError(t, (&RemediationConfig{TaskID: "restart-api", Delay: "0s", MaxRunsPerHour: 3, MinSeverity: SeverityWarning}).Validate())
+	assert.ErrorContains(t, (&RemediationConfig{}).Validate(), "task_id is required")
+	assert.ErrorContains(t, (&RemediationConfig{TaskID: "a/b"}).Validate(), "invalid task_id")
+	assert.ErrorContains(t, (&RemediationConfig{TaskID: "t", Delay: "-1m"}).Validate(), "invalid delay")
+	assert.ErrorContains(t, (&RemediationConfig{TaskID: "t", Delay: "soon"}).Validate(), "invalid delay")
+	assert.ErrorContains(t, (&RemediationConfig{TaskID: "t", MaxRunsPerHour: -1}).Validate(), "max_runs_per_hour")
+	assert.ErrorContains(t, (&RemediationConfig{TaskID: "t", MinSeverity: "high"}).Validate(), "invalid min_severity")
+
+	alert := AlertConfig{ID: "a1", Name: "API down", Severity: SeverityCritical,
+		Threshold:   ThresholdConfig{MetricType: MetricCPU, MetricName: "usage_percent", Operator: OperatorGreaterThan, Value: 90},
+		Remediation: &RemediationConfig{}}
+	assert.ErrorContains(t, alert.Validate(), "invalid remediation")
+}
+
+func TestRemediationConfigDefaults(t *testing.T) {
+	r := &RemediationConfig{TaskID: "t"}
+	assert.Equal(t, time.Minute, r.ConfirmationDelay())
+	assert.Equal(t, 1, r.RunsPerHour())
+	assert.True(t, r.Remediates(SeverityCritical))
+	assert.False(t, r.Remediates(SeverityWarning), "only critical alerts by default")
+
+	r = &RemediationConfig{TaskID: "t", Delay: "0s", MaxRunsPerHour: 4, MinSeverity: SeverityWarning}
+	assert.Equal(t, time.Duration(0), r.ConfirmationDelay())
+	assert.Equal(t, 4, r.RunsPerHour())
+	assert.True(t, r.Remediates(SeverityWarning))
+	assert.True(t, r.Remediates(SeverityCritical))
+	assert.False(t, r.Remediates(SeverityInfo))
+}
@@ -0,0 +1,255 @@
+// File: internal/models/silence.go
+// Brief: Silence and label matcher models for Argus
+// Detailed: Contains the Silence type that mutes notifications for alerts whose labels match a set of Alertmanager-style matchers during a time window, the Matcher type and its text syntax, and the label set derived from an alert configuration.
+// Author: drama.lin@aver.com
+// Date: 2026-10-14
+
+package models
+
+import (
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Well-known alert labels
+const (
+	LabelAlertName  = "alertname"
+	LabelAlertID    = "alert_id"
+	LabelSeverity   = "severity"
+	LabelMetricType = "metric_type"
+	LabelMetricName = "metric_name"
+	LabelTarget     = "target"
+	LabelGroup      = "group" // ID of the alert's group, so silences can mute a whole group
+	LabelTeam       = "team"  // Owning team of the alert
+)
+
+// LabelSet returns the label set used to match the alert against silences:
+// its custom Labels plus its name, ID, severity, metric type and name, and
+// group, team and target if any. The built-in labels take precedence.
+func (a *AlertConfig) LabelSet() map[string]string {
+	labels := make(map[string]string, len(a.Labels)+8)
+	for k, v := range a.Labels {
+		labels[k] = v
+	}
+	builtin := map[string]string{
+		LabelAlertName:  a.Name,
+		LabelAlertID:    a.ID,
+		LabelSeverity:   string(a.Severity),
+		LabelMetricType: string(a.Threshold.MetricType),
+		LabelMetricName: a.Threshold.MetricName,
+		LabelGroup:      a.GroupID,
+		LabelTeam:       a.Team,
+	}
+	if a.Threshold.Target != nil && *a.Threshold.Target != "" {
+		builtin[LabelTarget] = *a.Threshold.Target
+	}
+	for k, v := range builtin {
+		if v != "" {
+			labels[k] = v
+		}
+	}
+	return labels
+}
+
+// Fingerprint returns a stable hex identifier of a label set
+func Fingerprint(labels map[string]string) string {
+	keys := make([]string, 0, len(labels))
+	for k := range labels {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	h := fnv.New64a()
+	for _, k := range keys {
+		h.Write([]byte(k))
+		h.Write([]byte{0xff})
+		h.Write([]byte(labels[k]))
+		h.Write([]byte{0xff})
+	}
+	return fmt.Sprintf("%016x", h.Sum64())
+}
+
+// Matcher compares one label, with Alertmanager semantics: IsEqual false
+// negates the match, IsRegex treats Value as a regular expression anchored at
+// both ends, and a missing label matches as the empty string
+type Matcher struct {
+	Name    string `json:"name"`
+	Value   string `json:"value"`
+	IsRegex bool   `json:"isRegex"`
+	IsEqual bool   `json:"isEqual"`
+
+	re *regexp.Regexp
+}
+
+// Validate checks the matcher and compiles its regular expression
+func (m *Matcher) Validate() error {
+	if m.Name == "" {
+		return errors.New("matcher name is required")
+	}
+	if m.IsRegex {
+		re, err := regexp.Compile("^(?:" + m.Value + ")$")
+		if err != nil {
+			return fmt.Errorf("invalid matcher regex %q: %w", m.Value, err)
+		}
+		m.re = re
+	}
+	return nil
+}
+
+// Matches reports whether labels satisfy the matcher
+func (m *Matcher) Matches(labels map[string]string) bool {
+	value := labels[m.Name]
+	var ok bool
+	if m.IsRegex {
+		if m.re == nil {
+			if err := m.Validate(); err != nil {
+				return false
+			}
+		}
+		ok = m.re.MatchString(value)
+	} else {
+		ok = value == m.Value
+	}
+	return ok == m.IsEqual
+}
+
+// String renders the matcher in PromQL selector syntax, e.g. severity=~"warning|critical"
+func (m Matcher) String() string {
+	op := "="
+	switch {
+	case m.IsRegex && m.IsEqual:
+		op = "=~"
+	case m.IsRegex:
+		op = "!~"
+	case !m.IsEqual:
+		op = "!="
+	}
+	return fmt.Sprintf("%s%s%q", m.Name, op, m.Value)
+}
+
+// ParseMatcher parses name=value, name!=value, name=~regex or name!~regex.
+// The value may be double-quoted.
+func ParseMatcher(s string) (Matcher, error) {
+	s = strings.TrimSpace(s)
+	idx := strings.IndexAny(s, "=!")
+	if idx <= 0 {
+		return Matcher{}, fmt.Errorf("invalid matcher %q", s)
+	}
+	m := Matcher{Name: strings.TrimSpace(s[:idx]), IsEqual: true}
+	rest := s[idx:]
+	switch {
+	case strings.HasPrefix(rest, "=~"):
+		m.IsRegex, rest = true, rest[2:]
+	case strings.HasPrefix(rest, "!~"):
+		m.IsRegex, m.IsEqual, rest = true, false, rest[2:]
+	case strings.HasPrefix(rest, "!="):
+		m.IsEqual, rest = false, rest[2:]
+	case strings.HasPrefix(rest, "="):
+		rest = rest[1:]
+	default:
+		return Matcher{}, fmt.Errorf("invalid matcher %q", s)
+	}
+	rest = strings.TrimSpace(rest)
+	if len(rest) >= 2 && rest[0] == '"' && rest[len(rest)-1] == '"' {
+		rest = rest[1 : len(rest)-1]
+	}
+	m.Value = rest
+	if err := m.Validate(); err != nil {
+		return Matcher{}, err
+	}
+	return m, nil
+}
+
+// ParseMatchers parses a selector such as {severity="critical",alertname=~"cpu.*"}
+// or a single matcher. Commas inside quoted values are not supported.
+func ParseMatchers(s string) ([]Matcher, error) {
+	s = strings.TrimSpace(s)
+	s = strings.TrimSuffix(strings.TrimPrefix(s, "{"), "}")
+	var matchers []Matcher
+	for _, part := range strings.Split(s, ",") {
+		if strings.TrimSpace(part) == "" {
+			continue
+		}
+		m, err := ParseMatcher(part)
+		if err != nil {
+			return nil, err
+		}
+		matchers = append(matchers, m)
+	}
+	return matchers, nil
+}
+
+// MatchAll reports whether labels satisfy every matcher
+func MatchAll(matchers []Matcher, labels map[string]string) bool {
+	for i := range matchers {
+		if !matchers[i].Matches(labels) {
+			return false
+		}
+	}
+	return true
+}
+
+// SilenceState is where a silence is relative to its time window
+type SilenceState string
+
+// Available silence states
+const (
+	SilencePending SilenceState = "pending" // Starts in the future
+	SilenceActive  SilenceState = "active"  // Currently muting notifications
+	SilenceExpired SilenceState = "expired" // Ended
+)
+
+// Silence mutes notifications for matching alerts between StartsAt and EndsAt
+type Silence struct {
+	ID        string    `json:"id"`
+	Matchers  []Matcher `json:"matchers"`
+	StartsAt  time.Time `json:"startsAt"`
+	EndsAt    time.Time `json:"endsAt"`
+	CreatedBy string    `json:"createdBy"`
+	Comment   string    `json:"comment"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// Validate checks if the silence is valid and compiles its matchers
+func (s *Silence) Validate() error {
+	if s.ID == "" {
+		return errors.New("silence ID is required")
+	}
+	if len(s.Matchers) == 0 {
+		return errors.New("silence requires at least one matcher")
+	}
+	for i := range s.Matchers {
+		if err := s.Matchers[i].Validate(); err != nil {
+			return err
+		}
+	}
+	if s.StartsAt.IsZero() || s.EndsAt.IsZero() {
+		return errors.New("silence requires startsAt and endsAt")
+	}
+	if s.EndsAt.Before(s.StartsAt) {
+		return errors.New("silence endsAt must not be before startsAt")
+	}
+	return nil
+}
+
+// State returns the silence state at the given time
+func (s *Silence) State(at time.Time) SilenceState {
+	switch {
+	case at.Before(s.StartsAt):
+		return SilencePending
+	case at.Before(s.EndsAt):
+		return SilenceActive
+	default:
+		return SilenceExpired
+	}
+}
+
+// Mutes reports whether the silence is active at the given time and matches labels
+func (s *Silence) Mutes(labels map[string]string, at time.Time) bool {
+	return s.State(at) == SilenceActive && MatchAll(s.Matchers, labels)
+}
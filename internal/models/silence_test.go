@@ -0,0 +1,113 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseMatcher(t *testing.T) {
+	tests := []struct {
+		input       string
+		expected    Matcher
+		expectError bool
+	}{
+		{input: `severity="critical"`, expected: Matcher{Name: "severity", Value: "critical", IsEqual: true}},
+		{input: `severity!=info`, expected: Matcher{Name: "severity", Value: "info"}},
+		{input: `alertname=~"cpu.*"`, expected: Matcher{Name: "alertname", Value: "cpu.*", IsRegex: true, IsEqual: true}},
+		{input: `target!~"nginx|redis"`, expected: Matcher{Name: "target", Value: "nginx|redis", IsRegex: true}},
+		{input: `=value`, expectError: true},
+		{input: `name`, expectError: true},
+		{input: `name=~"("`, expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			m, err := ParseMatcher(tt.input)
+			if tt.expectError {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected.Name, m.Name)
+			assert.Equal(t, tt.expected.Value, m.Value)
+			assert.Equal(t, tt.expected.IsRegex, m.IsRegex)
+			assert.Equal(t, tt.expected.IsEqual, m.IsEqual)
+		})
+	}
+}
+
+func TestMatcherMatches(t *testing.T) {
+	labels := map[string]string{"alertname": "cpu_high", "severity": "critical"}
+
+	matchers, err := ParseMatchers(`{alertname=~"cpu.*", severity!="info"}`)
+	require.NoError(t, err)
+	require.Len(t, matchers, 2)
+	assert.True(t, MatchAll(matchers, labels))
+
+	// Regexes are anchored at both ends
+	m, err := ParseMatcher(`alertname=~"cpu"`)
+	require.NoError(t, err)
+	assert.False(t, m.Matches(labels))
+
+	// A missing label matches as the empty string
+	m, err = ParseMatcher(`team=""`)
+	require.NoError(t, err)
+	assert.True(t, m.Matches(labels))
+	assert.Equal(t, `team=""`, m.String())
+}
+
+func TestAlertConfigLabelSet(t *testing.T) {
+	target := "nginx"
+	alert := AlertConfig{
+		ID:       "alert-1",
+		Name:     "Nginx CPU",
+		Severity: SeverityWarning,
+		Labels:   map[string]string{"team": "web", LabelSeverity: "custom"},
+		GroupID:  "frontend",
+		Threshold: ThresholdConfig{
+			MetricType: MetricProcess,
+			MetricName: "cpu_percent",
+			Target:     &target,
+		},
+	}
+
+	labels := alert.LabelSet()
+	assert.Equal(t, "web", labels["team"])
+	assert.Equal(t, "warning", labels[LabelSeverity], "built-in labels take precedence")
+	assert.Equal(t, "Nginx CPU", labels[LabelAlertName])
+	assert.Equal(t, "nginx", labels[LabelTarget])
+	assert.Equal(t, "frontend", labels[LabelGroup])
+	assert.Equal(t, Fingerprint(labels), Fingerprint(alert.LabelSet()))
+
+	alert.Team = "storage"
+	assert.Equal(t, "storage", alert.LabelSet()["team"], "the team field takes precedence")
+}
+
+func TestSilenceStateAndMutes(t *testing.T) {
+	now := time.Date(2026, 10, 14, 12, 0, 0, 0, time.UTC)
+	silence := Silence{
+		ID:       "s1",
+		Matchers: []Matcher{{Name: "team", Value: "web", IsEqual: true}},
+		StartsAt: now,
+		EndsAt:   now.Add(time.Hour),
+	}
+	require.NoError(t, silence.Validate())
+
+	assert.Equal(t, SilencePending, silence.State(now.Add(-time.Minute)))
+	assert.Equal(t, SilenceActive, silence.State(now))
+	assert.Equal(t, SilenceExpired, silence.State(now.Add(time.Hour)))
+
+	assert.True(t, silence.Mutes(map[string]string{"team": "web"}, now.Add(time.Minute)))
+	assert.False(t, silence.Mutes(map[string]string{"team": "db"}, now.Add(time.Minute)))
+	assert.False(t, silence.Mutes(map[string]string{"team": "web"}, now.Add(2*time.Hour)))
+
+	invalid := silence
+	invalid.EndsAt = now.Add(-time.Minute)
+	assert.Error(t, invalid.Validate())
+	invalid = silence
+	invalid.Matchers = nil
+	assert.Error(t, invalid.Validate())
+}
@@ -0,0 +1,58 @@
+// File: internal/models/summary.go
+// Brief: Alert state summary model
+// Detailed: Contains the AlertSummary counting alerts by state and firing alerts by severity, with the highest firing severity and the firing alerts nobody (or, with identified users, the requesting user) has acknowledged by reading their notification, for dashboards and status badges.
+// Author: drama.lin@aver.com
+// Date: 2026-10-14
+
+package models
+
+import "slices"
+
+// AlertSummary counts alerts by state and the firing ones by severity
+type AlertSummary struct {
+	Total               int                   `json:"total"`
+	ByState             map[AlertState]int    `json:"by_state"`
+	BySeverity          map[AlertSeverity]int `json:"by_severity"`                // Firing (pending or active) alerts only
+	HighestSeverity     AlertSeverity         `json:"highest_severity,omitempty"` // Of the firing alerts; empty when none fires
+	Unacknowledged      int                   `json:"unacknowledged"`             // Firing alerts with an unread notification
+	UnreadNotifications int                   `json:"unread_notifications"`
+	User                string                `json:"user,omitempty"` // Whose read state the unread counts reflect
+}
+
+// NewAlertSummary returns a summary with every state and severity at zero
+func NewAlertSummary() *AlertSummary {
+	s := &AlertSummary{
+		ByState: map[AlertState]int{
+			StateActive:   0,
+			StatePending:  0,
+			StateResolved: 0,
+			StateInactive: 0,
+		},
+		BySeverity: make(map[AlertSeverity]int, len(Severities)),
+	}
+	for _, severity := range Severities {
+		s.BySeverity[severity] = 0
+	}
+	return s
+}
+
+// Add counts one alert. unread tells whether it has an unread notification.
+func (s *AlertSummary) Add(severity AlertSeverity, state AlertState, unread bool) {
+	s.Total++
+	s.ByState[state]++
+	if !state.Firing() {
+		return
+	}
+	s.BySeverity[severity]++
+	if unread {
+		s.Unacknowledged++
+	}
+	if slices.Index(Severities, severity) > slices.Index(Severities, s.HighestSeverity) {
+		s.HighestSeverity = severity
+	}
+}
+
+// Active returns the number of firing alerts, pending or active
+func (s *AlertSummary) Active() int {
+	return s.ByState[StatePending] + s.ByState[StateActive]
+}
@@ -0,0 +1,29 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAlertSummary_Add(t *testing.T) {
+	s := NewAlertSummary()
+	assert.Zero(t, s.Active())
+	assert.Empty(t, s.HighestSeverity)
+	assert.Equal(t, 0, s.BySeverity[SeverityCritical])
+
+	s.Add(SeverityWarning, StateActive, true)
+	s.Add(SeverityInfo, StateActive, false)
+	s.Add(SeverityCritical, StateInactive, false) // Not firing
+	s.Add(SeverityCritical, StateResolved, true)
+	assert.Equal(t, SeverityWarning, s.HighestSeverity)
+
+	s.Add(SeverityCritical, StatePending, true) // Firing, as evaluated alerts are
+	assert.Equal(t, 5, s.Total)
+	assert.Equal(t, 3, s.Active())
+	assert.Equal(t, 1, s.ByState[StatePending])
+	assert.Equal(t, 1, s.ByState[StateInactive])
+	assert.Equal(t, map[AlertSeverity]int{SeverityInfo: 1, SeverityWarning: 1, SeverityCritical: 1}, s.BySeverity)
+	assert.Equal(t, SeverityCritical, s.HighestSeverity)
+	assert.Equal(t, 2, s.Unacknowledged)
+}
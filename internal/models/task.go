@@ -10,6 +10,9 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"slices"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -26,6 +29,14 @@ const (
 	TaskSystemCleanup      TaskType = "system_cleanup"      // Temporary file cleanup task
 )
 
+// TaskTypes lists every task type
+var TaskTypes = []TaskType{TaskLogRotation, TaskMetricsAggregation, TaskHealthCheck, TaskSystemCleanup}
+
+// ParamDryRun is the task parameter asking runners that delete or modify files
+// to report the actions they would take without performing them, e.g.
+// "dry_run": "true"
+const ParamDryRun = "dry_run"
+
 // TaskStatus represents the current execution status of a task
 type TaskStatus string
 
@@ -39,9 +50,14 @@ const (
 
 // Schedule defines when and how often a task should run
 type Schedule struct {
-	CronExpression string    `json:"cron_expression"` // Cron expression for recurring tasks
-	OneTime        bool      `json:"one_time"`        // Whether this is a one-time task
-	NextRunTime    time.Time `json:"next_run_time"`   // Next scheduled execution time
+	CronExpression string    `json:"cron_expression"`    // Cron expression for recurring tasks
+	OneTime        bool      `json:"one_time"`           // Whether this is a one-time task
+	NextRunTime    time.Time `json:"next_run_time"`      // Next scheduled execution time (stored in UTC)
+	Timezone       string    `json:"timezone,omitempty"` // IANA timezone the cron expression is evaluated in
+	// When the pending run was originally due and why it was deferred: to an
+	// execution window, or by the throttle while the host is busy
+	DeferredFrom   *time.Time `json:"deferred_from,omitempty"`
+	DeferredReason string     `json:"deferred_reason,omitempty"`
 }
 
 // Validate checks if the schedule configuration is valid
@@ -49,9 +65,27 @@ func (s *Schedule) Validate() error {
 	if s.CronExpression == "" && !s.OneTime {
 		return errors.New("either cron_expression or one_time must be set")
 	}
+	if s.Timezone != "" {
+		if _, err := time.LoadLocation(s.Timezone); err != nil {
+			return fmt.Errorf("invalid timezone %q: %w", s.Timezone, err)
+		}
+	}
 	return nil
 }
 
+// Location returns the schedule's timezone, or fallback when none is set or it cannot be loaded
+func (s *Schedule) Location(fallback *time.Location) *time.Location {
+	if s.Timezone != "" {
+		if loc, err := time.LoadLocation(s.Timezone); err == nil {
+			return loc
+		}
+	}
+	if fallback == nil {
+		return time.UTC
+	}
+	return fallback
+}
+
 // TaskConfig defines a complete task configuration
 type TaskConfig struct {
 	ID          string            `json:"id"`                    // Unique identifier for the task
@@ -61,6 +95,13 @@ type TaskConfig struct {
 	Enabled     bool              `json:"enabled"`               // Whether this task is active
 	Schedule    Schedule          `json:"schedule"`              // When to run the task
 	Parameters  map[string]string `json:"parameters,omitempty"`  // Task-specific parameters
+	Tags        []string          `json:"tags,omitempty"`        // Free-form tags for bulk operations, e.g. "cleanup"
+	Owner       string            `json:"owner,omitempty"`       // Person responsible for the task
+	Team        string            `json:"team,omitempty"`        // Owning team
+	Contact     string            `json:"contact,omitempty"`     // Email, URL or chat handle to reach the owner
+	Windows     []ExecutionWindow `json:"windows,omitempty"`     // Times of day the task may run, in the schedule timezone
+	Critical    bool              `json:"critical,omitempty"`    // Never deferred by the throttle while the host is busy
+	Agents      *AgentTarget      `json:"agents,omitempty"`      // Agents running the task instead of the server
 	CreatedAt   time.Time         `json:"created_at"`            // Creation timestamp
 	UpdatedAt   time.Time         `json:"updated_at"`            // Last update timestamp
 }
@@ -78,15 +119,37 @@ func (t *TaskConfig) Validate() error {
 		return errors.New("task type is required")
 	}
 	// Validate task type
-	validTaskTypes := map[TaskType]bool{
-		TaskLogRotation:        true,
-		TaskMetricsAggregation: true,
-		TaskHealthCheck:        true,
-		TaskSystemCleanup:      true,
-	}
-	if !validTaskTypes[t.Type] {
+	if !slices.Contains(TaskTypes, t.Type) {
 		return fmt.Errorf("invalid task type: %s", t.Type)
 	}
+	for _, tag := range t.Tags {
+		if strings.TrimSpace(tag) == "" {
+			return errors.New("task tags must not be empty")
+		}
+	}
+	if err := validateOwnership(t.Owner, t.Team, t.Contact); err != nil {
+		return fmt.Errorf("invalid ownership: %w", err)
+	}
+	if s, ok := t.Parameters[ParamDryRun]; ok {
+		if _, err := strconv.ParseBool(s); err != nil {
+			return fmt.Errorf("invalid %s parameter %q: must be true or false", ParamDryRun, s)
+		}
+	}
+	if t.Type == TaskHealthCheck {
+		if _, err := ParseHealthCheckParams(t.Parameters); err != nil {
+			return fmt.Errorf("invalid health check parameters: %w", err)
+		}
+	}
+	for _, w := range t.Windows {
+		if err := w.Validate(); err != nil {
+			return fmt.Errorf("invalid execution window: %w", err)
+		}
+	}
+	if t.Agents != nil {
+		if err := t.Agents.Validate(); err != nil {
+			return fmt.Errorf("invalid agents: %w", err)
+		}
+	}
 	// Validate schedule
 	if err := t.Schedule.Validate(); err != nil {
 		return fmt.Errorf("invalid schedule: %w", err)
@@ -94,6 +157,73 @@ func (t *TaskConfig) Validate() error {
 	return nil
 }
 
+// HasTag reports whether the task is tagged with tag
+func (t *TaskConfig) HasTag(tag string) bool {
+	for _, own := range t.Tags {
+		if own == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// DryRun reports whether the task's dry_run parameter is set
+func (t *TaskConfig) DryRun() bool {
+	dryRun, _ := strconv.ParseBool(t.Parameters[ParamDryRun])
+	return dryRun
+}
+
+// TaskSelector selects tasks by tag and type. A task matches when it has any
+// of the tags and is of any of the types; an empty list does not restrict.
+type TaskSelector struct {
+	Tags  []string   `json:"tags,omitempty"`
+	Types []TaskType `json:"types,omitempty"`
+}
+
+// Empty reports whether the selector would match every task
+func (s TaskSelector) Empty() bool {
+	return len(s.Tags) == 0 && len(s.Types) == 0
+}
+
+// Matches reports whether the task satisfies the selector
+func (s TaskSelector) Matches(task *TaskConfig) bool {
+	if len(s.Types) > 0 {
+		matched := false
+		for _, taskType := range s.Types {
+			if task.Type == taskType {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+	if len(s.Tags) == 0 {
+		return true
+	}
+	for _, tag := range s.Tags {
+		if task.HasTag(tag) {
+			return true
+		}
+	}
+	return false
+}
+
+// In returns a copy of the task with its timestamps expressed in loc, for API responses
+func (t TaskConfig) In(loc *time.Location) TaskConfig {
+	t.CreatedAt = t.CreatedAt.In(loc)
+	t.UpdatedAt = t.UpdatedAt.In(loc)
+	if !t.Schedule.NextRunTime.IsZero() {
+		t.Schedule.NextRunTime = t.Schedule.NextRunTime.In(loc)
+	}
+	if t.Schedule.DeferredFrom != nil {
+		deferred := t.Schedule.DeferredFrom.In(loc)
+		t.Schedule.DeferredFrom = &deferred
+	}
+	return t
+}
+
 // GenerateID creates a new unique ID for a task
 func GenerateID() string {
 	return uuid.New().String()
@@ -135,28 +265,37 @@ func NewTaskExecution(taskID string) *TaskExecution {
 		ExecutionID: GenerateID(),
 		TaskID:      taskID,
 		Status:      StatusPending,
-		StartTime:   time.Now(),
+		StartTime:   time.Now().UTC(),
 	}
 }
 
 // Complete marks an execution as completed successfully
 func (e *TaskExecution) Complete(output string) {
 	e.Status = StatusCompleted
-	e.EndTime = time.Now()
+	e.EndTime = time.Now().UTC()
 	e.Output = output
 }
 
 // Fail marks an execution as failed
 func (e *TaskExecution) Fail(errMsg string) {
 	e.Status = StatusFailed
-	e.EndTime = time.Now()
+	e.EndTime = time.Now().UTC()
 	e.Error = errMsg
 }
 
 // Start marks an execution as running
 func (e *TaskExecution) Start() {
 	e.Status = StatusRunning
-	e.StartTime = time.Now()
+	e.StartTime = time.Now().UTC()
+}
+
+// In returns a copy of the execution with its timestamps expressed in loc, for API responses
+func (e TaskExecution) In(loc *time.Location) TaskExecution {
+	e.StartTime = e.StartTime.In(loc)
+	if !e.EndTime.IsZero() {
+		e.EndTime = e.EndTime.In(loc)
+	}
+	return e
 }
 
 // TaskRepository defines the interface for task storage operations
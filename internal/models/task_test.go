@@ -269,3 +269,82 @@ func TestTaskExecution(t *testing.T) {
 		})
 	}
 }
+
+func TestScheduleTimezone(t *testing.T) {
+	s := Schedule{CronExpression: "0 9 * * *", Timezone: "Asia/Taipei"}
+	require.NoError(t, s.Validate())
+	assert.Equal(t, "Asia/Taipei", s.Location(time.UTC).String())
+
+	s.Timezone = "Mars/Olympus_Mons"
+	assert.Error(t, s.Validate())
+	assert.Equal(t, time.UTC, s.Location(time.UTC), "unloadable timezones use the fallback")
+
+	s.Timezone = ""
+	assert.Equal(t, time.UTC, s.Location(nil))
+}
+
+func TestTaskConfigIn(t *testing.T) {
+	taipei, err := time.LoadLocation("Asia/Taipei")
+	require.NoError(t, err)
+
+	stored := time.Date(2026, 1, 2, 1, 0, 0, 0, time.UTC)
+	task := TaskConfig{CreatedAt: stored, UpdatedAt: stored, Schedule: Schedule{NextRunTime: stored}}
+
+	rendered := task.In(taipei)
+	assert.Equal(t, 9, rendered.Schedule.NextRunTime.Hour())
+	assert.True(t, rendered.Schedule.NextRunTime.Equal(stored), "rendering keeps the same instant")
+	assert.Equal(t, time.UTC, task.CreatedAt.Location(), "the original is not modified")
+
+	execution := TaskExecution{StartTime: stored}
+	renderedExec := execution.In(taipei)
+	assert.Equal(t, taipei, renderedExec.StartTime.Location())
+	assert.True(t, renderedExec.EndTime.IsZero(), "unset end time stays zero")
+}
+
+func TestTaskSelector(t *testing.T) {
+	cleanup := &TaskConfig{Type: TaskSystemCleanup, Tags: []string{"cleanup", "nightly"}}
+	rotation := &TaskConfig{Type: TaskLogRotation, Tags: []string{"nightly"}}
+	health := &TaskConfig{Type: TaskHealthCheck}
+
+	byTag := TaskSelector{Tags: []string{"cleanup"}}
+	assert.True(t, byTag.Matches(cleanup))
+	assert.False(t, byTag.Matches(rotation))
+	assert.False(t, byTag.Matches(health))
+
+	byType := TaskSelector{Types: []TaskType{TaskLogRotation, TaskHealthCheck}}
+	assert.False(t, byType.Matches(cleanup))
+	assert.True(t, byType.Matches(rotation))
+	assert.True(t, byType.Matches(health))
+
+	both := TaskSelector{Tags: []string{"nightly"}, Types: []TaskType{TaskLogRotation}}
+	assert.False(t, both.Matches(cleanup), "tags and types must both match")
+	assert.True(t, both.Matches(rotation))
+
+	assert.True(t, TaskSelector{}.Empty())
+	assert.True(t, TaskSelector{}.Matches(health))
+	assert.False(t, both.Empty())
+}
+
+func TestTaskConfigValidate_Tags(t *testing.T) {
+	task := TaskConfig{ID: "t1", Name: "Cleanup", Type: TaskSystemCleanup, Schedule: Schedule{CronExpression: "0 3 * * *"}, Tags: []string{"cleanup"}}
+	assert.NoError(t, task.Validate())
+
+	task.Tags = append(task.Tags, " ")
+	assert.Error(t, task.Validate())
+}
+
+func TestTaskConfigDryRun(t *testing.T) {
+	task := TaskConfig{ID: "t1", Name: "Cleanup", Type: TaskSystemCleanup, Schedule: Schedule{CronExpression: "0 3 * * *"}}
+	assert.False(t, task.DryRun())
+
+	task.Parameters = map[string]string{ParamDryRun: "true"}
+	assert.NoError(t, task.Validate())
+	assert.True(t, task.DryRun())
+
+	task.Parameters[ParamDryRun] = "false"
+	assert.NoError(t, task.Validate())
+	assert.False(t, task.DryRun())
+
+	task.Parameters[ParamDryRun] = "maybe"
+	assert.ErrorContains(t, task.Validate(), "invalid dry_run parameter")
+}
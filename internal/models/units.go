@@ -0,0 +1,289 @@
+// File: internal/models/units.go
+// Brief: Units for alert threshold values
+// Detailed: Parses and formats human-friendly quantities such as "10 MB", "90%" or "250ms". Threshold values are always stored in the base unit of their dimension (bytes, bytes per second, milliseconds, percent); the unit is kept only to render values the way the user wrote them.
+// Author: drama.lin@aver.com
+// Date: 2026-10-14
+
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Unit is the display unit of a threshold value
+type Unit string
+
+// Supported units. Decimal prefixes are powers of 1000, binary ones of 1024.
+const (
+	UnitPercent        Unit = "%"
+	UnitBytes          Unit = "B"
+	UnitKB             Unit = "KB"
+	UnitMB             Unit = "MB"
+	UnitGB             Unit = "GB"
+	UnitTB             Unit = "TB"
+	UnitKiB            Unit = "KiB"
+	UnitMiB            Unit = "MiB"
+	UnitGiB            Unit = "GiB"
+	UnitBytesPerSecond Unit = "B/s"
+	UnitKBPerSecond    Unit = "KB/s"
+	UnitMBPerSecond    Unit = "MB/s"
+	UnitGBPerSecond    Unit = "GB/s"
+	UnitMilliseconds   Unit = "ms"
+	UnitSeconds        Unit = "s"
+	UnitMinutes        Unit = "min"
+)
+
+// Dimension is the kind of quantity a unit measures
+type Dimension string
+
+// Available dimensions; DimensionNone is a plain number such as a load average
+const (
+	DimensionNone     Dimension = ""
+	DimensionPercent  Dimension = "percent"
+	DimensionBytes    Dimension = "bytes"
+	DimensionByteRate Dimension = "bytes_per_second"
+	DimensionDuration Dimension = "duration" // Base unit is milliseconds
+)
+
+type unitInfo struct {
+	dimension Dimension
+	factor    float64 // Base units per unit
+}
+
+var units = map[Unit]unitInfo{
+	UnitPercent:        {DimensionPercent, 1},
+	UnitBytes:          {DimensionBytes, 1},
+	UnitKB:             {DimensionBytes, 1e3},
+	UnitMB:             {DimensionBytes, 1e6},
+	UnitGB:             {DimensionBytes, 1e9},
+	UnitTB:             {DimensionBytes, 1e12},
+	UnitKiB:            {DimensionBytes, 1 << 10},
+	UnitMiB:            {DimensionBytes, 1 << 20},
+	UnitGiB:            {DimensionBytes, 1 << 30},
+	UnitBytesPerSecond: {DimensionByteRate, 1},
+	UnitKBPerSecond:    {DimensionByteRate, 1e3},
+	UnitMBPerSecond:    {DimensionByteRate, 1e6},
+	UnitGBPerSecond:    {DimensionByteRate, 1e9},
+	UnitMilliseconds:   {DimensionDuration, 1},
+	UnitSeconds:        {DimensionDuration, 1e3},
+	UnitMinutes:        {DimensionDuration, 60e3},
+}
+
+// SupportedUnits returns every supported unit, sorted
+func SupportedUnits() []Unit {
+	result := make([]Unit, 0, len(units))
+	for u := range units {
+		result = append(result, u)
+	}
+	slices.Sort(result)
+	return result
+}
+
+// ParseUnit returns the unit named s, ignoring case ("mb", "MB/s", "percent")
+func ParseUnit(s string) (Unit, error) {
+	s = strings.TrimSpace(s)
+	if strings.EqualFold(s, "percent") {
+		return UnitPercent, nil
+	}
+	if _, ok := units[Unit(s)]; ok {
+		return Unit(s), nil
+	}
+	for u := range units {
+		if strings.EqualFold(string(u), s) {
+			return u, nil
+		}
+	}
+	return "", fmt.Errorf("unknown unit: %q", s)
+}
+
+// ParseQuantity splits a quantity such as "10MB", "1.5 GB" or "90%" into its
+// number and unit. The unit is empty when s is a plain number.
+func ParseQuantity(s string) (float64, Unit, error) {
+	s = strings.TrimSpace(s)
+	split := len(s)
+	for i, c := range s {
+		if c == '%' || c == '/' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') {
+			// An exponent ("1e6") is still part of the number
+			if (c == 'e' || c == 'E') && i > 0 && i+1 < len(s) && strings.ContainsAny(s[i+1:i+2], "0123456789+-") {
+				continue
+			}
+			split = i
+			break
+		}
+	}
+	number, err := strconv.ParseFloat(strings.TrimSpace(s[:split]), 64)
+	if err != nil {
+		return 0, "", fmt.Errorf("invalid quantity %q", s)
+	}
+	if split == len(s) {
+		return number, "", nil
+	}
+	unit, err := ParseUnit(s[split:])
+	if err != nil {
+		return 0, "", fmt.Errorf("invalid quantity %q: %w", s, err)
+	}
+	return number, unit, nil
+}
+
+// Valid reports whether u is empty or a supported unit
+func (u Unit) Valid() bool {
+	_, ok := units[u]
+	return u == "" || ok
+}
+
+// Dimension returns what the unit measures
+func (u Unit) Dimension() Dimension {
+	return units[u].dimension
+}
+
+// ToBase converts v from u to the base unit of u's dimension
+func (u Unit) ToBase(v float64) float64 {
+	if info, ok := units[u]; ok {
+		return v * info.factor
+	}
+	return v
+}
+
+// FromBase converts v from the base unit of u's dimension to u
+func (u Unit) FromBase(v float64) float64 {
+	if info, ok := units[u]; ok {
+		return v / info.factor
+	}
+	return v
+}
+
+// Format renders a base-unit value in u, e.g. "10 MB" or "90%". Without a
+// unit it keeps the two decimals notifications have always shown.
+func (u Unit) Format(v float64) string {
+	if u == "" {
+		return strconv.FormatFloat(v, 'f', 2, 64)
+	}
+	number := strconv.FormatFloat(math.Round(u.FromBase(v)*100)/100, 'f', -1, 64)
+	if u == UnitPercent {
+		return number + string(u)
+	}
+	return number + " " + string(u)
+}
+
+// MetricDimension returns the dimension of a built-in metric's values. ok is
+// false for metrics whose unit is not known up front, e.g. ingested series.
+func MetricDimension(metricType MetricType, metricName string) (dimension Dimension, ok bool) {
+	switch metricType {
+	case MetricLoad, MetricLog:
+		return DimensionNone, true
+	case MetricCPU, MetricProcess, MetricAPI, MetricRAID, MetricPower, MetricService, MetricHTTP:
+		switch {
+		case strings.HasSuffix(metricName, "_percent"):
+			return DimensionPercent, true
+		case strings.HasSuffix(metricName, "_ms"):
+			return DimensionDuration, true
+		}
+		return DimensionNone, true
+	case MetricMemory:
+		if metricName == "used_percent" {
+			return DimensionPercent, true
+		}
+		return DimensionBytes, true
+	case MetricDisk:
+		if metricName == "free" {
+			return DimensionBytes, true
+		}
+		return DimensionPercent, true
+	case MetricNetwork:
+		if strings.HasPrefix(metricName, "bytes_") {
+			return DimensionBytes, true
+		}
+		return DimensionNone, true
+	}
+	return DimensionNone, false
+}
+
+// Display renders the threshold value in its unit, e.g. "10 MB", or the
+// string value of string metrics
+func (t ThresholdConfig) Display() string {
+	if t.StringMetric() {
+		return t.StringValue
+	}
+	return t.Unit.Format(t.Value)
+}
+
+// FormatValue renders a metric value in the threshold's unit, so templates
+// can show the current value the same way as the threshold
+func (t ThresholdConfig) FormatValue(v float64) string {
+	return t.Unit.Format(v)
+}
+
+// validateUnit checks that the unit is known and measures the same thing as
+// the metric
+func (t *ThresholdConfig) validateUnit() error {
+	if !t.Unit.Valid() {
+		return fmt.Errorf("invalid unit: %s", t.Unit)
+	}
+	if t.Unit == "" {
+		return nil
+	}
+	if dimension, ok := MetricDimension(t.MetricType, t.MetricName); ok && dimension != t.Unit.Dimension() {
+		if dimension == DimensionNone {
+			return fmt.Errorf("metric %s/%s takes no unit", t.MetricType, t.MetricName)
+		}
+		return fmt.Errorf("unit %s does not measure %s, the dimension of %s/%s", t.Unit, dimension, t.MetricType, t.MetricName)
+	}
+	return nil
+}
+
+// UnmarshalJSON accepts the value either as a number in base units or as a
+// quantity string such as "10MB". A string without a unit is read in the
+// threshold's unit. The unit of a quantity string becomes the threshold's
+// unit unless one is given explicitly.
+func (t *ThresholdConfig) UnmarshalJSON(data []byte) error {
+	type plain ThresholdConfig
+	aux := struct {
+		*plain
+		Value   json.RawMessage `json:"value"`
+		Display string          `json:"display,omitempty"` // Output only
+	}{plain: (*plain)(t)}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	if len(aux.Value) == 0 || string(aux.Value) == "null" {
+		return nil
+	}
+
+	var quantity string
+	if err := json.Unmarshal(aux.Value, &quantity); err != nil {
+		return json.Unmarshal(aux.Value, &t.Value)
+	}
+	number, unit, err := ParseQuantity(quantity)
+	if err != nil {
+		return err
+	}
+	if t.Unit == "" {
+		t.Unit = unit
+	}
+	if unit == "" {
+		unit = t.Unit
+	}
+	if unit.Dimension() != t.Unit.Dimension() {
+		return fmt.Errorf("value %q does not match unit %s", quantity, t.Unit)
+	}
+	t.Value = unit.ToBase(number)
+	return nil
+}
+
+// MarshalJSON adds the value rendered in its unit as "display"
+func (t ThresholdConfig) MarshalJSON() ([]byte, error) {
+	type plain ThresholdConfig
+	aux := struct {
+		plain
+		Display string `json:"display,omitempty"`
+	}{plain: plain(t)}
+	if t.Unit != "" {
+		aux.Display = t.Display()
+	}
+	return json.Marshal(aux)
+}
@@ -0,0 +1,121 @@
+package models
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseQuantity(t *testing.T) {
+	tests := []struct {
+		input  string
+		number float64
+		unit   Unit
+	}{
+		{"10MB", 10, UnitMB},
+		{"1.5 gb", 1.5, UnitGB},
+		{"90%", 90, UnitPercent},
+		{"90 percent", 90, UnitPercent},
+		{"250ms", 250, UnitMilliseconds},
+		{"20 MB/s", 20, UnitMBPerSecond},
+		{"1e6", 1e6, ""},
+		{"2e3 B", 2000, UnitBytes},
+		{" 42 ", 42, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			number, unit, err := ParseQuantity(tt.input)
+			require.NoError(t, err)
+			assert.Equal(t, tt.number, number)
+			assert.Equal(t, tt.unit, unit)
+		})
+	}
+
+	for _, input := range []string{"", "MB", "10 parsecs", "ten MB"} {
+		_, _, err := ParseQuantity(input)
+		assert.Error(t, err, input)
+	}
+}
+
+func TestUnitConversionAndFormat(t *testing.T) {
+	assert.Equal(t, 10e6, UnitMB.ToBase(10))
+	assert.Equal(t, float64(3<<30), UnitGiB.ToBase(3))
+	assert.Equal(t, 1500.0, UnitSeconds.ToBase(1.5))
+	assert.Equal(t, 2.5, UnitGB.FromBase(2.5e9))
+
+	assert.Equal(t, "10 MB", UnitMB.Format(10e6))
+	assert.Equal(t, "1.23 GB", UnitGB.Format(1234567890))
+	assert.Equal(t, "90%", UnitPercent.Format(90))
+	assert.Equal(t, "85.50", Unit("").Format(85.5), "no unit keeps two decimals")
+}
+
+func TestThresholdConfigValidate_Unit(t *testing.T) {
+	threshold := ThresholdConfig{MetricType: MetricMemory, MetricName: "used", Operator: OperatorGreaterThan, Value: 8e9, Unit: UnitGB}
+	assert.NoError(t, threshold.Validate())
+
+	threshold.Unit = UnitPercent
+	assert.Error(t, threshold.Validate(), "percent on a byte metric")
+
+	threshold.Unit = "furlongs"
+	assert.Error(t, threshold.Validate())
+
+	load := ThresholdConfig{MetricType: MetricCPU, MetricName: "load1", Operator: OperatorGreaterThan, Value: 4, Unit: UnitMB}
+	assert.Error(t, load.Validate(), "load averages take no unit")
+
+	latency := ThresholdConfig{MetricType: MetricAPI, MetricName: "avg_latency_ms", Operator: OperatorGreaterThan, Value: 500, Unit: UnitSeconds}
+	assert.NoError(t, latency.Validate())
+
+	var runtime ThresholdConfig
+	require.NoError(t, json.Unmarshal([]byte(`{"metric_type":"power","metric_name":"runtime_ms","operator":"<","value":"10min"}`), &runtime))
+	assert.Equal(t, 600000.0, runtime.Value)
+	assert.NoError(t, runtime.Validate())
+
+	var free ThresholdConfig
+	require.NoError(t, json.Unmarshal([]byte(`{"metric_type":"disk","metric_name":"free","target":"/var","operator":"<","value":"5GB"}`), &free))
+	assert.Equal(t, 5e9, free.Value)
+	assert.NoError(t, free.Validate())
+	inodes := ThresholdConfig{MetricType: MetricDisk, MetricName: "inode_percent", Operator: OperatorGreaterThan, Value: 90, Unit: UnitGB}
+	assert.Error(t, inodes.Validate(), "inode usage is a percentage")
+
+	series := ThresholdConfig{MetricType: MetricSeries, MetricName: "disk_write_bytes", Operator: OperatorGreaterThan, Value: 2e7, Unit: UnitMBPerSecond}
+	assert.NoError(t, series.Validate(), "series units are not known up front")
+}
+
+func TestThresholdConfigJSON_Units(t *testing.T) {
+	var threshold ThresholdConfig
+	require.NoError(t, json.Unmarshal([]byte(`{"metric_type":"memory","metric_name":"used","operator":">","value":"1.5GB"}`), &threshold))
+	assert.Equal(t, 1.5e9, threshold.Value)
+	assert.Equal(t, UnitGB, threshold.Unit)
+	assert.Equal(t, "1.5 GB", threshold.Display())
+
+	// An explicit unit wins; a bare number string is read in that unit
+	require.NoError(t, json.Unmarshal([]byte(`{"metric_type":"memory","metric_name":"used","operator":">","value":"1GB","unit":"MB"}`), &threshold))
+	assert.Equal(t, 1e9, threshold.Value)
+	assert.Equal(t, "1000 MB", threshold.Display())
+	require.NoError(t, json.Unmarshal([]byte(`{"metric_type":"memory","metric_name":"used","operator":">","value":"512","unit":"MiB"}`), &threshold))
+	assert.Equal(t, float64(512<<20), threshold.Value)
+
+	// Numbers are always in base units
+	require.NoError(t, json.Unmarshal([]byte(`{"metric_type":"memory","metric_name":"used","operator":">","value":10000000,"unit":"MB"}`), &threshold))
+	assert.Equal(t, 1e7, threshold.Value)
+
+	assert.Error(t, json.Unmarshal([]byte(`{"value":"90%","unit":"MB"}`), &threshold))
+	assert.Error(t, json.Unmarshal([]byte(`{"value":"10 parsecs"}`), &threshold))
+
+	// Round trip through the stored form, which carries a display value
+	data, err := json.Marshal(ThresholdConfig{MetricType: MetricMemory, MetricName: "used", Operator: OperatorGreaterThan, Value: 2e9, Unit: UnitGB})
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"value":2000000000`)
+	assert.Contains(t, string(data), `"display":"2 GB"`)
+	var decoded ThresholdConfig
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	assert.Equal(t, 2e9, decoded.Value)
+	assert.Equal(t, UnitGB, decoded.Unit)
+
+	data, err = json.Marshal(ThresholdConfig{MetricType: MetricCPU, MetricName: "usage_percent", Operator: OperatorGreaterThan, Value: 90})
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "display")
+	assert.NotContains(t, string(data), "unit")
+}
@@ -0,0 +1,106 @@
+// File: internal/models/window.go
+// Brief: Time-of-day execution windows for tasks
+// Detailed: Contains the ExecutionWindow type restricting when a task may run, e.g. cleanup only between 02:00 and 05:00, and the TaskConfig helpers the scheduler uses to defer runs due outside the windows to the next window start. Windows are evaluated in the task's schedule timezone and may cross midnight.
+// Author: drama.lin@aver.com
+// Date: 2026-10-14
+
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+// windowClockLayout is the time-of-day format of execution windows
+const windowClockLayout = "15:04"
+
+// ExecutionWindow is a daily time-of-day range, start inclusive and end
+// exclusive, in which a task may run. A window whose end is before its start
+// crosses midnight, e.g. 22:00-02:00.
+type ExecutionWindow struct {
+	Start string `json:"start"` // HH:MM
+	End   string `json:"end"`   // HH:MM
+}
+
+// Validate checks that both ends are HH:MM and differ
+func (w ExecutionWindow) Validate() error {
+	start, err := time.Parse(windowClockLayout, w.Start)
+	if err != nil {
+		return fmt.Errorf("invalid window start %q: must be HH:MM", w.Start)
+	}
+	end, err := time.Parse(windowClockLayout, w.End)
+	if err != nil {
+		return fmt.Errorf("invalid window end %q: must be HH:MM", w.End)
+	}
+	if start.Equal(end) {
+		return fmt.Errorf("window %s must not start and end at the same time", w)
+	}
+	return nil
+}
+
+// String renders the window as start-end, e.g. 02:00-05:00
+func (w ExecutionWindow) String() string {
+	return w.Start + "-" + w.End
+}
+
+// windowMinutes returns the minutes after midnight of an HH:MM time, or 0 when it
+// cannot be parsed
+func windowMinutes(clock string) int {
+	t, err := time.Parse(windowClockLayout, clock)
+	if err != nil {
+		return 0
+	}
+	return t.Hour()*60 + t.Minute()
+}
+
+// Contains reports whether t, on its own wall clock, falls inside the window
+func (w ExecutionWindow) Contains(t time.Time) bool {
+	start, end := windowMinutes(w.Start)*60, windowMinutes(w.End)*60
+	at := t.Hour()*3600 + t.Minute()*60 + t.Second()
+	if start < end {
+		return at >= start && at < end
+	}
+	return at >= start || at < end
+}
+
+// NextStart returns the first start of the window at or after t, in t's location
+func (w ExecutionWindow) NextStart(t time.Time) time.Time {
+	minutes := windowMinutes(w.Start)
+	start := time.Date(t.Year(), t.Month(), t.Day(), minutes/60, minutes%60, 0, 0, t.Location())
+	if start.Before(t) {
+		start = time.Date(t.Year(), t.Month(), t.Day()+1, minutes/60, minutes%60, 0, 0, t.Location())
+	}
+	return start
+}
+
+// InWindow reports whether the task may run at the given time, evaluating its
+// execution windows in loc. A task without windows may always run.
+func (t *TaskConfig) InWindow(at time.Time, loc *time.Location) bool {
+	if len(t.Windows) == 0 {
+		return true
+	}
+	local := at.In(loc)
+	for _, w := range t.Windows {
+		if w.Contains(local) {
+			return true
+		}
+	}
+	return false
+}
+
+// WindowStart returns when a run due at the given time happens, in UTC: at
+// itself when the task may run then, otherwise the earliest next start of one
+// of its execution windows, evaluated in loc
+func (t *TaskConfig) WindowStart(at time.Time, loc *time.Location) time.Time {
+	if t.InWindow(at, loc) {
+		return at.UTC()
+	}
+	local := at.In(loc)
+	var earliest time.Time
+	for _, w := range t.Windows {
+		if start := w.NextStart(local); earliest.IsZero() || start.Before(earliest) {
+			earliest = start
+		}
+	}
+	return earliest.UTC()
+}
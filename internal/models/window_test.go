@@ -0,0 +1,60 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExecutionWindowValidate(t *testing.T) {
+	assert.NoError(t, ExecutionWindow{Start: "02:00", End: "05:00"}.Validate())
+	assert.NoError(t, ExecutionWindow{Start: "22:00", End: "02:00"}.Validate(), "windows may cross midnight")
+	assert.ErrorContains(t, ExecutionWindow{Start: "2am", End: "05:00"}.Validate(), "must be HH:MM")
+	assert.ErrorContains(t, ExecutionWindow{Start: "02:00", End: "25:00"}.Validate(), "must be HH:MM")
+	assert.ErrorContains(t, ExecutionWindow{Start: "02:00", End: "02:00"}.Validate(), "same time")
+
+	task := TaskConfig{ID: "t1", Name: "cleanup", Type: TaskSystemCleanup, Schedule: Schedule{CronExpression: "0 * * * *"},
+		Windows: []ExecutionWindow{{Start: "02:00", End: "5:00pm"}}}
+	assert.ErrorContains(t, task.Validate(), "invalid execution window")
+}
+
+func TestExecutionWindowContains(t *testing.T) {
+	at := func(hour, minute int) time.Time { return time.Date(2026, 10, 14, hour, minute, 0, 0, time.UTC) }
+
+	night := ExecutionWindow{Start: "02:00", End: "05:00"}
+	assert.True(t, night.Contains(at(2, 0)), "start is inclusive")
+	assert.True(t, night.Contains(at(4, 59)))
+	assert.False(t, night.Contains(at(5, 0)), "end is exclusive")
+	assert.False(t, night.Contains(at(1, 59)))
+
+	overnight := ExecutionWindow{Start: "22:00", End: "02:00"}
+	assert.True(t, overnight.Contains(at(23, 0)))
+	assert.True(t, overnight.Contains(at(1, 0)))
+	assert.False(t, overnight.Contains(at(12, 0)))
+
+	assert.Equal(t, at(2, 0), night.NextStart(at(2, 0)))
+	assert.Equal(t, at(2, 0).AddDate(0, 0, 1), night.NextStart(at(6, 0)))
+}
+
+func TestTaskConfigWindowStart(t *testing.T) {
+	taipei, err := time.LoadLocation("Asia/Taipei")
+	require.NoError(t, err)
+	task := TaskConfig{Windows: []ExecutionWindow{{Start: "02:00", End: "05:00"}, {Start: "13:00", End: "14:00"}}}
+
+	// 12:00 Taipei is 04:00 UTC
+	due := time.Date(2026, 10, 14, 4, 0, 0, 0, time.UTC)
+	assert.False(t, task.InWindow(due, taipei))
+	assert.True(t, task.InWindow(due, time.UTC), "windows are evaluated in the given location")
+	assert.Equal(t, time.Date(2026, 10, 14, 5, 0, 0, 0, time.UTC), task.WindowStart(due, taipei), "the earliest window start, 13:00 Taipei")
+
+	// 15:00 Taipei defers to 02:00 Taipei the next day
+	due = time.Date(2026, 10, 14, 7, 0, 0, 0, time.UTC)
+	assert.Equal(t, time.Date(2026, 10, 14, 18, 0, 0, 0, time.UTC), task.WindowStart(due, taipei))
+
+	inside := time.Date(2026, 10, 14, 19, 30, 0, 0, time.UTC) // 03:30 Taipei
+	assert.Equal(t, inside, task.WindowStart(inside.In(taipei), taipei), "runs inside a window are not moved")
+
+	assert.True(t, (&TaskConfig{}).InWindow(due, taipei), "tasks without windows always run")
+}
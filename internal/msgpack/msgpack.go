@@ -0,0 +1,480 @@
+// File: internal/msgpack/msgpack.go
+// Brief: MessagePack encoding of JSON-tagged values
+// Detailed: Encodes Go values to MessagePack using the same field names, omitempty rules and time format as encoding/json, so binary WebSocket clients decode the same documents JSON clients receive; JSON payloads can also be transcoded directly.
+// Author: drama.lin@aver.com
+// Date: 2026-10-14
+
+// Package msgpack encodes values as MessagePack following encoding/json conventions.
+package msgpack
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ContentType is the media type of MessagePack payloads
+const ContentType = "application/msgpack"
+
+// Marshal returns the MessagePack encoding of v. Struct fields are named and
+// omitted as encoding/json would, times are RFC 3339 strings, and types
+// implementing json.Marshaler are transcoded from their JSON form.
+func Marshal(v interface{}) ([]byte, error) {
+	e := &encoder{buf: make([]byte, 0, 256)}
+	if err := e.value(reflect.ValueOf(v)); err != nil {
+		return nil, err
+	}
+	return e.buf, nil
+}
+
+// FromJSON transcodes a JSON document to MessagePack
+func FromJSON(data []byte) ([]byte, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	e := &encoder{buf: make([]byte, 0, len(data))}
+	if err := e.json(dec); err != nil {
+		return nil, err
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return nil, fmt.Errorf("msgpack: trailing data after JSON value")
+	}
+	return e.buf, nil
+}
+
+type encoder struct {
+	buf []byte
+}
+
+var (
+	timeType          = reflect.TypeOf(time.Time{})
+	jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+func (e *encoder) value(v reflect.Value) error {
+	if !v.IsValid() {
+		e.nil()
+		return nil
+	}
+	t := v.Type()
+	if t == timeType {
+		e.string(v.Interface().(time.Time).Format(time.RFC3339Nano))
+		return nil
+	}
+	if t.Implements(jsonMarshalerType) && !(v.Kind() == reflect.Pointer && v.IsNil()) {
+		data, err := v.Interface().(json.Marshaler).MarshalJSON()
+		if err != nil {
+			return err
+		}
+		dec := json.NewDecoder(bytes.NewReader(data))
+		dec.UseNumber()
+		return e.json(dec)
+	}
+	if t.Implements(textMarshalerType) && !(v.Kind() == reflect.Pointer && v.IsNil()) {
+		text, err := v.Interface().(encoding.TextMarshaler).MarshalText()
+		if err != nil {
+			return err
+		}
+		e.string(string(text))
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.Bool:
+		if v.Bool() {
+			e.buf = append(e.buf, 0xc3)
+		} else {
+			e.buf = append(e.buf, 0xc2)
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		e.int(v.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		e.uint(v.Uint())
+	case reflect.Float32:
+		e.buf = append(e.buf, 0xca)
+		e.buf = appendUint32(e.buf, math.Float32bits(float32(v.Float())))
+	case reflect.Float64:
+		e.float64(v.Float())
+	case reflect.String:
+		e.string(v.String())
+	case reflect.Interface, reflect.Pointer:
+		if v.IsNil() {
+			e.nil()
+			return nil
+		}
+		return e.value(v.Elem())
+	case reflect.Slice:
+		if v.IsNil() {
+			e.nil()
+			return nil
+		}
+		if t.Elem().Kind() == reflect.Uint8 {
+			e.bin(v.Bytes())
+			return nil
+		}
+		fallthrough
+	case reflect.Array:
+		e.arrayHeader(v.Len())
+		for i := 0; i < v.Len(); i++ {
+			if err := e.value(v.Index(i)); err != nil {
+				return err
+			}
+		}
+	case reflect.Map:
+		if v.IsNil() {
+			e.nil()
+			return nil
+		}
+		return e.mapValue(v)
+	case reflect.Struct:
+		return e.structValue(v)
+	default:
+		return fmt.Errorf("msgpack: unsupported type %s", t)
+	}
+	return nil
+}
+
+func (e *encoder) mapValue(v reflect.Value) error {
+	keys := make([]string, 0, v.Len())
+	values := make(map[string]reflect.Value, v.Len())
+	iter := v.MapRange()
+	for iter.Next() {
+		k := iter.Key()
+		var name string
+		switch {
+		case k.Kind() == reflect.String:
+			name = k.String()
+		case k.Type().Implements(textMarshalerType):
+			text, err := k.Interface().(encoding.TextMarshaler).MarshalText()
+			if err != nil {
+				return err
+			}
+			name = string(text)
+		default:
+			name = fmt.Sprint(k.Interface())
+		}
+		keys = append(keys, name)
+		values[name] = iter.Value()
+	}
+	// Sorted like encoding/json, so equal maps encode to equal bytes
+	sort.Strings(keys)
+	e.mapHeader(len(keys))
+	for _, k := range keys {
+		e.string(k)
+		if err := e.value(values[k]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (e *encoder) structValue(v reflect.Value) error {
+	fields := cachedFields(v.Type())
+	// Two passes, counting the header first, so no per-struct buffer is needed
+	n := 0
+	for _, f := range fields {
+		if _, ok := f.present(v); ok {
+			n++
+		}
+	}
+	e.mapHeader(n)
+	for _, f := range fields {
+		fv, ok := f.present(v)
+		if !ok {
+			continue
+		}
+		e.string(f.name)
+		if err := e.value(fv); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// fieldByIndex follows an embedded field path, reporting false when it passes
+// through a nil embedded pointer
+func fieldByIndex(v reflect.Value, index []int) (reflect.Value, bool) {
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Pointer {
+			if v.IsNil() {
+				return reflect.Value{}, false
+			}
+			v = v.Elem()
+		}
+		v = v.Field(x)
+	}
+	return v, true
+}
+
+func isEmpty(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return v.Len() == 0
+	case reflect.Bool:
+		return !v.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int() == 0
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return v.Uint() == 0
+	case reflect.Float32, reflect.Float64:
+		return v.Float() == 0
+	case reflect.Interface, reflect.Pointer:
+		return v.IsNil()
+	}
+	return false
+}
+
+type field struct {
+	name      string
+	index     []int
+	omitEmpty bool
+}
+
+// present returns the field of v, reporting false when it is omitted
+func (f field) present(v reflect.Value) (reflect.Value, bool) {
+	fv, ok := fieldByIndex(v, f.index)
+	if !ok || (f.omitEmpty && isEmpty(fv)) {
+		return reflect.Value{}, false
+	}
+	return fv, true
+}
+
+var fieldCache sync.Map // reflect.Type -> []field
+
+func cachedFields(t reflect.Type) []field {
+	if f, ok := fieldCache.Load(t); ok {
+		return f.([]field)
+	}
+	f, _ := fieldCache.LoadOrStore(t, typeFields(t, nil))
+	return f.([]field)
+}
+
+// typeFields lists the encoded fields of t in declaration order, promoting
+// untagged embedded structs and letting shallower fields win over promoted ones
+func typeFields(t reflect.Type, prefix []int) []field {
+	var fields []field
+	seen := make(map[string]bool)
+	var promoted []field
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		tag := sf.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+		index := append(append([]int{}, prefix...), i)
+
+		if sf.Anonymous && name == "" {
+			ft := sf.Type
+			if ft.Kind() == reflect.Pointer {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				promoted = append(promoted, typeFields(ft, index)...)
+				continue
+			}
+		}
+		if !sf.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = sf.Name
+		}
+		seen[name] = true
+		fields = append(fields, field{name: name, index: index, omitEmpty: strings.Contains(","+opts+",", ",omitempty,")})
+	}
+	for _, f := range promoted {
+		if !seen[f.name] {
+			seen[f.name] = true
+			fields = append(fields, f)
+		}
+	}
+	sort.Slice(fields, func(i, j int) bool {
+		a, b := fields[i].index, fields[j].index
+		for k := 0; k < len(a) && k < len(b); k++ {
+			if a[k] != b[k] {
+				return a[k] < b[k]
+			}
+		}
+		return len(a) < len(b)
+	})
+	return fields
+}
+
+// json transcodes the next JSON value read from dec
+func (e *encoder) json(dec *json.Decoder) error {
+	tok, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	switch t := tok.(type) {
+	case json.Delim:
+		var items []byte
+		outer := e.buf
+		e.buf = nil
+		n := 0
+		for dec.More() {
+			if t == '{' {
+				key, err := dec.Token()
+				if err != nil {
+					return err
+				}
+				e.string(key.(string))
+			}
+			if err := e.json(dec); err != nil {
+				return err
+			}
+			n++
+		}
+		if _, err := dec.Token(); err != nil {
+			return err
+		}
+		items, e.buf = e.buf, outer
+		if t == '{' {
+			e.mapHeader(n)
+		} else {
+			e.arrayHeader(n)
+		}
+		e.buf = append(e.buf, items...)
+	case bool:
+		e.value(reflect.ValueOf(t))
+	case json.Number:
+		if i, err := t.Int64(); err == nil {
+			e.int(i)
+		} else if f, err := t.Float64(); err == nil {
+			e.float64(f)
+		} else {
+			return fmt.Errorf("msgpack: invalid number %q", t)
+		}
+	case string:
+		e.string(t)
+	case nil:
+		e.nil()
+	}
+	return nil
+}
+
+func (e *encoder) nil() {
+	e.buf = append(e.buf, 0xc0)
+}
+
+func (e *encoder) int(i int64) {
+	switch {
+	case i >= 0:
+		e.uint(uint64(i))
+	case i >= -32:
+		e.buf = append(e.buf, byte(i))
+	case i >= math.MinInt8:
+		e.buf = append(e.buf, 0xd0, byte(i))
+	case i >= math.MinInt16:
+		e.buf = append(e.buf, 0xd1)
+		e.buf = appendUint16(e.buf, uint16(i))
+	case i >= math.MinInt32:
+		e.buf = append(e.buf, 0xd2)
+		e.buf = appendUint32(e.buf, uint32(i))
+	default:
+		e.buf = append(e.buf, 0xd3)
+		e.buf = appendUint64(e.buf, uint64(i))
+	}
+}
+
+func (e *encoder) uint(u uint64) {
+	switch {
+	case u <= 0x7f:
+		e.buf = append(e.buf, byte(u))
+	case u <= math.MaxUint8:
+		e.buf = append(e.buf, 0xcc, byte(u))
+	case u <= math.MaxUint16:
+		e.buf = append(e.buf, 0xcd)
+		e.buf = appendUint16(e.buf, uint16(u))
+	case u <= math.MaxUint32:
+		e.buf = append(e.buf, 0xce)
+		e.buf = appendUint32(e.buf, uint32(u))
+	default:
+		e.buf = append(e.buf, 0xcf)
+		e.buf = appendUint64(e.buf, u)
+	}
+}
+
+func (e *encoder) float64(f float64) {
+	e.buf = append(e.buf, 0xcb)
+	e.buf = appendUint64(e.buf, math.Float64bits(f))
+}
+
+func (e *encoder) string(s string) {
+	n := len(s)
+	switch {
+	case n <= 31:
+		e.buf = append(e.buf, 0xa0|byte(n))
+	case n <= math.MaxUint8:
+		e.buf = append(e.buf, 0xd9, byte(n))
+	case n <= math.MaxUint16:
+		e.buf = append(e.buf, 0xda)
+		e.buf = appendUint16(e.buf, uint16(n))
+	default:
+		e.buf = append(e.buf, 0xdb)
+		e.buf = appendUint32(e.buf, uint32(n))
+	}
+	e.buf = append(e.buf, s...)
+}
+
+func (e *encoder) bin(b []byte) {
+	n := len(b)
+	switch {
+	case n <= math.MaxUint8:
+		e.buf = append(e.buf, 0xc4, byte(n))
+	case n <= math.MaxUint16:
+		e.buf = append(e.buf, 0xc5)
+		e.buf = appendUint16(e.buf, uint16(n))
+	default:
+		e.buf = append(e.buf, 0xc6)
+		e.buf = appendUint32(e.buf, uint32(n))
+	}
+	e.buf = append(e.buf, b...)
+}
+
+func (e *encoder) arrayHeader(n int) {
+	switch {
+	case n <= 15:
+		e.buf = append(e.buf, 0x90|byte(n))
+	case n <= math.MaxUint16:
+		e.buf = append(e.buf, 0xdc)
+		e.buf = appendUint16(e.buf, uint16(n))
+	default:
+		e.buf = append(e.buf, 0xdd)
+		e.buf = appendUint32(e.buf, uint32(n))
+	}
+}
+
+func (e *encoder) mapHeader(n int) {
+	switch {
+	case n <= 15:
+		e.buf = append(e.buf, 0x80|byte(n))
+	case n <= math.MaxUint16:
+		e.buf = append(e.buf, 0xde)
+		e.buf = appendUint16(e.buf, uint16(n))
+	default:
+		e.buf = append(e.buf, 0xdf)
+		e.buf = appendUint32(e.buf, uint32(n))
+	}
+}
+
+func appendUint16(b []byte, v uint16) []byte {
+	return append(b, byte(v>>8), byte(v))
+}
+
+func appendUint32(b []byte, v uint32) []byte {
+	return append(b, byte(v>>24), byte(v>>16), byte(v>>8), byte(v))
+}
+
+func appendUint64(b []byte, v uint64) []byte {
+	return append(b, byte(v>>56), byte(v>>48), byte(v>>40), byte(v>>32), byte(v>>24), byte(v>>16), byte(v>>8), byte(v))
+}
@@ -0,0 +1,84 @@
+package msgpack
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMarshal_Scalars(t *testing.T) {
+	cases := []struct {
+		in   interface{}
+		want []byte
+	}{
+		{nil, []byte{0xc0}},
+		{true, []byte{0xc3}},
+		{false, []byte{0xc2}},
+		{7, []byte{0x07}},
+		{-5, []byte{0xfb}},
+		{200, []byte{0xcc, 0xc8}},
+		{-200, []byte{0xd1, 0xff, 0x38}},
+		{70000, []byte{0xce, 0x00, 0x01, 0x11, 0x70}},
+		{float32(1.5), []byte{0xca, 0x3f, 0xc0, 0x00, 0x00}},
+		{1.5, []byte{0xcb, 0x3f, 0xf8, 0, 0, 0, 0, 0, 0}},
+		{"hi", []byte{0xa2, 'h', 'i'}},
+		{[]byte{1, 2}, []byte{0xc4, 0x02, 0x01, 0x02}},
+		{[]int{1, 2}, []byte{0x92, 0x01, 0x02}},
+		{map[string]int{"b": 2, "a": 1}, []byte{0x82, 0xa1, 'a', 0x01, 0xa1, 'b', 0x02}},
+	}
+	for _, tc := range cases {
+		got, err := Marshal(tc.in)
+		require.NoError(t, err)
+		assert.Equal(t, tc.want, got, "%#v", tc.in)
+	}
+
+	long, err := Marshal(strings.Repeat("x", 40))
+	require.NoError(t, err)
+	assert.Equal(t, []byte{0xd9, 40}, long[:2])
+}
+
+type inner struct {
+	Host string `json:"host"`
+}
+
+type sample struct {
+	inner
+	PID     int32             `json:"pid"`
+	Name    string            `json:"name"`
+	CPU     float64           `json:"cpu_percent"`
+	Tags    map[string]string `json:"tags,omitempty"`
+	Skipped string            `json:"-"`
+	Note    *string           `json:"note"`
+	At      time.Time         `json:"at"`
+	Plain   bool
+	private int
+}
+
+func TestMarshal_MatchesJSON(t *testing.T) {
+	values := []interface{}{
+		sample{inner: inner{Host: "web-1"}, PID: 42, Name: "nginx", CPU: 12.5, Skipped: "x", At: time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC), private: 1},
+		// Non-integral floats, since JSON cannot tell 0.0 from 0
+		[]sample{{PID: 1, CPU: 0.5, Tags: map[string]string{"env": "prod"}}, {PID: 2, CPU: 99.9, Plain: true}},
+		map[string]interface{}{"type": "processes", "count": 3, "items": []string{"a", "b"}},
+	}
+	for _, v := range values {
+		jsonBytes, err := json.Marshal(v)
+		require.NoError(t, err)
+		fromJSON, err := FromJSON(jsonBytes)
+		require.NoError(t, err)
+		direct, err := Marshal(v)
+		require.NoError(t, err)
+		assert.Equal(t, fromJSON, direct, "%s", jsonBytes)
+	}
+}
+
+func TestFromJSON_Invalid(t *testing.T) {
+	_, err := FromJSON([]byte(`{"a":`))
+	assert.Error(t, err)
+	_, err = FromJSON([]byte(`{} {}`))
+	assert.Error(t, err)
+}
@@ -0,0 +1,98 @@
+// File: internal/netproxy/netproxy.go
+// Brief: Outbound HTTP proxy selection
+// Detailed: Builds the HTTP transports of outbound integrations (notification channels, endpoint health checks) from a proxy URL and a no-proxy list. HTTP, HTTPS and SOCKS5 proxies are supported; without a configured proxy the standard HTTP_PROXY, HTTPS_PROXY and NO_PROXY environment variables apply.
+// Author: drama.lin@aver.com
+// Date: 2026-10-14
+
+package netproxy
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+
+	"golang.org/x/net/http/httpproxy"
+)
+
+// None as the proxy URL connects directly, ignoring the environment
+const None = "none"
+
+// Schemes lists the supported proxy URL schemes
+var Schemes = []string{"http", "https", "socks5", "socks5h"}
+
+// Config selects the proxy of outbound requests
+type Config struct {
+	URL string // Proxy URL, None to connect directly, or empty to use the environment
+	// Hosts reached directly: host names (matching subdomains too), .domains
+	// (subdomains only), IP addresses, CIDR ranges, optionally with :port
+	NoProxy []string
+}
+
+// Validate checks the proxy URL
+func (c Config) Validate() error {
+	if c.URL == "" || c.URL == None {
+		return nil
+	}
+	u, err := url.Parse(c.URL)
+	if err != nil || u.Host == "" {
+		return fmt.Errorf("invalid proxy url %q: must be scheme://host:port", redacted(c.URL))
+	}
+	if !slices.Contains(Schemes, u.Scheme) {
+		return fmt.Errorf("invalid proxy url %q: scheme must be one of %s", redacted(c.URL), strings.Join(Schemes, ", "))
+	}
+	return nil
+}
+
+// Func returns the proxy function of an http.Transport. Requests to loopback
+// addresses are never proxied.
+func (c Config) Func() (func(*http.Request) (*url.URL, error), error) {
+	switch c.URL {
+	case "":
+		return http.ProxyFromEnvironment, nil
+	case None:
+		return nil, nil
+	}
+	if err := c.Validate(); err != nil {
+		return nil, err
+	}
+	proxyFor := (&httpproxy.Config{
+		HTTPProxy:  c.URL,
+		HTTPSProxy: c.URL,
+		NoProxy:    strings.Join(c.NoProxy, ","),
+	}).ProxyFunc()
+	return func(req *http.Request) (*url.URL, error) {
+		return proxyFor(req.URL)
+	}, nil
+}
+
+// Transport returns a copy of http.DefaultTransport using the proxy
+func (c Config) Transport() (*http.Transport, error) {
+	proxy, err := c.Func()
+	if err != nil {
+		return nil, err
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = proxy
+	return transport, nil
+}
+
+// String describes the proxy for logs, without its credentials
+func (c Config) String() string {
+	switch c.URL {
+	case "":
+		return "environment"
+	case None:
+		return "direct"
+	}
+	return redacted(c.URL)
+}
+
+// redacted masks the password of a proxy URL
+func redacted(raw string) string {
+	if u, err := url.Parse(raw); err == nil {
+		return u.Redacted()
+	}
+	return raw
+}
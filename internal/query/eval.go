@@ -0,0 +1,232 @@
+// File: internal/query/eval.go
+// Brief: Evaluation of metric history queries
+// Detailed: Runs a parsed query over the metric history and ingested series: selects the matching series over the range at the step's resolution, aligns them to step buckets and applies the aggregation function per group of labels.
+// Author: drama.lin@aver.com
+// Date: 2026-10-14
+
+package query
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"argus/internal/metrics"
+	"argus/internal/models"
+)
+
+// MaxPoints bounds the points per series a query may return
+const MaxPoints = 11000
+
+// Source provides the series called name within [from, to] at a resolution
+// not coarser than step where possible, and the resolution used
+type Source interface {
+	Select(name string, from, to time.Time, step time.Duration) ([]metrics.AggregateSeries, time.Duration)
+}
+
+// rollupSource serves the rollups of the metric history
+type rollupSource struct{ rollups *metrics.Rollups }
+
+func (s rollupSource) Select(name string, from, to time.Time, step time.Duration) ([]metrics.AggregateSeries, time.Duration) {
+	return s.rollups.Query(name, nil, from, to, step)
+}
+
+// FromRollups serves the metric history through its rollups
+func FromRollups(rollups *metrics.Rollups) Source {
+	return rollupSource{rollups}
+}
+
+// storeSource serves the raw samples of a series store
+type storeSource struct{ store *metrics.SeriesStore }
+
+func (s storeSource) Select(name string, from, to time.Time, step time.Duration) ([]metrics.AggregateSeries, time.Duration) {
+	return s.store.AggregateRange(name, nil, from, to, step), metrics.RawResolution
+}
+
+// FromStore serves the raw samples of store
+func FromStore(store *metrics.SeriesStore) Source {
+	return storeSource{store}
+}
+
+// Range is the time span and step a query is evaluated over
+type Range struct {
+	Start time.Time
+	End   time.Time
+	Step  time.Duration
+}
+
+// Validate checks the range and its number of steps
+func (r Range) Validate() error {
+	if !r.End.After(r.Start) {
+		return fmt.Errorf("end must be after start")
+	}
+	if r.Step <= 0 {
+		return fmt.Errorf("step must be positive")
+	}
+	if points := r.End.Sub(r.Start) / r.Step; points > MaxPoints {
+		return fmt.Errorf("range of %s at %s steps exceeds %d points, use a larger step", r.End.Sub(r.Start), r.Step, MaxPoints)
+	}
+	return nil
+}
+
+// Point is one value of a result series, at the start of its step
+type Point struct {
+	Timestamp time.Time `json:"timestamp"`
+	Value     float64   `json:"value"`
+}
+
+// Series is one series of a query result
+type Series struct {
+	Metric string            `json:"metric"`
+	Labels map[string]string `json:"labels"`
+	Points []Point           `json:"points"`
+}
+
+// Result is the outcome of evaluating a query
+type Result struct {
+	Query      string    `json:"query"`
+	Start      time.Time `json:"start"`
+	End        time.Time `json:"end"`
+	Step       string    `json:"step"`
+	Resolution string    `json:"resolution"` // Resolution of the data read ("raw" for raw samples)
+	Series     []Series  `json:"series"`
+}
+
+// group accumulates the step buckets of the series sharing the By labels
+type group struct {
+	labels  map[string]string
+	buckets map[int64]*bucket
+}
+
+// bucket combines the series of a group within one step
+type bucket struct {
+	agg    metrics.Aggregate
+	sum    float64 // Sum of the series' averages
+	series int
+}
+
+// Evaluate runs q over sources within r. The range must have been validated.
+func Evaluate(q *Query, sources []Source, r Range) Result {
+	result := Result{
+		Query:      q.String(),
+		Start:      r.Start,
+		End:        r.End,
+		Step:       r.Step.String(),
+		Resolution: "raw",
+		Series:     []Series{},
+	}
+
+	groups := make(map[string]*group)
+	var coarsest time.Duration
+	for _, source := range sources {
+		selected, resolution := source.Select(q.Metric, r.Start, r.End, r.Step)
+		coarsest = max(coarsest, resolution)
+		for _, s := range selected {
+			if !models.MatchAll(q.Matchers, s.Labels) {
+				continue
+			}
+			key, labels := groupOf(q, s)
+			g, ok := groups[key]
+			if !ok {
+				g = &group{labels: labels, buckets: make(map[int64]*bucket)}
+				groups[key] = g
+			}
+			for _, step := range alignSteps(s.Points, r) {
+				b, ok := g.buckets[step.Timestamp.UnixNano()]
+				if !ok {
+					b = &bucket{agg: metrics.Aggregate{Timestamp: step.Timestamp}}
+					g.buckets[step.Timestamp.UnixNano()] = b
+				}
+				b.agg.Merge(step)
+				b.sum += step.Avg
+				b.series++
+			}
+		}
+	}
+	if coarsest > 0 {
+		result.Resolution = coarsest.String()
+	}
+
+	for _, g := range groups {
+		series := Series{Metric: q.Metric, Labels: g.labels, Points: make([]Point, 0, len(g.buckets))}
+		for _, b := range g.buckets {
+			series.Points = append(series.Points, Point{Timestamp: b.agg.Timestamp, Value: value(q.Func, b)})
+		}
+		sort.Slice(series.Points, func(i, j int) bool { return series.Points[i].Timestamp.Before(series.Points[j].Timestamp) })
+		result.Series = append(result.Series, series)
+	}
+	sort.Slice(result.Series, func(i, j int) bool {
+		return labelsKey(result.Series[i].Labels) < labelsKey(result.Series[j].Labels)
+	})
+	return result
+}
+
+// groupOf returns the group a series falls in: itself without a function, or
+// the values of the By labels
+func groupOf(q *Query, s metrics.AggregateSeries) (string, map[string]string) {
+	if q.Func == FuncNone {
+		labels := make(map[string]string, len(s.Labels))
+		for k, v := range s.Labels {
+			labels[k] = v
+		}
+		return labelsKey(labels), labels
+	}
+	labels := make(map[string]string, len(q.By))
+	for _, name := range q.By {
+		if v, ok := s.Labels[name]; ok {
+			labels[name] = v
+		}
+	}
+	return labelsKey(labels), labels
+}
+
+// alignSteps merges the points of one series into the steps of r, dropping
+// those outside it
+func alignSteps(points []metrics.Aggregate, r Range) []metrics.Aggregate {
+	var steps []metrics.Aggregate
+	for _, p := range points {
+		if p.Timestamp.Before(r.Start.Truncate(r.Step)) || p.Timestamp.After(r.End) {
+			continue
+		}
+		start := p.Timestamp.Truncate(r.Step)
+		if n := len(steps); n == 0 || !steps[n-1].Timestamp.Equal(start) {
+			steps = append(steps, metrics.Aggregate{Timestamp: start})
+		}
+		steps[len(steps)-1].Merge(p)
+	}
+	return steps
+}
+
+// value applies fn to a bucket
+func value(fn Func, b *bucket) float64 {
+	switch fn {
+	case FuncMin:
+		return b.agg.Min
+	case FuncMax:
+		return b.agg.Max
+	case FuncSum:
+		return b.sum
+	case FuncCount:
+		return float64(b.series)
+	default:
+		return b.agg.Avg
+	}
+}
+
+// labelsKey renders labels in a stable order
+func labelsKey(labels map[string]string) string {
+	keys := make([]string, 0, len(labels))
+	for k := range labels {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var b strings.Builder
+	for _, k := range keys {
+		b.WriteString(k)
+		b.WriteByte('=')
+		b.WriteString(labels[k])
+		b.WriteByte(0)
+	}
+	return b.String()
+}
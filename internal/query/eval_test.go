@@ -0,0 +1,118 @@
+package query
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"argus/internal/clock"
+	"argus/internal/metrics"
+)
+
+var epoch = time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// newTestStore holds node_load1 for three hosts over ten minutes, one sample
+// per minute: web-1 at 1, web-2 at 3 and db-1 at 10
+func newTestStore(t *testing.T) *metrics.SeriesStore {
+	t.Helper()
+	clk := clock.NewFake(epoch.Add(10 * time.Minute))
+	store := metrics.NewSeriesStore(metrics.SeriesStoreConfig{Retention: time.Hour, Clock: clk})
+	for host, value := range map[string]float64{"web-1": 1, "web-2": 3, "db-1": 10} {
+		role := "web"
+		if host == "db-1" {
+			role = "db"
+		}
+		var samples []metrics.Sample
+		for i := 0; i < 10; i++ {
+			samples = append(samples, metrics.Sample{Timestamp: epoch.Add(time.Duration(i) * time.Minute), Value: value + float64(i%2)})
+		}
+		require.NoError(t, store.Append("node_load1", map[string]string{"host": host, "role": role}, samples))
+	}
+	return store
+}
+
+func evaluate(t *testing.T, store *metrics.SeriesStore, query string, step time.Duration) Result {
+	t.Helper()
+	q, err := Parse(query)
+	require.NoError(t, err)
+	r := Range{Start: epoch, End: epoch.Add(10 * time.Minute), Step: step}
+	require.NoError(t, r.Validate())
+	return Evaluate(q, []Source{FromStore(store)}, r)
+}
+
+func TestEvaluate_GroupsAndAggregates(t *testing.T) {
+	store := newTestStore(t)
+
+	byRole := evaluate(t, store, "avg by (role) (node_load1)", 5*time.Minute)
+	require.Len(t, byRole.Series, 2)
+	assert.Equal(t, map[string]string{"role": "db"}, byRole.Series[0].Labels)
+	assert.Equal(t, map[string]string{"role": "web"}, byRole.Series[1].Labels)
+	require.Len(t, byRole.Series[1].Points, 2)
+	// Web hosts alternate 1,2 and 3,4 over five minutes: 3 samples at the low value each
+	assert.InDelta(t, (1+2+1+2+1+3+4+3+4+3)/10.0, byRole.Series[1].Points[0].Value, 1e-9)
+	assert.Equal(t, epoch, byRole.Series[1].Points[0].Timestamp)
+	assert.Equal(t, "raw", byRole.Resolution)
+	assert.Equal(t, "5m0s", byRole.Step)
+
+	maxAll := evaluate(t, store, `max(node_load1{role="web"})`, 10*time.Minute)
+	require.Len(t, maxAll.Series, 1)
+	assert.Empty(t, maxAll.Series[0].Labels)
+	assert.Equal(t, 4.0, maxAll.Series[0].Points[0].Value)
+
+	minAll := evaluate(t, store, `min(node_load1)`, 10*time.Minute)
+	assert.Equal(t, 1.0, minAll.Series[0].Points[0].Value)
+
+	count := evaluate(t, store, `count(node_load1{host=~"web-.*"})`, time.Minute)
+	require.Len(t, count.Series, 1)
+	assert.Len(t, count.Series[0].Points, 10)
+	assert.Equal(t, 2.0, count.Series[0].Points[0].Value)
+
+	sum := evaluate(t, store, "sum(node_load1)", time.Minute)
+	assert.Equal(t, 14.0, sum.Series[0].Points[0].Value) // 1 + 3 + 10
+	assert.Equal(t, 17.0, sum.Series[0].Points[1].Value)
+}
+
+func TestEvaluate_SeriesWithoutFunction(t *testing.T) {
+	store := newTestStore(t)
+
+	result := evaluate(t, store, `node_load1{role="web"}`, 2*time.Minute)
+	require.Len(t, result.Series, 2)
+	assert.Equal(t, "web-1", result.Series[0].Labels["host"])
+	assert.Equal(t, "node_load1", result.Series[0].Metric)
+	require.Len(t, result.Series[0].Points, 5)
+	assert.InDelta(t, 1.5, result.Series[0].Points[0].Value, 1e-9)
+
+	assert.Empty(t, evaluate(t, store, "missing_metric", time.Minute).Series)
+}
+
+func TestEvaluate_UsesRollups(t *testing.T) {
+	clk := clock.NewFake(epoch.Add(48 * time.Hour))
+	store := metrics.NewSeriesStore(metrics.SeriesStoreConfig{Retention: 72 * time.Hour, Clock: clk})
+	var samples []metrics.Sample
+	for ts := epoch; ts.Before(clk.Now()); ts = ts.Add(time.Minute) {
+		samples = append(samples, metrics.Sample{Timestamp: ts, Value: 50})
+	}
+	require.NoError(t, store.Append("cpu_usage_percent", nil, samples))
+	config := metrics.DefaultRollupConfig()
+	config.Clock = clk
+	rollups, err := metrics.NewRollups(store, config)
+	require.NoError(t, err)
+	rollups.Compute()
+
+	q, err := Parse("avg(cpu.usage_percent)")
+	require.NoError(t, err)
+	result := Evaluate(q, []Source{FromRollups(rollups)}, Range{Start: epoch, End: clk.Now(), Step: 6 * time.Hour})
+	assert.Equal(t, "1h0m0s", result.Resolution)
+	require.Len(t, result.Series, 1)
+	assert.Len(t, result.Series[0].Points, 8)
+	assert.Equal(t, 50.0, result.Series[0].Points[0].Value)
+}
+
+func TestRange_Validate(t *testing.T) {
+	assert.NoError(t, Range{Start: epoch, End: epoch.Add(6 * time.Hour), Step: 5 * time.Minute}.Validate())
+	assert.Error(t, Range{Start: epoch, End: epoch, Step: time.Minute}.Validate())
+	assert.Error(t, Range{Start: epoch, End: epoch.Add(time.Hour), Step: 0}.Validate())
+	assert.Error(t, Range{Start: epoch, End: epoch.Add(30 * 24 * time.Hour), Step: time.Second}.Validate())
+}
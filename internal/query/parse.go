@@ -0,0 +1,206 @@
+// File: internal/query/parse.go
+// Brief: Parser for metric history queries
+// Detailed: Parses queries such as avg by (host) (cpu_usage_percent{env="prod"}) into a metric selector, label matchers, an optional aggregation function across series and the labels to group by, in a PromQL-like syntax.
+// Author: drama.lin@aver.com
+// Date: 2026-10-14
+
+package query
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"argus/internal/models"
+)
+
+// Func aggregates the series selected by a query
+type Func string
+
+// Aggregation functions
+const (
+	FuncNone  Func = ""      // Every series on its own, averaged per step
+	FuncAvg   Func = "avg"   // Average of every sample
+	FuncMin   Func = "min"   // Lowest sample
+	FuncMax   Func = "max"   // Highest sample
+	FuncSum   Func = "sum"   // Sum of the series' averages
+	FuncCount Func = "count" // Number of series with samples
+)
+
+// Funcs lists the aggregation functions
+var Funcs = []Func{FuncAvg, FuncMin, FuncMax, FuncSum, FuncCount}
+
+// Query selects series by metric name and labels and optionally aggregates
+// them, per group of By labels
+type Query struct {
+	Func     Func
+	Metric   string
+	Matchers []models.Matcher
+	By       []string
+}
+
+// String renders the query in its canonical form
+func (q *Query) String() string {
+	selector := q.Metric
+	if len(q.Matchers) > 0 {
+		parts := make([]string, len(q.Matchers))
+		for i, m := range q.Matchers {
+			parts[i] = m.String()
+		}
+		selector += "{" + strings.Join(parts, ",") + "}"
+	}
+	if q.Func == FuncNone {
+		return selector
+	}
+	if len(q.By) > 0 {
+		return fmt.Sprintf("%s by (%s) (%s)", q.Func, strings.Join(q.By, ", "), selector)
+	}
+	return fmt.Sprintf("%s(%s)", q.Func, selector)
+}
+
+// Parse parses a query. Accepted forms are a selector such as
+// cpu_usage_percent{mountpoint="/"}, an aggregation such as
+// max(cpu_usage_percent), and a grouped aggregation written either
+// avg by (host) (cpu_usage_percent) or avg(cpu_usage_percent) by (host). Dots in
+// metric names are read as underscores, so cpu.usage_percent also works.
+func Parse(s string) (*Query, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return nil, fmt.Errorf("empty query")
+	}
+	q := &Query{}
+
+	name, rest := leadingIdent(s)
+	if fn := Func(strings.ToLower(name)); slices.Contains(Funcs, fn) && startsGroup(rest) {
+		q.Func = fn
+		rest = strings.TrimSpace(rest)
+		if by, after, ok, err := parseBy(rest); err != nil {
+			return nil, err
+		} else if ok {
+			q.By, rest = by, strings.TrimSpace(after)
+		}
+		inner, after, err := parenthesized(rest)
+		if err != nil {
+			return nil, err
+		}
+		if err := q.parseSelector(inner); err != nil {
+			return nil, err
+		}
+		rest = strings.TrimSpace(after)
+		if q.By == nil {
+			if by, after, ok, err := parseBy(rest); err != nil {
+				return nil, err
+			} else if ok {
+				q.By, rest = by, strings.TrimSpace(after)
+			}
+		}
+		if rest != "" {
+			return nil, fmt.Errorf("unexpected %q after the query", rest)
+		}
+		return q, nil
+	}
+
+	if err := q.parseSelector(s); err != nil {
+		return nil, err
+	}
+	return q, nil
+}
+
+// startsGroup reports whether rest continues a function call or its by clause
+func startsGroup(rest string) bool {
+	rest = strings.TrimSpace(rest)
+	if strings.HasPrefix(rest, "(") {
+		return true
+	}
+	word, _ := leadingIdent(rest)
+	return strings.EqualFold(word, "by")
+}
+
+// parseSelector parses metric{matchers}
+func (q *Query) parseSelector(s string) error {
+	s = strings.TrimSpace(s)
+	name, rest := leadingIdent(s)
+	if name == "" {
+		return fmt.Errorf("invalid selector %q: expected a metric name", s)
+	}
+	q.Metric = strings.ReplaceAll(name, ".", "_")
+	rest = strings.TrimSpace(rest)
+	if rest == "" {
+		return nil
+	}
+	if !strings.HasPrefix(rest, "{") || !strings.HasSuffix(rest, "}") {
+		return fmt.Errorf("invalid selector %q: expected {label=\"value\",...} after the metric name", s)
+	}
+	matchers, err := models.ParseMatchers(rest)
+	if err != nil {
+		return fmt.Errorf("invalid selector %q: %w", s, err)
+	}
+	q.Matchers = matchers
+	return nil
+}
+
+// parseBy parses a leading by (label, ...) clause
+func parseBy(s string) ([]string, string, bool, error) {
+	word, rest := leadingIdent(s)
+	if !strings.EqualFold(word, "by") {
+		return nil, s, false, nil
+	}
+	inner, after, err := parenthesized(strings.TrimSpace(rest))
+	if err != nil {
+		return nil, "", false, fmt.Errorf("invalid by clause: %w", err)
+	}
+	by := []string{}
+	for _, label := range strings.Split(inner, ",") {
+		label = strings.TrimSpace(label)
+		if label == "" {
+			continue
+		}
+		if ident, tail := leadingIdent(label); ident != label || tail != "" {
+			return nil, "", false, fmt.Errorf("invalid by clause: %q is not a label name", label)
+		}
+		by = append(by, label)
+	}
+	if len(by) == 0 {
+		return nil, "", false, fmt.Errorf("invalid by clause: expected at least one label")
+	}
+	return by, after, true, nil
+}
+
+// parenthesized splits "(inner) rest", honouring nested parentheses and quoted values
+func parenthesized(s string) (string, string, error) {
+	if !strings.HasPrefix(s, "(") {
+		return "", "", fmt.Errorf("expected '(' at %q", s)
+	}
+	depth, quoted := 0, false
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case quoted && c == '\\':
+			i++
+		case c == '"':
+			quoted = !quoted
+		case quoted:
+		case c == '(':
+			depth++
+		case c == ')':
+			depth--
+			if depth == 0 {
+				return s[1:i], s[i+1:], nil
+			}
+		}
+	}
+	return "", "", fmt.Errorf("unbalanced parentheses in %q", s)
+}
+
+// leadingIdent splits a leading metric or label name off s
+func leadingIdent(s string) (string, string) {
+	i := 0
+	for i < len(s) {
+		c := s[i]
+		if c == '_' || c == '.' || c == ':' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || i > 0 && c >= '0' && c <= '9' {
+			i++
+			continue
+		}
+		break
+	}
+	return s[:i], s[i:]
+}
@@ -0,0 +1,57 @@
+package query
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		in        string
+		fn        Func
+		metric    string
+		matchers  int
+		by        []string
+		canonical string
+	}{
+		{"cpu_usage_percent", FuncNone, "cpu_usage_percent", 0, nil, "cpu_usage_percent"},
+		{`disk_used_percent{mountpoint="/"}`, FuncNone, "disk_used_percent", 1, nil, `disk_used_percent{mountpoint="/"}`},
+		{"max(cpu_usage_percent)", FuncMax, "cpu_usage_percent", 0, nil, "max(cpu_usage_percent)"},
+		{"avg by (host) (cpu.usage_percent)", FuncAvg, "cpu_usage_percent", 0, []string{"host"}, "avg by (host) (cpu_usage_percent)"},
+		{`AVG(node_load1{env="prod", role=~"web|db"}) by (host, role)`, FuncAvg, "node_load1", 2, []string{"host", "role"}, `avg by (host, role) (node_load1{env="prod",role=~"web|db"})`},
+		{`count by(job)(up{job!="argus"})`, FuncCount, "up", 1, []string{"job"}, `count by (job) (up{job!="argus"})`},
+		// A metric named like a function is still a selector
+		{`sum{a="b"}`, FuncNone, "sum", 1, nil, `sum{a="b"}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			q, err := Parse(tt.in)
+			require.NoError(t, err)
+			assert.Equal(t, tt.fn, q.Func)
+			assert.Equal(t, tt.metric, q.Metric)
+			assert.Len(t, q.Matchers, tt.matchers)
+			assert.Equal(t, tt.by, q.By)
+			assert.Equal(t, tt.canonical, q.String())
+		})
+	}
+}
+
+func TestParse_Errors(t *testing.T) {
+	for _, in := range []string{
+		"",
+		"avg(",
+		"avg(cpu) by",
+		"avg(cpu) by ()",
+		"avg(cpu) by (host-name)",
+		"avg by (host)",
+		"avg(cpu) extra",
+		`cpu{mountpoint="/"`,
+		`cpu mountpoint`,
+		`{mountpoint="/"}`,
+	} {
+		_, err := Parse(in)
+		assert.Error(t, err, in)
+	}
+}
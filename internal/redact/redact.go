@@ -0,0 +1,85 @@
+// File: internal/redact/redact.go
+// Brief: Regex-based redaction of outgoing content
+// Detailed: Provides Redactor, which masks secrets in notification bodies and task output before they leave the system.
+// Author: drama.lin@aver.com
+// Date: 2026-10-14
+
+// Package redact masks secrets in text using configurable regular expression rules.
+package redact
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// DefaultReplacement is used by rules that do not set a replacement.
+const DefaultReplacement = "[REDACTED]"
+
+// Rule describes one redaction pattern. Replacement may reference capture
+// groups (e.g. "${1}[REDACTED]") to keep a key name while masking its value.
+type Rule struct {
+	Name        string `yaml:"name" json:"name"`
+	Pattern     string `yaml:"pattern" json:"pattern"`
+	Replacement string `yaml:"replacement" json:"replacement,omitempty"`
+}
+
+// DefaultRules catch the secrets most commonly found in command output and logs.
+var DefaultRules = []Rule{
+	{Name: "key-value-secret", Pattern: `(?i)\b((?:password|passwd|pwd|secret|token|api[_-]?key|access[_-]?key)\s*[:=]\s*)("[^"]*"|'[^']*'|\S+)`, Replacement: "${1}" + DefaultReplacement},
+	{Name: "bearer-token", Pattern: `(?i)\b(bearer\s+)[A-Za-z0-9\-._~+/]+=*`, Replacement: "${1}" + DefaultReplacement},
+	{Name: "url-credentials", Pattern: `(\w+://[^:/\s]+:)[^@\s]+@`, Replacement: "${1}" + DefaultReplacement + "@"},
+	{Name: "aws-access-key", Pattern: `\b(?:AKIA|ASIA)[0-9A-Z]{16}\b`},
+	{Name: "private-key", Pattern: `(?s)-----BEGIN [A-Z ]*PRIVATE KEY-----.*?-----END [A-Z ]*PRIVATE KEY-----`},
+}
+
+type compiledRule struct {
+	name        string
+	re          *regexp.Regexp
+	replacement string
+}
+
+// Redactor applies an ordered list of compiled rules. A nil Redactor is valid
+// and returns its input unchanged.
+type Redactor struct {
+	rules []compiledRule
+}
+
+// New compiles rules into a Redactor, reporting the first invalid pattern.
+func New(rules []Rule) (*Redactor, error) {
+	r := &Redactor{rules: make([]compiledRule, 0, len(rules))}
+	for i, rule := range rules {
+		re, err := regexp.Compile(rule.Pattern)
+		if err != nil {
+			name := rule.Name
+			if name == "" {
+				name = fmt.Sprintf("#%d", i)
+			}
+			return nil, fmt.Errorf("invalid redaction rule %s: %w", name, err)
+		}
+		replacement := rule.Replacement
+		if replacement == "" {
+			replacement = DefaultReplacement
+		}
+		r.rules = append(r.rules, compiledRule{name: rule.Name, re: re, replacement: replacement})
+	}
+	return r, nil
+}
+
+// Redact returns s with every rule applied in order.
+func (r *Redactor) Redact(s string) string {
+	if r == nil || s == "" {
+		return s
+	}
+	for _, rule := range r.rules {
+		s = rule.re.ReplaceAllString(s, rule.replacement)
+	}
+	return s
+}
+
+// Len returns the number of rules.
+func (r *Redactor) Len() int {
+	if r == nil {
+		return 0
+	}
+	return len(r.rules)
+}
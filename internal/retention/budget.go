@@ -0,0 +1,262 @@
+// File: internal/retention/budget.go
+// Brief: Disk usage budget for Argus's own storage
+// Detailed: Periodically measures the storage directories (alerts, tasks, base path), purges the oldest data of the lowest-value types first through the retention engine's stores while over budget, and reports when usage reaches the warning level so Argus never fills the disk it is watching.
+// Author: drama.lin@aver.com
+// Date: 2026-10-14
+
+package retention
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"log/slog"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"argus/internal/clock"
+	"argus/internal/utils"
+)
+
+// maxPruneRounds limits the purges of one data type per check
+const maxPruneRounds = 8
+
+// BudgetConfig holds configuration for the storage budget
+type BudgetConfig struct {
+	MaxBytes    int64         // Size limit of the directories
+	WarnPercent float64       // Usage percentage at which the warning fires
+	Interval    time.Duration // How often usage is measured
+	Dirs        []string      // Storage directories; nested ones are counted once
+	PruneOrder  []Kind        // Data types purged when over budget, lowest value first
+	Clock       clock.Clock   // Time source (nil uses the real clock)
+}
+
+// DefaultBudgetConfig returns default configuration for the storage budget
+func DefaultBudgetConfig() BudgetConfig {
+	return BudgetConfig{
+		WarnPercent: 80,
+		Interval:    time.Minute,
+		PruneOrder:  []Kind{KindExecutions, KindAuditLogs, KindAlertEvents},
+	}
+}
+
+// DirUsage is the size of one storage directory
+type DirUsage struct {
+	Path  string `json:"path"`
+	Bytes int64  `json:"bytes"`
+}
+
+// BudgetStatus reports storage usage against the budget
+type BudgetStatus struct {
+	MaxBytes    int64        `json:"max_bytes"`
+	UsedBytes   int64        `json:"used_bytes"`
+	Percent     float64      `json:"percent"`
+	WarnPercent float64      `json:"warn_percent"`
+	Warning     bool         `json:"warning"` // Usage is at or above the warning level
+	Directories []DirUsage   `json:"directories"`
+	LastCheck   *time.Time   `json:"last_check,omitempty"`
+	Pruned      map[Kind]int `json:"pruned,omitempty"` // Items purged by the last check
+	Error       string       `json:"error,omitempty"`
+}
+
+// Budget keeps the storage directories within a size limit
+type Budget struct {
+	config BudgetConfig
+	clock  clock.Clock
+	engine *Engine
+	warn   func(BudgetStatus)
+
+	mu     sync.Mutex
+	status BudgetStatus
+}
+
+// NewBudget creates a budget purging through the stores registered with engine
+func NewBudget(config BudgetConfig, engine *Engine) *Budget {
+	defaults := DefaultBudgetConfig()
+	if config.WarnPercent <= 0 {
+		config.WarnPercent = defaults.WarnPercent
+	}
+	if config.Interval <= 0 {
+		config.Interval = defaults.Interval
+	}
+	if config.PruneOrder == nil {
+		config.PruneOrder = defaults.PruneOrder
+	}
+	config.Dirs = utils.OutermostDirs(config.Dirs)
+	return &Budget{
+		config: config,
+		clock:  clock.OrReal(config.Clock),
+		engine: engine,
+		status: BudgetStatus{MaxBytes: config.MaxBytes, WarnPercent: config.WarnPercent, Directories: []DirUsage{}},
+	}
+}
+
+// SetWarnFunc sets the function called after every check while usage is at or
+// above the warning level, and once when it drops below again
+func (b *Budget) SetWarnFunc(fn func(BudgetStatus)) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.warn = fn
+}
+
+// dirSize returns the total size of the regular files under dir
+func dirSize(dir string) (int64, error) {
+	var size int64
+	err := filepath.WalkDir(dir, func(_ string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil // Removed while walking, or not created yet
+			}
+			return err
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
+			return err
+		}
+		size += info.Size()
+		return nil
+	})
+	return size, err
+}
+
+// measure returns the size of every storage directory and their total
+func (b *Budget) measure() ([]DirUsage, int64, error) {
+	dirs := make([]DirUsage, 0, len(b.config.Dirs))
+	var total int64
+	for _, dir := range b.config.Dirs {
+		size, err := dirSize(dir)
+		if err != nil {
+			return dirs, total, err
+		}
+		dirs = append(dirs, DirUsage{Path: dir, Bytes: size})
+		total += size
+	}
+	return dirs, total, nil
+}
+
+// prune purges the oldest data of kind until used fits the budget or the type
+// has nothing left to purge, and returns the new total and the items purged
+func (b *Budget) prune(kind Kind, used int64) (int64, int, error) {
+	target := b.engine.target(kind)
+	if target == nil || target.Usage == nil || target.Purge == nil {
+		return used, 0, nil
+	}
+	purged := 0
+	for round := 0; round < maxPruneRounds && used > b.config.MaxBytes; round++ {
+		usage, err := target.Usage()
+		if err != nil {
+			return used, purged, err
+		}
+		if usage.Items == 0 || usage.Oldest == nil || usage.Bytes <= 0 {
+			break
+		}
+		// Assuming data accumulates evenly over time, purge the oldest share
+		// of it matching the excess; the last round purges everything
+		now := b.clock.Now().UTC()
+		share := float64(used-b.config.MaxBytes) / float64(usage.Bytes)
+		if share < 0.1 {
+			share = 0.1
+		}
+		before := now
+		if share < 1 && round < maxPruneRounds-1 {
+			before = usage.Oldest.Add(time.Duration(share * float64(now.Sub(*usage.Oldest))))
+		}
+		n, err := target.Purge(before)
+		purged += n
+		if err != nil {
+			return used, purged, err
+		}
+		if _, used, err = b.measure(); err != nil {
+			return used, purged, err
+		}
+		if !before.Before(now) {
+			break
+		}
+	}
+	return used, purged, nil
+}
+
+// Check measures the storage directories, purges data while over budget and
+// reports the resulting usage
+func (b *Budget) Check() BudgetStatus {
+	status := BudgetStatus{MaxBytes: b.config.MaxBytes, WarnPercent: b.config.WarnPercent}
+	dirs, used, err := b.measure()
+	if err == nil && used > b.config.MaxBytes {
+		slog.Warn("Storage over budget, purging oldest data", "used_bytes", used, "max_bytes", b.config.MaxBytes)
+		status.Pruned = make(map[Kind]int)
+		for _, kind := range b.config.PruneOrder {
+			if used <= b.config.MaxBytes {
+				break
+			}
+			var n int
+			used, n, err = b.prune(kind, used)
+			if n > 0 {
+				status.Pruned[kind] = n
+				slog.Info("Storage budget purge completed", "kind", kind, "purged", n, "used_bytes", used)
+			}
+			if err != nil {
+				break
+			}
+		}
+		if err == nil {
+			dirs, used, err = b.measure()
+		}
+	}
+	if err != nil {
+		slog.Error("Storage budget check failed", "error", err)
+		status.Error = err.Error()
+	}
+
+	now := b.clock.Now().UTC()
+	status.Directories = dirs
+	status.UsedBytes = used
+	if b.config.MaxBytes > 0 {
+		status.Percent = float64(used) * 100 / float64(b.config.MaxBytes)
+	}
+	status.Warning = status.Percent >= b.config.WarnPercent
+	status.LastCheck = &now
+
+	b.mu.Lock()
+	wasWarning := b.status.Warning
+	b.status = status
+	warn := b.warn
+	b.mu.Unlock()
+
+	if status.Warning && !wasWarning {
+		slog.Warn("Storage budget warning level reached", "used_bytes", used, "max_bytes", b.config.MaxBytes, "percent", status.Percent)
+	}
+	if warn != nil && (status.Warning || wasWarning) {
+		warn(status)
+	}
+	return status
+}
+
+// Start checks now and then every interval until ctx is cancelled
+func (b *Budget) Start(ctx context.Context) {
+	ticker := b.clock.NewTicker(b.config.Interval)
+	go func() {
+		defer ticker.Stop()
+		for {
+			b.Check()
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C():
+			}
+		}
+	}()
+}
+
+// Status returns the result of the last check
+func (b *Budget) Status() BudgetStatus {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.status
+}
@@ -0,0 +1,135 @@
+package retention
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"argus/internal/clock"
+)
+
+// fileTarget stores one 100 byte file per item, named by its age in hours
+type fileTarget struct {
+	dir   string
+	clock clock.Clock
+}
+
+func newFileTarget(t *testing.T, dir string, clk clock.Clock, ages ...int) *fileTarget {
+	t.Helper()
+	require.NoError(t, os.MkdirAll(dir, 0755))
+	for _, age := range ages {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, fmt.Sprintf("%03d", age)), make([]byte, 100), 0644))
+	}
+	return &fileTarget{dir: dir, clock: clk}
+}
+
+func (f *fileTarget) items(t *testing.T) []string {
+	t.Helper()
+	entries, err := os.ReadDir(f.dir)
+	require.NoError(t, err)
+	names := make([]string, 0, len(entries))
+	for _, e := range entries {
+		names = append(names, e.Name())
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (f *fileTarget) target() Target {
+	stamp := func(name string) time.Time {
+		var age int
+		fmt.Sscanf(name, "%d", &age)
+		return f.clock.Now().Add(-time.Duration(age) * time.Hour)
+	}
+	return Target{
+		Usage: func() (Usage, error) {
+			var u Usage
+			entries, err := os.ReadDir(f.dir)
+			if err != nil {
+				return u, err
+			}
+			for _, e := range entries {
+				u.Observe(stamp(e.Name()))
+				u.Bytes += 100
+			}
+			return u, nil
+		},
+		Purge: func(before time.Time) (int, error) {
+			entries, err := os.ReadDir(f.dir)
+			if err != nil {
+				return 0, err
+			}
+			purged := 0
+			for _, e := range entries {
+				if stamp(e.Name()).Before(before) {
+					if err := os.Remove(filepath.Join(f.dir, e.Name())); err != nil {
+						return purged, err
+					}
+					purged++
+				}
+			}
+			return purged, nil
+		},
+	}
+}
+
+func TestBudget_PrunesLowValueDataFirst(t *testing.T) {
+	base := t.TempDir()
+	clk := clock.NewFake(epoch)
+	engine := NewEngine(Config{Clock: clk})
+	executions := newFileTarget(t, filepath.Join(base, "tasks"), clk, 1, 2, 3, 4, 5, 6, 7, 8)
+	events := newFileTarget(t, filepath.Join(base, "alerts"), clk, 1, 2, 3, 4)
+	engine.Register(KindExecutions, executions.target())
+	engine.Register(KindAlertEvents, events.target())
+
+	budget := NewBudget(BudgetConfig{MaxBytes: 1000, Dirs: []string{base}, Clock: clk}, engine)
+	var warnings []BudgetStatus
+	budget.SetWarnFunc(func(s BudgetStatus) { warnings = append(warnings, s) })
+
+	status := budget.Check()
+	assert.Empty(t, status.Error)
+	assert.LessOrEqual(t, status.UsedBytes, int64(1000))
+	assert.Greater(t, status.Pruned[KindExecutions], 0)
+	assert.Zero(t, status.Pruned[KindAlertEvents], "alert history is kept while executions can go")
+	assert.Len(t, events.items(t), 4)
+	assert.Equal(t, "001", executions.items(t)[0], "the newest executions are kept")
+	assert.True(t, status.Warning)
+	require.Len(t, warnings, 1)
+	require.NotNil(t, budget.Status().LastCheck)
+
+	// Once usage falls below the warning level the warning is reported cleared once
+	require.NoError(t, os.RemoveAll(executions.dir))
+	status = budget.Check()
+	assert.Equal(t, int64(400), status.UsedBytes)
+	assert.Equal(t, 40.0, status.Percent)
+	assert.False(t, status.Warning)
+	require.Len(t, warnings, 2)
+	assert.False(t, warnings[1].Warning)
+
+	budget.Check()
+	assert.Len(t, warnings, 2)
+}
+
+func TestBudget_PurgesEverythingWhenNeeded(t *testing.T) {
+	base := t.TempDir()
+	clk := clock.NewFake(epoch)
+	engine := NewEngine(Config{Clock: clk})
+	executions := newFileTarget(t, filepath.Join(base, "tasks"), clk, 1, 2)
+	events := newFileTarget(t, filepath.Join(base, "alerts"), clk, 1, 2, 3)
+	engine.Register(KindExecutions, executions.target())
+	engine.Register(KindAlertEvents, events.target())
+	require.NoError(t, os.WriteFile(filepath.Join(base, "alerts.json"), make([]byte, 150), 0644)) // Not purgeable
+
+	status := NewBudget(BudgetConfig{MaxBytes: 200, Dirs: []string{base}, Clock: clk}, engine).Check()
+	assert.Empty(t, executions.items(t))
+	assert.Empty(t, events.items(t))
+	assert.Equal(t, 2, status.Pruned[KindExecutions])
+	assert.Equal(t, 3, status.Pruned[KindAlertEvents])
+	assert.Equal(t, int64(150), status.UsedBytes)
+}
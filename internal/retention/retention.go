@@ -0,0 +1,275 @@
+// File: internal/retention/retention.go
+// Brief: Central data retention policies and purge job
+// Detailed: Keeps a maximum age per data type (metrics history, alert events, task executions, notifications, audit logs) and periodically purges older data from the stores registered for each type. Reports current usage, the last purge and the next one for the /api/retention endpoint.
+// Author: drama.lin@aver.com
+// Date: 2026-10-14
+
+package retention
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"argus/internal/clock"
+)
+
+// Kind is a type of stored data with its own retention policy
+type Kind string
+
+// Data types with retention policies
+const (
+	KindMetricsHistory Kind = "metrics_history" // Recorded host metrics
+	KindAlertEvents    Kind = "alert_events"    // Alert state change history
+	KindExecutions     Kind = "executions"      // Task execution records
+	KindNotifications  Kind = "notifications"   // In-app notifications
+	KindAuditLogs      Kind = "audit_logs"      // Audit trail of API changes
+)
+
+// Kinds lists every data type, in reporting order
+var Kinds = []Kind{KindMetricsHistory, KindAlertEvents, KindExecutions, KindNotifications, KindAuditLogs}
+
+// ParseKind returns the data type named s
+func ParseKind(s string) (Kind, error) {
+	for _, kind := range Kinds {
+		if string(kind) == s {
+			return kind, nil
+		}
+	}
+	return "", fmt.Errorf("unknown data type %q: must be one of %v", s, Kinds)
+}
+
+// Usage is how much data of one type is stored
+type Usage struct {
+	Items  int        `json:"items"`
+	Bytes  int64      `json:"bytes,omitempty"`  // On-disk size; unset for in-memory data
+	Oldest *time.Time `json:"oldest,omitempty"` // Timestamp of the oldest item
+}
+
+// Observe counts an item stored at t
+func (u *Usage) Observe(t time.Time) {
+	u.Items++
+	if u.Oldest == nil || t.Before(*u.Oldest) {
+		oldest := t
+		u.Oldest = &oldest
+	}
+}
+
+// Target is the store holding the data of one type
+type Target struct {
+	Usage func() (Usage, error)               // Reports the stored data
+	Purge func(before time.Time) (int, error) // Removes data older than before, returning the items removed
+}
+
+// Config holds configuration for the retention engine
+type Config struct {
+	Interval time.Duration // How often the purge job runs
+	Clock    clock.Clock   // Time source (nil uses the real clock)
+}
+
+// DefaultConfig returns default configuration for the retention engine
+func DefaultConfig() Config {
+	return Config{Interval: time.Hour}
+}
+
+// Status reports the policy and state of one data type
+type Status struct {
+	Kind       Kind       `json:"kind"`
+	MaxAge     string     `json:"max_age,omitempty"` // Unset when the data is kept forever
+	Available  bool       `json:"available"`         // Whether Argus stores this data type
+	Usage      *Usage     `json:"usage,omitempty"`
+	UsageError string     `json:"usage_error,omitempty"`
+	LastPurge  *time.Time `json:"last_purge,omitempty"`
+	LastPurged int        `json:"last_purged"` // Items removed by the last purge
+	LastError  string     `json:"last_error,omitempty"`
+	NextPurge  *time.Time `json:"next_purge,omitempty"`
+}
+
+// Result is the outcome of purging one data type
+type Result struct {
+	Kind   Kind
+	Before time.Time
+	Purged int
+	Err    error
+}
+
+type state struct {
+	maxAge     time.Duration
+	target     *Target
+	lastPurge  time.Time
+	lastPurged int
+	lastError  string
+}
+
+// Engine enforces the retention policies
+type Engine struct {
+	config Config
+	clock  clock.Clock
+
+	mu        sync.Mutex
+	kinds     map[Kind]*state
+	running   bool
+	nextPurge time.Time
+}
+
+// NewEngine creates an engine without policies or targets
+func NewEngine(config Config) *Engine {
+	if config.Interval <= 0 {
+		config.Interval = DefaultConfig().Interval
+	}
+	e := &Engine{
+		config: config,
+		clock:  clock.OrReal(config.Clock),
+		kinds:  make(map[Kind]*state, len(Kinds)),
+	}
+	for _, kind := range Kinds {
+		e.kinds[kind] = &state{}
+	}
+	return e
+}
+
+// SetPolicy sets the maximum age of the data of kind; zero keeps it forever
+func (e *Engine) SetPolicy(kind Kind, maxAge time.Duration) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.kinds[kind].maxAge = maxAge
+}
+
+// Register sets the store holding the data of kind
+func (e *Engine) Register(kind Kind, target Target) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.kinds[kind].target = &target
+}
+
+// target returns the store registered for kind, or nil
+func (e *Engine) target(kind Kind) *Target {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.kinds[kind].target
+}
+
+// Interval returns how often the purge job runs
+func (e *Engine) Interval() time.Duration {
+	return e.config.Interval
+}
+
+// Running reports whether the purge job has been started
+func (e *Engine) Running() bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.running
+}
+
+// RunOnce purges every data type with a policy and a registered store
+func (e *Engine) RunOnce() []Result {
+	now := e.clock.Now().UTC()
+	type job struct {
+		kind   Kind
+		before time.Time
+		target *Target
+	}
+	var jobs []job
+	e.mu.Lock()
+	for _, kind := range Kinds {
+		st := e.kinds[kind]
+		if st.maxAge > 0 && st.target != nil && st.target.Purge != nil {
+			jobs = append(jobs, job{kind: kind, before: now.Add(-st.maxAge), target: st.target})
+		}
+	}
+	e.mu.Unlock()
+
+	// Purges run without the lock so status requests are not held up by disk I/O
+	results := make([]Result, 0, len(jobs))
+	for _, j := range jobs {
+		purged, err := j.target.Purge(j.before)
+		results = append(results, Result{Kind: j.kind, Before: j.before, Purged: purged, Err: err})
+		if err != nil {
+			slog.Error("Retention purge failed", "kind", j.kind, "before", j.before, "error", err)
+		} else if purged > 0 {
+			slog.Info("Retention purge completed", "kind", j.kind, "before", j.before, "purged", purged)
+		}
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	for _, r := range results {
+		st := e.kinds[r.Kind]
+		st.lastPurge = now
+		st.lastPurged = r.Purged
+		st.lastError = ""
+		if r.Err != nil {
+			st.lastError = r.Err.Error()
+		}
+	}
+	return results
+}
+
+// Start runs the purge job now and then every interval until ctx is cancelled
+func (e *Engine) Start(ctx context.Context) {
+	e.mu.Lock()
+	e.running = true
+	e.mu.Unlock()
+
+	ticker := e.clock.NewTicker(e.config.Interval)
+	go func() {
+		defer ticker.Stop()
+		for {
+			e.RunOnce()
+			e.mu.Lock()
+			e.nextPurge = e.clock.Now().UTC().Add(e.config.Interval)
+			e.mu.Unlock()
+			select {
+			case <-ctx.Done():
+				e.mu.Lock()
+				e.running = false
+				e.mu.Unlock()
+				return
+			case <-ticker.C():
+			}
+		}
+	}()
+}
+
+// Status reports every data type's policy, usage and purge state
+func (e *Engine) Status() []Status {
+	type entry struct {
+		status Status
+		target *Target
+	}
+	entries := make([]entry, 0, len(Kinds))
+	e.mu.Lock()
+	for _, kind := range Kinds {
+		st := e.kinds[kind]
+		s := Status{Kind: kind, Available: st.target != nil, LastPurged: st.lastPurged, LastError: st.lastError}
+		if st.maxAge > 0 {
+			s.MaxAge = st.maxAge.String()
+			if e.running && !e.nextPurge.IsZero() && st.target != nil {
+				next := e.nextPurge
+				s.NextPurge = &next
+			}
+		}
+		if !st.lastPurge.IsZero() {
+			last := st.lastPurge
+			s.LastPurge = &last
+		}
+		entries = append(entries, entry{status: s, target: st.target})
+	}
+	e.mu.Unlock()
+
+	result := make([]Status, 0, len(entries))
+	for _, en := range entries {
+		if en.target != nil && en.target.Usage != nil {
+			usage, err := en.target.Usage()
+			if err != nil {
+				en.status.UsageError = err.Error()
+			} else {
+				en.status.Usage = &usage
+			}
+		}
+		result = append(result, en.status)
+	}
+	return result
+}
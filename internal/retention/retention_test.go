@@ -0,0 +1,154 @@
+package retention
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"argus/internal/clock"
+)
+
+var epoch = time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// fakeTarget stores item timestamps in memory
+type fakeTarget struct {
+	items   []time.Time
+	err     error
+	befores []time.Time
+}
+
+func (f *fakeTarget) target() Target {
+	return Target{
+		Usage: func() (Usage, error) {
+			var u Usage
+			for _, t := range f.items {
+				u.Observe(t)
+			}
+			return u, nil
+		},
+		Purge: func(before time.Time) (int, error) {
+			f.befores = append(f.befores, before)
+			if f.err != nil {
+				return 0, f.err
+			}
+			kept := f.items[:0]
+			for _, t := range f.items {
+				if !t.Before(before) {
+					kept = append(kept, t)
+				}
+			}
+			purged := len(f.items) - len(kept)
+			f.items = kept
+			return purged, nil
+		},
+	}
+}
+
+func statusOf(t *testing.T, statuses []Status, kind Kind) Status {
+	t.Helper()
+	for _, s := range statuses {
+		if s.Kind == kind {
+			return s
+		}
+	}
+	require.Failf(t, "missing status", "kind %s", kind)
+	return Status{}
+}
+
+func TestParseKind(t *testing.T) {
+	kind, err := ParseKind("executions")
+	require.NoError(t, err)
+	assert.Equal(t, KindExecutions, kind)
+
+	_, err = ParseKind("logs")
+	assert.Error(t, err)
+}
+
+func TestEngine_RunOncePurgesOldData(t *testing.T) {
+	clk := clock.NewFake(epoch)
+	engine := NewEngine(Config{Interval: time.Hour, Clock: clk})
+
+	events := &fakeTarget{items: []time.Time{epoch.Add(-48 * time.Hour), epoch.Add(-time.Hour)}}
+	executions := &fakeTarget{items: []time.Time{epoch.Add(-48 * time.Hour)}}
+	engine.Register(KindAlertEvents, events.target())
+	engine.Register(KindExecutions, executions.target()) // No policy: kept forever
+	engine.SetPolicy(KindAlertEvents, 24*time.Hour)
+	engine.SetPolicy(KindNotifications, time.Hour) // No store: nothing to purge
+
+	results := engine.RunOnce()
+	require.Len(t, results, 1)
+	assert.Equal(t, KindAlertEvents, results[0].Kind)
+	assert.Equal(t, 1, results[0].Purged)
+	assert.Equal(t, epoch.Add(-24*time.Hour), results[0].Before)
+	assert.Len(t, events.items, 1)
+	assert.Empty(t, executions.befores)
+
+	statuses := engine.Status()
+	require.Len(t, statuses, len(Kinds))
+
+	st := statusOf(t, statuses, KindAlertEvents)
+	assert.Equal(t, "24h0m0s", st.MaxAge)
+	assert.True(t, st.Available)
+	require.NotNil(t, st.Usage)
+	assert.Equal(t, 1, st.Usage.Items)
+	require.NotNil(t, st.LastPurge)
+	assert.Equal(t, epoch, *st.LastPurge)
+	assert.Equal(t, 1, st.LastPurged)
+	assert.Nil(t, st.NextPurge, "not scheduled before Start")
+
+	st = statusOf(t, statuses, KindExecutions)
+	assert.Empty(t, st.MaxAge)
+	assert.Equal(t, 1, st.Usage.Items)
+	assert.Nil(t, st.LastPurge)
+
+	st = statusOf(t, statuses, KindAuditLogs)
+	assert.False(t, st.Available)
+	assert.Nil(t, st.Usage)
+}
+
+func TestEngine_RecordsPurgeErrors(t *testing.T) {
+	engine := NewEngine(Config{Clock: clock.NewFake(epoch)})
+	broken := &fakeTarget{err: errors.New("disk full")}
+	engine.Register(KindExecutions, broken.target())
+	engine.SetPolicy(KindExecutions, time.Hour)
+
+	results := engine.RunOnce()
+	require.Len(t, results, 1)
+	assert.Error(t, results[0].Err)
+	assert.Equal(t, "disk full", statusOf(t, engine.Status(), KindExecutions).LastError)
+
+	broken.err = nil
+	engine.RunOnce()
+	assert.Empty(t, statusOf(t, engine.Status(), KindExecutions).LastError)
+}
+
+func TestEngine_StartRunsEveryInterval(t *testing.T) {
+	clk := clock.NewFake(epoch)
+	engine := NewEngine(Config{Interval: time.Hour, Clock: clk})
+	events := &fakeTarget{}
+	engine.Register(KindAlertEvents, events.target())
+	engine.SetPolicy(KindAlertEvents, 24*time.Hour)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	engine.Start(ctx)
+	assert.True(t, engine.Running())
+
+	// The first purge runs immediately, then after every interval
+	require.Eventually(t, func() bool {
+		next := statusOf(t, engine.Status(), KindAlertEvents).NextPurge
+		return next != nil && next.Equal(epoch.Add(time.Hour))
+	}, time.Second, time.Millisecond)
+	clk.Advance(time.Hour)
+	require.Eventually(t, func() bool {
+		next := statusOf(t, engine.Status(), KindAlertEvents).NextPurge
+		return next != nil && next.Equal(epoch.Add(2*time.Hour))
+	}, time.Second, time.Millisecond)
+
+	cancel()
+	require.Eventually(t, func() bool { return !engine.Running() }, time.Second, time.Millisecond)
+}
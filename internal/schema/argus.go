@@ -0,0 +1,190 @@
+// File: internal/schema/argus.go
+// Brief: JSON Schemas of Argus alert and task definitions
+// Detailed: Generates the schemas of models.AlertConfig and models.TaskConfig and registers the rules their Validate methods enforce: enum values shared with the models package, required fields, the metric names allowed per metric type and the email recipient requirement.
+// Author: drama.lin@aver.com
+// Date: 2026-10-14
+
+package schema
+
+import (
+	"sort"
+
+	"argus/internal/models"
+)
+
+// Names of the published schemas
+const (
+	NameAlert = "alert"
+	NameTask  = "task"
+)
+
+// IDBase prefixes the $id of every published schema
+const IDBase = "/api/schemas/"
+
+// values converts a typed list such as models.Severities into enum values
+func values[T any](list []T) []interface{} {
+	result := make([]interface{}, len(list))
+	for i, v := range list {
+		result[i] = v
+	}
+	return result
+}
+
+// newArgusGenerator returns a generator with the rules of the models package
+func newArgusGenerator() *Generator {
+	g := NewGenerator()
+
+	g.Enum(models.MetricType(""), values(models.MetricTypes)...)
+	g.Enum(models.ComparisonOperator(""), values(models.Operators)...)
+	g.Enum(models.AlertSeverity(""), values(models.Severities)...)
+	g.Enum(models.NotificationType(""), values(models.NotificationTypes)...)
+	g.Enum(models.TaskType(""), values(models.TaskTypes)...)
+	// An empty unit means a plain number
+	g.Enum(models.Unit(""), append([]interface{}{""}, values(models.SupportedUnits())...)...)
+
+	g.Require(models.AlertConfig{}, "name", "severity", "threshold")
+	g.Require(models.ThresholdConfig{}, "metric_type", "metric_name", "operator")
+	g.Require(models.NotificationConfig{}, "type")
+	g.Require(models.TaskConfig{}, "name", "type", "schedule")
+
+	// ThresholdConfig.UnmarshalJSON also reads quantity strings, and
+	// MarshalJSON adds the rendered value
+	g.Property(models.ThresholdConfig{}, "value", Schema{
+		"description": "Number in the base unit of the metric, or a quantity such as \"1.5GB\" or \"90%\"",
+		"oneOf": []Schema{
+			{"type": "number"},
+			{"type": "string", "pattern": `^\s*[-+]?[0-9.]+([eE][-+]?[0-9]+)?\s*[%A-Za-z/]*\s*$`},
+		},
+	})
+	g.Property(models.ThresholdConfig{}, "display", Schema{
+		"type":        "string",
+		"readOnly":    true,
+		"description": "Value rendered in its unit, e.g. \"10 MB\"",
+	})
+
+	for _, end := range []string{"start", "end"} {
+		g.Property(models.ExecutionWindow{}, end, Schema{
+			"type":        "string",
+			"pattern":     `^([01]?[0-9]|2[0-3]):[0-5][0-9]$`,
+			"description": "Time of day as HH:MM in the schedule's timezone",
+		})
+	}
+	g.Require(models.ExecutionWindow{}, "start", "end")
+	// A task runs on one agent or on the agents matching a label selector
+	g.Rule(models.AgentTarget{}, Schema{
+		"oneOf": []Schema{{"required": []string{"host"}}, {"required": []string{"labels"}}},
+	})
+	g.Property(models.Schedule{}, "deferred_from", Schema{
+		"type":        "string",
+		"format":      "date-time",
+		"readOnly":    true,
+		"description": "When the pending run was originally due, if it was deferred",
+	})
+	g.Property(models.Schedule{}, "deferred_reason", Schema{
+		"type":        "string",
+		"readOnly":    true,
+		"description": "Why the pending run was deferred: outside the execution windows, or by the throttle while the host is busy",
+	})
+
+	metricTypes := make([]string, 0, len(models.MetricNames))
+	for metricType := range models.MetricNames {
+		metricTypes = append(metricTypes, string(metricType))
+	}
+	sort.Strings(metricTypes)
+	for _, metricType := range metricTypes {
+		g.Rule(models.ThresholdConfig{}, whenEquals("metric_type", metricType, Schema{
+			"metric_name": Schema{"enum": values(models.MetricNames[models.MetricType(metricType)])},
+		}))
+	}
+	g.Rule(models.ThresholdConfig{}, whenEquals("metric_type", string(models.MetricSeries), Schema{
+		"metric_name": Schema{"minLength": 1},
+	}))
+	// String metrics such as service active_state compare string_value
+	g.Rule(models.ThresholdConfig{}, Schema{
+		"anyOf": []Schema{
+			{"required": []string{"value"}},
+			{"required": []string{"string_value"}},
+		},
+	})
+
+	g.Rule(models.NotificationConfig{}, Schema{
+		"if": Schema{
+			"properties": Schema{"type": Schema{"const": models.NotificationEmail}},
+			"required":   []string{"type"},
+		},
+		"then": Schema{
+			"properties": Schema{"settings": Schema{
+				"properties": Schema{"recipient": Schema{"type": "string", "minLength": 1}},
+				"required":   []string{"recipient"},
+			}},
+			"required": []string{"settings"},
+		},
+	})
+	g.Rule(models.NotificationConfig{}, Schema{
+		"if": Schema{
+			"properties": Schema{"type": Schema{"const": models.NotificationWebhook}},
+			"required":   []string{"type"},
+		},
+		"then": Schema{
+			"properties": Schema{"settings": Schema{
+				"properties": Schema{
+					"url": Schema{"type": "string", "pattern": "^https?://"},
+					models.WebhookPayloadSetting: Schema{
+						"type":        []string{"object", "array"},
+						"description": "JSON body replacing the default payload; strings are templates over its fields, e.g. \"{{ .AlertName }}\"",
+					},
+				},
+				"required": []string{"url"},
+			}},
+			"required": []string{"settings"},
+		},
+	})
+
+	g.Rule(models.NotificationConfig{}, Schema{
+		"if": Schema{
+			"properties": Schema{"type": Schema{"const": models.NotificationJira}},
+			"required":   []string{"type"},
+		},
+		"then": Schema{
+			"properties": Schema{"settings": Schema{
+				"properties": Schema{
+					"project":    Schema{"type": "string", "minLength": 1},
+					"issue_type": Schema{"type": "string", "minLength": 1},
+					"labels": Schema{
+						"type":        "array",
+						"items":       Schema{"type": "string"},
+						"description": "Labels of the issue; templates over the webhook payload fields, e.g. \"team-{{ .Labels.team }}\"",
+					},
+				},
+			}},
+		},
+	})
+
+	g.Rule(models.Schedule{}, Schema{
+		"anyOf": []Schema{
+			{"properties": Schema{"cron_expression": Schema{"minLength": 1}}, "required": []string{"cron_expression"}},
+			{"properties": Schema{"one_time": Schema{"const": true}}, "required": []string{"one_time"}},
+		},
+	})
+	return g
+}
+
+// whenEquals applies properties when property equals value
+func whenEquals(property, value string, properties Schema) Schema {
+	return Schema{
+		"if": Schema{
+			"properties": Schema{property: Schema{"const": value}},
+			"required":   []string{property},
+		},
+		"then": Schema{"properties": properties},
+	}
+}
+
+// All returns every published schema by name
+func All() map[string]Schema {
+	g := newArgusGenerator()
+	return map[string]Schema{
+		NameAlert: g.Document(models.AlertConfig{}, IDBase+NameAlert, "Argus alert configuration"),
+		NameTask:  g.Document(models.TaskConfig{}, IDBase+NameTask, "Argus task configuration"),
+	}
+}
@@ -0,0 +1,184 @@
+// File: internal/schema/schema.go
+// Brief: JSON Schema generation from Go structs
+// Detailed: Builds JSON Schema (draft 2020-12) documents by reflecting over JSON-tagged Go types, following encoding/json field naming, omitempty and embedded-struct promotion. Rules reflection cannot see, such as enum values, required fields and conditional constraints, are registered per type.
+// Author: drama.lin@aver.com
+// Date: 2026-10-14
+
+package schema
+
+import (
+	"reflect"
+	"strings"
+	"time"
+)
+
+// Draft is the JSON Schema dialect of generated documents
+const Draft = "https://json-schema.org/draft/2020-12/schema"
+
+// Schema is a JSON Schema document or subschema
+type Schema map[string]interface{}
+
+// Generator builds schemas for Go types
+type Generator struct {
+	enums      map[reflect.Type][]interface{}
+	required   map[reflect.Type][]string
+	properties map[reflect.Type]map[string]Schema
+	rules      map[reflect.Type][]Schema
+}
+
+// NewGenerator creates a generator without type-specific rules
+func NewGenerator() *Generator {
+	return &Generator{
+		enums:      make(map[reflect.Type][]interface{}),
+		required:   make(map[reflect.Type][]string),
+		properties: make(map[reflect.Type]map[string]Schema),
+		rules:      make(map[reflect.Type][]Schema),
+	}
+}
+
+// typeOf returns the type of sample, dereferencing pointers
+func typeOf(sample interface{}) reflect.Type {
+	t := reflect.TypeOf(sample)
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	return t
+}
+
+// Enum restricts values of sample's type, e.g. a string-based constant type,
+// to values
+func (g *Generator) Enum(sample interface{}, values ...interface{}) {
+	g.enums[typeOf(sample)] = values
+}
+
+// Require marks JSON properties of sample's struct type as required
+func (g *Generator) Require(sample interface{}, names ...string) {
+	t := typeOf(sample)
+	g.required[t] = append(g.required[t], names...)
+}
+
+// Property replaces the generated schema of one property of sample's struct
+// type, or adds a property the struct does not declare (e.g. one produced by
+// a custom MarshalJSON)
+func (g *Generator) Property(sample interface{}, name string, s Schema) {
+	t := typeOf(sample)
+	if g.properties[t] == nil {
+		g.properties[t] = make(map[string]Schema)
+	}
+	g.properties[t][name] = s
+}
+
+// Rule adds a constraint every value of sample's type must satisfy, e.g. an
+// if/then schema tying one property's values to another's
+func (g *Generator) Rule(sample interface{}, s Schema) {
+	t := typeOf(sample)
+	g.rules[t] = append(g.rules[t], s)
+}
+
+// Document returns the schema of sample's type as a standalone document
+func (g *Generator) Document(sample interface{}, id, title string) Schema {
+	s := g.schema(typeOf(sample))
+	s["$schema"] = Draft
+	s["$id"] = id
+	s["title"] = title
+	return s
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+var durationType = reflect.TypeOf(time.Duration(0))
+
+func (g *Generator) schema(t reflect.Type) Schema {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	var s Schema
+	switch {
+	case t == timeType:
+		s = Schema{"type": "string", "format": "date-time"}
+	case t == durationType:
+		s = Schema{"type": "integer", "description": "Duration in nanoseconds"}
+	default:
+		switch t.Kind() {
+		case reflect.Bool:
+			s = Schema{"type": "boolean"}
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+			reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			s = Schema{"type": "integer"}
+		case reflect.Float32, reflect.Float64:
+			s = Schema{"type": "number"}
+		case reflect.String:
+			s = Schema{"type": "string"}
+		case reflect.Slice, reflect.Array:
+			s = Schema{"type": "array", "items": g.schema(t.Elem())}
+		case reflect.Map:
+			s = Schema{"type": "object", "additionalProperties": g.schema(t.Elem())}
+		case reflect.Struct:
+			s = g.object(t)
+		default:
+			// interface{} and anything encoding/json renders dynamically
+			s = Schema{}
+		}
+	}
+	if values, ok := g.enums[t]; ok {
+		s["enum"] = values
+	}
+	if rules := g.rules[t]; len(rules) > 0 {
+		s["allOf"] = rules
+	}
+	return s
+}
+
+func (g *Generator) object(t reflect.Type) Schema {
+	properties := Schema{}
+	g.fields(t, properties, make(map[string]bool))
+	for name, override := range g.properties[t] {
+		properties[name] = override
+	}
+	s := Schema{"type": "object", "properties": properties}
+	if required := g.required[t]; len(required) > 0 {
+		s["required"] = required
+	}
+	return s
+}
+
+// fields adds the properties of t's fields, promoting untagged embedded
+// structs; shallower fields win over promoted ones as in encoding/json
+func (g *Generator) fields(t reflect.Type, properties Schema, seen map[string]bool) {
+	var embedded []reflect.Type
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		tag := sf.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, _, _ := strings.Cut(tag, ",")
+		if sf.Anonymous && name == "" {
+			ft := sf.Type
+			if ft.Kind() == reflect.Pointer {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				embedded = append(embedded, ft)
+				continue
+			}
+		}
+		if !sf.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = sf.Name
+		}
+		seen[name] = true
+		properties[name] = g.schema(sf.Type)
+	}
+	for _, ft := range embedded {
+		promoted := Schema{}
+		g.fields(ft, promoted, make(map[string]bool))
+		for name, s := range promoted {
+			if !seen[name] {
+				seen[name] = true
+				properties[name] = s
+			}
+		}
+	}
+}
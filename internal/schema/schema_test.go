@@ -0,0 +1,161 @@
+package schema
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"argus/internal/models"
+)
+
+type color string
+
+type base struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+type sample struct {
+	base
+	Name     string            `json:"title"`
+	Internal string            `json:"-"`
+	Color    color             `json:"color,omitempty"`
+	Count    *int              `json:"count"`
+	Ratio    float64           `json:"ratio"`
+	Tags     []string          `json:"tags"`
+	Labels   map[string]string `json:"labels"`
+	At       time.Time         `json:"at"`
+	Timeout  time.Duration     `json:"timeout"`
+	Any      interface{}       `json:"any"`
+	Plain    bool
+	hidden   string
+}
+
+func TestGenerator_Object(t *testing.T) {
+	g := NewGenerator()
+	g.Enum(color(""), "red", "blue")
+	g.Require(sample{}, "id")
+	s := g.Document(&sample{}, "/sample", "Sample")
+
+	assert.Equal(t, Draft, s["$schema"])
+	assert.Equal(t, "/sample", s["$id"])
+	assert.Equal(t, []string{"id"}, s["required"])
+	props := s["properties"].(Schema)
+	assert.ElementsMatch(t, []string{"id", "name", "title", "color", "count", "ratio", "tags", "labels", "at", "timeout", "any", "Plain"}, keys(props))
+
+	assert.Equal(t, Schema{"type": "string", "enum": []interface{}{"red", "blue"}}, props["color"])
+	assert.Equal(t, Schema{"type": "integer"}, props["count"])
+	assert.Equal(t, Schema{"type": "number"}, props["ratio"])
+	assert.Equal(t, Schema{"type": "array", "items": Schema{"type": "string"}}, props["tags"])
+	assert.Equal(t, Schema{"type": "object", "additionalProperties": Schema{"type": "string"}}, props["labels"])
+	assert.Equal(t, Schema{"type": "string", "format": "date-time"}, props["at"])
+	assert.Equal(t, "integer", props["timeout"].(Schema)["type"])
+	assert.Equal(t, Schema{}, props["any"])
+	assert.Equal(t, Schema{"type": "boolean"}, props["Plain"])
+}
+
+func TestGenerator_PropertyAndRule(t *testing.T) {
+	g := NewGenerator()
+	g.Property(base{}, "id", Schema{"type": "string", "format": "uuid"})
+	g.Property(base{}, "extra", Schema{"readOnly": true})
+	g.Rule(base{}, Schema{"not": Schema{"required": []string{"extra"}}})
+	s := g.schema(typeOf(base{}))
+
+	props := s["properties"].(Schema)
+	assert.Equal(t, "uuid", props["id"].(Schema)["format"])
+	assert.Contains(t, props, "extra")
+	assert.Len(t, s["allOf"], 1)
+}
+
+func TestAll_Alert(t *testing.T) {
+	s := All()[NameAlert]
+	require.NotNil(t, s)
+	assert.Equal(t, IDBase+NameAlert, s["$id"])
+	assert.Equal(t, []string{"name", "severity", "threshold"}, s["required"])
+
+	props := s["properties"].(Schema)
+	assert.Equal(t, values(models.Severities), props["severity"].(Schema)["enum"])
+	for _, name := range []string{"group_id", "owner", "team", "contact", "labels"} {
+		assert.Contains(t, props, name)
+	}
+
+	threshold := props["threshold"].(Schema)
+	tprops := threshold["properties"].(Schema)
+	assert.Equal(t, values(models.MetricTypes), tprops["metric_type"].(Schema)["enum"])
+	assert.Equal(t, values(models.Operators), tprops["operator"].(Schema)["enum"])
+	assert.Contains(t, tprops["unit"].(Schema)["enum"], models.UnitMB)
+	assert.Contains(t, tprops["value"].(Schema), "oneOf")
+	assert.Equal(t, true, tprops["display"].(Schema)["readOnly"])
+
+	// One metric name rule per built-in metric type, plus the series rule
+	// and the value or string_value rule
+	rules := threshold["allOf"].([]Schema)
+	assert.Len(t, rules, len(models.MetricNames)+2)
+	cpu := rules[indexOfRule(t, rules, string(models.MetricCPU))]
+	names := cpu["then"].(Schema)["properties"].(Schema)["metric_name"].(Schema)["enum"]
+	assert.Equal(t, values(models.MetricNames[models.MetricCPU]), names)
+
+	notification := props["notifications"].(Schema)["items"].(Schema)
+	assert.Equal(t, values(models.NotificationTypes), notification["properties"].(Schema)["type"].(Schema)["enum"])
+	// Required settings of email and webhook notifications, and the types of jira settings
+	rules = notification["allOf"].([]Schema)
+	require.Len(t, rules, 3)
+	assert.Equal(t, []string{"url"}, rules[1]["then"].(Schema)["properties"].(Schema)["settings"].(Schema)["required"])
+	jira := rules[2]["then"].(Schema)["properties"].(Schema)["settings"].(Schema)
+	assert.NotContains(t, jira, "required", "jira settings default to the server's")
+	assert.Equal(t, "array", jira["properties"].(Schema)["labels"].(Schema)["type"])
+}
+
+func TestAll_Task(t *testing.T) {
+	s := All()[NameTask]
+	require.NotNil(t, s)
+	assert.Equal(t, []string{"name", "type", "schedule"}, s["required"])
+	props := s["properties"].(Schema)
+	assert.Equal(t, values(models.TaskTypes), props["type"].(Schema)["enum"])
+	assert.Equal(t, Schema{"type": "array", "items": Schema{"type": "string"}}, props["tags"])
+	schedule := props["schedule"].(Schema)
+	assert.Contains(t, schedule["properties"], "cron_expression")
+	assert.Equal(t, true, schedule["properties"].(Schema)["deferred_from"].(Schema)["readOnly"])
+	assert.Len(t, schedule["allOf"], 1)
+
+	window := props["windows"].(Schema)["items"].(Schema)
+	assert.Equal(t, []string{"start", "end"}, window["required"])
+	assert.Contains(t, window["properties"].(Schema)["start"], "pattern")
+
+	agents := props["agents"].(Schema)
+	assert.Contains(t, agents["properties"], "labels")
+	assert.Len(t, agents["allOf"], 1)
+}
+
+func TestAll_Marshal(t *testing.T) {
+	for name, s := range All() {
+		data, err := json.Marshal(s)
+		require.NoError(t, err, name)
+		var decoded map[string]interface{}
+		require.NoError(t, json.Unmarshal(data, &decoded), name)
+		assert.Equal(t, Draft, decoded["$schema"], name)
+	}
+}
+
+func keys(s Schema) []string {
+	result := make([]string, 0, len(s))
+	for k := range s {
+		result = append(result, k)
+	}
+	return result
+}
+
+func indexOfRule(t *testing.T, rules []Schema, metricType string) int {
+	t.Helper()
+	for i, rule := range rules {
+		cond := rule["if"].(Schema)["properties"].(Schema)["metric_type"].(Schema)
+		if cond["const"] == metricType {
+			return i
+		}
+	}
+	t.Fatalf("no rule for metric type %s", metricType)
+	return -1
+}
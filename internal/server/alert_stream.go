@@ -0,0 +1,93 @@
+// File: internal/server/alert_stream.go
+// Brief: Alert event stream for WebSocket subscribers
+// Detailed: Publishes the state changes of alerts detected by the evaluator to clients subscribed to the "alerts" topic as they happen, so dashboards follow alerts without polling /api/alerts/status. Clients joining first receive a snapshot of the currently firing alerts, kept up to date with every event.
+// Author: drama.lin@aver.com
+// Date: 2026-10-14
+
+package server
+
+import (
+	"log/slog"
+	"sort"
+	"sync"
+	"time"
+
+	"argus/internal/models"
+)
+
+// TopicAlerts is the ?subscribe= topic of the alert stream
+const TopicAlerts = "alerts"
+
+// Kinds of alert stream frames
+const (
+	AlertFrameSnapshot = "snapshot"
+	AlertFrameEvent    = "event"
+)
+
+// AlertFrame is one message of the alert stream. A snapshot frame lists the
+// firing (pending or active) alerts; an event frame carries a state change and the alert's
+// status after it.
+type AlertFrame struct {
+	Type      string                    `json:"type"` // Always "alerts"
+	Kind      string                    `json:"kind"` // "snapshot" or "event"
+	Seq       uint64                    `json:"seq"`
+	Timestamp time.Time                 `json:"timestamp"`
+	Active    []models.AlertStatus      `json:"active,omitempty"` // Snapshot frames only, by alert ID; omitted when none
+	Event     *models.AlertHistoryEntry `json:"event,omitempty"`  // Event frames only
+	Status    *models.AlertStatus       `json:"status,omitempty"` // Event frames only
+}
+
+// AlertStream publishes alert events to WebSocket subscribers
+type AlertStream struct {
+	hub    *Hub
+	source func() map[string]*models.AlertStatus
+
+	mu  sync.Mutex
+	seq uint64
+}
+
+// NewAlertStream creates a stream whose snapshots list the firing alerts of
+// source, e.g. Evaluator.GetAllAlertStatus
+func NewAlertStream(hub *Hub, source func() map[string]*models.AlertStatus) *AlertStream {
+	return &AlertStream{hub: hub, source: source}
+}
+
+// Start sets the snapshot sent to clients subscribing before the first event
+func (s *AlertStream) Start() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	snapshot := s.snapshot(time.Now().UTC())
+	if err := s.hub.PublishTopic(TopicAlerts, snapshot, snapshot); err != nil {
+		slog.Error("Failed to publish alert stream snapshot", "error", err)
+	}
+}
+
+// Publish sends an alert event to the subscribers and updates the snapshot
+// sent to clients subscribing later
+func (s *AlertStream) Publish(event models.AlertEvent) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.seq++
+	entry := models.NewAlertHistoryEntry(event)
+	frame := &AlertFrame{Type: TopicAlerts, Kind: AlertFrameEvent, Seq: s.seq, Timestamp: entry.Timestamp, Event: &entry}
+	if event.Status != nil {
+		status := *event.Status
+		frame.Status = &status
+	}
+	if err := s.hub.PublishTopic(TopicAlerts, frame, s.snapshot(entry.Timestamp)); err != nil {
+		slog.Error("Failed to publish alert stream event", "alert_id", event.AlertID, "error", err)
+	}
+}
+
+// snapshot returns a snapshot frame of the firing alerts as of the latest
+// event; s.mu must be held
+func (s *AlertStream) snapshot(now time.Time) *AlertFrame {
+	active := []models.AlertStatus{}
+	for _, status := range s.source() {
+		if status.State.Firing() {
+			active = append(active, *status)
+		}
+	}
+	sort.Slice(active, func(i, j int) bool { return active[i].AlertID < active[j].AlertID })
+	return &AlertFrame{Type: TopicAlerts, Kind: AlertFrameSnapshot, Seq: s.seq, Timestamp: now, Active: active}
+}
@@ -0,0 +1,30 @@
+package server
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"argus/internal/models"
+)
+
+func TestAlertStream_Snapshot(t *testing.T) {
+	stream := NewAlertStream(NewHub(), func() map[string]*models.AlertStatus {
+		return map[string]*models.AlertStatus{
+			"disk":   {AlertID: "disk", State: models.StatePending}, // Evaluated alerts fire as pending
+			"cpu":    {AlertID: "cpu", State: models.StateActive},
+			"memory": {AlertID: "memory", State: models.StateResolved},
+			"load":   {AlertID: "load", State: models.StateInactive},
+		}
+	})
+
+	now := time.Now().UTC()
+	frame := stream.snapshot(now)
+	assert.Equal(t, AlertFrameSnapshot, frame.Kind)
+	assert.Equal(t, now, frame.Timestamp)
+	if assert.Len(t, frame.Active, 2) {
+		assert.Equal(t, "cpu", frame.Active[0].AlertID)
+		assert.Equal(t, "disk", frame.Active[1].AlertID)
+	}
+}
@@ -0,0 +1,144 @@
+// File: internal/server/auth.go
+// Brief: Authentication of API and WebSocket requests by the login providers
+// Detailed: While a login provider or API keys are enabled, requires every API, WebSocket and debug request to carry credentials the providers accept and rejects the others with 401, and stores the user's identity and role for the handlers and the access control. Mutating requests authenticated by a session cookie must carry the session's CSRF token, or are rejected with 403, so other sites cannot make a signed-in browser change anything. The login endpoints, the health check and the status badge stay open, and so do the agent endpoints when enrollment gives agents credentials of their own.
+// Author: drama.lin@aver.com
+// Date: 2026-10-14
+
+package server
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"argus/internal/auth"
+	"argus/internal/handlers"
+)
+
+// authProtected lists the path prefixes requiring a login
+var authProtected = []string{"/api/", "/ws", "/debug/"}
+
+// authExempt lists path prefixes served without a login
+var authExempt = []string{
+	"/api/auth/",     // Login, callback and provider discovery
+	"/api/health",    // Load balancer and orchestrator probes
+	"/api/badge.svg", // Status badge embedded in wikis and READMEs
+}
+
+// agentEndpoint is a route served to agents; ":" segments match any one
+// segment
+type agentEndpoint struct {
+	method, path string
+}
+
+// agentExempt lists the agent endpoints, served without a login when agents
+// authenticate with enrollment credentials. They are matched exactly, so the
+// other host endpoints, e.g. GET /api/hosts/enrollments, keep requiring one.
+var agentExempt = []agentEndpoint{
+	{http.MethodPost, "/api/hosts/enroll"},               // Agent onboarding
+	{http.MethodPost, "/api/hosts/heartbeat"},            // Agent reports
+	{http.MethodPost, "/api/hosts/:id/jobs/claim"},       // Agents claiming task runs
+	{http.MethodPost, "/api/hosts/:id/jobs/:job/result"}, // Agents reporting task runs
+}
+
+// matches reports whether a request is for the endpoint
+func (e agentEndpoint) matches(method, path string) bool {
+	if method != e.method {
+		return false
+	}
+	want, got := strings.Split(e.path, "/"), strings.Split(path, "/")
+	if len(want) != len(got) {
+		return false
+	}
+	for i, segment := range want {
+		if strings.HasPrefix(segment, ":") {
+			if got[i] == "" {
+				return false
+			}
+		} else if segment != got[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// authRequired reports whether a request requires a login. The agent
+// endpoints only skip it when agents carry credentials of their own.
+func authRequired(method, path string, agentCredentials bool) bool {
+	protected := false
+	for _, prefix := range authProtected {
+		if strings.HasPrefix(path, prefix) {
+			protected = true
+			break
+		}
+	}
+	if !protected {
+		return false
+	}
+	for _, prefix := range authExempt {
+		if strings.HasPrefix(path, prefix) {
+			return false
+		}
+	}
+	if !agentCredentials {
+		return true
+	}
+	for _, endpoint := range agentExempt {
+		if endpoint.matches(method, path) {
+			return false
+		}
+	}
+	return true
+}
+
+// AuthMiddleware authenticates requests against the login providers and
+// rejects unauthenticated requests to protected paths with 401. Requests to
+// the login endpoints are identified when they carry credentials, so the
+// dashboard can ask who is logged in. Mutating requests by session cookie
+// without the session's CSRF token are rejected with 403. agentCredentials
+// tells whether agents authenticate with enrollment credentials; without
+// them, the agent endpoints require a login like the rest of the API.
+func AuthMiddleware(authenticator *auth.Authenticator, agentCredentials bool) gin.HandlerFunc {
+	challenge := `Bearer realm="argus"`
+	switch {
+	case authenticator.OIDC != nil:
+	case authenticator.LDAP != nil:
+		// Browsers prompt for LDAP credentials when no login page exists
+		challenge = `Basic realm="argus", charset="UTF-8"`
+	default:
+		challenge = `APIKey realm="argus", header="` + authenticator.APIKeys.Header() + `"`
+	}
+	return func(c *gin.Context) {
+		path := c.Request.URL.Path
+		required := authRequired(c.Request.Method, path, agentCredentials)
+		if !required && !strings.HasPrefix(path, "/api/auth/") {
+			c.Next()
+			return
+		}
+		identity, err := authenticator.Authenticate(c.Request.Context(), c.Request)
+		if err == nil {
+			if err := authenticator.CheckCSRF(c.Request, identity); err != nil {
+				slog.Warn("CSRF check failed", "path", path, "user", identity.User, "client_ip", c.ClientIP())
+				c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "Missing or invalid CSRF token"})
+				return
+			}
+			c.Set(handlers.UserContextKey, identity.User)
+			c.Set(handlers.IdentityContextKey, identity)
+			c.Next()
+			return
+		}
+		if !required {
+			c.Next()
+			return
+		}
+		if !errors.Is(err, auth.ErrUnauthenticated) || c.GetHeader("Authorization") != "" ||
+			(authenticator.APIKeys != nil && c.GetHeader(authenticator.APIKeys.Header()) != "") {
+			slog.Warn("Authentication failed", "path", path, "client_ip", c.ClientIP(), "error", err)
+		}
+		c.Header("WWW-Authenticate", challenge)
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Authentication required"})
+	}
+}
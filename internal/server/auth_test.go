@@ -0,0 +1,85 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+
+	"argus/internal/auth"
+)
+
+func TestAuthMiddleware_AgentEndpoints(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	authenticator := &auth.Authenticator{
+		APIKeys: auth.NewAPIKeys("", []auth.APIKey{{Name: "agents", Hash: auth.HashAPIKey("agent-key-0123456789"), Role: auth.RoleOperator}}),
+		Roles:   auth.RoleMapping{Default: auth.RoleViewer},
+	}
+	serve := func(agentCredentials bool, method, target, key string) int {
+		r := gin.New()
+		r.Use(AuthMiddleware(authenticator, agentCredentials))
+		r.NoRoute(func(c *gin.Context) {
+			c.Status(http.StatusOK)
+		})
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(method, target, nil)
+		if key != "" {
+			req.Header.Set(auth.DefaultAPIKeyHeader, key)
+		}
+		r.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	t.Run("EnrollmentOff", func(t *testing.T) {
+		// Agents have no credentials of their own: the endpoints need a login
+		assert.Equal(t, http.StatusUnauthorized, serve(false, http.MethodPost, "/api/hosts/heartbeat", ""))
+		assert.Equal(t, http.StatusUnauthorized, serve(false, http.MethodPost, "/api/hosts/web-1/jobs/claim", ""))
+		assert.Equal(t, http.StatusUnauthorized, serve(false, http.MethodPost, "/api/hosts/web-1/jobs/j1/result", ""))
+		assert.Equal(t, http.StatusOK, serve(false, http.MethodPost, "/api/hosts/heartbeat", "agent-key-0123456789"))
+		assert.Equal(t, http.StatusOK, serve(false, http.MethodGet, "/api/health", ""))
+	})
+
+	t.Run("EnrollmentOn", func(t *testing.T) {
+		// The hosts handler checks the agent credentials
+		assert.Equal(t, http.StatusOK, serve(true, http.MethodPost, "/api/hosts/heartbeat", ""))
+		assert.Equal(t, http.StatusOK, serve(true, http.MethodPost, "/api/hosts/enroll", ""))
+		assert.Equal(t, http.StatusOK, serve(true, http.MethodPost, "/api/hosts/web-1/jobs/claim", ""))
+		assert.Equal(t, http.StatusOK, serve(true, http.MethodPost, "/api/hosts/web-1/jobs/j1/result", ""))
+		assert.Equal(t, http.StatusUnauthorized, serve(true, http.MethodGet, "/api/hosts", ""))
+
+		// Only the agent endpoints themselves are exempt
+		assert.Equal(t, http.StatusUnauthorized, serve(true, http.MethodGet, "/api/hosts/enrollments", ""))
+		assert.Equal(t, http.StatusUnauthorized, serve(true, http.MethodGet, "/api/hosts/heartbeat", ""))
+		assert.Equal(t, http.StatusUnauthorized, serve(true, http.MethodGet, "/api/hosts/jobs", ""))
+		assert.Equal(t, http.StatusUnauthorized, serve(true, http.MethodPost, "/api/hosts/web-1/revoke", ""))
+		assert.Equal(t, http.StatusUnauthorized, serve(true, http.MethodPost, "/api/hosts/web-1/jobs/j1/result/extra", ""))
+		assert.Equal(t, http.StatusOK, serve(true, http.MethodGet, "/api/hosts/enrollments", "agent-key-0123456789"))
+	})
+}
+
+func TestAuthMiddleware_ExemptPaths(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	authenticator := &auth.Authenticator{
+		APIKeys: auth.NewAPIKeys("", []auth.APIKey{{Name: "ops", Hash: auth.HashAPIKey("ops-key-0123456789"), Role: auth.RoleViewer}}),
+		Roles:   auth.RoleMapping{Default: auth.RoleViewer},
+	}
+	r := gin.New()
+	r.Use(AuthMiddleware(authenticator, false))
+	r.NoRoute(func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+	serve := func(target string) int {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, target, nil)
+		r.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	// Embedded badges are fetched by wikis without credentials
+	assert.Equal(t, http.StatusOK, serve("/api/badge.svg"))
+	assert.Equal(t, http.StatusOK, serve("/api/badge.svg?team=storage&label=storage"))
+	assert.Equal(t, http.StatusOK, serve("/api/health"))
+	assert.Equal(t, http.StatusUnauthorized, serve("/api/alerts/summary"))
+}
@@ -0,0 +1,215 @@
+// File: internal/server/cache.go
+// Brief: Short-lived response cache for expensive list endpoints
+// Detailed: Serves repeated GET requests to configured path prefixes from memory for a short TTL so dashboards polling every second do not re-read storage and re-serialize on each request; any write under a prefix invalidates its cached responses.
+// Author: drama.lin@aver.com
+// Date: 2026-10-14
+
+package server
+
+import (
+	"bytes"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"argus/internal/clock"
+	"argus/internal/handlers"
+)
+
+// ResponseCacheConfig holds configuration for the response cache
+type ResponseCacheConfig struct {
+	TTL        time.Duration // How long a response is served from cache
+	Paths      []string      // Path prefixes whose GET responses are cached
+	MaxEntries int           // Responses kept at most (0 means unlimited)
+	Clock      clock.Clock   // Time source (nil uses the real clock)
+}
+
+// DefaultResponseCacheConfig returns default configuration for the response cache
+func DefaultResponseCacheConfig() ResponseCacheConfig {
+	return ResponseCacheConfig{
+		TTL:        2 * time.Second,
+		Paths:      []string{"/api/alerts", "/api/tasks", "/api/process", "/api/metrics/process"},
+		MaxEntries: 1000,
+	}
+}
+
+// ResponseCacheStats reports response cache usage for self-metrics
+type ResponseCacheStats struct {
+	Entries       int     `json:"entries"`
+	Hits          uint64  `json:"hits"`
+	Misses        uint64  `json:"misses"`
+	HitRatio      float64 `json:"hit_ratio"`
+	Invalidations uint64  `json:"invalidations"`
+}
+
+type cachedResponse struct {
+	prefix      string
+	contentType string
+	body        []byte
+	expires     time.Time
+}
+
+// ResponseCache caches successful GET responses per path and query
+type ResponseCache struct {
+	config ResponseCacheConfig
+	clock  clock.Clock
+
+	mu            sync.Mutex
+	entries       map[string]*cachedResponse
+	hits          uint64
+	misses        uint64
+	invalidations uint64
+}
+
+// NewResponseCache creates an empty response cache
+func NewResponseCache(config ResponseCacheConfig) *ResponseCache {
+	if config.TTL <= 0 {
+		config.TTL = DefaultResponseCacheConfig().TTL
+	}
+	return &ResponseCache{
+		config:  config,
+		clock:   clock.OrReal(config.Clock),
+		entries: make(map[string]*cachedResponse),
+	}
+}
+
+// prefixFor returns the configured prefix covering path, or "" if none does
+func (rc *ResponseCache) prefixFor(path string) string {
+	best := ""
+	for _, prefix := range rc.config.Paths {
+		if (path == prefix || strings.HasPrefix(path, strings.TrimSuffix(prefix, "/")+"/")) && len(prefix) > len(best) {
+			best = prefix
+		}
+	}
+	return best
+}
+
+// cacheKey identifies a response; localized endpoints vary by Accept-Language
+// and per-user state such as notification read receipts by the user
+func cacheKey(r *http.Request, user string) string {
+	return r.URL.Path + "?" + r.URL.RawQuery + "#" + r.Header.Get("Accept-Language") + "#" + user
+}
+
+// Invalidate drops every cached response under prefix
+func (rc *ResponseCache) Invalidate(prefix string) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	for key, entry := range rc.entries {
+		if entry.prefix == prefix {
+			delete(rc.entries, key)
+		}
+	}
+	rc.invalidations++
+}
+
+func (rc *ResponseCache) lookup(key string) (*cachedResponse, bool) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	entry, ok := rc.entries[key]
+	if ok && rc.clock.Now().Before(entry.expires) {
+		rc.hits++
+		return entry, true
+	}
+	if ok {
+		delete(rc.entries, key)
+	}
+	rc.misses++
+	return nil, false
+}
+
+func (rc *ResponseCache) store(key string, entry *cachedResponse) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	if rc.config.MaxEntries > 0 && len(rc.entries) >= rc.config.MaxEntries {
+		now := rc.clock.Now()
+		for k, e := range rc.entries {
+			if !now.Before(e.expires) {
+				delete(rc.entries, k)
+			}
+		}
+		if len(rc.entries) >= rc.config.MaxEntries {
+			return
+		}
+	}
+	rc.entries[key] = entry
+}
+
+// Stats returns the cache counters
+func (rc *ResponseCache) Stats() ResponseCacheStats {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	st := ResponseCacheStats{
+		Entries:       len(rc.entries),
+		Hits:          rc.hits,
+		Misses:        rc.misses,
+		Invalidations: rc.invalidations,
+	}
+	if total := rc.hits + rc.misses; total > 0 {
+		st.HitRatio = float64(rc.hits) / float64(total)
+	}
+	return st
+}
+
+// captureWriter keeps a copy of the response body as it is written
+type captureWriter struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *captureWriter) Write(data []byte) (int, error) {
+	w.body.Write(data)
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *captureWriter) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
+
+// Middleware serves cached GET responses and invalidates a prefix after any
+// successful write request under it. Requests with Cache-Control: no-cache
+// bypass the cache but still refresh it.
+func (rc *ResponseCache) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		prefix := rc.prefixFor(c.Request.URL.Path)
+		if prefix == "" {
+			c.Next()
+			return
+		}
+
+		if c.Request.Method != http.MethodGet {
+			c.Next()
+			if c.Writer.Status() < http.StatusBadRequest {
+				rc.Invalidate(prefix)
+			}
+			return
+		}
+
+		key := cacheKey(c.Request, handlers.RequestUser(c))
+		if !strings.Contains(c.GetHeader("Cache-Control"), "no-cache") {
+			if entry, ok := rc.lookup(key); ok {
+				c.Header("X-Cache", "HIT")
+				c.Data(http.StatusOK, entry.contentType, entry.body)
+				c.Abort()
+				return
+			}
+		}
+
+		writer := &captureWriter{ResponseWriter: c.Writer}
+		c.Writer = writer
+		c.Header("X-Cache", "MISS")
+		c.Next()
+
+		if writer.Status() == http.StatusOK {
+			rc.store(key, &cachedResponse{
+				prefix:      prefix,
+				contentType: writer.Header().Get("Content-Type"),
+				body:        writer.body.Bytes(),
+				expires:     rc.clock.Now().Add(rc.config.TTL),
+			})
+		}
+	}
+}
@@ -0,0 +1,218 @@
+// File: internal/server/commands.go
+// Brief: WebSocket command channel
+// Detailed: Lets WebSocket clients send commands such as acknowledging an alert, running a task or creating a silence. Each command is translated into the matching REST request and served by the HTTP router with the headers of the WebSocket handshake, so it passes the same middleware (read-only mode, user identification, usage accounting) as the REST API. Browsers cannot set headers on the handshake, so clients signed in by a session cookie pass its CSRF token as the csrf_token query parameter of the WebSocket URL. Responses carry the command's correlation ID and go only to the client that sent it.
+// Author: drama.lin@aver.com
+// Date: 2026-10-14
+
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"argus/internal/auth"
+)
+
+const (
+	// Time allowed for one command, e.g. a task run.
+	commandTimeout = 60 * time.Second
+
+	// Commands a client may have in flight at once.
+	maxInflightCommands = 8
+)
+
+// Command is a request sent by a client over the WebSocket, e.g.
+// {"id": "42", "command": "run_task", "params": {"task_id": "backup"}}
+type Command struct {
+	ID      string          `json:"id"` // Correlation ID echoed in the response
+	Command string          `json:"command"`
+	Params  json.RawMessage `json:"params,omitempty"`
+}
+
+// CommandResponse answers a Command. Data is the body of the REST response the
+// command maps to and Status its HTTP status.
+type CommandResponse struct {
+	Type    string          `json:"type"` // Always "response"
+	ID      string          `json:"id"`
+	Command string          `json:"command"`
+	OK      bool            `json:"ok"`
+	Status  int             `json:"status,omitempty"`
+	Data    json.RawMessage `json:"data,omitempty"`
+	Error   string          `json:"error,omitempty"`
+}
+
+// commandRoute maps a command to a REST endpoint. Path segments written as
+// {param} are filled from the string params of the same name; with body the
+// params are sent as the request body.
+type commandRoute struct {
+	method string
+	path   string
+	body   bool
+}
+
+// commandRoutes lists the supported commands
+var commandRoutes = map[string]commandRoute{
+	"ack":                 {method: http.MethodPost, path: "/api/alerts/{alert_id}/ack"},
+	"ack_notification":    {method: http.MethodPost, path: "/api/alerts/notifications/{notification_id}/read"},
+	"ack_all":             {method: http.MethodPost, path: "/api/alerts/notifications/read-all"},
+	"test_alert":          {method: http.MethodPost, path: "/api/alerts/test/{alert_id}"},
+	"run_task":            {method: http.MethodPost, path: "/api/tasks/{task_id}/run"},
+	"silence":             {method: http.MethodPost, path: "/api/v2/silences", body: true},
+	"expire_silence":      {method: http.MethodDelete, path: "/api/v2/silence/{silence_id}"},
+	"silence_group":       {method: http.MethodPost, path: "/api/alert-groups/{group_id}/silence", body: true},
+	"unsilence_group":     {method: http.MethodDelete, path: "/api/alert-groups/{group_id}/silence"},
+	"enable_alert_group":  {method: http.MethodPost, path: "/api/alert-groups/{group_id}/enable"},
+	"disable_alert_group": {method: http.MethodPost, path: "/api/alert-groups/{group_id}/disable"},
+}
+
+// commandHeaders are handshake headers not forwarded to command requests:
+// they describe the WebSocket connection rather than the caller
+var commandHeaders = []string{"Upgrade", "Connection", "Accept-Encoding", "Content-Length",
+	"Sec-Websocket-Key", "Sec-Websocket-Version", "Sec-Websocket-Extensions", "Sec-Websocket-Protocol"}
+
+// commandRequest builds the REST request a command maps to, carrying the
+// headers of the WebSocket handshake so it is authorized like the client's
+// own REST calls
+func commandRequest(ctx context.Context, handshake *http.Request, cmd Command) (*http.Request, error) {
+	route, ok := commandRoutes[cmd.Command]
+	if !ok {
+		return nil, fmt.Errorf("unknown command %q", cmd.Command)
+	}
+	params := map[string]interface{}{}
+	if len(cmd.Params) > 0 {
+		if err := json.Unmarshal(cmd.Params, &params); err != nil {
+			return nil, fmt.Errorf("invalid params: %w", err)
+		}
+	}
+
+	segments := strings.Split(route.path, "/")
+	for i, segment := range segments {
+		name, isParam := strings.CutPrefix(segment, "{")
+		if !isParam {
+			continue
+		}
+		name = strings.TrimSuffix(name, "}")
+		value, _ := params[name].(string)
+		if value == "" {
+			return nil, fmt.Errorf("missing param %q", name)
+		}
+		segments[i] = url.PathEscape(value)
+	}
+
+	var body []byte
+	if route.body {
+		body = cmd.Params
+	}
+	req, err := http.NewRequestWithContext(ctx, route.method, strings.Join(segments, "/"), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header = handshake.Header.Clone()
+	for _, name := range commandHeaders {
+		req.Header.Del(name)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if token := handshake.URL.Query().Get("csrf_token"); token != "" && req.Header.Get(auth.CSRFHeader) == "" {
+		req.Header.Set(auth.CSRFHeader, token)
+	}
+	req.Host = handshake.Host
+	req.RemoteAddr = handshake.RemoteAddr
+	return req, nil
+}
+
+// commandRecorder captures the response of a command request
+type commandRecorder struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+func (r *commandRecorder) Header() http.Header { return r.header }
+
+func (r *commandRecorder) Write(data []byte) (int, error) {
+	if r.status == 0 {
+		r.status = http.StatusOK
+	}
+	return r.body.Write(data)
+}
+
+func (r *commandRecorder) WriteHeader(status int) {
+	if r.status == 0 {
+		r.status = status
+	}
+}
+
+// responseError extracts the error message of a failed REST response, which
+// is either {"error": "..."} or, for the Alertmanager API, a bare string
+func responseError(body []byte) string {
+	var message string
+	if json.Unmarshal(body, &message) == nil {
+		return message
+	}
+	var envelope struct {
+		Error string `json:"error"`
+	}
+	if json.Unmarshal(body, &envelope) == nil && envelope.Error != "" {
+		return envelope.Error
+	}
+	return strings.TrimSpace(string(body))
+}
+
+// executeCommand serves cmd with handler on behalf of the client that
+// connected with handshake
+func executeCommand(handler http.Handler, handshake *http.Request, cmd Command) CommandResponse {
+	response := CommandResponse{Type: "response", ID: cmd.ID, Command: cmd.Command}
+	if handler == nil {
+		response.Error = "commands are not enabled"
+		return response
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), commandTimeout)
+	defer cancel()
+	req, err := commandRequest(ctx, handshake, cmd)
+	if err != nil {
+		response.Error = err.Error()
+		return response
+	}
+
+	recorder := &commandRecorder{header: make(http.Header)}
+	handler.ServeHTTP(recorder, req)
+	if recorder.status == 0 {
+		recorder.status = http.StatusOK
+	}
+	response.Status = recorder.status
+	response.OK = recorder.status < http.StatusBadRequest
+	if !response.OK {
+		response.Error = responseError(recorder.body.Bytes())
+	}
+	if body := recorder.body.Bytes(); json.Valid(body) {
+		response.Data = body
+	}
+	return response
+}
+
+// handleMessage runs one command received from a client and queues the
+// response to it. Commands run concurrently up to maxInflightCommands, so a
+// long task run does not stall the connection.
+func (c *Client) handleMessage(message []byte) {
+	var cmd Command
+	if err := json.Unmarshal(message, &cmd); err != nil || cmd.Command == "" {
+		c.hub.reply(c, CommandResponse{Type: "response", ID: cmd.ID, Error: "invalid command: expected {\"id\": ..., \"command\": ..., \"params\": {...}}"})
+		return
+	}
+	select {
+	case c.inflight <- struct{}{}:
+	default:
+		c.hub.reply(c, CommandResponse{Type: "response", ID: cmd.ID, Command: cmd.Command, Error: "too many commands in flight"})
+		return
+	}
+	go func() {
+		defer func() { <-c.inflight }()
+		c.hub.reply(c, executeCommand(c.hub.commands, c.handshake, cmd))
+	}()
+}
@@ -5,11 +5,19 @@
 package server
 
 import (
+	"context"
+	"encoding/json"
 	"log/slog"
 	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/gorilla/websocket"
+
+	"argus/internal/msgpack"
 )
 
 const (
@@ -22,15 +30,118 @@ const (
 	// Send pings to peer with this period. Must be less than pongWait.
 	pingPeriod = (pongWait * 9) / 10
 
-	// Maximum message size allowed from peer.
-	maxMessageSize = 512
+	// Maximum message size allowed from peer; commands carry request bodies
+	// such as silences.
+	maxMessageSize = 16 << 10
+
+	// Close reason sent to clients when the server is draining connections.
+	shutdownCloseReason = "server-shutting-down"
+)
+
+// shutdownEvent is broadcast to every client before its connection is closed on shutdown,
+// so dashboards can distinguish a planned restart from a network failure and reconnect.
+var shutdownEvent = []byte(`{"type":"server-shutting-down"}`)
+
+// Encoding is the wire format of the frames sent to a client. Clients pick
+// one with the ?encoding= query parameter or by offering the matching
+// Sec-WebSocket-Protocol ("argus.json" or "argus.msgpack"); JSON is the default.
+type Encoding string
+
+const (
+	EncodingJSON    Encoding = "json"    // Text frames holding JSON
+	EncodingMsgpack Encoding = "msgpack" // Binary frames holding MessagePack
 )
 
+// Encodings lists the supported encodings
+var Encodings = []Encoding{EncodingJSON, EncodingMsgpack}
+
+const subprotocolPrefix = "argus."
+
+// negotiateEncoding returns the encoding requested by ?encoding=, or "" if the
+// parameter is absent, and whether the requested encoding is supported
+func negotiateEncoding(r *http.Request) (Encoding, bool) {
+	name := r.URL.Query().Get("encoding")
+	if name == "" {
+		return "", true
+	}
+	for _, enc := range Encodings {
+		if Encoding(name) == enc {
+			return enc, true
+		}
+	}
+	return "", false
+}
+
+// parseTopics returns the topics listed in ?subscribe=, e.g. "processes"
+func parseTopics(r *http.Request) map[string]bool {
+	topics := make(map[string]bool)
+	for _, list := range r.URL.Query()["subscribe"] {
+		for _, topic := range strings.Split(list, ",") {
+			if topic = strings.TrimSpace(topic); topic != "" {
+				topics[topic] = true
+			}
+		}
+	}
+	return topics
+}
+
+// frame is one broadcast message, encoded for each encoding on first use
+type frame struct {
+	topic    string      // Only subscribers receive it; "" goes to every client
+	value    interface{} // Original value, when published with Publish
+	encoded  map[Encoding][]byte
+	keyframe *frame // Replaces the topic's keyframe sent to clients that subscribe later
+}
+
+func newFrame(value interface{}, jsonData []byte) *frame {
+	encoded := make(map[Encoding][]byte, len(Encodings))
+	if jsonData != nil {
+		encoded[EncodingJSON] = jsonData
+	}
+	return &frame{value: value, encoded: encoded}
+}
+
+// bytes returns the frame in enc. Only the hub goroutine calls it, so the
+// encoded map needs no locking.
+func (f *frame) bytes(enc Encoding) ([]byte, error) {
+	if data, ok := f.encoded[enc]; ok {
+		return data, nil
+	}
+	var data []byte
+	var err error
+	if enc == EncodingJSON {
+		data, err = json.Marshal(f.value)
+	} else if f.value != nil {
+		data, err = msgpack.Marshal(f.value)
+	} else {
+		data, err = msgpack.FromJSON(f.encoded[EncodingJSON])
+	}
+	if err != nil {
+		return nil, err
+	}
+	f.encoded[enc] = data
+	return data, nil
+}
+
+// EncodingStats reports the clients and traffic of one encoding
+type EncodingStats struct {
+	Clients int64  `json:"clients"`
+	Frames  uint64 `json:"frames"`
+	Bytes   uint64 `json:"bytes"`
+}
+
+type encodingCounters struct {
+	clients atomic.Int64
+	frames  atomic.Uint64
+	bytes   atomic.Uint64
+}
+
 var upgrader = websocket.Upgrader{
 	ReadBufferSize:  1024,
 	WriteBufferSize: 1024,
+	Subprotocols:    []string{subprotocolPrefix + string(EncodingMsgpack), subprotocolPrefix + string(EncodingJSON)},
 	CheckOrigin: func(r *http.Request) bool {
-		return true // Allow all connections
+		return true // Checked by ServeWs against the hub's allowed origins
 	},
 }
 
@@ -43,6 +154,18 @@ type Client struct {
 
 	// Buffered channel of outbound messages.
 	send chan []byte
+
+	// Wire format of outbound messages.
+	encoding Encoding
+
+	// Topics the client subscribed to with ?subscribe=.
+	topics map[string]bool
+
+	// Handshake request whose headers authorize the client's commands.
+	handshake *http.Request
+
+	// Slots of the commands being executed.
+	inflight chan struct{}
 }
 
 // readPump pumps messages from the websocket connection to the hub.
@@ -50,12 +173,13 @@ func (c *Client) readPump() {
 	defer func() {
 		c.hub.unregister <- c
 		c.conn.Close()
+		c.hub.active.Done()
 	}()
 	c.conn.SetReadLimit(maxMessageSize)
 	c.conn.SetReadDeadline(time.Now().Add(pongWait))
 	c.conn.SetPongHandler(func(string) error { c.conn.SetReadDeadline(time.Now().Add(pongWait)); return nil })
 	for {
-		_, _, err := c.conn.ReadMessage()
+		_, message, err := c.conn.ReadMessage()
 		if err != nil {
 			if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseAbnormalClosure) {
 				slog.Error("WebSocket read error", "error", err, "type", "unexpected_close")
@@ -64,6 +188,7 @@ func (c *Client) readPump() {
 			}
 			break
 		}
+		c.handleMessage(message)
 	}
 }
 
@@ -80,10 +205,22 @@ func (c *Client) writePump() {
 			c.conn.SetWriteDeadline(time.Now().Add(writeWait))
 			if !ok {
 				// The hub closed the channel.
-				c.conn.WriteMessage(websocket.CloseMessage, []byte{})
+				closeMessage := []byte{}
+				if c.hub.IsDraining() {
+					closeMessage = websocket.FormatCloseMessage(websocket.CloseGoingAway, shutdownCloseReason)
+				}
+				c.conn.WriteMessage(websocket.CloseMessage, closeMessage)
 				return
 			}
-			c.conn.WriteMessage(websocket.TextMessage, message)
+			messageType := websocket.TextMessage
+			if c.encoding != EncodingJSON {
+				messageType = websocket.BinaryMessage
+			}
+			if err := c.conn.WriteMessage(messageType, message); err == nil {
+				counters := c.hub.counters[c.encoding]
+				counters.frames.Add(1)
+				counters.bytes.Add(uint64(len(message)))
+			}
 		case <-ticker.C:
 			c.conn.SetWriteDeadline(time.Now().Add(writeWait))
 			if err := c.conn.WriteMessage(websocket.PingMessage, nil); err != nil {
@@ -100,20 +237,59 @@ type Hub struct {
 	clients map[*Client]bool
 
 	// Inbound messages from the clients.
-	broadcast chan []byte
+	broadcast chan *frame
 
 	// Register requests from the clients.
 	register chan *Client
 
 	// Unregister requests from clients.
 	unregister chan *Client
+
+	// Shutdown requests; closes all client connections.
+	shutdown chan struct{}
+
+	// Set once Shutdown is called; new upgrades are refused while draining.
+	draining atomic.Bool
+
+	// Tracks connections whose pumps are still running.
+	active sync.WaitGroup
+
+	// Clients and traffic per encoding.
+	counters map[Encoding]*encodingCounters
+
+	// Latest full state per topic, sent to clients when they connect so
+	// streams of deltas can be applied from there.
+	keyframes map[string]*frame
+
+	// Responses to commands, each sent only to the client that asked.
+	replies chan reply
+
+	// Serves the REST requests commands map to; nil disables commands.
+	commands http.Handler
+
+	// Origins besides the server's own whose pages may connect.
+	allowedOrigins []string
+}
+
+// reply is a frame for a single client
+type reply struct {
+	client *Client
+	frame  *frame
 }
 
 func NewHub() *Hub {
+	counters := make(map[Encoding]*encodingCounters, len(Encodings))
+	for _, enc := range Encodings {
+		counters[enc] = &encodingCounters{}
+	}
 	return &Hub{
-		broadcast:  make(chan []byte),
+		counters:   counters,
+		keyframes:  make(map[string]*frame),
+		broadcast:  make(chan *frame),
+		replies:    make(chan reply),
 		register:   make(chan *Client),
 		unregister: make(chan *Client),
+		shutdown:   make(chan struct{}),
 		clients:    make(map[*Client]bool),
 	}
 }
@@ -122,37 +298,226 @@ func (h *Hub) Run() {
 	for {
 		select {
 		case client := <-h.register:
+			if h.IsDraining() {
+				// Raced with Shutdown; close immediately instead of serving.
+				close(client.send)
+				continue
+			}
 			h.clients[client] = true
+			h.counters[client.encoding].clients.Add(1)
+			for topic := range client.topics {
+				if keyframe, ok := h.keyframes[topic]; ok {
+					h.send(client, keyframe)
+				}
+			}
 		case client := <-h.unregister:
 			if _, ok := h.clients[client]; ok {
-				delete(h.clients, client)
-				close(client.send)
+				h.remove(client)
+			}
+		case <-h.shutdown:
+			// Notify every client, then close its send channel so writePump
+			// sends a going-away close frame and the connection winds down.
+			event := newFrame(nil, shutdownEvent)
+			for client := range h.clients {
+				if message, err := event.bytes(client.encoding); err == nil {
+					select {
+					case client.send <- message:
+					default:
+					}
+				}
+				h.remove(client)
+			}
+		case r := <-h.replies:
+			if _, ok := h.clients[r.client]; ok {
+				h.send(r.client, r.frame)
+			}
+		case f := <-h.broadcast:
+			if f.keyframe != nil {
+				h.keyframes[f.topic] = f.keyframe
 			}
-		case message := <-h.broadcast:
 			for client := range h.clients {
-				select {
-				case client.send <- message:
-				default:
-					close(client.send)
-					delete(h.clients, client)
+				if f.topic == "" || client.topics[f.topic] {
+					h.send(client, f)
 				}
 			}
 		}
 	}
 }
 
+// send queues a frame for a client, dropping clients that fall behind
+func (h *Hub) send(client *Client, f *frame) {
+	message, err := f.bytes(client.encoding)
+	if err != nil {
+		slog.Error("Failed to encode WebSocket message", "encoding", client.encoding, "error", err)
+		return
+	}
+	select {
+	case client.send <- message:
+	default:
+		h.remove(client)
+	}
+}
+
+// remove closes the send channel of a registered client and forgets it
+func (h *Hub) remove(client *Client) {
+	close(client.send)
+	delete(h.clients, client)
+	h.counters[client.encoding].clients.Add(-1)
+}
+
+// Broadcast sends a JSON message to every client, transcoded for clients
+// using another encoding.
 func (h *Hub) Broadcast(message []byte) {
-	h.broadcast <- message
+	h.broadcast <- newFrame(nil, message)
+}
+
+// Publish sends v to every client, encoding it once per encoding in use.
+func (h *Hub) Publish(v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	h.broadcast <- newFrame(v, data)
+	return nil
+}
+
+// PublishTopic sends v to the clients subscribed to topic. When keyframe is
+// not nil it becomes the state sent first to clients subscribing later, so
+// it must describe the topic as of v; it is only encoded when a client
+// subscribes.
+func (h *Hub) PublishTopic(topic string, v interface{}, keyframe interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	f := newFrame(v, data)
+	f.topic = topic
+	if keyframe != nil {
+		f.keyframe = newFrame(keyframe, nil)
+	}
+	h.broadcast <- f
+	return nil
+}
+
+// SetCommandHandler enables the command channel: commands sent by clients are
+// served as REST requests by handler, normally the API router. It must be
+// called before clients connect.
+func (h *Hub) SetCommandHandler(handler http.Handler) {
+	h.commands = handler
+}
+
+// SetAllowedOrigins sets the origins, e.g. https://dashboard.example.com,
+// whose pages may connect besides the server's own. It must be called before
+// clients connect.
+func (h *Hub) SetAllowedOrigins(origins []string) {
+	h.allowedOrigins = origins
+}
+
+// allowedOrigin reports whether a handshake's Origin is the server's own or
+// an allowed one. Handshakes without an Origin header come from programs
+// rather than browsers. Browsers send the site's cookies with handshakes of
+// any page, and commands are served with them, so a page of another origin
+// could otherwise act as the signed-in user.
+func (h *Hub) allowedOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+	if u, err := url.Parse(origin); err == nil && strings.EqualFold(u.Host, r.Host) {
+		return true
+	}
+	for _, allowed := range h.allowedOrigins {
+		if strings.EqualFold(strings.TrimRight(allowed, "/"), origin) {
+			return true
+		}
+	}
+	return false
+}
+
+// reply sends a command response to one client in its encoding; it is dropped
+// if the client has disconnected meanwhile.
+func (h *Hub) reply(client *Client, response CommandResponse) {
+	data, err := json.Marshal(response)
+	if err != nil {
+		slog.Error("Failed to marshal WebSocket command response", "command", response.Command, "error", err)
+		return
+	}
+	h.replies <- reply{client: client, frame: newFrame(nil, data)}
+}
+
+// Stats returns the connected clients and the frames and bytes sent per encoding.
+func (h *Hub) Stats() map[Encoding]EncodingStats {
+	stats := make(map[Encoding]EncodingStats, len(h.counters))
+	for enc, c := range h.counters {
+		stats[enc] = EncodingStats{Clients: c.clients.Load(), Frames: c.frames.Load(), Bytes: c.bytes.Load()}
+	}
+	return stats
+}
+
+// IsDraining reports whether the hub is shutting down and refusing new connections.
+func (h *Hub) IsDraining() bool {
+	return h.draining.Load()
+}
+
+// Shutdown stops accepting new connections, notifies connected clients that the server
+// is going away, and waits until their connections close or the context expires.
+func (h *Hub) Shutdown(ctx context.Context) error {
+	if !h.draining.CompareAndSwap(false, true) {
+		return nil
+	}
+	slog.Info("Draining WebSocket clients")
+
+	select {
+	case h.shutdown <- struct{}{}:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	done := make(chan struct{})
+	go func() {
+		h.active.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		slog.Info("All WebSocket clients disconnected")
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
 }
 
 // ServeWs handles websocket requests from the peer.
 func ServeWs(hub *Hub, w http.ResponseWriter, r *http.Request) {
+	if hub.IsDraining() {
+		http.Error(w, "server is shutting down", http.StatusServiceUnavailable)
+		return
+	}
+	if !hub.allowedOrigin(r) {
+		slog.Warn("Rejected WebSocket handshake from another origin", "origin", r.Header.Get("Origin"), "host", r.Host)
+		http.Error(w, "origin not allowed", http.StatusForbidden)
+		return
+	}
+	encoding, ok := negotiateEncoding(r)
+	if !ok {
+		http.Error(w, "unsupported encoding, use json or msgpack", http.StatusBadRequest)
+		return
+	}
 	conn, err := upgrader.Upgrade(w, r, nil)
 	if err != nil {
 		slog.Error("Failed to upgrade websocket:", "error", err)
 		return
 	}
-	client := &Client{hub: hub, conn: conn, send: make(chan []byte, 256)}
+	if encoding == "" {
+		encoding = EncodingJSON
+		if protocol := conn.Subprotocol(); protocol != "" {
+			encoding = Encoding(protocol[len(subprotocolPrefix):])
+		}
+	}
+	client := &Client{hub: hub, conn: conn, send: make(chan []byte, 256), encoding: encoding, topics: parseTopics(r),
+		handshake: r.Clone(context.Background()), inflight: make(chan struct{}, maxInflightCommands)}
+	hub.active.Add(1)
 	client.hub.register <- client
 
 	// Allow collection of memory referenced by the caller by doing all work in
@@ -0,0 +1,59 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServeWs_Origin(t *testing.T) {
+	hub := NewHub()
+	hub.SetAllowedOrigins([]string{"https://dashboard.example.com/"})
+	go hub.Run()
+	t.Cleanup(func() { hub.Shutdown(context.Background()) })
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ServeWs(hub, w, r)
+	}))
+	t.Cleanup(srv.Close)
+	wsURL := "ws" + strings.TrimPrefix(srv.URL, "http")
+
+	dial := func(origin string) (int, error) {
+		header := http.Header{"Cookie": {"argus_session=signed-in"}}
+		if origin != "" {
+			header.Set("Origin", origin)
+		}
+		conn, resp, err := websocket.DefaultDialer.Dial(wsURL, header)
+		if err == nil {
+			conn.Close()
+		}
+		if resp == nil {
+			return 0, err
+		}
+		return resp.StatusCode, err
+	}
+
+	tests := []struct {
+		name   string
+		origin string
+		want   int
+	}{
+		{"NoOrigin", "", http.StatusSwitchingProtocols},
+		{"SameOrigin", srv.URL, http.StatusSwitchingProtocols},
+		{"AllowedOrigin", "https://dashboard.example.com", http.StatusSwitchingProtocols},
+		{"OtherOrigin", "https://evil.example.com", http.StatusForbidden},
+		{"OtherPort", "http://127.0.0.1:1", http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, err := dial(tt.origin)
+			require.NotZero(t, status, "dial failed: %v", err)
+			assert.Equal(t, tt.want, status)
+		})
+	}
+}
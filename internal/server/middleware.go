@@ -2,11 +2,14 @@ package server
 
 import (
 	"log/slog"
+	"net/http"
 	"strings"
 	"sync"
 	"time"
 
 	"github.com/gin-gonic/gin"
+
+	"argus/internal/handlers"
 )
 
 // Pool for reusing string builders in logging
@@ -36,6 +39,67 @@ func LoggingMiddleware() gin.HandlerFunc {
 	})
 }
 
+// SlowRequestMiddleware logs a warning for requests that take longer than the threshold.
+// A non-positive threshold disables slow-request logging.
+func SlowRequestMiddleware(threshold time.Duration) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if threshold <= 0 {
+			c.Next()
+			return
+		}
+
+		start := time.Now()
+		c.Next()
+
+		if latency := time.Since(start); latency > threshold {
+			slog.Warn("Slow HTTP request",
+				"method", c.Request.Method,
+				"path", c.Request.URL.Path,
+				"status", c.Writer.Status(),
+				"latency", latency,
+				"threshold", threshold,
+				"client_ip", c.ClientIP(),
+			)
+		}
+	}
+}
+
+// MaxBodySizeMiddleware caps the size of request bodies. The limit for a request is taken
+// from the longest matching path prefix in overrides, falling back to defaultLimit.
+// A non-positive limit leaves the body unrestricted.
+func MaxBodySizeMiddleware(defaultLimit int64, overrides map[string]int64) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		limit := bodyLimitForPath(c.Request.URL.Path, defaultLimit, overrides)
+		if limit <= 0 || c.Request.Body == nil {
+			c.Next()
+			return
+		}
+
+		if c.Request.ContentLength > limit {
+			c.AbortWithStatusJSON(http.StatusRequestEntityTooLarge, gin.H{
+				"error": "Request body too large",
+			})
+			return
+		}
+
+		c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, limit)
+		c.Next()
+	}
+}
+
+// bodyLimitForPath resolves the body size limit for the given request path
+func bodyLimitForPath(path string, defaultLimit int64, overrides map[string]int64) int64 {
+	limit := defaultLimit
+	matched := 0
+	for prefix, prefixLimit := range overrides {
+		if strings.HasPrefix(path, prefix) && len(prefix) > matched {
+			limit = prefixLimit
+			matched = len(prefix)
+		}
+	}
+	return limit
+}
+
 // shouldSkipLogging determines if we should skip logging for certain paths
 func shouldSkipLogging(path string) bool {
 	// Skip logging for static assets and health checks to reduce log noise
@@ -58,8 +122,8 @@ func shouldSkipLogging(path string) bool {
 var (
 	corsOrigin      = "*"
 	corsCredentials = "true"
-	corsHeaders     = "Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, accept, origin, Cache-Control, X-Requested-With"
-	corsMethods     = "POST, OPTIONS, GET, PUT, DELETE"
+	corsHeaders     = "Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, X-API-Key, Authorization, accept, origin, Cache-Control, X-Requested-With"
+	corsMethods     = "POST, OPTIONS, GET, PUT, PATCH, DELETE"
 )
 
 // CORSMiddleware sets CORS headers for all requests with optimized allocations
@@ -120,13 +184,14 @@ func CompressionMiddleware() gin.HandlerFunc {
 	}
 }
 
-// SecurityHeadersMiddleware adds security headers
-func SecurityHeadersMiddleware() gin.HandlerFunc {
+// UserHeaderMiddleware identifies the requesting user from a header set by an
+// authenticating reverse proxy, such as X-Forwarded-User. The header must only
+// be trusted when the proxy strips it from client requests.
+func UserHeaderMiddleware(header string) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		c.Header("X-Content-Type-Options", "nosniff")
-		c.Header("X-Frame-Options", "DENY")
-		c.Header("X-XSS-Protection", "1; mode=block")
-		c.Header("Referrer-Policy", "strict-origin-when-cross-origin")
+		if user := strings.TrimSpace(c.GetHeader(header)); user != "" {
+			c.Set(handlers.UserContextKey, user)
+		}
 		c.Next()
 	}
 }
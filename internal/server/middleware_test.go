@@ -3,6 +3,7 @@ package server
 import (
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
@@ -56,4 +57,14 @@ func TestCORSMiddleware(t *testing.T) {
 	assert.Equal(t, http.StatusOK, w.Code)
 	assert.Equal(t, "*", w.Header().Get("Access-Control-Allow-Origin"))
 	assert.Equal(t, "true", w.Header().Get("Access-Control-Allow-Credentials"))
+
+	// Preflight of a bulk alert edit
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("OPTIONS", "/api/alerts/bulk", nil)
+	req.Header.Set("Origin", "http://example.com")
+	req.Header.Set("Access-Control-Request-Method", "PATCH")
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	assert.Contains(t, strings.Split(w.Header().Get("Access-Control-Allow-Methods"), ", "), "PATCH")
 }
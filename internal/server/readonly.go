@@ -0,0 +1,122 @@
+// File: internal/server/readonly.go
+// Brief: Global read-only mode for the HTTP API
+// Detailed: While enabled, rejects every request that would change alerts, tasks, groups, silences, notifications or faults with 403, so a dashboard can be exposed to a broad audience or frozen during an audit. Evaluators, schedulers and data feeds such as remote-write keep working.
+// Author: drama.lin@aver.com
+// Date: 2026-10-14
+
+package server
+
+import (
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync/atomic"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ReadOnlyPath is the admin endpoint toggling read-only mode. It stays
+// writable so the mode can be switched off again.
+const ReadOnlyPath = "/api/admin/read-only"
+
+// readOnlyExempt lists path prefixes of POST endpoints that do not change any
+// state, or that feed metrics and alerts in rather than configure Argus
+var readOnlyExempt = []string{
+	ReadOnlyPath,
+	"/api/auth/",                 // Login and logout
+	"/api/grafana/",              // Grafana queries are POSTs
+	"/api/notifications/preview", // Renders without sending
+	"/api/ingest/",               // Prometheus remote-write
+	"/api/v2/alerts",             // Alerts pushed by Prometheus
+}
+
+// ReadOnlyMode rejects mutating API requests while enabled
+type ReadOnlyMode struct {
+	enabled atomic.Bool
+}
+
+// NewReadOnlyMode creates a read-only mode, initially enabled or not
+func NewReadOnlyMode(enabled bool) *ReadOnlyMode {
+	m := &ReadOnlyMode{}
+	m.enabled.Store(enabled)
+	return m
+}
+
+// ReadOnly reports whether mutating requests are rejected
+func (m *ReadOnlyMode) ReadOnly() bool {
+	return m.enabled.Load()
+}
+
+// SetReadOnly switches read-only mode on or off
+func (m *ReadOnlyMode) SetReadOnly(enabled bool) {
+	if m.enabled.Swap(enabled) != enabled {
+		slog.Warn("Read-only mode changed", "read_only", enabled)
+	}
+}
+
+// allowedReadOnly reports whether a request may proceed in read-only mode
+func allowedReadOnly(r *http.Request) bool {
+	switch r.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return true
+	}
+	path := r.URL.Path
+	for _, prefix := range readOnlyExempt {
+		if strings.HasPrefix(path, prefix) {
+			return true
+		}
+	}
+	if strings.HasPrefix(path, "/api/alerts") {
+		// Dry runs of alert rules change nothing
+		if r.Method == http.MethodPost && strings.HasSuffix(path, "/simulate") {
+			return true
+		}
+		if preview, _ := strconv.ParseBool(r.URL.Query().Get("preview")); preview && previewable(r.Method, path) {
+			return true
+		}
+		if dryRun, _ := strconv.ParseBool(r.URL.Query().Get("dry_run")); dryRun && path == "/api/alerts/bulk" {
+			return true
+		}
+	}
+	for _, endpoint := range agentExempt {
+		// Agent onboarding, reports and task runs
+		if endpoint.matches(r.Method, path) {
+			return true
+		}
+	}
+	if path == "/api/v2/silences" {
+		// Previews of the alerts a silence matches
+		if preview, _ := strconv.ParseBool(r.URL.Query().Get("preview")); preview {
+			return true
+		}
+	}
+	return false
+}
+
+// previewable reports whether an alert endpoint answers ?preview=true with
+// an impact preview instead of saving: creating, updating and cloning alerts
+func previewable(method, path string) bool {
+	parts := strings.Split(strings.Trim(strings.TrimPrefix(path, "/api/alerts"), "/"), "/")
+	switch method {
+	case http.MethodPost:
+		return parts[0] == "" || (len(parts) == 2 && parts[1] == "clone")
+	case http.MethodPut:
+		return len(parts) == 1 && parts[0] != ""
+	}
+	return false
+}
+
+// Middleware rejects mutating requests with 403 while read-only mode is on
+func (m *ReadOnlyMode) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !m.ReadOnly() || allowedReadOnly(c.Request) {
+			c.Next()
+			return
+		}
+		c.AbortWithStatusJSON(http.StatusForbidden, gin.H{
+			"error":     "Argus is in read-only mode",
+			"read_only": true,
+		})
+	}
+}
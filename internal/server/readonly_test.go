@@ -0,0 +1,47 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReadOnlyMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(NewReadOnlyMode(true).Middleware())
+	r.NoRoute(func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	tests := []struct {
+		method string
+		target string
+		want   int
+	}{
+		{http.MethodGet, "/api/alerts", http.StatusOK},
+		{http.MethodPost, "/api/alerts", http.StatusForbidden},
+		{http.MethodPost, "/api/alerts?preview=true", http.StatusOK},
+		{http.MethodPut, "/api/alerts/a1?preview=true", http.StatusOK},
+		{http.MethodPost, "/api/alerts/a1/clone?preview=true", http.StatusOK},
+		{http.MethodPost, "/api/alerts/a1/simulate", http.StatusOK},
+		{http.MethodPatch, "/api/alerts/bulk?dry_run=true", http.StatusOK},
+		{http.MethodPatch, "/api/alerts/bulk", http.StatusForbidden},
+		// Endpoints without previews ignore ?preview and change state
+		{http.MethodDelete, "/api/alerts/a1?preview=true", http.StatusForbidden},
+		{http.MethodDelete, "/api/alerts/notifications?preview=1", http.StatusForbidden},
+		{http.MethodPost, "/api/alerts/defaults?preview=true", http.StatusForbidden},
+		{http.MethodPost, "/api/alerts/a1/ack?preview=true", http.StatusForbidden},
+		{http.MethodPost, "/api/v2/silences?preview=true", http.StatusOK},
+		{http.MethodPost, ReadOnlyPath, http.StatusOK},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(tt.method, tt.target, nil)
+		r.ServeHTTP(w, req)
+		assert.Equal(t, tt.want, w.Code, "%s %s", tt.method, tt.target)
+	}
+}
@@ -1,6 +1,7 @@
 package server
 
 import (
+	"fmt"
 	"net/http"
 	"net/http/pprof"
 	"time"
@@ -37,8 +38,9 @@ func setupPprofRoutes(router *gin.Engine, pprofPath string) {
 }
 
 // NewServer sets up the Gin engine, middleware, and routes with production optimizations.
-// Accepts configuration, alert/task handlers, and metrics handler, returns the *gin.Engine.
-func NewServer(cfg *config.Config, alertsHandler IRoutesRegister, tasksHandler IRoutesRegister, metricsHandler *handlers.MetricsHandler) *gin.Engine {
+// Accepts configuration, alert/task handlers, metrics handler and optional extra middleware
+// run after logging (e.g. API usage tracking), returns the *gin.Engine.
+func NewServer(cfg *config.Config, alertsHandler IRoutesRegister, tasksHandler IRoutesRegister, metricsHandler *handlers.MetricsHandler, middleware ...gin.HandlerFunc) *gin.Engine {
 	// Configure Gin for production or development
 	if !cfg.Debug.Enabled {
 		gin.SetMode(gin.ReleaseMode)
@@ -53,7 +55,9 @@ func NewServer(cfg *config.Config, alertsHandler IRoutesRegister, tasksHandler I
 	router.Use(gin.Recovery())
 
 	// 2. Security headers (early in the chain)
-	router.Use(SecurityHeadersMiddleware())
+	if cfg.SecurityHeaders.Enabled {
+		router.Use(SecurityHeadersMiddleware(cfg.SecurityHeaders))
+	}
 
 	// 3. CORS middleware (before any request processing)
 	router.Use(CORSMiddleware())
@@ -66,8 +70,20 @@ func NewServer(cfg *config.Config, alertsHandler IRoutesRegister, tasksHandler I
 		router.Use(CompressionMiddleware())
 	}
 
-	// 6. Logging middleware (last to capture all request details)
+	// 6. Request body size limits (before handlers read the body)
+	router.Use(MaxBodySizeMiddleware(cfg.Server.MaxBodyBytes, cfg.Server.BodyLimits))
+
+	// 7. Logging middleware (last to capture all request details)
 	router.Use(LoggingMiddleware())
+	router.Use(SlowRequestMiddleware(parseDurationOrDefault(cfg.Server.SlowRequestThreshold, 0)))
+
+	// 8. Requesting user named by an authenticating proxy
+	if cfg.Server.UserHeader != "" {
+		router.Use(UserHeaderMiddleware(cfg.Server.UserHeader))
+	}
+
+	// 9. Caller-supplied middleware
+	router.Use(middleware...)
 
 	// Add pprof endpoints if debug mode is enabled
 	if cfg.Debug.Enabled && cfg.Debug.PprofEnabled {
@@ -119,8 +135,14 @@ func NewServer(cfg *config.Config, alertsHandler IRoutesRegister, tasksHandler I
 			metricsGroup.GET("/cpu", metricsHandler.GetCPU)
 			metricsGroup.GET("/memory", metricsHandler.GetMemory)
 			metricsGroup.GET("/network", metricsHandler.GetNetwork)
+			metricsGroup.GET("/disk", metricsHandler.GetDisk)
+			metricsGroup.GET("/raid", metricsHandler.GetRAID)
+			metricsGroup.GET("/power", metricsHandler.GetPower)
 			metricsGroup.GET("/process", metricsHandler.GetProcess)
 			metricsGroup.GET("/health", metricsHandler.GetMetricsHealth)
+			metricsGroup.GET("/all", metricsHandler.GetAllMetrics)
+			metricsGroup.GET("/self", metricsHandler.GetSelfMetrics)
+			metricsGroup.GET("/self/api", metricsHandler.GetAPIUsage)
 		}
 
 		// Legacy endpoints for backward compatibility
@@ -138,19 +160,45 @@ func NewServer(cfg *config.Config, alertsHandler IRoutesRegister, tasksHandler I
 	return router
 }
 
-// CreateOptimizedHTTPServer creates an HTTP server with production-optimized settings
-func CreateOptimizedHTTPServer(handler http.Handler, addr string) *http.Server {
+// Fallback values used when the corresponding server setting is empty
+const (
+	defaultReadTimeout       = 15 * time.Second
+	defaultWriteTimeout      = 15 * time.Second
+	defaultIdleTimeout       = 60 * time.Second
+	defaultReadHeaderTimeout = 5 * time.Second
+	defaultMaxHeaderBytes    = 1 << 20 // 1 MB
+)
+
+// parseDurationOrDefault parses a duration string, returning fallback when it is empty or invalid
+func parseDurationOrDefault(value string, fallback time.Duration) time.Duration {
+	if value == "" {
+		return fallback
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		return fallback
+	}
+	return d
+}
+
+// CreateOptimizedHTTPServer creates an HTTP server using the timeouts and limits from the server configuration
+func CreateOptimizedHTTPServer(handler http.Handler, cfg *config.Config) *http.Server {
+	maxHeaderBytes := cfg.Server.MaxHeaderBytes
+	if maxHeaderBytes <= 0 {
+		maxHeaderBytes = defaultMaxHeaderBytes
+	}
+
 	return &http.Server{
-		Addr:    addr,
+		Addr:    fmt.Sprintf(":%d", cfg.Server.Port),
 		Handler: handler,
 
 		// Production timeouts
-		ReadTimeout:       15 * time.Second,
-		WriteTimeout:      15 * time.Second,
-		IdleTimeout:       60 * time.Second,
-		ReadHeaderTimeout: 5 * time.Second,
+		ReadTimeout:       parseDurationOrDefault(cfg.Server.ReadTimeout, defaultReadTimeout),
+		WriteTimeout:      parseDurationOrDefault(cfg.Server.WriteTimeout, defaultWriteTimeout),
+		IdleTimeout:       parseDurationOrDefault(cfg.Server.IdleTimeout, defaultIdleTimeout),
+		ReadHeaderTimeout: parseDurationOrDefault(cfg.Server.ReadHeaderTimeout, defaultReadHeaderTimeout),
 
 		// Optimize for production
-		MaxHeaderBytes: 1 << 20, // 1 MB
+		MaxHeaderBytes: maxHeaderBytes,
 	}
 }
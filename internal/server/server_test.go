@@ -8,6 +8,7 @@ import (
 	"github.com/stretchr/testify/mock"
 
 	"argus/internal/config"
+	"argus/internal/handlers"
 )
 
 // MockRoutesRegister is a mock for the IRoutesRegister interface
@@ -25,19 +26,15 @@ func TestNewServer(t *testing.T) {
 	mockAlertHandler := new(MockRoutesRegister)
 	mockTaskHandler := new(MockRoutesRegister)
 
-	// Create mock handler functions
-	mockCPUHandler := func(c *gin.Context) {}
-	mockMemoryHandler := func(c *gin.Context) {}
-	mockNetworkHandler := func(c *gin.Context) {}
-	mockProcessHandler := func(c *gin.Context) {}
+	// Metrics routes are registered without being served
+	metricsHandler := handlers.NewMetricsHandler(nil)
 
 	// Set up expectations
 	mockAlertHandler.On("RegisterRoutes", mock.Anything).Return()
 	mockTaskHandler.On("RegisterRoutes", mock.Anything).Return()
 
 	// Create a new server
-	server := NewServer(mockCfg, mockAlertHandler, mockTaskHandler,
-		mockCPUHandler, mockMemoryHandler, mockNetworkHandler, mockProcessHandler)
+	server := NewServer(mockCfg, mockAlertHandler, mockTaskHandler, metricsHandler)
 
 	// Assert server is not nil
 	assert.NotNil(t, server)
@@ -0,0 +1,122 @@
+// File: internal/server/stream.go
+// Brief: Process metrics stream for WebSocket subscribers
+// Detailed: Publishes the collected process list to clients subscribed to the "processes" topic, as a full snapshot every few updates and as deltas of added, changed and removed PIDs in between; clients joining mid-stream first receive the latest state.
+// Author: drama.lin@aver.com
+// Date: 2026-10-14
+
+package server
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"argus/internal/metrics"
+)
+
+// TopicProcesses is the ?subscribe= topic of the process stream
+const TopicProcesses = "processes"
+
+// Kinds of process stream frames
+const (
+	ProcessFrameFull  = "full"
+	ProcessFrameDelta = "delta"
+)
+
+// ProcessFrame is one message of the process stream. A full frame lists
+// every process; a delta frame applies to the state after frame Seq-1, so
+// clients that see a gap in Seq should wait for the next full frame.
+type ProcessFrame struct {
+	Type      string                `json:"type"` // Always "processes"
+	Kind      string                `json:"kind"` // "full" or "delta"
+	Seq       uint64                `json:"seq"`
+	UpdatedAt time.Time             `json:"updated_at"`
+	Processes []metrics.ProcessInfo `json:"processes,omitempty"` // Full frames only
+	metrics.ProcessDelta
+}
+
+// ProcessStreamConfig holds configuration for the process stream
+type ProcessStreamConfig struct {
+	Interval      time.Duration // How often the collector cache is checked for new data
+	KeyframeEvery int           // Frames between full snapshots (1 sends only full frames)
+}
+
+// DefaultProcessStreamConfig returns default configuration for the process stream
+func DefaultProcessStreamConfig() ProcessStreamConfig {
+	return ProcessStreamConfig{
+		Interval:      time.Second,
+		KeyframeEvery: 30,
+	}
+}
+
+// ProcessStream publishes process metrics to WebSocket subscribers
+type ProcessStream struct {
+	hub    *Hub
+	source func() *metrics.ProcessMetrics
+	config ProcessStreamConfig
+
+	seq       uint64
+	last      []metrics.ProcessInfo
+	updatedAt time.Time
+}
+
+// NewProcessStream creates a stream publishing the snapshots returned by
+// source, e.g. Collector.GetProcessMetrics
+func NewProcessStream(hub *Hub, source func() *metrics.ProcessMetrics, config ProcessStreamConfig) *ProcessStream {
+	defaults := DefaultProcessStreamConfig()
+	if config.Interval <= 0 {
+		config.Interval = defaults.Interval
+	}
+	if config.KeyframeEvery <= 0 {
+		config.KeyframeEvery = defaults.KeyframeEvery
+	}
+	return &ProcessStream{hub: hub, source: source, config: config}
+}
+
+// Start publishes new snapshots until ctx is cancelled
+func (s *ProcessStream) Start(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(s.config.Interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := s.publish(); err != nil {
+					slog.Error("Failed to publish process stream frame", "error", err)
+				}
+			}
+		}
+	}()
+}
+
+// publish sends the latest snapshot if the collector has refreshed it
+func (s *ProcessStream) publish() error {
+	snapshot := s.source()
+	if snapshot == nil || !snapshot.UpdatedAt.After(s.updatedAt) {
+		return nil
+	}
+
+	s.seq++
+	full := &ProcessFrame{
+		Type:      TopicProcesses,
+		Kind:      ProcessFrameFull,
+		Seq:       s.seq,
+		UpdatedAt: snapshot.UpdatedAt,
+		Processes: snapshot.Processes,
+	}
+	frame := full
+	if s.last != nil && (s.seq-1)%uint64(s.config.KeyframeEvery) != 0 {
+		frame = &ProcessFrame{
+			Type:         TopicProcesses,
+			Kind:         ProcessFrameDelta,
+			Seq:          s.seq,
+			UpdatedAt:    snapshot.UpdatedAt,
+			ProcessDelta: metrics.DiffProcesses(s.last, snapshot.Processes),
+		}
+	}
+	s.last = snapshot.Processes
+	s.updatedAt = snapshot.UpdatedAt
+	return s.hub.PublishTopic(TopicProcesses, frame, full)
+}
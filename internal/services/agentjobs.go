@@ -0,0 +1,353 @@
+// File: internal/services/agentjobs.go
+// Brief: Execution of scheduled tasks on remote agents
+// Detailed: Queues a job for every reporting agent a task targets, by host ID or label selector, instead of running the task on the server. Agents claim their jobs along with their heartbeats, run them with their local runners and report the executions back, which are recorded in the central execution store attributed to the host. Jobs an agent does not claim or report in time are recorded as failed runs. The queue is kept in memory, so jobs outstanding when the server restarts are lost.
+// Author: drama.lin@aver.com
+// Date: 2026-10-14
+
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"argus/internal/clock"
+	"argus/internal/models"
+)
+
+// Execution metadata keys attributing a run to the agent that executed it
+const (
+	HostMetadata     = "host"
+	HostnameMetadata = "hostname"
+	AgentJobMetadata = "agent_job"
+)
+
+// Agent job errors
+var (
+	ErrNoAgents         = errors.New("no reporting agent runs tasks for")
+	ErrAgentJobNotFound = errors.New("agent job not found")
+	ErrAgentsDisabled   = errors.New("tasks targeting agents need the host inventory enabled")
+)
+
+// queuedJob is a job waiting for its agent to claim it or report its result
+type queuedJob struct {
+	models.AgentJob
+	timeout time.Duration
+}
+
+// AgentJobs queues the runs of tasks targeting agents until the agents claim
+// them and report their results
+type AgentJobs struct {
+	registry *HostRegistry
+	clock    clock.Clock
+
+	mu   sync.Mutex
+	jobs map[string]*queuedJob
+}
+
+// NewAgentJobs creates an empty queue for the agents in registry
+func NewAgentJobs(registry *HostRegistry) *AgentJobs {
+	return &AgentJobs{
+		registry: registry,
+		clock:    registry.clock,
+		jobs:     make(map[string]*queuedJob),
+	}
+}
+
+// Dispatch queues a run of task, with metadata to record with its execution,
+// for every reporting host its agent target selects whose agent runs tasks.
+// Hosts with a job of the task still outstanding are skipped, so runs do not
+// pile up on a slow or unreachable agent.
+func (q *AgentJobs) Dispatch(task *models.TaskConfig, timeout time.Duration, metadata map[string]string) ([]models.AgentJob, error) {
+	now := q.clock.Now().UTC()
+	var jobs []models.AgentJob
+	matched := 0
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for _, host := range q.registry.List() {
+		if host.Status != models.HostUp || !host.Tasks || !task.Agents.Matches(host) {
+			continue
+		}
+		matched++
+		if previous := q.outstanding(task.ID, host.ID); previous != nil {
+			slog.Warn("Skipping agent run while the previous one is outstanding",
+				"task_id", task.ID, "host", host.ID, "job", previous.ID, "created_at", previous.CreatedAt)
+			continue
+		}
+		job := &queuedJob{
+			AgentJob: models.AgentJob{
+				ID:        models.GenerateID(),
+				Host:      host.ID,
+				Task:      *task,
+				Timeout:   timeout.String(),
+				CreatedAt: now,
+				Metadata:  map[string]string{HostnameMetadata: host.Hostname},
+			},
+			timeout: timeout,
+		}
+		for k, v := range metadata {
+			job.Metadata[k] = v
+		}
+		q.jobs[job.ID] = job
+		jobs = append(jobs, job.AgentJob)
+	}
+	if matched == 0 {
+		return nil, fmt.Errorf("%w %s", ErrNoAgents, task.Agents)
+	}
+	return jobs, nil
+}
+
+// outstanding returns the queued job of the task for a host, if any. The
+// caller must hold q.mu.
+func (q *AgentJobs) outstanding(taskID, hostID string) *queuedJob {
+	for _, job := range q.jobs {
+		if job.Task.ID == taskID && job.Host == hostID {
+			return job
+		}
+	}
+	return nil
+}
+
+// Claim hands the jobs queued for a host to its agent
+func (q *AgentJobs) Claim(hostID string) []models.AgentJob {
+	now := q.clock.Now().UTC()
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	jobs := []models.AgentJob{}
+	for _, job := range q.jobs {
+		if job.Host == hostID && job.ClaimedAt == nil {
+			claimed := now
+			job.ClaimedAt = &claimed
+			jobs = append(jobs, job.AgentJob)
+		}
+	}
+	sortJobs(jobs)
+	return jobs
+}
+
+// Complete removes a job of host whose agent reported its result
+func (q *AgentJobs) Complete(hostID, jobID string) (models.AgentJob, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	job, ok := q.jobs[jobID]
+	if !ok || job.Host != hostID {
+		return models.AgentJob{}, ErrAgentJobNotFound
+	}
+	delete(q.jobs, jobID)
+	return job.AgentJob, nil
+}
+
+// Expire removes and returns the jobs not claimed within the registry's stale
+// timeout, or not reported within their timeout plus the stale timeout after
+// being claimed
+func (q *AgentJobs) Expire(now time.Time) []models.AgentJob {
+	grace := q.registry.StaleAfter()
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	var expired []models.AgentJob
+	for id, job := range q.jobs {
+		deadline := job.CreatedAt.Add(grace)
+		if job.ClaimedAt != nil {
+			deadline = job.ClaimedAt.Add(job.timeout + grace)
+		}
+		if now.After(deadline) {
+			delete(q.jobs, id)
+			expired = append(expired, job.AgentJob)
+		}
+	}
+	sortJobs(expired)
+	return expired
+}
+
+// List returns the outstanding jobs, oldest first
+func (q *AgentJobs) List() []models.AgentJob {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	jobs := make([]models.AgentJob, 0, len(q.jobs))
+	for _, job := range q.jobs {
+		jobs = append(jobs, job.AgentJob)
+	}
+	sortJobs(jobs)
+	return jobs
+}
+
+// sortJobs orders jobs by creation time and ID
+func sortJobs(jobs []models.AgentJob) {
+	sort.Slice(jobs, func(i, j int) bool {
+		if !jobs[i].CreatedAt.Equal(jobs[j].CreatedAt) {
+			return jobs[i].CreatedAt.Before(jobs[j].CreatedAt)
+		}
+		return jobs[i].ID < jobs[j].ID
+	})
+}
+
+// SetHostRegistry lets tasks targeting agents run on the hosts in registry
+func (s *TaskScheduler) SetHostRegistry(registry *HostRegistry) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.agentJobs = NewAgentJobs(registry)
+}
+
+// agentQueue returns the queue of agent jobs, or nil without a host registry
+func (s *TaskScheduler) agentQueue() *AgentJobs {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	return s.agentJobs
+}
+
+// dispatchTask queues a run of a task targeting agents
+func (s *TaskScheduler) dispatchTask(task *models.TaskConfig, metadata map[string]string) ([]models.AgentJob, error) {
+	queue := s.agentQueue()
+	if queue == nil {
+		return nil, ErrAgentsDisabled
+	}
+	jobs, err := queue.Dispatch(task, s.config.TaskTimeout, metadata)
+	if err != nil {
+		return nil, err
+	}
+	hosts := make([]string, len(jobs))
+	for i, job := range jobs {
+		hosts[i] = job.Host
+	}
+	slog.Info("Dispatched task to agents", "task_id", task.ID, "task_name", task.Name, "hosts", hosts)
+	return jobs, nil
+}
+
+// runOnAgents dispatches a scheduled run of a task targeting agents. A run no
+// agent can take is recorded as failed, so it shows in the task's history.
+func (s *TaskScheduler) runOnAgents(task *models.TaskConfig, metadata map[string]string) error {
+	if _, err := s.dispatchTask(task, metadata); err != nil {
+		slog.Warn("Failed to dispatch task to agents", "task_id", task.ID, "task_name", task.Name, "error", err)
+		execution := models.NewTaskExecution(task.ID)
+		execution.TaskName = task.Name
+		execution.TaskType = task.Type
+		execution.Status = models.StatusFailed
+		execution.EndTime = execution.StartTime
+		execution.Error = err.Error()
+		execution.Metadata = metadata
+		if err := s.repository.RecordExecution(s.ctx, execution); err != nil {
+			return fmt.Errorf("failed to record task execution: %w", err)
+		}
+	}
+	return nil
+}
+
+// dispatchedExecution describes a run started on agents, whose results are
+// recorded as the agents report them
+func dispatchedExecution(task *models.TaskConfig, jobs []models.AgentJob) *models.TaskExecution {
+	execution := models.NewTaskExecution(task.ID)
+	execution.TaskName = task.Name
+	execution.TaskType = task.Type
+	hosts := make([]string, len(jobs))
+	ids := make([]string, len(jobs))
+	for i, job := range jobs {
+		hosts[i], ids[i] = job.Host, job.ID
+	}
+	if len(jobs) == 0 {
+		execution.Output = "Every targeted agent is still running the task"
+	} else {
+		execution.Output = "Dispatched to the agents on " + strings.Join(hosts, ", ")
+	}
+	execution.Metadata = map[string]string{AgentJobMetadata: strings.Join(ids, ",")}
+	return execution
+}
+
+// ClaimAgentJobs hands the jobs queued for a host to its agent
+func (s *TaskScheduler) ClaimAgentJobs(hostID string) []models.AgentJob {
+	queue := s.agentQueue()
+	if queue == nil {
+		return []models.AgentJob{}
+	}
+	return queue.Claim(hostID)
+}
+
+// AgentJobs returns the jobs waiting for agents to claim them or report their
+// results
+func (s *TaskScheduler) AgentJobs() []models.AgentJob {
+	queue := s.agentQueue()
+	if queue == nil {
+		return []models.AgentJob{}
+	}
+	return queue.List()
+}
+
+// CompleteAgentJob records the execution an agent reported for one of its
+// jobs, attributed to its host
+func (s *TaskScheduler) CompleteAgentJob(hostID, jobID string, execution *models.TaskExecution) error {
+	queue := s.agentQueue()
+	if queue == nil {
+		return ErrAgentJobNotFound
+	}
+	job, err := queue.Complete(hostID, jobID)
+	if err != nil {
+		return err
+	}
+	execution.TaskID = job.Task.ID
+	execution.TaskName = job.Task.Name
+	execution.TaskType = job.Task.Type
+	if execution.ExecutionID == "" {
+		execution.ExecutionID = models.GenerateID()
+	}
+	if execution.Status != models.StatusCompleted && execution.Status != models.StatusFailed {
+		if execution.Error == "" {
+			execution.Error = fmt.Sprintf("agent reported status %q", execution.Status)
+		}
+		execution.Status = models.StatusFailed
+	}
+	if execution.EndTime.IsZero() {
+		execution.EndTime = s.clock.Now().UTC()
+	}
+	s.attribute(execution, job)
+	s.redactExecution(execution)
+	s.compareCleanup(execution)
+	if err := s.repository.RecordExecution(s.ctx, execution); err != nil {
+		return fmt.Errorf("failed to record task execution: %w", err)
+	}
+	slog.Info("Agent reported task execution",
+		"task_id", job.Task.ID, "host", hostID, "job", jobID, "status", execution.Status)
+	return nil
+}
+
+// attribute adds the host and job of an agent run to its execution metadata
+func (s *TaskScheduler) attribute(execution *models.TaskExecution, job models.AgentJob) {
+	if execution.Metadata == nil {
+		execution.Metadata = make(map[string]string)
+	}
+	for k, v := range job.Metadata {
+		execution.Metadata[k] = v
+	}
+	execution.Metadata[HostMetadata] = job.Host
+	execution.Metadata[AgentJobMetadata] = job.ID
+}
+
+// expireAgentJobs records the jobs agents did not claim or report in time as
+// failed runs
+func (s *TaskScheduler) expireAgentJobs(now time.Time) {
+	queue := s.agentQueue()
+	if queue == nil {
+		return
+	}
+	for _, job := range queue.Expire(now) {
+		execution := models.NewTaskExecution(job.Task.ID)
+		execution.TaskName = job.Task.Name
+		execution.TaskType = job.Task.Type
+		execution.Status = models.StatusFailed
+		execution.StartTime = job.CreatedAt
+		execution.EndTime = now.UTC()
+		if job.ClaimedAt == nil {
+			execution.Error = fmt.Sprintf("agent on host %s did not claim the run", job.Host)
+		} else {
+			execution.Error = fmt.Sprintf("agent on host %s did not report a result within %s", job.Host, job.Timeout)
+		}
+		s.attribute(execution, job)
+		slog.Warn("Agent run expired", "task_id", job.Task.ID, "host", job.Host, "job", job.ID, "error", execution.Error)
+		if err := s.repository.RecordExecution(s.ctx, execution); err != nil {
+			slog.Error("Failed to record expired agent run", "task_id", job.Task.ID, "job", job.ID, "error", err)
+		}
+	}
+}
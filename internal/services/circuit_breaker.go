@@ -0,0 +1,150 @@
+// File: internal/services/circuit_breaker.go
+// Brief: Per-channel circuit breaker for notification delivery
+// Detailed: Tracks consecutive delivery failures for each notification channel, opening the circuit after a threshold and probing it half-open after a cool-down.
+// Author: drama.lin@aver.com
+// Date: 2026-10-14
+
+package services
+
+import (
+	"sync"
+	"time"
+)
+
+const (
+	DefaultCircuitFailureThreshold = 5
+	DefaultCircuitOpenTimeout      = 1 * time.Minute
+)
+
+// CircuitState is the delivery state of a notification channel
+type CircuitState string
+
+const (
+	CircuitClosed   CircuitState = "closed"    // Delivering normally
+	CircuitOpen     CircuitState = "open"      // Failing; notifications go to the dead-letter queue
+	CircuitHalfOpen CircuitState = "half-open" // Cool-down elapsed; one probe delivery is allowed
+)
+
+// CircuitStatus is a snapshot of a channel's circuit breaker
+type CircuitStatus struct {
+	Channel             string       `json:"channel"`
+	State               CircuitState `json:"state"`
+	ConsecutiveFailures int          `json:"consecutive_failures"`
+	LastError           string       `json:"last_error,omitempty"`
+	OpenedAt            *time.Time   `json:"opened_at,omitempty"`
+}
+
+// circuitBreaker guards a single channel. onChange is called outside the lock
+// whenever the state changes.
+type circuitBreaker struct {
+	name        string
+	threshold   int
+	openTimeout time.Duration
+	onChange    func(name string, from, to CircuitState, lastErr string)
+	now         func() time.Time
+
+	mu        sync.Mutex
+	state     CircuitState
+	failures  int
+	lastError string
+	openedAt  time.Time
+	probing   bool
+	probeAt   time.Time
+}
+
+func newCircuitBreaker(name string, threshold int, openTimeout time.Duration, onChange func(string, CircuitState, CircuitState, string)) *circuitBreaker {
+	if threshold <= 0 {
+		threshold = DefaultCircuitFailureThreshold
+	}
+	if openTimeout <= 0 {
+		openTimeout = DefaultCircuitOpenTimeout
+	}
+	return &circuitBreaker{
+		name:        name,
+		threshold:   threshold,
+		openTimeout: openTimeout,
+		onChange:    onChange,
+		now:         time.Now,
+		state:       CircuitClosed,
+	}
+}
+
+// allow reports whether a delivery may be attempted. Once the open timeout has
+// elapsed a single probe is let through in the half-open state.
+func (b *circuitBreaker) allow() bool {
+	b.mu.Lock()
+	var changed bool
+	allowed := true
+	switch b.state {
+	case CircuitOpen:
+		if b.now().Sub(b.openedAt) < b.openTimeout {
+			allowed = false
+			break
+		}
+		b.state = CircuitHalfOpen
+		b.probing, b.probeAt = true, b.now()
+		changed = true
+	case CircuitHalfOpen:
+		// A probe whose outcome never arrived is abandoned after another timeout
+		if b.probing && b.now().Sub(b.probeAt) < b.openTimeout {
+			allowed = false
+		} else {
+			b.probing, b.probeAt = true, b.now()
+		}
+	}
+	lastErr := b.lastError
+	b.mu.Unlock()
+
+	if changed {
+		b.notify(CircuitOpen, CircuitHalfOpen, lastErr)
+	}
+	return allowed
+}
+
+// record updates the breaker with the outcome of a delivery attempt
+func (b *circuitBreaker) record(err error) {
+	b.mu.Lock()
+	from := b.state
+	b.probing = false
+	if err == nil {
+		b.failures = 0
+		b.lastError = ""
+		b.state = CircuitClosed
+	} else {
+		b.failures++
+		b.lastError = err.Error()
+		if b.state == CircuitHalfOpen || b.failures >= b.threshold {
+			b.state = CircuitOpen
+			b.openedAt = b.now()
+		}
+	}
+	to, lastErr := b.state, b.lastError
+	b.mu.Unlock()
+
+	if from != to {
+		b.notify(from, to, lastErr)
+	}
+}
+
+func (b *circuitBreaker) notify(from, to CircuitState, lastErr string) {
+	if b.onChange != nil {
+		b.onChange(b.name, from, to, lastErr)
+	}
+}
+
+// status returns a snapshot of the breaker
+func (b *circuitBreaker) status() CircuitStatus {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	st := CircuitStatus{
+		Channel:             b.name,
+		State:               b.state,
+		ConsecutiveFailures: b.failures,
+		LastError:           b.lastError,
+	}
+	if b.state != CircuitClosed {
+		openedAt := b.openedAt
+		st.OpenedAt = &openedAt
+	}
+	return st
+}
@@ -0,0 +1,76 @@
+package services
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"argus/internal/clock"
+)
+
+func TestCircuitBreaker_OpenHalfOpen(t *testing.T) {
+	clk := clock.NewFake(time.Now())
+	var changes []CircuitState
+	breaker := newCircuitBreaker("email", 2, time.Minute, func(_ string, _, to CircuitState, _ string) {
+		changes = append(changes, to)
+	})
+	breaker.now = clk.Now
+	errSMTP := errors.New("smtp unavailable")
+
+	// Failures below the threshold keep the circuit closed
+	assert.True(t, breaker.allow())
+	breaker.record(errSMTP)
+	assert.Equal(t, CircuitClosed, breaker.status().State)
+
+	// Reaching the threshold opens it and stops deliveries
+	assert.True(t, breaker.allow())
+	breaker.record(errSMTP)
+	status := breaker.status()
+	assert.Equal(t, CircuitOpen, status.State)
+	assert.Equal(t, 2, status.ConsecutiveFailures)
+	assert.Equal(t, "smtp unavailable", status.LastError)
+	assert.NotNil(t, status.OpenedAt)
+	assert.False(t, breaker.allow())
+
+	// After the open timeout a single probe is let through
+	clk.Advance(time.Minute)
+	assert.True(t, breaker.allow())
+	assert.Equal(t, CircuitHalfOpen, breaker.status().State)
+	assert.False(t, breaker.allow(), "only one probe at a time")
+
+	// A failed probe opens the circuit again straight away
+	breaker.record(errSMTP)
+	assert.Equal(t, CircuitOpen, breaker.status().State)
+	assert.False(t, breaker.allow())
+
+	// A successful probe closes it
+	clk.Advance(time.Minute)
+	assert.True(t, breaker.allow())
+	breaker.record(nil)
+	status = breaker.status()
+	assert.Equal(t, CircuitClosed, status.State)
+	assert.Zero(t, status.ConsecutiveFailures)
+	assert.Nil(t, status.OpenedAt)
+	assert.True(t, breaker.allow())
+
+	assert.Equal(t, []CircuitState{CircuitOpen, CircuitHalfOpen, CircuitOpen, CircuitHalfOpen, CircuitClosed}, changes)
+}
+
+func TestCircuitBreaker_AbandonedProbe(t *testing.T) {
+	clk := clock.NewFake(time.Now())
+	breaker := newCircuitBreaker("webhook", 1, time.Minute, nil)
+	breaker.now = clk.Now
+
+	breaker.record(errors.New("connection refused"))
+	clk.Advance(time.Minute)
+	assert.True(t, breaker.allow())
+
+	// A probe whose outcome never arrives is given up after another timeout
+	clk.Advance(30 * time.Second)
+	assert.False(t, breaker.allow())
+	clk.Advance(30 * time.Second)
+	assert.True(t, breaker.allow())
+	assert.Equal(t, CircuitHalfOpen, breaker.status().State)
+}
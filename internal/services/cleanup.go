@@ -0,0 +1,91 @@
+// File: internal/services/cleanup.go
+// Brief: Comparison of cleanup and log rotation runs with their previous run
+// Detailed: When a run reports the space it reclaimed, the scheduler compares it with the previous real run of the task on the same host and records the change and the growth rate in the execution metadata. CleanupTrend turns a task's executions into the series the dashboard charts. Dry runs and failed runs removed nothing, so no run is compared with them and the trend leaves them out.
+// Author: drama.lin@aver.com
+// Date: 2026-10-14
+
+package services
+
+import (
+	"log/slog"
+	"maps"
+	"sort"
+	"time"
+
+	"argus/internal/models"
+)
+
+// cleanupHistory is how many of a task's latest executions are searched for
+// the previous run
+const cleanupHistory = 50
+
+// CleanupPoint is a run in the cleanup trend of a task
+type CleanupPoint struct {
+	ExecutionID string    `json:"execution_id"`
+	StartTime   time.Time `json:"start_time"`
+	Host        string    `json:"host,omitempty"` // Agent host, empty for runs on the server
+	models.CleanupStats
+	Comparison *models.CleanupComparison `json:"comparison,omitempty"` // Nil for the first run on the host
+}
+
+// CleanupTrend returns the real runs among executions that reported reclaimed
+// space, oldest first, each compared with the previous run on its host
+func CleanupTrend(executions []*models.TaskExecution) []CleanupPoint {
+	runs := make([]*models.TaskExecution, 0, len(executions))
+	for _, e := range executions {
+		if reclaimed(e) {
+			runs = append(runs, e)
+		}
+	}
+	sort.Slice(runs, func(i, j int) bool { return runs[i].StartTime.Before(runs[j].StartTime) })
+
+	points := make([]CleanupPoint, 0, len(runs))
+	previous := make(map[string]*models.TaskExecution)
+	for _, e := range runs {
+		stats, _ := models.ParseCleanupStats(e.Metadata)
+		point := CleanupPoint{ExecutionID: e.ExecutionID, StartTime: e.StartTime, Host: e.Metadata[HostMetadata], CleanupStats: stats}
+		if before, ok := previous[point.Host]; ok {
+			comparison, _ := models.CompareCleanup(e, before)
+			point.Comparison = &comparison
+		}
+		previous[point.Host] = e
+		points = append(points, point)
+	}
+	return points
+}
+
+// compareCleanup records in the metadata of a run reporting reclaimed space
+// how it compares with the previous real run of its task on the same host
+func (s *TaskScheduler) compareCleanup(execution *models.TaskExecution) {
+	if execution.Status != models.StatusCompleted {
+		return
+	}
+	if _, ok := models.ParseCleanupStats(execution.Metadata); !ok {
+		return
+	}
+	executions, err := s.repository.GetTaskExecutions(s.ctx, execution.TaskID, cleanupHistory)
+	if err != nil {
+		slog.Warn("Failed to get the previous run for comparison", "task_id", execution.TaskID, "error", err)
+		return
+	}
+	// Executions are newest first
+	for _, e := range executions {
+		if e.ExecutionID == execution.ExecutionID || !e.StartTime.Before(execution.StartTime) ||
+			e.Metadata[HostMetadata] != execution.Metadata[HostMetadata] || !reclaimed(e) {
+			continue
+		}
+		comparison, _ := models.CompareCleanup(execution, e)
+		maps.Copy(execution.Metadata, comparison.Metadata())
+		return
+	}
+}
+
+// reclaimed reports whether an execution is a completed real run reporting
+// reclaimed space
+func reclaimed(e *models.TaskExecution) bool {
+	if e.Status != models.StatusCompleted || e.Metadata[DryRunMetadata] == "true" {
+		return false
+	}
+	_, ok := models.ParseCleanupStats(e.Metadata)
+	return ok
+}
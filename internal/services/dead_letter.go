@@ -0,0 +1,129 @@
+// File: internal/services/dead_letter.go
+// Brief: Dead-letter queue for undeliverable notifications
+// Detailed: Keeps a bounded, in-memory list of notifications that failed or were rejected by an open circuit so they can be inspected and retried.
+// Author: drama.lin@aver.com
+// Date: 2026-10-14
+
+package services
+
+import (
+	"sync"
+	"time"
+
+	"argus/internal/models"
+)
+
+// DefaultDeadLetterSize is the number of undeliverable notifications kept
+const DefaultDeadLetterSize = 500
+
+// DeadLetter is a notification that could not be delivered
+type DeadLetter struct {
+	ID        string                  `json:"id"`
+	Channel   models.NotificationType `json:"channel"`
+	AlertID   string                  `json:"alert_id"`
+	AlertName string                  `json:"alert_name"`
+	State     models.AlertState       `json:"state"`
+	Subject   string                  `json:"subject"`
+	Body      string                  `json:"body"`
+	Error     string                  `json:"error"`
+	Attempts  int                     `json:"attempts"`
+	Timestamp time.Time               `json:"timestamp"`
+
+	event models.AlertEvent // Original event, kept for retries
+}
+
+// DeadLetterQueue is a bounded FIFO of undeliverable notifications; the
+// oldest entries are dropped once it is full.
+type DeadLetterQueue struct {
+	mu      sync.RWMutex
+	entries []DeadLetter
+	maxSize int
+}
+
+// NewDeadLetterQueue creates a queue holding at most maxSize entries
+func NewDeadLetterQueue(maxSize int) *DeadLetterQueue {
+	if maxSize <= 0 {
+		maxSize = DefaultDeadLetterSize
+	}
+	return &DeadLetterQueue{maxSize: maxSize}
+}
+
+// Add stores an undeliverable notification and returns its ID
+func (q *DeadLetterQueue) Add(channel models.NotificationType, event models.AlertEvent, subject, body string, cause error) string {
+	entry := DeadLetter{
+		ID:        generateID(),
+		Channel:   channel,
+		AlertID:   event.AlertID,
+		State:     event.NewState,
+		Subject:   subject,
+		Body:      body,
+		Attempts:  1,
+		Timestamp: time.Now().UTC(),
+		event:     event,
+	}
+	if event.Alert != nil {
+		entry.AlertName = event.Alert.Name
+	}
+	if cause != nil {
+		entry.Error = cause.Error()
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if len(q.entries) >= q.maxSize {
+		q.entries = q.entries[1:]
+	}
+	q.entries = append(q.entries, entry)
+	return entry.ID
+}
+
+// List returns a copy of all entries, oldest first
+func (q *DeadLetterQueue) List() []DeadLetter {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+	result := make([]DeadLetter, len(q.entries))
+	copy(result, q.entries)
+	return result
+}
+
+// Len returns the number of queued entries
+func (q *DeadLetterQueue) Len() int {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+	return len(q.entries)
+}
+
+// Take removes and returns the entry with the given ID
+func (q *DeadLetterQueue) Take(id string) (DeadLetter, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for i, entry := range q.entries {
+		if entry.ID == id {
+			q.entries = append(q.entries[:i], q.entries[i+1:]...)
+			return entry, true
+		}
+	}
+	return DeadLetter{}, false
+}
+
+// requeue puts a failed retry back, keeping its ID and counting the attempt
+func (q *DeadLetterQueue) requeue(entry DeadLetter, cause error) {
+	entry.Attempts++
+	entry.Timestamp = time.Now().UTC()
+	if cause != nil {
+		entry.Error = cause.Error()
+	}
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if len(q.entries) >= q.maxSize {
+		q.entries = q.entries[1:]
+	}
+	q.entries = append(q.entries, entry)
+}
+
+// Clear removes all entries
+func (q *DeadLetterQueue) Clear() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.entries = nil
+}
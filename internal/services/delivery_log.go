@@ -0,0 +1,101 @@
+// File: internal/services/delivery_log.go
+// Brief: Log of notification delivery attempts
+// Detailed: Keeps a bounded, in-memory record of every notification delivery outcome per channel, successful or not, so the notifications sent for an alert can be reviewed after an incident.
+// Author: drama.lin@aver.com
+// Date: 2026-10-14
+
+package services
+
+import (
+	"sync"
+	"time"
+
+	"argus/internal/models"
+)
+
+// DefaultDeliveryLogSize is the number of delivery attempts kept
+const DefaultDeliveryLogSize = 1000
+
+// Delivery outcomes
+const (
+	DeliverySent   = "sent"
+	DeliveryFailed = "failed"
+)
+
+// Delivery is the outcome of one notification delivery attempt
+type Delivery struct {
+	Channel   models.NotificationType `json:"channel"`
+	AlertID   string                  `json:"alert_id"`
+	AlertName string                  `json:"alert_name"`
+	State     models.AlertState       `json:"state"`
+	Subject   string                  `json:"subject"`
+	Status    string                  `json:"status"`
+	Error     string                  `json:"error,omitempty"`
+	Retry     bool                    `json:"retry,omitempty"` // A retried dead letter
+	Timestamp time.Time               `json:"timestamp"`
+}
+
+// DeliveryLog is a bounded FIFO of delivery attempts; the oldest entries are
+// dropped once it is full.
+type DeliveryLog struct {
+	mu      sync.RWMutex
+	entries []Delivery
+	maxSize int
+}
+
+// NewDeliveryLog creates a log holding at most maxSize entries
+func NewDeliveryLog(maxSize int) *DeliveryLog {
+	if maxSize <= 0 {
+		maxSize = DefaultDeliveryLogSize
+	}
+	return &DeliveryLog{maxSize: maxSize}
+}
+
+// Record stores the outcome of delivering event through channel
+func (l *DeliveryLog) Record(channel models.NotificationType, event models.AlertEvent, subject string, retry bool, cause error) {
+	entry := Delivery{
+		Channel:   channel,
+		AlertID:   event.AlertID,
+		State:     event.NewState,
+		Subject:   subject,
+		Status:    DeliverySent,
+		Retry:     retry,
+		Timestamp: time.Now().UTC(),
+	}
+	if event.Alert != nil {
+		entry.AlertName = event.Alert.Name
+	}
+	if cause != nil {
+		entry.Status, entry.Error = DeliveryFailed, cause.Error()
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if len(l.entries) >= l.maxSize {
+		l.entries = l.entries[1:]
+	}
+	l.entries = append(l.entries, entry)
+}
+
+// List returns a copy of all entries, oldest first
+func (l *DeliveryLog) List() []Delivery {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	result := make([]Delivery, len(l.entries))
+	copy(result, l.entries)
+	return result
+}
+
+// ForAlert returns the entries of an alert within [from, to], oldest first.
+// A zero to is unbounded.
+func (l *DeliveryLog) ForAlert(alertID string, from, to time.Time) []Delivery {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	result := []Delivery{}
+	for _, entry := range l.entries {
+		if entry.AlertID == alertID && !entry.Timestamp.Before(from) && (to.IsZero() || !entry.Timestamp.After(to)) {
+			result = append(result, entry)
+		}
+	}
+	return result
+}
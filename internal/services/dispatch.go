@@ -0,0 +1,142 @@
+// File: internal/services/dispatch.go
+// Brief: Per-channel notification dispatch queues
+// Detailed: Hands the notifications of each channel to its own bounded queue and worker goroutines, which render, check the circuit breaker and send them. A slow channel (e.g. an SMTP server timing out) only delays its own deliveries. Notifications discarded by a full queue are dead-lettered so they can be retried.
+// Author: drama.lin@aver.com
+// Date: 2026-10-14
+
+package services
+
+import (
+	"log/slog"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"argus/internal/models"
+)
+
+// Dispatch defaults
+const (
+	DefaultDispatchWorkers   = 2   // Workers per channel
+	DefaultDispatchQueueSize = 100 // Notifications waiting per channel
+)
+
+// dispatchQueue holds the notifications waiting for one channel and the
+// workers delivering them
+type dispatchQueue struct {
+	typ      models.NotificationType
+	channel  NotificationChannel
+	jobs     chan models.AlertEvent
+	overflow OverflowConfig
+	dropped  atomic.Uint64
+	workers  sync.WaitGroup
+}
+
+// startDispatch starts the workers of a channel's queue
+func (n *Notifier) startDispatch(typ models.NotificationType, channel NotificationChannel) *dispatchQueue {
+	size := n.config.DispatchQueueSize
+	if size <= 0 {
+		size = DefaultDispatchQueueSize
+	}
+	q := &dispatchQueue{
+		typ:      typ,
+		channel:  channel,
+		jobs:     make(chan models.AlertEvent, size),
+		overflow: n.config.DispatchQueueOverflow,
+	}
+	for i := 0; i < n.config.DispatchWorkers; i++ {
+		q.workers.Add(1)
+		go func() {
+			defer q.workers.Done()
+			for event := range q.jobs {
+				n.deliver(q.typ, q.channel, event)
+			}
+		}()
+	}
+	return q
+}
+
+// enqueue hands event to the channel's workers. A notification discarded by
+// the full queue is rendered and dead-lettered. The caller holds n.mu.
+func (n *Notifier) enqueue(q *dispatchQueue, event models.AlertEvent) {
+	if offer(q.jobs, event, q.overflow, &q.dropped) {
+		return
+	}
+	subject, body, _, err := n.prepare(q.typ, q.channel, event)
+	if err != nil {
+		slog.Error("Failed to render notification template", "type", q.typ, "error", err)
+		return
+	}
+	n.recordDelivery(q.typ, event, subject, body, ErrQueueFull)
+}
+
+// stats reports the fill level and drop count of the queue
+func (q *dispatchQueue) stats() QueueStats {
+	return QueueStats{
+		Name:     "dispatch_" + strings.ReplaceAll(string(q.typ), "-", "_"),
+		Policy:   q.overflow.Policy,
+		Capacity: cap(q.jobs),
+		Length:   len(q.jobs),
+		Dropped:  q.dropped.Load(),
+	}
+}
+
+// stop lets the workers deliver what is queued and waits for them
+func (q *dispatchQueue) stop() {
+	close(q.jobs)
+	q.workers.Wait()
+}
+
+// deliver renders and sends event through one channel from a dispatch worker
+func (n *Notifier) deliver(typ models.NotificationType, channel NotificationChannel, event models.AlertEvent) {
+	n.mu.RLock()
+	subject, body, localized, err := n.prepare(typ, channel, event)
+	breaker := n.breakers[typ]
+	n.mu.RUnlock()
+	if err != nil {
+		slog.Error("Failed to render notification template", "type", typ, "error", err)
+		return
+	}
+	n.send(typ, channel, breaker, event, subject, body, localized)
+}
+
+// prepare renders event for a channel in its timezone, with secrets redacted.
+// Channels that negotiate the language on read also get every locale, unless
+// the channel's own template replaces the localized ones. The caller holds n.mu.
+func (n *Notifier) prepare(typ models.NotificationType, channel NotificationChannel, event models.AlertEvent) (string, string, map[string]models.LocalizedContent, error) {
+	rendered := event
+	rendered.Timestamp = event.Timestamp.In(n.locationFor(typ, event))
+	subject, body, err := n.renderForChannel(typ, rendered)
+	if err != nil {
+		return "", "", nil, err
+	}
+	subject, body = n.config.Redactor.Redact(subject), n.config.Redactor.Redact(body)
+	var localized map[string]models.LocalizedContent
+	if _, ok := channel.(LocalizedNotificationChannel); ok && n.channelTemplate(typ, event) == nil {
+		localized = n.renderAllLocales(rendered)
+	}
+	return subject, body, localized, nil
+}
+
+// send delivers a rendered notification unless the channel's circuit is open,
+// in which case it goes straight to the dead-letter queue
+func (n *Notifier) send(typ models.NotificationType, channel NotificationChannel, breaker *circuitBreaker, event models.AlertEvent, subject, body string, localized map[string]models.LocalizedContent) {
+	if breaker != nil && !breaker.allow() {
+		n.deadLetters.Add(typ, event, subject, body, ErrCircuitOpen)
+		n.deliveries.Record(typ, event, subject, false, ErrCircuitOpen)
+		slog.Warn("Notification channel circuit open, dead-lettered", "type", typ, "alert_id", event.AlertID)
+		return
+	}
+
+	var err error
+	if l, ok := channel.(LocalizedNotificationChannel); ok && localized != nil {
+		err = l.SendLocalized(event, subject, body, localized)
+	} else {
+		err = channel.Send(event, subject, body)
+	}
+	// Async channels report delivery through their result handler; only
+	// enqueue failures are recorded here
+	if _, async := channel.(AsyncNotificationChannel); err != nil || !async {
+		n.recordDelivery(typ, event, subject, body, err)
+	}
+}
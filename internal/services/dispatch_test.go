@@ -0,0 +1,98 @@
+package services
+
+import (
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"argus/internal/models"
+)
+
+// flakyChannel fails its deliveries while fail is set; safe for dispatch workers
+type flakyChannel struct {
+	fail atomic.Bool
+	sent atomic.Int32
+}
+
+var errFlaky = errors.New("relay unavailable")
+
+func (c *flakyChannel) Send(models.AlertEvent, string, string) error {
+	if c.fail.Load() {
+		return errFlaky
+	}
+	c.sent.Add(1)
+	return nil
+}
+
+func (c *flakyChannel) Type() models.NotificationType { return models.NotificationWebhook }
+func (c *flakyChannel) Name() string                  { return "Flaky Channel" }
+
+func TestDispatch_DeadLetterReplay(t *testing.T) {
+	n := NewNotifier(nil)
+	t.Cleanup(n.Stop)
+	channel := &flakyChannel{}
+	channel.fail.Store(true)
+	n.RegisterChannel(channel)
+
+	// The failed delivery from a dispatch worker is dead-lettered
+	event := createTestAlertEvent(t)
+	n.ProcessEvent(event)
+	require.Eventually(t, func() bool { return n.DeadLetters().Len() == 1 }, 5*time.Second, time.Millisecond)
+	entry := n.DeadLetters().List()[0]
+	assert.Equal(t, models.NotificationWebhook, entry.Channel)
+	assert.Equal(t, event.AlertID, entry.AlertID)
+	assert.Equal(t, "Test Alert", entry.AlertName)
+	assert.Equal(t, errFlaky.Error(), entry.Error)
+	assert.Equal(t, 1, entry.Attempts)
+	assert.NotEmpty(t, entry.Subject)
+
+	// A failed replay goes back in the queue under the same ID
+	assert.ErrorIs(t, n.RetryDeadLetter(entry.ID), errFlaky)
+	entries := n.DeadLetters().List()
+	require.Len(t, entries, 1)
+	assert.Equal(t, entry.ID, entries[0].ID)
+	assert.Equal(t, 2, entries[0].Attempts)
+
+	// A successful replay delivers the stored notification and removes it
+	channel.fail.Store(false)
+	require.NoError(t, n.RetryDeadLetter(entry.ID))
+	assert.Equal(t, int32(1), channel.sent.Load())
+	assert.Zero(t, n.DeadLetters().Len())
+	deliveries := n.Deliveries().List()
+	last := deliveries[len(deliveries)-1]
+	assert.Equal(t, DeliverySent, last.Status)
+	assert.True(t, last.Retry)
+
+	assert.ErrorIs(t, n.RetryDeadLetter(entry.ID), ErrDeadLetterNotFound)
+}
+
+func TestDispatch_OpenCircuitDeadLetters(t *testing.T) {
+	config := DefaultConfig()
+	config.CircuitFailureThreshold = 1
+	config.RateLimit = 10
+	n := NewNotifier(config)
+	t.Cleanup(n.Stop)
+	channel := &flakyChannel{}
+	channel.fail.Store(true)
+	n.RegisterChannel(channel)
+
+	n.ProcessEvent(createTestAlertEvent(t))
+	require.Eventually(t, func() bool { return n.GetChannelStatus()[0].State == CircuitOpen }, 5*time.Second, time.Millisecond)
+	require.Equal(t, 1, n.DeadLetters().Len())
+
+	// With the circuit open later notifications skip the channel
+	channel.fail.Store(false)
+	n.ProcessEvent(createTestAlertEvent(t))
+	require.Eventually(t, func() bool { return n.DeadLetters().Len() == 2 }, 5*time.Second, time.Millisecond)
+	assert.Equal(t, ErrCircuitOpen.Error(), n.DeadLetters().List()[1].Error)
+	assert.Zero(t, channel.sent.Load())
+
+	// Replays wait for the circuit too
+	id := n.DeadLetters().List()[0].ID
+	assert.ErrorIs(t, n.RetryDeadLetter(id), ErrCircuitOpen)
+	assert.Equal(t, 2, n.DeadLetters().Len())
+}
@@ -0,0 +1,127 @@
+// File: internal/services/enrollment.go
+// Brief: Agent enrollment and authentication
+// Detailed: Admits agents presenting the shared enrollment token, issues each a random credential of its own (only its hash is stored), authenticates heartbeats with it and revokes agents, which then can neither report nor enroll again until removed.
+// Author: drama.lin@aver.com
+// Date: 2026-10-14
+
+package services
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"argus/internal/database"
+	"argus/internal/models"
+)
+
+// AgentCredentialPrefix starts every issued agent credential
+const AgentCredentialPrefix = "agt_"
+
+// Enrollment errors
+var (
+	ErrInvalidEnrollmentToken = errors.New("invalid enrollment token")
+	ErrAgentUnauthorized      = errors.New("invalid agent credential")
+	ErrAgentRevoked           = errors.New("agent has been revoked")
+)
+
+// AgentEnroller admits agents and authenticates their reports
+type AgentEnroller struct {
+	store *database.AgentStore
+	token string
+	now   func() time.Time
+}
+
+// NewAgentEnroller creates an enroller admitting agents presenting token
+func NewAgentEnroller(store *database.AgentStore, token string) *AgentEnroller {
+	return &AgentEnroller{store: store, token: token, now: time.Now}
+}
+
+// hashCredential returns the stored form of a credential
+func hashCredential(credential string) string {
+	sum := sha256.Sum256([]byte(credential))
+	return hex.EncodeToString(sum[:])
+}
+
+// Enroll admits the agent if token is the enrollment token and returns its new
+// credential. An enrolled agent that lost its credential may enroll again,
+// which replaces the old one; a revoked agent may not.
+func (e *AgentEnroller) Enroll(token, id, hostname string) (models.AgentEnrollResponse, error) {
+	if subtle.ConstantTimeCompare([]byte(token), []byte(e.token)) != 1 {
+		return models.AgentEnrollResponse{}, ErrInvalidEnrollmentToken
+	}
+	existing, err := e.store.Get(id)
+	if err == nil && existing.Revoked {
+		return models.AgentEnrollResponse{}, ErrAgentRevoked
+	}
+
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return models.AgentEnrollResponse{}, fmt.Errorf("failed to generate agent credential: %w", err)
+	}
+	credential := AgentCredentialPrefix + hex.EncodeToString(secret)
+	agent := &models.AgentEnrollment{
+		ID:             id,
+		Hostname:       hostname,
+		CredentialHash: hashCredential(credential),
+		EnrolledAt:     e.now().UTC(),
+	}
+	if err := e.store.Save(agent); err != nil {
+		return models.AgentEnrollResponse{}, err
+	}
+	slog.Info("Agent enrolled", "agent", id, "hostname", hostname, "re_enrolled", existing != nil)
+	return models.AgentEnrollResponse{ID: id, Credential: credential}, nil
+}
+
+// Authenticate checks that credential was issued to the agent with the given ID
+func (e *AgentEnroller) Authenticate(id, credential string) error {
+	agent, err := e.store.Get(id)
+	if err != nil {
+		return ErrAgentUnauthorized
+	}
+	if subtle.ConstantTimeCompare([]byte(hashCredential(credential)), []byte(agent.CredentialHash)) != 1 {
+		return ErrAgentUnauthorized
+	}
+	if agent.Revoked {
+		return ErrAgentRevoked
+	}
+	return nil
+}
+
+// Revoke invalidates the agent's credential and blocks it from enrolling again
+func (e *AgentEnroller) Revoke(id string) error {
+	agent, err := e.store.Get(id)
+	if err != nil {
+		return err
+	}
+	if agent.Revoked {
+		return nil
+	}
+	now := e.now().UTC()
+	agent.Revoked = true
+	agent.RevokedAt = &now
+	if err := e.store.Save(agent); err != nil {
+		return err
+	}
+	slog.Warn("Agent revoked", "agent", id)
+	return nil
+}
+
+// Forget deletes the agent's enrollment, revoked or not, so it can enroll again
+func (e *AgentEnroller) Forget(id string) error {
+	return e.store.Delete(id)
+}
+
+// List returns every enrollment without credential hashes
+func (e *AgentEnroller) List() []*models.AgentEnrollment {
+	agents := e.store.List()
+	for _, agent := range agents {
+		agent.CredentialHash = ""
+	}
+	return agents
+}
@@ -1,4 +1,4 @@
-// File: internal/sync/evaluator.go
+// File: internal/services/evaluator.go
 // Brief: Unified alert evaluation logic (migrated from internal/alerts/evaluator/)
 // Detailed: Contains Evaluator, metricCollector, and all related logic for evaluating alert conditions and generating events.
 // Author: drama.lin@aver.com
@@ -8,15 +8,24 @@ package services
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
 
+	"argus/internal/clock"
 	"argus/internal/database"
+	"argus/internal/logwatch"
 	"argus/internal/metrics"
 	"argus/internal/models"
+	"argus/internal/usage"
+	"argus/internal/utils"
 )
 
 const (
@@ -24,6 +33,8 @@ const (
 	DefaultAlertDebounceCount = 2
 	DefaultAlertResolveCount  = 2
 	DefaultEventChannelSize   = 1000
+	DefaultContextTopN        = 5
+	DefaultContextSamples     = 20
 )
 
 type EvaluatorConfig struct {
@@ -31,6 +42,19 @@ type EvaluatorConfig struct {
 	AlertDebounceCount int
 	AlertResolveCount  int
 	EventChannelSize   int
+	// ContextTopN is how many processes/partitions are captured into the event
+	// context when an alert fires (0 disables context capture)
+	ContextTopN int
+	// ContextSamples is how many recent samples of the alert's metric are
+	// captured into the event context from the metrics history (0 disables)
+	ContextSamples int
+	// EventOverflow is applied when the event channel is full
+	EventOverflow OverflowConfig
+	// Clock drives the evaluation ticker and event timestamps (nil uses the real clock)
+	Clock clock.Clock
+	// LogRepeatInterval is how often a repeating evaluation error is logged
+	// again (0 uses utils.DefaultLogRepeatInterval)
+	LogRepeatInterval time.Duration
 }
 
 func DefaultEvaluatorConfig() *EvaluatorConfig {
@@ -39,12 +63,16 @@ func DefaultEvaluatorConfig() *EvaluatorConfig {
 		AlertDebounceCount: DefaultAlertDebounceCount,
 		AlertResolveCount:  DefaultAlertResolveCount,
 		EventChannelSize:   DefaultEventChannelSize,
+		ContextTopN:        DefaultContextTopN,
+		ContextSamples:     DefaultContextSamples,
+		EventOverflow:      OverflowConfig{Policy: OverflowDropNewest},
 	}
 }
 
 // AlertStatusMap represents a thread-safe map of alert statuses using atomic operations
 type AlertStatusMap struct {
 	data atomic.Value // stores map[string]*models.AlertStatus
+	mu   sync.Mutex   // Serializes writers; maps cannot be compared, so no CompareAndSwap
 }
 
 // NewAlertStatusMap creates a new atomic alert status map
@@ -73,78 +101,80 @@ func (m *AlertStatusMap) GetAll() map[string]*models.AlertStatus {
 
 // Update atomically updates the alert status map using read-copy-update pattern
 func (m *AlertStatusMap) Update(alertID string, status *models.AlertStatus) {
-	for {
-		oldMap := m.data.Load().(map[string]*models.AlertStatus)
-		newMap := make(map[string]*models.AlertStatus, len(oldMap)+1)
-
-		// Copy existing entries
-		for id, s := range oldMap {
-			newMap[id] = s
-		}
-
-		// Update the specific entry
-		newMap[alertID] = status
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	oldMap := m.data.Load().(map[string]*models.AlertStatus)
+	newMap := make(map[string]*models.AlertStatus, len(oldMap)+1)
 
-		// Attempt atomic swap
-		if m.data.CompareAndSwap(oldMap, newMap) {
-			break
-		}
-		// If CAS failed, retry with new snapshot
+	// Copy existing entries
+	for id, s := range oldMap {
+		newMap[id] = s
 	}
+
+	// Update the specific entry
+	newMap[alertID] = status
+	m.data.Store(newMap)
 }
 
 // Delete atomically removes an alert status
 func (m *AlertStatusMap) Delete(alertID string) {
-	for {
-		oldMap := m.data.Load().(map[string]*models.AlertStatus)
-		if _, exists := oldMap[alertID]; !exists {
-			return // Nothing to delete
-		}
-
-		newMap := make(map[string]*models.AlertStatus, len(oldMap)-1)
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	oldMap := m.data.Load().(map[string]*models.AlertStatus)
+	if _, exists := oldMap[alertID]; !exists {
+		return // Nothing to delete
+	}
 
-		// Copy existing entries except the one to delete
-		for id, s := range oldMap {
-			if id != alertID {
-				newMap[id] = s
-			}
-		}
+	newMap := make(map[string]*models.AlertStatus, len(oldMap)-1)
 
-		// Attempt atomic swap
-		if m.data.CompareAndSwap(oldMap, newMap) {
-			break
+	// Copy existing entries except the one to delete
+	for id, s := range oldMap {
+		if id != alertID {
+			newMap[id] = s
 		}
-		// If CAS failed, retry with new snapshot
 	}
+	m.data.Store(newMap)
 }
 
 // Initialize atomically sets the initial alert status map
 func (m *AlertStatusMap) Initialize(statusMap map[string]*models.AlertStatus) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
 	m.data.Store(statusMap)
 }
 
 type Evaluator struct {
 	config           *EvaluatorConfig
-	alertStore       *database.AlertStore
+	clock            clock.Clock
+	alertStore       database.AlertStore
 	alertStatus      *AlertStatusMap
 	metricsCollector *metrics.Collector
+	seriesStore      *metrics.SeriesStore
+	history          *metrics.SeriesStore // Recorded host metrics for impact previews
+	apiUsage         *usage.Tracker
+	logWatcher       *logwatch.Watcher
+	endpoints        *EndpointResults // Latest health check probes for http alerts
 	eventCh          chan models.AlertEvent
+	droppedEvents    atomic.Uint64
+	errorLog         *utils.LogSampler // Throttles errors repeating every tick
 	wg               sync.WaitGroup
 
 	// Object pools for reducing allocations
 	eventPool sync.Pool
 }
 
-func NewEvaluator(alertStore *database.AlertStore, config *EvaluatorConfig) *Evaluator {
+func NewEvaluator(alertStore database.AlertStore, config *EvaluatorConfig) *Evaluator {
 	if config == nil {
 		config = DefaultEvaluatorConfig()
 	}
 
 	return &Evaluator{
 		config:      config,
+		clock:       clock.OrReal(config.Clock),
 		alertStore:  alertStore,
 		alertStatus: NewAlertStatusMap(),
 		eventCh:     make(chan models.AlertEvent, config.EventChannelSize),
+		errorLog:    utils.NewLogSampler(config.LogRepeatInterval, config.Clock),
 		eventPool: sync.Pool{
 			New: func() interface{} {
 				return &models.AlertEvent{}
@@ -153,6 +183,26 @@ func NewEvaluator(alertStore *database.AlertStore, config *EvaluatorConfig) *Eva
 	}
 }
 
+// SetSeriesStore sets the store of ingested series used by series alerts
+func (e *Evaluator) SetSeriesStore(store *metrics.SeriesStore) {
+	e.seriesStore = store
+}
+
+// SetAPIUsage sets the API usage tracker evaluated by api alerts
+func (e *Evaluator) SetAPIUsage(tracker *usage.Tracker) {
+	e.apiUsage = tracker
+}
+
+// SetLogWatcher sets the log watcher whose match counts log alerts evaluate
+func (e *Evaluator) SetLogWatcher(watcher *logwatch.Watcher) {
+	e.logWatcher = watcher
+}
+
+// SetEndpointResults sets the health check probes http alerts evaluate
+func (e *Evaluator) SetEndpointResults(results *EndpointResults) {
+	e.endpoints = results
+}
+
 // SetMetricsCollector sets the centralized metrics collector
 func (e *Evaluator) SetMetricsCollector(collector *metrics.Collector) {
 	e.metricsCollector = collector
@@ -188,6 +238,17 @@ func (e *Evaluator) Events() <-chan models.AlertEvent {
 	return e.eventCh
 }
 
+// EventQueueStats reports the fill level and drop count of the event channel
+func (e *Evaluator) EventQueueStats() QueueStats {
+	return QueueStats{
+		Name:     "alert_events",
+		Policy:   e.config.EventOverflow.Policy,
+		Capacity: cap(e.eventCh),
+		Length:   len(e.eventCh),
+		Dropped:  e.droppedEvents.Load(),
+	}
+}
+
 func (e *Evaluator) GetAlertStatus(alertID string) (*models.AlertStatus, bool) {
 	return e.alertStatus.Get(alertID)
 }
@@ -209,6 +270,7 @@ func (e *Evaluator) initAlertStatus() error {
 				AlertID: config.ID,
 				State:   models.StateInactive,
 				Message: fmt.Sprintf("Alert %s initialized", config.Name),
+				Source:  models.SourceLocal,
 			}
 		}
 	}
@@ -220,7 +282,7 @@ func (e *Evaluator) initAlertStatus() error {
 
 func (e *Evaluator) evaluationLoop(ctx context.Context) {
 	defer e.wg.Done()
-	ticker := time.NewTicker(e.config.EvaluationInterval)
+	ticker := e.clock.NewTicker(e.config.EvaluationInterval)
 	defer ticker.Stop()
 
 	// Persistent counters to avoid allocations
@@ -232,7 +294,7 @@ func (e *Evaluator) evaluationLoop(ctx context.Context) {
 		case <-ctx.Done():
 			slog.Info("Evaluation loop stopped due to context cancellation")
 			return
-		case <-ticker.C:
+		case <-ticker.C():
 			e.evaluateAlerts(pendingCounters, resolveCounters)
 		}
 	}
@@ -241,94 +303,185 @@ func (e *Evaluator) evaluationLoop(ctx context.Context) {
 func (e *Evaluator) evaluateAlerts(pendingCounters, resolveCounters map[string]int) {
 	alertConfigs, err := e.alertStore.ListAlerts()
 	if err != nil {
-		slog.Error("Failed to list alerts", "error", err)
+		e.errorLog.Log(slog.LevelError, "list-alerts", "Failed to list alerts", "error", err)
 		return
 	}
+	e.errorLog.Reset("list-alerts")
 
 	for _, config := range alertConfigs {
 		if !config.Enabled {
 			continue
 		}
 
-		currentValue, err := e.evaluateMetric(config.Threshold)
+		var (
+			currentValue float64
+			stringValue  string
+			exceeded     bool
+		)
+		if config.Threshold.StringMetric() {
+			stringValue, err = e.evaluateStringMetric(config.Threshold)
+			if err == nil {
+				exceeded, err = config.Threshold.MatchString(stringValue)
+			}
+		} else {
+			currentValue, err = e.evaluateMetric(config.Threshold)
+			exceeded = err == nil && e.compareValue(currentValue, config.Threshold.Value, config.Threshold.Operator)
+		}
+		if errors.Is(err, metrics.ErrInitializing) {
+			// Not stale, just warming up: keep the alert's state as it is
+			slog.Debug("Skipping alert while metrics initialize", "alert_id", config.ID)
+			continue
+		}
 		if err != nil {
-			slog.Error("Failed to evaluate metric",
-				"alert_id", config.ID,
-				"alert_name", config.Name,
-				"error", err)
+			if e.markNoData(config, err) {
+				// Auto-resolved: count afresh once data returns
+				setCounter(pendingCounters, config.ID, 0)
+				setCounter(resolveCounters, config.ID, 0)
+			}
 			continue
 		}
 
-		exceeded := e.compareValue(currentValue, config.Threshold.Value, config.Threshold.Operator)
-		e.processAlertState(config, currentValue, exceeded, pendingCounters, resolveCounters)
+		e.updateAlertState(config, currentValue, stringValue, exceeded, pendingCounters, resolveCounters)
 	}
 }
 
 func (e *Evaluator) processAlertState(config *models.AlertConfig, currentValue float64, exceeded bool, pendingCounters, resolveCounters map[string]int) {
+	e.updateAlertState(config, currentValue, "", exceeded, pendingCounters, resolveCounters)
+}
+
+// updateAlertState applies one evaluation of the alert; stringValue is the
+// current value of string metrics
+func (e *Evaluator) updateAlertState(config *models.AlertConfig, currentValue float64, stringValue string, exceeded bool, pendingCounters, resolveCounters map[string]int) {
 	// Get current status or create new one
 	status, exists := e.alertStatus.Get(config.ID)
 	if !exists {
 		status = &models.AlertStatus{
 			AlertID: config.ID,
 			State:   models.StateInactive,
+			Source:  models.SourceLocal,
 		}
 	}
 
 	// Create a copy for modification to avoid race conditions
 	newStatus := *status
-	newStatus.CurrentValue = currentValue
+	newStatus.CurrentValue, newStatus.StringValue = currentValue, stringValue
+	newStatus.NoData, newStatus.NoDataSince, newStatus.NoDataReason = false, nil, ""
+	if status.NoData {
+		slog.Info("Alert metric available again", "alert_id", config.ID, "alert_name", config.Name)
+	}
 
-	switch status.State {
-	case models.StateInactive:
-		if exceeded {
-			pendingCounters[config.ID]++
-			if pendingCounters[config.ID] >= e.config.AlertDebounceCount {
-				oldState := newStatus.State
-				newStatus.State = models.StatePending
-				delete(pendingCounters, config.ID)
-				e.alertStatus.Update(config.ID, &newStatus)
-				e.generateEvent(oldState, newStatus.State, currentValue, config, &newStatus)
-			}
-		} else {
-			// Reset pending counter if condition is no longer met
-			delete(pendingCounters, config.ID)
+	next, pending, resolve := nextAlertState(status.State, exceeded,
+		pendingCounters[config.ID], resolveCounters[config.ID],
+		e.config.AlertDebounceCount, e.config.AlertResolveCount)
+	setCounter(pendingCounters, config.ID, pending)
+	setCounter(resolveCounters, config.ID, resolve)
+
+	if next != status.State {
+		oldState := newStatus.State
+		newStatus.State = next
+		now := e.clock.Now().UTC()
+		switch next {
+		case models.StatePending:
+			newStatus.TriggeredAt, newStatus.ResolvedAt = &now, nil
+		case models.StateResolved:
+			newStatus.ResolvedAt = &now
+		}
+		e.alertStatus.Update(config.ID, &newStatus)
+		e.generateEvent(oldState, newStatus.State, currentValue, config, &newStatus)
+	}
+
+	// Update current value even if state didn't change
+	if exists {
+		e.alertStatus.Update(config.ID, &newStatus)
+	}
+}
+
+// markNoData records that an alert could not be evaluated. Its state is kept
+// as it is, but the status reports why so a broken collector does not go
+// unnoticed behind an alert that never fires. A firing alert with
+// AutoResolveAfter set is resolved once it had no data for that long, with
+// the reason in its history; markNoData reports whether it was.
+func (e *Evaluator) markNoData(config *models.AlertConfig, err error) bool {
+	status, exists := e.alertStatus.Get(config.ID)
+	if !exists {
+		status = &models.AlertStatus{
+			AlertID: config.ID,
+			State:   models.StateInactive,
+			Source:  models.SourceLocal,
 		}
+	}
 
-	case models.StatePending:
+	now := e.clock.Now().UTC()
+	newStatus := *status
+	newStatus.NoDataReason = err.Error()
+	if !status.NoData {
+		newStatus.NoData, newStatus.NoDataSince = true, &now
+		slog.Warn("Alert has no data",
+			"alert_id", config.ID,
+			"alert_name", config.Name,
+			"error", err)
+	}
+
+	timeout := config.AutoResolveTimeout()
+	if timeout <= 0 || !newStatus.State.Firing() || now.Sub(*newStatus.NoDataSince) < timeout {
+		e.alertStatus.Update(config.ID, &newStatus)
+		return false
+	}
+	oldState := newStatus.State
+	newStatus.State, newStatus.ResolvedAt = models.StateResolved, &now
+	e.alertStatus.Update(config.ID, &newStatus)
+	slog.Info("Alert auto-resolved without data",
+		"alert_id", config.ID,
+		"alert_name", config.Name,
+		"auto_resolve_after", timeout.String(),
+		"error", err)
+
+	// The reason goes with this event only, not the status later events copy
+	eventStatus := newStatus
+	eventStatus.Message = fmt.Sprintf("Auto-resolved after %s without data: %s", timeout, err)
+	e.generateEvent(oldState, models.StateResolved, newStatus.CurrentValue, config, &eventStatus)
+	return true
+}
+
+// nextAlertState applies one evaluation to an alert's state machine. pending
+// counts consecutive exceeded evaluations towards debounceCount, resolve counts
+// consecutive normal ones towards resolveCount; both are returned updated.
+// Inactive and resolved alerts go pending after debounceCount exceeded
+// evaluations in a row, and pending alerts resolve after resolveCount normal ones.
+func nextAlertState(state models.AlertState, exceeded bool, pending, resolve, debounceCount, resolveCount int) (models.AlertState, int, int) {
+	switch state {
+	case models.StateInactive, models.StateResolved:
 		if !exceeded {
-			resolveCounters[config.ID]++
-			if resolveCounters[config.ID] >= e.config.AlertResolveCount {
-				oldState := newStatus.State
-				newStatus.State = models.StateResolved
-				delete(resolveCounters, config.ID)
-				e.alertStatus.Update(config.ID, &newStatus)
-				e.generateEvent(oldState, newStatus.State, currentValue, config, &newStatus)
-			}
-		} else {
-			// Reset resolve counter if condition is still met
-			delete(resolveCounters, config.ID)
+			// Reset pending counter if condition is no longer met
+			return state, 0, resolve
+		}
+		pending++
+		if pending >= debounceCount {
+			return models.StatePending, 0, resolve
 		}
+		return state, pending, resolve
 
-	case models.StateResolved:
+	case models.StatePending:
 		if exceeded {
-			pendingCounters[config.ID]++
-			if pendingCounters[config.ID] >= e.config.AlertDebounceCount {
-				oldState := newStatus.State
-				newStatus.State = models.StatePending
-				delete(pendingCounters, config.ID)
-				e.alertStatus.Update(config.ID, &newStatus)
-				e.generateEvent(oldState, newStatus.State, currentValue, config, &newStatus)
-			}
-		} else {
-			// Reset pending counter if condition is no longer met
-			delete(pendingCounters, config.ID)
+			// Reset resolve counter if condition is still met
+			return state, pending, 0
+		}
+		resolve++
+		if resolve >= resolveCount {
+			return models.StateResolved, pending, 0
 		}
+		return state, pending, resolve
 	}
+	return state, pending, resolve
+}
 
-	// Update current value even if state didn't change
-	if exists {
-		e.alertStatus.Update(config.ID, &newStatus)
+// setCounter stores n in counters, dropping the key once it is back to zero
+func setCounter(counters map[string]int, id string, n int) {
+	if n == 0 {
+		delete(counters, id)
+		return
 	}
+	counters[id] = n
 }
 
 // generateEvent creates and sends an alert event using object pooling
@@ -343,35 +496,183 @@ func (e *Evaluator) generateEvent(oldState, newState models.AlertState, currentV
 		NewState:     newState,
 		CurrentValue: currentValue,
 		Threshold:    config.Threshold.Value,
-		Timestamp:    time.Now(),
+		Timestamp:    e.clock.Now().UTC(),
 		Message:      status.Message,
 		Alert:        config,
 		Status:       status,
+		Source:       models.SourceLocal,
+		StringValue:  status.StringValue,
+	}
+	if newState == models.StatePending || newState == models.StateActive {
+		event.Context = e.captureContext(config.Threshold, currentValue)
+	}
+
+	// Record the transition in the alert history
+	if e.alertStore != nil {
+		if err := e.alertStore.AppendHistory(models.NewAlertHistoryEntry(*event)); err != nil {
+			e.errorLog.Log(slog.LevelWarn, "history", "Failed to record alert history", "alert_id", config.ID, "error", err)
+		} else {
+			e.errorLog.Reset("history")
+		}
 	}
 
-	// Send event non-blocking to avoid deadlocks if channel is full
-	select {
-	case e.eventCh <- *event:
-		// Event sent successfully
+	// Queue the event according to the overflow policy; never blocks longer than its timeout
+	if offer(e.eventCh, *event, e.config.EventOverflow, &e.droppedEvents) {
 		slog.Debug("Alert event generated",
 			"alert_id", config.ID,
 			"alert_name", config.Name,
 			"old_state", oldState,
 			"new_state", newState,
 			"current_value", currentValue)
-	default:
-		slog.Warn("Event channel full, dropping alert event",
+	} else {
+		e.errorLog.Log(slog.LevelWarn, "event-channel-full", "Event channel full, dropping alert event",
 			"alert_id", config.ID,
 			"alert_name", config.Name,
 			"old_state", oldState,
-			"new_state", newState)
+			"new_state", newState,
+			"policy", e.config.EventOverflow.Policy)
 	}
 
 	// Return event to pool
 	e.eventPool.Put(event)
 }
 
+// captureContext snapshots the resources relevant to a firing alert from the
+// collector: the top consumers for CPU, memory and process alerts, and the
+// fullest partitions for disk alerts, along with the recent samples of its
+// metric. It returns nil when nothing is available.
+func (e *Evaluator) captureContext(threshold models.ThresholdConfig, value float64) *models.EventContext {
+	ctx := &models.EventContext{
+		Samples:    e.recentSamples(threshold, value),
+		CapturedAt: e.clock.Now().UTC(),
+	}
+	if topN := e.config.ContextTopN; e.metricsCollector != nil && topN > 0 {
+		switch threshold.MetricType {
+		case models.MetricCPU, models.MetricMemory, models.MetricProcess:
+			if processMetrics := e.metricsCollector.GetProcessMetrics(); processMetrics != nil {
+				byMemory := threshold.MetricType == models.MetricMemory || threshold.MetricName == "memory_percent"
+				ctx.TopProcesses = topProcesses(processMetrics.Processes, topN, byMemory)
+			}
+		case models.MetricDisk:
+			if diskMetrics := e.metricsCollector.GetDiskMetrics(); diskMetrics != nil {
+				ctx.Partitions = fullestPartitions(diskMetrics.Partitions, threshold, topN)
+			}
+		}
+	}
+
+	if len(ctx.TopProcesses) == 0 && len(ctx.Partitions) == 0 && len(ctx.Samples) == 0 {
+		return nil
+	}
+	return ctx
+}
+
+// recentSamples returns the last ContextSamples recorded values of the
+// threshold's metric, or nil when it is not recorded. When several series
+// match, the one whose latest value is closest to the evaluated value is used.
+func (e *Evaluator) recentSamples(threshold models.ThresholdConfig, value float64) []models.MetricSample {
+	n := e.config.ContextSamples
+	if n <= 0 {
+		return nil
+	}
+	store, name, selector, err := e.historySeries(threshold)
+	if err != nil {
+		return nil
+	}
+	var samples []metrics.Sample
+	distance := math.Inf(1)
+	for _, series := range store.Tail(name, selector, n) {
+		if d := math.Abs(series.Samples[len(series.Samples)-1].Value - value); d < distance {
+			samples, distance = series.Samples, d
+		}
+	}
+	if len(samples) == 0 {
+		return nil
+	}
+	result := make([]models.MetricSample, len(samples))
+	for i, sample := range samples {
+		result[i] = models.MetricSample{Timestamp: sample.Timestamp, Value: sample.Value}
+	}
+	return result
+}
+
+// topProcesses returns the n processes using the most CPU (or memory)
+func topProcesses(processes []metrics.ProcessInfo, n int, byMemory bool) []models.ProcessSnapshot {
+	sorted := make([]metrics.ProcessInfo, len(processes))
+	copy(sorted, processes)
+	sort.Slice(sorted, func(i, j int) bool {
+		if byMemory {
+			return sorted[i].MemPercent > sorted[j].MemPercent
+		}
+		return sorted[i].CPUPercent > sorted[j].CPUPercent
+	})
+	if len(sorted) > n {
+		sorted = sorted[:n]
+	}
+
+	snapshots := make([]models.ProcessSnapshot, 0, len(sorted))
+	for _, p := range sorted {
+		snapshots = append(snapshots, models.ProcessSnapshot{
+			PID:        p.PID,
+			Name:       p.Name,
+			CPUPercent: p.CPUPercent,
+			MemPercent: float64(p.MemPercent),
+		})
+	}
+	return snapshots
+}
+
+// fullestPartitions returns the partitions the threshold's target and labels
+// select, or the n partitions with the highest usage when it selects none
+func fullestPartitions(partitions []metrics.DiskUsage, threshold models.ThresholdConfig, n int) []models.PartitionSnapshot {
+	var selected []metrics.DiskUsage
+	if (threshold.Target != nil && *threshold.Target != "") || len(threshold.Labels) > 0 {
+		for _, p := range partitions {
+			if partitionSelected(p, threshold) {
+				selected = append(selected, p)
+			}
+		}
+	} else {
+		selected = make([]metrics.DiskUsage, len(partitions))
+		copy(selected, partitions)
+		sort.Slice(selected, func(i, j int) bool { return selected[i].UsedPercent > selected[j].UsedPercent })
+		if len(selected) > n {
+			selected = selected[:n]
+		}
+	}
+
+	snapshots := make([]models.PartitionSnapshot, 0, len(selected))
+	for _, p := range selected {
+		snapshots = append(snapshots, models.PartitionSnapshot{
+			Device:      p.Device,
+			Mountpoint:  p.Mountpoint,
+			Fstype:      p.Fstype,
+			Total:       p.Total,
+			Free:        p.Free,
+			UsedPercent: p.UsedPercent,
+
+			InodesUsedPercent: p.InodesUsedPercent,
+		})
+	}
+	return snapshots
+}
+
 func (e *Evaluator) evaluateMetric(threshold models.ThresholdConfig) (float64, error) {
+	if threshold.MetricType == models.MetricSeries {
+		return e.evaluateSeries(threshold)
+	}
+	if threshold.MetricType == models.MetricAPI {
+		return e.evaluateAPIUsage(threshold)
+	}
+	if threshold.MetricType == models.MetricHTTP {
+		return e.evaluateHTTP(threshold)
+	}
+	if threshold.MetricType == models.MetricLog {
+		if e.logWatcher == nil {
+			return 0, fmt.Errorf("log watching is not enabled")
+		}
+		count, err := e.logWatcher.Count(threshold.MetricName)
+		return float64(count), err
+	}
 	// Prioritize collector if available
 	if e.metricsCollector != nil {
 		return e.evaluateMetricFromCollector(threshold)
@@ -380,7 +681,50 @@ func (e *Evaluator) evaluateMetric(threshold models.ThresholdConfig) (float64, e
 	return e.evaluateMetricDirect(threshold)
 }
 
+// collectorKinds maps collector metric types to the metric kind whose
+// collection health they depend on
+var collectorKinds = map[models.MetricType]string{
+	models.MetricCPU:     metrics.KindCPU,
+	models.MetricLoad:    metrics.KindCPU,
+	models.MetricMemory:  metrics.KindMemory,
+	models.MetricNetwork: metrics.KindNetwork,
+	models.MetricDisk:    metrics.KindDisk,
+	models.MetricProcess: metrics.KindProcess,
+	models.MetricRAID:    metrics.KindRAID,
+	models.MetricPower:   metrics.KindPower,
+	models.MetricService: metrics.KindServices,
+}
+
 func (e *Evaluator) evaluateMetricFromCollector(threshold models.ThresholdConfig) (float64, error) {
+	value, err := 0.0, e.collectorHealthError(threshold)
+	if err == nil {
+		value, err = e.collectorValue(threshold)
+	}
+	if err != nil && !errors.Is(err, metrics.ErrInitializing) && e.metricsCollector.Initializing() {
+		return 0, fmt.Errorf("%w: %v", metrics.ErrInitializing, err)
+	}
+	return value, err
+}
+
+// collectorHealthError returns an error when the collector keeps failing to
+// gather the threshold's metric kind or stopped refreshing it, so alerts are
+// not evaluated against the last value it managed to collect
+func (e *Evaluator) collectorHealthError(threshold models.ThresholdConfig) error {
+	kind, ok := collectorKinds[threshold.MetricType]
+	if !ok {
+		return nil
+	}
+	health := e.metricsCollector.MetricHealth(kind)
+	switch health.Status {
+	case metrics.StatusFailing:
+		return fmt.Errorf("%s collector failing (%d consecutive errors): %s", kind, health.ConsecutiveFailures, health.LastError)
+	case metrics.StatusStale:
+		return fmt.Errorf("%s metrics stale since %s", kind, health.LastSuccess.Format(time.RFC3339))
+	}
+	return nil
+}
+
+func (e *Evaluator) collectorValue(threshold models.ThresholdConfig) (float64, error) {
 	switch threshold.MetricType {
 	case models.MetricCPU:
 		cpuMetrics := e.metricsCollector.GetCPUMetrics()
@@ -399,40 +743,192 @@ func (e *Evaluator) evaluateMetricFromCollector(threshold models.ThresholdConfig
 		if networkMetrics == nil {
 			return 0, fmt.Errorf("network metrics not available")
 		}
-		return e.extractNetworkValue(networkMetrics, threshold.MetricName)
+		return extractNetworkValue(networkMetrics, threshold)
+	case models.MetricDisk:
+		diskMetrics := e.metricsCollector.GetDiskMetrics()
+		if diskMetrics == nil {
+			return 0, fmt.Errorf("disk metrics not available")
+		}
+		return extractDiskValue(diskMetrics, threshold)
 	case models.MetricProcess:
+		if threshold.MetricName == "count" {
+			return e.countProcesses(threshold)
+		}
 		processMetrics := e.metricsCollector.GetProcessMetrics()
 		if processMetrics == nil {
 			return 0, fmt.Errorf("process metrics not available")
 		}
+		if processMetrics.Initializing {
+			// Lifetime averages would misfire process CPU alerts
+			return 0, metrics.ErrInitializing
+		}
 		return e.extractProcessValue(processMetrics.Processes, threshold)
+	case models.MetricRAID:
+		raidMetrics := e.metricsCollector.GetRAIDMetrics()
+		if raidMetrics == nil {
+			return 0, fmt.Errorf("RAID metrics not available")
+		}
+		return extractRAIDValue(raidMetrics, threshold)
+	case models.MetricPower:
+		powerMetrics := e.metricsCollector.GetPowerMetrics()
+		if powerMetrics == nil {
+			return 0, fmt.Errorf("power metrics not available")
+		}
+		return extractPowerValue(powerMetrics, threshold)
+	case models.MetricService:
+		serviceMetrics := e.metricsCollector.GetServiceMetrics()
+		if serviceMetrics == nil {
+			return 0, fmt.Errorf("service metrics not available")
+		}
+		return extractServiceValue(serviceMetrics, threshold)
 	default:
 		return 0, fmt.Errorf("unsupported metric type for collector: %s", threshold.MetricType)
 	}
 }
 
+// SeriesStaleAfter is how old the latest sample of a series may be before a
+// series alert stops evaluating it
+const SeriesStaleAfter = 5 * time.Minute
+
+// evaluateSeries returns the latest value of the ingested series selected by
+// the threshold's metric name and labels. When several series match, the one
+// closest to firing is used: the lowest value for < and <= thresholds, the
+// highest otherwise.
+func (e *Evaluator) evaluateSeries(threshold models.ThresholdConfig) (float64, error) {
+	if e.seriesStore == nil {
+		return 0, fmt.Errorf("series ingestion is not enabled")
+	}
+	cutoff := e.clock.Now().Add(-SeriesStaleAfter)
+	lowest := threshold.Operator == models.OperatorLessThan || threshold.Operator == models.OperatorLessThanOrEqual
+
+	found := false
+	var value float64
+	for _, s := range e.seriesStore.Latest(threshold.MetricName, threshold.Labels) {
+		if s.Latest.Timestamp.Before(cutoff) {
+			continue
+		}
+		if !found || (lowest && s.Latest.Value < value) || (!lowest && s.Latest.Value > value) {
+			value = s.Latest.Value
+		}
+		found = true
+	}
+	if !found {
+		return 0, fmt.Errorf("no recent samples for series: %s", threshold.MetricName)
+	}
+	return value, nil
+}
+
+// evaluateAPIUsage returns an API usage value over the tracker's rolling
+// window, limited to the namespace and token labels when set
+func (e *Evaluator) evaluateAPIUsage(threshold models.ThresholdConfig) (float64, error) {
+	if e.apiUsage == nil {
+		return 0, fmt.Errorf("API usage metrics are not enabled")
+	}
+	stats := e.apiUsage.Window(threshold.Labels["namespace"], threshold.Labels["token"])
+	switch threshold.MetricName {
+	case "error_rate_percent":
+		return stats.ErrorRatePercent(), nil
+	case "client_error_rate_percent":
+		return stats.ClientErrorRatePercent(), nil
+	case "requests_per_minute":
+		return stats.RequestsPerMinute(), nil
+	case "avg_latency_ms":
+		return stats.AvgLatencyMs(), nil
+	default:
+		return 0, fmt.Errorf("unsupported API metric: %s", threshold.MetricName)
+	}
+}
+
+// extractProcessValue returns the usage of the process the threshold targets
+// by name, name pattern or PID. When several processes match, the one closest
+// to firing is used, as for series alerts.
 func (e *Evaluator) extractProcessValue(processes []metrics.ProcessInfo, threshold models.ThresholdConfig) (float64, error) {
 	if threshold.Target == nil || *threshold.Target == "" {
 		return 0, fmt.Errorf("process alert requires a target (name or PID)")
 	}
+	lowest := threshold.Operator == models.OperatorLessThan || threshold.Operator == models.OperatorLessThanOrEqual
 
+	found := false
+	var value float64
+	var lastErr error
 	for _, p := range processes {
-		// Match by process name if provided, otherwise by PID
-		if p.Name == *threshold.Target || fmt.Sprintf("%d", p.PID) == *threshold.Target {
-			switch threshold.MetricName {
-			case "cpu_percent":
-				return p.CPUPercent, nil
-			case "memory_percent":
-				return float64(p.MemPercent), nil
-			default:
-				return 0, fmt.Errorf("unsupported metric for process: %s", threshold.MetricName)
-			}
+		if !threshold.MatchProcess(p.Name, p.PID) {
+			continue
+		}
+		v, err := processValue(p, threshold.MetricName)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		if !found || (lowest && v < value) || (!lowest && v > value) {
+			value = v
 		}
+		found = true
+	}
+	switch {
+	case found:
+		return value, nil
+	case lastErr != nil:
+		return 0, lastErr
 	}
-
 	return 0, fmt.Errorf("process not found: %s", *threshold.Target)
 }
 
+// processValue returns one usage metric of a process
+func processValue(p metrics.ProcessInfo, metricName string) (float64, error) {
+	switch metricName {
+	case "cpu_percent":
+		return p.CPUPercent, nil
+	case "memory_percent":
+		return float64(p.MemPercent), nil
+	default:
+		return processLimitValue(p, metricName)
+	}
+}
+
+// countProcesses returns how many running processes the threshold targets.
+// The count covers every process rather than the top consumers the process
+// metrics keep, and is 0 instead of an error when none runs, so a threshold
+// like count < 1 fires once a daemon dies.
+func (e *Evaluator) countProcesses(threshold models.ThresholdConfig) (float64, error) {
+	count, err := e.metricsCollector.CountProcesses(context.Background(), threshold.MatchProcess)
+	if err != nil {
+		return 0, err
+	}
+	return float64(count), nil
+}
+
+// processLimitValue returns a process's usage of one of its resource limits.
+// Percentages of limits the process does not have are errors rather than 0,
+// so the alert reports why it cannot evaluate.
+func processLimitValue(p metrics.ProcessInfo, metricName string) (float64, error) {
+	limits := p.Limits
+	if limits == nil {
+		return 0, fmt.Errorf("resource limits not available for process: %s", p.Name)
+	}
+	switch metricName {
+	case "open_files":
+		return float64(limits.OpenFiles), nil
+	case "open_files_percent":
+		if limits.MaxOpenFiles == 0 {
+			return 0, fmt.Errorf("process %s has no open files limit", p.Name)
+		}
+		return limits.OpenFilesPercent, nil
+	case "address_space_percent":
+		if limits.MaxAddressSpace == 0 {
+			return 0, fmt.Errorf("process %s has no address space limit", p.Name)
+		}
+		return limits.AddressSpacePercent, nil
+	case "cgroup_memory_percent":
+		if limits.CgroupMemoryLimit == 0 {
+			return 0, fmt.Errorf("process %s is not in a memory-limited cgroup", p.Name)
+		}
+		return limits.CgroupMemoryPercent, nil
+	default:
+		return 0, fmt.Errorf("unsupported metric for process: %s", metricName)
+	}
+}
+
 func (e *Evaluator) extractCPUValue(cpuMetrics *metrics.CPUMetrics, metricName string) (float64, error) {
 	switch metricName {
 	case "usage_percent":
@@ -461,19 +957,473 @@ func (e *Evaluator) extractMemoryValue(memoryMetrics *metrics.MemoryMetrics, met
 	}
 }
 
-func (e *Evaluator) extractNetworkValue(networkMetrics *metrics.NetworkMetrics, metricName string) (float64, error) {
-	switch metricName {
+// extractNetworkValue returns a network counter summed over the interfaces
+// the threshold's labels select, e.g. interface="eth0", or over every
+// interface without labels
+func extractNetworkValue(networkMetrics *metrics.NetworkMetrics, threshold models.ThresholdConfig) (float64, error) {
+	io := metrics.InterfaceIO{
+		BytesSent:   networkMetrics.BytesSent,
+		BytesRecv:   networkMetrics.BytesRecv,
+		PacketsSent: networkMetrics.PacketsSent,
+		PacketsRecv: networkMetrics.PacketsRecv,
+	}
+	if len(threshold.Labels) > 0 {
+		io = metrics.InterfaceIO{}
+		found := false
+		for _, i := range networkMetrics.Interfaces {
+			if threshold.SelectsLabels(map[string]string{"interface": i.Name}) {
+				io.BytesSent += i.BytesSent
+				io.BytesRecv += i.BytesRecv
+				io.PacketsSent += i.PacketsSent
+				io.PacketsRecv += i.PacketsRecv
+				found = true
+			}
+		}
+		if !found {
+			return 0, fmt.Errorf("network interface not found: %s", threshold.Labels["interface"])
+		}
+	}
+
+	switch threshold.MetricName {
 	case "bytes_sent":
-		return float64(networkMetrics.BytesSent), nil
+		return float64(io.BytesSent), nil
 	case "bytes_recv":
-		return float64(networkMetrics.BytesRecv), nil
+		return float64(io.BytesRecv), nil
 	case "packets_sent":
-		return float64(networkMetrics.PacketsSent), nil
+		return float64(io.PacketsSent), nil
 	case "packets_recv":
-		return float64(networkMetrics.PacketsRecv), nil
+		return float64(io.PacketsRecv), nil
+	default:
+		return 0, fmt.Errorf("unsupported network metric: %s", threshold.MetricName)
+	}
+}
+
+// partitionSelected reports whether the threshold's target (a mountpoint or
+// device) and labels (mountpoint, device and fstype) select a partition
+func partitionSelected(p metrics.DiskUsage, threshold models.ThresholdConfig) bool {
+	if target := threshold.Target; target != nil && *target != "" && p.Mountpoint != *target && p.Device != *target {
+		return false
+	}
+	return threshold.SelectsLabels(map[string]string{"mountpoint": p.Mountpoint, "device": p.Device, "fstype": p.Fstype})
+}
+
+// extractDiskValue returns a disk metric of the partition closest to firing
+// among those the threshold's target (a mountpoint or device) and labels
+// select, or among every partition without them: the lowest value for < and
+// <= thresholds, e.g. the least free bytes, the highest otherwise, e.g. the
+// fullest partition's usage_percent. Filesystems without a fixed inode
+// table, e.g. btrfs, are skipped for inode_percent.
+func extractDiskValue(diskMetrics *metrics.DiskMetrics, threshold models.ThresholdConfig) (float64, error) {
+	target := ""
+	if threshold.Target != nil {
+		target = *threshold.Target
+	}
+	lowest := threshold.Operator == models.OperatorLessThan || threshold.Operator == models.OperatorLessThanOrEqual
+
+	found, value := false, 0.0
+	for _, p := range diskMetrics.Partitions {
+		if !partitionSelected(p, threshold) {
+			continue
+		}
+		var v float64
+		switch threshold.MetricName {
+		case "usage_percent":
+			v = p.UsedPercent
+		case "free":
+			v = float64(p.Free)
+		case "inode_percent":
+			if p.InodesTotal == 0 {
+				if target != "" {
+					return 0, fmt.Errorf("partition %s (%s) has no inode limit", target, p.Fstype)
+				}
+				continue
+			}
+			v = p.InodesUsedPercent
+		default:
+			return 0, fmt.Errorf("unsupported disk metric: %s", threshold.MetricName)
+		}
+		if !found || (lowest && v < value) || (!lowest && v > value) {
+			value = v
+		}
+		found = true
+	}
+	if !found {
+		switch {
+		case target != "":
+			return 0, fmt.Errorf("partition not found: %s", target)
+		case len(threshold.Labels) > 0:
+			return 0, fmt.Errorf("no partition matches the labels %v", threshold.Labels)
+		}
+		return 0, fmt.Errorf("no partitions found")
+	}
+	return value, nil
+}
+
+// extractRAIDValue returns a RAID metric of the array or pool named by the
+// threshold's target, or across every array and pool without one: the
+// number of degraded arrays and pools, their failed devices, the least
+// advanced rebuild (100 when none is rebuilding) or the fullest pool
+func extractRAIDValue(raidMetrics *metrics.RAIDMetrics, threshold models.ThresholdConfig) (float64, error) {
+	target := ""
+	if threshold.Target != nil {
+		target = *threshold.Target
+	}
+	arrays := make([]metrics.RAIDArray, 0, len(raidMetrics.Arrays))
+	for _, a := range raidMetrics.Arrays {
+		if target == "" || a.Name == target {
+			arrays = append(arrays, a)
+		}
+	}
+	pools := make([]metrics.ZFSPool, 0, len(raidMetrics.Pools))
+	for _, p := range raidMetrics.Pools {
+		if target == "" || p.Name == target {
+			pools = append(pools, p)
+		}
+	}
+	if target != "" && len(arrays) == 0 && len(pools) == 0 {
+		return 0, fmt.Errorf("RAID array or ZFS pool not found: %s", target)
+	}
+
+	switch threshold.MetricName {
+	case "degraded":
+		degraded := 0
+		for _, a := range arrays {
+			if a.Degraded {
+				degraded++
+			}
+		}
+		for _, p := range pools {
+			if p.Degraded {
+				degraded++
+			}
+		}
+		return float64(degraded), nil
+	case "failed_devices":
+		failed := 0
+		for _, a := range arrays {
+			failed += len(a.FailedDevices)
+		}
+		for _, p := range pools {
+			failed += len(p.FailedDevices)
+		}
+		return float64(failed), nil
+	case "rebuild_percent":
+		progress := 100.0
+		for _, a := range arrays {
+			if a.Rebuilding() {
+				progress = min(progress, a.SyncPercent)
+			}
+		}
+		for _, p := range pools {
+			if p.Rebuilding() {
+				progress = min(progress, p.ScanPercent)
+			}
+		}
+		return progress, nil
+	case "capacity_percent":
+		if len(pools) == 0 {
+			return 0, fmt.Errorf("capacity is only reported for ZFS pools")
+		}
+		capacity := 0.0
+		for _, p := range pools {
+			capacity = max(capacity, p.CapacityPercent)
+		}
+		return capacity, nil
+	default:
+		return 0, fmt.Errorf("unsupported RAID metric: %s", threshold.MetricName)
+	}
+}
+
+// powerDevice is a battery or UPS as power alerts see it
+type powerDevice struct {
+	charge, runtime, load float64
+	onBattery             bool
+	onBatterySeconds      float64
+	ups                   bool
+}
+
+// extractPowerValue returns a power metric of the battery or UPS named by
+// the threshold's target (a battery name, UPS name or UPS ID such as
+// "rack1@localhost"), or across every device without one: the lowest charge
+// and runtime, the number of devices on battery, the longest time on battery
+// or the highest UPS load. Durations are in milliseconds. Untargeted alerts
+// do not evaluate while a NUT server cannot be queried, since the missing
+// UPS may be the one on battery.
+func extractPowerValue(powerMetrics *metrics.PowerMetrics, threshold models.ThresholdConfig) (float64, error) {
+	target := ""
+	if threshold.Target != nil {
+		target = *threshold.Target
+	}
+	var devices []powerDevice
+	for _, b := range powerMetrics.Batteries {
+		if target == "" || b.Name == target {
+			devices = append(devices, powerDevice{charge: b.ChargePercent, runtime: b.RuntimeSeconds, onBattery: b.OnBattery, onBatterySeconds: b.OnBatterySeconds})
+		}
+	}
+	for _, u := range powerMetrics.UPSes {
+		if target == "" || u.Name == target || u.ID() == target {
+			devices = append(devices, powerDevice{charge: u.ChargePercent, runtime: u.RuntimeSeconds, load: u.LoadPercent, onBattery: u.OnBattery, onBatterySeconds: u.OnBatterySeconds, ups: true})
+		}
+	}
+	if target == "" && len(powerMetrics.ServerErrors) > 0 {
+		servers := make([]string, 0, len(powerMetrics.ServerErrors))
+		for server := range powerMetrics.ServerErrors {
+			servers = append(servers, server)
+		}
+		sort.Strings(servers)
+		return 0, fmt.Errorf("NUT server %s unavailable: %s", servers[0], powerMetrics.ServerErrors[servers[0]])
+	}
+	if len(devices) == 0 {
+		if target != "" {
+			return 0, fmt.Errorf("battery or UPS not found: %s", target)
+		}
+		return 0, fmt.Errorf("no batteries or UPSes found")
+	}
+
+	switch threshold.MetricName {
+	case "charge_percent":
+		charge := devices[0].charge
+		for _, d := range devices {
+			charge = min(charge, d.charge)
+		}
+		return charge, nil
+	case "runtime_ms":
+		runtime, found := 0.0, false
+		for _, d := range devices {
+			if d.runtime > 0 && (!found || d.runtime < runtime) {
+				runtime, found = d.runtime, true
+			}
+		}
+		if !found {
+			return 0, fmt.Errorf("no runtime estimate available (batteries only report one while discharging)")
+		}
+		return runtime * 1000, nil
+	case "on_battery":
+		onBattery := 0
+		for _, d := range devices {
+			if d.onBattery {
+				onBattery++
+			}
+		}
+		return float64(onBattery), nil
+	case "on_battery_ms":
+		longest := 0.0
+		for _, d := range devices {
+			longest = max(longest, d.onBatterySeconds)
+		}
+		return longest * 1000, nil
+	case "load_percent":
+		load, found := 0.0, false
+		for _, d := range devices {
+			if d.ups {
+				load, found = max(load, d.load), true
+			}
+		}
+		if !found {
+			return 0, fmt.Errorf("load is only reported for UPSes")
+		}
+		return load, nil
+	default:
+		return 0, fmt.Errorf("unsupported power metric: %s", threshold.MetricName)
+	}
+}
+
+// extractServiceValue returns a service metric of the service named by the
+// threshold's target (a service name such as "ssh" or its unit), or across
+// every installed service without one: the number of services down or
+// their restarts. The clock synchronization metrics read the NTP service.
+func extractServiceValue(serviceMetrics *metrics.ServiceMetrics, threshold models.ThresholdConfig) (float64, error) {
+	if !serviceMetrics.Available {
+		return 0, fmt.Errorf("systemd is not available")
+	}
+	switch threshold.MetricName {
+	case "ntp_synchronized", "time_offset_ms":
+		ntp, ok := serviceMetrics.Service(metrics.ServiceNTP)
+		if !ok || !ntp.Installed {
+			return 0, fmt.Errorf("no NTP service installed")
+		}
+		if serviceMetrics.TimeSync == nil {
+			return 0, fmt.Errorf("NTP service %s is %s", ntp.Unit, ntp.ActiveState)
+		}
+		if threshold.MetricName == "ntp_synchronized" {
+			if serviceMetrics.TimeSync.Synchronized {
+				return 1, nil
+			}
+			return 0, nil
+		}
+		if !serviceMetrics.TimeSync.HasOffset {
+			return 0, fmt.Errorf("clock offset is only reported by chrony")
+		}
+		return serviceMetrics.TimeSync.OffsetSeconds * 1000, nil
+	}
+
+	services := serviceMetrics.Services
+	if threshold.Target != nil && *threshold.Target != "" {
+		service, ok := serviceMetrics.Service(*threshold.Target)
+		if !ok {
+			return 0, fmt.Errorf("service not watched: %s", *threshold.Target)
+		}
+		if !service.Installed {
+			return 0, fmt.Errorf("service not installed: %s", *threshold.Target)
+		}
+		services = []metrics.ServiceState{service}
+	}
+	switch threshold.MetricName {
+	case "down":
+		down := 0
+		for _, s := range services {
+			if s.Installed && !s.Active() {
+				down++
+			}
+		}
+		return float64(down), nil
+	case "restarts":
+		restarts := 0
+		for _, s := range services {
+			restarts += s.Restarts
+		}
+		return float64(restarts), nil
+	default:
+		return 0, fmt.Errorf("unsupported service metric: %s", threshold.MetricName)
+	}
+}
+
+// endpointResult returns the latest probe of the endpoint named by the
+// threshold's target
+func (e *Evaluator) endpointResult(threshold models.ThresholdConfig) (models.HealthProbeResult, error) {
+	if e.endpoints == nil {
+		return models.HealthProbeResult{}, fmt.Errorf("health check results are not available")
+	}
+	target := ""
+	if threshold.Target != nil {
+		target = *threshold.Target
+	}
+	result, ok := e.endpoints.Get(target)
+	if !ok {
+		return result, fmt.Errorf("endpoint not probed yet by a health_check task: %s", target)
+	}
+	return result, nil
+}
+
+// evaluateHTTP returns a numeric metric of the latest probe of an endpoint:
+// whether it was healthy (1 or 0), its HTTP status (0 without a response) or
+// how long it took
+func (e *Evaluator) evaluateHTTP(threshold models.ThresholdConfig) (float64, error) {
+	result, err := e.endpointResult(threshold)
+	if err != nil {
+		return 0, err
+	}
+	switch threshold.MetricName {
+	case "healthy":
+		if result.Healthy {
+			return 1, nil
+		}
+		return 0, nil
+	case "status_code":
+		return float64(result.Status), nil
+	case "duration_ms":
+		return result.DurationMs, nil
+	default:
+		return 0, fmt.Errorf("unsupported HTTP metric: %s", threshold.MetricName)
+	}
+}
+
+// evaluateStringMetric returns the current value of a string metric: the HTTP
+// status of an endpoint's latest probe (empty without a response), a
+// service's active or sub state, or the state of a RAID array or ZFS pool
+func (e *Evaluator) evaluateStringMetric(threshold models.ThresholdConfig) (string, error) {
+	if threshold.MetricType == models.MetricHTTP {
+		result, err := e.endpointResult(threshold)
+		if err != nil || result.Status == 0 {
+			return "", err
+		}
+		return strconv.Itoa(result.Status), nil
+	}
+	if e.metricsCollector == nil {
+		return "", fmt.Errorf("direct metric evaluation not supported, use centralized metrics collector")
+	}
+	value, err := "", e.collectorHealthError(threshold)
+	if err == nil {
+		value, err = e.collectorString(threshold)
+	}
+	if err != nil && !errors.Is(err, metrics.ErrInitializing) && e.metricsCollector.Initializing() {
+		return "", fmt.Errorf("%w: %v", metrics.ErrInitializing, err)
+	}
+	return value, err
+}
+
+func (e *Evaluator) collectorString(threshold models.ThresholdConfig) (string, error) {
+	switch threshold.MetricType {
+	case models.MetricService:
+		serviceMetrics := e.metricsCollector.GetServiceMetrics()
+		if serviceMetrics == nil {
+			return "", fmt.Errorf("service metrics not available")
+		}
+		return extractServiceState(serviceMetrics, threshold)
+	case models.MetricRAID:
+		raidMetrics := e.metricsCollector.GetRAIDMetrics()
+		if raidMetrics == nil {
+			return "", fmt.Errorf("RAID metrics not available")
+		}
+		return extractRAIDState(raidMetrics, threshold)
+	default:
+		return "", fmt.Errorf("unsupported string metric type: %s", threshold.MetricType)
+	}
+}
+
+// extractServiceState returns the systemd active state (e.g. "failed") or
+// sub state (e.g. "dead") of the service named by the threshold's target
+func extractServiceState(serviceMetrics *metrics.ServiceMetrics, threshold models.ThresholdConfig) (string, error) {
+	if !serviceMetrics.Available {
+		return "", fmt.Errorf("systemd is not available")
+	}
+	target := ""
+	if threshold.Target != nil {
+		target = *threshold.Target
+	}
+	service, ok := serviceMetrics.Service(target)
+	if !ok {
+		return "", fmt.Errorf("service not watched: %s", target)
+	}
+	if !service.Installed {
+		return "", fmt.Errorf("service not installed: %s", target)
+	}
+	switch threshold.MetricName {
+	case "active_state":
+		return service.ActiveState, nil
+	case "sub_state":
+		return service.SubState, nil
 	default:
-		return 0, fmt.Errorf("unsupported network metric: %s", metricName)
+		return "", fmt.Errorf("unsupported service metric: %s", threshold.MetricName)
+	}
+}
+
+// extractRAIDState returns the state of the RAID array or ZFS pool named by
+// the threshold's target: "degraded" for a degraded array and otherwise its
+// md state (e.g. "active"), or the lower-case health of a pool (e.g.
+// "online", "degraded" or "faulted")
+func extractRAIDState(raidMetrics *metrics.RAIDMetrics, threshold models.ThresholdConfig) (string, error) {
+	target := ""
+	if threshold.Target != nil {
+		target = *threshold.Target
+	}
+	if threshold.MetricName != "state" {
+		return "", fmt.Errorf("unsupported RAID metric: %s", threshold.MetricName)
+	}
+	for _, a := range raidMetrics.Arrays {
+		if a.Name == target {
+			if a.Degraded {
+				return "degraded", nil
+			}
+			return a.State, nil
+		}
+	}
+	for _, p := range raidMetrics.Pools {
+		if p.Name == target {
+			return strings.ToLower(p.Health), nil
+		}
 	}
+	return "", fmt.Errorf("RAID array or ZFS pool not found: %s", target)
 }
 
 // Fallback direct metric evaluation (kept for backward compatibility)
@@ -498,7 +1448,7 @@ func (e *Evaluator) compareValue(current, threshold float64, operator models.Com
 	case models.OperatorNotEqual:
 		return current != threshold
 	default:
-		slog.Warn("Unknown comparison operator", "operator", operator)
+		e.errorLog.Log(slog.LevelWarn, "operator:"+string(operator), "Unknown comparison operator", "operator", operator)
 		return false
 	}
 }
@@ -15,14 +15,14 @@ import (
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
-	"github.com/stretchr/testify/mock"
 
+	"argus/internal/clock"
 	"argus/internal/database"
 	"argus/internal/metrics"
 	"argus/internal/models"
 )
 
-func createTestAlertStore(t testing.TB) *database.AlertStore {
+func createTestAlertStore(t testing.TB) database.AlertStore {
 	t.Helper()
 	tempDir := t.TempDir()
 	store, err := database.NewAlertStore(tempDir)
@@ -55,6 +55,51 @@ func createTestAlertConfig(t testing.TB) models.AlertConfig {
 	}
 }
 
+// startSeriesEvaluator starts an evaluator on a fake clock over a series
+// store, so tests drive evaluations by advancing the clock instead of sleeping
+func startSeriesEvaluator(t *testing.T, store database.AlertStore, config *EvaluatorConfig) (*Evaluator, *clock.Fake, *metrics.SeriesStore) {
+	t.Helper()
+	clk := clock.NewFake(time.Now())
+	series := metrics.NewSeriesStore(metrics.SeriesStoreConfig{Retention: time.Hour, Clock: clk})
+	config.Clock = clk
+	evaluator := NewEvaluator(store, config)
+	evaluator.SetSeriesStore(series)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	require.NoError(t, evaluator.Start(ctx))
+	t.Cleanup(func() {
+		cancel()
+		evaluator.Stop()
+	})
+	clk.BlockUntil(1)
+	return evaluator, clk, series
+}
+
+// seriesTestAlert returns a test alert firing while test_metric exceeds 90
+func seriesTestAlert(t testing.TB) models.AlertConfig {
+	alert := createTestAlertConfig(t)
+	alert.Threshold.MetricType = models.MetricSeries
+	alert.Threshold.MetricName = "test_metric"
+	return alert
+}
+
+// nextEvent feeds value into test_metric each evaluation until an event arrives
+func nextEvent(t *testing.T, evaluator *Evaluator, clk *clock.Fake, series *metrics.SeriesStore, value float64) models.AlertEvent {
+	t.Helper()
+	var event models.AlertEvent
+	require.Eventually(t, func() bool {
+		clk.Advance(evaluator.config.EvaluationInterval)
+		series.Append("test_metric", nil, []metrics.Sample{{Timestamp: clk.Now(), Value: value}})
+		select {
+		case event = <-evaluator.Events():
+			return true
+		default:
+			return false
+		}
+	}, 5*time.Second, time.Millisecond)
+	return event
+}
+
 func TestNewEvaluator(t *testing.T) {
 	store := createTestAlertStore(t)
 	config := &EvaluatorConfig{
@@ -68,7 +113,6 @@ func TestNewEvaluator(t *testing.T) {
 	assert.Equal(t, config, evaluator.config)
 	assert.NotNil(t, evaluator.alertStatus)
 	assert.NotNil(t, evaluator.eventCh)
-	assert.NotNil(t, evaluator.metrics)
 
 	// Test default config
 	evaluator = NewEvaluator(store, nil)
@@ -97,9 +141,7 @@ func TestEvaluatorStart(t *testing.T) {
 	time.Sleep(50 * time.Millisecond)
 
 	// Verify the alert status was initialized
-	evaluator.statusMu.RLock()
-	status, exists := evaluator.alertStatus[testAlert.ID]
-	evaluator.statusMu.RUnlock()
+	status, exists := evaluator.GetAlertStatus(testAlert.ID)
 
 	assert.True(t, exists)
 	assert.NotNil(t, status)
@@ -111,50 +153,33 @@ func TestEvaluatorStart(t *testing.T) {
 
 func TestMetricCollection(t *testing.T) {
 	store := createTestAlertStore(t)
-	testAlert := createTestAlertConfig(t)
+	testAlert := seriesTestAlert(t)
 
 	// Create test alert
 	err := store.CreateAlert(&testAlert)
 	require.NoError(t, err)
 
-	evaluator := NewEvaluator(store, &EvaluatorConfig{
+	evaluator, clk, series := startSeriesEvaluator(t, store, &EvaluatorConfig{
 		EvaluationInterval: 50 * time.Millisecond,
 		AlertDebounceCount: 1,
 		AlertResolveCount:  1,
+		EventChannelSize:   10,
 	})
 
-	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
-	defer cancel()
-
-	err = evaluator.Start(ctx)
-	require.NoError(t, err)
-
-	// Wait for events
-	eventCount := 0
-	timeout := time.After(150 * time.Millisecond)
-	for {
-		select {
-		case event := <-evaluator.Events():
-			eventCount++
-			assert.Equal(t, testAlert.ID, event.AlertID)
-			assert.NotZero(t, event.CurrentValue)
-		case <-timeout:
-			goto end
-		}
-	}
-end:
-	evaluator.Stop()
-	assert.True(t, eventCount > 0, "Should have received at least one event")
+	event := nextEvent(t, evaluator, clk, series, 95)
+	assert.Equal(t, testAlert.ID, event.AlertID)
+	assert.Equal(t, 95.0, event.CurrentValue)
 }
 
 func TestEvaluatorStopCleanup(t *testing.T) {
 	store := createTestAlertStore(t)
 	evaluator := NewEvaluator(store, nil)
 
-	// Start and immediately stop
-	ctx := context.Background()
+	// Start and immediately stop; Stop waits for the loop to see ctx end
+	ctx, cancel := context.WithCancel(context.Background())
 	err := evaluator.Start(ctx)
 	require.NoError(t, err)
+	cancel()
 	evaluator.Stop()
 
 	// Verify channel is closed
@@ -164,95 +189,53 @@ func TestEvaluatorStopCleanup(t *testing.T) {
 
 func TestAlertStateTransitions(t *testing.T) {
 	store := createTestAlertStore(t)
-	testAlert := createTestAlertConfig(t)
+	testAlert := seriesTestAlert(t)
 
 	// Create test alert
 	err := store.CreateAlert(&testAlert)
 	require.NoError(t, err)
 
-	evaluator := NewEvaluator(store, &EvaluatorConfig{
+	evaluator, clk, series := startSeriesEvaluator(t, store, &EvaluatorConfig{
 		EvaluationInterval: 50 * time.Millisecond,
 		AlertDebounceCount: 2, // Require 2 consecutive violations to trigger
 		AlertResolveCount:  2, // Require 2 consecutive recoveries to resolve
+		EventChannelSize:   10,
 	})
 
-	// Initialize with inactive state
-	evaluator.alertStatus[testAlert.ID] = &models.AlertStatus{
-		AlertID: testAlert.ID,
-		State:   models.StateInactive,
-	}
-
-	ctx, cancel := context.WithTimeout(context.Background(), 300*time.Millisecond)
-	defer cancel()
+	event := nextEvent(t, evaluator, clk, series, 95)
+	assert.Equal(t, models.StateInactive, event.OldState)
+	assert.Equal(t, models.StatePending, event.NewState)
 
-	err = evaluator.Start(ctx)
-	require.NoError(t, err)
+	event = nextEvent(t, evaluator, clk, series, 50)
+	assert.Equal(t, models.StatePending, event.OldState)
+	assert.Equal(t, models.StateResolved, event.NewState)
 
-	// Wait and collect events
-	var events []models.AlertEvent
-	timeout := time.After(250 * time.Millisecond)
-collectEvents:
-	for {
-		select {
-		case event := <-evaluator.Events():
-			events = append(events, event)
-		case <-timeout:
-			break collectEvents
-		}
-	}
-
-	evaluator.Stop()
-
-	// Verify we got some events
-	assert.NotEmpty(t, events, "Should have received at least one event")
-}
-
-// MockMetricsCollector is a mock implementation of the MetricsCollector
-type MockMetricsCollector struct {
-	mock.Mock
-}
-
-func (m *MockMetricsCollector) GetCPU() (*metrics.CPUMetrics, error) {
-	args := m.Called()
-	return args.Get(0).(*metrics.CPUMetrics), args.Error(1)
-}
-
-func (m *MockMetricsCollector) GetMemory() (*metrics.MemoryMetrics, error) {
-	args := m.Called()
-	return args.Get(0).(*metrics.MemoryMetrics), args.Error(1)
-}
-
-func (m *MockMetricsCollector) GetNetwork() (*metrics.NetworkMetrics, error) {
-	args := m.Called()
-	return args.Get(0).(*metrics.NetworkMetrics), args.Error(1)
+	status, ok := evaluator.GetAlertStatus(testAlert.ID)
+	require.True(t, ok)
+	assert.Equal(t, models.StateResolved, status.State)
+	assert.NotNil(t, status.ResolvedAt)
 }
 
-func TestEvaluator_evaluateMetricFromCollector(t *testing.T) {
-	// Setup
-	mockCollector := new(MockMetricsCollector)
-	alertStore, _ := database.NewAlertStore(":memory:")
+func TestEvaluator_extractCPUValue(t *testing.T) {
+	alertStore := createTestAlertStore(t)
 	evaluator := NewEvaluator(alertStore, DefaultEvaluatorConfig())
-	evaluator.SetMetricsCollector(mockCollector)
-
-	// Mock CPU metrics
-	mockCollector.On("GetCPU").Return(&metrics.CPUMetrics{
+	cpu := &metrics.CPUMetrics{
 		UsagePercent: 95.0,
 		Load1:        1.5,
-	}, nil)
+	}
 
 	// Test case 1: CPU usage
-	threshold := models.ThresholdConfig{MetricType: "cpu", MetricName: "usage_percent"}
-	value, err := evaluator.evaluateMetric(threshold)
+	value, err := evaluator.extractCPUValue(cpu, "usage_percent")
 	assert.NoError(t, err)
 	assert.Equal(t, 95.0, value)
 
 	// Test case 2: CPU load
-	threshold = models.ThresholdConfig{MetricType: "cpu", MetricName: "load1"}
-	value, err = evaluator.evaluateMetric(threshold)
+	value, err = evaluator.extractCPUValue(cpu, "load1")
 	assert.NoError(t, err)
 	assert.Equal(t, 1.5, value)
 
-	mockCollector.AssertExpectations(t)
+	_, err = evaluator.extractCPUValue(cpu, "steal")
+	assert.Error(t, err)
 }
 
 func TestEvaluator_evaluateMetricDirect(t *testing.T) {
@@ -263,7 +246,7 @@ func TestEvaluator_evaluateMetricDirect(t *testing.T) {
 }
 
 func TestEvaluator_processAlertState(t *testing.T) {
-	alertStore, _ := database.NewAlertStore(":memory:")
+	alertStore := createTestAlertStore(t)
 	evaluator := NewEvaluator(alertStore, DefaultEvaluatorConfig())
 
 	alertConfig := &models.AlertConfig{
@@ -278,41 +261,39 @@ func TestEvaluator_processAlertState(t *testing.T) {
 	resolveCounters := make(map[string]int)
 
 	// Initial state: inactive
-	status, _ := evaluator.GetAlertStatus("alert-1")
-	if status == nil {
-		status = &models.AlertStatus{State: models.StateInactive}
-		evaluator.alertStatus.Update("alert-1", status)
-	}
+	evaluator.alertStatus.Update("alert-1", &models.AlertStatus{AlertID: "alert-1", State: models.StateInactive})
 
-	// Condition exceeded for the first time -> pending
+	// Condition exceeded for the first time -> still inactive, counting
 	evaluator.processAlertState(alertConfig, 95.0, true, pendingCounters, resolveCounters)
-	status, _ = evaluator.GetAlertStatus("alert-1")
-	assert.Equal(t, models.StatePending, status.State)
+	status, _ := evaluator.GetAlertStatus("alert-1")
+	assert.Equal(t, models.StateInactive, status.State)
 	assert.Equal(t, 1, pendingCounters["alert-1"])
 
-	// Condition exceeded again, reaching debounce count -> active
+	// Condition exceeded again, reaching debounce count -> pending
 	pendingCounters["alert-1"] = evaluator.config.AlertDebounceCount - 1
 	evaluator.processAlertState(alertConfig, 96.0, true, pendingCounters, resolveCounters)
 	status, _ = evaluator.GetAlertStatus("alert-1")
-	assert.Equal(t, models.StateActive, status.State)
+	assert.Equal(t, models.StatePending, status.State)
+	assert.NotNil(t, status.TriggeredAt)
 	assert.Equal(t, 0, pendingCounters["alert-1"]) // counter reset
 
-	// Condition no longer exceeded -> resolving
+	// Condition no longer exceeded -> still pending, counting
 	evaluator.processAlertState(alertConfig, 85.0, false, pendingCounters, resolveCounters)
 	status, _ = evaluator.GetAlertStatus("alert-1")
-	assert.Equal(t, models.StateResolving, status.State)
+	assert.Equal(t, models.StatePending, status.State)
 	assert.Equal(t, 1, resolveCounters["alert-1"])
 
-	// Condition still not exceeded, reaching resolve count -> inactive (resolved)
+	// Condition still not exceeded, reaching resolve count -> resolved
 	resolveCounters["alert-1"] = evaluator.config.AlertResolveCount - 1
 	evaluator.processAlertState(alertConfig, 80.0, false, pendingCounters, resolveCounters)
 	status, _ = evaluator.GetAlertStatus("alert-1")
-	assert.Equal(t, models.StateInactive, status.State)
+	assert.Equal(t, models.StateResolved, status.State)
+	assert.NotNil(t, status.ResolvedAt)
 	assert.Equal(t, 0, resolveCounters["alert-1"]) // counter reset
 }
 
 func TestEvaluator_StartStop(t *testing.T) {
-	alertStore, _ := database.NewAlertStore(":memory:")
+	alertStore := createTestAlertStore(t)
 	evaluator := NewEvaluator(alertStore, DefaultEvaluatorConfig())
 
 	ctx, cancel := context.WithCancel(context.Background())
@@ -330,22 +311,29 @@ func TestEvaluator_StartStop(t *testing.T) {
 	assert.False(t, ok, "Event channel should be closed after stopping")
 }
 
-// Add a mock for metrics.Collector's other methods if needed
-func (m *MockMetricsCollector) GetDisk() (*metrics.DiskMetrics, error) {
-	args := m.Called()
-	return args.Get(0).(*metrics.DiskMetrics), args.Error(1)
-}
-
-func (m *MockMetricsCollector) GetProcesses() ([]*metrics.ProcessMetrics, error) {
-	args := m.Called()
-	return args.Get(0).([]*metrics.ProcessMetrics), args.Error(1)
-}
-
-func (m *MockMetricsCollector) Start(ctx context.Context) error {
-	args := m.Called(ctx)
-	return args.Error(0)
-}
-
-func (m *MockMetricsCollector) Stop() {
-	m.Called()
+func TestExtractDiskValue_ClosestToFiring(t *testing.T) {
+	disks := &metrics.DiskMetrics{Partitions: []metrics.DiskUsage{
+		{Device: "/dev/sda1", Mountpoint: "/", Fstype: "ext4", Free: 40 << 30, UsedPercent: 60, InodesTotal: 1000, InodesUsedPercent: 30},
+		{Device: "/dev/sdb1", Mountpoint: "/data", Fstype: "xfs", Free: 5 << 30, UsedPercent: 95, InodesTotal: 1000, InodesUsedPercent: 10},
+		{Device: "/dev/sdc1", Mountpoint: "/backup", Fstype: "btrfs", Free: 500 << 30, UsedPercent: 20},
+	}}
+
+	tests := []struct {
+		metric   string
+		operator models.ComparisonOperator
+		want     float64
+	}{
+		{"usage_percent", models.OperatorGreaterThan, 95},
+		{"usage_percent", models.OperatorLessThan, 20},
+		{"usage_percent", models.OperatorLessThanOrEqual, 20},
+		{"inode_percent", models.OperatorGreaterThanOrEqual, 30},
+		{"inode_percent", models.OperatorLessThan, 10}, // btrfs has no inode limit
+		{"free", models.OperatorLessThan, 5 << 30},
+		{"free", models.OperatorGreaterThan, 500 << 30},
+	}
+	for _, tt := range tests {
+		value, err := extractDiskValue(disks, models.ThresholdConfig{MetricType: models.MetricDisk, MetricName: tt.metric, Operator: tt.operator})
+		require.NoError(t, err)
+		assert.Equal(t, tt.want, value, "%s %s", tt.metric, tt.operator)
+	}
 }
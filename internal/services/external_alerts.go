@@ -0,0 +1,213 @@
+// File: internal/services/external_alerts.go
+// Brief: Alerts pushed by external tools through the Alertmanager API
+// Detailed: Tracks alerts posted to /api/v2/alerts by Prometheus or other Alertmanager clients, turns firing and resolving edges into alert events for the notifier, and resolves alerts whose clients stopped re-sending them.
+// Author: drama.lin@aver.com
+// Date: 2026-10-14
+
+package services
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"argus/internal/clock"
+	"argus/internal/models"
+)
+
+// Alertmanager defaults for pushed alerts
+const (
+	// ExternalAlertResolveTimeout is when an alert without endsAt resolves if not re-sent
+	ExternalAlertResolveTimeout = 5 * time.Minute
+	// ExternalAlertRetention is how long resolved alerts stay listed
+	ExternalAlertRetention = 15 * time.Minute
+	// ExternalAlertSystem is the system of pushed alerts that do not name one
+	ExternalAlertSystem = "alertmanager"
+)
+
+// ExternalAlert is an alert received from an Alertmanager client
+type ExternalAlert struct {
+	Fingerprint  string            `json:"fingerprint"`
+	Labels       map[string]string `json:"labels"`
+	Annotations  map[string]string `json:"annotations,omitempty"`
+	StartsAt     time.Time         `json:"startsAt"`
+	EndsAt       time.Time         `json:"endsAt"`
+	UpdatedAt    time.Time         `json:"updatedAt"`
+	GeneratorURL string            `json:"generatorURL,omitempty"`
+	Source       string            `json:"source"` // Where the alert originated, see models.AlertEvent.Source
+
+	firing bool // Whether a firing event was sent and not yet resolved
+}
+
+// Firing reports whether the alert is firing at the given time
+func (a *ExternalAlert) Firing(at time.Time) bool {
+	return !at.Before(a.StartsAt) && at.Before(a.EndsAt)
+}
+
+// Config returns the alert configuration the notifier renders for this alert
+func (a *ExternalAlert) Config() *models.AlertConfig {
+	severity := models.AlertSeverity(a.Labels[models.LabelSeverity])
+	switch severity {
+	case models.SeverityInfo, models.SeverityWarning, models.SeverityCritical:
+	default:
+		severity = models.SeverityWarning
+	}
+	description := a.Annotations["description"]
+	if description == "" {
+		description = a.Annotations["summary"]
+	}
+	return &models.AlertConfig{
+		ID:          "external-" + a.Fingerprint,
+		Name:        a.Labels[models.LabelAlertName],
+		Description: description,
+		Enabled:     true,
+		Severity:    severity,
+		Labels:      a.Labels,
+		CreatedAt:   a.StartsAt,
+		UpdatedAt:   a.UpdatedAt,
+	}
+}
+
+// ExternalAlerts keeps pushed alerts and notifies on their state changes
+type ExternalAlerts struct {
+	mu       sync.Mutex
+	alerts   map[string]*ExternalAlert
+	notifier *Notifier
+	clock    clock.Clock
+}
+
+// NewExternalAlerts creates an empty registry notifying through notifier (may be nil)
+func NewExternalAlerts(notifier *Notifier, clk clock.Clock) *ExternalAlerts {
+	return &ExternalAlerts{
+		alerts:   make(map[string]*ExternalAlert),
+		notifier: notifier,
+		clock:    clock.OrReal(clk),
+	}
+}
+
+// Receive records pushed alerts. An alert without startsAt starts now and one
+// without endsAt resolves after ExternalAlertResolveTimeout unless re-sent;
+// one without a source comes from ExternalAlertSystem.
+func (x *ExternalAlerts) Receive(alerts []ExternalAlert) {
+	now := x.clock.Now().UTC()
+
+	x.mu.Lock()
+	var events []models.AlertEvent
+	for _, in := range alerts {
+		fp := models.Fingerprint(in.Labels)
+		alert, ok := x.alerts[fp]
+		if !ok {
+			alert = &ExternalAlert{Fingerprint: fp, Labels: in.Labels}
+			x.alerts[fp] = alert
+		}
+		alert.Annotations = in.Annotations
+		alert.GeneratorURL = in.GeneratorURL
+		alert.Source = in.Source
+		if alert.Source == "" {
+			alert.Source = models.ExternalSource(ExternalAlertSystem)
+		}
+		alert.UpdatedAt = now
+		switch {
+		case !in.StartsAt.IsZero():
+			alert.StartsAt = in.StartsAt.UTC()
+		case !ok || !alert.firing:
+			alert.StartsAt = now
+		}
+		if in.EndsAt.IsZero() {
+			alert.EndsAt = now.Add(ExternalAlertResolveTimeout)
+		} else {
+			alert.EndsAt = in.EndsAt.UTC()
+		}
+		if event, changed := x.transition(alert, now); changed {
+			events = append(events, event)
+		}
+	}
+	x.mu.Unlock()
+
+	x.notify(events)
+}
+
+// transition updates the alert's notified state and returns the event for a change
+func (x *ExternalAlerts) transition(alert *ExternalAlert, now time.Time) (models.AlertEvent, bool) {
+	firing := alert.Firing(now)
+	if firing == alert.firing {
+		return models.AlertEvent{}, false
+	}
+	alert.firing = firing
+
+	event := models.AlertEvent{
+		OldState:  models.StateInactive,
+		NewState:  models.StateActive,
+		Timestamp: now,
+		Message:   alert.Annotations["summary"],
+		Source:    alert.Source,
+	}
+	if !firing {
+		event.OldState, event.NewState = models.StateActive, models.StateResolved
+	}
+	event.Alert = alert.Config()
+	event.AlertID = event.Alert.ID
+	event.Status = &models.AlertStatus{AlertID: event.AlertID, State: event.NewState, TriggeredAt: &alert.StartsAt, Source: alert.Source}
+	if !firing {
+		event.Status.ResolvedAt = &alert.EndsAt
+	}
+	return event, true
+}
+
+func (x *ExternalAlerts) notify(events []models.AlertEvent) {
+	if x.notifier == nil {
+		return
+	}
+	for _, event := range events {
+		x.notifier.ProcessEvent(event)
+	}
+}
+
+// Sweep resolves alerts whose endsAt has passed and forgets those resolved
+// longer than ExternalAlertRetention ago
+func (x *ExternalAlerts) Sweep() {
+	now := x.clock.Now().UTC()
+
+	x.mu.Lock()
+	var events []models.AlertEvent
+	for fp, alert := range x.alerts {
+		if event, changed := x.transition(alert, now); changed {
+			events = append(events, event)
+		}
+		if !alert.firing && alert.EndsAt.Add(ExternalAlertRetention).Before(now) {
+			delete(x.alerts, fp)
+		}
+	}
+	x.mu.Unlock()
+
+	x.notify(events)
+}
+
+// Start sweeps every interval until ctx is cancelled
+func (x *ExternalAlerts) Start(ctx context.Context, interval time.Duration) {
+	go func() {
+		ticker := x.clock.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C():
+				x.Sweep()
+			}
+		}
+	}()
+}
+
+// List returns copies of the known alerts ordered by fingerprint
+func (x *ExternalAlerts) List() []ExternalAlert {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	result := make([]ExternalAlert, 0, len(x.alerts))
+	for _, alert := range x.alerts {
+		result = append(result, *alert)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Fingerprint < result[j].Fingerprint })
+	return result
+}
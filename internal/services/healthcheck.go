@@ -0,0 +1,181 @@
+// File: internal/services/healthcheck.go
+// Brief: Health check task runner
+// Detailed: Runs health_check tasks by probing their HTTP endpoints concurrently with a bounded worker pool, each with its own timeout and expected status, so a slow or unreachable endpoint no longer delays the others. The execution output is the structured result of every probe; the execution fails when any endpoint is unhealthy. The latest probe of each endpoint is kept for http alerts.
+// Author: drama.lin@aver.com
+// Date: 2026-10-14
+
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"argus/internal/models"
+)
+
+// maxProbeBody bounds the response body read from a probed endpoint
+const maxProbeBody = 64 << 10
+
+type HealthCheckRunner struct {
+	BaseTaskRunner
+	client  *http.Client     // nil uses http.DefaultClient
+	results *EndpointResults // Latest probes, for http alerts (nil keeps none)
+}
+
+// SetTransport sets the transport of endpoint probes, e.g. one using a proxy
+func (r *HealthCheckRunner) SetTransport(transport http.RoundTripper) {
+	r.client = &http.Client{Transport: transport}
+}
+
+// SetResults sets where the runner keeps the latest probe of each endpoint
+func (r *HealthCheckRunner) SetResults(results *EndpointResults) {
+	r.results = results
+}
+
+// EndpointResults keeps the latest probe of every health check endpoint, by
+// name and by URL, so http alerts can evaluate them between task runs
+type EndpointResults struct {
+	mu      sync.RWMutex
+	results map[string]models.HealthProbeResult
+}
+
+// NewEndpointResults returns an empty result store
+func NewEndpointResults() *EndpointResults {
+	return &EndpointResults{results: make(map[string]models.HealthProbeResult)}
+}
+
+// Record replaces the latest probes of the endpoints in results
+func (r *EndpointResults) Record(results []models.HealthProbeResult) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, result := range results {
+		r.results[result.URL] = result
+		r.results[result.Name] = result
+	}
+}
+
+// Get returns the latest probe of the endpoint with the given name or URL
+func (r *EndpointResults) Get(endpoint string) (models.HealthProbeResult, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	result, ok := r.results[endpoint]
+	return result, ok
+}
+
+func (r *HealthCheckRunner) Run(ctx context.Context, task *models.TaskConfig) (*models.TaskExecution, error) {
+	params, err := models.ParseHealthCheckParams(task.Parameters)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidParameter, err)
+	}
+
+	execution := models.NewTaskExecution(task.ID)
+	execution.TaskName = task.Name
+	execution.TaskType = task.Type
+	execution.Start()
+
+	results := r.probeEndpoints(ctx, params.Endpoints, params.Concurrency)
+	if r.results != nil && ctx.Err() == nil {
+		r.results.Record(results)
+	}
+	var unhealthy []string
+	for _, result := range results {
+		if !result.Healthy {
+			unhealthy = append(unhealthy, result.Name)
+		}
+	}
+	output, err := json.MarshalIndent(results, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode health check results: %w", err)
+	}
+	execution.Metadata = map[string]string{
+		"endpoints": strconv.Itoa(len(results)),
+		"healthy":   strconv.Itoa(len(results) - len(unhealthy)),
+		"unhealthy": strconv.Itoa(len(unhealthy)),
+	}
+	if len(unhealthy) > 0 {
+		execution.Fail(fmt.Sprintf("%d of %d endpoints unhealthy: %s", len(unhealthy), len(results), strings.Join(unhealthy, ", ")))
+		execution.Output = string(output)
+	} else {
+		execution.Complete(string(output))
+	}
+	return execution, nil
+}
+
+// probeEndpoints probes the endpoints with at most concurrency at once and
+// returns their results in the order of endpoints
+func (r *HealthCheckRunner) probeEndpoints(ctx context.Context, endpoints []models.HealthEndpoint, concurrency int) []models.HealthProbeResult {
+	results := make([]models.HealthProbeResult, len(endpoints))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < min(max(concurrency, 1), len(endpoints)); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				results[i] = r.probe(ctx, endpoints[i])
+			}
+		}()
+	}
+	for i := range endpoints {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+	return results
+}
+
+// probe sends one request to the endpoint within its timeout
+func (r *HealthCheckRunner) probe(ctx context.Context, endpoint models.HealthEndpoint) models.HealthProbeResult {
+	result := models.HealthProbeResult{Name: endpoint.Name, URL: endpoint.URL, Expected: endpoint.ExpectedStatus}
+	started := time.Now()
+	status, err := r.send(ctx, endpoint)
+	result.DurationMs = float64(time.Since(started)) / float64(time.Millisecond)
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+
+	result.Status = status
+	result.Healthy = endpoint.Healthy(status)
+	if !result.Healthy {
+		expected := "2xx"
+		if endpoint.ExpectedStatus != 0 {
+			expected = strconv.Itoa(endpoint.ExpectedStatus)
+		}
+		result.Error = fmt.Sprintf("unexpected status %d, expected %s", status, expected)
+	}
+	return result
+}
+
+// send requests the endpoint and returns the response status
+func (r *HealthCheckRunner) send(ctx context.Context, endpoint models.HealthEndpoint) (int, error) {
+	ctx, cancel := context.WithTimeout(ctx, endpoint.TimeoutDuration())
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, endpoint.Method, endpoint.URL, nil)
+	if err != nil {
+		return 0, err
+	}
+	client := r.client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return 0, fmt.Errorf("timed out after %s", endpoint.TimeoutDuration())
+		}
+		return 0, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, maxProbeBody))
+	return resp.StatusCode, nil
+}
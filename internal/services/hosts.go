@@ -0,0 +1,210 @@
+// File: internal/services/hosts.go
+// Brief: Inventory of agents reporting to a central server
+// Detailed: Registers hosts from agent heartbeats, tracks their labels, agent version, reported health and last-seen time, and marks agents that stopped reporting as stale, raising an ArgusAgentDown alert for each until it reports again or is removed.
+// Author: drama.lin@aver.com
+// Date: 2026-10-14
+
+package services
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sort"
+	"sync"
+	"time"
+
+	"argus/internal/clock"
+	"argus/internal/models"
+)
+
+// DefaultHostStaleAfter is how long an agent may go without reporting
+const DefaultHostStaleAfter = 2 * time.Minute
+
+// HostDownAlertName is the alertname of the alert raised for a stale agent
+const HostDownAlertName = "ArgusAgentDown"
+
+// HostRegistryConfig holds configuration for the host registry
+type HostRegistryConfig struct {
+	StaleAfter time.Duration // Time without a heartbeat after which a host is stale
+	Clock      clock.Clock   // Time source (nil uses the real clock)
+}
+
+// DefaultHostRegistryConfig returns default configuration for the host registry
+func DefaultHostRegistryConfig() HostRegistryConfig {
+	return HostRegistryConfig{StaleAfter: DefaultHostStaleAfter}
+}
+
+// HostRegistry keeps the hosts whose agents report to this server
+type HostRegistry struct {
+	config HostRegistryConfig
+	clock  clock.Clock
+	alerts *ExternalAlerts
+
+	mu    sync.Mutex
+	hosts map[string]*models.Host
+}
+
+// NewHostRegistry creates an empty registry raising stale-agent alerts
+// through alerts (may be nil)
+func NewHostRegistry(config HostRegistryConfig, alerts *ExternalAlerts) *HostRegistry {
+	if config.StaleAfter <= 0 {
+		config.StaleAfter = DefaultHostStaleAfter
+	}
+	return &HostRegistry{
+		config: config,
+		clock:  clock.OrReal(config.Clock),
+		alerts: alerts,
+		hosts:  make(map[string]*models.Host),
+	}
+}
+
+// StaleAfter returns the time without a heartbeat after which a host is stale
+func (r *HostRegistry) StaleAfter() time.Duration {
+	return r.config.StaleAfter
+}
+
+// Heartbeat records a report from the agent at address, registering its host
+// on the first one. The heartbeat must have been validated.
+func (r *HostRegistry) Heartbeat(hb models.HostHeartbeat, address string) models.Host {
+	now := r.clock.Now().UTC()
+
+	r.mu.Lock()
+	host, ok := r.hosts[hb.ID]
+	if !ok {
+		host = &models.Host{ID: hb.ID, RegisteredAt: now}
+		r.hosts[hb.ID] = host
+		slog.Info("Host registered", "host", hb.ID, "hostname", hb.Hostname, "agent_version", hb.AgentVersion)
+	}
+	recovered := ok && host.Status == models.HostStale
+	host.Hostname = hb.Hostname
+	host.Labels = hb.Labels
+	host.AgentVersion = hb.AgentVersion
+	host.Address = address
+	host.Health = hb.Health
+	host.HealthMessage = hb.HealthMessage
+	host.Tasks = hb.Tasks
+	host.Status = models.HostUp
+	host.LastSeen = now
+	result := *host
+	r.mu.Unlock()
+
+	if recovered {
+		slog.Info("Host reporting again", "host", hb.ID)
+		r.resolve(result, now)
+	}
+	return result
+}
+
+// List returns the registered hosts ordered by hostname and ID
+func (r *HostRegistry) List() []models.Host {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	result := make([]models.Host, 0, len(r.hosts))
+	for _, host := range r.hosts {
+		result = append(result, *host)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Hostname != result[j].Hostname {
+			return result[i].Hostname < result[j].Hostname
+		}
+		return result[i].ID < result[j].ID
+	})
+	return result
+}
+
+// Get returns the host with the given ID
+func (r *HostRegistry) Get(id string) (models.Host, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	host, ok := r.hosts[id]
+	if !ok {
+		return models.Host{}, false
+	}
+	return *host, true
+}
+
+// Remove forgets a host, e.g. one decommissioned on purpose, resolving its
+// stale-agent alert
+func (r *HostRegistry) Remove(id string) bool {
+	r.mu.Lock()
+	host, ok := r.hosts[id]
+	delete(r.hosts, id)
+	r.mu.Unlock()
+
+	if ok && host.Status == models.HostStale {
+		r.resolve(*host, r.clock.Now().UTC())
+	}
+	return ok
+}
+
+// Sweep marks hosts without a recent heartbeat stale and re-sends the alerts
+// of every stale host, so they stay firing
+func (r *HostRegistry) Sweep() {
+	now := r.clock.Now().UTC()
+	cutoff := now.Add(-r.config.StaleAfter)
+
+	r.mu.Lock()
+	var stale []models.Host
+	for _, host := range r.hosts {
+		if host.LastSeen.Before(cutoff) {
+			if host.Status != models.HostStale {
+				host.Status = models.HostStale
+				slog.Warn("Host stopped reporting", "host", host.ID, "last_seen", host.LastSeen)
+			}
+			stale = append(stale, *host)
+		}
+	}
+	r.mu.Unlock()
+
+	if r.alerts == nil || len(stale) == 0 {
+		return
+	}
+	alerts := make([]ExternalAlert, 0, len(stale))
+	for _, host := range stale {
+		alerts = append(alerts, hostDownAlert(host))
+	}
+	r.alerts.Receive(alerts)
+}
+
+// Start sweeps every interval until ctx is cancelled
+func (r *HostRegistry) Start(ctx context.Context, interval time.Duration) {
+	go func() {
+		ticker := r.clock.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C():
+				r.Sweep()
+			}
+		}
+	}()
+}
+
+// resolve ends the stale-agent alert of host
+func (r *HostRegistry) resolve(host models.Host, now time.Time) {
+	if r.alerts == nil {
+		return
+	}
+	alert := hostDownAlert(host)
+	alert.EndsAt = now
+	r.alerts.Receive([]ExternalAlert{alert})
+}
+
+// hostDownAlert is the alert raised while host's agent is not reporting
+func hostDownAlert(host models.Host) ExternalAlert {
+	return ExternalAlert{
+		Labels: map[string]string{
+			models.LabelAlertName: HostDownAlertName,
+			models.LabelSeverity:  string(models.SeverityCritical),
+			models.LabelHost:      host.ID,
+		},
+		Annotations: map[string]string{
+			"summary":     fmt.Sprintf("Agent on %s stopped reporting", host.Hostname),
+			"description": fmt.Sprintf("No heartbeat from host %s (%s) since %s", host.ID, host.Hostname, host.LastSeen.Format(time.RFC3339)),
+		},
+		Source: models.AgentSource(host.ID),
+	}
+}
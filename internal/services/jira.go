@@ -0,0 +1,344 @@
+// File: internal/services/jira.go
+// Brief: Jira notification channel opening an issue per critical alert
+// Detailed: Opens a Jira issue through the REST API when a critical alert with a jira notification activates, in the project and with the issue type and labels of its settings or the server defaults, and comments on the issue and applies the resolve transition when the alert resolves. The issue opened for each alert is kept in a JSON file next to the alert configurations, so a restart between activation and resolution does not lose track of it. Requests authenticate with the account email and API token, or the token alone as a bearer token.
+// Author: drama.lin@aver.com
+// Date: 2026-10-14
+
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"argus/internal/models"
+	"argus/internal/tmplfunc"
+)
+
+// Jira channel defaults
+const (
+	DefaultJiraIssueType = "Task"
+	DefaultJiraTimeout   = 10 * time.Second
+)
+
+// JiraConfig configures the jira channel
+type JiraConfig struct {
+	URL               string            // Base URL, e.g. https://example.atlassian.net
+	User              string            // Account email of the token; empty sends the token as a bearer token
+	APIToken          string            // API or personal access token
+	Project           string            // Project key of alerts not setting one
+	IssueType         string            // Issue type of alerts not setting one
+	ResolveTransition string            // Transition applied on resolution, e.g. Done; empty only comments
+	StatePath         string            // JSON file of the issues opened per alert
+	Timeout           time.Duration     // Per request
+	Transport         http.RoundTripper // nil uses http.DefaultTransport
+	Limits            tmplfunc.Limits   // Execution limits of label templates
+}
+
+// JiraIssue is the issue opened for an alert
+type JiraIssue struct {
+	Key       string    `json:"key"` // e.g. OPS-42
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// JiraChannel opens and resolves Jira issues for critical alerts
+type JiraChannel struct {
+	config JiraConfig
+	client *http.Client
+	sendMu sync.Mutex // Keeps an activation and the resolution of an alert in order
+	mu     sync.Mutex
+	issues map[string]JiraIssue // Alert ID to its open issue
+}
+
+// NewJiraChannel creates the channel and loads the issues left open before a
+// restart
+func NewJiraChannel(config JiraConfig) (*JiraChannel, error) {
+	if config.IssueType == "" {
+		config.IssueType = DefaultJiraIssueType
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = DefaultJiraTimeout
+	}
+	config.URL = strings.TrimRight(config.URL, "/")
+	c := &JiraChannel{
+		config: config,
+		client: &http.Client{Timeout: config.Timeout, Transport: config.Transport},
+		issues: make(map[string]JiraIssue),
+	}
+	if config.StatePath != "" {
+		data, err := os.ReadFile(config.StatePath)
+		switch {
+		case errors.Is(err, os.ErrNotExist):
+		case err != nil:
+			return nil, fmt.Errorf("failed to read jira issues: %w", err)
+		default:
+			if err := json.Unmarshal(data, &c.issues); err != nil {
+				return nil, fmt.Errorf("failed to parse jira issues: %w", err)
+			}
+		}
+	}
+	return c, nil
+}
+
+// jiraSettings returns the settings of the alert's first enabled jira
+// notification, or nil if it has none
+func jiraSettings(alert *models.AlertConfig) map[string]interface{} {
+	if alert == nil {
+		return nil
+	}
+	for _, notif := range alert.Notifications {
+		if notif.Type == models.NotificationJira && notif.Enabled {
+			if notif.Settings == nil {
+				return map[string]interface{}{}
+			}
+			return notif.Settings
+		}
+	}
+	return nil
+}
+
+// Send opens an issue when a critical alert activates and resolves it when
+// the alert resolves; other events and alerts without a jira notification
+// are skipped
+func (c *JiraChannel) Send(event models.AlertEvent, subject, body string) error {
+	settings := jiraSettings(event.Alert)
+	if settings == nil {
+		return nil
+	}
+	c.sendMu.Lock()
+	defer c.sendMu.Unlock()
+
+	issue, open := c.issue(event.AlertID)
+	switch {
+	case event.NewState.Firing() && event.Alert.Severity == models.SeverityCritical:
+		if open {
+			return nil // Repeated notification, or pending moving on to active
+		}
+		return c.open(event, settings, subject, body)
+	case event.NewState == models.StateResolved && open:
+		return c.resolve(event, issue, body)
+	}
+	return nil
+}
+
+func (c *JiraChannel) issue(alertID string) (JiraIssue, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	issue, ok := c.issues[alertID]
+	return issue, ok
+}
+
+// open creates the issue of an activation and records it for the resolution
+func (c *JiraChannel) open(event models.AlertEvent, settings map[string]interface{}, subject, body string) error {
+	project, _ := settings["project"].(string)
+	if project == "" {
+		project = c.config.Project
+	}
+	if project == "" {
+		return fmt.Errorf("jira notification of alert %s has no project", event.AlertID)
+	}
+	issueType, _ := settings["issue_type"].(string)
+	if issueType == "" {
+		issueType = c.config.IssueType
+	}
+	labels, err := c.labels(event, settings, subject, body)
+	if err != nil {
+		return fmt.Errorf("failed to render jira labels: %w", err)
+	}
+
+	fields := map[string]interface{}{
+		"project":     map[string]string{"key": project},
+		"issuetype":   map[string]string{"name": issueType},
+		"summary":     jiraSummary(subject),
+		"description": body,
+	}
+	if len(labels) > 0 {
+		fields["labels"] = labels
+	}
+	var created struct {
+		Key string `json:"key"`
+	}
+	if err := c.do(http.MethodPost, "/rest/api/2/issue", map[string]interface{}{"fields": fields}, &created); err != nil {
+		return fmt.Errorf("failed to create jira issue: %w", err)
+	}
+	if created.Key == "" {
+		return errors.New("failed to create jira issue: response has no issue key")
+	}
+
+	c.mu.Lock()
+	c.issues[event.AlertID] = JiraIssue{Key: created.Key, CreatedAt: event.Timestamp}
+	err = c.save()
+	c.mu.Unlock()
+	if err != nil {
+		// The issue exists; only a restart before the resolution loses track of it
+		slog.Error("Failed to save jira issues", "error", err)
+	}
+	slog.Info("Jira issue created", "alert_id", event.AlertID, "issue", created.Key)
+	return nil
+}
+
+// resolve comments on the issue of a resolved alert and transitions it
+func (c *JiraChannel) resolve(event models.AlertEvent, issue JiraIssue, body string) error {
+	path := "/rest/api/2/issue/" + url.PathEscape(issue.Key)
+	if err := c.do(http.MethodPost, path+"/comment", map[string]string{"body": body}, nil); err != nil {
+		return fmt.Errorf("failed to comment on jira issue %s: %w", issue.Key, err)
+	}
+	if name := c.config.ResolveTransition; name != "" {
+		var available struct {
+			Transitions []struct {
+				ID   string `json:"id"`
+				Name string `json:"name"`
+				To   struct {
+					Name string `json:"name"`
+				} `json:"to"`
+			} `json:"transitions"`
+		}
+		if err := c.do(http.MethodGet, path+"/transitions", nil, &available); err != nil {
+			return fmt.Errorf("failed to list transitions of jira issue %s: %w", issue.Key, err)
+		}
+		id := ""
+		for _, t := range available.Transitions {
+			if strings.EqualFold(t.Name, name) || strings.EqualFold(t.To.Name, name) {
+				id = t.ID
+				break
+			}
+		}
+		if id == "" {
+			// Already transitioned by hand; the comment records the resolution
+			slog.Warn("Jira issue has no resolve transition", "issue", issue.Key, "transition", name)
+		} else if err := c.do(http.MethodPost, path+"/transitions", map[string]interface{}{"transition": map[string]string{"id": id}}, nil); err != nil {
+			return fmt.Errorf("failed to transition jira issue %s: %w", issue.Key, err)
+		}
+	}
+
+	c.mu.Lock()
+	delete(c.issues, event.AlertID)
+	err := c.save()
+	c.mu.Unlock()
+	if err != nil {
+		slog.Error("Failed to save jira issues", "error", err)
+	}
+	slog.Info("Jira issue resolved", "alert_id", event.AlertID, "issue", issue.Key)
+	return nil
+}
+
+// labels renders the label templates of the settings over the fields of the
+// webhook payload. Jira labels cannot contain spaces, so runs of whitespace
+// become dashes; empty and repeated labels are dropped.
+func (c *JiraChannel) labels(event models.AlertEvent, settings map[string]interface{}, subject, body string) ([]string, error) {
+	list, _ := settings["labels"].([]interface{})
+	if len(list) == 0 {
+		return nil, nil
+	}
+	data := webhookDelivery{Event: event, Subject: subject, Body: body}.payload()
+	labels := make([]string, 0, len(list))
+	seen := make(map[string]bool, len(list))
+	for i, item := range list {
+		text, _ := item.(string)
+		tmpl, err := tmplfunc.ParseSandboxedText(fmt.Sprintf("labels[%d]", i), text, c.config.Limits)
+		if err != nil {
+			return nil, err
+		}
+		label, err := tmplfunc.ExecuteText(tmpl, data, c.config.Limits)
+		if err != nil {
+			return nil, err
+		}
+		label = strings.Join(strings.Fields(label), "-")
+		if label == "" || seen[label] {
+			continue
+		}
+		seen[label] = true
+		labels = append(labels, label)
+	}
+	return labels, nil
+}
+
+// jiraSummary shortens a subject to the 255 characters Jira accepts
+func jiraSummary(subject string) string {
+	subject = strings.Join(strings.Fields(subject), " ")
+	if runes := []rune(subject); len(runes) > 255 {
+		return string(runes[:254]) + "…"
+	}
+	return subject
+}
+
+// do sends a request with a JSON body and decodes the response into out when set
+func (c *JiraChannel) do(method, path string, in, out interface{}) error {
+	var body io.Reader
+	if in != nil {
+		data, err := json.Marshal(in)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(data)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), c.config.Timeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, method, c.config.URL+path, body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	req.Header.Set("User-Agent", "Argus")
+	if c.config.User != "" {
+		req.SetBasicAuth(c.config.User, c.config.APIToken)
+	} else {
+		req.Header.Set("Authorization", "Bearer "+c.config.APIToken)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("jira request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		detail, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("jira returned HTTP %d: %s", resp.StatusCode, strings.TrimSpace(string(detail)))
+	}
+	if out == nil {
+		io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10)) // Lets the connection be reused
+		return nil
+	}
+	return json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(out)
+}
+
+// save writes the open issues; the caller holds c.mu
+func (c *JiraChannel) save() error {
+	if c.config.StatePath == "" {
+		return nil
+	}
+	data, err := json.MarshalIndent(c.issues, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(c.config.StatePath), 0755); err != nil {
+		return err
+	}
+	tmp := c.config.StatePath + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, c.config.StatePath)
+}
+
+func (c *JiraChannel) Type() models.NotificationType {
+	return models.NotificationJira
+}
+
+func (c *JiraChannel) Name() string {
+	return "Jira Issues"
+}
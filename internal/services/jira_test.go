@@ -0,0 +1,138 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"argus/internal/models"
+)
+
+// fakeJira records the issues, comments and transitions posted to it
+type fakeJira struct {
+	mu          sync.Mutex
+	created     []map[string]interface{} // Fields of each created issue
+	comments    map[string][]string
+	transitions map[string][]string
+}
+
+func newFakeJira(t *testing.T) (*fakeJira, *httptest.Server) {
+	j := &fakeJira{comments: make(map[string][]string), transitions: make(map[string][]string)}
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /rest/api/2/issue", func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Fields map[string]interface{} `json:"fields"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		j.mu.Lock()
+		j.created = append(j.created, req.Fields)
+		key := fmt.Sprintf("OPS-%d", len(j.created))
+		j.mu.Unlock()
+		json.NewEncoder(w).Encode(map[string]string{"key": key})
+	})
+	mux.HandleFunc("POST /rest/api/2/issue/{key}/comment", func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Body string `json:"body"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		j.mu.Lock()
+		j.comments[r.PathValue("key")] = append(j.comments[r.PathValue("key")], req.Body)
+		j.mu.Unlock()
+	})
+	mux.HandleFunc("GET /rest/api/2/issue/{key}/transitions", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"transitions":[{"id":"21","name":"In Progress","to":{"name":"In Progress"}},{"id":"31","name":"Close","to":{"name":"Done"}}]}`))
+	})
+	mux.HandleFunc("POST /rest/api/2/issue/{key}/transitions", func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Transition struct {
+				ID string `json:"id"`
+			} `json:"transition"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		j.mu.Lock()
+		j.transitions[r.PathValue("key")] = append(j.transitions[r.PathValue("key")], req.Transition.ID)
+		j.mu.Unlock()
+		w.WriteHeader(http.StatusNoContent)
+	})
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	return j, srv
+}
+
+func jiraTestEvent(t *testing.T, state models.AlertState) models.AlertEvent {
+	event := createTestAlertEvent(t)
+	event.NewState = state
+	event.Alert.Notifications = []models.NotificationConfig{{
+		Type:     models.NotificationJira,
+		Enabled:  true,
+		Settings: map[string]any{"labels": []interface{}{"argus", "{{ .Severity }}"}},
+	}}
+	return event
+}
+
+func TestJiraChannel_CreateAndDedupe(t *testing.T) {
+	jira, srv := newFakeJira(t)
+	config := JiraConfig{
+		URL:               srv.URL + "/",
+		User:              "ops@example.com",
+		APIToken:          "secret",
+		Project:           "OPS",
+		ResolveTransition: "Done",
+		StatePath:         filepath.Join(t.TempDir(), "jira_issues.json"),
+	}
+	channel, err := NewJiraChannel(config)
+	require.NoError(t, err)
+
+	// A critical alert going pending opens one issue
+	require.NoError(t, channel.Send(jiraTestEvent(t, models.StatePending), "Test Subject", "Test Body"))
+	require.Len(t, jira.created, 1)
+	fields := jira.created[0]
+	assert.Equal(t, map[string]interface{}{"key": "OPS"}, fields["project"])
+	assert.Equal(t, map[string]interface{}{"name": DefaultJiraIssueType}, fields["issuetype"])
+	assert.Equal(t, "Test Subject", fields["summary"])
+	assert.Equal(t, "Test Body", fields["description"])
+	assert.Equal(t, []interface{}{"argus", "critical"}, fields["labels"])
+
+	// Further firing notifications reuse it, also after a restart
+	require.NoError(t, channel.Send(jiraTestEvent(t, models.StateActive), "Test Subject", "Test Body"))
+	channel, err = NewJiraChannel(config)
+	require.NoError(t, err)
+	require.NoError(t, channel.Send(jiraTestEvent(t, models.StateActive), "Test Subject", "Test Body"))
+	assert.Len(t, jira.created, 1)
+
+	// The resolution comments on the issue and applies the resolve transition
+	require.NoError(t, channel.Send(jiraTestEvent(t, models.StateResolved), "Resolved", "Back to normal"))
+	assert.Equal(t, []string{"Back to normal"}, jira.comments["OPS-1"])
+	assert.Equal(t, []string{"31"}, jira.transitions["OPS-1"])
+	_, open := channel.issue("test-alert")
+	assert.False(t, open)
+
+	// The next activation opens a new issue
+	require.NoError(t, channel.Send(jiraTestEvent(t, models.StatePending), "Test Subject", "Test Body"))
+	assert.Len(t, jira.created, 2)
+	issue, open := channel.issue("test-alert")
+	assert.True(t, open)
+	assert.Equal(t, "OPS-2", issue.Key)
+}
+
+func TestJiraChannel_SkipsNonCritical(t *testing.T) {
+	jira, srv := newFakeJira(t)
+	channel, err := NewJiraChannel(JiraConfig{URL: srv.URL, APIToken: "secret", Project: "OPS"})
+	require.NoError(t, err)
+
+	event := jiraTestEvent(t, models.StatePending)
+	event.Alert.Severity = models.SeverityWarning
+	require.NoError(t, channel.Send(event, "Test Subject", "Test Body"))
+
+	// Resolving an alert without an open issue does nothing either
+	require.NoError(t, channel.Send(jiraTestEvent(t, models.StateResolved), "Resolved", "Back to normal"))
+	assert.Empty(t, jira.created)
+	assert.Empty(t, jira.comments)
+}
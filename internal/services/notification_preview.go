@@ -0,0 +1,64 @@
+// File: internal/services/notification_preview.go
+// Brief: Rendering of notifications for every channel without sending them
+// Detailed: Renders an alert event through the same path as delivery, with each registered channel's own template, timezone, locale and redaction and a webhook's payload mapping, so template changes can be checked before an alert fires. Silences, rate limits and circuit breakers are not consulted and nothing is sent, queued or recorded.
+// Author: drama.lin@aver.com
+// Date: 2026-10-14
+
+package services
+
+import (
+	"encoding/json"
+	"sort"
+
+	"argus/internal/models"
+)
+
+// NotificationPreview is an event rendered for one channel as it would be
+// sent. Skipped explains why it would not be delivered ("no_recipient"), and
+// Error why it failed to render.
+type NotificationPreview struct {
+	Channel   models.NotificationType            `json:"channel"`
+	Subject   string                             `json:"subject"`
+	Body      string                             `json:"body"`
+	Payload   json.RawMessage                    `json:"payload,omitempty"`   // Webhook payload mapping, rendered
+	Localized map[string]models.LocalizedContent `json:"localized,omitempty"` // In-app notifications in every locale
+	Skipped   string                             `json:"skipped,omitempty"`
+	Error     string                             `json:"error,omitempty"`
+}
+
+// PreviewEvent renders event for every registered channel, ordered by
+// channel, as ProcessEvent would send it
+func (n *Notifier) PreviewEvent(event models.AlertEvent) []NotificationPreview {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+
+	types := make([]models.NotificationType, 0, len(n.channels))
+	for typ := range n.channels {
+		types = append(types, typ)
+	}
+	sort.Slice(types, func(i, j int) bool { return types[i] < types[j] })
+
+	previews := make([]NotificationPreview, 0, len(types))
+	for _, typ := range types {
+		channel := n.channels[typ]
+		preview := NotificationPreview{Channel: typ}
+		if !hasRecipient(typ, event.Alert) {
+			preview.Skipped = "no_recipient"
+		}
+		subject, body, localized, err := n.prepare(typ, channel, event)
+		if err != nil {
+			preview.Error = err.Error()
+			previews = append(previews, preview)
+			continue
+		}
+		preview.Subject, preview.Body, preview.Localized = subject, body, localized
+		if webhook, ok := channel.(*WebhookChannel); ok && preview.Skipped == "" {
+			d := webhookDelivery{Event: event, Subject: subject, Body: body}
+			if preview.Payload, err = webhook.renderPayload(webhookSettings(event.Alert), d); err != nil {
+				preview.Error = err.Error()
+			}
+		}
+		previews = append(previews, preview)
+	}
+	return previews
+}
@@ -8,19 +8,30 @@
 package services
 
 import (
+	"bytes"
 	"context"
 	"crypto/tls"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"html/template"
 	"log/slog"
+	"maps"
 	"net/smtp"
+	"os/exec"
+	"sort"
 	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
 
+	"argus/internal/clock"
+	"argus/internal/faults"
+	"argus/internal/i18n"
 	"argus/internal/models"
+	"argus/internal/redact"
+	"argus/internal/retention"
+	"argus/internal/tmplfunc"
 	"argus/internal/utils"
 )
 
@@ -39,8 +50,34 @@ type NotificationTemplate struct {
 type CompiledTemplate struct {
 	Subject *template.Template
 	Body    *template.Template
+	// Limits of user-provided templates, which execute sandboxed (nil for
+	// the built-in ones)
+	Limits *tmplfunc.Limits
 }
 
+// sourceSection names where the alert originated, when known
+const sourceSection = `{{ with .Source }}Source: {{ . }}
+{{ end }}`
+
+// ownerSection names who owns the alert, when it has ownership metadata
+const ownerSection = `{{ with .Alert }}{{ if or .Owner .Team .Contact }}
+{{ if .Owner }}Owner: {{ .Owner }}
+{{ end }}{{ if .Team }}Team: {{ .Team }}
+{{ end }}{{ if .Contact }}Contact: {{ .Contact }}
+{{ end }}{{ end }}{{ end }}`
+
+// eventContextSection lists the resources captured when the alert fired and
+// the trend of its metric
+const eventContextSection = `{{ with .Context }}{{ if .TopProcesses }}
+Top processes at trigger time:
+{{ range .TopProcesses }}  {{ .Name }} (PID {{ .PID }}): CPU {{ printf "%.1f" .CPUPercent }}%, MEM {{ printf "%.1f" .MemPercent }}%
+{{ end }}{{ end }}{{ if .Partitions }}
+Affected partitions:
+{{ range .Partitions }}  {{ .Mountpoint }} ({{ .Device }}): {{ printf "%.1f" .UsedPercent }}% used, {{ .FreeHuman }} free
+{{ end }}{{ end }}{{ if .Samples }}
+Recent values: {{ .Sparkline }} ({{ len .Samples }} samples up to the trigger)
+{{ end }}{{ end }}`
+
 // DefaultTemplates provides default templates for different alert severities and states
 var DefaultTemplates = map[models.AlertSeverity]map[models.AlertState]NotificationTemplate{
 	models.SeverityInfo: {
@@ -50,14 +87,14 @@ var DefaultTemplates = map[models.AlertSeverity]map[models.AlertState]Notificati
 Alert: {{ .Alert.Name }}
 Status: ACTIVE
 Severity: INFO
-Time: {{ .Timestamp.Format "2006-01-02 15:04:05" }}
-Value: {{ printf "%.2f" .CurrentValue }}
-Threshold: {{ .Alert.Threshold.Operator }} {{ printf "%.2f" .Alert.Threshold.Value }}
+Time: {{ .Timestamp.Format "2006-01-02 15:04:05 MST" }}
+Value: {{ .DisplayValue }}
+Threshold: {{ .Alert.Threshold.Operator }} {{ .Alert.Threshold.Display }}
 
 {{ .Message }}
 
 Description: {{ .Alert.Description }}
-`,
+` + sourceSection + ownerSection + eventContextSection,
 		},
 		models.StateInactive: {
 			Subject: "[RESOLVED] Argus Alert: {{ .Alert.Name }}",
@@ -65,14 +102,14 @@ Description: {{ .Alert.Description }}
 Alert: {{ .Alert.Name }}
 Status: RESOLVED
 Severity: INFO
-Time: {{ .Timestamp.Format "2006-01-02 15:04:05" }}
-Value: {{ printf "%.2f" .CurrentValue }}
-Threshold: {{ .Alert.Threshold.Operator }} {{ printf "%.2f" .Alert.Threshold.Value }}
+Time: {{ .Timestamp.Format "2006-01-02 15:04:05 MST" }}
+Value: {{ .DisplayValue }}
+Threshold: {{ .Alert.Threshold.Operator }} {{ .Alert.Threshold.Display }}
 
 {{ .Message }}
 
 Description: {{ .Alert.Description }}
-`,
+` + sourceSection + ownerSection + eventContextSection,
 		},
 	},
 	models.SeverityWarning: {
@@ -82,14 +119,14 @@ Description: {{ .Alert.Description }}
 Alert: {{ .Alert.Name }}
 Status: ACTIVE
 Severity: WARNING
-Time: {{ .Timestamp.Format "2006-01-02 15:04:05" }}
-Value: {{ printf "%.2f" .CurrentValue }}
-Threshold: {{ .Alert.Threshold.Operator }} {{ printf "%.2f" .Alert.Threshold.Value }}
+Time: {{ .Timestamp.Format "2006-01-02 15:04:05 MST" }}
+Value: {{ .DisplayValue }}
+Threshold: {{ .Alert.Threshold.Operator }} {{ .Alert.Threshold.Display }}
 
 {{ .Message }}
 
 Description: {{ .Alert.Description }}
-`,
+` + sourceSection + ownerSection + eventContextSection,
 		},
 		models.StateInactive: {
 			Subject: "[RESOLVED] Argus Alert: {{ .Alert.Name }}",
@@ -97,14 +134,14 @@ Description: {{ .Alert.Description }}
 Alert: {{ .Alert.Name }}
 Status: RESOLVED
 Severity: WARNING
-Time: {{ .Timestamp.Format "2006-01-02 15:04:05" }}
-Value: {{ printf "%.2f" .CurrentValue }}
-Threshold: {{ .Alert.Threshold.Operator }} {{ printf "%.2f" .Alert.Threshold.Value }}
+Time: {{ .Timestamp.Format "2006-01-02 15:04:05 MST" }}
+Value: {{ .DisplayValue }}
+Threshold: {{ .Alert.Threshold.Operator }} {{ .Alert.Threshold.Display }}
 
 {{ .Message }}
 
 Description: {{ .Alert.Description }}
-`,
+` + sourceSection + ownerSection + eventContextSection,
 		},
 	},
 	models.SeverityCritical: {
@@ -114,14 +151,14 @@ Description: {{ .Alert.Description }}
 Alert: {{ .Alert.Name }}
 Status: ACTIVE
 Severity: CRITICAL
-Time: {{ .Timestamp.Format "2006-01-02 15:04:05" }}
-Value: {{ printf "%.2f" .CurrentValue }}
-Threshold: {{ .Alert.Threshold.Operator }} {{ printf "%.2f" .Alert.Threshold.Value }}
+Time: {{ .Timestamp.Format "2006-01-02 15:04:05 MST" }}
+Value: {{ .DisplayValue }}
+Threshold: {{ .Alert.Threshold.Operator }} {{ .Alert.Threshold.Display }}
 
 {{ .Message }}
 
 Description: {{ .Alert.Description }}
-`,
+` + sourceSection + ownerSection + eventContextSection,
 		},
 		models.StateInactive: {
 			Subject: "[RESOLVED] Argus Alert: {{ .Alert.Name }}",
@@ -129,14 +166,14 @@ Description: {{ .Alert.Description }}
 Alert: {{ .Alert.Name }}
 Status: RESOLVED
 Severity: CRITICAL
-Time: {{ .Timestamp.Format "2006-01-02 15:04:05" }}
-Value: {{ printf "%.2f" .CurrentValue }}
-Threshold: {{ .Alert.Threshold.Operator }} {{ printf "%.2f" .Alert.Threshold.Value }}
+Time: {{ .Timestamp.Format "2006-01-02 15:04:05 MST" }}
+Value: {{ .DisplayValue }}
+Threshold: {{ .Alert.Threshold.Operator }} {{ .Alert.Threshold.Display }}
 
 {{ .Message }}
 
 Description: {{ .Alert.Description }}
-`,
+` + sourceSection + ownerSection + eventContextSection,
 		},
 	},
 }
@@ -146,12 +183,46 @@ type NotifierConfig struct {
 	RateLimit       int
 	RateLimitWindow time.Duration
 	Templates       map[models.AlertSeverity]map[models.AlertState]NotificationTemplate
+	// ChannelTemplates override Templates for one channel type, e.g. a short
+	// subject for chat and rich HTML for email; severities and states without
+	// an override fall back to Templates
+	ChannelTemplates map[models.NotificationType]map[models.AlertSeverity]map[models.AlertState]NotificationTemplate
+	// TemplateLimits bound the execution of the user-provided ChannelTemplates,
+	// which are parsed without the call function or template invocations
+	TemplateLimits tmplfunc.Limits
+	// Localization: Locale is used by channels that render a single language
+	// (e.g. email); LocaleTemplates holds the template sets for non-English locales
+	Locale          i18n.Locale
+	LocaleTemplates map[i18n.Locale]map[models.AlertSeverity]map[models.AlertState]NotificationTemplate
+	// Timezones used to render event times in templates (nil Location means server local
+	// time); an alert's notification setting "timezone" overrides both for that channel
+	Location         *time.Location
+	ChannelLocations map[models.NotificationType]*time.Location
+	// Redactor masks secrets in rendered subjects and bodies before they are sent (nil disables)
+	Redactor *redact.Redactor
+	// Dispatch: every channel delivers from its own queue with DispatchWorkers
+	// workers so a slow channel does not delay the others (0 delivers
+	// synchronously in ProcessEvent)
+	DispatchWorkers       int
+	DispatchQueueSize     int
+	DispatchQueueOverflow OverflowConfig // Applied when a channel's queue is full
 	// Email worker pool configuration
-	EmailWorkerCount int
-	EmailQueueSize   int
+	EmailWorkerCount   int
+	EmailQueueSize     int
+	EmailQueueOverflow OverflowConfig // Applied when the email queue is full
 	// SMTP connection pool configuration
 	SMTPPoolSize    int
 	SMTPIdleTimeout time.Duration
+	// Circuit breaker per channel: open after CircuitFailureThreshold consecutive
+	// failures, probe again after CircuitOpenTimeout
+	CircuitFailureThreshold int
+	CircuitOpenTimeout      time.Duration
+	// DeadLetterSize bounds the queue of undeliverable notifications
+	DeadLetterSize int
+	// DeliveryLogSize bounds the log of delivery attempts
+	DeliveryLogSize int
+	// Clock drives rate limit windows and circuit breaker timeouts (nil uses the real clock)
+	Clock clock.Clock
 }
 
 func DefaultConfig() *NotifierConfig {
@@ -159,10 +230,23 @@ func DefaultConfig() *NotifierConfig {
 		RateLimit:        5,
 		RateLimitWindow:  1 * time.Hour,
 		Templates:        DefaultTemplates,
+		Locale:           i18n.DefaultLocale,
+		LocaleTemplates:  DefaultLocaleTemplates,
 		EmailWorkerCount: 3,
 		EmailQueueSize:   100,
 		SMTPPoolSize:     5,
 		SMTPIdleTimeout:  5 * time.Minute,
+
+		EmailQueueOverflow: OverflowConfig{Policy: OverflowDropNewest},
+
+		DispatchWorkers:       DefaultDispatchWorkers,
+		DispatchQueueSize:     DefaultDispatchQueueSize,
+		DispatchQueueOverflow: OverflowConfig{Policy: OverflowDropNewest},
+
+		CircuitFailureThreshold: DefaultCircuitFailureThreshold,
+		CircuitOpenTimeout:      DefaultCircuitOpenTimeout,
+		DeadLetterSize:          DefaultDeadLetterSize,
+		DeliveryLogSize:         DefaultDeliveryLogSize,
 	}
 }
 
@@ -172,10 +256,32 @@ type NotificationChannel interface {
 	Name() string
 }
 
+// AsyncNotificationChannel is implemented by channels that deliver in the
+// background (e.g. email). Send only queues the notification; the handler
+// receives the final outcome so the channel's circuit breaker sees real failures.
+type AsyncNotificationChannel interface {
+	NotificationChannel
+	SetResultHandler(handler func(event models.AlertEvent, subject, body string, err error))
+}
+
+// ErrCircuitOpen is recorded for notifications rejected by an open circuit breaker
+var ErrCircuitOpen = errors.New("notification channel circuit is open")
+
+// ErrDeadLetterNotFound is returned when retrying an unknown dead letter
+var ErrDeadLetterNotFound = errors.New("dead letter not found")
+
+// LocalizedNotificationChannel is implemented by channels that keep content for
+// every supported locale so the language can be negotiated when it is read.
+type LocalizedNotificationChannel interface {
+	NotificationChannel
+	SendLocalized(event models.AlertEvent, subject, body string, localized map[string]models.LocalizedContent) error
+}
+
 // Efficient rate limiter using time-based expiry
 type rateLimiter struct {
 	entries sync.Map // map[string]*rateLimitEntry
 	config  *NotifierConfig
+	clock   clock.Clock
 }
 
 type rateLimitEntry struct {
@@ -184,14 +290,14 @@ type rateLimitEntry struct {
 }
 
 func newRateLimiter(config *NotifierConfig) *rateLimiter {
-	rl := &rateLimiter{config: config}
+	rl := &rateLimiter{config: config, clock: clock.OrReal(config.Clock)}
 	// Start cleanup goroutine
 	go rl.cleanup()
 	return rl
 }
 
 func (rl *rateLimiter) isAllowed(key string) bool {
-	now := time.Now().Unix()
+	now := rl.clock.Now().Unix()
 	
 	// Load or create entry
 	entryInterface, _ := rl.entries.LoadOrStore(key, &rateLimitEntry{
@@ -220,11 +326,11 @@ func (rl *rateLimiter) isAllowed(key string) bool {
 }
 
 func (rl *rateLimiter) cleanup() {
-	ticker := time.NewTicker(rl.config.RateLimitWindow)
+	ticker := rl.clock.NewTicker(rl.config.RateLimitWindow)
 	defer ticker.Stop()
 
-	for range ticker.C {
-		now := time.Now().Unix()
+	for range ticker.C() {
+		now := rl.clock.Now().Unix()
 		rl.entries.Range(func(key, value interface{}) bool {
 			entry := value.(*rateLimitEntry)
 			if atomic.LoadInt64(&entry.expiresAt) < now {
@@ -240,9 +346,31 @@ type Notifier struct {
 	channels          map[models.NotificationType]NotificationChannel
 	rateLimiter       *rateLimiter
 	compiledTemplates map[models.AlertSeverity]map[models.AlertState]*CompiledTemplate
+	localeTemplates   map[i18n.Locale]map[models.AlertSeverity]map[models.AlertState]*CompiledTemplate
+	channelTemplates  map[models.NotificationType]map[models.AlertSeverity]map[models.AlertState]*CompiledTemplate
+	breakers          map[models.NotificationType]*circuitBreaker
+	dispatch          map[models.NotificationType]*dispatchQueue // Empty when delivering synchronously
+	stopped           bool
+	deadLetters       *DeadLetterQueue
+	deliveries        *DeliveryLog
+	silencer          Silencer
+	clock             clock.Clock
 	mu                sync.RWMutex
 }
 
+// Silencer decides whether notifications for an alert are muted
+type Silencer interface {
+	// Silenced returns the IDs of the silences muting labels at the given time
+	Silenced(labels map[string]string, at time.Time) []string
+}
+
+// SetSilencer installs the silences consulted before every notification
+func (n *Notifier) SetSilencer(silencer Silencer) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.silencer = silencer
+}
+
 func NewNotifier(config *NotifierConfig) *Notifier {
 	if config == nil {
 		config = DefaultConfig()
@@ -252,6 +380,11 @@ func NewNotifier(config *NotifierConfig) *Notifier {
 		config:      config,
 		channels:    make(map[models.NotificationType]NotificationChannel),
 		rateLimiter: newRateLimiter(config),
+		breakers:    make(map[models.NotificationType]*circuitBreaker),
+		dispatch:    make(map[models.NotificationType]*dispatchQueue),
+		deadLetters: NewDeadLetterQueue(config.DeadLetterSize),
+		deliveries:  NewDeliveryLog(config.DeliveryLogSize),
+		clock:       clock.OrReal(config.Clock),
 	}
 
 	// Pre-compile templates for performance
@@ -265,36 +398,78 @@ func NewNotifier(config *NotifierConfig) *Notifier {
 }
 
 func (n *Notifier) compileTemplates() error {
-	n.compiledTemplates = make(map[models.AlertSeverity]map[models.AlertState]*CompiledTemplate)
-
 	templates := n.config.Templates
 	if templates == nil {
 		templates = DefaultTemplates
 	}
 
+	compiled, err := compileTemplateSet(templates, nil)
+	if err != nil {
+		return err
+	}
+	n.compiledTemplates = compiled
+
+	n.localeTemplates = make(map[i18n.Locale]map[models.AlertSeverity]map[models.AlertState]*CompiledTemplate)
+	for loc, set := range n.config.LocaleTemplates {
+		compiled, err := compileTemplateSet(set, nil)
+		if err != nil {
+			return fmt.Errorf("locale %s: %w", loc, err)
+		}
+		n.localeTemplates[loc] = compiled
+	}
+
+	channelTemplates := make(map[models.NotificationType]map[models.AlertSeverity]map[models.AlertState]*CompiledTemplate)
+	limits := n.config.TemplateLimits
+	for typ, set := range n.config.ChannelTemplates {
+		compiled, err := compileTemplateSet(set, &limits)
+		if err != nil {
+			return fmt.Errorf("channel %s: %w", typ, err)
+		}
+		channelTemplates[typ] = compiled
+	}
+	n.channelTemplates = channelTemplates
+
+	slog.Info("Pre-compiled notification templates", "count", len(templates), "locales", len(n.localeTemplates), "channels", len(n.channelTemplates))
+	return nil
+}
+
+// compileTemplateSet parses one severity/state template set, in the sandbox
+// when limits are given
+func compileTemplateSet(templates map[models.AlertSeverity]map[models.AlertState]NotificationTemplate, limits *tmplfunc.Limits) (map[models.AlertSeverity]map[models.AlertState]*CompiledTemplate, error) {
+	result := make(map[models.AlertSeverity]map[models.AlertState]*CompiledTemplate)
+
 	for severity, stateTemplates := range templates {
-		n.compiledTemplates[severity] = make(map[models.AlertState]*CompiledTemplate)
+		result[severity] = make(map[models.AlertState]*CompiledTemplate)
 
 		for state, tmpl := range stateTemplates {
-			subjTmpl, err := template.New("subject").Parse(tmpl.Subject)
+			subjTmpl, err := parseTemplate("subject", tmpl.Subject, limits)
 			if err != nil {
-				return fmt.Errorf("failed to compile subject template for %s/%s: %w", severity, state, err)
+				return nil, fmt.Errorf("failed to compile subject template for %s/%s: %w", severity, state, err)
 			}
 
-			bodyTmpl, err := template.New("body").Parse(tmpl.Body)
+			bodyTmpl, err := parseTemplate("body", tmpl.Body, limits)
 			if err != nil {
-				return fmt.Errorf("failed to compile body template for %s/%s: %w", severity, state, err)
+				return nil, fmt.Errorf("failed to compile body template for %s/%s: %w", severity, state, err)
 			}
 
-			n.compiledTemplates[severity][state] = &CompiledTemplate{
+			result[severity][state] = &CompiledTemplate{
 				Subject: subjTmpl,
 				Body:    bodyTmpl,
+				Limits:  limits,
 			}
 		}
 	}
 
-	slog.Info("Pre-compiled notification templates", "count", len(templates))
-	return nil
+	return result, nil
+}
+
+// parseTemplate parses a built-in template, or a user-provided one in the
+// sandbox when limits are given
+func parseTemplate(name, text string, limits *tmplfunc.Limits) (*template.Template, error) {
+	if limits != nil {
+		return tmplfunc.ParseSandboxed(name, text, *limits)
+	}
+	return template.New(name).Funcs(tmplfunc.Funcs()).Parse(text)
 }
 
 func (n *Notifier) RegisterChannel(channel NotificationChannel) {
@@ -302,6 +477,22 @@ func (n *Notifier) RegisterChannel(channel NotificationChannel) {
 	defer n.mu.Unlock()
 	channelType := channel.Type()
 	n.channels[channelType] = channel
+	breaker := newCircuitBreaker(string(channelType), n.config.CircuitFailureThreshold, n.config.CircuitOpenTimeout, n.onCircuitChange)
+	breaker.now = n.clock.Now
+	n.breakers[channelType] = breaker
+	if async, ok := channel.(AsyncNotificationChannel); ok {
+		async.SetResultHandler(func(event models.AlertEvent, subject, body string, err error) {
+			n.recordDelivery(channelType, event, subject, body, err)
+		})
+	}
+	// A replaced channel's workers finish its queue in the background
+	if old := n.dispatch[channelType]; old != nil {
+		go old.stop()
+		delete(n.dispatch, channelType)
+	}
+	if n.config.DispatchWorkers > 0 && !n.stopped {
+		n.dispatch[channelType] = n.startDispatch(channelType, channel)
+	}
 	slog.Info("Registered notification channel", "type", channelType, "name", channel.Name())
 }
 
@@ -312,10 +503,26 @@ func (n *Notifier) GetChannel(channelType models.NotificationType) (Notification
 	return ch, ok
 }
 
+// ProcessEvent notifies every channel of event. With dispatch workers it
+// only queues the notifications; they are rendered and sent by each
+// channel's workers.
 func (n *Notifier) ProcessEvent(event models.AlertEvent) {
 	n.mu.RLock()
 	defer n.mu.RUnlock()
 
+	if n.stopped {
+		slog.Warn("Notifier stopped, notification dropped", "alert_id", event.AlertID)
+		return
+	}
+
+	// Silenced alerts notify no channel, whether firing or resolving
+	if n.silencer != nil && event.Alert != nil {
+		if ids := n.silencer.Silenced(event.Alert.LabelSet(), n.clock.Now()); len(ids) > 0 {
+			slog.Info("Notification silenced", "alert_id", event.AlertID, "silences", ids)
+			return
+		}
+	}
+
 	for typ, channel := range n.channels {
 		// Check rate limit using efficient time-based expiry
 		rateLimitKey := fmt.Sprintf("%s:%s", string(typ), event.AlertID)
@@ -324,19 +531,191 @@ func (n *Notifier) ProcessEvent(event models.AlertEvent) {
 			continue
 		}
 
-		// Render templates (using pre-compiled templates if available)
-		subject, body, err := n.renderTemplates(event)
+		if q := n.dispatch[typ]; q != nil {
+			n.enqueue(q, event)
+			continue
+		}
+
+		// Render templates in the channel's timezone (using pre-compiled templates if available)
+		subject, body, localized, err := n.prepare(typ, channel, event)
 		if err != nil {
-			slog.Error("Failed to render notification template", "error", err)
+			slog.Error("Failed to render notification template", "type", typ, "error", err)
 			continue
 		}
+		n.send(typ, channel, n.breakers[typ], event, subject, body, localized)
+	}
+}
+
+// recordDelivery logs a delivery outcome, feeds it to the channel's circuit
+// breaker and dead-letters failed notifications
+func (n *Notifier) recordDelivery(typ models.NotificationType, event models.AlertEvent, subject, body string, err error) {
+	n.deliveries.Record(typ, event, subject, false, err)
+	if err != nil {
+		slog.Error("Failed to send notification", "type", typ, "alert_id", event.AlertID, "error", err)
+		n.deadLetters.Add(typ, event, subject, body, err)
+	}
+	// A full queue is a capacity problem, not a sign that the channel is failing
+	if errors.Is(err, ErrQueueFull) {
+		return
+	}
+	if breaker := n.breakers[typ]; breaker != nil {
+		breaker.record(err)
+	}
+}
+
+// onCircuitChange logs breaker transitions and raises an in-app warning when a
+// channel stops (or resumes) delivering
+func (n *Notifier) onCircuitChange(channel string, from, to CircuitState, lastErr string) {
+	slog.Warn("Notification channel circuit changed", "channel", channel, "from", from, "to", to, "last_error", lastErr)
+
+	if channel == string(models.NotificationInApp) {
+		return
+	}
+	inApp, ok := n.channels[models.NotificationInApp].(*InAppChannel)
+	if !ok {
+		return
+	}
+	switch {
+	case to == CircuitOpen && from == CircuitClosed:
+		inApp.SendSystem(models.SeverityWarning,
+			fmt.Sprintf("Notification channel %s is failing", channel),
+			fmt.Sprintf("Delivery via %s failed repeatedly (last error: %s). Notifications are being held in the dead-letter queue and delivery will be retried automatically.", channel, lastErr))
+	case to == CircuitClosed:
+		inApp.SendSystem(models.SeverityInfo,
+			fmt.Sprintf("Notification channel %s restored", channel),
+			fmt.Sprintf("Delivery via %s is working again. Held notifications can be retried from the dead-letter queue.", channel))
+	}
+}
 
-		// Send notification (non-blocking for email)
-		if err := channel.Send(event, subject, body); err != nil {
-			slog.Error("Failed to send notification", "type", typ, "error", err)
+// GetChannelStatus returns the circuit breaker state of every registered channel, sorted by channel
+func (n *Notifier) GetChannelStatus() []CircuitStatus {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+	statuses := make([]CircuitStatus, 0, len(n.breakers))
+	for _, breaker := range n.breakers {
+		statuses = append(statuses, breaker.status())
+	}
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].Channel < statuses[j].Channel })
+	return statuses
+}
+
+// Deliveries returns the log of delivery attempts
+func (n *Notifier) Deliveries() *DeliveryLog {
+	return n.deliveries
+}
+
+// DeadLetters returns the dead-letter queue
+func (n *Notifier) DeadLetters() *DeadLetterQueue {
+	return n.deadLetters
+}
+
+// RetryDeadLetter re-sends a dead-lettered notification through its channel.
+// A failed retry is put back in the queue with its attempt count increased.
+func (n *Notifier) RetryDeadLetter(id string) error {
+	entry, ok := n.deadLetters.Take(id)
+	if !ok {
+		return ErrDeadLetterNotFound
+	}
+
+	n.mu.RLock()
+	channel, exists := n.channels[entry.Channel]
+	breaker := n.breakers[entry.Channel]
+	n.mu.RUnlock()
+	if !exists {
+		n.deadLetters.requeue(entry, fmt.Errorf("channel %s is not registered", entry.Channel))
+		return fmt.Errorf("channel %s is not registered", entry.Channel)
+	}
+	if breaker != nil && !breaker.allow() {
+		n.deadLetters.requeue(entry, ErrCircuitOpen)
+		return ErrCircuitOpen
+	}
+
+	err := channel.Send(entry.event, entry.Subject, entry.Body)
+	_, async := channel.(AsyncNotificationChannel)
+	if err != nil {
+		n.deadLetters.requeue(entry, err)
+	}
+	if err != nil || !async {
+		n.deliveries.Record(entry.Channel, entry.event, entry.Subject, true, err)
+	}
+	if breaker != nil && (err != nil || !async) {
+		breaker.record(err)
+	}
+	return err
+}
+
+// locationFor resolves the timezone that event times are rendered in for a channel
+func (n *Notifier) locationFor(typ models.NotificationType, event models.AlertEvent) *time.Location {
+	if event.Alert != nil {
+		for _, notif := range event.Alert.Notifications {
+			if notif.Type != typ || notif.Settings == nil {
+				continue
+			}
+			if tz, ok := notif.Settings["timezone"].(string); ok && tz != "" {
+				if loc, err := time.LoadLocation(tz); err == nil {
+					return loc
+				}
+				slog.Warn("Ignoring invalid notification timezone", "alert_id", event.AlertID, "timezone", tz)
+			}
+		}
+	}
+	if loc, ok := n.config.ChannelLocations[typ]; ok && loc != nil {
+		return loc
+	}
+	if n.config.Location != nil {
+		return n.config.Location
+	}
+	return time.Local
+}
+
+// channelTemplate returns the channel's own template for the event's severity
+// and state, nil when the channel has none
+func (n *Notifier) channelTemplate(typ models.NotificationType, event models.AlertEvent) *CompiledTemplate {
+	if event.Alert == nil {
+		return nil
+	}
+	return n.channelTemplates[typ][event.Alert.Severity][event.NewState]
+}
+
+// renderForChannel renders the event for a channel with its own template,
+// falling back to the severity/state templates in the configured locale
+func (n *Notifier) renderForChannel(typ models.NotificationType, event models.AlertEvent) (string, string, error) {
+	if compiled := n.channelTemplate(typ, event); compiled != nil {
+		return n.executeCompiledTemplate(compiled, event)
+	}
+	return n.renderTemplatesForLocale(event, n.config.Locale)
+}
+
+// renderTemplatesForLocale renders the event with the template set for loc,
+// falling back to the default (English) templates when the locale has none.
+func (n *Notifier) renderTemplatesForLocale(event models.AlertEvent, loc i18n.Locale) (string, string, error) {
+	if set, ok := n.localeTemplates[loc]; ok {
+		if compiled, ok := set[event.Alert.Severity][event.NewState]; ok {
+			return n.executeCompiledTemplate(compiled, event)
+		}
+		if compiled, ok := set[models.SeverityInfo][models.StateActive]; ok {
+			return n.executeCompiledTemplate(compiled, event)
+		}
+	}
+	return n.renderTemplates(event)
+}
+
+// renderAllLocales renders the event for every supported locale, skipping
+// locales whose templates fail to render.
+func (n *Notifier) renderAllLocales(event models.AlertEvent) map[string]models.LocalizedContent {
+	localized := make(map[string]models.LocalizedContent, len(i18n.Supported))
+	for _, loc := range i18n.Supported {
+		subject, body, err := n.renderTemplatesForLocale(event, loc)
+		if err != nil {
+			slog.Warn("Failed to render localized notification", "locale", loc, "error", err)
 			continue
 		}
+		localized[string(loc)] = models.LocalizedContent{
+			Subject: n.config.Redactor.Redact(subject),
+			Message: n.config.Redactor.Redact(body),
+		}
 	}
+	return localized
 }
 
 func (n *Notifier) renderTemplates(event models.AlertEvent) (string, string, error) {
@@ -362,6 +741,18 @@ func (n *Notifier) renderTemplates(event models.AlertEvent) (string, string, err
 }
 
 func (n *Notifier) executeCompiledTemplate(compiled *CompiledTemplate, event models.AlertEvent) (string, string, error) {
+	if compiled.Limits != nil {
+		subject, err := tmplfunc.Execute(compiled.Subject, event, *compiled.Limits)
+		if err != nil {
+			return "", "", fmt.Errorf("failed to execute subject template: %w", err)
+		}
+		body, err := tmplfunc.Execute(compiled.Body, event, *compiled.Limits)
+		if err != nil {
+			return "", "", fmt.Errorf("failed to execute body template: %w", err)
+		}
+		return subject, body, nil
+	}
+
 	// Use pooled buffers for template rendering
 	subjBuf := utils.GetBytesBuffer()
 	defer utils.PutBytesBuffer(subjBuf)
@@ -391,11 +782,11 @@ func (n *Notifier) renderTemplatesRuntime(event models.AlertEvent) (string, stri
 	if !ok {
 		tmpl = DefaultTemplates[models.SeverityInfo][models.StateActive]
 	}
-	subjTmpl, err := template.New("subject").Parse(tmpl.Subject)
+	subjTmpl, err := template.New("subject").Funcs(tmplfunc.Funcs()).Parse(tmpl.Subject)
 	if err != nil {
 		return "", "", err
 	}
-	bodyTmpl, err := template.New("body").Parse(tmpl.Body)
+	bodyTmpl, err := template.New("body").Funcs(tmplfunc.Funcs()).Parse(tmpl.Body)
 	if err != nil {
 		return "", "", err
 	}
@@ -427,8 +818,19 @@ type EmailConfig struct {
 	Password string
 	From     string
 	UseSSL   bool
+	// SendmailPath switches the channel to sendmail mode: messages are piped to
+	// this local MTA binary instead of being sent over SMTP
+	SendmailPath string
+	SendmailArgs []string // Arguments for SendmailPath (default DefaultSendmailArgs)
 }
 
+// DefaultSendmailArgs reads recipients from the message headers (-t) and does
+// not treat a line with a single dot as end of input (-i)
+var DefaultSendmailArgs = []string{"-t", "-i"}
+
+// DefaultSendmailTimeout bounds how long the relay command may run per message
+const DefaultSendmailTimeout = 30 * time.Second
+
 func DefaultEmailConfig() *EmailConfig {
 	return &EmailConfig{
 		Host:     "smtp.example.com",
@@ -456,10 +858,12 @@ type EmailChannel struct {
 	config      *EmailConfig
 	notifierCfg *NotifierConfig
 	emailQueue  chan EmailJob
+	dropped     atomic.Uint64
 	smtpPool    sync.Pool
 	workers     sync.WaitGroup
 	ctx         context.Context
 	cancel      context.CancelFunc
+	onResult    func(event models.AlertEvent, subject, body string, err error)
 }
 
 func NewEmailChannel(config *EmailConfig, notifierConfig *NotifierConfig) *EmailChannel {
@@ -520,34 +924,73 @@ func (c *EmailChannel) emailWorker() {
 	}
 }
 
+// SetResultHandler registers a callback that receives the outcome of every queued email
+func (c *EmailChannel) SetResultHandler(handler func(event models.AlertEvent, subject, body string, err error)) {
+	c.onResult = handler
+}
+
 func (c *EmailChannel) processEmailJob(job EmailJob) {
-	if job.Event.Alert == nil || len(job.Event.Alert.Notifications) == 0 {
-		slog.Error("Alert has no notification settings", "alert_id", job.Event.AlertID)
-		return
+	err := c.deliverEmailJob(job)
+	// Alerts without an email recipient say nothing about the health of the channel
+	if c.onResult != nil && !errors.Is(err, errNoRecipient) {
+		c.onResult(job.Event, job.Subject, job.Body, err)
 	}
+}
 
-	var recipient string
-	for _, notif := range job.Event.Alert.Notifications {
-		if notif.Type == models.NotificationEmail && notif.Enabled {
-			if notif.Settings != nil {
-				if r, ok := notif.Settings["recipient"].(string); ok && r != "" {
-					recipient = r
-					break
-				}
+// emailRecipient returns the recipient of the alert's first enabled email
+// notification, or "" if it has none
+func emailRecipient(alert *models.AlertConfig) string {
+	if alert == nil {
+		return ""
+	}
+	for _, notif := range alert.Notifications {
+		if notif.Type == models.NotificationEmail && notif.Enabled && notif.Settings != nil {
+			if r, ok := notif.Settings["recipient"].(string); ok && r != "" {
+				return r
 			}
 		}
 	}
+	return ""
+}
+
+// errNoRecipient marks jobs that cannot be delivered because of alert configuration
+var errNoRecipient = errors.New("no valid email recipient")
+
+func (c *EmailChannel) deliverEmailJob(job EmailJob) error {
+	if job.Event.Alert == nil || len(job.Event.Alert.Notifications) == 0 {
+		slog.Error("Alert has no notification settings", "alert_id", job.Event.AlertID)
+		return errNoRecipient
+	}
 
+	recipient := emailRecipient(job.Event.Alert)
 	if recipient == "" {
 		slog.Error("No valid email recipient found", "alert_id", job.Event.AlertID)
-		return
+		return errNoRecipient
+	}
+
+	// Simulated SMTP outage: fails the job before any relay or server is contacted
+	if err := faults.Inject(faults.EmailSend); err != nil {
+		slog.Error("Failed to send email", "recipient", recipient, "error", err)
+		return err
+	}
+
+	if c.config.SendmailPath != "" {
+		if err := c.sendViaCommand(recipient, job.Subject, job.Body); err != nil {
+			slog.Error("Failed to send email via relay command", "recipient", recipient, "command", c.config.SendmailPath, "error", err)
+			return err
+		}
+		slog.Info("Email handed to relay command",
+			"recipient", recipient,
+			"subject", job.Subject,
+			"alert_id", job.Event.AlertID)
+		return nil
 	}
 
 	// Get SMTP connection from pool
 	conn := c.getSMTPConnection()
 	if conn == nil {
 		slog.Error("Failed to get SMTP connection", "alert_id", job.Event.AlertID)
-		return
+		return errors.New("failed to get SMTP connection")
 	}
 
 	defer c.returnSMTPConnection(conn)
@@ -557,13 +1000,14 @@ func (c *EmailChannel) processEmailJob(job EmailJob) {
 		slog.Error("Failed to send email", "recipient", recipient, "error", err)
 		// Mark connection as bad
 		conn.client = nil
-		return
+		return err
 	}
 
 	slog.Info("Email sent successfully",
 		"recipient", recipient,
 		"subject", job.Subject,
 		"alert_id", job.Event.AlertID)
+	return nil
 }
 
 func (c *EmailChannel) getSMTPConnection() *SMTPConnection {
@@ -640,16 +1084,42 @@ func (c *EmailChannel) sendEmailWithConnection(conn *SMTPConnection, recipient,
 	defer w.Close()
 
 	// Write message
-	msg := fmt.Sprintf("To: %s\r\nFrom: %s\r\nSubject: %s\r\nContent-Type: text/plain; charset=UTF-8\r\n\r\n%s\r\n",
-		recipient, c.config.From, subject, body)
-
-	if _, err := w.Write([]byte(msg)); err != nil {
+	if _, err := w.Write(c.buildMessage(recipient, subject, body)); err != nil {
 		return fmt.Errorf("failed to write message: %w", err)
 	}
 
 	return nil
 }
 
+// buildMessage formats the RFC 5322 message shared by SMTP and sendmail delivery
+func (c *EmailChannel) buildMessage(recipient, subject, body string) []byte {
+	return []byte(fmt.Sprintf("To: %s\r\nFrom: %s\r\nSubject: %s\r\nContent-Type: text/plain; charset=UTF-8\r\n\r\n%s\r\n",
+		recipient, c.config.From, subject, body))
+}
+
+// sendViaCommand pipes the message to the configured sendmail-compatible binary
+func (c *EmailChannel) sendViaCommand(recipient, subject, body string) error {
+	args := c.config.SendmailArgs
+	if len(args) == 0 {
+		args = DefaultSendmailArgs
+	}
+
+	ctx, cancel := context.WithTimeout(c.ctx, DefaultSendmailTimeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, c.config.SendmailPath, args...)
+	cmd.Stdin = bytes.NewReader(c.buildMessage(recipient, subject, body))
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return fmt.Errorf("relay command failed: %w: %s", err, msg)
+		}
+		return fmt.Errorf("relay command failed: %w", err)
+	}
+	return nil
+}
+
 func (c *EmailChannel) cleanupConnections() {
 	ticker := time.NewTicker(c.notifierCfg.SMTPIdleTimeout)
 	defer ticker.Stop()
@@ -672,12 +1142,21 @@ func (c *EmailChannel) Send(event models.AlertEvent, subject, body string) error
 		Body:    body,
 	}
 
-	// Non-blocking send to queue
-	select {
-	case c.emailQueue <- job:
-		return nil
-	default:
-		return fmt.Errorf("email queue is full")
+	// Queue according to the overflow policy; never blocks longer than its timeout
+	if !offer(c.emailQueue, job, c.notifierCfg.EmailQueueOverflow, &c.dropped) {
+		return fmt.Errorf("email %w", ErrQueueFull)
+	}
+	return nil
+}
+
+// QueueStats reports the fill level and drop count of the email queue
+func (c *EmailChannel) QueueStats() QueueStats {
+	return QueueStats{
+		Name:     "email",
+		Policy:   c.notifierCfg.EmailQueueOverflow.Policy,
+		Capacity: cap(c.emailQueue),
+		Length:   len(c.emailQueue),
+		Dropped:  c.dropped.Load(),
 	}
 }
 
@@ -716,6 +1195,9 @@ type InAppChannel struct {
 	maxSize       int
 	mu            sync.RWMutex
 	hub           Broadcaster
+	overflow      OverflowConfig
+	dropped       atomic.Uint64
+	cleared       chan struct{} // Closed and replaced whenever the list is cleared
 }
 
 func NewInAppChannel(maxSize int, hub Broadcaster) *InAppChannel {
@@ -723,10 +1205,88 @@ func NewInAppChannel(maxSize int, hub Broadcaster) *InAppChannel {
 		notifications: make([]models.InAppNotification, 0, maxSize),
 		maxSize:       maxSize,
 		hub:           hub,
+		overflow:      OverflowConfig{Policy: OverflowDropOldest},
+		cleared:       make(chan struct{}),
+	}
+}
+
+// SetOverflow sets what happens when the notification list is full. The list
+// only shrinks when it is cleared, so the block policy waits for a clear.
+func (c *InAppChannel) SetOverflow(overflow OverflowConfig) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.overflow = overflow
+}
+
+// QueueStats reports the fill level and drop count of the notification list
+func (c *InAppChannel) QueueStats() QueueStats {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return QueueStats{
+		Name:     "in_app",
+		Policy:   c.overflow.Policy,
+		Capacity: c.maxSize,
+		Length:   len(c.notifications),
+		Dropped:  c.dropped.Load(),
+	}
+}
+
+// waitForRoom applies the overflow policy while c.mu is held and the list may be
+// full. It returns with c.mu held and reports whether the new notification
+// should be stored.
+func (c *InAppChannel) waitForRoom() bool {
+	if faults.Active(faults.QueueFull) {
+		c.dropped.Add(1)
+		return false
+	}
+	if len(c.notifications) < c.maxSize {
+		return true
 	}
+	switch c.overflow.Policy {
+	case OverflowDropNewest:
+		c.dropped.Add(1)
+		return false
+	case OverflowBlock:
+		cleared := c.cleared
+		c.mu.Unlock()
+		timer := time.NewTimer(c.overflow.blockTimeout())
+		select {
+		case <-cleared:
+		case <-timer.C:
+		}
+		timer.Stop()
+		c.mu.Lock()
+		if len(c.notifications) < c.maxSize {
+			return true
+		}
+		c.dropped.Add(1)
+		return false
+	}
+	// Drop oldest: remove the oldest notification
+	c.notifications = c.notifications[1:]
+	c.dropped.Add(1)
+	return true
 }
 
 func (c *InAppChannel) Send(event models.AlertEvent, subject, body string) error {
+	return c.SendLocalized(event, subject, body, nil)
+}
+
+// SendSystem raises a notification about Argus itself (e.g. a failing channel)
+// that is not tied to an alert
+func (c *InAppChannel) SendSystem(severity models.AlertSeverity, subject, body string) {
+	event := models.AlertEvent{
+		NewState: models.StateActive,
+		Alert:    &models.AlertConfig{Name: "Argus", Severity: severity},
+	}
+	if err := c.Send(event, subject, body); err != nil {
+		slog.Error("Failed to send system notification", "subject", subject, "error", err)
+	}
+}
+
+// SendLocalized stores the notification together with its per-locale content.
+// subject and body are the default-locale rendering that is broadcast.
+func (c *InAppChannel) SendLocalized(event models.AlertEvent, subject, body string, localized map[string]models.LocalizedContent) error {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
@@ -739,14 +1299,15 @@ func (c *InAppChannel) Send(event models.AlertEvent, subject, body string) error
 		State:     event.NewState,
 		Message:   body,
 		Subject:   subject,
-		Timestamp: time.Now(),
+		Timestamp: time.Now().UTC(),
+		Source:    event.Source,
 		Read:      false,
+		Localized: localized,
 	}
 
-	// Add to internal list (and cap size)
-	if len(c.notifications) >= c.maxSize {
-		// Remove the oldest notification
-		c.notifications = c.notifications[1:]
+	// Add to internal list, applying the overflow policy when it is full
+	if !c.waitForRoom() {
+		return fmt.Errorf("in-app %w", ErrQueueFull)
 	}
 	c.notifications = append(c.notifications, notification)
 
@@ -774,6 +1335,24 @@ func (c *InAppChannel) GetNotifications() []models.InAppNotification {
 	defer c.mu.RUnlock()
 	result := make([]models.InAppNotification, len(c.notifications))
 	copy(result, c.notifications)
+	for i := range result {
+		result[i].ReadBy = maps.Clone(result[i].ReadBy)
+	}
+	return result
+}
+
+// GetNotificationsForLocale returns all notifications with Subject and Message
+// replaced by the loc rendering where one was stored. With a user, Read tells
+// whether that user has read the notification.
+func (c *InAppChannel) GetNotificationsForLocale(loc i18n.Locale, user string) []models.InAppNotification {
+	result := c.GetNotifications()
+	for i := range result {
+		if content, ok := result[i].Localized[string(loc)]; ok {
+			result[i].Subject = content.Subject
+			result[i].Message = content.Message
+		}
+		result[i].Read = result[i].ReadFor(user)
+	}
 	return result
 }
 
@@ -790,22 +1369,52 @@ func (c *InAppChannel) GetUnreadNotifications() []models.InAppNotification {
 }
 
 func (c *InAppChannel) MarkAsRead(id string) bool {
+	return c.MarkAsReadBy(id, "")
+}
+
+// MarkAsReadBy records that user read the notification; without a user it
+// is marked read for everyone
+func (c *InAppChannel) MarkAsReadBy(id, user string) bool {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 	for i, notification := range c.notifications {
 		if notification.ID == id {
-			c.notifications[i].Read = true
+			c.notifications[i].MarkReadBy(user, time.Now().UTC())
 			return true
 		}
 	}
 	return false
 }
 
+// MarkAlertAsReadBy records that user read every notification of an alert
+// and returns the number of them; without a user they are marked read for
+// everyone
+func (c *InAppChannel) MarkAlertAsReadBy(alertID, user string) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := time.Now().UTC()
+	marked := 0
+	for i := range c.notifications {
+		if c.notifications[i].AlertID == alertID {
+			c.notifications[i].MarkReadBy(user, now)
+			marked++
+		}
+	}
+	return marked
+}
+
 func (c *InAppChannel) MarkAllAsRead() {
+	c.MarkAllAsReadBy("")
+}
+
+// MarkAllAsReadBy records that user read every notification; without a user
+// they are marked read for everyone
+func (c *InAppChannel) MarkAllAsReadBy(user string) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
+	now := time.Now().UTC()
 	for i := range c.notifications {
-		c.notifications[i].Read = true
+		c.notifications[i].MarkReadBy(user, now)
 	}
 }
 
@@ -813,6 +1422,36 @@ func (c *InAppChannel) ClearNotifications() {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 	c.notifications = make([]models.InAppNotification, 0, c.maxSize)
+	close(c.cleared)
+	c.cleared = make(chan struct{})
+}
+
+// PurgeBefore removes notifications created before before and returns the
+// number removed
+func (c *InAppChannel) PurgeBefore(before time.Time) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	kept := c.notifications[:0]
+	for _, notification := range c.notifications {
+		if !notification.Timestamp.Before(before) {
+			kept = append(kept, notification)
+		}
+	}
+	purged := len(c.notifications) - len(kept)
+	clear(c.notifications[len(kept):])
+	c.notifications = kept
+	return purged
+}
+
+// Usage reports the number of notifications and the oldest one's time
+func (c *InAppChannel) Usage() retention.Usage {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	var usage retention.Usage
+	for _, notification := range c.notifications {
+		usage.Observe(notification.Timestamp)
+	}
+	return usage
 }
 
 func generateID() string {
@@ -842,8 +1481,23 @@ func (n *Notifier) GetNotifications() []models.InAppNotification {
 	return inApp.GetNotifications()
 }
 
-// MarkNotificationRead marks a notification as read by ID in the in-app channel.
-func (n *Notifier) MarkNotificationRead(id string) bool {
+// GetNotificationsForLocale returns all in-app notifications rendered for loc,
+// with the read state of user when one is given.
+func (n *Notifier) GetNotificationsForLocale(loc i18n.Locale, user string) []models.InAppNotification {
+	ch, ok := n.channels[models.NotificationInApp]
+	if !ok {
+		return nil
+	}
+	inApp, ok := ch.(*InAppChannel)
+	if !ok {
+		return nil
+	}
+	return inApp.GetNotificationsForLocale(loc, user)
+}
+
+// MarkNotificationRead marks a notification as read by ID in the in-app
+// channel, for user only when one is given.
+func (n *Notifier) MarkNotificationRead(id, user string) bool {
 	ch, ok := n.channels[models.NotificationInApp]
 	if !ok {
 		return false
@@ -852,11 +1506,26 @@ func (n *Notifier) MarkNotificationRead(id string) bool {
 	if !ok {
 		return false
 	}
-	return inApp.MarkAsRead(id)
+	return inApp.MarkAsReadBy(id, user)
 }
 
-// MarkAllNotificationsRead marks all in-app notifications as read.
-func (n *Notifier) MarkAllNotificationsRead() {
+// MarkAlertNotificationsRead marks the in-app notifications of an alert as
+// read, for user only when one is given, and returns the number of them.
+func (n *Notifier) MarkAlertNotificationsRead(alertID, user string) int {
+	ch, ok := n.channels[models.NotificationInApp]
+	if !ok {
+		return 0
+	}
+	inApp, ok := ch.(*InAppChannel)
+	if !ok {
+		return 0
+	}
+	return inApp.MarkAlertAsReadBy(alertID, user)
+}
+
+// MarkAllNotificationsRead marks all in-app notifications as read, for user
+// only when one is given.
+func (n *Notifier) MarkAllNotificationsRead(user string) {
 	ch, ok := n.channels[models.NotificationInApp]
 	if !ok {
 		return
@@ -865,7 +1534,7 @@ func (n *Notifier) MarkAllNotificationsRead() {
 	if !ok {
 		return
 	}
-	inApp.MarkAllAsRead()
+	inApp.MarkAllAsReadBy(user)
 }
 
 // ClearNotifications removes all in-app notifications.
@@ -881,14 +1550,68 @@ func (n *Notifier) ClearNotifications() {
 	inApp.ClearNotifications()
 }
 
-// Stop gracefully shuts down the notifier
-func (n *Notifier) Stop() {
+// PurgeNotifications removes in-app notifications created before before and
+// returns the number removed
+func (n *Notifier) PurgeNotifications(before time.Time) int {
+	ch, ok := n.channels[models.NotificationInApp]
+	if !ok {
+		return 0
+	}
+	inApp, ok := ch.(*InAppChannel)
+	if !ok {
+		return 0
+	}
+	return inApp.PurgeBefore(before)
+}
+
+// NotificationUsage reports the number of in-app notifications
+func (n *Notifier) NotificationUsage() retention.Usage {
+	ch, ok := n.channels[models.NotificationInApp]
+	if !ok {
+		return retention.Usage{}
+	}
+	inApp, ok := ch.(*InAppChannel)
+	if !ok {
+		return retention.Usage{}
+	}
+	return inApp.Usage()
+}
+
+// QueueStats reports the fill level and drop count of every registered channel
+// with a bounded queue and of every dispatch queue
+func (n *Notifier) QueueStats() []QueueStats {
 	n.mu.RLock()
 	defer n.mu.RUnlock()
+	stats := make([]QueueStats, 0, len(n.channels)+len(n.dispatch))
+	for _, channel := range n.channels {
+		if q, ok := channel.(interface{ QueueStats() QueueStats }); ok {
+			stats = append(stats, q.QueueStats())
+		}
+	}
+	for _, q := range n.dispatch {
+		stats = append(stats, q.stats())
+	}
+	sort.Slice(stats, func(i, j int) bool { return stats[i].Name < stats[j].Name })
+	return stats
+}
+
+// Stop gracefully shuts down the notifier: queued notifications are delivered,
+// then the channels stop. Later events are dropped.
+func (n *Notifier) Stop() {
+	n.mu.Lock()
+	n.stopped = true
+	queues := n.dispatch
+	n.dispatch = make(map[models.NotificationType]*dispatchQueue)
+	n.mu.Unlock()
+	for _, q := range queues {
+		q.stop()
+	}
 
+	n.mu.RLock()
+	defer n.mu.RUnlock()
 	for _, channel := range n.channels {
-		if emailChannel, ok := channel.(*EmailChannel); ok {
-			emailChannel.Stop()
+		if stopper, ok := channel.(interface{ Stop() }); ok {
+			stopper.Stop()
 		}
 	}
 }
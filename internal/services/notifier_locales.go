@@ -0,0 +1,77 @@
+// File: internal/services/notifier_locales.go
+// Brief: Localized notification template sets
+// Detailed: Provides the non-English default templates, keyed by locale, used by the Notifier alongside DefaultTemplates.
+// Author: drama.lin@aver.com
+// Date: 2026-10-14
+
+package services
+
+import (
+	"argus/internal/i18n"
+	"argus/internal/models"
+)
+
+// DefaultLocaleTemplates provides template sets for locales other than English.
+// English always uses NotifierConfig.Templates (DefaultTemplates by default).
+var DefaultLocaleTemplates = map[i18n.Locale]map[models.AlertSeverity]map[models.AlertState]NotificationTemplate{
+	i18n.TraditionalChinese: {
+		models.SeverityInfo: {
+			models.StateActive:   zhTWTemplate("資訊", "觸發中"),
+			models.StateInactive: zhTWTemplate("資訊", "已解除"),
+		},
+		models.SeverityWarning: {
+			models.StateActive:   zhTWTemplate("警告", "觸發中"),
+			models.StateInactive: zhTWTemplate("警告", "已解除"),
+		},
+		models.SeverityCritical: {
+			models.StateActive:   zhTWTemplate("嚴重", "觸發中"),
+			models.StateInactive: zhTWTemplate("嚴重", "已解除"),
+		},
+	},
+}
+
+// zhTWSourceSection is the Traditional Chinese sourceSection
+const zhTWSourceSection = `{{ with .Source }}來源：{{ . }}
+{{ end }}`
+
+// zhTWOwnerSection is the Traditional Chinese ownerSection
+const zhTWOwnerSection = `{{ with .Alert }}{{ if or .Owner .Team .Contact }}
+{{ if .Owner }}負責人：{{ .Owner }}
+{{ end }}{{ if .Team }}團隊：{{ .Team }}
+{{ end }}{{ if .Contact }}聯絡方式：{{ .Contact }}
+{{ end }}{{ end }}{{ end }}`
+
+// zhTWEventContextSection is the Traditional Chinese eventContextSection
+const zhTWEventContextSection = `{{ with .Context }}{{ if .TopProcesses }}
+觸發時資源使用最高的程序：
+{{ range .TopProcesses }}  {{ .Name }}（PID {{ .PID }}）：CPU {{ printf "%.1f" .CPUPercent }}%，記憶體 {{ printf "%.1f" .MemPercent }}%
+{{ end }}{{ end }}{{ if .Partitions }}
+受影響的分割區：
+{{ range .Partitions }}  {{ .Mountpoint }}（{{ .Device }}）：已使用 {{ printf "%.1f" .UsedPercent }}%，剩餘 {{ .FreeHuman }}
+{{ end }}{{ end }}{{ if .Samples }}
+觸發前的近期數值：{{ .Sparkline }}（{{ len .Samples }} 筆取樣）
+{{ end }}{{ end }}`
+
+// zhTWTemplate builds a Traditional Chinese template. Resolved notifications
+// use the state label in the subject tag, matching the English [RESOLVED] form.
+func zhTWTemplate(severity, status string) NotificationTemplate {
+	tag := severity
+	if status == "已解除" {
+		tag = status
+	}
+	return NotificationTemplate{
+		Subject: "[" + tag + "] Argus 告警：{{ .Alert.Name }}",
+		Body: `
+告警：{{ .Alert.Name }}
+狀態：` + status + `
+嚴重程度：` + severity + `
+時間：{{ .Timestamp.Format "2006-01-02 15:04:05 MST" }}
+數值：{{ .DisplayValue }}
+閾值：{{ .Alert.Threshold.Operator }} {{ .Alert.Threshold.Display }}
+
+{{ .Message }}
+
+說明：{{ .Alert.Description }}
+` + zhTWSourceSection + zhTWOwnerSection + zhTWEventContextSection,
+	}
+}
@@ -17,7 +17,6 @@ import (
 	"github.com/stretchr/testify/mock"
 
 	"argus/internal/models"
-	"argus/internal/server"
 )
 
 func TestNewNotifier(t *testing.T) {
@@ -42,7 +41,7 @@ func TestNewNotifier(t *testing.T) {
 
 func TestRegisterChannel(t *testing.T) {
 	n := NewNotifier(nil)
-	inAppChannel := NewInAppChannel(100)
+	inAppChannel := NewInAppChannel(100, nopHub{})
 	n.RegisterChannel(inAppChannel)
 	channel, ok := n.GetChannel(models.NotificationInApp)
 	assert.True(t, ok)
@@ -51,7 +50,10 @@ func TestRegisterChannel(t *testing.T) {
 }
 
 func TestProcessEvent(t *testing.T) {
-	n := NewNotifier(nil)
+	// Without dispatch workers the channel is sent to inline
+	config := DefaultConfig()
+	config.DispatchWorkers = 0
+	n := NewNotifier(config)
 	mockChannel := &mockNotificationChannel{
 		sendFunc: func(event models.AlertEvent, subject, body string) error {
 			return nil
@@ -218,17 +220,24 @@ func TestEmailChannelSend(t *testing.T) {
 			"recipient": "test@example.com",
 		},
 	})
+	// Send only queues the job; the delivery failure reaches the result handler
+	results := make(chan error, 1)
+	channel.SetResultHandler(func(_ models.AlertEvent, _, _ string, err error) { results <- err })
+	defer channel.Stop()
 	err := channel.Send(event, "Test Subject", "Test Body")
+	require.NoError(t, err)
+	select {
+	case err = <-results:
+	case <-time.After(30 * time.Second):
+		t.Fatal("email job was not processed")
+	}
 	assert.Error(t, err)
-	assert.True(t, strings.Contains(err.Error(), "failed to send email") || strings.Contains(err.Error(), "no valid email recipient"))
+	assert.True(t, strings.Contains(err.Error(), "failed to send email") || strings.Contains(err.Error(), "SMTP"))
 }
 
 // InAppChannel tests
 func TestNewInAppChannel(t *testing.T) {
-	channel := NewInAppChannel(0)
-	assert.NotNil(t, channel)
-	assert.Equal(t, 100, channel.maxSize)
-	channel = NewInAppChannel(50)
+	channel := NewInAppChannel(50, nopHub{})
 	assert.NotNil(t, channel)
 	assert.Equal(t, 50, channel.maxSize)
 }
@@ -246,6 +255,11 @@ func (m *MockHub) Run() {
 	// No-op for testing
 }
 
+// nopHub discards broadcasts for tests that only inspect the stored list
+type nopHub struct{}
+
+func (nopHub) Broadcast([]byte) {}
+
 func TestInAppChannel_Send(t *testing.T) {
 	// Setup
 	mockHub := new(MockHub)
@@ -273,8 +287,8 @@ func TestInAppChannel_Send(t *testing.T) {
 
 	assert.Equal(t, "alert-1", capturedNotification.AlertID)
 	assert.Equal(t, subject, capturedNotification.Subject)
-	assert.Equal(t, body, capturedNotification.Body)
-	assert.False(t, capturedNotification.IsRead)
+	assert.Equal(t, body, capturedNotification.Message)
+	assert.False(t, capturedNotification.Read)
 
 	// Verify in-memory store
 	notifications := channel.GetNotifications()
@@ -301,7 +315,7 @@ func TestInAppChannel_MaxSize(t *testing.T) {
 }
 
 func TestInAppChannelGetUnreadNotifications(t *testing.T) {
-	channel := NewInAppChannel(10)
+	channel := NewInAppChannel(10, nopHub{})
 	event := createTestAlertEvent(t)
 	for i := 0; i < 3; i++ {
 		err := channel.Send(event, "Test Subject", "Test Body")
@@ -314,7 +328,7 @@ func TestInAppChannelGetUnreadNotifications(t *testing.T) {
 }
 
 func TestInAppChannelMarkAsRead(t *testing.T) {
-	channel := NewInAppChannel(10)
+	channel := NewInAppChannel(10, nopHub{})
 	event := createTestAlertEvent(t)
 	err := channel.Send(event, "Test Subject", "Test Body")
 	require.NoError(t, err)
@@ -330,7 +344,7 @@ func TestInAppChannelMarkAsRead(t *testing.T) {
 }
 
 func TestInAppChannelMarkAllAsRead(t *testing.T) {
-	channel := NewInAppChannel(10)
+	channel := NewInAppChannel(10, nopHub{})
 	event := createTestAlertEvent(t)
 	for i := 0; i < 3; i++ {
 		err := channel.Send(event, "Test Subject", "Test Body")
@@ -346,7 +360,7 @@ func TestInAppChannelMarkAllAsRead(t *testing.T) {
 }
 
 func TestInAppChannelClearNotifications(t *testing.T) {
-	channel := NewInAppChannel(10)
+	channel := NewInAppChannel(10, nopHub{})
 	event := createTestAlertEvent(t)
 	for i := 0; i < 3; i++ {
 		err := channel.Send(event, "Test Subject", "Test Body")
@@ -0,0 +1,109 @@
+// File: internal/services/overflow.go
+// Brief: Overflow policies for bounded event and notification queues
+// Detailed: Defines what happens when the evaluator event channel, the email queue or the in-app notification list is full (drop the oldest entry, drop the new one, or block for a while), and counts every drop so capacity problems show up in self-metrics.
+// Author: drama.lin@aver.com
+// Date: 2026-10-14
+
+package services
+
+import (
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"argus/internal/faults"
+)
+
+// OverflowPolicy decides what a full queue does with a new entry
+type OverflowPolicy string
+
+const (
+	OverflowDropOldest OverflowPolicy = "drop-oldest" // Evict the oldest queued entry to make room
+	OverflowDropNewest OverflowPolicy = "drop-newest" // Discard the new entry
+	OverflowBlock      OverflowPolicy = "block"       // Wait up to BlockTimeout for room, then discard the new entry
+)
+
+// ErrQueueFull is returned when a notification is discarded by a full queue.
+// It signals lack of capacity rather than a failing channel.
+var ErrQueueFull = errors.New("notification queue is full")
+
+// DefaultOverflowBlockTimeout bounds how long the block policy waits for room
+const DefaultOverflowBlockTimeout = 1 * time.Second
+
+// ParseOverflowPolicy validates a configured policy name. An empty name
+// returns def.
+func ParseOverflowPolicy(name string, def OverflowPolicy) (OverflowPolicy, error) {
+	switch p := OverflowPolicy(name); p {
+	case "":
+		return def, nil
+	case OverflowDropOldest, OverflowDropNewest, OverflowBlock:
+		return p, nil
+	}
+	return "", fmt.Errorf("invalid overflow policy %q: must be one of %s, %s, %s", name, OverflowDropOldest, OverflowDropNewest, OverflowBlock)
+}
+
+// OverflowConfig is the overflow behaviour of one bounded queue
+type OverflowConfig struct {
+	Policy       OverflowPolicy
+	BlockTimeout time.Duration // Only used by OverflowBlock
+}
+
+func (o OverflowConfig) blockTimeout() time.Duration {
+	if o.BlockTimeout <= 0 {
+		return DefaultOverflowBlockTimeout
+	}
+	return o.BlockTimeout
+}
+
+// QueueStats reports the fill level and drop count of a bounded queue
+type QueueStats struct {
+	Name     string         `json:"name"`
+	Policy   OverflowPolicy `json:"policy"`
+	Capacity int            `json:"capacity"`
+	Length   int            `json:"length"`
+	Dropped  uint64         `json:"dropped"`
+}
+
+// offer queues item on ch according to the overflow policy, adding to dropped
+// for every entry lost (the evicted oldest one or item itself). It reports
+// whether item was queued.
+func offer[T any](ch chan T, item T, overflow OverflowConfig, dropped *atomic.Uint64) bool {
+	if faults.Active(faults.QueueFull) {
+		dropped.Add(1)
+		return false
+	}
+	select {
+	case ch <- item:
+		return true
+	default:
+	}
+
+	switch overflow.Policy {
+	case OverflowDropOldest:
+		// Consumers may race us for the free slot, so retry a few times
+		for i := 0; i < 3; i++ {
+			select {
+			case <-ch:
+				dropped.Add(1)
+			default:
+			}
+			select {
+			case ch <- item:
+				return true
+			default:
+			}
+		}
+	case OverflowBlock:
+		timer := time.NewTimer(overflow.blockTimeout())
+		defer timer.Stop()
+		select {
+		case ch <- item:
+			return true
+		case <-timer.C:
+		}
+	}
+
+	dropped.Add(1)
+	return false
+}
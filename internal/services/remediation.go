@@ -0,0 +1,372 @@
+// File: internal/services/remediation.go
+// Brief: Remediation tasks run when alerts activate
+// Detailed: Runs the task bound to an alert's remediation once the alert has kept firing for the confirmation delay after activating, unless a guard rail holds it back: remediation disabled in the configuration, an alert severity below the remediation's minimum, a matching silence, or the maximum runs per rolling hour. Every remediation scheduled, cancelled, skipped, executed or failed is logged and appended to a JSON lines audit log next to the alert configurations; the latest entries are kept in memory for the API and the file is purged by the audit_logs retention policy.
+// Author: drama.lin@aver.com
+// Date: 2026-10-14
+
+package services
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"argus/internal/clock"
+	"argus/internal/models"
+	"argus/internal/retention"
+)
+
+// RemediationAlertMetadata names the alert in the executions of remediations
+const RemediationAlertMetadata = "remediation_alert"
+
+// DefaultRemediationAuditSize is the number of audit records kept in memory
+const DefaultRemediationAuditSize = 500
+
+// RemediationRunner runs the tasks of remediations
+type RemediationRunner interface {
+	RunTaskWithMetadata(taskID string, metadata map[string]string) (*models.TaskExecution, error)
+}
+
+// RemediatorConfig configures the remediator
+type RemediatorConfig struct {
+	Enabled   bool        // Disabled remediations are audited as skipped
+	AuditPath string      // JSON lines audit log; empty keeps records in memory only
+	AuditSize int         // Records kept in memory for the API
+	Clock     clock.Clock // nil uses the real clock
+}
+
+// Remediator runs the remediation tasks of activated alerts
+type Remediator struct {
+	config   RemediatorConfig
+	clock    clock.Clock
+	mu       sync.Mutex
+	runner   RemediationRunner
+	silencer Silencer
+	pending  map[string]chan struct{} // Alert ID to the confirmation waiting for its delay; closed to cancel it
+	runs     map[string][]time.Time   // Alert ID to its remediation runs within the last hour
+	records  []models.RemediationRecord
+	wg       sync.WaitGroup
+	done     chan struct{}
+}
+
+// NewRemediator creates a remediator and loads the latest records of the
+// audit log
+func NewRemediator(config RemediatorConfig) (*Remediator, error) {
+	if config.AuditSize <= 0 {
+		config.AuditSize = DefaultRemediationAuditSize
+	}
+	r := &Remediator{
+		config:  config,
+		clock:   clock.OrReal(config.Clock),
+		pending: make(map[string]chan struct{}),
+		runs:    make(map[string][]time.Time),
+		done:    make(chan struct{}),
+	}
+	records, err := r.readAudit()
+	if err != nil {
+		return nil, err
+	}
+	if len(records) > config.AuditSize {
+		records = records[len(records)-config.AuditSize:]
+	}
+	r.records = records
+	return r, nil
+}
+
+// SetRunner sets the scheduler running remediation tasks
+func (r *Remediator) SetRunner(runner RemediationRunner) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.runner = runner
+}
+
+// SetSilencer holds back the remediations of silenced alerts
+func (r *Remediator) SetSilencer(silencer Silencer) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.silencer = silencer
+}
+
+// ProcessEvent schedules the remediation of an activated alert and cancels
+// it when the alert stops firing before the confirmation delay ends
+func (r *Remediator) ProcessEvent(event models.AlertEvent) {
+	alert := event.Alert
+	if alert == nil || alert.Remediation == nil {
+		return
+	}
+	remediation := alert.Remediation
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	switch {
+	case event.NewState.Firing() && !event.OldState.Firing():
+		if _, waiting := r.pending[event.AlertID]; waiting {
+			return
+		}
+		switch {
+		case !r.config.Enabled:
+			r.record(event, models.RemediationSkipped, "remediation is disabled (alerts.remediation.enabled)", nil)
+		case !remediation.Remediates(alert.Severity):
+			lowest := remediation.MinSeverity
+			if lowest == "" {
+				lowest = models.SeverityCritical
+			}
+			r.record(event, models.RemediationSkipped, fmt.Sprintf("severity %s is below %s", alert.Severity, lowest), nil)
+		default:
+			delay := remediation.ConfirmationDelay()
+			cancel := make(chan struct{})
+			r.pending[event.AlertID] = cancel
+			r.record(event, models.RemediationScheduled, fmt.Sprintf("runs after %s unless the alert stops firing", delay), nil)
+			r.wg.Add(1)
+			go r.confirm(event, cancel, delay)
+		}
+	case !event.NewState.Firing():
+		if cancel, waiting := r.pending[event.AlertID]; waiting {
+			close(cancel)
+			delete(r.pending, event.AlertID)
+			r.record(event, models.RemediationCancelled, fmt.Sprintf("alert %s during the confirmation delay", event.NewState), nil)
+		}
+	}
+}
+
+// confirm runs the remediation of event once its delay has passed, unless it
+// was cancelled meanwhile or a guard rail holds it back
+func (r *Remediator) confirm(event models.AlertEvent, cancel chan struct{}, delay time.Duration) {
+	defer r.wg.Done()
+	select {
+	case <-cancel:
+		return
+	case <-r.done:
+		return
+	case <-r.clock.After(delay):
+	}
+
+	r.mu.Lock()
+	if r.pending[event.AlertID] != cancel {
+		r.mu.Unlock()
+		return
+	}
+	delete(r.pending, event.AlertID)
+	now := r.clock.Now()
+	remediation := event.Alert.Remediation
+	recent := r.recentRuns(event.AlertID, now)
+	var silences []string
+	if r.silencer != nil {
+		silences = r.silencer.Silenced(event.Alert.LabelSet(), now)
+	}
+	runner := r.runner
+	run := false
+	switch {
+	case len(silences) > 0:
+		r.record(event, models.RemediationSkipped, fmt.Sprintf("alert is silenced by %v", silences), nil)
+	case len(recent) >= remediation.RunsPerHour():
+		r.record(event, models.RemediationSkipped, fmt.Sprintf("max_runs_per_hour (%d) reached", remediation.RunsPerHour()), nil)
+	case runner == nil:
+		r.record(event, models.RemediationFailed, "tasks are not available", nil)
+	default:
+		// Counted before running, so a failing task is not retried more often
+		r.runs[event.AlertID] = append(recent, now)
+		run = true
+	}
+	r.mu.Unlock()
+	if !run {
+		return
+	}
+
+	slog.Info("Running remediation task", "alert_id", event.AlertID, "task_id", remediation.TaskID)
+	execution, err := runner.RunTaskWithMetadata(remediation.TaskID, map[string]string{RemediationAlertMetadata: event.AlertID})
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err != nil {
+		r.record(event, models.RemediationFailed, err.Error(), nil)
+		return
+	}
+	reason := ""
+	if execution.Status == models.StatusFailed {
+		reason = execution.Error
+	}
+	r.record(event, models.RemediationExecuted, reason, execution)
+}
+
+// recentRuns returns the runs of an alert's remediation within the hour
+// before now, forgetting older ones; the caller holds r.mu
+func (r *Remediator) recentRuns(alertID string, now time.Time) []time.Time {
+	runs := r.runs[alertID]
+	kept := runs[:0]
+	for _, at := range runs {
+		if now.Sub(at) < time.Hour {
+			kept = append(kept, at)
+		}
+	}
+	if len(kept) == 0 {
+		delete(r.runs, alertID)
+		return nil
+	}
+	r.runs[alertID] = kept
+	return kept
+}
+
+// record logs an audit record and appends it to the audit log; the caller
+// holds r.mu
+func (r *Remediator) record(event models.AlertEvent, action models.RemediationAction, reason string, execution *models.TaskExecution) {
+	record := models.RemediationRecord{
+		ID:        generateID(),
+		Timestamp: r.clock.Now().UTC(),
+		AlertID:   event.AlertID,
+		AlertName: event.Alert.Name,
+		Severity:  event.Alert.Severity,
+		Value:     event.CurrentValue,
+		TaskID:    event.Alert.Remediation.TaskID,
+		Action:    action,
+		Reason:    reason,
+	}
+	if execution != nil {
+		record.ExecutionID, record.Status = execution.ExecutionID, execution.Status
+	}
+	slog.Info("Remediation "+string(action), "alert_id", record.AlertID, "task_id", record.TaskID,
+		"reason", record.Reason, "execution_id", record.ExecutionID)
+
+	if len(r.records) >= r.config.AuditSize {
+		r.records = r.records[1:]
+	}
+	r.records = append(r.records, record)
+	if err := r.appendAudit(record); err != nil {
+		slog.Error("Failed to write remediation audit log", "error", err)
+	}
+}
+
+// Records returns the latest audit records, newest first, of one alert or
+// of all alerts when alertID is empty
+func (r *Remediator) Records(alertID string) []models.RemediationRecord {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	result := make([]models.RemediationRecord, 0, len(r.records))
+	for i := len(r.records) - 1; i >= 0; i-- {
+		if alertID == "" || r.records[i].AlertID == alertID {
+			result = append(result, r.records[i])
+		}
+	}
+	return result
+}
+
+func (r *Remediator) appendAudit(record models.RemediationRecord) error {
+	if r.config.AuditPath == "" {
+		return nil
+	}
+	data, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(r.config.AuditPath), 0755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(r.config.AuditPath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// readAudit reads every record of the audit log, skipping unreadable lines
+func (r *Remediator) readAudit() ([]models.RemediationRecord, error) {
+	if r.config.AuditPath == "" {
+		return nil, nil
+	}
+	data, err := os.ReadFile(r.config.AuditPath)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read remediation audit log: %w", err)
+	}
+	var records []models.RemediationRecord
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 0, 64<<10), 1<<20)
+	for scanner.Scan() {
+		var record models.RemediationRecord
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			slog.Warn("Skipping unreadable remediation audit record", "error", err)
+			continue
+		}
+		records = append(records, record)
+	}
+	return records, scanner.Err()
+}
+
+// AuditUsage reports the records of the audit log for the retention engine
+func (r *Remediator) AuditUsage() (retention.Usage, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	records, err := r.readAudit()
+	if err != nil {
+		return retention.Usage{}, err
+	}
+	var usage retention.Usage
+	for _, record := range records {
+		usage.Observe(record.Timestamp)
+	}
+	if info, err := os.Stat(r.config.AuditPath); err == nil {
+		usage.Bytes = info.Size()
+	}
+	return usage, nil
+}
+
+// PurgeAudit removes the audit records older than before
+func (r *Remediator) PurgeAudit(before time.Time) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	kept := r.records[:0]
+	for _, record := range r.records {
+		if !record.Timestamp.Before(before) {
+			kept = append(kept, record)
+		}
+	}
+	r.records = kept
+
+	records, err := r.readAudit()
+	if err != nil || len(records) == 0 {
+		return 0, err
+	}
+	var buf bytes.Buffer
+	purged := 0
+	for _, record := range records {
+		if record.Timestamp.Before(before) {
+			purged++
+			continue
+		}
+		data, err := json.Marshal(record)
+		if err != nil {
+			return 0, err
+		}
+		buf.Write(append(data, '\n'))
+	}
+	if purged == 0 {
+		return 0, nil
+	}
+	tmp := r.config.AuditPath + ".tmp"
+	if err := os.WriteFile(tmp, buf.Bytes(), 0644); err != nil {
+		return 0, fmt.Errorf("failed to write remediation audit log: %w", err)
+	}
+	if err := os.Rename(tmp, r.config.AuditPath); err != nil {
+		return 0, fmt.Errorf("failed to write remediation audit log: %w", err)
+	}
+	return purged, nil
+}
+
+// Stop abandons the remediations waiting for their delay and waits for the
+// running ones
+func (r *Remediator) Stop() {
+	close(r.done)
+	r.wg.Wait()
+}
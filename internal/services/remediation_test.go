@@ -0,0 +1,118 @@
+package services
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"argus/internal/clock"
+	"argus/internal/models"
+)
+
+// countingRunner completes every remediation task it is asked to run
+type countingRunner struct {
+	runs atomic.Int32
+}
+
+func (r *countingRunner) RunTaskWithMetadata(taskID string, metadata map[string]string) (*models.TaskExecution, error) {
+	r.runs.Add(1)
+	return &models.TaskExecution{ExecutionID: generateID(), TaskID: taskID, Status: models.StatusCompleted, Metadata: metadata}, nil
+}
+
+func remediationEvent(from, to models.AlertState) models.AlertEvent {
+	return models.AlertEvent{
+		AlertID:  "disk-full",
+		OldState: from,
+		NewState: to,
+		Alert: &models.AlertConfig{
+			ID:       "disk-full",
+			Name:     "Disk Full",
+			Severity: models.SeverityCritical,
+			Remediation: &models.RemediationConfig{
+				TaskID:         "cleanup-logs",
+				Delay:          "2m",
+				MaxRunsPerHour: 1,
+			},
+		},
+	}
+}
+
+// lastAction waits for the newest audit record of the alert to have action
+func lastAction(t *testing.T, r *Remediator, action models.RemediationAction) models.RemediationRecord {
+	t.Helper()
+	var record models.RemediationRecord
+	require.Eventually(t, func() bool {
+		records := r.Records("disk-full")
+		if len(records) == 0 {
+			return false
+		}
+		record = records[0]
+		return record.Action == action
+	}, 5*time.Second, time.Millisecond)
+	return record
+}
+
+func TestRemediator_Cooldown(t *testing.T) {
+	clk := clock.NewFake(time.Now())
+	r, err := NewRemediator(RemediatorConfig{Enabled: true, Clock: clk})
+	require.NoError(t, err)
+	t.Cleanup(r.Stop)
+	runner := &countingRunner{}
+	r.SetRunner(runner)
+
+	fire := func() {
+		r.ProcessEvent(remediationEvent(models.StateResolved, models.StatePending))
+		lastAction(t, r, models.RemediationScheduled)
+		clk.BlockUntil(1)
+		clk.Advance(2 * time.Minute)
+	}
+	resolve := func() {
+		r.ProcessEvent(remediationEvent(models.StatePending, models.StateResolved))
+	}
+
+	// The task runs once the alert kept firing for the confirmation delay
+	fire()
+	record := lastAction(t, r, models.RemediationExecuted)
+	assert.Equal(t, "cleanup-logs", record.TaskID)
+	assert.Equal(t, models.StatusCompleted, record.Status)
+	assert.Equal(t, int32(1), runner.runs.Load())
+
+	// Firing again within the hour is held back by max_runs_per_hour
+	resolve()
+	clk.Advance(5 * time.Minute)
+	fire()
+	record = lastAction(t, r, models.RemediationSkipped)
+	assert.Equal(t, "max_runs_per_hour (1) reached", record.Reason)
+	assert.Equal(t, int32(1), runner.runs.Load())
+
+	// Once the hour has passed it runs again
+	resolve()
+	clk.Advance(time.Hour)
+	fire()
+	lastAction(t, r, models.RemediationExecuted)
+	assert.Equal(t, int32(2), runner.runs.Load())
+}
+
+func TestRemediator_CancelledDuringDelay(t *testing.T) {
+	clk := clock.NewFake(time.Now())
+	r, err := NewRemediator(RemediatorConfig{Enabled: true, Clock: clk})
+	require.NoError(t, err)
+	t.Cleanup(r.Stop)
+	runner := &countingRunner{}
+	r.SetRunner(runner)
+
+	r.ProcessEvent(remediationEvent(models.StateInactive, models.StatePending))
+	lastAction(t, r, models.RemediationScheduled)
+	clk.BlockUntil(1)
+
+	// An alert resolving before the delay ends cancels the remediation
+	clk.Advance(time.Minute)
+	r.ProcessEvent(remediationEvent(models.StatePending, models.StateResolved))
+	lastAction(t, r, models.RemediationCancelled)
+	clk.Advance(time.Hour)
+	assert.Zero(t, runner.runs.Load())
+	assert.Len(t, r.Records("disk-full"), 2)
+}
@@ -11,10 +11,16 @@ import (
 	"errors"
 	"fmt"
 	"log/slog"
+	"maps"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
+	"argus/internal/clock"
+	"argus/internal/metrics"
 	"argus/internal/models"
+	"argus/internal/redact"
 
 	"github.com/robfig/cron/v3"
 )
@@ -25,10 +31,46 @@ const (
 	DefaultTaskTimeout        = 30 * time.Minute
 )
 
+// Execution metadata keys recording when a deferred run was originally due
+// and why it was deferred
+const (
+	DeferredFromMetadata   = "deferred_from"
+	DeferredReasonMetadata = "deferred_reason"
+)
+
+// DryRunMetadata marks the executions of dry runs
+const DryRunMetadata = "dry_run"
+
 type TaskSchedulerConfig struct {
 	CheckInterval      time.Duration
 	MaxConcurrentTasks int
 	TaskTimeout        time.Duration
+	// Location is used for tasks whose schedule sets no timezone (nil means server local time)
+	Location *time.Location
+	// Redactor masks secrets in execution output and errors before they are recorded (nil disables)
+	Redactor *redact.Redactor
+	// Clock drives the schedule check loop and due-time calculations (nil uses the real clock)
+	Clock clock.Clock
+	// Throttle defers non-critical tasks while the host is busy (zero disables)
+	Throttle ThrottleConfig
+	// DryRun runs every task as a dry run, whatever its dry_run parameter
+	DryRun bool
+}
+
+// cronParser is the parser used for all task cron expressions: the five
+// standard fields (minute, hour, day of month, month, day of week) with
+// ranges, lists, steps and names, e.g. "30 2 * * 1-5" or "0 */6 * * MON-FRI",
+// and the macros @yearly (@annually), @monthly, @weekly, @daily (@midnight),
+// @hourly and @every <duration>. When both day fields are restricted, either
+// matching is enough, as in Vixie cron.
+var cronParser = cron.NewParser(cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow | cron.Descriptor)
+
+// ValidateCronExpression checks that a cron expression can be parsed by the task scheduler
+func ValidateCronExpression(expr string) error {
+	if _, err := cronParser.Parse(expr); err != nil {
+		return fmt.Errorf("invalid cron expression %q: %w", expr, err)
+	}
+	return nil
 }
 
 func DefaultTaskSchedulerConfig() *TaskSchedulerConfig {
@@ -41,15 +83,19 @@ func DefaultTaskSchedulerConfig() *TaskSchedulerConfig {
 
 type TaskScheduler struct {
 	config     *TaskSchedulerConfig
+	clock      clock.Clock
 	repository models.TaskRepository
 	runners    map[models.TaskType]TaskRunner
 	semaphore  chan struct{}
-	cronParser cron.Parser
 	wg         sync.WaitGroup
 	ctx        context.Context
 	cancel     context.CancelFunc
 	mutex      sync.RWMutex
 	running    bool
+	collector  *metrics.Collector       // Host load for the throttle
+	throttled  map[string]ThrottledTask // Runs held back at the last check, by task ID
+	deferrals  atomic.Uint64
+	agentJobs  *AgentJobs // Runs queued for agents (nil without a host registry)
 }
 
 func NewTaskScheduler(repo models.TaskRepository, config *TaskSchedulerConfig) *TaskScheduler {
@@ -59,10 +105,10 @@ func NewTaskScheduler(repo models.TaskRepository, config *TaskSchedulerConfig) *
 	ctx, cancel := context.WithCancel(context.Background())
 	return &TaskScheduler{
 		config:     config,
+		clock:      clock.OrReal(config.Clock),
 		repository: repo,
 		runners:    make(map[models.TaskType]TaskRunner),
 		semaphore:  make(chan struct{}, config.MaxConcurrentTasks),
-		cronParser: cron.NewParser(cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow),
 		ctx:        ctx,
 		cancel:     cancel,
 		running:    false,
@@ -109,14 +155,14 @@ func (s *TaskScheduler) Stop() {
 
 func (s *TaskScheduler) scheduleLoop() {
 	defer s.wg.Done()
-	ticker := time.NewTicker(s.config.CheckInterval)
+	ticker := s.clock.NewTicker(s.config.CheckInterval)
 	defer ticker.Stop()
 	if err := s.checkScheduledTasks(); err != nil {
 		slog.Error("Error checking scheduled tasks", "error", err)
 	}
 	for {
 		select {
-		case <-ticker.C:
+		case <-ticker.C():
 			if err := s.checkScheduledTasks(); err != nil {
 				slog.Error("Error checking scheduled tasks", "error", err)
 			}
@@ -132,12 +178,38 @@ func (s *TaskScheduler) checkScheduledTasks() error {
 	if err != nil {
 		return fmt.Errorf("failed to list tasks: %w", err)
 	}
-	now := time.Now()
+	now := s.clock.Now()
+	s.expireAgentJobs(now)
+	var reason string
+	if s.config.Throttle.Enabled() {
+		reason = s.config.Throttle.throttleReason(s.hostLoad())
+	}
+	throttled := make(map[string]ThrottledTask)
+	defer func() {
+		s.mutex.Lock()
+		s.throttled = throttled
+		s.mutex.Unlock()
+	}()
 	for _, task := range tasks {
 		if !task.Enabled {
 			continue
 		}
+		if task.Schedule.NextRunTime.IsZero() && task.Schedule.CronExpression != "" {
+			// Tasks stored without a next run time start at their next activation
+			if err := s.updateNextRunTime(task); err != nil {
+				slog.Error("Failed to schedule task", "task_id", task.ID, "error", err)
+			}
+			continue
+		}
 		if !task.Schedule.NextRunTime.IsZero() && task.Schedule.NextRunTime.Before(now) {
+			if loc := task.Schedule.Location(s.defaultLocation()); !task.InWindow(now, loc) {
+				s.deferTask(task, now, loc)
+				continue
+			}
+			// Runs on agents do not load this host
+			if reason != "" && !task.Critical && task.Agents == nil && s.throttle(task, now, reason, throttled) {
+				continue
+			}
 			s.wg.Add(1)
 			go func(t *models.TaskConfig) {
 				defer s.wg.Done()
@@ -155,8 +227,56 @@ func (s *TaskScheduler) checkScheduledTasks() error {
 	return nil
 }
 
+// deferTask moves a run due outside the task's execution windows to the start
+// of the next window, remembering when it was originally due
+func (s *TaskScheduler) deferTask(task *models.TaskConfig, now time.Time, loc *time.Location) {
+	if task.Schedule.DeferredFrom == nil {
+		due := task.Schedule.NextRunTime
+		task.Schedule.DeferredFrom = &due
+	}
+	windows := make([]string, len(task.Windows))
+	for i, w := range task.Windows {
+		windows[i] = w.String()
+	}
+	task.Schedule.DeferredReason = "outside the execution windows " + strings.Join(windows, ", ")
+	task.Schedule.NextRunTime = task.WindowStart(now, loc)
+	slog.Info("Deferring task to its execution window",
+		"task_id", task.ID,
+		"task_name", task.Name,
+		"due", task.Schedule.DeferredFrom,
+		"next_run_time", task.Schedule.NextRunTime)
+	if err := s.repository.UpdateTask(s.ctx, task); err != nil {
+		slog.Error("Failed to defer task", "task_id", task.ID, "error", err)
+	}
+}
+
 func (s *TaskScheduler) executeTask(task *models.TaskConfig) error {
 	slog.Info("Executing scheduled task", "task_id", task.ID, "task_name", task.Name)
+	run, metadata := s.runTask(task, deferralMetadata(task))
+	if run.Agents != nil {
+		if err := s.runOnAgents(run, metadata); err != nil {
+			return err
+		}
+	} else if err := s.runLocally(run, metadata); err != nil {
+		return err
+	}
+	task.Schedule.DeferredFrom, task.Schedule.DeferredReason = nil, ""
+	if !task.Schedule.OneTime {
+		if err := s.updateNextRunTime(task); err != nil {
+			return fmt.Errorf("failed to update next run time: %w", err)
+		}
+	} else {
+		task.Enabled = false
+		if err := s.repository.UpdateTask(s.ctx, task); err != nil {
+			return fmt.Errorf("failed to disable one-time task: %w", err)
+		}
+	}
+	return nil
+}
+
+// runLocally runs a task with the runner of its type and records the execution
+// with metadata
+func (s *TaskScheduler) runLocally(task *models.TaskConfig, metadata map[string]string) error {
 	s.mutex.RLock()
 	runner, exists := s.runners[task.Type]
 	s.mutex.RUnlock()
@@ -169,41 +289,160 @@ func (s *TaskScheduler) executeTask(task *models.TaskConfig) error {
 	if err != nil {
 		return fmt.Errorf("task execution failed: %w", err)
 	}
-	if err := s.repository.RecordExecution(s.ctx, execution); err != nil {
-		return fmt.Errorf("failed to record task execution: %w", err)
-	}
-	if !task.Schedule.OneTime {
-		if err := s.updateNextRunTime(task); err != nil {
-			return fmt.Errorf("failed to update next run time: %w", err)
+	s.redactExecution(execution)
+	if len(metadata) > 0 {
+		if execution.Metadata == nil {
+			execution.Metadata = make(map[string]string)
 		}
-	} else {
-		task.Enabled = false
-		if err := s.repository.UpdateTask(s.ctx, task); err != nil {
-			return fmt.Errorf("failed to disable one-time task: %w", err)
+		for k, v := range metadata {
+			execution.Metadata[k] = v
 		}
 	}
+	s.compareCleanup(execution)
+	if err := s.repository.RecordExecution(s.ctx, execution); err != nil {
+		return fmt.Errorf("failed to record task execution: %w", err)
+	}
 	return nil
 }
 
+// runTask returns the task a run hands to its runner and the metadata recorded
+// with its execution. A dry run, asked for by the scheduler, the task's
+// dry_run parameter or DryRunMetadata in metadata, gets a copy of the task
+// with the parameter set, so runners that delete or modify files, also those
+// of agents, only report what they would do.
+func (s *TaskScheduler) runTask(task *models.TaskConfig, metadata map[string]string) (*models.TaskConfig, map[string]string) {
+	if !s.config.DryRun && !task.DryRun() && metadata[DryRunMetadata] != "true" {
+		return task, metadata
+	}
+	run := *task
+	run.Parameters = make(map[string]string, len(task.Parameters)+1)
+	maps.Copy(run.Parameters, task.Parameters)
+	run.Parameters[models.ParamDryRun] = "true"
+	dryRun := make(map[string]string, len(metadata)+1)
+	maps.Copy(dryRun, metadata)
+	dryRun[DryRunMetadata] = "true"
+	return &run, dryRun
+}
+
+// deferralMetadata returns the execution metadata of a deferred run, or nil
+// when the run was not deferred
+func deferralMetadata(task *models.TaskConfig) map[string]string {
+	deferred := task.Schedule.DeferredFrom
+	if deferred == nil {
+		return nil
+	}
+	return map[string]string{
+		DeferredFromMetadata:   deferred.UTC().Format(time.RFC3339),
+		DeferredReasonMetadata: task.Schedule.DeferredReason,
+	}
+}
+
 func (s *TaskScheduler) updateNextRunTime(task *models.TaskConfig) error {
+	if err := ScheduleNextRun(task, s.defaultLocation(), s.clock.Now()); err != nil {
+		return err
+	}
+	return s.repository.UpdateTask(s.ctx, task)
+}
+
+// ScheduleNextRun sets the next run time of a task to the first activation of
+// its cron expression after now, evaluated in the task's timezone, or fallback
+// when it has none
+func ScheduleNextRun(task *models.TaskConfig, fallback *time.Location, now time.Time) error {
 	if task.Schedule.CronExpression == "" {
 		return fmt.Errorf("task has no cron expression")
 	}
-	schedule, err := s.cronParser.Parse(task.Schedule.CronExpression)
+	schedule, err := cronParser.Parse(task.Schedule.CronExpression)
 	if err != nil {
-		return fmt.Errorf("invalid cron expression: %w", err)
+		return fmt.Errorf("invalid cron expression %q: %w", task.Schedule.CronExpression, err)
+	}
+	task.Schedule.NextRunTime = NextRunTime(schedule, task.Schedule.Location(fallback), now)
+	return nil
+}
+
+// redactExecution masks secrets in an execution's output before it is stored or returned
+func (s *TaskScheduler) redactExecution(execution *models.TaskExecution) {
+	if execution == nil || s.config.Redactor == nil {
+		return
+	}
+	execution.Output = s.config.Redactor.Redact(execution.Output)
+	execution.Error = s.config.Redactor.Redact(execution.Error)
+	for k, v := range execution.Metadata {
+		execution.Metadata[k] = s.config.Redactor.Redact(v)
 	}
-	now := time.Now()
-	nextRun := schedule.Next(now)
-	task.Schedule.NextRunTime = nextRun
-	return s.repository.UpdateTask(s.ctx, task)
+}
+
+// defaultLocation returns the timezone for schedules that don't set one
+func (s *TaskScheduler) defaultLocation() *time.Location {
+	if s.config.Location == nil {
+		return time.Local
+	}
+	return s.config.Location
+}
+
+// NextRunTime evaluates schedule in loc and returns the next activation after now in UTC,
+// so a "0 9 * * *" task in Asia/Taipei fires at 09:00 Taipei time whatever the server zone.
+func NextRunTime(schedule cron.Schedule, loc *time.Location, now time.Time) time.Time {
+	return schedule.Next(now.In(loc)).UTC()
+}
+
+// UpcomingRuns returns the activations of an enabled task after from and up to
+// until, in UTC and at most limit of them. Cron schedules are evaluated in the
+// task's timezone, or fallback when it has none; one-time tasks yield their
+// stored next run time. Activations outside the task's execution windows are
+// deferred to the next window start, where they run once.
+func UpcomingRuns(task *models.TaskConfig, fallback *time.Location, from, until time.Time, limit int) []time.Time {
+	if !task.Enabled {
+		return nil
+	}
+	loc := task.Schedule.Location(fallback)
+	if task.Schedule.CronExpression == "" {
+		next := task.WindowStart(task.Schedule.NextRunTime, loc)
+		if task.Schedule.OneTime && next.After(from) && !next.After(until) {
+			return []time.Time{next}
+		}
+		return nil
+	}
+	schedule, err := cronParser.Parse(task.Schedule.CronExpression)
+	if err != nil {
+		return nil
+	}
+	var runs []time.Time
+	for next := NextRunTime(schedule, loc, from); !next.IsZero() && len(runs) < limit; next = NextRunTime(schedule, loc, next) {
+		run := task.WindowStart(next, loc)
+		if run.After(until) {
+			break
+		}
+		if len(runs) == 0 || run.After(runs[len(runs)-1]) {
+			runs = append(runs, run)
+		}
+		if run.After(next) {
+			// Skip the activations deferred to the same window start
+			next = run.Add(-time.Nanosecond)
+		}
+	}
+	return runs
 }
 
 func (s *TaskScheduler) RunTaskNow(taskID string) (*models.TaskExecution, error) {
+	return s.RunTaskWithMetadata(taskID, nil)
+}
+
+// RunTaskWithMetadata is RunTaskNow recording metadata with the execution,
+// e.g. the alert a remediation ran the task for. DryRunMetadata set to "true"
+// makes it a dry run.
+func (s *TaskScheduler) RunTaskWithMetadata(taskID string, metadata map[string]string) (*models.TaskExecution, error) {
 	task, err := s.repository.GetTask(s.ctx, taskID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get task: %w", err)
 	}
+	task, metadata = s.runTask(task, metadata)
+	if task.Agents != nil {
+		jobs, err := s.dispatchTask(task, metadata)
+		if err != nil {
+			return nil, err
+		}
+		return dispatchedExecution(task, jobs), nil
+	}
 	s.mutex.RLock()
 	runner, exists := s.runners[task.Type]
 	s.mutex.RUnlock()
@@ -216,6 +455,16 @@ func (s *TaskScheduler) RunTaskNow(taskID string) (*models.TaskExecution, error)
 	if err != nil {
 		return nil, fmt.Errorf("task execution failed: %w", err)
 	}
+	if len(metadata) > 0 {
+		if execution.Metadata == nil {
+			execution.Metadata = make(map[string]string)
+		}
+		for k, v := range metadata {
+			execution.Metadata[k] = v
+		}
+	}
+	s.redactExecution(execution)
+	s.compareCleanup(execution)
 	if err := s.repository.RecordExecution(s.ctx, execution); err != nil {
 		return nil, fmt.Errorf("failed to record task execution: %w", err)
 	}
@@ -229,6 +478,9 @@ var (
 	ErrInvalidParameter    = errors.New("invalid task parameter")
 )
 
+// TaskRunner runs the tasks of a type. Runners that delete or modify files must
+// honor the task's DryRun: report the actions they would take, in the same
+// execution output and metadata as a real run, without performing them.
 type TaskRunner interface {
 	Run(ctx context.Context, task *models.TaskConfig) (*models.TaskExecution, error)
 	GetType() models.TaskType
@@ -249,7 +501,7 @@ func NewTaskRunner(taskType models.TaskType) (TaskRunner, error) {
 	case models.TaskMetricsAggregation:
 		return &MetricsAggregationRunner{BaseTaskRunner{taskType: models.TaskMetricsAggregation}}, nil
 	case models.TaskHealthCheck:
-		return &HealthCheckRunner{BaseTaskRunner{taskType: models.TaskHealthCheck}}, nil
+		return &HealthCheckRunner{BaseTaskRunner: BaseTaskRunner{taskType: models.TaskHealthCheck}}, nil
 	case models.TaskSystemCleanup:
 		return &SystemCleanupRunner{BaseTaskRunner{taskType: models.TaskSystemCleanup}}, nil
 	default:
@@ -273,14 +525,6 @@ func (r *MetricsAggregationRunner) Run(ctx context.Context, task *models.TaskCon
 	return nil, errors.New("MetricsAggregationRunner not implemented")
 }
 
-type HealthCheckRunner struct {
-	BaseTaskRunner
-}
-
-func (r *HealthCheckRunner) Run(ctx context.Context, task *models.TaskConfig) (*models.TaskExecution, error) {
-	return nil, errors.New("HealthCheckRunner not implemented")
-}
-
 type SystemCleanupRunner struct {
 	BaseTaskRunner
 }
@@ -297,6 +541,7 @@ func (r *SystemCleanupRunner) Run(ctx context.Context, task *models.TaskConfig)
 //go:generate mockery --name TaskSchedulerInterface --output ../mocks --case=underscore
 type TaskSchedulerInterface interface {
 	RunTaskNow(taskID string) (*models.TaskExecution, error)
+	RunTaskWithMetadata(taskID string, metadata map[string]string) (*models.TaskExecution, error)
 	Start() error
 	Stop()
 }
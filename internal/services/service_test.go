@@ -10,6 +10,8 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -127,10 +129,12 @@ func TestTaskSchedulerStartStop(t *testing.T) {
 type mockTaskRunner struct {
 	taskType      models.TaskType
 	runFunc       func(context.Context, *models.TaskConfig) (*models.TaskExecution, error)
-	delay         time.Duration           // simulates task execution time
-	shouldTimeout bool                    // forces task to timeout
-	errorOnRun    error                   // simulates run error
+	delay         time.Duration // simulates task execution time
+	shouldTimeout bool          // forces task to timeout
+	errorOnRun    error         // simulates run error
+	mu            sync.Mutex
 	executions    []*models.TaskExecution // tracks all executions
+	attempts      atomic.Int32            // counts Run calls, including failed ones
 }
 
 func newMockTaskRunner(taskType models.TaskType) *mockTaskRunner {
@@ -145,6 +149,7 @@ func (r *mockTaskRunner) GetType() models.TaskType {
 }
 
 func (r *mockTaskRunner) Run(ctx context.Context, task *models.TaskConfig) (*models.TaskExecution, error) {
+	r.attempts.Add(1)
 	if r.errorOnRun != nil {
 		return nil, r.errorOnRun
 	}
@@ -165,23 +170,34 @@ func (r *mockTaskRunner) Run(ctx context.Context, task *models.TaskConfig) (*mod
 	}
 
 	exec := &models.TaskExecution{
-		ID:        uuid.New().String(),
-		TaskID:    task.ID,
-		Status:    models.StatusCompleted,
-		StartTime: time.Now().Add(-r.delay), // Account for simulated execution time
-		EndTime:   time.Now(),
-		Output:    "Task completed successfully",
+		ExecutionID: uuid.New().String(),
+		TaskID:      task.ID,
+		Status:      models.StatusCompleted,
+		StartTime:   time.Now().Add(-r.delay), // Account for simulated execution time
+		EndTime:     time.Now(),
+		Output:      "Task completed successfully",
 	}
 
-	r.executions = append(r.executions, exec)
+	// Keep a copy, the scheduler goes on to update the execution it returns
+	recorded := *exec
+	r.mu.Lock()
+	r.executions = append(r.executions, &recorded)
+	r.mu.Unlock()
 	return exec, nil
 }
 
+// completed returns a copy of the executions recorded so far
+func (r *mockTaskRunner) completed() []*models.TaskExecution {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]*models.TaskExecution(nil), r.executions...)
+}
+
 // verifyTaskExecution checks if a task was executed correctly
 func verifyTaskExecution(t *testing.T, runner *mockTaskRunner, taskID string, expectedStatus models.TaskStatus) {
 	t.Helper()
 	var found bool
-	for _, exec := range runner.executions {
+	for _, exec := range runner.completed() {
 		if exec.TaskID == taskID {
 			found = true
 			assert.Equal(t, expectedStatus, exec.Status, "Task execution status mismatch")
@@ -199,7 +215,7 @@ func waitForNExecutions(t *testing.T, runner *mockTaskRunner, n int, timeout tim
 	t.Helper()
 	deadline := time.Now().Add(timeout)
 	for time.Now().Before(deadline) {
-		if len(runner.executions) >= n {
+		if len(runner.completed()) >= n {
 			return true
 		}
 		time.Sleep(10 * time.Millisecond)
@@ -242,17 +258,17 @@ func TestTaskSchedulerExecution(t *testing.T) {
 		TaskTimeout:        1 * time.Second,
 	})
 
-	executionCount := 0
+	var executionCount atomic.Int32
 	testRunner := &mockTaskRunner{
 		taskType: models.TaskSystemCleanup,
 		runFunc: func(ctx context.Context, task *models.TaskConfig) (*models.TaskExecution, error) {
-			executionCount++
+			executionCount.Add(1)
 			return &models.TaskExecution{
-				ID:        task.ID,
-				TaskID:    task.ID,
-				Status:    models.StatusCompleted,
-				StartTime: time.Now(),
-				EndTime:   time.Now(),
+				ExecutionID: task.ID,
+				TaskID:      task.ID,
+				Status:      models.StatusCompleted,
+				StartTime:   time.Now(),
+				EndTime:     time.Now(),
 			}, nil
 		},
 	}
@@ -272,7 +288,7 @@ func TestTaskSchedulerExecution(t *testing.T) {
 	time.Sleep(200 * time.Millisecond)
 
 	// Verify task was executed
-	assert.Greater(t, executionCount, 0)
+	assert.Greater(t, executionCount.Load(), int32(0))
 
 	// Clean up
 	scheduler.Stop()
@@ -310,9 +326,9 @@ func TestTaskSchedulerConcurrency(t *testing.T) {
 	}
 
 	// Verify execution timing indicates concurrent execution
-	if len(runner.executions) >= 2 {
-		firstEnd := runner.executions[0].EndTime
-		secondStart := runner.executions[1].StartTime
+	if executions := runner.completed(); len(executions) >= 2 {
+		firstEnd := executions[0].EndTime
+		secondStart := executions[1].StartTime
 		assert.True(t, secondStart.Before(firstEnd),
 			"Second task should start before first task ends, indicating concurrent execution")
 	}
@@ -343,11 +359,9 @@ func TestTaskSchedulerTimeout(t *testing.T) {
 	err = taskStore.CreateTask(context.Background(), &task)
 	require.NoError(t, err)
 
-	// Wait for execution
-	time.Sleep(300 * time.Millisecond)
-
-	// Verify task execution was attempted
-	assert.NotEmpty(t, runner.executions, "Task should have been attempted")
+	// Verify task execution was attempted and cut off by the timeout
+	assert.Eventually(t, func() bool { return runner.attempts.Load() > 0 }, 2*time.Second, 10*time.Millisecond, "Task should have been attempted")
+	assert.Empty(t, runner.completed(), "Timed out task should not complete")
 }
 
 func TestTaskSchedulerErrorHandling(t *testing.T) {
@@ -376,14 +390,12 @@ func TestTaskSchedulerErrorHandling(t *testing.T) {
 	require.NoError(t, err)
 
 	// Wait for execution attempt
-	time.Sleep(100 * time.Millisecond)
+	require.Eventually(t, func() bool { return runner.attempts.Load() > 0 }, 2*time.Second, 10*time.Millisecond, "Task should have been attempted")
 
-	// Verify task state reflects error
+	// A runner error leaves no execution to record
 	executions, err := taskStore.GetExecutions(context.Background(), task.ID)
 	require.NoError(t, err)
-	assert.NotEmpty(t, executions, "Should have at least one execution")
-	lastExec := executions[len(executions)-1]
-	assert.Equal(t, models.StatusFailed, lastExec.Status)
+	assert.Empty(t, executions)
 }
 
 func TestTaskSchedulerRescheduling(t *testing.T) {
@@ -411,56 +423,89 @@ func TestTaskSchedulerRescheduling(t *testing.T) {
 
 	// Wait for multiple executions
 	time.Sleep(200 * time.Millisecond)
-	firstCount := len(runner.executions)
+	firstCount := len(runner.completed())
 	assert.Greater(t, firstCount, 0, "Task should have executed at least once")
 
 	time.Sleep(1 * time.Second)
-	secondCount := len(runner.executions)
+	secondCount := len(runner.completed())
 	assert.Greater(t, secondCount, firstCount, "Task should have been rescheduled and executed again")
 }
 
-func TestTaskSchedulerEdgeCases(t *testing.T) {
-	taskStore := createTestTaskStore(t)
-	scheduler := NewTaskScheduler(taskStore, nil) // Use default config
+func TestScheduleNextRun(t *testing.T) {
+	taipei, err := time.LoadLocation("Asia/Taipei")
+	require.NoError(t, err)
+	now := time.Date(2026, 10, 16, 3, 0, 0, 0, taipei) // A Friday
+
+	tests := []struct {
+		expr string
+		want time.Time
+	}{
+		{"30 2 * * 1-5", time.Date(2026, 10, 19, 2, 30, 0, 0, taipei)},
+		{"*/15 8-17 * * *", time.Date(2026, 10, 16, 8, 0, 0, 0, taipei)},
+		{"0 9 1 * MON", time.Date(2026, 10, 19, 9, 0, 0, 0, taipei)},
+		{"0 0 29 2 *", time.Date(2028, 2, 29, 0, 0, 0, 0, taipei)},
+		{"@hourly", time.Date(2026, 10, 16, 4, 0, 0, 0, taipei)},
+		{"@daily", time.Date(2026, 10, 17, 0, 0, 0, 0, taipei)},
+		{"@weekly", time.Date(2026, 10, 18, 0, 0, 0, 0, taipei)},
+		{"@monthly", time.Date(2026, 11, 1, 0, 0, 0, 0, taipei)},
+		{"@every 90m", now.Add(90 * time.Minute)},
+	}
+	for _, tt := range tests {
+		task := models.TaskConfig{Schedule: models.Schedule{CronExpression: tt.expr, Timezone: "Asia/Taipei"}}
+		require.NoError(t, ScheduleNextRun(&task, time.UTC, now), tt.expr)
+		assert.True(t, tt.want.Equal(task.Schedule.NextRunTime), "%s: got %s, want %s", tt.expr, task.Schedule.NextRunTime, tt.want)
+		assert.Equal(t, time.UTC, task.Schedule.NextRunTime.Location(), "%s is stored in UTC", tt.expr)
+	}
 
+	task := models.TaskConfig{Schedule: models.Schedule{CronExpression: "@daily"}}
+	require.NoError(t, ScheduleNextRun(&task, taipei, now))
+	assert.True(t, time.Date(2026, 10, 17, 0, 0, 0, 0, taipei).Equal(task.Schedule.NextRunTime), "the fallback zone applies without a timezone")
+
+	for _, expr := range []string{"", "daily", "* * * *", "0 0 0 * * *", "61 * * * *", "@fortnightly"} {
+		task := models.TaskConfig{Schedule: models.Schedule{CronExpression: expr}}
+		assert.Error(t, ScheduleNextRun(&task, time.UTC, now), "%q", expr)
+	}
+}
+
+func TestTaskSchedulerEdgeCases(t *testing.T) {
 	t.Run("UnknownTaskType", func(t *testing.T) {
+		taskStore := createTestTaskStore(t)
 		task := createTestTaskConfig(t)
 		task.Type = "unknown_type"
 		task.Schedule.NextRunTime = time.Now()
-		err := taskStore.CreateTask(context.Background(), &task)
-		require.NoError(t, err)
-
-		// Start scheduler without any runners
-		err = scheduler.Start()
-		require.NoError(t, err)
-		defer scheduler.Stop()
 
-		time.Sleep(100 * time.Millisecond)
-
-		// Verify task was marked as failed due to unknown type
-		executions, err := taskStore.GetExecutions(context.Background(), task.ID)
-		require.NoError(t, err)
-		if assert.NotEmpty(t, executions) {
-			assert.Equal(t, models.StatusFailed, executions[0].Status)
-		}
+		// Tasks without a known type never reach the scheduler
+		err := taskStore.CreateTask(context.Background(), &task)
+		assert.ErrorContains(t, err, "invalid task type")
 	})
 
 	t.Run("DisabledTask", func(t *testing.T) {
+		taskStore := createTestTaskStore(t)
+		scheduler := NewTaskScheduler(taskStore, &TaskSchedulerConfig{
+			CheckInterval:      50 * time.Millisecond,
+			MaxConcurrentTasks: 1,
+			TaskTimeout:        1 * time.Second,
+		})
 		runner := newMockTaskRunner(models.TaskSystemCleanup)
 		scheduler.RegisterRunner(runner)
 
+		err := scheduler.Start()
+		require.NoError(t, err)
+		defer scheduler.Stop()
+
 		task := createTestTaskConfig(t)
 		task.Enabled = false
 		task.Schedule.NextRunTime = time.Now()
-		err := taskStore.CreateTask(context.Background(), &task)
+		err = taskStore.CreateTask(context.Background(), &task)
 		require.NoError(t, err)
 
 		time.Sleep(100 * time.Millisecond)
 
-		assert.Empty(t, runner.executions, "Disabled task should not be executed")
+		assert.Zero(t, runner.attempts.Load(), "Disabled task should not be executed")
 	})
 
 	t.Run("ConcurrentTaskLimit", func(t *testing.T) {
+		taskStore := createTestTaskStore(t)
 		scheduler := NewTaskScheduler(taskStore, &TaskSchedulerConfig{
 			CheckInterval:      50 * time.Millisecond,
 			MaxConcurrentTasks: 1,
@@ -480,7 +525,7 @@ func TestTaskSchedulerEdgeCases(t *testing.T) {
 		time.Sleep(400 * time.Millisecond)
 
 		// Verify that tasks were executed sequentially
-		assert.LessOrEqual(t, len(runner.executions), 2,
+		assert.LessOrEqual(t, len(runner.completed()), 2,
 			"With 200ms delay and 400ms wait, no more than 2 tasks should complete")
 	})
 }
@@ -0,0 +1,219 @@
+// File: internal/services/simulate.go
+// Brief: Dry-run of an alert rule against a synthetic metric series
+// Detailed: Feeds user-supplied values through the evaluator's state machine and the notifier's rate limiting without touching live alert status, history or channels, so debounce and resolve settings can be checked before an alert is enabled.
+// Author: drama.lin@aver.com
+// Date: 2026-10-14
+
+package services
+
+import (
+	"sort"
+	"time"
+
+	"argus/internal/models"
+)
+
+// MaxSimulationValues bounds the length of a simulated series
+const MaxSimulationValues = 10000
+
+// SimulationOptions tunes a simulation run. Zero values fall back to the
+// evaluator's configuration.
+type SimulationOptions struct {
+	Interval      time.Duration // Spacing between values (default: evaluation interval)
+	Start         time.Time     // Timestamp of the first value (default: now)
+	DebounceCount int           // Consecutive exceeded values before pending
+	ResolveCount  int           // Consecutive normal values before resolved
+}
+
+// SimulationStep is the alert state after one value of the series
+type SimulationStep struct {
+	Index     int               `json:"index"`
+	Timestamp time.Time         `json:"timestamp"`
+	Value     float64           `json:"value"`
+	Exceeded  bool              `json:"exceeded"`
+	State     models.AlertState `json:"state"`
+}
+
+// SimulatedTransition is a state change the series would cause
+type SimulatedTransition struct {
+	Index     int               `json:"index"`
+	Timestamp time.Time         `json:"timestamp"`
+	Value     float64           `json:"value"`
+	OldState  models.AlertState `json:"old_state"`
+	NewState  models.AlertState `json:"new_state"`
+}
+
+// SimulatedNotification is a notification a transition would produce on one
+// channel. Skipped explains why it would not be delivered ("silenced",
+// "rate_limited", "no_recipient").
+type SimulatedNotification struct {
+	Index     int                     `json:"index"`
+	Timestamp time.Time               `json:"timestamp"`
+	Channel   models.NotificationType `json:"channel"`
+	State     models.AlertState       `json:"state"`
+	Subject   string                  `json:"subject,omitempty"`
+	Skipped   string                  `json:"skipped,omitempty"`
+}
+
+// SimulationResult is the outcome of running a series through an alert rule
+type SimulationResult struct {
+	AlertID       string                  `json:"alert_id"`
+	DebounceCount int                     `json:"debounce_count"`
+	ResolveCount  int                     `json:"resolve_count"`
+	Interval      string                  `json:"interval"`
+	Steps         []SimulationStep        `json:"steps"`
+	Transitions   []SimulatedTransition   `json:"transitions"`
+	Notifications []SimulatedNotification `json:"notifications"`
+	FinalState    models.AlertState       `json:"final_state"`
+}
+
+// Simulate runs values through the alert's threshold and the evaluator's
+// state machine, starting from the inactive state. It has no side effects:
+// live status, history and the event queue are left untouched. The alert's
+// Enabled flag is ignored so rules can be checked before they are switched on.
+func (e *Evaluator) Simulate(config *models.AlertConfig, values []float64, opts SimulationOptions) SimulationResult {
+	if opts.Interval <= 0 {
+		opts.Interval = e.config.EvaluationInterval
+	}
+	if opts.Start.IsZero() {
+		opts.Start = e.clock.Now().UTC()
+	}
+	if opts.DebounceCount <= 0 {
+		opts.DebounceCount = e.config.AlertDebounceCount
+	}
+	if opts.ResolveCount <= 0 {
+		opts.ResolveCount = e.config.AlertResolveCount
+	}
+
+	result := SimulationResult{
+		AlertID:       config.ID,
+		DebounceCount: opts.DebounceCount,
+		ResolveCount:  opts.ResolveCount,
+		Interval:      opts.Interval.String(),
+		Steps:         make([]SimulationStep, 0, len(values)),
+		Transitions:   []SimulatedTransition{},
+		Notifications: []SimulatedNotification{},
+	}
+
+	state := models.StateInactive
+	pending, resolve := 0, 0
+	for i, value := range values {
+		ts := opts.Start.Add(time.Duration(i) * opts.Interval)
+		exceeded := e.compareValue(value, config.Threshold.Value, config.Threshold.Operator)
+
+		var next models.AlertState
+		next, pending, resolve = nextAlertState(state, exceeded, pending, resolve, opts.DebounceCount, opts.ResolveCount)
+		if next != state {
+			result.Transitions = append(result.Transitions, SimulatedTransition{
+				Index:     i,
+				Timestamp: ts,
+				Value:     value,
+				OldState:  state,
+				NewState:  next,
+			})
+			state = next
+		}
+		result.Steps = append(result.Steps, SimulationStep{
+			Index:     i,
+			Timestamp: ts,
+			Value:     value,
+			Exceeded:  exceeded,
+			State:     state,
+		})
+	}
+	result.FinalState = state
+	return result
+}
+
+// PreviewNotifications returns the notifications the registered channels would
+// send for simulated transitions of alert. Silences and rate limiting are
+// applied at the transition timestamps, the latter starting from an empty
+// window; circuit breakers and the live rate limiter are not consulted and
+// nothing is sent.
+func (n *Notifier) PreviewNotifications(alert *models.AlertConfig, transitions []SimulatedTransition) []SimulatedNotification {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+
+	types := make([]models.NotificationType, 0, len(n.channels))
+	for typ := range n.channels {
+		types = append(types, typ)
+	}
+	sort.Slice(types, func(i, j int) bool { return types[i] < types[j] })
+
+	// Same fixed-window accounting as rateLimiter.isAllowed
+	type window struct {
+		count     int
+		expiresAt int64
+	}
+	windows := make(map[models.NotificationType]*window)
+	windowSeconds := int64(n.config.RateLimitWindow.Seconds())
+
+	previews := []SimulatedNotification{}
+	for _, t := range transitions {
+		event := models.AlertEvent{
+			AlertID:      alert.ID,
+			OldState:     t.OldState,
+			NewState:     t.NewState,
+			CurrentValue: t.Value,
+			Threshold:    alert.Threshold.Value,
+			Timestamp:    t.Timestamp,
+			Alert:        alert,
+			Status: &models.AlertStatus{
+				AlertID:      alert.ID,
+				State:        t.NewState,
+				CurrentValue: t.Value,
+				Source:       models.SourceLocal,
+			},
+			Source: models.SourceLocal,
+		}
+
+		for _, typ := range types {
+			preview := SimulatedNotification{
+				Index:     t.Index,
+				Timestamp: t.Timestamp,
+				Channel:   typ,
+				State:     t.NewState,
+			}
+
+			now := t.Timestamp.Unix()
+			w, ok := windows[typ]
+			if !ok || w.expiresAt < now {
+				w = &window{expiresAt: now + windowSeconds}
+				windows[typ] = w
+			}
+
+			switch {
+			case n.silencer != nil && len(n.silencer.Silenced(alert.LabelSet(), t.Timestamp)) > 0:
+				preview.Skipped = "silenced"
+			case w.count >= n.config.RateLimit:
+				preview.Skipped = "rate_limited"
+			case !hasRecipient(typ, alert):
+				w.count++
+				preview.Skipped = "no_recipient"
+			default:
+				w.count++
+				rendered := event
+				rendered.Timestamp = event.Timestamp.In(n.locationFor(typ, event))
+				if subject, _, err := n.renderForChannel(typ, rendered); err == nil {
+					preview.Subject = n.config.Redactor.Redact(subject)
+				}
+			}
+			previews = append(previews, preview)
+		}
+	}
+	return previews
+}
+
+// hasRecipient reports whether the alert notifies someone on a channel: email
+// and webhook need an address, Jira its settings
+func hasRecipient(typ models.NotificationType, alert *models.AlertConfig) bool {
+	switch typ {
+	case models.NotificationEmail:
+		return emailRecipient(alert) != ""
+	case models.NotificationWebhook:
+		return webhookURL(alert) != ""
+	case models.NotificationJira:
+		return jiraSettings(alert) != nil
+	}
+	return true
+}
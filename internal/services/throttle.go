@@ -0,0 +1,169 @@
+// File: internal/services/throttle.go
+// Brief: Resource-aware throttling of scheduled tasks
+// Detailed: Defers the due runs of non-critical tasks while the host CPU usage or IO wait read from the metrics collector is above the configured thresholds, so maintenance work does not pile onto an already stressed machine. A run deferred for longer than the maximum goes ahead anyway. The thresholds, the current load and the deferred tasks are reported for the API.
+// Author: drama.lin@aver.com
+// Date: 2026-10-14
+
+package services
+
+import (
+	"fmt"
+	"log/slog"
+	"sort"
+	"time"
+
+	"argus/internal/metrics"
+	"argus/internal/models"
+)
+
+// DefaultThrottleMaxDefer is how long a run is deferred at most by default
+const DefaultThrottleMaxDefer = time.Hour
+
+// ThrottleConfig sets when non-critical tasks are deferred; a zero threshold
+// disables its check
+type ThrottleConfig struct {
+	CPUPercent    float64       // Defer while CPU usage is above
+	IOWaitPercent float64       // Defer while the share of CPU time waiting on IO is above
+	MaxDefer      time.Duration // Runs deferred this long go ahead anyway (0 defers until the load drops)
+}
+
+// Enabled reports whether any threshold is set
+func (c ThrottleConfig) Enabled() bool {
+	return c.CPUPercent > 0 || c.IOWaitPercent > 0
+}
+
+// HostLoad is the load compared against the throttle thresholds
+type HostLoad struct {
+	CPUPercent    float64   `json:"cpu_percent"`
+	IOWaitPercent float64   `json:"iowait_percent"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+// ThrottledTask is a due run the throttle is holding back
+type ThrottledTask struct {
+	TaskID   string    `json:"task_id"`
+	TaskName string    `json:"task_name"`
+	DueAt    time.Time `json:"due_at"`
+	Since    time.Time `json:"since"` // When the throttle first held it back
+	Reason   string    `json:"reason"`
+}
+
+// ThrottleStatus reports the throttle thresholds, the current host load and
+// the runs held back
+type ThrottleStatus struct {
+	Enabled       bool            `json:"enabled"`
+	CPUPercent    float64         `json:"cpu_percent,omitempty"`
+	IOWaitPercent float64         `json:"iowait_percent,omitempty"`
+	MaxDefer      string          `json:"max_defer,omitempty"`
+	Load          *HostLoad       `json:"load,omitempty"` // Nil without fresh CPU metrics
+	Throttling    bool            `json:"throttling"`     // Whether non-critical runs are deferred now
+	Reason        string          `json:"reason,omitempty"`
+	Deferred      []ThrottledTask `json:"deferred"`
+	Deferrals     uint64          `json:"deferrals"` // Runs deferred since startup
+}
+
+// SetMetricsCollector sets the collector whose CPU metrics drive the throttle
+func (s *TaskScheduler) SetMetricsCollector(collector *metrics.Collector) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.collector = collector
+}
+
+// hostLoad returns the current load, or nil without a collector or fresh CPU metrics
+func (s *TaskScheduler) hostLoad() *HostLoad {
+	s.mutex.RLock()
+	collector := s.collector
+	s.mutex.RUnlock()
+	if collector == nil {
+		return nil
+	}
+	cpu := collector.GetCPUMetrics()
+	if cpu == nil {
+		return nil
+	}
+	return &HostLoad{CPUPercent: cpu.UsagePercent, IOWaitPercent: cpu.IOWaitPercent, UpdatedAt: cpu.UpdatedAt}
+}
+
+// throttleReason returns why non-critical runs are deferred under load, or ""
+// when they may run. It names the thresholds rather than the load, so the
+// reason stored with a task only changes when another threshold is crossed.
+func (c ThrottleConfig) throttleReason(load *HostLoad) string {
+	if load == nil {
+		return ""
+	}
+	switch {
+	case c.CPUPercent > 0 && load.CPUPercent > c.CPUPercent:
+		return fmt.Sprintf("host CPU usage above %g%%", c.CPUPercent)
+	case c.IOWaitPercent > 0 && load.IOWaitPercent > c.IOWaitPercent:
+		return fmt.Sprintf("host IO wait above %g%%", c.IOWaitPercent)
+	}
+	return ""
+}
+
+// throttle holds back a due run of a non-critical task for reason, unless it
+// has been held back for MaxDefer already. It reports whether the run was
+// held back.
+func (s *TaskScheduler) throttle(task *models.TaskConfig, now time.Time, reason string, throttled map[string]ThrottledTask) bool {
+	s.mutex.RLock()
+	held, ok := s.throttled[task.ID]
+	s.mutex.RUnlock()
+	first := !ok || !held.DueAt.Equal(task.Schedule.NextRunTime)
+	if first {
+		held = ThrottledTask{TaskID: task.ID, TaskName: task.Name, DueAt: task.Schedule.NextRunTime, Since: now}
+	}
+	if maxDefer := s.config.Throttle.MaxDefer; maxDefer > 0 && now.Sub(held.Since) >= maxDefer {
+		slog.Warn("Running throttled task after the maximum deferral",
+			"task_id", task.ID, "task_name", task.Name, "reason", reason, "max_defer", maxDefer.String())
+		return false
+	}
+	if first {
+		s.deferrals.Add(1)
+		slog.Info("Deferring task while the host is busy", "task_id", task.ID, "task_name", task.Name, "reason", reason)
+	}
+	held.Reason = reason
+	throttled[task.ID] = held
+
+	if task.Schedule.DeferredReason != reason {
+		if task.Schedule.DeferredFrom == nil {
+			due := task.Schedule.NextRunTime
+			task.Schedule.DeferredFrom = &due
+		}
+		task.Schedule.DeferredReason = reason
+		if err := s.repository.UpdateTask(s.ctx, task); err != nil {
+			slog.Error("Failed to record task deferral", "task_id", task.ID, "error", err)
+		}
+	}
+	return true
+}
+
+// ThrottleStatus returns the throttle thresholds, the current host load and
+// the runs held back at the last schedule check
+func (s *TaskScheduler) ThrottleStatus() ThrottleStatus {
+	config := s.config.Throttle
+	load := s.hostLoad()
+	status := ThrottleStatus{
+		Enabled:       config.Enabled(),
+		CPUPercent:    config.CPUPercent,
+		IOWaitPercent: config.IOWaitPercent,
+		Load:          load,
+		Deferred:      []ThrottledTask{},
+		Deferrals:     s.deferrals.Load(),
+	}
+	if config.MaxDefer > 0 {
+		status.MaxDefer = config.MaxDefer.String()
+	}
+	if status.Enabled {
+		status.Reason = config.throttleReason(load)
+		status.Throttling = status.Reason != ""
+	}
+
+	s.mutex.RLock()
+	for _, held := range s.throttled {
+		status.Deferred = append(status.Deferred, held)
+	}
+	s.mutex.RUnlock()
+	sort.Slice(status.Deferred, func(i, j int) bool {
+		return status.Deferred[i].DueAt.Before(status.Deferred[j].DueAt)
+	})
+	return status
+}
@@ -0,0 +1,406 @@
+// File: internal/services/webhook.go
+// Brief: Webhook notification channel backed by an on-disk queue
+// Detailed: Posts alert notifications as JSON to the URL configured on each alert, either the default payload or the payload mapping of the alert's webhook settings rendered from it, so a receiver such as Jira or ServiceNow gets the exact body it expects. Deliveries wait in a bounded on-disk queue until the target accepts them, so notifications survive target outages and Argus restarts. A failing URL is retried in order while deliveries to other URLs keep flowing; deliveries dropped from a full queue or expired after the retention end up in the dead-letter queue.
+// Author: drama.lin@aver.com
+// Date: 2026-10-14
+
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"argus/internal/clock"
+	"argus/internal/diskqueue"
+	"argus/internal/faults"
+	"argus/internal/models"
+	"argus/internal/tmplfunc"
+	"argus/internal/utils"
+)
+
+// Webhook channel defaults
+const (
+	DefaultWebhookQueueSize     = 1000
+	DefaultWebhookRetention     = 24 * time.Hour
+	DefaultWebhookRetryInterval = 30 * time.Second
+	DefaultWebhookTimeout       = 10 * time.Second
+)
+
+// WebhookConfig configures the webhook channel
+type WebhookConfig struct {
+	QueueDir      string            // Directory of the on-disk delivery queue
+	QueueSize     int               // Pending deliveries kept; the oldest are dropped beyond it
+	Retention     time.Duration     // Pending deliveries older than this are dropped
+	RetryInterval time.Duration     // Wait before retrying a URL after a failed delivery
+	Timeout       time.Duration     // Per request
+	Transport     http.RoundTripper // nil uses http.DefaultTransport
+	Clock         clock.Clock       // nil uses the real clock
+	Limits        tmplfunc.Limits   // Execution limits of payload mappings
+}
+
+// WebhookPayload is the JSON body posted to webhook URLs
+type WebhookPayload struct {
+	AlertID       string                `json:"alert_id"`
+	AlertName     string                `json:"alert_name,omitempty"`
+	Severity      models.AlertSeverity  `json:"severity,omitempty"`
+	State         models.AlertState     `json:"state"`
+	PreviousState models.AlertState     `json:"previous_state"`
+	Value         float64               `json:"value"`
+	Threshold     float64               `json:"threshold"`
+	Message       string                `json:"message,omitempty"`
+	Subject       string                `json:"subject"`
+	Body          string                `json:"body"`
+	Labels        map[string]string     `json:"labels,omitempty"`
+	Source        string                `json:"source,omitempty"`  // Where the alert originated, see models.AlertEvent.Source
+	Samples       []models.MetricSample `json:"samples,omitempty"` // Recent values of the metric up to the trigger
+	Timestamp     time.Time             `json:"timestamp"`
+}
+
+// webhookDelivery is a notification waiting in the queue
+type webhookDelivery struct {
+	URL     string            `json:"url"`
+	Event   models.AlertEvent `json:"event"`
+	Subject string            `json:"subject"`
+	Body    string            `json:"body"`
+	Payload json.RawMessage   `json:"payload,omitempty"` // Rendered payload mapping, replacing the default payload
+}
+
+func (d webhookDelivery) payload() WebhookPayload {
+	p := WebhookPayload{
+		AlertID:       d.Event.AlertID,
+		State:         d.Event.NewState,
+		PreviousState: d.Event.OldState,
+		Value:         d.Event.CurrentValue,
+		Threshold:     d.Event.Threshold,
+		Message:       d.Event.Message,
+		Subject:       d.Subject,
+		Body:          d.Body,
+		Source:        d.Event.Source,
+		Timestamp:     d.Event.Timestamp,
+	}
+	if alert := d.Event.Alert; alert != nil {
+		p.AlertName, p.Severity, p.Labels = alert.Name, alert.Severity, alert.LabelSet()
+	}
+	if d.Event.Context != nil {
+		p.Samples = d.Event.Context.Samples
+	}
+	return p
+}
+
+// errWebhookRejected marks responses that retrying will not change, e.g. 404
+var errWebhookRejected = errors.New("webhook rejected the notification")
+
+// WebhookChannel delivers notifications to webhook URLs through an on-disk queue
+type WebhookChannel struct {
+	config   WebhookConfig
+	queue    *diskqueue.Queue
+	client   *http.Client
+	clock    clock.Clock
+	errorLog *utils.LogSampler // Throttles retry warnings while a URL stays down
+	mu       sync.Mutex        // Guards onResult, set while resumed deliveries may be running
+	onResult func(event models.AlertEvent, subject, body string, err error)
+	ctx      context.Context
+	cancel   context.CancelFunc
+	done     chan struct{}
+}
+
+// NewWebhookChannel opens the delivery queue and starts delivering what is
+// left in it from before a restart
+func NewWebhookChannel(config WebhookConfig) (*WebhookChannel, error) {
+	if config.QueueSize <= 0 {
+		config.QueueSize = DefaultWebhookQueueSize
+	}
+	if config.Retention <= 0 {
+		config.Retention = DefaultWebhookRetention
+	}
+	if config.RetryInterval <= 0 {
+		config.RetryInterval = DefaultWebhookRetryInterval
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = DefaultWebhookTimeout
+	}
+	queue, err := diskqueue.Open(diskqueue.Config{
+		Dir:       config.QueueDir,
+		MaxItems:  config.QueueSize,
+		Retention: config.Retention,
+		Clock:     config.Clock,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open webhook queue: %w", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	c := &WebhookChannel{
+		config:   config,
+		queue:    queue,
+		client:   &http.Client{Timeout: config.Timeout, Transport: config.Transport},
+		clock:    clock.OrReal(config.Clock),
+		errorLog: utils.NewLogSampler(utils.DefaultLogRepeatInterval, config.Clock),
+		ctx:      ctx,
+		cancel:   cancel,
+		done:     make(chan struct{}),
+	}
+	if pending := queue.Stats().Length; pending > 0 {
+		slog.Info("Resuming queued webhook deliveries", "pending", pending)
+	}
+	go c.run()
+	return c, nil
+}
+
+// SetResultHandler registers a callback that receives the final outcome of
+// every queued delivery; failed attempts that will be retried are not reported
+func (c *WebhookChannel) SetResultHandler(handler func(event models.AlertEvent, subject, body string, err error)) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.onResult = handler
+}
+
+func (c *WebhookChannel) report(d webhookDelivery, err error) {
+	c.mu.Lock()
+	onResult := c.onResult
+	c.mu.Unlock()
+	if onResult != nil {
+		onResult(d.Event, d.Subject, d.Body, err)
+	}
+}
+
+// webhookSettings returns the settings of the alert's first enabled webhook
+// notification with a URL, or nil if it has none
+func webhookSettings(alert *models.AlertConfig) map[string]interface{} {
+	if alert == nil {
+		return nil
+	}
+	for _, notif := range alert.Notifications {
+		if notif.Type == models.NotificationWebhook && notif.Enabled && notif.Settings != nil {
+			if u, ok := notif.Settings["url"].(string); ok && u != "" {
+				return notif.Settings
+			}
+		}
+	}
+	return nil
+}
+
+// webhookURL returns the URL of the alert's first enabled webhook
+// notification, or "" if it has none
+func webhookURL(alert *models.AlertConfig) string {
+	u, _ := webhookSettings(alert)["url"].(string)
+	return u
+}
+
+// RenderWebhookPayload renders a payload mapping, whose strings are templates
+// over the fields of the default payload, into the JSON body to post
+func RenderWebhookPayload(mapping any, payload WebhookPayload, limits tmplfunc.Limits) (json.RawMessage, error) {
+	tmpl, err := tmplfunc.ParseJSON(models.WebhookPayloadSetting, mapping, limits)
+	if err != nil {
+		return nil, err
+	}
+	rendered, err := tmpl.Execute(payload)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(rendered)
+}
+
+// renderPayload renders the payload mapping of the webhook settings for a
+// delivery, nil without one, so the default payload is posted
+func (c *WebhookChannel) renderPayload(settings map[string]interface{}, d webhookDelivery) (json.RawMessage, error) {
+	mapping, ok := settings[models.WebhookPayloadSetting]
+	if !ok {
+		return nil, nil
+	}
+	payload, err := RenderWebhookPayload(mapping, d.payload(), c.config.Limits)
+	if err != nil {
+		return nil, fmt.Errorf("failed to render webhook payload: %w", err)
+	}
+	return payload, nil
+}
+
+// webhookHost returns the host of a webhook URL for logging, since the path
+// and query often carry a token
+func webhookHost(target string) string {
+	if u, err := url.Parse(target); err == nil && u.Host != "" {
+		return u.Host
+	}
+	return "invalid URL"
+}
+
+// Send queues the notification for the alert's webhook URL; alerts without
+// one are skipped
+func (c *WebhookChannel) Send(event models.AlertEvent, subject, body string) error {
+	settings := webhookSettings(event.Alert)
+	if settings == nil {
+		return nil
+	}
+	d := webhookDelivery{URL: settings["url"].(string), Event: event, Subject: subject, Body: body}
+	payload, err := c.renderPayload(settings, d)
+	if err != nil {
+		return err
+	}
+	d.Payload = payload
+	_, dropped, err := c.queue.Push(d)
+	if err != nil {
+		return fmt.Errorf("failed to queue webhook: %w", err)
+	}
+	for _, item := range dropped {
+		var d webhookDelivery
+		if item.Decode(&d) == nil {
+			slog.Warn("Webhook queue full, dropped oldest delivery", "host", webhookHost(d.URL), "alert_id", d.Event.AlertID, "queued_at", item.Enqueued)
+			c.report(d, fmt.Errorf("webhook %w, dropped after %d attempts (last error: %s)", ErrQueueFull, item.Attempts, item.LastError))
+		}
+	}
+	return nil
+}
+
+// run delivers queued notifications until the channel is stopped
+func (c *WebhookChannel) run() {
+	defer close(c.done)
+	retryAt := make(map[string]time.Time) // URL to when it may be tried again after a failure
+	for {
+		for _, item := range c.queue.Expire() {
+			var d webhookDelivery
+			if item.Decode(&d) == nil {
+				slog.Warn("Webhook delivery expired", "host", webhookHost(d.URL), "alert_id", d.Event.AlertID, "attempts", item.Attempts)
+				c.report(d, fmt.Errorf("webhook delivery expired after %s in the queue, %d attempts (last error: %s)", c.config.Retention, item.Attempts, item.LastError))
+			}
+		}
+		wait := c.deliverPending(retryAt)
+		select {
+		case <-c.ctx.Done():
+			return
+		case <-c.queue.Ready():
+		case <-c.clock.After(wait):
+		}
+	}
+}
+
+// deliverPending attempts the queued deliveries, oldest first, skipping URLs
+// waiting to be retried. It returns how long to wait before the next retry.
+func (c *WebhookChannel) deliverPending(retryAt map[string]time.Time) time.Duration {
+	wait := c.config.RetryInterval
+	blocked := make(map[string]bool) // URLs not tried again this round, keeping their deliveries in order
+	for _, item := range c.queue.Items() {
+		if c.ctx.Err() != nil {
+			return wait
+		}
+		var d webhookDelivery
+		if err := item.Decode(&d); err != nil {
+			slog.Error("Discarding unreadable webhook delivery", "id", item.ID, "error", err)
+			c.queue.Remove(item.ID)
+			continue
+		}
+		if blocked[d.URL] {
+			continue
+		}
+		now := c.clock.Now()
+		if at, ok := retryAt[d.URL]; ok && now.Before(at) {
+			blocked[d.URL] = true
+			wait = min(wait, at.Sub(now))
+			continue
+		}
+
+		err := c.post(d)
+		switch {
+		case err == nil:
+			delete(retryAt, d.URL)
+			c.errorLog.Reset(d.URL)
+			c.queue.Remove(item.ID)
+			slog.Info("Webhook delivered", "host", webhookHost(d.URL), "alert_id", d.Event.AlertID, "attempts", item.Attempts+1)
+			c.report(d, nil)
+		case errors.Is(err, errWebhookRejected):
+			c.queue.Remove(item.ID)
+			slog.Error("Webhook rejected notification", "host", webhookHost(d.URL), "alert_id", d.Event.AlertID, "error", err)
+			c.report(d, err)
+		default:
+			item.Attempts++
+			item.LastError = err.Error()
+			if updateErr := c.queue.Update(item); updateErr != nil && !errors.Is(updateErr, diskqueue.ErrNotFound) {
+				slog.Error("Failed to save webhook delivery attempt", "id", item.ID, "error", updateErr)
+			}
+			retryAt[d.URL] = now.Add(c.config.RetryInterval)
+			blocked[d.URL] = true
+			c.errorLog.Log(slog.LevelWarn, d.URL, "Webhook delivery failed, will retry",
+				"host", webhookHost(d.URL), "alert_id", d.Event.AlertID, "attempts", item.Attempts,
+				"pending", c.queue.Stats().Length, "retry_in", c.config.RetryInterval.String(), "error", err)
+		}
+	}
+	return wait
+}
+
+// post sends one delivery; errors wrapping errWebhookRejected are not retried
+func (c *WebhookChannel) post(d webhookDelivery) error {
+	if err := faults.Inject(faults.WebhookSend); err != nil {
+		return err
+	}
+	data := []byte(d.Payload)
+	if len(data) == 0 {
+		var err error
+		if data, err = json.Marshal(d.payload()); err != nil {
+			return fmt.Errorf("%w: %v", errWebhookRejected, err)
+		}
+	}
+	ctx, cancel := context.WithTimeout(c.ctx, c.config.Timeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, d.URL, bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("%w: %v", errWebhookRejected, err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "Argus")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		// The URL error names the full URL, token included
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+		return fmt.Errorf("webhook request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10)) // Lets the connection be reused
+
+	switch code := resp.StatusCode; {
+	case code >= 200 && code < 300:
+		return nil
+	case code >= 400 && code < 500 && code != http.StatusRequestTimeout && code != http.StatusTooManyRequests:
+		return fmt.Errorf("%w: HTTP %d", errWebhookRejected, code)
+	default:
+		return fmt.Errorf("webhook returned HTTP %d", code)
+	}
+}
+
+// QueueStats reports the fill level of the delivery queue; deliveries lost
+// to expiry count as dropped
+func (c *WebhookChannel) QueueStats() QueueStats {
+	stats := c.queue.Stats()
+	return QueueStats{
+		Name:     "webhook",
+		Policy:   OverflowDropOldest,
+		Capacity: stats.Capacity,
+		Length:   stats.Length,
+		Dropped:  stats.Dropped + stats.Expired,
+	}
+}
+
+func (c *WebhookChannel) Type() models.NotificationType {
+	return models.NotificationWebhook
+}
+
+func (c *WebhookChannel) Name() string {
+	return "Webhook Notifications"
+}
+
+// Stop ends delivery; queued notifications stay on disk for the next start
+func (c *WebhookChannel) Stop() {
+	c.cancel()
+	<-c.done
+}
@@ -0,0 +1,80 @@
+package services
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"argus/internal/clock"
+	"argus/internal/models"
+)
+
+func TestWebhookChannel_ReplaysQueueAfterRestart(t *testing.T) {
+	var up atomic.Bool
+	var requests atomic.Int32
+	received := make(chan WebhookPayload, 1)
+	release := make(chan struct{}) // Holds the replayed delivery until its result handler is set
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		if !up.Load() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		<-release
+		var payload WebhookPayload
+		if err := json.NewDecoder(r.Body).Decode(&payload); err == nil {
+			received <- payload
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	event := createTestAlertEvent(t)
+	event.Alert.Notifications = []models.NotificationConfig{{
+		Type:     models.NotificationWebhook,
+		Enabled:  true,
+		Settings: map[string]any{"url": srv.URL + "/hook"},
+	}}
+	config := WebhookConfig{QueueDir: t.TempDir(), Clock: clock.NewFake(time.Now())}
+
+	// The target is down: the delivery fails and waits on disk for its retry
+	channel, err := NewWebhookChannel(config)
+	require.NoError(t, err)
+	require.NoError(t, channel.Send(event, "Test Subject", "Test Body"))
+	require.Eventually(t, func() bool {
+		items := channel.queue.Items()
+		return len(items) == 1 && items[0].Attempts == 1
+	}, 5*time.Second, time.Millisecond)
+	channel.Stop()
+	assert.Equal(t, int32(1), requests.Load())
+
+	// After a restart the queued delivery is sent without waiting for the retry interval
+	up.Store(true)
+	results := make(chan error, 1)
+	channel, err = NewWebhookChannel(config)
+	require.NoError(t, err)
+	channel.SetResultHandler(func(_ models.AlertEvent, _, _ string, err error) { results <- err })
+	close(release)
+	t.Cleanup(channel.Stop)
+
+	select {
+	case payload := <-received:
+		assert.Equal(t, event.AlertID, payload.AlertID)
+		assert.Equal(t, models.StateActive, payload.State)
+		assert.Equal(t, "Test Subject", payload.Subject)
+	case <-time.After(5 * time.Second):
+		t.Fatal("queued webhook was not replayed")
+	}
+	select {
+	case err := <-results:
+		assert.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("replayed webhook was not reported")
+	}
+	assert.Zero(t, channel.QueueStats().Length)
+}
@@ -0,0 +1,329 @@
+// File: internal/statuspage/statuspage.go
+// Brief: Public status page computation and rendering
+// Detailed: Builds a read-only status page from alert groups published as components: each component's current state from the firing alerts of its group (critical alerts are an outage, others a degradation), and a daily uptime history from the alerts' state change history. Renders the page as JSON or a minimal self-contained HTML page, exposing neither alert names nor values.
+// Author: drama.lin@aver.com
+// Date: 2026-10-14
+
+// Package statuspage builds public status pages from alert groups.
+package statuspage
+
+import (
+	"bytes"
+	"cmp"
+	"html/template"
+	"slices"
+	"time"
+
+	"argus/internal/models"
+)
+
+// DefaultHistoryDays is the number of days of uptime history shown
+const DefaultHistoryDays = 90
+
+// DefaultTitle is the heading of a page without a configured title
+const DefaultTitle = "System Status"
+
+// Component states, from best to worst
+const (
+	StateOperational = "operational"
+	StateDegraded    = "degraded"     // A non-critical alert of the component is firing
+	StateOutage      = "major_outage" // A critical alert of the component is firing
+)
+
+// stateRank orders the states to find the worst
+var stateRank = map[string]int{StateOperational: 0, StateDegraded: 1, StateOutage: 2}
+
+// Component is an alert group published on the status page
+type Component struct {
+	Group       string `yaml:"group" json:"group"`             // Alert group ID
+	Name        string `yaml:"name" json:"name"`               // Shown name, the group's name when empty
+	Description string `yaml:"description" json:"description"` // Optional text below the name
+}
+
+// Options configure a status page
+type Options struct {
+	Title       string
+	Components  []Component
+	HistoryDays int            // DefaultHistoryDays when zero
+	Location    *time.Location // Time zone of the days, time.Local when nil
+}
+
+// Day is the uptime of a component on one calendar day
+type Day struct {
+	Date            string  `json:"date"`   // YYYY-MM-DD
+	Status          string  `json:"status"` // Worst state reached during the day
+	UptimePercent   float64 `json:"uptime_percent"`
+	DowntimeSeconds float64 `json:"downtime_seconds"` // Time in major outage
+}
+
+// ComponentStatus is the state and history of one component
+type ComponentStatus struct {
+	Name          string  `json:"name"`
+	Description   string  `json:"description,omitempty"`
+	Status        string  `json:"status"`
+	UptimePercent float64 `json:"uptime_percent"` // Over the whole history
+	History       []Day   `json:"history"`        // Oldest first, today last
+}
+
+// Page is a status page
+type Page struct {
+	Title      string            `json:"title"`
+	Status     string            `json:"status"` // Worst state of the components
+	Components []ComponentStatus `json:"components"`
+	UpdatedAt  time.Time         `json:"updated_at"`
+}
+
+// Source provides the alerts behind the components
+type Source struct {
+	Groups   map[string]*models.AlertGroup
+	Alerts   []*models.AlertConfig
+	Statuses map[string]*models.AlertStatus
+	// History returns an alert's state changes, in any order
+	History func(alertID string) ([]models.AlertHistoryEntry, error)
+}
+
+// period is a time range [start, end)
+type period struct {
+	start, end time.Time
+}
+
+// Build computes the status page at now. An alert whose history cannot be
+// read only contributes its current state.
+func Build(opts Options, src Source, now time.Time) *Page {
+	days := opts.HistoryDays
+	if days <= 0 {
+		days = DefaultHistoryDays
+	}
+	loc := opts.Location
+	if loc == nil {
+		loc = time.Local
+	}
+	page := &Page{Title: opts.Title, Status: StateOperational, Components: make([]ComponentStatus, 0, len(opts.Components)), UpdatedAt: now}
+	if page.Title == "" {
+		page.Title = DefaultTitle
+	}
+
+	local := now.In(loc)
+	from := time.Date(local.Year(), local.Month(), local.Day()-days+1, 0, 0, 0, 0, loc)
+	for _, component := range opts.Components {
+		status := ComponentStatus{Name: component.Name, Description: component.Description, Status: StateOperational}
+		if group, ok := src.Groups[component.Group]; ok && status.Name == "" {
+			status.Name = group.Name
+		}
+		if status.Name == "" {
+			status.Name = component.Group
+		}
+
+		var outages, degradations []period
+		for _, alert := range src.Alerts {
+			if !alert.Enabled || alert.GroupID != component.Group {
+				continue
+			}
+			state := StateDegraded
+			if alert.Severity == models.SeverityCritical {
+				state = StateOutage
+			}
+			current := src.Statuses[alert.ID]
+			if current != nil && current.State.Firing() && stateRank[state] > stateRank[status.Status] {
+				status.Status = state
+			}
+
+			var history []models.AlertHistoryEntry
+			if src.History != nil {
+				history, _ = src.History(alert.ID)
+			}
+			periods := firingPeriods(history, current, from, now)
+			if state == StateOutage {
+				outages = append(outages, periods...)
+			} else {
+				degradations = append(degradations, periods...)
+			}
+		}
+		status.History, status.UptimePercent = dailyUptime(merge(outages), merge(degradations), from, now, days)
+
+		if stateRank[status.Status] > stateRank[page.Status] {
+			page.Status = status.Status
+		}
+		page.Components = append(page.Components, status)
+	}
+	return page
+}
+
+// firingPeriods returns the periods an alert was firing (pending or active)
+// within [from, to], from its state changes and current status. A period
+// whose start was purged from the history starts at from.
+func firingPeriods(history []models.AlertHistoryEntry, current *models.AlertStatus, from, to time.Time) []period {
+	entries := slices.Clone(history)
+	slices.SortStableFunc(entries, func(a, b models.AlertHistoryEntry) int { return a.Timestamp.Compare(b.Timestamp) })
+
+	var periods []period
+	var start time.Time
+	firing := false
+	for _, entry := range entries {
+		switch {
+		case entry.NewState.Firing() && !firing:
+			start, firing = entry.Timestamp, true
+		case !entry.NewState.Firing() && firing:
+			periods = append(periods, period{start, entry.Timestamp})
+			firing = false
+		case !entry.NewState.Firing() && entry.OldState.Firing():
+			periods = append(periods, period{from, entry.Timestamp})
+		}
+	}
+	if !firing && current != nil && current.State.Firing() {
+		start, firing = from, true
+		if current.TriggeredAt != nil {
+			start = *current.TriggeredAt
+		}
+	}
+	if firing {
+		periods = append(periods, period{start, to})
+	}
+
+	clipped := periods[:0]
+	for _, p := range periods {
+		p.start, p.end = maxTime(p.start, from), minTime(p.end, to)
+		if p.end.After(p.start) {
+			clipped = append(clipped, p)
+		}
+	}
+	return clipped
+}
+
+// merge returns the union of periods as sorted, disjoint periods
+func merge(periods []period) []period {
+	slices.SortFunc(periods, func(a, b period) int { return cmp.Compare(a.start.UnixNano(), b.start.UnixNano()) })
+	var merged []period
+	for _, p := range periods {
+		if n := len(merged); n > 0 && !p.start.After(merged[n-1].end) {
+			merged[n-1].end = maxTime(merged[n-1].end, p.end)
+			continue
+		}
+		merged = append(merged, p)
+	}
+	return merged
+}
+
+// overlap returns the time periods cover within [start, end)
+func overlap(periods []period, start, end time.Time) time.Duration {
+	var total time.Duration
+	for _, p := range periods {
+		if s, e := maxTime(p.start, start), minTime(p.end, end); e.After(s) {
+			total += e.Sub(s)
+		}
+	}
+	return total
+}
+
+// dailyUptime splits [from, now] into calendar days and returns each day's
+// uptime with the uptime over the whole range. Only outages count as
+// downtime; degradations mark their days degraded.
+func dailyUptime(outages, degradations []period, from, now time.Time, days int) ([]Day, float64) {
+	history := make([]Day, 0, days)
+	var total, down time.Duration
+	for i := 0; i < days; i++ {
+		start := time.Date(from.Year(), from.Month(), from.Day()+i, 0, 0, 0, 0, from.Location())
+		end := minTime(time.Date(from.Year(), from.Month(), from.Day()+i+1, 0, 0, 0, 0, from.Location()), now)
+		length := end.Sub(start)
+		outage := overlap(outages, start, end)
+
+		day := Day{Date: start.Format(time.DateOnly), Status: StateOperational, UptimePercent: 100, DowntimeSeconds: outage.Seconds()}
+		switch {
+		case outage > 0:
+			day.Status = StateOutage
+		case overlap(degradations, start, end) > 0:
+			day.Status = StateDegraded
+		}
+		if length > 0 {
+			day.UptimePercent = percent(length-outage, length)
+		}
+		history = append(history, day)
+		total += length
+		down += outage
+	}
+	if total <= 0 {
+		return history, 100
+	}
+	return history, percent(total-down, total)
+}
+
+// percent returns part of whole as a percentage rounded to 3 decimals
+func percent(part, whole time.Duration) float64 {
+	p := float64(part) / float64(whole) * 100
+	return float64(int64(p*1000+0.5)) / 1000
+}
+
+func minTime(a, b time.Time) time.Time {
+	if a.Before(b) {
+		return a
+	}
+	return b
+}
+
+func maxTime(a, b time.Time) time.Time {
+	if a.After(b) {
+		return a
+	}
+	return b
+}
+
+// stateLabels are the human-readable states of the HTML page
+var stateLabels = map[string]string{
+	StateOperational: "Operational",
+	StateDegraded:    "Degraded performance",
+	StateOutage:      "Major outage",
+}
+
+// pageLabels are the banners of the overall page state
+var pageLabels = map[string]string{
+	StateOperational: "All systems operational",
+	StateDegraded:    "Some systems degraded",
+	StateOutage:      "Major outage",
+}
+
+var pageTemplate = template.Must(template.New("status").Funcs(template.FuncMap{
+	"label":  func(state string) string { return stateLabels[state] },
+	"banner": func(state string) string { return pageLabels[state] },
+}).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<meta http-equiv="refresh" content="60">
+<title>{{.Title}}</title>
+<style>
+body{font-family:-apple-system,"Segoe UI",Helvetica,Arial,sans-serif;color:#24292f;max-width:860px;margin:2rem auto;padding:0 1rem}
+.banner{padding:1rem;border-radius:6px;color:#fff;font-weight:600;margin-bottom:1.5rem}
+.component{border:1px solid #d0d7de;border-radius:6px;padding:1rem;margin-bottom:1rem}
+.head{display:flex;justify-content:space-between;font-weight:600}
+.description{color:#57606a;font-size:.9rem;margin:.25rem 0}
+.bars{display:flex;gap:1px;margin:.75rem 0 .25rem;height:32px}
+.bars span{flex:1;border-radius:1px}
+.meta{display:flex;justify-content:space-between;color:#57606a;font-size:.8rem}
+.operational{background:#2da44e}.degraded{background:#d4a72c}.major_outage{background:#cf222e}
+.text-operational{color:#2da44e}.text-degraded{color:#9a6700}.text-major_outage{color:#cf222e}
+footer{color:#57606a;font-size:.8rem;text-align:center;margin-top:2rem}
+</style>
+</head>
+<body>
+<h1>{{.Title}}</h1>
+<div class="banner {{.Status}}">{{banner .Status}}</div>
+{{range .Components}}<div class="component">
+<div class="head"><span>{{.Name}}</span><span class="text-{{.Status}}">{{label .Status}}</span></div>
+{{if .Description}}<div class="description">{{.Description}}</div>{{end}}
+<div class="bars">{{range .History}}<span class="{{.Status}}" title="{{.Date}}: {{printf "%.2f" .UptimePercent}}% uptime"></span>{{end}}</div>
+<div class="meta"><span>{{len .History}} days ago</span><span>{{printf "%.2f" .UptimePercent}}% uptime</span><span>Today</span></div>
+</div>
+{{end}}<footer>Updated {{.UpdatedAt.Format "2006-01-02 15:04:05 MST"}}</footer>
+</body>
+</html>
+`))
+
+// HTML renders the page as a self-contained HTML document
+func (p *Page) HTML() ([]byte, error) {
+	var buf bytes.Buffer
+	if err := pageTemplate.Execute(&buf, p); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
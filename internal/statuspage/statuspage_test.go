@@ -0,0 +1,120 @@
+package statuspage
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"argus/internal/models"
+)
+
+var now = time.Date(2026, 10, 14, 12, 0, 0, 0, time.UTC)
+
+func change(id string, old, new models.AlertState, at time.Time) models.AlertHistoryEntry {
+	return models.AlertHistoryEntry{AlertID: id, OldState: old, NewState: new, Timestamp: at}
+}
+
+func TestFiringPeriods(t *testing.T) {
+	from := now.Add(-48 * time.Hour)
+	history := []models.AlertHistoryEntry{
+		change("a", models.StateActive, models.StateResolved, now.Add(-47*time.Hour)), // started before the window
+		change("a", models.StatePending, models.StateActive, now.Add(-10*time.Hour)),
+		change("a", models.StateInactive, models.StatePending, now.Add(-11*time.Hour)),
+		change("a", models.StateActive, models.StateResolved, now.Add(-9*time.Hour)),
+		change("a", models.StatePending, models.StateActive, now.Add(-time.Hour)),
+	}
+	periods := firingPeriods(history, nil, from, now)
+	assert.Equal(t, []period{
+		{from, now.Add(-47 * time.Hour)},
+		{now.Add(-11 * time.Hour), now.Add(-9 * time.Hour)},
+		{now.Add(-time.Hour), now},
+	}, periods, "entries are sorted, pending fires, and a period still firing lasts until now")
+
+	triggered := now.Add(-30 * time.Minute)
+	periods = firingPeriods(nil, &models.AlertStatus{State: models.StateActive, TriggeredAt: &triggered}, from, now)
+	assert.Equal(t, []period{{triggered, now}}, periods, "an active alert without history fires since it triggered")
+
+	periods = firingPeriods(nil, &models.AlertStatus{State: models.StatePending, TriggeredAt: &triggered}, from, now)
+	assert.Equal(t, []period{{triggered, now}}, periods, "a pending alert fires too")
+}
+
+func TestMerge(t *testing.T) {
+	at := func(h int) time.Time { return now.Add(time.Duration(h) * time.Hour) }
+	merged := merge([]period{{at(5), at(6)}, {at(0), at(2)}, {at(1), at(3)}, {at(3), at(4)}})
+	assert.Equal(t, []period{{at(0), at(4)}, {at(5), at(6)}}, merged)
+}
+
+func TestBuild(t *testing.T) {
+	alerts := []*models.AlertConfig{
+		{ID: "db-down", GroupID: "db", Enabled: true, Severity: models.SeverityCritical},
+		{ID: "db-slow", GroupID: "db", Enabled: true, Severity: models.SeverityWarning},
+		{ID: "web-down", GroupID: "web", Enabled: true, Severity: models.SeverityCritical},
+		{ID: "web-old", GroupID: "web", Enabled: false, Severity: models.SeverityCritical},
+	}
+	yesterday := time.Date(2026, 10, 13, 6, 0, 0, 0, time.UTC)
+	histories := map[string][]models.AlertHistoryEntry{
+		"db-down": {
+			change("db-down", models.StatePending, models.StateActive, yesterday),
+			change("db-down", models.StateActive, models.StateResolved, yesterday.Add(6*time.Hour)),
+		},
+		"db-slow": {change("db-slow", models.StatePending, models.StateActive, now.Add(-time.Hour))},
+		"web-old": {change("web-old", models.StatePending, models.StateActive, yesterday)},
+	}
+	src := Source{
+		Groups: map[string]*models.AlertGroup{"db": {ID: "db", Name: "Database"}, "web": {ID: "web", Name: "Web"}},
+		Alerts: alerts,
+		Statuses: map[string]*models.AlertStatus{
+			"db-slow": {State: models.StateActive},
+			"web-old": {State: models.StateActive},
+		},
+		History: func(id string) ([]models.AlertHistoryEntry, error) {
+			if id == "web-down" {
+				return nil, errors.New("unreadable")
+			}
+			return histories[id], nil
+		},
+	}
+	opts := Options{
+		Components:  []Component{{Group: "db"}, {Group: "web", Name: "Website", Description: "Public site"}, {Group: "gone"}},
+		HistoryDays: 3,
+		Location:    time.UTC,
+	}
+
+	page := Build(opts, src, now)
+	assert.Equal(t, DefaultTitle, page.Title)
+	assert.Equal(t, StateDegraded, page.Status)
+	require.Len(t, page.Components, 3)
+
+	db := page.Components[0]
+	assert.Equal(t, "Database", db.Name, "named after the group")
+	assert.Equal(t, StateDegraded, db.Status, "a firing warning degrades the component")
+	require.Len(t, db.History, 3)
+	assert.Equal(t, Day{Date: "2026-10-12", Status: StateOperational, UptimePercent: 100}, db.History[0])
+	assert.Equal(t, Day{Date: "2026-10-13", Status: StateOutage, UptimePercent: 75, DowntimeSeconds: 6 * 3600}, db.History[1])
+	assert.Equal(t, Day{Date: "2026-10-14", Status: StateDegraded, UptimePercent: 100}, db.History[2], "today so far, degradations are not downtime")
+	assert.Equal(t, 90.0, db.UptimePercent, "6h down out of the 60h since the first day started")
+
+	web := page.Components[1]
+	assert.Equal(t, "Website", web.Name)
+	assert.Equal(t, "Public site", web.Description)
+	assert.Equal(t, StateOperational, web.Status, "disabled alerts are ignored")
+	assert.Equal(t, 100.0, web.UptimePercent)
+
+	assert.Equal(t, "gone", page.Components[2].Name, "unknown groups are named by their ID")
+}
+
+func TestPage_HTML(t *testing.T) {
+	page := Build(Options{Title: "Acme <Status>", Components: []Component{{Group: "web", Name: "Web"}}, HistoryDays: 2, Location: time.UTC},
+		Source{Alerts: []*models.AlertConfig{{ID: "a", GroupID: "web", Enabled: true, Severity: models.SeverityCritical}},
+			Statuses: map[string]*models.AlertStatus{"a": {State: models.StateActive}}}, now)
+	html, err := page.HTML()
+	require.NoError(t, err)
+	s := string(html)
+	assert.Contains(t, s, "<title>Acme &lt;Status&gt;</title>")
+	assert.Contains(t, s, `<div class="banner major_outage">Major outage</div>`)
+	assert.Equal(t, 2, strings.Count(s, `title="2026-10-1`), "one bar per day")
+}
@@ -0,0 +1,184 @@
+// File: internal/tlsclient/tlsclient.go
+// Brief: TLS material of outbound connections
+// Detailed: Loads the custom CA bundles and client certificates (mTLS) of outbound integrations for internal PKI environments. Client certificates are read again when their files change, so renewed certificates apply without a restart, and their expiry is reported for self-monitoring.
+// Author: drama.lin@aver.com
+// Date: 2026-10-14
+
+package tlsclient
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"sync"
+	"time"
+)
+
+// Client certificate expiry states
+const (
+	StatusOK       = "ok"
+	StatusExpiring = "expiring" // Expires within the warning window
+	StatusExpired  = "expired"
+	StatusError    = "error" // Unreadable certificate
+)
+
+// Config holds the TLS material of outbound connections
+type Config struct {
+	CAFile   string // PEM bundle of CAs trusted in addition to the system pool
+	CertFile string // PEM client certificate, followed by its intermediates
+	KeyFile  string // PEM private key of CertFile
+}
+
+// Validate checks that the CA bundle and the client key pair load
+func (c Config) Validate() error {
+	if (c.CertFile == "") != (c.KeyFile == "") {
+		return errors.New("cert_file and key_file must be set together")
+	}
+	if _, err := c.RootCAs(); err != nil {
+		return err
+	}
+	if c.CertFile != "" {
+		if _, err := loadKeyPair(c.CertFile, c.KeyFile); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RootCAs returns the system pool plus the CA bundle, or nil (the system
+// pool alone) without a bundle
+func (c Config) RootCAs() (*x509.CertPool, error) {
+	if c.CAFile == "" {
+		return nil, nil
+	}
+	data, err := os.ReadFile(c.CAFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CA bundle: %w", err)
+	}
+	pool, err := x509.SystemCertPool()
+	if err != nil {
+		pool = x509.NewCertPool()
+	}
+	if !pool.AppendCertsFromPEM(data) {
+		return nil, fmt.Errorf("invalid CA bundle %s: no PEM certificates found", c.CAFile)
+	}
+	return pool, nil
+}
+
+// Apply sets the trusted CAs and the client certificate on tc
+func (c Config) Apply(tc *tls.Config) error {
+	if err := c.Validate(); err != nil {
+		return err
+	}
+	pool, err := c.RootCAs()
+	if err != nil {
+		return err
+	}
+	if pool != nil {
+		tc.RootCAs = pool
+	}
+	if c.CertFile != "" {
+		tc.GetClientCertificate = (&keyPair{certFile: c.CertFile, keyFile: c.KeyFile}).get
+	}
+	return nil
+}
+
+// CertStatus is the expiry of a client certificate
+type CertStatus struct {
+	Name     string    `json:"name"`
+	File     string    `json:"file"`
+	Subject  string    `json:"subject,omitempty"`
+	Issuer   string    `json:"issuer,omitempty"`
+	NotAfter time.Time `json:"not_after"`
+	DaysLeft int       `json:"days_left"` // Whole days until NotAfter, negative once expired
+	Status   string    `json:"status"`    // ok, expiring, expired or error
+	Error    string    `json:"error,omitempty"`
+}
+
+// Check reports the expiry of the client certificate at now; it is
+// expiring within warnBefore of its end
+func (c Config) Check(name string, now time.Time, warnBefore time.Duration) CertStatus {
+	status := CertStatus{Name: name, File: c.CertFile}
+	pair, err := loadKeyPair(c.CertFile, c.KeyFile)
+	if err != nil {
+		status.Status = StatusError
+		status.Error = err.Error()
+		return status
+	}
+	leaf := pair.Leaf
+	status.Subject = leaf.Subject.String()
+	status.Issuer = leaf.Issuer.String()
+	status.NotAfter = leaf.NotAfter
+	left := leaf.NotAfter.Sub(now)
+	status.DaysLeft = int(left / (24 * time.Hour))
+	switch {
+	case left <= 0:
+		status.Status = StatusExpired
+	case left <= warnBefore:
+		status.Status = StatusExpiring
+	default:
+		status.Status = StatusOK
+	}
+	return status
+}
+
+// keyPair serves a client certificate, reloading it when its files change
+type keyPair struct {
+	certFile, keyFile string
+
+	mu       sync.Mutex
+	cert     *tls.Certificate
+	modified time.Time // Latest modification time of the loaded files
+}
+
+// get implements tls.Config.GetClientCertificate. A renewed certificate that
+// fails to load is logged and the previous one kept.
+func (k *keyPair) get(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	modified := k.modTime()
+	if k.cert != nil && modified.Equal(k.modified) {
+		return k.cert, nil
+	}
+	cert, err := loadKeyPair(k.certFile, k.keyFile)
+	if err != nil {
+		if k.cert == nil {
+			return nil, err
+		}
+		slog.Warn("Failed to reload client certificate, keeping the previous one", "cert_file", k.certFile, "error", err)
+		return k.cert, nil
+	}
+	if k.cert != nil {
+		slog.Info("Client certificate reloaded", "cert_file", k.certFile, "not_after", cert.Leaf.NotAfter)
+	}
+	k.cert, k.modified = cert, modified
+	return cert, nil
+}
+
+// modTime returns the latest modification time of the key pair files
+func (k *keyPair) modTime() time.Time {
+	var latest time.Time
+	for _, path := range []string{k.certFile, k.keyFile} {
+		if info, err := os.Stat(path); err == nil && info.ModTime().After(latest) {
+			latest = info.ModTime()
+		}
+	}
+	return latest
+}
+
+// loadKeyPair loads a PEM client certificate and key, parsing the leaf
+func loadKeyPair(certFile, keyFile string) (*tls.Certificate, error) {
+	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return nil, fmt.Errorf("invalid client certificate %s: %w", certFile, err)
+	}
+	if cert.Leaf == nil {
+		if cert.Leaf, err = x509.ParseCertificate(cert.Certificate[0]); err != nil {
+			return nil, fmt.Errorf("invalid client certificate %s: %w", certFile, err)
+		}
+	}
+	return &cert, nil
+}
@@ -0,0 +1,184 @@
+package tlsclient
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io"
+	"log"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testCA issues certificates for tests
+type testCA struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	pem  []byte
+}
+
+func newTestCA(t *testing.T) *testCA {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "Test CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(24 * time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	return &testCA{cert: cert, key: key, pem: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})}
+}
+
+// issue writes a certificate for name expiring at notAfter and its key to dir
+func (ca *testCA) issue(t *testing.T, dir, name string, notAfter time.Time, usage x509.ExtKeyUsage) (certFile, keyFile string) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: name},
+		DNSNames:     []string{name},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     notAfter,
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{usage},
+	}
+	if ip := net.ParseIP(name); ip != nil {
+		template.IPAddresses = []net.IP{ip}
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, ca.cert, &key.PublicKey, ca.key)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+	certFile = filepath.Join(dir, name+".crt")
+	keyFile = filepath.Join(dir, name+".key")
+	require.NoError(t, os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0644))
+	require.NoError(t, os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0600))
+	return certFile, keyFile
+}
+
+func TestConfig_Apply(t *testing.T) {
+	dir := t.TempDir()
+	ca := newTestCA(t)
+	caFile := filepath.Join(dir, "ca.pem")
+	require.NoError(t, os.WriteFile(caFile, ca.pem, 0644))
+	serverCert, serverKey := ca.issue(t, dir, "127.0.0.1", time.Now().Add(time.Hour), x509.ExtKeyUsageServerAuth)
+	clientCert, clientKey := ca.issue(t, dir, "argus", time.Now().Add(time.Hour), x509.ExtKeyUsageClientAuth)
+
+	// A server of the internal PKI requiring client certificates
+	pair, err := tls.LoadX509KeyPair(serverCert, serverKey)
+	require.NoError(t, err)
+	clients := x509.NewCertPool()
+	clients.AddCert(ca.cert)
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(r.TLS.PeerCertificates[0].Subject.CommonName))
+	}))
+	server.TLS = &tls.Config{Certificates: []tls.Certificate{pair}, ClientAuth: tls.RequireAndVerifyClientCert, ClientCAs: clients}
+	server.Config.ErrorLog = log.New(io.Discard, "", 0) // Rejected handshakes
+	server.StartTLS()
+	defer server.Close()
+
+	get := func(c Config) error {
+		tc := &tls.Config{}
+		require.NoError(t, c.Apply(tc))
+		client := &http.Client{Transport: &http.Transport{TLSClientConfig: tc}}
+		resp, err := client.Get(server.URL)
+		if err != nil {
+			return err
+		}
+		resp.Body.Close()
+		return nil
+	}
+	assert.Error(t, get(Config{}), "the test CA is not in the system pool")
+	assert.Error(t, get(Config{CAFile: caFile}), "the server requires a client certificate")
+	assert.NoError(t, get(Config{CAFile: caFile, CertFile: clientCert, KeyFile: clientKey}))
+}
+
+func TestConfig_Validate(t *testing.T) {
+	dir := t.TempDir()
+	ca := newTestCA(t)
+	certFile, keyFile := ca.issue(t, dir, "argus", time.Now().Add(time.Hour), x509.ExtKeyUsageClientAuth)
+	caFile := filepath.Join(dir, "ca.pem")
+	require.NoError(t, os.WriteFile(caFile, ca.pem, 0644))
+
+	assert.NoError(t, Config{}.Validate())
+	assert.NoError(t, Config{CAFile: caFile, CertFile: certFile, KeyFile: keyFile}.Validate())
+	assert.ErrorContains(t, Config{CertFile: certFile}.Validate(), "must be set together")
+	assert.ErrorContains(t, Config{CAFile: filepath.Join(dir, "missing.pem")}.Validate(), "failed to read CA bundle")
+	assert.ErrorContains(t, Config{CAFile: keyFile}.Validate(), "no PEM certificates")
+	assert.ErrorContains(t, Config{CertFile: caFile, KeyFile: keyFile}.Validate(), "invalid client certificate")
+}
+
+func TestConfig_Check(t *testing.T) {
+	dir := t.TempDir()
+	ca := newTestCA(t)
+	now := time.Now()
+	certFile, keyFile := ca.issue(t, dir, "argus", now.Add(10*24*time.Hour+time.Hour), x509.ExtKeyUsageClientAuth)
+	c := Config{CertFile: certFile, KeyFile: keyFile}
+
+	status := c.Check("webhook", now, 7*24*time.Hour)
+	assert.Equal(t, StatusOK, status.Status)
+	assert.Equal(t, "webhook", status.Name)
+	assert.Equal(t, "CN=argus", status.Subject)
+	assert.Equal(t, "CN=Test CA", status.Issuer)
+	assert.Equal(t, 10, status.DaysLeft)
+
+	assert.Equal(t, StatusExpiring, c.Check("webhook", now, 30*24*time.Hour).Status)
+	expired := c.Check("webhook", now.Add(12*24*time.Hour), 30*24*time.Hour)
+	assert.Equal(t, StatusExpired, expired.Status)
+	assert.Equal(t, -1, expired.DaysLeft)
+
+	broken := Config{CertFile: filepath.Join(dir, "missing.crt"), KeyFile: keyFile}.Check("webhook", now, 0)
+	assert.Equal(t, StatusError, broken.Status)
+	assert.NotEmpty(t, broken.Error)
+}
+
+func TestKeyPair_Reload(t *testing.T) {
+	dir := t.TempDir()
+	ca := newTestCA(t)
+	certFile, keyFile := ca.issue(t, dir, "argus", time.Now().Add(time.Hour), x509.ExtKeyUsageClientAuth)
+	k := &keyPair{certFile: certFile, keyFile: keyFile}
+
+	first, err := k.get(nil)
+	require.NoError(t, err)
+	again, err := k.get(nil)
+	require.NoError(t, err)
+	assert.Same(t, first, again, "unchanged files are not read again")
+
+	// A renewed certificate is picked up
+	ca.issue(t, dir, "argus", time.Now().Add(48*time.Hour), x509.ExtKeyUsageClientAuth)
+	later := time.Now().Add(time.Minute)
+	require.NoError(t, os.Chtimes(certFile, later, later))
+	renewed, err := k.get(nil)
+	require.NoError(t, err)
+	assert.True(t, renewed.Leaf.NotAfter.After(first.Leaf.NotAfter))
+
+	// A broken renewal keeps the previous certificate
+	require.NoError(t, os.WriteFile(certFile, []byte("garbage"), 0644))
+	later = later.Add(time.Minute)
+	require.NoError(t, os.Chtimes(certFile, later, later))
+	kept, err := k.get(nil)
+	require.NoError(t, err)
+	assert.Same(t, renewed, kept)
+}
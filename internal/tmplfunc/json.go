@@ -0,0 +1,184 @@
+// File: internal/tmplfunc/json.go
+// Brief: JSON structures templated value by value
+// Detailed: Compiles a JSON value, such as the payload mapping of a webhook destination, whose strings are sandboxed text templates, and renders it into the structure to marshal. Objects and arrays are rendered recursively and other values are kept as they are. A string that is only a reference to a field, e.g. "{{ .Value }}", yields the field's value rather than its text, so numbers, booleans and nested objects keep their JSON type.
+// Author: drama.lin@aver.com
+// Date: 2026-10-14
+
+package tmplfunc
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	texttemplate "text/template"
+	"text/template/parse"
+)
+
+// JSONTemplate is a JSON value whose strings are templates
+type JSONTemplate struct {
+	root   jsonNode
+	limits Limits
+}
+
+// jsonNode is a compiled part of a JSONTemplate
+type jsonNode interface {
+	render(data any, limits Limits) (any, error)
+}
+
+type (
+	jsonObject  map[string]jsonNode
+	jsonArray   []jsonNode
+	jsonLiteral struct{ value any }
+	jsonString  struct {
+		tmpl  *texttemplate.Template
+		field []string // Path of a string that only references a field, e.g. {{ .Labels.team }}
+	}
+)
+
+// ParseJSON compiles a value decoded from JSON or YAML. Templates are named
+// after their path in it, e.g. payload.fields.summary, so errors point at
+// the string to fix.
+func ParseJSON(name string, value any, limits Limits) (*JSONTemplate, error) {
+	limits = limits.withDefaults()
+	root, err := parseJSON(name, value, limits)
+	if err != nil {
+		return nil, err
+	}
+	return &JSONTemplate{root: root, limits: limits}, nil
+}
+
+func parseJSON(path string, value any, limits Limits) (jsonNode, error) {
+	switch v := value.(type) {
+	case map[string]any:
+		object := make(jsonObject, len(v))
+		for key, child := range v {
+			node, err := parseJSON(path+"."+key, child, limits)
+			if err != nil {
+				return nil, err
+			}
+			object[key] = node
+		}
+		return object, nil
+	case []any:
+		array := make(jsonArray, len(v))
+		for i, child := range v {
+			node, err := parseJSON(fmt.Sprintf("%s[%d]", path, i), child, limits)
+			if err != nil {
+				return nil, err
+			}
+			array[i] = node
+		}
+		return array, nil
+	case string:
+		if !strings.Contains(v, "{{") {
+			return jsonLiteral{v}, nil
+		}
+		t, err := ParseSandboxedText(path, v, limits)
+		if err != nil {
+			return nil, err
+		}
+		return jsonString{tmpl: t, field: fieldReference(t)}, nil
+	default:
+		return jsonLiteral{v}, nil
+	}
+}
+
+// fieldReference returns the field path of a template consisting of nothing
+// but one field, or nil
+func fieldReference(t *texttemplate.Template) []string {
+	if t.Tree == nil || len(t.Tree.Root.Nodes) != 1 {
+		return nil
+	}
+	action, ok := t.Tree.Root.Nodes[0].(*parse.ActionNode)
+	if !ok || len(action.Pipe.Decl) > 0 || len(action.Pipe.Cmds) != 1 || len(action.Pipe.Cmds[0].Args) != 1 {
+		return nil
+	}
+	if field, ok := action.Pipe.Cmds[0].Args[0].(*parse.FieldNode); ok {
+		return field.Ident
+	}
+	return nil
+}
+
+// Execute renders the value with data, ready to be marshaled
+func (t *JSONTemplate) Execute(data any) (any, error) {
+	return t.root.render(data, t.limits)
+}
+
+func (o jsonObject) render(data any, limits Limits) (any, error) {
+	result := make(map[string]any, len(o))
+	for key, node := range o {
+		value, err := node.render(data, limits)
+		if err != nil {
+			return nil, err
+		}
+		result[key] = value
+	}
+	return result, nil
+}
+
+func (a jsonArray) render(data any, limits Limits) (any, error) {
+	result := make([]any, len(a))
+	for i, node := range a {
+		value, err := node.render(data, limits)
+		if err != nil {
+			return nil, err
+		}
+		result[i] = value
+	}
+	return result, nil
+}
+
+func (l jsonLiteral) render(any, Limits) (any, error) {
+	return l.value, nil
+}
+
+func (s jsonString) render(data any, limits Limits) (any, error) {
+	if s.field != nil {
+		if value, ok := lookupField(data, s.field); ok {
+			return value, nil
+		}
+	}
+	return ExecuteText(s.tmpl, data, limits)
+}
+
+// lookupField resolves a field path through struct fields and string-keyed
+// maps. It reports false for paths the template has to evaluate, such as
+// method calls.
+func lookupField(data any, path []string) (any, bool) {
+	v := reflect.ValueOf(data)
+	for _, name := range path {
+		for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+			if v.IsNil() {
+				return nil, false
+			}
+			v = v.Elem()
+		}
+		switch v.Kind() {
+		case reflect.Struct:
+			field, ok := v.Type().FieldByName(name)
+			if !ok || !field.IsExported() {
+				return nil, false
+			}
+			value, err := v.FieldByIndexErr(field.Index)
+			if err != nil {
+				return nil, false
+			}
+			v = value
+		case reflect.Map:
+			if v.Type().Key().Kind() != reflect.String {
+				return nil, false
+			}
+			value := v.MapIndex(reflect.ValueOf(name).Convert(v.Type().Key()))
+			if !value.IsValid() {
+				value = reflect.Zero(v.Type().Elem())
+			}
+			v = value
+		default:
+			return nil, false
+		}
+	}
+	if !v.IsValid() || !v.CanInterface() {
+		return nil, false
+	}
+	return v.Interface(), true
+}
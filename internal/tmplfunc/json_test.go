@@ -0,0 +1,73 @@
+package tmplfunc
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type jsonTestData struct {
+	Name      string
+	Value     float64
+	Firing    bool
+	Labels    map[string]string
+	Timestamp time.Time
+}
+
+func TestJSONTemplate(t *testing.T) {
+	var mapping any
+	require.NoError(t, json.Unmarshal([]byte(`{
+		"fields": {
+			"project": {"key": "OPS"},
+			"summary": "{{ .Name }} at {{ round .Value 1 }} <{{ .Labels.team }}>",
+			"priority": {"name": "{{ if .Firing }}High{{ else }}Low{{ end }}"},
+			"labels": ["argus", "{{ .Labels.team }}"]
+		},
+		"value": "{{ .Value }}",
+		"firing": "{{ .Firing }}",
+		"tags": "{{ .Labels }}",
+		"missing": "{{ .Labels.owner }}",
+		"owner": "owner-{{ .Labels.owner }}",
+		"day": "{{ .Timestamp.Format \"2006-01-02\" }}",
+		"retries": 3
+	}`), &mapping))
+	tmpl, err := ParseJSON("payload", mapping, Limits{})
+	require.NoError(t, err)
+
+	data := jsonTestData{Name: "disk", Value: 91.26, Firing: true, Labels: map[string]string{"team": "storage"},
+		Timestamp: time.Date(2026, 10, 14, 8, 0, 0, 0, time.UTC)}
+	rendered, err := tmpl.Execute(data)
+	require.NoError(t, err)
+	out, err := json.Marshal(rendered)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"fields": {
+			"project": {"key": "OPS"},
+			"summary": "disk at 91.3 <storage>",
+			"priority": {"name": "High"},
+			"labels": ["argus", "storage"]
+		},
+		"value": 91.26,
+		"firing": true,
+		"tags": {"team": "storage"},
+		"missing": "",
+		"owner": "owner-",
+		"day": "2026-10-14",
+		"retries": 3
+	}`, string(out), "field references keep their type and text is not HTML-escaped")
+}
+
+func TestParseJSON_Errors(t *testing.T) {
+	_, err := ParseJSON("payload", map[string]any{"fields": map[string]any{"summary": "{{ .Name"}}, Limits{})
+	assert.ErrorContains(t, err, "payload.fields.summary")
+	_, err = ParseJSON("payload", []any{"ok", "{{ call .Name }}"}, Limits{})
+	assert.ErrorContains(t, err, "call function is not allowed")
+
+	tmpl, err := ParseJSON("payload", map[string]any{"name": "{{ .Nmae }}"}, Limits{})
+	require.NoError(t, err)
+	_, err = tmpl.Execute(jsonTestData{})
+	assert.ErrorContains(t, err, "can't evaluate field Nmae")
+}
@@ -0,0 +1,131 @@
+// File: internal/tmplfunc/markdown.go
+// Brief: Minimal Markdown to HTML rendering for templates
+// Detailed: Renders the Markdown subset used in alert descriptions and messages (headings, paragraphs, lists, fenced code, inline code, emphasis and links) as HTML. The input is HTML-escaped first and links are limited to http, https, mailto and relative URLs, so rendering descriptions cannot inject markup or scripts into notifications.
+// Author: drama.lin@aver.com
+// Date: 2026-10-14
+
+package tmplfunc
+
+import (
+	"fmt"
+	"html"
+	"html/template"
+	"regexp"
+	"strings"
+)
+
+var (
+	headingPattern  = regexp.MustCompile(`^(#{1,6})\s+(.*?)\s*#*\s*$`)
+	bulletPattern   = regexp.MustCompile(`^\s*[-*+]\s+(.*)$`)
+	numberedPattern = regexp.MustCompile(`^\s*\d+[.)]\s+(.*)$`)
+	linkPattern     = regexp.MustCompile(`\[([^\]]+)\]\(([^)\s]+)\)`)
+	strongPattern   = regexp.MustCompile(`\*\*([^*]+)\*\*|__([^_]+)__`)
+	emPattern       = regexp.MustCompile(`\*([^*]+)\*`)
+	// Underscores only emphasize between word boundaries, so names such as
+	// usage_percent stay as they are
+	underscorePattern = regexp.MustCompile(`(^|[^\w])_([^_]+)_([^\w]|$)`)
+)
+
+// Markdown renders Markdown text as HTML. Raw HTML in the input is escaped.
+func Markdown(v any) template.HTML {
+	var out strings.Builder
+	var paragraph []string
+	list := "" // ul or ol while in a list
+	flush := func() {
+		if len(paragraph) > 0 {
+			fmt.Fprintf(&out, "<p>%s</p>\n", inline(strings.Join(paragraph, "\n")))
+			paragraph = nil
+		}
+		if list != "" {
+			fmt.Fprintf(&out, "</%s>\n", list)
+			list = ""
+		}
+	}
+
+	lines := strings.Split(strings.ReplaceAll(fmt.Sprint(v), "\r\n", "\n"), "\n")
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case strings.HasPrefix(trimmed, "```"):
+			flush()
+			var code []string
+			for i++; i < len(lines) && !strings.HasPrefix(strings.TrimSpace(lines[i]), "```"); i++ {
+				code = append(code, lines[i])
+			}
+			fmt.Fprintf(&out, "<pre><code>%s</code></pre>\n", template.HTMLEscapeString(strings.Join(code, "\n")))
+		case trimmed == "":
+			flush()
+		case headingPattern.MatchString(trimmed):
+			flush()
+			m := headingPattern.FindStringSubmatch(trimmed)
+			fmt.Fprintf(&out, "<h%d>%s</h%d>\n", len(m[1]), inline(m[2]), len(m[1]))
+		case bulletPattern.MatchString(line), numberedPattern.MatchString(line):
+			kind, m := "ul", bulletPattern.FindStringSubmatch(line)
+			if m == nil {
+				kind, m = "ol", numberedPattern.FindStringSubmatch(line)
+			}
+			if list != kind {
+				flush()
+				fmt.Fprintf(&out, "<%s>\n", kind)
+				list = kind
+			}
+			fmt.Fprintf(&out, "<li>%s</li>\n", inline(m[1]))
+		case list != "":
+			// A list ends at a line that is not an item
+			flush()
+			paragraph = append(paragraph, trimmed)
+		default:
+			paragraph = append(paragraph, trimmed)
+		}
+	}
+	flush()
+	return template.HTML(strings.TrimSuffix(out.String(), "\n"))
+}
+
+// inline renders code spans, links and emphasis within a block
+func inline(text string) string {
+	// Code spans are taken verbatim, so split them off first
+	parts := strings.Split(text, "`")
+	var out strings.Builder
+	for i, part := range parts {
+		switch {
+		case i%2 == 1 && i < len(parts)-1:
+			out.WriteString("<code>" + template.HTMLEscapeString(part) + "</code>")
+		case i%2 == 1:
+			// Unmatched backtick
+			out.WriteString("`" + emphasis(template.HTMLEscapeString(part)))
+		default:
+			out.WriteString(emphasis(template.HTMLEscapeString(part)))
+		}
+	}
+	return out.String()
+}
+
+// emphasis renders links, strong and emphasized text of escaped text
+func emphasis(text string) string {
+	text = linkPattern.ReplaceAllStringFunc(text, func(s string) string {
+		m := linkPattern.FindStringSubmatch(s)
+		if !safeURL(html.UnescapeString(m[2])) {
+			return m[1]
+		}
+		return fmt.Sprintf(`<a href="%s">%s</a>`, m[2], m[1])
+	})
+	text = strongPattern.ReplaceAllString(text, "<strong>$1$2</strong>")
+	text = emPattern.ReplaceAllString(text, "<em>$1</em>")
+	return underscorePattern.ReplaceAllString(text, "$1<em>$2</em>$3")
+}
+
+// safeURL reports whether a link target is http(s), mailto or relative
+func safeURL(u string) bool {
+	lower := strings.ToLower(u)
+	if strings.HasPrefix(lower, "//") {
+		return false // Protocol-relative, to any host
+	}
+	for _, prefix := range []string{"http://", "https://", "mailto:", "/", "#"} {
+		if strings.HasPrefix(lower, prefix) {
+			return true
+		}
+	}
+	return false
+}
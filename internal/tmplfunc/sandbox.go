@@ -0,0 +1,260 @@
+// File: internal/tmplfunc/sandbox.go
+// Brief: Execution limits for user-provided templates
+// Detailed: Parses user-provided notification templates, as HTML or as plain text, with a restricted set of actions and runs them with a timeout and an output size cap, so a mistaken template cannot produce megabyte notifications or hold up delivery. Templates may not call function values, invoke other templates or range over large literal counts, and printf widths are bounded by the output cap.
+// Author: drama.lin@aver.com
+// Date: 2026-10-14
+
+package tmplfunc
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"html/template"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	texttemplate "text/template"
+	"text/template/parse"
+	"time"
+)
+
+// Sandbox defaults
+const (
+	DefaultTimeout   = time.Second
+	DefaultMaxOutput = 64 << 10 // Bytes per rendered template
+	maxRangeLiteral  = 1000     // Iterations of a range over a number literal
+)
+
+// Sandbox errors
+var (
+	ErrTimeout        = errors.New("template execution timed out")
+	ErrOutputTooLarge = errors.New("template output too large")
+)
+
+var (
+	formatSpecPattern = regexp.MustCompile(`%[^a-zA-Z%]*`)
+	digitsPattern     = regexp.MustCompile(`\d+`)
+)
+
+// Limits bound the execution of user-provided templates; zero fields use the
+// defaults
+type Limits struct {
+	Timeout   time.Duration // Per execution
+	MaxOutput int           // Bytes per rendered template
+}
+
+func (l Limits) withDefaults() Limits {
+	if l.Timeout <= 0 {
+		l.Timeout = DefaultTimeout
+	}
+	if l.MaxOutput <= 0 {
+		l.MaxOutput = DefaultMaxOutput
+	}
+	return l
+}
+
+// SandboxFuncs returns the functions of user-provided templates: the helpers,
+// and a printf whose widths and precisions stay within maxOutput
+func SandboxFuncs(maxOutput int) template.FuncMap {
+	funcs := Funcs()
+	funcs["printf"] = func(format string, args ...any) (string, error) {
+		for _, spec := range formatSpecPattern.FindAllString(format, -1) {
+			if strings.Contains(spec, "*") {
+				return "", fmt.Errorf("printf: width from an argument (%s) is not allowed", spec)
+			}
+			for _, digits := range digitsPattern.FindAllString(spec, -1) {
+				if n, err := strconv.Atoi(digits); err != nil || n > maxOutput {
+					return "", fmt.Errorf("printf: width %s exceeds the %d byte output limit", digits, maxOutput)
+				}
+			}
+		}
+		return fmt.Sprintf(format, args...), nil
+	}
+	return funcs
+}
+
+// ParseSandboxed parses a user-provided template, rejecting the actions it may
+// not use
+func ParseSandboxed(name, text string, limits Limits) (*template.Template, error) {
+	limits = limits.withDefaults()
+	t, err := template.New(name).Funcs(SandboxFuncs(limits.MaxOutput)).Parse(text)
+	if err != nil {
+		return nil, err
+	}
+	for _, defined := range t.Templates() {
+		if err := checkTree(defined.Tree); err != nil {
+			return nil, err
+		}
+	}
+	return t, nil
+}
+
+// ParseSandboxedText is ParseSandboxed for output that is not HTML, such as
+// the fields of a JSON payload, which must not be escaped. A missing map key,
+// e.g. an unset label, renders as the zero value rather than "<no value>".
+func ParseSandboxedText(name, text string, limits Limits) (*texttemplate.Template, error) {
+	limits = limits.withDefaults()
+	t, err := texttemplate.New(name).Funcs(SandboxFuncs(limits.MaxOutput)).Option("missingkey=zero").Parse(text)
+	if err != nil {
+		return nil, err
+	}
+	for _, defined := range t.Templates() {
+		if err := checkTree(defined.Tree); err != nil {
+			return nil, err
+		}
+	}
+	return t, nil
+}
+
+// checkTree rejects the actions sandboxed templates may not use
+func checkTree(tree *parse.Tree) error {
+	if tree == nil {
+		return nil
+	}
+	return checkNode(tree, tree.Root)
+}
+
+// checkNode rejects template invocations, the call function and ranges over
+// large number literals
+func checkNode(tree *parse.Tree, node parse.Node) error {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return nil
+		}
+		for _, child := range n.Nodes {
+			if err := checkNode(tree, child); err != nil {
+				return err
+			}
+		}
+	case *parse.ActionNode:
+		return checkPipe(tree, n.Pipe)
+	case *parse.IfNode:
+		return checkBranch(tree, &n.BranchNode)
+	case *parse.WithNode:
+		return checkBranch(tree, &n.BranchNode)
+	case *parse.RangeNode:
+		if p := n.Pipe; p != nil && len(p.Cmds) == 1 && len(p.Cmds[0].Args) == 1 {
+			if num, ok := p.Cmds[0].Args[0].(*parse.NumberNode); ok && num.IsInt && num.Int64 > maxRangeLiteral {
+				location, _ := tree.ErrorContext(n)
+				return fmt.Errorf("%s: range over %d exceeds the limit of %d iterations", location, num.Int64, maxRangeLiteral)
+			}
+		}
+		return checkBranch(tree, &n.BranchNode)
+	case *parse.TemplateNode:
+		location, _ := tree.ErrorContext(n)
+		return fmt.Errorf("%s: invoking templates ({{template %q}}) is not allowed", location, n.Name)
+	}
+	return nil
+}
+
+func checkBranch(tree *parse.Tree, n *parse.BranchNode) error {
+	if err := checkPipe(tree, n.Pipe); err != nil {
+		return err
+	}
+	if err := checkNode(tree, n.List); err != nil {
+		return err
+	}
+	return checkNode(tree, n.ElseList)
+}
+
+func checkPipe(tree *parse.Tree, pipe *parse.PipeNode) error {
+	if pipe == nil {
+		return nil
+	}
+	for _, cmd := range pipe.Cmds {
+		for _, arg := range cmd.Args {
+			switch a := arg.(type) {
+			case *parse.IdentifierNode:
+				if a.Ident == "call" {
+					location, _ := tree.ErrorContext(a)
+					return fmt.Errorf("%s: the call function is not allowed", location)
+				}
+			case *parse.PipeNode:
+				if err := checkPipe(tree, a); err != nil {
+					return err
+				}
+			case *parse.ChainNode:
+				if p, ok := a.Node.(*parse.PipeNode); ok {
+					if err := checkPipe(tree, p); err != nil {
+						return err
+					}
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// Execute renders a sandboxed template, failing once it writes more than
+// limits.MaxOutput bytes or runs longer than limits.Timeout. A template still
+// running at the timeout is abandoned; its further writes fail.
+func Execute(t *template.Template, data any, limits Limits) (string, error) {
+	return execute(func(w io.Writer) error { return t.Execute(w, data) }, limits)
+}
+
+// ExecuteText renders a sandboxed text template like Execute
+func ExecuteText(t *texttemplate.Template, data any, limits Limits) (string, error) {
+	return execute(func(w io.Writer) error { return t.Execute(w, data) }, limits)
+}
+
+// execute runs a template within the limits
+func execute(run func(w io.Writer) error, limits Limits) (string, error) {
+	limits = limits.withDefaults()
+	w := &limitedWriter{max: limits.MaxOutput}
+	done := make(chan error, 1)
+	go func() {
+		done <- run(w)
+	}()
+
+	timer := time.NewTimer(limits.Timeout)
+	defer timer.Stop()
+	select {
+	case err := <-done:
+		if err != nil {
+			return "", err
+		}
+		return w.String(), nil
+	case <-timer.C:
+		err := fmt.Errorf("%w after %s", ErrTimeout, limits.Timeout)
+		w.fail(err)
+		return "", err
+	}
+}
+
+// limitedWriter buffers template output up to max bytes
+type limitedWriter struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+	max int
+	err error
+}
+
+func (w *limitedWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.err != nil {
+		return 0, w.err
+	}
+	if w.buf.Len()+len(p) > w.max {
+		w.err = fmt.Errorf("%w: more than %d bytes", ErrOutputTooLarge, w.max)
+		return 0, w.err
+	}
+	return w.buf.Write(p)
+}
+
+// fail makes further writes return err
+func (w *limitedWriter) fail(err error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.err = err
+}
+
+func (w *limitedWriter) String() string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.buf.String()
+}
@@ -0,0 +1,74 @@
+package tmplfunc
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSandboxed(t *testing.T) {
+	_, err := ParseSandboxed("body", `{{ .Name | upper }} at {{ round .Value 1 }}{{ range 3 }}!{{ end }}`, Limits{})
+	require.NoError(t, err)
+
+	for text, want := range map[string]string{
+		`{{ call .Func }}`: "call function is not allowed",
+		`{{ if true }}{{ len (call .Func) }}{{ end }}`:  "call function is not allowed",
+		`{{ define "x" }}hi{{ end }}{{ template "x" }}`: `invoking templates ({{template "x"}}) is not allowed`,
+		`{{ block "x" . }}hi{{ end }}`:                  "invoking templates",
+		`{{ range 100000000 }}x{{ end }}`:               "exceeds the limit of 1000 iterations",
+		`{{ .Name `:                                     "unclosed action",
+	} {
+		_, err := ParseSandboxed("body", text, Limits{})
+		assert.ErrorContains(t, err, want, text)
+	}
+}
+
+func TestExecute(t *testing.T) {
+	data := map[string]any{"Name": "disk", "Items": make([]int, 2000)}
+
+	tmpl, err := ParseSandboxed("body", `{{ .Name }}: {{ printf "%5.1f" 3.14159 }}`, Limits{})
+	require.NoError(t, err)
+	out, err := Execute(tmpl, data, Limits{})
+	require.NoError(t, err)
+	assert.Equal(t, "disk:   3.1", out)
+
+	// Output beyond the cap
+	tmpl, err = ParseSandboxed("body", `{{ range .Items }}0123456789{{ end }}`, Limits{})
+	require.NoError(t, err)
+	_, err = Execute(tmpl, data, Limits{MaxOutput: 1000})
+	assert.ErrorIs(t, err, ErrOutputTooLarge)
+	out, err = Execute(tmpl, data, Limits{MaxOutput: 20000})
+	require.NoError(t, err)
+	assert.Len(t, out, 20000)
+
+	// Widths would allocate beyond the cap before anything is written
+	tmpl, err = ParseSandboxed("body", `{{ printf "%999999999d" 1 }}`, Limits{})
+	require.NoError(t, err)
+	_, err = Execute(tmpl, data, Limits{})
+	assert.ErrorContains(t, err, "exceeds the 65536 byte output limit")
+	tmpl, err = ParseSandboxed("body", `{{ printf "%*d" 10 1 }}`, Limits{})
+	require.NoError(t, err)
+	_, err = Execute(tmpl, data, Limits{})
+	assert.ErrorContains(t, err, "not allowed")
+
+	// Loops producing no output run into the timeout
+	tmpl, err = ParseSandboxed("body", `{{ range .Items }}{{ range $.Items }}{{ end }}{{ end }}`, Limits{})
+	require.NoError(t, err)
+	start := time.Now()
+	_, err = Execute(tmpl, data, Limits{Timeout: 10 * time.Millisecond})
+	assert.ErrorIs(t, err, ErrTimeout)
+	assert.Less(t, time.Since(start), time.Second)
+}
+
+func TestLimitedWriter(t *testing.T) {
+	w := &limitedWriter{max: 5}
+	_, err := w.Write([]byte("abc"))
+	require.NoError(t, err)
+	_, err = w.Write([]byte("def"))
+	assert.ErrorIs(t, err, ErrOutputTooLarge)
+	_, err = w.Write([]byte("g"))
+	assert.ErrorIs(t, err, ErrOutputTooLarge, "failures stick")
+	assert.Equal(t, "abc", w.String())
+}
@@ -0,0 +1,173 @@
+// File: internal/tmplfunc/tmplfunc.go
+// Brief: Helper functions for notification templates
+// Detailed: Provides the functions registered into every notification template (markdown, humanizeBytes, humanizeDuration, round, upper) and their documentation, so custom templates get readable values without formatting them by hand. Helpers accept any numeric type so template values need no conversion.
+// Author: drama.lin@aver.com
+// Date: 2026-10-14
+
+// Package tmplfunc provides helper functions for notification templates.
+package tmplfunc
+
+import (
+	"fmt"
+	"html/template"
+	"math"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// Helper documents one template function
+type Helper struct {
+	Name        string `json:"name"`
+	Signature   string `json:"signature"`
+	Description string `json:"description"`
+	Example     string `json:"example"`
+	Output      string `json:"output"` // What the example renders
+}
+
+// Helpers lists the template functions, by name
+var Helpers = []Helper{
+	{
+		Name:        "humanizeBytes",
+		Signature:   "humanizeBytes NUMBER",
+		Description: "Formats a byte count in binary units.",
+		Example:     "{{ humanizeBytes 1610612736 }}",
+		Output:      "1.5 GiB",
+	},
+	{
+		Name:        "humanizeDuration",
+		Signature:   "humanizeDuration DURATION|SECONDS",
+		Description: "Formats a duration, or a number of seconds, with its two largest units.",
+		Example:     "{{ humanizeDuration 93784 }}",
+		Output:      "1d 2h",
+	},
+	{
+		Name:        "markdown",
+		Signature:   "markdown STRING",
+		Description: "Renders Markdown as HTML: headings, paragraphs, bullet and numbered lists, fenced code blocks, `code`, **bold**, *italic* and [links](https://example.com). Raw HTML in the input is escaped.",
+		Example:     "{{ markdown \"**Disk** full on `/var`\" }}",
+		Output:      "<p><strong>Disk</strong> full on <code>/var</code></p>",
+	},
+	{
+		Name:        "round",
+		Signature:   "round NUMBER [PLACES]",
+		Description: "Rounds a number half away from zero to PLACES decimals (default 0).",
+		Example:     "{{ round .CurrentValue 1 }}",
+		Output:      "87.3",
+	},
+	{
+		Name:        "upper",
+		Signature:   "upper STRING",
+		Description: "Converts text to upper case.",
+		Example:     "{{ upper .Alert.Severity }}",
+		Output:      "CRITICAL",
+	},
+}
+
+// Funcs returns the template functions to register with template.Funcs
+func Funcs() template.FuncMap {
+	return template.FuncMap{
+		"humanizeBytes":    HumanizeBytes,
+		"humanizeDuration": HumanizeDuration,
+		"markdown":         Markdown,
+		"round":            Round,
+		"upper":            Upper,
+	}
+}
+
+// toFloat converts any numeric value, or a time.Duration in seconds
+func toFloat(v any) (float64, error) {
+	if d, ok := v.(time.Duration); ok {
+		return d.Seconds(), nil
+	}
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(rv.Int()), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return float64(rv.Uint()), nil
+	case reflect.Float32, reflect.Float64:
+		return rv.Float(), nil
+	}
+	return 0, fmt.Errorf("expected a number, got %T", v)
+}
+
+// HumanizeBytes formats a byte count in binary units, e.g. "1.5 GiB"
+func HumanizeBytes(v any) (string, error) {
+	b, err := toFloat(v)
+	if err != nil {
+		return "", err
+	}
+	const unit = 1024
+	abs := math.Abs(b)
+	if abs < unit {
+		return fmt.Sprintf("%d B", int64(b)), nil
+	}
+	exp := 0
+	for abs >= unit*unit && exp < 5 {
+		abs /= unit
+		b /= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", b/unit, "KMGTPE"[exp]), nil
+}
+
+// HumanizeDuration formats a time.Duration, or a number of seconds, with its
+// two largest units, e.g. "1d 2h" or "45s"
+func HumanizeDuration(v any) (string, error) {
+	secs, err := toFloat(v)
+	if err != nil {
+		return "", err
+	}
+	sign := ""
+	if secs < 0 {
+		sign, secs = "-", -secs
+	}
+	if secs < 1 {
+		if secs == 0 {
+			return "0s", nil
+		}
+		return fmt.Sprintf("%s%dms", sign, int64(math.Round(secs*1000))), nil
+	}
+	units := []struct {
+		suffix string
+		size   int64
+	}{{"d", 86400}, {"h", 3600}, {"m", 60}, {"s", 1}}
+	rest := int64(secs)
+	for i, u := range units {
+		n := rest / u.size
+		if n == 0 {
+			continue
+		}
+		out := fmt.Sprintf("%s%d%s", sign, n, u.suffix)
+		if i+1 < len(units) {
+			if m := rest % u.size / units[i+1].size; m > 0 {
+				out += fmt.Sprintf(" %d%s", m, units[i+1].suffix)
+			}
+		}
+		return out, nil
+	}
+	return "0s", nil
+}
+
+// Round rounds v half away from zero to the given decimal places (default 0)
+func Round(v any, places ...int) (float64, error) {
+	f, err := toFloat(v)
+	if err != nil {
+		return 0, err
+	}
+	if len(places) > 1 {
+		return 0, fmt.Errorf("expected at most one number of places, got %d", len(places))
+	}
+	p := 0
+	if len(places) == 1 {
+		p = places[0]
+	}
+	scale := math.Pow(10, float64(p))
+	return math.Round(f*scale) / scale, nil
+}
+
+// Upper converts text, or any value's string form, to upper case
+func Upper(v any) string {
+	return strings.ToUpper(fmt.Sprint(v))
+}
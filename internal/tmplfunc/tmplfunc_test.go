@@ -0,0 +1,91 @@
+package tmplfunc
+
+import (
+	"bytes"
+	"html/template"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHumanizeBytes(t *testing.T) {
+	for in, want := range map[any]string{
+		512:                "512 B",
+		uint64(1536):       "1.5 KiB",
+		1610612736.0:       "1.5 GiB",
+		int64(5 << 40):     "5.0 TiB",
+		-2048:              "-2.0 KiB",
+		float32(1048576.0): "1.0 MiB",
+	} {
+		got, err := HumanizeBytes(in)
+		require.NoError(t, err)
+		assert.Equal(t, want, got, "%v", in)
+	}
+	_, err := HumanizeBytes("lots")
+	assert.Error(t, err)
+}
+
+func TestHumanizeDuration(t *testing.T) {
+	for in, want := range map[any]string{
+		0:                          "0s",
+		45:                         "45s",
+		93784:                      "1d 2h",
+		3600:                       "1h",
+		90 * time.Second:           "1m 30s",
+		250 * time.Millisecond:     "250ms",
+		-7200.0:                    "-2h",
+		26*time.Hour + time.Minute: "1d 2h",
+	} {
+		got, err := HumanizeDuration(in)
+		require.NoError(t, err)
+		assert.Equal(t, want, got, "%v", in)
+	}
+}
+
+func TestRound(t *testing.T) {
+	got, err := Round(87.25, 1)
+	require.NoError(t, err)
+	assert.Equal(t, 87.3, got)
+	got, err = Round(-2.5)
+	require.NoError(t, err)
+	assert.Equal(t, -3.0, got)
+	_, err = Round(1.0, 1, 2)
+	assert.Error(t, err)
+}
+
+func TestMarkdown(t *testing.T) {
+	in := "# Disk *full*\n\nThe `/var` partition is at **95%**.\nSee [runbook](https://wiki/disk) or [this](javascript:alert(1)).\n\n- clean logs\n- grow volume\n\n1. first\n2. second\nafter <script>\n\n```\nrm -rf <tmp>\n```\nkeep usage_percent and _this_"
+	assert.Equal(t, template.HTML(`<h1>Disk <em>full</em></h1>
+<p>The <code>/var</code> partition is at <strong>95%</strong>.
+See <a href="https://wiki/disk">runbook</a> or this).</p>
+<ul>
+<li>clean logs</li>
+<li>grow volume</li>
+</ul>
+<ol>
+<li>first</li>
+<li>second</li>
+</ol>
+<p>after &lt;script&gt;</p>
+<pre><code>rm -rf &lt;tmp&gt;</code></pre>
+<p>keep usage_percent and <em>this</em></p>`), Markdown(in))
+	assert.Equal(t, template.HTML("<p>a x `b</p>"), Markdown("a [x](//evil.com) `b"), "protocol-relative links lose their target")
+}
+
+func TestFuncs(t *testing.T) {
+	names := make([]string, 0, len(Helpers))
+	for _, h := range Helpers {
+		names = append(names, h.Name)
+		assert.Contains(t, Funcs(), h.Name)
+	}
+	assert.Len(t, Funcs(), len(Helpers), "every function is documented")
+	assert.IsIncreasing(t, names)
+
+	tmpl := template.Must(template.New("body").Funcs(Funcs()).Parse(
+		`{{ upper .Name }} {{ humanizeBytes .Free }} free for {{ humanizeDuration .Since }} ({{ round .Used 1 }}%) {{ markdown .Description }}`))
+	var buf bytes.Buffer
+	require.NoError(t, tmpl.Execute(&buf, map[string]any{"Name": "disk", "Free": uint64(1 << 30), "Since": 2 * time.Hour, "Used": 97.46, "Description": "**on fire** <b>"}))
+	assert.Equal(t, "DISK 1.0 GiB free for 2h (97.5%) <p><strong>on fire</strong> &lt;b&gt;</p>", buf.String())
+}
@@ -0,0 +1,184 @@
+// File: internal/update/apply.go
+// Brief: Self-update of single-binary deployments
+// Detailed: Downloads the binary of the running platform attached to a release, verifies it against the SHA-256 checksum published with the release, and swaps it in place of the running executable, keeping the replaced binary next to it for a rollback. The running process is not restarted; the service manager supervising Argus (e.g. systemd) starts the new binary on its next restart.
+// Author: drama.lin@aver.com
+// Date: 2026-10-14
+
+package update
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// MaxBinarySize bounds the size of a downloaded binary
+const MaxBinarySize = 512 << 20
+
+// ChecksumsAsset is the release asset listing the SHA-256 of every binary,
+// in the format of sha256sum, as an alternative to one <binary>.sha256 asset
+// per binary
+const ChecksumsAsset = "checksums.txt"
+
+// AssetName returns the name of the release binary of a platform, e.g.
+// argus_linux_amd64
+func AssetName(goos, goarch string) string {
+	return fmt.Sprintf("argus_%s_%s", goos, goarch)
+}
+
+// Apply replaces the executable at exe with the release binary named asset,
+// after verifying its checksum. The replaced binary is kept as exe.old, whose
+// path is returned.
+func (c *Checker) Apply(ctx context.Context, release Release, asset, exe string) (string, error) {
+	binary, checksum := findAsset(release.Assets, asset), findAsset(release.Assets, asset+".sha256")
+	if binary == nil {
+		return "", fmt.Errorf("release %s has no %s binary", release.Version, asset)
+	}
+	if checksum == nil {
+		checksum = findAsset(release.Assets, ChecksumsAsset)
+	}
+	if checksum == nil {
+		return "", fmt.Errorf("release %s publishes no checksum of %s", release.Version, asset)
+	}
+
+	// No client timeout: a slow download is bounded by ctx instead
+	client := &http.Client{Transport: c.config.Transport}
+	sums, err := c.download(ctx, client, checksum.URL, 1<<20)
+	if err != nil {
+		return "", fmt.Errorf("failed to download checksum: %w", err)
+	}
+	want, err := parseChecksum(sums, asset)
+	if err != nil {
+		return "", err
+	}
+
+	info, err := os.Stat(exe)
+	if err != nil {
+		return "", err
+	}
+	tmp := exe + ".new"
+	if err := c.downloadFile(ctx, client, binary.URL, tmp, info.Mode().Perm(), want); err != nil {
+		os.Remove(tmp)
+		return "", err
+	}
+
+	backup := exe + ".old"
+	if err := os.Rename(exe, backup); err != nil {
+		os.Remove(tmp)
+		return "", fmt.Errorf("failed to back up %s: %w", exe, err)
+	}
+	if err := os.Rename(tmp, exe); err != nil {
+		if restoreErr := os.Rename(backup, exe); restoreErr != nil {
+			return "", fmt.Errorf("failed to install %s: %w (restoring the backup failed: %v)", exe, err, restoreErr)
+		}
+		os.Remove(tmp)
+		return "", fmt.Errorf("failed to install %s: %w", exe, err)
+	}
+	return backup, nil
+}
+
+func findAsset(assets []Asset, name string) *Asset {
+	for i := range assets {
+		if assets[i].Name == name {
+			return &assets[i]
+		}
+	}
+	return nil
+}
+
+// parseChecksum returns the checksum of asset from a .sha256 asset (the
+// checksum alone or a sha256sum line) or a checksums list
+func parseChecksum(data []byte, asset string) ([]byte, error) {
+	scanner := bufio.NewScanner(strings.NewReader(string(data)))
+	lines := 0
+	var only []byte
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 {
+			continue
+		}
+		lines++
+		sum, err := hex.DecodeString(fields[0])
+		if err != nil || len(sum) != sha256.Size {
+			continue
+		}
+		switch {
+		case len(fields) == 1:
+			only = sum
+		case strings.TrimPrefix(fields[1], "*") == asset:
+			return sum, nil
+		}
+	}
+	if only != nil && lines == 1 {
+		return only, nil
+	}
+	return nil, fmt.Errorf("no SHA-256 checksum of %s in the release checksums", asset)
+}
+
+// download reads a small file into memory
+func (c *Checker) download(ctx context.Context, client *http.Client, url string, limit int64) ([]byte, error) {
+	body, err := c.get(ctx, client, url)
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+	return io.ReadAll(io.LimitReader(body, limit))
+}
+
+// downloadFile writes url to path with perm and verifies its SHA-256
+func (c *Checker) downloadFile(ctx context.Context, client *http.Client, url, path string, perm os.FileMode, want []byte) error {
+	body, err := c.get(ctx, client, url)
+	if err != nil {
+		return fmt.Errorf("failed to download %s: %w", filepath.Base(path), err)
+	}
+	defer body.Close()
+
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, perm)
+	if err != nil {
+		return err
+	}
+	hash := sha256.New()
+	n, err := io.Copy(io.MultiWriter(file, hash), io.LimitReader(body, MaxBinarySize+1))
+	if err == nil {
+		err = file.Sync()
+	}
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	switch {
+	case err != nil:
+		return fmt.Errorf("failed to download binary: %w", err)
+	case n > MaxBinarySize:
+		return fmt.Errorf("binary is larger than %d bytes", MaxBinarySize)
+	case !bytes.Equal(hash.Sum(nil), want):
+		return errors.New("downloaded binary does not match the release checksum")
+	}
+	// OpenFile applies the umask; the binary keeps the mode of the one it replaces
+	return os.Chmod(path, perm)
+}
+
+func (c *Checker) get(ctx context.Context, client *http.Client, url string) (io.ReadCloser, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", "Argus/"+c.config.Current)
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, fmt.Errorf("HTTP %d", resp.StatusCode)
+	}
+	return resp.Body, nil
+}
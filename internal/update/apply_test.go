@@ -0,0 +1,116 @@
+package update
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// releaseServer serves the files of a release and returns the assets of
+// the named ones
+func releaseServer(t *testing.T, files map[string]string) func(names ...string) []Asset {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		content, ok := files[r.URL.Path[1:]]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(content))
+	}))
+	t.Cleanup(server.Close)
+	assets := func(names ...string) []Asset {
+		list := make([]Asset, len(names))
+		for i, name := range names {
+			list[i] = Asset{Name: name, URL: server.URL + "/" + name}
+		}
+		return list
+	}
+	return assets
+}
+
+func sha256Hex(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:])
+}
+
+func installed(t *testing.T) string {
+	exe := filepath.Join(t.TempDir(), "argus")
+	require.NoError(t, os.WriteFile(exe, []byte("old binary"), 0o750))
+	return exe
+}
+
+func TestApply(t *testing.T) {
+	asset := AssetName("linux", "amd64")
+	assets := releaseServer(t, map[string]string{
+		asset:          "new binary",
+		ChecksumsAsset: sha256Hex("other") + "  argus_darwin_arm64\n" + sha256Hex("new binary") + " *" + asset + "\n",
+	})
+	exe := installed(t)
+
+	checker := NewChecker(Config{Current: "v1.0.0"})
+	backup, err := checker.Apply(context.Background(), Release{Version: "v1.1.0", Assets: assets(asset, ChecksumsAsset)}, asset, exe)
+	require.NoError(t, err)
+	assert.Equal(t, exe+".old", backup)
+
+	data, err := os.ReadFile(exe)
+	require.NoError(t, err)
+	assert.Equal(t, "new binary", string(data))
+	info, err := os.Stat(exe)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o750), info.Mode().Perm(), "the mode of the replaced binary is kept")
+	data, err = os.ReadFile(backup)
+	require.NoError(t, err)
+	assert.Equal(t, "old binary", string(data))
+}
+
+func TestApply_ChecksumMismatch(t *testing.T) {
+	asset := AssetName("linux", "arm64")
+	assets := releaseServer(t, map[string]string{
+		asset:             "tampered binary",
+		asset + ".sha256": sha256Hex("new binary") + "\n",
+	})
+	exe := installed(t)
+
+	_, err := NewChecker(Config{}).Apply(context.Background(), Release{Version: "v1.1.0", Assets: assets(asset, asset+".sha256")}, asset, exe)
+	assert.ErrorContains(t, err, "does not match the release checksum")
+
+	data, err := os.ReadFile(exe)
+	require.NoError(t, err)
+	assert.Equal(t, "old binary", string(data), "the installed binary is untouched")
+	assert.NoFileExists(t, exe+".new")
+	assert.NoFileExists(t, exe+".old")
+}
+
+func TestApply_MissingAssets(t *testing.T) {
+	asset := AssetName("linux", "amd64")
+	assets := releaseServer(t, map[string]string{asset: "new binary"})
+	exe := installed(t)
+	checker := NewChecker(Config{})
+
+	_, err := checker.Apply(context.Background(), Release{Version: "v1.1.0", Assets: assets("argus_windows_amd64.exe")}, asset, exe)
+	assert.ErrorContains(t, err, "has no argus_linux_amd64 binary")
+
+	_, err = checker.Apply(context.Background(), Release{Version: "v1.1.0", Assets: assets(asset)}, asset, exe)
+	assert.ErrorContains(t, err, "publishes no checksum")
+}
+
+func TestParseChecksum(t *testing.T) {
+	sum := sha256Hex("x")
+	_, err := parseChecksum([]byte(sum+"  argus_linux_386\n"), "argus_linux_amd64")
+	assert.Error(t, err, "a list without the asset")
+
+	got, err := parseChecksum([]byte(sum+"\n"), "argus_linux_amd64")
+	require.NoError(t, err)
+	assert.Equal(t, sum, hex.EncodeToString(got))
+
+	_, err = parseChecksum([]byte("not-a-checksum\n"), "argus_linux_amd64")
+	assert.Error(t, err)
+}
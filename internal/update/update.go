@@ -0,0 +1,185 @@
+// File: internal/update/update.go
+// Brief: Checker comparing the running version with the latest release
+// Detailed: Periodically fetches the latest release from a configurable endpoint answering in the format of the GitHub releases API (tag_name, html_url, published_at and assets), keeps the outcome of the last check for the version API, and calls a handler once for each newer release found, e.g. to raise an in-app notification.
+// Author: drama.lin@aver.com
+// Date: 2026-10-14
+
+package update
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"argus/internal/clock"
+)
+
+// Checker defaults
+const (
+	DefaultInterval = 24 * time.Hour
+	DefaultTimeout  = 30 * time.Second
+)
+
+// Config configures a Checker
+type Config struct {
+	URL       string            // Latest release endpoint, e.g. https://api.github.com/repos/<owner>/<repo>/releases/latest
+	Current   string            // Version of the running binary
+	Interval  time.Duration     // Between checks (0 uses DefaultInterval)
+	Timeout   time.Duration     // Per request (0 uses DefaultTimeout)
+	Transport http.RoundTripper // nil uses http.DefaultTransport
+	Clock     clock.Clock       // nil uses the real clock
+}
+
+// Asset is a file attached to a release
+type Asset struct {
+	Name string `json:"name"`
+	URL  string `json:"browser_download_url"`
+}
+
+// Release is a published release
+type Release struct {
+	Version     string    `json:"tag_name"`
+	URL         string    `json:"html_url"`
+	PublishedAt time.Time `json:"published_at"`
+	Assets      []Asset   `json:"assets"`
+}
+
+// Status is the outcome of the last check
+type Status struct {
+	Current         string     `json:"current"`
+	Latest          string     `json:"latest,omitempty"`
+	UpdateAvailable bool       `json:"update_available"`
+	ReleaseURL      string     `json:"release_url,omitempty"`
+	PublishedAt     *time.Time `json:"published_at,omitempty"`
+	CheckedAt       *time.Time `json:"checked_at,omitempty"` // Unset before the first check
+	Error           string     `json:"error,omitempty"`      // Of the last check; the release of the previous one is kept
+}
+
+// Checker looks for newer releases
+type Checker struct {
+	config   Config
+	client   *http.Client
+	clock    clock.Clock
+	onUpdate func(Status)
+
+	mu       sync.Mutex
+	status   Status
+	notified string // Latest version the handler was called for
+}
+
+// NewChecker creates a checker; Run starts the periodic checks
+func NewChecker(config Config) *Checker {
+	if config.Interval <= 0 {
+		config.Interval = DefaultInterval
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = DefaultTimeout
+	}
+	return &Checker{
+		config: config,
+		client: &http.Client{Timeout: config.Timeout, Transport: config.Transport},
+		clock:  clock.OrReal(config.Clock),
+		status: Status{Current: config.Current},
+	}
+}
+
+// OnUpdate sets the handler called once for each newer release found
+func (c *Checker) OnUpdate(handler func(Status)) {
+	c.onUpdate = handler
+}
+
+// Status returns the outcome of the last check
+func (c *Checker) Status() Status {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.status
+}
+
+// Run checks at once and then every interval until ctx is done
+func (c *Checker) Run(ctx context.Context) {
+	ticker := c.clock.NewTicker(c.config.Interval)
+	defer ticker.Stop()
+	for {
+		if _, err := c.Check(ctx); err != nil && ctx.Err() == nil {
+			slog.Warn("Update check failed", "url", c.config.URL, "error", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C():
+		}
+	}
+}
+
+// Check fetches the latest release and updates the status
+func (c *Checker) Check(ctx context.Context) (Status, error) {
+	release, err := c.Latest(ctx)
+	now := c.clock.Now().UTC()
+
+	c.mu.Lock()
+	c.status.CheckedAt = &now
+	if err != nil {
+		c.status.Error = err.Error()
+		status := c.status
+		c.mu.Unlock()
+		return status, err
+	}
+	c.status.Error = ""
+	c.status.Latest = release.Version
+	c.status.ReleaseURL = release.URL
+	c.status.PublishedAt = nil
+	if !release.PublishedAt.IsZero() {
+		published := release.PublishedAt.UTC()
+		c.status.PublishedAt = &published
+	}
+	c.status.UpdateAvailable = Newer(release.Version, c.config.Current)
+	status := c.status
+	notify := status.UpdateAvailable && c.notified != release.Version
+	if notify {
+		c.notified = release.Version
+	}
+	c.mu.Unlock()
+
+	if notify {
+		slog.Info("A newer Argus release is available", "current", status.Current, "latest", status.Latest, "url", status.ReleaseURL)
+		if c.onUpdate != nil {
+			c.onUpdate(status)
+		}
+	}
+	return status, nil
+}
+
+// Latest fetches the latest release from the endpoint
+func (c *Checker) Latest(ctx context.Context) (Release, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.config.URL, nil)
+	if err != nil {
+		return Release{}, err
+	}
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("User-Agent", "Argus/"+c.config.Current)
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return Release{}, fmt.Errorf("release request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		detail, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return Release{}, fmt.Errorf("release endpoint returned HTTP %d: %s", resp.StatusCode, strings.TrimSpace(string(detail)))
+	}
+	var release Release
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 4<<20)).Decode(&release); err != nil {
+		return Release{}, fmt.Errorf("invalid release: %w", err)
+	}
+	if release.Version == "" {
+		return Release{}, errors.New("invalid release: no tag_name")
+	}
+	return release, nil
+}
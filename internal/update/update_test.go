@@ -0,0 +1,88 @@
+package update
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"argus/internal/clock"
+)
+
+func TestChecker_Check(t *testing.T) {
+	var tag atomic.Value
+	tag.Store("v1.3.0")
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Argus/v1.2.0", r.Header.Get("User-Agent"))
+		w.Write([]byte(`{"tag_name": "` + tag.Load().(string) + `", "html_url": "https://example.com/releases/v1.3.0", "published_at": "2026-10-01T12:00:00Z"}`))
+	}))
+	defer server.Close()
+
+	now := time.Date(2026, 10, 14, 8, 0, 0, 0, time.UTC)
+	checker := NewChecker(Config{URL: server.URL, Current: "v1.2.0", Clock: clock.NewFake(now)})
+	assert.Nil(t, checker.Status().CheckedAt)
+
+	var notified []string
+	checker.OnUpdate(func(s Status) { notified = append(notified, s.Latest) })
+
+	status, err := checker.Check(context.Background())
+	require.NoError(t, err)
+	assert.True(t, status.UpdateAvailable)
+	assert.Equal(t, "v1.3.0", status.Latest)
+	assert.Equal(t, "https://example.com/releases/v1.3.0", status.ReleaseURL)
+	assert.Equal(t, time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC), *status.PublishedAt)
+	assert.Equal(t, now, *status.CheckedAt)
+	assert.Equal(t, status, checker.Status())
+
+	_, err = checker.Check(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []string{"v1.3.0"}, notified, "a release is notified once")
+
+	tag.Store("v1.4.0")
+	_, err = checker.Check(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []string{"v1.3.0", "v1.4.0"}, notified)
+}
+
+func TestChecker_CheckFailureKeepsRelease(t *testing.T) {
+	fail := atomic.Bool{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if fail.Load() {
+			http.Error(w, "rate limited", http.StatusForbidden)
+			return
+		}
+		w.Write([]byte(`{"tag_name": "v1.2.0"}`))
+	}))
+	defer server.Close()
+
+	checker := NewChecker(Config{URL: server.URL, Current: "v1.2.0"})
+	status, err := checker.Check(context.Background())
+	require.NoError(t, err)
+	assert.False(t, status.UpdateAvailable, "the running version is the latest")
+
+	fail.Store(true)
+	status, err = checker.Check(context.Background())
+	assert.ErrorContains(t, err, "HTTP 403: rate limited")
+	assert.Equal(t, "v1.2.0", status.Latest)
+	assert.Contains(t, status.Error, "HTTP 403")
+
+	fail.Store(false)
+	status, err = checker.Check(context.Background())
+	require.NoError(t, err)
+	assert.Empty(t, status.Error)
+}
+
+func TestChecker_InvalidRelease(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"name": "no tag"}`))
+	}))
+	defer server.Close()
+
+	_, err := NewChecker(Config{URL: server.URL}).Check(context.Background())
+	assert.ErrorContains(t, err, "no tag_name")
+}
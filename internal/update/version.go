@@ -0,0 +1,110 @@
+// File: internal/update/version.go
+// Brief: Release version comparison
+// Detailed: Parses release versions of the form v1.2.3 or 1.2.3-rc.1 and orders them as semantic versions, so the running build can be compared with the latest release. Development builds, whose version is not a release (e.g. "(devel)" or "unknown"), are never reported as outdated.
+// Author: drama.lin@aver.com
+// Date: 2026-10-14
+
+package update
+
+import (
+	"strconv"
+	"strings"
+)
+
+// version is a parsed release version
+type version struct {
+	core       [3]int
+	prerelease []string // Dot-separated identifiers after '-'; empty for a release
+}
+
+// parseVersion parses a release version, with or without a leading v and
+// build metadata (+...). Missing minor and patch numbers are 0.
+func parseVersion(s string) (version, bool) {
+	s = strings.TrimPrefix(strings.TrimSpace(s), "v")
+	s, _, _ = strings.Cut(s, "+")
+	s, pre, hasPre := strings.Cut(s, "-")
+
+	var v version
+	parts := strings.Split(s, ".")
+	if len(parts) > 3 {
+		return version{}, false
+	}
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 {
+			return version{}, false
+		}
+		v.core[i] = n
+	}
+	if hasPre {
+		if pre == "" {
+			return version{}, false
+		}
+		v.prerelease = strings.Split(pre, ".")
+	}
+	return v, true
+}
+
+// compare returns -1, 0 or 1 as v is older than, equal to or newer than o.
+// A prerelease is older than its release.
+func (v version) compare(o version) int {
+	for i := range v.core {
+		if c := compareInt(v.core[i], o.core[i]); c != 0 {
+			return c
+		}
+	}
+	switch {
+	case len(v.prerelease) == 0 && len(o.prerelease) == 0:
+		return 0
+	case len(v.prerelease) == 0:
+		return 1
+	case len(o.prerelease) == 0:
+		return -1
+	}
+	for i := 0; i < len(v.prerelease) && i < len(o.prerelease); i++ {
+		if c := compareIdentifier(v.prerelease[i], o.prerelease[i]); c != 0 {
+			return c
+		}
+	}
+	return compareInt(len(v.prerelease), len(o.prerelease))
+}
+
+// compareIdentifier orders prerelease identifiers: numbers numerically and
+// before words, words lexically
+func compareIdentifier(a, b string) int {
+	na, errA := strconv.Atoi(a)
+	nb, errB := strconv.Atoi(b)
+	switch {
+	case errA == nil && errB == nil:
+		return compareInt(na, nb)
+	case errA == nil:
+		return -1
+	case errB == nil:
+		return 1
+	}
+	return strings.Compare(a, b)
+}
+
+func compareInt(a, b int) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+// Newer reports whether latest is a newer release than current. It is false
+// when either is not a release version, e.g. for development builds.
+func Newer(latest, current string) bool {
+	l, ok := parseVersion(latest)
+	if !ok {
+		return false
+	}
+	c, ok := parseVersion(current)
+	if !ok {
+		return false
+	}
+	return l.compare(c) > 0
+}
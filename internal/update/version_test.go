@@ -0,0 +1,30 @@
+package update
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewer(t *testing.T) {
+	tests := []struct {
+		latest, current string
+		want            bool
+	}{
+		{"v1.2.0", "v1.1.9", true},
+		{"v1.10.0", "v1.9.0", true},
+		{"1.2", "v1.2.0", false},
+		{"v1.2.0", "v1.2.0-rc.1", true},
+		{"v1.2.0-rc.2", "v1.2.0-rc.10", false},
+		{"v1.2.0-rc.1", "v1.2.0-beta", true},
+		{"v1.2.0-rc.1.1", "v1.2.0-rc.1", true},
+		{"v1.2.0+build.5", "v1.2.0", false},
+		{"v1.1.0", "v1.2.0", false},
+		{"v2.0.0", "(devel)", false},
+		{"v2.0.0", "unknown", false},
+		{"latest", "v1.0.0", false},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, Newer(tt.latest, tt.current), "%s over %s", tt.latest, tt.current)
+	}
+}
@@ -0,0 +1,36 @@
+// File: internal/usage/middleware.go
+// Brief: Gin middleware feeding the API usage tracker
+// Detailed: Times every /api request and records it under its route group and caller token once the handler has written the status.
+// Author: drama.lin@aver.com
+// Date: 2026-10-14
+
+package usage
+
+import (
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// UnmatchedNamespace groups requests to paths without a registered route, so
+// scanners cannot create a namespace per probed path
+const UnmatchedNamespace = "unmatched"
+
+// Middleware records every API request in the tracker
+func (t *Tracker) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		namespace := Namespace(c.Request.URL.Path)
+		if namespace == "" {
+			c.Next()
+			return
+		}
+
+		start := time.Now()
+		c.Next()
+
+		if c.FullPath() == "" {
+			namespace = UnmatchedNamespace
+		}
+		t.Record(namespace, TokenID(c.Request), c.Writer.Status(), time.Since(start))
+	}
+}
@@ -0,0 +1,347 @@
+// File: internal/usage/usage.go
+// Brief: API usage metrics per namespace and token
+// Detailed: Counts API requests by status class and records latency histograms for every namespace (API route group) and caller token, keeps a rolling window for error-rate alerting, and renders both as JSON stats and Prometheus text exposition.
+// Author: drama.lin@aver.com
+// Date: 2026-10-14
+
+// Package usage tracks API request counts, errors and latencies per caller.
+package usage
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"argus/internal/clock"
+)
+
+// Keys used when a request carries no token or the key limit is reached
+const (
+	AnonymousToken = "anonymous"
+	OverflowKey    = "other"
+)
+
+// LatencyBuckets are the histogram upper bounds in seconds (Prometheus defaults)
+var LatencyBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// slotDuration is the granularity of the rolling window
+const slotDuration = time.Minute
+
+// Config holds configuration for the usage tracker
+type Config struct {
+	Window  time.Duration // Rolling window for recent stats and alerting
+	MaxKeys int           // Namespace/token pairs tracked before new ones fold into "other" (0 means unlimited)
+	Clock   clock.Clock   // Time source (nil uses the real clock)
+}
+
+// DefaultConfig returns default configuration for the usage tracker
+func DefaultConfig() Config {
+	return Config{
+		Window:  5 * time.Minute,
+		MaxKeys: 1000,
+	}
+}
+
+// Key identifies a caller of the API
+type Key struct {
+	Namespace string `json:"namespace"`
+	Token     string `json:"token"`
+}
+
+// WindowStats are request totals over the rolling window
+type WindowStats struct {
+	Seconds      float64 `json:"seconds"` // Window length
+	Requests     uint64  `json:"requests"`
+	ClientErrors uint64  `json:"client_errors"`
+	ServerErrors uint64  `json:"server_errors"`
+	LatencySum   float64 `json:"latency_seconds_sum"`
+}
+
+// ErrorRatePercent returns the share of requests that failed with a 5xx status
+func (w WindowStats) ErrorRatePercent() float64 {
+	if w.Requests == 0 {
+		return 0
+	}
+	return float64(w.ServerErrors) / float64(w.Requests) * 100
+}
+
+// ClientErrorRatePercent returns the share of requests rejected with a 4xx status
+func (w WindowStats) ClientErrorRatePercent() float64 {
+	if w.Requests == 0 {
+		return 0
+	}
+	return float64(w.ClientErrors) / float64(w.Requests) * 100
+}
+
+// RequestsPerMinute returns the average request rate over the window
+func (w WindowStats) RequestsPerMinute() float64 {
+	if w.Seconds <= 0 {
+		return 0
+	}
+	return float64(w.Requests) / w.Seconds * 60
+}
+
+// AvgLatencyMs returns the mean request latency in milliseconds
+func (w WindowStats) AvgLatencyMs() float64 {
+	if w.Requests == 0 {
+		return 0
+	}
+	return w.LatencySum / float64(w.Requests) * 1000
+}
+
+// Stats are the totals since start and over the window for one key
+type Stats struct {
+	Key
+	Requests     uint64            `json:"requests"`
+	Status       map[string]uint64 `json:"status"` // Requests by status class, e.g. "2xx"
+	LatencySum   float64           `json:"latency_seconds_sum"`
+	AvgLatencyMs float64           `json:"avg_latency_ms"`
+	Window       WindowStats       `json:"window"`
+	ErrorRate    float64           `json:"window_error_rate_percent"`
+}
+
+type slot struct {
+	index int64 // Slot number since the Unix epoch
+	WindowStats
+}
+
+type entry struct {
+	classes    [6]uint64 // Index 1-5 for 1xx-5xx, 0 for anything else
+	latencySum float64
+	buckets    []uint64 // Non-cumulative counts per LatencyBuckets bound, plus +Inf
+	slots      []slot
+}
+
+func (e *entry) requests() uint64 {
+	var n uint64
+	for _, c := range e.classes {
+		n += c
+	}
+	return n
+}
+
+// window sums the slots within the rolling window ending at now
+func (e *entry) window(now int64) WindowStats {
+	w := WindowStats{Seconds: (time.Duration(len(e.slots)) * slotDuration).Seconds()}
+	for _, s := range e.slots {
+		if s.index > now-int64(len(e.slots)) {
+			w.Requests += s.Requests
+			w.ClientErrors += s.ClientErrors
+			w.ServerErrors += s.ServerErrors
+			w.LatencySum += s.LatencySum
+		}
+	}
+	return w
+}
+
+// Tracker records API usage
+type Tracker struct {
+	config Config
+	clock  clock.Clock
+	slots  int
+
+	mu      sync.Mutex
+	entries map[Key]*entry
+}
+
+// NewTracker creates an empty usage tracker
+func NewTracker(config Config) *Tracker {
+	if config.Window <= 0 {
+		config.Window = DefaultConfig().Window
+	}
+	slots := int((config.Window + slotDuration - 1) / slotDuration)
+	return &Tracker{
+		config:  config,
+		clock:   clock.OrReal(config.Clock),
+		slots:   slots,
+		entries: make(map[Key]*entry),
+	}
+}
+
+func (t *Tracker) slotIndex() int64 {
+	return t.clock.Now().UnixNano() / int64(slotDuration)
+}
+
+// Record counts one request
+func (t *Tracker) Record(namespace, token string, status int, latency time.Duration) {
+	key := Key{Namespace: namespace, Token: token}
+	now := t.slotIndex()
+	seconds := latency.Seconds()
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	e, ok := t.entries[key]
+	if !ok {
+		if t.config.MaxKeys > 0 && len(t.entries) >= t.config.MaxKeys {
+			key = Key{Namespace: OverflowKey, Token: OverflowKey}
+			e = t.entries[key]
+		}
+		if e == nil {
+			e = &entry{buckets: make([]uint64, len(LatencyBuckets)+1), slots: make([]slot, t.slots)}
+			t.entries[key] = e
+		}
+	}
+
+	class := status / 100
+	if class < 1 || class > 5 {
+		class = 0
+	}
+	e.classes[class]++
+	e.latencySum += seconds
+	e.buckets[sort.SearchFloat64s(LatencyBuckets, seconds)]++
+
+	s := &e.slots[now%int64(len(e.slots))]
+	if s.index != now {
+		*s = slot{index: now}
+	}
+	s.Requests++
+	s.LatencySum += seconds
+	switch class {
+	case 4:
+		s.ClientErrors++
+	case 5:
+		s.ServerErrors++
+	}
+}
+
+// Snapshot returns the stats of every key, ordered by namespace and token
+func (t *Tracker) Snapshot() []Stats {
+	now := t.slotIndex()
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	result := make([]Stats, 0, len(t.entries))
+	for key, e := range t.entries {
+		st := Stats{Key: key, Requests: e.requests(), Status: make(map[string]uint64), LatencySum: e.latencySum, Window: e.window(now)}
+		for class, n := range e.classes {
+			if n > 0 {
+				st.Status[classLabel(class)] = n
+			}
+		}
+		if st.Requests > 0 {
+			st.AvgLatencyMs = st.LatencySum / float64(st.Requests) * 1000
+		}
+		st.ErrorRate = st.Window.ErrorRatePercent()
+		result = append(result, st)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Namespace != result[j].Namespace {
+			return result[i].Namespace < result[j].Namespace
+		}
+		return result[i].Token < result[j].Token
+	})
+	return result
+}
+
+// Window returns the rolling window totals of the keys matching namespace
+// and token; an empty value matches every key
+func (t *Tracker) Window(namespace, token string) WindowStats {
+	now := t.slotIndex()
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	total := WindowStats{Seconds: (time.Duration(t.slots) * slotDuration).Seconds()}
+	for key, e := range t.entries {
+		if (namespace != "" && key.Namespace != namespace) || (token != "" && key.Token != token) {
+			continue
+		}
+		w := e.window(now)
+		total.Requests += w.Requests
+		total.ClientErrors += w.ClientErrors
+		total.ServerErrors += w.ServerErrors
+		total.LatencySum += w.LatencySum
+	}
+	return total
+}
+
+func classLabel(class int) string {
+	if class == 0 {
+		return "other"
+	}
+	return fmt.Sprintf("%dxx", class)
+}
+
+// WritePrometheus writes the request counters and latency histograms in the
+// Prometheus text exposition format
+func (t *Tracker) WritePrometheus(w io.Writer) error {
+	t.mu.Lock()
+	keys := make([]Key, 0, len(t.entries))
+	for key := range t.entries {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].Namespace != keys[j].Namespace {
+			return keys[i].Namespace < keys[j].Namespace
+		}
+		return keys[i].Token < keys[j].Token
+	})
+
+	var b strings.Builder
+	b.WriteString("# HELP argus_api_requests_total API requests by namespace, token and status class.\n")
+	b.WriteString("# TYPE argus_api_requests_total counter\n")
+	for _, key := range keys {
+		for class, n := range t.entries[key].classes {
+			if n > 0 {
+				fmt.Fprintf(&b, "argus_api_requests_total{%s,code=%q} %d\n", labels(key), classLabel(class), n)
+			}
+		}
+	}
+	b.WriteString("# HELP argus_api_request_duration_seconds API request latency by namespace and token.\n")
+	b.WriteString("# TYPE argus_api_request_duration_seconds histogram\n")
+	for _, key := range keys {
+		e := t.entries[key]
+		var cumulative uint64
+		for i, bound := range LatencyBuckets {
+			cumulative += e.buckets[i]
+			fmt.Fprintf(&b, "argus_api_request_duration_seconds_bucket{%s,le=\"%g\"} %d\n", labels(key), bound, cumulative)
+		}
+		cumulative += e.buckets[len(LatencyBuckets)]
+		fmt.Fprintf(&b, "argus_api_request_duration_seconds_bucket{%s,le=\"+Inf\"} %d\n", labels(key), cumulative)
+		fmt.Fprintf(&b, "argus_api_request_duration_seconds_sum{%s} %g\n", labels(key), e.latencySum)
+		fmt.Fprintf(&b, "argus_api_request_duration_seconds_count{%s} %d\n", labels(key), cumulative)
+	}
+	t.mu.Unlock()
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func labels(key Key) string {
+	return fmt.Sprintf("namespace=%q,token=%q", key.Namespace, key.Token)
+}
+
+// TokenID identifies the caller of a request without exposing its credential:
+// the bearer token or X-API-Key header is reduced to a short SHA-256 prefix
+func TokenID(r *http.Request) string {
+	token := r.Header.Get("X-API-Key")
+	if auth := r.Header.Get("Authorization"); token == "" && auth != "" {
+		if scheme, value, ok := strings.Cut(auth, " "); ok && strings.EqualFold(scheme, "Bearer") {
+			token = strings.TrimSpace(value)
+		}
+	}
+	if token == "" {
+		return AnonymousToken
+	}
+	sum := sha256.Sum256([]byte(token))
+	return "tok_" + hex.EncodeToString(sum[:4])
+}
+
+// Namespace returns the API route group of a path, e.g. "alerts" for
+// /api/alerts/123, or "" for paths outside /api
+func Namespace(path string) string {
+	rest, ok := strings.CutPrefix(path, "/api/")
+	if !ok {
+		return ""
+	}
+	namespace, _, _ := strings.Cut(rest, "/")
+	return namespace
+}
@@ -0,0 +1,117 @@
+package usage
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"argus/internal/clock"
+)
+
+var epoch = time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+func TestTracker_RecordAndWindow(t *testing.T) {
+	clk := clock.NewFake(epoch)
+	tracker := NewTracker(Config{Window: 5 * time.Minute, Clock: clk})
+
+	tracker.Record("alerts", "tok_a", 200, 10*time.Millisecond)
+	tracker.Record("alerts", "tok_a", 500, 30*time.Millisecond)
+	tracker.Record("alerts", "tok_b", 404, 20*time.Millisecond)
+	tracker.Record("tasks", "tok_a", 200, time.Millisecond)
+
+	w := tracker.Window("alerts", "")
+	assert.Equal(t, uint64(3), w.Requests)
+	assert.Equal(t, uint64(1), w.ServerErrors)
+	assert.Equal(t, uint64(1), w.ClientErrors)
+	assert.InDelta(t, 33.33, w.ErrorRatePercent(), 0.01)
+	assert.InDelta(t, 20.0, w.AvgLatencyMs(), 0.001)
+	assert.InDelta(t, 0.6, w.RequestsPerMinute(), 0.001)
+	assert.Equal(t, uint64(4), tracker.Window("", "").Requests)
+	assert.Equal(t, uint64(3), tracker.Window("", "tok_a").Requests)
+
+	// Requests age out of the window but stay in the totals
+	clk.Advance(6 * time.Minute)
+	assert.Equal(t, uint64(0), tracker.Window("", "").Requests)
+	assert.Equal(t, 0.0, tracker.Window("", "").ErrorRatePercent())
+
+	stats := tracker.Snapshot()
+	require.Len(t, stats, 3)
+	assert.Equal(t, Key{Namespace: "alerts", Token: "tok_a"}, stats[0].Key)
+	assert.Equal(t, uint64(2), stats[0].Requests)
+	assert.Equal(t, map[string]uint64{"2xx": 1, "5xx": 1}, stats[0].Status)
+	assert.InDelta(t, 20.0, stats[0].AvgLatencyMs, 0.001)
+}
+
+func TestTracker_MaxKeys(t *testing.T) {
+	tracker := NewTracker(Config{MaxKeys: 2})
+	tracker.Record("a", "t", 200, 0)
+	tracker.Record("b", "t", 200, 0)
+	tracker.Record("c", "t", 200, 0)
+	tracker.Record("d", "t", 200, 0)
+	tracker.Record("a", "t", 200, 0)
+
+	stats := tracker.Snapshot()
+	require.Len(t, stats, 3)
+	assert.Equal(t, uint64(2), stats[0].Requests)
+	assert.Equal(t, Key{Namespace: OverflowKey, Token: OverflowKey}, stats[2].Key)
+	assert.Equal(t, uint64(2), stats[2].Requests)
+}
+
+func TestTracker_WritePrometheus(t *testing.T) {
+	tracker := NewTracker(DefaultConfig())
+	tracker.Record("alerts", AnonymousToken, 200, 20*time.Millisecond)
+	tracker.Record("alerts", AnonymousToken, 503, 2*time.Second)
+
+	var b strings.Builder
+	require.NoError(t, tracker.WritePrometheus(&b))
+	out := b.String()
+	assert.Contains(t, out, "# TYPE argus_api_request_duration_seconds histogram\n")
+	assert.Contains(t, out, `argus_api_requests_total{namespace="alerts",token="anonymous",code="5xx"} 1`)
+	assert.Contains(t, out, `argus_api_request_duration_seconds_bucket{namespace="alerts",token="anonymous",le="0.025"} 1`)
+	assert.Contains(t, out, `argus_api_request_duration_seconds_bucket{namespace="alerts",token="anonymous",le="2.5"} 2`)
+	assert.Contains(t, out, `argus_api_request_duration_seconds_bucket{namespace="alerts",token="anonymous",le="+Inf"} 2`)
+	assert.Contains(t, out, `argus_api_request_duration_seconds_count{namespace="alerts",token="anonymous"} 2`)
+}
+
+func TestTokenIDAndNamespace(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/api/alerts", nil)
+	assert.Equal(t, AnonymousToken, TokenID(req))
+
+	req.Header.Set("Authorization", "Bearer secret-token")
+	id := TokenID(req)
+	assert.True(t, strings.HasPrefix(id, "tok_"))
+	assert.NotContains(t, id, "secret")
+
+	keyed := httptest.NewRequest(http.MethodGet, "/api/alerts", nil)
+	keyed.Header.Set("X-API-Key", "secret-token")
+	assert.Equal(t, id, TokenID(keyed), "the same credential maps to the same ID")
+
+	assert.Equal(t, "alerts", Namespace("/api/alerts/123"))
+	assert.Equal(t, "v2", Namespace("/api/v2/silences"))
+	assert.Equal(t, "", Namespace("/assets/app.js"))
+}
+
+func TestMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	tracker := NewTracker(DefaultConfig())
+	router := gin.New()
+	router.Use(tracker.Middleware())
+	router.GET("/api/alerts", func(c *gin.Context) { c.Status(http.StatusOK) })
+	router.GET("/", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	for _, path := range []string{"/api/alerts", "/api/nope/1", "/"} {
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	stats := tracker.Snapshot()
+	require.Len(t, stats, 2)
+	assert.Equal(t, "alerts", stats[0].Namespace)
+	assert.Equal(t, UnmatchedNamespace, stats[1].Namespace)
+	assert.Equal(t, map[string]uint64{"4xx": 1}, stats[1].Status)
+}
@@ -0,0 +1,82 @@
+// File: internal/utils/logsample.go
+// Brief: Deduplication of repeated log messages
+// Detailed: Provides LogSampler, which logs the first occurrence of a recurring message and then at most once per interval, reporting how many occurrences were suppressed in between, so a persistently failing metric source does not log the same error on every tick.
+// Author: drama.lin@aver.com
+// Date: 2026-10-14
+
+package utils
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"argus/internal/clock"
+)
+
+// DefaultLogRepeatInterval is how often a repeating message is logged again
+const DefaultLogRepeatInterval = 5 * time.Minute
+
+// LogSampler logs messages identified by a key at most once per interval
+type LogSampler struct {
+	interval time.Duration
+	clock    clock.Clock
+
+	mu   sync.Mutex
+	seen map[string]*sampledLog
+}
+
+type sampledLog struct {
+	loggedAt   time.Time
+	suppressed int // Occurrences since loggedAt that were not logged
+}
+
+// NewLogSampler creates a sampler logging each key at most once per interval
+// (DefaultLogRepeatInterval when not positive). A nil clock uses the real one.
+func NewLogSampler(interval time.Duration, c clock.Clock) *LogSampler {
+	if interval <= 0 {
+		interval = DefaultLogRepeatInterval
+	}
+	return &LogSampler{
+		interval: interval,
+		clock:    clock.OrReal(c),
+		seen:     make(map[string]*sampledLog),
+	}
+}
+
+// Log logs msg with args at level on the first occurrence of key and once the
+// interval has passed since key was last logged; other occurrences are only
+// counted, and the count is added as "suppressed" to the next logged one. It
+// reports whether the message was logged.
+func (s *LogSampler) Log(level slog.Level, key, msg string, args ...any) bool {
+	now := s.clock.Now()
+
+	s.mu.Lock()
+	entry, ok := s.seen[key]
+	if ok && now.Sub(entry.loggedAt) < s.interval {
+		entry.suppressed++
+		s.mu.Unlock()
+		return false
+	}
+	suppressed := 0
+	if ok {
+		suppressed = entry.suppressed
+	}
+	s.seen[key] = &sampledLog{loggedAt: now}
+	s.mu.Unlock()
+
+	if suppressed > 0 {
+		args = append(args, "suppressed", suppressed)
+	}
+	slog.Log(context.Background(), level, msg, args...)
+	return true
+}
+
+// Reset forgets key, typically once the failure it reports has cleared, so the
+// next occurrence is logged right away
+func (s *LogSampler) Reset(key string) {
+	s.mu.Lock()
+	delete(s.seen, key)
+	s.mu.Unlock()
+}
@@ -0,0 +1,76 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"argus/internal/clock"
+)
+
+func captureLogs(t *testing.T) *bytes.Buffer {
+	var buf bytes.Buffer
+	previous := slog.Default()
+	slog.SetDefault(slog.New(slog.NewJSONHandler(&buf, nil)))
+	t.Cleanup(func() { slog.SetDefault(previous) })
+	return &buf
+}
+
+func logLines(t *testing.T, buf *bytes.Buffer) []map[string]any {
+	var lines []map[string]any
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		if line == "" {
+			continue
+		}
+		var record map[string]any
+		require.NoError(t, json.Unmarshal([]byte(line), &record))
+		lines = append(lines, record)
+	}
+	return lines
+}
+
+func TestLogSampler(t *testing.T) {
+	buf := captureLogs(t)
+	clk := clock.NewFake(time.Date(2026, 10, 14, 8, 0, 0, 0, time.UTC))
+	sampler := NewLogSampler(time.Minute, clk)
+
+	assert.True(t, sampler.Log(slog.LevelError, "cpu", "Failed to collect metrics", "kind", "cpu"))
+	for i := 0; i < 3; i++ {
+		clk.Advance(10 * time.Second)
+		assert.False(t, sampler.Log(slog.LevelError, "cpu", "Failed to collect metrics", "kind", "cpu"))
+	}
+	assert.True(t, sampler.Log(slog.LevelWarn, "disk", "Other key"), "keys are sampled independently")
+
+	clk.Advance(30 * time.Second)
+	assert.True(t, sampler.Log(slog.LevelError, "cpu", "Failed to collect metrics", "kind", "cpu"), "logged again once the interval passed")
+
+	sampler.Reset("cpu")
+	assert.True(t, sampler.Log(slog.LevelError, "cpu", "Failed to collect metrics", "kind", "cpu"), "logged right away after a reset")
+
+	lines := logLines(t, buf)
+	require.Len(t, lines, 4)
+	assert.Equal(t, "ERROR", lines[0]["level"])
+	assert.NotContains(t, lines[0], "suppressed")
+	assert.Equal(t, "WARN", lines[1]["level"])
+	assert.Equal(t, "cpu", lines[2]["kind"])
+	assert.Equal(t, 3.0, lines[2]["suppressed"])
+	assert.NotContains(t, lines[3], "suppressed")
+}
+
+func TestNewLogSampler_DefaultInterval(t *testing.T) {
+	captureLogs(t)
+	clk := clock.NewFake(time.Date(2026, 10, 14, 8, 0, 0, 0, time.UTC))
+	sampler := NewLogSampler(0, clk)
+
+	assert.True(t, sampler.Log(slog.LevelInfo, "k", "msg"))
+	clk.Advance(DefaultLogRepeatInterval - time.Second)
+	assert.False(t, sampler.Log(slog.LevelInfo, "k", "msg"))
+	clk.Advance(time.Second)
+	assert.True(t, sampler.Log(slog.LevelInfo, "k", "msg"))
+}
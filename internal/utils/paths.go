@@ -0,0 +1,46 @@
+// File: internal/utils/paths.go
+// Brief: Filesystem path helpers
+// Detailed: Normalizes sets of storage directories so data under nested or repeated paths is only walked, measured or copied once.
+// Author: drama.lin@aver.com
+// Date: 2026-10-14
+
+package utils
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// OutermostDirs returns dirs as absolute paths, dropping empty entries,
+// duplicates and directories inside another one, in their original order
+func OutermostDirs(dirs []string) []string {
+	abs := make([]string, 0, len(dirs))
+	for _, dir := range dirs {
+		if dir == "" {
+			continue
+		}
+		if p, err := filepath.Abs(dir); err == nil {
+			dir = p
+		}
+		abs = append(abs, filepath.Clean(dir))
+	}
+	var result []string
+	for i, dir := range abs {
+		nested := false
+		for j, other := range abs {
+			if i == j {
+				continue
+			}
+			rel, err := filepath.Rel(other, dir)
+			inside := err == nil && rel != "." && !strings.HasPrefix(rel, "..")
+			if inside || (rel == "." && j < i) {
+				nested = true
+				break
+			}
+		}
+		if !nested {
+			result = append(result, dir)
+		}
+	}
+	return result
+}
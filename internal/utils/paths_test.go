@@ -0,0 +1,22 @@
+package utils
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOutermostDirs(t *testing.T) {
+	base := t.TempDir()
+	other := t.TempDir()
+	dirs := OutermostDirs([]string{
+		base,
+		filepath.Join(base, "alerts"),
+		"",
+		other,
+		base,
+		filepath.Join(base, "..", filepath.Base(base), "tasks"),
+	})
+	assert.Equal(t, []string{base, other}, dirs)
+}
@@ -0,0 +1,393 @@
+// File: internal/widgets/widgets.go
+// Brief: Server-side computation of dashboard widget payloads
+// Detailed: Renders the widgets of a stored dashboard into ready-to-draw payloads: a gauge's latest value with its fill and threshold status, a top list ranked by latest value, a sparkline's values on a fixed grid and the firing alerts, so thin clients such as wall-mounted displays need no query or aggregation logic.
+// Author: drama.lin@aver.com
+// Date: 2026-10-14
+
+package widgets
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"argus/internal/clock"
+	"argus/internal/metrics"
+	"argus/internal/models"
+	"argus/internal/query"
+)
+
+// Defaults of widget settings
+const (
+	DefaultSparklineRange  = time.Hour
+	DefaultSparklinePoints = 60
+	DefaultLimit           = 10
+)
+
+// Latest values are the last step of this window, so a gauge shows the
+// current value without waiting for a full rollup bucket
+const (
+	LatestWindow = 5 * time.Minute
+	LatestStep   = 30 * time.Second
+)
+
+// Gauge statuses
+const (
+	StatusOK       = "ok"
+	StatusWarning  = "warning"
+	StatusCritical = "critical"
+	StatusNoData   = "no_data"
+)
+
+// Alert is one alert offered to alert lists
+type Alert struct {
+	ID             string               `json:"id"`
+	Name           string               `json:"name"`
+	Severity       models.AlertSeverity `json:"severity"`
+	State          models.AlertState    `json:"state"`
+	Value          float64              `json:"value"`
+	Since          *time.Time           `json:"since,omitempty"`
+	Owner          string               `json:"owner,omitempty"`
+	Team           string               `json:"team,omitempty"`
+	GroupID        string               `json:"group_id,omitempty"`
+	Source         string               `json:"source,omitempty"` // Where the alert originated, see models.AlertEvent.Source
+	Unacknowledged bool                 `json:"unacknowledged"`   // Its in-app notification is unread
+}
+
+// Gauge is the payload of a gauge widget
+type Gauge struct {
+	Value   *float64    `json:"value"` // Nil without recent data
+	Display string      `json:"display"`
+	Min     float64     `json:"min"`
+	Max     float64     `json:"max"`
+	Percent float64     `json:"percent"` // Position of the value between min and max, 0-100
+	Status  string      `json:"status"`
+	Unit    models.Unit `json:"unit,omitempty"`
+}
+
+// TopItem is one row of a top list
+type TopItem struct {
+	Label   string            `json:"label"`
+	Labels  map[string]string `json:"labels,omitempty"`
+	PID     int32             `json:"pid,omitempty"`
+	Value   float64           `json:"value"`
+	Display string            `json:"display"`
+}
+
+// TopList is the payload of a top list widget
+type TopList struct {
+	Items []TopItem `json:"items"`
+}
+
+// SparklineSeries is one line of a sparkline. Values[i] is the value of the
+// step starting at Start + i*Step, nil where there is no data.
+type SparklineSeries struct {
+	Label  string            `json:"label"`
+	Labels map[string]string `json:"labels,omitempty"`
+	Values []*float64        `json:"values"`
+	Last   *float64          `json:"last"`
+	Min    *float64          `json:"min"`
+	Max    *float64          `json:"max"`
+}
+
+// Sparkline is the payload of a sparkline widget
+type Sparkline struct {
+	Start  time.Time         `json:"start"`
+	Step   string            `json:"step"`
+	Series []SparklineSeries `json:"series"`
+}
+
+// AlertList is the payload of an alert list widget
+type AlertList struct {
+	Alerts []Alert `json:"alerts"`
+	Total  int     `json:"total"` // Matching alerts before the limit
+}
+
+// Payload is a rendered widget; exactly one of the typed fields is set
+// unless Error is
+type Payload struct {
+	ID        string            `json:"id"`
+	Title     string            `json:"title,omitempty"`
+	Type      models.WidgetType `json:"type"`
+	Gauge     *Gauge            `json:"gauge,omitempty"`
+	TopList   *TopList          `json:"top_list,omitempty"`
+	Sparkline *Sparkline        `json:"sparkline,omitempty"`
+	AlertList *AlertList        `json:"alert_list,omitempty"`
+	Error     string            `json:"error,omitempty"`
+}
+
+// Dashboard is a rendered dashboard
+type Dashboard struct {
+	ID          string    `json:"id"`
+	Name        string    `json:"name"`
+	Refresh     string    `json:"refresh,omitempty"`
+	GeneratedAt time.Time `json:"generated_at"`
+	Widgets     []Payload `json:"widgets"`
+}
+
+// Renderer computes widget payloads
+type Renderer struct {
+	Sources   []query.Source                 // Metric history for queries
+	Processes func() *metrics.ProcessMetrics // Nil disables process top lists
+	Alerts    func() []Alert                 // Nil disables alert lists
+	Clock     clock.Clock                    // Time source (nil uses the real clock)
+}
+
+// Validate checks the dashboard and parses the queries of its widgets
+func Validate(d *models.Dashboard) error {
+	if err := d.Validate(); err != nil {
+		return err
+	}
+	for i, w := range d.Widgets {
+		if w.Query == "" {
+			continue
+		}
+		if _, err := query.Parse(w.Query); err != nil {
+			return fmt.Errorf("widget %d: invalid query: %w", i+1, err)
+		}
+	}
+	return nil
+}
+
+// RenderDashboard renders every widget of d
+func (r *Renderer) RenderDashboard(d *models.Dashboard) Dashboard {
+	now := clock.OrReal(r.Clock).Now().UTC()
+	result := Dashboard{ID: d.ID, Name: d.Name, Refresh: d.Refresh, GeneratedAt: now, Widgets: make([]Payload, 0, len(d.Widgets))}
+	for i := range d.Widgets {
+		result.Widgets = append(result.Widgets, r.render(&d.Widgets[i], now))
+	}
+	return result
+}
+
+// Render renders one widget
+func (r *Renderer) Render(w *models.Widget) Payload {
+	return r.render(w, clock.OrReal(r.Clock).Now().UTC())
+}
+
+func (r *Renderer) render(w *models.Widget, now time.Time) Payload {
+	payload := Payload{ID: w.ID, Title: w.Title, Type: w.Type}
+	var err error
+	switch w.Type {
+	case models.WidgetGauge:
+		payload.Gauge, err = r.gauge(w, now)
+	case models.WidgetTopList:
+		payload.TopList, err = r.topList(w, now)
+	case models.WidgetSparkline:
+		payload.Sparkline, err = r.sparkline(w, now)
+	case models.WidgetAlertList:
+		payload.AlertList, err = r.alertList(w)
+	default:
+		err = fmt.Errorf("invalid widget type: %q", w.Type)
+	}
+	if err != nil {
+		payload.Error = err.Error()
+	}
+	return payload
+}
+
+// latest returns the series of q with the value of their last step within
+// LatestWindow
+func (r *Renderer) latest(q *query.Query, now time.Time) []query.Series {
+	result := query.Evaluate(q, r.Sources, query.Range{Start: now.Add(-LatestWindow), End: now, Step: LatestStep})
+	series := result.Series[:0]
+	for _, s := range result.Series {
+		if len(s.Points) > 0 {
+			series = append(series, s)
+		}
+	}
+	return series
+}
+
+func (r *Renderer) gauge(w *models.Widget, now time.Time) (*Gauge, error) {
+	q, err := query.Parse(w.Query)
+	if err != nil {
+		return nil, fmt.Errorf("invalid query: %w", err)
+	}
+	gauge := &Gauge{Min: w.Min, Max: w.GaugeMax(), Unit: w.Unit, Status: StatusNoData, Display: "-"}
+	series := r.latest(q, now)
+	if len(series) == 0 {
+		return gauge, nil
+	}
+	points := series[0].Points
+	value := points[len(points)-1].Value
+	gauge.Value = &value
+	gauge.Display = w.Unit.Format(value)
+	gauge.Percent = min(max((value-gauge.Min)/(gauge.Max-gauge.Min)*100, 0), 100)
+	gauge.Status = status(value, w.Warning, w.Critical)
+	return gauge, nil
+}
+
+// status compares value with the thresholds. A critical threshold below the
+// warning one means lower values are worse.
+func status(value float64, warning, critical *float64) string {
+	descending := warning != nil && critical != nil && *critical < *warning
+	breached := func(threshold *float64) bool {
+		if threshold == nil {
+			return false
+		}
+		if descending {
+			return value <= *threshold
+		}
+		return value >= *threshold
+	}
+	switch {
+	case breached(critical):
+		return StatusCritical
+	case breached(warning):
+		return StatusWarning
+	default:
+		return StatusOK
+	}
+}
+
+func limit(w *models.Widget) int {
+	if w.Limit > 0 {
+		return w.Limit
+	}
+	return DefaultLimit
+}
+
+// label names a series by its label values, ordered by label name, or by its
+// metric when it has no labels
+func label(metric string, labels map[string]string) string {
+	if len(labels) == 0 {
+		return metric
+	}
+	keys := make([]string, 0, len(labels))
+	for k := range labels {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	values := make([]string, len(keys))
+	for i, k := range keys {
+		values[i] = labels[k]
+	}
+	return strings.Join(values, " / ")
+}
+
+func (r *Renderer) topList(w *models.Widget, now time.Time) (*TopList, error) {
+	if w.Processes != "" {
+		return r.topProcesses(w)
+	}
+	q, err := query.Parse(w.Query)
+	if err != nil {
+		return nil, fmt.Errorf("invalid query: %w", err)
+	}
+	items := []TopItem{}
+	for _, s := range r.latest(q, now) {
+		value := s.Points[len(s.Points)-1].Value
+		items = append(items, TopItem{Label: label(s.Metric, s.Labels), Labels: s.Labels, Value: value, Display: w.Unit.Format(value)})
+	}
+	sortItems(items)
+	return &TopList{Items: items[:min(len(items), limit(w))]}, nil
+}
+
+func (r *Renderer) topProcesses(w *models.Widget) (*TopList, error) {
+	if r.Processes == nil {
+		return nil, fmt.Errorf("process metrics are not available")
+	}
+	items := []TopItem{}
+	if processes := r.Processes(); processes != nil {
+		for _, p := range processes.Processes {
+			value := p.CPUPercent
+			if w.Processes == models.ProcessesByMemory {
+				value = float64(p.MemPercent)
+			}
+			items = append(items, TopItem{Label: p.Name, PID: p.PID, Value: value, Display: models.UnitPercent.Format(value)})
+		}
+	}
+	sortItems(items)
+	return &TopList{Items: items[:min(len(items), limit(w))]}, nil
+}
+
+// sortItems orders a top list by value, highest first
+func sortItems(items []TopItem) {
+	slices.SortStableFunc(items, func(a, b TopItem) int {
+		if c := cmp.Compare(b.Value, a.Value); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.Label, b.Label)
+	})
+}
+
+func (r *Renderer) sparkline(w *models.Widget, now time.Time) (*Sparkline, error) {
+	q, err := query.Parse(w.Query)
+	if err != nil {
+		return nil, fmt.Errorf("invalid query: %w", err)
+	}
+	span := DefaultSparklineRange
+	if w.Range != "" {
+		if span, err = time.ParseDuration(w.Range); err != nil {
+			return nil, fmt.Errorf("invalid range: %w", err)
+		}
+	}
+	points := w.Points
+	if points == 0 {
+		points = DefaultSparklinePoints
+	}
+	step := max((span / time.Duration(points)).Round(time.Second), time.Second)
+	start := now.Add(-span).Truncate(step)
+	slots := int(now.Sub(start)/step) + 1
+
+	result := query.Evaluate(q, r.Sources, query.Range{Start: now.Add(-span), End: now, Step: step})
+	sparkline := &Sparkline{Start: start, Step: step.String(), Series: make([]SparklineSeries, 0, len(result.Series))}
+	for _, s := range result.Series {
+		line := SparklineSeries{Label: label(s.Metric, s.Labels), Labels: s.Labels, Values: make([]*float64, slots)}
+		for _, p := range s.Points {
+			i := int(p.Timestamp.Sub(start) / step)
+			if i < 0 || i >= slots {
+				continue
+			}
+			value := p.Value
+			line.Values[i] = &value
+			line.Last = &value
+			if line.Min == nil || value < *line.Min {
+				line.Min = &value
+			}
+			if line.Max == nil || value > *line.Max {
+				line.Max = &value
+			}
+		}
+		sparkline.Series = append(sparkline.Series, line)
+	}
+	return sparkline, nil
+}
+
+func (r *Renderer) alertList(w *models.Widget) (*AlertList, error) {
+	if r.Alerts == nil {
+		return nil, fmt.Errorf("alerts are not available")
+	}
+	minSeverity := slices.Index(models.Severities, w.Severity)
+	alerts := []Alert{}
+	for _, a := range r.Alerts() {
+		if a.State != models.StateActive && a.State != models.StatePending {
+			continue
+		}
+		if slices.Index(models.Severities, a.Severity) < minSeverity {
+			continue
+		}
+		if (w.Owner != "" && a.Owner != w.Owner) || (w.Team != "" && a.Team != w.Team) || (w.GroupID != "" && a.GroupID != w.GroupID) {
+			continue
+		}
+		alerts = append(alerts, a)
+	}
+	slices.SortStableFunc(alerts, func(a, b Alert) int {
+		if c := cmp.Compare(slices.Index(models.Severities, b.Severity), slices.Index(models.Severities, a.Severity)); c != 0 {
+			return c
+		}
+		if a.State != b.State {
+			if a.State == models.StateActive {
+				return -1
+			}
+			return 1
+		}
+		if a.Since != nil && b.Since != nil && !a.Since.Equal(*b.Since) {
+			return a.Since.Compare(*b.Since)
+		}
+		return cmp.Compare(a.Name, b.Name)
+	})
+	return &AlertList{Alerts: alerts[:min(len(alerts), limit(w))], Total: len(alerts)}, nil
+}
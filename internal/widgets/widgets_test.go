@@ -0,0 +1,163 @@
+package widgets
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"argus/internal/clock"
+	"argus/internal/metrics"
+	"argus/internal/models"
+	"argus/internal/query"
+)
+
+var epoch = time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// newTestRenderer serves ten minutes of cpu_usage_percent for two hosts, one
+// sample per minute ending at the renderer's now: web-1 climbing from 50 to
+// 95 and db-1 steady at 20
+func newTestRenderer(t *testing.T) *Renderer {
+	t.Helper()
+	now := epoch.Add(10 * time.Minute)
+	clk := clock.NewFake(now)
+	store := metrics.NewSeriesStore(metrics.SeriesStoreConfig{Retention: time.Hour, Clock: clk})
+	var web, db []metrics.Sample
+	for i := 0; i < 10; i++ {
+		at := epoch.Add(time.Duration(i+1) * time.Minute)
+		web = append(web, metrics.Sample{Timestamp: at, Value: 50 + float64(i)*5})
+		db = append(db, metrics.Sample{Timestamp: at, Value: 20})
+	}
+	require.NoError(t, store.Append("cpu_usage_percent", map[string]string{"host": "web-1"}, web))
+	require.NoError(t, store.Append("cpu_usage_percent", map[string]string{"host": "db-1"}, db))
+	return &Renderer{Sources: []query.Source{query.FromStore(store)}, Clock: clk}
+}
+
+func ptr(v float64) *float64 { return &v }
+
+func TestRenderer_Gauge(t *testing.T) {
+	r := newTestRenderer(t)
+	w := &models.Widget{ID: "cpu", Type: models.WidgetGauge, Query: `max(cpu_usage_percent)`, Unit: models.UnitPercent, Warning: ptr(80), Critical: ptr(90)}
+
+	p := r.Render(w)
+	require.Empty(t, p.Error)
+	require.NotNil(t, p.Gauge.Value)
+	assert.Equal(t, 95.0, *p.Gauge.Value)
+	assert.Equal(t, "95%", p.Gauge.Display)
+	assert.Equal(t, 95.0, p.Gauge.Percent)
+	assert.Equal(t, StatusCritical, p.Gauge.Status)
+
+	w.Query = `cpu_usage_percent{host="db-1"}`
+	w.Min, w.Max = 0, 40
+	p = r.Render(w)
+	assert.Equal(t, 50.0, p.Gauge.Percent)
+	assert.Equal(t, StatusOK, p.Gauge.Status)
+
+	w.Query = "missing_metric"
+	p = r.Render(w)
+	assert.Nil(t, p.Gauge.Value)
+	assert.Equal(t, StatusNoData, p.Gauge.Status)
+
+	w.Query = "avg(("
+	assert.Contains(t, r.Render(w).Error, "invalid query")
+}
+
+func TestStatus(t *testing.T) {
+	assert.Equal(t, StatusOK, status(50, ptr(80), ptr(90)))
+	assert.Equal(t, StatusWarning, status(80, ptr(80), ptr(90)))
+	assert.Equal(t, StatusCritical, status(99, nil, ptr(90)))
+
+	// Lower is worse, e.g. free disk space
+	assert.Equal(t, StatusOK, status(50, ptr(20), ptr(10)))
+	assert.Equal(t, StatusWarning, status(15, ptr(20), ptr(10)))
+	assert.Equal(t, StatusCritical, status(5, ptr(20), ptr(10)))
+	assert.Equal(t, StatusOK, status(5, nil, nil))
+}
+
+func TestRenderer_TopList(t *testing.T) {
+	r := newTestRenderer(t)
+	p := r.Render(&models.Widget{ID: "top", Type: models.WidgetTopList, Query: "cpu_usage_percent", Unit: models.UnitPercent})
+	require.Empty(t, p.Error)
+	require.Len(t, p.TopList.Items, 2)
+	assert.Equal(t, "web-1", p.TopList.Items[0].Label)
+	assert.Equal(t, "95%", p.TopList.Items[0].Display)
+	assert.Equal(t, "db-1", p.TopList.Items[1].Label)
+
+	p = r.Render(&models.Widget{ID: "top", Type: models.WidgetTopList, Processes: models.ProcessesByMemory, Limit: 2})
+	assert.Equal(t, "process metrics are not available", p.Error)
+
+	r.Processes = func() *metrics.ProcessMetrics {
+		return &metrics.ProcessMetrics{Processes: []metrics.ProcessInfo{
+			{PID: 1, Name: "init", CPUPercent: 0.1, MemPercent: 0.5},
+			{PID: 42, Name: "postgres", CPUPercent: 12, MemPercent: 30},
+			{PID: 7, Name: "nginx", CPUPercent: 40, MemPercent: 2},
+		}}
+	}
+	p = r.Render(&models.Widget{ID: "top", Type: models.WidgetTopList, Processes: models.ProcessesByMemory, Limit: 2})
+	require.Len(t, p.TopList.Items, 2)
+	assert.Equal(t, int32(42), p.TopList.Items[0].PID)
+	assert.Equal(t, "nginx", p.TopList.Items[1].Label)
+}
+
+func TestRenderer_Sparkline(t *testing.T) {
+	r := newTestRenderer(t)
+	p := r.Render(&models.Widget{ID: "spark", Type: models.WidgetSparkline, Query: `cpu_usage_percent{host="web-1"}`, Range: "10m", Points: 10})
+	require.Empty(t, p.Error)
+	assert.Equal(t, epoch, p.Sparkline.Start)
+	assert.Equal(t, "1m0s", p.Sparkline.Step)
+	require.Len(t, p.Sparkline.Series, 1)
+
+	line := p.Sparkline.Series[0]
+	assert.Equal(t, "web-1", line.Label)
+	require.Len(t, line.Values, 11)
+	assert.Nil(t, line.Values[0], "no sample in the first minute")
+	assert.Equal(t, 50.0, *line.Values[1])
+	assert.Equal(t, 95.0, *line.Last)
+	assert.Equal(t, 50.0, *line.Min)
+	assert.Equal(t, 95.0, *line.Max)
+}
+
+func TestRenderer_AlertList(t *testing.T) {
+	r := newTestRenderer(t)
+	w := &models.Widget{ID: "alerts", Type: models.WidgetAlertList, Severity: models.SeverityWarning, Limit: 2}
+	assert.Equal(t, "alerts are not available", r.Render(w).Error)
+
+	early, late := epoch, epoch.Add(time.Minute)
+	r.Alerts = func() []Alert {
+		return []Alert{
+			{ID: "a", Name: "disk", Severity: models.SeverityWarning, State: models.StateActive, Since: &early},
+			{ID: "b", Name: "cpu", Severity: models.SeverityCritical, State: models.StatePending},
+			{ID: "c", Name: "load", Severity: models.SeverityCritical, State: models.StateActive, Since: &late, Team: "web"},
+			{ID: "d", Name: "info", Severity: models.SeverityInfo, State: models.StateActive},
+			{ID: "e", Name: "ok", Severity: models.SeverityCritical, State: models.StateInactive},
+		}
+	}
+	p := r.Render(w)
+	require.Empty(t, p.Error)
+	assert.Equal(t, 3, p.AlertList.Total)
+	require.Len(t, p.AlertList.Alerts, 2)
+	assert.Equal(t, "c", p.AlertList.Alerts[0].ID, "active critical first")
+	assert.Equal(t, "b", p.AlertList.Alerts[1].ID)
+
+	w.Team = "web"
+	assert.Equal(t, 1, r.Render(w).AlertList.Total)
+}
+
+func TestRenderDashboard(t *testing.T) {
+	r := newTestRenderer(t)
+	d := &models.Dashboard{ID: "d1", Name: "Ops", Refresh: "30s", Widgets: []models.Widget{
+		{ID: "g", Type: models.WidgetGauge, Query: "avg(cpu_usage_percent)"},
+		{ID: "a", Type: models.WidgetAlertList},
+	}}
+	require.NoError(t, Validate(d))
+
+	result := r.RenderDashboard(d)
+	assert.Equal(t, epoch.Add(10*time.Minute), result.GeneratedAt)
+	require.Len(t, result.Widgets, 2)
+	assert.Equal(t, 57.5, *result.Widgets[0].Gauge.Value)
+	assert.NotEmpty(t, result.Widgets[1].Error, "one failing widget does not fail the dashboard")
+
+	d.Widgets[0].Query = "avg("
+	assert.ErrorContains(t, Validate(d), "widget 1: invalid query")
+}
@@ -0,0 +1,194 @@
+// File: pkg/client/alerts.go
+// Brief: Alert endpoints of the Argus API client
+// Detailed: Lists, reads, creates, updates, clones, bulk-edits and deletes alert configurations under /api/alerts, creates the default alerts, and reads their current status and state change history.
+// Author: drama.lin@aver.com
+// Date: 2026-10-14
+
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+// AlertFilter selects the alerts listed; empty fields select all
+type AlertFilter struct {
+	Owner string
+	Team  string
+	Group string // Alert group ID, or "none" for ungrouped alerts
+}
+
+// BulkAlertEdit is one partial edit applied to several alerts. The alerts
+// edited are those listed in IDs and matching Match.
+type BulkAlertEdit struct {
+	IDs   []string `json:"ids,omitempty"`
+	Match string   `json:"match,omitempty"` // Label selector, e.g. {severity="warning",metric_type="cpu"}
+	// Set holds the fields to change, e.g. map[string]interface{}{"enabled": false};
+	// objects such as the threshold are merged field by field
+	Set              interface{} `json:"set,omitempty"`
+	ThresholdPercent float64     `json:"threshold_percent,omitempty"` // Raises numeric thresholds by this percentage
+}
+
+// BulkAlertResult reports a bulk edit
+type BulkAlertResult struct {
+	Matched int      `json:"matched"`
+	Updated int      `json:"updated"` // Alerts the edit changed
+	DryRun  bool     `json:"dry_run"`
+	Alerts  []*Alert `json:"alerts"` // The changed alerts, as stored
+}
+
+// DefaultAlert is one of the server's default alerts, by template name
+type DefaultAlert struct {
+	Template string `json:"template"` // e.g. cpu or disk
+	Alert
+}
+
+// DefaultAlertsResult reports the creation of default alerts
+type DefaultAlertsResult struct {
+	Created  []*Alert `json:"created"`
+	Existing []string `json:"existing"` // IDs of the defaults already stored, left as they are
+}
+
+// ListAlerts returns the alert configurations
+func (c *Client) ListAlerts(ctx context.Context) ([]*Alert, error) {
+	return c.ListAlertsFiltered(ctx, AlertFilter{})
+}
+
+// ListAlertsFiltered returns the alert configurations selected by filter
+func (c *Client) ListAlertsFiltered(ctx context.Context, filter AlertFilter) ([]*Alert, error) {
+	query := url.Values{}
+	for key, value := range map[string]string{"owner": filter.Owner, "team": filter.Team, "group": filter.Group} {
+		if value != "" {
+			query.Set(key, value)
+		}
+	}
+	var alerts []*Alert
+	if err := c.call(ctx, http.MethodGet, "/api/alerts", query, nil, &alerts); err != nil {
+		return nil, err
+	}
+	return alerts, nil
+}
+
+// GetAlert returns an alert configuration
+func (c *Client) GetAlert(ctx context.Context, id string) (*Alert, error) {
+	var alert Alert
+	if err := c.call(ctx, http.MethodGet, "/api/alerts/"+url.PathEscape(id), nil, nil, &alert); err != nil {
+		return nil, err
+	}
+	return &alert, nil
+}
+
+// CreateAlert creates an alert, returning it as stored; the server assigns
+// an ID to an alert without one
+func (c *Client) CreateAlert(ctx context.Context, alert *Alert) (*Alert, error) {
+	var created Alert
+	if err := c.call(ctx, http.MethodPost, "/api/alerts", nil, alert, &created); err != nil {
+		return nil, err
+	}
+	return &created, nil
+}
+
+// UpdateAlert replaces the alert with alert.ID, returning it as stored
+func (c *Client) UpdateAlert(ctx context.Context, alert *Alert) (*Alert, error) {
+	var updated Alert
+	if err := c.call(ctx, http.MethodPut, "/api/alerts/"+url.PathEscape(alert.ID), nil, alert, &updated); err != nil {
+		return nil, err
+	}
+	return &updated, nil
+}
+
+// DeleteAlert deletes an alert
+func (c *Client) DeleteAlert(ctx context.Context, id string) error {
+	return c.call(ctx, http.MethodDelete, "/api/alerts/"+url.PathEscape(id), nil, nil, nil)
+}
+
+// CloneAlert copies an alert under a new ID. The fields of overrides, e.g.
+// map[string]interface{}{"name": "High CPU (db)"}, replace those of the copy;
+// nil copies the alert as is, named with a " (copy)" suffix.
+func (c *Client) CloneAlert(ctx context.Context, id string, overrides interface{}) (*Alert, error) {
+	if overrides == nil {
+		overrides = json.RawMessage("{}")
+	}
+	var clone Alert
+	if err := c.call(ctx, http.MethodPost, "/api/alerts/"+url.PathEscape(id)+"/clone", nil, overrides, &clone); err != nil {
+		return nil, err
+	}
+	return &clone, nil
+}
+
+// BulkUpdateAlerts applies one edit to several alerts; none is stored unless
+// every edited alert is valid. A dry run returns the edited alerts without
+// storing them.
+func (c *Client) BulkUpdateAlerts(ctx context.Context, edit BulkAlertEdit, dryRun bool) (*BulkAlertResult, error) {
+	query := url.Values{}
+	if dryRun {
+		query.Set("dry_run", "true")
+	}
+	var result BulkAlertResult
+	if err := c.call(ctx, http.MethodPatch, "/api/alerts/bulk", query, edit, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// DefaultAlerts returns the default alerts as the server would create them
+func (c *Client) DefaultAlerts(ctx context.Context) ([]DefaultAlert, error) {
+	var defaults []DefaultAlert
+	if err := c.call(ctx, http.MethodGet, "/api/alerts/defaults", nil, nil, &defaults); err != nil {
+		return nil, err
+	}
+	return defaults, nil
+}
+
+// CreateDefaultAlerts creates the default alerts with the given template
+// names, or all of them, that are not stored yet
+func (c *Client) CreateDefaultAlerts(ctx context.Context, templates ...string) (*DefaultAlertsResult, error) {
+	body := struct {
+		Templates []string `json:"templates,omitempty"`
+	}{templates}
+	var result DefaultAlertsResult
+	if err := c.call(ctx, http.MethodPost, "/api/alerts/defaults", nil, body, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// AlertHistory returns the latest state changes of an alert, newest first;
+// a limit of 0 uses the server's default of 50
+func (c *Client) AlertHistory(ctx context.Context, id string, limit int) ([]AlertHistoryEntry, error) {
+	query := url.Values{}
+	if limit > 0 {
+		query.Set("limit", strconv.Itoa(limit))
+	}
+	var history []AlertHistoryEntry
+	if err := c.call(ctx, http.MethodGet, "/api/alerts/"+url.PathEscape(id)+"/history", query, nil, &history); err != nil {
+		return nil, err
+	}
+	return history, nil
+}
+
+// AlertStatuses returns the current status of every evaluated alert, by ID
+func (c *Client) AlertStatuses(ctx context.Context) (map[string]*AlertStatus, error) {
+	var statuses map[string]*AlertStatus
+	if err := c.call(ctx, http.MethodGet, "/api/alerts/status", nil, nil, &statuses); err != nil {
+		return nil, err
+	}
+	return statuses, nil
+}
+
+// AlertStatus returns the current status of an alert
+func (c *Client) AlertStatus(ctx context.Context, id string) (*AlertStatus, error) {
+	var status AlertStatus
+	if err := c.call(ctx, http.MethodGet, "/api/alerts/status/"+url.PathEscape(id), nil, nil, &status); err != nil {
+		return nil, err
+	}
+	return &status, nil
+}
+
+// TestAlert sends a test notification of an alert through its channels
+func (c *Client) TestAlert(ctx context.Context, id string) error {
+	return c.call(ctx, http.MethodPost, "/api/alerts/test/"+url.PathEscape(id), nil, nil, nil)
+}
@@ -0,0 +1,287 @@
+// File: pkg/client/client.go
+// Brief: Go client of the Argus REST API
+// Detailed: Sends authenticated JSON requests to an Argus server and decodes the responses of both API shapes, the {"success", "data", "error"} envelope of the alert endpoints and the bare bodies of the task and metric ones, into typed values. Failed responses become an *APIError carrying the status and the server's message. Requests that are safe to repeat (GET, PUT, DELETE, and any request refused with 429 or never sent because the connection failed) are retried with exponential backoff and jitter, honoring Retry-After; every call takes a context bounding its attempts and waits.
+// Author: drama.lin@aver.com
+// Date: 2026-10-14
+
+// Package client is a typed Go client of the Argus API, for services, the CLI
+// and agents talking to an Argus server.
+//
+//	c, err := client.New(client.Config{BaseURL: "https://argus.example.com:8080", APIKey: os.Getenv("ARGUS_API_KEY")})
+//	alerts, err := c.ListAlerts(ctx)
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Defaults of the client configuration
+const (
+	DefaultTimeout      = 30 * time.Second
+	DefaultRetries      = 3
+	DefaultMinBackoff   = 250 * time.Millisecond
+	DefaultMaxBackoff   = 10 * time.Second
+	DefaultAPIKeyHeader = "X-API-Key"
+	DefaultUserAgent    = "argus-client"
+)
+
+// maxErrorBody is how much of a failed response is read for its message
+const maxErrorBody = 64 << 10
+
+// Config holds configuration for a client
+type Config struct {
+	BaseURL      string       // Server URL, e.g. https://argus.example.com:8080
+	APIKey       string       // Sent in APIKeyHeader (auth.api_keys)
+	APIKeyHeader string       // Header of the API key (default X-API-Key, see auth.api_key_header)
+	Token        string       // Sent as a bearer token, e.g. an OIDC access token
+	UserAgent    string       // Default argus-client
+	HTTPClient   *http.Client // nil uses a client with a timeout of DefaultTimeout
+	Retries      int          // Retries of a failed request (0 uses DefaultRetries, negative disables)
+	MinBackoff   time.Duration
+	MaxBackoff   time.Duration
+}
+
+// Client calls the API of one Argus server. It is safe for concurrent use.
+type Client struct {
+	config  Config
+	baseURL *url.URL
+	http    *http.Client
+}
+
+// APIError is a response of the server reporting a failure
+type APIError struct {
+	StatusCode int
+	Message    string // The server's error message, or the start of the body
+	ReadOnly   bool   // Refused because the server is in read-only mode
+}
+
+func (e *APIError) Error() string {
+	if e.Message == "" {
+		return fmt.Sprintf("argus: HTTP %d", e.StatusCode)
+	}
+	return fmt.Sprintf("argus: HTTP %d: %s", e.StatusCode, e.Message)
+}
+
+// IsNotFound reports whether err is a 404 response, e.g. of an unknown alert
+func IsNotFound(err error) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound
+}
+
+// New creates a client of the server at config.BaseURL
+func New(config Config) (*Client, error) {
+	base, err := url.Parse(strings.TrimRight(config.BaseURL, "/"))
+	if err != nil || (base.Scheme != "http" && base.Scheme != "https") || base.Host == "" {
+		return nil, fmt.Errorf("invalid base URL %q: must be an http or https URL", config.BaseURL)
+	}
+	if config.APIKeyHeader == "" {
+		config.APIKeyHeader = DefaultAPIKeyHeader
+	}
+	if config.UserAgent == "" {
+		config.UserAgent = DefaultUserAgent
+	}
+	if config.Retries == 0 {
+		config.Retries = DefaultRetries
+	}
+	if config.MinBackoff <= 0 {
+		config.MinBackoff = DefaultMinBackoff
+	}
+	if config.MaxBackoff < config.MinBackoff {
+		config.MaxBackoff = max(DefaultMaxBackoff, config.MinBackoff)
+	}
+	httpClient := config.HTTPClient
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: DefaultTimeout}
+	}
+	return &Client{config: config, baseURL: base, http: httpClient}, nil
+}
+
+// envelope is the body of the endpoints answering with models.APIResponse
+type envelope struct {
+	Success bool            `json:"success"`
+	Data    json.RawMessage `json:"data"`
+	Error   string          `json:"error"`
+}
+
+// call sends a request to an endpoint answering with the API envelope and
+// decodes its data into out
+func (c *Client) call(ctx context.Context, method, path string, query url.Values, in, out interface{}) error {
+	var env envelope
+	if err := c.do(ctx, method, path, query, in, &env); err != nil {
+		return err
+	}
+	if !env.Success {
+		return &APIError{StatusCode: http.StatusOK, Message: env.Error}
+	}
+	if out == nil || len(env.Data) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(env.Data, out); err != nil {
+		return fmt.Errorf("argus: invalid response of %s %s: %w", method, path, err)
+	}
+	return nil
+}
+
+// do sends a request with in as its JSON body, retrying it when that is safe,
+// and decodes the JSON response into out
+func (c *Client) do(ctx context.Context, method, path string, query url.Values, in, out interface{}) error {
+	var body []byte
+	if in != nil {
+		var err error
+		if body, err = json.Marshal(in); err != nil {
+			return fmt.Errorf("argus: failed to encode request: %w", err)
+		}
+	}
+	target := c.url(path, query)
+
+	for attempt := 0; ; attempt++ {
+		resp, err := c.send(ctx, method, target, body)
+		retry, wait := c.retryable(method, resp, err), time.Duration(0)
+		if err == nil {
+			if retry {
+				wait = retryAfter(resp)
+			}
+			if !retry || attempt >= c.config.Retries {
+				return decodeResponse(resp, method, path, out)
+			}
+			io.Copy(io.Discard, io.LimitReader(resp.Body, maxErrorBody))
+			resp.Body.Close()
+		} else if !retry || attempt >= c.config.Retries {
+			return fmt.Errorf("argus: %s %s: %w", method, path, err)
+		}
+
+		if wait <= 0 {
+			wait = c.backoff(attempt)
+		} else if wait > c.config.MaxBackoff {
+			wait = c.config.MaxBackoff
+		}
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return fmt.Errorf("argus: %s %s: %w", method, path, ctx.Err())
+		case <-timer.C:
+		}
+	}
+}
+
+// url resolves an API path against the base URL
+func (c *Client) url(path string, query url.Values) string {
+	u := *c.baseURL
+	u.Path = c.baseURL.Path + path
+	u.RawQuery = query.Encode()
+	return u.String()
+}
+
+// send makes one attempt of a request
+func (c *Client) send(ctx context.Context, method, target string, body []byte) (*http.Response, error) {
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, target, reader)
+	if err != nil {
+		return nil, err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	req.Header.Set("Accept", "application/json")
+	c.authorize(req.Header)
+	return c.http.Do(req)
+}
+
+// authorize sets the credentials and user agent of a request
+func (c *Client) authorize(header http.Header) {
+	header.Set("User-Agent", c.config.UserAgent)
+	if c.config.APIKey != "" {
+		header.Set(c.config.APIKeyHeader, c.config.APIKey)
+	}
+	if c.config.Token != "" {
+		header.Set("Authorization", "Bearer "+c.config.Token)
+	}
+}
+
+// retryable reports whether a failed attempt may be repeated: idempotent
+// requests after a network error or a 502, 503 or 504, and any request that
+// was rate limited or never reached the server
+func (c *Client) retryable(method string, resp *http.Response, err error) bool {
+	if c.config.Retries < 0 {
+		return false
+	}
+	idempotent := method == http.MethodGet || method == http.MethodHead || method == http.MethodPut || method == http.MethodDelete
+	if err != nil {
+		if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+			return false
+		}
+		var opErr *net.OpError
+		return idempotent || (errors.As(err, &opErr) && opErr.Op == "dial")
+	}
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests:
+		return true
+	case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return idempotent
+	}
+	return false
+}
+
+// backoff returns the wait before retry attempt+1: exponential from
+// MinBackoff, capped at MaxBackoff, with jitter so clients retrying together
+// spread out
+func (c *Client) backoff(attempt int) time.Duration {
+	wait := c.config.MaxBackoff
+	if attempt < 30 {
+		wait = min(c.config.MinBackoff<<attempt, c.config.MaxBackoff)
+	}
+	return wait/2 + time.Duration(rand.Int63n(int64(wait/2)+1))
+}
+
+// retryAfter returns the wait asked for by a Retry-After header in seconds
+func retryAfter(resp *http.Response) time.Duration {
+	seconds, err := strconv.Atoi(resp.Header.Get("Retry-After"))
+	if err != nil || seconds < 0 {
+		return 0
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+// decodeResponse decodes a successful response into out, or returns the
+// failure it reports
+func decodeResponse(resp *http.Response, method, path string, out interface{}) error {
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		data, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+		apiErr := &APIError{StatusCode: resp.StatusCode}
+		var failure struct {
+			Error    string `json:"error"`
+			ReadOnly bool   `json:"read_only"`
+		}
+		if json.Unmarshal(data, &failure) == nil && failure.Error != "" {
+			apiErr.Message, apiErr.ReadOnly = failure.Error, failure.ReadOnly
+		} else {
+			apiErr.Message = strings.TrimSpace(string(data[:min(len(data), 256)]))
+		}
+		return apiErr
+	}
+	if out == nil || resp.StatusCode == http.StatusNoContent {
+		io.Copy(io.Discard, io.LimitReader(resp.Body, maxErrorBody))
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("argus: invalid response of %s %s: %w", method, path, err)
+	}
+	return nil
+}
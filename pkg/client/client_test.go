@@ -0,0 +1,227 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"argus/internal/models"
+	"argus/internal/server"
+)
+
+func newTestClient(t *testing.T, handler http.Handler) *Client {
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+	c, err := New(Config{BaseURL: srv.URL + "/", APIKey: "key-0123456789abcdef", MinBackoff: time.Millisecond, MaxBackoff: 5 * time.Millisecond})
+	require.NoError(t, err)
+	return c
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func TestClient_Alerts(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/alerts", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "key-0123456789abcdef", r.Header.Get("X-API-Key"))
+		assert.Equal(t, "argus-client", r.Header.Get("User-Agent"))
+		assert.Equal(t, "ops", r.URL.Query().Get("team"))
+		writeJSON(w, http.StatusOK, models.APIResponse{Success: true, Data: []*Alert{{ID: "a1", Name: "High CPU"}}})
+	})
+	mux.HandleFunc("POST /api/alerts", func(w http.ResponseWriter, r *http.Request) {
+		var alert Alert
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&alert))
+		alert.ID = "generated"
+		writeJSON(w, http.StatusCreated, models.APIResponse{Success: true, Data: alert})
+	})
+	mux.HandleFunc("GET /api/alerts/{id}", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusNotFound, models.APIResponse{Success: false, Error: "Alert not found"})
+	})
+	mux.HandleFunc("PATCH /api/alerts/bulk", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusForbidden, map[string]interface{}{"error": "Argus is in read-only mode", "read_only": true})
+	})
+	c := newTestClient(t, mux)
+	ctx := context.Background()
+
+	alerts, err := c.ListAlertsFiltered(ctx, AlertFilter{Team: "ops"})
+	require.NoError(t, err)
+	require.Len(t, alerts, 1)
+	assert.Equal(t, "High CPU", alerts[0].Name)
+
+	created, err := c.CreateAlert(ctx, &Alert{Name: "Disk", Severity: SeverityWarning, Threshold: Threshold{MetricType: MetricDisk}})
+	require.NoError(t, err)
+	assert.Equal(t, "generated", created.ID)
+	assert.Equal(t, SeverityWarning, created.Severity)
+
+	_, err = c.GetAlert(ctx, "missing/../a1")
+	assert.True(t, IsNotFound(err))
+	assert.EqualError(t, err, "argus: HTTP 404: Alert not found")
+
+	_, err = c.BulkUpdateAlerts(ctx, BulkAlertEdit{IDs: []string{"a1"}, Set: map[string]interface{}{"enabled": false}}, false)
+	var apiErr *APIError
+	require.ErrorAs(t, err, &apiErr)
+	assert.True(t, apiErr.ReadOnly)
+}
+
+func TestClient_Tasks(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/tasks", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, []string{"backup", "nightly"}, r.URL.Query()["tag"])
+		assert.Equal(t, []string{"system_cleanup"}, r.URL.Query()["type"])
+		writeJSON(w, http.StatusOK, []*Task{{ID: "t1", Type: TaskSystemCleanup}})
+	})
+	mux.HandleFunc("POST /api/tasks/{id}/run", func(w http.ResponseWriter, r *http.Request) {
+		if r.PathValue("id") != "t1" {
+			writeJSON(w, http.StatusNotFound, map[string]string{"error": "Task not found"})
+			return
+		}
+		assert.Equal(t, "true", r.URL.Query().Get("dry_run"))
+		writeJSON(w, http.StatusOK, TaskExecution{ExecutionID: "e1", TaskID: "t1", Status: StatusCompleted})
+	})
+	mux.HandleFunc("DELETE /api/tasks/{id}", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})
+	c := newTestClient(t, mux)
+	ctx := context.Background()
+
+	tasks, err := c.ListTasksFiltered(ctx, TaskFilter{Tags: []string{"backup", "nightly"}, Types: []TaskType{TaskSystemCleanup}})
+	require.NoError(t, err)
+	require.Len(t, tasks, 1)
+
+	execution, err := c.RunTask(ctx, "t1", true)
+	require.NoError(t, err)
+	assert.Equal(t, StatusCompleted, execution.Status)
+	_, err = c.RunTask(ctx, "t2", false)
+	assert.EqualError(t, err, "argus: HTTP 404: Task not found")
+
+	assert.NoError(t, c.DeleteTask(ctx, "t1"))
+}
+
+func TestClient_Retry(t *testing.T) {
+	var attempts atomic.Int32
+	var status atomic.Int32
+	status.Store(http.StatusServiceUnavailable)
+	c := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if attempts.Add(1) < 3 {
+			w.Header().Set("Retry-After", "0")
+			writeJSON(w, int(status.Load()), map[string]string{"error": "busy"})
+			return
+		}
+		writeJSON(w, http.StatusOK, CPUMetrics{UsagePercent: 12.5})
+	}))
+	ctx := context.Background()
+
+	cpu, err := c.CPU(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 12.5, cpu.UsagePercent)
+	assert.Equal(t, int32(3), attempts.Load(), "503s of idempotent requests are retried")
+
+	attempts.Store(0)
+	_, err = c.RunTask(ctx, "t1", false)
+	assert.EqualError(t, err, "argus: HTTP 503: busy", "a POST is not repeated once the server may have acted on it")
+	assert.Equal(t, int32(1), attempts.Load())
+
+	attempts.Store(0)
+	status.Store(http.StatusTooManyRequests)
+	_, err = c.RunTask(ctx, "t1", false)
+	assert.NoError(t, err, "rate limited requests were not acted on")
+	assert.Equal(t, int32(3), attempts.Load())
+
+	attempts.Store(-10)
+	_, err = c.CPU(ctx)
+	assert.EqualError(t, err, "argus: HTTP 429: busy", "retries are bounded")
+	assert.Equal(t, int32(-6), attempts.Load(), "one attempt and DefaultRetries retries")
+
+	c.config.MinBackoff, c.config.MaxBackoff = time.Hour, time.Hour
+	attempts.Store(-10)
+	ctx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+	defer cancel()
+	_, err = c.Memory(ctx)
+	assert.ErrorIs(t, err, context.DeadlineExceeded, "the context bounds the waits")
+}
+
+func TestClient_Unreachable(t *testing.T) {
+	srv := httptest.NewServer(http.NotFoundHandler())
+	srv.Close()
+	c, err := New(Config{BaseURL: srv.URL, MinBackoff: time.Millisecond, Retries: 2})
+	require.NoError(t, err)
+
+	err = c.MarkAllNotificationsRead(context.Background())
+	var opErr interface{ Timeout() bool }
+	assert.ErrorAs(t, err, &opErr, "connection failures surface after the retries")
+
+	_, err = New(Config{BaseURL: "argus:8080"})
+	assert.ErrorContains(t, err, "must be an http or https URL")
+}
+
+func TestSubscribe(t *testing.T) {
+	statuses := map[string]*models.AlertStatus{"a1": {AlertID: "a1", State: models.StateActive}}
+	newHub := func() *server.Hub {
+		hub := server.NewHub()
+		go hub.Run()
+		server.NewAlertStream(hub, func() map[string]*models.AlertStatus { return statuses }).Start()
+		return hub
+	}
+	var current atomic.Pointer[server.Hub]
+	first := newHub()
+	current.Store(first)
+	c := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/ws", r.URL.Path)
+		assert.Equal(t, "alerts", r.URL.Query().Get("subscribe"))
+		assert.Equal(t, "key-0123456789abcdef", r.Header.Get("X-API-Key"))
+		server.ServeWs(current.Load(), w, r)
+	}))
+
+	errDone := errors.New("done")
+	var messages []Message
+	err := c.Subscribe(context.Background(), []string{TopicAlerts}, func(msg Message) error {
+		messages = append(messages, msg)
+		switch len(messages) {
+		case 1:
+			// The server restarts: the subscription reconnects to the new one
+			statuses["a2"] = &models.AlertStatus{AlertID: "a2", State: models.StateActive}
+			current.Store(newHub())
+			go first.Shutdown(context.Background())
+		case 3:
+			return errDone
+		}
+		return nil
+	})
+	assert.ErrorIs(t, err, errDone)
+
+	require.Len(t, messages, 3)
+	frame, err := messages[0].Alert()
+	require.NoError(t, err)
+	assert.Equal(t, "snapshot", frame.Kind)
+	require.Len(t, frame.Active, 1)
+	assert.Equal(t, "a1", frame.Active[0].AlertID)
+
+	assert.Equal(t, MessageShuttingDown, messages[1].Type)
+	_, err = messages[1].Alert()
+	assert.Error(t, err)
+
+	frame, err = messages[2].Alert()
+	require.NoError(t, err)
+	assert.Len(t, frame.Active, 2, "the new connection starts with a fresh snapshot")
+}
+
+func TestSubscribe_Refused(t *testing.T) {
+	c := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "Authentication required"})
+	}))
+	err := c.Subscribe(context.Background(), []string{TopicAlerts}, func(Message) error { return nil })
+	var apiErr *APIError
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, http.StatusUnauthorized, apiErr.StatusCode)
+}
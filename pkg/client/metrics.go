@@ -0,0 +1,92 @@
+// File: pkg/client/metrics.go
+// Brief: Metric endpoints of the Argus API client
+// Detailed: Reads the host metrics cached by the server's collector, one kind at a time or as a single snapshot, and the collector's health. A kind the collector has not gathered yet, or cannot gather on the host, is a 503 APIError.
+// Author: drama.lin@aver.com
+// Date: 2026-10-14
+
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+// CPU returns the CPU usage and load averages
+func (c *Client) CPU(ctx context.Context) (*CPUMetrics, error) {
+	var m CPUMetrics
+	if err := c.do(ctx, http.MethodGet, "/api/metrics/cpu", nil, nil, &m); err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+
+// Memory returns the memory usage
+func (c *Client) Memory(ctx context.Context) (*MemoryMetrics, error) {
+	var m MemoryMetrics
+	if err := c.do(ctx, http.MethodGet, "/api/metrics/memory", nil, nil, &m); err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+
+// Network returns the network counters, in total and per interface
+func (c *Client) Network(ctx context.Context) (*NetworkMetrics, error) {
+	var m NetworkMetrics
+	if err := c.do(ctx, http.MethodGet, "/api/metrics/network", nil, nil, &m); err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+
+// Disk returns the disk usage, in total and per partition
+func (c *Client) Disk(ctx context.Context) (*DiskMetrics, error) {
+	var m DiskMetrics
+	if err := c.do(ctx, http.MethodGet, "/api/metrics/disk", nil, nil, &m); err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+
+// RAID returns the health of the software RAID arrays and ZFS pools
+func (c *Client) RAID(ctx context.Context) (*RAIDMetrics, error) {
+	var m RAIDMetrics
+	if err := c.do(ctx, http.MethodGet, "/api/metrics/raid", nil, nil, &m); err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+
+// Power returns the batteries and UPSes
+func (c *Client) Power(ctx context.Context) (*PowerMetrics, error) {
+	var m PowerMetrics
+	if err := c.do(ctx, http.MethodGet, "/api/metrics/power", nil, nil, &m); err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+
+// Snapshot returns every metric at once with the topN processes by CPU
+// usage; a topN of 0 uses the server's default
+func (c *Client) Snapshot(ctx context.Context, topN int) (*Snapshot, error) {
+	query := url.Values{}
+	if topN > 0 {
+		query.Set("top_n", strconv.Itoa(topN))
+	}
+	var s Snapshot
+	if err := c.do(ctx, http.MethodGet, "/api/metrics/all", query, nil, &s); err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+// CollectorHealth returns the health of the metrics collector and of each
+// metric kind
+func (c *Client) CollectorHealth(ctx context.Context) (*CollectorHealth, error) {
+	var h CollectorHealth
+	if err := c.do(ctx, http.MethodGet, "/api/metrics/health", nil, nil, &h); err != nil {
+		return nil, err
+	}
+	return &h, nil
+}
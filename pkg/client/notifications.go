@@ -0,0 +1,54 @@
+// File: pkg/client/notifications.go
+// Brief: In-app notification endpoints of the Argus API client
+// Detailed: Reads the in-app notifications in a chosen language and marks them read, one at a time, per alert or all at once. When the server identifies users, read states are those of the user the client authenticates as.
+// Author: drama.lin@aver.com
+// Date: 2026-10-14
+
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+)
+
+// Notifications returns the in-app notifications, rendered in lang (e.g.
+// "zh-TW"; empty uses the server's default)
+func (c *Client) Notifications(ctx context.Context, lang string) ([]Notification, error) {
+	query := url.Values{}
+	if lang != "" {
+		query.Set("lang", lang)
+	}
+	var notifications []Notification
+	if err := c.call(ctx, http.MethodGet, "/api/alerts/notifications", query, nil, &notifications); err != nil {
+		return nil, err
+	}
+	return notifications, nil
+}
+
+// MarkNotificationRead marks a notification as read
+func (c *Client) MarkNotificationRead(ctx context.Context, id string) error {
+	return c.call(ctx, http.MethodPost, "/api/alerts/notifications/"+url.PathEscape(id)+"/read", nil, nil, nil)
+}
+
+// MarkAllNotificationsRead marks every notification as read
+func (c *Client) MarkAllNotificationsRead(ctx context.Context) error {
+	return c.call(ctx, http.MethodPost, "/api/alerts/notifications/read-all", nil, nil, nil)
+}
+
+// AcknowledgeAlert marks the notifications of an alert as read, returning
+// how many were marked
+func (c *Client) AcknowledgeAlert(ctx context.Context, alertID string) (int, error) {
+	var result struct {
+		Acknowledged int `json:"acknowledged"`
+	}
+	if err := c.call(ctx, http.MethodPost, "/api/alerts/"+url.PathEscape(alertID)+"/ack", nil, nil, &result); err != nil {
+		return 0, err
+	}
+	return result.Acknowledged, nil
+}
+
+// ClearNotifications removes every notification
+func (c *Client) ClearNotifications(ctx context.Context) error {
+	return c.call(ctx, http.MethodDelete, "/api/alerts/notifications", nil, nil, nil)
+}
@@ -0,0 +1,173 @@
+// File: pkg/client/stream.go
+// Brief: WebSocket subscriptions of the Argus API client
+// Detailed: Subscribes to the alert and process streams of /ws and hands each message to a callback. A dropped connection, including the planned close of a server restart, is redialed with the client's backoff; the server starts every connection with a snapshot, so the state a subscriber builds from the messages stays complete across reconnects. Only consecutive failed dials count against the retries.
+// Author: drama.lin@aver.com
+// Date: 2026-10-14
+
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"argus/internal/metrics"
+)
+
+// Stream topics
+const (
+	TopicAlerts    = "alerts"
+	TopicProcesses = "processes"
+)
+
+// Message types
+const (
+	MessageAlerts       = "alerts"
+	MessageProcesses    = "processes"
+	MessageShuttingDown = "server-shutting-down" // Sent before a restart; the subscription reconnects
+)
+
+// handshakeTimeout limits each dial of the stream
+const handshakeTimeout = 10 * time.Second
+
+// Message is one message of a stream
+type Message struct {
+	Type string          `json:"type"` // One of the Message constants
+	Kind string          `json:"kind"` // e.g. snapshot or event
+	Seq  uint64          `json:"seq"`
+	Raw  json.RawMessage `json:"-"` // The whole message
+}
+
+// AlertFrame is a message of the alerts topic. A snapshot lists the firing
+// alerts; an event carries a state change and the alert's status after it.
+type AlertFrame struct {
+	Kind      string             `json:"kind"` // snapshot or event
+	Seq       uint64             `json:"seq"`
+	Timestamp time.Time          `json:"timestamp"`
+	Active    []AlertStatus      `json:"active"` // Snapshots only
+	Event     *AlertHistoryEntry `json:"event"`  // Events only
+	Status    *AlertStatus       `json:"status"` // Events only
+}
+
+// ProcessFrame is a message of the processes topic. A full frame lists every
+// process; a delta applies to the state after frame Seq-1, so after a gap in
+// Seq deltas should be ignored until the next full frame.
+type ProcessFrame struct {
+	Kind      string        `json:"kind"` // full or delta
+	Seq       uint64        `json:"seq"`
+	UpdatedAt time.Time     `json:"updated_at"`
+	Processes []ProcessInfo `json:"processes"` // Full frames only
+	metrics.ProcessDelta
+}
+
+// Alert decodes a message of the alerts topic
+func (m Message) Alert() (*AlertFrame, error) {
+	if m.Type != MessageAlerts {
+		return nil, fmt.Errorf("argus: %s message is not an alert frame", m.Type)
+	}
+	var frame AlertFrame
+	if err := json.Unmarshal(m.Raw, &frame); err != nil {
+		return nil, fmt.Errorf("argus: invalid alert frame: %w", err)
+	}
+	return &frame, nil
+}
+
+// Processes decodes a message of the processes topic
+func (m Message) Processes() (*ProcessFrame, error) {
+	if m.Type != MessageProcesses {
+		return nil, fmt.Errorf("argus: %s message is not a process frame", m.Type)
+	}
+	var frame ProcessFrame
+	if err := json.Unmarshal(m.Raw, &frame); err != nil {
+		return nil, fmt.Errorf("argus: invalid process frame: %w", err)
+	}
+	return &frame, nil
+}
+
+// Subscribe streams the messages of topics to handle until ctx is done, handle
+// returns an error or the server cannot be reached within the retries. It
+// returns the error that ended the subscription.
+func (c *Client) Subscribe(ctx context.Context, topics []string, handle func(Message) error) error {
+	target := *c.baseURL
+	target.Scheme = map[string]string{"http": "ws", "https": "wss"}[c.baseURL.Scheme]
+	target.Path = c.baseURL.Path + "/ws"
+	target.RawQuery = "subscribe=" + strings.Join(topics, ",")
+
+	dialer := websocket.Dialer{Proxy: http.ProxyFromEnvironment, HandshakeTimeout: handshakeTimeout}
+	if transport, ok := c.http.Transport.(*http.Transport); ok {
+		dialer.Proxy, dialer.TLSClientConfig = transport.Proxy, transport.TLSClientConfig
+	}
+	header := http.Header{}
+	c.authorize(header)
+
+	for failures := 0; ; {
+		conn, resp, err := dialer.DialContext(ctx, target.String(), header)
+		switch {
+		case err == nil:
+			failures = 0
+			var stop stopError
+			if errors.As(c.read(ctx, conn, handle), &stop) {
+				return stop.err
+			}
+		case ctx.Err() != nil:
+			return ctx.Err()
+		case resp != nil && resp.StatusCode != http.StatusServiceUnavailable:
+			// Refused, e.g. 401, rather than unavailable during a restart
+			return &APIError{StatusCode: resp.StatusCode, Message: fmt.Sprintf("websocket handshake failed: %v", err)}
+		default:
+			if failures++; c.config.Retries < 0 || failures > c.config.Retries {
+				return fmt.Errorf("argus: websocket: %w", err)
+			}
+		}
+
+		timer := time.NewTimer(c.backoff(max(failures-1, 0)))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// stopError ends a subscription instead of reconnecting
+type stopError struct{ err error }
+
+func (e stopError) Error() string { return e.err.Error() }
+
+// read hands the messages of a connection to handle until it closes
+func (c *Client) read(ctx context.Context, conn *websocket.Conn, handle func(Message) error) error {
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+			conn.Close()
+		case <-done:
+		}
+	}()
+	defer conn.Close()
+
+	for {
+		_, data, err := conn.ReadMessage()
+		if err != nil {
+			if ctx.Err() != nil {
+				return stopError{ctx.Err()}
+			}
+			return err
+		}
+		msg := Message{Raw: data}
+		if err := json.Unmarshal(data, &msg); err != nil {
+			return stopError{fmt.Errorf("argus: invalid stream message: %w", err)}
+		}
+		if err := handle(msg); err != nil {
+			return stopError{err}
+		}
+	}
+}
@@ -0,0 +1,107 @@
+// File: pkg/client/tasks.go
+// Brief: Task endpoints of the Argus API client
+// Detailed: Lists, reads, creates, updates and deletes scheduled tasks under /api/tasks, runs them on demand and reads their execution records.
+// Author: drama.lin@aver.com
+// Date: 2026-10-14
+
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+// TaskFilter selects the tasks listed; empty fields select all
+type TaskFilter struct {
+	Tags  []string   // Tasks carrying any of the tags
+	Types []TaskType // Tasks of any of the types
+	Owner string
+	Team  string
+}
+
+// ListTasks returns the task configurations
+func (c *Client) ListTasks(ctx context.Context) ([]*Task, error) {
+	return c.ListTasksFiltered(ctx, TaskFilter{})
+}
+
+// ListTasksFiltered returns the task configurations selected by filter
+func (c *Client) ListTasksFiltered(ctx context.Context, filter TaskFilter) ([]*Task, error) {
+	query := url.Values{"tag": filter.Tags}
+	for _, taskType := range filter.Types {
+		query.Add("type", string(taskType))
+	}
+	if filter.Owner != "" {
+		query.Set("owner", filter.Owner)
+	}
+	if filter.Team != "" {
+		query.Set("team", filter.Team)
+	}
+	var tasks []*Task
+	if err := c.do(ctx, http.MethodGet, "/api/tasks", query, nil, &tasks); err != nil {
+		return nil, err
+	}
+	return tasks, nil
+}
+
+// GetTask returns a task configuration
+func (c *Client) GetTask(ctx context.Context, id string) (*Task, error) {
+	var task Task
+	if err := c.do(ctx, http.MethodGet, "/api/tasks/"+url.PathEscape(id), nil, nil, &task); err != nil {
+		return nil, err
+	}
+	return &task, nil
+}
+
+// CreateTask creates a task, returning it as stored with its next run time;
+// the server assigns an ID to a task without one
+func (c *Client) CreateTask(ctx context.Context, task *Task) (*Task, error) {
+	var created Task
+	if err := c.do(ctx, http.MethodPost, "/api/tasks", nil, task, &created); err != nil {
+		return nil, err
+	}
+	return &created, nil
+}
+
+// UpdateTask replaces the task with task.ID, returning it as stored
+func (c *Client) UpdateTask(ctx context.Context, task *Task) (*Task, error) {
+	var updated Task
+	if err := c.do(ctx, http.MethodPut, "/api/tasks/"+url.PathEscape(task.ID), nil, task, &updated); err != nil {
+		return nil, err
+	}
+	return &updated, nil
+}
+
+// DeleteTask deletes a task
+func (c *Client) DeleteTask(ctx context.Context, id string) error {
+	return c.do(ctx, http.MethodDelete, "/api/tasks/"+url.PathEscape(id), nil, nil, nil)
+}
+
+// RunTask runs a task now, returning its execution. A dry run only reports
+// what runners that delete or modify files would do.
+func (c *Client) RunTask(ctx context.Context, id string, dryRun bool) (*TaskExecution, error) {
+	query := url.Values{}
+	if dryRun {
+		query.Set("dry_run", "true")
+	}
+	var execution TaskExecution
+	if err := c.do(ctx, http.MethodPost, "/api/tasks/"+url.PathEscape(id)+"/run", query, nil, &execution); err != nil {
+		return nil, err
+	}
+	return &execution, nil
+}
+
+// TaskExecutions returns the latest executions of a task, newest first; a
+// limit of 0 uses the server's default of 10
+func (c *Client) TaskExecutions(ctx context.Context, id string, limit int) ([]TaskExecution, error) {
+	query := url.Values{}
+	if limit > 0 {
+		query.Set("limit", strconv.Itoa(limit))
+	}
+	var executions []TaskExecution
+	if err := c.do(ctx, http.MethodGet, "/api/tasks/"+url.PathEscape(id)+"/executions", query, nil, &executions); err != nil {
+		return nil, err
+	}
+	return executions, nil
+}
@@ -0,0 +1,121 @@
+// File: pkg/client/types.go
+// Brief: Resource types of the Argus API client
+// Detailed: Re-exports the server's own models as aliases, so programs outside the module can build and read alerts, tasks, notifications and metrics with the exact types the server encodes, and a field added on the server is available to clients without a copy drifting apart.
+// Author: drama.lin@aver.com
+// Date: 2026-10-14
+
+package client
+
+import (
+	"time"
+
+	"argus/internal/metrics"
+	"argus/internal/models"
+)
+
+// Alerts
+type (
+	Alert              = models.AlertConfig
+	Threshold          = models.ThresholdConfig
+	NotificationConfig = models.NotificationConfig
+	AlertStatus        = models.AlertStatus
+	AlertHistoryEntry  = models.AlertHistoryEntry
+	AlertSeverity      = models.AlertSeverity
+	AlertState         = models.AlertState
+	MetricType         = models.MetricType
+	ComparisonOperator = models.ComparisonOperator
+)
+
+// Alert severities, states, metric types and threshold operators
+const (
+	SeverityInfo     = models.SeverityInfo
+	SeverityWarning  = models.SeverityWarning
+	SeverityCritical = models.SeverityCritical
+
+	StateActive   = models.StateActive
+	StateInactive = models.StateInactive
+	StatePending  = models.StatePending
+	StateResolved = models.StateResolved
+
+	MetricCPU     = models.MetricCPU
+	MetricMemory  = models.MetricMemory
+	MetricLoad    = models.MetricLoad
+	MetricNetwork = models.MetricNetwork
+	MetricDisk    = models.MetricDisk
+	MetricProcess = models.MetricProcess
+	MetricSeries  = models.MetricSeries
+
+	OperatorGreaterThan        = models.OperatorGreaterThan
+	OperatorGreaterThanOrEqual = models.OperatorGreaterThanOrEqual
+	OperatorLessThan           = models.OperatorLessThan
+	OperatorLessThanOrEqual    = models.OperatorLessThanOrEqual
+	OperatorEqual              = models.OperatorEqual
+	OperatorNotEqual           = models.OperatorNotEqual
+)
+
+// Tasks
+type (
+	Task          = models.TaskConfig
+	Schedule      = models.Schedule
+	TaskExecution = models.TaskExecution
+	TaskType      = models.TaskType
+	TaskStatus    = models.TaskStatus
+)
+
+// Task types and execution statuses
+const (
+	TaskLogRotation        = models.TaskLogRotation
+	TaskMetricsAggregation = models.TaskMetricsAggregation
+	TaskHealthCheck        = models.TaskHealthCheck
+	TaskSystemCleanup      = models.TaskSystemCleanup
+
+	StatusPending   = models.StatusPending
+	StatusRunning   = models.StatusRunning
+	StatusCompleted = models.StatusCompleted
+	StatusFailed    = models.StatusFailed
+)
+
+// Notifications
+type Notification = models.InAppNotification
+
+// Metrics. The per-kind endpoints omit UpdatedAt; Snapshot carries it.
+type (
+	CPUMetrics     = metrics.CPUMetrics
+	MemoryMetrics  = metrics.MemoryMetrics
+	NetworkMetrics = metrics.NetworkMetrics
+	DiskMetrics    = metrics.DiskMetrics
+	RAIDMetrics    = metrics.RAIDMetrics
+	PowerMetrics   = metrics.PowerMetrics
+	ProcessInfo    = metrics.ProcessInfo
+	MetricHealth   = metrics.MetricHealth
+)
+
+// Snapshot is every collected metric at once, from GET /api/metrics/all
+type Snapshot struct {
+	CPU       *CPUMetrics     `json:"cpu"`
+	Memory    *MemoryMetrics  `json:"memory"`
+	Disk      *DiskMetrics    `json:"disk"`
+	RAID      *RAIDMetrics    `json:"raid"`
+	Power     *PowerMetrics   `json:"power"`
+	Network   *NetworkMetrics `json:"network"`
+	Processes *struct {
+		Top        []ProcessInfo `json:"top"` // By CPU usage
+		TotalCount int           `json:"total_count"`
+	} `json:"processes"`
+	Alerts *struct {
+		Total   int                `json:"total"`
+		ByState map[AlertState]int `json:"by_state"`
+	} `json:"alerts"` // Omitted without alerting
+	Healthy   bool      `json:"healthy"`
+	Status    string    `json:"status"` // initializing, healthy, degraded or stale
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// CollectorHealth is the health of the metrics collector, from
+// GET /api/metrics/health
+type CollectorHealth struct {
+	Status       string                  `json:"status"` // initializing, healthy, degraded or unhealthy
+	Healthy      bool                    `json:"healthy"`
+	Initializing bool                    `json:"initializing"`
+	Metrics      map[string]MetricHealth `json:"metrics"` // By metric kind, e.g. cpu
+}
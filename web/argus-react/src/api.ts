@@ -38,20 +38,60 @@ class RequestTimeoutError extends Error {
  */
 class ArgusApiClient {
   private baseURL: string;
+  private csrfToken: Promise<string | undefined> | null = null;
 
   constructor(baseURL: string = API_BASE_URL) {
     this.baseURL = baseURL;
   }
 
+  /**
+   * Sends the browser to the OIDC login when it is enabled, returning to the
+   * current page afterwards
+   */
+  private async redirectToLogin(): Promise<void> {
+    try {
+      const response = await fetch(`${this.baseURL}/api/auth/config`);
+      const config: { login_url?: string } = await response.json();
+      if (config.login_url) {
+        const redirect = window.location.pathname + window.location.search;
+        window.location.assign(`${this.baseURL}${config.login_url}?redirect=${encodeURIComponent(redirect)}`);
+      }
+    } catch {
+      // Report the 401 as is
+    }
+  }
+
+  /**
+   * Returns the CSRF token of the session cookie, fetched once; undefined
+   * when not signed in to a session
+   */
+  private getCSRFToken(): Promise<string | undefined> {
+    if (!this.csrfToken) {
+      this.csrfToken = fetch(`${this.baseURL}/api/auth/session`)
+        .then(async (response) => (response.ok ? ((await response.json()).csrf_token as string) : undefined))
+        .catch(() => undefined);
+    }
+    return this.csrfToken;
+  }
+
   /**
    * Generic request method with timeout and error handling
    */
   private async request<T>(
     endpoint: string,
     options: RequestInit = {},
-    timeout: number = API_TIMEOUT
+    timeout: number = API_TIMEOUT,
+    retried: boolean = false
   ): Promise<ApiResponse<T>> {
     const url = `${this.baseURL}${endpoint}`;
+    const method = (options.method || 'GET').toUpperCase();
+    const csrfHeaders: Record<string, string> = {};
+    if (!['GET', 'HEAD', 'OPTIONS'].includes(method)) {
+      const token = await this.getCSRFToken();
+      if (token) {
+        csrfHeaders['X-CSRF-Token'] = token;
+      }
+    }
     
     // Create abort controller for timeout
     const controller = new AbortController();
@@ -59,12 +99,13 @@ class ArgusApiClient {
     
     try {
       const response = await fetch(url, {
+        signal: controller.signal,
+        ...options,
         headers: {
           'Content-Type': 'application/json',
+          ...csrfHeaders,
           ...options.headers,
         },
-        signal: controller.signal,
-        ...options,
       });
 
       // Clear timeout since request completed
@@ -81,8 +122,15 @@ class ArgusApiClient {
             break;
           case 401:
             errorMessage = 'Authentication required';
+            this.csrfToken = null;
+            await this.redirectToLogin();
             break;
           case 403:
+            if (!retried && errorText.includes('CSRF')) {
+              // Signed in to a new session since the token was fetched
+              this.csrfToken = null;
+              return this.request<T>(endpoint, options, timeout, true);
+            }
             errorMessage = 'Access forbidden';
             break;
           case 404:
@@ -241,8 +289,8 @@ class ArgusApiClient {
   }
 
   // TODO: Not currently used in UI, consider removal if no future Task features planned
-  async runTask(id: string): Promise<ApiResponse<TaskExecution>> {
-    return this.request<TaskExecution>(`/api/tasks/${id}/run`, {
+  async runTask(id: string, dryRun = false): Promise<ApiResponse<TaskExecution>> {
+    return this.request<TaskExecution>(`/api/tasks/${id}/run${dryRun ? '?dry_run=true' : ''}`, {
       method: 'POST',
     });
   }
@@ -81,12 +81,36 @@ const METRIC_NAME_OPTIONS: Record<MetricType, { value: string, label: string }[]
   ],
   'disk': [
     { value: 'usage_percent', label: 'Disk Usage (%)' },
-    { value: 'read_bytes', label: 'Read Bytes' },
-    { value: 'write_bytes', label: 'Write Bytes' }
+    { value: 'free', label: 'Free Space (bytes)' },
+    { value: 'inode_percent', label: 'Inode Usage (%)' }
   ],
   'process': [
     { value: 'cpu_percent', label: 'Process CPU Usage (%)' },
-    { value: 'memory_percent', label: 'Process Memory Usage (%)' }
+    { value: 'memory_percent', label: 'Process Memory Usage (%)' },
+    { value: 'open_files', label: 'Open Files' },
+    { value: 'open_files_percent', label: 'Open Files (% of limit)' },
+    { value: 'address_space_percent', label: 'Address Space (% of limit)' },
+    { value: 'cgroup_memory_percent', label: 'Cgroup Memory (% of limit)' },
+    { value: 'count', label: 'Running Processes' }
+  ],
+  'raid': [
+    { value: 'degraded', label: 'Degraded Arrays and Pools' },
+    { value: 'failed_devices', label: 'Failed Devices' },
+    { value: 'rebuild_percent', label: 'Rebuild Progress (%)' },
+    { value: 'capacity_percent', label: 'ZFS Pool Capacity (%)' }
+  ],
+  'power': [
+    { value: 'charge_percent', label: 'Battery Charge (%)' },
+    { value: 'runtime_ms', label: 'Battery Runtime (ms)' },
+    { value: 'on_battery', label: 'Devices on Battery' },
+    { value: 'on_battery_ms', label: 'Time on Battery (ms)' },
+    { value: 'load_percent', label: 'UPS Load (%)' }
+  ],
+  'service': [
+    { value: 'down', label: 'Services Down' },
+    { value: 'restarts', label: 'Service Restarts' },
+    { value: 'ntp_synchronized', label: 'Clock Synchronized' },
+    { value: 'time_offset_ms', label: 'Clock Offset (ms)' }
   ]
 };
 
@@ -425,6 +449,9 @@ const AlertDialog: React.FC<AlertDialogProps> = ({
                     <MenuItem value="network">Network</MenuItem>
                     <MenuItem value="disk">Disk</MenuItem>
                     <MenuItem value="process">Process</MenuItem>
+                    <MenuItem value="raid">RAID / ZFS</MenuItem>
+                    <MenuItem value="power">Battery / UPS</MenuItem>
+                    <MenuItem value="service">System Services</MenuItem>
                   </Select>
                   {errors['threshold.metric_type'] && (
                     <FormHelperText>{errors['threshold.metric_type']}</FormHelperText>
@@ -8,6 +8,7 @@ export { useNotification } from './useNotification';
 export { useDataFetching } from './useDataFetching';
 export { useDialogState } from './useDialogState';
 export { useDateFormatter } from './useDateFormatter';
+export { useAlertStream } from './useAlertStream';
 
 // Re-export existing hooks for convenience
 export { default as useApiCache } from './useApiCache';
@@ -0,0 +1,31 @@
+import { useCallback } from 'react';
+import type { AlertStreamFrame } from '../types/api';
+import useWebSocket from './useWebSocket';
+
+/**
+ * Hook following the alert state changes streamed over the WebSocket
+ * (?subscribe=alerts). The first frame is a snapshot of the active alerts,
+ * later ones are state changes; other messages on the connection are ignored.
+ *
+ * @param onFrame Called with every alert stream frame
+ * @returns Whether the stream is connected
+ */
+export function useAlertStream(onFrame: (frame: AlertStreamFrame) => void): { isConnected: boolean } {
+  const handleMessage = useCallback(
+    (data: any) => {
+      if (data && data.type === 'alerts') {
+        onFrame(data as AlertStreamFrame);
+      }
+    },
+    [onFrame]
+  );
+
+  const scheme = window.location.protocol === 'https:' ? 'wss' : 'ws';
+  const { isConnected } = useWebSocket({
+    url: `${scheme}://${window.location.host}/ws?subscribe=alerts`,
+    onMessage: handleMessage,
+  });
+  return { isConnected };
+}
+
+export default useAlertStream;
@@ -21,11 +21,11 @@ import NotificationsOffIcon from '@mui/icons-material/NotificationsOff';
 import PlayArrowIcon from '@mui/icons-material/PlayArrow';
 import AddIcon from '@mui/icons-material/Add';
 import { apiClient } from '../api';
-import type { AlertConfig, AlertStatus } from '../types/api';
+import type { AlertConfig, AlertStatus, AlertStreamFrame } from '../types/api';
 import LoadingErrorHandler from '../components/LoadingErrorHandler';
 import AlertDialog from '../components/AlertDialog';
 import { PageHeader, ConfirmDialog, StatusChip, type StatusConfig } from '../components/common';
-import { useNotification, useDateFormatter, useResourceCRUD } from '../hooks';
+import { useNotification, useDateFormatter, useResourceCRUD, useAlertStream } from '../hooks';
 
 // Define status and severity maps for StatusChip
 const ALERT_STATUS_MAP: Record<string, StatusConfig> = {
@@ -95,17 +95,31 @@ const Alerts: React.FC = () => {
     }
   }, []);
 
+  // Apply alert state changes as the server streams them
+  const handleAlertFrame = useCallback((frame: AlertStreamFrame) => {
+    if (frame.kind === 'snapshot') {
+      // Sent on (re)connect; changes missed while disconnected need a refetch
+      fetchAlertStatuses();
+      return;
+    }
+    const status = frame.status;
+    if (status) {
+      setAlertStatuses((prev) => ({ ...prev, [status.alert_id]: status }));
+    }
+  }, [fetchAlertStatuses]);
+  const { isConnected: streaming } = useAlertStream(handleAlertFrame);
+
   // Fetch alerts and statuses on component mount
   useEffect(() => {
     fetchAlertStatuses();
 
-    // Set up interval to refresh alert statuses
-    const intervalId = setInterval(fetchAlertStatuses, 30000); // Every 30 seconds
+    // Refresh alert statuses, rarely while the stream delivers changes
+    const intervalId = setInterval(fetchAlertStatuses, streaming ? 300000 : 30000);
 
     return () => {
       clearInterval(intervalId);
     };
-  }, [fetchAlertStatuses]);
+  }, [fetchAlertStatuses, streaming]);
 
   // Handle opening the create alert dialog
   const openCreateAlertDialog = () => {
@@ -203,7 +217,10 @@ const Alerts: React.FC = () => {
       'load': 'System Load',
       'network': 'Network',
       'disk': 'Disk',
-      'process': 'Process'
+      'process': 'Process',
+      'raid': 'RAID / ZFS',
+      'power': 'Battery / UPS',
+      'service': 'System Services'
     };
 
     const metricType = threshold.metric_type;
@@ -223,10 +240,27 @@ const Alerts: React.FC = () => {
       'bytes_recv': 'Bytes Received',
       'packets_sent': 'Packets Sent',
       'packets_recv': 'Packets Received',
-      'read_bytes': 'Read (bytes)',
-      'write_bytes': 'Write (bytes)',
+      'inode_percent': 'Inodes (%)',
       'cpu_percent': 'CPU (%)',
-      'memory_percent': 'Memory (%)'
+      'memory_percent': 'Memory (%)',
+      'open_files': 'Open Files',
+      'open_files_percent': 'Open Files (% of limit)',
+      'address_space_percent': 'Address Space (% of limit)',
+      'cgroup_memory_percent': 'Cgroup Memory (% of limit)',
+      'count': 'Running Processes',
+      'degraded': 'Degraded',
+      'failed_devices': 'Failed Devices',
+      'rebuild_percent': 'Rebuild (%)',
+      'capacity_percent': 'Pool Capacity (%)',
+      'charge_percent': 'Charge (%)',
+      'runtime_ms': 'Runtime (ms)',
+      'on_battery': 'On Battery',
+      'on_battery_ms': 'Time on Battery (ms)',
+      'load_percent': 'UPS Load (%)',
+      'down': 'Services Down',
+      'restarts': 'Restarts',
+      'ntp_synchronized': 'Clock Synchronized',
+      'time_offset_ms': 'Clock Offset (ms)'
     };
 
     if (metricName in metricNameOptions) {
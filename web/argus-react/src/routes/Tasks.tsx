@@ -31,6 +31,7 @@ import {
 } from '@mui/material';
 import RefreshIcon from '@mui/icons-material/Refresh';
 import PlayArrowIcon from '@mui/icons-material/PlayArrow';
+import PreviewIcon from '@mui/icons-material/Preview';
 import EditIcon from '@mui/icons-material/Edit';
 import DeleteIcon from '@mui/icons-material/Delete';
 import InfoIcon from '@mui/icons-material/Info';
@@ -109,12 +110,12 @@ const Tasks: React.FC = () => {
   });
 
   // Function to run a task immediately
-  const runTask = useCallback(async (id: string) => {
+  const runTask = useCallback(async (id: string, dryRun = false) => {
     try {
-      const response = await apiClient.runTask(id);
+      const response = await apiClient.runTask(id, dryRun);
       
       if (response.success && response.data) {
-        showNotification('Task started successfully', 'success');
+        showNotification(dryRun ? 'Dry run completed: see the executions for its report' : 'Task started successfully', 'success');
         await refetch(); // Refresh to show updated status
       } else {
         // Check for specific error messages
@@ -332,6 +333,16 @@ const Tasks: React.FC = () => {
                                 <PlayArrowIcon fontSize="small" />
                               </IconButton>
                             </Tooltip>
+                            <Tooltip title="Dry run: report the changes without making them">
+                              <IconButton 
+                                size="small" 
+                                color="primary"
+                                onClick={() => runTask(task.id, true)}
+                                disabled={actionLoading}
+                              >
+                                <PreviewIcon fontSize="small" />
+                              </IconButton>
+                            </Tooltip>
                             <Tooltip title="View executions">
                               <IconButton 
                                 size="small" 
@@ -425,7 +436,7 @@ const Tasks: React.FC = () => {
                   value={newTask.schedule?.cron_expression || ''}
                   onChange={(e) => handleNewTaskChange('cron_expression', e.target.value)}
                   disabled={actionLoading}
-                  helperText="e.g., '0 * * * *' for hourly execution"
+                  helperText="e.g., '30 2 * * 1-5' for 02:30 on weekdays, or @hourly, @daily"
                 />
               </Grid>
               <Grid item xs={12} sm={6}>
@@ -113,7 +113,7 @@ export interface TaskExecution {
 /**
  * Metric type for alert monitoring
  */
-export type MetricType = 'cpu' | 'memory' | 'load' | 'network' | 'disk' | 'process';
+export type MetricType = 'cpu' | 'memory' | 'load' | 'network' | 'disk' | 'process' | 'raid' | 'power' | 'service';
 
 /**
  * Comparison operator for alert thresholds
@@ -209,6 +209,31 @@ export interface AlertStatus {
   message?: string;
 }
 
+/**
+ * Message of the WebSocket alert stream (?subscribe=alerts)
+ */
+export interface AlertStreamFrame {
+  type: 'alerts';
+  /** A snapshot of the active alerts, sent first, or a state change */
+  kind: 'snapshot' | 'event';
+  seq: number;
+  timestamp: string;
+  /** Active alerts (snapshot frames) */
+  active?: AlertStatus[];
+  /** The state change (event frames) */
+  event?: {
+    alert_id: string;
+    alert_name: string;
+    old_state: AlertState;
+    new_state: AlertState;
+    value: number;
+    message?: string;
+    timestamp: string;
+  };
+  /** Status of the alert after the change (event frames) */
+  status?: AlertStatus;
+}
+
 /**
  * Alert notification
  */